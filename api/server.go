@@ -80,6 +80,11 @@ func (w *wrappedResponseWriter) WriteHeader(status int) {
 	w.ResponseWriter.WriteHeader(w.status)
 }
 
+// Unwrap returns the wrapped writer, so the streaming handlers can flush it.
+func (w *wrappedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // withLoggingHandler returns the middleware which logs response status for request.
 func withLoggingHandler(l logrus.FieldLogger, next http.Handler) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
@@ -71,3 +71,16 @@ func TestPing(t *testing.T) {
 	assert.Equal(t, []byte{'o', 'k'}, rw.Body.Bytes())
 	assert.NoError(t, res.Body.Close())
 }
+
+func TestLoggerFlush(t *testing.T) {
+	t.Parallel()
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "http://example.com/v1/stream", nil)
+	l, _ := logtest.NewNullLogger()
+	withLoggingHandler(l, http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		// the streaming handlers flush through the logging writer
+		assert.NoError(t, http.NewResponseController(rw).Flush())
+	}))(rw, r)
+	assert.True(t, rw.Flushed)
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	v1 "go.k6.io/k6/api/v1"
+)
+
+// Scenarios returns the scenarios of the test run.
+func (c *Client) Scenarios(ctx context.Context) (ret []v1.Scenario, err error) {
+	var resp v1.ScenariosJSONAPI
+
+	if err = c.CallAPI(ctx, http.MethodGet, &url.URL{Path: "/v1/scenarios"}, nil, &resp); err != nil {
+		return ret, err
+	}
+
+	return resp.Scenarios(), nil
+}
+
+// SetScenario tries to change the configuration of the running scenario with
+// the name of the patch and returns the new one if it was successful.
+func (c *Client) SetScenario(ctx context.Context, patch v1.Scenario) (ret v1.Scenario, err error) {
+	var resp v1.ScenarioJSONAPI
+
+	apiURL := &url.URL{Path: "/v1/scenarios/" + url.PathEscape(patch.Name)}
+	if err = c.CallAPI(ctx, http.MethodPatch, apiURL, v1.NewScenarioJSONAPI(patch), &resp); err != nil {
+		return ret, err
+	}
+
+	return resp.Scenario(), nil
+}
@@ -38,6 +38,14 @@ func NewHandler(cs *ControlSurface) http.Handler {
 		handleGetMetric(cs, rw, r, id)
 	})
 
+	mux.HandleFunc("/v1/stream", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		handleStream(cs, rw, r)
+	})
+
 	mux.HandleFunc("/v1/groups", func(rw http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			rw.WriteHeader(http.StatusMethodNotAllowed)
@@ -57,6 +65,27 @@ func NewHandler(cs *ControlSurface) http.Handler {
 		handleGetGroup(cs, rw, r, id)
 	})
 
+	mux.HandleFunc("/v1/scenarios", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		handleGetScenarios(cs, rw, r)
+	})
+
+	mux.HandleFunc("/v1/scenarios/", func(rw http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[len("/v1/scenarios/"):]
+		switch r.Method {
+		case http.MethodGet:
+			handleGetScenario(cs, rw, r, name)
+		case http.MethodPatch:
+			handlePatchScenario(cs, rw, r, name)
+		default:
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
 	mux.HandleFunc("/v1/setup", func(rw http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/executor"
+	"go.k6.io/k6/lib/types"
+)
+
+// Scenario represents a scenario of the test run in a k6 REST API. Only the
+// attributes that can be changed while the test is running are filled, depending
+// on the executor of the scenario.
+type Scenario struct {
+	Name     string             `json:"-" yaml:"name"`
+	Executor string             `json:"executor" yaml:"executor"`
+	Rate     null.Int           `json:"rate" yaml:"rate"`
+	TimeUnit types.NullDuration `json:"time-unit" yaml:"time-unit"`
+	VUs      null.Int           `json:"vus" yaml:"vus"`
+	VUsMax   null.Int           `json:"vus-max" yaml:"vus-max"`
+}
+
+// NewScenario constructs a new v1.Scenario struct that is used for a scenario
+// representation in a k6 REST API
+func NewScenario(e lib.Executor) Scenario {
+	config := e.GetConfig()
+	scenario := Scenario{
+		Name:     config.GetName(),
+		Executor: config.GetType(),
+	}
+
+	switch e := e.(type) {
+	case *executor.ConstantArrivalRate:
+		currentConfig := e.GetCurrentConfig()
+		scenario.Rate = currentConfig.Rate
+		scenario.TimeUnit = types.NullDurationFrom(currentConfig.TimeUnit.TimeDuration())
+	case *executor.ExternallyControlled:
+		currentConfig := e.GetCurrentConfig()
+		scenario.VUs = currentConfig.VUs
+		scenario.VUsMax = currentConfig.MaxVUs
+	}
+
+	return scenario
+}
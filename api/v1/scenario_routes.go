@@ -0,0 +1,118 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/executor"
+)
+
+func handleGetScenarios(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request) {
+	executors := cs.Scheduler.GetExecutors()
+	scenarios := make([]Scenario, 0, len(executors))
+	for _, e := range executors {
+		scenarios = append(scenarios, NewScenario(e))
+	}
+
+	data, err := json.Marshal(newScenariosJSONAPI(scenarios))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = rw.Write(data)
+}
+
+func getScenarioExecutor(cs *ControlSurface, name string) lib.Executor {
+	for _, e := range cs.Scheduler.GetExecutors() {
+		if e.GetConfig().GetName() == name {
+			return e
+		}
+	}
+	return nil
+}
+
+func handleGetScenario(cs *ControlSurface, rw http.ResponseWriter, _ *http.Request, name string) {
+	e := getScenarioExecutor(cs, name)
+	if e == nil {
+		apiError(rw, "Not Found", "No scenario with that name was found", http.StatusNotFound)
+		return
+	}
+
+	data, err := json.Marshal(NewScenarioJSONAPI(NewScenario(e)))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = rw.Write(data)
+}
+
+// updateScenario changes the configuration of the running executor of a
+// scenario with the set attributes of the patch.
+func updateScenario(r *http.Request, e lib.Executor, patch Scenario) error {
+	switch e := e.(type) {
+	case *executor.ConstantArrivalRate:
+		if patch.VUs.Valid || patch.VUsMax.Valid {
+			return errors.New("only the rate of a constant-arrival-rate scenario can be changed")
+		}
+		if !patch.Rate.Valid {
+			return nil
+		}
+		newConfig := e.GetCurrentConfig()
+		newConfig.Rate = patch.Rate
+		return e.UpdateConfig(r.Context(), newConfig)
+	case *executor.ExternallyControlled:
+		if patch.Rate.Valid {
+			return errors.New("only the VUs of an externally-controlled scenario can be changed")
+		}
+		newConfig := e.GetCurrentConfig().ExternallyControlledConfigParams
+		if patch.VUsMax.Valid {
+			newConfig.MaxVUs = patch.VUsMax
+		}
+		if patch.VUs.Valid {
+			newConfig.VUs = patch.VUs
+		}
+		return e.UpdateConfig(r.Context(), newConfig)
+	default:
+		return fmt.Errorf("the %s executor can't be reconfigured during the test", e.GetConfig().GetType())
+	}
+}
+
+func handlePatchScenario(cs *ControlSurface, rw http.ResponseWriter, r *http.Request, name string) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	e := getScenarioExecutor(cs, name)
+	if e == nil {
+		apiError(rw, "Not Found", "No scenario with that name was found", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		apiError(rw, "Couldn't read request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var scenarioEnvelop ScenarioJSONAPI
+	if err = json.Unmarshal(body, &scenarioEnvelop); err != nil {
+		apiError(rw, "Invalid data", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = updateScenario(r, e, scenarioEnvelop.Scenario()); err != nil {
+		apiError(rw, "Config update error", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := json.Marshal(NewScenarioJSONAPI(NewScenario(e)))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func getTestScenarios(t *testing.T) lib.ScenarioConfigs {
+	scenarios := lib.ScenarioConfigs{}
+	err := json.Unmarshal([]byte(`{
+		"arrival": {"executor": "constant-arrival-rate", "rate": 10, "timeUnit": "1s",
+			"duration": "10m", "preAllocatedVUs": 1, "maxVUs": 1, "gracefulStop": "0s"},
+		"iterations": {"executor": "shared-iterations", "vus": 1, "iterations": 1}
+	}`), &scenarios)
+	require.NoError(t, err)
+	return scenarios
+}
+
+func TestGetScenarios(t *testing.T) {
+	t.Parallel()
+
+	testState := getTestRunState(t, lib.Options{Scenarios: getTestScenarios(t)}, &minirunner.MiniRunner{})
+	cs := getControlSurface(t, testState)
+
+	t.Run("list", func(t *testing.T) {
+		t.Parallel()
+
+		rw := httptest.NewRecorder()
+		NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/scenarios", nil))
+		res := rw.Result()
+		t.Cleanup(func() {
+			assert.NoError(t, res.Body.Close())
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var envelop ScenariosJSONAPI
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &envelop))
+		require.Len(t, envelop.Data, 2)
+		assert.Equal(t, "scenarios", envelop.Data[0].Type)
+		assert.Equal(t, []Scenario{
+			{
+				Name: "arrival", Executor: "constant-arrival-rate",
+				Rate: null.IntFrom(10), TimeUnit: types.NullDurationFrom(time.Second),
+			},
+			{Name: "iterations", Executor: "shared-iterations"},
+		}, envelop.Scenarios())
+	})
+
+	t.Run("single", func(t *testing.T) {
+		t.Parallel()
+
+		rw := httptest.NewRecorder()
+		NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/scenarios/arrival", nil))
+		res := rw.Result()
+		t.Cleanup(func() {
+			assert.NoError(t, res.Body.Close())
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var envelop ScenarioJSONAPI
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &envelop))
+		assert.Equal(t, "arrival", envelop.Data.ID)
+		assert.Equal(t, null.IntFrom(10), envelop.Scenario().Rate)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		rw := httptest.NewRecorder()
+		NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/scenarios/unknown", nil))
+		res := rw.Result()
+		t.Cleanup(func() {
+			assert.NoError(t, res.Body.Close())
+		})
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
+
+func TestPatchScenario(t *testing.T) {
+	t.Parallel()
+
+	testState := getTestRunState(t, lib.Options{Scenarios: getTestScenarios(t)}, &minirunner.MiniRunner{})
+	cs := getControlSurface(t, testState)
+
+	globalCtx, globalCancel := context.WithCancel(context.Background())
+	defer globalCancel()
+	runCtx, runAbort := execution.NewTestRunContext(globalCtx, testState.Logger)
+	cs.RunCtx = runCtx
+
+	samples := make(chan metrics.SampleContainer, 1000)
+	go func() {
+		for range samples { //nolint:revive
+		}
+	}()
+	stopEmission, err := cs.Scheduler.Init(runCtx, samples)
+	require.NoError(t, err)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	defer func() {
+		runAbort(fmt.Errorf("custom cancel signal"))
+		wg.Wait()
+	}()
+	go func() {
+		assert.ErrorContains(t, cs.Scheduler.Run(globalCtx, runCtx, samples), "custom cancel signal")
+		stopEmission()
+		close(samples)
+		wg.Done()
+	}()
+	// wait for the executor to start running
+	time.Sleep(200 * time.Millisecond)
+
+	patch := func(name string, payload string) (*http.Response, []byte) {
+		rw := httptest.NewRecorder()
+		NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(
+			http.MethodPatch, "/v1/scenarios/"+name, bytes.NewReader([]byte(payload))))
+		res := rw.Result()
+		t.Cleanup(func() {
+			assert.NoError(t, res.Body.Close())
+		})
+		return res, rw.Body.Bytes()
+	}
+
+	res, body := patch("arrival", `{"data":{"type":"scenarios","id":"arrival","attributes":{"rate":50}}}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var envelop ScenarioJSONAPI
+	require.NoError(t, json.Unmarshal(body, &envelop))
+	assert.Equal(t, null.IntFrom(50), envelop.Scenario().Rate)
+
+	res, _ = patch("arrival", `{"data":{"type":"scenarios","id":"arrival","attributes":{"vus":5}}}`)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, _ = patch("arrival", `{"data":{"type":"scenarios","id":"arrival","attributes":{"rate":0}}}`)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, _ = patch("iterations", `{"data":{"type":"scenarios","id":"iterations","attributes":{"rate":5}}}`)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, _ = patch("unknown", `{"data":{"type":"scenarios","id":"unknown","attributes":{"rate":5}}}`)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
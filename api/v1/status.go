@@ -3,6 +3,7 @@ package v1
 import (
 	"gopkg.in/guregu/null.v3"
 
+	"go.k6.io/k6/execution"
 	"go.k6.io/k6/lib"
 )
 
@@ -16,6 +17,11 @@ type Status struct {
 	Stopped bool      `json:"stopped" yaml:"stopped"`
 	Running bool      `json:"running" yaml:"running"`
 	Tainted bool      `json:"tainted" yaml:"tainted"`
+
+	// AbortReason is why the test run was aborted, if it was, and
+	// FailureReasons why the script marked it as failed, if it did.
+	AbortReason    string   `json:"abort-reason,omitempty" yaml:"abort-reason,omitempty"`
+	FailureReasons []string `json:"failure-reasons,omitempty" yaml:"failure-reasons,omitempty"`
 }
 
 func newStatus(cs *ControlSurface) Status {
@@ -26,13 +32,19 @@ func newStatus(cs *ControlSurface) Status {
 		isStopped = true
 	default:
 	}
+	var abortReason string
+	if abortErr := execution.GetCancelReasonIfTestAborted(cs.RunCtx); abortErr != nil {
+		abortReason = abortErr.Error()
+	}
 	return Status{
-		Status:  executionState.GetCurrentExecutionStatus(),
-		Running: executionState.HasStarted() && !executionState.HasEnded(),
-		Paused:  null.BoolFrom(executionState.IsPaused()),
-		Stopped: isStopped,
-		VUs:     null.IntFrom(executionState.GetCurrentlyActiveVUsCount()),
-		VUsMax:  null.IntFrom(executionState.GetInitializedVUsCount()),
-		Tainted: cs.MetricsEngine.GetMetricsWithBreachedThresholdsCount() > 0,
+		Status:         executionState.GetCurrentExecutionStatus(),
+		Running:        executionState.HasStarted() && !executionState.HasEnded(),
+		Paused:         null.BoolFrom(executionState.IsPaused()),
+		Stopped:        isStopped,
+		VUs:            null.IntFrom(executionState.GetCurrentlyActiveVUsCount()),
+		VUsMax:         null.IntFrom(executionState.GetInitializedVUsCount()),
+		Tainted:        cs.MetricsEngine.GetMetricsWithBreachedThresholdsCount() > 0,
+		AbortReason:    abortReason,
+		FailureReasons: executionState.GetFailureReasons(),
 	}
 }
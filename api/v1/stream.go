@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"sort"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/ui/pb"
+)
+
+// ThresholdState represents the state of a threshold of a metric, as of its
+// last evaluation, in a k6 REST API.
+type ThresholdState struct {
+	Metric      string `json:"metric" yaml:"metric"`
+	Source      string `json:"source" yaml:"source"`
+	Failed      bool   `json:"failed" yaml:"failed"`
+	AbortOnFail bool   `json:"abort-on-fail" yaml:"abort-on-fail"`
+}
+
+// newThresholdStates returns the states of the thresholds of the metrics,
+// sorted by metric. The metrics lock has to be held.
+func newThresholdStates(observed map[string]*metrics.Metric) []ThresholdState {
+	states := []ThresholdState{}
+	for _, m := range observed {
+		for _, threshold := range m.Thresholds.Thresholds {
+			states = append(states, ThresholdState{
+				Metric:      m.Name,
+				Source:      threshold.Source,
+				Failed:      threshold.LastFailed,
+				AbortOnFail: threshold.AbortOnFail,
+			})
+		}
+	}
+	sort.SliceStable(states, func(i, j int) bool { return states[i].Metric < states[j].Metric })
+	return states
+}
+
+// ScenarioProgress represents the progress of a scenario of the test run in
+// a k6 REST API, as shown by its progress bar.
+type ScenarioProgress struct {
+	Name     string  `json:"name" yaml:"name"`
+	Executor string  `json:"executor" yaml:"executor"`
+	Status   string  `json:"status" yaml:"status"`
+	Progress float64 `json:"progress" yaml:"progress"`
+}
+
+//nolint:gochecknoglobals
+var progressStatuses = map[pb.Status]string{
+	pb.Running:     "running",
+	pb.Waiting:     "waiting",
+	pb.Stopping:    "stopping",
+	pb.Interrupted: "interrupted",
+	pb.Done:        "done",
+}
+
+func newScenarioProgress(e lib.Executor) ScenarioProgress {
+	config := e.GetConfig()
+	progress, status := e.GetProgress().Progress()
+	statusName, ok := progressStatuses[status]
+	if !ok { // the scenario isn't scheduled yet
+		statusName = progressStatuses[pb.Waiting]
+	}
+	return ScenarioProgress{
+		Name:     config.GetName(),
+		Executor: config.GetType(),
+		Status:   statusName,
+		Progress: progress,
+	}
+}
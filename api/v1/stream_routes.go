@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultStreamInterval = time.Second
+	minStreamInterval     = 100 * time.Millisecond
+)
+
+// handleStream pushes the status of the test run, the aggregates of the
+// metrics, the states of the thresholds and the progress of the scenarios as
+// server-sent events, every interval, until the client disconnects or the test
+// run ends, so the clients don't have to poll the other endpoints.
+func handleStream(cs *ControlSurface, rw http.ResponseWriter, r *http.Request) {
+	interval := defaultStreamInterval
+	if v := r.URL.Query().Get("interval"); v != "" {
+		var err error
+		interval, err = time.ParseDuration(v)
+		if err != nil {
+			apiError(rw, "Invalid interval", err.Error(), http.StatusBadRequest)
+			return
+		}
+		if interval < minStreamInterval {
+			apiError(rw, "Invalid interval",
+				fmt.Sprintf("the interval should be at least %s", minStreamInterval), http.StatusBadRequest)
+			return
+		}
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+	controller := http.NewResponseController(rw)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := writeStreamEvents(cs, rw); err != nil {
+			cs.RunState.Logger.WithError(err).Debug("Error while streaming the test run")
+			return
+		}
+		if err := controller.Flush(); err != nil {
+			cs.RunState.Logger.WithError(err).Debug("Error while flushing the test run stream")
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-r.Context().Done():
+			return
+		case <-cs.RunCtx.Done():
+			// the final state of the test run
+			if err := writeStreamEvents(cs, rw); err == nil {
+				_ = writeStreamEvent(rw, "end", struct{}{})
+				_ = controller.Flush()
+			}
+			return
+		}
+	}
+}
+
+func writeStreamEvents(cs *ControlSurface, w io.Writer) error {
+	var t time.Duration
+	if cs.Scheduler != nil {
+		t = cs.Scheduler.GetState().GetCurrentTestRunDuration()
+	}
+
+	cs.MetricsEngine.MetricsLock.Lock()
+	metrics := newMetricsJSONAPI(cs.MetricsEngine.ObservedMetrics, t)
+	thresholds := newThresholdStates(cs.MetricsEngine.ObservedMetrics)
+	cs.MetricsEngine.MetricsLock.Unlock()
+
+	executors := cs.Scheduler.GetExecutors()
+	scenarios := make([]ScenarioProgress, 0, len(executors))
+	for _, e := range executors {
+		scenarios = append(scenarios, newScenarioProgress(e))
+	}
+
+	if err := writeStreamEvent(w, "status", newStatusJSONAPIFromEngine(cs)); err != nil {
+		return err
+	}
+	if err := writeStreamEvent(w, "metrics", metrics); err != nil {
+		return err
+	}
+	if err := writeStreamEvent(w, "thresholds", thresholds); err != nil {
+		return err
+	}
+	return writeStreamEvent(w, "scenarios", scenarios)
+}
+
+// writeStreamEvent writes the value as the JSON data of a server-sent event.
+func writeStreamEvent(w io.Writer, event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
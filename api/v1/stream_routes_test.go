@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/minirunner"
+	"go.k6.io/k6/metrics"
+)
+
+// readStreamEvents reads the server-sent events until the end event or the
+// end of the stream, and returns the data of the events by name.
+func readStreamEvents(t *testing.T, res *http.Response) map[string][]string {
+	t.Helper()
+
+	events := make(map[string][]string)
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var event string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			events[event] = append(events[event], strings.TrimPrefix(line, "data: "))
+			if event == "end" {
+				return events
+			}
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	testState := getTestRunState(t, lib.Options{Scenarios: getTestScenarios(t)}, &minirunner.MiniRunner{})
+	testMetric, err := testState.Registry.NewMetric("my_metric", metrics.Trend, metrics.Time)
+	require.NoError(t, err)
+	testMetric.Thresholds = metrics.NewThresholds([]string{"p(95)<100", "max<200"})
+	testMetric.Thresholds.Thresholds[0].LastFailed = true
+	cs := getControlSurface(t, testState)
+	cs.MetricsEngine.ObservedMetrics = map[string]*metrics.Metric{
+		"my_metric": testMetric,
+	}
+
+	runCtx, cancelRun := context.WithCancel(cs.RunCtx)
+	cs.RunCtx = runCtx
+	srv := httptest.NewServer(NewHandler(cs))
+	t.Cleanup(srv.Close)
+
+	res, err := http.Get(srv.URL + "/v1/stream?interval=100ms") //nolint:noctx
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, res.Body.Close())
+	})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	cancelRun()
+	events := readStreamEvents(t, res)
+	require.Len(t, events["end"], 1)
+	for _, name := range []string{"status", "metrics", "thresholds", "scenarios"} {
+		require.NotEmpty(t, events[name], name)
+	}
+
+	var status StatusJSONAPI
+	require.NoError(t, json.Unmarshal([]byte(events["status"][0]), &status))
+	assert.Equal(t, "status", status.Data.Type)
+
+	var metricsDoc MetricsJSONAPI
+	require.NoError(t, json.Unmarshal([]byte(events["metrics"][0]), &metricsDoc))
+	require.Len(t, metricsDoc.Data, 1)
+	assert.Equal(t, "my_metric", metricsDoc.Data[0].ID)
+
+	var thresholds []ThresholdState
+	require.NoError(t, json.Unmarshal([]byte(events["thresholds"][0]), &thresholds))
+	assert.Equal(t, []ThresholdState{
+		{Metric: "my_metric", Source: "p(95)<100", Failed: true},
+		{Metric: "my_metric", Source: "max<200", Failed: false},
+	}, thresholds)
+
+	var scenarios []ScenarioProgress
+	require.NoError(t, json.Unmarshal([]byte(events["scenarios"][0]), &scenarios))
+	require.Len(t, scenarios, 2)
+	assert.Equal(t, ScenarioProgress{
+		Name: "arrival", Executor: "constant-arrival-rate", Status: "waiting", Progress: 0,
+	}, scenarios[0])
+}
+
+func TestStreamInvalidInterval(t *testing.T) {
+	t.Parallel()
+
+	cs := getControlSurface(t, getTestRunState(t, lib.Options{}, &minirunner.MiniRunner{}))
+	for _, interval := range []string{"second", "10ms"} {
+		rw := httptest.NewRecorder()
+		NewHandler(cs).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/v1/stream?interval="+interval, nil))
+		res := rw.Result()
+		assert.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, interval)
+	}
+}
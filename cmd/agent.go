@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"net/url"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/execution/distributed"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/loader"
+	"go.k6.io/k6/output"
+)
+
+// loadDistributedTest registers with the coordinator and loads the test
+// archive it returns, configured to only run the execution segment that was
+// assigned to this agent.
+func loadDistributedTest(
+	gs *state.GlobalState, cmd *cobra.Command, client *distributed.Client,
+) (*loadedAndConfiguredTest, *distributed.RegisterResponse, error) {
+	resp, err := client.Register(gs.Ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	gs.Logger.Debugf("Registered as instance %d, executing segment %s of %s",
+		resp.InstanceID, resp.ExecutionSegment, resp.ExecutionSegmentSequence)
+
+	segment, err := lib.NewExecutionSegmentFromString(resp.ExecutionSegment)
+	if err != nil {
+		return nil, nil, err
+	}
+	sequence, err := lib.NewExecutionSegmentSequenceFromString(resp.ExecutionSegmentSequence)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pwd, err := gs.Getwd()
+	if err != nil {
+		return nil, nil, err
+	}
+	src := &loader.SourceData{
+		URL:  &url.URL{Scheme: "file", Path: "/archive.tar"},
+		Data: resp.Archive,
+	}
+	test, err := loadTest(gs, cmd, "archive.tar", src, loader.CreateFilesystems(gs.FS), pwd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The thresholds and the end-of-test summary are handled by the
+	// coordinator, since only it has the metrics of the whole test run.
+	test.preInitState.RuntimeOptions.NoThresholds.Bool = true
+	test.preInitState.RuntimeOptions.NoSummary.Bool = true
+
+	configuredTest, err := test.consolidateDeriveAndValidateConfig(gs, cmd, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	configuredTest.derivedConfig.ExecutionSegment = segment
+	configuredTest.derivedConfig.ExecutionSegmentSequence = &sequence
+
+	return configuredTest, resp, nil
+}
+
+func agentFlagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.AddFlagSet(runtimeOptionFlagSet(false))
+	return flags
+}
+
+func getCmdAgent(gs *state.GlobalState) *cobra.Command {
+	c := &cmdRun{gs: gs}
+	c.loadConfiguredTest = func(cmd *cobra.Command, args []string) (*loadedAndConfiguredTest, execution.Controller, error) {
+		client := distributed.NewClient(args[0], gs.Env[coordinatorTokenEnv])
+		test, resp, err := loadDistributedTest(gs, cmd, client)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.extraOutputs = []output.Output{distributed.NewOutput(client, resp.InstanceID, gs.Logger)}
+		return test, distributed.NewController(gs.Ctx, client, resp.InstanceID, gs.Logger), nil
+	}
+
+	exampleText := getExampleText(gs, `
+  # Run the part of the test assigned by the coordinator at 10.0.0.1:6566.
+  K6_COORDINATOR_TOKEN=secret {{.}} agent 10.0.0.1:6566`[1:])
+
+	agentCmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Join a distributed test run as an agent",
+		Long: `Join a distributed test run as an agent.
+
+The agent gets the test archive and the part of the test it should execute from
+the coordinator started with "k6 coordinator", and sends all of its metrics back
+to it. The thresholds and the end-of-test summary are handled by the coordinator.
+
+The agent authenticates with the token set in the K6_COORDINATOR_TOKEN
+environment variable.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be the address of the coordinator"),
+		RunE:    c.run,
+	}
+
+	agentCmd.Flags().SortFlags = false
+	agentCmd.Flags().AddFlagSet(agentFlagSet())
+
+	return agentCmd
+}
@@ -275,3 +275,46 @@ func TestArchiveStdout(t *testing.T) {
 
 	require.GreaterOrEqual(t, len(ts.Stdout.Bytes()), 32)
 }
+
+func TestArchiveRunStreamedAsset(t *testing.T) {
+	t.Parallel()
+
+	testScript := []byte(`
+		import { check } from "k6";
+		import { openStream } from "k6/experimental/fs";
+
+		export const options = { iterations: 1, thresholds: { checks: ["rate==1"] } };
+
+		// opening the asset in the init context bundles it in the archive
+		openStream("data.bin");
+
+		export default async function () {
+			const reader = (await openStream("data.bin")).getReader();
+			let size = 0, chunks = 0;
+			for (;;) {
+				const { done, value } = await reader.read();
+				if (done) {
+					break;
+				}
+				size += value.length;
+				chunks++;
+			}
+			check(size, { "the whole asset is streamed": (s) => s === 100000 && chunks > 1 });
+		}
+	`)
+
+	ts := tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "script.js"), testScript, 0o644))
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "data.bin"), make([]byte, 100000), 0o644))
+	ts.CmdArgs = []string{"k6", "archive", "script.js"}
+	newRootCommand(ts.GlobalState).execute()
+
+	archive, err := fsext.ReadFile(ts.FS, "archive.tar")
+	require.NoError(t, err)
+
+	// the archive is run without the original files
+	runTS := tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(runTS.FS, filepath.Join(runTS.Cwd, "archive.tar"), archive, 0o644))
+	runTS.CmdArgs = []string{"k6", "run", "archive.tar"}
+	newRootCommand(runTS.GlobalState).execute()
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/baseline"
+	"go.k6.io/k6/lib/fsext"
+)
+
+// loadBaseline loads the baseline summary the end-of-test summary is compared
+// with, and parses the tolerances of the comparisons.
+func loadBaseline(gs *state.GlobalState, opts lib.RuntimeOptions) (*baseline.Baseline, baseline.Tolerances, error) {
+	var specs []string
+	if opts.BaselineTolerance.String != "" {
+		specs = strings.Split(opts.BaselineTolerance.String, ",")
+	}
+	tolerances, err := baseline.ParseTolerances(specs)
+	if err != nil {
+		return nil, baseline.Tolerances{}, err
+	}
+
+	data, err := fsext.ReadFile(gs.FS, opts.Baseline.String)
+	if err != nil {
+		return nil, baseline.Tolerances{}, fmt.Errorf("couldn't read the baseline: %w", err)
+	}
+	b, err := baseline.Load(data)
+	if err != nil {
+		return nil, baseline.Tolerances{}, fmt.Errorf("invalid baseline %s: %w", opts.Baseline.String, err)
+	}
+
+	return b, tolerances, nil
+}
+
+// getBaselineError returns the error failing the test run because of the
+// regressions of the metrics compared with the baseline.
+func getBaselineError(regressions []baseline.Comparison) error {
+	regressed := make([]string, len(regressions))
+	for i, r := range regressions {
+		regressed[i] = r.Metric + " " + r.Stat
+	}
+	return fmt.Errorf("metrics '%s' regressed beyond their baseline tolerances", strings.Join(regressed, ", "))
+}
@@ -267,5 +267,10 @@ func validateScenarioConfig(conf lib.ExecutorConfig, isExecutable func(string) b
 	if !isExecutable(execFn) {
 		return fmt.Errorf("executor %s: function '%s' not found in exports", conf.GetName(), execFn)
 	}
+	for _, fn := range []string{conf.GetSetup(), conf.GetTeardown()} {
+		if fn != "" && !isExecutable(fn) {
+			return fmt.Errorf("executor %s: function '%s' not found in exports", conf.GetName(), fn)
+		}
+	}
 	return nil
 }
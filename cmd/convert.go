@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/lib/converter"
+	"go.k6.io/k6/lib/fsext"
+)
+
+// convertCmd represents the `k6 convert` command
+type convertCmd struct {
+	gs *state.GlobalState
+
+	output         string
+	baseURL        string
+	overwriteFiles bool
+}
+
+func (c *convertCmd) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.StringVarP(&c.output, "output", "O", "", "output filename (stdout by default)")
+	flags.StringVar(&c.baseURL, "base-url", "", "base URL of the requests, instead of the server of an OpenAPI document")
+	flags.BoolVarP(&c.overwriteFiles, "force", "f", false, "Overwrite existing files")
+
+	return flags
+}
+
+func (c *convertCmd) run(_ *cobra.Command, args []string) error {
+	data, err := fsext.ReadFile(c.gs.FS, args[0])
+	if err != nil {
+		return err
+	}
+
+	script, err := converter.Convert(data, converter.Options{BaseURL: c.baseURL})
+	if err != nil {
+		return err
+	}
+
+	if c.output == "" || c.output == "-" {
+		_, err = c.gs.Stdout.Write(script)
+		return err
+	}
+
+	fileExists, err := fsext.Exists(c.gs.FS, c.output)
+	if err != nil {
+		return err
+	}
+	if fileExists && !c.overwriteFiles {
+		return fmt.Errorf("%s already exists, please use the `--force` flag if you want overwrite it", c.output)
+	}
+	if err = fsext.WriteFile(c.gs.FS, c.output, script, 0o644); err != nil {
+		return err
+	}
+
+	valueColor := getColor(c.gs.Flags.NoColor || !c.gs.Stdout.IsTTY, color.Bold)
+	printToStdout(c.gs, fmt.Sprintf(
+		"Converted %s into %s. You can now edit it, and execute it by running `%s run %s`.\n",
+		args[0], valueColor.Sprint(c.output), c.gs.BinaryName, c.output,
+	))
+	return nil
+}
+
+func getCmdConvert(gs *state.GlobalState) *cobra.Command {
+	c := &convertCmd{gs: gs}
+
+	exampleText := getExampleText(gs, `
+  # Convert a Postman collection into a script printed to stdout
+  {{.}} convert collection.postman_collection.json
+
+  # Convert an OpenAPI document into script.js
+  {{.}} convert -O script.js openapi.yaml
+
+  # Convert an OpenAPI document, sending the requests to a staging server
+  {{.}} convert --base-url https://staging.example.com -O script.js openapi.json`[1:])
+
+	convertCmd := &cobra.Command{
+		Use:   "convert",
+		Short: "Convert a Postman collection or an OpenAPI document into a k6 script",
+		Long: `Convert a Postman collection or an OpenAPI document into a k6 script.
+
+The format of the document, either a Postman collection v2.1 export or an
+OpenAPI 3.x document in JSON or YAML, is detected from its content.
+
+The requests of a Postman collection are made in the groups of its folders,
+with its variables overridable by the environment variables of the same names.
+
+Every operation of an OpenAPI document is made by an exported function,
+executed once by a scenario of its own. The parameters and bodies are taken
+from the examples of the document, or generated from their schemas, and the
+credentials of its security schemes are read from environment variables.
+
+The generated script is a starting point, which usually has to be edited.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be a path to a Postman collection or an OpenAPI document"),
+		RunE:    c.run,
+	}
+	convertCmd.Flags().AddFlagSet(c.flagSet())
+
+	return convertCmd
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cmd/tests"
+	"go.k6.io/k6/lib/fsext"
+)
+
+const testConvertCollection = `{
+  "info": {
+    "name": "Test",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "item": [{"name": "Hello", "request": "https://test.k6.io/hello"}]
+}`
+
+func TestConvertCmd_Stdout(t *testing.T) {
+	t.Parallel()
+
+	ts := tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, "collection.json", []byte(testConvertCollection), 0o644))
+	ts.CmdArgs = []string{"k6", "convert", "collection.json"}
+
+	newRootCommand(ts.GlobalState).execute()
+
+	assert.Contains(t, ts.Stdout.String(), `res = http.request("GET", "https://test.k6.io/hello", null);`)
+}
+
+func TestConvertCmd_Output(t *testing.T) {
+	t.Parallel()
+
+	ts := tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, "openapi.yaml", []byte(`
+openapi: 3.0.3
+info:
+  title: Test
+paths:
+  /hello:
+    get:
+      operationId: hello
+      responses:
+        "200":
+          description: Hello
+`), 0o644))
+	ts.CmdArgs = []string{"k6", "convert", "--base-url", "https://staging.k6.local", "-O", "test.js", "openapi.yaml"}
+
+	newRootCommand(ts.GlobalState).execute()
+
+	data, err := fsext.ReadFile(ts.FS, "test.js")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `const baseUrl = __ENV.BASE_URL || "https://staging.k6.local";`)
+	assert.Contains(t, string(data), "export function hello() {")
+}
+
+func TestConvertCmd_FileExists_NoOverwrite(t *testing.T) {
+	t.Parallel()
+
+	ts := tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, "collection.json", []byte(testConvertCollection), 0o644))
+	require.NoError(t, fsext.WriteFile(ts.FS, "test.js", []byte("untouched"), 0o644))
+	ts.CmdArgs = []string{"k6", "convert", "-O", "test.js", "collection.json"}
+	ts.ExpectedExitCode = -1
+
+	newRootCommand(ts.GlobalState).execute()
+
+	data, err := fsext.ReadFile(ts.FS, "test.js")
+	require.NoError(t, err)
+	assert.Equal(t, "untouched", string(data))
+}
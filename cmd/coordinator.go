@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/execution/distributed"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/metrics/engine"
+	"go.k6.io/k6/output"
+)
+
+// coordinatorTokenEnv is the environment variable holding the token the agents
+// authenticate with to the coordinator.
+const coordinatorTokenEnv = "K6_COORDINATOR_TOKEN"
+
+// cmdCoordinator handles the `k6 coordinator` sub-command
+type cmdCoordinator struct {
+	gs *state.GlobalState
+
+	address       string
+	instanceCount int
+}
+
+//nolint:funlen,gocognit,cyclop
+func (c *cmdCoordinator) run(cmd *cobra.Command, args []string) (err error) {
+	var logger logrus.FieldLogger = c.gs.Logger
+	printBanner(c.gs)
+
+	globalCtx, globalCancel := context.WithCancel(c.gs.Ctx)
+	defer globalCancel()
+
+	test, err := loadAndConfigureLocalTest(c.gs, cmd, args, getConfig)
+	if err != nil {
+		return err
+	}
+	if test.keyLogger != nil {
+		defer func() {
+			if klErr := test.keyLogger.Close(); klErr != nil {
+				logger.WithError(klErr).Warn("Error while closing the SSLKEYLOGFILE")
+			}
+		}()
+	}
+
+	// Same as with `k6 archive`, only the consolidated options should be
+	// archived, the agents derive the rest of the options themselves.
+	if err = test.initRunner.SetOptions(test.consolidatedConfig.Options); err != nil {
+		return err
+	}
+	archive := &bytes.Buffer{}
+	if err = test.initRunner.MakeArchive().Write(archive); err != nil {
+		return err
+	}
+
+	conf := test.derivedConfig
+	testRunState, err := test.buildTestRunState(conf.Options)
+	if err != nil {
+		return err
+	}
+
+	executionTuple, err := lib.NewExecutionTuple(nil, nil)
+	if err != nil {
+		return err
+	}
+	executionPlan := conf.Scenarios.GetFullExecutionRequirements(executionTuple)
+	outputs, err := createOutputs(c.gs, test, executionPlan)
+	if err != nil {
+		return err
+	}
+	outputs = append(outputs, testRunState.GroupSummary)
+
+	metricsEngine, err := engine.NewMetricsEngine(testRunState.Registry, logger)
+	if err != nil {
+		return err
+	}
+
+	shouldProcessMetrics := (!testRunState.RuntimeOptions.NoSummary.Bool ||
+		!testRunState.RuntimeOptions.NoThresholds.Bool)
+	var metricsIngester *engine.OutputIngester
+	if shouldProcessMetrics {
+		err = metricsEngine.InitSubMetricsAndThresholds(conf.Options, testRunState.RuntimeOptions.NoThresholds.Bool)
+		if err != nil {
+			return err
+		}
+		if !testRunState.RuntimeOptions.NoSummary.Bool {
+			metricsEngine.EnableBreakdowns()
+		}
+		metricsIngester = metricsEngine.CreateIngester()
+		outputs = append(outputs, metricsIngester)
+	}
+
+	// The agents get the test archive, with its environment variables, so
+	// they have to authenticate. A random token is generated if none is set.
+	token, generatedToken := c.gs.Env[coordinatorTokenEnv], false
+	if token == "" {
+		if token, err = newCoordinatorToken(); err != nil {
+			return err
+		}
+		generatedToken = true
+	}
+
+	samples := make(chan metrics.SampleContainer, conf.MetricSamplesBufferSize.Int64)
+	coordinator, err := distributed.NewCoordinator(
+		archive.Bytes(), c.instanceCount, token, testRunState.Registry, samples, logger,
+	)
+	if err != nil {
+		return err
+	}
+
+	if !testRunState.RuntimeOptions.NoSummary.Bool {
+		defer func() {
+			logger.Debug("Generating the end-of-test summary...")
+			summaryResult, hsErr := test.initRunner.HandleSummary(globalCtx, &lib.Summary{
+				Metrics:         metricsEngine.ObservedMetrics,
+				ScenarioMetrics: metricsEngine.ScenarioMetrics,
+				GroupMetrics:    metricsEngine.GroupMetrics,
+				RootGroup:       testRunState.GroupSummary.Group(),
+				TestRunDuration: coordinator.GetCurrentTestRunDuration(),
+				NoColor:         c.gs.Flags.NoColor,
+				UIState: lib.UIState{
+					IsStdOutTTY: c.gs.Stdout.IsTTY,
+					IsStdErrTTY: c.gs.Stderr.IsTTY,
+				},
+			})
+			if hsErr == nil {
+				hsErr = handleSummaryResult(c.gs.FS, c.gs.Stdout, c.gs.Stderr, summaryResult)
+			}
+			if hsErr != nil {
+				logger.WithError(hsErr).Error("failed to handle the end-of-test summary")
+			}
+		}()
+	}
+
+	outputManager := output.NewManager(outputs, logger, func(err error) {
+		if err != nil {
+			logger.WithError(err).Error("Received error to stop from output")
+		}
+		coordinator.Abort(err)
+	})
+	outputManager.SetCardinalityLimiter(newCardinalityLimiter(conf.Options))
+	outputManager.SetRedaction(conf.Redact)
+	waitOutputsFlushed, stopOutputs, err := outputManager.Start(samples)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		logger.Debug("Stopping outputs...")
+		stopOutputs(err)
+	}()
+
+	if !testRunState.RuntimeOptions.NoThresholds.Bool {
+		finalizeThresholds := metricsEngine.StartThresholdCalculations(
+			metricsIngester, coordinator.Abort, coordinator.GetCurrentTestRunDuration,
+		)
+		if finalizeThresholds != nil {
+			defer func() {
+				logger.Debug("Finalizing thresholds...")
+				breachedThresholds := finalizeThresholds()
+				if len(breachedThresholds) == 0 {
+					return
+				}
+				tErr := errext.WithAbortReasonIfNone(
+					errext.WithExitCodeIfNone(
+						fmt.Errorf("thresholds on metrics '%s' have been crossed", strings.Join(breachedThresholds, ", ")),
+						exitcodes.ThresholdsHaveFailed,
+					), errext.AbortedByThresholdsAfterTestEnd)
+				if err == nil {
+					err = tErr
+				} else {
+					logger.WithError(tErr).Debug("Crossed thresholds, but test already exited with another error")
+				}
+			}()
+		}
+	}
+
+	defer func() {
+		logger.Debug("Waiting for metrics processing to finish...")
+		coordinator.Close()
+		close(samples)
+		waitOutputsFlushed()
+		logger.Debug("Metrics processing finished!")
+	}()
+
+	listener, err := net.Listen("tcp", c.address)
+	if err != nil {
+		return fmt.Errorf("could not start the coordinator: %w", err)
+	}
+	srv := &http.Server{Handler: coordinator.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if serr := srv.Serve(listener); serr != nil && !errors.Is(serr, http.ErrServerClosed) {
+			logger.WithError(serr).Error("Error from the coordinator server")
+			globalCancel()
+		}
+	}()
+	defer func() {
+		shutdCtx, shutdCancel := context.WithTimeout(c.gs.Ctx, 1*time.Second)
+		defer shutdCancel()
+		if serr := srv.Shutdown(shutdCtx); serr != nil {
+			logger.WithError(serr).Debug("The coordinator server did not shut down correctly")
+			_ = srv.Close()
+		}
+	}()
+
+	printExecutionDescription(
+		c.gs, "distributed", args[0], "", conf, executionTuple, executionPlan, outputs,
+	)
+	logger.Infof("Waiting for %d instances to connect to %s...", c.instanceCount, listener.Addr())
+	if generatedToken {
+		logger.Infof("The agents have to be started with %s=%s", coordinatorTokenEnv, token)
+	}
+
+	gracefulStop := func(sig os.Signal) {
+		logger.WithField("sig", sig).Debug("Stopping k6 in response to signal...")
+		coordinator.Abort(errext.WithAbortReasonIfNone(
+			errext.WithExitCodeIfNone(
+				fmt.Errorf("test run was aborted because k6 received a '%s' signal", sig), exitcodes.ExternalAbort,
+			), errext.AbortedByUser,
+		))
+	}
+	onHardStop := func(sig os.Signal) {
+		logger.WithField("sig", sig).Error("Aborting k6 in response to signal")
+		globalCancel()
+	}
+	stopSignalHandling := handleTestAbortSignals(c.gs, gracefulStop, onHardStop)
+	defer stopSignalHandling()
+
+	select {
+	case <-coordinator.Done():
+	case <-globalCtx.Done():
+		return globalCtx.Err()
+	}
+
+	if err = coordinator.AbortError(); err != nil {
+		return err
+	}
+	return coordinator.InstanceError()
+}
+
+// newCoordinatorToken returns a new random token for the agents to
+// authenticate with.
+func newCoordinatorToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate the coordinator token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (c *cmdCoordinator) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.AddFlagSet(optionFlagSet())
+	flags.AddFlagSet(runtimeOptionFlagSet(true))
+	flags.AddFlagSet(configFlagSet())
+	flags.StringVar(&c.address, "coordinator-address", c.address, "address the agents connect to")
+	flags.IntVar(&c.instanceCount, "instance-count", c.instanceCount, "number of agents the test is split between")
+	return flags
+}
+
+func getCmdCoordinator(gs *state.GlobalState) *cobra.Command {
+	c := &cmdCoordinator{
+		gs:            gs,
+		address:       "localhost:6566",
+		instanceCount: 1,
+	}
+
+	exampleText := getExampleText(gs, `
+  # Split the test between 3 agents and wait for them to connect.
+  K6_COORDINATOR_TOKEN=secret {{.}} coordinator --instance-count 3 --coordinator-address 0.0.0.0:6566 script.js
+
+  # On each of the 3 machines, join the test run.
+  K6_COORDINATOR_TOKEN=secret {{.}} agent 10.0.0.1:6566`[1:])
+
+	coordinatorCmd := &cobra.Command{
+		Use:   "coordinator",
+		Short: "Coordinate a distributed test run",
+		Long: `Coordinate a distributed test run.
+
+The coordinator splits the test between the given number of agents started with
+"k6 agent", synchronizes their execution and aggregates all of their metrics.
+The thresholds, the end-of-test summary and the outputs are all handled by the
+coordinator, as if the whole test was executed by a single instance.
+
+The agents authenticate with the token set in the K6_COORDINATOR_TOKEN
+environment variable, or with the random one the coordinator logs if it's not
+set, as they get the test archive along with its environment variables.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should either be \"-\", if reading script from stdin, or a path to a script file"),
+		RunE:    c.run,
+	}
+
+	coordinatorCmd.Flags().SortFlags = false
+	coordinatorCmd.Flags().AddFlagSet(c.flagSet())
+
+	return coordinatorCmd
+}
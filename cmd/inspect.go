@@ -1,18 +1,23 @@
 package cmd
 
 import (
+	"crypto/x509"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/types"
 )
 
 // TODO: split apart like `k6 run` and `k6 archive`
 func getCmdInspect(gs *state.GlobalState) *cobra.Command {
-	var addExecReqs bool
+	var addExecReqs, addExecPlan bool
 
 	// inspectCmd represents the inspect command
 	inspectCmd := &cobra.Command{
@@ -30,12 +35,18 @@ func getCmdInspect(gs *state.GlobalState) *cobra.Command {
 			// (equal to the lib.Options struct) and extended, with additional
 			// fields with execution requirements.
 			var inspectOutput interface{}
-			if addExecReqs {
+			switch {
+			case addExecPlan:
+				inspectOutput, err = inspectOutputWithExecPlan(gs, cmd, test)
+				if err != nil {
+					return err
+				}
+			case addExecReqs:
 				inspectOutput, err = inspectOutputWithExecRequirements(gs, cmd, test)
 				if err != nil {
 					return err
 				}
-			} else {
+			default:
 				inspectOutput = test.initRunner.GetOptions()
 			}
 
@@ -55,19 +66,42 @@ func getCmdInspect(gs *state.GlobalState) *cobra.Command {
 		"execution-requirements",
 		false,
 		"include calculations of execution requirements for the test")
+	inspectCmd.Flags().BoolVar(&addExecPlan,
+		"execution-plan",
+		false,
+		"include the execution requirements and the VUs schedule of every scenario, and validate the "+
+			"certificates and the baseline of the test, without running it")
 
 	return inspectCmd
 }
 
+// inspectExecRequirements is the output of `k6 inspect` with the execution
+// requirements of the test.
+type inspectExecRequirements struct {
+	lib.Options
+	TotalDuration types.NullDuration `json:"totalDuration"`
+	MaxVUs        uint64             `json:"maxVUs"`
+}
+
 // If --execution-requirements is enabled, this will consolidate the config,
 // derive the value of `scenarios` and calculate the max test duration and VUs.
 func inspectOutputWithExecRequirements(
 	gs *state.GlobalState, cmd *cobra.Command, test *loadedTest,
 ) (interface{}, error) {
+	configuredTest, et, err := consolidateInspectedTest(gs, cmd, test)
+	if err != nil {
+		return nil, err
+	}
+	return newInspectExecRequirements(configuredTest, et), nil
+}
+
+func consolidateInspectedTest(
+	gs *state.GlobalState, cmd *cobra.Command, test *loadedTest,
+) (*loadedAndConfiguredTest, *lib.ExecutionTuple, error) {
 	// we don't actually support CLI flags here, so we pass nil as the getter
 	configuredTest, err := test.consolidateDeriveAndValidateConfig(gs, cmd, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	et, err := lib.NewExecutionTuple(
@@ -75,19 +109,130 @@ func inspectOutputWithExecRequirements(
 		configuredTest.derivedConfig.ExecutionSegmentSequence,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	return configuredTest, et, nil
+}
 
+func newInspectExecRequirements(
+	configuredTest *loadedAndConfiguredTest, et *lib.ExecutionTuple,
+) inspectExecRequirements {
 	executionPlan := configuredTest.derivedConfig.Scenarios.GetFullExecutionRequirements(et)
 	duration, _ := lib.GetEndOffset(executionPlan)
 
+	return inspectExecRequirements{
+		Options:       configuredTest.derivedConfig.Options,
+		TotalDuration: types.NewNullDuration(duration, true),
+		MaxVUs:        lib.GetMaxPossibleVUs(executionPlan),
+	}
+}
+
+// inspectExecutionStep is a step of the VUs schedule, from its time offset
+// until the next one.
+type inspectExecutionStep struct {
+	TimeOffset      types.Duration `json:"timeOffset"`
+	PlannedVUs      uint64         `json:"plannedVUs"`
+	MaxUnplannedVUs uint64         `json:"maxUnplannedVUs"`
+}
+
+// inspectScenarioPlan is the VUs schedule of a scenario, with the time
+// offsets of its steps relative to its start.
+type inspectScenarioPlan struct {
+	Name            string                 `json:"name"`
+	Executor        string                 `json:"executor"`
+	StartTime       types.Duration         `json:"startTime"`
+	LatestStartTime types.Duration         `json:"latestStartTime"`
+	Duration        types.Duration         `json:"duration"`
+	MaxVUs          uint64                 `json:"maxVUs"`
+	Steps           []inspectExecutionStep `json:"steps"`
+}
+
+func newInspectExecutionSteps(steps []lib.ExecutionStep) []inspectExecutionStep {
+	result := make([]inspectExecutionStep, len(steps))
+	for i, step := range steps {
+		result[i] = inspectExecutionStep{
+			TimeOffset:      types.Duration(step.TimeOffset),
+			PlannedVUs:      step.PlannedVUs,
+			MaxUnplannedVUs: step.MaxUnplannedVUs,
+		}
+	}
+	return result
+}
+
+// If --execution-plan is enabled, on top of the execution requirements, this
+// will resolve the VUs schedule of every scenario and of the whole test, and
+// validate what the test depends on and can only fail once it's running.
+func inspectOutputWithExecPlan(
+	gs *state.GlobalState, cmd *cobra.Command, test *loadedTest,
+) (interface{}, error) {
+	configuredTest, et, err := consolidateInspectedTest(gs, cmd, test)
+	if err != nil {
+		return nil, err
+	}
+	scenarios := configuredTest.derivedConfig.Scenarios
+	executionPlan := scenarios.GetFullExecutionRequirements(et)
+	duration, _ := lib.GetEndOffset(executionPlan)
+	if err = validateInspectedTest(gs, configuredTest, duration); err != nil {
+		return nil, errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+	}
+
+	plans := make([]inspectScenarioPlan, 0, len(scenarios))
+	for _, config := range scenarios.GetSortedConfigs() {
+		steps := config.GetExecutionRequirements(et)
+		duration, _ := lib.GetEndOffset(steps)
+		earliest, latest := scenarios.GetStartOffsets(et, config.GetName())
+		plans = append(plans, inspectScenarioPlan{
+			Name:            config.GetName(),
+			Executor:        config.GetType(),
+			StartTime:       types.Duration(earliest),
+			LatestStartTime: types.Duration(latest),
+			Duration:        types.Duration(duration),
+			MaxVUs:          lib.GetMaxPossibleVUs(steps),
+			Steps:           newInspectExecutionSteps(steps),
+		})
+	}
+
 	return struct {
-		lib.Options
-		TotalDuration types.NullDuration `json:"totalDuration"`
-		MaxVUs        uint64             `json:"maxVUs"`
+		inspectExecRequirements
+		ExecutionPlan []inspectExecutionStep `json:"executionPlan"`
+		Scenarios     []inspectScenarioPlan  `json:"scenarioPlans"`
 	}{
-		configuredTest.derivedConfig.Options,
-		types.NewNullDuration(duration, true),
-		lib.GetMaxPossibleVUs(executionPlan),
+		newInspectExecRequirements(configuredTest, et),
+		newInspectExecutionSteps(executionPlan),
+		plans,
 	}, nil
 }
+
+// validateInspectedTest validates what the test depends on, and isn't already
+// validated when it's loaded and its config is consolidated, i.e. the imports
+// and the files opened in the init context: the TLS client certificates
+// should be valid during the whole test, and the baseline should be readable.
+func validateInspectedTest(gs *state.GlobalState, test *loadedAndConfiguredTest, duration time.Duration) error {
+	start := time.Now()
+	end := start.Add(duration)
+
+	for _, auth := range test.derivedConfig.TLSAuth {
+		cert, err := auth.Certificate()
+		if err != nil {
+			return err
+		}
+		if len(cert.Certificate) == 0 {
+			continue
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("invalid TLS client certificate for %v: %w", auth.Domains, err)
+		}
+		if start.Before(leaf.NotBefore) || end.After(leaf.NotAfter) {
+			return fmt.Errorf("the TLS client certificate for %v is only valid from %s to %s, not during the whole test",
+				auth.Domains, leaf.NotBefore.Format(time.RFC3339), leaf.NotAfter.Format(time.RFC3339))
+		}
+	}
+
+	if test.preInitState.RuntimeOptions.Baseline.String != "" {
+		if _, _, err := loadBaseline(gs, test.preInitState.RuntimeOptions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cmd/tests"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func TestInspectExecutionPlan(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		export const options = {
+			scenarios: {
+				ramp: {
+					executor: "ramping-vus",
+					startVUs: 0,
+					stages: [{ duration: "10s", target: 2 }, { duration: "10s", target: 0 }],
+					gracefulRampDown: "0s",
+					gracefulStop: "0s",
+				},
+				after: {
+					executor: "constant-vus",
+					vus: 3,
+					duration: "5s",
+					gracefulStop: "0s",
+					startTime: "20s",
+				},
+			},
+		};
+		export default function() {};
+	`
+
+	ts := tests.NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+	ts.CmdArgs = []string{"k6", "inspect", "--execution-plan", "test.js"}
+	newRootCommand(ts.GlobalState).execute()
+
+	var output struct {
+		TotalDuration string                 `json:"totalDuration"`
+		MaxVUs        uint64                 `json:"maxVUs"`
+		ExecutionPlan []inspectExecutionStep `json:"executionPlan"`
+		Scenarios     []inspectScenarioPlan  `json:"scenarioPlans"`
+	}
+	require.NoError(t, json.Unmarshal(ts.Stdout.Bytes(), &output))
+	assert.Equal(t, "25s", output.TotalDuration)
+	assert.Equal(t, uint64(3), output.MaxVUs)
+	require.NotEmpty(t, output.ExecutionPlan)
+	assert.Equal(t, uint64(3), output.ExecutionPlan[len(output.ExecutionPlan)-2].PlannedVUs)
+
+	require.Len(t, output.Scenarios, 2)
+	ramp := output.Scenarios[0]
+	assert.Equal(t, "ramp", ramp.Name)
+	assert.Equal(t, "ramping-vus", ramp.Executor)
+	assert.Equal(t, "20s", ramp.Duration.String())
+	assert.Equal(t, uint64(2), ramp.MaxVUs)
+	after := output.Scenarios[1]
+	assert.Equal(t, "after", after.Name)
+	assert.Equal(t, "20s", after.StartTime.String())
+	assert.Equal(t, "20s", after.LatestStartTime.String())
+	assert.Equal(t, []inspectExecutionStep{{PlannedVUs: 3}, {TimeOffset: after.Duration}}, after.Steps)
+}
+
+// getTestCertificate returns a self-signed certificate and its key, valid
+// between the times, PEM-encoded.
+func getTestCertificate(t *testing.T, notBefore, notAfter time.Time) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "k6"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestInspectExecutionPlanValidation(t *testing.T) {
+	t.Parallel()
+
+	getScript := func(cert, key string) string {
+		tlsAuth, err := json.Marshal([]map[string]interface{}{
+			{"domains": []string{"example.com"}, "cert": cert, "key": key},
+		})
+		require.NoError(t, err)
+		return fmt.Sprintf(`
+			export const options = { vus: 1, duration: "1h", tlsAuth: %s };
+			export default function() {};
+		`, tlsAuth)
+	}
+
+	now := time.Now()
+	validCert, validKey := getTestCertificate(t, now.Add(-time.Hour), now.Add(24*time.Hour))
+	expiringCert, expiringKey := getTestCertificate(t, now.Add(-time.Hour), now.Add(time.Minute))
+
+	testCases := map[string]struct {
+		script string
+		args   []string
+		err    string
+	}{
+		"valid": {
+			script: getScript(validCert, validKey),
+		},
+		"certificate expiring during the test": {
+			script: getScript(expiringCert, expiringKey),
+			err:    "the TLS client certificate for [example.com] is only valid from",
+		},
+		"missing baseline": {
+			script: getScript(validCert, validKey),
+			args:   []string{"--baseline", "baseline.json"},
+			err:    "couldn't read the baseline",
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := tests.NewGlobalTestState(t)
+			require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(tc.script), 0o644))
+			ts.CmdArgs = append([]string{"k6", "inspect", "--execution-plan"}, tc.args...)
+			ts.CmdArgs = append(ts.CmdArgs, "test.js")
+			if tc.err != "" {
+				ts.ExpectedExitCode = int(exitcodes.InvalidConfig)
+			}
+			newRootCommand(ts.GlobalState).execute()
+
+			if tc.err != "" {
+				assert.Contains(t, ts.Stderr.String(), tc.err)
+				return
+			}
+			assert.Contains(t, ts.Stdout.String(), `"scenarioPlans"`)
+		})
+	}
+}
@@ -40,6 +40,9 @@ func optionFlagSet() *pflag.FlagSet {
 	flags.String("user-agent", fmt.Sprintf("k6/%s (https://k6.io/)", consts.Version), "user agent for http requests")
 	flags.String("http-debug", "", "log all HTTP requests and responses. Excludes body by default. To include body use '--http-debug=full'") //nolint:lll
 	flags.Lookup("http-debug").NoOptDefVal = "headers"
+	flags.String("trace-propagator", "", "propagate a trace context with the HTTP and gRPC requests, "+
+		"in the formats of these comma-separated propagators: 'w3c' or 'b3'")
+	flags.Float64("trace-sampling", 1, "ratio, between 0 and 1, of the propagated traces that are sampled")
 	flags.Bool("insecure-skip-tls-verify", false, "skip verification of TLS certificates")
 	flags.Bool("no-connection-reuse", false, "disable keep-alive connections")
 	flags.Bool("no-vu-connection-reuse", false, "don't reuse connections between iterations")
@@ -57,6 +60,10 @@ func optionFlagSet() *pflag.FlagSet {
 	)
 	flags.StringSlice("summary-trend-stats", nil, sumTrendStatsHelp)
 	flags.String("summary-time-unit", "", "define the time unit used to display the trend stats. Possible units are: 's', 'ms' and 'us'") //nolint:lll
+	flags.Bool("exact-trend-percentiles", false, "keep all the values of trend metrics to calculate exact percentiles, "+
+		"instead of estimating them from histograms")
+	flags.Int64("trend-percentiles-precision", metrics.DefaultTrendPrecision,
+		"precision, in significant digits, of the percentiles estimated from the histograms of trend metrics")
 	// system-tags must have a default value, but we can't specify it here, otherwiese, it will always override others.
 	// set it to nil here, and add the default in applyDefault() instead.
 	systemTagsCliHelpText := fmt.Sprintf(
@@ -64,6 +71,12 @@ func optionFlagSet() *pflag.FlagSet {
 		metrics.DefaultSystemTagSet.SetString(),
 	)
 	flags.StringSlice("system-tags", nil, systemTagsCliHelpText)
+	flags.Int64("metric-cardinality-limit", 0, "limit of the unique tag sets of every metric, past which the new "+
+		"values of its highest-cardinality tag are folded into '_other', 0 for no limit")
+	flags.Int64("tag-cardinality-limit", 0, "limit of the unique values of every tag of every metric, past which "+
+		"its new values are folded into '_other', 0 for no limit")
+	flags.Bool("load-generator-metrics", false, "emit the loadgen_* metrics of the CPU, the memory, the GC pauses "+
+		"and the event loop lag of the load generator itself")
 	flags.StringSlice("tag", nil, "add a `tag` to be applied to all samples, as `[name]=[value]`")
 	flags.String("console-output", "", "redirects the console logging to the provided output file")
 	flags.Bool("discard-response-bodies", false, "Read but don't process or save HTTP response bodies")
@@ -80,25 +93,32 @@ func optionFlagSet() *pflag.FlagSet {
 //nolint:funlen,gocognit,cyclop // this needs breaking up but probably should wait for croconf
 func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 	opts := lib.Options{
-		VUs:                     getNullInt64(flags, "vus"),
-		Duration:                getNullDuration(flags, "duration"),
-		Iterations:              getNullInt64(flags, "iterations"),
-		Paused:                  getNullBool(flags, "paused"),
-		NoSetup:                 getNullBool(flags, "no-setup"),
-		NoTeardown:              getNullBool(flags, "no-teardown"),
-		MaxRedirects:            getNullInt64(flags, "max-redirects"),
-		Batch:                   getNullInt64(flags, "batch"),
-		BatchPerHost:            getNullInt64(flags, "batch-per-host"),
-		RPS:                     getNullInt64(flags, "rps"),
-		UserAgent:               getNullString(flags, "user-agent"),
-		HTTPDebug:               getNullString(flags, "http-debug"),
-		InsecureSkipTLSVerify:   getNullBool(flags, "insecure-skip-tls-verify"),
-		NoConnectionReuse:       getNullBool(flags, "no-connection-reuse"),
-		NoVUConnectionReuse:     getNullBool(flags, "no-vu-connection-reuse"),
-		MinIterationDuration:    getNullDuration(flags, "min-iteration-duration"),
-		Throw:                   getNullBool(flags, "throw"),
-		DiscardResponseBodies:   getNullBool(flags, "discard-response-bodies"),
-		MetricSamplesBufferSize: null.NewInt(1000, false),
+		VUs:                       getNullInt64(flags, "vus"),
+		Duration:                  getNullDuration(flags, "duration"),
+		Iterations:                getNullInt64(flags, "iterations"),
+		Paused:                    getNullBool(flags, "paused"),
+		NoSetup:                   getNullBool(flags, "no-setup"),
+		NoTeardown:                getNullBool(flags, "no-teardown"),
+		MaxRedirects:              getNullInt64(flags, "max-redirects"),
+		Batch:                     getNullInt64(flags, "batch"),
+		BatchPerHost:              getNullInt64(flags, "batch-per-host"),
+		RPS:                       getNullInt64(flags, "rps"),
+		UserAgent:                 getNullString(flags, "user-agent"),
+		HTTPDebug:                 getNullString(flags, "http-debug"),
+		TracePropagator:           getNullString(flags, "trace-propagator"),
+		TraceSampling:             getNullFloat64(flags, "trace-sampling"),
+		InsecureSkipTLSVerify:     getNullBool(flags, "insecure-skip-tls-verify"),
+		NoConnectionReuse:         getNullBool(flags, "no-connection-reuse"),
+		NoVUConnectionReuse:       getNullBool(flags, "no-vu-connection-reuse"),
+		MinIterationDuration:      getNullDuration(flags, "min-iteration-duration"),
+		Throw:                     getNullBool(flags, "throw"),
+		DiscardResponseBodies:     getNullBool(flags, "discard-response-bodies"),
+		ExactTrendPercentiles:     getNullBool(flags, "exact-trend-percentiles"),
+		TrendPercentilesPrecision: getNullInt64(flags, "trend-percentiles-precision"),
+		MetricSamplesBufferSize:   null.NewInt(1000, false),
+		MetricCardinalityLimit:    getNullInt64(flags, "metric-cardinality-limit"),
+		TagCardinalityLimit:       getNullInt64(flags, "tag-cardinality-limit"),
+		LoadGeneratorMetrics:      getNullBool(flags, "load-generator-metrics"),
 	}
 
 	// Using Changed() because GetStringSlice() doesn't differentiate between empty and no value
@@ -9,7 +9,9 @@ import (
 	"go.k6.io/k6/cmd/state"
 	"go.k6.io/k6/ext"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/output"
+	"go.k6.io/k6/output/clickhouse"
 	"go.k6.io/k6/output/cloud"
 	"go.k6.io/k6/output/csv"
 	"go.k6.io/k6/output/influxdb"
@@ -38,16 +40,19 @@ const (
 	builtinOutputKafka
 	builtinOutputStatsd
 	builtinOutputExperimentalOpentelemetry
+	builtinOutputOpentelemetry
+	builtinOutputClickhouse
 )
 
 // TODO: move this to an output sub-module after we get rid of the old collectors?
 func getAllOutputConstructors() (map[string]output.Constructor, error) {
 	// Start with the built-in outputs
 	result := map[string]output.Constructor{
-		builtinOutputJSON.String():     json.New,
-		builtinOutputCloud.String():    cloud.New,
-		builtinOutputCSV.String():      csv.New,
-		builtinOutputInfluxdb.String(): influxdb.New,
+		builtinOutputJSON.String():       json.New,
+		builtinOutputCloud.String():      cloud.New,
+		builtinOutputCSV.String():        csv.New,
+		builtinOutputInfluxdb.String():   influxdb.New,
+		builtinOutputClickhouse.String(): clickhouse.New,
 		builtinOutputKafka.String(): func(_ output.Params) (output.Output, error) {
 			return nil, errors.New("the kafka output was deprecated in k6 v0.32.0 and removed in k6 v0.34.0, " +
 				"please use the new xk6 kafka output extension instead - https://github.com/k6io/xk6-output-kafka")
@@ -70,6 +75,11 @@ func getAllOutputConstructors() (map[string]output.Constructor, error) {
 		},
 		"web-dashboard": dashboard.New,
 		builtinOutputExperimentalOpentelemetry.String(): func(params output.Params) (output.Output, error) {
+			params.Logger.Warn("The experimental-opentelemetry output is now available as opentelemetry, " +
+				"the experimental-opentelemetry name is deprecated and will be removed in a future release.")
+			return opentelemetry.New(params)
+		},
+		builtinOutputOpentelemetry.String(): func(params output.Params) (output.Output, error) {
 			return opentelemetry.New(params)
 		},
 	}
@@ -161,6 +171,15 @@ func createOutputs(
 	return result, nil
 }
 
+// newCardinalityLimiter returns the limiter of the cardinality of the metrics
+// sent to the outputs, or nil if it isn't limited.
+func newCardinalityLimiter(opts lib.Options) *metrics.CardinalityLimiter {
+	if opts.MetricCardinalityLimit.Int64 <= 0 && opts.TagCardinalityLimit.Int64 <= 0 {
+		return nil
+	}
+	return metrics.NewCardinalityLimiter(int(opts.MetricCardinalityLimit.Int64), int(opts.TagCardinalityLimit.Int64))
+}
+
 func parseOutputArgument(s string) (t, arg string) {
 	parts := strings.SplitN(s, "=", 2)
 	switch len(parts) {
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/recorder"
+	"go.k6.io/k6/lib/types"
+)
+
+const (
+	defaultRecordListen = "localhost:8888"
+	defaultRecordCACert = "k6-record-ca.pem"
+	defaultRecordCAKey  = "k6-record-ca-key.pem"
+)
+
+// recordCmd represents the `k6 record` command
+type recordCmd struct {
+	gs *state.GlobalState
+
+	listen         string
+	caCert         string
+	caKey          string
+	hosts          []string
+	minThinkTime   time.Duration
+	overwriteFiles bool
+}
+
+func (c *recordCmd) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.StringVar(&c.listen, "listen", defaultRecordListen, "address the recording proxy listens on")
+	flags.StringVar(&c.caCert, "ca-cert", defaultRecordCACert,
+		"PEM file of the CA certificate intercepting the TLS connections, which is generated if it doesn't exist")
+	flags.StringVar(&c.caKey, "ca-key", defaultRecordCAKey, "PEM file of the private key of the CA")
+	flags.StringSliceVar(&c.hosts, "hosts", nil,
+		"only record the requests to these hostname patterns, e.g. '*.example.com', others are only tunneled")
+	flags.DurationVar(&c.minThinkTime, "min-think-time", recorder.DefaultMinThinkTime,
+		"minimum pause between two requests which is replayed with a sleep")
+	flags.BoolVarP(&c.overwriteFiles, "force", "f", false, "Overwrite existing files")
+
+	return flags
+}
+
+func (c *recordCmd) run(_ *cobra.Command, args []string) error {
+	target := defaultNewScriptName
+	if len(args) > 0 {
+		target = args[0]
+	}
+
+	fileExists, err := fsext.Exists(c.gs.FS, target)
+	if err != nil {
+		return err
+	}
+	if fileExists && !c.overwriteFiles {
+		return fmt.Errorf("%s already exists, please use the `--force` flag if you want overwrite it", target)
+	}
+
+	ca, err := c.loadOrCreateCA()
+	if err != nil {
+		return err
+	}
+
+	recording := &recorder.Recording{}
+	proxy := recorder.NewProxy(ca, recording, c.gs.Logger)
+	if len(c.hosts) > 0 {
+		if proxy.Hosts, err = types.NewHostnameTrie(c.hosts); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("tcp", c.listen)
+	if err != nil {
+		return fmt.Errorf("the recording proxy couldn't listen on %s: %w", c.listen, err)
+	}
+	server := &http.Server{Handler: proxy, ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- server.Serve(listener) }()
+
+	sigC := make(chan os.Signal, 1)
+	c.gs.SignalNotify(sigC, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer c.gs.SignalStop(sigC)
+
+	valueColor := getColor(c.gs.Flags.NoColor || !c.gs.Stdout.IsTTY, color.Bold)
+	printToStdout(c.gs, fmt.Sprintf(
+		"Recording the requests made through the proxy at %s.\n"+
+			"Configure it as the HTTP and HTTPS proxy of the client, which has to trust the CA certificate in %s.\n"+
+			"Press Ctrl+C to stop the recording and generate the script.\n",
+		valueColor.Sprint("http://"+listener.Addr().String()), valueColor.Sprint(c.caCert),
+	))
+
+	select {
+	case <-sigC:
+	case <-c.gs.Ctx.Done():
+	case err = <-serveErr:
+		return fmt.Errorf("the recording proxy failed: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = server.Shutdown(ctx); err != nil {
+		_ = server.Close()
+	}
+
+	entries := recording.Entries()
+	script := recorder.GenerateScript(entries, recorder.ScriptOptions{MinThinkTime: c.minThinkTime})
+	if err = fsext.WriteFile(c.gs.FS, target, script, 0o644); err != nil {
+		return err
+	}
+
+	printToStdout(c.gs, fmt.Sprintf(
+		"Recorded %d requests in %s. You can now edit it, and execute it by running `%s run %s`.\n",
+		len(entries), valueColor.Sprint(target), c.gs.BinaryName, target,
+	))
+	return nil
+}
+
+// loadOrCreateCA loads the CA from its files, or generates a new one and
+// writes it to them if they don't exist, so it only has to be trusted once.
+func (c *recordCmd) loadOrCreateCA() (*recorder.CA, error) {
+	certExists, err := fsext.Exists(c.gs.FS, c.caCert)
+	if err != nil {
+		return nil, err
+	}
+	keyExists, err := fsext.Exists(c.gs.FS, c.caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if certExists && keyExists {
+		certPEM, err := fsext.ReadFile(c.gs.FS, c.caCert)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM, err := fsext.ReadFile(c.gs.FS, c.caKey)
+		if err != nil {
+			return nil, err
+		}
+		return recorder.LoadCA(certPEM, keyPEM)
+	}
+	if certExists || keyExists {
+		return nil, errors.New("either both the CA certificate and key files should exist, or none of them")
+	}
+
+	ca, err := recorder.NewCA()
+	if err != nil {
+		return nil, err
+	}
+	if err = fsext.WriteFile(c.gs.FS, c.caCert, ca.CertificatePEM(), 0o644); err != nil {
+		return nil, err
+	}
+	if err = fsext.WriteFile(c.gs.FS, c.caKey, ca.KeyPEM(), 0o600); err != nil {
+		return nil, err
+	}
+	c.gs.Logger.Infof("Generated a new CA in %s, the clients have to trust it to record their HTTPS requests", c.caCert)
+	return ca, nil
+}
+
+func getCmdRecord(gs *state.GlobalState) *cobra.Command {
+	c := &recordCmd{gs: gs}
+
+	exampleText := getExampleText(gs, `
+  # Record the requests made through the proxy at localhost:8888 in script.js
+  {{.}} record
+
+  # Record the requests to example.com and its subdomains in test.js
+  {{.}} record --hosts 'example.com,*.example.com' test.js
+
+  # Record through a proxy listening on all the interfaces, with an existing CA
+  {{.}} record --listen 0.0.0.0:8888 --ca-cert ca.pem --ca-key ca-key.pem`[1:])
+
+	recordCmd := &cobra.Command{
+		Use:   "record",
+		Short: "Record the requests made through a proxy into a k6 script",
+		Long: `Record the requests made through a proxy into a k6 script.
+
+This command starts an HTTP proxy recording the requests made through it,
+until it's interrupted, and then stores a script making them in the file
+specified by the first argument, or in script.js by default.
+
+The HTTPS requests are intercepted with the certificates of a CA, which the
+client has to trust. The CA is generated on the first run, in the files of the
+--ca-cert and --ca-key flags, and reused by the following ones.
+
+The pauses between the requests are replayed with sleeps, and the values of
+the responses sent by the following requests are pointed out, as they may
+have to be extracted from the responses for the script to work.
+
+This command will not overwrite existing files.`,
+		Example: exampleText,
+		Args:    cobra.MaximumNArgs(1),
+		RunE:    c.run,
+	}
+	recordCmd.Flags().AddFlagSet(c.flagSet())
+
+	return recordCmd
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cmd/tests"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func TestRecordCmd(t *testing.T) {
+	t.Parallel()
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "hello")
+	}))
+	t.Cleanup(backend.Close)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	ts := tests.NewGlobalTestState(t)
+	ts.CmdArgs = []string{"k6", "record", "--listen", addr, "test.js"}
+	signals := make(chan chan<- os.Signal, 1)
+	ts.SignalNotify = func(c chan<- os.Signal, _ ...os.Signal) { signals <- c }
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		newRootCommand(ts.GlobalState).execute()
+	}()
+
+	// The proxy is listening once the command waits for the signals.
+	sigC := <-signals
+	proxyURL, err := url.Parse("http://" + addr)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	resp, err := client.Get(backend.URL + "/hello")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	sigC <- os.Interrupt
+	<-done
+
+	script, err := fsext.ReadFile(ts.FS, "test.js")
+	require.NoError(t, err)
+	assert.Contains(t, string(script), `res = http.get("`+backend.URL+`/hello"`)
+
+	for _, file := range []string{defaultRecordCACert, defaultRecordCAKey} {
+		exists, err := fsext.Exists(ts.FS, file)
+		require.NoError(t, err)
+		assert.True(t, exists, file)
+	}
+}
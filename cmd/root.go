@@ -61,9 +61,9 @@ func newRootCommand(gs *state.GlobalState) *rootCommand {
 	rootCmd.SetIn(gs.Stdin)
 
 	subCommands := []func(*state.GlobalState) *cobra.Command{
-		getCmdArchive, getCmdCloud, getCmdNewScript, getCmdInspect,
-		getCmdLogin, getCmdPause, getCmdResume, getCmdScale, getCmdRun,
-		getCmdStats, getCmdStatus, getCmdVersion,
+		getCmdAgent, getCmdArchive, getCmdCloud, getCmdConvert, getCmdCoordinator, getCmdNewScript,
+		getCmdInspect, getCmdLogin, getCmdPause, getCmdRecord, getCmdResume, getCmdScale, getCmdRun,
+		getCmdStats, getCmdStatus, getCmdSuite, getCmdVersion,
 	}
 
 	for _, sc := range subCommands {
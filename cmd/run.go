@@ -25,8 +25,10 @@ import (
 	"go.k6.io/k6/execution/local"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/baseline"
 	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/lib/trace"
+	"go.k6.io/k6/log"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/metrics/engine"
 	"go.k6.io/k6/output"
@@ -39,6 +41,10 @@ type cmdRun struct {
 
 	// TODO: figure out something more elegant?
 	loadConfiguredTest func(cmd *cobra.Command, args []string) (*loadedAndConfiguredTest, execution.Controller, error)
+
+	// extraOutputs are started in addition to the configured ones, e.g. the
+	// one `k6 agent` uses to send the metrics to the coordinator.
+	extraOutputs []output.Output
 }
 
 const (
@@ -125,6 +131,9 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 
 	// Write the full consolidated *and derived* options back to the Runner.
 	conf := test.derivedConfig
+	if conf.Redact.Valid {
+		log.AddRedactionHook(c.gs.Logger, conf.Redact.RedactText)
+	}
 	testRunState, err := test.buildTestRunState(conf.Options)
 	if err != nil {
 		return err
@@ -165,7 +174,9 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
+	outputs = append(outputs, c.extraOutputs...)
 	outputs = append(outputs, testRunState.GroupSummary)
+	outputs = append(outputs, execScheduler.GetState().SampleObservers())
 
 	metricsEngine, err := engine.NewMetricsEngine(testRunState.Registry, logger)
 	if err != nil {
@@ -184,30 +195,72 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		}
 		// We'll need to pipe metrics to the MetricsEngine if either the
 		// thresholds or the end-of-test summary are enabled.
+		if !testRunState.RuntimeOptions.NoSummary.Bool {
+			metricsEngine.EnableBreakdowns()
+		}
 		metricsIngester = metricsEngine.CreateIngester()
 		outputs = append(outputs, metricsIngester)
 	}
 
+	baselinePath := testRunState.RuntimeOptions.Baseline.String
+	var (
+		runBaseline        *baseline.Baseline
+		baselineTolerances baseline.Tolerances
+	)
+	if baselinePath != "" {
+		if runBaseline, baselineTolerances, err = loadBaseline(c.gs, testRunState.RuntimeOptions); err != nil {
+			return errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+		}
+	}
+
 	executionState := execScheduler.GetState()
 	if !testRunState.RuntimeOptions.NoSummary.Bool {
 		defer func() {
+			var comparisons []baseline.Comparison
+			if runBaseline != nil {
+				comparisons = runBaseline.Compare(metricsEngine.ObservedMetrics, baselineTolerances)
+			}
+
 			logger.Debug("Generating the end-of-test summary...")
-			summaryResult, hsErr := test.initRunner.HandleSummary(globalCtx, &lib.Summary{
-				Metrics:         metricsEngine.ObservedMetrics,
-				RootGroup:       testRunState.GroupSummary.Group(),
-				TestRunDuration: executionState.GetCurrentTestRunDuration(),
-				NoColor:         c.gs.Flags.NoColor,
+			summary := &lib.Summary{
+				Metrics:             metricsEngine.ObservedMetrics,
+				ScenarioMetrics:     metricsEngine.ScenarioMetrics,
+				GroupMetrics:        metricsEngine.GroupMetrics,
+				RootGroup:           testRunState.GroupSummary.Group(),
+				TestRunDuration:     executionState.GetCurrentTestRunDuration(),
+				NoColor:             c.gs.Flags.NoColor,
+				Baseline:            baselinePath,
+				BaselineComparisons: comparisons,
 				UIState: lib.UIState{
 					IsStdOutTTY: c.gs.Stdout.IsTTY,
 					IsStdErrTTY: c.gs.Stderr.IsTTY,
 				},
-			})
+				FailureReasons: executionState.GetFailureReasons(),
+			}
+			if abortErr := execution.GetCancelReasonIfTestAborted(runCtx); abortErr != nil {
+				summary.AbortReason = abortErr.Error()
+			}
+			summaryResult, hsErr := test.initRunner.HandleSummary(globalCtx, summary)
 			if hsErr == nil {
 				hsErr = handleSummaryResult(c.gs.FS, c.gs.Stdout, c.gs.Stderr, summaryResult)
 			}
 			if hsErr != nil {
 				logger.WithError(hsErr).Error("failed to handle the end-of-test summary")
 			}
+			if rErr := writeReports(c.gs, test, summary); rErr != nil {
+				logger.WithError(rErr).Error("failed to generate the end-of-test reports")
+			}
+
+			regressions := baseline.Regressions(comparisons)
+			if len(regressions) == 0 || !testRunState.RuntimeOptions.BaselineFail.Bool {
+				return
+			}
+			bErr := errext.WithExitCodeIfNone(getBaselineError(regressions), exitcodes.BaselineRegression)
+			if err == nil {
+				err = bErr
+			} else {
+				logger.WithError(bErr).Debug("Metrics regressed, but test already exited with another error")
+			}
 		}()
 	}
 
@@ -224,6 +277,8 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		// TODO: attach run status and exit code?
 		runAbort(err)
 	})
+	outputManager.SetCardinalityLimiter(newCardinalityLimiter(test.derivedConfig.Options))
+	outputManager.SetRedaction(test.derivedConfig.Redact)
 	samples := make(chan metrics.SampleContainer, test.derivedConfig.MetricSamplesBufferSize.Int64)
 	waitOutputsFlushed, stopOutputs, err := outputManager.Start(samples)
 	if err != nil {
@@ -424,6 +479,14 @@ func (c *cmdRun) run(cmd *cobra.Command, args []string) (err error) {
 		return err
 	}
 
+	if reasons := executionState.GetFailureReasons(); len(reasons) > 0 {
+		return errext.WithAbortReasonIfNone(
+			errext.WithExitCodeIfNone(
+				fmt.Errorf("the script marked the test run as failed: %s", strings.Join(reasons, ", ")),
+				exitcodes.ScriptMarkedFailed,
+			), errext.FailedByScript)
+	}
+
 	// Warn if no iterations could be completed.
 	if executionState.GetFullIterationCount() == 0 {
 		logger.Warn("No script iterations fully finished, consider making the test duration longer")
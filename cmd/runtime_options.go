@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -10,6 +11,8 @@ import (
 
 	"go.k6.io/k6/cmd/state"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/capture"
+	testreport "go.k6.io/k6/lib/report"
 )
 
 // TODO: move this whole file out of the cmd package? maybe when fixing
@@ -37,8 +40,27 @@ experimental_enhanced: esbuild-based transpiling for TypeScript and ES6+ support
 		"",
 		"output the end-of-test summary report to JSON file",
 	)
+	flags.String("baseline", "",
+		"compare the end-of-test summary with a baseline one, exported with --summary-export by a previous run")
+	flags.String("baseline-tolerance", "",
+		"comma-separated tolerances of the baseline comparison, e.g. '5%,http_req_duration:p(95)=20%' (default 10%)")
+	flags.Bool("baseline-fail", false, "fail the test run if a metric regressed beyond its baseline tolerance")
+	flags.StringArray("report", nil,
+		"generate an end-of-test report with `format=json|junit|html[,path=file]`, can be used multiple times")
 	flags.String("traces-output", "none",
 		"set the output for k6 traces, possible values are none,otel[=host:port]")
+	flags.Bool("allow-file-writes", false, "allow the experimental fs module to create and write files")
+	flags.Bool("typecheck", false, "type-check the script with the TypeScript compiler (tsc, or the K6_TSC env var) "+
+		"before running it, requires the experimental_enhanced compatibility mode")
+	flags.String("modules-lock", "",
+		"pin the integrity hashes of the remote modules in the given lock file, and fail if they change")
+	flags.String("modules-cache", "", "cache the remote modules in the given directory, to load them offline")
+	flags.StringArray("secret-source", nil,
+		"add a source of the secrets of k6/secrets with `type=param[,key=value]`, the type being "+
+			"env, file, vault, or aws, e.g. file=secrets.txt,name=db, can be used multiple times")
+	flags.String("capture-failures", "",
+		"capture the requests with unexpected statuses or failed checks, and their responses, "+
+			"with `path=dir[,max=n][,body=bytes]`, e.g. path=failures,max=100,body=10240")
 	return flags
 }
 
@@ -68,7 +90,15 @@ func getRuntimeOptions(flags *pflag.FlagSet, environment map[string]string) (lib
 		NoThresholds:         getNullBool(flags, "no-thresholds"),
 		NoSummary:            getNullBool(flags, "no-summary"),
 		SummaryExport:        getNullString(flags, "summary-export"),
+		Baseline:             getNullString(flags, "baseline"),
+		BaselineTolerance:    getNullString(flags, "baseline-tolerance"),
+		BaselineFail:         getNullBool(flags, "baseline-fail"),
 		TracesOutput:         getNullString(flags, "traces-output"),
+		AllowFileWrites:      getNullBool(flags, "allow-file-writes"),
+		TypeCheck:            getNullBool(flags, "typecheck"),
+		ModulesLock:          getNullString(flags, "modules-lock"),
+		ModulesCache:         getNullString(flags, "modules-cache"),
+		CaptureFailures:      getNullString(flags, "capture-failures"),
 		Env:                  make(map[string]string),
 	}
 
@@ -94,6 +124,19 @@ func getRuntimeOptions(flags *pflag.FlagSet, environment map[string]string) (lib
 	if err := saveBoolFromEnv(environment, "K6_NO_SUMMARY", &opts.NoSummary); err != nil {
 		return opts, err
 	}
+	if err := saveBoolFromEnv(environment, "K6_ALLOW_FILE_WRITES", &opts.AllowFileWrites); err != nil {
+		return opts, err
+	}
+	if err := saveBoolFromEnv(environment, "K6_BASELINE_FAIL", &opts.BaselineFail); err != nil {
+		return opts, err
+	}
+	if err := saveBoolFromEnv(environment, "K6_TYPECHECK", &opts.TypeCheck); err != nil {
+		return opts, err
+	}
+	if opts.TypeCheck.Bool && opts.CompatibilityMode.String != lib.CompatibilityModeExperimentalEnhanced.String() {
+		return opts, errors.New("type-checking the script requires the experimental_enhanced compatibility mode, " +
+			"use --compatibility-mode=experimental_enhanced")
+	}
 
 	if envVar, ok := environment["K6_SUMMARY_EXPORT"]; ok {
 		if !opts.SummaryExport.Valid {
@@ -101,6 +144,59 @@ func getRuntimeOptions(flags *pflag.FlagSet, environment map[string]string) (lib
 		}
 	}
 
+	if envVar, ok := environment["K6_BASELINE"]; ok && !opts.Baseline.Valid {
+		opts.Baseline = null.StringFrom(envVar)
+	}
+	if envVar, ok := environment["K6_BASELINE_TOLERANCE"]; ok && !opts.BaselineTolerance.Valid {
+		opts.BaselineTolerance = null.StringFrom(envVar)
+	}
+	if envVar, ok := environment["K6_MODULES_LOCK"]; ok && !opts.ModulesLock.Valid {
+		opts.ModulesLock = null.StringFrom(envVar)
+	}
+	if envVar, ok := environment["K6_MODULES_CACHE"]; ok && !opts.ModulesCache.Valid {
+		opts.ModulesCache = null.StringFrom(envVar)
+	}
+	if envVar, ok := environment["K6_CAPTURE_FAILURES"]; ok && !opts.CaptureFailures.Valid {
+		opts.CaptureFailures = null.StringFrom(envVar)
+	}
+	if opts.CaptureFailures.Valid {
+		if _, err := capture.ParseConfig(opts.CaptureFailures.String); err != nil {
+			return opts, err
+		}
+	}
+	secretSources, err := flags.GetStringArray("secret-source")
+	if err != nil {
+		return opts, err
+	}
+	if len(secretSources) > 0 {
+		opts.SecretSources = secretSources
+	} else if envVar, ok := environment["K6_SECRET_SOURCE"]; ok {
+		opts.SecretSources = []string{envVar}
+	}
+	reports, err := flags.GetStringArray("report")
+	if err != nil {
+		return opts, err
+	}
+	if len(reports) > 0 {
+		opts.Reports = reports
+	} else if envVar, ok := environment["K6_REPORT"]; ok {
+		opts.Reports = []string{envVar}
+	}
+	for _, r := range opts.Reports {
+		if _, err = testreport.ParseConfig(r); err != nil {
+			return opts, err
+		}
+	}
+	if len(opts.Reports) > 0 && opts.NoSummary.Bool {
+		return opts, errors.New("the end-of-test reports are a part of the end-of-test summary, " +
+			"which can't be disabled with --no-summary")
+	}
+
+	if opts.Baseline.String != "" && opts.NoSummary.Bool {
+		return opts, errors.New("the baseline comparison is a part of the end-of-test summary, " +
+			"which can't be disabled with --no-summary")
+	}
+
 	if envVar, ok := environment["SSLKEYLOGFILE"]; ok {
 		if !opts.KeyWriter.Valid {
 			opts.KeyWriter = null.StringFrom(envVar)
@@ -364,6 +364,60 @@ func TestRuntimeOptions(t *testing.T) {
 				TracesOutput:         defaultTracesOutput,
 			},
 		},
+		"baseline from env overwritten by CLI": {
+			useSysEnv: false,
+			systemEnv: map[string]string{
+				"K6_BASELINE": "foo.json", "K6_BASELINE_TOLERANCE": "5%", "K6_BASELINE_FAIL": "true",
+			},
+			cliFlags: []string{"--baseline", "bar.json", "--baseline-tolerance", "checks=1%"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				Baseline:             null.NewString("bar.json", true),
+				BaselineTolerance:    null.NewString("checks=1%", true),
+				BaselineFail:         null.NewBool(true, true),
+				TracesOutput:         defaultTracesOutput,
+			},
+		},
+		"baseline without summary": {
+			useSysEnv: false,
+			cliFlags:  []string{"--baseline", "bar.json", "--no-summary"},
+			expErr:    true,
+		},
+		"reports from env": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_REPORT": "format=junit"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				Reports:              []string{"format=junit"},
+				TracesOutput:         defaultTracesOutput,
+			},
+		},
+		"reports from env overwritten by CLI": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_REPORT": "format=junit"},
+			cliFlags:  []string{"--report", "format=json", "--report", "format=html,path=out.html"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				Reports:              []string{"format=json", "format=html,path=out.html"},
+				TracesOutput:         defaultTracesOutput,
+			},
+		},
+		"report with invalid format": {
+			useSysEnv: false,
+			cliFlags:  []string{"--report", "format=pdf"},
+			expErr:    true,
+		},
+		"report without summary": {
+			useSysEnv: false,
+			cliFlags:  []string{"--report", "format=json", "--no-summary"},
+			expErr:    true,
+		},
 		"env var error detected even when CLI flags overwrite 1": {
 			useSysEnv: false,
 			systemEnv: map[string]string{"K6_NO_THRESHOLDS": "boo"},
@@ -395,6 +449,129 @@ func TestRuntimeOptions(t *testing.T) {
 				TracesOutput:         null.NewString("foo", true),
 			},
 		},
+		"allow file writes from env": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_ALLOW_FILE_WRITES": "true"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				TracesOutput:         defaultTracesOutput,
+				AllowFileWrites:      null.NewBool(true, true),
+			},
+		},
+		"allow file writes from CLI": {
+			useSysEnv: false,
+			cliFlags:  []string{"--allow-file-writes"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				TracesOutput:         defaultTracesOutput,
+				AllowFileWrites:      null.NewBool(true, true),
+			},
+		},
+		"typecheck from CLI": {
+			useSysEnv: false,
+			cliFlags:  []string{"--typecheck", "--compatibility-mode", "experimental_enhanced"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    null.NewString("experimental_enhanced", true),
+				Env:                  map[string]string{},
+				TracesOutput:         defaultTracesOutput,
+				TypeCheck:            null.NewBool(true, true),
+			},
+		},
+		"typecheck from env": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_TYPECHECK": "true", "K6_COMPATIBILITY_MODE": "experimental_enhanced"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    null.NewString("experimental_enhanced", true),
+				Env:                  map[string]string{},
+				TracesOutput:         defaultTracesOutput,
+				TypeCheck:            null.NewBool(true, true),
+			},
+		},
+		"error typecheck without the experimental_enhanced compat mode": {
+			cliFlags: []string{"--typecheck"},
+			expErr:   true,
+		},
+		"modules lock and cache from CLI": {
+			useSysEnv: false,
+			cliFlags:  []string{"--modules-lock", "k6.lock", "--modules-cache", ".k6cache"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				TracesOutput:         defaultTracesOutput,
+				ModulesLock:          null.NewString("k6.lock", true),
+				ModulesCache:         null.NewString(".k6cache", true),
+			},
+		},
+		"modules lock and cache from env": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_MODULES_LOCK": "k6.lock", "K6_MODULES_CACHE": ".k6cache"},
+			cliFlags:  []string{"--modules-cache", "cache"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				TracesOutput:         defaultTracesOutput,
+				ModulesLock:          null.NewString("k6.lock", true),
+				ModulesCache:         null.NewString("cache", true),
+			},
+		},
+		"secret source from env": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_SECRET_SOURCE": "file=secrets.txt"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				TracesOutput:         defaultTracesOutput,
+				SecretSources:        []string{"file=secrets.txt"},
+			},
+		},
+		"secret sources from env overwritten by CLI": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_SECRET_SOURCE": "file=secrets.txt"},
+			cliFlags:  []string{"--secret-source", "env=K6_SECRET_", "--secret-source", "vault=k6/db,name=db"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				TracesOutput:         defaultTracesOutput,
+				SecretSources:        []string{"env=K6_SECRET_", "vault=k6/db,name=db"},
+			},
+		},
+		"capture failures from env overwritten by CLI": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_CAPTURE_FAILURES": "path=failures"},
+			cliFlags:  []string{"--capture-failures", "path=captures,max=10"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				TracesOutput:         defaultTracesOutput,
+				CaptureFailures:      null.NewString("path=captures,max=10", true),
+			},
+		},
+		"capture failures from env": {
+			useSysEnv: false,
+			systemEnv: map[string]string{"K6_CAPTURE_FAILURES": "path=failures"},
+			expRTOpts: lib.RuntimeOptions{
+				IncludeSystemEnvVars: null.NewBool(false, false),
+				CompatibilityMode:    defaultCompatMode,
+				Env:                  map[string]string{},
+				TracesOutput:         defaultTracesOutput,
+				CaptureFailures:      null.StringFrom("path=failures"),
+			},
+		},
+		"error invalid capture failures": {
+			cliFlags: []string{"--capture-failures", "max=0"},
+			expErr:   true,
+		},
 		"traces output from env overwritten by CLI": {
 			useSysEnv: false,
 			systemEnv: map[string]string{"K6_TRACES_OUTPUT": "foo"},
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/lib/strvals"
+	"go.k6.io/k6/secretsource"
+	"go.k6.io/k6/secretsource/aws"
+)
+
+// newSecretsManager returns the manager of the secret sources configured with
+// the --secret-source options, e.g. file=secrets.txt,name=db, whose first
+// token is the type of the source and its main parameter. The first source is
+// the default one, unless another one is marked with default=true.
+func newSecretsManager(gs *state.GlobalState, pwd string, lines []string) (*secretsource.Manager, error) {
+	sources := make(map[string]secretsource.Source, len(lines))
+	defaultSource, explicitDefault := "", false
+	for _, line := range lines {
+		name, isDefault, source, err := parseSecretSource(gs, pwd, line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret source %q: %w", line, err)
+		}
+		if _, ok := sources[name]; ok {
+			return nil, fmt.Errorf("the secret source %q is defined more than once, name them with name=", name)
+		}
+		sources[name] = source
+
+		switch {
+		case isDefault && explicitDefault:
+			return nil, fmt.Errorf("both the secret sources %q and %q are marked as the default one", defaultSource, name)
+		case isDefault:
+			defaultSource, explicitDefault = name, true
+		case defaultSource == "":
+			defaultSource = name
+		}
+	}
+	return secretsource.NewManager(sources, defaultSource)
+}
+
+// secretSourceParams are the parameters of the types of the secret sources,
+// besides their main ones, and their name and default parameters.
+//
+//nolint:gochecknoglobals
+var secretSourceParams = map[string][]string{
+	"vault": {"address", "namespace", "mount"},
+	"aws":   {"region", "endpoint"},
+}
+
+// parseSecretSource returns the source of the given configuration line, with
+// its name, which is its type by default, and whether it's the default one.
+//
+// The credentials of the secrets managers are taken from the environment, as
+// they are by their own tools, rather than from the options, so they don't
+// leak e.g. to the list of the processes.
+func parseSecretSource(
+	gs *state.GlobalState, pwd, line string,
+) (name string, isDefault bool, source secretsource.Source, err error) {
+	if !strings.Contains(line, "=") {
+		line += "=" // e.g. env, which has no main parameter
+	}
+	tokens, err := strvals.Parse(line)
+	if err != nil {
+		return "", false, nil, err
+	}
+
+	sourceType, mainParam := tokens[0].Key, tokens[0].Value
+	name = sourceType
+	params := make(map[string]string)
+	for _, token := range tokens[1:] {
+		switch token.Key {
+		case "name":
+			name = token.Value
+		case "default":
+			if isDefault, err = strconv.ParseBool(token.Value); err != nil {
+				return "", false, nil, fmt.Errorf("default needs to be a boolean: %w", err)
+			}
+		default:
+			params[token.Key] = token.Value
+		}
+	}
+
+	for key := range params {
+		if !contains(secretSourceParams[sourceType], key) {
+			return "", false, nil, fmt.Errorf("unknown parameter %q of the %s secret sources", key, sourceType)
+		}
+	}
+
+	switch sourceType {
+	case "env":
+		source = secretsource.NewEnvSource(gs.Env, mainParam)
+	case "file":
+		if mainParam == "" {
+			return "", false, nil, errors.New("the path of the secrets file is required, e.g. file=secrets.txt")
+		}
+		source, err = secretsource.NewFileSource(gs.FS, absolutePath(pwd, mainParam))
+	case "vault":
+		source, err = secretsource.NewVaultSource(secretsource.VaultConfig{
+			Address:   valueOrEnv(params["address"], gs.Env["VAULT_ADDR"]),
+			Token:     gs.Env["VAULT_TOKEN"],
+			Namespace: valueOrEnv(params["namespace"], gs.Env["VAULT_NAMESPACE"]),
+			Mount:     params["mount"],
+			Path:      mainParam,
+		}, nil)
+	case "aws":
+		source, err = aws.NewSource(aws.Config{
+			Region:          valueOrEnv(params["region"], valueOrEnv(gs.Env["AWS_REGION"], gs.Env["AWS_DEFAULT_REGION"])),
+			AccessKeyID:     gs.Env["AWS_ACCESS_KEY_ID"],
+			SecretAccessKey: gs.Env["AWS_SECRET_ACCESS_KEY"],
+			SessionToken:    gs.Env["AWS_SESSION_TOKEN"],
+			Endpoint:        params["endpoint"],
+			SecretID:        mainParam,
+		}, nil)
+	default:
+		return "", false, nil, fmt.Errorf("unknown type %q, it needs to be env, file, vault, or aws", sourceType)
+	}
+	if err != nil {
+		return "", false, nil, err
+	}
+	return name, isDefault, source, nil
+}
+
+func valueOrEnv(value, envValue string) string {
+	if value != "" {
+		return value
+	}
+	return envValue
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/execution/local"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+)
+
+// suiteConfig is the format of the test suite files.
+type suiteConfig struct {
+	// Parallel runs all of the tests at the same time, instead of one after
+	// the other.
+	Parallel bool `json:"parallel"`
+	// Options are shared by all tests, they override the script options.
+	Options lib.Options `json:"options"`
+	Tests   []suiteTest `json:"tests"`
+}
+
+// suiteTest is a single test in a test suite.
+type suiteTest struct {
+	Name string `json:"name"`
+	// Script is the path to the test script or archive, relative to the
+	// directory of the suite file.
+	Script string `json:"script"`
+	// Options override both the script options and the shared suite options.
+	Options lib.Options `json:"options"`
+	// Env contains environment variables that are set only for this test.
+	Env map[string]string `json:"env"`
+}
+
+type suiteTestResult struct {
+	name     string
+	duration time.Duration
+	skipped  bool
+	err      error
+}
+
+// cmdSuite handles the `k6 suite` sub-command
+type cmdSuite struct {
+	gs *state.GlobalState
+}
+
+func (c *cmdSuite) readSuite(suitePath string) (*suiteConfig, error) {
+	data, err := fsext.ReadFile(c.gs.FS, suitePath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the test suite '%s': %w", suitePath, err)
+	}
+
+	suite := &suiteConfig{}
+	if err = json.Unmarshal(data, suite); err != nil {
+		return nil, errext.WithExitCodeIfNone(
+			fmt.Errorf("couldn't parse the test suite '%s': %w", suitePath, err), exitcodes.InvalidConfig,
+		)
+	}
+	if len(suite.Tests) == 0 {
+		return nil, errext.WithExitCodeIfNone(
+			fmt.Errorf("the test suite '%s' doesn't have any tests", suitePath), exitcodes.InvalidConfig,
+		)
+	}
+
+	names := make(map[string]struct{}, len(suite.Tests))
+	for i, test := range suite.Tests {
+		if test.Script == "" {
+			return nil, errext.WithExitCodeIfNone(
+				fmt.Errorf("test #%d in the test suite doesn't have a script", i+1), exitcodes.InvalidConfig,
+			)
+		}
+		if test.Name == "" {
+			suite.Tests[i].Name = test.Script
+		}
+		if _, ok := names[suite.Tests[i].Name]; ok {
+			return nil, errext.WithExitCodeIfNone(
+				fmt.Errorf("the test suite has multiple tests named '%s'", suite.Tests[i].Name), exitcodes.InvalidConfig,
+			)
+		}
+		names[suite.Tests[i].Name] = struct{}{}
+	}
+	return suite, nil
+}
+
+func (c *cmdSuite) run(cmd *cobra.Command, args []string) error {
+	pwd, err := c.gs.Getwd()
+	if err != nil {
+		return err
+	}
+	suitePath := args[0]
+	if !filepath.IsAbs(suitePath) {
+		suitePath = filepath.Join(pwd, suitePath)
+	}
+	suite, err := c.readSuite(suitePath)
+	if err != nil {
+		return err
+	}
+
+	results := make([]suiteTestResult, len(suite.Tests))
+	if suite.Parallel {
+		wg := &sync.WaitGroup{}
+		for i, test := range suite.Tests {
+			i, test := i, test
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = c.runTest(cmd, filepath.Dir(suitePath), suite, test)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i, test := range suite.Tests {
+			// Don't run the rest of the tests if the suite was stopped by Ctrl+C.
+			if i > 0 && hasExitCode(results[i-1].err, exitcodes.ExternalAbort) {
+				for j := i; j < len(suite.Tests); j++ {
+					results[j] = suiteTestResult{name: suite.Tests[j].Name, skipped: true}
+				}
+				break
+			}
+			results[i] = c.runTest(cmd, filepath.Dir(suitePath), suite, test)
+		}
+	}
+
+	printToStdout(c.gs, getSuiteSummary(c.gs, args[0], results))
+
+	return getSuiteError(results)
+}
+
+// runTest runs a single test of the suite with an almost unchanged `k6 run`.
+// Every test gets its own copy of the global state, so that the environment
+// variables and the events don't leak between them.
+func (c *cmdSuite) runTest(cmd *cobra.Command, suiteDir string, suite *suiteConfig, test suiteTest) suiteTestResult {
+	gs := *c.gs
+	gs.Env = make(map[string]string, len(c.gs.Env)+len(test.Env))
+	for k, v := range c.gs.Env {
+		gs.Env[k] = v
+	}
+	for k, v := range test.Env {
+		gs.Env[k] = v
+	}
+	gs.Events = event.NewEventSystem(100, c.gs.Logger)
+	if suite.Parallel {
+		// Multiple REST API servers can't listen on the same address and the
+		// progress bars of concurrent tests would be garbled.
+		gs.Flags.Address = ""
+		gs.Flags.Quiet = true
+	}
+
+	script := test.Script
+	if !filepath.IsAbs(script) {
+		script = filepath.Join(suiteDir, script)
+	}
+
+	runCmd := &cmdRun{
+		gs: &gs,
+		loadConfiguredTest: func(cmd *cobra.Command, args []string) (*loadedAndConfiguredTest, execution.Controller, error) {
+			testConfigGetter := func(flags *pflag.FlagSet) (Config, error) {
+				cliConf, err := getConfig(flags)
+				if err != nil {
+					return cliConf, err
+				}
+				// The CLI config also contains the defaults, so it has to stay
+				// the base and it's applied again to keep its precedence.
+				suiteConf := Config{Options: suite.Options.Apply(test.Options)}
+				return cliConf.Apply(suiteConf).Apply(cliConf), nil
+			}
+			loadedTest, err := loadAndConfigureLocalTest(&gs, cmd, args, testConfigGetter)
+			return loadedTest, local.NewController(), err
+		},
+	}
+
+	gs.Logger.Debugf("Running test '%s' of the suite...", test.Name)
+	start := time.Now()
+	err := runCmd.run(cmd, []string{script})
+	if err != nil {
+		errText, fields := errext.Format(err)
+		gs.Logger.WithFields(fields).Errorf("Test '%s' failed: %s", test.Name, errText)
+	}
+	return suiteTestResult{name: test.Name, duration: time.Since(start), err: err}
+}
+
+func hasExitCode(err error, exitCode exitcodes.ExitCode) bool {
+	var ecerr errext.HasExitCode
+	return errors.As(err, &ecerr) && ecerr.ExitCode() == exitCode
+}
+
+func getSuiteSummary(gs *state.GlobalState, suitePath string, results []suiteTestResult) string {
+	noColor := gs.Flags.NoColor || !gs.Stdout.IsTTY
+	valueColor := getColor(noColor, color.FgCyan)
+	successColor := getColor(noColor, color.FgGreen)
+	failColor := getColor(noColor, color.FgRed)
+
+	var passed, failed, skipped int
+	for _, res := range results {
+		switch {
+		case res.skipped:
+			skipped++
+		case res.err != nil:
+			failed++
+		default:
+			passed++
+		}
+	}
+
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "\n         suite: %s\n", valueColor.Sprint(suitePath))
+	fmt.Fprintf(buf, "         tests: %s\n\n", valueColor.Sprintf(
+		"%d passed, %d failed, %d skipped", passed, failed, skipped))
+
+	for _, res := range results {
+		switch {
+		case res.skipped:
+			fmt.Fprintf(buf, "     - %s: skipped\n", res.name)
+		case res.err != nil:
+			errText, _ := errext.Format(res.err)
+			fmt.Fprintf(buf, "     %s %s: %s (%s)\n",
+				failColor.Sprint("✗"), res.name, failColor.Sprint(errText), res.duration.Round(time.Millisecond))
+		default:
+			fmt.Fprintf(buf, "     %s %s (%s)\n",
+				successColor.Sprint("✓"), res.name, res.duration.Round(time.Millisecond))
+		}
+	}
+	return buf.String()
+}
+
+// getSuiteError returns an error if any of the tests has failed, with the exit
+// code of the first failed test.
+func getSuiteError(results []suiteTestResult) error {
+	var (
+		failed   []string
+		firstErr error
+	)
+	for _, res := range results {
+		if res.err == nil {
+			continue
+		}
+		failed = append(failed, res.name)
+		if firstErr == nil {
+			firstErr = res.err
+		}
+	}
+	if firstErr == nil {
+		return nil
+	}
+
+	err := fmt.Errorf("%d of %d tests in the suite have failed: %s",
+		len(failed), len(results), strings.Join(failed, ", "))
+	var ecerr errext.HasExitCode
+	if errors.As(firstErr, &ecerr) {
+		return errext.WithExitCodeIfNone(err, ecerr.ExitCode())
+	}
+	return err
+}
+
+func (c *cmdSuite) flagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.AddFlagSet(optionFlagSet())
+	flags.AddFlagSet(runtimeOptionFlagSet(true))
+	flags.AddFlagSet(configFlagSet())
+	return flags
+}
+
+func getCmdSuite(gs *state.GlobalState) *cobra.Command {
+	c := &cmdSuite{gs: gs}
+
+	exampleText := getExampleText(gs, `
+  # Run all tests from the suite file.
+  {{.}} suite suite.json
+
+  # Run all tests from the suite file, sending their metrics to an influxdb server.
+  {{.}} suite -o influxdb=http://1.2.3.4:8086/k6 suite.json`[1:])
+
+	suiteCmd := &cobra.Command{
+		Use:   "suite",
+		Short: "Run a test suite",
+		Long: `Run a test suite.
+
+A test suite is a JSON file with multiple tests that are executed either one after
+the other or in parallel:
+
+  {
+    "parallel": false,
+    "options": { "thresholds": { "checks": ["rate==1"] } },
+    "tests": [
+      { "name": "login", "script": "login.js", "env": { "USER": "admin" } },
+      { "name": "checkout", "script": "checkout.js", "options": { "vus": 10, "duration": "1m" } }
+    ]
+  }
+
+The shared options override the options of all scripts, and the options of each
+test override the shared ones. Command-line flags override both of them.
+
+Every test prints its own end-of-test summary, followed by a combined summary of
+the suite. If any of the tests fails, k6 exits with the exit code of the first
+failed test.`,
+		Example: exampleText,
+		Args:    exactArgsWithMsg(1, "arg should be a path to a test suite file"),
+		RunE:    c.run,
+	}
+
+	suiteCmd.Flags().SortFlags = false
+	suiteCmd.Flags().AddFlagSet(c.flagSet())
+
+	return suiteCmd
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/consts"
+	"go.k6.io/k6/lib/fsext"
+	testreport "go.k6.io/k6/lib/report"
+)
+
+// gitCommitEnvVars are the environment variables the CI systems store the SHA
+// of the git commit being tested in.
+//
+//nolint:gochecknoglobals
+var gitCommitEnvVars = []string{"GITHUB_SHA", "CI_COMMIT_SHA", "GIT_COMMIT", "BUILD_SOURCEVERSION"}
+
+// writeReports generates the end-of-test reports of the summary, if any were
+// requested, and writes them to their files.
+func writeReports(gs *state.GlobalState, test *loadedAndConfiguredTest, summary *lib.Summary) error {
+	reports := test.preInitState.RuntimeOptions.Reports
+	if len(reports) == 0 {
+		return nil
+	}
+
+	r, err := testreport.New(summary, testreport.Metadata{
+		K6Version:   consts.Version,
+		Script:      test.sourceRootPath,
+		GitCommit:   getGitCommit(gs, test.pwd),
+		GeneratedAt: time.Now().UTC(),
+		Options:     test.derivedConfig.Options,
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, spec := range reports {
+		config, err := testreport.ParseConfig(spec)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		data, err := r.Encode(config.Format)
+		if err == nil {
+			err = fsext.WriteFile(gs.FS, config.Path, data, 0o644)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("couldn't write the %s report to '%s': %w", config.Format, config.Path, err))
+			continue
+		}
+		gs.Logger.Debugf("Wrote the %s end-of-test report to '%s'", config.Format, config.Path)
+	}
+
+	return consolidateErrorMessage(errs, "Could not generate some end-of-test reports:")
+}
+
+// getGitCommit returns the SHA of the git commit the test is run from, either
+// from the environment variables of the CI systems or from the git repository
+// of the test's directory, if it's at its root, or an empty string otherwise.
+func getGitCommit(gs *state.GlobalState, pwd string) string {
+	for _, name := range gitCommitEnvVars {
+		if sha := gs.Env[name]; sha != "" {
+			return sha
+		}
+	}
+
+	gitDir := filepath.Join(pwd, ".git")
+	head, err := fsext.ReadFile(gs.FS, filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref, isRef := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: ")
+	if !isRef {
+		return ref // a detached HEAD
+	}
+
+	if sha, err := fsext.ReadFile(gs.FS, filepath.Join(gitDir, filepath.FromSlash(ref))); err == nil {
+		return strings.TrimSpace(string(sha))
+	}
+
+	// The refs may have been packed, with lines of the form "<sha> <ref>".
+	packed, err := fsext.ReadFile(gs.FS, filepath.Join(gitDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(packed))
+	for scanner.Scan() {
+		if sha, packedRef, ok := strings.Cut(scanner.Text(), " "); ok && packedRef == ref {
+			return sha
+		}
+	}
+	return ""
+}
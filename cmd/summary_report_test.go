@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cmd/tests"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func TestGetGitCommit(t *testing.T) {
+	t.Parallel()
+
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+
+	testCases := map[string]struct {
+		env   map[string]string
+		files map[string]string
+		exp   string
+	}{
+		"none": {},
+		"env": {
+			env:   map[string]string{"CI_COMMIT_SHA": "fedcba"},
+			files: map[string]string{"HEAD": sha},
+			exp:   "fedcba",
+		},
+		"detached": {files: map[string]string{"HEAD": sha + "\n"}, exp: sha},
+		"ref": {
+			files: map[string]string{"HEAD": "ref: refs/heads/main\n", "refs/heads/main": sha + "\n"},
+			exp:   sha,
+		},
+		"packed ref": {
+			files: map[string]string{
+				"HEAD":        "ref: refs/heads/main\n",
+				"packed-refs": "# pack-refs with: peeled fully-peeled sorted\n" + sha + " refs/heads/main\n",
+			},
+			exp: sha,
+		},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := tests.NewGlobalTestState(t)
+			for k, v := range tc.env {
+				ts.Env[k] = v
+			}
+			for path, content := range tc.files {
+				path = filepath.Join(ts.Cwd, ".git", filepath.FromSlash(path))
+				require.NoError(t, fsext.WriteFile(ts.FS, path, []byte(content), 0o644))
+			}
+
+			assert.Equal(t, tc.exp, getGitCommit(ts.GlobalState, ts.Cwd))
+		})
+	}
+}
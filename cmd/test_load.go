@@ -19,6 +19,7 @@ import (
 	"go.k6.io/k6/js"
 	"go.k6.io/k6/js/modules"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/capture"
 	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/loader"
 	"go.k6.io/k6/metrics"
@@ -60,6 +61,14 @@ func loadLocalTest(gs *state.GlobalState, cmd *cobra.Command, args []string) (*l
 		sourceRootPath, resolvedPath, len(src.Data),
 	)
 
+	return loadTest(gs, cmd, sourceRootPath, src, fileSystems, pwd)
+}
+
+// loadTest initializes the first runner for the already read test source.
+func loadTest(
+	gs *state.GlobalState, cmd *cobra.Command, sourceRootPath string,
+	src *loader.SourceData, fileSystems map[string]fsext.Fs, pwd string,
+) (*loadedTest, error) {
 	gs.Logger.Debugf("Gathering k6 runtime options...")
 	runtimeOptions, err := getRuntimeOptions(cmd.Flags(), gs.Env)
 	if err != nil {
@@ -79,6 +88,33 @@ func loadLocalTest(gs *state.GlobalState, cmd *cobra.Command, args []string) (*l
 		},
 	}
 
+	if runtimeOptions.ModulesCache.String != "" {
+		loader.CacheRemoteModules(fileSystems, gs.FS, absolutePath(pwd, runtimeOptions.ModulesCache.String))
+	}
+	if runtimeOptions.ModulesLock.String != "" {
+		state.ModulesLock, err = loader.ReadModulesLock(gs.FS, absolutePath(pwd, runtimeOptions.ModulesLock.String))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(runtimeOptions.SecretSources) > 0 {
+		state.SecretsManager, err = newSecretsManager(gs, pwd, runtimeOptions.SecretSources)
+		if err != nil {
+			return nil, err
+		}
+		state.SecretsManager.AddRedactionHook(gs.Logger)
+	}
+
+	if runtimeOptions.CaptureFailures.Valid {
+		config, err := capture.ParseConfig(runtimeOptions.CaptureFailures.String)
+		if err != nil {
+			return nil, err
+		}
+		config.Path = absolutePath(pwd, config.Path)
+		state.FailureCapture = capture.NewRecorder(gs.FS, config, gs.Logger)
+	}
+
 	test := &loadedTest{
 		pwd:            pwd,
 		sourceRootPath: sourceRootPath,
@@ -88,10 +124,21 @@ func loadLocalTest(gs *state.GlobalState, cmd *cobra.Command, args []string) (*l
 		preInitState:   state,
 	}
 
-	gs.Logger.Debugf("Initializing k6 runner for '%s' (%s)...", sourceRootPath, resolvedPath)
+	if runtimeOptions.TypeCheck.Bool {
+		if err := typeCheck(gs, src); err != nil {
+			return nil, err
+		}
+	}
+
+	gs.Logger.Debugf("Initializing k6 runner for '%s' (%s)...", sourceRootPath, src.URL)
 	if err := test.initializeFirstRunner(gs); err != nil {
 		return nil, fmt.Errorf("could not initialize '%s': %w", sourceRootPath, err)
 	}
+	if state.ModulesLock != nil {
+		if err := state.ModulesLock.Save(); err != nil {
+			return nil, err
+		}
+	}
 	gs.Logger.Debug("Runner successfully initialized!")
 	return test, nil
 }
@@ -109,13 +156,7 @@ func (lt *loadedTest) initializeFirstRunner(gs *state.GlobalState) error {
 	if lt.preInitState.RuntimeOptions.KeyWriter.Valid {
 		logger.Warnf("SSLKEYLOGFILE was specified, logging TLS connection keys to '%s'...",
 			lt.preInitState.RuntimeOptions.KeyWriter.String)
-		keylogFilename := lt.preInitState.RuntimeOptions.KeyWriter.String
-		// if path is absolute - no point doing anything
-		if !filepath.IsAbs(keylogFilename) {
-			// filepath.Abs could be used but it will get the pwd from `os` package instead of what is in lt.pwd
-			// this is against our general approach of not using `os` directly and makes testing harder
-			keylogFilename = filepath.Join(lt.pwd, keylogFilename)
-		}
+		keylogFilename := absolutePath(lt.pwd, lt.preInitState.RuntimeOptions.KeyWriter.String)
 		f, err := lt.fs.OpenFile(keylogFilename, syscall.O_WRONLY|syscall.O_CREAT|syscall.O_APPEND, 0o600)
 		if err != nil {
 			return fmt.Errorf("couldn't get absolute path for keylog file: %w", err)
@@ -163,6 +204,17 @@ func (lt *loadedTest) initializeFirstRunner(gs *state.GlobalState) error {
 	}
 }
 
+// absolutePath returns the path joined with the pwd, if it isn't absolute.
+func absolutePath(pwd, path string) string {
+	// if path is absolute - no point doing anything
+	if filepath.IsAbs(path) {
+		return path
+	}
+	// filepath.Abs could be used but it will get the pwd from `os` package instead of what is in lt.pwd
+	// this is against our general approach of not using `os` directly and makes testing harder
+	return filepath.Join(pwd, path)
+}
+
 // readSource is a small wrapper around loader.ReadSource returning
 // result of the load and filesystems map
 func readSource(gs *state.GlobalState, filename string) (*loader.SourceData, map[string]fsext.Fs, string, error) {
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cmd"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func TestDistributedRun(t *testing.T) {
+	t.Parallel()
+	script := `
+		import { Counter } from 'k6/metrics';
+
+		const iterCounter = new Counter('iter_counter');
+
+		export const options = {
+			scenarios: {
+				sc: { executor: 'shared-iterations', vus: 4, iterations: 20 },
+			},
+			thresholds: {
+				iter_counter: ['count == 20'],
+			},
+		};
+
+		export function setup() {
+			console.log('setup() called');
+			return { value: 42 };
+		}
+
+		export default function (data) {
+			if (data.value !== 42) {
+				throw new Error('wrong setup data');
+			}
+			iterCounter.add(1);
+		}
+
+		export function teardown() {
+			console.log('teardown() called');
+		}
+	`
+
+	coordinatorAddr := getFreeBindAddr(t)
+	coordinator := NewGlobalTestState(t)
+	coordinator.Env["K6_COORDINATOR_TOKEN"] = "token"
+	require.NoError(t, fsext.WriteFile(coordinator.FS, filepath.Join(coordinator.Cwd, "test.js"), []byte(script), 0o644))
+	coordinator.CmdArgs = []string{
+		"k6", "coordinator", "--log-output=stdout", "--instance-count", "2",
+		"--coordinator-address", coordinatorAddr, "test.js",
+	}
+
+	agents := []*GlobalTestState{NewGlobalTestState(t), NewGlobalTestState(t)}
+	agentsDone := make(chan struct{})
+	asyncWaitForStdoutAndRun(t, coordinator, 50, 100*time.Millisecond, "Waiting for 2 instances", func() {
+		wg := &sync.WaitGroup{}
+		for _, agent := range agents {
+			agent := agent
+			agent.Env["K6_COORDINATOR_TOKEN"] = "token"
+			agent.CmdArgs = []string{"k6", "agent", "--log-output=stdout", coordinatorAddr}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				cmd.ExecuteWithGlobalState(agent.GlobalState)
+			}()
+		}
+		wg.Wait()
+		close(agentsDone)
+	})
+
+	cmd.ExecuteWithGlobalState(coordinator.GlobalState)
+
+	stdout := coordinator.Stdout.String()
+	t.Log(stdout)
+	assert.Contains(t, stdout, "execution: distributed")
+	assert.Contains(t, stdout, "Instance 2 of 2 has registered")
+	assert.Contains(t, stdout, "✓ iter_counter")
+	assert.Regexp(t, `iterations\.+: 20`, stdout)
+
+	select {
+	case <-agentsDone:
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "the agents didn't finish")
+	}
+	var agentsStdout string
+	for _, agent := range agents {
+		agentsStdout += agent.Stdout.String()
+	}
+	assert.Equal(t, 1, strings.Count(agentsStdout, "setup() called"))
+	assert.Equal(t, 1, strings.Count(agentsStdout, "teardown() called"))
+}
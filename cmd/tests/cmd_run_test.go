@@ -28,6 +28,7 @@ import (
 	"go.k6.io/k6/errext/exitcodes"
 	"go.k6.io/k6/event"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/capture"
 	"go.k6.io/k6/lib/consts"
 	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/lib/testutils"
@@ -440,7 +441,7 @@ func TestExecutionTestOptionsDefaultValues(t *testing.T) {
 	loglines := ts.LoggerHook.Drain()
 	require.Len(t, loglines, 1)
 
-	expected := `{"paused":null,"executionSegment":null,"executionSegmentSequence":null,"noSetup":null,"setupTimeout":null,"noTeardown":null,"teardownTimeout":null,"rps":null,"dns":{"ttl":null,"select":null,"policy":null},"maxRedirects":null,"userAgent":null,"batch":null,"batchPerHost":null,"httpDebug":null,"insecureSkipTLSVerify":null,"tlsCipherSuites":null,"tlsVersion":null,"tlsAuth":null,"throw":null,"thresholds":null,"blacklistIPs":null,"blockHostnames":null,"hosts":null,"noConnectionReuse":null,"noVUConnectionReuse":null,"minIterationDuration":null,"ext":null,"summaryTrendStats":["avg", "min", "med", "max", "p(90)", "p(95)"],"summaryTimeUnit":null,"systemTags":["check","error","error_code","expected_response","group","method","name","proto","scenario","service","status","subproto","tls_version","url"],"tags":null,"metricSamplesBufferSize":null,"noCookiesReset":null,"discardResponseBodies":null,"consoleOutput":null,"scenarios":{"default":{"vus":null,"iterations":1,"executor":"shared-iterations","maxDuration":null,"startTime":null,"env":null,"tags":null,"gracefulStop":null,"exec":null}},"localIPs":null}`
+	expected := `{"paused":null,"executionSegment":null,"executionSegmentSequence":null,"noSetup":null,"setupTimeout":null,"noTeardown":null,"teardownTimeout":null,"rps":null,"dns":{"ttl":null,"select":null,"policy":null},"maxRedirects":null,"userAgent":null,"batch":null,"batchPerHost":null,"httpDebug":null,"tracePropagator":null,"traceSampling":null,"insecureSkipTLSVerify":null,"tlsCipherSuites":null,"tlsVersion":null,"tlsAuth":null,"throw":null,"thresholds":null,"blacklistIPs":null,"blockHostnames":null,"hosts":null,"urlGrouping":null,"noConnectionReuse":null,"noVUConnectionReuse":null,"maxIdleConnsPerHost":null,"maxRequestsPerConnection":null,"minIterationDuration":null,"ext":null,"summaryTrendStats":["avg", "min", "med", "max", "p(90)", "p(95)"],"summaryTimeUnit":null,"exactTrendPercentiles":null,"trendPercentilesPrecision":null,"systemTags":["check","error","error_code","expected_response","group","method","name","proto","scenario","service","status","subproto","tls_version","url"],"tags":null,"metricSamplesBufferSize":null,"metricCardinalityLimit":null,"tagCardinalityLimit":null,"loadGeneratorMetrics":null,"redact":null,"noCookiesReset":null,"discardResponseBodies":null,"consoleOutput":null,"scenarios":{"default":{"vus":null,"iterations":1,"executor":"shared-iterations","maxDuration":null,"startTime":null,"env":null,"tags":null,"gracefulStop":null,"exec":null}},"localIPs":null}`
 	assert.JSONEq(t, expected, loglines[0].Message)
 }
 
@@ -1178,6 +1179,68 @@ func TestAbortedByScriptAbortInTeardown(t *testing.T) {
 	})
 }
 
+func TestAbortedByScriptAbortWithOptions(t *testing.T) {
+	t.Parallel()
+	script := `
+		import exec from 'k6/execution';
+		import { sleep } from 'k6';
+
+		export const options = { vus: 2, duration: '10s' };
+
+		export default function () {
+			if (exec.vu.idInTest === 1) {
+				exec.test.abort('foo', { exitCode: 42, gracefulStop: '5s' });
+				sleep(0.5);
+				console.log('the aborting iteration is done');
+			}
+			sleep(0.5);
+		};
+
+		export function handleSummary(data) {
+			return { stdout: JSON.stringify(data.state) };
+		}
+	`
+
+	ts := getSingleFileTestState(t, script, nil, 42)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	assert.Contains(t, ts.Stdout.String(), `"abortReason":"test aborted: foo"`)
+	assert.True(t, testutils.LogContains(ts.LoggerHook.Drain(), logrus.InfoLevel, "the aborting iteration is done"))
+}
+
+func TestScriptMarkedFailed(t *testing.T) {
+	t.Parallel()
+	script := `
+		import exec from 'k6/execution';
+		import { Counter } from 'k6/metrics';
+
+		const done = new Counter('done');
+
+		export const options = {
+			iterations: 3,
+			thresholds: { done: ['count==3'] },
+		};
+
+		export default function () {
+			if (exec.scenario.iterationInTest === 0) {
+				exec.test.fail('bad data');
+			}
+			done.add(1);
+		};
+
+		export function handleSummary(data) {
+			return { stdout: JSON.stringify(data.state) };
+		}
+	`
+
+	ts := getSingleFileTestState(t, script, nil, exitcodes.ScriptMarkedFailed)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	assert.Contains(t, stdout, `"failureReasons":["test failed: bad data"]`)
+	assert.NotContains(t, stdout, "abortReason")
+}
+
 func testAbortedByScriptTestAbort(t *testing.T, script string, runTest func(*testing.T, *GlobalTestState)) {
 	ts := getSimpleCloudOutputTestState(
 		t, script, nil, cloudapi.RunStatusAbortedUser, cloudapi.ResultStatusPassed, exitcodes.ScriptAborted,
@@ -2412,3 +2475,538 @@ func TestTypeScriptSupport(t *testing.T) {
 	t.Log(stderr)
 	assert.Contains(t, stderr, `something 42`)
 }
+
+func TestBaselineComparison(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		import { Trend } from 'k6/metrics';
+
+		const wait = new Trend('wait', true);
+
+		export const options = { iterations: 5 };
+
+		export default function() { wait.add(150); };
+	`
+	baselineSummary := `{"metrics": {"wait": {"avg": 100, "med": 100, "p(95)": 140}}}`
+
+	testCases := []struct {
+		name        string
+		flags       []string
+		expExitCode exitcodes.ExitCode
+	}{
+		{name: "Report", flags: []string{"--baseline", "baseline.json"}},
+		{name: "Fail", flags: []string{"--baseline", "baseline.json", "--baseline-fail"}, expExitCode: exitcodes.BaselineRegression},
+		{
+			name:  "Tolerated",
+			flags: []string{"--baseline", "baseline.json", "--baseline-fail", "--baseline-tolerance", "60%"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := getSingleFileTestState(t, script, tc.flags, tc.expExitCode)
+			require.NoError(t, fsext.WriteFile(ts.FS, "baseline.json", []byte(baselineSummary), 0o644))
+			cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+			stdout := ts.Stdout.String()
+			t.Log(stdout)
+			assert.Contains(t, stdout, "█ baseline baseline.json")
+			assert.Contains(t, stdout, "wait p(95)...: 140ms → 150ms +7.1%")
+			if tc.expExitCode != 0 {
+				assert.True(t, testutils.LogContains(ts.LoggerHook.Drain(), logrus.ErrorLevel,
+					"metrics 'wait avg, wait med' regressed beyond their baseline tolerances"))
+			}
+		})
+	}
+}
+
+func TestEndOfTestReports(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		import { check } from 'k6';
+
+		export const options = {
+			iterations: 2,
+			thresholds: { checks: ['rate==1'] },
+		};
+
+		export default function() { check(__ITER, { 'is first': (i) => i === 0 }); };
+	`
+
+	ts := getSingleFileTestState(t, script, []string{
+		"--report", "format=json", "--report", "format=junit,path=junit.xml",
+	}, exitcodes.ThresholdsHaveFailed)
+	ts.Env["GITHUB_SHA"] = "0123456789abcdef"
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	data, err := fsext.ReadFile(ts.FS, "k6-report.json")
+	require.NoError(t, err)
+	var report map[string]any
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.EqualValues(t, 1, report["schemaVersion"])
+	assert.Equal(t, "0123456789abcdef", report["metadata"].(map[string]any)["gitCommit"]) //nolint:forcetypeassert
+	assert.Equal(t, []any{map[string]any{"metric": "checks", "source": "rate==1", "ok": false}}, report["thresholds"])
+	assert.Equal(t, []any{map[string]any{"name": "is first", "group": "", "passes": 1.0, "fails": 1.0}}, report["checks"])
+
+	data, err = fsext.ReadFile(ts.FS, "junit.xml")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<testsuites name="k6" tests="2" failures="2"`)
+}
+
+func TestCardinalityLimits(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		import { Counter } from 'k6/metrics';
+
+		const requests = new Counter('requests');
+
+		export const options = {
+			iterations: 10,
+			thresholds: {
+				'requests{id:_other}': ['count==7'],
+				'requests{id:2}': ['count==1'],
+			},
+		};
+
+		export default function() { requests.add(1, { id: String(__ITER) }); };
+	`
+
+	ts := getSingleFileTestState(t, script, []string{"--tag-cardinality-limit", "3"}, 0)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	assert.True(t, testutils.LogContains(ts.LoggerHook.Drain(), logrus.WarnLevel,
+		"The tag 'id' of the metric 'requests' has more than 3 unique values, its new values are folded into '_other'"))
+}
+
+func TestRedaction(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		import { Counter } from 'k6/metrics';
+
+		const requests = new Counter('requests');
+
+		export const options = {
+			iterations: 2,
+			redact: [
+				{ match: "[\\w.+-]+@[\\w-]+\\.[\\w.]+" },
+				{ tags: ["user"], replacement: "anonymous" },
+			],
+			thresholds: {
+				'requests{user:anonymous}': ['count==2'],
+			},
+		};
+
+		export default function() {
+			requests.add(1, { user: "jane" + __ITER });
+			console.log("signed in as jane@k6.io");
+		};
+	`
+
+	ts := getSingleFileTestState(t, script, nil, 0)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	logs := ts.LoggerHook.Drain()
+	assert.True(t, testutils.LogContains(logs, logrus.InfoLevel, "signed in as [REDACTED]"))
+	assert.False(t, testutils.LogContains(logs, logrus.InfoLevel, "jane@k6.io"))
+}
+
+func TestTypeCheck(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake TypeScript compiler is a shell script")
+	}
+
+	script := `
+		export const options = { iterations: 1 };
+
+		export default function() { const typed: number = 1; console.log("ran " + typed); };
+	`
+
+	testCases := []struct {
+		name        string
+		output      string
+		exitCode    int
+		expExitCode exitcodes.ExitCode
+	}{
+		{name: "Passed"},
+		{
+			name:        "Failed",
+			output:      "test.ts(4,45): error TS2322: Type 'string' is not assignable to type 'number'.",
+			exitCode:    2,
+			expExitCode: exitcodes.ScriptException,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			tsc := filepath.Join(dir, "tsc")
+			fakeTSC := fmt.Sprintf("#!/bin/sh\necho \"$@\" > %q\necho %q\nexit %d\n",
+				filepath.Join(dir, "args"), tc.output, tc.exitCode)
+			require.NoError(t, os.WriteFile(tsc, []byte(fakeTSC), 0o755)) //nolint:forbidigo
+
+			ts := NewGlobalTestState(t)
+			require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.ts"), []byte(script), 0o644))
+			ts.CmdArgs = []string{"k6", "run", "--typecheck", "--compatibility-mode", "experimental_enhanced", "test.ts"}
+			ts.Env["K6_TSC"] = tsc
+			ts.ExpectedExitCode = int(tc.expExitCode)
+			cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+			args, err := os.ReadFile(filepath.Join(dir, "args")) //nolint:forbidigo
+			require.NoError(t, err)
+			assert.Contains(t, string(args), "--noEmit --pretty false")
+			assert.Contains(t, string(args), filepath.Join(ts.Cwd, "test.ts"))
+
+			stderr := ts.Stderr.String()
+			t.Log(stderr)
+			if tc.expExitCode == 0 {
+				assert.Contains(t, stderr, "ran 1")
+			} else {
+				assert.Contains(t, stderr, "type-checking '"+filepath.Join(ts.Cwd, "test.ts")+"' failed")
+				assert.Contains(t, stderr, tc.output)
+				assert.NotContains(t, stderr, "ran 1")
+			}
+		})
+	}
+}
+
+func TestModulesLockAndCache(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		import { value } from "https://example.com/lib.js";
+
+		export const options = { iterations: 1 };
+
+		export default function() { console.log("value " + value); };
+	`
+
+	ts := NewGlobalTestState(t)
+	cachedPath := filepath.Join(ts.Cwd, "cache", "example.com", "lib.js")
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+	require.NoError(t, fsext.WriteFile(ts.FS, cachedPath, []byte("export const value = 1;"), 0o644))
+	ts.CmdArgs = []string{"k6", "run", "--modules-cache", "cache", "--modules-lock", "k6.lock", "test.js"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	assert.Contains(t, ts.Stderr.String(), "value 1")
+	lock, err := fsext.ReadFile(ts.FS, filepath.Join(ts.Cwd, "k6.lock"))
+	require.NoError(t, err)
+	assert.Contains(t, string(lock), `"https://example.com/lib.js": "sha384-`)
+
+	// the module changed since it was pinned
+	ts2 := NewGlobalTestState(t)
+	ts2.FS = ts.FS
+	ts2.Cwd = ts.Cwd
+	require.NoError(t, fsext.WriteFile(ts.FS, cachedPath, []byte("export const value = 2;"), 0o644))
+	ts2.CmdArgs = ts.CmdArgs
+	ts2.ExpectedExitCode = int(exitcodes.ScriptException)
+	cmd.ExecuteWithGlobalState(ts2.GlobalState)
+
+	stderr := ts2.Stderr.String()
+	assert.Contains(t, stderr, `the integrity of the module \"https://example.com/lib.js\"`)
+	assert.NotContains(t, stderr, "value 2")
+}
+
+func TestSecretSources(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		import secrets from "k6/secrets";
+
+		export const options = { iterations: 1 };
+
+		export default async function() {
+			const password = await secrets.get("password");
+			const token = await secrets.source("api").get("TOKEN");
+			console.log("the password is " + password + " and the token is " + token);
+		};
+	`
+
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "secrets.txt"), []byte("password=hunter2\n"), 0o644))
+	ts.Env["API_TOKEN"] = "abc123"
+	ts.CmdArgs = []string{
+		"k6", "run", "--secret-source", "file=secrets.txt", "--secret-source", "env=API_,name=api", "test.js",
+	}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stderr := ts.Stderr.String()
+	assert.Contains(t, stderr, "the password is ***SECRET_REDACTED*** and the token is ***SECRET_REDACTED***")
+	assert.NotContains(t, stderr, "hunter2")
+	assert.NotContains(t, stderr, "abc123")
+}
+
+func TestStructuredLogging(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		import log from "k6/log";
+
+		log.configure({ level: "info", rateLimits: { info: 1 } });
+
+		export const options = { iterations: 3 };
+
+		export default function() {
+			log.debug("not logged");
+			log.info("iteration done", { user: "admin" });
+		};
+	`
+
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+	ts.CmdArgs = []string{"k6", "run", "--log-format", "json", "test.js"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stderr := ts.Stderr.String()
+	assert.Equal(t, 1, strings.Count(stderr, `"msg":"iteration done"`))
+	assert.Contains(t, stderr, `"msg":"iteration done","scenario":"default","source":"log",`)
+	assert.Contains(t, stderr, `"user":"admin","vu":1}`)
+	assert.NotContains(t, stderr, "not logged")
+}
+
+func TestCaptureFailures(t *testing.T) {
+	t.Parallel()
+
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	script := tb.Replacer.Replace(`
+		import http from "k6/http";
+		import { check } from "k6";
+
+		export const options = { iterations: 1 };
+
+		export default function() {
+			http.post("HTTPBIN_IP_URL/status/503", "request body");
+			const res = http.get("HTTPBIN_IP_URL/get");
+			check(res, { "is created": (r) => r.status === 201 });
+			check(res, { "is ok": (r) => r.status === 200 });
+		};
+	`)
+
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+	ts.CmdArgs = []string{"k6", "run", "--capture-failures", "path=failures,body=16", "test.js"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	dir := filepath.Join(ts.Cwd, "failures")
+	entries, err := fsext.ReadDir(ts.FS, dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	var artifacts [2]capture.Artifact
+	for i, entry := range entries {
+		data, err := fsext.ReadFile(ts.FS, filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &artifacts[i]))
+	}
+
+	assert.Equal(t, "the status 503 isn't an expected one", artifacts[0].Reason)
+	assert.Equal(t, "default", artifacts[0].Scenario)
+	assert.Equal(t, "POST", artifacts[0].Request.Method)
+	assert.Equal(t, "request body", artifacts[0].Request.Body)
+	assert.Equal(t, 503, artifacts[0].Response.Status)
+
+	assert.Equal(t, "the checks failed", artifacts[1].Reason)
+	assert.Equal(t, []string{"is created"}, artifacts[1].Checks)
+	assert.Equal(t, 200, artifacts[1].Response.Status)
+	assert.Len(t, artifacts[1].Response.Body, 16)
+	assert.True(t, artifacts[1].Response.BodyTruncated)
+}
+
+func TestScenarioFaults(t *testing.T) {
+	t.Parallel()
+
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	script := tb.Replacer.Replace(`
+		import http from "k6/http";
+		import exec from "k6/execution";
+
+		export const options = {
+			hosts: { "HTTPBIN_DOMAIN": "HTTPBIN_IP" },
+			scenarios: {
+				dns: {
+					executor: "per-vu-iterations",
+					iterations: 1,
+					faults: { dnsFailures: { rate: 1 } },
+				},
+				resets: {
+					executor: "per-vu-iterations",
+					iterations: 1,
+					startTime: "100ms",
+					faults: { resets: {} },
+				},
+				healthy: {
+					executor: "per-vu-iterations",
+					iterations: 1,
+					startTime: "200ms",
+				},
+			},
+		};
+
+		export default function() {
+			const url = exec.scenario.name === "dns" ? "HTTPBIN_URL/get" : "HTTPBIN_IP_URL/get";
+			const res = http.get(url);
+			console.log(exec.scenario.name + " error code " + res.error_code + ", status " + res.status);
+		};
+	`)
+
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+	ts.CmdArgs = []string{"k6", "run", "test.js"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stderr := ts.Stderr.String()
+	assert.Contains(t, stderr, "dns error code 1101, status 0")
+	assert.Contains(t, stderr, "resets error code 1220, status 0")
+	assert.Contains(t, stderr, "healthy error code 0, status 200")
+}
+
+func TestScenarioWarmup(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		export const options = {
+			scenarios: {
+				tagged: {
+					executor: "shared-iterations",
+					iterations: 10,
+					warmup: 4,
+				},
+				dropped: {
+					executor: "shared-iterations",
+					iterations: 10,
+					warmup: { iterations: 3, samples: "drop" },
+				},
+			},
+			thresholds: {
+				"iterations{scenario:tagged}": ["count==6"],
+				"iterations{scenario:dropped}": ["count==7"],
+			},
+		};
+
+		export default function() {};
+	`
+
+	ts := getSingleFileTestState(t, script, []string{"--out", "json=results.json"}, 0)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	assert.Regexp(t, `iterations\.+: 13 `, ts.Stdout.String())
+
+	jsonResults, err := fsext.ReadFile(ts.FS, "results.json")
+	require.NoError(t, err)
+	warmupIterations := map[string]int{}
+	for _, line := range strings.Split(string(jsonResults), "\n") {
+		var sample struct {
+			Metric string
+			Type   string
+			Data   struct{ Tags map[string]string }
+		}
+		if line == "" || json.Unmarshal([]byte(line), &sample) != nil {
+			continue
+		}
+		if sample.Type == "Point" && sample.Metric == "iterations" && sample.Data.Tags["phase"] == "warmup" {
+			warmupIterations[sample.Data.Tags["scenario"]]++
+		}
+	}
+	assert.Equal(t, map[string]int{"tagged": 4}, warmupIterations)
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	t.Parallel()
+	tb := httpmultibin.NewHTTPMultiBin(t)
+
+	var gotRequests int64
+	tb.Mux.HandleFunc("/hooked", func(_ http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&gotRequests, 1)
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		assert.Empty(t, r.Header.Get("X-Debug"))
+	})
+
+	script := tb.Replacer.Replace(`
+		import http from "k6/http";
+		import { Counter } from "k6/metrics";
+		import { onIterationStart, onIterationEnd, onRequest } from "k6/experimental/hooks";
+
+		export const options = {
+			iterations: 3,
+			thresholds: {
+				"http_reqs{hooked:yes}": ["count==3"],
+				"http_reqs{debug:true}": ["count==0"],
+				"iteration_ends": ["count==3"],
+			},
+		};
+
+		const iterationEnds = new Counter("iteration_ends");
+		let token = 0;
+
+		onIterationStart(() => {
+			token++;
+		});
+		onIterationEnd((info) => {
+			if (info.error === undefined) {
+				iterationEnds.add(1);
+			}
+		});
+		onRequest((req) => {
+			if (req.protocol !== "http" || req.method !== "GET") {
+				throw new Error("unexpected request " + req.protocol + " " + req.method);
+			}
+			req.headers["Authorization"] = "Bearer token-" + (token - __ITER);
+			delete req.headers["X-Debug"];
+			req.tags.hooked = "yes";
+			delete req.tags.debug;
+		});
+
+		export default function () {
+			http.get("HTTPBIN_IP_URL/hooked", {
+				headers: { "X-Debug": "1" },
+				tags: { debug: "true" },
+			});
+		};
+	`)
+
+	ts := getSingleFileTestState(t, script, nil, 0)
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	assert.Equal(t, int64(3), atomic.LoadInt64(&gotRequests))
+}
+
+func TestLoadGeneratorMetrics(t *testing.T) {
+	t.Parallel()
+
+	script := `
+		export const options = {
+			iterations: 1,
+			thresholds: {
+				loadgen_heap_bytes: ["value>0"],
+				loadgen_event_loop_lag: ["max<1000"],
+				loadgen_saturated: ["rate<=1"],
+			},
+		};
+
+		export default async function() {
+			await new Promise((resolve) => setTimeout(resolve, 1500));
+		};
+	`
+
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "test.js"), []byte(script), 0o644))
+	ts.CmdArgs = []string{"k6", "run", "--load-generator-metrics", "test.js"}
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	stdout := ts.Stdout.String()
+	assert.Regexp(t, `✓ loadgen_event_loop_lag\.+: avg=`, stdout)
+	assert.Regexp(t, `✓ loadgen_heap_bytes\.+: \d`, stdout)
+	assert.Regexp(t, `✓ loadgen_saturated\.+: \d`, stdout)
+	assert.Contains(t, stdout, "loadgen_cpu")
+}
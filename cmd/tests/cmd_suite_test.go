@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/cmd"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func getSuiteTestState(t *testing.T, parallel string, expExitCode exitcodes.ExitCode) *GlobalTestState {
+	t.Helper()
+
+	ts := NewGlobalTestState(t)
+	files := map[string]string{
+		"suite.json": `{
+			"parallel": ` + parallel + `,
+			"options": { "iterations": 3 },
+			"tests": [
+				{ "name": "passing", "script": "tests/passing.js", "env": { "MESSAGE": "from the suite" } },
+				{ "name": "failing", "script": "tests/failing.js", "options": { "iterations": 5 } }
+			]
+		}`,
+		"tests/passing.js": `
+			export const options = { iterations: 10, thresholds: { iterations: ['count == 3'] } };
+			export default function () {
+				console.log('passing: ' + __ENV.MESSAGE);
+			};
+		`,
+		"tests/failing.js": `
+			export const options = { thresholds: { iterations: ['count == 3'] } };
+			export default function () {
+				console.log('failing: ' + __ENV.MESSAGE);
+			};
+		`,
+	}
+	for name, content := range files {
+		require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, name), []byte(content), 0o644))
+	}
+	ts.CmdArgs = []string{"k6", "suite", "--log-output=stdout", "suite.json"}
+	ts.ExpectedExitCode = int(expExitCode)
+
+	return ts
+}
+
+func TestSuiteRun(t *testing.T) {
+	t.Parallel()
+
+	for _, parallel := range []string{"false", "true"} {
+		parallel := parallel
+		t.Run("parallel="+parallel, func(t *testing.T) {
+			t.Parallel()
+
+			ts := getSuiteTestState(t, parallel, exitcodes.ThresholdsHaveFailed)
+			cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+			stdout := ts.Stdout.String()
+			t.Log(stdout)
+			assert.Contains(t, stdout, `msg="passing: from the suite"`)
+			assert.Contains(t, stdout, `msg="failing: undefined"`)
+			assert.Contains(t, stdout, "tests: 1 passed, 1 failed, 0 skipped")
+			assert.Regexp(t, `✓ passing \(`, stdout)
+			assert.Contains(t, stdout, "✗ failing: thresholds on metrics 'iterations' have been crossed")
+			assert.Contains(t, stdout, `level=error msg="1 of 2 tests in the suite have failed: failing"`)
+		})
+	}
+}
+
+func TestSuiteRunInvalid(t *testing.T) {
+	t.Parallel()
+
+	ts := NewGlobalTestState(t)
+	require.NoError(t, fsext.WriteFile(ts.FS, filepath.Join(ts.Cwd, "suite.json"), []byte(`{"tests": []}`), 0o644))
+	ts.CmdArgs = []string{"k6", "suite", "suite.json"}
+	ts.ExpectedExitCode = int(exitcodes.InvalidConfig)
+
+	cmd.ExecuteWithGlobalState(ts.GlobalState)
+
+	assert.Contains(t, ts.Stderr.String(), "doesn't have any tests")
+}
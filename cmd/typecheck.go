@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"go.k6.io/k6/cmd/state"
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/loader"
+)
+
+// typeCheckCompilerOptions are the options of the TypeScript compiler for the
+// scripts without a tsconfig.json next to them, which match how esbuild
+// transpiles them.
+//
+//nolint:gochecknoglobals
+var typeCheckCompilerOptions = []string{
+	"--target", "esnext",
+	"--module", "esnext",
+	"--moduleResolution", "bundler",
+	"--allowImportingTsExtensions",
+	"--allowJs",
+	"--skipLibCheck",
+}
+
+// typeCheck type-checks the local script with the TypeScript compiler, the tsc
+// command unless the K6_TSC env var says otherwise, so the type errors fail
+// the test before any VU is started. The types of the k6 modules are the ones
+// of the @types/k6 package, if it's installed next to the script.
+func typeCheck(gs *state.GlobalState, src *loader.SourceData) error {
+	if src.URL.Scheme != "file" || src.URL.Path == "/-" || detectTestType(src.Data) != testTypeJS {
+		gs.Logger.Warnf("Type-checking is only supported for the local scripts, '%s' isn't type-checked", src.URL)
+		return nil
+	}
+	script := filepath.FromSlash(src.URL.Path)
+
+	tsc := "tsc"
+	if envVar, ok := gs.Env["K6_TSC"]; ok && envVar != "" {
+		tsc = envVar
+	}
+	args := []string{"--noEmit", "--pretty", "false"}
+	tsconfig := filepath.Join(filepath.Dir(script), "tsconfig.json")
+	if exists, err := fsext.Exists(gs.FS, tsconfig); err == nil && exists {
+		args = append(args, "--project", tsconfig)
+	} else {
+		args = append(append(args, typeCheckCompilerOptions...), script)
+	}
+
+	gs.Logger.Debugf("Type-checking '%s' with '%s %s'...", script, tsc, strings.Join(args, " "))
+	output, err := exec.CommandContext(gs.Ctx, tsc, args...).CombinedOutput() //nolint:gosec
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &exitErr):
+		return errext.WithExitCodeIfNone(
+			fmt.Errorf("type-checking '%s' failed:\n%s", script, strings.TrimSpace(string(output))),
+			exitcodes.ScriptException,
+		)
+	case errors.Is(err, exec.ErrNotFound):
+		return errext.WithHint(
+			fmt.Errorf("couldn't find the TypeScript compiler '%s' to type-check '%s'", tsc, script),
+			"install it with 'npm install -g typescript', or set its path with the K6_TSC env var",
+		)
+	default:
+		return fmt.Errorf("couldn't type-check '%s': %w", script, err)
+	}
+}
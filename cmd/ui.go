@@ -115,7 +115,7 @@ func printExecutionDescription(
 		for _, out := range outputs {
 			desc := out.Description()
 			switch desc {
-			case engine.IngesterDescription, lib.GroupSummaryDescription:
+			case engine.IngesterDescription, lib.GroupSummaryDescription, lib.SampleObserversDescription:
 				continue
 			}
 			if strings.HasPrefix(desc, dashboard.OutputName) {
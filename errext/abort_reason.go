@@ -15,6 +15,9 @@ const (
 	AbortedByScriptAbort
 	AbortedByTimeout
 	AbortedByOutput
+	// FailedByScript is attached to the error of a test run that the script
+	// marked as failed, with test.fail(), but that wasn't stopped prematurely.
+	FailedByScript
 )
 
 // HasAbortReason is a wrapper around an error with an attached abort reason.
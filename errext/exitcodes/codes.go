@@ -53,4 +53,12 @@ const (
 
 	// GoPanic indicates the script was aborted by a panic in the Go runtime.
 	GoPanic ExitCode = 109
+
+	// BaselineRegression indicates that one or more metrics regressed beyond
+	// their tolerances, compared to the baseline of the test run.
+	BaselineRegression ExitCode = 110
+
+	// ScriptMarkedFailed indicates the script marked the test run as failed
+	// by a call to the k6 execution module's `test.fail()` function.
+	ScriptMarkedFailed ExitCode = 111
 )
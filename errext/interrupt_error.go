@@ -9,6 +9,10 @@ import (
 // InterruptError is an error that halts engine execution
 type InterruptError struct {
 	Reason string
+
+	// Code is the status code the k6 process exits with, ScriptAborted if it
+	// isn't set.
+	Code exitcodes.ExitCode
 }
 
 var _ interface {
@@ -23,6 +27,9 @@ func (i *InterruptError) Error() string {
 
 // ExitCode returns the status code used when the k6 process exits.
 func (i *InterruptError) ExitCode() exitcodes.ExitCode {
+	if i.Code != 0 {
+		return i.Code
+	}
 	return exitcodes.ScriptAborted
 }
 
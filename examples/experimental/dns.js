@@ -0,0 +1,18 @@
+import http from 'k6/http';
+import { check } from 'k6';
+import dns from 'k6/experimental/dns';
+
+export default async function () {
+  const ips = await dns.resolve('test.k6.io', { type: 'A', nameserver: '1.1.1.1' });
+  console.log(`test.k6.io resolves to ${ips.join(', ')}`);
+
+  // Send the requests of every other iteration to the first IP.
+  if (__ITER % 2 === 1) {
+    dns.setHost('test.k6.io', ips[0]);
+  } else {
+    dns.removeHost('test.k6.io');
+  }
+
+  const res = http.get('http://test.k6.io/');
+  check(res, { 'status is 200': (r) => r.status === 200 });
+}
@@ -0,0 +1,25 @@
+import http from 'k6/http';
+import { check } from 'k6';
+import { Faker, faker } from 'k6/experimental/faker';
+
+// The users are the same in every run, and in every VU.
+const users = new Faker(1);
+const admin = { name: users.fullName(), email: users.email() };
+
+export default function () {
+  // The default faker is seeded with the ID of the VU.
+  const payload = {
+    id: faker.uuid(),
+    name: faker.fullName(),
+    email: faker.email(),
+    address: faker.address(),
+    card: faker.creditCardNumber('mastercard'),
+    comment: faker.paragraph(),
+    createdBy: admin,
+  };
+
+  const res = http.post('https://httpbin.test.k6.io/post', JSON.stringify(payload), {
+    headers: { 'Content-Type': 'application/json' },
+  });
+  check(res, { 'status is 200': (r) => r.status === 200 });
+}
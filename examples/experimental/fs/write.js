@@ -0,0 +1,30 @@
+import { open, appendFile } from "k6/experimental/fs";
+import exec from "k6/execution";
+
+// Writing files has to be allowed explicitly:
+//
+//   k6 run --allow-file-writes write.js
+export const options = {
+	vus: 10,
+	iterations: 100,
+};
+
+// k6 doesn't support async in the init context. We use a top-level async function for `await`.
+//
+// Files opened for writing are created if they don't exist, and truncated otherwise.
+let log;
+(async function () {
+	log = await open(`vu-${__VU}.log`, "w");
+})();
+
+export default async function () {
+	// Each VU writes to its own file...
+	await log.write(`iteration ${exec.vu.iterationInScenario}\n`);
+
+	// ...while appendFile can be used from any VU to append to a shared file.
+	await appendFile("tokens.txt", `token-${exec.vu.idInTest}-${exec.vu.iterationInScenario}\n`);
+}
+
+export async function teardown() {
+	await appendFile("tokens.txt", "done\n");
+}
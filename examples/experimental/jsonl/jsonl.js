@@ -0,0 +1,29 @@
+import { open } from "k6/experimental/fs";
+import jsonl from "k6/experimental/jsonl";
+
+export const options = {
+	iterations: 3,
+};
+
+// k6 doesn't support async in the init context. We use a top-level async function for `await`.
+//
+// Each Virtual User gets its own `file` and `parser` copies.
+let parser;
+(async function () {
+	const file = await open("data.jsonl");
+	parser = new jsonl.Parser(file);
+})();
+
+export default async function () {
+	// The parser `next` method attempts to read the next line from the JSON Lines file.
+	//
+	// It returns an iterator-like object with a `done` property that indicates whether
+	// there are more lines to read, and a `value` property that contains the value the
+	// line holds, as `JSON.parse` would return it.
+	const { done, value } = await parser.next();
+	if (done) {
+		throw new Error("No more lines to read");
+	}
+
+	console.log(value.firstname, value.age);
+}
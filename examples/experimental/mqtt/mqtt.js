@@ -0,0 +1,46 @@
+import { connect } from "k6/experimental/mqtt";
+import { sleep } from "k6";
+import exec from "k6/execution";
+
+export const options = {
+	vus: 100,
+	duration: "1m",
+};
+
+const brokerURL = __ENV.MQTT_BROKER || "mqtt://localhost:1883";
+
+export default async function () {
+	// Each VU simulates a device, which resumes its session on reconnection when
+	// `cleanSession` is false. Use the `mqtts` scheme, and the `tls` option to
+	// present a client certificate, to connect over TLS.
+	const client = await connect(brokerURL, {
+		clientId: `device-${exec.vu.idInTest}`,
+		cleanSession: false,
+		keepAlive: "30s",
+	});
+
+	client.on("message", (message) => {
+		console.log(`${client.clientId} received ${message.payload} on ${message.topic}`);
+	});
+
+	client.on("error", (e) => {
+		console.error(`${client.clientId} lost its connection: ${e.error}`);
+	});
+
+	// The commands sent to the device are received with QoS 1 at most.
+	await client.subscribe(`devices/${exec.vu.idInTest}/commands`, { qos: 1 });
+
+	for (let i = 0; i < 10; i++) {
+		// QoS 1 and 2 publications resolve once the broker acknowledged them,
+		// which the mqtt_publish_duration metric measures.
+		await client.publish(
+			`devices/${exec.vu.idInTest}/telemetry`,
+			JSON.stringify({ temperature: 20 + Math.random() * 5 }),
+			{ qos: 1 },
+		);
+		sleep(1);
+	}
+
+	// The iteration lasts until the client is closed.
+	await client.close();
+}
@@ -0,0 +1,36 @@
+import { connect } from "k6/experimental/sockets";
+import { check } from "k6";
+
+export const options = {
+	iterations: 1,
+};
+
+export default async function () {
+	// TCP connections can be secured with TLS right away...
+	const socket = await connect("tcp", "test.k6.io:443", { timeout: "5s", tls: true });
+
+	await socket.send("GET / HTTP/1.1\r\nHost: test.k6.io\r\nConnection: close\r\n\r\n");
+
+	// receive() resolves to an ArrayBuffer holding the data available, or exactly
+	// `size` bytes when set, and rejects if no data is received within `timeout`.
+	const response = String.fromCharCode.apply(null, new Uint8Array(await socket.receive({ timeout: "5s" })));
+	check(response, { "status is 200": (r) => r.startsWith("HTTP/1.1 200") });
+
+	await socket.close();
+
+	// ...while UDP sockets send and receive single datagrams, here a DNS query
+	// for the A records of test.k6.io.
+	const dns = await connect("udp", "1.1.1.1:53");
+	await dns.send(
+		new Uint8Array([
+			0x12, 0x34, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x04, 0x74, 0x65, 0x73, 0x74, 0x02, 0x6b, 0x36, 0x02, 0x69, 0x6f, 0x00,
+			0x00, 0x01, 0x00, 0x01,
+		]),
+	);
+
+	const answer = new Uint8Array(await dns.receive({ timeout: "2s" }));
+	check(answer, { "same query id": (a) => a[0] === 0x12 && a[1] === 0x34 });
+
+	await dns.close();
+}
@@ -0,0 +1,47 @@
+import { EventSource } from "k6/experimental/sse";
+import { check } from "k6";
+
+export const options = {
+	vus: 1,
+	iterations: 1,
+};
+
+export default function () {
+	// Like the ones of LLM APIs, event streams can be requested through POST requests.
+	const es = new EventSource("https://sse.example.com/v1/completions", {
+		method: "POST",
+		headers: { "Content-Type": "application/json" },
+		body: JSON.stringify({ prompt: "Hello", stream: true }),
+		// The time to wait for before reconnecting, unless the server sets it through `retry`.
+		reconnectionDelay: "1s",
+	});
+
+	let tokens = 0;
+
+	es.on("open", () => {
+		console.log("connected");
+	});
+
+	// Events sent without an `event` type are dispatched as "message" events...
+	es.on("message", (e) => {
+		if (e.data === "[DONE]") {
+			check(tokens, { "received tokens": (n) => n > 0 });
+
+			// The iteration lasts until the event source is closed.
+			es.close();
+			return;
+		}
+
+		tokens++;
+	});
+
+	// ...while the others are dispatched under their own type.
+	es.on("ping", (e) => {
+		console.log(`ping, last event id: ${e.lastEventId}`);
+	});
+
+	// Lost connections are established again, with the Last-Event-ID header set.
+	es.on("error", (e) => {
+		console.log(`error: ${e.error}, readyState: ${es.readyState}`);
+	});
+}
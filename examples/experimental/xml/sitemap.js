@@ -0,0 +1,38 @@
+import http from "k6/http";
+import xml from "k6/experimental/xml";
+
+export const options = {
+	iterations: 1,
+};
+
+export default async function () {
+	const res = http.get("https://test.k6.io/sitemap.xml");
+
+	// The parser reads the document as a sequence of events, rather than building
+	// its whole tree in memory. It accepts files opened through `k6/experimental/fs`,
+	// as well as strings and ArrayBuffers, such as response bodies.
+	const parser = new xml.Parser(res.body, { skipWhitespace: true });
+
+	// The parser `next` method returns an iterator-like object with a `done`
+	// property that indicates whether there are more events to read, and a
+	// `value` property that holds the event.
+	let inLoc = false;
+	let { done, value } = await parser.next();
+	while (!done) {
+		switch (value.type) {
+			case "startElement":
+				inLoc = value.name === "loc";
+				break;
+			case "text":
+				if (inLoc) {
+					console.log(value.text);
+				}
+				break;
+			case "endElement":
+				inLoc = false;
+				break;
+		}
+
+		({ done, value } = await parser.next());
+	}
+}
@@ -0,0 +1,44 @@
+import grpc from 'k6/net/grpc';
+import { check } from "k6";
+
+// to run this sample, you need to start the grpc server first.
+// to start the grpc server, run the following command in k6 repository's root:
+// go run -mod=mod examples/grpc_server/*.go
+// (golang should be installed)
+const GRPC_ADDR = __ENV.GRPC_ADDR || '127.0.0.1:10000';
+const GRPC_PROTO_PATH = __ENV.GRPC_PROTO_PATH || '../lib/testutils/grpcservice/route_guide.proto';
+
+let client = new grpc.Client();
+
+client.load([], GRPC_PROTO_PATH);
+
+export default () => {
+  // The RPCs failing with one of the retryable status codes are retried, like a
+  // service mesh would, up to maxAttempts times in total, with an exponential
+  // backoff in between. The latency of the retried attempts is measured by the
+  // grpc_req_retry_duration metric, apart from grpc_req_duration.
+  client.connect(GRPC_ADDR, {
+    plaintext: true,
+    retryPolicy: {
+      maxAttempts: 3,
+      initialBackoff: "100ms",
+      maxBackoff: "1s",
+      backoffMultiplier: 2,
+      retryableStatusCodes: [grpc.StatusUnavailable, "RESOURCE_EXHAUSTED"],
+    },
+  });
+
+  // The server as a whole, or one of its services, can be checked through the
+  // gRPC health checking protocol.
+  const health = client.healthCheck();
+  check(health, { "server is serving": (r) => r && r.message.status === "SERVING" });
+
+  const response = client.invoke("main.FeatureExplorer/GetFeature", {
+    latitude: 410248224,
+    longitude: -747127767
+  })
+
+  check(response, { "status is OK": (r) => r && r.status === grpc.StatusOK });
+
+  client.close()
+}
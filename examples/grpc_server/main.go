@@ -34,6 +34,8 @@ import (
 
 	"go.k6.io/k6/lib/testutils/grpcservice"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/testdata"
 
 	"google.golang.org/grpc/reflection"
@@ -78,6 +80,7 @@ func main() {
 	grpcServer := grpc.NewServer(opts...)
 	grpcservice.RegisterRouteGuideServer(grpcServer, grpcservice.NewRouteGuideServer(features...))
 	grpcservice.RegisterFeatureExplorerServer(grpcServer, grpcservice.NewFeatureExplorerServer(features...))
+	healthgrpc.RegisterHealthServer(grpcServer, health.NewServer())
 	reflection.Register(grpcServer)
 	grpcServer.Serve(lis)
 }
@@ -0,0 +1,22 @@
+import http from "k6/http";
+import { check } from "k6";
+
+export const options = {
+  // Each VU keeps at most 4 idle connections per host, and closes the
+  // connections once they served 100 requests.
+  maxIdleConnsPerHost: 4,
+  maxRequestsPerConnection: 100,
+};
+
+export default function () {
+  http.get("https://test.k6.io/");
+  http.get("https://test.k6.io/");
+
+  // The stats are those of the VU's own connections.
+  const stats = http.connectionStats();
+  check(stats, { "connection is reused": (s) => s.reused > 0 });
+  console.log(`open: ${stats.open}, idle: ${stats.idle}, reused: ${stats.reused}`);
+
+  // Closing the idle connections forces the next requests to reconnect.
+  http.closeIdleConnections();
+}
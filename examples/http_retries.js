@@ -0,0 +1,29 @@
+import http from "k6/http";
+import { check } from "k6";
+
+export const options = {
+  thresholds: {
+    // The retries are counted by the http_req_retries metric.
+    http_req_retries: ["count<10"],
+  },
+};
+
+export default function () {
+  // Retried up to 3 times on network errors, and on 502, 503 and 504 responses,
+  // waiting 100ms, then 200ms, then 400ms in between.
+  let res = http.get("https://httpbin.test.k6.io/status/503", { retries: 3 });
+  check(res, { "is status 503": (r) => r.status === 503 });
+
+  // The backoff, and what's retried, can be tuned.
+  res = http.get("https://httpbin.test.k6.io/status/429", {
+    retries: {
+      count: 2,
+      backoff: "constant", // or "linear", or "exponential"
+      delay: "1s",
+      maxDelay: "5s",
+      statusCodes: [429],
+      networkErrors: false,
+    },
+  });
+  check(res, { "is status 429": (r) => r.status === 429 });
+}
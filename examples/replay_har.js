@@ -0,0 +1,29 @@
+import http from "k6/http";
+import exec from "k6/execution";
+
+// The recorded requests, in the order they were made.
+const entries = JSON.parse(open("./recording.har")).log.entries;
+
+export const options = {
+  scenarios: {
+    replay: {
+      executor: "replay",
+      // Every entry starts an iteration at its original offset, twice as fast.
+      timestamps: entries.map((e) => e.startedDateTime),
+      timeScale: 2,
+      preAllocatedVUs: 10,
+      maxVUs: 100,
+    },
+  },
+};
+
+export default function () {
+  // The iterations are started in the order of the timestamps.
+  const { request } = entries[exec.scenario.iterationInTest];
+  const headers = Object.fromEntries(
+    request.headers.filter((h) => !h.name.startsWith(":")).map((h) => [h.name, h.value]),
+  );
+  http.request(request.method, request.url, request.postData ? request.postData.text : null, {
+    headers: headers,
+  });
+}
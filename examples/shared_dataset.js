@@ -0,0 +1,21 @@
+import http from "k6/http";
+import exec from "k6/execution";
+import { SharedDataset } from "k6/data";
+
+// The file is loaded only once and shared between all VUs, and every row is
+// parsed only when it's accessed, so even huge files can be used.
+const users = new SharedDataset("./users.csv");
+
+export const options = {
+  vus: 10,
+  duration: "30s",
+};
+
+export default function () {
+  // Every row is an object with the columns from the CSV header.
+  const user = users[exec.scenario.iterationInTest % users.length];
+  http.post("https://quickpizza.grafana.com/api/users/token/login", JSON.stringify({
+    username: user.username,
+    password: user.password,
+  }));
+}
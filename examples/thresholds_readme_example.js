@@ -18,7 +18,11 @@ export let options = {
     ],
     thresholds: {
         // We want the 95th percentile of all HTTP request durations to be less than 500ms
-        "http_req_duration": ["p(95)<500"],
+        "http_req_duration": [
+            "p(95)<500",
+            // Abort the test if it climbs over 1s over any 1 minute window, even briefly
+            { threshold: "p(95)<1000 over 1m", abortOnFail: true },
+        ],
         // Requests with the staticAsset tag should finish even faster
         "http_req_duration{staticAsset:yes}": ["p(99)<250"],
         // Thresholds based on the custom metric we defined and use to track application failures
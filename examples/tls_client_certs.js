@@ -0,0 +1,17 @@
+import http from "k6/http";
+import { check } from "k6";
+import exec from "k6/execution";
+
+// Every tenant has its own client certificate and key, in PEM format.
+const tenants = [
+  { cert: open("./tenant-a.crt"), key: open("./tenant-a.key") },
+  { cert: open("./tenant-b.crt"), key: open("./tenant-b.key"), password: "secret" },
+];
+
+export default function () {
+  // The VUs use different identities, and passing another certificate in the
+  // same VU, e.g. after it was rotated, opens new connections with it.
+  const tlsAuth = tenants[exec.vu.idInTest % tenants.length];
+  const res = http.get("https://mtls.example.com/", { tlsAuth: tlsAuth });
+  check(res, { "status is 200": (r) => r.status === 200 });
+}
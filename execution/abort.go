@@ -3,6 +3,8 @@ package execution
 import (
 	"context"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -19,9 +21,35 @@ type testAbortController struct {
 	logger logrus.FieldLogger
 	lock   sync.Mutex // only the first reason will be kept, other will be logged
 	reason error      // see errext package, you can wrap errors to attach exit status, run status, etc.
+
+	// stopping is set once the test run is stopped gracefully, after which no
+	// new iterations are started, and the context is cancelled once the
+	// iterations in progress are done, or their grace period is over.
+	stopping   atomic.Bool
+	iterations atomic.Int64
 }
 
 func (tac *testAbortController) abort(err error) {
+	if tac.setReason(err) {
+		tac.cancel()
+	}
+}
+
+func (tac *testAbortController) stop(err error, gracePeriod time.Duration) {
+	if !tac.setReason(err) {
+		return
+	}
+	tac.stopping.Store(true)
+	if tac.iterations.Load() == 0 {
+		tac.cancel()
+		return
+	}
+	time.AfterFunc(gracePeriod, tac.cancel)
+}
+
+// setReason returns whether the reason was set, i.e. whether the test run
+// wasn't already aborted.
+func (tac *testAbortController) setReason(err error) bool {
 	tac.lock.Lock()
 	defer tac.lock.Unlock()
 	if tac.reason != nil {
@@ -29,10 +57,25 @@ func (tac *testAbortController) abort(err error) {
 			"test abort with reason '%s' was attempted when the test was already aborted due to '%s'",
 			err.Error(), tac.reason.Error(),
 		)
-		return
+		return false
 	}
 	tac.reason = err
-	tac.cancel()
+	return true
+}
+
+func (tac *testAbortController) startIteration() bool {
+	tac.iterations.Add(1)
+	if tac.stopping.Load() {
+		tac.endIteration()
+		return false
+	}
+	return true
+}
+
+func (tac *testAbortController) endIteration() {
+	if tac.iterations.Add(-1) == 0 && tac.stopping.Load() {
+		tac.cancel()
+	}
 }
 
 func (tac *testAbortController) getReason() error {
@@ -71,6 +114,36 @@ func AbortTestRun(ctx context.Context, err error) bool {
 	return false
 }
 
+// StopTestRun will stop the test run gracefully with the given reason, if the
+// provided context is actually a TestRuncontext or a child of one: no new
+// iterations are started, and the test run context is cancelled once the
+// iterations in progress are done, or after the grace period.
+func StopTestRun(ctx context.Context, err error, gracePeriod time.Duration) bool {
+	if x := ctx.Value(testAbortKey{}); x != nil {
+		if v, ok := x.(*testAbortController); ok {
+			v.stop(err, gracePeriod)
+			return true
+		}
+	}
+	return false
+}
+
+// StartTestRunIteration accounts for an iteration being started in the
+// provided context, and returns the function to call once it's done. It
+// returns false if the test run is being stopped, in which case the iteration
+// shouldn't be started.
+func StartTestRunIteration(ctx context.Context) (done func(), ok bool) {
+	if x := ctx.Value(testAbortKey{}); x != nil {
+		if v, ok := x.(*testAbortController); ok {
+			if !v.startIteration() {
+				return nil, false
+			}
+			return v.endIteration, true
+		}
+	}
+	return func() {}, true
+}
+
 // GetCancelReasonIfTestAborted returns a reason the Context was cancelled, if it was
 // aborted with these functions. It will return nil if ctx is not an
 // TestRunContext (or its children) or if it was never aborted.
@@ -0,0 +1,48 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/testutils"
+)
+
+func TestStopTestRun(t *testing.T) {
+	t.Parallel()
+
+	ctx, _ := NewTestRunContext(context.Background(), testutils.NewLogger(t))
+	iterationDone, ok := StartTestRunIteration(ctx)
+	require.True(t, ok)
+
+	reason := errors.New("stopped")
+	require.True(t, StopTestRun(ctx, reason, time.Minute))
+	assert.Equal(t, reason, GetCancelReasonIfTestAborted(ctx))
+
+	// no new iterations are started, and the ones in progress can finish
+	_, ok = StartTestRunIteration(ctx)
+	assert.False(t, ok)
+	assert.NoError(t, ctx.Err())
+
+	iterationDone()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestStopTestRunGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	ctx, _ := NewTestRunContext(context.Background(), testutils.NewLogger(t))
+	_, ok := StartTestRunIteration(ctx)
+	require.True(t, ok)
+
+	require.True(t, StopTestRun(ctx, errors.New("stopped"), 10*time.Millisecond))
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the test run wasn't cancelled after the grace period")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || zos || windows)
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!zos,!windows
+
+package execution
+
+import (
+	"errors"
+	"time"
+)
+
+// processCPUTime isn't supported on this platform, so loadgen_cpu isn't
+// emitted, and the saturation only depends on the scheduling latencies.
+func processCPUTime() (time.Duration, error) {
+	return 0, errors.New("the CPU time of the process isn't available on this platform")
+}
@@ -0,0 +1,23 @@
+package execution
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPUTime returns the user and the kernel CPU time used by the process.
+func processCPUTime() (time.Duration, error) {
+	process, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return 0, err
+	}
+	var creation, exit, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(process, &creation, &exit, &kernel, &user); err != nil {
+		return 0, err
+	}
+	// the filetimes are in 100-nanosecond intervals
+	ticks := func(ft syscall.Filetime) uint64 {
+		return uint64(ft.HighDateTime)<<32 | uint64(ft.LowDateTime)
+	}
+	return time.Duration((ticks(kernel) + ticks(user)) * 100), nil
+}
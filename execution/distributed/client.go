@@ -0,0 +1,103 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Client is used by the agents to talk to the coordinator.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient returns a new Client for the coordinator at the given address,
+// which authenticates with the given token, unless it's empty. If the address
+// doesn't have a scheme, plain HTTP is used.
+func NewClient(address, token string) *Client {
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(address, "/"),
+		token:      token,
+		httpClient: &http.Client{},
+	}
+}
+
+// Register registers a new agent with the coordinator.
+func (c *Client) Register(ctx context.Context) (*RegisterResponse, error) {
+	resp := &RegisterResponse{}
+	if err := c.call(ctx, registerPath, struct{}{}, resp); err != nil {
+		return nil, fmt.Errorf("could not register with the coordinator: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *Client) signal(ctx context.Context, req signalRequest) error {
+	return c.call(ctx, signalPath, req, &emptyResponse{})
+}
+
+func (c *Client) wait(ctx context.Context, eventID string) (*waitResponse, error) {
+	resp := &waitResponse{}
+	err := c.call(ctx, waitPath, waitRequest{EventID: eventID}, resp)
+	return resp, err
+}
+
+func (c *Client) getData(ctx context.Context, id string) (*getDataResponse, error) {
+	resp := &getDataResponse{}
+	err := c.call(ctx, getDataPath, getDataRequest{ID: id}, resp)
+	return resp, err
+}
+
+func (c *Client) setData(ctx context.Context, req setDataRequest) error {
+	return c.call(ctx, setDataPath, req, &emptyResponse{})
+}
+
+func (c *Client) sendMetrics(ctx context.Context, req metricsRequest) (*metricsResponse, error) {
+	resp := &metricsResponse{}
+	err := c.call(ctx, metricsPath, req, resp)
+	return resp, err
+}
+
+func (c *Client) done(ctx context.Context, req doneRequest) error {
+	return c.call(ctx, donePath, req, &emptyResponse{})
+}
+
+func (c *Client) call(ctx context.Context, path string, reqBody, respBody interface{}) error {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		errResp := errorResponse{}
+		if err := json.NewDecoder(res.Body).Decode(&errResp); err != nil || errResp.Error == "" {
+			return fmt.Errorf("unexpected response status %d from the coordinator", res.StatusCode)
+		}
+		return errors.New(errResp.Error)
+	}
+	return json.NewDecoder(res.Body).Decode(respBody)
+}
@@ -0,0 +1,87 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Controller implements the execution.Controller interface for the agents of
+// a distributed test run, by delegating all synchronization to the
+// coordinator.
+type Controller struct {
+	ctx        context.Context //nolint:containedctx
+	client     *Client
+	instanceID int
+	logger     logrus.FieldLogger
+}
+
+// NewController creates a new distributed execution Controller for the agent
+// with the given instance ID. The context is used for all requests to the
+// coordinator, so cancelling it unblocks any pending waits.
+func NewController(ctx context.Context, client *Client, instanceID int, logger logrus.FieldLogger) *Controller {
+	return &Controller{
+		ctx:        ctx,
+		client:     client,
+		instanceID: instanceID,
+		logger:     logger.WithField("component", "distributed-controller"),
+	}
+}
+
+// GetOrCreateData asks the coordinator for the data with the given ID. Only
+// the first agent to request it calls the callback, and its result is then
+// returned to all other agents.
+func (c *Controller) GetOrCreateData(id string, callback func() ([]byte, error)) ([]byte, error) {
+	c.logger.Debugf("Getting or creating data '%s'", id)
+	resp, err := c.client.getData(c.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Create {
+		if resp.Error != "" {
+			return nil, errors.New(resp.Error)
+		}
+		return resp.Data, nil
+	}
+
+	data, err := callback()
+	req := setDataRequest{ID: id, Data: data}
+	if err != nil {
+		req.Error = err.Error()
+	}
+	if serr := c.client.setData(c.ctx, req); serr != nil {
+		c.logger.WithError(serr).Errorf("Could not send the data '%s' to the coordinator", id)
+		if err == nil {
+			err = serr
+		}
+	}
+	return data, err
+}
+
+// Signal notifies the coordinator that this agent has reached the given event
+// ID, or that it has had an error.
+func (c *Controller) Signal(eventID string, err error) error {
+	c.logger.Debugf("Signalling event '%s'", eventID)
+	req := signalRequest{InstanceID: c.instanceID, EventID: eventID}
+	if err != nil {
+		req.Error = err.Error()
+	}
+	return c.client.signal(c.ctx, req)
+}
+
+// Subscribe returns a callback that waits until all agents have reached the
+// given event ID, or until one of them has signalled an error for it.
+func (c *Controller) Subscribe(eventID string) func() error {
+	return func() error {
+		c.logger.Debugf("Waiting for event '%s'", eventID)
+		resp, err := c.client.wait(c.ctx, eventID)
+		if err != nil {
+			return err
+		}
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,423 @@
+// Package distributed implements distributed k6 test runs, where a central
+// coordinator splits the test between multiple agents, synchronizes their
+// execution and aggregates all of their metrics.
+package distributed
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+// Coordinator is the central part of a distributed test run. It hands out the
+// test archive and the execution segments to the agents, implements the
+// synchronization primitives of the execution.Controller for them and
+// receives all of their metrics.
+type Coordinator struct {
+	logger        logrus.FieldLogger
+	archive       []byte
+	instanceCount int
+	token         string
+	sequence      lib.ExecutionSegmentSequence
+	registry      *metrics.Registry
+	samples       chan<- metrics.SampleContainer
+
+	mu          sync.Mutex
+	registered  int
+	startTime   time.Time
+	events      map[string]*eventState
+	data        map[string]*dataState
+	finished    map[int]struct{}
+	instanceErr error
+	allFinished chan struct{}
+	abortErr    error
+	aborted     chan struct{}
+
+	// samplesMu guards the samples channel, so it can be safely closed after
+	// Close() has returned.
+	samplesMu     sync.RWMutex
+	samplesClosed bool
+}
+
+type eventState struct {
+	reached map[int]struct{}
+	err     string
+	done    chan struct{}
+}
+
+type dataState struct {
+	data []byte
+	err  string
+	done chan struct{}
+}
+
+// NewCoordinator returns a new Coordinator that splits the test from the given
+// archive between instanceCount agents, which have to authenticate with the
+// given token, unless it's empty. The received metric samples are registered
+// in the given registry and sent to the samples channel.
+func NewCoordinator(
+	archive []byte, instanceCount int, token string, registry *metrics.Registry,
+	samples chan<- metrics.SampleContainer, logger logrus.FieldLogger,
+) (*Coordinator, error) {
+	if instanceCount < 1 {
+		return nil, fmt.Errorf("the number of instances should be at least 1, but it is %d", instanceCount)
+	}
+
+	segments := make([]*lib.ExecutionSegment, 0, instanceCount)
+	for i := 0; i < instanceCount; i++ {
+		segment, err := lib.NewExecutionSegment(
+			big.NewRat(int64(i), int64(instanceCount)), big.NewRat(int64(i+1), int64(instanceCount)),
+		)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+	sequence, err := lib.NewExecutionSegmentSequence(segments...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Coordinator{
+		logger:        logger.WithField("component", "coordinator"),
+		archive:       archive,
+		instanceCount: instanceCount,
+		token:         token,
+		sequence:      sequence,
+		registry:      registry,
+		samples:       samples,
+		events:        make(map[string]*eventState),
+		data:          make(map[string]*dataState),
+		finished:      make(map[int]struct{}),
+		allFinished:   make(chan struct{}),
+		aborted:       make(chan struct{}),
+	}, nil
+}
+
+// Handler returns the HTTP handler with all of the endpoints the agents use,
+// which reject the requests without the coordinator's token.
+func (c *Coordinator) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(registerPath, handle(c.register))
+	mux.HandleFunc(signalPath, handle(c.signal))
+	mux.HandleFunc(waitPath, handle(c.wait))
+	mux.HandleFunc(getDataPath, handle(c.getData))
+	mux.HandleFunc(setDataPath, handle(c.setData))
+	mux.HandleFunc(metricsPath, handle(c.receiveMetrics))
+	mux.HandleFunc(donePath, handle(c.done))
+	return c.authenticate(mux)
+}
+
+// authenticate wraps the handler so that it only serves the requests bearing
+// the coordinator's token, if it has one, as they get the test archive and its
+// environment variables.
+func (c *Coordinator) authenticate(next http.Handler) http.Handler {
+	if c.token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + c.token)
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(rw).Encode(errorResponse{Error: "invalid coordinator token"})
+			return
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// Done returns a channel that is closed when all of the agents have finished
+// running their part of the test and have sent all of their metrics.
+func (c *Coordinator) Done() <-chan struct{} {
+	return c.allFinished
+}
+
+// InstanceError returns the first error any of the agents finished with.
+func (c *Coordinator) InstanceError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.instanceErr
+}
+
+// Abort aborts the test run on all agents with the given reason. Only the
+// first call has any effect.
+func (c *Coordinator) Abort(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.abortErr != nil {
+		return
+	}
+	c.logger.WithError(err).Debug("Aborting the test run on all instances")
+	c.abortErr = err
+	close(c.aborted)
+}
+
+// Close makes the coordinator reject any further metrics. After it returns,
+// the samples channel can be safely closed.
+func (c *Coordinator) Close() {
+	c.samplesMu.Lock()
+	defer c.samplesMu.Unlock()
+	c.samplesClosed = true
+}
+
+// AbortError returns the error the test run was aborted with, if any.
+func (c *Coordinator) AbortError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.abortErr
+}
+
+// GetCurrentTestRunDuration returns how much time has passed since all of the
+// agents have registered, or 0 if some of them still haven't.
+func (c *Coordinator) GetCurrentTestRunDuration() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.startTime.IsZero() {
+		return 0
+	}
+	return time.Since(c.startTime)
+}
+
+func (c *Coordinator) register(_ context.Context, _ *struct{}) (*RegisterResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.registered >= c.instanceCount {
+		return nil, fmt.Errorf("all %d instances have already registered", c.instanceCount)
+	}
+
+	id := c.registered
+	c.registered++
+	if c.registered == c.instanceCount {
+		c.startTime = time.Now()
+	}
+	c.logger.Infof("Instance %d of %d has registered", c.registered, c.instanceCount)
+
+	return &RegisterResponse{
+		InstanceID:               id,
+		Archive:                  c.archive,
+		ExecutionSegment:         c.sequence[id].String(),
+		ExecutionSegmentSequence: c.sequence.String(),
+	}, nil
+}
+
+// checkInstance returns an error if the given instance ID wasn't handed out to
+// an agent, it has to be called with the lock held.
+func (c *Coordinator) checkInstance(instanceID int) error {
+	if instanceID < 0 || instanceID >= c.registered {
+		return fmt.Errorf("instance %d hasn't registered", instanceID)
+	}
+	return nil
+}
+
+// getEvent returns the state of the given event, it has to be called with the
+// lock held.
+func (c *Coordinator) getEvent(eventID string) *eventState {
+	ev, ok := c.events[eventID]
+	if !ok {
+		ev = &eventState{reached: make(map[int]struct{}), done: make(chan struct{})}
+		c.events[eventID] = ev
+	}
+	return ev
+}
+
+func (c *Coordinator) signal(_ context.Context, req *signalRequest) (*emptyResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkInstance(req.InstanceID); err != nil {
+		return nil, err
+	}
+
+	ev := c.getEvent(req.EventID)
+	select {
+	case <-ev.done:
+		return &emptyResponse{}, nil // already released
+	default:
+	}
+
+	if req.Error != "" {
+		c.logger.Debugf("Instance %d signalled an error for '%s': %s", req.InstanceID, req.EventID, req.Error)
+		ev.err = req.Error
+		close(ev.done)
+		return &emptyResponse{}, nil
+	}
+
+	ev.reached[req.InstanceID] = struct{}{}
+	if len(ev.reached) == c.instanceCount {
+		c.logger.Debugf("All instances have reached '%s'", req.EventID)
+		close(ev.done)
+	}
+	return &emptyResponse{}, nil
+}
+
+func (c *Coordinator) wait(ctx context.Context, req *waitRequest) (*waitResponse, error) {
+	c.mu.Lock()
+	ev := c.getEvent(req.EventID)
+	c.mu.Unlock()
+
+	select {
+	case <-ev.done:
+		return &waitResponse{Error: ev.err}, nil
+	case <-c.aborted:
+		return &waitResponse{Error: c.AbortError().Error()}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Coordinator) getData(ctx context.Context, req *getDataRequest) (*getDataResponse, error) {
+	c.mu.Lock()
+	ds, ok := c.data[req.ID]
+	if !ok {
+		c.data[req.ID] = &dataState{done: make(chan struct{})}
+		c.mu.Unlock()
+		return &getDataResponse{Create: true}, nil
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-ds.done:
+		return &getDataResponse{Data: ds.data, Error: ds.err}, nil
+	case <-c.aborted:
+		return &getDataResponse{Error: c.AbortError().Error()}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Coordinator) setData(_ context.Context, req *setDataRequest) (*emptyResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ds, ok := c.data[req.ID]
+	if !ok {
+		return nil, fmt.Errorf("data with ID '%s' wasn't requested", req.ID)
+	}
+	select {
+	case <-ds.done:
+		return nil, fmt.Errorf("data with ID '%s' was already set", req.ID)
+	default:
+	}
+	ds.data, ds.err = req.Data, req.Error
+	close(ds.done)
+	return &emptyResponse{}, nil
+}
+
+func (c *Coordinator) receiveMetrics(_ context.Context, req *metricsRequest) (*metricsResponse, error) {
+	// The samples channel is closed after all of the instances are done, so
+	// we can't accept any metrics after that.
+	c.mu.Lock()
+	instanceErr := c.checkInstance(req.InstanceID)
+	_, finished := c.finished[req.InstanceID]
+	abortErr := c.abortErr
+	c.mu.Unlock()
+	if instanceErr != nil {
+		return nil, instanceErr
+	}
+	if finished {
+		return nil, fmt.Errorf("instance %d has already finished", req.InstanceID)
+	}
+
+	container := make(metrics.Samples, 0, len(req.Samples))
+	for _, s := range req.Samples {
+		metric, err := c.registry.NewMetric(s.Metric, s.Type, s.Contains)
+		if err != nil {
+			c.logger.WithError(err).Warnf("Dropping a sample of metric '%s' from instance %d", s.Metric, req.InstanceID)
+			continue
+		}
+		container = append(container, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: metric,
+				Tags:   c.registry.RootTagSet().WithTagsFromMap(s.Tags),
+			},
+			Time:     s.Time,
+			Value:    s.Value,
+			Metadata: s.Metadata,
+		})
+	}
+	if len(container) > 0 {
+		c.samplesMu.RLock()
+		if c.samplesClosed {
+			c.samplesMu.RUnlock()
+			return nil, errors.New("the coordinator doesn't accept any more metrics")
+		}
+		c.samples <- container
+		c.samplesMu.RUnlock()
+	}
+
+	resp := &metricsResponse{}
+	if abortErr != nil {
+		resp.Abort = abortErr.Error()
+	}
+	return resp, nil
+}
+
+func (c *Coordinator) done(_ context.Context, req *doneRequest) (*emptyResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkInstance(req.InstanceID); err != nil {
+		return nil, err
+	}
+	if _, ok := c.finished[req.InstanceID]; ok {
+		return &emptyResponse{}, nil
+	}
+	c.finished[req.InstanceID] = struct{}{}
+	if req.Error != "" {
+		c.logger.Debugf("Instance %d finished with an error: %s", req.InstanceID, req.Error)
+		if c.instanceErr == nil {
+			c.instanceErr = fmt.Errorf("instance %d finished with an error: %s", req.InstanceID, req.Error)
+		}
+	}
+	c.logger.Infof("Instance %d has finished", req.InstanceID)
+
+	if len(c.finished) == c.instanceCount {
+		close(c.allFinished)
+	}
+	return &emptyResponse{}, nil
+}
+
+// handle wraps a typed coordinator method in an HTTP handler that decodes the
+// JSON request and encodes the JSON response.
+func handle[Req, Resp any](fn func(context.Context, *Req) (*Resp, error)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			_ = json.NewEncoder(rw).Encode(errorResponse{Error: "only POST requests are supported"})
+			return
+		}
+
+		req := new(Req)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(rw).Encode(errorResponse{Error: err.Error()})
+			return
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			status := http.StatusConflict
+			if errors.Is(err, context.Canceled) {
+				status = http.StatusServiceUnavailable
+			}
+			rw.WriteHeader(status)
+			_ = json.NewEncoder(rw).Encode(errorResponse{Error: err.Error()})
+			return
+		}
+		_ = json.NewEncoder(rw).Encode(resp)
+	}
+}
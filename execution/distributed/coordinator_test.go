@@ -0,0 +1,230 @@
+package distributed
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/execution"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+)
+
+func newTestCoordinator(
+	t *testing.T, instanceCount int,
+) (*Coordinator, *Client, chan metrics.SampleContainer, *metrics.Registry) {
+	t.Helper()
+
+	registry := metrics.NewRegistry()
+	samples := make(chan metrics.SampleContainer, 100)
+	coordinator, err := NewCoordinator(
+		[]byte("archive"), instanceCount, "token", registry, samples, testutils.NewLogger(t),
+	)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(coordinator.Handler())
+	t.Cleanup(srv.Close)
+
+	return coordinator, NewClient(srv.URL, "token"), samples, registry
+}
+
+// registerInstances registers the given number of instances with the
+// coordinator, as the agents would do.
+func registerInstances(t *testing.T, client *Client, count int) {
+	t.Helper()
+
+	for i := 0; i < count; i++ {
+		_, err := client.Register(context.Background())
+		require.NoError(t, err)
+	}
+}
+
+func TestCoordinatorRegister(t *testing.T) {
+	t.Parallel()
+
+	_, client, _, _ := newTestCoordinator(t, 3)
+
+	expSegments := []string{"0:1/3", "1/3:2/3", "2/3:1"}
+	for i, expSegment := range expSegments {
+		resp, err := client.Register(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, i, resp.InstanceID)
+		assert.Equal(t, []byte("archive"), resp.Archive)
+		assert.Equal(t, expSegment, resp.ExecutionSegment)
+		assert.Equal(t, "0,1/3,2/3,1", resp.ExecutionSegmentSequence)
+	}
+
+	_, err := client.Register(context.Background())
+	assert.ErrorContains(t, err, "all 3 instances have already registered")
+}
+
+func TestCoordinatorAuthentication(t *testing.T) {
+	t.Parallel()
+
+	_, client, _, _ := newTestCoordinator(t, 1)
+
+	for _, token := range []string{"", "wrong"} {
+		_, err := NewClient(client.baseURL, token).Register(context.Background())
+		assert.ErrorContains(t, err, "invalid coordinator token", "token %q", token)
+	}
+
+	_, err := client.Register(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestCoordinatorUnknownInstance(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client, _, _ := newTestCoordinator(t, 2)
+	registerInstances(t, client, 1)
+
+	ctx := context.Background()
+	for _, id := range []int{-1, 1, 2} {
+		assert.ErrorContains(t, client.signal(ctx, signalRequest{InstanceID: id, EventID: "event"}), "hasn't registered")
+		_, err := client.sendMetrics(ctx, metricsRequest{InstanceID: id})
+		assert.ErrorContains(t, err, "hasn't registered")
+		assert.ErrorContains(t, client.done(ctx, doneRequest{InstanceID: id}), "hasn't registered")
+	}
+
+	assert.NoError(t, client.signal(ctx, signalRequest{InstanceID: 0, EventID: "event"}))
+	_, err := client.sendMetrics(ctx, metricsRequest{InstanceID: 0})
+	assert.NoError(t, err)
+	assert.NoError(t, client.done(ctx, doneRequest{InstanceID: 0}))
+
+	select {
+	case <-coordinator.Done():
+		t.Error("the coordinator shouldn't be done before all instances have finished")
+	default:
+	}
+}
+
+func TestControllerSignalAndWait(t *testing.T) {
+	t.Parallel()
+
+	_, client, _, _ := newTestCoordinator(t, 2)
+	registerInstances(t, client, 2)
+	logger := testutils.NewLogger(t)
+	first := NewController(context.Background(), client, 0, logger)
+	second := NewController(context.Background(), client, 1, logger)
+
+	var released int64
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, execution.SignalAndWait(first, "barrier"))
+		atomic.AddInt64(&released, 1)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&released), "released before all instances reached the barrier")
+
+	require.NoError(t, execution.SignalAndWait(second, "barrier"))
+	wg.Wait()
+	assert.Equal(t, int64(1), atomic.LoadInt64(&released))
+
+	// an error from any instance releases all of them with it
+	wait := first.Subscribe("failing")
+	require.NoError(t, second.Signal("failing", errors.New("init failed")))
+	assert.EqualError(t, wait(), "init failed")
+}
+
+func TestControllerGetOrCreateData(t *testing.T) {
+	t.Parallel()
+
+	_, client, _, _ := newTestCoordinator(t, 3)
+	logger := testutils.NewLogger(t)
+
+	var calls int64
+	results := make([][]byte, 3)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			controller := NewController(context.Background(), client, i, logger)
+			data, err := controller.GetOrCreateData("setup", func() ([]byte, error) {
+				atomic.AddInt64(&calls, 1)
+				time.Sleep(100 * time.Millisecond)
+				return []byte("setup data"), nil
+			})
+			assert.NoError(t, err)
+			results[i] = data
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+	for _, data := range results {
+		assert.Equal(t, []byte("setup data"), data)
+	}
+
+	controller := NewController(context.Background(), client, 0, logger)
+	_, err := controller.GetOrCreateData("failing", func() ([]byte, error) {
+		return nil, errors.New("setup failed")
+	})
+	assert.EqualError(t, err, "setup failed")
+	_, err = controller.GetOrCreateData("failing", func() ([]byte, error) {
+		panic("shouldn't be called")
+	})
+	assert.EqualError(t, err, "setup failed")
+}
+
+func TestOutputSendsMetrics(t *testing.T) {
+	t.Parallel()
+
+	coordinator, client, samples, registry := newTestCoordinator(t, 1)
+	registerInstances(t, client, 1)
+
+	agentRegistry := metrics.NewRegistry()
+	metric := agentRegistry.MustNewMetric("my_counter", metrics.Counter)
+	now := time.Now()
+
+	out := NewOutput(client, 0, testutils.NewLogger(t))
+	var stopErr error
+	out.SetTestRunStopCallback(func(err error) { stopErr = err })
+	require.NoError(t, out.Start())
+
+	out.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: metric,
+			Tags:   agentRegistry.RootTagSet().With("scenario", "default"),
+		},
+		Time:  now,
+		Value: 5,
+	}})
+	out.flushMetrics()
+
+	received := metrics.GetBufferedSamples(samples)
+	require.Len(t, received, 1)
+	sample := received[0].GetSamples()[0]
+	assert.Same(t, registry.Get("my_counter"), sample.Metric)
+	assert.Equal(t, map[string]string{"scenario": "default"}, sample.Tags.Map())
+	assert.True(t, now.Equal(sample.Time))
+	assert.Equal(t, float64(5), sample.Value)
+
+	coordinator.Abort(errors.New("thresholds crossed"))
+	out.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: metric, Tags: agentRegistry.RootTagSet()},
+		Time:       now,
+		Value:      1,
+	}})
+	out.flushMetrics()
+	assert.ErrorContains(t, stopErr, "test run was aborted by the coordinator: thresholds crossed")
+
+	require.NoError(t, out.StopWithTestError(nil))
+	select {
+	case <-coordinator.Done():
+	default:
+		t.Error("the coordinator should be done after all instances have stopped")
+	}
+	assert.NoError(t, coordinator.InstanceError())
+}
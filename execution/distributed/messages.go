@@ -0,0 +1,92 @@
+package distributed
+
+import (
+	"time"
+
+	"go.k6.io/k6/metrics"
+)
+
+// The HTTP endpoints of the coordinator, all of them accept and return JSON.
+const (
+	registerPath = "/v1/register"
+	signalPath   = "/v1/signal"
+	waitPath     = "/v1/wait"
+	getDataPath  = "/v1/data/get"
+	setDataPath  = "/v1/data/set"
+	metricsPath  = "/v1/metrics"
+	donePath     = "/v1/done"
+)
+
+// RegisterResponse contains everything an agent needs to run its part of the
+// test: the test archive and the execution segment it should run.
+type RegisterResponse struct {
+	InstanceID               int    `json:"instanceID"`
+	Archive                  []byte `json:"archive"`
+	ExecutionSegment         string `json:"executionSegment"`
+	ExecutionSegmentSequence string `json:"executionSegmentSequence"`
+}
+
+type signalRequest struct {
+	InstanceID int    `json:"instanceID"`
+	EventID    string `json:"eventID"`
+	Error      string `json:"error,omitempty"`
+}
+
+type waitRequest struct {
+	EventID string `json:"eventID"`
+}
+
+type waitResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+type getDataRequest struct {
+	ID string `json:"id"`
+}
+
+type getDataResponse struct {
+	// Create is true if the caller is the first one to request the data and
+	// it has to create it and then send it back with a setDataRequest.
+	Create bool   `json:"create,omitempty"`
+	Data   []byte `json:"data,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type setDataRequest struct {
+	ID    string `json:"id"`
+	Data  []byte `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// sample is the wire representation of a single metrics.Sample.
+type sample struct {
+	Metric   string             `json:"metric"`
+	Type     metrics.MetricType `json:"type"`
+	Contains metrics.ValueType  `json:"contains"`
+	Tags     map[string]string  `json:"tags,omitempty"`
+	Metadata map[string]string  `json:"metadata,omitempty"`
+	Time     time.Time          `json:"time"`
+	Value    float64            `json:"value"`
+}
+
+type metricsRequest struct {
+	InstanceID int      `json:"instanceID"`
+	Samples    []sample `json:"samples"`
+}
+
+type metricsResponse struct {
+	// Abort contains the reason if the coordinator has aborted the test run,
+	// e.g. because of a threshold with abortOnFail.
+	Abort string `json:"abort,omitempty"`
+}
+
+type doneRequest struct {
+	InstanceID int    `json:"instanceID"`
+	Error      string `json:"error,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type emptyResponse struct{}
@@ -0,0 +1,120 @@
+package distributed
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/output"
+)
+
+const flushPeriod = 1 * time.Second
+
+// Output forwards all metric samples of an agent to the coordinator, which
+// aggregates them and evaluates the thresholds for the whole test run.
+type Output struct {
+	output.SampleBuffer
+
+	client          *Client
+	instanceID      int
+	logger          logrus.FieldLogger
+	periodicFlusher *output.PeriodicFlusher
+
+	abortOnce       sync.Once
+	testRunStopFunc func(error)
+}
+
+var (
+	_ output.WithStopWithTestError = &Output{}
+	_ output.WithTestRunStop       = &Output{}
+)
+
+// NewOutput returns a new Output for the agent with the given instance ID.
+func NewOutput(client *Client, instanceID int, logger logrus.FieldLogger) *Output {
+	return &Output{
+		client:     client,
+		instanceID: instanceID,
+		logger:     logger.WithField("output", "distributed"),
+	}
+}
+
+// Description returns a human-readable description of the output.
+func (o *Output) Description() string {
+	return fmt.Sprintf("distributed (coordinator %s)", o.client.baseURL)
+}
+
+// SetTestRunStopCallback receives the function that is called when the
+// coordinator has aborted the test run.
+func (o *Output) SetTestRunStopCallback(stopFunc func(error)) {
+	o.testRunStopFunc = stopFunc
+}
+
+// Start starts the goroutine that periodically sends the metrics.
+func (o *Output) Start() error {
+	pf, err := output.NewPeriodicFlusher(flushPeriod, o.flushMetrics)
+	if err != nil {
+		return err
+	}
+	o.periodicFlusher = pf
+	return nil
+}
+
+// Stop sends the remaining metrics and notifies the coordinator that the agent
+// has finished.
+func (o *Output) Stop() error {
+	return o.StopWithTestError(nil)
+}
+
+// StopWithTestError sends the remaining metrics and notifies the coordinator
+// that the agent has finished with the given error.
+func (o *Output) StopWithTestError(testRunErr error) error {
+	o.periodicFlusher.Stop()
+
+	req := doneRequest{InstanceID: o.instanceID}
+	if testRunErr != nil {
+		req.Error = testRunErr.Error()
+	}
+	return o.client.done(context.Background(), req)
+}
+
+func (o *Output) flushMetrics() {
+	containers := o.GetBufferedSamples()
+	if len(containers) == 0 {
+		return
+	}
+
+	var samples []sample
+	for _, sc := range containers {
+		for _, s := range sc.GetSamples() {
+			samples = append(samples, sample{
+				Metric:   s.Metric.Name,
+				Type:     s.Metric.Type,
+				Contains: s.Metric.Contains,
+				Tags:     s.Tags.Map(),
+				Metadata: s.Metadata,
+				Time:     s.Time,
+				Value:    s.Value,
+			})
+		}
+	}
+
+	resp, err := o.client.sendMetrics(context.Background(), metricsRequest{
+		InstanceID: o.instanceID,
+		Samples:    samples,
+	})
+	if err != nil {
+		o.logger.WithError(err).Error("Could not send the metrics to the coordinator")
+		return
+	}
+	if resp.Abort != "" && o.testRunStopFunc != nil {
+		o.abortOnce.Do(func() {
+			o.testRunStopFunc(errext.WithAbortReasonIfNone(
+				fmt.Errorf("test run was aborted by the coordinator: %s", resp.Abort), errext.AbortedByOutput,
+			))
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package execution
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	runtimemetrics "runtime/metrics"
+	"sync"
+	"time"
+
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	heapObjectsMetric    = "/memory/classes/heap/objects:bytes"
+	schedLatenciesMetric = "/sched/latencies:seconds"
+
+	// the load generator is saturated when it uses this much of the CPUs it
+	// can use, or when its goroutines wait this long to be scheduled
+	saturatedCPU          = 0.9
+	saturatedSchedLatency = 50 * time.Millisecond
+)
+
+// loadGeneratorMonitor samples the resources used by the k6 process itself,
+// so the users can tell when k6, rather than the system under test, is the
+// bottleneck.
+type loadGeneratorMonitor struct {
+	builtinMetrics *metrics.BuiltinMetrics
+	tags           *metrics.TagSet
+
+	lastTime           time.Time
+	lastCPU            time.Duration
+	lastNumGC          int64
+	lastSchedLatencies []uint64
+	runtimeSamples     []runtimemetrics.Sample
+}
+
+func newLoadGeneratorMonitor(builtinMetrics *metrics.BuiltinMetrics, tags *metrics.TagSet) *loadGeneratorMonitor {
+	m := &loadGeneratorMonitor{
+		builtinMetrics: builtinMetrics,
+		tags:           tags,
+		runtimeSamples: []runtimemetrics.Sample{{Name: heapObjectsMetric}, {Name: schedLatenciesMetric}},
+	}
+	m.lastTime = time.Now()
+	m.lastCPU, _ = processCPUTime()
+	var gcStats debug.GCStats
+	debug.ReadGCStats(&gcStats)
+	m.lastNumGC = gcStats.NumGC
+	runtimemetrics.Read(m.runtimeSamples)
+	m.lastSchedLatencies = m.schedLatencies(nil)
+	return m
+}
+
+// sample returns the samples of the resources used since the last sample.
+func (m *loadGeneratorMonitor) sample(now time.Time) []metrics.Sample {
+	newSample := func(metric *metrics.Metric, value float64) metrics.Sample {
+		return metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: metric, Tags: m.tags},
+			Time:       now,
+			Value:      value,
+		}
+	}
+	var samples []metrics.Sample
+
+	var cpu float64
+	if cpuTime, err := processCPUTime(); err == nil {
+		elapsed := now.Sub(m.lastTime) * time.Duration(runtime.GOMAXPROCS(0))
+		if elapsed > 0 {
+			cpu = float64(cpuTime-m.lastCPU) / float64(elapsed)
+			samples = append(samples, newSample(m.builtinMetrics.LoadGeneratorCPU, cpu))
+		}
+		m.lastCPU = cpuTime
+	}
+	m.lastTime = now
+
+	runtimemetrics.Read(m.runtimeSamples)
+	if heap := m.runtimeSamples[0].Value; heap.Kind() == runtimemetrics.KindUint64 {
+		samples = append(samples, newSample(m.builtinMetrics.LoadGeneratorHeap, float64(heap.Uint64())))
+	}
+
+	var gcStats debug.GCStats
+	debug.ReadGCStats(&gcStats)
+	// the pauses are the most recent first, and only the last ones are kept
+	newGCs := gcStats.NumGC - m.lastNumGC
+	for i := int64(0); i < newGCs && i < int64(len(gcStats.Pause)); i++ {
+		samples = append(samples, newSample(m.builtinMetrics.LoadGeneratorGCPause,
+			metrics.D(gcStats.Pause[i])))
+	}
+	m.lastNumGC = gcStats.NumGC
+
+	var schedLatency time.Duration
+	m.lastSchedLatencies = m.schedLatencies(&schedLatency)
+
+	saturated := cpu >= saturatedCPU || schedLatency >= saturatedSchedLatency
+	samples = append(samples, newSample(m.builtinMetrics.LoadGeneratorSaturated, metrics.B(saturated)))
+	return samples
+}
+
+// schedLatencies returns a copy of the counts of the last read histogram of
+// the scheduling latencies, which the runtime reuses between the reads, and
+// sets the 99th percentile of the latencies since the previous read, if p99
+// isn't nil.
+func (m *loadGeneratorMonitor) schedLatencies(p99 *time.Duration) []uint64 {
+	latencies := m.runtimeSamples[1].Value
+	if latencies.Kind() != runtimemetrics.KindFloat64Histogram {
+		return nil
+	}
+	histogram := latencies.Float64Histogram()
+	if p99 != nil {
+		*p99 = histogramDeltaP99(m.lastSchedLatencies, histogram)
+	}
+	return append([]uint64(nil), histogram.Counts...)
+}
+
+// histogramDeltaP99 returns the 99th percentile of the values added to the
+// histogram since it had the previous counts, by the lower bound of their
+// bucket.
+func histogramDeltaP99(prevCounts []uint64, cur *runtimemetrics.Float64Histogram) time.Duration {
+	if len(prevCounts) != len(cur.Counts) {
+		return 0
+	}
+	var total uint64
+	for i := range cur.Counts {
+		total += cur.Counts[i] - prevCounts[i]
+	}
+	if total == 0 {
+		return 0
+	}
+	target := total - total/100
+	var seen uint64
+	for i := range cur.Counts {
+		seen += cur.Counts[i] - prevCounts[i]
+		if seen >= target {
+			return time.Duration(cur.Buckets[i] * float64(time.Second))
+		}
+	}
+	return 0
+}
+
+// emitLoadGeneratorMetrics starts the emission of the metrics of the
+// resources used by the load generator, every second, and returns a function
+// waiting for it to stop once the context is done.
+func (e *Scheduler) emitLoadGeneratorMetrics(ctx context.Context, out chan<- metrics.SampleContainer) func() {
+	e.state.Test.Logger.Debug("Starting emission of the load generator metrics...")
+	tags := e.state.Test.RunTags
+	monitor := newLoadGeneratorMonitor(e.state.Test.BuiltinMetrics, tags)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	ticker := time.NewTicker(1 * time.Second)
+	go func() {
+		defer func() {
+			ticker.Stop()
+			e.state.Test.Logger.Debug("Metrics emission of the load generator metrics stopped")
+			wg.Done()
+		}()
+
+		for {
+			select {
+			case t := <-ticker.C:
+				metrics.PushIfNotDone(ctx, out, metrics.ConnectedSamples{
+					Samples: monitor.sample(t),
+					Tags:    tags,
+					Time:    t,
+				})
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return wg.Wait
+}
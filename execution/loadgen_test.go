@@ -0,0 +1,44 @@
+package execution
+
+import (
+	runtimemetrics "runtime/metrics"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/metrics"
+)
+
+func TestLoadGeneratorMonitor(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	builtinMetrics := metrics.RegisterBuiltinMetrics(registry)
+	monitor := newLoadGeneratorMonitor(builtinMetrics, registry.RootTagSet())
+
+	samples := monitor.sample(time.Now().Add(time.Second))
+	values := make(map[string]float64, len(samples))
+	for _, sample := range samples {
+		values[sample.Metric.Name] = sample.Value
+	}
+	require.Contains(t, values, metrics.LoadGeneratorCPUName)
+	assert.GreaterOrEqual(t, values[metrics.LoadGeneratorCPUName], float64(0))
+	assert.Greater(t, values[metrics.LoadGeneratorHeapName], float64(0))
+	require.Contains(t, values, metrics.LoadGeneratorSaturatedName)
+}
+
+func TestHistogramDeltaP99(t *testing.T) {
+	t.Parallel()
+
+	cur := &runtimemetrics.Float64Histogram{
+		Counts:  []uint64{10, 1000, 20},
+		Buckets: []float64{0, 0.001, 0.05, 1},
+	}
+	assert.Equal(t, time.Duration(0), histogramDeltaP99(nil, cur))
+	assert.Equal(t, time.Duration(0), histogramDeltaP99([]uint64{10, 1000, 20}, cur))
+	// 990 of the new latencies are below 50ms, the rest above
+	assert.Equal(t, 50*time.Millisecond, histogramDeltaP99([]uint64{10, 10, 0}, cur))
+	assert.Equal(t, time.Millisecond, histogramDeltaP99([]uint64{10, 0, 20}, cur))
+}
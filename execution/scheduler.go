@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -324,12 +325,68 @@ func (e *Scheduler) initVUsAndExecutors(ctx context.Context, samplesOut chan<- m
 	return nil
 }
 
+// scenarioDoneEventID returns the ID of the event signaled by the instances
+// once they're done with the scenario of the provided name.
+func scenarioDoneEventID(name string) string {
+	return "scenario-done-" + name
+}
+
+// trackScenariosDone returns the channels closed once the scenarios the others
+// depend on, or with a teardown function, are done on all instances, and the
+// channels to close once they're done on this one, for all of the scenarios.
+func (e *Scheduler) trackScenariosDone(runCtx context.Context) (done, finished map[string]chan struct{}) {
+	logger := e.state.Test.Logger.WithField("phase", "execution-scheduler-run")
+
+	finished = make(map[string]chan struct{}, len(e.executorConfigs))
+	for _, config := range e.executorConfigs {
+		finished[config.GetName()] = make(chan struct{})
+	}
+
+	done = make(map[string]chan struct{})
+	for _, config := range e.executorConfigs {
+		tracked := config.GetDependsOn()
+		if config.GetTeardown() != "" && !e.state.Test.Options.NoTeardown.Bool {
+			tracked = append([]string{config.GetName()}, tracked...)
+		}
+		for _, dep := range tracked {
+			if _, ok := done[dep]; ok {
+				continue
+			}
+			depDone := make(chan struct{})
+			done[dep] = depDone
+			depFinished := finished[dep]
+			wait := e.controller.Subscribe(scenarioDoneEventID(dep))
+			go func(dep string) {
+				select {
+				case <-depFinished:
+				case <-runCtx.Done():
+					return
+				}
+				err := e.controller.Signal(scenarioDoneEventID(dep), nil)
+				if err == nil {
+					err = wait()
+				}
+				if err != nil {
+					logger.WithError(err).Warnf("Couldn't wait for the other instances to be done with %s", dep)
+				}
+				close(depDone)
+			}(dep)
+		}
+	}
+
+	return done, finished
+}
+
 // runExecutor gets called by the public Run() method once per configured
-// executor, each time in a new goroutine. It is responsible for waiting out the
-// configured startTime for the specific executor and then running its Run()
-// method.
+// executor, each time in a new goroutine. It is responsible for waiting for
+// the scenarios the executor depends on to be done, waiting out the configured
+// startTime for the specific executor and then running its Run() method,
+// between the setup and teardown functions of the scenario, if any.
+//
+//nolint:funlen
 func (e *Scheduler) runExecutor(
-	runCtx context.Context, runResults chan<- error, engineOut chan<- metrics.SampleContainer, executor lib.Executor,
+	globalCtx, runCtx context.Context, runResults chan<- error, engineOut chan<- metrics.SampleContainer,
+	executor lib.Executor, scenariosDone map[string]chan struct{}, finished chan<- struct{},
 ) {
 	executorConfig := executor.GetConfig()
 	executorStartTime := executorConfig.GetStartTime()
@@ -340,6 +397,27 @@ func (e *Scheduler) runExecutor(
 	})
 	executorProgress := executor.GetProgress()
 
+	// Check if we have to wait for other scenarios to be done first, the
+	// start time then being relative to their end
+	if deps := executorConfig.GetDependsOn(); len(deps) > 0 {
+		executorProgress.Modify(
+			pb.WithStatus(pb.Waiting),
+			pb.WithConstProgress(0, "waiting for "+strings.Join(deps, ", ")),
+		)
+
+		executorLogger.Debugf("Waiting for the scenarios the executor depends on...")
+		for _, dep := range deps {
+			select {
+			case <-runCtx.Done():
+				close(finished)
+				runResults <- nil // no error since executor hasn't started yet
+				return
+			case <-scenariosDone[dep]:
+				// continue
+			}
+		}
+	}
+
 	// Check if we have to wait before starting the actual executor execution
 	if executorStartTime > 0 {
 		startTime := time.Now()
@@ -354,6 +432,7 @@ func (e *Scheduler) runExecutor(
 		executorLogger.Debugf("Waiting for executor start time...")
 		select {
 		case <-runCtx.Done():
+			close(finished)
 			runResults <- nil // no error since executor hasn't started yet
 			return
 		case <-time.After(executorStartTime):
@@ -361,17 +440,66 @@ func (e *Scheduler) runExecutor(
 		}
 	}
 
+	name := executorConfig.GetName()
+	setupFn, teardownFn := executorConfig.GetSetup(), executorConfig.GetTeardown()
+	if e.state.Test.Options.NoSetup.Bool {
+		setupFn = ""
+	}
+	if e.state.Test.Options.NoTeardown.Bool {
+		teardownFn = ""
+	}
+
+	var setupData []byte
+	executorRunCtx := runCtx
+	if setupFn != "" {
+		executorProgress.Modify(pb.WithConstProgress(0, setupFn+"()"))
+		var err error
+		setupData, err = e.controller.GetOrCreateData("scenario-setup-"+name, func() ([]byte, error) {
+			return e.state.Test.Runner.ScenarioSetup(runCtx, engineOut, setupFn)
+		})
+		if err != nil {
+			executorLogger.WithField("error", err).Debugf("%s() aborted by error", setupFn)
+			close(finished)
+			runResults <- err
+			return
+		}
+		if len(setupData) == 0 {
+			setupData = nil // undefined, possibly received as empty data from another instance
+		}
+		executorRunCtx = lib.WithScenarioSetupData(runCtx, setupData)
+	}
+
 	executorProgress.Modify(
 		pb.WithStatus(pb.Running),
 		pb.WithConstProgress(0, "started"),
 	)
 	executorLogger.Debugf("Starting executor")
-	err := executor.Run(runCtx, engineOut) // executor should handle context cancel itself
+	err := executor.Run(executorRunCtx, engineOut) // executor should handle context cancel itself
 	if err == nil {
 		executorLogger.Debugf("Executor finished successfully")
 	} else {
 		executorLogger.WithField("error", err).Errorf("Executor error")
 	}
+	close(finished)
+
+	// The teardown is run once the scenario is done on all instances, with the
+	// global context, like the test teardown().
+	if teardownFn != "" {
+		select {
+		case <-scenariosDone[name]:
+		case <-runCtx.Done():
+		}
+		executorProgress.Modify(pb.WithConstProgress(1, teardownFn+"()"))
+		_, teardownErr := e.controller.GetOrCreateData("scenario-teardown-"+name, func() ([]byte, error) {
+			return nil, e.state.Test.Runner.ScenarioTeardown(globalCtx, engineOut, teardownFn, setupData)
+		})
+		if teardownErr != nil {
+			executorLogger.WithField("error", teardownErr).Debugf("%s() aborted by error", teardownFn)
+			if err == nil {
+				err = teardownErr
+			}
+		}
+	}
 	runResults <- err
 }
 
@@ -392,10 +520,15 @@ func (e *Scheduler) Init(
 
 	execSchedRunCtx, execSchedRunCancel := context.WithCancel(runCtx)
 	waitForVUsMetricPush := e.emitVUsAndVUsMax(execSchedRunCtx, samplesOut)
+	waitForLoadGeneratorMetricPush := func() {}
+	if e.state.Test.Options.LoadGeneratorMetrics.Bool {
+		waitForLoadGeneratorMetricPush = e.emitLoadGeneratorMetrics(execSchedRunCtx, samplesOut)
+	}
 	stopVUEmission = func() {
 		logger.Debugf("Stopping vus and vux_max metrics emission...")
 		execSchedRunCancel()
 		waitForVUsMetricPush()
+		waitForLoadGeneratorMetricPush()
 	}
 
 	defer func() {
@@ -496,8 +629,15 @@ func (e *Scheduler) Run(globalCtx, runCtx context.Context, samplesOut chan<- met
 
 	executorsRunCtx, executorsRunCancel := context.WithCancel(withExecStateCtx)
 	defer executorsRunCancel()
+	scenariosDone, scenariosFinished := e.trackScenariosDone(executorsRunCtx)
 	for _, exec := range e.executors {
-		go e.runExecutor(executorsRunCtx, runResults, samplesOut, exec)
+		name := exec.GetConfig().GetName()
+		go e.runExecutor(globalCtx, executorsRunCtx, runResults, samplesOut, exec, scenariosDone, scenariosFinished[name])
+		delete(scenariosFinished, name)
+	}
+	// The scenarios without work for this instance are done right away
+	for _, finished := range scenariosFinished {
+		close(finished)
 	}
 
 	// Wait for all executors to finish
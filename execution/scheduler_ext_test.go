@@ -804,6 +804,110 @@ func TestSchedulerSetupTeardownRun(t *testing.T) {
 	})
 }
 
+func TestSchedulerScenarioSetupTeardownRun(t *testing.T) {
+	t.Parallel()
+
+	newScenarios := func() lib.ScenarioConfigs {
+		checkout := executor.NewPerVUIterationsConfig("checkout")
+		checkout.VUs = null.IntFrom(2)
+		checkout.Iterations = null.IntFrom(2)
+		checkout.Setup = "checkoutSetup"
+		checkout.Teardown = "checkoutTeardown"
+		browse := executor.NewPerVUIterationsConfig("browse")
+		browse.VUs = null.IntFrom(1)
+		browse.Iterations = null.IntFrom(1)
+		return lib.ScenarioConfigs{checkout.GetName(): checkout, browse.GetName(): browse}
+	}
+
+	t.Run("Normal", func(t *testing.T) {
+		t.Parallel()
+		var iterations, teardowns atomic.Int64
+		runner := &minirunner.MiniRunner{
+			Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+				data, ok := lib.GetScenarioSetupData(ctx)
+				switch lib.GetScenarioState(ctx).Name {
+				case "checkout":
+					assert.True(t, ok)
+					assert.Equal(t, `{"cart":1}`, string(data))
+				default:
+					assert.False(t, ok)
+				}
+				assert.Zero(t, teardowns.Load())
+				iterations.Add(1)
+				return nil
+			},
+			ScenarioSetupFn: func(_ context.Context, _ chan<- metrics.SampleContainer, fn string) ([]byte, error) {
+				assert.Equal(t, "checkoutSetup", fn)
+				assert.Zero(t, iterations.Load())
+				return []byte(`{"cart":1}`), nil
+			},
+			ScenarioTeardownFn: func(_ context.Context, _ chan<- metrics.SampleContainer, fn string, data []byte) error {
+				assert.Equal(t, "checkoutTeardown", fn)
+				assert.Equal(t, `{"cart":1}`, string(data))
+				teardowns.Add(1)
+				return nil
+			},
+			Options: lib.Options{Scenarios: newScenarios()},
+		}
+		ctx, cancel, execScheduler, samples := newTestScheduler(t, runner, nil, lib.Options{})
+		defer cancel()
+
+		require.NoError(t, execScheduler.Run(ctx, ctx, samples))
+		assert.Equal(t, int64(5), iterations.Load())
+		assert.Equal(t, int64(1), teardowns.Load())
+	})
+	t.Run("Setup Error", func(t *testing.T) {
+		t.Parallel()
+		runner := &minirunner.MiniRunner{
+			ScenarioSetupFn: func(_ context.Context, _ chan<- metrics.SampleContainer, _ string) ([]byte, error) {
+				return nil, errors.New("setup error")
+			},
+			ScenarioTeardownFn: func(_ context.Context, _ chan<- metrics.SampleContainer, _ string, _ []byte) error {
+				return errors.New("teardown error")
+			},
+			Options: lib.Options{Scenarios: newScenarios()},
+		}
+		ctx, cancel, execScheduler, samples := newTestScheduler(t, runner, nil, lib.Options{})
+		defer cancel()
+		assert.EqualError(t, execScheduler.Run(ctx, ctx, samples), "setup error")
+	})
+	t.Run("Teardown Error", func(t *testing.T) {
+		t.Parallel()
+		runner := &minirunner.MiniRunner{
+			ScenarioTeardownFn: func(_ context.Context, _ chan<- metrics.SampleContainer, _ string, _ []byte) error {
+				return errors.New("teardown error")
+			},
+			Options: lib.Options{Scenarios: newScenarios()},
+		}
+		ctx, cancel, execScheduler, samples := newTestScheduler(t, runner, nil, lib.Options{})
+		defer cancel()
+		assert.EqualError(t, execScheduler.Run(ctx, ctx, samples), "teardown error")
+	})
+	t.Run("Don't Run Setup and Teardown", func(t *testing.T) {
+		t.Parallel()
+		runner := &minirunner.MiniRunner{
+			Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+				_, ok := lib.GetScenarioSetupData(ctx)
+				assert.False(t, ok)
+				return nil
+			},
+			ScenarioSetupFn: func(_ context.Context, _ chan<- metrics.SampleContainer, _ string) ([]byte, error) {
+				return nil, errors.New("setup error")
+			},
+			ScenarioTeardownFn: func(_ context.Context, _ chan<- metrics.SampleContainer, _ string, _ []byte) error {
+				return errors.New("teardown error")
+			},
+			Options: lib.Options{Scenarios: newScenarios()},
+		}
+		ctx, cancel, execScheduler, samples := newTestScheduler(t, runner, nil, lib.Options{
+			NoSetup:    null.BoolFrom(true),
+			NoTeardown: null.BoolFrom(true),
+		})
+		defer cancel()
+		assert.NoError(t, execScheduler.Run(ctx, ctx, samples))
+	})
+}
+
 func TestSchedulerStages(t *testing.T) {
 	t.Parallel()
 	testdata := map[string]struct {
@@ -942,6 +1046,40 @@ func TestSchedulerEndErrors(t *testing.T) {
 	assert.Empty(t, hook.Entries)
 }
 
+func TestSchedulerDependsOn(t *testing.T) {
+	t.Parallel()
+
+	first := executor.NewPerVUIterationsConfig("first")
+	first.VUs = null.IntFrom(2)
+	first.Iterations = null.IntFrom(1)
+	second := executor.NewPerVUIterationsConfig("second")
+	second.VUs = null.IntFrom(2)
+	second.Iterations = null.IntFrom(1)
+	second.DependsOn = []string{"first"}
+
+	var firstEnded, secondStarted atomic.Int64
+	runner := &minirunner.MiniRunner{
+		Fn: func(ctx context.Context, _ *lib.State, _ chan<- metrics.SampleContainer) error {
+			if lib.GetScenarioState(ctx).Name == "first" {
+				time.Sleep(200 * time.Millisecond)
+				firstEnded.Store(time.Now().UnixNano())
+			} else {
+				secondStarted.CompareAndSwap(0, time.Now().UnixNano())
+			}
+			return nil
+		},
+		Options: lib.Options{
+			Scenarios: lib.ScenarioConfigs{first.GetName(): first, second.GetName(): second},
+		},
+	}
+	ctx, cancel, execScheduler, samples := newTestScheduler(t, runner, nil, lib.Options{})
+	defer cancel()
+
+	require.NoError(t, execScheduler.Run(ctx, ctx, samples))
+	require.NotZero(t, secondStarted.Load())
+	assert.GreaterOrEqual(t, secondStarted.Load(), firstEnded.Load())
+}
+
 func TestSchedulerEndIterations(t *testing.T) {
 	t.Parallel()
 	registry := metrics.NewRegistry()
@@ -1210,6 +1348,17 @@ func TestRealTimeAndSetupTeardownMetrics(t *testing.T) {
 		assert.NoError(t, execScheduler.Run(ctx, ctx, samples))
 	}()
 
+	// the vus metrics are emitted every second
+	isPeriodic := func(sampleContainer metrics.SampleContainer) bool {
+		for _, s := range sampleContainer.GetSamples() {
+			switch s.Metric {
+			case piState.BuiltinMetrics.VUs, piState.BuiltinMetrics.VUsMax:
+				return true
+			}
+		}
+		return false
+	}
+
 	expectIn := func(from, to time.Duration, expected metrics.SampleContainer) {
 		start := time.Now()
 		from *= time.Millisecond
@@ -1217,14 +1366,7 @@ func TestRealTimeAndSetupTeardownMetrics(t *testing.T) {
 		for {
 			select {
 			case sampleContainer := <-samples:
-				gotVus := false
-				for _, s := range sampleContainer.GetSamples() {
-					if s.Metric == piState.BuiltinMetrics.VUs || s.Metric == piState.BuiltinMetrics.VUsMax {
-						gotVus = true
-						break
-					}
-				}
-				if gotVus {
+				if isPeriodic(sampleContainer) {
 					continue
 				}
 
@@ -1307,6 +1449,9 @@ func TestRealTimeAndSetupTeardownMetrics(t *testing.T) {
 	for {
 		select {
 		case s := <-samples:
+			if isPeriodic(s) {
+				continue
+			}
 			t.Fatalf("Did not expect anything in the sample channel bug got %#v", s)
 		case <-time.After(3 * time.Second):
 			t.Fatalf("Local execScheduler took way to long to finish")
@@ -38,6 +38,9 @@ type Bundle struct {
 
 	filesystems map[string]fsext.Fs
 	pwd         *url.URL
+	// openedTexts has the contents of the files opened as text, shared by
+	// the instances of the bundle.
+	openedTexts *openedTexts
 
 	callableExports map[string]struct{}
 	ModuleResolver  *modules.ModuleResolver
@@ -52,6 +55,7 @@ type BundleInstance struct {
 
 	mainModule   sobek.ModuleRecord
 	moduleVUImpl *moduleVUImpl
+	hooks        *lib.Hooks
 }
 
 func (bi *BundleInstance) getCallableExport(name string) sobek.Callable {
@@ -97,6 +101,7 @@ func newBundle(
 		CompatibilityMode: compatMode,
 		callableExports:   make(map[string]struct{}),
 		filesystems:       filesystems,
+		openedTexts:       newOpenedTexts(),
 		pwd:               src.PWD,
 		preInitState:      piState,
 	}
@@ -302,6 +307,7 @@ func (b *Bundle) instantiate(vuImpl *moduleVUImpl, vuID uint64) (*BundleInstance
 		TestPreInitState: b.preInitState,
 		FileSystems:      b.filesystems,
 		CWD:              b.pwd,
+		Hooks:            &lib.Hooks{},
 	}
 
 	modSys := modules.NewModuleSystem(b.ModuleResolver, vuImpl)
@@ -328,6 +334,7 @@ func (b *Bundle) instantiate(vuImpl *moduleVUImpl, vuID uint64) (*BundleInstance
 		Runtime:      vuImpl.runtime,
 		env:          b.preInitState.RuntimeOptions.Env,
 		moduleVUImpl: vuImpl,
+		hooks:        initenv.Hooks,
 	}
 	callback := func() error { // this exists so that Sobek catches uncatchable panics such as Interrupt
 		var err error
@@ -434,7 +441,7 @@ func (b *Bundle) setInitGlobals(rt *sobek.Runtime, vu *moduleVUImpl, modSys *mod
 		if err != nil {
 			return nil, err
 		}
-		return openImpl(rt, b.filesystems["file"], pwd, filename, args...)
+		return openImpl(rt, b.filesystems["file"], b.openedTexts, pwd, filename, args...)
 	})
 	warnAboutModuleMixing := func(name string) {
 		warnFunc := rt.ToValue(func() error {
@@ -478,6 +485,11 @@ func generateFileLoad(b *Bundle) modules.FileLoader {
 		if err != nil {
 			return nil, err
 		}
+		if lock := b.preInitState.ModulesLock; lock != nil && specifier.Scheme == "https" {
+			if err = lock.Verify(specifier.String(), d.Data); err != nil {
+				return nil, err
+			}
+		}
 		return d.Data, nil
 	}
 }
@@ -13,6 +13,7 @@ import (
 //nolint:gochecknoglobals
 var fieldNameExceptions = map[string]string{
 	"OCSP": "ocsp",
+	"UUID": "uuid",
 }
 
 // FieldName Returns the JS name for an exported struct field. The name is snake_cased, with respect for
@@ -49,6 +50,7 @@ var methodNameExceptions = map[string]string{
 	"HTML": "html",
 	"URL":  "url",
 	"OCSP": "ocsp",
+	"UUID": "uuid",
 }
 
 // MethodName Returns the JS name for an exported method. The first letter of the method's name is
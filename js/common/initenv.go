@@ -14,6 +14,8 @@ type InitEnvironment struct {
 	*lib.TestPreInitState
 	FileSystems map[string]fsext.Fs
 	CWD         *url.URL
+	// Hooks are the lifecycle hooks of the VU the init context belongs to.
+	Hooks *lib.Hooks
 	// TODO: get rid of this type altogether? we won't need it if we figure out
 	// how to handle .tar archive vs regular JS script differences in FileSystems
 }
@@ -0,0 +1,76 @@
+package common
+
+import (
+	"github.com/grafana/sobek"
+)
+
+// NewAsyncIterator creates a JS object implementing the async iterator protocol,
+// which next method is backed by the provided function, expected to return a
+// promise resolving to an iterator result, holding `done` and `value` properties.
+//
+// Its return method, called when a `for await...of` loop is exited early, resolves
+// right away: it is meant for iterators which next calls each read a single
+// value in the background, leaving nothing running to stop.
+//
+// When the runtime supports the `Symbol.asyncIterator` well-known symbol, the
+// object is also made async iterable, returning itself.
+func NewAsyncIterator(rt *sobek.Runtime, next func() *sobek.Promise) *sobek.Object {
+	obj := rt.NewObject()
+	mustSet(rt, obj.Set("next", next))
+	mustSet(rt, obj.Set("return", func(value sobek.Value) *sobek.Promise {
+		promise, resolve, _ := rt.NewPromise()
+
+		result := rt.NewObject()
+		mustSet(rt, result.Set("done", true))
+		mustSet(rt, result.Set("value", value))
+		resolve(result)
+
+		return promise
+	}))
+
+	if sym := AsyncIteratorSymbol(rt); sym != nil {
+		mustSet(rt, obj.SetSymbol(sym, func() *sobek.Object { return obj }))
+	}
+
+	return obj
+}
+
+// MakeAsyncIterable makes the provided object async iterable, so that it can be
+// iterated over through `for await...of` loops, each iteration resolving to the
+// iterator result the provided next function's promise resolves to. It is how
+// the streaming parsers of the experimental modules are made async iterable,
+// their next method being passed along.
+//
+// Note that it has no effect when the runtime does not support the
+// `Symbol.asyncIterator` well-known symbol, in which case next has to be called
+// explicitly.
+func MakeAsyncIterable(rt *sobek.Runtime, obj *sobek.Object, next func() *sobek.Promise) {
+	sym := AsyncIteratorSymbol(rt)
+	if sym == nil {
+		return
+	}
+
+	mustSet(rt, obj.SetSymbol(sym, func() *sobek.Object {
+		return NewAsyncIterator(rt, next)
+	}))
+}
+
+// AsyncIteratorSymbol returns the runtime's `Symbol.asyncIterator` well-known
+// symbol, or nil if the runtime does not support it.
+func AsyncIteratorSymbol(rt *sobek.Runtime) *sobek.Symbol {
+	symbol := rt.GlobalObject().Get("Symbol")
+	if symbol == nil {
+		return nil
+	}
+
+	sym, _ := symbol.ToObject(rt).Get("asyncIterator").(*sobek.Symbol)
+
+	return sym
+}
+
+// mustSet throws the provided error, if any, as setting a property failed.
+func mustSet(rt *sobek.Runtime, err error) {
+	if err != nil {
+		Throw(rt, err)
+	}
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeAsyncIterable(t *testing.T) {
+	t.Parallel()
+
+	newCounter := func(rt *sobek.Runtime) *sobek.Object {
+		count := 0
+		obj := rt.NewObject()
+
+		MakeAsyncIterable(rt, obj, func() *sobek.Promise {
+			promise, resolve, _ := rt.NewPromise()
+
+			count++
+			result := rt.NewObject()
+			require.NoError(t, result.Set("done", count > 3))
+			require.NoError(t, result.Set("value", count))
+			resolve(result)
+
+			return promise
+		})
+
+		return obj
+	}
+
+	t.Run("without Symbol.asyncIterator", func(t *testing.T) {
+		t.Parallel()
+
+		rt := sobek.New()
+		obj := newCounter(rt)
+
+		assert.Empty(t, obj.Symbols())
+	})
+
+	t.Run("with Symbol.asyncIterator", func(t *testing.T) {
+		t.Parallel()
+
+		rt := sobek.New()
+		_, err := rt.RunString(`Symbol.asyncIterator = Symbol("Symbol.asyncIterator")`)
+		require.NoError(t, err)
+
+		require.NoError(t, rt.Set("counter", newCounter(rt)))
+
+		_, err = rt.RunString(`
+			var got = [];
+			var iterator = counter[Symbol.asyncIterator]();
+			if (iterator[Symbol.asyncIterator]() !== iterator) {
+				throw new Error("the iterator should be async iterable");
+			}
+
+			(async () => {
+				for (let result = await iterator.next(); !result.done; result = await iterator.next()) {
+					got.push(result.value);
+				}
+
+				const returned = await iterator.return(42);
+				got.push(returned.done, returned.value);
+			})();
+		`)
+		require.NoError(t, err)
+
+		assert.Equal(t, []any{int64(1), int64(2), int64(3), true, int64(42)}, rt.Get("got").Export())
+	})
+}
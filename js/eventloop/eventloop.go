@@ -4,6 +4,7 @@ package eventloop
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/grafana/sobek"
 	"go.k6.io/k6/js/modules"
@@ -28,6 +29,11 @@ type EventLoop struct {
 	registeredCallbacks int
 	vu                  modules.VU
 
+	// trackLag enables the tracking of maxLag, the longest time a callback
+	// waited in the queue since the last start, only used on the loop.
+	trackLag bool
+	maxLag   time.Duration
+
 	// pendingPromiseRejections are rejected promises with no handler,
 	// if there is something in this map at an end of an event loop then it will exit with an error.
 	// It's similar to what Deno and Node do.
@@ -124,6 +130,9 @@ func (e *EventLoop) RegisterCallback() (enqueueCallback func(func() error)) {
 			panic("RegisterCallback called twice")
 		}
 		callbackCalled = true
+		if e.trackLag {
+			f = e.withLag(f)
+		}
 		e.queue = append(e.queue, f)
 		e.registeredCallbacks--
 		e.lock.Unlock()
@@ -162,6 +171,7 @@ func (e *EventLoop) putInfront(queue []func() error) {
 func (e *EventLoop) Start(firstCallback func() error) error {
 	e.pendingPromiseRejections = make(map[*sobek.Promise]struct{})
 	e.queue = []func() error{firstCallback}
+	e.maxLag = 0
 	for {
 		queue, awaiting := e.popAll()
 
@@ -198,6 +208,33 @@ func (e *EventLoop) Start(firstCallback func() error) error {
 	}
 }
 
+// withLag wraps the callback queued now so it updates maxLag when it's run.
+func (e *EventLoop) withLag(f func() error) func() error {
+	queued := time.Now()
+	return func() error {
+		if lag := time.Since(queued); lag > e.maxLag {
+			e.maxLag = lag
+		}
+		return f()
+	}
+}
+
+// TrackLag enables or disables the tracking of the lag returned by MaxLag,
+// which is off by default, as it times every callback.
+func (e *EventLoop) TrackLag(enabled bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.trackLag = enabled
+}
+
+// MaxLag returns the longest time a registered callback waited in the queue to
+// be run, since the event loop was last started, i.e. how late the loop is to
+// run the callbacks as it's busy running the other ones. It's always zero,
+// unless the lag is tracked.
+func (e *EventLoop) MaxLag() time.Duration {
+	return e.maxLag
+}
+
 // WaitOnRegistered waits on all registered callbacks so we know nothing is still doing work.
 // This does call back the callbacks and more can be queued over time.
 // A different mechanism needs to be used to tell the users that the event loop has errored out or winding down for a
@@ -237,3 +237,26 @@ func TestEventLoopAsyncAwait(t *testing.T) {
 	loop.WaitOnRegistered()
 	require.EqualError(t, err, "Uncaught (in promise) ReferenceError: some is not defined\n\tat a (<eval>:3:13(1))\n\tat <eval>:6:20(2)\n")
 }
+
+func TestEventLoopMaxLag(t *testing.T) {
+	t.Parallel()
+	loop := eventloop.New(&modulestest.VU{RuntimeField: sobek.New()})
+	busy := 200 * time.Millisecond
+	busyStart := func() error {
+		// the callback is queued right away, but it waits for the busy loop
+		loop.RegisterCallback()(func() error { return nil })
+		time.Sleep(busy)
+		return nil
+	}
+
+	// the lag isn't tracked by default
+	require.NoError(t, loop.Start(busyStart))
+	require.Zero(t, loop.MaxLag())
+
+	loop.TrackLag(true)
+	require.NoError(t, loop.Start(busyStart))
+	require.GreaterOrEqual(t, loop.MaxLag(), busy)
+
+	require.NoError(t, loop.Start(func() error { return nil }))
+	require.Zero(t, loop.MaxLag())
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sync"
+	"unicode/utf16"
 
 	"github.com/grafana/sobek"
 	"github.com/sirupsen/logrus"
@@ -17,18 +19,58 @@ const cantBeUsedOutsideInitContextMsg = `the "%s" function is only available in
 
 // openImpl implements openImpl() in the init context and will read and return the
 // contents of a file. If the second argument is "b" it returns an ArrayBuffer
-// instance, otherwise a string representation.
-func openImpl(rt *sobek.Runtime, fs fsext.Fs, basePWD *url.URL, filename string, args ...string) (sobek.Value, error) {
-	data, err := readFile(fs, fsext.Abs(basePWD.Path, filename))
-	if err != nil {
-		return nil, err
-	}
+// instance, otherwise a string value, which is shared by the VUs through the
+// texts cache.
+func openImpl(
+	rt *sobek.Runtime, fs fsext.Fs, texts *openedTexts, basePWD *url.URL, filename string, args ...string,
+) (sobek.Value, error) {
+	filename = fsext.Abs(basePWD.Path, filename)
 
 	if len(args) > 0 && args[0] == "b" {
+		data, err := readFile(fs, filename)
+		if err != nil {
+			return nil, err
+		}
+		// every VU needs its own copy, since ArrayBuffers are mutable
 		ab := rt.NewArrayBuffer(data)
 		return rt.ToValue(&ab), nil
 	}
-	return rt.ToValue(string(data)), nil
+
+	return texts.get(fs, filename)
+}
+
+// openedTexts caches the contents of the files opened as text, so all the VUs
+// share the same immutable string value instead of each of them having a copy
+// of it, which cuts the memory used by the tests with many VUs and large files.
+//
+// The values are built once, as the runtime would: the ASCII contents are kept
+// as they are, and the others are converted to UTF-16. They aren't tied to a
+// runtime, so they can be used by all of them. They're kept as long as the
+// bundle is, since VUs can be initialized at any time during the test.
+type openedTexts struct {
+	mu    sync.Mutex
+	texts map[string]sobek.String
+}
+
+func newOpenedTexts() *openedTexts {
+	return &openedTexts{texts: make(map[string]sobek.String)}
+}
+
+// get returns the contents of the file, which is read only the first time.
+func (ot *openedTexts) get(fs fsext.Fs, filename string) (sobek.String, error) {
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+
+	if text, ok := ot.texts[filename]; ok {
+		return text, nil
+	}
+	data, err := readFile(fs, filename)
+	if err != nil {
+		return nil, err
+	}
+	text := sobek.StringFromUTF16(utf16.Encode([]rune(string(data))))
+	ot.texts[filename] = text
+	return text, nil
 }
 
 func readFile(fileSystem fsext.Fs, filename string) (data []byte, err error) {
@@ -8,8 +8,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
+	"unicode/utf16"
+	"unsafe"
 
 	"github.com/grafana/sobek"
 	"github.com/sirupsen/logrus"
@@ -260,6 +263,50 @@ func TestInitContextOpen(t *testing.T) {
 		assert.Equal(t, buf, bi.getExported("data").Export())
 	})
 
+	// valueData returns the address of the data of a string value, either an
+	// ASCII string, or a slice of UTF-16 code units.
+	valueData := func(t *testing.T, v sobek.Value) uintptr {
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() { //nolint:exhaustive
+		case reflect.String:
+			return uintptr(unsafe.Pointer(unsafe.StringData(rv.String())))
+		case reflect.Slice:
+			return rv.Pointer()
+		default:
+			require.Failf(t, "unexpected string value", "%T", v)
+			return 0
+		}
+	}
+
+	for name, content := range map[string]string{
+		"SharedText":        "shared content",
+		"SharedUnicodeText": "contenu partagé, 共有コンテンツ 🙂",
+	} {
+		content := content
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			fs := fsext.NewMemMapFs()
+			require.NoError(t, fsext.WriteFile(fs, "/path/to/file.txt", []byte(content), 0o644))
+			b, err := getSimpleBundle(t, "/path/to/script.js", `
+				export let data = open("./file.txt");
+				export let length = data.length;
+				export default function() {}
+			`, fs)
+			require.NoError(t, err)
+
+			values := make([]sobek.Value, 2)
+			for i := range values {
+				bi, err := b.Instantiate(context.Background(), uint64(i+1))
+				require.NoError(t, err)
+				values[i] = bi.getExported("data")
+				assert.Equal(t, content, values[i].Export())
+				assert.EqualValues(t, len(utf16.Encode([]rune(content))), bi.getExported("length").ToInteger())
+			}
+			assert.Equal(t, valueData(t, values[0]), valueData(t, values[1]),
+				"the VUs should share the contents of the file")
+		})
+	}
+
 	testdata := map[string]string{
 		"Absolute": "/path/to/file",
 		"Relative": "./file",
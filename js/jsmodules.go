@@ -13,13 +13,31 @@ import (
 	"go.k6.io/k6/js/modules/k6/data"
 	"go.k6.io/k6/js/modules/k6/encoding"
 	"go.k6.io/k6/js/modules/k6/execution"
+	"go.k6.io/k6/js/modules/k6/experimental/channels"
+	"go.k6.io/k6/js/modules/k6/experimental/csv"
+	"go.k6.io/k6/js/modules/k6/experimental/dns"
+	"go.k6.io/k6/js/modules/k6/experimental/expect"
+	"go.k6.io/k6/js/modules/k6/experimental/faker"
 	"go.k6.io/k6/js/modules/k6/experimental/fs"
+	"go.k6.io/k6/js/modules/k6/experimental/hooks"
+	"go.k6.io/k6/js/modules/k6/experimental/jsonl"
+	"go.k6.io/k6/js/modules/k6/experimental/jwt"
+	"go.k6.io/k6/js/modules/k6/experimental/mqtt"
+	"go.k6.io/k6/js/modules/k6/experimental/pacing"
+	"go.k6.io/k6/js/modules/k6/experimental/parquet"
+	"go.k6.io/k6/js/modules/k6/experimental/ratelimit"
+	"go.k6.io/k6/js/modules/k6/experimental/sockets"
+	"go.k6.io/k6/js/modules/k6/experimental/sse"
+	"go.k6.io/k6/js/modules/k6/experimental/store"
 	"go.k6.io/k6/js/modules/k6/experimental/streams"
 	"go.k6.io/k6/js/modules/k6/experimental/tracing"
+	"go.k6.io/k6/js/modules/k6/experimental/xml"
 	"go.k6.io/k6/js/modules/k6/grpc"
 	"go.k6.io/k6/js/modules/k6/html"
 	"go.k6.io/k6/js/modules/k6/http"
+	"go.k6.io/k6/js/modules/k6/log"
 	"go.k6.io/k6/js/modules/k6/metrics"
+	"go.k6.io/k6/js/modules/k6/secrets"
 	"go.k6.io/k6/js/modules/k6/timers"
 	"go.k6.io/k6/js/modules/k6/ws"
 
@@ -56,13 +74,31 @@ func getInternalJSModules() map[string]interface{} {
 				" which will be removed after September 23rd, 2024 (v0.54.0). Ensure your scripts are migrated by then."+
 				" For more information, see the migration guide at the link:"+
 				" https://grafana.com/docs/k6/latest/using-k6-browser/migrating-to-k6-v0-52/"),
-		"k6/browser":         browser.New(),
-		"k6/experimental/fs": fs.New(),
-		"k6/net/grpc":        grpc.New(),
-		"k6/html":            html.New(),
-		"k6/http":            http.New(),
-		"k6/metrics":         metrics.New(),
-		"k6/ws":              ws.New(),
+		"k6/browser":                browser.New(),
+		"k6/experimental/channels":  channels.New(),
+		"k6/experimental/csv":       csv.New(),
+		"k6/experimental/dns":       dns.New(),
+		"k6/experimental/expect":    expect.New(),
+		"k6/experimental/faker":     faker.New(),
+		"k6/experimental/fs":        fs.New(),
+		"k6/experimental/hooks":     hooks.New(),
+		"k6/experimental/jsonl":     jsonl.New(),
+		"k6/experimental/jwt":       jwt.New(),
+		"k6/experimental/mqtt":      mqtt.New(),
+		"k6/experimental/pacing":    pacing.New(),
+		"k6/experimental/parquet":   parquet.New(),
+		"k6/experimental/ratelimit": ratelimit.New(),
+		"k6/experimental/sockets":   sockets.New(),
+		"k6/experimental/sse":       sse.New(),
+		"k6/experimental/store":     store.New(),
+		"k6/experimental/xml":       xml.New(),
+		"k6/net/grpc":               grpc.New(),
+		"k6/html":                   html.New(),
+		"k6/http":                   http.New(),
+		"k6/log":                    log.New(),
+		"k6/metrics":                metrics.New(),
+		"k6/secrets":                secrets.New(),
+		"k6/ws":                     ws.New(),
 		"k6/experimental/grpc": newRemovedModule(
 			"k6/experimental/grpc has been graduated, please use k6/net/grpc instead." +
 				" See https://grafana.com/docs/k6/latest/javascript-api/k6-net-grpc/ for more information.",
@@ -28,8 +28,10 @@ func (cm *cjsModule) Instantiate(_ *sobek.Runtime) (sobek.CyclicModuleInstance,
 
 func (cm *cjsModule) RequestedModules() []string { return nil }
 
-func (cm *cjsModule) Evaluate(_ *sobek.Runtime) *sobek.Promise {
-	panic("this shouldn't be called in the current implementation")
+// Evaluate is called when the module is imported dynamically, it requests no
+// modules, so there is nothing to resolve.
+func (cm *cjsModule) Evaluate(rt *sobek.Runtime) *sobek.Promise {
+	return rt.CyclicModuleRecordEvaluate(cm, nil)
 }
 
 func (cm *cjsModule) GetExportedNames(callback func([]string), _ ...sobek.ModuleRecord) bool {
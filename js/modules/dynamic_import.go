@@ -0,0 +1,105 @@
+package modules
+
+import (
+	"reflect"
+
+	"github.com/grafana/sobek"
+	"github.com/grafana/sobek/ast"
+)
+
+// importModuleDynamically is the host hook of the dynamic import() calls.
+//
+// As with the static imports, the modules can be loaded only while the first VU
+// is initialized, after which the resolution is locked. To let the VU code
+// lazily load the modules, e.g. the ones of each scenario, the dynamic imports
+// with a string literal specifier are resolved ahead, when the module that has
+// them is loaded, see preResolveDynamicImports.
+func (ms *ModuleSystem) importModuleDynamically(
+	referencingScriptOrModule interface{}, specifier sobek.Value, promiseCapability interface{},
+) {
+	rt := ms.vu.Runtime()
+	referrer, isModule := referencingScriptOrModule.(sobek.ModuleRecord)
+	if !isModule {
+		// the CommonJS modules are evaluated as scripts, so the module of the
+		// file calling import() is looked up by its name, as require() does
+		referrer = nil
+		if name := getCurrentModuleFile(ms.vu); name != "" && name != "file:///-" {
+			referrer, _ = ms.resolver.sobekModuleResolver(nil, name)
+		}
+	}
+
+	mod, err := ms.resolver.sobekModuleResolver(referrer, specifier.String())
+	if err != nil {
+		rt.FinishLoadingImportModule(referencingScriptOrModule, specifier, promiseCapability, nil, err)
+		return
+	}
+	rt.FinishLoadingImportModule(referencingScriptOrModule, specifier, promiseCapability, mod, nil)
+}
+
+// getCurrentModuleFile returns the name of the file of the innermost JS frame
+// of the call stack, which is the one calling import().
+func getCurrentModuleFile(vu VU) string {
+	var buf [10]sobek.StackFrame
+	for _, frame := range vu.Runtime().CaptureCallStack(10, buf[:0]) {
+		if name := frame.SrcName(); name != "" && name != "<native>" {
+			return name
+		}
+	}
+	return ""
+}
+
+// preResolveDynamicImports resolves the modules the given module imports
+// dynamically with a string literal specifier, so they are cached before the
+// resolution is locked. Their errors are cached as well, but only returned if
+// the import() calls are actually made.
+func (mr *ModuleResolver) preResolveDynamicImports(mod sobek.ModuleRecord, prg *ast.Program) {
+	if mr.locked {
+		return
+	}
+	basePWD := mr.reversePath(mod)
+	for _, specifier := range dynamicImportSpecifiers(prg) {
+		_, _ = mr.resolve(basePWD, specifier)
+	}
+}
+
+// dynamicImportSpecifiers returns the string literal specifiers of the
+// dynamic import() calls of the program.
+func dynamicImportSpecifiers(prg *ast.Program) []string {
+	var specifiers []string
+	astType := reflect.TypeOf(ast.Program{}).PkgPath()
+
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() { //nolint:exhaustive
+		case reflect.Interface, reflect.Ptr:
+			if v.IsNil() {
+				return
+			}
+			if call, ok := v.Interface().(*ast.CallExpression); ok {
+				if _, ok := call.Callee.(*ast.DynamicImportExpression); ok && len(call.ArgumentList) == 1 {
+					if lit, ok := call.ArgumentList[0].(*ast.StringLiteral); ok {
+						specifiers = append(specifiers, lit.Value.String())
+					}
+				}
+			}
+			walk(v.Elem())
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Struct:
+			// only the nodes of the AST are walked, not e.g. the source file
+			if v.Type().PkgPath() != astType {
+				return
+			}
+			for i := 0; i < v.NumField(); i++ {
+				if v.Type().Field(i).IsExported() {
+					walk(v.Field(i))
+				}
+			}
+		}
+	}
+	walk(reflect.ValueOf(prg))
+
+	return specifiers
+}
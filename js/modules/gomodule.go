@@ -36,7 +36,11 @@ func (gm *goModule) Instantiate(rt *sobek.Runtime) (sobek.CyclicModuleInstance,
 	return &goModuleInstance{rt: rt, mi: mi}, nil
 }
 
-func (gm *goModule) Evaluate(_ *sobek.Runtime) *sobek.Promise { panic("this shouldn't happen") }
+// Evaluate is called when the module is imported dynamically, it requests no
+// modules, so there is nothing to resolve.
+func (gm *goModule) Evaluate(rt *sobek.Runtime) *sobek.Promise {
+	return rt.CyclicModuleRecordEvaluate(gm, nil)
+}
 
 func (gm *goModule) GetExportedNames(callback func([]string), _ ...sobek.ModuleRecord) bool {
 	if gm.exportedNames != nil {
@@ -13,8 +13,10 @@ type basicGoModule struct {
 
 func (bgm *basicGoModule) Link() error { return nil }
 
-func (bgm *basicGoModule) Evaluate(_ *sobek.Runtime) *sobek.Promise {
-	panic("this shouldn't be called")
+// Evaluate is called when the module is imported dynamically, it requests no
+// modules, so there is nothing to resolve.
+func (bgm *basicGoModule) Evaluate(rt *sobek.Runtime) *sobek.Promise {
+	return rt.CyclicModuleRecordEvaluate(bgm, nil)
 }
 
 func (bgm *basicGoModule) InitializeEnvironment() error { return nil }
@@ -16,13 +16,15 @@ type (
 	// RootModule is the global module instance that will create module
 	// instances for each VU.
 	RootModule struct {
-		shared sharedArrays
+		shared   sharedArrays
+		datasets sharedDatasets
 	}
 
 	// Data represents an instance of the data module.
 	Data struct {
-		vu     modules.VU
-		shared *sharedArrays
+		vu       modules.VU
+		shared   *sharedArrays
+		datasets *sharedDatasets
 	}
 
 	sharedArrays struct {
@@ -42,6 +44,9 @@ func New() *RootModule {
 		shared: sharedArrays{
 			data: make(map[string]sharedArray),
 		},
+		datasets: sharedDatasets{
+			data: make(map[string]*sharedDataset),
+		},
 	}
 }
 
@@ -49,8 +54,9 @@ func New() *RootModule {
 // a new instance for each VU.
 func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 	return &Data{
-		vu:     vu,
-		shared: &rm.shared,
+		vu:       vu,
+		shared:   &rm.shared,
+		datasets: &rm.datasets,
 	}
 }
 
@@ -58,7 +64,8 @@ func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 func (d *Data) Exports() modules.Exports {
 	return modules.Exports{
 		Named: map[string]interface{}{
-			"SharedArray": d.sharedArray,
+			"SharedArray":   d.sharedArray,
+			"SharedDataset": d.sharedDataset,
 		},
 	}
 }
@@ -0,0 +1,288 @@
+package data
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib/fsext"
+)
+
+const (
+	datasetFormatCSV   = "csv"
+	datasetFormatJSONL = "jsonl"
+)
+
+// sharedDataset is a read-only dataset loaded from a CSV or JSONL file. Only
+// the raw file contents and the offsets of the rows are kept in memory,
+// shared between all VUs, and each row is parsed only when it's accessed.
+type sharedDataset struct {
+	data []byte
+	// offsets contains the start of every row, followed by the end of the last one.
+	offsets   []int
+	format    string
+	delimiter rune
+	columns   []string
+}
+
+type datasetOptions struct {
+	format    string
+	delimiter rune
+	header    bool
+}
+
+type sharedDatasets struct {
+	data map[string]*sharedDataset
+	mu   sync.Mutex
+}
+
+func (s *sharedDatasets) get(key string, load func() (*sharedDataset, error)) (*sharedDataset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if dataset, ok := s.data[key]; ok {
+		return dataset, nil
+	}
+	dataset, err := load()
+	if err != nil {
+		return nil, err
+	}
+	s.data[key] = dataset
+	return dataset, nil
+}
+
+// sharedDataset is a constructor returning a lazily parsed read-only array
+// with the rows of the given CSV or JSONL file.
+func (d *Data) sharedDataset(call sobek.ConstructorCall) *sobek.Object {
+	rt := d.vu.Runtime()
+
+	if d.vu.State() != nil {
+		common.Throw(rt, errors.New("new SharedDataset must be called in the init context"))
+	}
+
+	var path string
+	if !common.IsNullish(call.Argument(0)) {
+		path = call.Argument(0).String()
+	}
+	if path == "" {
+		common.Throw(rt, errors.New("empty path provided to SharedDataset's constructor"))
+	}
+	opts, err := parseDatasetOptions(rt, path, call.Argument(1))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	initEnv := d.vu.InitEnv()
+	path = fsext.Abs(initEnv.CWD.Path, path)
+	fs, ok := initEnv.FileSystems["file"]
+	if !ok {
+		common.Throw(rt, errors.New("SharedDataset failed; reason: unable to access the file system"))
+	}
+
+	key := fmt.Sprintf("%s|%s|%c|%t", path, opts.format, opts.delimiter, opts.header)
+	dataset, err := d.datasets.get(key, func() (*sharedDataset, error) {
+		data, err := fsext.ReadFile(fs, path)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read the dataset %q: %w", path, err)
+		}
+		return newSharedDataset(data, opts)
+	})
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	return dataset.wrap(rt).ToObject(rt)
+}
+
+func parseDatasetOptions(rt *sobek.Runtime, path string, value sobek.Value) (datasetOptions, error) {
+	opts := datasetOptions{format: datasetFormatCSV, delimiter: ',', header: true}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		opts.format = datasetFormatJSONL
+	}
+
+	if common.IsNullish(value) {
+		return opts, nil
+	}
+	obj := value.ToObject(rt)
+	if v := obj.Get("format"); !common.IsNullish(v) {
+		opts.format = v.String()
+		if opts.format != datasetFormatCSV && opts.format != datasetFormatJSONL {
+			return opts, fmt.Errorf("unsupported SharedDataset format %q, it should be either %q or %q",
+				opts.format, datasetFormatCSV, datasetFormatJSONL)
+		}
+	}
+	if v := obj.Get("delimiter"); !common.IsNullish(v) {
+		delimiter := v.String()
+		if utf8.RuneCountInString(delimiter) != 1 {
+			return opts, fmt.Errorf("the SharedDataset delimiter should be a single character, but it is %q", delimiter)
+		}
+		opts.delimiter, _ = utf8.DecodeRuneInString(delimiter)
+	}
+	if v := obj.Get("header"); !common.IsNullish(v) {
+		opts.header = v.ToBoolean()
+	}
+	return opts, nil
+}
+
+func newSharedDataset(data []byte, opts datasetOptions) (*sharedDataset, error) {
+	dataset := &sharedDataset{data: data, format: opts.format, delimiter: opts.delimiter}
+	if opts.format == datasetFormatJSONL {
+		dataset.indexLines()
+		return dataset, nil
+	}
+
+	if err := dataset.indexRecords(); err != nil {
+		return nil, err
+	}
+	if opts.header && len(dataset.offsets) > 1 {
+		columns, err := dataset.record(0)
+		if err != nil {
+			return nil, err
+		}
+		dataset.columns = columns
+		dataset.offsets = dataset.offsets[1:]
+	}
+	return dataset, nil
+}
+
+// indexLines finds the offsets of all non-empty lines.
+func (s *sharedDataset) indexLines() {
+	for start := 0; start < len(s.data); {
+		end := bytes.IndexByte(s.data[start:], '\n')
+		if end < 0 {
+			end = len(s.data)
+		} else {
+			end += start
+		}
+		if len(bytes.TrimSpace(s.data[start:end])) > 0 {
+			s.offsets = append(s.offsets, start)
+		}
+		start = end + 1
+	}
+	s.offsets = append(s.offsets, len(s.data))
+}
+
+// indexRecords finds the offsets of all CSV records, which can span multiple
+// lines if they contain quoted fields.
+func (s *sharedDataset) indexRecords() error {
+	r := csv.NewReader(bytes.NewReader(s.data))
+	r.Comma = s.delimiter
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+	for {
+		start := r.InputOffset()
+		if _, err := r.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("couldn't parse the dataset: %w", err)
+		}
+		s.offsets = append(s.offsets, int(start))
+	}
+	s.offsets = append(s.offsets, int(r.InputOffset()))
+	return nil
+}
+
+func (s *sharedDataset) len() int {
+	return len(s.offsets) - 1
+}
+
+func (s *sharedDataset) raw(index int) []byte {
+	return s.data[s.offsets[index]:s.offsets[index+1]]
+}
+
+func (s *sharedDataset) record(index int) ([]string, error) {
+	r := csv.NewReader(bytes.NewReader(s.raw(index)))
+	r.Comma = s.delimiter
+	r.FieldsPerRecord = -1
+	return r.Read()
+}
+
+type wrappedSharedDataset struct {
+	*sharedDataset
+
+	rt       *sobek.Runtime
+	freeze   sobek.Callable
+	isFrozen sobek.Callable
+	parse    sobek.Callable
+}
+
+func (s *sharedDataset) wrap(rt *sobek.Runtime) sobek.Value {
+	freeze, _ := sobek.AssertFunction(rt.GlobalObject().Get("Object").ToObject(rt).Get("freeze"))
+	isFrozen, _ := sobek.AssertFunction(rt.GlobalObject().Get("Object").ToObject(rt).Get("isFrozen"))
+	parse, _ := sobek.AssertFunction(rt.GlobalObject().Get("JSON").ToObject(rt).Get("parse"))
+	return rt.NewDynamicArray(wrappedSharedDataset{
+		sharedDataset: s,
+		rt:            rt,
+		freeze:        freeze,
+		isFrozen:      isFrozen,
+		parse:         parse,
+	})
+}
+
+func (s wrappedSharedDataset) Set(_ int, _ sobek.Value) bool {
+	panic(s.rt.NewTypeError("SharedDataset is immutable")) // this is specifically a type error
+}
+
+func (s wrappedSharedDataset) SetLen(_ int) bool {
+	panic(s.rt.NewTypeError("SharedDataset is immutable")) // this is specifically a type error
+}
+
+func (s wrappedSharedDataset) Len() int {
+	return s.len()
+}
+
+func (s wrappedSharedDataset) Get(index int) sobek.Value {
+	if index < 0 || index >= s.len() {
+		return sobek.Undefined()
+	}
+
+	val, err := s.getRow(index)
+	if err != nil {
+		common.Throw(s.rt, err)
+	}
+	if err = deepFreeze(s.rt, s.freeze, s.isFrozen, val); err != nil {
+		common.Throw(s.rt, err)
+	}
+	return val
+}
+
+func (s wrappedSharedDataset) getRow(index int) (sobek.Value, error) {
+	if s.format == datasetFormatJSONL {
+		return s.parse(sobek.Undefined(), s.rt.ToValue(string(s.raw(index))))
+	}
+
+	record, err := s.record(index)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse row %d of the dataset: %w", index, err)
+	}
+	if s.columns == nil {
+		values := make([]interface{}, len(record))
+		for i, v := range record {
+			values[i] = v
+		}
+		return s.rt.NewArray(values...), nil
+	}
+
+	obj := s.rt.NewObject()
+	for i, column := range s.columns {
+		var value sobek.Value = sobek.Undefined()
+		if i < len(record) {
+			value = s.rt.ToValue(record[i])
+		}
+		if err := obj.Set(column, value); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
@@ -0,0 +1,165 @@
+package data
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func newDatasetRuntime(t *testing.T, files map[string]string) *modulestest.Runtime {
+	t.Helper()
+
+	runtime, err := newConfiguredRuntime(t)
+	require.NoError(t, err)
+
+	fs := fsext.NewMemMapFs()
+	for name, content := range files {
+		require.NoError(t, fsext.WriteFile(fs, name, []byte(content), 0o644))
+	}
+	runtime.VU.InitEnvField.FileSystems = map[string]fsext.Fs{"file": fs}
+	runtime.VU.InitEnvField.CWD = &url.URL{Scheme: "file", Path: "/data/"}
+	_, err = runtime.VU.Runtime().RunString(`globalThis.SharedDataset = data.SharedDataset;`)
+	require.NoError(t, err)
+
+	return runtime
+}
+
+func TestSharedDataset(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"/data/users.csv": "username,password\nadmin,123\n\"multi\nline\",\"with,comma\"\n\nlast,\n",
+		"/data/users.jsonl": `{"username": "admin", "roles": ["a", "b"]}` + "\n\n" +
+			`{"username": "user"}`,
+		"/data/no-header.tsv": "a\tb\nc\td",
+	}
+
+	cases := map[string]string{
+		"csv with header": `
+			const ds = new SharedDataset("users.csv");
+			if (ds.length !== 3) throw new Error("bad length " + ds.length);
+			if (ds[0].username !== "admin" || ds[0].password !== "123") throw new Error("bad ds[0] " + JSON.stringify(ds[0]));
+			if (ds[1].username !== "multi\nline" || ds[1].password !== "with,comma") throw new Error("bad ds[1] " + JSON.stringify(ds[1]));
+			if (ds[2].username !== "last" || ds[2].password !== "") throw new Error("bad ds[2] " + JSON.stringify(ds[2]));
+			if (ds[3] !== undefined) throw new Error("ds[3] should be undefined");
+		`,
+		"jsonl": `
+			const ds = new SharedDataset("/data/users.jsonl");
+			if (ds.length !== 2) throw new Error("bad length " + ds.length);
+			if (ds[0].roles[1] !== "b") throw new Error("bad ds[0] " + JSON.stringify(ds[0]));
+			const names = [];
+			for (const row of ds) names.push(row.username);
+			if (names.join() !== "admin,user") throw new Error("bad iteration " + names.join());
+		`,
+		"csv without header": `
+			const ds = new SharedDataset("no-header.tsv", { delimiter: "\t", header: false });
+			if (ds.length !== 2) throw new Error("bad length " + ds.length);
+			if (ds[1][0] !== "c" || ds[1][1] !== "d") throw new Error("bad ds[1] " + JSON.stringify(ds[1]));
+		`,
+	}
+
+	for name, code := range cases {
+		code := code
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := newDatasetRuntime(t, files)
+			_, err := runtime.VU.Runtime().RunString(code)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSharedDatasetExceptions(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"/data/users.csv": "username\nadmin\n",
+		"/data/bad.csv":   "a,\"b\nc",
+	}
+
+	cases := map[string]struct {
+		code, err string
+	}{
+		"empty path": {
+			code: `new SharedDataset()`,
+			err:  "empty path provided to SharedDataset's constructor",
+		},
+		"missing file": {
+			code: `new SharedDataset("missing.csv")`,
+			err:  `couldn't read the dataset "/data/missing.csv"`,
+		},
+		"unsupported format": {
+			code: `new SharedDataset("users.csv", { format: "xml" })`,
+			err:  `unsupported SharedDataset format "xml"`,
+		},
+		"bad delimiter": {
+			code: `new SharedDataset("users.csv", { delimiter: ";;" })`,
+			err:  "the SharedDataset delimiter should be a single character",
+		},
+		"invalid csv": {
+			code: `new SharedDataset("bad.csv")`,
+			err:  "couldn't parse the dataset",
+		},
+		"modifying a row": {
+			code: `'use strict'; new SharedDataset("users.csv")[0].username = "bad"`,
+			err:  "Cannot assign to read only property 'username'",
+		},
+		"setting an index": {
+			code: `'use strict'; new SharedDataset("users.csv")[0] = {}`,
+			err:  "SharedDataset is immutable",
+		},
+	}
+
+	for name, testCase := range cases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := newDatasetRuntime(t, files)
+			_, err := runtime.VU.Runtime().RunString(testCase.code)
+			require.Error(t, err)
+			exc := new(sobek.Exception)
+			require.True(t, errors.As(err, &exc))
+			assert.Contains(t, exc.Error(), testCase.err)
+		})
+	}
+}
+
+func TestSharedDatasetNotInInitContext(t *testing.T) {
+	t.Parallel()
+
+	runtime := newDatasetRuntime(t, map[string]string{"/data/users.csv": "username\nadmin\n"})
+	runtime.MoveToVUContext(&lib.State{})
+
+	_, err := runtime.VU.Runtime().RunString(`new SharedDataset("users.csv")`)
+	require.ErrorContains(t, err, "new SharedDataset must be called in the init context")
+}
+
+func TestSharedDatasetIsShared(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{"/data/users.csv": "username\nadmin\n"}
+	first := newDatasetRuntime(t, files)
+	_, err := first.VU.Runtime().RunString(`new SharedDataset("users.csv")`)
+	require.NoError(t, err)
+
+	// the file was removed, but the already loaded dataset is reused
+	second, err := configuredRuntimeFromAnother(t, first)
+	require.NoError(t, err)
+	second.VU.InitEnvField.FileSystems = map[string]fsext.Fs{"file": fsext.NewMemMapFs()}
+	second.VU.InitEnvField.CWD = &url.URL{Scheme: "file", Path: "/data/"}
+	_, err = second.VU.Runtime().RunString(`
+		const ds = new data.SharedDataset("users.csv");
+		if (ds[0].username !== "admin") throw new Error("bad ds[0] " + JSON.stringify(ds[0]));
+	`)
+	require.NoError(t, err)
+}
@@ -62,11 +62,16 @@ func (s wrappedSharedArray) Len() int {
 }
 
 func (s wrappedSharedArray) deepFreeze(rt *sobek.Runtime, val sobek.Value) error {
+	return deepFreeze(rt, s.freeze, s.isFrozen, val)
+}
+
+// deepFreeze freezes the value and all of the objects it references.
+func deepFreeze(rt *sobek.Runtime, freeze, isFrozen sobek.Callable, val sobek.Value) error {
 	if val != nil && sobek.IsNull(val) {
 		return nil
 	}
 
-	_, err := s.freeze(sobek.Undefined(), val)
+	_, err := freeze(sobek.Undefined(), val)
 	if err != nil {
 		return err
 	}
@@ -79,12 +84,12 @@ func (s wrappedSharedArray) deepFreeze(rt *sobek.Runtime, val sobek.Value) error
 		prop := o.Get(key)
 		if prop != nil {
 			// isFrozen returns true for all non objects so it we don't need to check that
-			frozen, err := s.isFrozen(sobek.Undefined(), prop)
+			frozen, err := isFrozen(sobek.Undefined(), prop)
 			if err != nil {
 				return err
 			}
 			if !frozen.ToBoolean() { // prevent cycles
-				if err = s.deepFreeze(rt, prop); err != nil {
+				if err = deepFreeze(rt, freeze, isFrozen, prop); err != nil {
 					return err
 				}
 			}
@@ -10,9 +10,12 @@ import (
 	"github.com/grafana/sobek"
 
 	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
+	k6execution "go.k6.io/k6/execution"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
 
@@ -161,7 +164,14 @@ func (mi *ModuleInstance) newInstanceInfo() (*sobek.Object, error) {
 }
 
 //nolint:gochecknoglobals
-var testInfoInitContextErr = common.NewInitContextError("getting test options in the init context is not supported")
+var (
+	testInfoInitContextErr = common.NewInitContextError("getting test options in the init context is not supported")
+	testFailInitContextErr = common.NewInitContextError("marking the test as failed in the init context is not supported")
+)
+
+// testFailed is the reason the test run is marked as failed for, when the
+// script calls test.fail() without a reason.
+const testFailed = "test failed"
 
 // newTestInfo returns a sobek.Object with property accessors to retrieve
 // information and control execution of the overall test run.
@@ -173,14 +183,31 @@ func (mi *ModuleInstance) newTestInfo() (*sobek.Object, error) {
 	ti := map[string]func() interface{}{
 		// stop the test run
 		"abort": func() interface{} {
-			return func(msg sobek.Value) {
+			return func(msg sobek.Value, options sobek.Value) {
 				reason := errext.AbortTest
 				if msg != nil && !sobek.IsUndefined(msg) {
 					reason = fmt.Sprintf("%s: %s", reason, msg.String())
 				}
-				rt.Interrupt(&errext.InterruptError{Reason: reason})
+				mi.abortTest(&errext.InterruptError{Reason: reason}, options)
+			}
+		},
+		// mark the test run as failed, without stopping it
+		"fail": func() interface{} {
+			return func(msg sobek.Value) {
+				es := lib.GetExecutionState(mi.vu.Context())
+				if es == nil || mi.vu.State() == nil {
+					common.Throw(rt, testFailInitContextErr)
+				}
+				reason := testFailed
+				if msg != nil && !sobek.IsUndefined(msg) {
+					reason = fmt.Sprintf("%s: %s", reason, msg.String())
+				}
+				es.MarkFailed(reason)
 			}
 		},
+		"store": func() interface{} {
+			return mi.newStore()
+		},
 		"options": func() interface{} {
 			vuState := mi.vu.State()
 			if vuState == nil {
@@ -200,6 +227,81 @@ func (mi *ModuleInstance) newTestInfo() (*sobek.Object, error) {
 	return newInfoObj(rt, ti)
 }
 
+// abortTest aborts the test run with the error, which gets the exit code of
+// the options, if any. With a graceful stop, the iterations in progress,
+// including the current one, get its duration to finish, instead of being
+// interrupted.
+func (mi *ModuleInstance) abortTest(interruptErr *errext.InterruptError, options sobek.Value) {
+	rt := mi.vu.Runtime()
+	gracefulStop := time.Duration(-1)
+	if !common.IsNullish(options) {
+		opts := options.ToObject(rt)
+		if v := opts.Get("exitCode"); !common.IsNullish(v) {
+			code := v.ToInteger()
+			if code < 1 || code > 255 {
+				common.Throw(rt, fmt.Errorf("invalid exitCode %d, it needs to be between 1 and 255", code))
+			}
+			interruptErr.Code = exitcodes.ExitCode(code)
+		}
+		if v := opts.Get("gracefulStop"); !common.IsNullish(v) {
+			var err error
+			gracefulStop, err = types.GetDurationValue(v.Export())
+			if err != nil {
+				common.Throw(rt, fmt.Errorf("invalid gracefulStop: %w", err))
+			}
+			if gracefulStop < 0 {
+				common.Throw(rt, errors.New("invalid gracefulStop: it can't be negative"))
+			}
+		}
+	}
+
+	// the test run can only be stopped gracefully from the VU context
+	if gracefulStop >= 0 && mi.vu.State() != nil &&
+		k6execution.StopTestRun(mi.vu.Context(), interruptErr, gracefulStop) {
+		return
+	}
+	rt.Interrupt(interruptErr)
+}
+
+//nolint:gochecknoglobals
+var storeInitContextErr = common.NewInitContextError("using the test store in the init context is not supported")
+
+// newStore returns a sobek.Object to set and get JSON-encodable values shared
+// by all the VUs of the instance, e.g. for a scenario to pass data to the ones
+// depending on it. It's the same store as the k6/experimental/store module's.
+func (mi *ModuleInstance) newStore() *sobek.Object {
+	rt := mi.vu.Runtime()
+	es := lib.GetExecutionState(mi.vu.Context())
+	if es == nil {
+		common.Throw(rt, storeInitContextErr)
+	}
+
+	o := rt.NewObject()
+	must := func(err error) {
+		if err != nil {
+			common.Throw(rt, err)
+		}
+	}
+	must(o.Set("set", func(key string, value sobek.Value) {
+		data, err := json.Marshal(value.Export())
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("the value of the %q key can't be encoded to JSON: %w", key, err))
+		}
+		es.SetSharedData(key, data)
+	}))
+	must(o.Set("get", func(key string) sobek.Value {
+		data, ok := es.GetSharedData(key)
+		if !ok {
+			return sobek.Undefined()
+		}
+		var value interface{}
+		must(json.Unmarshal(data, &value))
+		return rt.ToValue(value)
+	}))
+
+	return o
+}
+
 //nolint:gochecknoglobals
 var vuInfoInitContextErr = common.NewInitContextError("getting VU information in the init context is not supported")
 
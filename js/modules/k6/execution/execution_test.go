@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.k6.io/k6/errext"
+	"go.k6.io/k6/errext/exitcodes"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modulestest"
 	"go.k6.io/k6/lib"
@@ -218,12 +219,100 @@ func TestAbortTest(t *testing.T) { //nolint:tparallel
 	t.Run("custom reason", func(t *testing.T) { //nolint:paralleltest
 		prove(t, `exec.test.abort("mayday")`, fmt.Sprintf("%s: mayday", errext.AbortTest))
 	})
+	t.Run("exit code", func(t *testing.T) { //nolint:paralleltest
+		_, err := rt.RunString(`exec.test.abort("mayday", { exitCode: 42 })`)
+		var x *sobek.InterruptedError
+		require.ErrorAs(t, err, &x)
+		v, ok := x.Value().(*errext.InterruptError)
+		require.True(t, ok)
+		assert.Equal(t, exitcodes.ExitCode(42), v.ExitCode())
+	})
+	t.Run("invalid options", func(t *testing.T) { //nolint:paralleltest
+		_, err := rt.RunString(`exec.test.abort("mayday", { exitCode: 256 })`)
+		assert.ErrorContains(t, err, "invalid exitCode 256, it needs to be between 1 and 255")
+		_, err = rt.RunString(`exec.test.abort("mayday", { gracefulStop: "-1s" })`)
+		assert.ErrorContains(t, err, "invalid gracefulStop: it can't be negative")
+	})
+}
+
+func TestFailTest(t *testing.T) {
+	t.Parallel()
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+
+	rt := sobek.New()
+	es := lib.NewExecutionState(nil, et, 0, 0)
+	m, ok := New().NewModuleInstance(
+		&modulestest.VU{
+			RuntimeField: rt,
+			CtxField:     lib.WithExecutionState(context.Background(), es),
+			StateField:   &lib.State{},
+		},
+	).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("exec", m.Exports().Default))
+
+	_, err = rt.RunString(`exec.test.fail(); exec.test.fail("bad data"); "still running"`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test failed", "test failed: bad data"}, es.GetFailureReasons())
+}
+
+func TestTestStore(t *testing.T) {
+	t.Parallel()
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+
+	rt := sobek.New()
+	es := lib.NewExecutionState(nil, et, 0, 0)
+	m, ok := New().NewModuleInstance(
+		&modulestest.VU{
+			RuntimeField: rt,
+			CtxField:     lib.WithExecutionState(context.Background(), es),
+			StateField:   &lib.State{},
+		},
+	).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("exec", m.Exports().Default))
+
+	_, err = rt.RunString(`exec.test.store.set("token", {value: "abc", ttl: 60})`)
+	require.NoError(t, err)
+	data, ok := es.GetSharedData("token")
+	require.True(t, ok)
+	assert.JSONEq(t, `{"value":"abc","ttl":60}`, string(data))
+
+	value, err := rt.RunString(`exec.test.store.get("token").value`)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", value.String())
+
+	value, err = rt.RunString(`exec.test.store.get("missing")`)
+	require.NoError(t, err)
+	assert.True(t, sobek.IsUndefined(value))
+}
+
+func TestTestStoreNoAvailableInInitContext(t *testing.T) {
+	t.Parallel()
+
+	rt := sobek.New()
+	m, ok := New().NewModuleInstance(
+		&modulestest.VU{
+			RuntimeField: rt,
+			InitEnvField: &common.InitEnvironment{},
+			CtxField:     context.Background(),
+		},
+	).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("exec", m.Exports().Default))
+
+	_, err := rt.RunString(`exec.test.store`)
+	require.ErrorContains(t, err, "using the test store in the init context is not supported")
 }
 
 func TestOptionsTestFull(t *testing.T) {
 	t.Parallel()
 
-	expected := `{"paused":true,"scenarios":{"const-vus":{"executor":"constant-vus","options":{"browser":{"someOption":true}},"startTime":"10s","gracefulStop":"30s","env":{"FOO":"bar"},"exec":"default","tags":{"tagkey":"tagvalue"},"vus":50,"duration":"10m0s"}},"executionSegment":"0:1/4","executionSegmentSequence":"0,1/4,1/2,1","noSetup":true,"setupTimeout":"1m0s","noTeardown":true,"teardownTimeout":"5m0s","rps":100,"dns":{"ttl":"1m","select":"roundRobin","policy":"any"},"maxRedirects":3,"userAgent":"k6-user-agent","batch":15,"batchPerHost":5,"httpDebug":"full","insecureSkipTLSVerify":true,"tlsCipherSuites":["TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"],"tlsVersion":{"min":"tls1.2","max":"tls1.3"},"tlsAuth":[{"domains":["example.com"],"cert":"mycert.pem","key":"mycert-key.pem","password":"mypwd"}],"throw":true,"thresholds":{"http_req_duration":[{"threshold":"rate>0.01","abortOnFail":true,"delayAbortEval":"10s"}]},"blacklistIPs":["192.0.2.0/24"],"blockHostnames":["test.k6.io","*.example.com"],"hosts":{"test.k6.io":"1.2.3.4:8443"},"noConnectionReuse":true,"noVUConnectionReuse":true,"minIterationDuration":"10s","ext":{"ext-one":{"rawkey":"rawvalue"}},"summaryTrendStats":["avg","min","max"],"summaryTimeUnit":"ms","systemTags":["iter","vu"],"tags":null,"metricSamplesBufferSize":8,"noCookiesReset":true,"discardResponseBodies":true,"consoleOutput":"loadtest.log","tags":{"runtag-key":"runtag-value"},"localIPs":"192.168.20.12-192.168.20.15,192.168.10.0/27"}`
+	expected := `{"paused":true,"scenarios":{"const-vus":{"executor":"constant-vus","options":{"browser":{"someOption":true}},"startTime":"10s","gracefulStop":"30s","env":{"FOO":"bar"},"exec":"default","tags":{"tagkey":"tagvalue"},"vus":50,"duration":"10m0s"}},"executionSegment":"0:1/4","executionSegmentSequence":"0,1/4,1/2,1","noSetup":true,"setupTimeout":"1m0s","noTeardown":true,"teardownTimeout":"5m0s","rps":100,"dns":{"ttl":"1m","select":"roundRobin","policy":"any"},"maxRedirects":3,"userAgent":"k6-user-agent","batch":15,"batchPerHost":5,"httpDebug":"full","tracePropagator":"w3c","traceSampling":0.5,"insecureSkipTLSVerify":true,"tlsCipherSuites":["TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"],"tlsVersion":{"min":"tls1.2","max":"tls1.3"},"tlsAuth":[{"domains":["example.com"],"cert":"mycert.pem","key":"mycert-key.pem","password":"mypwd"}],"throw":true,"thresholds":{"http_req_duration":[{"threshold":"rate>0.01","abortOnFail":true,"delayAbortEval":"10s"}]},"blacklistIPs":["192.0.2.0/24"],"blockHostnames":["test.k6.io","*.example.com"],"hosts":{"test.k6.io":"1.2.3.4:8443"},"urlGrouping":[{"path":"/users/{id}"}],"noConnectionReuse":true,"noVUConnectionReuse":true,"maxIdleConnsPerHost":4,"maxRequestsPerConnection":100,"minIterationDuration":"10s","ext":{"ext-one":{"rawkey":"rawvalue"}},"summaryTrendStats":["avg","min","max"],"summaryTimeUnit":"ms","exactTrendPercentiles":true,"trendPercentilesPrecision":4,"systemTags":["iter","vu"],"tags":null,"metricSamplesBufferSize":8,"metricCardinalityLimit":5000,"tagCardinalityLimit":100,"loadGeneratorMetrics":true,"redact":[{"tags":["user"]}],"noCookiesReset":true,"discardResponseBodies":true,"consoleOutput":"loadtest.log","tags":{"runtag-key":"runtag-value"},"localIPs":"192.168.20.12-192.168.20.15,192.168.10.0/27"}`
 
 	var (
 		rt    = sobek.New()
@@ -264,23 +353,27 @@ func TestOptionsTestFull(t *testing.T) {
 					require.NoError(t, err)
 					return &seq
 				}(),
-				NoSetup:               null.BoolFrom(true),
-				NoTeardown:            null.BoolFrom(true),
-				NoConnectionReuse:     null.BoolFrom(true),
-				NoVUConnectionReuse:   null.BoolFrom(true),
-				InsecureSkipTLSVerify: null.BoolFrom(true),
-				Throw:                 null.BoolFrom(true),
-				NoCookiesReset:        null.BoolFrom(true),
-				DiscardResponseBodies: null.BoolFrom(true),
-				RPS:                   null.IntFrom(100),
-				MaxRedirects:          null.IntFrom(3),
-				UserAgent:             null.StringFrom("k6-user-agent"),
-				Batch:                 null.IntFrom(15),
-				BatchPerHost:          null.IntFrom(5),
-				SetupTimeout:          types.NullDurationFrom(1 * time.Minute),
-				TeardownTimeout:       types.NullDurationFrom(5 * time.Minute),
-				MinIterationDuration:  types.NullDurationFrom(10 * time.Second),
-				HTTPDebug:             null.StringFrom("full"),
+				NoSetup:                  null.BoolFrom(true),
+				NoTeardown:               null.BoolFrom(true),
+				NoConnectionReuse:        null.BoolFrom(true),
+				NoVUConnectionReuse:      null.BoolFrom(true),
+				MaxIdleConnsPerHost:      null.IntFrom(4),
+				MaxRequestsPerConnection: null.IntFrom(100),
+				InsecureSkipTLSVerify:    null.BoolFrom(true),
+				Throw:                    null.BoolFrom(true),
+				NoCookiesReset:           null.BoolFrom(true),
+				DiscardResponseBodies:    null.BoolFrom(true),
+				RPS:                      null.IntFrom(100),
+				MaxRedirects:             null.IntFrom(3),
+				UserAgent:                null.StringFrom("k6-user-agent"),
+				Batch:                    null.IntFrom(15),
+				BatchPerHost:             null.IntFrom(5),
+				SetupTimeout:             types.NullDurationFrom(1 * time.Minute),
+				TeardownTimeout:          types.NullDurationFrom(5 * time.Minute),
+				MinIterationDuration:     types.NullDurationFrom(10 * time.Second),
+				HTTPDebug:                null.StringFrom("full"),
+				TracePropagator:          null.StringFrom("w3c"),
+				TraceSampling:            null.FloatFrom(0.5),
 				DNS: types.DNSConfig{
 					TTL:    null.StringFrom("1m"),
 					Select: types.NullDNSSelect{DNSSelect: types.DNSroundRobin, Valid: true},
@@ -340,18 +433,33 @@ func TestOptionsTestFull(t *testing.T) {
 					require.NoError(t, err)
 					return hs
 				}(),
+				URLGrouping: func() types.NullURLGrouping {
+					ug, err := types.NewNullURLGrouping([]types.URLGroupingRule{{Path: "/users/{id}"}})
+					require.NoError(t, err)
+					return ug
+				}(),
 				External: map[string]json.RawMessage{
 					"ext-one": json.RawMessage(`{"rawkey":"rawvalue"}`),
 				},
-				SummaryTrendStats: []string{"avg", "min", "max"},
-				SummaryTimeUnit:   null.StringFrom("ms"),
+				SummaryTrendStats:         []string{"avg", "min", "max"},
+				SummaryTimeUnit:           null.StringFrom("ms"),
+				ExactTrendPercentiles:     null.BoolFrom(true),
+				TrendPercentilesPrecision: null.IntFrom(4),
 				SystemTags: func() *metrics.SystemTagSet {
 					sysm := metrics.SystemTagSet(metrics.TagIter | metrics.TagVU)
 					return &sysm
 				}(),
 				RunTags:                 map[string]string{"runtag-key": "runtag-value"},
 				MetricSamplesBufferSize: null.IntFrom(8),
-				ConsoleOutput:           null.StringFrom("loadtest.log"),
+				MetricCardinalityLimit:  null.IntFrom(5000),
+				TagCardinalityLimit:     null.IntFrom(100),
+				LoadGeneratorMetrics:    null.BoolFrom(true),
+				Redact: func() types.NullRedaction {
+					r, err := types.NewNullRedaction([]types.RedactionRule{{Tags: []string{"user"}}})
+					require.NoError(t, err)
+					return r
+				}(),
+				ConsoleOutput: null.StringFrom("loadtest.log"),
 				LocalIPs: func() types.NullIPPool {
 					npool := types.NullIPPool{}
 					err := npool.UnmarshalText([]byte("192.168.20.12-192.168.20.15,192.168.10.0/27"))
@@ -0,0 +1,254 @@
+// Package channels provides a k6 module for publishing messages to, and
+// subscribing to messages from, named topics which are shared by all VUs of a
+// k6 instance, so they can signal each other without an external broker.
+package channels
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/types"
+)
+
+// defaultBufferSize is the default number of messages that a subscription
+// keeps until they are received, newer messages are dropped.
+const defaultBufferSize = 100
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU. It holds the subscriptions of all VUs.
+	RootModule struct {
+		mu     sync.RWMutex
+		topics map[string]map[*subscription]struct{}
+	}
+
+	// ModuleInstance represents an instance of the channels module for a single VU.
+	ModuleInstance struct {
+		vu   modules.VU
+		root *RootModule
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{
+		topics: make(map[string]map[*subscription]struct{}),
+	}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu, root: rm}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"publish":   mi.publish,
+			"subscribe": mi.subscribe,
+		},
+	}
+}
+
+// publish sends the message to all current subscribers of the topic, and
+// returns the number of subscriptions which received it.
+func (mi *ModuleInstance) publish(topic string, message sobek.Value) int {
+	rt := mi.vu.Runtime()
+	if mi.vu.State() == nil {
+		common.Throw(rt, common.NewInitContextError("publishing messages in the init context is not supported"))
+	}
+	if common.IsNullish(message) {
+		common.Throw(rt, fmt.Errorf("the message published to the %q topic can't be undefined or null", topic))
+	}
+	data, err := json.Marshal(message.Export())
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("the message published to the %q topic can't be encoded to JSON: %w", topic, err))
+	}
+
+	mi.root.mu.RLock()
+	defer mi.root.mu.RUnlock()
+
+	delivered := 0
+	for sub := range mi.root.topics[topic] {
+		select {
+		case sub.messages <- data:
+			delivered++
+		default:
+			// the buffer of the subscription is full
+		}
+	}
+	return delivered
+}
+
+// subscribe returns a new subscription to the topic, which receives all
+// messages published after it was created.
+func (mi *ModuleInstance) subscribe(topic string, options sobek.Value) *sobek.Object {
+	rt := mi.vu.Runtime()
+	bufferSize, err := parseSubscribeOptions(rt, options)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	sub := &subscription{
+		vu:       mi.vu,
+		root:     mi.root,
+		topic:    topic,
+		messages: make(chan []byte, bufferSize),
+		done:     make(chan struct{}),
+	}
+
+	mi.root.mu.Lock()
+	if mi.root.topics[topic] == nil {
+		mi.root.topics[topic] = make(map[*subscription]struct{})
+	}
+	mi.root.topics[topic][sub] = struct{}{}
+	mi.root.mu.Unlock()
+
+	obj := rt.NewObject()
+	for k, v := range map[string]any{
+		"topic":   topic,
+		"receive": sub.receive,
+		"close":   sub.close,
+	} {
+		if err := obj.Set(k, v); err != nil {
+			common.Throw(rt, err)
+		}
+	}
+	return obj
+}
+
+func parseSubscribeOptions(rt *sobek.Runtime, options sobek.Value) (int, error) {
+	if common.IsNullish(options) {
+		return defaultBufferSize, nil
+	}
+	buffer := options.ToObject(rt).Get("buffer")
+	if common.IsNullish(buffer) {
+		return defaultBufferSize, nil
+	}
+	size := buffer.ToInteger()
+	if size < 1 {
+		return 0, fmt.Errorf("the buffer of a subscription should be at least 1, but it is %d", size)
+	}
+	return int(size), nil
+}
+
+// subscription is a single subscription to a topic.
+type subscription struct {
+	vu       modules.VU
+	root     *RootModule
+	topic    string
+	messages chan []byte
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// receive returns a promise that resolves to the next message of the
+// subscription. The options can hold a `timeout` for the message to be
+// received within.
+func (s *subscription) receive(options sobek.Value) *sobek.Promise {
+	rt := s.vu.Runtime()
+	if s.vu.State() == nil {
+		common.Throw(rt, common.NewInitContextError("receiving messages in the init context is not supported"))
+	}
+	promise, resolve, reject := rt.NewPromise()
+
+	timeout, err := parseReceiveOptions(rt, options)
+	if err != nil {
+		reject(fmt.Errorf("receive() failed; reason: %w", err))
+		return promise
+	}
+
+	select {
+	case <-s.done:
+		reject(fmt.Errorf("receive() failed; reason: the subscription to the %q topic is closed", s.topic))
+		return promise
+	default:
+	}
+
+	callback := s.vu.RegisterCallback()
+	go func() {
+		var timeoutCh <-chan time.Time
+		if timeout > 0 {
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			timeoutCh = timer.C
+		}
+
+		select {
+		case data := <-s.messages:
+			callback(func() error {
+				var message any
+				if err := json.Unmarshal(data, &message); err != nil {
+					reject(err)
+					return nil
+				}
+				resolve(rt.ToValue(message))
+				return nil
+			})
+		case <-timeoutCh:
+			callback(func() error {
+				reject(fmt.Errorf("receive() failed; reason: no message in the %q topic was received within %s",
+					s.topic, timeout))
+				return nil
+			})
+		case <-s.done:
+			callback(func() error {
+				reject(fmt.Errorf("receive() failed; reason: the subscription to the %q topic is closed", s.topic))
+				return nil
+			})
+		case <-s.vu.Context().Done():
+			callback(func() error { return nil })
+		}
+	}()
+
+	return promise
+}
+
+func parseReceiveOptions(rt *sobek.Runtime, options sobek.Value) (time.Duration, error) {
+	if common.IsNullish(options) {
+		return 0, nil
+	}
+	timeout := options.ToObject(rt).Get("timeout")
+	if common.IsNullish(timeout) {
+		return 0, nil
+	}
+	d, err := types.GetDurationValue(timeout.Export())
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout: %w", err)
+	}
+	if d <= 0 {
+		return 0, errors.New("the timeout should be positive")
+	}
+	return d, nil
+}
+
+// close unsubscribes from the topic, any pending and future receive() calls
+// are rejected.
+func (s *subscription) close() {
+	s.closeOnce.Do(func() {
+		s.root.mu.Lock()
+		delete(s.root.topics[s.topic], s)
+		if len(s.root.topics[s.topic]) == 0 {
+			delete(s.root.topics, s.topic)
+		}
+		s.root.mu.Unlock()
+
+		close(s.done)
+	})
+}
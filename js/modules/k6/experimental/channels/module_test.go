@@ -0,0 +1,164 @@
+package channels
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+)
+
+func newChannelsRuntime(t *testing.T, root *RootModule) *modulestest.Runtime {
+	t.Helper()
+
+	runtime := modulestest.NewRuntime(t)
+	err := runtime.SetupModuleSystem(map[string]any{"k6/experimental/channels": root}, nil, nil)
+	require.NoError(t, err)
+	_, err = runtime.VU.Runtime().RunString(`var channels = require("k6/experimental/channels");`)
+	require.NoError(t, err)
+	runtime.MoveToVUContext(&lib.State{})
+
+	return runtime
+}
+
+func TestChannels(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"publish and receive": `
+			const sub = channels.subscribe("tokens");
+			if (sub.topic !== "tokens") throw new Error("bad topic " + sub.topic);
+			if (channels.publish("tokens", { token: "abc" }) !== 1) throw new Error("the message wasn't delivered");
+			if (channels.publish("other", "ignored") !== 0) throw new Error("the message was delivered to a wrong topic");
+			const msg = await sub.receive();
+			if (msg.token !== "abc") throw new Error("bad message " + JSON.stringify(msg));
+		`,
+		"multiple subscribers": `
+			const first = channels.subscribe("phase");
+			const second = channels.subscribe("phase");
+			if (channels.publish("phase", 2) !== 2) throw new Error("the message wasn't delivered to both");
+			if (await first.receive() !== 2 || await second.receive() !== 2) throw new Error("bad messages");
+		`,
+		"full buffer": `
+			const sub = channels.subscribe("topic", { buffer: 1 });
+			if (channels.publish("topic", 1) !== 1) throw new Error("the first message wasn't delivered");
+			if (channels.publish("topic", 2) !== 0) throw new Error("the second message was delivered");
+			if (await sub.receive() !== 1) throw new Error("bad message");
+		`,
+		"receive timeout": `
+			const sub = channels.subscribe("topic");
+			try {
+				await sub.receive({ timeout: "10ms" });
+				throw new Error("receive() should have timed out");
+			} catch (e) {
+				if (!e.toString().includes("no message in the \"topic\" topic was received within 10ms")) throw e;
+			}
+		`,
+		"close": `
+			const sub = channels.subscribe("topic");
+			const isClosed = (e) => e.toString().includes("the subscription to the \"topic\" topic is closed");
+			const pending = sub.receive().then(() => false, isClosed);
+			sub.close();
+			sub.close();
+			if (channels.publish("topic", 1) !== 0) throw new Error("the message was delivered to a closed subscription");
+			if (!await pending) throw new Error("the pending receive() should have failed");
+			if (!await sub.receive().then(() => false, isClosed)) throw new Error("receive() should have failed");
+		`,
+	}
+
+	for name, code := range cases {
+		code := code
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := newChannelsRuntime(t, New())
+			_, err := runtime.RunOnEventLoop(`(async () => {` + code + `})()`)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestChannelsExceptions(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		code, err string
+	}{
+		"undefined message": {
+			code: `channels.publish("topic")`,
+			err:  `the message published to the "topic" topic can't be undefined or null`,
+		},
+		"invalid buffer": {
+			code: `channels.subscribe("topic", { buffer: 0 })`,
+			err:  "the buffer of a subscription should be at least 1",
+		},
+	}
+
+	for name, testCase := range cases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := newChannelsRuntime(t, New())
+			_, err := runtime.VU.Runtime().RunString(testCase.code)
+			require.ErrorContains(t, err, testCase.err)
+		})
+	}
+}
+
+func TestChannelsInInitContext(t *testing.T) {
+	t.Parallel()
+
+	runtime := modulestest.NewRuntime(t)
+	err := runtime.SetupModuleSystem(map[string]any{"k6/experimental/channels": New()}, nil, nil)
+	require.NoError(t, err)
+
+	_, err = runtime.VU.Runtime().RunString(`
+		var channels = require("k6/experimental/channels");
+		var sub = channels.subscribe("topic");
+	`)
+	require.NoError(t, err, "subscribing in the init context should be allowed")
+
+	_, err = runtime.VU.Runtime().RunString(`channels.publish("topic", 1)`)
+	require.ErrorContains(t, err, "publishing messages in the init context is not supported")
+
+	_, err = runtime.VU.Runtime().RunString(`sub.receive()`)
+	require.ErrorContains(t, err, "receiving messages in the init context is not supported")
+}
+
+func TestChannelsBetweenVUs(t *testing.T) {
+	t.Parallel()
+
+	const vus = 5
+	root := New()
+	runtimes := make([]*modulestest.Runtime, vus)
+	for i := range runtimes {
+		runtimes[i] = newChannelsRuntime(t, root)
+		_, err := runtimes[i].VU.Runtime().RunString(`var sub = channels.subscribe("start");`)
+		require.NoError(t, err)
+	}
+
+	wg := &sync.WaitGroup{}
+	for _, runtime := range runtimes {
+		runtime := runtime
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := runtime.RunOnEventLoop(`(async () => {
+				const msg = await sub.receive({ timeout: "5s" });
+				if (msg.phase !== 2) throw new Error("bad message " + JSON.stringify(msg));
+			})()`)
+			assert.NoError(t, err)
+		}()
+	}
+
+	publisher := newChannelsRuntime(t, root)
+	v, err := publisher.VU.Runtime().RunString(`channels.publish("start", { phase: 2 })`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(vus), v.ToInteger())
+
+	wg.Wait()
+}
@@ -0,0 +1,108 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/sobek"
+	"gopkg.in/guregu/null.v3"
+)
+
+// At returns a promise resolving to the data row at the provided zero-based
+// index, the header, or the skipped first line, excluded.
+//
+// The offset of each row is indexed on first use, in a single pass over the
+// file, so that subsequent calls only seek to the requested row. Rows are
+// returned as they would be by [Parser.Next], although the options skipping
+// rows, such as `fromLine` or `filter`, do not apply. The parser's position is
+// left unchanged.
+//
+// The promise is rejected if the index is out of range.
+func (p *Parser) At(index int64) *sobek.Promise {
+	return runAsync(p,
+		func() ([]string, error) {
+			records, err := p.at(index)
+			return p.detach(records), err
+		},
+		func(records []string) any {
+			return p.toValue(records)
+		},
+	)
+}
+
+// at reads the row at the provided index, and restores the parser's position.
+func (p *Parser) at(index int64) ([]string, error) {
+	var records []string
+
+	err := p.restoringPosition(func() error {
+		var err error
+		records, err = p.readAt(index)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// readAt reads the row at the provided index, indexing the rows' offsets first
+// if they were not yet.
+func (p *Parser) readAt(index int64) ([]string, error) {
+	if p.rowOffsets == nil {
+		offsets, err := p.indexRows()
+		if err != nil {
+			return nil, fmt.Errorf("failed to index the file's rows; reason: %w", err)
+		}
+
+		p.rowOffsets = offsets
+	}
+
+	if index < 0 || index >= int64(len(p.rowOffsets)) {
+		return nil, fmt.Errorf("row index %d is out of range, as the file holds %d rows", index, len(p.rowOffsets))
+	}
+
+	// The line is the one the row would start on, were no row spanning multiple
+	// lines, and is only reported by errors.
+	if err := p.seek(cursor{Line: p.dataStart.Line + index, ByteOffset: p.rowOffsets[index]}); err != nil {
+		return nil, err
+	}
+
+	records, err := p.read()
+	if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+		return nil, err
+	}
+
+	// The record might be held by the parser's record buffer, which is reused.
+	return append([]string(nil), records...), nil
+}
+
+// indexRows returns the offset of each row following the header.
+func (p *Parser) indexRows() ([]int64, error) {
+	if _, err := p.source.Seek(p.dataStart.ByteOffset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	// Every row is indexed, regardless of its number of fields.
+	options := p.options
+	options.FieldsPerRecord = null.IntFrom(-1)
+	reader := newRecordReader(p.source, options, p.logger)
+
+	offsets := []int64{}
+	for {
+		start := p.dataStart.ByteOffset + reader.InputOffset()
+
+		_, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return offsets, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		offsets = append(offsets, start)
+	}
+}
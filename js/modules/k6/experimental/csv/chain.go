@@ -0,0 +1,313 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/grafana/sobek"
+	"gopkg.in/guregu/null.v3"
+)
+
+// newSourcesFrom creates the sources to read from out of the provided Sobek
+// value, which is expected to be either a single source, as supported by
+// [newSourceFrom], or an array of them.
+func newSourcesFrom(rt *sobek.Runtime, v sobek.Value) ([]source, error) {
+	obj, ok := v.(*sobek.Object)
+	if !ok || obj.ClassName() != "Array" {
+		src, err := newSourceFrom(rt, v)
+		if err != nil {
+			return nil, err
+		}
+
+		return []source{src}, nil
+	}
+
+	var values []sobek.Value
+	if err := rt.ExportTo(v, &values); err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		return nil, errors.New("first argument expected to hold at least one source, got an empty array instead")
+	}
+
+	srcs := make([]source, 0, len(values))
+	for i, value := range values {
+		src, err := newSourceFrom(rt, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source at index %d; reason: %w", i, err)
+		}
+
+		srcs = append(srcs, src)
+	}
+
+	return srcs, nil
+}
+
+// prepareSources decompresses, then decodes, the provided sources as requested
+// by the options, and chains them into a single one if there are several.
+func prepareSources(srcs []source, options parserOptions) (source, error) {
+	for i, src := range srcs {
+		src, err := src.decompress(options.Compression)
+		if err != nil {
+			return source{}, err
+		}
+
+		if options.Encoding != encodingUTF8 {
+			if src, err = src.decode(options.Encoding); err != nil {
+				return source{}, err
+			}
+		}
+
+		srcs[i] = src
+	}
+
+	if len(srcs) == 1 {
+		return srcs[0], nil
+	}
+
+	return chainSources(srcs, options)
+}
+
+// chainSources returns a source reading the provided sources one after the
+// other, as a single file.
+//
+// Files are expected to share the same layout: the byte order mark, metadata
+// lines, and first line, as consumed through the `header` or `skipFirstLine`
+// option, of the files following the first one are left out, so that they are
+// only read once, from the first file. Lines, and offsets, are thus counted
+// across files, as if the files were a single one.
+//
+// A line terminator is inserted after the files which do not end with one, so
+// that their last record is not joined with the following file's first one.
+func chainSources(srcs []source, options parserOptions) (source, error) {
+	chain := &chainedReader{}
+	paths := make([]string, 0, len(srcs))
+
+	for i, src := range srcs {
+		var start int64
+		if i > 0 {
+			var err error
+			if start, err = leadingLength(src, options); err != nil {
+				return source{}, fmt.Errorf("failed to skip the leading lines of source %d; reason: %w", i, err)
+			}
+		}
+
+		size := src.size()
+		chain.append(src, start, size-start)
+
+		terminated, err := endsWithNewline(src, size)
+		if err != nil {
+			return source{}, err
+		}
+
+		if !terminated && i < len(srcs)-1 {
+			chain.append(bytes.NewReader([]byte("\n")), 0, 1)
+		}
+
+		if src.isFile() {
+			paths = append(paths, src.path)
+		}
+	}
+
+	// A chain is only considered a file if each of its sources is.
+	var path string
+	if len(paths) == len(srcs) {
+		path = strings.Join(paths, ",")
+	}
+
+	return source{ReadSeeker: chain, path: path}, nil
+}
+
+// leadingLength returns the length of the content preceding the provided
+// source's first data line: its byte order mark, if the `stripBOM` option is
+// set, its metadata lines, and its first line, if the `header` or
+// `skipFirstLine` option is set.
+func leadingLength(src source, options parserOptions) (int64, error) {
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	var length int64
+	if options.StripBOM {
+		n, err := src.skipBOM()
+		if err != nil {
+			return 0, err
+		}
+
+		length += n
+	}
+
+	var r io.Reader = src
+	if options.MetadataLines.Valid && options.MetadataLines.Int64 > 0 {
+		br := bufio.NewReader(r)
+
+		_, n, err := readMetadataLines(br, options.MetadataLines.Int64)
+		if err != nil {
+			return 0, err
+		}
+
+		length += n
+		r = br
+	}
+
+	if options.Header || options.SkipFirstLine {
+		// The first line is read as a record, so that a quoted field spanning
+		// multiple lines is skipped altogether.
+		options.FieldsPerRecord = null.IntFrom(-1)
+		options.BestEffort = false
+		reader := newRecordReader(r, options, nil)
+
+		if _, err := reader.Read(); err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+
+		length += reader.InputOffset()
+	}
+
+	return length, nil
+}
+
+// endsWithNewline returns true if the provided source, of the given size, is
+// empty or ends with a line feed.
+func endsWithNewline(src source, size int64) (bool, error) {
+	if size == 0 {
+		return true, nil
+	}
+
+	if _, err := src.Seek(size-1, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	last := make([]byte, 1)
+	if _, err := io.ReadFull(src, last); err != nil {
+		return false, err
+	}
+
+	return last[0] == '\n', nil
+}
+
+// chainPart describes the section of a reader a [chainedReader] reads from.
+type chainPart struct {
+	r io.ReadSeeker
+
+	// start holds the offset, within r, the section starts at.
+	start int64
+
+	// length holds the length of the section.
+	length int64
+}
+
+// chainedReader is an [io.ReadSeeker] reading from a sequence of sections
+// of other readers, as if they were concatenated.
+type chainedReader struct {
+	parts []chainPart
+
+	// size holds the total length of the parts.
+	size int64
+
+	// pos holds the reader's position.
+	pos int64
+
+	// positioned indicates whether the part pos is in has been positioned at
+	// pos, which is needed after seeking, or moving to the next part.
+	positioned bool
+}
+
+var _ io.ReadSeeker = (*chainedReader)(nil)
+
+// append appends the section of the provided reader, starting at the given
+// offset, and of the given length, to the chain.
+func (cr *chainedReader) append(r io.ReadSeeker, start, length int64) {
+	cr.parts = append(cr.parts, chainPart{r: r, start: start, length: length})
+	cr.size += length
+}
+
+// Read implements the [io.Reader] interface.
+func (cr *chainedReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for {
+		if cr.pos >= cr.size {
+			return 0, io.EOF
+		}
+
+		part, offset := cr.locate(cr.pos)
+		if !cr.positioned {
+			if _, err := part.r.Seek(part.start+offset, io.SeekStart); err != nil {
+				return 0, err
+			}
+
+			cr.positioned = true
+		}
+
+		if remaining := part.length - offset; int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+
+		n, err := part.r.Read(p)
+		cr.pos += int64(n)
+
+		if int64(n) == part.length-offset || errors.Is(err, io.EOF) {
+			// The following read starts from the next part.
+			cr.positioned = false
+
+			if n == 0 && errors.Is(err, io.EOF) {
+				return 0, io.ErrUnexpectedEOF
+			}
+
+			err = nil
+		}
+
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+// Seek implements the [io.Seeker] interface.
+func (cr *chainedReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += cr.pos
+	case io.SeekEnd:
+		offset += cr.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	cr.pos = offset
+	cr.positioned = false
+
+	return offset, nil
+}
+
+// Size returns the total length of the chain.
+func (cr *chainedReader) Size() int64 {
+	return cr.size
+}
+
+// locate returns the part holding the provided position, and the position's
+// offset within it.
+func (cr *chainedReader) locate(pos int64) (chainPart, int64) {
+	for _, part := range cr.parts {
+		if pos < part.length {
+			return part, pos
+		}
+
+		pos -= part.length
+	}
+
+	return chainPart{}, 0
+}
@@ -0,0 +1,86 @@
+package csv
+
+import (
+	"fmt"
+
+	"go.k6.io/k6/js/modules/k6/experimental/fs"
+)
+
+const (
+	// compressionNone reads files as is.
+	compressionNone = "none"
+
+	// compressionGzip decompresses gzip compressed files.
+	compressionGzip = fs.CompressionGzip
+
+	// compressionZstd decompresses zstd compressed files.
+	compressionZstd = fs.CompressionZstd
+
+	// compressionAuto decompresses files starting with the gzip, or zstd, magic
+	// bytes, and reads the others as is.
+	compressionAuto = "auto"
+)
+
+// compressionMagics holds the bytes the files compressed with each of the
+// supported compressions start with.
+var compressionMagics = map[string][]byte{ //nolint:gochecknoglobals
+	compressionGzip: {0x1f, 0x8b},
+	compressionZstd: {0x28, 0xb5, 0x2f, 0xfd},
+}
+
+// parseCompression parses the provided compression option's value.
+func parseCompression(name string) (string, error) {
+	switch name {
+	case compressionNone, compressionGzip, compressionZstd, compressionAuto:
+		return name, nil
+	default:
+		return "", fmt.Errorf(
+			"unsupported compression %q; expected one of %q, %q, %q or %q",
+			name, compressionNone, compressionGzip, compressionZstd, compressionAuto,
+		)
+	}
+}
+
+// decompress returns a source holding the content of the provided one,
+// decompressed according to the given compression.
+//
+// The content is decompressed as it is read, so that it is never held in memory
+// at once, while the offsets reported by the parser, and the cursors it is
+// repositioned at, refer to the decompressed content. When the compressed stream
+// is truncated or corrupt, the content that could be decompressed is read, and
+// reading past it fails with the decompression error.
+//
+// Files transparently decompressed by the fs module, as their extension
+// designates a compression, are read as is.
+func (s source) decompress(compression string) (source, error) {
+	if s.decompressed {
+		return s, nil
+	}
+
+	if compression == compressionAuto {
+		compression = compressionNone
+
+		for _, candidate := range []string{compressionGzip, compressionZstd} {
+			compressed, err := s.hasPrefix(compressionMagics[candidate])
+			if err != nil {
+				return source{}, err
+			}
+
+			if compressed {
+				compression = candidate
+				break
+			}
+		}
+	}
+
+	if compression == compressionNone {
+		return s, nil
+	}
+
+	r, err := fs.NewDecompressingReader(s, compression)
+	if err != nil {
+		return source{}, err
+	}
+
+	return source{ReadSeeker: r, path: s.path, decompressed: true}, nil
+}
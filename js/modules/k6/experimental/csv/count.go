@@ -0,0 +1,66 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/sobek"
+	"gopkg.in/guregu/null.v3"
+)
+
+// Count returns a promise resolving to the number of data rows the file holds.
+//
+// The header, or the skipped first line, is not counted, whereas the rows the
+// `fromLine` and `toLine` options exclude are. Rows are counted as records, so
+// that quoted fields spanning multiple lines do not inflate the count. Once
+// counted, the parser is repositioned where it was, so that the following call
+// to [Parser.Next] is unaffected.
+func (p *Parser) Count() *sobek.Promise {
+	return runAsync(p, p.count, func(count int64) any {
+		return count
+	})
+}
+
+// count counts the records following the header, and restores the parser's
+// position, whether counting succeeded or not.
+func (p *Parser) count() (int64, error) {
+	var count int64
+
+	err := p.restoringPosition(func() error {
+		var err error
+		count, err = p.countRecords()
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// countRecords counts the records following the header.
+func (p *Parser) countRecords() (int64, error) {
+	if _, err := p.source.Seek(p.dataStart.ByteOffset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	// Every record is counted, regardless of its number of fields.
+	options := p.options
+	options.FieldsPerRecord = null.IntFrom(-1)
+	reader := newRecordReader(p.source, options, p.logger)
+
+	var count int64
+	for {
+		_, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+
+		if err != nil {
+			return 0, fmt.Errorf("failed to count the file's rows; reason: %w", err)
+		}
+
+		count++
+	}
+}
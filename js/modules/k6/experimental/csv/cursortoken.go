@@ -0,0 +1,170 @@
+package csv
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/sobek"
+)
+
+// cursorTokenVersion prefixes the cursor tokens produced by [Parser.ExportCursor],
+// so that their format can evolve.
+const cursorTokenVersion = "v1"
+
+// errCursorTokenMismatch is returned when importing a cursor token which was
+// exported from a different file than the one the parser reads from.
+var errCursorTokenMismatch = errors.New("the cursor token was exported from a different file")
+
+// fingerprint identifies the content of the file a parser reads from.
+type fingerprint struct {
+	// size holds the file's size, in bytes.
+	size int64
+
+	// checksum holds the CRC-32 checksum of the file's content.
+	checksum uint32
+}
+
+// cursorToken holds a parser's cursor, along with the fingerprint of the file
+// it was obtained from.
+type cursorToken struct {
+	cursor      cursor
+	fingerprint fingerprint
+}
+
+// String encodes the token into a compact string.
+func (ct cursorToken) String() string {
+	raw := fmt.Sprintf(
+		"%s:%d:%d:%d:%08x",
+		cursorTokenVersion, ct.cursor.Line, ct.cursor.ByteOffset, ct.fingerprint.size, ct.fingerprint.checksum,
+	)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// parseCursorToken decodes a token, as previously encoded by [cursorToken.String].
+func parseCursorToken(token string) (cursorToken, error) {
+	errInvalid := errors.New("invalid cursor token")
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursorToken{}, errInvalid
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 5 || parts[0] != cursorTokenVersion {
+		return cursorToken{}, errInvalid
+	}
+
+	var values [3]int64
+	for i, part := range parts[1:4] {
+		if values[i], err = strconv.ParseInt(part, 10, 64); err != nil || values[i] < 0 {
+			return cursorToken{}, errInvalid
+		}
+	}
+
+	checksum, err := strconv.ParseUint(parts[4], 16, 32)
+	if err != nil {
+		return cursorToken{}, errInvalid
+	}
+
+	return cursorToken{
+		cursor:      cursor{Line: values[0], ByteOffset: values[1]},
+		fingerprint: fingerprint{size: values[2], checksum: uint32(checksum)},
+	}, nil
+}
+
+// ExportCursor returns a promise resolving to a compact string token holding
+// the parser's current position, which can be persisted, such as through the
+// fs module, and later handed over to [Parser.ImportCursor] to resume reading
+// from that position, including from a different parser or test run.
+//
+// The token also holds the size and checksum of the file the parser reads from,
+// so that it is only imported by parsers reading from the same file.
+func (p *Parser) ExportCursor() *sobek.Promise {
+	return runAsync(p,
+		func() (cursorToken, error) {
+			fp, err := p.fingerprint()
+			if err != nil {
+				return cursorToken{}, fmt.Errorf("unable to fingerprint the parser's file; reason: %w", err)
+			}
+
+			return cursorToken{cursor: p.consumedCursor(), fingerprint: fp}, nil
+		},
+		func(token cursorToken) any {
+			return token.String()
+		},
+	)
+}
+
+// ImportCursor returns a promise resolving once the parser has been repositioned
+// at the position held by the provided token, as previously returned by
+// [Parser.ExportCursor].
+//
+// The promise is rejected if the token was exported from a different file.
+func (p *Parser) ImportCursor(token string) *sobek.Promise {
+	return runAsync(p,
+		func() (any, error) {
+			ct, err := parseCursorToken(token)
+			if err != nil {
+				return nil, err
+			}
+
+			fp, err := p.fingerprint()
+			if err != nil {
+				return nil, fmt.Errorf("unable to fingerprint the parser's file; reason: %w", err)
+			}
+
+			if ct.fingerprint != fp || ct.cursor.ByteOffset > fp.size {
+				return nil, errCursorTokenMismatch
+			}
+
+			return nil, p.seek(ct.cursor)
+		},
+		func(any) any {
+			return sobek.Undefined()
+		},
+	)
+}
+
+// fingerprint returns the fingerprint of the file the parser reads from,
+// computing it on first use.
+//
+// The source's position is restored once the file has been read, so that the
+// reader is not affected.
+func (p *Parser) fingerprint() (fingerprint, error) {
+	if p.fileFingerprint != nil {
+		return *p.fileFingerprint, nil
+	}
+
+	if p.source == nil {
+		return fingerprint{}, errors.New("the parser's source is not seekable")
+	}
+
+	position, err := p.source.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fingerprint{}, err
+	}
+
+	if _, err := p.source.Seek(0, io.SeekStart); err != nil {
+		return fingerprint{}, err
+	}
+
+	hash := crc32.NewIEEE()
+	size, err := io.Copy(hash, p.source)
+	if err != nil {
+		return fingerprint{}, err
+	}
+
+	if _, err := p.source.Seek(position, io.SeekStart); err != nil {
+		return fingerprint{}, err
+	}
+
+	p.fileFingerprint = &fingerprint{size: size, checksum: hash.Sum32()}
+
+	return *p.fileFingerprint, nil
+}
@@ -0,0 +1,60 @@
+package csv
+
+// consecutiveDeduper skips records identical to the one yielded right before
+// them, either as a whole or by the value of a key column.
+//
+// Only the previously yielded record is retained, as opposed to every record
+// seen so far, making it suitable to collapse runs of duplicates in sorted data.
+type consecutiveDeduper struct {
+	// column holds the index of the key column, or -1 when whole records are compared.
+	column int
+
+	// previous holds the previously yielded record, or its key.
+	previous []string
+
+	// seen indicates whether a record was yielded yet.
+	seen bool
+}
+
+// newConsecutiveDeduper creates a new consecutiveDeduper comparing records by
+// the provided key column, or as a whole when it is negative.
+func newConsecutiveDeduper(column int) *consecutiveDeduper {
+	return &consecutiveDeduper{column: column}
+}
+
+// isDuplicate returns true if the provided record is identical to the previously
+// yielded one. Otherwise, the record is retained as the previously yielded one.
+func (cd *consecutiveDeduper) isDuplicate(record []string) bool {
+	key := record
+	if cd.column >= 0 {
+		key = nil
+		if cd.column < len(record) {
+			key = record[cd.column : cd.column+1]
+		}
+	}
+
+	if cd.seen && equalFields(cd.previous, key) {
+		return true
+	}
+
+	// The record might be held by the parser's record buffer, which is reused.
+	cd.previous = append(cd.previous[:0], key...)
+	cd.seen = true
+
+	return false
+}
+
+// equalFields returns true if both records hold the same fields.
+func equalFields(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,65 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// namedDelimiters maps the names the `delimiter` option accepts, in place of
+// the character itself, to the delimiter they designate.
+var namedDelimiters = map[string]rune{ //nolint:gochecknoglobals
+	"comma":     ',',
+	"tab":       '\t',
+	"semicolon": ';',
+	"pipe":      '|',
+}
+
+// candidateDelimiters holds the delimiters the `autoDetectDelimiter` option
+// chooses from, in order of preference when they are equally likely.
+var candidateDelimiters = []rune{',', '\t', ';', '|'} //nolint:gochecknoglobals
+
+// Delimiter returns the character that separates the fields, as configured
+// through the `delimiter` option, or as detected through the
+// `autoDetectDelimiter` one.
+func (p *Parser) Delimiter() string {
+	return string(p.options.Delimiter)
+}
+
+// detectDelimiter returns the most likely delimiter of the line starting at the
+// provided offset within the source, among the [candidateDelimiters], leaving
+// the source's position unchanged.
+//
+// The line's occurrences of each candidate are counted, and the most frequent
+// one is chosen, regardless of quoting. If the line holds none of them, the
+// provided fallback is returned.
+func detectDelimiter(src io.ReadSeeker, offset int64, fallback rune) (rune, error) {
+	previous, err := src.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := src.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	line, err := bufio.NewReader(src).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+
+	if _, err := src.Seek(previous, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to restore the source's position; reason: %w", err)
+	}
+
+	delimiter, best := fallback, 0
+	for _, candidate := range candidateDelimiters {
+		if n := bytes.Count(line, []byte(string(candidate))); n > best {
+			delimiter, best = candidate, n
+		}
+	}
+
+	return delimiter, nil
+}
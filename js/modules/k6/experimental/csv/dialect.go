@@ -0,0 +1,96 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+)
+
+// builtinDialects holds the dialects that are available to every parser out of the box.
+//
+// Only dialects that parse differently from the default options are listed:
+// the default options already follow RFC 4180.
+//
+// Built-in dialects cannot be overridden by [ModuleInstance.RegisterDialect].
+var builtinDialects = map[string]parserOptions{ //nolint:gochecknoglobals
+	"tsv": func() parserOptions {
+		options := newDefaultParserOptions()
+		options.Delimiter = '\t'
+		return options
+	}(),
+}
+
+// dialectRegistry holds named sets of parser options, that can be referred to
+// through the `dialect` option when instantiating a new [Parser].
+//
+// Each VU holds its own registry, which is seeded with the [builtinDialects].
+type dialectRegistry struct {
+	dialects map[string]parserOptions
+}
+
+// newDialectRegistry creates a new dialectRegistry holding the built-in dialects.
+func newDialectRegistry() *dialectRegistry {
+	dialects := make(map[string]parserOptions, len(builtinDialects))
+	for name, options := range builtinDialects {
+		dialects[name] = options
+	}
+
+	return &dialectRegistry{dialects: dialects}
+}
+
+// get returns the options registered under the given name, if any.
+func (dr *dialectRegistry) get(name string) (parserOptions, bool) {
+	options, ok := dr.dialects[name]
+	return options, ok
+}
+
+// register registers the provided options under the given name.
+//
+// Registering a dialect under an already registered name overrides it, unless
+// the name belongs to a built-in dialect, in which case an error is returned.
+func (dr *dialectRegistry) register(name string, options parserOptions) error {
+	if name == "" {
+		return errors.New("dialect name cannot be empty")
+	}
+
+	if _, ok := builtinDialects[name]; ok {
+		return fmt.Errorf("cannot override the built-in %q dialect", name)
+	}
+
+	dr.dialects[name] = options
+
+	return nil
+}
+
+// RegisterDialect registers a named set of parser options, that can later be
+// used by parsers through the `dialect` option.
+//
+// Dialects are registered per VU, and can only be registered from the init
+// context, as parsers can only be instantiated there.
+func (mi *ModuleInstance) RegisterDialect(name sobek.Value, options sobek.Value) {
+	rt := mi.vu.Runtime()
+
+	if mi.vu.State() != nil {
+		common.Throw(rt, errors.New("registerDialect must be called in the init context"))
+	}
+
+	if common.IsNullish(name) {
+		common.Throw(rt, errors.New("registerDialect takes a non-nil name argument"))
+	}
+
+	if common.IsNullish(options) {
+		common.Throw(rt, errors.New("registerDialect takes a non-nil options argument"))
+	}
+
+	dialectOptions, err := newParserOptionsFrom(rt, options.ToObject(rt), mi.dialects)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("encountered an error while interpreting dialect options; reason: %w", err))
+	}
+
+	if err := mi.dialects.register(name.String(), dialectOptions); err != nil {
+		common.Throw(rt, fmt.Errorf("failed to register dialect; reason: %w", err))
+	}
+}
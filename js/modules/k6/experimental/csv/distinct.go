@@ -0,0 +1,126 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+)
+
+// DistinctCount returns a promise resolving to the number of distinct values held
+// by the provided column, in the records remaining to be read by the parser.
+//
+// The column is either designated by its zero-based index, or by its name when
+// the first line of the file was consumed through the `header` or `skipFirstLine`
+// option.
+//
+// When the `includeValues` option is set, the promise resolves to an object
+// holding both the `count` and the distinct `values`.
+//
+// Distinct values are computed in a single pass over the remaining records, which
+// are consumed by the operation. Every distinct value is held in memory until the
+// pass completes, which can be costly for high-cardinality columns.
+func (p *Parser) DistinctCount(column sobek.Value, options sobek.Value) *sobek.Promise {
+	rt := p.vu.Runtime()
+
+	index, err := p.columnIndex(column)
+	if err != nil {
+		promise, _, reject := rt.NewPromise()
+		reject(fmt.Errorf("distinctCount() failed; reason: %w", err))
+		return promise
+	}
+
+	includeValues := false
+	if !common.IsNullish(options) {
+		if v := options.ToObject(rt).Get("includeValues"); !common.IsNullish(v) {
+			includeValues = v.ToBoolean()
+		}
+	}
+
+	return runAsync(p,
+		func() ([]string, error) {
+			return p.distinct(index)
+		},
+		func(values []string) any {
+			if !includeValues {
+				return len(values)
+			}
+
+			return distinctResult{Count: len(values), Values: values}
+		},
+	)
+}
+
+// distinctResult holds the result of a [Parser.DistinctCount] call when the
+// `includeValues` option is set.
+type distinctResult struct {
+	// Count holds the number of distinct values.
+	Count int `js:"count"`
+
+	// Values holds the distinct values, in the order they were first read.
+	Values []string `js:"values"`
+}
+
+// distinct reads the remaining records, and returns the distinct values of the
+// column at the provided index, in the order they were first read.
+//
+// It must be called while holding the parser's lock.
+func (p *Parser) distinct(index int) ([]string, error) {
+	seen := make(map[string]struct{})
+	values := make([]string, 0)
+
+	for {
+		records, err := p.next()
+		if errors.Is(err, io.EOF) {
+			return values, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if index >= len(records) {
+			return nil, fmt.Errorf("line %d: column %d is out of range for a record of %d fields",
+				p.currentLine.Load(), index, len(records))
+		}
+
+		if _, ok := seen[records[index]]; ok {
+			continue
+		}
+
+		seen[records[index]] = struct{}{}
+		values = append(values, records[index])
+	}
+}
+
+// columnIndex returns the zero-based index of the provided column, designated
+// either by its index, or by its name in the parser's header.
+func (p *Parser) columnIndex(column sobek.Value) (int, error) {
+	if common.IsNullish(column) {
+		return 0, errors.New("column cannot be null or undefined")
+	}
+
+	if name, ok := column.Export().(string); ok {
+		if p.header == nil {
+			return 0, errors.New("columns can only be designated by name when the header or skipFirstLine option is set")
+		}
+
+		for i, h := range p.header {
+			if h == name {
+				return i, nil
+			}
+		}
+
+		return 0, fmt.Errorf("no column named %q", name)
+	}
+
+	index := column.ToInteger()
+	if index < 0 {
+		return 0, fmt.Errorf("column index %d cannot be negative", index)
+	}
+
+	return int(index), nil
+}
@@ -0,0 +1,63 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// encodingUTF8 is the name of the default encoding, in which files are read as is.
+const encodingUTF8 = "utf-8"
+
+// encodings holds the character encodings supported through the `encoding`
+// option, keyed by name.
+var encodings = map[string]encoding.Encoding{ //nolint:gochecknoglobals
+	"latin1":       charmap.ISO8859_1,
+	"iso-8859-1":   charmap.ISO8859_1,
+	"windows-1252": charmap.Windows1252,
+	"utf-16le":     unicode.UTF16(unicode.LittleEndian, unicode.UseBOM),
+	"utf-16be":     unicode.UTF16(unicode.BigEndian, unicode.UseBOM),
+}
+
+// parseEncoding parses the provided encoding option's value, which is returned
+// normalized.
+func parseEncoding(name string) (string, error) {
+	name = strings.ToLower(name)
+	if name == encodingUTF8 || name == "utf8" {
+		return encodingUTF8, nil
+	}
+
+	if _, ok := encodings[name]; !ok {
+		return "", fmt.Errorf(
+			"unsupported encoding %q; expected one of %q, %q, %q, %q, %q or %q",
+			name, encodingUTF8, "latin1", "iso-8859-1", "windows-1252", "utf-16le", "utf-16be",
+		)
+	}
+
+	return name, nil
+}
+
+// decode returns a source holding the content of the provided one, decoded
+// from the given encoding to UTF-8.
+//
+// The content is decoded once, in memory, so that the offsets reported by the
+// parser, and the cursors it is repositioned at, refer to the decoded content.
+func (s source) decode(name string) (source, error) {
+	enc, ok := encodings[name]
+	if !ok {
+		return s, nil
+	}
+
+	decoded, err := io.ReadAll(transform.NewReader(s, enc.NewDecoder()))
+	if err != nil {
+		return source{}, fmt.Errorf("failed to decode the source from %s; reason: %w", name, err)
+	}
+
+	return source{ReadSeeker: bytes.NewReader(decoded), path: s.path}, nil
+}
@@ -0,0 +1,123 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/sobek"
+)
+
+// recordParseError describes a record the parser failed to parse, positioned
+// within the file, as opposed to relative to where the reader started reading.
+type recordParseError struct {
+	// line holds the one-based physical line the error occurred on.
+	line int64
+
+	// column holds the one-based column the error occurred at, or 0 if the
+	// error concerns the record as a whole, such as [csv.ErrFieldCount].
+	column int64
+
+	// field holds the zero-based index of the field the error occurred in, or
+	// -1 if it is unknown, or the error concerns the record as a whole.
+	field int
+
+	err error
+}
+
+// Ensure that the recordParseError type implements the Go `error` interface.
+var _ error = (*recordParseError)(nil)
+
+// Error implements the Go `error` interface.
+func (e *recordParseError) Error() string {
+	if e.column > 0 {
+		return fmt.Sprintf("line %d, column %d: %s", e.line, e.column, e.err)
+	}
+
+	return fmt.Sprintf("line %d: %s", e.line, e.err)
+}
+
+// Unwrap returns the underlying error, which is [csv.ErrFieldCount], or the
+// error held by the reader's [csv.ParseError].
+func (e *recordParseError) Unwrap() error {
+	return e.err
+}
+
+// newRecordParseError positions the provided [csv.ParseError], reported by the
+// parser's reader, within the file.
+//
+// Errors concerning the record as a whole are reported at the line the record
+// starts on, and the other ones at the line and column they were detected at.
+func (p *Parser) newRecordParseError(pe *csv.ParseError) *recordParseError {
+	if errors.Is(pe.Err, csv.ErrFieldCount) {
+		return &recordParseError{line: p.readerLine + int64(pe.StartLine), field: -1, err: pe.Err}
+	}
+
+	return &recordParseError{
+		line:   p.readerLine + int64(pe.Line),
+		column: int64(pe.Column),
+		field:  positionedFields(p.reader),
+		err:    pe.Err,
+	}
+}
+
+// positionedFields returns the number of fields the provided reader positioned
+// while reading its last record, which is the index of the field it failed to
+// read, or -1 if the reader does not report the fields' positions.
+//
+// As [csv.Reader] does not expose the number of fields it positioned, the
+// fields' positions are requested until it panics on an out of range field.
+func positionedFields(reader recordReader) (n int) {
+	fp, ok := reader.(fieldPositioner)
+	if !ok {
+		return -1
+	}
+
+	defer func() {
+		_ = recover()
+	}()
+
+	for ; ; n++ {
+		fp.FieldPos(n)
+	}
+}
+
+// rejectionReason returns the reason a promise should be rejected with for the
+// provided error.
+//
+// When the error is a JS exception, it is the thrown value. When the error
+// denotes a record the parser failed to parse, it is an error object which
+// `line`, `column`, and `field` properties position the failure, and which
+// `message` property describes it. Columns and fields which are unknown are
+// set to null.
+//
+// It must be called from the event loop.
+func (p *Parser) rejectionReason(err error) any {
+	var ex *sobek.Exception
+	if errors.As(err, &ex) {
+		return ex.Value()
+	}
+
+	rt := p.vu.Runtime()
+	obj := rt.NewGoError(err)
+
+	var rpe *recordParseError
+	if !errors.As(err, &rpe) {
+		return obj
+	}
+
+	var column, field any
+	if rpe.column > 0 {
+		column = rpe.column
+	}
+
+	if rpe.field >= 0 {
+		field = rpe.field
+	}
+
+	must(rt, obj.Set("line", rpe.line))
+	must(rt, obj.Set("column", column))
+	must(rt, obj.Set("field", field))
+
+	return obj
+}
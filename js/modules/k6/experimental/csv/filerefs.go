@@ -0,0 +1,104 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/fsext"
+)
+
+// fileColumn describes a column which fields hold references to files, which
+// content is returned in place of the reference.
+type fileColumn struct {
+	// column holds the name, or index, the column was designated by.
+	column string
+
+	// index holds the column's index within records.
+	index int
+
+	// basePath holds the absolute path the column's references are relative to.
+	basePath string
+}
+
+// fileResolver substitutes the fields of file columns with the content of the
+// files they reference.
+//
+// As files can only be accessed from the init context, the file system is
+// captured when the parser is instantiated. Note that the referenced files are
+// read as records are, and are thus not known to k6 ahead of time: they are not
+// bundled in archives, and have to be available on the file system the test
+// runs from.
+type fileResolver struct {
+	fs      fsext.Fs
+	columns []fileColumn
+
+	// contents caches the content of the files read so far, by absolute path.
+	contents map[string]string
+}
+
+// newFileResolver creates a new fileResolver for the provided file columns, keyed
+// by column, which values hold the base path their references are relative to.
+//
+// It must be called from the init context.
+func (p *Parser) newFileResolver(vu modules.VU, fileColumns map[string]string) (*fileResolver, error) {
+	initEnv := vu.InitEnv()
+
+	fs, ok := initEnv.FileSystems["file"]
+	if !ok {
+		return nil, errors.New("unable to access the file system")
+	}
+
+	columns := make([]fileColumn, 0, len(fileColumns))
+	for column, basePath := range fileColumns {
+		index, err := p.resolveColumn(column)
+		if err != nil {
+			return nil, err
+		}
+
+		columns = append(columns, fileColumn{
+			column: column,
+			index:  index,
+			// We resolve the path relative to the entrypoint script, as the fs module does.
+			basePath: fsext.Abs(initEnv.CWD.Path, basePath),
+		})
+	}
+
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].index < columns[j].index
+	})
+
+	return &fileResolver{
+		fs:       fs,
+		columns:  columns,
+		contents: make(map[string]string),
+	}, nil
+}
+
+// resolve substitutes, in place, the provided record's file columns fields with
+// the content of the files they reference.
+func (fr *fileResolver) resolve(records []string) error {
+	for _, fc := range fr.columns {
+		if fc.index >= len(records) {
+			continue
+		}
+
+		path := fsext.Abs(fc.basePath, records[fc.index])
+
+		content, ok := fr.contents[path]
+		if !ok {
+			data, err := fsext.ReadFile(fr.fs, path)
+			if err != nil {
+				return fmt.Errorf("unable to read the file %q referenced by column %q; reason: %w", path, fc.column, err)
+			}
+
+			content = string(data)
+			fr.contents[path] = content
+		}
+
+		records[fc.index] = content
+	}
+
+	return nil
+}
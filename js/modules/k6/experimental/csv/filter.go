@@ -0,0 +1,87 @@
+package csv
+
+import (
+	"github.com/grafana/sobek"
+)
+
+// matchedRecord is a record read by [Parser.nextMatching], along with the
+// parser's cursor right after it, and the text it was parsed from.
+type matchedRecord struct {
+	records []string
+	cursor  cursor
+	raw     string
+}
+
+// nextMatching reads records until one is accepted, handing each of them over
+// to the event loop in turn, as accept might call into the runtime.
+//
+// Records are read in a background goroutine holding the parser's lock, which
+// is released as soon as the record has been read, as [runLocked] describes.
+// When accept rejects a record, another background read is scheduled from the
+// event loop, until a record is accepted, or reading fails.
+//
+// The outcome is handed over to settle, which is called from the event loop
+// with either the accepted record along with its runtime value, or an error,
+// which is [io.EOF] once there are no more records to read.
+func (p *Parser) nextMatching(
+	accept func(record matchedRecord, value sobek.Value) (bool, error),
+	settle func(record matchedRecord, value sobek.Value, err error),
+) {
+	p.readMatching(false, accept, settle)
+}
+
+// readMatching implements [Parser.nextMatching]. The previous record is
+// uncounted from the `limit` option before reading if it was rejected, as the
+// parser's state is only changed while holding its lock.
+func (p *Parser) readMatching(
+	rejected bool,
+	accept func(record matchedRecord, value sobek.Value) (bool, error),
+	settle func(record matchedRecord, value sobek.Value, err error),
+) {
+	rt := p.vu.Runtime()
+
+	read := func() (matchedRecord, error) {
+		if rejected {
+			// The record is not returned, and thus does not count towards the limit.
+			p.returned--
+		}
+
+		records, err := p.next()
+		return matchedRecord{records: p.detach(records), cursor: p.cursor(), raw: p.raw}, err
+	}
+
+	runLocked(p, read, func(record matchedRecord, err error) {
+		if err != nil {
+			settle(matchedRecord{}, nil, err)
+			return
+		}
+
+		value := rt.ToValue(p.toValue(record.records))
+
+		accepted, err := accept(record, value)
+		if err != nil {
+			settle(matchedRecord{}, nil, err)
+			return
+		}
+
+		if !accepted {
+			p.readMatching(true, accept, settle)
+			return
+		}
+
+		settle(record, value, nil)
+	})
+}
+
+// filter calls the `filter` option's callback with the provided record's value,
+// and the line it was read from.
+//
+// It must be called from the event loop.
+func (p *Parser) filter(record matchedRecord, value sobek.Value) (bool, error) {
+	keep, err := p.options.Filter(sobek.Undefined(), value, p.vu.Runtime().ToValue(record.cursor.Line))
+	if err != nil {
+		return false, err
+	}
+
+	return keep.ToBoolean(), nil
+}
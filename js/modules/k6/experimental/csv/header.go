@@ -0,0 +1,53 @@
+package csv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// nameEmptyHeaders names the provided header's empty column names after the
+// zero-based index of their column, prefixed with an underscore, so that their
+// fields are not mapped to an empty key.
+func nameEmptyHeaders(header []string) {
+	for i, name := range header {
+		if name == "" {
+			header[i] = "_" + strconv.Itoa(i)
+		}
+	}
+}
+
+// checkDuplicateHeaders returns an error listing the column names the provided
+// header holds several times, along with the zero-based positions of the
+// columns holding them, if any.
+func checkDuplicateHeaders(header []string) error {
+	positions := make(map[string][]int, len(header))
+	var names []string
+
+	for i, name := range header {
+		if len(positions[name]) == 1 {
+			names = append(names, name)
+		}
+
+		positions[name] = append(positions[name], i)
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	duplicates := make([]string, 0, len(names))
+	for _, name := range names {
+		columns := make([]string, 0, len(positions[name]))
+		for _, i := range positions[name] {
+			columns = append(columns, strconv.Itoa(i))
+		}
+
+		duplicates = append(duplicates, fmt.Sprintf("%q at columns %s", name, strings.Join(columns, ", ")))
+	}
+
+	return fmt.Errorf(
+		"the header holds duplicate column names: %s; set the allowDuplicateHeaders option to map them to the last column holding them",
+		strings.Join(duplicates, "; "),
+	)
+}
@@ -0,0 +1,107 @@
+package csv
+
+import (
+	"github.com/grafana/sobek"
+)
+
+// fieldDecoder decodes the raw value of the field at the provided column index
+// into a value handed over to the runtime.
+type fieldDecoder func(index int, raw string) sobek.Value
+
+// lazyRecord is a [sobek.DynamicObject] exposing a record as an object keyed by
+// the header's column names, which fields are only decoded once accessed.
+//
+// Decoded fields are cached, so that each field is decoded at most once. The
+// object is read-only: setting or deleting its properties has no effect.
+type lazyRecord struct {
+	// columns maps the header's column names to their index.
+	columns map[string]int
+
+	// names holds the header's column names, in order.
+	names []string
+
+	// fields holds the record's raw fields.
+	fields []string
+
+	// decoded holds the fields that were already decoded, by index.
+	decoded []sobek.Value
+
+	decode fieldDecoder
+}
+
+var _ sobek.DynamicObject = (*lazyRecord)(nil)
+
+// newLazyRecord creates a new lazyRecord exposing the provided fields, keyed by
+// the header's column names.
+//
+// The fields are copied, as they might be held by a buffer that is reused.
+func newLazyRecord(columns map[string]int, names []string, fields []string, decode fieldDecoder) *lazyRecord {
+	return &lazyRecord{
+		columns: columns,
+		names:   names,
+		fields:  append([]string(nil), fields...),
+		decoded: make([]sobek.Value, len(fields)),
+		decode:  decode,
+	}
+}
+
+// Get implements the [sobek.DynamicObject] interface.
+func (lr *lazyRecord) Get(key string) sobek.Value {
+	index, ok := lr.columns[key]
+	if !ok || index >= len(lr.fields) {
+		return nil
+	}
+
+	if lr.decoded[index] == nil {
+		lr.decoded[index] = lr.decode(index, lr.fields[index])
+	}
+
+	return lr.decoded[index]
+}
+
+// Set implements the [sobek.DynamicObject] interface.
+func (lr *lazyRecord) Set(string, sobek.Value) bool {
+	return false
+}
+
+// Has implements the [sobek.DynamicObject] interface.
+func (lr *lazyRecord) Has(key string) bool {
+	index, ok := lr.columns[key]
+	return ok && index < len(lr.fields)
+}
+
+// Delete implements the [sobek.DynamicObject] interface.
+func (lr *lazyRecord) Delete(string) bool {
+	return false
+}
+
+// Keys implements the [sobek.DynamicObject] interface.
+func (lr *lazyRecord) Keys() []string {
+	keys := make([]string, 0, len(lr.names))
+	for _, name := range lr.names {
+		if lr.Has(name) {
+			keys = append(keys, name)
+		}
+	}
+
+	return keys
+}
+
+// newColumnIndex maps the provided header's column names to their index.
+//
+// When a name is held by multiple columns, it designates the first one.
+func newColumnIndex(header []string) (map[string]int, []string) {
+	columns := make(map[string]int, len(header))
+	names := make([]string, 0, len(header))
+
+	for i, name := range header {
+		if _, ok := columns[name]; ok {
+			continue
+		}
+
+		columns[name] = i
+		names = append(names, name)
+	}
+
+	return columns, names
+}
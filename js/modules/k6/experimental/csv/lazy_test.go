@@ -0,0 +1,49 @@
+package csv
+
+import (
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazyRecordDecodesAccessedFieldsOnly(t *testing.T) {
+	t.Parallel()
+
+	rt := sobek.New()
+
+	decoded := make(map[int]int)
+	countingDecoder := func(index int, raw string) sobek.Value {
+		decoded[index]++
+		return rt.ToValue(raw + "!")
+	}
+
+	columns, names := newColumnIndex([]string{"a", "b", "c", "d"})
+	record := newLazyRecord(columns, names, []string{"1", "2", "3", "4"}, countingDecoder)
+	require.NoError(t, rt.Set("record", rt.NewDynamicObject(record)))
+
+	got, err := rt.RunString(`record.a + record.c + record.a + ("b" in record) + ("e" in record)`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1!3!1!truefalse", got.String())
+	assert.Equal(t, map[int]int{0: 1, 2: 1}, decoded)
+}
+
+func TestLazyRecordKeys(t *testing.T) {
+	t.Parallel()
+
+	rt := sobek.New()
+
+	// Duplicate names designate the first column, and missing fields are not exposed.
+	columns, names := newColumnIndex([]string{"a", "b", "a", "c"})
+	record := newLazyRecord(columns, names, []string{"1", "2", "3"}, func(_ int, raw string) sobek.Value {
+		return rt.ToValue(raw)
+	})
+	require.NoError(t, rt.Set("record", rt.NewDynamicObject(record)))
+
+	got, err := rt.RunString(`JSON.stringify(record)`)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"a":"1","b":"2"}`, got.String())
+}
@@ -0,0 +1,31 @@
+package csv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// readMetadataLines reads the provided number of lines from the given reader,
+// without interpreting them as CSV, and returns them stripped from their line
+// terminators, along with the number of bytes read.
+//
+// It fails if the reader holds fewer lines than requested.
+func readMetadataLines(r *bufio.Reader, count int64) ([]string, int64, error) {
+	lines := make([]string, 0, count)
+
+	var n int64
+	for int64(len(lines)) < count {
+		line, err := r.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			return nil, 0, fmt.Errorf("failed to read metadata line %d; reason: %w", len(lines)+1, err)
+		}
+
+		n += int64(len(line))
+		lines = append(lines, strings.TrimRight(line, "\r\n"))
+	}
+
+	return lines, n, nil
+}
@@ -0,0 +1,79 @@
+package csv
+
+import (
+	"time"
+
+	"go.k6.io/k6/metrics"
+)
+
+// instanceMetrics contains the metrics for the csv module.
+type instanceMetrics struct {
+	// RowsParsed counts the rows read by the parsers.
+	RowsParsed *metrics.Metric
+
+	// ParseDuration tracks the time spent reading each row.
+	ParseDuration *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.RowsParsed, err = registry.NewMetric("csv_rows_parsed", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.ParseDuration, err = registry.NewMetric("csv_parse_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// pushRowMetrics pushes the samples accounting for a row read by the parser,
+// and for the time spent reading it since the previous row was.
+//
+// Samples are tagged with the path of the file the parser reads from, if any.
+// As samples can only be pushed once the test has started, rows read in the
+// init context, such as the ones parsed by [ModuleInstance.Parse], are not
+// accounted for.
+func (p *Parser) pushRowMetrics() {
+	if p.metrics == nil {
+		return
+	}
+
+	state := p.vu.State()
+	if state == nil {
+		return
+	}
+
+	duration := p.readDuration
+	p.readDuration = 0
+
+	ctm := state.Tags.GetCurrentValues()
+	tags := ctm.Tags
+	if p.path != "" {
+		tags = tags.With("file", p.path)
+	}
+
+	now := time.Now()
+	metrics.PushIfNotDone(p.vu.Context(), state.Samples, metrics.ConnectedSamples{
+		Samples: []metrics.Sample{
+			{
+				TimeSeries: metrics.TimeSeries{Metric: p.metrics.RowsParsed, Tags: tags},
+				Time:       now,
+				Metadata:   ctm.Metadata,
+				Value:      1,
+			},
+			{
+				TimeSeries: metrics.TimeSeries{Metric: p.metrics.ParseDuration, Tags: tags},
+				Time:       now,
+				Metadata:   ctm.Metadata,
+				Value:      metrics.D(duration),
+			},
+		},
+		Tags: tags,
+		Time: now,
+	})
+}
@@ -0,0 +1,1959 @@
+// Package csv provides a k6 module that allows users to parse CSV files
+// opened through the k6/experimental/fs module, or CSV data held in memory,
+// in a streaming fashion.
+package csv
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/sobek"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU.
+	RootModule struct {
+		// ringBuffers holds the ring buffers shared by the VUs' parsers.
+		ringBuffers *ringBufferRegistry
+
+		// sharedRows holds the rows parsed once, and shared by the VUs.
+		sharedRows *sharedRowsRegistry
+	}
+
+	// ModuleInstance represents an instance of the csv module for a single VU.
+	ModuleInstance struct {
+		vu modules.VU
+
+		// dialects holds the dialects available to this VU's parsers.
+		dialects *dialectRegistry
+
+		// ringBuffers holds the ring buffers shared with the other VUs.
+		ringBuffers *ringBufferRegistry
+
+		// sharedRows holds the rows shared with the other VUs.
+		sharedRows *sharedRowsRegistry
+
+		// metrics holds the metrics emitted by this VU's parsers.
+		metrics *instanceMetrics
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{
+		ringBuffers: newRingBufferRegistry(),
+		sharedRows:  newSharedRowsRegistry(),
+	}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	metrics, err := registerMetrics(vu.InitEnv().Registry)
+	if err != nil {
+		common.Throw(vu.Runtime(), fmt.Errorf("failed to register csv module metrics: %w", err))
+	}
+
+	return &ModuleInstance{
+		vu:          vu,
+		dialects:    newDialectRegistry(),
+		ringBuffers: rm.ringBuffers,
+		sharedRows:  rm.sharedRows,
+		metrics:     metrics,
+	}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"Parser":          mi.NewParser,
+			"Writer":          mi.NewWriter,
+			"parse":           mi.Parse,
+			"registerDialect": mi.RegisterDialect,
+		},
+	}
+}
+
+// Parser is a CSV parser.
+type Parser struct {
+	// currentLine holds the number of physical lines read by the parser so
+	// far, including the ones that were skipped, and the ones spanned by quoted
+	// fields holding line terminators.
+	currentLine atomic.Int64
+
+	// recordLine holds the line, relative to where the reader started reading,
+	// the record read last ends on, or 0 if the reader does not report it.
+	recordLine int64
+
+	// offset holds the offset, in bytes, of the end of the last line read by
+	// the parser, as reported by [Parser.Offset].
+	offset atomic.Int64
+
+	// size holds the size, in bytes, of the source.
+	size int64
+
+	// reader is the CSV reader that enables to read records from the provided
+	// input file.
+	reader recordReader
+
+	// source holds the file the parser reads from, which allows to reposition
+	// the parser within it.
+	source io.ReadSeeker
+
+	// path holds the path of the file the parser reads from, if it reads from
+	// a file, which the parser's metrics are tagged with.
+	path string
+
+	// metrics holds the metrics the parser emits as it reads rows.
+	metrics *instanceMetrics
+
+	// readDuration holds the time spent reading records since the last row
+	// was returned.
+	readDuration time.Duration
+
+	// raw holds the text the last record read was parsed from, when the
+	// `includeRaw` option is set.
+	raw string
+
+	// origin holds the parser's position at the beginning of the file, once
+	// the byte order mark and the metadata lines were consumed.
+	origin cursor
+
+	// start holds the parser's position at the first data line, once the header
+	// and the lines preceding the `fromLine` option were consumed.
+	start cursor
+
+	// dataStart holds the parser's position right after the header, before the
+	// lines preceding the `fromLine` option were skipped.
+	dataStart cursor
+
+	// rowOffsets holds the offset of each row following the header, once indexed
+	// by [Parser.At].
+	rowOffsets []int64
+
+	// cycleRead indicates whether a record was read since the parser last
+	// rewound to its start, when the `cycle` option is set.
+	cycleRead bool
+
+	// fileFingerprint holds the fingerprint of the file the parser reads from,
+	// once computed.
+	fileFingerprint *fingerprint
+
+	// readerLine holds the number of lines the parser had read when the reader
+	// started reading.
+	readerLine int64
+
+	// baseOffset holds the offset, in bytes, within the source at which
+	// the reader started reading.
+	baseOffset int64
+
+	// mu serializes the reads from the reader, and the changes to the
+	// parser's position.
+	mu sync.Mutex
+
+	// record holds the buffer records are copied into when the
+	// `preallocCapacity` option is set.
+	record []string
+
+	// header holds the first line of the file, when it was consumed through
+	// the `header` or `skipFirstLine` option.
+	header []string
+
+	// options holds the parser's options as provided by the user.
+	options parserOptions
+
+	// staleness holds the filter skipping records older than the
+	// `maxAgeSeconds` option, if it was set.
+	staleness *stalenessFilter
+
+	// ring holds the ring buffer shared with the other VUs, when the
+	// `ringBuffer` option is set.
+	ring *ringBuffer
+
+	// patterns holds the patterns records' fields are validated against, as
+	// set through the `patterns` option.
+	patterns []columnPattern
+
+	// deduper skips records identical to the previously yielded one, when the
+	// `dedupeConsecutive` option is set.
+	deduper *consecutiveDeduper
+
+	// files substitutes file columns' references with the content of the files
+	// they reference, when the `fileColumns` option is set.
+	files *fileResolver
+
+	// columns maps the header's column names to their index, when the `lazy`
+	// option is set.
+	columns map[string]int
+
+	// columnNames holds the header's distinct column names, in order, when the
+	// `lazy` option is set.
+	columnNames []string
+
+	// schema holds the types fields are coerced to, keyed by column index, when
+	// the `schema` option is set.
+	schema map[int]columnType
+
+	// shard holds the partition of the file's rows the parser returns, when
+	// the `shard` or `partitionByVU` option is set.
+	shard *shardOptions
+
+	// recordErrors holds the errors of the records that were skipped, when the
+	// `skipErrors` option is set.
+	recordErrors []recordError
+
+	// errorsMu guards recordErrors, which are read from the event loop.
+	errorsMu sync.Mutex
+
+	// projection holds the indices of the columns records are projected onto,
+	// in order, when the `columns` option is set.
+	projection []int
+
+	// returned holds the number of records returned by the parser so far, as
+	// limited by the `limit` option.
+	returned int64
+
+	// pending holds a record that was read ahead, such as by [Parser.SkipUntil]
+	// or [Parser.Peek], and should be returned by the next read.
+	pending *pendingRecord
+
+	// before holds the parser's position before the record read last was, so
+	// that it can be restored when the record is read ahead.
+	before cursor
+
+	// logger is used to report the issues the parser recovered from.
+	logger logrus.FieldLogger
+
+	// vu is the VU instance that owns this module instance.
+	vu modules.VU
+}
+
+// parseResult holds the result of a CSV parser's parsing operation such
+// as when calling the [Parser.Next] method.
+type parseResult struct {
+	// Done indicates whether the parser has finished reading the file.
+	Done bool `js:"done"`
+
+	// Value holds the line's records value.
+	Value any `js:"value"`
+}
+
+// cursorParseResult holds the result of a CSV parser's parsing operation when
+// the `includeCursor` option is set.
+type cursorParseResult struct {
+	// Done indicates whether the parser has finished reading the file.
+	Done bool `js:"done"`
+
+	// Value holds the line's records value.
+	Value any `js:"value"`
+
+	// Cursor holds the parser's position right after the line was read.
+	Cursor cursor `js:"cursor"`
+}
+
+// cursor describes a parser's position within the file it reads from.
+type cursor struct {
+	// Line holds the number of lines read by the parser.
+	Line int64 `js:"line"`
+
+	// ByteOffset holds the offset, in bytes, of the end of the last line
+	// read by the parser.
+	ByteOffset int64 `js:"byteOffset"`
+}
+
+// NewParser creates a new CSV parser instance.
+//
+// Its first argument is either the source to read from, or an array of sources,
+// such as files sharing the same layout, to read one after the other as if they
+// were a single file.
+func (mi *ModuleInstance) NewParser(call sobek.ConstructorCall) *sobek.Object {
+	rt := mi.vu.Runtime()
+
+	if mi.vu.State() != nil {
+		common.Throw(rt, errors.New("csv Parser constructor must be called in the init context"))
+	}
+
+	if len(call.Arguments) < 1 || common.IsNullish(call.Argument(0)) {
+		common.Throw(rt, errors.New("csv Parser constructor takes at least one non-nil source argument"))
+	}
+
+	// Obtain the source argument, or arguments, from the constructor call
+	srcs, err := newSourcesFrom(rt, call.Argument(0))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	options := newDefaultParserOptions()
+	if len(call.Arguments) > 1 && !common.IsNullish(call.Argument(1)) {
+		var err error
+		options, err = newParserOptionsFrom(rt, call.Argument(1).ToObject(rt), mi.dialects)
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("encountered an error while interpreting Parser options; reason: %w", err))
+		}
+	}
+
+	// Decompress, then decode, the sources if requested, before anything is read
+	// from them, and chain them into a single one if there are several.
+	src, err := prepareSources(srcs, options)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	// Strip the byte order mark the file might start with, so that it is not
+	// read as part of the first field.
+	var baseOffset int64
+	if options.StripBOM {
+		if baseOffset, err = src.skipBOM(); err != nil {
+			common.Throw(rt, fmt.Errorf("failed to strip the byte order mark; reason: %w", err))
+		}
+	}
+
+	var source io.Reader = src
+
+	// Capture the metadata lines if requested, before handing the rest of the
+	// file over to the csv reader.
+	var metadataLines int64
+	if options.MetadataLines.Valid && options.MetadataLines.Int64 > 0 {
+		metadataLines = options.MetadataLines.Int64
+		br := bufio.NewReader(source)
+
+		lines, n, err := readMetadataLines(br, metadataLines)
+		if err != nil {
+			common.Throw(rt, err)
+		}
+		baseOffset += n
+
+		if options.OnMetadata != nil {
+			if _, err := options.OnMetadata(sobek.Undefined(), rt.ToValue(lines)); err != nil {
+				common.Throw(rt, fmt.Errorf("onMetadata callback failed; reason: %w", err))
+			}
+		}
+
+		source = br
+	}
+
+	// Detect the delimiter from the first line the csv reader reads, be it the
+	// header or a data line.
+	if options.AutoDetectDelimiter {
+		if options.Delimiter, err = detectDelimiter(src, baseOffset, options.Delimiter); err != nil {
+			common.Throw(rt, fmt.Errorf("failed to detect the delimiter; reason: %w", err))
+		}
+
+		if options.Delimiter == options.Comment || options.Delimiter == options.Quote {
+			common.Throw(rt, fmt.Errorf("the detected delimiter %q conflicts with the comment or quote option", options.Delimiter))
+		}
+	}
+
+	parser := newParser(source, options, mi.vu, mi.vu.InitEnv().Logger)
+	parser.source = src
+	parser.path = src.path
+	parser.metrics = mi.metrics
+	parser.size = src.size()
+	parser.baseOffset = baseOffset
+	parser.currentLine.Add(metadataLines)
+	parser.readerLine = metadataLines
+
+	parser.origin = parser.cursor()
+
+	// Consume the first line if requested, either as a header or to skip it
+	if err := parser.readHeader(); err != nil {
+		common.Throw(rt, err)
+	}
+
+	// Project records onto the requested columns, which the header is projected
+	// onto as well, so that the options below designate the projected columns.
+	if len(options.Columns) > 0 {
+		projection, err := parser.resolveProjection(options.Columns)
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("invalid columns option; reason: %w", err))
+		}
+
+		parser.projection = projection
+
+		if parser.header != nil {
+			// The header's width was checked against when resolving the projection.
+			parser.header, _ = parser.project(parser.header)
+		}
+	}
+
+	if options.Lazy {
+		parser.columns, parser.columnNames = newColumnIndex(parser.header)
+	}
+
+	parser.dataStart = parser.cursor()
+
+	// Resolve the columns patterns apply to, now that the header is known
+	if len(options.Patterns) > 0 {
+		patterns, err := parser.resolvePatterns(options.Patterns)
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("invalid patterns option; reason: %w", err))
+		}
+
+		parser.patterns = patterns
+	}
+
+	if len(options.Schema) > 0 {
+		schema, err := parser.resolveSchema(options.Schema)
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("invalid schema option; reason: %w", err))
+		}
+
+		parser.schema = schema
+	}
+
+	if len(options.FileColumns) > 0 {
+		files, err := parser.newFileResolver(mi.vu, options.FileColumns)
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("invalid fileColumns option; reason: %w", err))
+		}
+
+		parser.files = files
+	}
+
+	if options.DedupeConsecutive {
+		column := -1
+		if options.DedupeKey.Valid {
+			var err error
+			if column, err = parser.resolveColumn(options.DedupeKey.String); err != nil {
+				common.Throw(rt, fmt.Errorf("invalid dedupeKey option; reason: %w", err))
+			}
+		}
+
+		parser.deduper = newConsecutiveDeduper(column)
+	}
+
+	// Skip the data rows preceding the one designated by the fromLine option
+	if err := parser.skipToFromLine(); err != nil {
+		common.Throw(rt, err)
+	}
+
+	// Share the records with the other VUs if requested
+	if options.RingBuffer != nil {
+		if !src.isFile() {
+			common.Throw(rt, errors.New("the ringBuffer option requires the parser to read from a fs.File"))
+		}
+
+		ring, err := mi.ringBuffers.get(src.path, int(options.RingBuffer.Size))
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("failed to set up the shared ring buffer; reason: %w", err))
+		}
+
+		parser.ring = ring
+	}
+
+	// Resume from a previously obtained cursor if requested
+	if options.Restore != nil {
+		if err := parser.seek(*options.Restore); err != nil {
+			common.Throw(rt, fmt.Errorf("failed to restore the parser's cursor; reason: %w", err))
+		}
+	}
+
+	obj := rt.ToValue(parser).ToObject(rt)
+	common.MakeAsyncIterable(rt, obj, parser.Next)
+
+	return obj
+}
+
+// newParser creates a new [Parser] reading from the provided source, and
+// configured with the given options.
+func newParser(source io.Reader, options parserOptions, vu modules.VU, logger logrus.FieldLogger) *Parser {
+	parser := &Parser{
+		reader:    newRecordReader(source, options, logger),
+		options:   options,
+		staleness: newStalenessFilter(options, time.Now()),
+		shard:     options.Shard,
+		logger:    logger,
+		vu:        vu,
+	}
+
+	// When a capacity is preallocated, records are copied into a single
+	// buffer, sized to the number of columns, instead of a newly allocated
+	// slice for each record.
+	if options.PreallocCapacity.Valid {
+		parser.record = make([]string, 0, options.PreallocCapacity.Int64)
+	}
+
+	return parser
+}
+
+// newRecordReader instantiates and configures a record reader using the provided
+// source and options.
+//
+// Issues the reader recovers from, if any, are reported through the logger.
+func newRecordReader(source io.Reader, options parserOptions, logger logrus.FieldLogger) recordReader {
+	// The readers reuse the buffered reader instead of wrapping it in their own,
+	// as long as it is at least as large as theirs.
+	if options.BufferSize.Valid {
+		source = bufio.NewReaderSize(source, int(options.BufferSize.Int64))
+	}
+
+	if options.BestEffort {
+		return newBestEffortReader(source, options.Delimiter, options.Comment, logger)
+	}
+
+	// The standard library's reader only supports the default quote.
+	if options.Quote != defaultQuote {
+		return newQuoteReader(source, options)
+	}
+
+	r := csv.NewReader(source)
+	r.Comma = options.Delimiter
+	r.Comment = options.Comment
+	r.LazyQuotes = options.LazyQuotes
+	r.TrimLeadingSpace = options.TrimLeadingSpace
+
+	if options.FieldsPerRecord.Valid {
+		r.FieldsPerRecord = int(options.FieldsPerRecord.Int64)
+	}
+	r.ReuseRecord = options.PreallocCapacity.Valid
+
+	return r
+}
+
+// seek repositions the parser at the provided cursor.
+//
+// As the csv reader buffers its input, it is replaced by a new one reading
+// from the cursor's offset.
+func (p *Parser) seek(c cursor) error {
+	if p.source == nil {
+		return errors.New("the parser's source is not seekable")
+	}
+
+	if _, err := p.source.Seek(c.ByteOffset, io.SeekStart); err != nil {
+		return err
+	}
+
+	p.reader = newRecordReader(p.source, p.options, p.logger)
+	p.baseOffset = c.ByteOffset
+	p.currentLine.Store(c.Line)
+	p.offset.Store(c.ByteOffset)
+	p.readerLine = c.Line
+	p.pending = nil
+
+	return nil
+}
+
+// cursor returns the parser's current position.
+func (p *Parser) cursor() cursor {
+	return cursor{
+		Line:       p.currentLine.Load(),
+		ByteOffset: p.baseOffset + p.reader.InputOffset(),
+	}
+}
+
+// restoringPosition runs fn, and repositions the parser where it was
+// beforehand, whether fn succeeded or not.
+//
+// If the position cannot be restored, that error is returned instead of fn's.
+func (p *Parser) restoringPosition(fn func() error) error {
+	if p.source == nil {
+		return errors.New("the parser's source is not seekable")
+	}
+
+	previous, offset, pending := p.cursor(), p.offset.Load(), p.pending
+
+	err := fn()
+
+	if serr := p.seek(previous); serr != nil {
+		return fmt.Errorf("failed to restore the parser's position; reason: %w", serr)
+	}
+	p.pending = pending
+	p.offset.Store(offset)
+
+	return err
+}
+
+// Next returns a promise resolving to the next row of the CSV file.
+//
+// Once the end of the file, or the line configured through the `toLine`
+// option, has been reached, the promise resolves to a result which `done`
+// property is set to true.
+//
+// Records older than the `maxAgeSeconds` option, if set, are skipped, as are the
+// records for which the `filter` option's callback, if set, returns false.
+//
+// When the `includeCursor` or `includeRaw` option is set, the result also holds
+// the parser's cursor, or the text the record was parsed from, respectively.
+//
+// If a record cannot be parsed, the promise is rejected with an error which
+// `line`, `column`, and `field` properties position the failure.
+func (p *Parser) Next() *sobek.Promise {
+	if p.options.Filter != nil || p.options.Transform != nil {
+		return p.nextFiltered()
+	}
+
+	if !p.options.IncludeCursor && !p.options.IncludeRaw {
+		read := func() ([]string, error) {
+			records, err := p.next()
+			return p.detach(records), err
+		}
+
+		return readAsync(p, read, func(records []string) any {
+			return parseResult{Done: false, Value: p.toValue(records)}
+		})
+	}
+
+	type cursorRecords struct {
+		records []string
+		cursor  cursor
+		raw     string
+	}
+
+	read := func() (cursorRecords, error) {
+		records, err := p.next()
+		return cursorRecords{records: p.detach(records), cursor: p.cursor(), raw: p.raw}, err
+	}
+
+	return readAsync(p, read, func(cr cursorRecords) any {
+		return p.detailedResult(p.toValue(cr.records), cr.cursor, cr.raw)
+	})
+}
+
+// nextFiltered returns a promise resolving to the next record for which the
+// `filter` option's callback returns true, as mapped by the `transform` option's
+// callback.
+//
+// As the callbacks have to be called from the event loop, they are called as
+// each record is handed over to them by [Parser.nextMatching]. The promise is
+// rejected if either callback throws.
+func (p *Parser) nextFiltered() *sobek.Promise {
+	promise, resolve, reject := p.vu.Runtime().NewPromise()
+
+	p.nextMatching(p.matchesFilter, func(record matchedRecord, value sobek.Value, err error) {
+		if err == nil {
+			value, err = p.transform(value, record.cursor.Line)
+		}
+
+		switch {
+		case errors.Is(err, io.EOF):
+			resolve(parseResult{Done: true, Value: []string{}})
+		case err != nil:
+			reject(p.rejectionReason(err))
+		case p.options.IncludeCursor || p.options.IncludeRaw:
+			resolve(p.detailedResult(value, record.cursor, record.raw))
+		default:
+			resolve(parseResult{Done: false, Value: value})
+		}
+	})
+
+	return promise
+}
+
+// readAsync runs the provided read function in a background goroutine, and returns
+// a promise resolving to the result produced by toResult.
+//
+// The promise resolves to a done [parseResult] once read returns [io.EOF].
+func readAsync[T any](p *Parser, read func() (T, error), toResult func(T) any) *sobek.Promise {
+	return runAsync(p,
+		func() (readOutcome[T], error) {
+			result, err := read()
+			if errors.Is(err, io.EOF) {
+				return readOutcome[T]{done: true}, nil
+			}
+
+			return readOutcome[T]{result: result}, err
+		},
+		func(outcome readOutcome[T]) any {
+			if outcome.done {
+				return parseResult{Done: true, Value: []string{}}
+			}
+
+			return toResult(outcome.result)
+		},
+	)
+}
+
+// readOutcome holds the outcome of a read operation run by [readAsync].
+type readOutcome[T any] struct {
+	result T
+	done   bool
+}
+
+// runAsync runs the provided function in a background goroutine, and returns a
+// promise resolving to the value produced by toValue, which is called from the
+// event loop.
+//
+// The parser's lock is held while the run is in progress. The promise is
+// rejected if the VU's context is done before the run completes, as
+// [runLocked] describes.
+func runAsync[T any](p *Parser, run func() (T, error), toValue func(T) any) *sobek.Promise {
+	promise, resolve, reject := p.vu.Runtime().NewPromise()
+
+	runLocked(p, run, func(result T, err error) {
+		if err != nil {
+			reject(p.rejectionReason(err))
+			return
+		}
+
+		resolve(toValue(result))
+	})
+
+	return promise
+}
+
+// runLocked runs the provided function in a background goroutine holding the
+// parser's lock, and hands its outcome over to handle, which is called from the
+// event loop. The lock is released by the goroutine as soon as the run
+// completes, as the event loop might never call handle once the VU's context is
+// done. The outcome thus must not be held by the parser's record buffer, which
+// is reused by subsequent reads, as [Parser.detach] ensures.
+//
+// As reading from a slow source might block for a while, handle is called with
+// the VU context's error as soon as the context is done, such as when the test
+// is aborted, rather than once the run completes. The run is then abandoned,
+// and its outcome discarded. Runs starting once the context is done fail right
+// away, without reading.
+func runLocked[T any](p *Parser, run func() (T, error), handle func(T, error)) {
+	ctx := p.vu.Context()
+	callback := p.vu.RegisterCallback()
+
+	type outcome struct {
+		result T
+		err    error
+	}
+
+	outcomes := make(chan outcome, 1)
+
+	go func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if err := ctx.Err(); err != nil {
+			outcomes <- outcome{err: err}
+			return
+		}
+
+		result, err := run()
+		outcomes <- outcome{result: result, err: err}
+	}()
+
+	go func() {
+		select {
+		case o := <-outcomes:
+			callback(func() error {
+				handle(o.result, o.err)
+				return nil
+			})
+		case <-ctx.Done():
+			callback(func() error {
+				var zero T
+				handle(zero, fmt.Errorf("reading was interrupted; reason: %w", ctx.Err()))
+				return nil
+			})
+		}
+	}()
+}
+
+// next reads the next record to be returned by the parser, skipping the ones
+// that should not be returned according to the parser's options.
+//
+// It returns [io.EOF] once there are no more records to return, or once as many
+// records as the `limit` option allows have been returned. Each record read
+// is accounted for by the parser's metrics.
+func (p *Parser) next() ([]string, error) {
+	if p.options.Limit.Valid && p.returned >= p.options.Limit.Int64 {
+		if p.ring != nil {
+			p.ring.close()
+		}
+
+		return nil, io.EOF
+	}
+
+	// Records read ahead have already been accounted for by the metrics.
+	readAhead := p.pending != nil
+	p.before = cursor{Line: p.currentLine.Load(), ByteOffset: p.offset.Load()}
+
+	records, err := p.nextUnlimited()
+	if err == nil {
+		p.returned++
+		if !readAhead {
+			p.pushRowMetrics()
+		}
+	}
+
+	return records, err
+}
+
+// nextUnlimited reads the next record to be returned by the parser, regardless
+// of the `limit` option.
+//
+// When the `ringBuffer` option is set, the record is also pushed to the shared
+// ring buffer, which is closed once the end of the file is reached.
+func (p *Parser) nextUnlimited() ([]string, error) {
+	if p.pending != nil {
+		return p.consumePending(), nil
+	}
+
+	records, err := p.nextRecord()
+	if p.options.Cycle {
+		records, err = p.cycle(records, err)
+	}
+
+	if p.ring == nil {
+		return records, err
+	}
+
+	if errors.Is(err, io.EOF) {
+		p.ring.close()
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The records might be held by the parser's record buffer, which is reused.
+	if err := p.ring.push(p.vu.Context(), append([]string(nil), records...)); err != nil {
+		return nil, fmt.Errorf("failed to push line %d to the shared ring buffer; reason: %w", p.currentLine.Load(), err)
+	}
+
+	return records, nil
+}
+
+// cycle rewinds the parser to its start, and reads the first data record again,
+// if the provided error denotes the end of the file, or the line configured
+// through the `toLine` option, was reached. The parser's current line is reset
+// accordingly, so that the `toLine` option applies to each cycle.
+//
+// In order not to loop endlessly, [io.EOF] is returned if no record was read
+// since the parser last rewound.
+func (p *Parser) cycle(records []string, err error) ([]string, error) {
+	if !errors.Is(err, io.EOF) {
+		if err == nil {
+			p.cycleRead = true
+		}
+
+		return records, err
+	}
+
+	if !p.cycleRead {
+		return nil, io.EOF
+	}
+
+	if err := p.seek(p.start); err != nil {
+		return nil, fmt.Errorf("failed to rewind to the first data line; reason: %w", err)
+	}
+
+	p.cycleRead = false
+
+	records, err = p.nextRecord()
+	if err == nil {
+		p.cycleRead = true
+	}
+
+	return records, err
+}
+
+// nextRecord reads the next record matching the parser's options from the
+// underlying reader.
+func (p *Parser) nextRecord() ([]string, error) {
+	for {
+		// If the toLine option was set, and we have reached it, we're done.
+		if p.options.ToLine.Valid && p.currentLine.Load() > p.options.ToLine.Int64 {
+			return nil, io.EOF
+		}
+
+		records, err := p.read()
+
+		// As the csv reader expects every record to hold as many fields as the
+		// first one, empty lines are reported as errors, unless they are skipped.
+		emptyLine := p.options.SkipEmptyLines && isEmptyRecord(records)
+		if err != nil && !(emptyLine && errors.Is(err, csv.ErrFieldCount)) {
+			if p.options.SkipErrors && p.skipError(err) {
+				continue
+			}
+
+			// The reader reports lines relative to where it started reading, which
+			// differs from the parser's line when metadata lines were skipped, or
+			// when it was repositioned.
+			var pe *csv.ParseError
+			if errors.As(err, &pe) {
+				return nil, p.newRecordParseError(pe)
+			}
+
+			if errors.Is(err, csv.ErrFieldCount) {
+				return nil, &recordParseError{line: p.currentLine.Load() + 1, field: -1, err: csv.ErrFieldCount}
+			}
+
+			return nil, err
+		}
+
+		p.advanceLine()
+
+		if emptyLine {
+			continue
+		}
+
+		if p.options.SkipBlankRows && isBlank(records) {
+			continue
+		}
+
+		inShard, err := p.inShard()
+		if err != nil {
+			return nil, err
+		}
+
+		if !inShard {
+			continue
+		}
+
+		if p.staleness != nil {
+			stale, err := p.staleness.isStale(records)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", p.currentLine.Load(), err)
+			}
+
+			if stale {
+				continue
+			}
+		}
+
+		if err := p.validatePatterns(records); err != nil {
+			return nil, err
+		}
+
+		if p.deduper != nil && p.deduper.isDuplicate(records) {
+			continue
+		}
+
+		if p.files != nil {
+			if err := p.files.resolve(records); err != nil {
+				return nil, fmt.Errorf("line %d: %w", p.currentLine.Load(), err)
+			}
+		}
+
+		if err := p.boundFields(records); err != nil {
+			return nil, err
+		}
+
+		if err := p.validateSchema(records); err != nil {
+			return nil, err
+		}
+
+		return records, nil
+	}
+}
+
+// fieldPositioner is implemented by the record readers able to report the
+// position of the fields of the most recently read record, such as [csv.Reader].
+type fieldPositioner interface {
+	FieldPos(field int) (line, column int)
+}
+
+// recordEndLine returns the one-based line, relative to where the provided
+// reader started reading, the record it read last ends on, and false if the
+// reader does not report it.
+//
+// The line the record's last field starts on is offset by the line terminators
+// it holds, which quoted fields spanning multiple lines do, and which readers
+// normalize to line feeds.
+func recordEndLine(reader recordReader, records []string) (int64, bool) {
+	switch r := reader.(type) {
+	case *bestEffortReader:
+		return r.line, true
+	case fieldPositioner:
+		if len(records) == 0 {
+			return 0, false
+		}
+
+		last := len(records) - 1
+		line, _ := r.FieldPos(last)
+
+		return int64(line + strings.Count(records[last], "\n")), true
+	default:
+		return 0, false
+	}
+}
+
+// advanceLine accounts for the record read last having been consumed, updating
+// the parser's current line and offset.
+//
+// The parser's current line is set to the physical line the record ends on, as
+// reported by the reader, so that it accounts for the comment lines the reader
+// skipped, and for the quoted fields spanning multiple lines.
+func (p *Parser) advanceLine() {
+	p.offset.Store(p.baseOffset + p.reader.InputOffset())
+
+	if p.recordLine > 0 {
+		p.currentLine.Store(p.readerLine + p.recordLine)
+		return
+	}
+
+	p.currentLine.Add(1)
+}
+
+// isEmptyRecord returns true if the provided record holds no field, or a single
+// empty one, as read from an empty line.
+func isEmptyRecord(records []string) bool {
+	return len(records) == 0 || (len(records) == 1 && records[0] == "")
+}
+
+// isBlank returns true if every field of the provided record is empty, or only
+// holds whitespace.
+func isBlank(records []string) bool {
+	for _, field := range records {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// read reads the next record from the underlying reader, and projects it onto
+// the columns designated by the `columns` option, if set.
+//
+// When the `preallocCapacity` option is set, the record is copied into the
+// parser's record buffer, which is only valid until the next call to read.
+//
+// The time spent reading is accounted for by the parser's metrics. When the
+// `includeRaw` option is set, the text the record was parsed from is kept.
+func (p *Parser) read() ([]string, error) {
+	offset, start := p.reader.InputOffset(), time.Now()
+	records, err := p.reader.Read()
+	p.readDuration += time.Since(start)
+
+	// Records holding an unexpected number of fields are still consumed.
+	p.recordLine = 0
+	if err == nil || errors.Is(err, csv.ErrFieldCount) {
+		p.recordLine, _ = recordEndLine(p.reader, records)
+	}
+
+	if err != nil {
+		return records, err
+	}
+
+	if p.options.IncludeRaw {
+		if p.raw, err = p.readRaw(offset, p.reader.InputOffset()); err != nil {
+			return nil, fmt.Errorf("failed to read the record's raw text; reason: %w", err)
+		}
+	}
+
+	if records, err = p.project(records); err != nil || p.record == nil {
+		return records, err
+	}
+
+	p.record = append(p.record[:0], records...)
+
+	return p.record, nil
+}
+
+// detach returns the provided records, or a copy of them if they are held by
+// the parser's record buffer, which is reused by subsequent reads, so that they
+// can be handed over to the event loop once the parser's lock is released.
+func (p *Parser) detach(records []string) []string {
+	if p.record == nil || records == nil {
+		return records
+	}
+
+	return append([]string(nil), records...)
+}
+
+// toValue converts the provided records into a value that can safely be
+// handed over to the runtime.
+//
+// It must be called from the event loop.
+func (p *Parser) toValue(records []string) any {
+	if p.options.Lazy {
+		return p.vu.Runtime().NewDynamicObject(newLazyRecord(p.columns, p.columnNames, records, p.decodeField))
+	}
+
+	if p.options.Header {
+		return p.toMap(records)
+	}
+
+	if p.schema != nil {
+		values := make([]any, len(records))
+		for i, field := range records {
+			values[i] = p.decodeField(i, field)
+		}
+
+		return p.vu.Runtime().NewArray(values...)
+	}
+
+	if p.record == nil {
+		return records
+	}
+
+	// The runtime would otherwise wrap the parser's record buffer, and
+	// observe its content changing on subsequent reads.
+	values := make([]any, len(records))
+	for i, field := range records {
+		values[i] = field
+	}
+
+	return p.vu.Runtime().NewArray(values...)
+}
+
+// toMap maps the header's column names to the provided records' fields.
+//
+// Fields beyond the header's length are ignored, and columns missing from the
+// record are mapped to an empty string. When the `schema` option is set, fields
+// are coerced to their column's type.
+func (p *Parser) toMap(records []string) any {
+	if p.schema == nil {
+		values := make(map[string]string, len(p.header))
+		for i, name := range p.header {
+			var field string
+			if i < len(records) {
+				field = records[i]
+			}
+
+			values[name] = field
+		}
+
+		return values
+	}
+
+	values := make(map[string]any, len(p.header))
+	for i, name := range p.header {
+		if i >= len(records) {
+			values[name] = ""
+			continue
+		}
+
+		values[name] = p.decodeField(i, records[i])
+	}
+
+	return values
+}
+
+// decodeField decodes the raw value of the field at the provided column index,
+// coercing it to the column's type when the `schema` option is set.
+//
+// It must be called from the event loop.
+func (p *Parser) decodeField(index int, raw string) sobek.Value {
+	return p.coerceValue(index, raw)
+}
+
+// parserOptions holds options used to configure CSV parsing when utilizing the module.
+//
+// The options can be set by the user when instantiating a new [Parser].
+type parserOptions struct {
+	// Delimiter is the character that separates the fields in the CSV.
+	Delimiter rune `js:"delimiter"`
+
+	// AutoDetectDelimiter indicates whether the delimiter should be detected
+	// from the file's first line, as the most frequent of `,`, `\t`, `;` and
+	// `|`. Delimiter is used if the line holds none of them.
+	AutoDetectDelimiter bool `js:"autoDetectDelimiter"`
+
+	// Comment is the character that, when starting a line, marks it as a comment
+	// which is skipped by the parser. Comment lines are accounted for by the
+	// parser's current line.
+	Comment rune `js:"comment"`
+
+	// Quote is the character wrapping fields which hold delimiters, quotes,
+	// or line terminators. It defaults to `"`; any other character makes the
+	// parser use its own reader, slower than the standard library's one.
+	Quote rune `js:"quote"`
+
+	// SkipFirstLine indicates whether the first line should be skipped.
+	SkipFirstLine bool `js:"skipFirstLine"`
+
+	// Header indicates whether the first line should be consumed as the header,
+	// in which case records are returned as objects mapping the header's column
+	// names to the records' fields.
+	//
+	// Empty column names are named after the zero-based index of their column,
+	// prefixed with an underscore, such as `_2`.
+	//
+	// It conflicts with the SkipFirstLine option.
+	Header bool `js:"header"`
+
+	// AsObjects is an alias of the Header option: when set, the first line is
+	// consumed as the header, and records are returned as objects keyed by the
+	// header's column names.
+	AsObjects bool `js:"asObjects"`
+
+	// AllowDuplicateHeaders indicates whether the header may hold the same
+	// column name several times, in which case records map the name to the
+	// field of the last column holding it, or of the first one when the Lazy
+	// option is set. Otherwise, such a header is rejected.
+	AllowDuplicateHeaders bool `js:"allowDuplicateHeaders"`
+
+	// FromLine indicates the zero-based index of the first data row to return,
+	// the data rows preceding it being skipped: with `fromLine: N`, the first
+	// row [Parser.Next] returns is data row N, regardless of whether a header,
+	// or a first line, was consumed through the Header or SkipFirstLine option,
+	// as it is not a data row. Rows are indexed as they are by [Parser.At].
+	//
+	// As opposed to ToLine, it counts rows, rather than physical lines: a row
+	// holding a quoted field spanning multiple lines is a single row.
+	FromLine null.Int `js:"fromLine"`
+
+	// ToLine indicates the line at which to stop reading the CSV file (inclusive).
+	//
+	// As the parser's current line, and the lines errors report, it counts
+	// physical lines: a record holding a quoted field spanning multiple lines
+	// accounts for each of them.
+	ToLine null.Int `js:"toLine"`
+
+	// SkipEmptyLines indicates whether empty records, holding no field or a single
+	// empty one, should be skipped instead of being returned.
+	//
+	// Skipped lines are accounted for by the FromLine and ToLine options.
+	SkipEmptyLines bool `js:"skipEmptyLines"`
+
+	// SkipBlankRows indicates whether records which fields are all empty, or only
+	// hold whitespace, should be skipped.
+	//
+	// Note that truly empty lines are always ignored by the parser, whereas this
+	// option also covers lines such as `  ` or `, ,`.
+	SkipBlankRows bool `js:"skipBlankRows"`
+
+	// TimestampColumn indicates the index of the column holding the records'
+	// timestamp, used in conjunction with MaxAgeSeconds.
+	//
+	// Timestamps are expected to be either a number of seconds elapsed since
+	// the Unix epoch, or an RFC 3339 formatted date.
+	TimestampColumn null.Int `js:"timestampColumn"`
+
+	// MaxAgeSeconds indicates the maximum age, in seconds, a record's timestamp can
+	// have, relative to the parser's creation, for it to be returned. Older records
+	// are skipped.
+	MaxAgeSeconds null.Int `js:"maxAgeSeconds"`
+
+	// PreallocCapacity indicates the capacity of the buffer parsed records are
+	// copied into, in order to reduce allocations when parsing wide files.
+	//
+	// When set to 0, the buffer is sized to the number of columns of the first
+	// record.
+	PreallocCapacity null.Int `js:"preallocCapacity"`
+
+	// BufferSize indicates the size, in bytes, of the buffer the source is read
+	// through, so that large files can be read in fewer, larger, reads. When
+	// unset, the reader's default buffer size of 4096 bytes is used.
+	BufferSize null.Int `js:"bufferSize"`
+
+	// MetadataLines indicates the number of lines at the beginning of the file that
+	// hold metadata, rather than CSV records. Those lines are not parsed, and are
+	// handed over to the OnMetadata callback, if set, before parsing begins.
+	MetadataLines null.Int `js:"metadataLines"`
+
+	// OnMetadata is called with the metadata lines, as an array of strings, when
+	// the MetadataLines option is set.
+	OnMetadata sobek.Callable `js:"onMetadata"`
+
+	// Filter is called with the fields of each record, and the line it was read
+	// from, and decides whether the record should be returned by Next, or
+	// skipped, depending on whether it returns a truthy value.
+	Filter sobek.Callable `js:"filter"`
+
+	// Transform holds a callback which, when set, is called with each record's
+	// value and the line it was read from, once accepted by Filter, if set. The
+	// value it returns is returned in place of the record's.
+	Transform sobek.Callable `js:"transform"`
+
+	// IncludeCursor indicates whether the results of Next should hold the
+	// parser's cursor, describing its position within the file.
+	IncludeCursor bool `js:"includeCursor"`
+
+	// IncludeRaw indicates whether the results of Next should hold the text
+	// each record was parsed from, including the line terminators of the
+	// quoted fields spanning multiple lines.
+	IncludeRaw bool `js:"includeRaw"`
+
+	// Restore holds a cursor, as previously returned by a parser, from which
+	// the parser should resume reading.
+	Restore *cursor `js:"restore"`
+
+	// LazyQuotes indicates whether quotes appearing in non-standard positions,
+	// such as within unquoted fields, should be tolerated instead of failing
+	// the read. It defaults to false, so that parsing is strict.
+	LazyQuotes bool `js:"lazyQuotes"`
+
+	// FieldsPerRecord indicates the number of fields each record is expected to
+	// hold. When positive, records must hold exactly that many fields. When 0,
+	// the default, records must hold as many fields as the first one, which is
+	// the header when the Header or SkipFirstLine option is set. When -1, the
+	// number of fields is not checked.
+	FieldsPerRecord null.Int `js:"fieldsPerRecord"`
+
+	// TrimLeadingSpace indicates whether the leading white space of fields should
+	// be ignored. It defaults to false, preserving fields as they are.
+	TrimLeadingSpace bool `js:"trimLeadingSpace"`
+
+	// Cycle indicates whether the parser should rewind to the first data line,
+	// and keep on reading, when reaching the end of the file, or the line
+	// configured through ToLine, instead of being done.
+	//
+	// The header and the lines preceding FromLine are not returned again.
+	Cycle bool `js:"cycle"`
+
+	// BestEffort indicates whether the parser should recover from malformed
+	// quoting instead of failing. Lines holding unbalanced quotes are then split
+	// on the delimiter, and a warning is logged.
+	//
+	// This mode is lossy: recovered records might not hold the values intended
+	// by the file's author, and the number of fields per record is not enforced.
+	BestEffort bool `js:"bestEffort"`
+
+	// RingBuffer, when set, makes the parser push the records it reads to a
+	// bounded buffer shared with the parsers of every VU reading from the same
+	// file, which can take them through TakeShared.
+	RingBuffer *ringBufferOptions `js:"ringBuffer"`
+
+	// Patterns holds regular expressions, keyed by column, the records' fields
+	// must match. Columns are designated by name when the Header or SkipFirstLine
+	// option is set, and by index otherwise.
+	Patterns map[string]*regexp.Regexp `js:"patterns"`
+
+	// OnPatternMismatch indicates how fields not matching their column's pattern
+	// are handled: either "reject", the default, failing the read, or "warn",
+	// logging a warning and returning the record nonetheless.
+	OnPatternMismatch string `js:"onPatternMismatch"`
+
+	// Lazy indicates whether records should be returned as objects keyed by the
+	// header's column names, which fields are only decoded once accessed. This
+	// reduces the work done for wide files, of which only a few columns are used.
+	//
+	// It requires the Header or SkipFirstLine option to be set.
+	Lazy bool `js:"lazy"`
+
+	// DedupeConsecutive indicates whether records identical to the record yielded
+	// right before them should be skipped. Records are compared as a whole, or by
+	// the DedupeKey column when set.
+	DedupeConsecutive bool `js:"dedupeConsecutive"`
+
+	// DedupeKey designates the column records are compared by when the
+	// DedupeConsecutive option is set, either by name or by index.
+	DedupeKey null.String `js:"dedupeKey"`
+
+	// FileColumns holds the columns which fields hold file names, keyed by column,
+	// with values holding the base path the file names are relative to. The
+	// content of the referenced files is returned in place of their name.
+	FileColumns map[string]string `js:"fileColumns"`
+
+	// MaxFieldLength indicates the maximum number of characters a field can hold.
+	// Records holding longer fields are rejected, unless TruncateFields is set.
+	MaxFieldLength null.Int `js:"maxFieldLength"`
+
+	// TruncateFields indicates whether fields longer than MaxFieldLength should
+	// be truncated to that length, instead of being rejected.
+	TruncateFields bool `js:"truncateFields"`
+
+	// Ellipsis indicates whether truncated fields should end with an ellipsis,
+	// which counts towards MaxFieldLength.
+	Ellipsis bool `js:"ellipsis"`
+
+	// Columns designates the columns records are projected onto, in order, either
+	// by name when the Header or SkipFirstLine option is set, or by index. The
+	// other options designating columns refer to the projected ones.
+	Columns []string `js:"columns"`
+
+	// Schema holds the types fields are coerced to, keyed by column, among
+	// "string", "number", "boolean" and "date". Columns are designated by name
+	// when the Header or SkipFirstLine option is set, and by index otherwise.
+	// Fields that cannot be coerced fail the read, unless the TypeMode option
+	// is lenient.
+	Schema map[string]string `js:"schema"`
+
+	// ColumnTypes is an alternative to the Schema option, which also accepts
+	// the "int", "float", and "bool" types, "float" and "bool" being aliases
+	// of "number" and "boolean". It conflicts with the Schema option.
+	ColumnTypes map[string]string `js:"columnTypes"`
+
+	// TypeMode indicates how fields that cannot be coerced to their column's
+	// type are handled, among "strict", the default, failing the read, and
+	// "lenient", leaving them as strings.
+	TypeMode string `js:"typeMode"`
+
+	// Encoding indicates the character encoding of the file, among "utf-8", the
+	// default, "latin1", "iso-8859-1", "windows-1252", "utf-16le" and "utf-16be".
+	// Files in another encoding than UTF-8 are decoded in memory before being
+	// parsed, and the byte offsets of cursors refer to the decoded content.
+	Encoding string `js:"encoding"`
+
+	// StripBOM indicates whether a UTF-8 byte order mark the file starts with
+	// should be stripped, rather than being read as part of the first field. It
+	// defaults to true.
+	StripBOM bool `js:"stripBOM"`
+
+	// Shard designates the partition of the file's rows the parser returns, as
+	// an object holding the partition's zero-based index and the total number
+	// of partitions. Rows are assigned to partitions in a round-robin fashion,
+	// by the line they end on.
+	Shard *shardOptions `js:"shard"`
+
+	// PartitionByVU indicates whether the parser should only return the rows of
+	// the partition of the VU reading them, among as many partitions as the
+	// maximum number of VUs the test can run. Partitions are fixed for the
+	// duration of the test, and are not rebalanced as VUs ramp up or down.
+	//
+	// VUs are identified across the instances of distributed tests, so that
+	// instances running different execution segments do not read the same rows.
+	//
+	// It conflicts with the Shard option.
+	PartitionByVU bool `js:"partitionByVU"`
+
+	// PartitionBy designates what the file's rows are partitioned by. Its only
+	// supported value, "vu", is equivalent to setting the PartitionByVU option.
+	PartitionBy string `js:"partitionBy"`
+
+	// Compression indicates the compression of the file, among "none", the
+	// default, "gzip", "zstd", and "auto", detecting gzip and zstd compressed
+	// files from their first bytes. Compressed files are decompressed as they
+	// are parsed, and the other options apply to the decompressed content.
+	// Files the fs module already decompresses, based on their extension, are
+	// parsed as is.
+	Compression string `js:"compression"`
+
+	// SkipErrors indicates whether records that cannot be parsed should be
+	// skipped, instead of failing the read. The errors are collected, and can
+	// be retrieved through the parser's errors method. It can also be set with
+	// the `onError` option, set to either "skip" or "abort", the default.
+	SkipErrors bool `js:"skipErrors"`
+
+	// Limit indicates the maximum number of records the parser returns, once
+	// skipped and filtered records are set aside. When the Cycle option is set,
+	// it caps the number of records returned across cycles.
+	Limit null.Int `js:"limit"`
+}
+
+// newDefaultParserOptions creates a new parserOptions instance with default values.
+func newDefaultParserOptions() parserOptions {
+	return parserOptions{
+		Delimiter:         ',',
+		Quote:             defaultQuote,
+		SkipFirstLine:     false,
+		OnPatternMismatch: patternMismatchReject,
+		Encoding:          encodingUTF8,
+		Compression:       compressionNone,
+		StripBOM:          true,
+		TypeMode:          typeModeStrict,
+	}
+}
+
+// newParserOptionsFrom creates a new parserOptions instance from the given
+// Sobek object.
+//
+// If the object holds a `dialect` property, the options registered under that
+// name are used as a base, and any other property set on the object overrides
+// them.
+func newParserOptionsFrom(rt *sobek.Runtime, obj *sobek.Object, dialects *dialectRegistry) (parserOptions, error) {
+	options := newDefaultParserOptions()
+
+	if obj == nil {
+		return options, nil
+	}
+
+	if v := obj.Get("dialect"); !common.IsNullish(v) {
+		dialect, ok := dialects.get(v.String())
+		if !ok {
+			return options, fmt.Errorf("unknown dialect %q", v.String())
+		}
+
+		options = dialect
+	}
+
+	return applyParserOptions(rt, options, obj)
+}
+
+// newCursorFrom creates a new cursor from the provided Sobek value, as
+// previously returned by a parser.
+func newCursorFrom(rt *sobek.Runtime, v sobek.Value) (cursor, error) {
+	if c, ok := v.Export().(cursor); ok {
+		return c, nil
+	}
+
+	obj := v.ToObject(rt)
+	line, lineOk := integerProperty(obj, "line")
+	byteOffset, byteOffsetOk := integerProperty(obj, "byteOffset")
+	if !lineOk || !byteOffsetOk {
+		return cursor{}, errors.New("a cursor must hold integer line and byteOffset properties")
+	}
+
+	if line < 0 || byteOffset < 0 {
+		return cursor{}, errors.New("a cursor's line and byteOffset must be positive")
+	}
+
+	return cursor{Line: line, ByteOffset: byteOffset}, nil
+}
+
+// integerProperty returns the value of the provided object's property, and
+// whether it is set to an integer.
+func integerProperty(obj *sobek.Object, name string) (int64, bool) {
+	v := obj.Get(name)
+	if common.IsNullish(v) {
+		return 0, false
+	}
+
+	i, ok := v.Export().(int64)
+	return i, ok
+}
+
+// parseDelimiter parses the provided delimiter option's value.
+//
+// Delimiters can also be designated by name, such as "tab" or "semicolon".
+func parseDelimiter(v sobek.Value) (rune, error) {
+	delimiter := v.String()
+	if named, ok := namedDelimiters[delimiter]; ok {
+		return named, nil
+	}
+
+	// A delimiter is gonna be treated as a rune in the Go code, so we need to make sure it's a single character,
+	// which might be encoded over multiple bytes.
+	runes := []rune(delimiter)
+	if len(runes) == 0 {
+		return 0, errors.New("delimiter must be a single non-empty character")
+	}
+
+	if len(runes) > 1 {
+		return 0, errors.New("delimiter must be a single character")
+	}
+
+	return runes[0], nil
+}
+
+// applyParserOptions overrides the provided options with the ones set on the
+// given Sobek object, and validates the result.
+func applyParserOptions(rt *sobek.Runtime, options parserOptions, obj *sobek.Object) (parserOptions, error) {
+	if v := obj.Get("delimiter"); !common.IsNullish(v) {
+		delimiter, err := parseDelimiter(v)
+		if err != nil {
+			return options, err
+		}
+
+		options.Delimiter = delimiter
+	}
+
+	if v := obj.Get("autoDetectDelimiter"); !common.IsNullish(v) {
+		options.AutoDetectDelimiter = v.ToBoolean()
+	}
+
+	if v := obj.Get("comment"); !common.IsNullish(v) {
+		comment := []rune(v.String())
+		if len(comment) != 1 {
+			return options, errors.New("comment must be a single character")
+		}
+
+		options.Comment = comment[0]
+	}
+
+	if options.Comment != 0 && options.Comment == options.Delimiter {
+		return options, errors.New("comment must differ from the delimiter")
+	}
+
+	if v := obj.Get("quote"); !common.IsNullish(v) {
+		quote := []rune(v.String())
+		if len(quote) != 1 || !validQuote(quote[0]) {
+			return options, errors.New("quote must be a single character, other than a line terminator")
+		}
+
+		options.Quote = quote[0]
+	}
+
+	if options.Quote == options.Delimiter || options.Quote == options.Comment {
+		return options, errors.New("quote must differ from the delimiter and the comment")
+	}
+
+	if v := obj.Get("skipFirstLine"); !common.IsNullish(v) {
+		options.SkipFirstLine = v.ToBoolean()
+	}
+
+	if v := obj.Get("header"); !common.IsNullish(v) {
+		options.Header = v.ToBoolean()
+	}
+
+	if v := obj.Get("asObjects"); !common.IsNullish(v) {
+		options.AsObjects = v.ToBoolean()
+	}
+
+	if v := obj.Get("allowDuplicateHeaders"); !common.IsNullish(v) {
+		options.AllowDuplicateHeaders = v.ToBoolean()
+	}
+
+	if v := obj.Get("fromLine"); !common.IsNullish(v) {
+		options.FromLine = null.IntFrom(v.ToInteger())
+	}
+
+	if v := obj.Get("toLine"); !common.IsNullish(v) {
+		options.ToLine = null.IntFrom(v.ToInteger())
+	}
+
+	if v := obj.Get("skipEmptyLines"); !common.IsNullish(v) {
+		options.SkipEmptyLines = v.ToBoolean()
+	}
+
+	if v := obj.Get("skipBlankRows"); !common.IsNullish(v) {
+		options.SkipBlankRows = v.ToBoolean()
+	}
+
+	if v := obj.Get("timestampColumn"); !common.IsNullish(v) {
+		options.TimestampColumn = null.IntFrom(v.ToInteger())
+	}
+
+	if v := obj.Get("maxAgeSeconds"); !common.IsNullish(v) {
+		options.MaxAgeSeconds = null.IntFrom(v.ToInteger())
+	}
+
+	if v := obj.Get("preallocCapacity"); !common.IsNullish(v) {
+		options.PreallocCapacity = null.IntFrom(v.ToInteger())
+	}
+
+	if v := obj.Get("bufferSize"); !common.IsNullish(v) {
+		options.BufferSize = null.IntFrom(v.ToInteger())
+	}
+
+	if options.BufferSize.Valid && options.BufferSize.Int64 < 1 {
+		return options, errors.New("bufferSize must be greater than 0")
+	}
+
+	if v := obj.Get("metadataLines"); !common.IsNullish(v) {
+		options.MetadataLines = null.IntFrom(v.ToInteger())
+	}
+
+	if v := obj.Get("onMetadata"); !common.IsNullish(v) {
+		onMetadata, ok := sobek.AssertFunction(v)
+		if !ok {
+			return options, errors.New("onMetadata must be a function")
+		}
+
+		options.OnMetadata = onMetadata
+	}
+
+	if v := obj.Get("filter"); !common.IsNullish(v) {
+		filter, ok := sobek.AssertFunction(v)
+		if !ok {
+			return options, errors.New("filter must be a function")
+		}
+
+		options.Filter = filter
+	}
+
+	if v := obj.Get("transform"); !common.IsNullish(v) {
+		transform, ok := sobek.AssertFunction(v)
+		if !ok {
+			return options, errors.New("transform must be a function")
+		}
+
+		options.Transform = transform
+	}
+
+	if v := obj.Get("includeCursor"); !common.IsNullish(v) {
+		options.IncludeCursor = v.ToBoolean()
+	}
+
+	if v := obj.Get("includeRaw"); !common.IsNullish(v) {
+		options.IncludeRaw = v.ToBoolean()
+	}
+
+	if v := obj.Get("restore"); !common.IsNullish(v) {
+		restore, err := newCursorFrom(rt, v)
+		if err != nil {
+			return options, fmt.Errorf("invalid restore option; reason: %w", err)
+		}
+
+		options.Restore = &restore
+	}
+
+	if v := obj.Get("lazyQuotes"); !common.IsNullish(v) {
+		options.LazyQuotes = v.ToBoolean()
+	}
+
+	if v := obj.Get("fieldsPerRecord"); !common.IsNullish(v) {
+		options.FieldsPerRecord = null.IntFrom(v.ToInteger())
+	}
+
+	if options.FieldsPerRecord.Valid && options.FieldsPerRecord.Int64 < -1 {
+		return options, errors.New("fieldsPerRecord must be either -1, 0 or positive")
+	}
+
+	if v := obj.Get("trimLeadingSpace"); !common.IsNullish(v) {
+		options.TrimLeadingSpace = v.ToBoolean()
+	}
+
+	if v := obj.Get("cycle"); !common.IsNullish(v) {
+		options.Cycle = v.ToBoolean()
+	}
+
+	if v := obj.Get("bestEffort"); !common.IsNullish(v) {
+		options.BestEffort = v.ToBoolean()
+	}
+
+	if v := obj.Get("ringBuffer"); !common.IsNullish(v) {
+		size := v.ToObject(rt).Get("size")
+		if common.IsNullish(size) || size.ToInteger() < 1 {
+			return options, errors.New("ringBuffer requires a size greater than 0")
+		}
+
+		options.RingBuffer = &ringBufferOptions{Size: size.ToInteger()}
+	}
+
+	if v := obj.Get("patterns"); !common.IsNullish(v) {
+		patterns, err := newPatternsFrom(rt, v)
+		if err != nil {
+			return options, err
+		}
+
+		options.Patterns = patterns
+	}
+
+	if v := obj.Get("onPatternMismatch"); !common.IsNullish(v) {
+		switch mode := v.String(); mode {
+		case patternMismatchReject, patternMismatchWarn:
+			options.OnPatternMismatch = mode
+		default:
+			return options, fmt.Errorf("onPatternMismatch must be either %q or %q", patternMismatchReject, patternMismatchWarn)
+		}
+	}
+
+	if v := obj.Get("lazy"); !common.IsNullish(v) {
+		options.Lazy = v.ToBoolean()
+	}
+
+	if v := obj.Get("dedupeConsecutive"); !common.IsNullish(v) {
+		options.DedupeConsecutive = v.ToBoolean()
+	}
+
+	if v := obj.Get("dedupeKey"); !common.IsNullish(v) {
+		options.DedupeKey = null.StringFrom(v.String())
+	}
+
+	if options.DedupeKey.Valid && !options.DedupeConsecutive {
+		return options, errors.New("dedupeKey requires the dedupeConsecutive option to be set")
+	}
+
+	if v := obj.Get("fileColumns"); !common.IsNullish(v) {
+		fileColumns := v.ToObject(rt)
+
+		options.FileColumns = make(map[string]string, len(fileColumns.Keys()))
+		for _, column := range fileColumns.Keys() {
+			options.FileColumns[column] = fileColumns.Get(column).String()
+		}
+	}
+
+	if v := obj.Get("columns"); !common.IsNullish(v) {
+		columns, err := newColumnsFrom(rt, v)
+		if err != nil {
+			return options, err
+		}
+
+		options.Columns = columns
+	}
+
+	if v := obj.Get("limit"); !common.IsNullish(v) {
+		options.Limit = null.IntFrom(v.ToInteger())
+	}
+
+	if options.Limit.Valid && options.Limit.Int64 < 0 {
+		return options, errors.New("limit must be positive")
+	}
+
+	if v := obj.Get("skipErrors"); !common.IsNullish(v) {
+		options.SkipErrors = v.ToBoolean()
+	}
+
+	if v := obj.Get("onError"); !common.IsNullish(v) {
+		skip, err := parseOnError(v.String())
+		if err != nil {
+			return options, err
+		}
+
+		if !common.IsNullish(obj.Get("skipErrors")) && skip != options.SkipErrors {
+			return options, errors.New("onError conflicts with the skipErrors option")
+		}
+
+		options.SkipErrors = skip
+	}
+
+	if v := obj.Get("compression"); !common.IsNullish(v) {
+		compression, err := parseCompression(v.String())
+		if err != nil {
+			return options, err
+		}
+
+		options.Compression = compression
+	}
+
+	if v := obj.Get("encoding"); !common.IsNullish(v) {
+		encoding, err := parseEncoding(v.String())
+		if err != nil {
+			return options, err
+		}
+
+		options.Encoding = encoding
+	}
+
+	if v := obj.Get("stripBOM"); !common.IsNullish(v) {
+		options.StripBOM = v.ToBoolean()
+	}
+
+	if v := obj.Get("shard"); !common.IsNullish(v) {
+		shard := v.ToObject(rt)
+
+		var index, total int64
+		if v := shard.Get("index"); !common.IsNullish(v) {
+			index = v.ToInteger()
+		}
+		if v := shard.Get("total"); !common.IsNullish(v) {
+			total = v.ToInteger()
+		}
+
+		shardOptions, err := newShardOptions(index, total)
+		if err != nil {
+			return options, err
+		}
+
+		options.Shard = shardOptions
+	}
+
+	if v := obj.Get("partitionByVU"); !common.IsNullish(v) {
+		options.PartitionByVU = v.ToBoolean()
+	}
+
+	if v := obj.Get("partitionBy"); !common.IsNullish(v) {
+		if v.String() != "vu" {
+			return options, fmt.Errorf("partitionBy must be \"vu\", got %q instead", v.String())
+		}
+
+		options.PartitionBy = v.String()
+		options.PartitionByVU = true
+	}
+
+	if options.Shard != nil && options.PartitionByVU {
+		return options, errors.New("the shard and partitionByVU options conflict, and cannot both be set")
+	}
+
+	if v := obj.Get("schema"); !common.IsNullish(v) {
+		schema, err := newSchemaFrom(rt, v)
+		if err != nil {
+			return options, err
+		}
+
+		options.Schema = schema
+	}
+
+	if v := obj.Get("columnTypes"); !common.IsNullish(v) {
+		if len(options.Schema) > 0 {
+			return options, errors.New("the schema and columnTypes options conflict, and cannot both be set")
+		}
+
+		columnTypes, err := newSchemaFrom(rt, v)
+		if err != nil {
+			return options, err
+		}
+
+		options.ColumnTypes = columnTypes
+		options.Schema = columnTypes
+	}
+
+	if v := obj.Get("typeMode"); !common.IsNullish(v) {
+		switch mode := v.String(); mode {
+		case typeModeStrict, typeModeLenient:
+			options.TypeMode = mode
+		default:
+			return options, fmt.Errorf("typeMode must be either %q or %q, got %q instead", typeModeStrict, typeModeLenient, mode)
+		}
+	}
+
+	if v := obj.Get("maxFieldLength"); !common.IsNullish(v) {
+		options.MaxFieldLength = null.IntFrom(v.ToInteger())
+	}
+
+	if v := obj.Get("truncateFields"); !common.IsNullish(v) {
+		options.TruncateFields = v.ToBoolean()
+	}
+
+	if v := obj.Get("ellipsis"); !common.IsNullish(v) {
+		options.Ellipsis = v.ToBoolean()
+	}
+
+	if options.MaxFieldLength.Valid && options.MaxFieldLength.Int64 < 1 {
+		return options, errors.New("maxFieldLength must be greater than 0")
+	}
+
+	if (options.TruncateFields || options.Ellipsis) && !options.MaxFieldLength.Valid {
+		return options, errors.New("truncateFields and ellipsis require the maxFieldLength option to be set")
+	}
+
+	if options.AsObjects && options.SkipFirstLine {
+		return options, errors.New("the asObjects and skipFirstLine options conflict, and cannot both be set")
+	}
+
+	if options.AsObjects {
+		options.Header = true
+	}
+
+	if options.Header && options.SkipFirstLine {
+		return options, errors.New("the header and skipFirstLine options conflict, and cannot both be set")
+	}
+
+	if options.BestEffort && options.Quote != defaultQuote {
+		return options, errors.New("bestEffort only supports the default quote")
+	}
+
+	if options.Lazy && !options.Header && !options.SkipFirstLine {
+		return options, errors.New("lazy requires the header or skipFirstLine option to be set")
+	}
+
+	if options.MaxAgeSeconds.Valid {
+		if !options.TimestampColumn.Valid {
+			return options, errors.New("maxAgeSeconds requires the timestampColumn option to be set")
+		}
+
+		if options.MaxAgeSeconds.Int64 < 0 || options.TimestampColumn.Int64 < 0 {
+			return options, errors.New("maxAgeSeconds and timestampColumn must be positive")
+		}
+	}
+
+	if options.MetadataLines.Valid && options.MetadataLines.Int64 < 0 {
+		return options, errors.New("metadataLines must be positive")
+	}
+
+	// The fromLine option designates a data row, whereas the toLine option
+	// designates a physical line, so the data row is compared by the line it
+	// is on, at the earliest.
+	if options.FromLine.Valid && options.ToLine.Valid &&
+		options.leadingLines()+options.FromLine.Int64 > options.ToLine.Int64 {
+		return options, errors.New("fromLine must designate a data row on or before toLine")
+	}
+
+	if options.PreallocCapacity.Valid && options.PreallocCapacity.Int64 < 0 {
+		return options, errors.New("preallocCapacity must be positive")
+	}
+
+	return options, nil
+}
+
+// leadingLines returns the number of physical lines preceding the data rows:
+// the metadata lines, and the header or the skipped first line.
+func (o parserOptions) leadingLines() int64 {
+	var lines int64
+	if o.MetadataLines.Valid {
+		lines += o.MetadataLines.Int64
+	}
+	if o.Header || o.SkipFirstLine {
+		lines++
+	}
+	return lines
+}
+
+// must is a small helper that will panic if err is not nil.
+func must(rt *sobek.Runtime, err error) {
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
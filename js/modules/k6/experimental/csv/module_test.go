@@ -0,0 +1,5191 @@
+package csv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/js/compiler"
+	"go.k6.io/k6/js/modules/k6/experimental/fs"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/executor"
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/metrics"
+)
+
+// testFilePath holds the path to the CSV file used in the tests.
+const testFilePath = fsext.FilePathSeparator + "data.csv"
+
+const testCSV = "firstname,lastname,age\nfoo,bar,42\nbaz,qux,43\nquux,corge,44\n"
+
+func TestParserConstructor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("constructing a parser without options should succeed", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file);
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("undefined or null options should be ignored", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, {
+				delimiter: undefined,
+				header: undefined,
+				fromLine: null,
+				toLine: undefined,
+				shard: undefined,
+			});
+
+			const { done, value } = await parser.next();
+			if (done || value[0] !== "firstname") {
+				throw new Error("Unexpected first record " + JSON.stringify(value));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("constructing a parser without a file should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`new csv.Parser()`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "csv Parser constructor takes at least one non-nil source argument")
+
+		_, err = r.RunOnEventLoop(`new csv.Parser(null)`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "csv Parser constructor takes at least one non-nil source argument")
+	})
+
+	t.Run("constructing a parser from something other than a file should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`new csv.Parser(42)`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "first argument expected to be a fs.File instance")
+	})
+
+	t.Run("constructing a parser with invalid options should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { delimiter: ";;" });
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "delimiter must be a single character")
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { fromLine: 3, toLine: 1 });
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fromLine must designate a data row on or before toLine")
+
+		// With a header, data row 1 is on line 2
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { header: true, fromLine: 2, toLine: 2 });
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fromLine must designate a data row on or before toLine")
+	})
+}
+
+func TestParserInMemorySource(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "string", source: `"name;age\nfoo;42\nbar;43\nbaz;44\n"`},
+		{name: "ArrayBuffer", source: `encode("name;age\nfoo;42\nbar;43\nbaz;44\n").buffer`},
+		{name: "Uint8Array", source: `encode("name;age\nfoo;42\nbar;43\nbaz;44\n")`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run("parsing a "+tt.name+" should honor the options", func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const encode = (s) => new Uint8Array([...s].map((c) => c.charCodeAt(0)));
+
+				const source = %s;
+				const parser = new csv.Parser(source, { delimiter: ";", header: true, fromLine: 1, cycle: true });
+
+				let got = [];
+				for (let i = 0; i < 3; i++) {
+					const { done, value } = await parser.next();
+					if (done) {
+						break;
+					}
+
+					got.push(value.name + "=" + value.age);
+				}
+
+				if (got.join("|") !== "bar=43|baz=44|bar=43") {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, tt.source)))
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("modifying the source once the parser is instantiated should have no effect", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(`
+			const source = new Uint8Array([...("a,b\n")].map((c) => c.charCodeAt(0)));
+			const parser = new csv.Parser(source);
+			source[0] = "z".charCodeAt(0);
+
+			const { value } = await parser.next();
+			if (value[0] !== "a") {
+				throw new Error("Unexpected record " + JSON.stringify(value));
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("the ringBuffer option should require a file", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`new csv.Parser("a,b\n", { ringBuffer: { size: 1 } })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the ringBuffer option requires the parser to read from a fs.File")
+	})
+}
+
+func TestParserNext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("next should return the file's records in order", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file);
+
+			let gotParsedCount = 0;
+			let { done, value } = await parser.next();
+			while (!done) {
+				if (!Array.isArray(value) || value.length !== 3) {
+					throw new Error("Expected record to be an array of 3 fields, got " + JSON.stringify(value));
+				}
+
+				gotParsedCount++;
+				({ done, value } = await parser.next());
+			}
+
+			if (gotParsedCount !== 4) {
+				throw new Error("Expected to parse 4 records, got " + gotParsedCount);
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("next should honor the delimiter option", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, "foo;bar;42\n"))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { delimiter: ";" });
+
+			const { done, value } = await parser.next();
+			if (done || value.join("|") !== "foo|bar|42") {
+				throw new Error("Unexpected record " + JSON.stringify(value));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("next should honor the skipFirstLine option", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true });
+
+			const { done, value } = await parser.next();
+			if (done || value[0] !== "foo") {
+				throw new Error("Expected first record to start with 'foo', got " + JSON.stringify(value));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("next should honor the fromLine and toLine options", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { fromLine: 1, toLine: 2 });
+
+			let records = [];
+			let { done, value } = await parser.next();
+			while (!done) {
+				records.push(value[0]);
+				({ done, value } = await parser.next());
+			}
+
+			if (records.join("|") !== "foo|baz") {
+				throw new Error("Unexpected records " + JSON.stringify(records));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("fromLine and toLine should be compared on the same basis", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		// Data row 1 is on line 2, after the header, so it's the only row up
+		// to line 2; without a header, data row 2 is on line 2.
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const read = async (options) => {
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, options);
+				let records = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					records.push(value);
+					({ done, value } = await parser.next());
+				}
+				return records;
+			};
+
+			let records = await read({ header: true, fromLine: 1, toLine: 2 });
+			if (records.length !== 1 || records[0].firstname !== "baz") {
+				throw new Error("Unexpected records " + JSON.stringify(records));
+			}
+
+			records = await read({ fromLine: 2, toLine: 2 });
+			if (records.length !== 1 || records[0][0] !== "baz") {
+				throw new Error("Unexpected records " + JSON.stringify(records));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParserHeader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records should be returned as objects keyed by the header", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { header: true });
+
+			const { done, value } = await parser.next();
+			if (done || Object.keys(value).sort().join(",") !== "age,firstname,lastname") {
+				throw new Error("Unexpected record " + JSON.stringify(value));
+			}
+
+			if (value.firstname !== "foo" || value.age !== "42") {
+				throw new Error("Unexpected fields " + value.firstname + ", " + value.age);
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("fromLine should skip data lines after the header", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { header: true, fromLine: 1 });
+
+			const { done, value } = await parser.next();
+			if (done || value.firstname !== "baz") {
+				throw new Error("Unexpected record " + JSON.stringify(value));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("header and skipFirstLine should conflict", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { header: true, skipFirstLine: true });
+		`, testFilePath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the header and skipFirstLine options conflict")
+	})
+}
+
+func TestParserCycle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		options string
+		want    string
+	}{
+		{
+			name:    "the parser should restart from the first data line",
+			content: testCSV,
+			options: `{ skipFirstLine: true, cycle: true }`,
+			want:    "foo|baz|quux|foo|baz|quux|foo",
+		},
+		{
+			name:    "the parser should restart after the header",
+			content: testCSV,
+			options: `{ header: true, cycle: true }`,
+			want:    "foo|baz|quux|foo|baz|quux|foo",
+		},
+		{
+			name:    "the parser should honor the fromLine and toLine options on each cycle",
+			content: testCSV,
+			options: `{ skipFirstLine: true, fromLine: 1, toLine: 3, cycle: true }`,
+			want:    "baz|quux|baz|quux|baz|quux|baz",
+		},
+		{
+			name:    "the parser should be done when there is no data line",
+			content: "firstname,lastname,age\n",
+			options: `{ skipFirstLine: true, cycle: true }`,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, tt.content))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				for (let i = 0; i < 7; i++) {
+					const { done, value } = await parser.next();
+					if (done) {
+						break;
+					}
+
+					got.push(value.firstname || value[0]);
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserPreallocCapacity(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records should not be altered by subsequent reads", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { preallocCapacity: 0 });
+
+			let records = [];
+			let { done, value } = await parser.next();
+			while (!done) {
+				records.push(value);
+				({ done, value } = await parser.next());
+			}
+
+			const got = records.map((r) => r.join("|")).join(",");
+			if (got !== "firstname|lastname|age,foo|bar|42,baz|qux|43,quux|corge|44") {
+				throw new Error("Unexpected records " + got);
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("negative preallocCapacity should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { preallocCapacity: -1 });
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "preallocCapacity must be positive")
+	})
+}
+
+func BenchmarkParserRead(b *testing.B) {
+	const columns = 200
+
+	fields := make([]string, columns)
+	for i := range fields {
+		fields[i] = "field" + strconv.Itoa(i)
+	}
+	row := strings.Join(fields, ",") + "\n"
+
+	benchmarks := []struct {
+		name             string
+		preallocCapacity null.Int
+	}{
+		{name: "default"},
+		{name: "preallocated", preallocCapacity: null.IntFrom(columns)},
+	}
+
+	for _, bm := range benchmarks {
+		bm := bm
+
+		b.Run(bm.name, func(b *testing.B) {
+			options := newDefaultParserOptions()
+			options.PreallocCapacity = bm.preallocCapacity
+
+			parser := newParser(strings.NewReader(strings.Repeat(row, b.N)), options, nil, logrus.New())
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.read(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParserNext(b *testing.B) {
+	fields := make([]string, 20)
+	for i := range fields {
+		fields[i] = "field" + strconv.Itoa(i)
+	}
+	row := strings.Join(fields, ",") + "\n"
+
+	benchmarks := []struct {
+		name       string
+		bufferSize null.Int
+	}{
+		{name: "default"},
+		{name: "64KiB", bufferSize: null.IntFrom(64 << 10)},
+		{name: "1MiB", bufferSize: null.IntFrom(1 << 20)},
+	}
+
+	for _, bm := range benchmarks {
+		bm := bm
+
+		b.Run(bm.name, func(b *testing.B) {
+			// The rows are read from an actual file, so that each read of the
+			// source incurs a system call, as it would for a file on disk.
+			path := filepath.Join(b.TempDir(), "data.csv")
+			require.NoError(b, os.WriteFile(path, []byte(strings.Repeat(row, b.N)), 0o600))
+
+			f, err := os.Open(path) //nolint:gosec
+			require.NoError(b, err)
+			b.Cleanup(func() { _ = f.Close() })
+
+			options := newDefaultParserOptions()
+			options.BufferSize = bm.bufferSize
+
+			parser := newParser(f, options, nil, logrus.New())
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.next(); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
+
+func TestParserAsyncIterator(t *testing.T) {
+	t.Parallel()
+
+	// The runtime does not support the `Symbol.asyncIterator` well-known symbol,
+	// nor `for await...of` loops, so we define the former and emulate the latter.
+	const polyfill = `Symbol.asyncIterator = Symbol("Symbol.asyncIterator");`
+
+	t.Run("iterating should yield every record until done", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(polyfill + wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true });
+
+			const iterator = parser[Symbol.asyncIterator]();
+
+			let got = [];
+			for (let result = await iterator.next(); !result.done; result = await iterator.next()) {
+				got.push(result.value[0]);
+			}
+
+			if (got.join("|") !== "foo|baz|quux") {
+				throw new Error("Unexpected records " + JSON.stringify(got));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("returning early should terminate the iteration", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(polyfill + wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true });
+
+			const iterator = parser[Symbol.asyncIterator]();
+			const { value } = await iterator.next();
+			if (value[0] !== "foo") {
+				throw new Error("Unexpected record " + JSON.stringify(value));
+			}
+
+			const { done } = await iterator.return();
+			if (!done) {
+				throw new Error("Expected return to terminate the iteration");
+			}
+
+			// The parser should remain usable.
+			const next = await parser.next();
+			if (next.done || next.value[0] !== "baz") {
+				throw new Error("Unexpected record " + JSON.stringify(next));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParserWindows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		size int
+		step int
+		want string
+	}{
+		{name: "overlapping windows", size: 3, step: 2, want: "1,2,3|3,4,5"},
+		{name: "sliding windows", size: 4, step: 1, want: "1,2,3,4|2,3,4,5|3,4,5,6"},
+		{name: "contiguous windows", size: 2, step: 2, want: "1,2|3,4|5,6"},
+		{name: "windows with gaps", size: 2, step: 3, want: "1,2|4,5"},
+		{name: "window larger than the file", size: 7, step: 1, want: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, "1\n2\n3\n4\n5\n6\n"))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file);
+				const windows = parser.windows(%d, %d);
+
+				let got = [];
+				let { done, value } = await windows.next();
+				while (!done) {
+					if (value.length !== %d) {
+						throw new Error("Unexpected window size " + value.length);
+					}
+
+					got.push(value.map((record) => record[0]).join(","));
+					({ done, value } = await windows.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected windows " + got.join("|"));
+				}
+			`, testFilePath, tt.size, tt.step, tt.size, tt.want)))
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("invalid windows arguments should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file);
+			parser.windows(0);
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "windows() takes a size argument greater than 0")
+	})
+}
+
+func TestParserDistinctCount(t *testing.T) {
+	t.Parallel()
+
+	const distinctCSV = "name,country\nfoo,FR\nbar,US\nbaz,FR\nqux,DE\nquux,US\n"
+
+	t.Run("distinctCount should count the distinct values of a column by index", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, distinctCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true });
+
+			const count = await parser.distinctCount(1);
+			if (count !== 3) {
+				throw new Error("Expected 3 distinct values, got " + count);
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("distinctCount should count the distinct values of a column by name", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, distinctCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true });
+
+			const { count, values } = await parser.distinctCount("country", { includeValues: true });
+			if (count !== 3 || values.join(",") !== "FR,US,DE") {
+				throw new Error("Unexpected distinct values " + JSON.stringify(values));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("distinctCount by name without a header should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, distinctCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file);
+
+			try {
+				await parser.distinctCount("country");
+				throw new Error("Expected distinctCount to fail");
+			} catch (err) {
+				if (!err.toString().includes("skipFirstLine")) {
+					throw err;
+				}
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParserMetadataLines(t *testing.T) {
+	t.Parallel()
+
+	t.Run("metadata lines should be surfaced and not parsed", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, "ROWS=2\r\nname,age\nfoo,42\nbar,43\n"))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+
+			let metadata;
+			const parser = new csv.Parser(file, {
+				metadataLines: 1,
+				onMetadata: (lines) => { metadata = lines; },
+				skipFirstLine: true,
+			});
+
+			if (metadata.length !== 1 || metadata[0] !== "ROWS=2") {
+				throw new Error("Unexpected metadata " + JSON.stringify(metadata));
+			}
+
+			const { done, value } = await parser.next();
+			if (done || value.join("|") !== "foo|42") {
+				throw new Error("Unexpected record " + JSON.stringify(value));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("more metadata lines than the file holds should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, "ROWS=2\n"))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { metadataLines: 2 });
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read metadata line 2")
+	})
+}
+
+func TestParserFilter(t *testing.T) {
+	t.Parallel()
+
+	const countriesCSV = "name,country\nfoo,US\nbar,FR\nbaz,US\nqux,DE\n"
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "only the records the filter returns true for should be returned",
+			options: `{ skipFirstLine: true, filter: (fields) => fields[1] === "US" }`,
+			want:    "foo|baz",
+		},
+		{
+			name:    "the filter should be called with header mode objects",
+			options: `{ header: true, filter: (fields) => fields.country !== "US" }`,
+			want:    "bar|qux",
+		},
+		{
+			name:    "the filter should be called with the line number",
+			options: `{ skipFirstLine: true, filter: (fields, line) => line % 2 === 1 }`,
+			want:    "bar|qux",
+		},
+		{
+			name:    "the filter should be honored along with the cursor",
+			options: `{ skipFirstLine: true, includeCursor: true, filter: (fields) => fields[0] === "qux" }`,
+			want:    "qux@5",
+		},
+		{
+			name:    "an exception thrown by the filter should reject the promise",
+			options: `{ skipFirstLine: true, filter: (fields) => { throw new Error("filter failed"); } }`,
+			wantErr: "filter failed",
+		},
+		{
+			name:    "a filter that is not a function should fail",
+			options: `{ filter: "US" }`,
+			wantErr: "filter must be a function",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, countriesCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value, cursor } = await parser.next();
+				while (!done) {
+					const name = value.name || value[0];
+					got.push(cursor ? name + "@" + cursor.line : name);
+					({ done, value, cursor } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserReadAll(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "every remaining record should be returned",
+			content: testCSV,
+			options: `{ skipFirstLine: true }`,
+			want:    `[["foo","bar","42"],["baz","qux","43"],["quux","corge","44"]]`,
+		},
+		{
+			name:    "records should be returned as objects in header mode",
+			content: testCSV,
+			options: `{ header: true, toLine: 2 }`,
+			want:    `[{"age":"42","firstname":"foo","lastname":"bar"},{"age":"43","firstname":"baz","lastname":"qux"}]`,
+		},
+		{
+			name:    "records should be copied out of the record buffer",
+			content: testCSV,
+			options: `{ skipFirstLine: true, preallocCapacity: 3, toLine: 2 }`,
+			want:    `[["foo","bar","42"],["baz","qux","43"]]`,
+		},
+		{
+			name:    "the filter should be honored",
+			content: testCSV,
+			options: `{ skipFirstLine: true, filter: (fields) => fields[0] !== "baz" }`,
+			want:    `[["foo","bar","42"],["quux","corge","44"]]`,
+		},
+		{
+			name:    "the rows read so far should be held by the error",
+			content: "foo,42\nbar,43\nbaz\n",
+			options: `{}`,
+			wantErr: `readAll() failed after reading 2 rows; reason: line 3: wrong number of fields [["foo","42"],["bar","43"]]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, tt.content))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let rows;
+				try {
+					rows = await parser.readAll();
+				} catch (e) {
+					throw new Error(e.message + " " + JSON.stringify(e.rows));
+				}
+
+				// Sort object keys, so that the comparison does not depend on their order.
+				const got = JSON.stringify(rows, (key, value) =>
+					value && typeof value === "object" && !Array.isArray(value)
+						? Object.fromEntries(Object.entries(value).sort())
+						: value
+				);
+
+				if (got !== %q) {
+					throw new Error("Unexpected rows " + got);
+				}
+
+				const { done } = await parser.next();
+				if (!done) {
+					throw new Error("Expected the parser to be done");
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserLazyQuotes(t *testing.T) {
+	t.Parallel()
+
+	const malformedCSV = "id,note\n1,a 6\" screen\n2,\"quoted \"inner\" quotes\"\n"
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "bare quotes should fail by default",
+			options: `{ skipFirstLine: true }`,
+			wantErr: `bare " in non-quoted-field`,
+		},
+		{
+			name:    "bare quotes should be tolerated with lazy quotes",
+			options: `{ skipFirstLine: true, lazyQuotes: true }`,
+			want:    `[["1","a 6\" screen"],["2","quoted \"inner\" quotes"]]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, malformedCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				const got = JSON.stringify(await parser.readAll());
+				if (got !== %q) {
+					throw new Error("Unexpected records " + got);
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserFieldsPerRecord(t *testing.T) {
+	t.Parallel()
+
+	const raggedCSV = "a,b,c\n1,2,3\n4,5\n6,7,8,9\n"
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "records should hold as many fields as the header by default",
+			options: `{ header: true }`,
+			wantErr: "line 3: wrong number of fields",
+		},
+		{
+			name:    "records should hold as many fields as the first one when set to 0",
+			options: `{ fieldsPerRecord: 0, skipFirstLine: true, metadataLines: 1 }`,
+			wantErr: "line 3: wrong number of fields",
+		},
+		{
+			name:    "records should hold exactly the expected number of fields when positive",
+			options: `{ fieldsPerRecord: 3, skipFirstLine: true }`,
+			wantErr: "line 3: wrong number of fields",
+		},
+		{
+			name:    "the number of fields should not be checked when set to -1",
+			options: `{ fieldsPerRecord: -1, skipFirstLine: true }`,
+			want:    "1,2,3|4,5|6,7,8,9",
+		},
+		{
+			name:    "a header not holding the expected number of fields should fail",
+			options: `{ fieldsPerRecord: 2, header: true }`,
+			wantErr: "wrong number of fields",
+		},
+		{
+			name:    "invalid values should fail",
+			options: `{ fieldsPerRecord: -2 }`,
+			wantErr: "fieldsPerRecord must be either -1, 0 or positive",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, raggedCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(Array.isArray(value) ? value.join(",") : JSON.stringify(value));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserTrimLeadingSpace(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+	}{
+		{
+			name:    "leading spaces should be preserved by default",
+			options: `{}`,
+			want:    `["1"," 2 "," 3"]`,
+		},
+		{
+			name:    "leading spaces should be trimmed when requested",
+			options: `{ trimLeadingSpace: true }`,
+			want:    `["1","2 ","3"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, "1, 2 , 3\n"))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				const { value } = await parser.next();
+				if (JSON.stringify(value) !== %q) {
+					throw new Error("Unexpected record " + JSON.stringify(value));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserComment(t *testing.T) {
+	t.Parallel()
+
+	const commentedCSV = "# generated by a script\nfirstname,lastname,age\nfoo,bar,42\n# second section\n#\nbaz,qux,43\n"
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "comment lines should be skipped, and accounted for by the current line",
+			options: `{ comment: "#", skipFirstLine: true, includeCursor: true }`,
+			want:    "foo@3|baz@6",
+		},
+		{
+			name:    "comment lines should be skipped in best effort mode",
+			options: `{ comment: "#", skipFirstLine: true, bestEffort: true }`,
+			want:    "foo|baz",
+		},
+		{
+			name:    "a comment of more than one character should fail",
+			options: `{ comment: "//" }`,
+			wantErr: "comment must be a single character",
+		},
+		{
+			name:    "a comment equal to the delimiter should fail",
+			options: `{ comment: ";", delimiter: ";" }`,
+			wantErr: "comment must differ from the delimiter",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, commentedCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value, cursor } = await parser.next();
+				while (!done) {
+					got.push(cursor ? value[0] + "@" + cursor.line : value[0]);
+					({ done, value, cursor } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserSkipEmptyLines(t *testing.T) {
+	t.Parallel()
+
+	const sectionsCSV = "name,age\nfoo,42\n\"\"\nbar,43\n\"\"\n\"\"\nbaz,44\n"
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "empty lines should fail by default",
+			options: `{ skipFirstLine: true }`,
+			wantErr: "wrong number of fields",
+		},
+		{
+			name:    "empty lines should be skipped when requested",
+			options: `{ skipFirstLine: true, skipEmptyLines: true }`,
+			want:    "foo,42|bar,43|baz,44",
+		},
+		{
+			name:    "skipped empty lines should be accounted for by toLine",
+			options: `{ skipFirstLine: true, skipEmptyLines: true, toLine: 3 }`,
+			want:    "foo,42|bar,43",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, sectionsCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value.join(","));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserSkipBlankRows(t *testing.T) {
+	t.Parallel()
+
+	const blankCSV = "foo,42\n  ,\t\n , \nbar,43\n"
+
+	tests := []struct {
+		name          string
+		skipBlankRows bool
+		want          string
+	}{
+		{name: "blank rows should be returned by default", skipBlankRows: false, want: "foo,42|  ,\t| , |bar,43"},
+		{name: "blank rows should be skipped when requested", skipBlankRows: true, want: "foo,42|bar,43"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, blankCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, { skipBlankRows: %t });
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value.join(","));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.skipBlankRows, tt.want)))
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserBestEffort(t *testing.T) {
+	t.Parallel()
+
+	const malformedCSV = "foo,\"a, quoted comment\",42\n" +
+		"bar,un\"quoted,43\n" +
+		"\"baz,unterminated,44\n" +
+		"qux,\"multi\nline\",45\n" +
+		"quux,trailing\",46\n"
+
+	t.Run("malformed rows should fail by default", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, malformedCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file);
+
+			await parser.next();
+			await parser.next();
+		`, testFilePath)))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("malformed rows should be recovered from in best effort mode", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, malformedCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { bestEffort: true });
+
+			let got = [];
+			let { done, value } = await parser.next();
+			while (!done) {
+				got.push(value);
+				({ done, value } = await parser.next());
+			}
+
+			const want = [
+				["foo", "a, quoted comment", "42"],
+				["bar", 'un"quoted', "43"],
+				["baz", "unterminated", "44"],
+				["qux", "multi\nline", "45"],
+				["quux", "trailing", "46"],
+			];
+
+			if (JSON.stringify(got) !== JSON.stringify(want)) {
+				throw new Error("Unexpected records " + JSON.stringify(got));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParserTakeShared(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records read by the producer should be taken by the consumers", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const producer = new csv.Parser(file, { skipFirstLine: true, ringBuffer: { size: 4 } });
+			const consumer = new csv.Parser(file, { ringBuffer: { size: 4 } });
+
+			let { done } = await producer.next();
+			while (!done) {
+				({ done } = await producer.next());
+			}
+
+			let got = [];
+			let { done: taken, value } = await consumer.takeShared();
+			while (!taken) {
+				got.push(value.join(","));
+				({ done: taken, value } = await consumer.takeShared());
+			}
+
+			if (got.join("|") !== "foo,bar,42|baz,qux,43|quux,corge,44") {
+				throw new Error("Unexpected records " + JSON.stringify(got));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("takeShared without the ringBuffer option should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file);
+
+			await parser.takeShared();
+		`, testFilePath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "takeShared() requires the ringBuffer option to be set")
+	})
+
+	t.Run("ringBuffer without a size should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { ringBuffer: {} });
+		`, testFilePath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ringBuffer requires a size greater than 0")
+	})
+}
+
+func TestParserPatterns(t *testing.T) {
+	t.Parallel()
+
+	const contactsCSV = "name,email\nfoo,foo@example.com\nbar,not-an-email\nbaz,baz@example.com\n"
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "matching values should be returned",
+			options: `{ skipFirstLine: true, toLine: 1, patterns: { email: "^[^@]+@[^@]+$" } }`,
+			want:    "foo,foo@example.com",
+		},
+		{
+			name:    "columns should be designated by index without a header",
+			options: `{ fromLine: 1, patterns: { 0: "^[a-z]+$" } }`,
+			want:    "foo,foo@example.com|bar,not-an-email|baz,baz@example.com",
+		},
+		{
+			name:    "mismatching values should be rejected by default",
+			options: `{ skipFirstLine: true, patterns: { email: "^[^@]+@[^@]+$" } }`,
+			wantErr: `line 3, column "email": value "not-an-email" does not match the pattern "^[^@]+@[^@]+$"`,
+		},
+		{
+			name:    "mismatching values should be returned when warning",
+			options: `{ skipFirstLine: true, onPatternMismatch: "warn", patterns: { email: "^[^@]+@[^@]+$" } }`,
+			want:    "foo,foo@example.com|bar,not-an-email|baz,baz@example.com",
+		},
+		{
+			name:    "unknown columns should fail",
+			options: `{ skipFirstLine: true, patterns: { phone: "^[0-9]+$" } }`,
+			wantErr: `no column named "phone"`,
+		},
+		{
+			name:    "invalid patterns should fail",
+			options: `{ skipFirstLine: true, patterns: { email: "(" } }`,
+			wantErr: `invalid pattern for column "email"`,
+		},
+		{
+			name:    "invalid mismatch modes should fail",
+			options: `{ onPatternMismatch: "ignore" }`,
+			wantErr: `onPatternMismatch must be either "reject" or "warn"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, contactsCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value.join(","));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserSkipUntil(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		predicate   string
+		wantSkipped int
+		wantNext    string
+	}{
+		{
+			name:        "the matching record should be returned by the next call to next",
+			predicate:   `(fields) => fields[0] === "baz"`,
+			wantSkipped: 1,
+			wantNext:    "baz,qux,43",
+		},
+		{
+			name:        "a first record matching should not be skipped",
+			predicate:   `(fields) => fields[0] === "foo"`,
+			wantSkipped: 0,
+			wantNext:    "foo,bar,42",
+		},
+		{
+			name:        "the parser should reach the end of the file when no record matches",
+			predicate:   `(fields) => false`,
+			wantSkipped: 3,
+			wantNext:    "done",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, { skipFirstLine: true });
+
+				const skipped = await parser.skipUntil(%s);
+				if (skipped !== %d) {
+					throw new Error("Expected " + %d + " skipped records, got " + skipped);
+				}
+
+				const { done, value } = await parser.next();
+				const got = done ? "done" : value.join(",");
+				if (got !== %q) {
+					throw new Error("Unexpected next record " + got);
+				}
+			`, testFilePath, tt.predicate, tt.wantSkipped, tt.wantSkipped, tt.wantNext)))
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("a predicate that is not a function should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file);
+
+			await parser.skipUntil("foo");
+		`, testFilePath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "skipUntil() takes a predicate function argument")
+	})
+}
+
+func TestParserLazy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records should be returned as objects keyed by the header", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true, lazy: true, preallocCapacity: 3 });
+
+			const first = (await parser.next()).value;
+			const second = (await parser.next()).value;
+
+			if (first.firstname !== "foo" || first.age !== "42") {
+				throw new Error("Unexpected first record " + JSON.stringify(first));
+			}
+
+			if (JSON.stringify(second) !== '{"firstname":"baz","lastname":"qux","age":"43"}') {
+				throw new Error("Unexpected second record " + JSON.stringify(second));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("lazy without skipFirstLine should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { lazy: true });
+		`, testFilePath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "lazy requires the header or skipFirstLine option to be set")
+	})
+}
+
+func TestParserDedupeConsecutive(t *testing.T) {
+	t.Parallel()
+
+	const runsCSV = "key,value\na,1\na,1\na,1\nb,2\nb,3\na,1\nc,4\nc,4\n"
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "every record should be returned by default",
+			options: `{ skipFirstLine: true }`,
+			want:    "a,1|a,1|a,1|b,2|b,3|a,1|c,4|c,4",
+		},
+		{
+			name:    "runs of identical records should be collapsed",
+			options: `{ skipFirstLine: true, dedupeConsecutive: true }`,
+			want:    "a,1|b,2|b,3|a,1|c,4",
+		},
+		{
+			name:    "runs of records sharing a key should be collapsed",
+			options: `{ skipFirstLine: true, dedupeConsecutive: true, dedupeKey: "key" }`,
+			want:    "a,1|b,2|a,1|c,4",
+		},
+		{
+			name:    "runs of records sharing a key designated by index should be collapsed",
+			options: `{ skipFirstLine: true, dedupeConsecutive: true, dedupeKey: 0, preallocCapacity: 2 }`,
+			want:    "a,1|b,2|a,1|c,4",
+		},
+		{
+			name:    "dedupeKey without dedupeConsecutive should fail",
+			options: `{ skipFirstLine: true, dedupeKey: "key" }`,
+			wantErr: "dedupeKey requires the dedupeConsecutive option to be set",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, runsCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value.join(","));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserMaxFieldLength(t *testing.T) {
+	t.Parallel()
+
+	const longCSV = "short,abcdefghij\nunicode,ééééééé\n"
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "overlong fields should be rejected by default",
+			options: `{ maxFieldLength: 5 }`,
+			wantErr: "line 1: field 1 exceeds the maximum field length of 5 characters",
+		},
+		{
+			name:    "overlong fields should be truncated when requested",
+			options: `{ maxFieldLength: 5, truncateFields: true }`,
+			want:    "short,abcde|unico,ééééé",
+		},
+		{
+			name:    "truncated fields should end with an ellipsis when requested",
+			options: `{ maxFieldLength: 5, truncateFields: true, ellipsis: true }`,
+			want:    "short,abcd…|unic…,éééé…",
+		},
+		{
+			name:    "fields within the maximum length should be left untouched",
+			options: `{ maxFieldLength: 10, truncateFields: true, ellipsis: true }`,
+			want:    "short,abcdefghij|unicode,ééééééé",
+		},
+		{
+			name:    "truncateFields without maxFieldLength should fail",
+			options: `{ truncateFields: true }`,
+			wantErr: "truncateFields and ellipsis require the maxFieldLength option to be set",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, longCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value.join(","));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserFileColumns(t *testing.T) {
+	t.Parallel()
+
+	const requestsCSV = "name,body\nfirst,first.json\nsecond,nested/second.json\nagain,first.json\n"
+
+	t.Run("referenced files content should be returned in place of their name", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, requestsCSV))
+		require.NoError(t, writeTestFile(r, "/bodies/first.json", `{"id":1}`))
+		require.NoError(t, writeTestFile(r, "/bodies/nested/second.json", `{"id":2}`))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true, fileColumns: { body: "bodies" } });
+
+			let got = [];
+			let { done, value } = await parser.next();
+			while (!done) {
+				got.push(value);
+				({ done, value } = await parser.next());
+			}
+
+			const want = [["first", '{"id":1}'], ["second", '{"id":2}'], ["again", '{"id":1}']];
+			if (JSON.stringify(got) !== JSON.stringify(want)) {
+				throw new Error("Unexpected records " + JSON.stringify(got));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing referenced files should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, requestsCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true, fileColumns: { 1: "/bodies" } });
+
+			await parser.next();
+		`, testFilePath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unable to read the file "/bodies/first.json" referenced by column "1"`)
+	})
+}
+
+func TestParserCursor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cursors should be monotonic", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true, includeCursor: true });
+
+			let previous = { line: 1, byteOffset: 23 };
+			let { done, value, cursor } = await parser.next();
+			while (!done) {
+				if (cursor.line !== previous.line + 1 || cursor.byteOffset <= previous.byteOffset) {
+					throw new Error("Unexpected cursor " + JSON.stringify(cursor) + " after " + JSON.stringify(previous));
+				}
+
+				previous = cursor;
+				({ done, value, cursor } = await parser.next());
+			}
+
+			if (previous.line !== 4 || previous.byteOffset !== %d) {
+				throw new Error("Unexpected last cursor " + JSON.stringify(previous));
+			}
+		`, testFilePath, len(testCSV))))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("a parser should resume from a restored cursor", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			let file = await fs.open(%q);
+			let parser = new csv.Parser(file, { skipFirstLine: true, includeCursor: true });
+
+			let { cursor } = await parser.next();
+
+			file = await fs.open(%q);
+			parser = new csv.Parser(file, { skipFirstLine: true, includeCursor: true, restore: cursor });
+
+			const { done, value, cursor: next } = await parser.next();
+			if (done || value[0] !== "baz" || next.line !== cursor.line + 1) {
+				throw new Error("Unexpected record " + JSON.stringify(value) + " at " + JSON.stringify(next));
+			}
+		`, testFilePath, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	for _, restore := range []string{
+		`{}`,
+		`{ line: 1 }`,
+		`{ byteOffset: 1 }`,
+		`{ line: null, byteOffset: 1 }`,
+		`{ line: 1.5, byteOffset: 1 }`,
+		`{ line: "1", byteOffset: 1 }`,
+		`{ line: 1, byteOffset: true }`,
+	} {
+		restore := restore
+
+		t.Run("restoring an invalid cursor "+restore+" should fail", func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				new csv.Parser(file, { restore: %s });
+			`, testFilePath, restore)))
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "a cursor must hold integer line and byteOffset properties")
+		})
+	}
+}
+
+func TestParserExportCursor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("a cursor should round-trip across parsers", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			let file = await fs.open(%q);
+			let parser = new csv.Parser(file, { skipFirstLine: true });
+
+			await parser.next();
+			const token = await parser.exportCursor();
+			if (typeof token !== "string" || token.length === 0) {
+				throw new Error("Unexpected token " + token);
+			}
+
+			// Exporting the cursor should not affect the parser's position.
+			let { value } = await parser.next();
+			if (value[0] !== "baz") {
+				throw new Error("Unexpected record after export " + JSON.stringify(value));
+			}
+
+			file = await fs.open(%q);
+			parser = new csv.Parser(file);
+			await parser.importCursor(token);
+
+			value = (await parser.next()).value;
+			if (value[0] !== "baz") {
+				throw new Error("Unexpected record after import " + JSON.stringify(value));
+			}
+		`, testFilePath, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("a cursor exported from a different file should be rejected", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+		require.NoError(t, writeTestFile(r, "/other.csv", strings.ToUpper(testCSV)))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const parser = new csv.Parser(await fs.open(%q));
+			await parser.next();
+			const token = await parser.exportCursor();
+
+			const other = new csv.Parser(await fs.open("/other.csv"));
+			await other.importCursor(token);
+		`, testFilePath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), errCursorTokenMismatch.Error())
+	})
+
+	t.Run("an invalid cursor token should be rejected", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const parser = new csv.Parser(await fs.open(%q));
+			await parser.importCursor("not a token");
+		`, testFilePath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid cursor token")
+	})
+}
+
+func TestParserMaxAge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("next should skip stale records", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath,
+			"stale,2000-01-01T00:00:00Z\nfresh,2999-01-01T00:00:00Z\nstale,946684800\n"))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { timestampColumn: 1, maxAgeSeconds: 3600 });
+
+			let records = [];
+			let { done, value } = await parser.next();
+			while (!done) {
+				records.push(value[0]);
+				({ done, value } = await parser.next());
+			}
+
+			if (records.join("|") !== "fresh") {
+				throw new Error("Unexpected records " + JSON.stringify(records));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("maxAgeSeconds without timestampColumn should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { maxAgeSeconds: 3600 });
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "maxAgeSeconds requires the timestampColumn option to be set")
+	})
+}
+
+func TestDialects(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parser should use a built-in dialect", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, "foo\tbar\t42\n"))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { dialect: "tsv" });
+
+			const { done, value } = await parser.next();
+			if (done || value.join("|") !== "foo|bar|42") {
+				throw new Error("Unexpected record " + JSON.stringify(value));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("built-in dialects should parse differently from the default", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, "foo\tbar,baz\t42\n"))
+
+		for name := range builtinDialects {
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				let file = await fs.open(%q);
+				const expected = JSON.stringify((await new csv.Parser(file).next()).value);
+
+				file = await fs.open(%q);
+				const actual = JSON.stringify((await new csv.Parser(file, { dialect: %q }).next()).value);
+
+				if (actual === expected) {
+					throw new Error("Dialect %s parsed the same as the default: " + actual);
+				}
+			`, testFilePath, testFilePath, name, name)))
+
+			assert.NoError(t, err, name)
+		}
+	})
+
+	t.Run("parser should use a registered dialect with per-call overrides", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, "name;age\nfoo;42\nbar;43\n"))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			csv.registerDialect("semicolon", { delimiter: ";", skipFirstLine: true });
+
+			let file = await fs.open(%q);
+			let parser = new csv.Parser(file, { dialect: "semicolon" });
+
+			let { done, value } = await parser.next();
+			if (done || value.join("|") !== "foo|42") {
+				throw new Error("Unexpected record " + JSON.stringify(value));
+			}
+
+			file = await fs.open(%q);
+			parser = new csv.Parser(file, { dialect: "semicolon", skipFirstLine: false });
+
+			({ done, value } = await parser.next());
+			if (done || value.join("|") !== "name|age") {
+				throw new Error("Unexpected record " + JSON.stringify(value));
+			}
+		`, testFilePath, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("parser with an unknown dialect should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { dialect: "doesnotexist" });
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown dialect "doesnotexist"`)
+	})
+
+	t.Run("overriding a built-in dialect should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`csv.registerDialect("tsv", { delimiter: ";" })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot override the built-in "tsv" dialect`)
+	})
+}
+
+func TestParserColumns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "records should be projected onto the columns designated by index",
+			options: `{ skipFirstLine: true, columns: [2, 0] }`,
+			want:    "42,foo|43,baz|44,quux",
+		},
+		{
+			name:    "records should be projected onto the columns designated by name",
+			options: `{ skipFirstLine: true, columns: ["age", "firstname"] }`,
+			want:    "42,foo|43,baz|44,quux",
+		},
+		{
+			name:    "header records should only hold the designated columns",
+			options: `{ header: true, columns: ["lastname"] }`,
+			want:    `{"lastname":"bar"}|{"lastname":"qux"}|{"lastname":"corge"}`,
+		},
+		{
+			name:    "other options should designate the projected columns",
+			options: `{ skipFirstLine: true, columns: ["age", "lastname"], patterns: { 0: "^4[23]$" } }`,
+			wantErr: `line 4, column "0": value "44" does not match the pattern "^4[23]$"`,
+		},
+		{
+			name:    "an unknown column name should fail",
+			options: `{ skipFirstLine: true, columns: ["email"] }`,
+			wantErr: `invalid columns option; reason: no column named "email"`,
+		},
+		{
+			name:    "an out of range column index should fail",
+			options: `{ skipFirstLine: true, columns: [3] }`,
+			wantErr: "invalid columns option; reason: no column at index 3, as records hold 3 columns",
+		},
+		{
+			name:    "an out of range column index should fail when reading records without a header",
+			options: `{ columns: [3] }`,
+			wantErr: "line 1: no column at index 3, as the record holds 3 fields",
+		},
+		{
+			name:    "designating columns by name without a header should fail",
+			options: `{ columns: ["age"] }`,
+			wantErr: "columns can only be designated by name when the header or skipFirstLine option is set",
+		},
+		{
+			name:    "an empty columns option should fail",
+			options: `{ columns: [] }`,
+			wantErr: "columns must designate at least one column",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(Array.isArray(value) ? value.join(",") : JSON.stringify(value));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserSchema(t *testing.T) {
+	t.Parallel()
+
+	const typedCSV = "id,active,price,created\n1,true,9.99,2024-01-02T03:04:05Z\n2,false,10,1700000000\n"
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "fields should be coerced to the types of the columns designated by index",
+			options: `{ skipFirstLine: true, schema: { 0: "number", 1: "boolean", 3: "date" } }`,
+			want:    `[1,true,"9.99","2024-01-02T03:04:05.000Z"]|[2,false,"10","2023-11-14T22:13:20.000Z"]`,
+		},
+		{
+			name:    "fields should be coerced to the types of the columns designated by name",
+			options: `{ header: true, columns: ["id", "price"], schema: { price: "number", id: "string" } }`,
+			want:    `{"id":"1","price":9.99}|{"id":"2","price":10}`,
+		},
+		{
+			name:    "lazy records should be coerced once accessed",
+			options: `{ header: true, lazy: true, schema: { active: "boolean" } }`,
+			want:    `{"active":true,"created":"2024-01-02T03:04:05Z","id":"1","price":"9.99"}|{"active":false,"created":"1700000000","id":"2","price":"10"}`,
+		},
+		{
+			name:    "fields that cannot be coerced should fail the read",
+			options: `{ skipFirstLine: true, schema: { 3: "number" } }`,
+			wantErr: `line 2, column "3": unable to coerce value "2024-01-02T03:04:05Z" to a number`,
+		},
+		{
+			name:    "columnTypes should coerce fields to the types of the columns",
+			options: `{ header: true, columns: ["id", "active", "price"], columnTypes: { id: "int", active: "bool", price: "float" } }`,
+			want:    `{"active":true,"id":1,"price":9.99}|{"active":false,"id":2,"price":10}`,
+		},
+		{
+			name:    "fields that cannot be coerced to an int should fail the read",
+			options: `{ skipFirstLine: true, columnTypes: { 2: "int" } }`,
+			wantErr: `line 2, column "2": unable to coerce value "9.99" to an int`,
+		},
+		{
+			name:    "fields that cannot be coerced should be left as strings when lenient",
+			options: `{ header: true, columns: ["id", "price"], columnTypes: { price: "int" }, typeMode: "lenient" }`,
+			want:    `{"id":"1","price":"9.99"}|{"id":"2","price":10}`,
+		},
+		{
+			name:    "an unknown type mode should fail",
+			options: `{ skipFirstLine: true, columnTypes: { 0: "int" }, typeMode: "loose" }`,
+			wantErr: `typeMode must be either "strict" or "lenient", got "loose" instead`,
+		},
+		{
+			name:    "schema and columnTypes should conflict",
+			options: `{ skipFirstLine: true, schema: { 0: "number" }, columnTypes: { 0: "int" } }`,
+			wantErr: "the schema and columnTypes options conflict",
+		},
+		{
+			name:    "an unknown type should fail",
+			options: `{ header: true, schema: { id: "integer" } }`,
+			wantErr: `invalid type "integer" for column "id"`,
+		},
+		{
+			name:    "an unknown column should fail",
+			options: `{ header: true, schema: { email: "string" } }`,
+			wantErr: `invalid schema option; reason: no column named "email"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, typedCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(JSON.stringify(Array.isArray(value) ? value : Object.fromEntries(Object.keys(value).sort().map((k) => [k, value[k]]))));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserEncoding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "latin1 encoded files should be decoded",
+			content: "name,country\nJos\xe9,Espa\xf1a\nRen\xe9e,France\n",
+			options: `{ skipFirstLine: true, encoding: "latin1" }`,
+			want:    "José,España|Renée,France",
+		},
+		{
+			name:    "windows-1252 encoded files should be decoded",
+			content: "name,price\ncaf\xe9,\x805\n",
+			options: `{ skipFirstLine: true, encoding: "windows-1252" }`,
+			want:    "café,€5",
+		},
+		{
+			name:    "utf-16le encoded files should be decoded",
+			content: "\xff\xfen\x00,\x00\xf1\x00\n\x00",
+			options: `{ encoding: "utf-16le" }`,
+			want:    "n,ñ",
+		},
+		{
+			name:    "utf-8 encoded files should be read as is",
+			content: "name,country\nJosé,España\n",
+			options: `{ skipFirstLine: true, encoding: "UTF-8" }`,
+			want:    "José,España",
+		},
+		{
+			name:    "an unsupported encoding should fail",
+			content: testCSV,
+			options: `{ encoding: "ebcdic" }`,
+			wantErr: `unsupported encoding "ebcdic"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, tt.content))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value.join(","));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserStripBOM(t *testing.T) {
+	t.Parallel()
+
+	const bomCSV = "\xef\xbb\xbfname,age\nfoo,42\n"
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+	}{
+		{
+			name:    "a leading byte order mark should be stripped by default",
+			options: `{ header: true }`,
+			want:    `["name","age"]`,
+		},
+		{
+			name:    "a leading byte order mark should be kept when requested",
+			options: `{ header: true, stripBOM: false }`,
+			want:    "[\"\ufeffname\",\"age\"]",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, bomCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				const { value } = await parser.next();
+				const got = JSON.stringify(Object.keys(value).sort().reverse());
+				if (got !== %q) {
+					throw new Error("Unexpected header " + got);
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("cycling should rewind past the byte order mark", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const parser = new csv.Parser(%q, { cycle: true });
+
+			let got = [];
+			for (let i = 0; i < 4; i++) {
+				const { value } = await parser.next();
+				got.push(value.join(","));
+			}
+
+			if (got.join("|") !== "name,age|foo,42|name,age|foo,42") {
+				throw new Error("Unexpected records " + JSON.stringify(got));
+			}
+		`, bomCSV)))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParserSeek(t *testing.T) {
+	t.Parallel()
+
+	t.Run("seeking should reposition the parser at the requested line", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true });
+
+			await parser.next();
+			await parser.next();
+			await parser.next();
+
+			await parser.seek(2);
+			let { value } = await parser.next();
+			if (value.join(",") !== "baz,qux,43") {
+				throw new Error("Unexpected record after seeking forward " + JSON.stringify(value));
+			}
+
+			await parser.seek(1);
+			({ value } = await parser.next());
+			if (value.join(",") !== "foo,bar,42") {
+				throw new Error("Unexpected record after seeking backward " + JSON.stringify(value));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("seeking beyond the end of the file should fail, and leave the parser in place", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true });
+
+			await parser.next();
+
+			let failed = false;
+			try {
+				await parser.seek(10);
+			} catch (err) {
+				if (!String(err).includes("cannot seek to line 10, beyond the end of the file")) {
+					throw err;
+				}
+				failed = true;
+			}
+
+			if (!failed) {
+				throw new Error("Expected seeking beyond the end of the file to fail");
+			}
+
+			const { value } = await parser.next();
+			if (value.join(",") !== "baz,qux,43") {
+				throw new Error("Unexpected record " + JSON.stringify(value));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("seeking before the first data line should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true });
+			await parser.seek(0);
+		`, testFilePath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot seek to line 0, before the first data line 1")
+	})
+}
+
+func TestParserProgress(t *testing.T) {
+	t.Parallel()
+
+	r, err := newConfiguredRuntime(t)
+	require.NoError(t, err)
+
+	require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+	_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+		const file = await fs.open(%q);
+		const parser = new csv.Parser(file, { skipFirstLine: true });
+
+		if (parser.size() !== %d) {
+			throw new Error("Unexpected size " + parser.size());
+		}
+
+		let offsets = [parser.offset()];
+		let { done } = await parser.next();
+		while (!done) {
+			offsets.push(parser.offset());
+			({ done } = await parser.next());
+		}
+
+		if (JSON.stringify(offsets) !== "[23,34,45,59]") {
+			throw new Error("Unexpected offsets " + JSON.stringify(offsets));
+		}
+	`, testFilePath, len(testCSV))))
+
+	assert.NoError(t, err)
+}
+
+func TestParserCount(t *testing.T) {
+	t.Parallel()
+
+	const multilineCSV = "name,bio\nfoo,\"multi\nline\"\nbar,baz\nqux,quux\n"
+
+	tests := []struct {
+		name    string
+		options string
+		want    int
+	}{
+		{
+			name:    "every row should be counted",
+			options: `{}`,
+			want:    4,
+		},
+		{
+			name:    "the header should not be counted",
+			options: `{ header: true }`,
+			want:    3,
+		},
+		{
+			name:    "rows excluded by fromLine and toLine should be counted",
+			options: `{ skipFirstLine: true, fromLine: 1, toLine: 2 }`,
+			want:    3,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, multilineCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				const count = await parser.count();
+				if (count !== %d) {
+					throw new Error("Unexpected count " + count);
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("counting should leave the parser's position unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, multilineCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true });
+
+			await parser.next();
+			await parser.count();
+
+			const { value } = await parser.next();
+			if (value.join(",") !== "bar,baz") {
+				throw new Error("Unexpected record " + JSON.stringify(value));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parsed rows should be shared and read-only", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const rows = csv.parse(await fs.open(%q), { skipFirstLine: true });
+			if (rows.length !== 3) {
+				throw new Error("Unexpected length " + rows.length);
+			}
+
+			const row = rows.get(1);
+			if (row.join(",") !== "baz,qux,43") {
+				throw new Error("Unexpected row " + JSON.stringify(row));
+			}
+
+			row[0] = "modified";
+			if (rows.get(1)[0] !== "baz") {
+				throw new Error("Expected the shared rows not to be modified");
+			}
+
+			if (rows.get(3) !== undefined) {
+				throw new Error("Expected out of range rows to be undefined");
+			}
+
+			const again = csv.parse(await fs.open(%q), { skipFirstLine: true });
+			if (again.length !== rows.length) {
+				throw new Error("Unexpected length " + again.length);
+			}
+		`, testFilePath, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("parsed rows should be objects in header mode", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const rows = csv.parse(await fs.open(%q), { header: true });
+			if (rows.length !== 3 || rows.get(0).age !== "42") {
+				throw new Error("Unexpected rows " + JSON.stringify(rows.get(0)));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("parsed rows should be objects with the asObjects option", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const rows = csv.parse(await fs.open(%q), { asObjects: true });
+			if (rows.length !== 3 || rows.get(2).firstname !== "quux") {
+				throw new Error("Unexpected rows " + JSON.stringify(rows.get(2)));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("parsing an in-memory source should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`csv.parse("a,b\n1,2\n")`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "csv.parse() requires a fs.File")
+	})
+}
+
+func TestParserShard(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "rows should be assigned to partitions in a round-robin fashion",
+			options: `{ skipFirstLine: true, shard: { index: 0, total: 2 } }`,
+			want:    "foo|quux",
+		},
+		{
+			name:    "partitions should be disjoint",
+			options: `{ skipFirstLine: true, shard: { index: 1, total: 2 } }`,
+			want:    "baz",
+		},
+		{
+			name:    "an out of range index should fail",
+			options: `{ shard: { index: 2, total: 2 } }`,
+			wantErr: "shard requires an index between 0 and 1",
+		},
+		{
+			name:    "shard and partitionByVU should conflict",
+			options: `{ shard: { index: 0, total: 2 }, partitionByVU: true }`,
+			wantErr: "the shard and partitionByVU options conflict",
+		},
+		{
+			name:    "shard and partitionBy should conflict",
+			options: `{ shard: { index: 0, total: 2 }, partitionBy: "vu" }`,
+			wantErr: "the shard and partitionByVU options conflict",
+		},
+		{
+			name:    "an unsupported partitionBy should fail",
+			options: `{ partitionBy: "iteration" }`,
+			wantErr: `partitionBy must be "vu", got "iteration" instead`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value[0]);
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("partitionByVU should only return the rows of the VU's partition", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			globalThis.parser = new csv.Parser(await fs.open(%q), { skipFirstLine: true, partitionByVU: true });
+		`, testFilePath)))
+		require.NoError(t, err)
+
+		config := executor.NewPerVUIterationsConfig("default")
+		config.VUs = null.IntFrom(2)
+
+		et, err := lib.NewExecutionTuple(nil, nil)
+		require.NoError(t, err)
+
+		testRunState := &lib.TestRunState{Options: lib.Options{Scenarios: lib.ScenarioConfigs{"default": config}}}
+		r.VU.CtxField = lib.WithExecutionState(r.VU.Context(), lib.NewExecutionState(testRunState, et, 2, 2))
+		r.MoveToVUContext(newTestVUState(r, 2, make(chan metrics.SampleContainer, 100)))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(`
+			let got = [];
+			let { done, value } = await parser.next();
+			while (!done) {
+				got.push(value[0]);
+				({ done, value } = await parser.next());
+			}
+
+			if (got.join("|") !== "baz") {
+				throw new Error("Unexpected records " + JSON.stringify(got));
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("partitionBy vu should partition the rows among the VUs of every execution segment", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		rows := "n\n"
+		for i := 2; i <= 9; i++ {
+			rows += strconv.Itoa(i) + "\n"
+		}
+
+		require.NoError(t, writeTestFile(r, testFilePath, rows))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			globalThis.parser = new csv.Parser(await fs.open(%q), { skipFirstLine: true, partitionBy: "vu" });
+		`, testFilePath)))
+		require.NoError(t, err)
+
+		config := executor.NewPerVUIterationsConfig("default")
+		config.VUs = null.IntFrom(4)
+
+		// The instance runs the second half of the test's VUs, which global ids
+		// alternate with the ones of the first half.
+		segment, err := lib.NewExecutionSegmentFromString("1/2:1")
+		require.NoError(t, err)
+
+		sequence, err := lib.NewExecutionSegmentSequenceFromString("0,1/2,1")
+		require.NoError(t, err)
+
+		et, err := lib.NewExecutionTuple(segment, &sequence)
+		require.NoError(t, err)
+
+		testRunState := &lib.TestRunState{Options: lib.Options{Scenarios: lib.ScenarioConfigs{"default": config}}}
+		r.VU.CtxField = lib.WithExecutionState(r.VU.Context(), lib.NewExecutionState(testRunState, et, 2, 2))
+
+		state := newTestVUState(r, 1, make(chan metrics.SampleContainer, 100))
+		state.VUIDGlobal = 2
+		r.MoveToVUContext(state)
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(`
+			let got = [];
+			let { done, value } = await parser.next();
+			while (!done) {
+				got.push(value[0]);
+				({ done, value } = await parser.next());
+			}
+
+			if (got.join("|") !== "5|9") {
+				throw new Error("Unexpected records " + JSON.stringify(got));
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("partitionByVU should fail outside of a VU", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const parser = new csv.Parser(await fs.open(%q), { partitionByVU: true });
+			await parser.next();
+		`, testFilePath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "partitionByVU requires records to be read by a VU")
+	})
+}
+
+func TestParserAt(t *testing.T) {
+	t.Parallel()
+
+	const multilineCSV = "name,bio\nfoo,\"multi\nline\"\nbar,baz\nqux,quux\n"
+
+	t.Run("rows should be accessed by index", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, multilineCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { header: true });
+
+			await parser.next();
+
+			let got = [];
+			for (const index of [2, 0, 1, 2]) {
+				got.push((await parser.at(index)).name);
+			}
+
+			if (got.join("|") !== "qux|foo|bar|qux") {
+				throw new Error("Unexpected rows " + JSON.stringify(got));
+			}
+
+			if ((await parser.at(0)).bio !== "multi\nline") {
+				throw new Error("Unexpected multiline field");
+			}
+
+			const { value } = await parser.next();
+			if (value.name !== "bar") {
+				throw new Error("Expected the parser's position to be unchanged, got " + JSON.stringify(value));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("an out of range index should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, multilineCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { header: true });
+			await parser.at(3);
+		`, testFilePath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "row index 3 is out of range, as the file holds 3 rows")
+	})
+}
+
+func TestParserCompression(t *testing.T) {
+	t.Parallel()
+
+	gzipped := gzipTestData(t, testCSV)
+	zstded := zstdTestData(t, testCSV)
+
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "gzip compressed files should be decompressed",
+			content: gzipped,
+			options: `{ header: true, compression: "gzip", toLine: 2 }`,
+			want:    "foo|baz",
+		},
+		{
+			name:    "gzip compressed files should be detected",
+			content: gzipped,
+			options: `{ skipFirstLine: true, compression: "auto" }`,
+			want:    "foo|baz|quux",
+		},
+		{
+			name:    "uncompressed files should be read as is when detecting compression",
+			content: testCSV,
+			options: `{ skipFirstLine: true, compression: "auto" }`,
+			want:    "foo|baz|quux",
+		},
+		{
+			name:    "a truncated gzip stream should fail reading",
+			content: gzipped[:len(gzipped)-12],
+			options: `{ skipFirstLine: true, compression: "gzip" }`,
+			wantErr: "failed to decompress the gzip stream; reason: unexpected EOF",
+		},
+		{
+			name:    "an uncompressed file should fail when expecting gzip",
+			content: testCSV,
+			options: `{ compression: "gzip" }`,
+			wantErr: "failed to decompress the gzip stream; reason: gzip: invalid header",
+		},
+		{
+			name:    "zstd compressed files should be decompressed",
+			content: zstded,
+			options: `{ header: true, compression: "zstd", toLine: 2 }`,
+			want:    "foo|baz",
+		},
+		{
+			name:    "zstd compressed files should be detected",
+			content: zstded,
+			options: `{ skipFirstLine: true, compression: "auto" }`,
+			want:    "foo|baz|quux",
+		},
+		{
+			name:    "files decompressed by the fs module should be parsed as is",
+			path:    testFilePath + ".gz",
+			content: gzipped,
+			options: `{ skipFirstLine: true, compression: "gzip" }`,
+			want:    "foo|baz|quux",
+		},
+		{
+			name:    "an uncompressed file should fail when expecting zstd",
+			content: testCSV,
+			options: `{ compression: "zstd" }`,
+			wantErr: "failed to decompress the zstd stream",
+		},
+		{
+			name:    "an unsupported compression should fail",
+			content: testCSV,
+			options: `{ compression: "brotli" }`,
+			wantErr: `unsupported compression "brotli"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			path := tt.path
+			if path == "" {
+				path = testFilePath
+			}
+
+			require.NoError(t, writeTestFile(r, path, tt.content))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(Array.isArray(value) ? value[0] : value.firstname);
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, path, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserSkipErrors(t *testing.T) {
+	t.Parallel()
+
+	const malformedCSV = "a,b\n1,2\n3\n4,5\n6,\"x\"y\n7,8\n"
+
+	t.Run("malformed records should be skipped, and their errors collected", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, malformedCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true, skipErrors: true });
+
+			let got = [];
+			let { done, value } = await parser.next();
+			while (!done) {
+				got.push(value.join(","));
+				({ done, value } = await parser.next());
+			}
+
+			if (got.join("|") !== "1,2|4,5|7,8") {
+				throw new Error("Unexpected records " + JSON.stringify(got));
+			}
+
+			const errors = JSON.stringify(parser.errors());
+			const want = JSON.stringify([
+				{ line: 3, message: "wrong number of fields" },
+				{ line: 5, message: 'extraneous or missing " in quoted-field' },
+			]);
+			if (errors !== want) {
+				throw new Error("Unexpected errors " + errors);
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("malformed records should fail the read by default", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, malformedCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true });
+
+			await parser.next();
+			await parser.next();
+		`, testFilePath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 3: wrong number of fields")
+	})
+
+	t.Run("the onError option should set the policy for malformed records", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, malformedCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true, onError: "skip" });
+
+			let got = [];
+			let { done, value } = await parser.next();
+			while (!done) {
+				got.push(value.join(","));
+				({ done, value } = await parser.next());
+			}
+
+			if (got.join("|") !== "1,2|4,5|7,8") {
+				throw new Error("Unexpected records " + JSON.stringify(got));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	for _, options := range []string{
+		`{ skipFirstLine: true, skipErrors: undefined, onError: "skip" }`,
+		`{ skipFirstLine: true, skipErrors: null, onError: "skip" }`,
+	} {
+		options := options
+
+		t.Run("the onError option should not conflict with a nullish skipErrors option "+options, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, malformedCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value.join(","));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== "1,2|4,5|7,8") {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, options)))
+
+			assert.NoError(t, err)
+		})
+	}
+
+	for options, wantErr := range map[string]string{
+		`{ onError: "ignore" }`:                  `onError must be either "skip" or "abort"`,
+		`{ onError: "abort", skipErrors: true }`: "onError conflicts with the skipErrors option",
+	} {
+		options, wantErr := options, wantErr
+
+		t.Run("invalid onError option "+options+" should fail", func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, malformedCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				new csv.Parser(file, %s);
+			`, testFilePath, options)))
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), wantErr)
+		})
+	}
+}
+
+func TestParserLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "the number of returned records should be limited",
+			options: `{ skipFirstLine: true, limit: 2 }`,
+			want:    "foo|baz",
+		},
+		{
+			name:    "filtered records should not count towards the limit",
+			options: `{ skipFirstLine: true, limit: 2, filter: (fields) => fields[0] !== "foo" }`,
+			want:    "baz|quux",
+		},
+		{
+			name:    "the limit should cap the records returned across cycles",
+			options: `{ skipFirstLine: true, limit: 5, cycle: true }`,
+			want:    "foo|baz|quux|foo|baz",
+		},
+		{
+			name:    "a limit of 0 should return no records",
+			options: `{ limit: 0 }`,
+			want:    "",
+		},
+		{
+			name:    "a negative limit should fail",
+			options: `{ limit: -1 }`,
+			wantErr: "limit must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value[0]);
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("records skipped until a match should not count towards the limit", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new csv.Parser(file, { skipFirstLine: true, limit: 2 });
+
+			await parser.skipUntil((fields) => fields[0] === "baz");
+			const rows = await parser.readAll();
+			if (JSON.stringify(rows) !== JSON.stringify([["baz", "qux", "43"], ["quux", "corge", "44"]])) {
+				throw new Error("Unexpected rows " + JSON.stringify(rows));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParserQuote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		csv     string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "fields wrapped in a custom quote should hold delimiters and line terminators",
+			csv:     "'a,b',c\n'multi\r\nline',d\n",
+			options: `{ quote: "'" }`,
+			want:    `[["a,b","c"],["multi\nline","d"]]`,
+		},
+		{
+			name:    "a doubled custom quote should be unescaped",
+			csv:     "'it''s',\"as is\"\n",
+			options: `{ quote: "'" }`,
+			want:    `[["it's","\"as is\""]]`,
+		},
+		{
+			name:    "empty and comment lines should be skipped with a custom quote",
+			csv:     "# comment\n\n'a';'b'\n",
+			options: `{ quote: "'", delimiter: ";", comment: "#" }`,
+			want:    `[["a","b"]]`,
+		},
+		{
+			name:    "leading spaces should be trimmed with a custom quote",
+			csv:     "a,  'b'\n",
+			options: `{ quote: "'", trimLeadingSpace: true }`,
+			want:    `[["a","b"]]`,
+		},
+		{
+			name:    "lazy quotes should be tolerated with a custom quote",
+			csv:     "a'b,'c'd'\n",
+			options: `{ quote: "'", lazyQuotes: true }`,
+			want:    `[["a'b","c'd"]]`,
+		},
+		{
+			name:    "a bare custom quote should fail",
+			csv:     "a'b,c\n",
+			options: `{ quote: "'" }`,
+			wantErr: "bare \" in non-quoted-field",
+		},
+		{
+			name:    "an unterminated custom quote should fail",
+			csv:     "'a,b\n",
+			options: `{ quote: "'" }`,
+			wantErr: "extraneous or missing \" in quoted-field",
+		},
+		{
+			name:    "records holding a different number of fields should fail with a custom quote",
+			csv:     "a,b\nc\n",
+			options: `{ quote: "'" }`,
+			wantErr: "wrong number of fields",
+		},
+		{
+			name:    "a quote of more than one character should fail",
+			csv:     "a\n",
+			options: `{ quote: "''" }`,
+			wantErr: "quote must be a single character",
+		},
+		{
+			name:    "a quote equal to the delimiter should fail",
+			csv:     "a\n",
+			options: `{ quote: ";", delimiter: ";" }`,
+			wantErr: "quote must differ from the delimiter and the comment",
+		},
+		{
+			name:    "a custom quote should conflict with bestEffort",
+			csv:     "a\n",
+			options: `{ quote: "'", bestEffort: true }`,
+			wantErr: "bestEffort only supports the default quote",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, tt.csv))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value);
+					({ done, value } = await parser.next());
+				}
+
+				if (JSON.stringify(got) !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserMetrics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reading rows should emit the rows parsed and parse duration metrics", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			globalThis.parser = new csv.Parser(await fs.open(%q), { skipFirstLine: true });
+		`, testFilePath)))
+		require.NoError(t, err)
+
+		samples := make(chan metrics.SampleContainer, 100)
+		r.MoveToVUContext(newTestVUState(r, 1, samples))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(`
+			let { done } = await parser.next();
+			while (!done) {
+				({ done } = await parser.next());
+			}
+		`))
+		require.NoError(t, err)
+
+		close(samples)
+
+		var rows, durations int
+		for container := range samples {
+			for _, sample := range container.GetSamples() {
+				file, ok := sample.Tags.Get("file")
+				assert.True(t, ok)
+				assert.Equal(t, testFilePath, file)
+
+				switch sample.Metric.Name {
+				case "csv_rows_parsed":
+					rows += int(sample.Value)
+				case "csv_parse_duration":
+					durations++
+					assert.GreaterOrEqual(t, sample.Value, 0.0)
+				}
+			}
+		}
+
+		assert.Equal(t, 3, rows)
+		assert.Equal(t, 3, durations)
+	})
+
+	t.Run("reading rows in the init context should not emit metrics", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const parser = new csv.Parser(await fs.open(%q), { skipFirstLine: true });
+			const { done, value } = await parser.next();
+			if (done || value[0] !== "foo") {
+				throw new Error("Unexpected record " + JSON.stringify(value));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParserIncludeRaw(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		csv     string
+		options string
+		want    string
+	}{
+		{
+			name:    "results should hold the lines records were parsed from",
+			csv:     "a, b ,c\r\nd,e,f\n",
+			options: `{ includeRaw: true }`,
+			want:    `["a, b ,c","d,e,f"]`,
+		},
+		{
+			name:    "raw text should include the line terminators of multi-line quoted fields",
+			csv:     "a,\"multi\nline\"\nb,c\n",
+			options: `{ includeRaw: true }`,
+			want:    `["a,\"multi\nline\"","b,c"]`,
+		},
+		{
+			name:    "raw text should leave out skipped empty and comment lines",
+			csv:     "a,b\n\n# comment\nc,d",
+			options: `{ includeRaw: true, comment: "#" }`,
+			want:    `["a,b","c,d"]`,
+		},
+		{
+			name:    "raw text should hold the whole line when columns are projected",
+			csv:     "a,b\nc,d\n",
+			options: `{ includeRaw: true, columns: [1] }`,
+			want:    `["a,b","c,d"]`,
+		},
+		{
+			name:    "raw text should be included along with the cursor",
+			csv:     "a,b\nc,d\n",
+			options: `{ includeRaw: true, includeCursor: true, filter: (fields) => fields[0] === "c" }`,
+			want:    `["c,d"]`,
+		},
+		{
+			name:    "raw text should be read from the header's following lines",
+			csv:     "h1,h2\na,b\n",
+			options: `{ includeRaw: true, header: true }`,
+			want:    `["a,b"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, tt.csv))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let result = await parser.next();
+				while (!result.done) {
+					got.push(result.raw);
+					result = await parser.next();
+				}
+
+				if (JSON.stringify(got) !== %q) {
+					throw new Error("Unexpected raw text " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("results should not hold the raw text by default", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const parser = new csv.Parser(await fs.open(%q));
+			const result = await parser.next();
+			if (result.raw !== undefined) {
+				throw new Error("Unexpected raw text " + result.raw);
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("raw text should be in sync with the cursor", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const parser = new csv.Parser(await fs.open(%q), { skipFirstLine: true, includeRaw: true, includeCursor: true });
+			const result = await parser.next();
+			if (result.raw !== "foo,bar,42" || result.cursor.line !== 2 || result.value[0] !== "foo") {
+				throw new Error("Unexpected result " + JSON.stringify(result));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParserTransform(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "records should be mapped by the transform callback",
+			options: `{ skipFirstLine: true, transform: (fields) => fields[0] + " " + fields[1] }`,
+			want:    `["foo bar","baz qux","quux corge"]`,
+		},
+		{
+			name:    "the transform callback should receive the line records were read from",
+			options: `{ skipFirstLine: true, transform: (fields, line) => line }`,
+			want:    `[2,3,4]`,
+		},
+		{
+			name:    "records should be filtered before being transformed",
+			options: `{ header: true, filter: (row) => typeof row === "object" && row.age !== "43", transform: (row) => row.firstname }`,
+			want:    `["foo","quux"]`,
+		},
+		{
+			name:    "a transform callback throwing should reject with the line",
+			options: `{ skipFirstLine: true, transform: (fields) => { if (fields[0] === "baz") { throw new Error("boom"); } return fields; } }`,
+			wantErr: "line 3: the transform callback failed; reason: Error: boom",
+		},
+		{
+			name:    "a transform option which is not a function should fail",
+			options: `{ transform: "nope" }`,
+			wantErr: "transform must be a function",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value);
+					({ done, value } = await parser.next());
+				}
+
+				if (JSON.stringify(got) !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("readAll should return the transformed records", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const parser = new csv.Parser(await fs.open(%q), { skipFirstLine: true, transform: (fields) => fields.length });
+			const rows = await parser.readAll();
+			if (JSON.stringify(rows) !== "[3,3,3]") {
+				throw new Error("Unexpected rows " + JSON.stringify(rows));
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParserBufferSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options string
+		wantErr string
+	}{
+		{
+			name:    "records should be read through a buffer smaller than a record",
+			options: `{ skipFirstLine: true, bufferSize: 16 }`,
+		},
+		{
+			name:    "records should be read through a buffer larger than the file",
+			options: `{ skipFirstLine: true, bufferSize: 1048576 }`,
+		},
+		{
+			name:    "records should be read through a buffer when seeking",
+			options: `{ skipFirstLine: true, bufferSize: 8192, cycle: true }`,
+		},
+		{
+			name:    "a bufferSize of 0 should fail",
+			options: `{ bufferSize: 0 }`,
+			wantErr: "bufferSize must be greater than 0",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				for (let i = 0; i < 3; i++) {
+					const { done, value } = await parser.next();
+					if (done) {
+						break;
+					}
+					got.push(value[0]);
+				}
+
+				if (got.join("|") !== "foo|baz|quux") {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserChain(t *testing.T) {
+	t.Parallel()
+
+	const (
+		janPath = fsext.FilePathSeparator + "jan.csv"
+		febPath = fsext.FilePathSeparator + "feb.csv"
+	)
+
+	tests := []struct {
+		name    string
+		jan     string
+		feb     string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "records should be read from each file in turn",
+			jan:     "a,1\nb,2\n",
+			feb:     "c,3\n",
+			options: `{}`,
+			want:    `[["a","1"],["b","2"],["c","3"]]`,
+		},
+		{
+			name:    "the header should be read from the first file, and skipped from the following ones",
+			jan:     "name,value\na,1\n",
+			feb:     "name,value\nb,2\n",
+			options: `{ header: true, transform: (row) => row.name + "=" + row.value }`,
+			want:    `["a=1","b=2"]`,
+		},
+		{
+			name:    "a repeated header spanning multiple lines should be skipped",
+			jan:     "\"na\nme\",value\na,1\n",
+			feb:     "\"na\nme\",value\nb,2\n",
+			options: `{ skipFirstLine: true }`,
+			want:    `[["a","1"],["b","2"]]`,
+		},
+		{
+			name:    "a file without a trailing line terminator should not be joined with the next one",
+			jan:     "a,1",
+			feb:     "b,2",
+			options: `{}`,
+			want:    `[["a","1"],["b","2"]]`,
+		},
+		{
+			name:    "a file only holding a header should be skipped",
+			jan:     "name,value\na,1\n",
+			feb:     "name,value\n",
+			options: `{ skipFirstLine: true }`,
+			want:    `[["a","1"]]`,
+		},
+		{
+			name:    "lines should be counted across files",
+			jan:     "name,value\na,1\n",
+			feb:     "name,value\nb,2\n",
+			options: `{ skipFirstLine: true, transform: (fields, line) => line }`,
+			want:    `[2,3]`,
+		},
+		{
+			name:    "cycling should rewind to the first file",
+			jan:     "name\na\n",
+			feb:     "name\nb\n",
+			options: `{ skipFirstLine: true, cycle: true, limit: 4 }`,
+			want:    `[["a"],["b"],["a"],["b"]]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, janPath, tt.jan))
+			require.NoError(t, writeTestFile(r, febPath, tt.feb))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const files = [await fs.open(%q), await fs.open(%q)];
+				const parser = new csv.Parser(files, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value);
+					({ done, value } = await parser.next());
+				}
+
+				if (JSON.stringify(got) !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, janPath, febPath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("an empty array of files should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(`
+			new csv.Parser([]);
+		`))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "got an empty array instead")
+	})
+
+	t.Run("an array holding an invalid source should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, janPath, "a\n"))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			new csv.Parser([await fs.open(%q), 42]);
+		`, janPath)))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid source at index 1")
+	})
+}
+
+func TestParserDelimiter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		csv           string
+		options       string
+		wantDelimiter string
+		want          string
+		wantErr       string
+	}{
+		{
+			name:          "a tab delimiter should be detected",
+			csv:           "a\tb\tc\n1\t2\t3\n",
+			options:       `{ autoDetectDelimiter: true }`,
+			wantDelimiter: "\t",
+			want:          `[["a","b","c"],["1","2","3"]]`,
+		},
+		{
+			name:          "a semicolon delimiter should be detected from the header",
+			csv:           "a;b,c;d\n1;2,3;4\n",
+			options:       `{ autoDetectDelimiter: true, skipFirstLine: true }`,
+			wantDelimiter: ";",
+			want:          `[["1","2,3","4"]]`,
+		},
+		{
+			name:          "a pipe delimiter should be detected past the metadata lines",
+			csv:           "exported,on,monday\na|b\n1|2\n",
+			options:       `{ autoDetectDelimiter: true, metadataLines: 1 }`,
+			wantDelimiter: "|",
+			want:          `[["a","b"],["1","2"]]`,
+		},
+		{
+			name:          "the configured delimiter should be used when none is detected",
+			csv:           "a:b\n",
+			options:       `{ autoDetectDelimiter: true, delimiter: ":" }`,
+			wantDelimiter: ":",
+			want:          `[["a","b"]]`,
+		},
+		{
+			name:          "a named delimiter should be resolved",
+			csv:           "a\tb\n",
+			options:       `{ delimiter: "tab" }`,
+			wantDelimiter: "\t",
+			want:          `[["a","b"]]`,
+		},
+		{
+			name:          "the semicolon named delimiter should be resolved",
+			csv:           "a;b\n",
+			options:       `{ delimiter: "semicolon" }`,
+			wantDelimiter: ";",
+			want:          `[["a","b"]]`,
+		},
+		{
+			name:          "a delimiter encoded over multiple bytes should be accepted",
+			csv:           "a»b»c\n1»2»3\n",
+			options:       `{ delimiter: "»" }`,
+			wantDelimiter: "»",
+			want:          `[["a","b","c"],["1","2","3"]]`,
+		},
+		{
+			name:          "a wide delimiter should be accepted",
+			csv:           "a、b\n",
+			options:       `{ delimiter: "、" }`,
+			wantDelimiter: "、",
+			want:          `[["a","b"]]`,
+		},
+		{
+			name:    "a delimiter of several multi-byte characters should fail",
+			csv:     "a»»b\n",
+			options: `{ delimiter: "»»" }`,
+			wantErr: "delimiter must be a single character",
+		},
+		{
+			name:    "an empty delimiter should fail",
+			csv:     "a,b\n",
+			options: `{ delimiter: "" }`,
+			wantErr: "delimiter must be a single non-empty character",
+		},
+		{
+			name:          "an absent delimiter should default to a comma",
+			csv:           "a,b\n",
+			options:       `{ header: false }`,
+			wantDelimiter: ",",
+			want:          `[["a","b"]]`,
+		},
+		{
+			name:    "a detected delimiter equal to the comment should fail",
+			csv:     "a;b;c\n",
+			options: `{ autoDetectDelimiter: true, comment: ";" }`,
+			wantErr: "conflicts with the comment or quote option",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, tt.csv))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				if (parser.delimiter() !== %q) {
+					throw new Error("Unexpected delimiter " + JSON.stringify(parser.delimiter()));
+				}
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value);
+					({ done, value } = await parser.next());
+				}
+
+				if (JSON.stringify(got) !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.wantDelimiter, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserPhysicalLines(t *testing.T) {
+	t.Parallel()
+
+	// The first record spans the first two lines.
+	const multiline = "a,\"x\ny\"\nb,c\nd,e\n"
+
+	tests := []struct {
+		name    string
+		csv     string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "lines should account for quoted fields spanning multiple lines",
+			csv:     multiline,
+			options: `{ transform: (fields, line) => line }`,
+			want:    `[2,3,4]`,
+		},
+		{
+			name:    "lines should account for quoted fields holding carriage returns",
+			csv:     "a,\"x\r\ny\"\r\nb,c\r\n",
+			options: `{ transform: (fields, line) => line }`,
+			want:    `[2,3]`,
+		},
+		{
+			name:    "lines should account for comments and multi-line fields alike",
+			csv:     "# comment\na,\"x\ny\"\nb,c\n",
+			options: `{ comment: "#", transform: (fields, line) => line }`,
+			want:    `[3,4]`,
+		},
+		{
+			name:    "toLine should count physical lines",
+			csv:     multiline,
+			options: `{ toLine: 1, transform: (fields) => fields[0] }`,
+			want:    `["a"]`,
+		},
+		{
+			name:    "fromLine should count rows, rather than physical lines",
+			csv:     multiline,
+			options: `{ fromLine: 1, transform: (fields) => fields[0] }`,
+			want:    `["b","d"]`,
+		},
+		{
+			name:    "errors should report the physical line",
+			csv:     "a,\"x\ny\"\nb\n",
+			options: `{}`,
+			wantErr: "line 3: wrong number of fields",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, tt.csv))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(value);
+					({ done, value } = await parser.next());
+				}
+
+				if (JSON.stringify(got) !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserFromLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "fromLine should designate the zero-based index of the first data row",
+			options: `{ fromLine: 2 }`,
+			want:    "baz|quux",
+		},
+		{
+			name:    "fromLine should not count the skipped first line as a data row",
+			options: `{ skipFirstLine: true, fromLine: 2 }`,
+			want:    "quux",
+		},
+		{
+			name:    "fromLine should not count the header as a data row",
+			options: `{ header: true, fromLine: 1, transform: (row) => row.firstname }`,
+			want:    "baz|quux",
+		},
+		{
+			name:    "a fromLine of 0 should return every data row",
+			options: `{ skipFirstLine: true, fromLine: 0 }`,
+			want:    "foo|baz|quux",
+		},
+		{
+			name:    "a fromLine of 0 should return every line when none is skipped",
+			options: `{ fromLine: 0 }`,
+			want:    "firstname|foo|baz|quux",
+		},
+		{
+			name:    "a fromLine past the last data row should return no row",
+			options: `{ skipFirstLine: true, fromLine: 3 }`,
+			want:    "",
+		},
+		{
+			name:    "a fromLine beyond the end of the file should fail",
+			options: `{ skipFirstLine: true, fromLine: 4 }`,
+			wantErr: "failed to skip rows until fromLine",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(typeof value === "string" ? value : value[0]);
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserPeek(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options string
+		script  string
+	}{
+		{
+			name:    "the peeked row should be returned by the following call to next",
+			options: `{ skipFirstLine: true }`,
+			script: `
+				const peeked = await parser.peek();
+				const next = await parser.next();
+				if (peeked.done || peeked.value.join(",") !== "foo,bar,42" || next.value.join(",") !== "foo,bar,42") {
+					throw new Error("Unexpected rows " + JSON.stringify([peeked, next]));
+				}
+
+				const following = await parser.next();
+				if (following.value.join(",") !== "baz,qux,43") {
+					throw new Error("Unexpected following row " + JSON.stringify(following));
+				}
+			`,
+		},
+		{
+			name:    "peeking repeatedly should return the same row",
+			options: `{ skipFirstLine: true }`,
+			script: `
+				const first = await parser.peek();
+				const second = await parser.peek();
+				if (first.value.join(",") !== "foo,bar,42" || second.value.join(",") !== "foo,bar,42") {
+					throw new Error("Unexpected rows " + JSON.stringify([first, second]));
+				}
+			`,
+		},
+		{
+			name:    "peeking should not advance the parser's offset",
+			options: `{ skipFirstLine: true }`,
+			script: `
+				const before = parser.offset();
+				await parser.peek();
+				if (parser.offset() !== before) {
+					throw new Error("Expected offset " + before + ", got " + parser.offset());
+				}
+
+				await parser.next();
+				if (parser.offset() <= before) {
+					throw new Error("Expected the offset to advance past " + before + ", got " + parser.offset());
+				}
+			`,
+		},
+		{
+			name:    "peeking past the last row should resolve to done",
+			options: `{ skipFirstLine: true, fromLine: 2 }`,
+			script: `
+				await parser.next();
+				const { done } = await parser.peek();
+				if (!done) {
+					throw new Error("Expected peek to be done");
+				}
+			`,
+		},
+		{
+			name:    "the peeked row should be counted towards limit once consumed",
+			options: `{ skipFirstLine: true, limit: 1 }`,
+			script: `
+				await parser.peek();
+				const peeked = await parser.peek();
+				const next = await parser.next();
+				const following = await parser.next();
+				if (peeked.done || next.done || !following.done) {
+					throw new Error("Unexpected rows " + JSON.stringify([peeked, next, following]));
+				}
+			`,
+		},
+		{
+			name:    "peeking should not reach toLine before the row is consumed",
+			options: `{ skipFirstLine: true, toLine: 1 }`,
+			script: `
+				const peeked = await parser.peek();
+				const next = await parser.next();
+				if (peeked.value[0] !== "foo" || next.value[0] !== "foo") {
+					throw new Error("Unexpected rows " + JSON.stringify([peeked, next]));
+				}
+
+				const afterPeek = await parser.peek();
+				const afterNext = await parser.next();
+				if (!afterPeek.done || !afterNext.done) {
+					throw new Error("Expected the parser to be done beyond toLine");
+				}
+			`,
+		},
+		{
+			name:    "the transform callback should be called with the line the row is consumed at",
+			options: `{ skipFirstLine: true, transform: (row, line) => row[0] + "@" + line }`,
+			script: `
+				const peeked = await parser.peek();
+				const next = await parser.next();
+				if (peeked.value !== "foo@2" || next.value !== "foo@2") {
+					throw new Error("Unexpected rows " + JSON.stringify([peeked, next]));
+				}
+			`,
+		},
+		{
+			name:    "the peeked row should be the next one accepted by the filter callback",
+			options: `{ skipFirstLine: true, filter: (row) => row[0] !== "foo" }`,
+			script: `
+				const peeked = await parser.peek();
+				const next = await parser.next();
+				if (peeked.value[0] !== "baz" || next.value[0] !== "baz") {
+					throw new Error("Unexpected rows " + JSON.stringify([peeked, next]));
+				}
+			`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+				%s
+			`, testFilePath, tt.options, tt.script)))
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserReset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+	}{
+		{
+			name:    "the rows should be read again from the first data row",
+			options: `{ skipFirstLine: true }`,
+			want:    "foo|baz|quux/foo|baz|quux",
+		},
+		{
+			name:    "the header should be read again",
+			options: `{ header: true, transform: (row) => row.firstname }`,
+			want:    "foo|baz|quux/foo|baz|quux",
+		},
+		{
+			name:    "the rows preceding fromLine should be skipped again",
+			options: `{ skipFirstLine: true, fromLine: 1 }`,
+			want:    "baz|quux/baz|quux",
+		},
+		{
+			name:    "the rows counted towards limit should be reset",
+			options: `{ skipFirstLine: true, limit: 2 }`,
+			want:    "foo|baz/foo|baz",
+		},
+		{
+			name:    "the current line should be reset",
+			options: `{ skipFirstLine: true, transform: (row, line) => row[0] + "@" + line }`,
+			want:    "foo@2|baz@3|quux@4/foo@2|baz@3|quux@4",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				const readAll = async () => {
+					let got = [];
+					let { done, value } = await parser.next();
+					while (!done) {
+						got.push(typeof value === "string" ? value : value[0]);
+						({ done, value } = await parser.next());
+					}
+
+					return got.join("|");
+				};
+
+				const first = await readAll();
+				await parser.reset();
+				const second = await readAll();
+
+				if (first + "/" + second !== %q) {
+					throw new Error("Unexpected records " + first + "/" + second);
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("resetting a parser which source is not seekable should fail", func(t *testing.T) {
+		t.Parallel()
+
+		parser := newParser(strings.NewReader(testCSV), newDefaultParserOptions(), nil, logrus.New())
+
+		err := parser.reset()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the parser's source is not seekable")
+	})
+}
+
+func TestParserErrorDetails(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		csv     string
+		options string
+		want    string
+	}{
+		{
+			name:    "a bare quote should be positioned at its line, column, and field",
+			csv:     "a,b\nfoo,ba\"r\n",
+			options: `{ skipFirstLine: true }`,
+			want:    `{"line":2,"column":7,"field":1,"message":"line 2, column 7: bare \" in non-quoted-field"}`,
+		},
+		{
+			name:    "an extraneous quote should be positioned at its line, column, and field",
+			csv:     "a,b,c\nfoo,bar,\"baz\"qux\n",
+			options: `{ skipFirstLine: true }`,
+			want:    `{"line":2,"column":13,"field":2,"message":"line 2, column 13: extraneous or missing \" in quoted-field"}`,
+		},
+		{
+			name:    "an error of a custom quote should be positioned at its field",
+			csv:     "a,b\nfoo,ba'r\n",
+			options: `{ skipFirstLine: true, quote: "'" }`,
+			want:    `{"line":2,"column":7,"field":1,"message":"line 2, column 7: bare \" in non-quoted-field"}`,
+		},
+		{
+			name:    "a wrong number of fields should be positioned at the record's line only",
+			csv:     "a,b\nfoo,bar\nbaz\n",
+			options: `{ skipFirstLine: true }`,
+			want:    `{"line":3,"column":null,"field":null,"message":"line 3: wrong number of fields"}`,
+		},
+		{
+			name:    "lines should account for the metadata lines",
+			csv:     "# generated\na,b\nfoo,ba\"r\n",
+			options: `{ metadataLines: 1, skipFirstLine: true }`,
+			want:    `{"line":3,"column":7,"field":1,"message":"line 3, column 7: bare \" in non-quoted-field"}`,
+		},
+		{
+			name:    "other errors should only hold a message",
+			csv:     "a,b\nfoo,bar\n",
+			options: `{ skipFirstLine: true, patterns: { 0: "^[0-9]+$" } }`,
+			want:    `{"message":"line 2, column \"0\": value \"foo\" does not match the pattern \"^[0-9]+$\""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, tt.csv))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got;
+				try {
+					while (!(await parser.next()).done) {}
+				} catch (err) {
+					got = JSON.stringify({ line: err.line, column: err.column, field: err.field, message: err.message });
+				}
+
+				if (got !== %q) {
+					throw new Error("Unexpected error " + got);
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserDuplicateHeaders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		csv     string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "a header holding duplicate column names should be rejected by default",
+			csv:     "id,name,id,age,name\n1,foo,2,42,bar\n",
+			options: `{ header: true }`,
+			wantErr: `the header holds duplicate column names: "id" at columns 0, 2; "name" at columns 1, 4`,
+		},
+		{
+			name:    "duplicate column names should map to the last column when allowed",
+			csv:     "id,name,id\n1,foo,2\n",
+			options: `{ header: true, allowDuplicateHeaders: true, transform: (row) => row.id + "|" + row.name }`,
+			want:    "2|foo",
+		},
+		{
+			name:    "a header holding distinct column names should be accepted",
+			csv:     "id,name\n1,foo\n",
+			options: `{ header: true, transform: (row) => row.id + "|" + row.name }`,
+			want:    "1|foo",
+		},
+		{
+			name:    "duplicate names should be accepted in a skipped first line",
+			csv:     "id,id\n1,2\n",
+			options: `{ skipFirstLine: true, transform: (row) => row.join("|") }`,
+			want:    "1|2",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, tt.csv))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				const { value } = await parser.next();
+				if (value !== %q) {
+					throw new Error("Unexpected record " + value);
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserInterruption(t *testing.T) {
+	t.Parallel()
+
+	t.Run("next should reject once the VU's context is done while reading", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		source := &blockingReader{reading: make(chan struct{}), release: make(chan struct{})}
+		defer close(source.release)
+
+		parser := newParser(source, newDefaultParserOptions(), r.VU, logrus.New())
+		require.NoError(t, r.VU.Runtime().Set("parser", parser))
+
+		go func() {
+			<-source.reading
+			r.CancelContext()
+		}()
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(`
+			for (let i = 0; i < 2; i++) {
+				let got;
+				try {
+					await parser.next();
+				} catch (err) {
+					got = err.message;
+				}
+
+				if (!got || !got.includes("reading was interrupted")) {
+					throw new Error("Expected read " + i + " to be interrupted, got " + got);
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("the parser's lock should be released even if the event loop never handles the read", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		parser := newParser(strings.NewReader(testCSV), newDefaultParserOptions(), r.VU, logrus.New())
+
+		// The event loop isn't running, so the read's outcome is never handled.
+		_ = parser.Next()
+
+		assert.Eventually(t, func() bool {
+			// Waits for the record to have been read, before the lock is taken.
+			if parser.currentLine.Load() == 0 || !parser.mu.TryLock() {
+				return false
+			}
+
+			parser.mu.Unlock()
+			return true
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestParserAsObjects(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		csv     string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "records should be objects keyed by the header's column names",
+			csv:     testCSV,
+			options: `{ asObjects: true }`,
+			want:    "foo bar 42|baz qux 43|quux corge 44",
+		},
+		{
+			name:    "fromLine should designate the first data row following the header",
+			csv:     testCSV,
+			options: `{ asObjects: true, fromLine: 2 }`,
+			want:    "quux corge 44",
+		},
+		{
+			name:    "empty column names should be named after their column",
+			csv:     "firstname,,age\nfoo,bar,42\n",
+			options: `{ asObjects: true, transform: (row) => [row.firstname, row._1, row.age] }`,
+			want:    "foo bar 42",
+		},
+		{
+			name:    "duplicate column names should be rejected",
+			csv:     "firstname,firstname,age\nfoo,bar,42\n",
+			options: `{ asObjects: true }`,
+			wantErr: `the header holds duplicate column names: "firstname" at columns 0, 1`,
+		},
+		{
+			name:    "asObjects should conflict with skipFirstLine",
+			csv:     testCSV,
+			options: `{ asObjects: true, skipFirstLine: true }`,
+			wantErr: "the asObjects and skipFirstLine options conflict",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, tt.csv))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new csv.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(Array.isArray(value) ? value.join(" ") : [value.firstname, value.lastname, value.age].join(" "));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected records " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		options string
+		want    string
+	}{
+		{
+			name:    "rows should be encoded with the default delimiter",
+			options: ``,
+			want:    "id,name\n1,\"foo, bar\"\n2,\n",
+		},
+		{
+			name:    "rows should be encoded with the configured delimiter",
+			options: `{ delimiter: ";", useCRLF: true }`,
+			want:    "id;name\r\n1;foo, bar\r\n2;\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const writer = new csv.Writer(%s);
+
+				await writer.writeRow(["id", "name"]);
+				await writer.writeRow([1, "foo, bar"]);
+				await writer.writeRow([2, null]);
+
+				if (writer.toString() !== "") {
+					throw new Error("Expected rows to be buffered until flushed");
+				}
+
+				await writer.flush();
+
+				if (writer.toString() !== %q) {
+					throw new Error("Unexpected content " + JSON.stringify(writer.toString()));
+				}
+			`, tt.options, tt.want)))
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("written content should be parsable", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(`
+			const writer = new csv.Writer({ delimiter: "\t" });
+			await writer.writeRow(["a", "b"]);
+			await writer.writeRow(["multi\nline", "\"quoted\""]);
+			await writer.flush();
+
+			const rows = await new csv.Parser(writer.toString(), { dialect: "tsv" }).readAll();
+			if (JSON.stringify(rows) !== JSON.stringify([["a", "b"], ["multi\nline", "\"quoted\""]])) {
+				throw new Error("Unexpected rows " + JSON.stringify(rows));
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("an invalid delimiter should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`new csv.Writer({ delimiter: ";;" })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "delimiter must be a single character")
+	})
+
+	t.Run("an empty delimiter should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`new csv.Writer({ delimiter: "" })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "delimiter must be a single non-empty character")
+	})
+
+	t.Run("writing to a file should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testCSV))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			new csv.Writer(file);
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fs.File instances are read-only")
+	})
+}
+
+const initGlobals = `
+	globalThis.fs = require("k6/experimental/fs");
+	globalThis.csv = require("k6/experimental/csv");
+`
+
+func newConfiguredRuntime(t testing.TB) (*modulestest.Runtime, error) {
+	runtime := modulestest.NewRuntime(t)
+
+	modules := map[string]interface{}{
+		"k6/experimental/fs":  fs.New(),
+		"k6/experimental/csv": New(),
+	}
+
+	err := runtime.SetupModuleSystem(modules, nil, compiler.New(runtime.VU.InitEnv().Logger))
+	if err != nil {
+		return nil, err
+	}
+
+	// Set up the VU environment with an in-memory filesystem and a CWD of "/".
+	runtime.VU.InitEnvField.FileSystems = map[string]fsext.Fs{
+		"file": fsext.NewMemMapFs(),
+	}
+	runtime.VU.InitEnvField.CWD = &url.URL{Scheme: "file"}
+
+	// Ensure the `fs` and `csv` modules are available in the VU's runtime.
+	_, err = runtime.VU.Runtime().RunString(initGlobals)
+
+	return runtime, err
+}
+
+// writeTestFile writes the provided content to the given path in the runtime's
+// in-memory filesystem.
+func writeTestFile(r *modulestest.Runtime, path, content string) error {
+	return fsext.WriteFile(r.VU.InitEnvField.FileSystems["file"], path, []byte(content), 0o644)
+}
+
+// newTestVUState returns a VU state for the provided VU id, which samples
+// are pushed to the given channel.
+func newTestVUState(r *modulestest.Runtime, vuID uint64, samples chan metrics.SampleContainer) *lib.State {
+	return &lib.State{
+		VUID:       vuID,
+		VUIDGlobal: vuID,
+		Samples:    samples,
+		Tags:       lib.NewVUStateTags(r.VU.InitEnvField.Registry.RootTagSet()),
+	}
+}
+
+// gzipTestData returns the provided content, gzip compressed.
+func gzipTestData(t *testing.T, content string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+
+	_, err := zw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return buf.String()
+}
+
+func zstdTestData(t *testing.T, content string) string {
+	t.Helper()
+
+	zw, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+
+	return string(zw.EncodeAll([]byte(content), nil))
+}
+
+// wrapInAsyncLambda is a helper function that wraps the provided input in an async lambda. This
+// makes the use of `await` statements in the input possible.
+func wrapInAsyncLambda(input string) string {
+	// This makes it possible to use `await` freely on the "top" level
+	return "(async () => {\n " + input + "\n })()"
+}
+
+// blockingReader is an io.Reader which reads block until released.
+type blockingReader struct {
+	// reading is closed once the first read starts.
+	reading chan struct{}
+
+	// release is closed to unblock the reads, which then return io.EOF.
+	release chan struct{}
+
+	once sync.Once
+}
+
+func (br *blockingReader) Read([]byte) (int, error) {
+	br.once.Do(func() { close(br.reading) })
+	<-br.release
+
+	return 0, io.EOF
+}
@@ -0,0 +1,132 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/sobek"
+)
+
+const (
+	// patternMismatchReject makes the parser fail reading records holding
+	// fields that do not match their column's pattern.
+	patternMismatchReject = "reject"
+
+	// patternMismatchWarn makes the parser log a warning, and return records
+	// holding fields that do not match their column's pattern.
+	patternMismatchWarn = "warn"
+)
+
+// columnPattern holds the regular expression a column's fields must match.
+type columnPattern struct {
+	// column holds the name, or index, the column was designated by.
+	column string
+
+	// index holds the column's index within records.
+	index int
+
+	// re holds the compiled pattern.
+	re *regexp.Regexp
+}
+
+// newPatternsFrom compiles the patterns held by the provided Sobek object,
+// keyed by column.
+func newPatternsFrom(rt *sobek.Runtime, v sobek.Value) (map[string]*regexp.Regexp, error) {
+	obj := v.ToObject(rt)
+
+	patterns := make(map[string]*regexp.Regexp, len(obj.Keys()))
+	for _, column := range obj.Keys() {
+		re, err := regexp.Compile(obj.Get(column).String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for column %q; reason: %w", column, err)
+		}
+
+		patterns[column] = re
+	}
+
+	return patterns, nil
+}
+
+// resolvePatterns resolves the columns the provided patterns are keyed by to
+// their index within records.
+//
+// Columns are designated by name when the parser holds a header, and by index
+// otherwise.
+func (p *Parser) resolvePatterns(patterns map[string]*regexp.Regexp) ([]columnPattern, error) {
+	resolved := make([]columnPattern, 0, len(patterns))
+	for column, re := range patterns {
+		index, err := p.resolveColumn(column)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved = append(resolved, columnPattern{column: column, index: index, re: re})
+	}
+
+	// Validate fields in a deterministic order, so that errors are reproducible.
+	sort.Slice(resolved, func(i, j int) bool {
+		return resolved[i].index < resolved[j].index
+	})
+
+	return resolved, nil
+}
+
+// resolveColumn returns the index of the column designated by the provided
+// key, which is either a name found in the parser's header, or an index.
+func (p *Parser) resolveColumn(column string) (int, error) {
+	for i, h := range p.header {
+		if h == column {
+			return i, nil
+		}
+	}
+
+	index, err := strconv.Atoi(column)
+	if err != nil {
+		if p.header == nil {
+			return 0, errors.New("columns can only be designated by name when the header or skipFirstLine option is set")
+		}
+
+		return 0, fmt.Errorf("no column named %q", column)
+	}
+
+	if index < 0 {
+		return 0, fmt.Errorf("column index %d cannot be negative", index)
+	}
+
+	return index, nil
+}
+
+// validatePatterns checks the provided record's fields against their column's
+// pattern.
+//
+// Depending on the `onPatternMismatch` option, a mismatch either results in an
+// error, or in a warning being logged.
+func (p *Parser) validatePatterns(records []string) error {
+	for _, cp := range p.patterns {
+		var field string
+		if cp.index < len(records) {
+			field = records[cp.index]
+		}
+
+		if cp.index < len(records) && cp.re.MatchString(field) {
+			continue
+		}
+
+		err := fmt.Errorf(
+			"line %d, column %q: value %q does not match the pattern %q",
+			p.currentLine.Load(), cp.column, field, cp.re.String(),
+		)
+
+		if p.options.OnPatternMismatch == patternMismatchWarn {
+			p.logger.Warn(err.Error())
+			continue
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package csv
+
+import (
+	"errors"
+	"io"
+
+	"github.com/grafana/sobek"
+)
+
+// pendingRecord holds a record that was read ahead of being consumed, along
+// with the parser's state right after it was read.
+type pendingRecord struct {
+	records []string
+
+	// line holds the parser's current line once the record is consumed.
+	line int64
+
+	// offset holds the parser's offset once the record is consumed.
+	offset int64
+
+	// raw holds the text the record was parsed from, when the `includeRaw`
+	// option is set.
+	raw string
+}
+
+// Peek returns a promise resolving to the next row of the CSV file, as
+// [Parser.Next] would, without consuming it: the following call to
+// [Parser.Next] returns the same row.
+//
+// The parser's current line and offset are left unchanged until the row is
+// consumed, and the row only counts towards the `limit` option then. When the
+// `filter` or `transform` option is set, its callback is called again as the
+// row is consumed.
+func (p *Parser) Peek() *sobek.Promise {
+	if p.options.Filter != nil || p.options.Transform != nil {
+		return p.peekFiltered()
+	}
+
+	type peekedRecords struct {
+		records []string
+		cursor  cursor
+		raw     string
+	}
+
+	read := func() (peekedRecords, error) {
+		records, err := p.next()
+		if err != nil {
+			return peekedRecords{}, err
+		}
+
+		// The cursor is the one right after the record, as returned by Next.
+		peeked := peekedRecords{records: p.detach(records), cursor: p.cursor(), raw: p.raw}
+		p.unread(records)
+
+		return peeked, nil
+	}
+
+	return readAsync(p, read, func(pr peekedRecords) any {
+		if !p.options.IncludeCursor && !p.options.IncludeRaw {
+			return parseResult{Done: false, Value: p.toValue(pr.records)}
+		}
+
+		return p.detailedResult(p.toValue(pr.records), pr.cursor, pr.raw)
+	})
+}
+
+// peekFiltered returns a promise resolving to the next record for which the
+// `filter` option's callback returns true, as mapped by the `transform` option's
+// callback, without consuming it.
+//
+// Records rejected by the `filter` option's callback are consumed.
+func (p *Parser) peekFiltered() *sobek.Promise {
+	promise, resolve, reject := p.vu.Runtime().NewPromise()
+
+	p.nextMatching(p.matchesFilter, func(record matchedRecord, value sobek.Value, err error) {
+		if err == nil {
+			value, err = p.transform(value, record.cursor.Line)
+		}
+
+		switch {
+		case errors.Is(err, io.EOF):
+			resolve(parseResult{Done: true, Value: []string{}})
+			return
+		case err != nil:
+			reject(p.rejectionReason(err))
+			return
+		}
+
+		p.unreadAsync(record, func(err error) {
+			switch {
+			case err != nil:
+				reject(p.rejectionReason(err))
+			case p.options.IncludeCursor || p.options.IncludeRaw:
+				resolve(p.detailedResult(value, record.cursor, record.raw))
+			default:
+				resolve(parseResult{Done: false, Value: value})
+			}
+		})
+	})
+
+	return promise
+}
+
+// unread holds the provided record, which was just returned by [Parser.next],
+// so that it is returned by the next read instead, and restores the parser's
+// current line and offset to the ones preceding it.
+//
+// The record is thus counted towards the `limit` option once consumed.
+func (p *Parser) unread(records []string) {
+	p.pending = &pendingRecord{
+		// The records might be held by the parser's record buffer, which is reused.
+		records: append([]string(nil), records...),
+		line:    p.currentLine.Load(),
+		offset:  p.offset.Load(),
+		raw:     p.raw,
+	}
+
+	p.currentLine.Store(p.before.Line)
+	p.offset.Store(p.before.ByteOffset)
+	p.returned--
+}
+
+// unreadAsync unreads the provided record, which was just returned by
+// [Parser.nextMatching], in a background goroutine holding the parser's lock,
+// and then calls done from the event loop.
+func (p *Parser) unreadAsync(record matchedRecord, done func(error)) {
+	runLocked(p,
+		func() (struct{}, error) {
+			p.unread(record.records)
+			return struct{}{}, nil
+		},
+		func(_ struct{}, err error) {
+			done(err)
+		},
+	)
+}
+
+// consumePending returns the record read ahead, and updates the parser's
+// current line and offset to the ones following it.
+func (p *Parser) consumePending() []string {
+	pending := p.pending
+	p.pending = nil
+
+	p.currentLine.Store(pending.line)
+	p.offset.Store(pending.offset)
+	p.raw = pending.raw
+
+	return pending.records
+}
+
+// consumedCursor returns the position right after the last record consumed
+// by the parser, which precedes the record read ahead, if any.
+func (p *Parser) consumedCursor() cursor {
+	if p.pending == nil {
+		return p.cursor()
+	}
+
+	return cursor{Line: p.currentLine.Load(), ByteOffset: p.offset.Load()}
+}
@@ -0,0 +1,35 @@
+package csv
+
+import "go.k6.io/k6/js/modules/k6/experimental/fs"
+
+// Offset returns the offset, in bytes, within the file of the end of the last
+// line read by the parser, including the lines it skipped.
+//
+// As opposed to the position of the underlying file, which the csv reader
+// buffers ahead of, the offset is accounted for from the records actually
+// read. Along with [Parser.Size], it allows to report the parser's progress.
+// The offset is updated once a read completes, and thus does not reflect the
+// reads still in progress.
+func (p *Parser) Offset() int64 {
+	return p.offset.Load()
+}
+
+// Size returns the size, in bytes, of the file the parser reads from.
+//
+// When the `encoding` option is set, both the size and the offset refer to the
+// content decoded to UTF-8.
+func (p *Parser) Size() int64 {
+	return p.size
+}
+
+// size returns the size of the source.
+func (s source) size() int64 {
+	switch r := s.ReadSeeker.(type) {
+	case fs.ReadSeekStater:
+		return r.Stat().Size
+	case interface{ Size() int64 }:
+		return r.Size()
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,83 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/sobek"
+)
+
+// newColumnsFrom reads the columns designated by the provided Sobek array, as
+// either names or indices.
+func newColumnsFrom(rt *sobek.Runtime, v sobek.Value) ([]string, error) {
+	var values []sobek.Value
+	if err := rt.ExportTo(v, &values); err != nil {
+		return nil, errors.New("columns must be an array of column names or indices")
+	}
+
+	if len(values) == 0 {
+		return nil, errors.New("columns must designate at least one column")
+	}
+
+	columns := make([]string, 0, len(values))
+	for _, value := range values {
+		columns = append(columns, value.String())
+	}
+
+	return columns, nil
+}
+
+// resolveProjection resolves the provided columns, designated by name or index,
+// to their index within the records read from the file.
+//
+// When the number of columns is known, either from the header or from the
+// `fieldsPerRecord` option, indices are checked against it.
+func (p *Parser) resolveProjection(columns []string) ([]int, error) {
+	width := -1
+	switch {
+	case p.header != nil:
+		width = len(p.header)
+	case p.options.FieldsPerRecord.Valid && p.options.FieldsPerRecord.Int64 > 0:
+		width = int(p.options.FieldsPerRecord.Int64)
+	}
+
+	indices := make([]int, 0, len(columns))
+	for _, column := range columns {
+		index, err := p.resolveColumn(column)
+		if err != nil {
+			return nil, err
+		}
+
+		if width >= 0 && index >= width {
+			return nil, fmt.Errorf("no column at index %d, as records hold %d columns", index, width)
+		}
+
+		indices = append(indices, index)
+	}
+
+	return indices, nil
+}
+
+// project returns the fields of the provided record designated by the
+// `columns` option, in the order they were designated in.
+//
+// Empty records are returned as is, so that they can be skipped.
+func (p *Parser) project(records []string) ([]string, error) {
+	if p.projection == nil || isEmptyRecord(records) {
+		return records, nil
+	}
+
+	projected := make([]string, 0, len(p.projection))
+	for _, index := range p.projection {
+		if index >= len(records) {
+			return nil, fmt.Errorf(
+				"line %d: no column at index %d, as the record holds %d fields",
+				p.currentLine.Load()+1, index, len(records),
+			)
+		}
+
+		projected = append(projected, records[index])
+	}
+
+	return projected, nil
+}
@@ -0,0 +1,323 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultQuote holds the character wrapping quoted fields, unless the `quote`
+// option is set.
+const defaultQuote = '"'
+
+var _ recordReader = (*quoteReader)(nil)
+
+// quoteReader is a [recordReader] wrapping quoted fields with a configurable
+// quote character, as opposed to [csv.Reader], which only supports `"`.
+//
+// It follows the same rules as the standard library's reader: quoted fields
+// can hold delimiters and line terminators, a quote is escaped by doubling it,
+// and empty and comment lines are ignored. Errors are reported as
+// [csv.ParseError]s, holding the same line numbers the standard library's
+// reader would.
+//
+// As the standard library's reader is faster, this one is only used when a
+// quote other than the default one is configured.
+type quoteReader struct {
+	r *bufio.Reader
+
+	// comma holds the fields delimiter.
+	comma rune
+
+	// comment holds the character marking comment lines, if any.
+	comment rune
+
+	// quote holds the character wrapping quoted fields.
+	quote rune
+
+	lazyQuotes       bool
+	trimLeadingSpace bool
+
+	// fieldsPerRecord holds the number of fields each record is expected to
+	// hold, as [csv.Reader.FieldsPerRecord] does.
+	fieldsPerRecord int
+
+	// numLine holds the number of physical lines read.
+	numLine int
+
+	// offset holds the offset of the end of the last line read.
+	offset int64
+
+	// positions holds the position of each field of the last record read.
+	positions []fieldPosition
+}
+
+// fieldPosition holds the one-based line and column a field starts at.
+type fieldPosition struct {
+	line, col int
+}
+
+// newQuoteReader creates a new quoteReader reading from the provided source,
+// and configured with the given options.
+func newQuoteReader(source io.Reader, options parserOptions) *quoteReader {
+	qr := &quoteReader{
+		r:                bufio.NewReader(source),
+		comma:            options.Delimiter,
+		comment:          options.Comment,
+		quote:            options.Quote,
+		lazyQuotes:       options.LazyQuotes,
+		trimLeadingSpace: options.TrimLeadingSpace,
+	}
+
+	if options.FieldsPerRecord.Valid {
+		qr.fieldsPerRecord = int(options.FieldsPerRecord.Int64)
+	}
+
+	return qr
+}
+
+// Read implements the [recordReader] interface.
+func (qr *quoteReader) Read() ([]string, error) {
+	record, err := qr.readRecord()
+	if err != nil {
+		return record, err
+	}
+
+	switch {
+	case qr.fieldsPerRecord > 0 && len(record) != qr.fieldsPerRecord:
+		line := qr.positions[0].line
+		return record, &csv.ParseError{StartLine: line, Line: line, Column: 1, Err: csv.ErrFieldCount}
+	case qr.fieldsPerRecord == 0:
+		qr.fieldsPerRecord = len(record)
+	}
+
+	return record, nil
+}
+
+// InputOffset implements the [recordReader] interface.
+func (qr *quoteReader) InputOffset() int64 {
+	return qr.offset
+}
+
+// FieldPos returns the line and column the provided field of the last record
+// read starts at, as [csv.Reader.FieldPos] does.
+func (qr *quoteReader) FieldPos(field int) (line, column int) {
+	if field < 0 || field >= len(qr.positions) {
+		panic("out of range index passed to FieldPos")
+	}
+
+	return qr.positions[field].line, qr.positions[field].col
+}
+
+// readLine returns the next physical line, with its `\r\n` terminator, if
+// any, normalized to `\n`.
+func (qr *quoteReader) readLine() ([]byte, error) {
+	line, err := qr.r.ReadBytes('\n')
+	if len(line) > 0 && errors.Is(err, io.EOF) {
+		err = nil
+
+		// A trailing carriage return is dropped, as the standard library's
+		// reader does.
+		if line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+	}
+
+	qr.offset += int64(len(line))
+	if len(line) > 0 {
+		qr.numLine++
+	}
+
+	if n := len(line); n >= 2 && line[n-2] == '\r' && line[n-1] == '\n' {
+		line[n-2] = '\n'
+		line = line[:n-1]
+	}
+
+	return line, err
+}
+
+// readRecord reads the fields of the next record, skipping empty and comment
+// lines.
+//
+//nolint:funlen,gocognit,cyclop
+func (qr *quoteReader) readRecord() ([]string, error) {
+	var (
+		line    []byte
+		readErr error
+	)
+	for readErr == nil {
+		line, readErr = qr.readLine()
+		if qr.comment != 0 && nextRune(line) == qr.comment {
+			line = nil
+			continue
+		}
+
+		if readErr == nil && len(line) == lengthNL(line) {
+			line = nil
+			continue
+		}
+
+		break
+	}
+
+	if errors.Is(readErr, io.EOF) {
+		return nil, readErr
+	}
+
+	var (
+		err    error
+		fields []string
+		field  []byte
+
+		recLine  = qr.numLine
+		quoteLen = utf8.RuneLen(qr.quote)
+		commaLen = utf8.RuneLen(qr.comma)
+		pos      = fieldPosition{line: qr.numLine, col: 1}
+	)
+
+	qr.positions = qr.positions[:0]
+
+parseField:
+	for {
+		if qr.trimLeadingSpace {
+			i := bytes.IndexFunc(line, func(r rune) bool { return !unicode.IsSpace(r) })
+			if i < 0 {
+				i = len(line)
+				pos.col -= lengthNL(line)
+			}
+
+			line = line[i:]
+			pos.col += i
+		}
+
+		if len(line) == 0 || nextRune(line) != qr.quote {
+			// The field is not quoted, and thus ends at the next delimiter.
+			i := bytes.IndexRune(line, qr.comma)
+			value := line
+			if i >= 0 {
+				value = value[:i]
+			} else {
+				value = value[:len(value)-lengthNL(value)]
+			}
+
+			if !qr.lazyQuotes {
+				if j := bytes.IndexRune(value, qr.quote); j >= 0 {
+					err = &csv.ParseError{StartLine: recLine, Line: qr.numLine, Column: pos.col + j, Err: csv.ErrBareQuote}
+					break parseField
+				}
+			}
+
+			fields = append(fields, string(value))
+			qr.positions = append(qr.positions, pos)
+
+			if i < 0 {
+				break parseField
+			}
+
+			line = line[i+commaLen:]
+			pos.col += i + commaLen
+			continue parseField
+		}
+
+		// The field is quoted, and thus ends at the quote followed by a
+		// delimiter, or by the end of the line.
+		start := pos
+		line = line[quoteLen:]
+		pos.col += quoteLen
+		field = field[:0]
+
+		for {
+			i := bytes.IndexRune(line, qr.quote)
+
+			switch {
+			case i >= 0:
+				field = append(field, line[:i]...)
+				line = line[i+quoteLen:]
+				pos.col += i + quoteLen
+
+				switch r := nextRune(line); {
+				case len(line) > 0 && r == qr.quote:
+					// A doubled quote is an escaped quote.
+					field = append(field, line[:quoteLen]...)
+					line = line[quoteLen:]
+					pos.col += quoteLen
+				case len(line) > 0 && r == qr.comma:
+					line = line[commaLen:]
+					pos.col += commaLen
+					fields = append(fields, string(field))
+					qr.positions = append(qr.positions, start)
+					continue parseField
+				case lengthNL(line) == len(line):
+					fields = append(fields, string(field))
+					qr.positions = append(qr.positions, start)
+					break parseField
+				case qr.lazyQuotes:
+					field = utf8.AppendRune(field, qr.quote)
+				default:
+					err = &csv.ParseError{StartLine: recLine, Line: qr.numLine, Column: pos.col - quoteLen, Err: csv.ErrQuote}
+					break parseField
+				}
+			case len(line) > 0:
+				// The quoted field spans the following line.
+				field = append(field, line...)
+				if readErr != nil {
+					break parseField
+				}
+
+				pos.col += len(line)
+				line, readErr = qr.readLine()
+				if len(line) > 0 {
+					pos.line++
+					pos.col = 1
+				}
+
+				if errors.Is(readErr, io.EOF) {
+					readErr = nil
+				}
+			default:
+				// The input ended before the quoted field did.
+				if !qr.lazyQuotes && readErr == nil {
+					err = &csv.ParseError{StartLine: recLine, Line: pos.line, Column: pos.col, Err: csv.ErrQuote}
+					break parseField
+				}
+
+				fields = append(fields, string(field))
+				qr.positions = append(qr.positions, start)
+				break parseField
+			}
+		}
+	}
+
+	if err == nil {
+		err = readErr
+	}
+
+	return fields, err
+}
+
+// nextRune returns the first rune of the provided input, or
+// [utf8.RuneError] if it is empty.
+func nextRune(b []byte) rune {
+	r, _ := utf8.DecodeRune(b)
+	return r
+}
+
+// lengthNL returns 1 if the provided input ends with a line feed, and 0
+// otherwise.
+func lengthNL(b []byte) int {
+	if len(b) > 0 && b[len(b)-1] == '\n' {
+		return 1
+	}
+
+	return 0
+}
+
+// validQuote returns true if the provided character can be used to wrap
+// quoted fields.
+func validQuote(r rune) bool {
+	return r != 0 && r != '\r' && r != '\n' && r != utf8.RuneError && utf8.ValidRune(r)
+}
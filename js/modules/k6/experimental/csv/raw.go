@@ -0,0 +1,96 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// rawParseResult holds the result of a CSV parser's parsing operation when the
+// `includeRaw` option is set.
+type rawParseResult struct {
+	parseResult
+
+	// Raw holds the text the record was parsed from.
+	Raw string `js:"raw"`
+}
+
+// rawCursorParseResult holds the result of a CSV parser's parsing operation
+// when both the `includeRaw` and `includeCursor` options are set.
+type rawCursorParseResult struct {
+	cursorParseResult
+
+	// Raw holds the text the record was parsed from.
+	Raw string `js:"raw"`
+}
+
+// detailedResult returns the result of a parsing operation which resolved to
+// the provided value, along with the cursor and raw text the `includeCursor`
+// and `includeRaw` options respectively request.
+func (p *Parser) detailedResult(value any, c cursor, raw string) any {
+	switch {
+	case p.options.IncludeCursor && p.options.IncludeRaw:
+		return rawCursorParseResult{cursorParseResult: cursorParseResult{Value: value, Cursor: c}, Raw: raw}
+	case p.options.IncludeRaw:
+		return rawParseResult{parseResult: parseResult{Value: value}, Raw: raw}
+	default:
+		return cursorParseResult{Value: value, Cursor: c}
+	}
+}
+
+// readRaw returns the text the reader consumed between the provided offsets,
+// relative to where it started reading, as the record it read last.
+//
+// As the reader buffers its input, the source is repositioned where it was
+// once the text has been read. The empty and comment lines the reader skipped
+// before the record, and the record's final line terminator, are left out,
+// whereas the line terminators within quoted fields are kept.
+func (p *Parser) readRaw(start, end int64) (string, error) {
+	if p.source == nil {
+		return "", nil
+	}
+
+	previous, err := p.source.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := p.source.Seek(p.baseOffset+start, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, end-start)
+	_, err = io.ReadFull(p.source, buf)
+
+	if _, serr := p.source.Seek(previous, io.SeekStart); serr != nil {
+		return "", fmt.Errorf("failed to restore the source's position; reason: %w", serr)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(p.trimSkippedLines(buf)), nil
+}
+
+// trimSkippedLines trims the empty and comment lines leading the provided
+// text, and its final line terminator.
+func (p *Parser) trimSkippedLines(text []byte) []byte {
+	for len(text) > 0 {
+		line, rest, found := bytes.Cut(text, []byte("\n"))
+		if !found {
+			break
+		}
+
+		empty := len(bytes.TrimRight(line, "\r")) == 0
+		comment := p.options.Comment != 0 && bytes.HasPrefix(line, []byte(string(p.options.Comment)))
+		if !empty && !comment {
+			break
+		}
+
+		text = rest
+	}
+
+	text = bytes.TrimSuffix(text, []byte("\n"))
+	return bytes.TrimSuffix(text, []byte("\r"))
+}
@@ -0,0 +1,112 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/sobek"
+)
+
+// ReadAll returns a promise resolving to an array holding every remaining record,
+// from the parser's current position to the end of the file, or to the line
+// configured through the `toLine` option.
+//
+// Records are returned as they would be by [Parser.Next], honoring the parser's
+// options, including the `filter` and `transform` callbacks. As every record is
+// held in memory, it is best suited to reasonably sized files.
+//
+// If reading fails partway, the promise is rejected with an error which `rows`
+// property holds the records read so far.
+func (p *Parser) ReadAll() *sobek.Promise {
+	rt := p.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+
+	// As the callbacks have to be called from the event loop, records are handed
+	// over to them one at a time.
+	if p.options.Filter != nil || p.options.Transform != nil {
+		var rows []any
+
+		var settle func(record matchedRecord, value sobek.Value, err error)
+		settle = func(record matchedRecord, value sobek.Value, err error) {
+			if err == nil {
+				value, err = p.transform(value, record.cursor.Line)
+			}
+
+			switch {
+			case errors.Is(err, io.EOF):
+				resolve(rt.NewArray(rows...))
+			case err != nil:
+				reject(p.newReadAllError(err, rows))
+			default:
+				rows = append(rows, value)
+				p.nextMatching(p.matchesFilter, settle)
+			}
+		}
+
+		p.nextMatching(p.matchesFilter, settle)
+
+		return promise
+	}
+
+	callback := p.vu.RegisterCallback()
+
+	go func() {
+		p.mu.Lock()
+		// The lock is released right away, as the event loop might never call
+		// the callback once the VU's context is done.
+		defer p.mu.Unlock()
+
+		var (
+			records [][]string
+			err     error
+		)
+
+		for {
+			// Stop reading as soon as the VU's context is done, such as when the
+			// test is aborted.
+			if err = p.vu.Context().Err(); err != nil {
+				break
+			}
+
+			var record []string
+			record, err = p.next()
+			if err != nil {
+				break
+			}
+
+			// The record might be held by the parser's record buffer, which is reused.
+			records = append(records, append([]string(nil), record...))
+		}
+
+		callback(func() error {
+			rows := make([]any, len(records))
+			for i, record := range records {
+				rows[i] = p.toValue(record)
+			}
+
+			if !errors.Is(err, io.EOF) {
+				reject(p.newReadAllError(err, rows))
+				return nil
+			}
+
+			resolve(rt.NewArray(rows...))
+			return nil
+		})
+	}()
+
+	return promise
+}
+
+// newReadAllError creates a new error, holding the rows read so far by
+// [Parser.ReadAll] in its `rows` property, to aid debugging.
+//
+// It must be called from the event loop.
+func (p *Parser) newReadAllError(err error, rows []any) *sobek.Object {
+	rt := p.vu.Runtime()
+
+	obj := rt.NewGoError(fmt.Errorf("readAll() failed after reading %d rows; reason: %w", len(rows), err))
+	must(rt, obj.Set("rows", rt.NewArray(rows...)))
+
+	return obj
+}
@@ -0,0 +1,203 @@
+package csv
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordReader reads CSV records from an underlying source.
+//
+// It is implemented by [csv.Reader], and by the module's own readers
+// supporting parsing modes the standard library does not.
+type recordReader interface {
+	// Read reads one record from the underlying source.
+	Read() ([]string, error)
+
+	// InputOffset returns the input stream byte offset of the end of the
+	// most recently read record.
+	InputOffset() int64
+}
+
+var (
+	_ recordReader = (*csv.Reader)(nil)
+	_ recordReader = (*bestEffortReader)(nil)
+)
+
+// maxBestEffortRecordLines holds the maximum number of physical lines a
+// [bestEffortReader] attempts to join when looking for the end of a quoted
+// field, before considering its quotes unbalanced.
+const maxBestEffortRecordLines = 64
+
+// bestEffortReader is a [recordReader] recovering from malformed quoting.
+//
+// It prefers quote-aware parsing, supporting quoted fields spanning multiple
+// lines, but falls back to splitting a line on the delimiter when its quotes
+// are unbalanced, emitting a warning. Recovered records might thus not hold
+// the values the file's author intended: this mode is lossy.
+//
+// As it validates records one at a time, the number of fields of each record
+// is not enforced.
+type bestEffortReader struct {
+	r *bufio.Reader
+
+	// comma holds the fields delimiter.
+	comma rune
+
+	// comment holds the character marking comment lines, if any.
+	comment rune
+
+	// offset holds the offset of the end of the last record read.
+	offset int64
+
+	// line holds the number of physical lines consumed.
+	line int64
+
+	// pending holds the lines that were read ahead, but not consumed.
+	pending []string
+
+	logger logrus.FieldLogger
+}
+
+// newBestEffortReader creates a new bestEffortReader reading from the provided source.
+func newBestEffortReader(source io.Reader, comma, comment rune, logger logrus.FieldLogger) *bestEffortReader {
+	return &bestEffortReader{
+		r:       bufio.NewReader(source),
+		comma:   comma,
+		comment: comment,
+		logger:  logger,
+	}
+}
+
+// Read implements the [recordReader] interface.
+func (ber *bestEffortReader) Read() ([]string, error) {
+	// Empty and comment lines are ignored, as the standard library's reader does.
+	var first string
+	for first == "" || isEmptyLine(first) || ber.isComment(first) {
+		if first != "" {
+			ber.consume(first)
+		}
+
+		var err error
+		first, err = ber.readLine()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lines := []string{first}
+	for {
+		buf := strings.Join(lines, "")
+
+		record, err := ber.parse(buf, false)
+		if err == nil {
+			for _, line := range lines {
+				ber.consume(line)
+			}
+
+			return record, nil
+		}
+
+		// A quoted field might span multiple lines, in which case we attempt
+		// to find its end in the following lines.
+		if !errors.Is(err, csv.ErrQuote) || strings.Count(buf, `"`)%2 == 0 || len(lines) >= maxBestEffortRecordLines {
+			break
+		}
+
+		next, err := ber.readLine()
+		if err != nil {
+			break
+		}
+
+		lines = append(lines, next)
+	}
+
+	// We failed to parse a well-formed record, and thus recover by only considering
+	// the first line. The lines read ahead are kept for the following reads.
+	ber.pending = append(lines[1:], ber.pending...)
+	ber.consume(first)
+
+	// Bare quotes are kept as is, whereas a line holding unbalanced quotes is
+	// split on the delimiter.
+	record, err := ber.parse(first, true)
+	if err != nil || strings.Count(first, `"`)%2 != 0 {
+		record = ber.split(first)
+	}
+
+	ber.logger.Warnf("csv parser recovered from malformed quoting on line %d; the record %q might be inaccurate",
+		ber.line, strings.TrimRight(first, "\r\n"))
+
+	return record, nil
+}
+
+// InputOffset implements the [recordReader] interface.
+func (ber *bestEffortReader) InputOffset() int64 {
+	return ber.offset
+}
+
+// readLine returns the next physical line, including its line terminator.
+func (ber *bestEffortReader) readLine() (string, error) {
+	if len(ber.pending) > 0 {
+		line := ber.pending[0]
+		ber.pending = ber.pending[1:]
+		return line, nil
+	}
+
+	line, err := ber.r.ReadString('\n')
+	if errors.Is(err, io.EOF) && line != "" {
+		return line, nil
+	}
+
+	return line, err
+}
+
+// consume accounts for the provided line being consumed.
+func (ber *bestEffortReader) consume(line string) {
+	ber.offset += int64(len(line))
+	ber.line++
+}
+
+// parse parses the provided input as a single CSV record.
+func (ber *bestEffortReader) parse(input string, lazyQuotes bool) ([]string, error) {
+	r := csv.NewReader(strings.NewReader(input))
+	r.Comma = ber.comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = lazyQuotes
+
+	record, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	// The input should hold a single record.
+	if _, err := r.Read(); !errors.Is(err, io.EOF) {
+		return nil, csv.ErrQuote
+	}
+
+	return record, nil
+}
+
+// split splits the provided line on the delimiter, ignoring quoting altogether,
+// and trims the fields' surrounding quotes.
+func (ber *bestEffortReader) split(line string) []string {
+	fields := strings.Split(strings.TrimRight(line, "\r\n"), string(ber.comma))
+	for i, field := range fields {
+		fields[i] = strings.Trim(field, `"`)
+	}
+
+	return fields
+}
+
+// isComment returns true if the provided line is a comment line.
+func (ber *bestEffortReader) isComment(line string) bool {
+	return ber.comment != 0 && strings.HasPrefix(line, string(ber.comment))
+}
+
+// isEmptyLine returns true if the provided line only holds a line terminator.
+func isEmptyLine(line string) bool {
+	return strings.TrimRight(line, "\r\n") == ""
+}
@@ -0,0 +1,111 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/sobek"
+)
+
+// Reset returns a promise resolving once the parser has been rewound to the
+// beginning of the file, as if it was constructed anew with the same options.
+//
+// As opposed to [Parser.Seek], the header, or skipped first line, is read again,
+// before the rows preceding the `fromLine` option are skipped. The parser's
+// current line, and the rows counted towards the `limit` option, are reset.
+// The byte order mark and metadata lines, if any, are not read again.
+//
+// The promise is rejected if the parser's source is not seekable.
+func (p *Parser) Reset() *sobek.Promise {
+	return runAsync(p,
+		func() (any, error) {
+			return nil, p.reset()
+		},
+		func(any) any {
+			return sobek.Undefined()
+		},
+	)
+}
+
+// reset rewinds the parser to the beginning of the file, and primes it again.
+func (p *Parser) reset() error {
+	if p.source == nil {
+		return errors.New("the parser's source is not seekable")
+	}
+
+	if err := p.seek(p.origin); err != nil {
+		return fmt.Errorf("failed to rewind to the beginning of the file; reason: %w", err)
+	}
+
+	p.returned = 0
+	p.cycleRead = false
+	p.rowOffsets = nil
+
+	if p.deduper != nil {
+		p.deduper = newConsecutiveDeduper(p.deduper.column)
+	}
+
+	if err := p.readHeader(); err != nil {
+		return err
+	}
+
+	if p.options.Lazy {
+		p.columns, p.columnNames = newColumnIndex(p.header)
+	}
+
+	p.dataStart = p.cursor()
+
+	return p.skipToFromLine()
+}
+
+// readHeader consumes the first line, if the `header` or `skipFirstLine`
+// option is set, and holds it as the parser's header.
+//
+// In header mode, empty column names are named after their column, and a header
+// holding the same column name several times is rejected, unless the
+// `allowDuplicateHeaders` option is set.
+func (p *Parser) readHeader() error {
+	if !p.options.Header && !p.options.SkipFirstLine {
+		return nil
+	}
+
+	header, err := p.read()
+	if err != nil {
+		return fmt.Errorf("failed to consume the first line; reason: %w", err)
+	}
+
+	header = append([]string(nil), header...)
+	if p.options.Header {
+		nameEmptyHeaders(header)
+	}
+
+	if p.options.Header && !p.options.AllowDuplicateHeaders {
+		if err := checkDuplicateHeaders(header); err != nil {
+			return err
+		}
+	}
+
+	p.header = header
+	p.advanceLine()
+
+	return nil
+}
+
+// skipToFromLine skips the data rows preceding the one designated by the
+// `fromLine` option, and marks the parser's position as its first data line.
+func (p *Parser) skipToFromLine() error {
+	if p.options.FromLine.Valid && p.options.FromLine.Int64 > 0 {
+		for i := int64(0); i < p.options.FromLine.Int64; i++ {
+			if _, err := p.read(); err != nil {
+				return fmt.Errorf("failed to skip rows until fromLine; reason: %w", err)
+			}
+
+			p.advanceLine()
+		}
+	}
+
+	p.start = p.cursor()
+	p.offset.Store(p.start.ByteOffset)
+
+	return nil
+}
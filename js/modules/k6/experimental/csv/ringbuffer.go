@@ -0,0 +1,177 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+)
+
+// errRingBufferClosed is returned when pushing records to a ring buffer which
+// producer has already reached the end of its file.
+var errRingBufferClosed = errors.New("the shared ring buffer is closed")
+
+// ringBufferOptions holds the options of the ring buffer a parser, configured
+// through the `ringBuffer` option, shares with the other VUs.
+type ringBufferOptions struct {
+	// Size holds the maximum number of records the ring buffer can hold.
+	Size int64 `js:"size"`
+}
+
+// ringBuffer is a bounded buffer of records, shared by the parsers of every VU
+// reading from the same file.
+//
+// It implements a work-queue: records pushed by producers are taken, in order,
+// by consumers, and each record is taken exactly once. Pushing to a full
+// buffer blocks until a consumer takes a record, and taking from an empty
+// buffer blocks until a producer pushes one, or closes the buffer.
+//
+// Once closed, the records the buffer still holds can be taken, after which
+// takes return [io.EOF].
+type ringBuffer struct {
+	records chan []string
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// newRingBuffer creates a new ringBuffer holding up to size records.
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{
+		records: make(chan []string, size),
+		closed:  make(chan struct{}),
+	}
+}
+
+// push pushes the provided record to the buffer, blocking until there is room
+// for it, the buffer is closed, or the context is done.
+func (rb *ringBuffer) push(ctx context.Context, record []string) error {
+	// A record pushed once the buffer is closed would otherwise never be taken.
+	select {
+	case <-rb.closed:
+		return errRingBufferClosed
+	default:
+	}
+
+	select {
+	case rb.records <- record:
+		return nil
+	case <-rb.closed:
+		return errRingBufferClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// take takes the oldest record from the buffer, blocking until one is available,
+// the buffer is closed, or the context is done.
+//
+// It returns [io.EOF] once the buffer is closed, and every record it held has
+// been taken.
+func (rb *ringBuffer) take(ctx context.Context) ([]string, error) {
+	select {
+	case record := <-rb.records:
+		return record, nil
+	case <-rb.closed:
+		select {
+		case record := <-rb.records:
+			return record, nil
+		default:
+			return nil, io.EOF
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// close closes the buffer, signaling consumers that no more records will be pushed.
+//
+// It is safe to call close multiple times.
+func (rb *ringBuffer) close() {
+	rb.closeOnce.Do(func() {
+		close(rb.closed)
+	})
+}
+
+// ringBufferRegistry holds the ring buffers shared by the VUs of a test run,
+// keyed by the path of the file they are filled from.
+type ringBufferRegistry struct {
+	mu      sync.Mutex
+	buffers map[string]*ringBuffer
+}
+
+// newRingBufferRegistry creates a new, empty, ringBufferRegistry.
+func newRingBufferRegistry() *ringBufferRegistry {
+	return &ringBufferRegistry{buffers: make(map[string]*ringBuffer)}
+}
+
+// get returns the ring buffer associated with the provided path, creating it
+// with the given size if it does not exist yet.
+//
+// An error is returned if the existing buffer was created with a different size.
+func (rbr *ringBufferRegistry) get(path string, size int) (*ringBuffer, error) {
+	rbr.mu.Lock()
+	defer rbr.mu.Unlock()
+
+	if rb, ok := rbr.buffers[path]; ok {
+		if cap(rb.records) != size {
+			return nil, fmt.Errorf(
+				"the ring buffer shared for %q already exists with a size of %d", path, cap(rb.records),
+			)
+		}
+
+		return rb, nil
+	}
+
+	rb := newRingBuffer(size)
+	rbr.buffers[path] = rb
+
+	return rb, nil
+}
+
+// TakeShared returns a promise resolving to the oldest record of the ring
+// buffer shared by the parsers of every VU reading from the same file, as
+// configured through the `ringBuffer` option.
+//
+// Records are pushed to the shared buffer by the parsers reading them through
+// [Parser.Next], and are taken exactly once, across VUs. The promise resolves
+// once a record is available, or to a result which `done` property is set to
+// true once the producing parser has reached the end of its file and the buffer
+// has been drained.
+//
+// A given parser is expected to be used either as a producer, or as a consumer.
+func (p *Parser) TakeShared() *sobek.Promise {
+	if p.ring == nil {
+		common.Throw(p.vu.Runtime(), errors.New("takeShared() requires the ringBuffer option to be set"))
+	}
+
+	promise, resolve, reject := p.vu.Runtime().NewPromise()
+	callback := p.vu.RegisterCallback()
+	ctx := p.vu.Context()
+
+	go func() {
+		record, err := p.ring.take(ctx)
+
+		callback(func() error {
+			if errors.Is(err, io.EOF) {
+				resolve(parseResult{Done: true, Value: []string{}})
+				return nil
+			}
+
+			if err != nil {
+				reject(err)
+				return nil
+			}
+
+			resolve(parseResult{Done: false, Value: record})
+			return nil
+		})
+	}()
+
+	return promise
+}
@@ -0,0 +1,128 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingBufferConcurrentProducerConsumers(t *testing.T) {
+	t.Parallel()
+
+	const (
+		records   = 1000
+		consumers = 8
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rb := newRingBuffer(4)
+
+	go func() {
+		defer rb.close()
+
+		for i := 0; i < records; i++ {
+			if err := rb.push(ctx, []string{strconv.Itoa(i)}); err != nil {
+				t.Errorf("unexpected push error: %v", err)
+				return
+			}
+		}
+	}()
+
+	var (
+		mu    sync.Mutex
+		taken = make(map[string]int, records)
+		wg    sync.WaitGroup
+	)
+
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				record, err := rb.take(ctx)
+				if errors.Is(err, io.EOF) {
+					return
+				}
+
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				mu.Lock()
+				taken[record[0]]++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// Every record should have been taken exactly once.
+	require.Len(t, taken, records)
+	for record, count := range taken {
+		assert.Equal(t, 1, count, "record %s was taken %d times", record, count)
+	}
+}
+
+func TestRingBufferClosed(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rb := newRingBuffer(2)
+
+	require.NoError(t, rb.push(ctx, []string{"foo"}))
+	rb.close()
+	rb.close()
+
+	assert.ErrorIs(t, rb.push(ctx, []string{"bar"}), errRingBufferClosed)
+
+	// The records held by the buffer should still be taken once it is closed.
+	record, err := rb.take(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, record)
+
+	_, err = rb.take(ctx)
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestRingBufferContextDone(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rb := newRingBuffer(1)
+
+	_, err := rb.take(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	require.NoError(t, rb.push(context.Background(), []string{"foo"}))
+	assert.ErrorIs(t, rb.push(ctx, []string{"bar"}), context.Canceled)
+}
+
+func TestRingBufferRegistry(t *testing.T) {
+	t.Parallel()
+
+	rbr := newRingBufferRegistry()
+
+	rb, err := rbr.get("/data.csv", 2)
+	require.NoError(t, err)
+
+	same, err := rbr.get("/data.csv", 2)
+	require.NoError(t, err)
+	assert.Same(t, rb, same)
+
+	_, err = rbr.get("/data.csv", 3)
+	assert.Error(t, err)
+}
@@ -0,0 +1,171 @@
+package csv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/sobek"
+)
+
+const (
+	// fieldTypeString leaves fields as strings.
+	fieldTypeString = "string"
+
+	// fieldTypeNumber coerces fields to numbers.
+	fieldTypeNumber = "number"
+
+	// fieldTypeBoolean coerces fields to booleans.
+	fieldTypeBoolean = "boolean"
+
+	// fieldTypeDate coerces fields to dates, from either a number of seconds
+	// elapsed since the Unix epoch, or an RFC 3339 formatted date.
+	fieldTypeDate = "date"
+
+	// fieldTypeInteger coerces fields to integral numbers.
+	fieldTypeInteger = "int"
+)
+
+// fieldTypeAliases maps the alternative names of the types fields can be
+// coerced to, as accepted by the `columnTypes` option, to the type they name.
+var fieldTypeAliases = map[string]string{
+	"float": fieldTypeNumber,
+	"bool":  fieldTypeBoolean,
+}
+
+const (
+	// typeModeStrict fails the reads of records which fields cannot be coerced
+	// to their column's type.
+	typeModeStrict = "strict"
+
+	// typeModeLenient leaves the fields which cannot be coerced to their
+	// column's type as strings.
+	typeModeLenient = "lenient"
+)
+
+// columnType holds the type a column's fields are coerced to, as set through the
+// `schema` option.
+type columnType struct {
+	// column holds the name, or index, the column was designated by.
+	column string
+
+	// kind holds the type the column's fields are coerced to.
+	kind string
+}
+
+// newSchemaFrom reads the types held by the provided Sobek object, keyed by column.
+func newSchemaFrom(rt *sobek.Runtime, v sobek.Value) (map[string]string, error) {
+	obj := v.ToObject(rt)
+
+	schema := make(map[string]string, len(obj.Keys()))
+	for _, column := range obj.Keys() {
+		kind := obj.Get(column).String()
+		if alias, ok := fieldTypeAliases[kind]; ok {
+			kind = alias
+		}
+
+		switch kind {
+		case fieldTypeString, fieldTypeNumber, fieldTypeInteger, fieldTypeBoolean, fieldTypeDate:
+			schema[column] = kind
+		default:
+			return nil, fmt.Errorf(
+				"invalid type %q for column %q; expected one of %q, %q, %q, %q or %q",
+				obj.Get(column).String(), column,
+				fieldTypeString, fieldTypeNumber, fieldTypeInteger, fieldTypeBoolean, fieldTypeDate,
+			)
+		}
+	}
+
+	return schema, nil
+}
+
+// resolveSchema resolves the columns the provided schema is keyed by to their
+// index within records.
+func (p *Parser) resolveSchema(schema map[string]string) (map[int]columnType, error) {
+	resolved := make(map[int]columnType, len(schema))
+	for column, kind := range schema {
+		index, err := p.resolveColumn(column)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved[index] = columnType{column: column, kind: kind}
+	}
+
+	return resolved, nil
+}
+
+// validateSchema checks that the provided record's fields can be coerced to
+// their column's type, unless the `typeMode` option is lenient.
+func (p *Parser) validateSchema(records []string) error {
+	if p.options.TypeMode == typeModeLenient {
+		return nil
+	}
+
+	for i, field := range records {
+		ct, ok := p.schema[i]
+		if !ok {
+			continue
+		}
+
+		if _, err := coerceField(ct.kind, field); err != nil {
+			article := "a"
+			if ct.kind == fieldTypeInteger {
+				article = "an"
+			}
+
+			return fmt.Errorf(
+				"line %d, column %q: unable to coerce value %q to %s %s",
+				p.currentLine.Load(), ct.column, field, article, ct.kind,
+			)
+		}
+	}
+
+	return nil
+}
+
+// coerceField coerces the provided raw field to the given type.
+func coerceField(kind string, raw string) (any, error) {
+	switch kind {
+	case fieldTypeNumber:
+		return strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	case fieldTypeInteger:
+		return strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	case fieldTypeBoolean:
+		return strconv.ParseBool(strings.TrimSpace(raw))
+	case fieldTypeDate:
+		return parseTimestamp(strings.TrimSpace(raw))
+	default:
+		return raw, nil
+	}
+}
+
+// coerceValue converts the provided raw field, at the given column index, into a
+// runtime value of the column's type.
+//
+// The field is expected to have been validated by [Parser.validateSchema], or is
+// left as a string if it cannot be coerced, as the `typeMode` option allows when
+// lenient. It must be called from the event loop.
+func (p *Parser) coerceValue(index int, raw string) sobek.Value {
+	rt := p.vu.Runtime()
+
+	ct, ok := p.schema[index]
+	if !ok {
+		return rt.ToValue(raw)
+	}
+
+	value, err := coerceField(ct.kind, raw)
+	if err != nil {
+		return rt.ToValue(raw)
+	}
+
+	if timestamp, ok := value.(time.Time); ok {
+		date, err := rt.New(rt.Get("Date"), rt.ToValue(timestamp.UnixMilli()))
+		must(rt, err)
+
+		return date
+	}
+
+	return rt.ToValue(value)
+}
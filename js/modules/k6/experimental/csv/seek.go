@@ -0,0 +1,85 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+)
+
+// Seek returns a promise resolving once the parser has been repositioned at the
+// provided line, so that the following call to [Parser.Next] returns the record
+// read from the line after it.
+//
+// Lines are counted as the parser's current line is: the header, or the skipped
+// first line, and the lines skipped through the `fromLine` option are accounted
+// for, and the parser cannot be positioned before them. The parser rewinds to
+// its first data line, and reads and discards lines until the requested one is
+// reached. As the csv reader buffers its input, it is recreated from the
+// rewound position; the parser's lock is held throughout, so that no other read
+// is interleaved.
+//
+// The promise is rejected if the requested line is beyond the end of the file,
+// in which case the parser is left at its previous position.
+func (p *Parser) Seek(lineArg sobek.Value) *sobek.Promise {
+	if common.IsNullish(lineArg) {
+		promise, _, reject := p.vu.Runtime().NewPromise()
+		reject(errors.New("seek() takes a line number argument"))
+		return promise
+	}
+
+	line := lineArg.ToInteger()
+
+	return runAsync(p,
+		func() (any, error) {
+			if line < p.start.Line {
+				return nil, fmt.Errorf("cannot seek to line %d, before the first data line %d", line, p.start.Line)
+			}
+
+			previous := p.consumedCursor()
+
+			err := p.seekLine(line)
+			if err == nil {
+				return nil, nil
+			}
+
+			if rerr := p.seek(previous); rerr != nil {
+				return nil, fmt.Errorf("failed to restore the parser's position; reason: %w", rerr)
+			}
+
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("cannot seek to line %d, beyond the end of the file", line)
+			}
+
+			return nil, err
+		},
+		func(any) any {
+			return sobek.Undefined()
+		},
+	)
+}
+
+// seekLine rewinds the parser to its first data line, and discards lines until
+// the provided one is reached.
+func (p *Parser) seekLine(line int64) error {
+	if err := p.seek(p.start); err != nil {
+		return err
+	}
+
+	for p.currentLine.Load() < line {
+		_, err := p.read()
+
+		// Lines are discarded as they are, regardless of their number of fields.
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return err
+		}
+
+		p.advanceLine()
+	}
+
+	return nil
+}
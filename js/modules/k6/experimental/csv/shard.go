@@ -0,0 +1,94 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
+)
+
+// shardOptions designates the partition of a file's rows a parser returns, as
+// set through the `shard` option, or resolved through the `partitionByVU` one.
+//
+// Rows are assigned to partitions in a round-robin fashion, by the line they
+// end on: the row ending on line L belongs to the partition of index L % Total.
+// Partitions are thus disjoint, and together cover every row of the file.
+type shardOptions struct {
+	// Index holds the zero-based index of the partition.
+	Index int64 `js:"index"`
+
+	// Total holds the number of partitions.
+	Total int64 `js:"total"`
+}
+
+// inShard returns true if the record the parser has just read belongs to its
+// partition, if any.
+//
+// When the `partitionByVU` option is set, the partition is resolved once the
+// first record is read, as the VU's identity is only known once the test runs.
+func (p *Parser) inShard() (bool, error) {
+	if p.shard == nil {
+		if !p.options.PartitionByVU {
+			return true, nil
+		}
+
+		shard, err := vuShard(p.vu)
+		if err != nil {
+			return false, err
+		}
+
+		p.shard = &shard
+	}
+
+	return p.currentLine.Load()%p.shard.Total == p.shard.Index, nil
+}
+
+// vuShard resolves the partition of the provided VU, among the maximum number
+// of VUs the test can run.
+//
+// The number of partitions is derived from the test's execution plan, and is
+// thus fixed for the duration of the test: as VUs ramp up or down, rows are not
+// reassigned, and the rows of a VU that is not running are not read.
+//
+// In distributed tests, each instance only runs the VUs of its execution
+// segment. Partitions are thus resolved from the VUs' global identifiers, among
+// the VUs of the whole test, rather than of the instance's segment, so that the
+// instances' partitions do not overlap.
+func vuShard(vu modules.VU) (shardOptions, error) {
+	state := vu.State()
+	if state == nil {
+		return shardOptions{}, errors.New("partitionByVU requires records to be read by a VU, once the test has started")
+	}
+
+	es := lib.GetExecutionState(vu.Context())
+	if es == nil {
+		return shardOptions{}, errors.New("partitionByVU requires the test's execution state to be available")
+	}
+
+	// The tuple spanning the whole test's execution.
+	et, err := lib.NewExecutionTuple(nil, nil)
+	if err != nil {
+		return shardOptions{}, err
+	}
+
+	total := lib.GetMaxPossibleVUs(es.Test.Options.Scenarios.GetFullExecutionRequirements(et))
+	if state.VUIDGlobal < 1 || state.VUIDGlobal > total {
+		return shardOptions{}, fmt.Errorf("the VU id %d is outside of the test's %d possible VUs", state.VUIDGlobal, total)
+	}
+
+	return shardOptions{Index: int64(state.VUIDGlobal - 1), Total: int64(total)}, nil
+}
+
+// newShardOptions validates the provided partition.
+func newShardOptions(index, total int64) (*shardOptions, error) {
+	if total < 1 {
+		return nil, errors.New("shard requires a total greater than 0")
+	}
+
+	if index < 0 || index >= total {
+		return nil, fmt.Errorf("shard requires an index between 0 and %d", total-1)
+	}
+
+	return &shardOptions{Index: index, Total: total}, nil
+}
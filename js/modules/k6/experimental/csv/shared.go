@@ -0,0 +1,208 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+// sharedRows holds the rows of a file parsed once, and shared by every VU.
+//
+// It is immutable once parsed, and can thus be read concurrently.
+type sharedRows struct {
+	// header holds the file's header, when the `header` option is set.
+	header []string
+
+	// rows holds the file's records.
+	rows [][]string
+}
+
+// sharedRowsEntry holds the rows parsed for a given file and set of options.
+type sharedRowsEntry struct {
+	// mu ensures the file is only parsed by a single VU, while the others wait.
+	mu sync.Mutex
+
+	// rows holds the parsed rows, once parsed.
+	rows *sharedRows
+}
+
+// sharedRowsRegistry holds the rows shared by the VUs of a test run, keyed by
+// the path of the file they were parsed from, and the options they were parsed
+// with.
+type sharedRowsRegistry struct {
+	mu      sync.Mutex
+	entries map[string]*sharedRowsEntry
+}
+
+// newSharedRowsRegistry creates a new, empty, sharedRowsRegistry.
+func newSharedRowsRegistry() *sharedRowsRegistry {
+	return &sharedRowsRegistry{entries: make(map[string]*sharedRowsEntry)}
+}
+
+// get returns the rows associated with the provided key, parsing them with the
+// given function if no VU did yet.
+//
+// If parsing fails, the next call attempts to parse the rows again.
+func (srr *sharedRowsRegistry) get(key string, parse func() *sharedRows) *sharedRows {
+	srr.mu.Lock()
+	entry, ok := srr.entries[key]
+	if !ok {
+		entry = &sharedRowsEntry{}
+		srr.entries[key] = entry
+	}
+	srr.mu.Unlock()
+
+	// The lock is released even if parsing throws.
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.rows == nil {
+		entry.rows = parse()
+	}
+
+	return entry.rows
+}
+
+// SharedRows gives a VU read-only access to the rows of a file parsed through
+// [ModuleInstance.Parse].
+type SharedRows struct {
+	// Length holds the number of shared rows.
+	Length int `js:"length"`
+
+	rows *sharedRows
+
+	vu modules.VU
+}
+
+// Parse parses the provided file once, for every VU, and returns a handle
+// giving read-only access to its rows, through its `get(index)` method and
+// `length` property.
+//
+// The file is parsed as a [Parser] would, with the same options. Rows are held
+// in memory once, as opposed to once per VU, and are shared by the VUs parsing
+// the same file with the same options. Options are told apart by their JSON
+// representation, which callbacks, such as `filter`, are absent from.
+//
+// It must be called in the init context.
+func (mi *ModuleInstance) Parse(call sobek.FunctionCall) sobek.Value {
+	rt := mi.vu.Runtime()
+
+	if mi.vu.State() != nil {
+		common.Throw(rt, errors.New("csv.parse() must be called in the init context"))
+	}
+
+	if common.IsNullish(call.Argument(0)) {
+		common.Throw(rt, errors.New("csv.parse() takes a non-nil fs.File argument"))
+	}
+
+	src, err := newSourceFrom(rt, call.Argument(0))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	if !src.isFile() {
+		common.Throw(rt, errors.New("csv.parse() requires a fs.File, in order to share its rows across VUs"))
+	}
+
+	key := src.path + "\x00" + stringify(rt, call.Argument(1))
+
+	rows := mi.sharedRows.get(key, func() *sharedRows {
+		return mi.parseShared(call)
+	})
+
+	return rt.ToValue(&SharedRows{Length: len(rows.rows), rows: rows, vu: mi.vu})
+}
+
+// parseShared reads every row of the file through a [Parser] constructed from
+// the provided call's arguments.
+func (mi *ModuleInstance) parseShared(call sobek.FunctionCall) *sharedRows {
+	rt := mi.vu.Runtime()
+
+	parser, ok := mi.NewParser(sobek.ConstructorCall{Arguments: call.Arguments}).Export().(*Parser)
+	if !ok {
+		common.Throw(rt, errors.New("csv.parse() failed to instantiate a parser"))
+	}
+
+	parser.mu.Lock()
+	defer parser.mu.Unlock()
+
+	rows := &sharedRows{}
+	if parser.options.Header {
+		rows.header = parser.header
+	}
+
+	for {
+		record, err := parser.next()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("csv.parse() failed after reading %d rows; reason: %w", len(rows.rows), err))
+		}
+
+		// The record might be held by the parser's record buffer, which is reused.
+		rows.rows = append(rows.rows, append([]string(nil), record...))
+	}
+}
+
+// Get returns the row at the provided index, or undefined if it is out of
+// range.
+//
+// Rows are returned as objects keyed by the header's column names when the
+// `header` option is set, and as arrays of strings otherwise. Each call
+// returns a copy, so that the shared rows cannot be modified.
+func (sr *SharedRows) Get(index int64) sobek.Value {
+	rt := sr.vu.Runtime()
+
+	if index < 0 || index >= int64(len(sr.rows.rows)) {
+		return sobek.Undefined()
+	}
+
+	row := sr.rows.rows[index]
+	if sr.rows.header == nil {
+		values := make([]any, len(row))
+		for i, field := range row {
+			values[i] = field
+		}
+
+		return rt.NewArray(values...)
+	}
+
+	obj := rt.NewObject()
+	for i, name := range sr.rows.header {
+		var field string
+		if i < len(row) {
+			field = row[i]
+		}
+
+		must(rt, obj.Set(name, field))
+	}
+
+	return obj
+}
+
+// stringify returns the JSON representation of the provided value.
+func stringify(rt *sobek.Runtime, v sobek.Value) string {
+	if common.IsNullish(v) {
+		return ""
+	}
+
+	fn, ok := sobek.AssertFunction(rt.Get("JSON").ToObject(rt).Get("stringify"))
+	if !ok {
+		return ""
+	}
+
+	s, err := fn(sobek.Undefined(), v)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	return s.String()
+}
@@ -0,0 +1,49 @@
+package csv
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedRowsRegistry(t *testing.T) {
+	t.Parallel()
+
+	const vus = 16
+
+	srr := newSharedRowsRegistry()
+
+	var (
+		parsed atomic.Int64
+		wg     sync.WaitGroup
+	)
+
+	got := make([]*sharedRows, vus)
+	for i := 0; i < vus; i++ {
+		i := i
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			got[i] = srr.get("data.csv", func() *sharedRows {
+				parsed.Add(1)
+				return &sharedRows{rows: [][]string{{"foo", "bar"}}}
+			})
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int64(1), parsed.Load(), "the rows should be parsed exactly once")
+	for _, rows := range got {
+		assert.Same(t, got[0], rows)
+	}
+
+	other := srr.get("other.csv", func() *sharedRows {
+		return &sharedRows{}
+	})
+	assert.NotSame(t, got[0], other)
+}
@@ -0,0 +1,66 @@
+package csv
+
+import (
+	"errors"
+	"io"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+)
+
+// SkipUntil returns a promise resolving to the number of records discarded
+// while advancing the parser until the provided predicate returns true for
+// a record's fields.
+//
+// The matching record is not discarded, and is returned by the following call
+// to [Parser.Next]. If no record matches, the parser is left at the end of the
+// file.
+func (p *Parser) SkipUntil(predicate sobek.Value) *sobek.Promise {
+	rt := p.vu.Runtime()
+
+	fn, ok := sobek.AssertFunction(predicate)
+	if !ok {
+		common.Throw(rt, errors.New("skipUntil() takes a predicate function argument"))
+	}
+
+	promise, resolve, reject := rt.NewPromise()
+
+	var skipped int64
+	accept := func(_ matchedRecord, value sobek.Value) (bool, error) {
+		matched, err := fn(sobek.Undefined(), value)
+		if err != nil {
+			return false, err
+		}
+
+		if !matched.ToBoolean() {
+			skipped++
+			return false, nil
+		}
+
+		return true, nil
+	}
+
+	p.nextMatching(accept, func(record matchedRecord, _ sobek.Value, err error) {
+		if errors.Is(err, io.EOF) {
+			resolve(skipped)
+			return
+		}
+
+		if err != nil {
+			reject(p.rejectionReason(err))
+			return
+		}
+
+		p.unreadAsync(record, func(err error) {
+			if err != nil {
+				reject(p.rejectionReason(err))
+				return
+			}
+
+			resolve(skipped)
+		})
+	})
+
+	return promise
+}
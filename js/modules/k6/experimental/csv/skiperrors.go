@@ -0,0 +1,72 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+)
+
+const (
+	// onErrorAbort makes the parser fail reading records that cannot be parsed.
+	onErrorAbort = "abort"
+
+	// onErrorSkip makes the parser skip the records that cannot be parsed,
+	// as the `skipErrors` option does.
+	onErrorSkip = "skip"
+)
+
+// parseOnError returns whether the records that cannot be parsed should be
+// skipped, according to the provided `onError` policy.
+func parseOnError(policy string) (bool, error) {
+	switch policy {
+	case onErrorSkip:
+		return true, nil
+	case onErrorAbort:
+		return false, nil
+	default:
+		return false, fmt.Errorf("onError must be either %q or %q", onErrorSkip, onErrorAbort)
+	}
+}
+
+// recordError describes a record that was skipped, as it could not be parsed,
+// when the `skipErrors` option is set.
+type recordError struct {
+	// Line holds the line the error occurred on.
+	Line int64 `js:"line"`
+
+	// Message describes the error.
+	Message string `js:"message"`
+}
+
+// Errors returns the errors encountered while parsing the records that were
+// skipped, in order, when the `skipErrors` option is set.
+func (p *Parser) Errors() []recordError {
+	p.errorsMu.Lock()
+	defer p.errorsMu.Unlock()
+
+	return append([]recordError{}, p.recordErrors...)
+}
+
+// skipError records the provided error, and accounts for the lines the
+// offending record spanned, if it is a parse error.
+//
+// It returns false if the error is not a parse error, and thus should not be
+// skipped.
+func (p *Parser) skipError(err error) bool {
+	var pe *csv.ParseError
+	if !errors.As(err, &pe) {
+		return false
+	}
+
+	// The reader reports lines relative to where it started reading.
+	line := p.readerLine + int64(pe.Line)
+	p.currentLine.Store(line)
+	p.offset.Store(p.baseOffset + p.reader.InputOffset())
+
+	p.errorsMu.Lock()
+	defer p.errorsMu.Unlock()
+
+	p.recordErrors = append(p.recordErrors, recordError{Line: line, Message: pe.Err.Error()})
+
+	return true
+}
@@ -0,0 +1,89 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/modules/k6/experimental/fs"
+)
+
+// source describes the input a [Parser] reads from.
+type source struct {
+	io.ReadSeeker
+
+	// path holds the path of the file the source reads from, if it is a file.
+	path string
+
+	// decompressed indicates whether the source reads the decompressed
+	// content of a compressed file.
+	decompressed bool
+}
+
+// newSourceFrom creates a new source from the provided Sobek value, which is
+// expected to be either a [fs.File], a string, an ArrayBuffer or a Uint8Array.
+//
+// In-memory sources are copied, so that modifying them once the parser has been
+// instantiated has no effect on it.
+func newSourceFrom(rt *sobek.Runtime, v sobek.Value) (source, error) {
+	switch data := v.Export().(type) {
+	case string:
+		return source{ReadSeeker: strings.NewReader(data)}, nil
+	case sobek.ArrayBuffer:
+		return source{ReadSeeker: bytes.NewReader(bytes.Clone(data.Bytes()))}, nil
+	case []byte:
+		return source{ReadSeeker: bytes.NewReader(bytes.Clone(data))}, nil
+	}
+
+	var file *fs.File
+	if err := rt.ExportTo(v, &file); err != nil || file == nil || file.Impl == nil {
+		return source{}, fmt.Errorf(
+			"first argument expected to be a fs.File instance, a string, an ArrayBuffer or a Uint8Array, got %T instead", v,
+		)
+	}
+
+	return source{ReadSeeker: file.Impl, path: file.Path, decompressed: file.Compression != ""}, nil
+}
+
+// isFile returns true if the source reads from a file.
+func (s source) isFile() bool {
+	return s.path != ""
+}
+
+// utf8BOM is the byte order mark files encoded in UTF-8 might start with.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF} //nolint:gochecknoglobals
+
+// skipBOM advances the source past a leading UTF-8 byte order mark, if any, and
+// returns the number of bytes skipped.
+func (s source) skipBOM() (int64, error) {
+	hasBOM, err := s.hasPrefix(utf8BOM)
+	if err != nil || !hasBOM {
+		return 0, err
+	}
+
+	if _, err := s.Seek(int64(len(utf8BOM)), io.SeekCurrent); err != nil {
+		return 0, err
+	}
+
+	return int64(len(utf8BOM)), nil
+}
+
+// hasPrefix returns true if the source starts with the provided prefix, leaving
+// its position unchanged.
+func (s source) hasPrefix(prefix []byte) (bool, error) {
+	buf := make([]byte, len(prefix))
+	n, err := io.ReadFull(s, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, err
+	}
+
+	if _, err := s.Seek(int64(-n), io.SeekCurrent); err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(buf[:n], prefix), nil
+}
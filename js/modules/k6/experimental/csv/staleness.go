@@ -0,0 +1,68 @@
+package csv
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// stalenessFilter decides whether records are too old to be returned, based
+// on the timestamp held by one of their columns.
+type stalenessFilter struct {
+	// column holds the index of the column holding the records' timestamp.
+	column int
+
+	// maxAge holds the maximum age a record can have to be returned.
+	maxAge time.Duration
+
+	// reference holds the point in time records' age is computed against.
+	reference time.Time
+}
+
+// newStalenessFilter creates a new stalenessFilter from the provided options,
+// using reference as the point in time records' age is computed against.
+//
+// It returns nil if the options do not configure a maximum age.
+func newStalenessFilter(options parserOptions, reference time.Time) *stalenessFilter {
+	if !options.MaxAgeSeconds.Valid {
+		return nil
+	}
+
+	return &stalenessFilter{
+		column:    int(options.TimestampColumn.Int64),
+		maxAge:    time.Duration(options.MaxAgeSeconds.Int64) * time.Second,
+		reference: reference,
+	}
+}
+
+// isStale returns true if the provided record's timestamp is older than the
+// filter's maximum age.
+func (sf *stalenessFilter) isStale(record []string) (bool, error) {
+	if sf.column >= len(record) {
+		return false, fmt.Errorf("timestamp column %d is out of range for a record of %d fields", sf.column, len(record))
+	}
+
+	timestamp, err := parseTimestamp(record[sf.column])
+	if err != nil {
+		return false, err
+	}
+
+	return sf.reference.Sub(timestamp) > sf.maxAge, nil
+}
+
+// parseTimestamp parses the provided value either as a number of seconds
+// elapsed since the Unix epoch, or as an RFC 3339 formatted date.
+func parseTimestamp(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(
+			"unable to parse timestamp %q; expected either a Unix timestamp in seconds or an RFC 3339 date", value,
+		)
+	}
+
+	return timestamp, nil
+}
@@ -0,0 +1,94 @@
+package csv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestStalenessFilter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	options := newDefaultParserOptions()
+	options.TimestampColumn = null.IntFrom(1)
+	options.MaxAgeSeconds = null.IntFrom(60)
+
+	filter := newStalenessFilter(options, now)
+	require.NotNil(t, filter)
+
+	tests := []struct {
+		name      string
+		record    []string
+		wantStale bool
+		wantErr   bool
+	}{
+		{
+			name:      "fresh RFC 3339 timestamp",
+			record:    []string{"foo", "2024-06-01T11:59:30Z"},
+			wantStale: false,
+		},
+		{
+			name:      "stale RFC 3339 timestamp",
+			record:    []string{"foo", "2024-06-01T11:58:59Z"},
+			wantStale: true,
+		},
+		{
+			name:      "timestamp exactly at the maximum age",
+			record:    []string{"foo", "2024-06-01T11:59:00Z"},
+			wantStale: false,
+		},
+		{
+			name:      "fresh Unix timestamp",
+			record:    []string{"foo", "1717243190"}, // 2024-06-01T11:59:50Z
+			wantStale: false,
+		},
+		{
+			name:      "stale Unix timestamp",
+			record:    []string{"foo", "1717242000"}, // 2024-06-01T11:40:00Z
+			wantStale: true,
+		},
+		{
+			name:      "timestamp in the future",
+			record:    []string{"foo", "2024-06-01T13:00:00Z"},
+			wantStale: false,
+		},
+		{
+			name:    "invalid timestamp",
+			record:  []string{"foo", "yesterday"},
+			wantErr: true,
+		},
+		{
+			name:    "missing timestamp column",
+			record:  []string{"foo"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotStale, err := filter.isStale(tt.record)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStale, gotStale)
+		})
+	}
+}
+
+func TestNewStalenessFilter(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, newStalenessFilter(newDefaultParserOptions(), time.Now()))
+}
@@ -0,0 +1,45 @@
+package csv
+
+import (
+	"fmt"
+
+	"github.com/grafana/sobek"
+)
+
+// matchesFilter returns true if the provided record is accepted by the `filter`
+// option's callback, or if no such callback is set.
+//
+// It must be called from the event loop.
+func (p *Parser) matchesFilter(record matchedRecord, value sobek.Value) (bool, error) {
+	if p.options.Filter == nil {
+		return true, nil
+	}
+
+	return p.filter(record, value)
+}
+
+// transform calls the `transform` option's callback, if set, with the provided
+// record's value, and the line it was read from, and returns the value it
+// returns in place of the record's.
+//
+// As the callback calls into the runtime, it has to be called from the event
+// loop: records are read in a background goroutine, and handed over to the
+// callback through [Parser.nextMatching], once accepted by the `filter` option's
+// callback, if any, along with the line they were read from.
+//
+// If the callback throws, the returned error holds the line the record was
+// read from.
+func (p *Parser) transform(value sobek.Value, line int64) (sobek.Value, error) {
+	if p.options.Transform == nil {
+		return value, nil
+	}
+
+	transformed, err := p.options.Transform(sobek.Undefined(), value, p.vu.Runtime().ToValue(line))
+	if err != nil {
+		// The exception is not wrapped, so that promises are rejected with an
+		// error holding the line, rather than with the thrown value.
+		return nil, fmt.Errorf("line %d: the transform callback failed; reason: %s", line, err.Error())
+	}
+
+	return transformed, nil
+}
@@ -0,0 +1,63 @@
+package csv
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// ellipsis is appended to the fields truncated by [Parser.boundFields] when
+// the `ellipsis` option is set.
+const ellipsis = "…"
+
+// boundFields enforces the `maxFieldLength` option on the provided record's
+// fields, which length is counted in characters.
+//
+// When the `truncateFields` option is set, overlong fields are truncated in
+// place, ending with an ellipsis if the `ellipsis` option is set, so that they
+// hold exactly maxFieldLength characters. Otherwise, an error is returned.
+func (p *Parser) boundFields(records []string) error {
+	if !p.options.MaxFieldLength.Valid {
+		return nil
+	}
+
+	maxLength := int(p.options.MaxFieldLength.Int64)
+	for i, field := range records {
+		if utf8.RuneCountInString(field) <= maxLength {
+			continue
+		}
+
+		if !p.options.TruncateFields {
+			return fmt.Errorf(
+				"line %d: field %d exceeds the maximum field length of %d characters",
+				p.currentLine.Load(), i, maxLength,
+			)
+		}
+
+		records[i] = truncate(field, maxLength, p.options.Ellipsis)
+	}
+
+	return nil
+}
+
+// truncate truncates the provided value to length characters, the last of which
+// being an ellipsis when withEllipsis is true.
+func truncate(value string, length int, withEllipsis bool) string {
+	if withEllipsis && length > 0 {
+		length--
+	}
+
+	runes := 0
+	for i := range value {
+		if runes == length {
+			if withEllipsis {
+				return value[:i] + ellipsis
+			}
+
+			return value[:i]
+		}
+
+		runes++
+	}
+
+	return value
+}
@@ -0,0 +1,94 @@
+package csv
+
+import (
+	"errors"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+)
+
+// Windows returns an async iterator yielding arrays of `size` consecutive records,
+// advancing by `step` records between each window.
+//
+// When `step` is lower than `size`, consecutive windows overlap. It defaults to 1.
+// Trailing records that are not enough to fill a whole window are not yielded.
+func (p *Parser) Windows(size sobek.Value, step sobek.Value) *sobek.Object {
+	rt := p.vu.Runtime()
+
+	if common.IsNullish(size) || size.ToInteger() < 1 {
+		common.Throw(rt, errors.New("windows() takes a size argument greater than 0"))
+	}
+
+	wi := &windowIterator{
+		parser: p,
+		size:   int(size.ToInteger()),
+		step:   1,
+	}
+
+	if !common.IsNullish(step) {
+		if step.ToInteger() < 1 {
+			common.Throw(rt, errors.New("windows() takes a step argument greater than 0"))
+		}
+
+		wi.step = int(step.ToInteger())
+	}
+
+	return common.NewAsyncIterator(rt, func() *sobek.Promise {
+		return readAsync(p, wi.next, func(window [][]string) any {
+			return parseResult{Done: false, Value: window}
+		})
+	})
+}
+
+// windowIterator yields fixed-size windows of consecutive records read by a [Parser].
+type windowIterator struct {
+	parser *Parser
+
+	// size holds the number of records in each window.
+	size int
+
+	// step holds the number of records windows advance by.
+	step int
+
+	// window holds the records of the window being built.
+	window [][]string
+
+	// skip holds the number of records to discard before building the next
+	// window, when the step is greater than the window's size.
+	skip int
+}
+
+// next returns the next window of records.
+//
+// It must be called while holding the parser's lock.
+func (wi *windowIterator) next() ([][]string, error) {
+	for ; wi.skip > 0; wi.skip-- {
+		if _, err := wi.parser.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	for len(wi.window) < wi.size {
+		records, err := wi.parser.next()
+		if err != nil {
+			return nil, err
+		}
+
+		// Records are copied, as they might be held by the parser's reusable
+		// record buffer.
+		wi.window = append(wi.window, append([]string(nil), records...))
+	}
+
+	window := make([][]string, wi.size)
+	copy(window, wi.window)
+
+	if wi.step >= wi.size {
+		wi.skip = wi.step - wi.size
+		wi.window = wi.window[:0]
+	} else {
+		wi.window = append(wi.window[:0], wi.window[wi.step:]...)
+	}
+
+	return window, nil
+}
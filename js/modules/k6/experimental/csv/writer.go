@@ -0,0 +1,140 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/modules/k6/experimental/fs"
+)
+
+// Writer is a CSV writer, encoding rows into an in-memory buffer which content
+// can be read back as a string.
+type Writer struct {
+	// writer is the CSV writer encoding rows into the buffer.
+	writer *csv.Writer
+
+	// buffer holds the encoded rows, once flushed.
+	buffer *bytes.Buffer
+
+	// vu is the VU instance that owns this module instance.
+	vu modules.VU
+}
+
+// writerOptions holds options used to configure CSV encoding.
+type writerOptions struct {
+	// Delimiter is the character that separates the fields in the CSV.
+	Delimiter rune `js:"delimiter"`
+
+	// UseCRLF indicates whether lines should be terminated by \r\n instead of \n.
+	UseCRLF bool `js:"useCRLF"`
+}
+
+// NewWriter creates a new CSV writer instance.
+//
+// The writer encodes rows into an in-memory buffer. As the fs module only gives
+// read access to files, a [fs.File] cannot be used as the writer's destination.
+func (mi *ModuleInstance) NewWriter(call sobek.ConstructorCall) *sobek.Object {
+	rt := mi.vu.Runtime()
+
+	optionsArg := call.Argument(0)
+	if isFile(rt, optionsArg) {
+		common.Throw(rt, errors.New(
+			"csv Writer only supports writing to an in-memory buffer, as fs.File instances are read-only",
+		))
+	}
+
+	options := writerOptions{Delimiter: ','}
+	if !common.IsNullish(optionsArg) {
+		obj := optionsArg.ToObject(rt)
+
+		if v := obj.Get("delimiter"); !common.IsNullish(v) {
+			delimiter, err := parseDelimiter(v)
+			if err != nil {
+				common.Throw(rt, fmt.Errorf("encountered an error while interpreting Writer options; reason: %w", err))
+			}
+
+			options.Delimiter = delimiter
+		}
+
+		if v := obj.Get("useCRLF"); !common.IsNullish(v) {
+			options.UseCRLF = v.ToBoolean()
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	w := csv.NewWriter(buffer)
+	w.Comma = options.Delimiter
+	w.UseCRLF = options.UseCRLF
+
+	return rt.ToValue(&Writer{writer: w, buffer: buffer, vu: mi.vu}).ToObject(rt)
+}
+
+// isFile returns true if the provided value is a [fs.File].
+func isFile(rt *sobek.Runtime, v sobek.Value) bool {
+	var file *fs.File
+	return !common.IsNullish(v) && rt.ExportTo(v, &file) == nil && file != nil && file.Impl != nil
+}
+
+// WriteRow returns a promise resolving once the provided fields have been
+// encoded as a row.
+//
+// Fields are converted to strings. Rows are buffered, and only reflected in the
+// writer's content once flushed.
+func (w *Writer) WriteRow(fields sobek.Value) *sobek.Promise {
+	rt := w.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+
+	if common.IsNullish(fields) {
+		reject(errors.New("writeRow() takes a non-nil fields argument"))
+		return promise
+	}
+
+	var values []sobek.Value
+	if err := rt.ExportTo(fields, &values); err != nil {
+		reject(fmt.Errorf("writeRow() takes an array of fields; reason: %w", err))
+		return promise
+	}
+
+	row := make([]string, len(values))
+	for i, value := range values {
+		if !common.IsNullish(value) {
+			row[i] = value.String()
+		}
+	}
+
+	if err := w.writer.Write(row); err != nil {
+		reject(err)
+		return promise
+	}
+
+	resolve(sobek.Undefined())
+
+	return promise
+}
+
+// Flush returns a promise resolving once the buffered rows have been written
+// to the writer's content.
+func (w *Writer) Flush() *sobek.Promise {
+	promise, resolve, reject := w.vu.Runtime().NewPromise()
+
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		reject(err)
+		return promise
+	}
+
+	resolve(sobek.Undefined())
+
+	return promise
+}
+
+// ToString returns the content of the writer, holding the rows flushed so far.
+func (w *Writer) ToString() string {
+	return w.buffer.String()
+}
@@ -0,0 +1,21 @@
+package dns
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics for the dns module.
+type instanceMetrics struct {
+	// LookupDuration measures the time the explicit lookups took.
+	LookupDuration *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.LookupDuration, err = registry.NewMetric("dns_lookup_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
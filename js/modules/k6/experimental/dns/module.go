@@ -0,0 +1,103 @@
+// Package dns provides a k6 module for explicit DNS lookups, and for
+// overriding the addresses hostnames resolve to while the test is running, e.g.
+// to switch the VUs between blue/green or canary deployments.
+package dns
+
+import (
+	"errors"
+	"fmt"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/types"
+)
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the dns module for a single VU.
+	ModuleInstance struct {
+		vu      modules.VU
+		metrics *instanceMetrics
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	metrics, err := registerMetrics(vu.InitEnv().Registry)
+	if err != nil {
+		common.Throw(vu.Runtime(), fmt.Errorf("failed to register DNS module metrics: %w", err))
+	}
+
+	return &ModuleInstance{vu: vu, metrics: metrics}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"resolve":    mi.resolve,
+			"setHost":    mi.setHost,
+			"removeHost": mi.removeHost,
+		},
+	}
+}
+
+// setHost makes the VU's connections to the hostname go to the address, which
+// is an IP with an optional port, until it's removed.
+func (mi *ModuleInstance) setHost(hostname, address string) {
+	rt := mi.vu.Runtime()
+	dialer := mi.getDialer("setHost")
+	if hostname == "" {
+		common.Throw(rt, errors.New("setHost requires a hostname"))
+	}
+
+	remote, err := parseAddress(address)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid address of the %q host: %w", hostname, err))
+	}
+	dialer.SetHostOverride(hostname, remote)
+}
+
+// removeHost removes the override of the hostname set by setHost, and returns
+// whether it existed.
+func (mi *ModuleInstance) removeHost(hostname string) bool {
+	return mi.getDialer("removeHost").RemoveHostOverride(hostname)
+}
+
+func (mi *ModuleInstance) getDialer(method string) *netext.Dialer {
+	rt := mi.vu.Runtime()
+	state := mi.vu.State()
+	if state == nil {
+		common.Throw(rt, common.NewInitContextError(
+			fmt.Sprintf("calling %s in the init context is not supported", method)))
+	}
+
+	dialer, ok := state.Dialer.(*netext.Dialer)
+	if !ok {
+		common.Throw(rt, fmt.Errorf("%s isn't supported by the VU's dialer", method))
+	}
+	return dialer
+}
+
+// parseAddress parses an IP, with an optional port, like the hosts option.
+func parseAddress(address string) (types.Host, error) {
+	var remote types.Host
+	err := remote.UnmarshalText([]byte(address))
+	return remote, err
+}
@@ -0,0 +1,147 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/metrics"
+)
+
+type testState struct {
+	*modulestest.Runtime
+	dialer  *netext.Dialer
+	samples chan metrics.SampleContainer
+}
+
+func newTestState(t testing.TB) testState {
+	t.Helper()
+
+	testRuntime := modulestest.NewRuntime(t)
+	samples := make(chan metrics.SampleContainer, 1000)
+	dialer := netext.NewDialer(net.Dialer{}, netext.NewResolver(net.LookupIP, 0, 0, 0))
+
+	registry := metrics.NewRegistry()
+	state := &lib.State{
+		Dialer:         dialer,
+		Samples:        samples,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	}
+
+	m, ok := New().NewModuleInstance(testRuntime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, testRuntime.VU.RuntimeField.Set("dns", m.Exports().Named))
+	testRuntime.MoveToVUContext(state)
+
+	return testState{
+		Runtime: testRuntime,
+		dialer:  dialer,
+		samples: samples,
+	}
+}
+
+func TestSetHost(t *testing.T) {
+	t.Parallel()
+	ts := newTestState(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	_, err = ts.VU.Runtime().RunString(`dns.setHost("test.k6.local", "` + listener.Addr().String() + `")`)
+	require.NoError(t, err)
+
+	conn, err := ts.dialer.DialContext(context.Background(), "tcp", "test.k6.local:1")
+	require.NoError(t, err)
+	_ = conn.Close()
+
+	v, err := ts.VU.Runtime().RunString(`dns.removeHost("test.k6.local")`)
+	require.NoError(t, err)
+	assert.True(t, v.ToBoolean())
+	v, err = ts.VU.Runtime().RunString(`dns.removeHost("test.k6.local")`)
+	require.NoError(t, err)
+	assert.False(t, v.ToBoolean())
+
+	_, err = ts.VU.Runtime().RunString(`dns.setHost("test.k6.local", "not-an-ip")`)
+	require.ErrorContains(t, err, `invalid address of the "test.k6.local" host`)
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+	ts := newTestState(t)
+
+	_, err := ts.RunOnEventLoop(`
+		dns.resolve("localhost", { type: "A", timeout: "2s" }).then((ips) => {
+			if (!ips.includes("127.0.0.1")) {
+				throw new Error("unexpected IPs: " + ips);
+			}
+		});
+	`)
+	require.NoError(t, err)
+
+	var durations []metrics.Sample
+	for _, container := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range container.GetSamples() {
+			if sample.Metric.Name == "dns_lookup_duration" {
+				durations = append(durations, sample)
+			}
+		}
+	}
+	require.Len(t, durations, 1)
+	host, _ := durations[0].Tags.Get("host")
+	recordType, _ := durations[0].Tags.Get("type")
+	assert.Equal(t, "localhost", host)
+	assert.Equal(t, "A", recordType)
+}
+
+func TestResolveErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		options, err string
+	}{
+		"type":       {`{ type: "MX" }`, `unsupported record type "MX"`},
+		"nameserver": {`{ nameserver: "dns.local" }`, "invalid nameserver"},
+		"timeout":    {`{ timeout: -1 }`, "the timeout should be positive"},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ts := newTestState(t)
+
+			_, err := ts.RunOnEventLoop(`
+				dns.resolve("localhost", ` + tc.options + `).then(
+					() => { throw new Error("unexpected success"); },
+					(err) => {
+						if (!err.toString().includes(` + "`" + tc.err + "`" + `)) {
+							throw new Error("unexpected error: " + err);
+						}
+					},
+				);
+			`)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestInitContext(t *testing.T) {
+	t.Parallel()
+
+	runtime := modulestest.NewRuntime(t)
+	m, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.RuntimeField.Set("dns", m.Exports().Named))
+
+	_, err := runtime.VU.Runtime().RunString(`dns.setHost("test.k6.local", "127.0.0.1")`)
+	require.ErrorContains(t, err, "calling setHost in the init context is not supported")
+	_, err = runtime.VU.Runtime().RunString(`dns.removeHost("test.k6.local")`)
+	require.ErrorContains(t, err, "calling removeHost in the init context is not supported")
+}
@@ -0,0 +1,148 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/promises"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+const defaultLookupTimeout = 5 * time.Second
+
+// lookupParams are the parsed options of resolve().
+type lookupParams struct {
+	// network is the network of the IPs to look up: "ip4", "ip6" or "ip".
+	network    string
+	recordType string
+	nameserver string
+	timeout    time.Duration
+}
+
+// resolve returns a promise that resolves to the IPs of the hostname.
+//
+// The options can hold the `type` of the records to look up, either "A" or
+// "AAAA" (both by default), the `nameserver` to query, an IP with an optional
+// port, instead of the system's one, and a `timeout` for the lookup.
+//
+// The duration of the lookup is measured by the dns_lookup_duration metric.
+func (mi *ModuleInstance) resolve(hostname string, options sobek.Value) *sobek.Promise {
+	promise, resolve, reject := promises.New(mi.vu)
+
+	state := mi.vu.State()
+	if state == nil {
+		reject(errors.New("resolve() failed; reason: resolving hostnames in the init context is not supported"))
+		return promise
+	}
+
+	params, err := parseLookupParams(mi.vu.Runtime(), options)
+	if err != nil {
+		reject(fmt.Errorf("resolve() failed; reason: %w", err))
+		return promise
+	}
+
+	tagsAndMeta := state.Tags.GetCurrentValues()
+	tagsAndMeta.SetTag("host", hostname)
+	tagsAndMeta.SetTag("type", params.recordType)
+	if params.nameserver != "" {
+		tagsAndMeta.SetTag("nameserver", params.nameserver)
+	}
+
+	ctx := mi.vu.Context()
+	go func() {
+		start := time.Now()
+		ips, err := lookup(ctx, hostname, params)
+		end := time.Now()
+		if err != nil {
+			reject(fmt.Errorf("resolve() failed; reason: %w", err))
+			return
+		}
+
+		metrics.PushIfNotDone(ctx, state.Samples, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: mi.metrics.LookupDuration,
+				Tags:   tagsAndMeta.Tags,
+			},
+			Time:     end,
+			Metadata: tagsAndMeta.Metadata,
+			Value:    metrics.D(end.Sub(start)),
+		})
+
+		result := make([]string, len(ips))
+		for i, ip := range ips {
+			result[i] = ip.String()
+		}
+		resolve(result)
+	}()
+
+	return promise
+}
+
+func lookup(ctx context.Context, hostname string, params lookupParams) ([]net.IP, error) {
+	resolver := net.DefaultResolver
+	if params.nameserver != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, params.nameserver)
+			},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, params.timeout)
+	defer cancel()
+	return resolver.LookupIP(ctx, params.network, hostname)
+}
+
+func parseLookupParams(rt *sobek.Runtime, options sobek.Value) (lookupParams, error) {
+	params := lookupParams{network: "ip", recordType: "A,AAAA", timeout: defaultLookupTimeout}
+	if common.IsNullish(options) {
+		return params, nil
+	}
+	opts := options.ToObject(rt)
+
+	if v := opts.Get("type"); !common.IsNullish(v) {
+		params.recordType = strings.ToUpper(v.String())
+		switch params.recordType {
+		case "A":
+			params.network = "ip4"
+		case "AAAA":
+			params.network = "ip6"
+		default:
+			return params, fmt.Errorf("unsupported record type %q, it should be either A or AAAA", v.String())
+		}
+	}
+
+	if v := opts.Get("nameserver"); !common.IsNullish(v) {
+		nameserver, err := parseAddress(v.String())
+		if err != nil {
+			return params, fmt.Errorf("invalid nameserver: %w", err)
+		}
+		if nameserver.Port == 0 {
+			nameserver.Port = 53
+		}
+		params.nameserver = nameserver.String()
+	}
+
+	if v := opts.Get("timeout"); !common.IsNullish(v) {
+		timeout, err := types.GetDurationValue(v.Export())
+		if err != nil {
+			return params, fmt.Errorf("invalid timeout: %w", err)
+		}
+		if timeout <= 0 {
+			return params, errors.New("the timeout should be positive")
+		}
+		params.timeout = timeout
+	}
+
+	return params, nil
+}
@@ -0,0 +1,432 @@
+// Package expect provides a k6 module with typed assertions, which are
+// recorded as checks along with the diagnostics of their failures: what was
+// expected, the actual value and an excerpt of the payload it came from.
+package expect
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the expect module for a single VU.
+	ModuleInstance struct {
+		vu modules.VU
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+const (
+	// maxValueLength is the maximum length of the values in the diagnostics.
+	maxValueLength = 100
+	// maxPayloadLength is the maximum length of the payload excerpts.
+	maxPayloadLength = 200
+)
+
+// ErrExpectInInitContext is returned when expect() is used in the init context.
+//
+//nolint:gochecknoglobals
+var ErrExpectInInitContext = common.NewInitContextError("Using expect() in the init context is not supported")
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"expect": mi.expect,
+		},
+	}
+}
+
+// matcher compares the actual value of an assertion with its expected
+// arguments. It returns an error if the arguments are invalid.
+type matcher func(rt *sobek.Runtime, actual sobek.Value, args []sobek.Value) (bool, error)
+
+//nolint:gochecknoglobals
+var matchers = map[string]matcher{
+	"toBe": func(_ *sobek.Runtime, actual sobek.Value, args []sobek.Value) (bool, error) {
+		return actual.SameAs(arg(args, 0)), nil
+	},
+	"toEqual": func(_ *sobek.Runtime, actual sobek.Value, args []sobek.Value) (bool, error) {
+		return deepEqual(actual, arg(args, 0)), nil
+	},
+	"toBeTruthy": func(_ *sobek.Runtime, actual sobek.Value, _ []sobek.Value) (bool, error) {
+		return actual.ToBoolean(), nil
+	},
+	"toBeFalsy": func(_ *sobek.Runtime, actual sobek.Value, _ []sobek.Value) (bool, error) {
+		return !actual.ToBoolean(), nil
+	},
+	"toBeDefined": func(_ *sobek.Runtime, actual sobek.Value, _ []sobek.Value) (bool, error) {
+		return !sobek.IsUndefined(actual), nil
+	},
+	"toBeUndefined": func(_ *sobek.Runtime, actual sobek.Value, _ []sobek.Value) (bool, error) {
+		return sobek.IsUndefined(actual), nil
+	},
+	"toBeNull": func(_ *sobek.Runtime, actual sobek.Value, _ []sobek.Value) (bool, error) {
+		return sobek.IsNull(actual), nil
+	},
+	"toBeGreaterThan":        compareNumbers(func(a, b float64) bool { return a > b }),
+	"toBeGreaterThanOrEqual": compareNumbers(func(a, b float64) bool { return a >= b }),
+	"toBeLessThan":           compareNumbers(func(a, b float64) bool { return a < b }),
+	"toBeLessThanOrEqual":    compareNumbers(func(a, b float64) bool { return a <= b }),
+	"toContain":              contains,
+	"toMatch":                match,
+	"toHaveProperty":         hasProperty,
+	"toHaveLength": func(rt *sobek.Runtime, actual sobek.Value, args []sobek.Value) (bool, error) {
+		if common.IsNullish(actual) {
+			return false, nil
+		}
+		length := actual.ToObject(rt).Get("length")
+		return length != nil && length.SameAs(rt.ToValue(arg(args, 0).ToInteger())), nil
+	},
+}
+
+// assertion holds the actual value of an expect() call and its options.
+type assertion struct {
+	mi      *ModuleInstance
+	actual  sobek.Value
+	message string
+	payload string
+	tags    sobek.Value
+}
+
+// expect starts an assertion on the value. The options are either the message
+// of the assertion, which is its check's name, or an object with the message,
+// the payload the value came from, an excerpt of which is part of the
+// diagnostics of its failures, and the tags of its sample.
+func (mi *ModuleInstance) expect(actual sobek.Value, options sobek.Value) *sobek.Object {
+	rt := mi.vu.Runtime()
+	if mi.vu.State() == nil {
+		common.Throw(rt, ErrExpectInInitContext)
+	}
+
+	a := &assertion{mi: mi, actual: actual}
+	if actual == nil {
+		a.actual = sobek.Undefined()
+	}
+	switch {
+	case common.IsNullish(options):
+	case isString(options):
+		a.message = options.String()
+	default:
+		obj := options.ToObject(rt)
+		if v := obj.Get("message"); !common.IsNullish(v) {
+			a.message = v.String()
+		}
+		if v := obj.Get("payload"); !common.IsNullish(v) {
+			a.payload = truncate(v.String(), maxPayloadLength)
+		}
+		a.tags = obj.Get("tags")
+	}
+
+	return a.object(false)
+}
+
+// object returns the object with the matchers of the assertion, and its
+// negated ones as the not property.
+func (a *assertion) object(negated bool) *sobek.Object {
+	rt := a.mi.vu.Runtime()
+	obj := rt.NewObject()
+	for name, m := range matchers {
+		name, m := name, m
+		must(rt, obj.Set(name, func(call sobek.FunctionCall) sobek.Value {
+			return rt.ToValue(a.assert(name, m, negated, call.Arguments))
+		}))
+	}
+	if !negated {
+		must(rt, obj.Set("not", a.object(true)))
+	}
+	return obj
+}
+
+// assert applies the matcher and records its outcome as a check.
+func (a *assertion) assert(name string, m matcher, negated bool, args []sobek.Value) bool {
+	rt := a.mi.vu.Runtime()
+	pass, err := m(rt, a.actual, args)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("%s: %w", name, err))
+	}
+	if negated {
+		pass = !pass
+		name = "not." + name
+	}
+
+	formattedArgs := make([]string, len(args))
+	for i, v := range args {
+		formattedArgs[i] = formatValue(v)
+	}
+	expected := strings.Join(formattedArgs, ", ")
+
+	checkName := a.message
+	if checkName == "" {
+		checkName = "expect(value)." + name + "(" + expected + ")"
+	}
+
+	var failure *lib.CheckFailure
+	if !pass {
+		message := "expected value " + describe(name)
+		if expected != "" {
+			message += " " + expected
+		}
+		failure = &lib.CheckFailure{
+			Message:  message,
+			Expected: expected,
+			Actual:   formatValue(a.actual),
+			Payload:  a.payload,
+		}
+	}
+
+	if err := a.record(checkName, failure); err != nil {
+		common.Throw(rt, err)
+	}
+	return pass
+}
+
+// record emits the sample of the checks metric of the assertion, with the
+// diagnostics of its failure as metadata, if it failed.
+func (a *assertion) record(name string, failure *lib.CheckFailure) error {
+	if strings.Contains(name, lib.GroupSeparator) {
+		return lib.ErrNameContainsGroupSeparator
+	}
+
+	state := a.mi.vu.State()
+	tagsAndMeta := state.Tags.GetCurrentValues()
+	if !common.IsNullish(a.tags) {
+		if err := common.ApplyCustomUserTags(a.mi.vu.Runtime(), &tagsAndMeta, a.tags); err != nil {
+			return err
+		}
+	}
+	if state.Options.SystemTags.Has(metrics.TagCheck) {
+		tagsAndMeta.SetTag("check", name)
+	}
+
+	sample := metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: state.BuiltinMetrics.Checks,
+		},
+		Time:  time.Now(),
+		Value: 1,
+	}
+	if failure != nil {
+		sample.Value = 0
+		tagsAndMeta.SetMetadata(lib.CheckMetadataMessage, failure.Message)
+		tagsAndMeta.SetMetadata(lib.CheckMetadataExpected, failure.Expected)
+		tagsAndMeta.SetMetadata(lib.CheckMetadataActual, failure.Actual)
+		if failure.Payload != "" {
+			tagsAndMeta.SetMetadata(lib.CheckMetadataPayload, failure.Payload)
+		}
+	}
+	sample.Tags = tagsAndMeta.Tags
+	sample.Metadata = tagsAndMeta.Metadata
+
+	metrics.PushIfNotDone(a.mi.vu.Context(), state.Samples, sample)
+	return nil
+}
+
+func compareNumbers(compare func(a, b float64) bool) matcher {
+	return func(_ *sobek.Runtime, actual sobek.Value, args []sobek.Value) (bool, error) {
+		expected := arg(args, 0)
+		if !isNumber(expected) {
+			return false, fmt.Errorf("the expected value %s isn't a number", formatValue(expected))
+		}
+		if !isNumber(actual) {
+			return false, nil
+		}
+		return compare(actual.ToFloat(), expected.ToFloat()), nil
+	}
+}
+
+// contains returns whether the actual string contains the expected substring,
+// or the actual array contains the expected element.
+func contains(rt *sobek.Runtime, actual sobek.Value, args []sobek.Value) (bool, error) {
+	expected := arg(args, 0)
+	if common.IsNullish(actual) {
+		return false, nil
+	}
+	if isString(actual) {
+		return strings.Contains(actual.String(), expected.String()), nil
+	}
+
+	obj := actual.ToObject(rt)
+	if obj.ClassName() != "Array" {
+		return false, nil
+	}
+	length := obj.Get("length").ToInteger()
+	for i := int64(0); i < length; i++ {
+		if obj.Get(fmt.Sprint(i)).SameAs(expected) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// match returns whether the actual string matches the expected regular
+// expression, either a RegExp or a string.
+func match(rt *sobek.Runtime, actual sobek.Value, args []sobek.Value) (bool, error) {
+	expected := arg(args, 0)
+	s, ok := actual.Export().(string)
+	if !ok {
+		return false, nil
+	}
+
+	if obj, isObj := expected.(*sobek.Object); isObj && obj.ClassName() == "RegExp" {
+		test, _ := sobek.AssertFunction(obj.Get("test"))
+		result, err := test(obj, rt.ToValue(s))
+		if err != nil {
+			return false, err
+		}
+		return result.ToBoolean(), nil
+	}
+
+	re, err := regexp.Compile(expected.String())
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(s), nil
+}
+
+// hasProperty returns whether the actual object has the property at the
+// expected dot-separated path, with the expected value, if any.
+func hasProperty(rt *sobek.Runtime, actual sobek.Value, args []sobek.Value) (bool, error) {
+	path := arg(args, 0)
+	if common.IsNullish(path) {
+		return false, errors.New("the path of the property is required")
+	}
+
+	value := actual
+	for _, key := range strings.Split(path.String(), ".") {
+		if common.IsNullish(value) {
+			return false, nil
+		}
+		if value = value.ToObject(rt).Get(key); value == nil {
+			return false, nil
+		}
+	}
+
+	if len(args) < 2 {
+		return true, nil
+	}
+	return deepEqual(value, args[1]), nil
+}
+
+// deepEqual returns whether the values are equal, recursively comparing the
+// properties of the objects and the elements of the arrays.
+func deepEqual(a, b sobek.Value) bool {
+	if a.SameAs(b) {
+		return true
+	}
+	if common.IsNullish(a) || common.IsNullish(b) {
+		return false
+	}
+
+	// The JSON encoding of the maps has their keys sorted, so it's the same
+	// for the objects with the same properties in different orders.
+	aData, aErr := json.Marshal(a.Export())
+	bData, bErr := json.Marshal(b.Export())
+	return aErr == nil && bErr == nil && string(aData) == string(bData)
+}
+
+func isString(v sobek.Value) bool {
+	_, ok := v.Export().(string)
+	return ok
+}
+
+func isNumber(v sobek.Value) bool {
+	switch v.Export().(type) {
+	case int64, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// arg returns the i-th argument, or undefined if it wasn't passed.
+func arg(args []sobek.Value, i int) sobek.Value {
+	if i < len(args) && args[i] != nil {
+		return args[i]
+	}
+	return sobek.Undefined()
+}
+
+// describe turns the name of a matcher into words, e.g. not.toBeGreaterThan
+// into "not to be greater than".
+func describe(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ReplaceAll(name, ".", " ") {
+		if unicode.IsUpper(r) {
+			b.WriteRune(' ')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// formatValue formats the value for the diagnostics, as JSON if possible.
+func formatValue(v sobek.Value) string {
+	switch {
+	case sobek.IsUndefined(v):
+		return "undefined"
+	case sobek.IsNull(v):
+		return "null"
+	}
+	if _, isFunction := sobek.AssertFunction(v); isFunction {
+		return "[Function]"
+	}
+	if obj, isObj := v.(*sobek.Object); isObj && obj.ClassName() == "RegExp" {
+		return obj.String()
+	}
+
+	data, err := json.Marshal(v.Export())
+	if err != nil {
+		return truncate(v.String(), maxValueLength)
+	}
+	return truncate(string(data), maxValueLength)
+}
+
+// truncate shortens the string to the maximum number of characters, replacing
+// its end with an ellipsis.
+func truncate(s string, maxLength int) string {
+	if utf8.RuneCountInString(s) <= maxLength {
+		return s
+	}
+	return string([]rune(s)[:maxLength-1]) + "…"
+}
+
+func must(rt *sobek.Runtime, err error) {
+	if err != nil {
+		common.Throw(rt, err)
+	}
+}
@@ -0,0 +1,143 @@
+package expect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+func newExpectRuntime(t *testing.T) (*modulestest.Runtime, chan metrics.SampleContainer) {
+	t.Helper()
+
+	runtime := modulestest.NewRuntime(t)
+	m, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.RuntimeField.Set("expect", m.Exports().Named["expect"]))
+
+	registry := metrics.NewRegistry()
+	samples := make(chan metrics.SampleContainer, 1000)
+	runtime.MoveToVUContext(&lib.State{
+		Options:        lib.Options{SystemTags: &metrics.DefaultSystemTagSet},
+		Samples:        samples,
+		Tags:           lib.NewVUStateTags(registry.RootTagSet().WithTagsFromMap(map[string]string{"group": ""})),
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+	})
+
+	return runtime, samples
+}
+
+func TestMatchers(t *testing.T) {
+	t.Parallel()
+
+	passing := []string{
+		`expect(200).toBe(200)`,
+		`expect("a").not.toBe("b")`,
+		`expect({ a: [1, { b: 2 }], c: "d" }).toEqual({ c: "d", a: [1, { b: 2 }] })`,
+		`expect([1, 2]).not.toEqual([2, 1])`,
+		`expect(1).toBeTruthy()`,
+		`expect("").toBeFalsy()`,
+		`expect(0).toBeDefined()`,
+		`expect(undefined).toBeUndefined()`,
+		`expect(null).toBeNull()`,
+		`expect(2).toBeGreaterThan(1)`,
+		`expect(2).toBeGreaterThanOrEqual(2)`,
+		`expect(1.5).toBeLessThan(2)`,
+		`expect(2).toBeLessThanOrEqual(2)`,
+		`expect("abc").not.toBeLessThan(2)`,
+		`expect("hello world").toContain("world")`,
+		`expect([1, 2, 3]).toContain(2)`,
+		`expect([1, 2, 3]).not.toContain("2")`,
+		`expect("abc-123").toMatch(/\d+$/)`,
+		`expect("abc-123").toMatch("^abc")`,
+		`expect({ a: { b: null } }).toHaveProperty("a.b")`,
+		`expect({ a: { b: [1] } }).toHaveProperty("a.b", [1])`,
+		`expect({ a: 1 }).not.toHaveProperty("a.b")`,
+		`expect([1, 2]).toHaveLength(2)`,
+		`expect("abc").toHaveLength(3)`,
+	}
+
+	for _, code := range passing {
+		code := code
+		t.Run(code, func(t *testing.T) {
+			t.Parallel()
+
+			runtime, samples := newExpectRuntime(t)
+			result, err := runtime.VU.Runtime().RunString(code)
+			require.NoError(t, err)
+			assert.True(t, result.ToBoolean())
+
+			bufSamples := metrics.GetBufferedSamples(samples)
+			require.Len(t, bufSamples, 1)
+			sample, ok := bufSamples[0].(metrics.Sample)
+			require.True(t, ok)
+			assert.Equal(t, 1.0, sample.Value)
+			assert.Empty(t, sample.Metadata)
+		})
+	}
+}
+
+func TestFailureDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	runtime, samples := newExpectRuntime(t)
+	result, err := runtime.VU.Runtime().RunString(`
+		expect(500, { message: "status is 200", payload: "x".repeat(300), tags: { endpoint: "login" } }).toBe(200)
+	`)
+	require.NoError(t, err)
+	assert.False(t, result.ToBoolean())
+
+	bufSamples := metrics.GetBufferedSamples(samples)
+	require.Len(t, bufSamples, 1)
+	sample, ok := bufSamples[0].(metrics.Sample)
+	require.True(t, ok)
+	assert.Equal(t, runtime.VU.State().BuiltinMetrics.Checks, sample.Metric)
+	assert.Equal(t, 0.0, sample.Value)
+	assert.Equal(t, map[string]string{"group": "", "check": "status is 200", "endpoint": "login"}, sample.Tags.Map())
+
+	failure, ok := lib.NewCheckFailure(sample.Metadata)
+	require.True(t, ok)
+	assert.Equal(t, "expected value to be 200", failure.Message)
+	assert.Equal(t, "200", failure.Expected)
+	assert.Equal(t, "500", failure.Actual)
+	assert.Len(t, []rune(failure.Payload), maxPayloadLength)
+}
+
+func TestDefaultCheckName(t *testing.T) {
+	t.Parallel()
+
+	runtime, samples := newExpectRuntime(t)
+	_, err := runtime.VU.Runtime().RunString(`expect({ id: 1 }).not.toHaveProperty("error")`)
+	require.NoError(t, err)
+
+	bufSamples := metrics.GetBufferedSamples(samples)
+	require.Len(t, bufSamples, 1)
+	sample, ok := bufSamples[0].(metrics.Sample)
+	require.True(t, ok)
+	name, _ := sample.Tags.Get("check")
+	assert.Equal(t, `expect(value).not.toHaveProperty("error")`, name)
+}
+
+func TestInvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	runtime, _ := newExpectRuntime(t)
+	_, err := runtime.VU.Runtime().RunString(`expect(1).toBeGreaterThan("a")`)
+	require.ErrorContains(t, err, `toBeGreaterThan: the expected value "a" isn't a number`)
+}
+
+func TestExpectInInitContext(t *testing.T) {
+	t.Parallel()
+
+	runtime := modulestest.NewRuntime(t)
+	m, ok := New().NewModuleInstance(runtime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, runtime.VU.RuntimeField.Set("expect", m.Exports().Named["expect"]))
+
+	_, err := runtime.VU.Runtime().RunString(`expect(1)`)
+	require.ErrorContains(t, err, "Using expect() in the init context is not supported")
+}
@@ -0,0 +1,49 @@
+package faker
+
+//nolint:gochecknoglobals
+var (
+	firstNames = []string{
+		"Ada", "Alan", "Alice", "Amara", "Anna", "Arjun", "Ben", "Carlos", "Chen", "Chloe",
+		"Daniel", "David", "Diego", "Elena", "Emma", "Fatima", "Grace", "Hana", "Ivan", "James",
+		"Julia", "Kenji", "Lena", "Liam", "Lucas", "Maria", "Mateo", "Mia", "Noah", "Nora",
+		"Olivia", "Omar", "Priya", "Rosa", "Sam", "Sara", "Sofia", "Tom", "Yuki", "Zoe",
+	}
+
+	lastNames = []string{
+		"Anderson", "Brown", "Chen", "Clark", "Davis", "Diaz", "Evans", "Garcia", "Hall", "Hernandez",
+		"Ivanova", "Jackson", "Johnson", "Kim", "Kowalski", "Lee", "Lopez", "Martin", "Miller", "Moore",
+		"Muller", "Nguyen", "Novak", "Patel", "Rossi", "Sato", "Schmidt", "Silva", "Smith", "Taylor",
+		"Thomas", "Walker", "White", "Williams", "Wilson", "Wright", "Young", "Yilmaz", "Zhang", "Zimmer",
+	}
+
+	// emailDomains are the domains reserved for documentation by RFC 2606.
+	emailDomains = []string{"example.com", "example.net", "example.org"}
+
+	streetNames = []string{
+		"Acacia", "Birch", "Cedar", "Chestnut", "Church", "Elm", "Forest", "Highland", "Hill", "Lake",
+		"Maple", "Meadow", "Mill", "Oak", "Park", "Pine", "River", "Spring", "Sunset", "Willow",
+	}
+
+	streetSuffixes = []string{"Avenue", "Boulevard", "Court", "Drive", "Lane", "Road", "Street", "Way"}
+
+	cities = []string{
+		"Amsterdam", "Athens", "Berlin", "Boston", "Buenos Aires", "Cairo", "Chicago", "Dublin", "Istanbul", "Lisbon",
+		"London", "Madrid", "Melbourne", "Mexico City", "Montreal", "Mumbai", "Nairobi", "Oslo", "Paris", "Prague",
+		"Rome", "San Francisco", "Seoul", "Singapore", "Stockholm", "Sydney", "Tokyo", "Toronto", "Vienna", "Warsaw",
+	}
+
+	countries = []string{
+		"Argentina", "Australia", "Austria", "Brazil", "Canada", "China", "Egypt", "France", "Germany", "Greece",
+		"India", "Ireland", "Italy", "Japan", "Kenya", "Mexico", "Netherlands", "Norway", "Poland", "Portugal",
+		"Singapore", "South Korea", "Spain", "Sweden", "Turkey", "United Kingdom", "United States",
+	}
+
+	loremWords = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+		"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+		"ad", "minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip",
+		"ex", "ea", "commodo", "consequat", "duis", "aute", "irure", "in", "reprehenderit", "voluptate",
+		"velit", "esse", "cillum", "fugiat", "nulla", "pariatur", "excepteur", "sint", "occaecat", "cupidatat",
+		"non", "proident", "sunt", "culpa", "qui", "officia", "deserunt", "mollit", "anim", "id", "est", "laborum",
+	}
+)
@@ -0,0 +1,211 @@
+package faker
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+// Faker generates fake data from a seeded source, which makes the data
+// deterministic. It isn't safe for concurrent use, every VU has its own.
+type Faker struct {
+	vu modules.VU
+
+	// rnd is the source of the generated data. The one of the default faker
+	// of the VU is created on its first use, seeded with the VU's ID.
+	rnd *rand.Rand
+}
+
+// Address is a postal address.
+type Address struct {
+	Street  string `js:"street"`
+	City    string `js:"city"`
+	ZipCode string `js:"zipCode"`
+	Country string `js:"country"`
+}
+
+// Seed resets the faker to the given seed.
+func (f *Faker) Seed(seed int64) {
+	f.rnd = rand.New(rand.NewSource(seed)) //nolint:gosec
+}
+
+func (f *Faker) rand() *rand.Rand {
+	if f.rnd == nil {
+		state := f.vu.State()
+		if state == nil {
+			common.Throw(f.vu.Runtime(), common.NewInitContextError(
+				"using the default faker in the init context is not supported, create a new Faker with a seed instead"))
+		}
+		f.Seed(int64(state.VUID))
+	}
+	return f.rnd
+}
+
+func (f *Faker) pick(values []string) string {
+	return values[f.rand().Intn(len(values))]
+}
+
+func (f *Faker) digits(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteByte(byte('0' + f.rand().Intn(10)))
+	}
+	return b.String()
+}
+
+// FirstName returns a first name.
+func (f *Faker) FirstName() string {
+	return f.pick(firstNames)
+}
+
+// LastName returns a last name.
+func (f *Faker) LastName() string {
+	return f.pick(lastNames)
+}
+
+// FullName returns a first name followed by a last name.
+func (f *Faker) FullName() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Username returns a username made of a name and a number.
+func (f *Faker) Username() string {
+	return strings.ToLower(f.FirstName()+"."+f.LastName()) + strconv.Itoa(f.rand().Intn(1000))
+}
+
+// Email returns an email address in one of the domains reserved for
+// documentation, so no mail is ever sent to a real inbox.
+func (f *Faker) Email() string {
+	return f.Username() + "@" + f.pick(emailDomains)
+}
+
+// StreetAddress returns a house number and a street name.
+func (f *Faker) StreetAddress() string {
+	return strconv.Itoa(1+f.rand().Intn(9999)) + " " + f.pick(streetNames) + " " + f.pick(streetSuffixes)
+}
+
+// City returns the name of a city.
+func (f *Faker) City() string {
+	return f.pick(cities)
+}
+
+// ZipCode returns a 5-digit zip code.
+func (f *Faker) ZipCode() string {
+	return f.digits(5)
+}
+
+// Country returns the name of a country.
+func (f *Faker) Country() string {
+	return f.pick(countries)
+}
+
+// Address returns a postal address.
+func (f *Faker) Address() Address {
+	return Address{
+		Street:  f.StreetAddress(),
+		City:    f.City(),
+		ZipCode: f.ZipCode(),
+		Country: f.Country(),
+	}
+}
+
+// UUID returns a random (version 4) UUID.
+func (f *Faker) UUID() string {
+	var b [16]byte
+	_, _ = f.rand().Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// cardIssuer describes the numbers of the cards of an issuer.
+type cardIssuer struct {
+	prefixes []string
+	length   int
+}
+
+//nolint:gochecknoglobals
+var cardIssuers = map[string]cardIssuer{
+	"visa":       {prefixes: []string{"4"}, length: 16},
+	"mastercard": {prefixes: []string{"51", "52", "53", "54", "55"}, length: 16},
+	"amex":       {prefixes: []string{"34", "37"}, length: 15},
+}
+
+// CreditCardNumber returns a credit card number of the issuer, which is either
+// "visa" (by default), "mastercard" or "amex". The numbers pass the Luhn check,
+// but don't belong to any real card.
+func (f *Faker) CreditCardNumber(issuer string) string {
+	if issuer == "" {
+		issuer = "visa"
+	}
+	card, ok := cardIssuers[strings.ToLower(issuer)]
+	if !ok {
+		common.Throw(f.vu.Runtime(), fmt.Errorf("unsupported credit card issuer %q, it should be visa, mastercard or amex", issuer))
+	}
+
+	number := f.pick(card.prefixes)
+	number += f.digits(card.length - len(number) - 1)
+	return number + strconv.Itoa(luhnCheckDigit(number))
+}
+
+// luhnCheckDigit returns the digit completing the number to pass the Luhn check.
+func luhnCheckDigit(number string) int {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		// The digits are doubled from the one preceding the check digit.
+		if (len(number)-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10
+}
+
+// Word returns a lorem ipsum word.
+func (f *Faker) Word() string {
+	return f.pick(loremWords)
+}
+
+// Words returns the given number of lorem ipsum words, 3 by default, separated
+// by spaces.
+func (f *Faker) Words(count int) string {
+	if count <= 0 {
+		count = 3
+	}
+	words := make([]string, count)
+	for i := range words {
+		words[i] = f.Word()
+	}
+	return strings.Join(words, " ")
+}
+
+// Sentence returns a lorem ipsum sentence of the given number of words, or of
+// 4 to 12 words by default.
+func (f *Faker) Sentence(words int) string {
+	if words <= 0 {
+		words = 4 + f.rand().Intn(9)
+	}
+	sentence := f.Words(words)
+	return strings.ToUpper(sentence[:1]) + sentence[1:] + "."
+}
+
+// Paragraph returns a lorem ipsum paragraph of the given number of sentences,
+// or of 3 to 6 sentences by default.
+func (f *Faker) Paragraph(sentences int) string {
+	if sentences <= 0 {
+		sentences = 3 + f.rand().Intn(4)
+	}
+	paragraph := make([]string, sentences)
+	for i := range paragraph {
+		paragraph[i] = f.Sentence(0)
+	}
+	return strings.Join(paragraph, " ")
+}
@@ -0,0 +1,71 @@
+// Package faker provides a k6 module generating fake data, such as names,
+// emails, addresses, UUIDs, credit card numbers and lorem text, to
+// parameterize the payloads of the tests.
+//
+// The generators are seeded, so the same seed always generates the same data.
+package faker
+
+import (
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the faker module for a single VU.
+	ModuleInstance struct {
+		vu modules.VU
+
+		// faker is the default faker of the VU, which is seeded with its ID.
+		faker *Faker
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu, faker: &Faker{vu: vu}}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"Faker": mi.NewFaker,
+			"faker": mi.faker,
+		},
+	}
+}
+
+// NewFaker creates a new faker, seeded with its optional seed argument, or
+// with the current time otherwise.
+func (mi *ModuleInstance) NewFaker(call sobek.ConstructorCall) *sobek.Object {
+	rt := mi.vu.Runtime()
+
+	seed := time.Now().UnixNano()
+	if v := call.Argument(0); !common.IsNullish(v) {
+		seed = v.ToInteger()
+	}
+
+	f := &Faker{vu: mi.vu}
+	f.Seed(seed)
+	return rt.ToValue(f).ToObject(rt)
+}
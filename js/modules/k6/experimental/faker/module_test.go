@@ -0,0 +1,91 @@
+package faker
+
+import (
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+)
+
+func newFakerRuntime(t *testing.T) *modulestest.Runtime {
+	t.Helper()
+
+	runtime := modulestest.NewRuntime(t)
+	err := runtime.SetupModuleSystem(map[string]any{"k6/experimental/faker": New()}, nil, nil)
+	require.NoError(t, err)
+	_, err = runtime.VU.Runtime().RunString(`var { Faker, faker } = require("k6/experimental/faker");`)
+	require.NoError(t, err)
+
+	return runtime
+}
+
+func TestFaker(t *testing.T) {
+	t.Parallel()
+
+	runtime := newFakerRuntime(t)
+	_, err := runtime.VU.Runtime().RunString(`
+		const f = new Faker(42);
+		if (!/^[A-Z][a-z]+ [A-Z][a-z]+$/.test(f.fullName())) throw new Error("bad full name");
+		if (!/^[a-z]+\.[a-z]+\d+@example\.(com|net|org)$/.test(f.email())) throw new Error("bad email");
+		const address = f.address();
+		if (!/^\d+ [A-Z][a-z]+ [A-Z][a-z]+$/.test(address.street)) throw new Error("bad street " + address.street);
+		if (!/^\d{5}$/.test(address.zipCode) || !address.city || !address.country) {
+			throw new Error("bad address " + JSON.stringify(address));
+		}
+		if (!/^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$/.test(f.uuid())) {
+			throw new Error("bad uuid");
+		}
+		if (!/^4\d{15}$/.test(f.creditCardNumber())) throw new Error("bad visa number");
+		if (!/^3[47]\d{13}$/.test(f.creditCardNumber("amex"))) throw new Error("bad amex number");
+		if (f.words(5).split(" ").length !== 5) throw new Error("bad words");
+		if (!/^[A-Z][a-z ]+\.$/.test(f.sentence(4))) throw new Error("bad sentence");
+		if (f.paragraph(2).split(".").length !== 3) throw new Error("bad paragraph");
+	`)
+	require.NoError(t, err)
+}
+
+func TestFakerDeterministic(t *testing.T) {
+	t.Parallel()
+
+	generate := func(code string) sobek.Value {
+		runtime := newFakerRuntime(t)
+		runtime.MoveToVUContext(&lib.State{VUID: 7})
+		v, err := runtime.VU.Runtime().RunString(code)
+		require.NoError(t, err)
+		return v
+	}
+
+	const code = `[faker.fullName(), faker.uuid(), new Faker(1).email()].join()`
+	first := generate(code).String()
+	assert.Equal(t, first, generate(code).String())
+
+	v := generate(`
+		const a = new Faker(1), b = new Faker(2);
+		faker.seed(1);
+		[a.paragraph() === faker.paragraph(), a.uuid() === b.uuid()];
+	`)
+	assert.Equal(t, []any{true, false}, v.Export())
+}
+
+func TestFakerErrors(t *testing.T) {
+	t.Parallel()
+
+	runtime := newFakerRuntime(t)
+	_, err := runtime.VU.Runtime().RunString(`faker.fullName()`)
+	require.ErrorContains(t, err, "using the default faker in the init context is not supported")
+
+	_, err = runtime.VU.Runtime().RunString(`new Faker(1).creditCardNumber("diners")`)
+	require.ErrorContains(t, err, `unsupported credit card issuer "diners"`)
+}
+
+func TestLuhnCheckDigit(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 3, luhnCheckDigit("7992739871"))
+	assert.Equal(t, 1, luhnCheckDigit("411111111111111"))
+	assert.Equal(t, 5, luhnCheckDigit("37828224631000"))
+}
@@ -0,0 +1,249 @@
+package fs
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// CompressionGzip designates gzip compressed content.
+	CompressionGzip = "gzip"
+
+	// CompressionZstd designates zstd compressed content.
+	CompressionZstd = "zstd"
+)
+
+// CompressionFromPath returns the compression the file at the provided path
+// is expected to hold content compressed with, based on its extension, or an
+// empty string if it is not expected to be compressed.
+func CompressionFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz":
+		return CompressionGzip
+	case ".zst":
+		return CompressionZstd
+	default:
+		return ""
+	}
+}
+
+// decoder decompresses a compressed stream.
+type decoder interface {
+	io.Reader
+
+	// Reset resets the decoder, so that it decompresses the provided stream
+	// from its start.
+	Reset(r io.Reader) error
+}
+
+// zstdDecoder adapts a [zstd.Decoder] to the decoder interface.
+type zstdDecoder struct {
+	*zstd.Decoder
+}
+
+// Reset implements the decoder interface.
+func (d zstdDecoder) Reset(r io.Reader) error {
+	return d.Decoder.Reset(r)
+}
+
+// DecompressingReader is an [io.ReadSeeker] reading the decompressed content
+// of a compressed stream, as it is read, rather than decompressing it at once.
+//
+// Its offsets refer to the decompressed content. Seeking forward decompresses,
+// and discards, the content up to the offset sought, while seeking backward
+// restarts decompressing from the start of the stream: reading sequentially,
+// or seeking forward, is thus the efficient way to read it.
+//
+// When the compressed stream is truncated or corrupt, the content that could be
+// decompressed is read, and reading past it fails with the decompression error.
+type DecompressingReader struct {
+	// mu serializes the operations on the reader.
+	mu sync.Mutex
+
+	src         io.ReadSeeker
+	compression string
+	decoder     decoder
+
+	// pos holds the offset, within the decompressed content, of the next
+	// byte to read.
+	pos int64
+
+	// size holds the size of the decompressed content, or -1 until it is known.
+	size int64
+}
+
+var _ io.ReadSeeker = (*DecompressingReader)(nil)
+
+// NewDecompressingReader creates a new [DecompressingReader] reading the
+// provided stream, compressed with the given compression.
+//
+// It fails if the stream does not start as expected from the compression.
+func NewDecompressingReader(src io.ReadSeeker, compression string) (*DecompressingReader, error) {
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var (
+		dec decoder
+		err error
+	)
+
+	switch compression {
+	case CompressionGzip:
+		var zr *gzip.Reader
+		if zr, err = gzip.NewReader(src); err == nil {
+			dec = zr
+		}
+	case CompressionZstd:
+		// Decoding in the calling goroutine, the decoder holds no resources
+		// to be released once done.
+		var zr *zstd.Decoder
+		if zr, err = zstd.NewReader(src, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true)); err == nil {
+			dec = zstdDecoder{Decoder: zr}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compression)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the %s stream; reason: %w", compression, err)
+	}
+
+	return &DecompressingReader{src: src, compression: compression, decoder: dec, size: -1}, nil
+}
+
+// Read implements the [io.Reader] interface.
+func (dr *DecompressingReader) Read(p []byte) (int, error) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	return dr.read(p)
+}
+
+// read reads from the decoder, as [DecompressingReader.Read] does, while the
+// reader's lock is held.
+func (dr *DecompressingReader) read(p []byte) (int, error) {
+	n, err := dr.decoder.Read(p)
+	dr.pos += int64(n)
+
+	switch {
+	case errors.Is(err, io.EOF):
+		dr.size = dr.pos
+	case err != nil:
+		err = fmt.Errorf("failed to decompress the %s stream; reason: %w", dr.compression, err)
+	}
+
+	return n, err
+}
+
+// Seek implements the [io.Seeker] interface.
+func (dr *DecompressingReader) Seek(offset int64, whence int) (int64, error) {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += dr.pos
+	case io.SeekEnd:
+		size, err := dr.sizeLocked()
+		if err != nil {
+			return 0, err
+		}
+
+		offset += size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if offset < dr.pos {
+		if err := dr.rewind(); err != nil {
+			return 0, err
+		}
+	}
+
+	// The content up to the offset sought is decompressed, and discarded.
+	if _, err := io.CopyN(io.Discard, readerFunc(dr.read), offset-dr.pos); err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+
+	return dr.pos, nil
+}
+
+// Size returns the size of the decompressed content, which is decompressed
+// once, and discarded, to be measured, unless it has been entirely read already.
+//
+// If the compressed stream is truncated or corrupt, the size of the content
+// that could be decompressed is returned.
+func (dr *DecompressingReader) Size() int64 {
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	size, _ := dr.sizeLocked()
+
+	return size
+}
+
+// sizeLocked returns the size of the decompressed content, as [DecompressingReader.Size]
+// does, while the reader's lock is held, along with the decompression error
+// preventing it from being entirely decompressed, if any.
+func (dr *DecompressingReader) sizeLocked() (int64, error) {
+	if dr.size >= 0 {
+		return dr.size, nil
+	}
+
+	pos := dr.pos
+
+	_, err := io.Copy(io.Discard, readerFunc(dr.read))
+	size := dr.pos
+
+	if err != nil {
+		// The content that could be decompressed is considered to be the whole of it.
+		dr.size = size
+	}
+
+	// The reader is brought back to where it was.
+	if rewindErr := dr.rewind(); rewindErr != nil {
+		return size, rewindErr
+	}
+
+	if _, copyErr := io.CopyN(io.Discard, readerFunc(dr.read), pos); copyErr != nil && !errors.Is(copyErr, io.EOF) {
+		return size, copyErr
+	}
+
+	return size, nil
+}
+
+// rewind restarts decompressing from the start of the compressed stream.
+func (dr *DecompressingReader) rewind() error {
+	if _, err := dr.src.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if err := dr.decoder.Reset(dr.src); err != nil {
+		return fmt.Errorf("failed to decompress the %s stream; reason: %w", dr.compression, err)
+	}
+
+	dr.pos = 0
+
+	return nil
+}
+
+// readerFunc adapts a function to the [io.Reader] interface.
+type readerFunc func(p []byte) (int, error)
+
+// Read implements the [io.Reader] interface.
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
@@ -0,0 +1,185 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/grafana/sobek"
+	"go.k6.io/k6/js/promises"
+	"go.k6.io/k6/lib/fsext"
+)
+
+// DirEntry describes an entry of a directory, as listed by [ModuleInstance.Readdir]
+// or [ModuleInstance.Glob].
+type DirEntry struct {
+	// Name holds the base name of the entry.
+	Name string `json:"name"`
+
+	// Path holds the absolute path of the entry, which can be passed to [ModuleInstance.Open].
+	Path string `json:"path"`
+
+	// Size holds the size of the entry in bytes.
+	Size int64 `json:"size"`
+
+	// Mtime holds the entry's modification time, in milliseconds since the
+	// Unix epoch.
+	Mtime int64 `json:"mtime"`
+
+	// IsDirectory indicates whether the entry is a directory.
+	IsDirectory bool `json:"isDirectory" js:"isDirectory"`
+}
+
+// newDirEntry creates a new [DirEntry] describing the entry at the provided
+// path, out of the given FileInfo.
+func newDirEntry(path string, info fs.FileInfo) DirEntry {
+	return DirEntry{
+		Name:        info.Name(),
+		Path:        path,
+		Size:        info.Size(),
+		Mtime:       info.ModTime().UnixMilli(),
+		IsDirectory: info.IsDir(),
+	}
+}
+
+// Readdir returns a promise that will resolve to the entries of the directory
+// at the provided path, as [DirEntry] instances sorted by name.
+//
+// Like opening a file, it is only allowed in the init context, and the path is
+// resolved relative to the entrypoint script.
+func (mi *ModuleInstance) Readdir(path sobek.Value) *sobek.Promise {
+	promise, resolve, reject := promises.New(mi.vu)
+
+	if mi.vu.State() != nil {
+		reject(newFsError(ForbiddenError, "readdir() failed; reason: listing a directory is allowed only in the Init context"))
+		return promise
+	}
+
+	pathStr, err := exportPath("readdir", path)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	go func() {
+		entries, err := mi.readdirImpl(pathStr)
+		if err != nil {
+			reject(err)
+			return
+		}
+
+		resolve(entries)
+	}()
+
+	return promise
+}
+
+func (mi *ModuleInstance) readdirImpl(path string) ([]DirEntry, error) {
+	initEnv := mi.vu.InitEnv()
+
+	// See [ModuleInstance.openImpl] for the rationale.
+	path = fsext.Abs(initEnv.CWD.Path, path)
+
+	fs, ok := initEnv.FileSystems["file"]
+	if !ok {
+		return nil, errors.New("readdir() failed; reason: unable to access the file system")
+	}
+
+	if exists, err := fsext.Exists(fs, path); err != nil {
+		return nil, fmt.Errorf("readdir() failed, unable to verify if %q exists; reason: %w", path, err)
+	} else if !exists {
+		return nil, newFsError(NotFoundError, fmt.Sprintf("no such file or directory %q", path))
+	}
+
+	if isDir, err := fsext.IsDir(fs, path); err != nil {
+		return nil, fmt.Errorf("readdir() failed, unable to verify if %q is a directory; reason: %w", path, err)
+	} else if !isDir {
+		return nil, newFsError(InvalidResourceError, fmt.Sprintf("cannot list %q: it is not a directory", path))
+	}
+
+	infos, err := fsext.ReadDir(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("readdir() failed, unable to list %q; reason: %w", path, err)
+	}
+
+	entries := make([]DirEntry, 0, len(infos))
+	for _, info := range infos {
+		entries = append(entries, newDirEntry(filepath.Join(path, info.Name()), info))
+	}
+
+	return entries, nil
+}
+
+// Glob returns a promise that will resolve to the entries matching the provided
+// pattern, as [DirEntry] instances sorted by path.
+//
+// The pattern follows the syntax of Go's [filepath.Match], each of its path
+// elements matching a single one: `data/*.json` matches the JSON files of the
+// data directory, but not the ones of its subdirectories.
+//
+// Like opening a file, it is only allowed in the init context, and the pattern
+// is resolved relative to the entrypoint script.
+func (mi *ModuleInstance) Glob(pattern sobek.Value) *sobek.Promise {
+	promise, resolve, reject := promises.New(mi.vu)
+
+	if mi.vu.State() != nil {
+		reject(newFsError(ForbiddenError, "glob() failed; reason: listing files is allowed only in the Init context"))
+		return promise
+	}
+
+	patternStr, err := exportPath("glob", pattern)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	if _, err := filepath.Match(patternStr, ""); err != nil {
+		reject(newFsError(TypeError, fmt.Sprintf("glob() failed; reason: invalid pattern %q", patternStr)))
+		return promise
+	}
+
+	go func() {
+		entries, err := mi.globImpl(patternStr)
+		if err != nil {
+			reject(err)
+			return
+		}
+
+		resolve(entries)
+	}()
+
+	return promise
+}
+
+func (mi *ModuleInstance) globImpl(pattern string) ([]DirEntry, error) {
+	initEnv := mi.vu.InitEnv()
+
+	// See [ModuleInstance.openImpl] for the rationale.
+	pattern = fsext.Abs(initEnv.CWD.Path, pattern)
+
+	fs, ok := initEnv.FileSystems["file"]
+	if !ok {
+		return nil, errors.New("glob() failed; reason: unable to access the file system")
+	}
+
+	paths, err := fsext.Glob(fs, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("glob() failed, unable to match %q; reason: %w", pattern, err)
+	}
+
+	sort.Strings(paths)
+
+	entries := make([]DirEntry, 0, len(paths))
+	for _, path := range paths {
+		info, err := fs.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("glob() failed, unable to stat %q; reason: %w", path, err)
+		}
+
+		entries = append(entries, newDirEntry(path, info))
+	}
+
+	return entries, nil
+}
@@ -1,6 +1,7 @@
 package fs
 
 import (
+	"errors"
 	"io"
 	"path/filepath"
 	"sync/atomic"
@@ -15,6 +16,11 @@ type file struct {
 	// data holds a pointer to the file's data
 	data []byte
 
+	// decompressed reads the decompressed content of the file's data, when
+	// the file is transparently decompressed, in which case the file's
+	// offsets and size refer to the decompressed content.
+	decompressed *DecompressingReader
+
 	// offset holds the current offset in the file
 	//
 	// TODO: using an atomic here does not guarantee ordering of reads and seeks, and leaves
@@ -59,8 +65,17 @@ func (f *file) Read(into []byte) (n int, err error) {
 
 	// Read the data into the provided slice, and update
 	// the offset accordingly
-	n = copy(into, f.data[currentOffset:newOffset])
-	f.offset.Store(newOffset)
+	if f.decompressed != nil {
+		n, err = f.readDecompressed(into[:newOffset-currentOffset], currentOffset)
+		f.offset.Store(currentOffset + int64(n))
+
+		if err != nil {
+			return n, err
+		}
+	} else {
+		n = copy(into, f.data[currentOffset:newOffset])
+		f.offset.Store(newOffset)
+	}
 
 	// If we've reached or surpassed the end, set the error to EOF
 	if targetOffset > fileSize {
@@ -138,5 +153,62 @@ const (
 )
 
 func (f *file) size() int64 {
+	if f.decompressed != nil {
+		return f.decompressed.Size()
+	}
+
 	return int64(len(f.data))
 }
+
+// readDecompressed reads len(into) bytes of the file's decompressed content,
+// starting at the provided offset.
+func (f *file) readDecompressed(into []byte, offset int64) (int, error) {
+	if _, err := f.decompressed.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	n, err := io.ReadFull(f.decompressed, into)
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		err = nil
+	}
+
+	return n, err
+}
+
+// ReadSeekStater is the interface exposed by a [File] to other Go modules.
+//
+// Unlike the [file] it wraps, it follows the standard library's semantics,
+// and signals that the end of the file has been reached with [io.EOF].
+type ReadSeekStater interface {
+	io.Reader
+	io.Seeker
+
+	// Stat returns a FileInfo describing the file.
+	Stat() *FileInfo
+}
+
+// readSeekStater adapts a [file] to the [ReadSeekStater] interface.
+type readSeekStater struct {
+	*file
+}
+
+// Ensure that `readSeekStater` implements the ReadSeekStater interface.
+var _ ReadSeekStater = (*readSeekStater)(nil)
+
+// Read implements the [io.Reader] interface, and returns [io.EOF] when the
+// end of the file has been reached.
+func (rss *readSeekStater) Read(into []byte) (int, error) {
+	n, err := rss.file.Read(into)
+
+	var fsErr *fsError
+	if errors.As(err, &fsErr) && fsErr.kind == EOFError {
+		return n, io.EOF
+	}
+
+	return n, err
+}
+
+// Stat returns a FileInfo describing the file.
+func (rss *readSeekStater) Stat() *FileInfo {
+	return rss.file.stat()
+}
@@ -5,6 +5,7 @@
 package fs
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"reflect"
@@ -27,6 +28,11 @@ type (
 	ModuleInstance struct {
 		vu    modules.VU
 		cache *cache
+
+		// initEnv holds the VU's init environment, which is only available
+		// from the VU during the init context, while files can be written
+		// to outside of it.
+		initEnv *common.InitEnvironment
 	}
 )
 
@@ -45,7 +51,7 @@ func New() *RootModule {
 // NewModuleInstance implements the modules.Module interface and returns a new
 // instance of our module for the given VU.
 func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
-	return &ModuleInstance{vu: vu, cache: rm.cache}
+	return &ModuleInstance{vu: vu, cache: rm.cache, initEnv: vu.InitEnv()}
 }
 
 // Exports implements the modules.Module interface and returns the exports of
@@ -53,7 +59,12 @@ func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 func (mi *ModuleInstance) Exports() modules.Exports {
 	return modules.Exports{
 		Named: map[string]any{
-			"open": mi.Open,
+			"open":       mi.Open,
+			"openStream": mi.OpenStream,
+			"writeFile":  mi.WriteFile,
+			"appendFile": mi.AppendFile,
+			"readdir":    mi.Readdir,
+			"glob":       mi.Glob,
 			"SeekMode": map[string]any{
 				"Start":   SeekModeStart,
 				"Current": SeekModeCurrent,
@@ -64,7 +75,16 @@ func (mi *ModuleInstance) Exports() modules.Exports {
 }
 
 // Open opens a file and returns a promise that will resolve to a [File] instance
-func (mi *ModuleInstance) Open(path sobek.Value) *sobek.Promise {
+//
+// The file is opened for reading, unless the optional mode argument is set to
+// "w" or "a", in which case it is opened for writing, or appending, and is
+// created if it does not exist. Writing files must be allowed through the
+// `--allow-file-writes` flag.
+//
+// Files opened for reading which extension is `.gz` or `.zst` are transparently
+// decompressed, as they are read, unless the mode argument is an object which
+// `decompress` property is set to false: its `mode` property then holds the mode.
+func (mi *ModuleInstance) Open(path sobek.Value, mode sobek.Value) *sobek.Promise {
 	promise, resolve, reject := promises.New(mi.vu)
 
 	if mi.vu.State() != nil {
@@ -84,8 +104,25 @@ func (mi *ModuleInstance) Open(path sobek.Value) *sobek.Promise {
 		return promise
 	}
 
+	options, err := exportOpenOptions(mode)
+	if err != nil {
+		reject(newFsError(TypeError, "open() failed; reason: the options argument "+err.Error()))
+		return promise
+	}
+
 	go func() {
-		file, err := mi.openImpl(pathStr)
+		if options.mode != OpenModeRead {
+			file, err := mi.openWritableImpl(pathStr, options.mode)
+			if err != nil {
+				reject(err)
+				return
+			}
+
+			resolve(file)
+			return
+		}
+
+		file, err := mi.openImpl(pathStr, options.decompress)
 		if err != nil {
 			reject(err)
 			return
@@ -97,7 +134,51 @@ func (mi *ModuleInstance) Open(path sobek.Value) *sobek.Promise {
 	return promise
 }
 
-func (mi *ModuleInstance) openImpl(path string) (*File, error) {
+// openOptions holds the options a file is opened with.
+type openOptions struct {
+	// mode indicates what the file is opened for.
+	mode OpenMode
+
+	// decompress indicates whether a file opened for reading, which extension
+	// designates a compression, is transparently decompressed.
+	decompress bool
+}
+
+// exportOpenOptions returns the options the provided value designates, either
+// a mode, or an object holding the `mode` and `decompress` options. The file is
+// opened for reading, and transparently decompressed, by default.
+func exportOpenOptions(v sobek.Value) (openOptions, error) {
+	options := openOptions{mode: OpenModeRead, decompress: true}
+	if common.IsNullish(v) {
+		return options, nil
+	}
+
+	modeValue := v
+	if obj, ok := v.(*sobek.Object); ok {
+		modeValue = obj.Get("mode")
+
+		if decompress := obj.Get("decompress"); !common.IsNullish(decompress) {
+			options.decompress = decompress.ToBoolean()
+		}
+	} else if _, ok := v.Export().(string); !ok {
+		return options, fmt.Errorf("must be either a mode or an object, got %s instead", v.ExportType())
+	}
+
+	if common.IsNullish(modeValue) {
+		return options, nil
+	}
+
+	switch mode := OpenMode(modeValue.String()); mode {
+	case OpenModeRead, OpenModeWrite, OpenModeAppend:
+		options.mode = mode
+	default:
+		return options, fmt.Errorf(`mode must be one of "r", "w", or "a", got %q instead`, modeValue.String())
+	}
+
+	return options, nil
+}
+
+func (mi *ModuleInstance) openImpl(path string, decompress bool) (*File, error) {
 	initEnv := mi.vu.InitEnv()
 
 	// We resolve the path relative to the entrypoint script, as opposed to
@@ -135,7 +216,7 @@ func (mi *ModuleInstance) openImpl(path string) (*File, error) {
 		return nil, err
 	}
 
-	return &File{
+	f := &File{
 		Path: path,
 		file: file{
 			path: path,
@@ -143,7 +224,20 @@ func (mi *ModuleInstance) openImpl(path string) (*File, error) {
 		},
 		vu:    mi.vu,
 		cache: mi.cache,
-	}, nil
+	}
+	f.Impl = &readSeekStater{file: &f.file}
+
+	if compression := CompressionFromPath(path); decompress && compression != "" {
+		dr, err := NewDecompressingReader(bytes.NewReader(data), compression)
+		if err != nil {
+			return nil, newFsError(InvalidResourceError, fmt.Sprintf("cannot open %q: %s", path, err))
+		}
+
+		f.file.decompressed = dr
+		f.Compression = compression
+	}
+
+	return f, nil
 }
 
 // File represents a file and exposes methods to interact with it.
@@ -157,6 +251,21 @@ type File struct {
 	// file contains the actual implementation for the file system.
 	file
 
+	// Impl gives other Go modules, such as csv, access to the file's
+	// underlying implementation using the standard [io.Reader] and
+	// [io.Seeker] semantics.
+	//
+	// It is explicitly hidden from the JS runtime.
+	Impl ReadSeekStater `js:"-"`
+
+	// Compression holds the compression the file's content is transparently
+	// decompressed from, if any, in which case other Go modules read the
+	// decompressed content through Impl.
+	Compression string `js:"-"`
+
+	// writer writes to the file, if it was opened for writing.
+	writer *writer
+
 	// vu holds a reference to the VU this file is associated with.
 	//
 	// We need this to be able to access the VU's runtime, and produce
@@ -172,10 +281,21 @@ type File struct {
 // Stat returns a promise that will resolve to a [FileInfo] instance describing
 // the file.
 func (f *File) Stat() *sobek.Promise {
-	promise, resolve, _ := promises.New(f.vu)
+	promise, resolve, reject := promises.New(f.vu)
 
 	go func() {
-		resolve(f.file.stat())
+		if f.writer == nil {
+			resolve(f.file.stat())
+			return
+		}
+
+		info, err := f.writer.stat(f.Path)
+		if err != nil {
+			reject(fmt.Errorf("stat() failed; reason: %w", err))
+			return
+		}
+
+		resolve(info)
 	}()
 
 	return promise
@@ -191,6 +311,11 @@ func (f *File) Stat() *sobek.Promise {
 func (f *File) Read(into sobek.Value) *sobek.Promise {
 	promise, resolve, reject := f.vu.Runtime().NewPromise()
 
+	if f.writer != nil {
+		reject(newFsError(ForbiddenError, "read() failed; reason: the file was opened for writing"))
+		return promise
+	}
+
 	if common.IsNullish(into) {
 		reject(newFsError(TypeError, "read() failed; reason: into argument cannot be null or undefined"))
 		return promise
@@ -260,6 +385,11 @@ func (f *File) Read(into sobek.Value) *sobek.Promise {
 func (f *File) Seek(offset sobek.Value, whence sobek.Value) *sobek.Promise {
 	promise, resolve, reject := f.vu.Runtime().NewPromise()
 
+	if f.writer != nil {
+		reject(newFsError(ForbiddenError, "seek() failed; reason: the file was opened for writing"))
+		return promise
+	}
+
 	intOffset, err := exportInt(offset)
 	if err != nil {
 		reject(newFsError(TypeError, "seek() failed; reason: the offset argument "+err.Error()))
@@ -1,11 +1,14 @@
 package fs
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"net/url"
 	"path/filepath"
 	"testing"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.k6.io/k6/js/compiler"
@@ -13,6 +16,7 @@ import (
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/metrics"
+	"gopkg.in/guregu/null.v3"
 )
 
 const testFileName = "bonjour.txt"
@@ -597,6 +601,610 @@ func TestFile(t *testing.T) {
 	})
 }
 
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	t.Run("opening a file for writing without allowing file writes should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			try {
+				const file = await fs.open('bonjour.txt', 'w');
+				throw 'unexpected promise resolution with result: ' + file;
+			} catch (err) {
+				if (err.name !== 'ForbiddenError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("opening a file with an invalid mode should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.RuntimeOptions.AllowFileWrites = null.BoolFrom(true)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			try {
+				const file = await fs.open('bonjour.txt', 'x');
+				throw 'unexpected promise resolution with result: ' + file;
+			} catch (err) {
+				if (err.name !== 'TypeError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("writing to a file opened for writing should succeed", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.RuntimeOptions.AllowFileWrites = null.BoolFrom(true)
+
+		testFilePath := fsext.FilePathSeparator + testFileName
+		fs := newTestFs(t, func(fs fsext.Fs) error {
+			return fsext.WriteFile(fs, testFilePath, []byte("Hello, world"), 0o644)
+		})
+		runtime.VU.InitEnvField.FileSystems["file"] = fs
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q, 'w');
+
+			let n = await file.write('Bonjour, ');
+			if (n !== 9) {
+				throw 'unexpected number of bytes written ' + n + '; expected 9';
+			}
+
+			n = await file.write(new Uint8Array([108, 101, 32, 109, 111, 110, 100, 101]));
+			if (n !== 8) {
+				throw 'unexpected number of bytes written ' + n + '; expected 8';
+			}
+
+			const info = await file.stat();
+			if (info.size !== 17) {
+				throw 'unexpected file size ' + info.size + '; expected 17';
+			}
+
+			await file.close();
+
+			try {
+				await file.write('!');
+				throw 'unexpected write to a closed file';
+			} catch (err) {
+				if (err.name !== 'ForbiddenError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`, testFilePath)))
+		require.NoError(t, err)
+
+		got, err := fsext.ReadFile(fs, testFilePath)
+		require.NoError(t, err)
+		assert.Equal(t, "Bonjour, le monde", string(got))
+	})
+
+	t.Run("writing to a file opened for appending should append to it", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.RuntimeOptions.AllowFileWrites = null.BoolFrom(true)
+
+		testFilePath := fsext.FilePathSeparator + testFileName
+		fs := newTestFs(t, func(fs fsext.Fs) error {
+			return fsext.WriteFile(fs, testFilePath, []byte("Bonjour"), 0o644)
+		})
+		runtime.VU.InitEnvField.FileSystems["file"] = fs
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q, 'a');
+			await file.write(', le monde');
+			await file.close();
+		`, testFilePath)))
+		require.NoError(t, err)
+
+		got, err := fsext.ReadFile(fs, testFilePath)
+		require.NoError(t, err)
+		assert.Equal(t, "Bonjour, le monde", string(got))
+	})
+
+	t.Run("reading from a file opened for writing should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.RuntimeOptions.AllowFileWrites = null.BoolFrom(true)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			const file = await fs.open('bonjour.txt', 'w');
+
+			try {
+				await file.read(new Uint8Array(4));
+				throw 'unexpected read from a file opened for writing';
+			} catch (err) {
+				if (err.name !== 'ForbiddenError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("writing to a file opened for reading should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.RuntimeOptions.AllowFileWrites = null.BoolFrom(true)
+
+		testFilePath := fsext.FilePathSeparator + testFileName
+		fs := newTestFs(t, func(fs fsext.Fs) error {
+			return fsext.WriteFile(fs, testFilePath, []byte("Bonjour"), 0o644)
+		})
+		runtime.VU.InitEnvField.FileSystems["file"] = fs
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+
+			try {
+				await file.write('!');
+				throw 'unexpected write to a file opened for reading';
+			} catch (err) {
+				if (err.name !== 'ForbiddenError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("writeFile and appendFile should succeed in the VU context", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.RuntimeOptions.AllowFileWrites = null.BoolFrom(true)
+
+		fs := fsext.NewMemMapFs()
+		runtime.VU.InitEnvField.FileSystems["file"] = fs
+
+		runtime.MoveToVUContext(&lib.State{
+			Tags: lib.NewVUStateTags(metrics.NewRegistry().RootTagSet().With("tag-vu", "mytag")),
+		})
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			await fs.writeFile('bonjour.txt', 'Hello');
+			await fs.writeFile('bonjour.txt', 'Bonjour');
+			await fs.appendFile('bonjour.txt', new Uint8Array([44, 32, 108, 101, 32, 109, 111, 110, 100, 101]).buffer);
+			await fs.appendFile('created.txt', 'created');
+		`))
+		require.NoError(t, err)
+
+		got, err := fsext.ReadFile(fs, fsext.FilePathSeparator+"bonjour.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "Bonjour, le monde", string(got))
+
+		got, err = fsext.ReadFile(fs, fsext.FilePathSeparator+"created.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "created", string(got))
+	})
+
+	t.Run("writeFile without allowing file writes should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			try {
+				await fs.writeFile('bonjour.txt', 'Bonjour');
+				throw 'unexpected promise resolution';
+			} catch (err) {
+				if (err.name !== 'ForbiddenError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`))
+		require.NoError(t, err)
+
+		exists, err := fsext.Exists(runtime.VU.InitEnvField.FileSystems["file"], fsext.FilePathSeparator+"bonjour.txt")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestReaddir(t *testing.T) {
+	t.Parallel()
+
+	newListingFs := func(t *testing.T) fsext.Fs {
+		return newTestFs(t, func(fs fsext.Fs) error {
+			for path, content := range map[string]string{
+				"/data/b.json":        "{}",
+				"/data/a.json":        `{"a":1}`,
+				"/data/notes.txt":     "notes",
+				"/data/nested/c.json": "[]",
+			} {
+				if err := fsext.WriteFile(fs, filepath.FromSlash(path), []byte(content), 0o644); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+
+	t.Run("readdir should list the entries of a directory", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.FileSystems["file"] = newListingFs(t)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			const entries = await fs.readdir('data');
+
+			const got = entries.map((e) => e.name + ':' + e.size + ':' + e.isDirectory).join(',');
+			if (got !== 'a.json:7:false,b.json:2:false,nested:' + entries[2].size + ':true,notes.txt:5:false') {
+				throw 'unexpected entries ' + got;
+			}
+
+			if (entries[0].path !== '/data/a.json' && entries[0].path !== '\\data\\a.json') {
+				throw 'unexpected path ' + entries[0].path;
+			}
+
+			if (typeof entries[0].mtime !== 'number' || entries[0].mtime <= 0) {
+				throw 'unexpected mtime ' + entries[0].mtime;
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("readdir on a file should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.FileSystems["file"] = newListingFs(t)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			try {
+				await fs.readdir('data/a.json');
+				throw 'unexpected promise resolution';
+			} catch (err) {
+				if (err.name !== 'InvalidResourceError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("readdir on a non existing directory should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			try {
+				await fs.readdir('missing');
+				throw 'unexpected promise resolution';
+			} catch (err) {
+				if (err.name !== 'NotFoundError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("glob should list the entries matching a pattern", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.FileSystems["file"] = newListingFs(t)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			const entries = await fs.glob('data/*.json');
+
+			const got = entries.map((e) => e.name).join(',');
+			if (got !== 'a.json,b.json') {
+				throw 'unexpected entries ' + got;
+			}
+
+			const none = await fs.glob('data/*.csv');
+			if (none.length !== 0) {
+				throw 'unexpected entries ' + none.map((e) => e.name).join(',');
+			}
+
+			try {
+				await fs.glob('data/[');
+				throw 'unexpected promise resolution';
+			} catch (err) {
+				if (err.name !== 'TypeError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("listing files in VU context should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		runtime.MoveToVUContext(&lib.State{
+			Tags: lib.NewVUStateTags(metrics.NewRegistry().RootTagSet().With("tag-vu", "mytag")),
+		})
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			for (const list of [() => fs.readdir('data'), () => fs.glob('data/*')]) {
+				try {
+					await list();
+					throw 'unexpected promise resolution';
+				} catch (err) {
+					if (err.name !== 'ForbiddenError') {
+						throw 'unexpected error: ' + err;
+					}
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestDecompression(t *testing.T) {
+	t.Parallel()
+
+	const content = "0123456789"
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	_, err := gw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	zw, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	zstded := zw.EncodeAll([]byte(content), nil)
+
+	newCompressedFs := func(t *testing.T) fsext.Fs {
+		return newTestFs(t, func(fs fsext.Fs) error {
+			for path, data := range map[string][]byte{
+				"/data.txt.gz":  gzipped.Bytes(),
+				"/data.txt.zst": zstded,
+				"/invalid.gz":   []byte(content),
+			} {
+				if err := fsext.WriteFile(fs, filepath.FromSlash(path), data, 0o644); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+
+	for _, path := range []string{"/data.txt.gz", "/data.txt.zst"} {
+		path := path
+
+		t.Run("opening "+path+" should read its decompressed content", func(t *testing.T) {
+			t.Parallel()
+
+			runtime, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+			runtime.VU.InitEnvField.FileSystems["file"] = newCompressedFs(t)
+
+			_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+
+				const info = await file.stat();
+				if (info.size !== 10) {
+					throw 'expected size to be 10, got ' + info.size + ' instead';
+				}
+
+				let buffer = new Uint8Array(4);
+				let bytesRead = await file.read(buffer);
+				if (bytesRead !== 4 || buffer[0] !== 48 || buffer[3] !== 51) {
+					throw 'unexpected first read of ' + bytesRead + ' bytes: ' + buffer;
+				}
+
+				let offset = await file.seek(8, fs.SeekMode.Start);
+				if (offset !== 8) {
+					throw 'unexpected offset ' + offset;
+				}
+
+				bytesRead = await file.read(buffer);
+				if (bytesRead !== 2 || buffer[0] !== 56 || buffer[1] !== 57) {
+					throw 'unexpected read after seeking forward of ' + bytesRead + ' bytes: ' + buffer;
+				}
+
+				offset = await file.seek(1, fs.SeekMode.Start);
+				bytesRead = await file.read(buffer);
+				if (offset !== 1 || bytesRead !== 4 || buffer[0] !== 49 || buffer[3] !== 52) {
+					throw 'unexpected read after seeking backward of ' + bytesRead + ' bytes: ' + buffer;
+				}
+			`, path)))
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("opening a compressed file with decompress disabled should read its raw content", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.FileSystems["file"] = newCompressedFs(t)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open('/data.txt.gz', { decompress: false });
+
+			const info = await file.stat();
+			if (info.size !== %d) {
+				throw 'unexpected size ' + info.size;
+			}
+
+			let buffer = new Uint8Array(2);
+			await file.read(buffer);
+			if (buffer[0] !== 0x1f || buffer[1] !== 0x8b) {
+				throw 'expected the gzip magic bytes, got ' + buffer + ' instead';
+			}
+		`, gzipped.Len())))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("opening an invalid compressed file should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.FileSystems["file"] = newCompressedFs(t)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			try {
+				await fs.open('/invalid.gz');
+				throw 'unexpected promise resolution';
+			} catch (err) {
+				if (err.name !== 'InvalidResourceError') {
+					throw 'unexpected error: ' + err;
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestOpenStream(t *testing.T) {
+	t.Parallel()
+
+	const content = "0123456789"
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	_, err := gw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	newStreamFs := func(t *testing.T) fsext.Fs {
+		return newTestFs(t, func(fs fsext.Fs) error {
+			if err := fsext.WriteFile(fs, fsext.FilePathSeparator+"data.txt", []byte(content), 0o644); err != nil {
+				return err
+			}
+			if err := fsext.WriteFile(fs, fsext.FilePathSeparator+"data.txt.gz", gzipped.Bytes(), 0o644); err != nil {
+				return err
+			}
+			return fs.Mkdir(fsext.FilePathSeparator+"dir", 0o644)
+		})
+	}
+
+	const readAll = `
+		async function readAll(stream) {
+			const reader = stream.getReader();
+			let text = "";
+			for (;;) {
+				const { done, value } = await reader.read();
+				if (done) {
+					return text;
+				}
+				text += String.fromCharCode(...value);
+			}
+		}
+	`
+
+	t.Run("streaming a file should read its content", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.FileSystems["file"] = newStreamFs(t)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(readAll + `
+			for (const [path, options, expected] of [
+				["data.txt", undefined, "0123456789"],
+				["data.txt.gz", undefined, "0123456789"],
+				["data.txt.gz", { decompress: false }, null],
+			]) {
+				const text = await readAll(await fs.openStream(path, options));
+				if (expected !== null && text !== expected) {
+					throw 'unexpected content of ' + path + ': ' + text;
+				}
+				if (expected === null && text.length !== ` + fmt.Sprint(gzipped.Len()) + `) {
+					throw 'unexpected length of the compressed ' + path + ': ' + text.length;
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("streaming a file in VU context should succeed", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.FileSystems["file"] = newStreamFs(t)
+
+		runtime.MoveToVUContext(&lib.State{
+			Tags: lib.NewVUStateTags(metrics.NewRegistry().RootTagSet().With("tag-vu", "mytag")),
+		})
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(readAll + `
+			const text = await readAll(await fs.openStream("data.txt"));
+			if (text !== "0123456789") {
+				throw 'unexpected content: ' + text;
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("streaming a missing file or a directory should fail", func(t *testing.T) {
+		t.Parallel()
+
+		runtime, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+		runtime.VU.InitEnvField.FileSystems["file"] = newStreamFs(t)
+
+		_, err = runtime.RunOnEventLoop(wrapInAsyncLambda(`
+			for (const [path, name] of [["missing.txt", "NotFoundError"], ["dir", "InvalidResourceError"]]) {
+				try {
+					await fs.openStream(path);
+					throw 'unexpected promise resolution for ' + path;
+				} catch (err) {
+					if (err.name !== name) {
+						throw 'unexpected error for ' + path + ': ' + err;
+					}
+				}
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+}
+
 func TestOpenImpl(t *testing.T) {
 	t.Parallel()
 
@@ -612,7 +1220,7 @@ func TestOpenImpl(t *testing.T) {
 			cache: &cache{},
 		}
 
-		f, gotErr := mi.openImpl(testFileName)
+		f, gotErr := mi.openImpl(testFileName, true)
 
 		assert.Error(t, gotErr)
 		assert.Nil(t, f)
@@ -629,7 +1237,7 @@ func TestOpenImpl(t *testing.T) {
 			cache: &cache{},
 		}
 
-		_, err = mi.openImpl(testFileName)
+		_, err = mi.openImpl(testFileName, true)
 		assert.Error(t, err)
 		var fsError *fsError
 		assert.ErrorAs(t, err, &fsError)
@@ -652,7 +1260,7 @@ func TestOpenImpl(t *testing.T) {
 			cache: &cache{},
 		}
 
-		_, err = mi.openImpl("/dir")
+		_, err = mi.openImpl("/dir", true)
 		assert.Error(t, err)
 		var fsError *fsError
 		assert.ErrorAs(t, err, &fsError)
@@ -676,7 +1284,7 @@ func TestOpenImpl(t *testing.T) {
 			cache: &cache{},
 		}
 
-		_, err = mi.openImpl("../bonjour.txt")
+		_, err = mi.openImpl("../bonjour.txt", true)
 		assert.NoError(t, err)
 	})
 }
@@ -0,0 +1,102 @@
+package fs
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules/k6/experimental/streams"
+	"go.k6.io/k6/js/promises"
+	"go.k6.io/k6/lib/fsext"
+)
+
+// OpenStream opens a file for reading and returns a promise that will resolve
+// to a ReadableStream of its content, as Uint8Array chunks.
+//
+// As opposed to [ModuleInstance.Open], the content of the file is read lazily,
+// as the stream is consumed, instead of being loaded in memory as a whole, so
+// big assets, e.g. datasets or upload payloads, can be streamed. It can be
+// called both in the init context and in the VU context, as long as the file
+// was opened in the init context first, which bundles it in the archives.
+//
+// Files which extension is `.gz` or `.zst` are transparently decompressed, like
+// the ones opened with open() are, unless the optional options argument has its
+// `decompress` property set to false.
+func (mi *ModuleInstance) OpenStream(path sobek.Value, options sobek.Value) *sobek.Promise {
+	promise, resolve, reject := promises.New(mi.vu)
+
+	if common.IsNullish(path) {
+		reject(newFsError(TypeError, "openStream() failed; reason: path cannot be null or undefined"))
+		return promise
+	}
+	pathStr := path.String()
+	if pathStr == "" {
+		reject(newFsError(TypeError, "openStream() failed; reason: path cannot be empty"))
+		return promise
+	}
+
+	decompress := true
+	if obj, ok := options.(*sobek.Object); ok {
+		if v := obj.Get("decompress"); !common.IsNullish(v) {
+			decompress = v.ToBoolean()
+		}
+	}
+
+	rc, err := mi.openStreamImpl(pathStr, decompress)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	resolve(streams.NewReadableStreamFromReadCloser(mi.vu, rc))
+	return promise
+}
+
+func (mi *ModuleInstance) openStreamImpl(path string, decompress bool) (io.ReadCloser, error) {
+	if mi.initEnv == nil {
+		return nil, newFsError(ForbiddenError, "openStream() failed; reason: the init environment is not available")
+	}
+	path = fsext.Abs(mi.initEnv.CWD.Path, path)
+
+	fs, ok := mi.initEnv.FileSystems["file"]
+	if !ok {
+		return nil, fmt.Errorf("openStream() failed; reason: unable to access the file system")
+	}
+
+	if exists, err := fsext.Exists(fs, path); err != nil {
+		return nil, fmt.Errorf("openStream() failed, unable to verify if %q exists; reason: %w", path, err)
+	} else if !exists {
+		return nil, newFsError(NotFoundError, fmt.Sprintf("no such file or directory %q", path))
+	}
+
+	if isDir, err := fsext.IsDir(fs, path); err != nil {
+		return nil, fmt.Errorf("openStream() failed, unable to verify if %q is a directory; reason: %w", path, err)
+	} else if isDir {
+		return nil, newFsError(
+			InvalidResourceError,
+			fmt.Sprintf("cannot open %q: opening a directory is not supported", path),
+		)
+	}
+
+	f, err := fs.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("openStream() failed; reason: %w", err)
+	}
+
+	compression := CompressionFromPath(path)
+	if !decompress || compression == "" {
+		return f, nil
+	}
+
+	dr, err := NewDecompressingReader(f, compression)
+	if err != nil {
+		_ = f.Close()
+		return nil, newFsError(InvalidResourceError, fmt.Sprintf("cannot open %q: %s", path, err))
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{dr, f}, nil
+}
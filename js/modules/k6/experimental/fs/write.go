@@ -0,0 +1,327 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/grafana/sobek"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/promises"
+	"go.k6.io/k6/lib/fsext"
+)
+
+// OpenMode indicates what a file is opened for.
+type OpenMode string
+
+const (
+	// OpenModeRead opens a file for reading.
+	OpenModeRead OpenMode = "r"
+
+	// OpenModeWrite opens a file for writing, creating it if it does not
+	// exist, and truncating it otherwise.
+	OpenModeWrite OpenMode = "w"
+
+	// OpenModeAppend opens a file for writing at its end, creating it if it
+	// does not exist.
+	OpenModeAppend OpenMode = "a"
+)
+
+// flags returns the flags to open a file under the mode with.
+func (m OpenMode) flags() int {
+	if m == OpenModeAppend {
+		return os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	return os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+}
+
+// writableFile is the file a [File] opened for writing writes to.
+type writableFile interface {
+	io.Writer
+	io.Closer
+
+	Stat() (os.FileInfo, error)
+}
+
+// writer writes to a file opened for writing.
+type writer struct {
+	// mu serializes the writes, which happen in background goroutines.
+	mu sync.Mutex
+
+	file writableFile
+
+	// closed indicates whether the file has been closed.
+	closed bool
+}
+
+// write writes the provided data to the file.
+func (w *writer) write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, newFsError(ForbiddenError, "write() failed; reason: the file is closed")
+	}
+
+	return w.file.Write(data)
+}
+
+// close closes the file. Closing a closed file has no effect.
+func (w *writer) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+
+	return w.file.Close()
+}
+
+// stat returns a FileInfo describing the file.
+func (w *writer) stat(path string) (*FileInfo, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := w.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileInfo{Name: filepath.Base(path), Size: info.Size()}, nil
+}
+
+// WriteFile writes the provided data, either a string or an ArrayBuffer or
+// typed array, to the file at the given path, creating it if it does not
+// exist, and replacing its content otherwise.
+//
+// Like [ModuleInstance.AppendFile], and unlike opening a file, it can be used
+// outside of the init context, for instance to persist per-VU artifacts.
+// Writing files must be allowed through the `--allow-file-writes` flag.
+func (mi *ModuleInstance) WriteFile(path sobek.Value, data sobek.Value) *sobek.Promise {
+	return mi.writeFile("writeFile", OpenModeWrite, path, data)
+}
+
+// AppendFile appends the provided data, either a string or an ArrayBuffer or
+// typed array, to the file at the given path, creating it if it does not exist.
+//
+// Writing files must be allowed through the `--allow-file-writes` flag.
+func (mi *ModuleInstance) AppendFile(path sobek.Value, data sobek.Value) *sobek.Promise {
+	return mi.writeFile("appendFile", OpenModeAppend, path, data)
+}
+
+// writeFile implements [ModuleInstance.WriteFile] and [ModuleInstance.AppendFile],
+// which the provided operation name designates.
+func (mi *ModuleInstance) writeFile(op string, mode OpenMode, path sobek.Value, data sobek.Value) *sobek.Promise {
+	promise, resolve, reject := promises.New(mi.vu)
+
+	pathStr, err := exportPath(op, path)
+	if err != nil {
+		reject(err)
+		return promise
+	}
+
+	// The data is copied, so that it is not modified while being written.
+	bytes, err := exportBytes(data)
+	if err != nil {
+		reject(newFsError(TypeError, op+"() failed; reason: the data argument "+err.Error()))
+		return promise
+	}
+
+	go func() {
+		f, err := mi.openWritable(op, mi.absPath(pathStr), mode)
+		if err != nil {
+			reject(err)
+			return
+		}
+
+		_, err = f.Write(bytes)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			reject(fmt.Errorf("%s() failed; reason: %w", op, err))
+			return
+		}
+
+		resolve(sobek.Undefined())
+	}()
+
+	return promise
+}
+
+// openWritableImpl opens the file at the provided path for writing, under the
+// given mode, and returns a [File] writing to it.
+func (mi *ModuleInstance) openWritableImpl(path string, mode OpenMode) (*File, error) {
+	path = mi.absPath(path)
+
+	f, err := mi.openWritable("open", path, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{
+		Path:   path,
+		file:   file{path: path},
+		writer: &writer{file: f},
+		vu:     mi.vu,
+		cache:  mi.cache,
+	}, nil
+}
+
+// openWritable opens the file at the provided absolute path for writing, under
+// the given mode, on behalf of the operation the provided name designates, if
+// writing files has been allowed.
+func (mi *ModuleInstance) openWritable(op string, path string, mode OpenMode) (writableFile, error) {
+	if mi.initEnv == nil || !mi.initEnv.RuntimeOptions.AllowFileWrites.Bool {
+		return nil, newFsError(
+			ForbiddenError,
+			op+"() failed; reason: writing files is not allowed, use the --allow-file-writes flag to allow it",
+		)
+	}
+
+	fs, ok := mi.initEnv.FileSystems["file"]
+	if !ok {
+		return nil, errors.New(op + "() failed; reason: unable to access the file system")
+	}
+
+	if isDir, err := fsext.IsDir(fs, path); err == nil && isDir {
+		return nil, newFsError(
+			InvalidResourceError,
+			fmt.Sprintf("cannot open %q: writing to a directory is not supported", path),
+		)
+	}
+
+	f, err := fs.OpenFile(path, mode.flags(), 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, newFsError(NotFoundError, fmt.Sprintf("no such file or directory %q", filepath.Dir(path)))
+		}
+
+		return nil, fmt.Errorf("%s() failed, unable to open %q for writing; reason: %w", op, path, err)
+	}
+
+	return f, nil
+}
+
+// absPath resolves the provided path relative to the entrypoint script.
+//
+// See [ModuleInstance.openImpl] for the rationale.
+func (mi *ModuleInstance) absPath(path string) string {
+	if mi.initEnv == nil || mi.initEnv.CWD == nil {
+		return path
+	}
+
+	return fsext.Abs(mi.initEnv.CWD.Path, path)
+}
+
+// Write writes the provided data, either a string or an ArrayBuffer or typed
+// array, to a file opened for writing.
+//
+// Resolves to the number of bytes written.
+func (f *File) Write(data sobek.Value) *sobek.Promise {
+	promise, resolve, reject := promises.New(f.vu)
+
+	if f.writer == nil {
+		reject(newFsError(ForbiddenError, "write() failed; reason: the file was not opened for writing"))
+		return promise
+	}
+
+	// The data is copied, so that it is not modified while being written.
+	bytes, err := exportBytes(data)
+	if err != nil {
+		reject(newFsError(TypeError, "write() failed; reason: the data argument "+err.Error()))
+		return promise
+	}
+
+	go func() {
+		n, err := f.writer.write(bytes)
+
+		var fsErr *fsError
+		if errors.As(err, &fsErr) {
+			reject(fsErr)
+			return
+		}
+
+		if err != nil {
+			reject(fmt.Errorf("write() failed; reason: %w", err))
+			return
+		}
+
+		resolve(n)
+	}()
+
+	return promise
+}
+
+// Close closes a file opened for writing, after which it cannot be written to
+// anymore. It has no effect on files opened for reading.
+func (f *File) Close() *sobek.Promise {
+	promise, resolve, reject := promises.New(f.vu)
+
+	if f.writer == nil {
+		resolve(sobek.Undefined())
+		return promise
+	}
+
+	go func() {
+		if err := f.writer.close(); err != nil {
+			reject(fmt.Errorf("close() failed; reason: %w", err))
+			return
+		}
+
+		resolve(sobek.Undefined())
+	}()
+
+	return promise
+}
+
+// exportPath returns the path the provided value holds, as the path argument
+// of the operation the given name designates.
+func exportPath(op string, path sobek.Value) (string, error) {
+	if common.IsNullish(path) {
+		return "", newFsError(TypeError, op+"() failed; reason: path cannot be null or undefined")
+	}
+
+	pathStr := path.String()
+	if pathStr == "" {
+		return "", newFsError(TypeError, op+"() failed; reason: path cannot be empty")
+	}
+
+	return pathStr, nil
+}
+
+// exportBytes returns a copy of the bytes the provided value, either a string,
+// an ArrayBuffer, or a typed array, holds.
+func exportBytes(v sobek.Value) ([]byte, error) {
+	if common.IsNullish(v) {
+		return nil, errors.New("cannot be null or undefined")
+	}
+
+	exported := v.Export()
+
+	// Typed arrays are exported as slices of their elements, so their bytes are
+	// obtained from the ArrayBuffer they are a view of.
+	if obj, ok := v.(*sobek.Object); ok && obj.Get("buffer") != nil {
+		if ab, ok := obj.Get("buffer").Export().(sobek.ArrayBuffer); ok {
+			offset := obj.Get("byteOffset").ToInteger()
+			length := obj.Get("byteLength").ToInteger()
+
+			return append([]byte(nil), ab.Bytes()[offset:offset+length]...), nil
+		}
+	}
+
+	b, err := common.ToBytes(exported)
+	if err != nil {
+		return nil, errors.New("must be a string, an ArrayBuffer, or a typed array")
+	}
+
+	return append([]byte(nil), b...), nil
+}
@@ -0,0 +1,119 @@
+// Package hooks provides a k6 module registering the lifecycle hooks of the
+// script, which are invoked around each of its iterations and each of its HTTP
+// and gRPC requests, e.g. to inject an authentication token or tag all the
+// requests, without changing all the places they are made.
+package hooks
+
+import (
+	"fmt"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
+)
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the hooks module for a single VU.
+	ModuleInstance struct {
+		vu modules.VU
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"onIterationStart": mi.OnIterationStart,
+			"onIterationEnd":   mi.OnIterationEnd,
+			"onRequest":        mi.OnRequest,
+		},
+	}
+}
+
+// OnIterationStart registers a function called at the start of each iteration,
+// before the function of the scenario. An exception it throws fails the
+// iteration.
+func (mi *ModuleInstance) OnIterationStart(fn sobek.Value) error {
+	hooks, callable, err := mi.register("onIterationStart", fn)
+	if err != nil {
+		return err
+	}
+	hooks.OnIterationStart(func() error {
+		_, err := callable(sobek.Undefined())
+		return err
+	})
+	return nil
+}
+
+// OnIterationEnd registers a function called at the end of each iteration which
+// wasn't interrupted, once its async operations are done, with an object whose
+// error property is the error of the iteration, if it failed.
+func (mi *ModuleInstance) OnIterationEnd(fn sobek.Value) error {
+	hooks, callable, err := mi.register("onIterationEnd", fn)
+	if err != nil {
+		return err
+	}
+	hooks.OnIterationEnd(func(iterErr error) error {
+		rt := mi.vu.Runtime()
+		info := rt.NewObject()
+		if iterErr != nil {
+			if err := info.Set("error", iterErr.Error()); err != nil {
+				return err
+			}
+		}
+		_, err := callable(sobek.Undefined(), info)
+		return err
+	})
+	return nil
+}
+
+// OnRequest registers a function called before each HTTP and gRPC request is
+// sent, with the request, whose headers, or metadata, and tags it can change.
+// An exception it throws fails the request.
+func (mi *ModuleInstance) OnRequest(fn sobek.Value) error {
+	hooks, callable, err := mi.register("onRequest", fn)
+	if err != nil {
+		return err
+	}
+	hooks.OnRequest(func(req *lib.HookedRequest) error {
+		_, err := callable(sobek.Undefined(), mi.vu.Runtime().ToValue(req))
+		return err
+	})
+	return nil
+}
+
+// register returns the hooks of the VU, and the function to register in them.
+func (mi *ModuleInstance) register(name string, fn sobek.Value) (*lib.Hooks, sobek.Callable, error) {
+	initEnv := mi.vu.InitEnv()
+	if initEnv == nil || initEnv.Hooks == nil {
+		return nil, nil, common.NewInitContextError("the hooks must be registered in the init context")
+	}
+	callable, ok := sobek.AssertFunction(fn)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s() needs a function", name)
+	}
+	return initEnv.Hooks, callable, nil
+}
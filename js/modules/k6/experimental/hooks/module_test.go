@@ -0,0 +1,81 @@
+package hooks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+)
+
+func newHooksRuntime(t *testing.T) *modulestest.Runtime {
+	t.Helper()
+
+	runtime := modulestest.NewRuntime(t)
+	err := runtime.SetupModuleSystem(map[string]any{"k6/experimental/hooks": New()}, nil, nil)
+	require.NoError(t, err)
+	_, err = runtime.VU.Runtime().RunString(
+		`var { onIterationStart, onIterationEnd, onRequest } = require("k6/experimental/hooks");`)
+	require.NoError(t, err)
+
+	return runtime
+}
+
+func TestHooks(t *testing.T) {
+	t.Parallel()
+
+	runtime := newHooksRuntime(t)
+	_, err := runtime.VU.Runtime().RunString(`
+		var calls = [];
+		onIterationStart(() => calls.push("start"));
+		onIterationEnd((info) => calls.push("end: " + info.error));
+		onRequest((req) => {
+			calls.push(req.protocol + " " + req.method + " " + req.url);
+			req.headers["authorization"] = "Bearer " + req.tags.name;
+			req.tags.hooked = "yes";
+		});
+	`)
+	require.NoError(t, err)
+
+	registry := runtime.VU.InitEnvField.Registry
+	state := &lib.State{}
+	runtime.MoveToVUContext(state)
+	require.NoError(t, state.Hooks.IterationStart())
+
+	req := lib.NewHookedRequest("grpc", "/grpc.Service/Method", "localhost:9000/grpc.Service/Method", nil,
+		registry.RootTagSet().With("name", "method"))
+	require.NoError(t, state.Hooks.Request(req))
+	set, removed := req.HeaderChanges()
+	assert.Equal(t, map[string]string{"authorization": "Bearer method"}, set)
+	assert.Empty(t, removed)
+	assert.Equal(t, map[string]string{"name": "method", "hooked": "yes"}, req.Tags)
+
+	require.NoError(t, state.Hooks.IterationEnd(errors.New("iteration failed")))
+
+	calls, err := runtime.VU.Runtime().RunString(`calls.join("\n")`)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"start\ngrpc /grpc.Service/Method localhost:9000/grpc.Service/Method\nend: iteration failed",
+		calls.String())
+}
+
+func TestHooksErrors(t *testing.T) {
+	t.Parallel()
+
+	runtime := newHooksRuntime(t)
+	_, err := runtime.VU.Runtime().RunString(`onRequest("not a function")`)
+	assert.ErrorContains(t, err, "onRequest() needs a function")
+
+	_, err = runtime.VU.Runtime().RunString(`onIterationStart(() => { throw new Error("no start") })`)
+	require.NoError(t, err)
+
+	state := &lib.State{}
+	runtime.MoveToVUContext(state)
+	assert.ErrorContains(t, state.Hooks.IterationStart(), "no start")
+
+	_, err = runtime.VU.Runtime().RunString(`onIterationEnd(() => {})`)
+	assert.ErrorContains(t, err, "the hooks must be registered in the init context")
+}
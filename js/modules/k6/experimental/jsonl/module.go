@@ -0,0 +1,293 @@
+// Package jsonl provides a k6 module that allows users to parse JSON Lines
+// files, holding one JSON value per line, opened through the [fs] module, in
+// a streaming fashion.
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/grafana/sobek"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/modules/k6/experimental/fs"
+)
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the jsonl module for a single VU.
+	ModuleInstance struct {
+		vu modules.VU
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"Parser": mi.NewParser,
+		},
+	}
+}
+
+// Parser is a JSON Lines parser.
+type Parser struct {
+	// currentLine holds the number of lines read by the parser so far,
+	// including the ones that were skipped.
+	currentLine atomic.Int64
+
+	// reader reads the lines of the provided input file. As opposed to a
+	// [bufio.Scanner], it does not limit the length of lines.
+	reader *bufio.Reader
+
+	// mu serializes the reads, which happen in background goroutines.
+	mu sync.Mutex
+
+	// options holds the parser's options as provided by the user.
+	options parserOptions
+
+	// vu is the VU instance that owns this module instance.
+	vu modules.VU
+}
+
+// parseResult holds the result of a JSON Lines parser's parsing operation such
+// as when calling the [Parser.Next] method.
+type parseResult struct {
+	// Done indicates whether the parser has finished reading the file.
+	Done bool `js:"done"`
+
+	// Value holds the line's JSON value.
+	Value sobek.Value `js:"value"`
+}
+
+// NewParser creates a new JSON Lines parser instance.
+//
+// The parser is async iterable, each iteration resolving to the result
+// [Parser.Next] would.
+func (mi *ModuleInstance) NewParser(call sobek.ConstructorCall) *sobek.Object {
+	rt := mi.vu.Runtime()
+
+	if mi.vu.State() != nil {
+		common.Throw(rt, errors.New("jsonl Parser constructor must be called in the init context"))
+	}
+
+	if len(call.Arguments) < 1 || common.IsNullish(call.Argument(0)) {
+		common.Throw(rt, errors.New("jsonl Parser constructor takes at least one non-nil source argument"))
+	}
+
+	// Obtain the file argument from the constructor call
+	var file *fs.File
+	if err := rt.ExportTo(call.Argument(0), &file); err != nil || file == nil || file.Impl == nil {
+		common.Throw(rt, fmt.Errorf("first argument expected to be a fs.File instance, got %T instead", call.Argument(0)))
+	}
+
+	options := newDefaultParserOptions()
+	if len(call.Arguments) > 1 && !common.IsNullish(call.Argument(1)) {
+		var err error
+		options, err = newParserOptionsFrom(call.Argument(1).ToObject(rt))
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("encountered an error while interpreting Parser options; reason: %w", err))
+		}
+	}
+
+	parser := &Parser{
+		reader:  bufio.NewReader(file.Impl),
+		options: options,
+		vu:      mi.vu,
+	}
+
+	// Skip the values preceding the one designated by the fromLine option
+	if options.FromLine.Valid && options.FromLine.Int64 > 0 {
+		for i := int64(0); i < options.FromLine.Int64; i++ {
+			if _, err := parser.readLine(); err != nil {
+				common.Throw(rt, fmt.Errorf("failed to skip lines until fromLine; reason: %w", err))
+			}
+		}
+	}
+
+	obj := rt.ToValue(parser).ToObject(rt)
+	common.MakeAsyncIterable(rt, obj, parser.Next)
+
+	return obj
+}
+
+// Next returns a promise resolving to the value held by the next line of the
+// JSON Lines file. Blank lines are skipped.
+//
+// Lines are read in a background goroutine, and parsed on the event loop, the
+// same way `JSON.parse` would parse them. Only a single line is held in memory
+// at a time, which makes the parser suitable to files too large to be parsed
+// at once.
+//
+// Once the end of the file, or the line configured through the `toLine`
+// option, has been reached, the promise resolves to a result which `done`
+// property is set to true. The promise is rejected if a line does not hold
+// a valid JSON value.
+func (p *Parser) Next() *sobek.Promise {
+	rt := p.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+	callback := p.vu.RegisterCallback()
+
+	go func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		line, err := p.next()
+		number := p.currentLine.Load()
+
+		callback(func() error {
+			if errors.Is(err, io.EOF) {
+				resolve(parseResult{Done: true, Value: sobek.Undefined()})
+				return nil
+			}
+
+			if err != nil {
+				reject(err)
+				return nil
+			}
+
+			value, err := parseJSON(rt, line)
+			if err != nil {
+				reject(fmt.Errorf("line %d: invalid JSON value; reason: %w", number, err))
+				return nil
+			}
+
+			resolve(parseResult{Done: false, Value: value})
+			return nil
+		})
+	}()
+
+	return promise
+}
+
+// next reads the next non-blank line, if the line configured through the
+// `toLine` option has not been reached yet.
+func (p *Parser) next() ([]byte, error) {
+	for {
+		// If the toLine option was set, and we have reached it, we're done.
+		if p.options.ToLine.Valid && p.currentLine.Load() >= p.options.ToLine.Int64 {
+			return nil, io.EOF
+		}
+
+		line, err := p.readLine()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			return line, nil
+		}
+	}
+}
+
+// readLine reads the next line, stripped of its line terminator, and accounts
+// for it in the parser's current line.
+//
+// The last line of the file is not required to end with a line terminator.
+func (p *Parser) readLine() ([]byte, error) {
+	line, err := p.reader.ReadBytes('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || len(line) == 0) {
+		return nil, err
+	}
+
+	p.currentLine.Add(1)
+
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r")), nil
+}
+
+// parseJSON parses the provided JSON text the way `JSON.parse` does.
+//
+// It must be called from the event loop.
+func parseJSON(rt *sobek.Runtime, text []byte) (sobek.Value, error) {
+	parse, ok := sobek.AssertFunction(rt.Get("JSON").ToObject(rt).Get("parse"))
+	if !ok {
+		return nil, errors.New("JSON.parse is not a function")
+	}
+
+	value, err := parse(sobek.Undefined(), rt.ToValue(string(text)))
+	if err != nil {
+		var ex *sobek.Exception
+		if errors.As(err, &ex) {
+			return nil, errors.New(ex.Value().String())
+		}
+
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// parserOptions holds options used to configure JSON Lines parsing when
+// utilizing the module.
+//
+// The options can be set by the user when instantiating a new [Parser].
+type parserOptions struct {
+	// FromLine indicates the number of lines to skip before starting to
+	// return values, blank lines included.
+	FromLine null.Int `js:"fromLine"`
+
+	// ToLine indicates the line at which to stop reading the file (inclusive),
+	// lines being numbered from 1.
+	ToLine null.Int `js:"toLine"`
+}
+
+// newDefaultParserOptions creates a new parserOptions instance with default values.
+func newDefaultParserOptions() parserOptions {
+	return parserOptions{}
+}
+
+// newParserOptionsFrom creates a new parserOptions instance from the given
+// Sobek object.
+func newParserOptionsFrom(obj *sobek.Object) (parserOptions, error) {
+	options := newDefaultParserOptions()
+
+	if obj == nil {
+		return options, nil
+	}
+
+	if v := obj.Get("fromLine"); v != nil {
+		options.FromLine = null.IntFrom(v.ToInteger())
+	}
+
+	if v := obj.Get("toLine"); v != nil {
+		options.ToLine = null.IntFrom(v.ToInteger())
+	}
+
+	if options.FromLine.Valid && options.FromLine.Int64 < 0 {
+		return options, errors.New("fromLine must be greater than or equal to 0")
+	}
+
+	if options.FromLine.Valid && options.ToLine.Valid && options.FromLine.Int64 >= options.ToLine.Int64 {
+		return options, errors.New("fromLine must be less than toLine")
+	}
+
+	return options, nil
+}
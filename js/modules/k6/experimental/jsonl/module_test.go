@@ -0,0 +1,241 @@
+package jsonl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/compiler"
+	"go.k6.io/k6/js/modules/k6/experimental/fs"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib/fsext"
+)
+
+// testFilePath holds the path to the JSON Lines file used in the tests.
+const testFilePath = fsext.FilePathSeparator + "data.jsonl"
+
+const testJSONL = `{"name":"foo","age":42}
+{"name":"bar","age":43,"tags":["a","b"]}
+{"name":"baz","age":44,"nested":{"ok":true}}
+`
+
+func TestParserConstructor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("constructing a parser without options should succeed", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testJSONL))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new jsonl.Parser(file);
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("constructing a parser without a file should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`new jsonl.Parser()`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "jsonl Parser constructor takes at least one non-nil source argument")
+	})
+
+	t.Run("constructing a parser from something other than a file should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`new jsonl.Parser(42)`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "first argument expected to be a fs.File instance")
+	})
+
+	t.Run("constructing a parser with invalid options should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testJSONL))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new jsonl.Parser(file, { fromLine: 2, toLine: 1 });
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fromLine must be less than toLine")
+	})
+}
+
+func TestParserNext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "next should return the value of each line",
+			content: testJSONL,
+			options: `{}`,
+			want:    `{"name":"foo","age":42}|{"name":"bar","age":43,"tags":["a","b"]}|{"name":"baz","age":44,"nested":{"ok":true}}`,
+		},
+		{
+			name:    "any JSON value should be supported",
+			content: "1\n\"two\"\n[3]\nnull\n",
+			options: `{}`,
+			want:    `1|"two"|[3]|null`,
+		},
+		{
+			name:    "blank lines should be skipped",
+			content: "{\"a\":1}\n\n  \r\n{\"a\":2}\r\n",
+			options: `{}`,
+			want:    `{"a":1}|{"a":2}`,
+		},
+		{
+			name:    "a last line without a line terminator should be read",
+			content: "{\"a\":1}\n{\"a\":2}",
+			options: `{}`,
+			want:    `{"a":1}|{"a":2}`,
+		},
+		{
+			name:    "fromLine and toLine should bound the lines read",
+			content: testJSONL,
+			options: `{ fromLine: 1, toLine: 2 }`,
+			want:    `{"name":"bar","age":43,"tags":["a","b"]}`,
+		},
+		{
+			name:    "a line holding invalid JSON should fail with its line number",
+			content: "{\"a\":1}\n{\"a\":\n",
+			options: `{}`,
+			wantErr: "line 2: invalid JSON value",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, tt.content))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new jsonl.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(JSON.stringify(value));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected values " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParserAsyncIterator(t *testing.T) {
+	t.Parallel()
+
+	// The runtime does not support the `Symbol.asyncIterator` well-known symbol,
+	// nor `for await...of` loops, so we define the former and emulate the latter.
+	const polyfill = `Symbol.asyncIterator = Symbol("Symbol.asyncIterator");`
+
+	r, err := newConfiguredRuntime(t)
+	require.NoError(t, err)
+
+	require.NoError(t, writeTestFile(r, testFilePath, testJSONL))
+
+	_, err = r.RunOnEventLoop(polyfill + wrapInAsyncLambda(fmt.Sprintf(`
+		const file = await fs.open(%q);
+		const parser = new jsonl.Parser(file);
+
+		const iterator = parser[Symbol.asyncIterator]();
+
+		let got = [];
+		for (let result = await iterator.next(); !result.done; result = await iterator.next()) {
+			got.push(result.value.name);
+		}
+
+		if (got.join("|") !== "foo|bar|baz") {
+			throw new Error("Unexpected values " + JSON.stringify(got));
+		}
+	`, testFilePath)))
+
+	assert.NoError(t, err)
+}
+
+const initGlobals = `
+	globalThis.fs = require("k6/experimental/fs");
+	globalThis.jsonl = require("k6/experimental/jsonl");
+`
+
+func newConfiguredRuntime(t testing.TB) (*modulestest.Runtime, error) {
+	runtime := modulestest.NewRuntime(t)
+
+	modules := map[string]interface{}{
+		"k6/experimental/fs":    fs.New(),
+		"k6/experimental/jsonl": New(),
+	}
+
+	err := runtime.SetupModuleSystem(modules, nil, compiler.New(runtime.VU.InitEnv().Logger))
+	if err != nil {
+		return nil, err
+	}
+
+	// Set up the VU environment with an in-memory filesystem and a CWD of "/".
+	runtime.VU.InitEnvField.FileSystems = map[string]fsext.Fs{
+		"file": fsext.NewMemMapFs(),
+	}
+	runtime.VU.InitEnvField.CWD = &url.URL{Scheme: "file"}
+
+	// Ensure the `fs` and `jsonl` modules are available in the VU's runtime.
+	_, err = runtime.VU.Runtime().RunString(initGlobals)
+
+	return runtime, err
+}
+
+// writeTestFile writes the provided content to the given path in the runtime's
+// in-memory filesystem.
+func writeTestFile(r *modulestest.Runtime, path, content string) error {
+	return fsext.WriteFile(r.VU.InitEnvField.FileSystems["file"], path, []byte(content), 0o644)
+}
+
+// wrapInAsyncLambda is a helper function that wraps the provided input in an async lambda. This
+// makes the use of `await` statements in the input possible.
+func wrapInAsyncLambda(input string) string {
+	// This makes it possible to use `await` freely on the "top" level
+	return "(async () => {\n " + input + "\n })()"
+}
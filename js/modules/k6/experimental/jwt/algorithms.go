@@ -0,0 +1,224 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	// the hash functions used by the algorithms have to be registered
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// algorithm is one of the JWS algorithms from RFC 7518.
+type algorithm struct {
+	name string
+	hash crypto.Hash
+	// family is the first two letters of the algorithm: HS, RS, PS or ES.
+	family string
+	// curve is only used by the ES algorithms.
+	curve elliptic.Curve
+}
+
+//nolint:gochecknoglobals
+var algorithms = map[string]algorithm{
+	"HS256": {name: "HS256", hash: crypto.SHA256, family: "HS"},
+	"HS384": {name: "HS384", hash: crypto.SHA384, family: "HS"},
+	"HS512": {name: "HS512", hash: crypto.SHA512, family: "HS"},
+	"RS256": {name: "RS256", hash: crypto.SHA256, family: "RS"},
+	"RS384": {name: "RS384", hash: crypto.SHA384, family: "RS"},
+	"RS512": {name: "RS512", hash: crypto.SHA512, family: "RS"},
+	"PS256": {name: "PS256", hash: crypto.SHA256, family: "PS"},
+	"PS384": {name: "PS384", hash: crypto.SHA384, family: "PS"},
+	"PS512": {name: "PS512", hash: crypto.SHA512, family: "PS"},
+	"ES256": {name: "ES256", hash: crypto.SHA256, family: "ES", curve: elliptic.P256()},
+	"ES384": {name: "ES384", hash: crypto.SHA384, family: "ES", curve: elliptic.P384()},
+	"ES512": {name: "ES512", hash: crypto.SHA512, family: "ES", curve: elliptic.P521()},
+}
+
+func getAlgorithm(name string) (algorithm, error) {
+	alg, ok := algorithms[name]
+	if !ok {
+		names := make([]string, 0, len(algorithms))
+		for n := range algorithms {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return alg, fmt.Errorf("unsupported algorithm %q, it should be one of %s", name, strings.Join(names, ", "))
+	}
+	return alg, nil
+}
+
+func (a algorithm) digest(data []byte) []byte {
+	h := a.hash.New()
+	_, _ = h.Write(data)
+	return h.Sum(nil)
+}
+
+// sign returns the signature of the data with the key, which is the secret
+// for the HMAC algorithms and a PEM-encoded private key for the others.
+func (a algorithm) sign(key []byte, data []byte) ([]byte, error) {
+	if a.family == "HS" {
+		if len(key) == 0 {
+			return nil, errors.New("the secret for the HMAC algorithms can't be empty")
+		}
+		mac := hmac.New(a.hash.New, key)
+		_, _ = mac.Write(data)
+		return mac.Sum(nil), nil
+	}
+
+	privateKey, err := parsePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		switch a.family {
+		case "RS":
+			return rsa.SignPKCS1v15(rand.Reader, k, a.hash, a.digest(data))
+		case "PS":
+			return rsa.SignPSS(rand.Reader, k, a.hash, a.digest(data),
+				&rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+	case *ecdsa.PrivateKey:
+		if a.family == "ES" {
+			if k.Curve != a.curve {
+				return nil, fmt.Errorf("the %s algorithm requires a key on the %s curve", a.name, a.curve.Params().Name)
+			}
+			r, s, err := ecdsa.Sign(rand.Reader, k, a.digest(data))
+			if err != nil {
+				return nil, err
+			}
+			// the signature is the concatenation of r and s, with a fixed size
+			size := (a.curve.Params().BitSize + 7) / 8
+			signature := make([]byte, 2*size)
+			r.FillBytes(signature[:size])
+			s.FillBytes(signature[size:])
+			return signature, nil
+		}
+	}
+	return nil, fmt.Errorf("the key can't be used with the %s algorithm", a.name)
+}
+
+// verify checks the signature of the data with the key, which is the secret
+// for the HMAC algorithms and a PEM-encoded public key, certificate or private
+// key for the others.
+func (a algorithm) verify(key []byte, data, signature []byte) error {
+	errInvalid := errors.New("invalid signature")
+
+	if a.family == "HS" {
+		expected, err := a.sign(key, data)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal(expected, signature) {
+			return errInvalid
+		}
+		return nil
+	}
+
+	publicKey, err := parsePublicKey(key)
+	if err != nil {
+		return err
+	}
+
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		switch a.family {
+		case "RS":
+			if rsa.VerifyPKCS1v15(k, a.hash, a.digest(data), signature) != nil {
+				return errInvalid
+			}
+			return nil
+		case "PS":
+			if rsa.VerifyPSS(k, a.hash, a.digest(data), signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}) != nil {
+				return errInvalid
+			}
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		if a.family == "ES" {
+			size := (a.curve.Params().BitSize + 7) / 8
+			if k.Curve != a.curve || len(signature) != 2*size {
+				return errInvalid
+			}
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			if !ecdsa.Verify(k, a.digest(data), r, s) {
+				return errInvalid
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("the key can't be used with the %s algorithm", a.name)
+}
+
+func decodePEM(key []byte) (*pem.Block, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("the key should be PEM-encoded")
+	}
+	return block, nil
+}
+
+// parsePrivateKey parses PKCS #8, PKCS #1 and SEC 1 private keys.
+func parsePrivateKey(key []byte) (crypto.PrivateKey, error) {
+	block, err := decodePEM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported private key type %q", block.Type)
+	}
+}
+
+// parsePublicKey parses PKIX and PKCS #1 public keys and certificates. It also
+// accepts private keys, whose public part is used.
+func parsePublicKey(key []byte) (crypto.PublicKey, error) {
+	block, err := decodePEM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+
+	privateKey, err := parsePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+	return signer.Public(), nil
+}
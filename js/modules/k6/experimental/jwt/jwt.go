@@ -0,0 +1,257 @@
+// Package jwt provides a k6 module for signing and verifying JSON Web Tokens.
+package jwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/types"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// JWT represents an instance of the jwt module.
+	JWT struct {
+		vu  modules.VU
+		now func() time.Time
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &JWT{}
+)
+
+// New returns a pointer to a new RootModule instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface to return
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &JWT{vu: vu, now: time.Now}
+}
+
+// Exports returns the exports of the jwt module.
+func (j *JWT) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"sign":   j.sign,
+			"verify": j.verify,
+			"decode": j.decode,
+		},
+	}
+}
+
+// sign returns a signed token with the given payload. The options can hold the
+// `algorithm` (HS256 by default), additional `header` fields and an
+// `expiresIn` duration, which sets the `iat` and `exp` claims.
+func (j *JWT) sign(payload sobek.Value, key interface{}, options sobek.Value) (string, error) {
+	rt := j.vu.Runtime()
+	if common.IsNullish(payload) {
+		return "", errors.New("the payload of the token can't be undefined or null")
+	}
+	claims, ok := payload.Export().(map[string]interface{})
+	if !ok {
+		return "", errors.New("the payload of the token should be an object")
+	}
+	keyData, err := common.ToBytes(key)
+	if err != nil {
+		return "", fmt.Errorf("invalid key: %w", err)
+	}
+
+	header := map[string]interface{}{"typ": "JWT"}
+	algName := "HS256"
+	var expiresIn time.Duration
+	if !common.IsNullish(options) {
+		opts := options.ToObject(rt)
+		if v := opts.Get("algorithm"); !common.IsNullish(v) {
+			algName = v.String()
+		}
+		if v := opts.Get("header"); !common.IsNullish(v) {
+			extra, ok := v.Export().(map[string]interface{})
+			if !ok {
+				return "", errors.New("the header option should be an object")
+			}
+			for k, v := range extra {
+				header[k] = v
+			}
+		}
+		if v := opts.Get("expiresIn"); !common.IsNullish(v) {
+			if expiresIn, err = types.GetDurationValue(v.Export()); err != nil {
+				return "", fmt.Errorf("invalid expiresIn: %w", err)
+			}
+		}
+	}
+
+	alg, err := getAlgorithm(algName)
+	if err != nil {
+		return "", err
+	}
+	header["alg"] = alg.name
+
+	if expiresIn != 0 {
+		now := j.now()
+		// the payload is a copy, so it's safe to modify it
+		claims["iat"] = now.Unix()
+		claims["exp"] = now.Add(expiresIn).Unix()
+	}
+
+	encodedHeader, err := encodeSegment(header)
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := encodeSegment(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encodedHeader + "." + encodedClaims
+	signature, err := alg.sign(keyData, []byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("couldn't sign the token: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// verify checks the signature and the `exp` and `nbf` claims of the token, and
+// returns its payload. The options can hold the allowed `algorithms` (all of
+// the ones matching the key by default) and a `clockTolerance` duration.
+func (j *JWT) verify(token string, key interface{}, options sobek.Value) (interface{}, error) {
+	rt := j.vu.Runtime()
+	keyData, err := common.ToBytes(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+
+	var (
+		allowed        []string
+		clockTolerance time.Duration
+	)
+	if !common.IsNullish(options) {
+		opts := options.ToObject(rt)
+		if v := opts.Get("algorithms"); !common.IsNullish(v) {
+			if err = rt.ExportTo(v, &allowed); err != nil {
+				return nil, fmt.Errorf("the algorithms option should be an array of strings: %w", err)
+			}
+		}
+		if v := opts.Get("clockTolerance"); !common.IsNullish(v) {
+			if clockTolerance, err = types.GetDurationValue(v.Export()); err != nil {
+				return nil, fmt.Errorf("invalid clockTolerance: %w", err)
+			}
+		}
+	}
+
+	header, claims, signature, err := parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	algName, _ := header["alg"].(string)
+	alg, err := getAlgorithm(algName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if err = checkAllowedAlgorithm(alg, allowed, keyData); err != nil {
+		return nil, err
+	}
+
+	signingInput := token[:strings.LastIndexByte(token, '.')]
+	if err = alg.verify(keyData, []byte(signingInput), signature); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	if err = checkTimeClaims(claims, j.now(), clockTolerance); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// decode returns the header and the payload of the token, without verifying it.
+func (j *JWT) decode(token string) (interface{}, error) {
+	header, claims, _, err := parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"header": header, "payload": claims}, nil
+}
+
+// checkAllowedAlgorithm prevents the algorithm confusion attacks, where a
+// public key is used as an HMAC secret, by only allowing by default the
+// algorithms matching the type of the key.
+func checkAllowedAlgorithm(alg algorithm, allowed []string, key []byte) error {
+	if allowed == nil {
+		isPEM := bytes.Contains(key, []byte("-----BEGIN "))
+		if isPEM == (alg.family == "HS") {
+			return fmt.Errorf("invalid token: the %s algorithm doesn't match the type of the key", alg.name)
+		}
+		return nil
+	}
+
+	for _, name := range allowed {
+		if name == alg.name {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid token: the %s algorithm isn't allowed", alg.name)
+}
+
+func checkTimeClaims(claims map[string]interface{}, now time.Time, tolerance time.Duration) error {
+	if exp, ok := claims["exp"].(float64); ok && !now.Add(-tolerance).Before(unixTime(exp)) {
+		return errors.New("invalid token: the token has expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(tolerance).Before(unixTime(nbf)) {
+		return errors.New("invalid token: the token isn't valid yet")
+	}
+	return nil
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+func encodeSegment(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("couldn't encode the token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func parseToken(token string) (header, claims map[string]interface{}, signature []byte, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, nil, errors.New("invalid token: it should have 3 parts separated by dots")
+	}
+	if err = decodeSegment(parts[0], &header); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid token header: %w", err)
+	}
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid token payload: %w", err)
+	}
+	if signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+	return header, claims, signature, nil
+}
+
+func decodeSegment(segment string, value interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
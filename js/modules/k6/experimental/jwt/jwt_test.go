@@ -0,0 +1,200 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modulestest"
+)
+
+// jwtIOToken is the example token from jwt.io, signed with HS256 and the
+// "your-256-bit-secret" secret.
+const jwtIOToken = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+	"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ." +
+	"SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c"
+
+func makeRuntime(t *testing.T, now time.Time) *sobek.Runtime {
+	t.Helper()
+
+	rt := sobek.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+
+	m, ok := New().NewModuleInstance(
+		&modulestest.VU{
+			RuntimeField: rt,
+			InitEnvField: &common.InitEnvironment{},
+			CtxField:     context.Background(),
+		},
+	).(*JWT)
+	require.True(t, ok)
+	m.now = func() time.Time { return now }
+	require.NoError(t, rt.Set("jwt", m.Exports().Named))
+
+	return rt
+}
+
+func encodePEM(t *testing.T, blockType string, der []byte, err error) string {
+	t.Helper()
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}))
+}
+
+// setKeys generates the keys used by the tests and sets them as globals.
+func setKeys(t *testing.T, rt *sobek.Runtime) {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	keys := map[string]string{
+		"rsaPrivate":   encodePEM(t, "PRIVATE KEY", pkcs8, err),
+		"rsaPKCS1":     encodePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), nil),
+		"rsaPublicRaw": encodePEM(t, "RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey), nil),
+	}
+	spki, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	keys["rsaPublic"] = encodePEM(t, "PUBLIC KEY", spki, err)
+
+	for name, curve := range map[string]elliptic.Curve{
+		"ES256": elliptic.P256(), "ES384": elliptic.P384(), "ES512": elliptic.P521(),
+	} {
+		ecKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+		sec1, err := x509.MarshalECPrivateKey(ecKey)
+		keys[name+"Private"] = encodePEM(t, "EC PRIVATE KEY", sec1, err)
+		spki, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+		keys[name+"Public"] = encodePEM(t, "PUBLIC KEY", spki, err)
+	}
+
+	require.NoError(t, rt.Set("keys", keys))
+}
+
+func TestJWT(t *testing.T) {
+	t.Parallel()
+
+	rt := makeRuntime(t, time.Unix(1700000000, 0))
+	setKeys(t, rt)
+
+	cases := map[string]string{
+		"verify the jwt.io token": `
+			const payload = jwt.verify("` + jwtIOToken + `", "your-256-bit-secret");
+			if (payload.name !== "John Doe" || payload.iat !== 1516239022) throw new Error("bad payload " + JSON.stringify(payload));
+		`,
+		"sign and verify": `
+			const pairs = {
+				HS256: ["secret", "secret"], HS384: ["secret", "secret"], HS512: ["secret", "secret"],
+				RS256: [keys.rsaPrivate, keys.rsaPublic], RS384: [keys.rsaPKCS1, keys.rsaPublicRaw], RS512: [keys.rsaPrivate, keys.rsaPrivate],
+				PS256: [keys.rsaPrivate, keys.rsaPublic], PS384: [keys.rsaPKCS1, keys.rsaPublic], PS512: [keys.rsaPrivate, keys.rsaPublicRaw],
+				ES256: [keys.ES256Private, keys.ES256Public], ES384: [keys.ES384Private, keys.ES384Public], ES512: [keys.ES512Private, keys.ES512Public],
+			};
+			for (const [algorithm, [privateKey, publicKey]] of Object.entries(pairs)) {
+				const token = jwt.sign({ sub: "user" }, privateKey, { algorithm: algorithm, header: { kid: "key-1" } });
+				const payload = jwt.verify(token, publicKey);
+				if (payload.sub !== "user") throw new Error(algorithm + ": bad payload " + JSON.stringify(payload));
+				const { header } = jwt.decode(token);
+				if (header.alg !== algorithm || header.typ !== "JWT" || header.kid !== "key-1") {
+					throw new Error(algorithm + ": bad header " + JSON.stringify(header));
+				}
+			}
+		`,
+		"expiresIn": `
+			const token = jwt.sign({ sub: "user" }, "secret", { expiresIn: "1h" });
+			const { payload } = jwt.decode(token);
+			if (payload.iat !== 1700000000 || payload.exp !== 1700003600) throw new Error("bad payload " + JSON.stringify(payload));
+		`,
+		"clockTolerance": `
+			const token = jwt.sign({ exp: 1699999991, nbf: 1700000010 }, "secret");
+			jwt.verify(token, "secret", { clockTolerance: "10s" });
+		`,
+	}
+
+	for name, code := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := rt.RunString(`(function() {` + code + `})()`)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestJWTErrors(t *testing.T) {
+	t.Parallel()
+
+	rt := makeRuntime(t, time.Unix(1700000000, 0))
+	setKeys(t, rt)
+
+	cases := map[string]struct {
+		code, err string
+	}{
+		"wrong secret": {
+			code: `jwt.verify("` + jwtIOToken + `", "wrong")`,
+			err:  "invalid token: invalid signature",
+		},
+		"tampered payload": {
+			code: `
+				const [header, , signature] = jwt.sign({ admin: false }, keys.rsaPrivate, { algorithm: "RS256" }).split(".");
+				const payload = jwt.sign({ admin: true }, "secret").split(".")[1];
+				jwt.verify([header, payload, signature].join("."), keys.rsaPublic);
+			`,
+			err: "invalid token: invalid signature",
+		},
+		"algorithm confusion": {
+			code: `jwt.verify(jwt.sign({}, keys.rsaPublic), keys.rsaPublic)`,
+			err:  "the HS256 algorithm doesn't match the type of the key",
+		},
+		"algorithm not allowed": {
+			code: `jwt.verify(jwt.sign({}, "secret", { algorithm: "HS512" }), "secret", { algorithms: ["HS256"] })`,
+			err:  "the HS512 algorithm isn't allowed",
+		},
+		"wrong curve": {
+			code: `jwt.sign({}, keys.ES384Private, { algorithm: "ES256" })`,
+			err:  "the ES256 algorithm requires a key on the P-256 curve",
+		},
+		"wrong key type": {
+			code: `jwt.sign({}, keys.ES256Private, { algorithm: "RS256" })`,
+			err:  "the key can't be used with the RS256 algorithm",
+		},
+		"not a PEM key": {
+			code: `jwt.sign({}, "secret", { algorithm: "RS256" })`,
+			err:  "the key should be PEM-encoded",
+		},
+		"unsupported algorithm": {
+			code: `jwt.sign({}, "secret", { algorithm: "none" })`,
+			err:  `unsupported algorithm "none"`,
+		},
+		"expired": {
+			code: `jwt.verify(jwt.sign({ exp: 1700000000 }, "secret"), "secret")`,
+			err:  "the token has expired",
+		},
+		"not valid yet": {
+			code: `jwt.verify(jwt.sign({ nbf: 1700000001 }, "secret"), "secret")`,
+			err:  "the token isn't valid yet",
+		},
+		"malformed": {
+			code: `jwt.decode("abc")`,
+			err:  "invalid token: it should have 3 parts separated by dots",
+		},
+		"payload not an object": {
+			code: `jwt.sign("abc", "secret")`,
+			err:  "the payload of the token should be an object",
+		},
+	}
+
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := rt.RunString(`(function() {` + testCase.code + `})()`)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.err)
+		})
+	}
+}
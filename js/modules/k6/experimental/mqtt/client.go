@@ -0,0 +1,877 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/sobek"
+	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/promises"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	eventMessage = "message"
+	eventError   = "error"
+	eventClose   = "close"
+)
+
+// defaultKeepAlive is the keep alive interval of the connections, unless the
+// user set it.
+const defaultKeepAlive = 60 * time.Second
+
+// Message is a message received from a subscription.
+type Message struct {
+	// Topic holds the topic the message was published to.
+	Topic string `js:"topic"`
+
+	// Payload holds the payload of the message, as a string.
+	Payload string `js:"payload"`
+
+	// Data holds the payload of the message, as an ArrayBuffer.
+	Data sobek.ArrayBuffer `js:"data"`
+
+	// QoS holds the QoS level the message was delivered with.
+	QoS byte `js:"qos"`
+
+	// Retain indicates whether the message was retained by the broker.
+	Retain bool `js:"retain"`
+
+	// Dup indicates whether the message might have been delivered before.
+	Dup bool `js:"dup"`
+}
+
+// ErrorEvent is the event the error listeners are called with.
+type ErrorEvent struct {
+	// Error holds the message of the error that happened.
+	Error string `js:"error"`
+}
+
+// tlsOptions configures the TLS connections to the brokers.
+type tlsOptions struct {
+	// serverName holds the name of the broker, checked against its certificate,
+	// which defaults to the host of its URL.
+	serverName string
+
+	// insecureSkipVerify indicates whether the broker's certificate is not to
+	// be verified.
+	insecureSkipVerify bool
+
+	// certificates holds the client certificates presented to the broker.
+	certificates []tls.Certificate
+}
+
+// clientParams represent the parameters bag of connect().
+type clientParams struct {
+	address     string
+	host        string
+	connect     connectOptions
+	timeout     time.Duration
+	tls         *tlsOptions
+	tagsAndMeta *metrics.TagsAndMeta
+}
+
+// Client is a connection to an MQTT broker.
+type Client struct {
+	// ClientID holds the identifier the client connected with.
+	ClientID string `js:"clientId"`
+
+	vu          modules.VU
+	metrics     *instanceMetrics
+	tagsAndMeta *metrics.TagsAndMeta
+	tq          *taskqueue.TaskQueue
+
+	conn    net.Conn
+	reader  *bufio.Reader
+	writeMu sync.Mutex
+
+	// mu guards pending and nextID.
+	mu sync.Mutex
+
+	// pending holds the channels the acknowledgements of the packets sent are
+	// forwarded to, by packet identifier.
+	pending map[uint16]chan packet
+	nextID  uint16
+
+	// received holds the identifiers of the QoS 2 messages received, until
+	// their release, so that they are delivered once. It is only accessed by
+	// the goroutine reading the packets.
+	received map[uint16]bool
+
+	// done is closed once the connection is closed, after err is set to the
+	// error the connection was lost with, if any.
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+	opened    time.Time
+
+	// listeners is only accessed on the event loop.
+	listeners map[string][]func(sobek.Value) (sobek.Value, error)
+}
+
+// Connect returns a promise that will resolve to a [Client] connected to the
+// broker at the provided URL, with either the mqtt or mqtts scheme, once the
+// broker accepted the connection.
+//
+// The options can hold the `clientId`, `username` and `password` to connect
+// with, whether to start a `cleanSession`, or resume the session the broker
+// persisted for the client, the `keepAlive` interval, a `timeout` for the
+// connection to be accepted within, a `tls` object holding a `serverName`, an
+// `insecureSkipVerify` flag, and the PEM encoded `cert` and `key` of a client
+// certificate, and `tags` to tag the metrics of the client with.
+//
+// The iteration lasts until the client is closed.
+func (mi *ModuleInstance) Connect(brokerURL sobek.Value, options sobek.Value) *sobek.Promise {
+	promise, resolve, reject := promises.New(mi.vu)
+
+	state := mi.vu.State()
+	if state == nil {
+		reject(errors.New("connect() failed; reason: using MQTT in the init context is not supported"))
+		return promise
+	}
+
+	params, err := parseClientParams(state, mi.vu.Runtime(), brokerURL, options)
+	if err != nil {
+		reject(fmt.Errorf("connect() failed; reason: %w", err))
+		return promise
+	}
+
+	c := &Client{
+		ClientID:    params.connect.clientID,
+		vu:          mi.vu,
+		metrics:     mi.metrics,
+		tagsAndMeta: params.tagsAndMeta,
+		tq:          taskqueue.New(mi.vu.RegisterCallback),
+		pending:     make(map[uint16]chan packet),
+		received:    make(map[uint16]bool),
+		done:        make(chan struct{}),
+		listeners:   make(map[string][]func(sobek.Value) (sobek.Value, error)),
+	}
+
+	go func() {
+		if err := c.connect(state, params); err != nil {
+			c.tq.Close()
+			reject(fmt.Errorf("connect() failed; reason: %w", err))
+			return
+		}
+
+		resolve(c)
+
+		go c.readPackets()
+		go c.keepAlive(time.Duration(params.connect.keepAlive) * time.Second)
+		go func() {
+			select {
+			case <-mi.vu.Context().Done():
+				c.shutdown(nil)
+			case <-c.done:
+			}
+		}()
+	}()
+
+	return promise
+}
+
+// parseClientParams parses and validates the arguments of connect().
+func parseClientParams(state *lib.State, rt *sobek.Runtime, brokerURL, raw sobek.Value) (*clientParams, error) {
+	if common.IsNullish(brokerURL) {
+		return nil, errors.New("the broker URL cannot be empty")
+	}
+
+	u, err := url.Parse(brokerURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker URL %q: %w", brokerURL.String(), err)
+	}
+
+	tagsAndMeta := state.Tags.GetCurrentValues()
+	params := &clientParams{
+		host:        u.Hostname(),
+		connect:     connectOptions{cleanSession: true, keepAlive: uint16(defaultKeepAlive / time.Second)},
+		tagsAndMeta: &tagsAndMeta,
+	}
+
+	port := u.Port()
+	switch u.Scheme {
+	case "mqtt":
+		if port == "" {
+			port = "1883"
+		}
+	case "mqtts":
+		if port == "" {
+			port = "8883"
+		}
+		params.tls = &tlsOptions{}
+	default:
+		return nil, fmt.Errorf("the broker URL requires the scheme mqtt or mqtts, but got %q", u.Scheme)
+	}
+
+	params.address = net.JoinHostPort(params.host, port)
+
+	if !common.IsNullish(raw) {
+		if err := params.parseOptions(rt, raw); err != nil {
+			return nil, err
+		}
+	}
+
+	if params.connect.clientID == "" {
+		if params.connect.clientID, err = randomClientID(); err != nil {
+			return nil, err
+		}
+	}
+
+	// the `name` and `url` tags have the exact same values, as for the other protocols
+	systemTags := state.Options.SystemTags
+	if nameTagValue, ok := params.tagsAndMeta.Tags.Get(metrics.TagName.String()); ok {
+		params.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, nameTagValue)
+	} else {
+		params.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, u.String())
+		params.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagName, u.String())
+	}
+
+	return params, nil
+}
+
+// parseOptions parses the options of connect().
+func (p *clientParams) parseOptions(rt *sobek.Runtime, raw sobek.Value) error {
+	options := raw.ToObject(rt)
+	for _, k := range options.Keys() {
+		v := options.Get(k)
+		if common.IsNullish(v) {
+			continue
+		}
+
+		switch k {
+		case "clientId":
+			p.connect.clientID = v.String()
+		case "username":
+			p.connect.username = v.String()
+		case "password":
+			p.connect.password = v.String()
+		case "cleanSession":
+			p.connect.cleanSession = v.ToBoolean()
+		case "keepAlive":
+			keepAlive, err := types.GetDurationValue(v.Export())
+			if err != nil || keepAlive < 0 || keepAlive > math.MaxUint16*time.Second {
+				return fmt.Errorf("invalid keepAlive option %q", v.String())
+			}
+			p.connect.keepAlive = uint16(keepAlive / time.Second)
+		case "timeout":
+			timeout, err := types.GetDurationValue(v.Export())
+			if err != nil {
+				return fmt.Errorf("invalid timeout option: %w", err)
+			}
+			p.timeout = timeout
+		case "tls":
+			tlsOpts, err := parseTLSOptions(rt, v)
+			if err != nil {
+				return err
+			}
+			p.tls = tlsOpts
+		case "tags":
+			if err := common.ApplyCustomUserTags(rt, p.tagsAndMeta, v); err != nil {
+				return fmt.Errorf("invalid tags option: %w", err)
+			}
+		default:
+			return fmt.Errorf("unknown option %s", k)
+		}
+	}
+
+	return nil
+}
+
+// parseTLSOptions parses the tls option of connect().
+func parseTLSOptions(rt *sobek.Runtime, v sobek.Value) (*tlsOptions, error) {
+	opts := &tlsOptions{}
+
+	var cert, key string
+	obj := v.ToObject(rt)
+	for _, k := range obj.Keys() {
+		switch k {
+		case "serverName":
+			opts.serverName = obj.Get(k).String()
+		case "insecureSkipVerify":
+			opts.insecureSkipVerify = obj.Get(k).ToBoolean()
+		case "cert":
+			cert = obj.Get(k).String()
+		case "key":
+			key = obj.Get(k).String()
+		default:
+			return nil, fmt.Errorf("unknown tls option %s", k)
+		}
+	}
+
+	if cert != "" || key != "" {
+		certificate, err := tls.X509KeyPair([]byte(cert), []byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls client certificate: %w", err)
+		}
+		opts.certificates = []tls.Certificate{certificate}
+	}
+
+	return opts, nil
+}
+
+// randomClientID returns a random client identifier.
+func randomClientID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate a client identifier: %w", err)
+	}
+
+	return "k6-" + hex.EncodeToString(b), nil
+}
+
+// connect connects to the broker, and waits for it to accept the connection.
+func (c *Client) connect(state *lib.State, params *clientParams) error {
+	ctx := c.vu.Context()
+	if params.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, params.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	conn, err := state.Dialer.DialContext(ctx, "tcp", params.address)
+	if err != nil {
+		return err
+	}
+
+	if params.tls != nil {
+		if conn, err = handshake(ctx, state, conn, params.host, params.tls); err != nil {
+			return err
+		}
+	}
+
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+
+	// the connection is closed if it isn't accepted in time
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-stop:
+		}
+	}()
+
+	if err := c.write(connectPacket(params.connect)); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	connack, err := readPacket(c.reader)
+	if err == nil {
+		err = checkConnack(connack)
+	}
+	if err != nil {
+		_ = conn.Close()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		return err
+	}
+
+	c.opened = time.Now()
+	c.pushSample(c.metrics.Connecting, start, metrics.D(c.opened.Sub(start)))
+
+	return nil
+}
+
+// handshake secures the provided connection with TLS, and returns the secured
+// connection, which the provided connection is closed in favor of on failure.
+func handshake(ctx context.Context, state *lib.State, conn net.Conn, host string, opts *tlsOptions) (net.Conn, error) {
+	config := &tls.Config{} //nolint:gosec
+	if state.TLSConfig != nil {
+		config = state.TLSConfig.Clone()
+	}
+
+	config.ServerName = host
+	if opts.serverName != "" {
+		config.ServerName = opts.serverName
+	}
+
+	if opts.insecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+
+	if len(opts.certificates) > 0 {
+		config.Certificates = opts.certificates
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("the TLS handshake failed: %w", err)
+	}
+
+	return tlsConn, nil
+}
+
+// readPackets reads the packets the broker sends, until the connection is closed.
+func (c *Client) readPackets() {
+	for {
+		p, err := readPacket(c.reader)
+		if err == nil {
+			err = c.handle(p)
+		}
+
+		if err != nil {
+			c.shutdown(err)
+			return
+		}
+	}
+}
+
+// handle handles a packet received from the broker.
+func (c *Client) handle(p packet) error {
+	switch p.kind {
+	case packetPublish:
+		m, err := parsePublish(p)
+		if err != nil {
+			return err
+		}
+
+		switch m.qos {
+		case 0:
+			c.deliver(m)
+			return nil
+		case 1:
+			c.deliver(m)
+			return c.write(ackPacket(packetPuback, m.id))
+		default:
+			if !c.received[m.id] {
+				c.received[m.id] = true
+				c.deliver(m)
+			}
+			return c.write(ackPacket(packetPubrec, m.id))
+		}
+	case packetPubrel:
+		id, err := packetID(p)
+		if err != nil {
+			return err
+		}
+
+		delete(c.received, id)
+		return c.write(ackPacket(packetPubcomp, id))
+	case packetPubrec:
+		// The QoS 2 messages published are released, and completed by a PUBCOMP packet.
+		id, err := packetID(p)
+		if err != nil {
+			return err
+		}
+
+		return c.write(ackPacket(packetPubrel, id))
+	case packetPuback, packetPubcomp, packetSuback, packetUnsuback:
+		id, err := packetID(p)
+		if err != nil {
+			return err
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[id]
+		delete(c.pending, id)
+		c.mu.Unlock()
+
+		if ok {
+			ch <- p
+		}
+
+		return nil
+	case packetPingresp:
+		return nil
+	default:
+		return fmt.Errorf("unexpected packet of type %d", p.kind)
+	}
+}
+
+// deliver queues calling the message listeners with the provided message.
+func (c *Client) deliver(m message) {
+	c.pushSample(c.metrics.MessagesReceived, time.Now(), 1)
+
+	c.tq.Queue(func() error {
+		rt := c.vu.Runtime()
+
+		return c.callListeners(eventMessage, rt.ToValue(Message{
+			Topic:   m.topic,
+			Payload: string(m.payload),
+			Data:    rt.NewArrayBuffer(m.payload),
+			QoS:     m.qos,
+			Retain:  m.retain,
+			Dup:     m.dup,
+		}))
+	})
+}
+
+// keepAlive pings the broker at the provided interval, until the connection
+// is closed, so that the broker keeps it open.
+func (c *Client) keepAlive(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.write(packet{kind: packetPingreq}); err != nil {
+				c.shutdown(err)
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// write sends the provided packet to the broker, unless the connection is closed.
+func (c *Client) write(p packet) error {
+	b, err := p.encode()
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.closedError()
+	default:
+	}
+
+	if _, err = c.conn.Write(b); err != nil {
+		select {
+		case <-c.done:
+			return c.closedError()
+		default:
+		}
+	}
+
+	return err
+}
+
+// request sends the provided packet, of the given identifier, and returns the
+// packet the broker acknowledged it with.
+func (c *Client) request(id uint16, p packet) (packet, error) {
+	ch := make(chan packet, 1)
+
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	if err := c.write(p); err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+
+		return packet{}, err
+	}
+
+	select {
+	case ack := <-ch:
+		return ack, nil
+	case <-c.done:
+		return packet{}, c.closedError()
+	}
+}
+
+// packetID returns an identifier for a new packet, not used by a packet
+// waiting for its acknowledgement.
+func (c *Client) packetID() uint16 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		c.nextID++
+		if _, used := c.pending[c.nextID]; c.nextID != 0 && !used {
+			return c.nextID
+		}
+	}
+}
+
+// closedError returns the error the operations fail with once the connection
+// is closed.
+func (c *Client) closedError() error {
+	if c.err != nil {
+		return fmt.Errorf("the connection was lost: %w", c.err)
+	}
+
+	return errors.New("the client is closed")
+}
+
+// Publish returns a promise that will resolve once the provided payload,
+// either a string, an ArrayBuffer, or a typed array, is published to the given
+// topic: once it is sent for the QoS level 0, and once the broker acknowledged
+// it for the QoS levels 1 and 2.
+//
+// The options can hold the `qos` level to publish with, and whether the broker
+// is to `retain` the message.
+func (c *Client) Publish(topic string, payload sobek.Value, options sobek.Value) *sobek.Promise {
+	promise, resolve, reject := promises.New(c.vu)
+
+	if topic == "" || strings.ContainsAny(topic, "#+") {
+		reject(fmt.Errorf("publish() failed; reason: invalid topic %q", topic))
+		return promise
+	}
+
+	var data []byte
+	if !common.IsNullish(payload) {
+		b, err := common.ToBytes(payload.Export())
+		if err != nil {
+			reject(fmt.Errorf("publish() failed; reason: %w", err))
+			return promise
+		}
+
+		// The payload is copied, so that it is not modified while being sent.
+		data = append([]byte(nil), b...)
+	}
+
+	m := message{topic: topic, payload: data}
+	if err := c.parsePublishOptions(options, &m); err != nil {
+		reject(fmt.Errorf("publish() failed; reason: %w", err))
+		return promise
+	}
+
+	go func() {
+		start := time.Now()
+
+		var err error
+		if m.qos == 0 {
+			err = c.write(publishPacket(m))
+		} else {
+			m.id = c.packetID()
+			_, err = c.request(m.id, publishPacket(m))
+		}
+
+		if err != nil {
+			reject(fmt.Errorf("publish() failed; reason: %w", err))
+			return
+		}
+
+		end := time.Now()
+		c.pushSample(c.metrics.MessagesSent, end, 1)
+		c.pushSample(c.metrics.PublishDuration, end, metrics.D(end.Sub(start)))
+
+		resolve(sobek.Undefined())
+	}()
+
+	return promise
+}
+
+// parsePublishOptions parses the options of publish() into the provided message.
+func (c *Client) parsePublishOptions(raw sobek.Value, m *message) error {
+	if common.IsNullish(raw) {
+		return nil
+	}
+
+	options := raw.ToObject(c.vu.Runtime())
+	for _, k := range options.Keys() {
+		v := options.Get(k)
+		if common.IsNullish(v) {
+			continue
+		}
+
+		switch k {
+		case "qos":
+			qos, err := parseQoS(v)
+			if err != nil {
+				return err
+			}
+			m.qos = qos
+		case "retain":
+			m.retain = v.ToBoolean()
+		default:
+			return fmt.Errorf("unknown option %s", k)
+		}
+	}
+
+	return nil
+}
+
+// parseQoS parses the provided QoS level.
+func parseQoS(v sobek.Value) (byte, error) {
+	qos := v.ToInteger()
+	if qos < 0 || qos > 2 {
+		return 0, fmt.Errorf("invalid qos option %d: it must be 0, 1 or 2", qos)
+	}
+
+	return byte(qos), nil
+}
+
+// Subscribe returns a promise that will resolve to the QoS level the broker
+// granted, once it acknowledged the subscription to the provided topic filter.
+//
+// The options can hold the maximum `qos` level to receive the messages with.
+func (c *Client) Subscribe(filter string, options sobek.Value) *sobek.Promise {
+	promise, resolve, reject := promises.New(c.vu)
+
+	if filter == "" {
+		reject(errors.New("subscribe() failed; reason: the topic filter cannot be empty"))
+		return promise
+	}
+
+	var qos byte
+	if !common.IsNullish(options) {
+		obj := options.ToObject(c.vu.Runtime())
+		for _, k := range obj.Keys() {
+			if k != "qos" {
+				reject(fmt.Errorf("subscribe() failed; reason: unknown option %s", k))
+				return promise
+			}
+
+			var err error
+			if qos, err = parseQoS(obj.Get(k)); err != nil {
+				reject(fmt.Errorf("subscribe() failed; reason: %w", err))
+				return promise
+			}
+		}
+	}
+
+	go func() {
+		id := c.packetID()
+
+		ack, err := c.request(id, subscribePacket(id, filter, qos))
+		if err == nil && len(ack.body) != 3 {
+			err = errors.New("malformed SUBACK packet")
+		}
+		if err == nil && ack.body[2] == subackFailure {
+			err = fmt.Errorf("the broker refused the subscription to %q", filter)
+		}
+
+		if err != nil {
+			reject(fmt.Errorf("subscribe() failed; reason: %w", err))
+			return
+		}
+
+		resolve(ack.body[2])
+	}()
+
+	return promise
+}
+
+// Unsubscribe returns a promise that will resolve once the broker acknowledged
+// the unsubscription from the provided topic filter.
+func (c *Client) Unsubscribe(filter string) *sobek.Promise {
+	promise, resolve, reject := promises.New(c.vu)
+
+	go func() {
+		id := c.packetID()
+
+		if _, err := c.request(id, unsubscribePacket(id, filter)); err != nil {
+			reject(fmt.Errorf("unsubscribe() failed; reason: %w", err))
+			return
+		}
+
+		resolve(sobek.Undefined())
+	}()
+
+	return promise
+}
+
+// On registers a handler for a certain event type: "message", called with each
+// [Message] received, "error", called with an [ErrorEvent] when the connection
+// is lost, and "close", called once the connection is closed.
+func (c *Client) On(event string, handler func(sobek.Value) (sobek.Value, error)) {
+	rt := c.vu.Runtime()
+
+	if handler == nil {
+		common.Throw(rt, fmt.Errorf("handler for %q event isn't a callable function", event))
+	}
+
+	switch event {
+	case eventMessage, eventError, eventClose:
+		c.listeners[event] = append(c.listeners[event], handler)
+	default:
+		common.Throw(rt, fmt.Errorf("unknown MQTT client's event type: %s", event))
+	}
+}
+
+// Close returns a promise that will resolve once the client is disconnected
+// from the broker. Closing a closed client has no effect.
+func (c *Client) Close() *sobek.Promise {
+	promise, resolve, _ := promises.New(c.vu)
+
+	go func() {
+		select {
+		case <-c.done:
+		default:
+			// the connection is closed anyway
+			_ = c.write(packet{kind: packetDisconnect})
+			c.shutdown(nil)
+		}
+
+		resolve(sobek.Undefined())
+	}()
+
+	return promise
+}
+
+// shutdown closes the connection, which was lost with the provided error, if
+// any, and queues calling the error and close listeners.
+func (c *Client) shutdown(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.done)
+		_ = c.conn.Close()
+
+		c.pushSample(c.metrics.SessionDuration, time.Now(), metrics.D(time.Since(c.opened)))
+
+		c.tq.Queue(func() error {
+			if err != nil {
+				if err := c.callListeners(eventError, c.vu.Runtime().ToValue(ErrorEvent{Error: err.Error()})); err != nil {
+					return err
+				}
+			}
+
+			return c.callListeners(eventClose, sobek.Undefined())
+		})
+		c.tq.Close()
+	})
+}
+
+// callListeners calls the listeners of the provided event type with the given
+// value. The client is closed if any of them throws.
+func (c *Client) callListeners(event string, v sobek.Value) error {
+	for _, listener := range c.listeners[event] {
+		if _, err := listener(v); err != nil {
+			c.shutdown(nil)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pushSample pushes a sample of the provided metric, tagged with the client's
+// tags and metadata.
+func (c *Client) pushSample(metric *metrics.Metric, t time.Time, value float64) {
+	metrics.PushIfNotDone(c.vu.Context(), c.vu.State().Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: metric,
+			Tags:   c.tagsAndMeta.Tags,
+		},
+		Time:     t,
+		Metadata: c.tagsAndMeta.Metadata,
+		Value:    value,
+	})
+}
@@ -0,0 +1,51 @@
+package mqtt
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics for the mqtt module.
+type instanceMetrics struct {
+	// Connecting measures the time spent connecting to the brokers, until
+	// they accepted the connections.
+	Connecting *metrics.Metric
+
+	// SessionDuration measures the time connections stayed open.
+	SessionDuration *metrics.Metric
+
+	// MessagesSent counts the messages published.
+	MessagesSent *metrics.Metric
+
+	// MessagesReceived counts the messages received from the subscriptions.
+	MessagesReceived *metrics.Metric
+
+	// PublishDuration measures the time spent publishing messages, until the
+	// brokers acknowledged them for the QoS levels 1 and 2.
+	PublishDuration *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.Connecting, err = registry.NewMetric("mqtt_connecting", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.SessionDuration, err = registry.NewMetric("mqtt_session_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.MessagesSent, err = registry.NewMetric("mqtt_msgs_sent", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.MessagesReceived, err = registry.NewMetric("mqtt_msgs_received", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.PublishDuration, err = registry.NewMetric("mqtt_publish_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,358 @@
+package mqtt
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+type testState struct {
+	*modulestest.Runtime
+	samples chan metrics.SampleContainer
+}
+
+func newTestState(t testing.TB) testState {
+	t.Helper()
+
+	tb := httpmultibin.NewHTTPMultiBin(t)
+
+	testRuntime := modulestest.NewRuntime(t)
+	samples := make(chan metrics.SampleContainer, 1000)
+
+	logger := logrus.New()
+	logger.Out = io.Discard
+
+	registry := metrics.NewRegistry()
+	state := &lib.State{
+		Dialer: tb.Dialer,
+		Options: lib.Options{
+			SystemTags: metrics.NewSystemTagSet(metrics.TagURL),
+			UserAgent:  null.StringFrom("TestUserAgent"),
+		},
+		Logger:         logger,
+		Samples:        samples,
+		TLSConfig:      tb.TLSClientConfig,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	}
+
+	m, ok := New().NewModuleInstance(testRuntime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, testRuntime.VU.RuntimeField.Set("mqtt", m.Exports().Named))
+	testRuntime.MoveToVUContext(state)
+
+	return testState{
+		Runtime: testRuntime,
+		samples: samples,
+	}
+}
+
+// testBroker is a minimal MQTT broker, routing the messages published to the
+// clients subscribed to their topics, with the QoS level they published them
+// with, regardless of the one the clients subscribed with.
+type testBroker struct {
+	t        testing.TB
+	listener net.Listener
+
+	mu            sync.Mutex
+	subscriptions map[*brokerConn][]string
+}
+
+// brokerConn is a client connection to a [testBroker].
+type brokerConn struct {
+	conn    net.Conn
+	writeMu sync.Mutex
+	nextID  uint16
+}
+
+func (c *brokerConn) write(p packet) {
+	b, _ := p.encode()
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_, _ = c.conn.Write(b)
+}
+
+func newTestBroker(t testing.TB) *testBroker {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	b := &testBroker{t: t, listener: listener, subscriptions: make(map[*brokerConn][]string)}
+	go b.serve()
+
+	return b
+}
+
+// url returns the URL of the broker.
+func (b *testBroker) url() string {
+	return "mqtt://" + b.listener.Addr().String()
+}
+
+func (b *testBroker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go b.handle(&brokerConn{conn: conn})
+	}
+}
+
+func (b *testBroker) handle(c *brokerConn) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.subscriptions, c)
+		b.mu.Unlock()
+
+		_ = c.conn.Close()
+	}()
+
+	r := bufio.NewReader(c.conn)
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			return
+		}
+
+		switch p.kind {
+		case packetConnect:
+			var code byte
+			if strings.Contains(string(p.body), "refused") {
+				code = 5
+			}
+			c.write(packet{kind: packetConnack, body: []byte{0, code}})
+		case packetSubscribe:
+			id, _ := packetID(p)
+			filter, rest, _ := readString(p.body[2:])
+
+			code := rest[0]
+			if filter == "forbidden" {
+				code = subackFailure
+			} else {
+				b.mu.Lock()
+				b.subscriptions[c] = append(b.subscriptions[c], filter)
+				b.mu.Unlock()
+			}
+
+			c.write(packet{kind: packetSuback, body: []byte{byte(id >> 8), byte(id), code}})
+		case packetUnsubscribe:
+			id, _ := packetID(p)
+			b.mu.Lock()
+			delete(b.subscriptions, c)
+			b.mu.Unlock()
+			c.write(ackPacket(packetUnsuback, id))
+		case packetPublish:
+			m, err := parsePublish(p)
+			assert.NoError(b.t, err)
+
+			switch m.qos {
+			case 1:
+				c.write(ackPacket(packetPuback, m.id))
+			case 2:
+				c.write(ackPacket(packetPubrec, m.id))
+			}
+
+			b.route(m)
+		case packetPubrel:
+			id, _ := packetID(p)
+			c.write(ackPacket(packetPubcomp, id))
+		case packetPubrec:
+			id, _ := packetID(p)
+			c.write(ackPacket(packetPubrel, id))
+		case packetPingreq:
+			c.write(packet{kind: packetPingresp})
+		case packetDisconnect:
+			return
+		}
+	}
+}
+
+// route sends the provided message to the clients subscribed to its topic.
+func (b *testBroker) route(m message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for c, filters := range b.subscriptions {
+		for _, filter := range filters {
+			if filter == m.topic || (strings.HasSuffix(filter, "#") && strings.HasPrefix(m.topic, strings.TrimSuffix(filter, "#"))) {
+				c.nextID++
+				c.write(publishPacket(message{topic: m.topic, payload: m.payload, qos: m.qos, id: c.nextID}))
+				break
+			}
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	for _, qos := range []int{0, 1, 2} {
+		qos := qos
+
+		t.Run("QoS"+string(rune('0'+qos)), func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestState(t)
+			broker := newTestBroker(t)
+			require.NoError(t, ts.VU.RuntimeField.Set("BROKER_URL", broker.url()))
+			require.NoError(t, ts.VU.RuntimeField.Set("QOS", qos))
+
+			_, err := ts.RunOnEventLoop(`
+				(async function () {
+					const received = [];
+					const client = await mqtt.connect(BROKER_URL, { clientId: "device-1", keepAlive: "1s" });
+					if (client.clientId !== "device-1") { throw new Error("unexpected client id: " + client.clientId); }
+
+					client.on("message", (m) => {
+						received.push(m.topic + "=" + m.payload + "/" + new Uint8Array(m.data).length + "/" + m.qos);
+					});
+
+					const granted = await client.subscribe("devices/#", { qos: QOS });
+					if (granted !== QOS) { throw new Error("unexpected granted QoS: " + granted); }
+
+					await client.publish("devices/1", "on", { qos: QOS });
+					await client.publish("devices/2", new Uint8Array([111, 102, 102]), { qos: QOS });
+					await client.publish("other", "ignored", { qos: QOS });
+
+					// the messages are received once another round trip completes
+					await client.unsubscribe("devices/#");
+					await client.publish("devices/3", "unsubscribed", { qos: 1 });
+
+					const want = "devices/1=on/2/" + QOS + ",devices/2=off/3/" + QOS;
+					if (received.join(",") !== want) { throw new Error("unexpected messages: " + received.join(",")); }
+
+					await client.close();
+				})()
+			`)
+			require.NoError(t, err)
+
+			values := make(map[string]float64)
+			for _, container := range metrics.GetBufferedSamples(ts.samples) {
+				for _, sample := range container.GetSamples() {
+					values[sample.Metric.Name] += sample.Value
+
+					url, _ := sample.Tags.Get("url")
+					assert.Equal(t, broker.url(), url)
+				}
+			}
+
+			assert.Equal(t, 4.0, values["mqtt_msgs_sent"])
+			assert.Equal(t, 2.0, values["mqtt_msgs_received"])
+			assert.Contains(t, values, "mqtt_connecting")
+			assert.Contains(t, values, "mqtt_publish_duration")
+			assert.Contains(t, values, "mqtt_session_duration")
+		})
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{
+			name:    "unsupported scheme",
+			script:  `await mqtt.connect("ws://127.0.0.1:1883")`,
+			wantErr: `the broker URL requires the scheme mqtt or mqtts, but got "ws"`,
+		},
+		{
+			name:    "unknown option",
+			script:  `await mqtt.connect(BROKER_URL, { unknown: true })`,
+			wantErr: "unknown option unknown",
+		},
+		{
+			name:    "refused connection",
+			script:  `await mqtt.connect(BROKER_URL, { username: "refused" })`,
+			wantErr: "the server refused the connection: not authorized",
+		},
+		{
+			name: "refused subscription",
+			script: `
+				const client = await mqtt.connect(BROKER_URL);
+				try {
+					await client.subscribe("forbidden");
+				} finally {
+					await client.close();
+				}
+			`,
+			wantErr: `the broker refused the subscription to "forbidden"`,
+		},
+		{
+			name: "invalid topic",
+			script: `
+				const client = await mqtt.connect(BROKER_URL);
+				try {
+					await client.publish("devices/+", "on");
+				} finally {
+					await client.close();
+				}
+			`,
+			wantErr: `invalid topic "devices/+"`,
+		},
+		{
+			name: "invalid qos",
+			script: `
+				const client = await mqtt.connect(BROKER_URL);
+				try {
+					await client.publish("devices/1", "on", { qos: 3 });
+				} finally {
+					await client.close();
+				}
+			`,
+			wantErr: "invalid qos option 3: it must be 0, 1 or 2",
+		},
+		{
+			name: "closed client",
+			script: `
+				const client = await mqtt.connect(BROKER_URL);
+				let closed = false;
+				client.on("close", () => { closed = true; });
+				await client.close();
+				if (!closed) { throw new Error("the close listeners weren't called"); }
+				await client.subscribe("devices/#");
+			`,
+			wantErr: "subscribe() failed; reason: the client is closed",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestState(t)
+			broker := newTestBroker(t)
+			require.NoError(t, ts.VU.RuntimeField.Set("BROKER_URL", broker.url()))
+
+			_, err := ts.RunOnEventLoop(`
+				(async function () {
+					` + tt.script + `
+				})()
+			`)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The types of the MQTT 3.1.1 control packets.
+const (
+	packetConnect     byte = 1
+	packetConnack     byte = 2
+	packetPublish     byte = 3
+	packetPuback      byte = 4
+	packetPubrec      byte = 5
+	packetPubrel      byte = 6
+	packetPubcomp     byte = 7
+	packetSubscribe   byte = 8
+	packetSuback      byte = 9
+	packetUnsubscribe byte = 10
+	packetUnsuback    byte = 11
+	packetPingreq     byte = 12
+	packetPingresp    byte = 13
+	packetDisconnect  byte = 14
+)
+
+// maxRemainingLength is the largest remaining length of a packet, as its
+// encoding allows.
+const maxRemainingLength = 268435455
+
+// protocolLevel designates the 3.1.1 version of the protocol.
+const protocolLevel = 4
+
+// subackFailure is the return code of a SUBACK packet for a refused subscription.
+const subackFailure = 0x80
+
+// packet is an MQTT control packet.
+type packet struct {
+	// kind holds the type of the packet.
+	kind byte
+
+	// flags holds the flags of the packet's fixed header.
+	flags byte
+
+	// body holds the variable header and the payload of the packet.
+	body []byte
+}
+
+// readPacket reads a packet from the provided reader.
+func readPacket(r *bufio.Reader) (packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+
+	var length, multiplier int
+	for i := 0; ; i++ {
+		if i == 4 {
+			return packet{}, errors.New("malformed remaining length")
+		}
+
+		b, err := r.ReadByte()
+		if err != nil {
+			return packet{}, unexpectedEOF(err)
+		}
+
+		length += int(b&0x7f) << multiplier
+		multiplier += 7
+
+		if b&0x80 == 0 {
+			break
+		}
+	}
+
+	p := packet{kind: header >> 4, flags: header & 0x0f, body: make([]byte, length)}
+	if _, err := io.ReadFull(r, p.body); err != nil {
+		return packet{}, unexpectedEOF(err)
+	}
+
+	return p, nil
+}
+
+// unexpectedEOF turns the provided end of the stream into an [io.ErrUnexpectedEOF],
+// as it is reached in the middle of a packet.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
+
+// encode returns the encoded packet.
+func (p packet) encode() ([]byte, error) {
+	length := len(p.body)
+	if length > maxRemainingLength {
+		return nil, fmt.Errorf("the packet is too large: %d bytes", length)
+	}
+
+	b := make([]byte, 0, 5+length)
+	b = append(b, p.kind<<4|p.flags)
+
+	for {
+		digit := byte(length & 0x7f)
+		length >>= 7
+
+		if length > 0 {
+			digit |= 0x80
+		}
+
+		b = append(b, digit)
+
+		if length == 0 {
+			break
+		}
+	}
+
+	return append(b, p.body...), nil
+}
+
+// appendString appends the provided string, prefixed by its length, to the
+// given body.
+func appendString(body []byte, s string) []byte {
+	body = binary.BigEndian.AppendUint16(body, uint16(len(s))) //nolint:gosec
+	return append(body, s...)
+}
+
+// readString reads a string, prefixed by its length, from the provided body,
+// and returns it along with the rest of the body.
+func readString(body []byte) (string, []byte, error) {
+	if len(body) < 2 {
+		return "", nil, errors.New("malformed string")
+	}
+
+	length := int(binary.BigEndian.Uint16(body))
+	if len(body) < 2+length {
+		return "", nil, errors.New("malformed string")
+	}
+
+	return string(body[2 : 2+length]), body[2+length:], nil
+}
+
+// connectOptions describes the connection a CONNECT packet requests.
+type connectOptions struct {
+	clientID     string
+	username     string
+	password     string
+	cleanSession bool
+	keepAlive    uint16 // in seconds
+}
+
+// connectPacket returns a CONNECT packet requesting the described connection.
+func connectPacket(opts connectOptions) packet {
+	var flags byte
+	if opts.cleanSession {
+		flags |= 0x02
+	}
+	if opts.username != "" {
+		flags |= 0x80
+	}
+	if opts.password != "" {
+		flags |= 0x40
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, protocolLevel, flags)
+	body = binary.BigEndian.AppendUint16(body, opts.keepAlive)
+	body = appendString(body, opts.clientID)
+
+	if opts.username != "" {
+		body = appendString(body, opts.username)
+	}
+	if opts.password != "" {
+		body = appendString(body, opts.password)
+	}
+
+	return packet{kind: packetConnect, body: body}
+}
+
+// connackErrors describes the reasons a server refuses connections for, by
+// the return codes of CONNACK packets.
+var connackErrors = map[byte]string{ //nolint:gochecknoglobals
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// checkConnack returns an error if the provided CONNACK packet refuses the connection.
+func checkConnack(p packet) error {
+	if p.kind != packetConnack || len(p.body) != 2 {
+		return fmt.Errorf("expected a CONNACK packet, got a packet of type %d", p.kind)
+	}
+
+	if code := p.body[1]; code != 0 {
+		reason, ok := connackErrors[code]
+		if !ok {
+			reason = fmt.Sprintf("return code %d", code)
+		}
+
+		return fmt.Errorf("the server refused the connection: %s", reason)
+	}
+
+	return nil
+}
+
+// message is an application message, as a PUBLISH packet carries.
+type message struct {
+	topic   string
+	payload []byte
+	qos     byte
+	retain  bool
+	dup     bool
+
+	// id holds the identifier of the packet, for the QoS levels 1 and 2.
+	id uint16
+}
+
+// publishPacket returns a PUBLISH packet carrying the provided message.
+func publishPacket(m message) packet {
+	flags := m.qos << 1
+	if m.retain {
+		flags |= 0x01
+	}
+	if m.dup {
+		flags |= 0x08
+	}
+
+	body := appendString(nil, m.topic)
+	if m.qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, m.id)
+	}
+
+	return packet{kind: packetPublish, flags: flags, body: append(body, m.payload...)}
+}
+
+// parsePublish returns the message the provided PUBLISH packet carries.
+func parsePublish(p packet) (message, error) {
+	m := message{
+		qos:    (p.flags >> 1) & 0x03,
+		retain: p.flags&0x01 != 0,
+		dup:    p.flags&0x08 != 0,
+	}
+
+	if m.qos > 2 {
+		return message{}, fmt.Errorf("malformed PUBLISH packet: invalid QoS %d", m.qos)
+	}
+
+	topic, rest, err := readString(p.body)
+	if err != nil {
+		return message{}, fmt.Errorf("malformed PUBLISH packet: %w", err)
+	}
+	m.topic = topic
+
+	if m.qos > 0 {
+		if len(rest) < 2 {
+			return message{}, errors.New("malformed PUBLISH packet: missing packet identifier")
+		}
+
+		m.id = binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+	}
+
+	m.payload = rest
+
+	return m, nil
+}
+
+// ackPacket returns a packet of the provided type, acknowledging the packet
+// of the given identifier, such as PUBACK.
+func ackPacket(kind byte, id uint16) packet {
+	var flags byte
+	if kind == packetPubrel {
+		flags = 0x02
+	}
+
+	return packet{kind: kind, flags: flags, body: binary.BigEndian.AppendUint16(nil, id)}
+}
+
+// packetID returns the identifier of the provided packet, which starts its body.
+func packetID(p packet) (uint16, error) {
+	if len(p.body) < 2 {
+		return 0, fmt.Errorf("malformed packet of type %d: missing packet identifier", p.kind)
+	}
+
+	return binary.BigEndian.Uint16(p.body), nil
+}
+
+// subscribePacket returns a SUBSCRIBE packet, of the provided identifier,
+// subscribing to the given topic filter with the maximum QoS.
+func subscribePacket(id uint16, filter string, qos byte) packet {
+	body := binary.BigEndian.AppendUint16(nil, id)
+	body = appendString(body, filter)
+	body = append(body, qos)
+
+	return packet{kind: packetSubscribe, flags: 0x02, body: body}
+}
+
+// unsubscribePacket returns an UNSUBSCRIBE packet, of the provided identifier,
+// unsubscribing from the given topic filter.
+func unsubscribePacket(id uint16, filter string) packet {
+	body := binary.BigEndian.AppendUint16(nil, id)
+	body = appendString(body, filter)
+
+	return packet{kind: packetUnsubscribe, flags: 0x02, body: body}
+}
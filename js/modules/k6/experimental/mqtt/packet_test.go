@@ -0,0 +1,93 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketEncoding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		packet packet
+		header []byte
+	}{
+		{
+			name:   "empty",
+			packet: packet{kind: packetPingreq},
+			header: []byte{0xc0, 0x00},
+		},
+		{
+			name:   "one length byte",
+			packet: packet{kind: packetPublish, flags: 0x03, body: make([]byte, 127)},
+			header: []byte{0x33, 0x7f},
+		},
+		{
+			name:   "two length bytes",
+			packet: packet{kind: packetPublish, body: make([]byte, 128)},
+			header: []byte{0x30, 0x80, 0x01},
+		},
+		{
+			name:   "three length bytes",
+			packet: packet{kind: packetPublish, body: make([]byte, 16384)},
+			header: []byte{0x30, 0x80, 0x80, 0x01},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := tt.packet.encode()
+			require.NoError(t, err)
+			assert.Equal(t, tt.header, b[:len(tt.header)])
+
+			p, err := readPacket(bufio.NewReader(bytes.NewReader(b)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.packet.kind, p.kind)
+			assert.Equal(t, tt.packet.flags, p.flags)
+			assert.Len(t, p.body, len(tt.packet.body))
+		})
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := readPacket(bufio.NewReader(bytes.NewReader([]byte{0x30, 0x05, 0x00})))
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
+
+func TestPublishPacket(t *testing.T) {
+	t.Parallel()
+
+	want := message{topic: "devices/1", payload: []byte("on"), qos: 2, retain: true, dup: true, id: 42}
+
+	m, err := parsePublish(publishPacket(want))
+	require.NoError(t, err)
+	assert.Equal(t, want, m)
+
+	m, err = parsePublish(publishPacket(message{topic: "devices/1", payload: []byte("off")}))
+	require.NoError(t, err)
+	assert.Equal(t, message{topic: "devices/1", payload: []byte("off")}, m)
+}
+
+func TestCheckConnack(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, checkConnack(packet{kind: packetConnack, body: []byte{0, 0}}))
+	assert.EqualError(t,
+		checkConnack(packet{kind: packetConnack, body: []byte{0, 5}}),
+		"the server refused the connection: not authorized")
+	assert.EqualError(t,
+		checkConnack(packet{kind: packetSuback, body: []byte{0, 1, 0}}),
+		"expected a CONNACK packet, got a packet of type 9")
+}
@@ -0,0 +1,130 @@
+// Package pacing provides a k6 module modeling the think time of the users,
+// with sleeps of randomly distributed durations, and the pacing of the
+// iterations, with a sleep for the remainder of a target iteration duration.
+package pacing
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the pacing module for a single VU.
+	ModuleInstance struct {
+		vu modules.VU
+
+		// rnd is the source of the sleep durations, which is created on its
+		// first use, seeded with the current time, unless it was seeded before.
+		rnd *rand.Rand
+	}
+
+	// Sleep sleeps for durations following a random distribution.
+	Sleep struct {
+		mi *ModuleInstance
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"sleep": &Sleep{mi: mi},
+			"pace":  mi.Pace,
+			"seed":  mi.Seed,
+		},
+	}
+}
+
+// Seed resets the source of the sleep durations of the VU to the given seed,
+// which makes them deterministic.
+func (mi *ModuleInstance) Seed(seed int64) {
+	mi.rnd = rand.New(rand.NewSource(seed)) //nolint:gosec
+}
+
+func (mi *ModuleInstance) rand() *rand.Rand {
+	if mi.rnd == nil {
+		mi.Seed(time.Now().UnixNano())
+	}
+	return mi.rnd
+}
+
+// Pace sleeps for the remainder of the target duration of the iteration, in
+// seconds, and returns the duration it slept for. It doesn't sleep if the
+// iteration already lasted longer.
+func (mi *ModuleInstance) Pace(target float64) (float64, error) {
+	state := mi.vu.State()
+	if state == nil {
+		return 0, common.NewInitContextError("using pace() in the init context is not supported")
+	}
+	if math.IsNaN(target) || target < 0 {
+		return 0, fmt.Errorf("the target iteration duration must be a non-negative number of seconds, got %v", target)
+	}
+
+	remaining := time.Duration(target*float64(time.Second)) - time.Since(state.IterationStart)
+	if remaining <= 0 {
+		return 0, nil
+	}
+	return mi.sleep(remaining.Seconds()), nil
+}
+
+// sleep sleeps for the given number of seconds, or until the VU is stopped,
+// and returns it.
+func (mi *ModuleInstance) sleep(secs float64) float64 {
+	timer := time.NewTimer(time.Duration(secs * float64(time.Second)))
+	select {
+	case <-timer.C:
+	case <-mi.vu.Context().Done():
+		timer.Stop()
+	}
+	return secs
+}
+
+// Normal sleeps for a duration following a normal distribution of the given
+// mean and standard deviation, in seconds, and returns it. The negative
+// durations, which the distribution can give, are clamped to zero.
+func (s *Sleep) Normal(mean, stddev float64) (float64, error) {
+	if math.IsNaN(mean) || math.IsInf(mean, 0) || mean < 0 {
+		return 0, fmt.Errorf("the mean must be a non-negative number of seconds, got %v", mean)
+	}
+	if math.IsNaN(stddev) || math.IsInf(stddev, 0) || stddev < 0 {
+		return 0, fmt.Errorf("the standard deviation must be a non-negative number of seconds, got %v", stddev)
+	}
+
+	return s.mi.sleep(math.Max(0, mean+stddev*s.mi.rand().NormFloat64())), nil
+}
+
+// Exponential sleeps for a duration following an exponential distribution of
+// the given rate, i.e. with a mean of 1/rate seconds, and returns it.
+func (s *Sleep) Exponential(rate float64) (float64, error) {
+	if math.IsNaN(rate) || math.IsInf(rate, 0) || rate <= 0 {
+		return 0, fmt.Errorf("the rate must be a positive number, got %v", rate)
+	}
+
+	return s.mi.sleep(s.mi.rand().ExpFloat64() / rate), nil
+}
@@ -0,0 +1,96 @@
+package pacing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+)
+
+func newPacingRuntime(t *testing.T) *modulestest.Runtime {
+	t.Helper()
+
+	runtime := modulestest.NewRuntime(t)
+	err := runtime.SetupModuleSystem(map[string]any{"k6/experimental/pacing": New()}, nil, nil)
+	require.NoError(t, err)
+	_, err = runtime.VU.Runtime().RunString(`var { sleep, pace, seed } = require("k6/experimental/pacing");`)
+	require.NoError(t, err)
+
+	return runtime
+}
+
+func TestSleepDistributions(t *testing.T) {
+	t.Parallel()
+
+	runtime := newPacingRuntime(t)
+	result, err := runtime.VU.Runtime().RunString(`
+		seed(42);
+		const durations = [sleep.normal(0.005, 0.002), sleep.exponential(1000), sleep.normal(0.003, 0)];
+		seed(42);
+		const again = [sleep.normal(0.005, 0.002), sleep.exponential(1000), sleep.normal(0.003, 0)];
+		if (JSON.stringify(durations) !== JSON.stringify(again)) {
+			throw new Error("the same seed gave different durations");
+		}
+		durations;
+	`)
+	require.NoError(t, err)
+
+	var durations []float64
+	require.NoError(t, runtime.VU.Runtime().ExportTo(result, &durations))
+	require.Len(t, durations, 3)
+	assert.GreaterOrEqual(t, durations[0], 0.0)
+	assert.Greater(t, durations[1], 0.0)
+	assert.Equal(t, 0.003, durations[2])
+}
+
+func TestSleepInvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		`sleep.normal(-1, 1)`:         "the mean must be a non-negative number of seconds, got -1",
+		`sleep.normal(1, -1)`:         "the standard deviation must be a non-negative number of seconds, got -1",
+		`sleep.normal(1, NaN)`:        "the standard deviation must be a non-negative number of seconds, got NaN",
+		`sleep.exponential(0)`:        "the rate must be a positive number, got 0",
+		`sleep.exponential(Infinity)`: "the rate must be a positive number, got +Inf",
+	}
+	for code, expErr := range tests {
+		code, expErr := code, expErr
+		t.Run(code, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := newPacingRuntime(t)
+			_, err := runtime.VU.Runtime().RunString(code)
+			require.ErrorContains(t, err, expErr)
+		})
+	}
+}
+
+func TestPace(t *testing.T) {
+	t.Parallel()
+
+	runtime := newPacingRuntime(t)
+	_, err := runtime.VU.Runtime().RunString(`pace(1)`)
+	require.ErrorContains(t, err, "using pace() in the init context is not supported")
+
+	state := &lib.State{IterationStart: time.Now().Add(-990 * time.Millisecond)}
+	runtime.MoveToVUContext(state)
+
+	start := time.Now()
+	result, err := runtime.VU.Runtime().RunString(`pace(1)`)
+	require.NoError(t, err)
+	assert.Greater(t, result.ToFloat(), 0.0)
+	assert.LessOrEqual(t, result.ToFloat(), 0.01)
+	assert.GreaterOrEqual(t, time.Since(start), time.Duration(result.ToFloat()*float64(time.Second)))
+
+	// The iteration already lasted longer than the target.
+	result, err = runtime.VU.Runtime().RunString(`pace(0.5)`)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, result.ToFloat())
+
+	_, err = runtime.VU.Runtime().RunString(`pace(-1)`)
+	require.ErrorContains(t, err, "the target iteration duration must be a non-negative number of seconds, got -1")
+}
@@ -0,0 +1,317 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// column describes a column of a Parquet file's schema.
+type column struct {
+	// index holds the column's index among the file's columns, which is also
+	// the index of its chunk within each row group.
+	index int
+
+	// element holds the schema element describing the column.
+	element schemaElement
+}
+
+// optional indicates whether the column's values can be null.
+func (c column) optional() bool {
+	return c.element.repetitionType == repetitionOptional
+}
+
+// byteArray returns the provided byte array value as a string, if the column
+// is annotated as holding text, or as a copy of it otherwise.
+func (c column) byteArray(b []byte) any {
+	if c.element.isString() {
+		return string(b)
+	}
+
+	return append([]byte(nil), b...)
+}
+
+// columnsFrom returns the columns described by the provided schema.
+//
+// Only flat schemas, made of required or optional columns, are supported.
+func columnsFrom(schema []schemaElement) ([]column, error) {
+	if len(schema) == 0 {
+		return nil, errors.New("the file's schema is empty")
+	}
+
+	columns := make([]column, 0, len(schema)-1)
+
+	for i, e := range schema[1:] {
+		if e.numChildren > 0 || !e.hasType {
+			return nil, fmt.Errorf("column %q is nested, which is not supported", e.name)
+		}
+
+		if e.repetitionType == repetitionRepeated {
+			return nil, fmt.Errorf("column %q is repeated, which is not supported", e.name)
+		}
+
+		columns = append(columns, column{index: i, element: e})
+	}
+
+	if int(schema[0].numChildren) != len(columns) {
+		return nil, errors.New("the file's schema is nested, which is not supported")
+	}
+
+	return columns, nil
+}
+
+// project returns the provided columns designated by the given names, in the
+// order of the names. All the columns are returned if no name is provided.
+func project(columns []column, names []string) ([]column, error) {
+	if len(names) == 0 {
+		return columns, nil
+	}
+
+	projected := make([]column, 0, len(names))
+
+	for _, name := range names {
+		found := false
+
+		for _, c := range columns {
+			if c.element.name == name {
+				projected = append(projected, c)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("the file holds no column named %q", name)
+		}
+	}
+
+	return projected, nil
+}
+
+// readColumnChunk reads the values of the provided column held by the given
+// chunk of a row group holding numRows rows.
+//
+// Null values are returned as nil.
+func readColumnChunk(r io.ReadSeeker, col column, chunk columnChunk, numRows int64) ([]any, error) {
+	if len(chunk.path) > 0 && strings.Join(chunk.path, ".") != col.element.name {
+		return nil, fmt.Errorf("the chunk of column %q holds column %q", col.element.name, strings.Join(chunk.path, "."))
+	}
+
+	if chunk.totalCompressedSize <= 0 {
+		return nil, fmt.Errorf("the chunk of column %q is empty", col.element.name)
+	}
+
+	if _, err := r.Seek(chunk.start(), io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, chunk.totalCompressedSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("failed to read the chunk of column %q; reason: %w", col.element.name, err)
+	}
+
+	values := make([]any, 0, numRows)
+
+	var dictionary []any
+
+	for int64(len(values)) < numRows {
+		if len(data) == 0 {
+			return nil, fmt.Errorf("the chunk of column %q holds %d values, expected %d", col.element.name, len(values), numRows)
+		}
+
+		header, n, err := decodePageHeader(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page header in the chunk of column %q; reason: %w", col.element.name, err)
+		}
+
+		if header.compressedSize < 0 || int(header.compressedSize) > len(data)-n {
+			return nil, fmt.Errorf("truncated page in the chunk of column %q", col.element.name)
+		}
+
+		body := data[n : n+int(header.compressedSize)]
+		data = data[n+int(header.compressedSize):]
+
+		switch header.typ {
+		case pageDictionary:
+			dictionary, err = readDictionaryPage(col, chunk.codec, header, body)
+		case pageData, pageDataV2:
+			var page []any
+			if page, err = readDataPage(col, chunk.codec, header, body, dictionary); err == nil {
+				values = append(values, page...)
+			}
+		default:
+			// Index pages do not hold values.
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid page in the chunk of column %q; reason: %w", col.element.name, err)
+		}
+	}
+
+	return values, nil
+}
+
+// readDictionaryPage reads the values of a dictionary page.
+func readDictionaryPage(col column, codec int32, header pageHeader, body []byte) ([]any, error) {
+	data, err := decompress(codec, body, header.uncompressedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodePlain(col, data, int(header.numValues))
+}
+
+// readDataPage reads the values of a data page, of either version, nulls
+// included, out of the provided dictionary, if the page's values are
+// dictionary encoded.
+func readDataPage(col column, codec int32, header pageHeader, body []byte, dictionary []any) ([]any, error) {
+	var (
+		levels []byte
+		data   []byte
+		err    error
+	)
+
+	if header.typ == pageDataV2 {
+		length := int(header.definitionLevelsLength) + int(header.repetitionLevelsLength)
+		if header.definitionLevelsLength < 0 || header.repetitionLevelsLength < 0 || length > len(body) {
+			return nil, errTruncatedPage
+		}
+
+		levels, data = body[:header.definitionLevelsLength], body[length:]
+		if header.compressed {
+			if data, err = decompress(codec, data, header.uncompressedSize-int32(length)); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		if data, err = decompress(codec, body, header.uncompressedSize); err != nil {
+			return nil, err
+		}
+
+		// The definition levels of version 1 data pages are prefixed with their length.
+		if col.optional() {
+			if levels, data, err = prefixed(data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	count := int(header.numValues)
+	defined := make([]bool, count)
+	nonNull := count
+
+	if col.optional() {
+		definitions, err := decodeHybrid(levels, 1, count)
+		if err != nil {
+			return nil, err
+		}
+
+		nonNull = 0
+		for i, level := range definitions {
+			defined[i] = level == 1
+			if defined[i] {
+				nonNull++
+			}
+		}
+	} else {
+		for i := range defined {
+			defined[i] = true
+		}
+	}
+
+	present, err := decodeValues(col, header, data, nonNull, dictionary)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]any, count)
+	for i, next := 0, 0; i < count; i++ {
+		if defined[i] {
+			values[i] = present[next]
+			next++
+		}
+	}
+
+	return values, nil
+}
+
+// decodeValues decodes the n non-null values of a data page, encoded through
+// the page's encoding.
+func decodeValues(col column, header pageHeader, data []byte, n int, dictionary []any) ([]any, error) {
+	switch header.encoding {
+	case encodingPlain:
+		return decodePlain(col, data, n)
+	case encodingPlainDictionary, encodingRLEDictionary:
+		if dictionary == nil {
+			return nil, errors.New("dictionary encoded values are not preceded by a dictionary page")
+		}
+
+		if len(data) == 0 {
+			if n == 0 {
+				return nil, nil
+			}
+
+			return nil, errTruncatedPage
+		}
+
+		indices, err := decodeHybrid(data[1:], int(data[0]), n)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]any, n)
+		for i, index := range indices {
+			if index < 0 || int(index) >= len(dictionary) {
+				return nil, fmt.Errorf("dictionary index %d is out of range", index)
+			}
+
+			values[i] = dictionary[index]
+		}
+
+		return values, nil
+	case encodingRLE:
+		if col.element.typ != typeBoolean {
+			return nil, errors.New("run length encoding is only supported for booleans")
+		}
+
+		// Version 1 data pages prefix run length encoded values with their length.
+		if header.typ == pageData {
+			var err error
+			if data, _, err = prefixed(data); err != nil {
+				return nil, err
+			}
+		}
+
+		bits, err := decodeHybrid(data, 1, n)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]any, n)
+		for i, bit := range bits {
+			values[i] = bit == 1
+		}
+
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %d", header.encoding)
+	}
+}
+
+// prefixed splits the provided data into the section prefixed with its 4-byte
+// little-endian length, and the data following it.
+func prefixed(data []byte) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, errTruncatedPage
+	}
+
+	length := binary.LittleEndian.Uint32(data)
+	if uint64(length) > uint64(len(data)-4) {
+		return nil, nil, errTruncatedPage
+	}
+
+	return data[4 : 4+length], data[4+length:], nil
+}
@@ -0,0 +1,229 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// The encodings of Parquet values, and levels.
+const (
+	encodingPlain           = 0
+	encodingPlainDictionary = 2
+	encodingRLE             = 3
+	encodingRLEDictionary   = 8
+)
+
+// The compression codecs of Parquet pages.
+const (
+	codecUncompressed = 0
+	codecSnappy       = 1
+	codecGzip         = 2
+	codecZstd         = 6
+)
+
+// errTruncatedPage is returned when a page's data ends unexpectedly.
+var errTruncatedPage = errors.New("truncated page data")
+
+// decompress decompresses the provided page data, compressed with the given
+// codec, into uncompressedSize bytes.
+func decompress(codec int32, data []byte, uncompressedSize int32) ([]byte, error) {
+	switch codec {
+	case codecUncompressed:
+		return data, nil
+	case codecSnappy:
+		return snappy.Decode(make([]byte, 0, uncompressedSize), data)
+	case codecGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		defer func() { _ = r.Close() }()
+
+		return readAllSized(r, uncompressedSize)
+	case codecZstd:
+		r, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		defer r.Close()
+
+		return readAllSized(r, uncompressedSize)
+	default:
+		return nil, fmt.Errorf("unsupported compression codec %d", codec)
+	}
+}
+
+// readAllSized reads the uncompressedSize bytes the provided decompressing
+// reader is expected to produce.
+func readAllSized(r io.Reader, uncompressedSize int32) ([]byte, error) {
+	data := make([]byte, uncompressedSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// decodeHybrid decodes n values, each bitWidth bits wide, encoded through the
+// hybrid of run length encoding and bit-packing Parquet encodes levels, and
+// dictionary indices, with.
+func decodeHybrid(data []byte, bitWidth int, n int) ([]int32, error) {
+	if bitWidth < 0 || bitWidth > 32 {
+		return nil, fmt.Errorf("invalid bit width %d", bitWidth)
+	}
+
+	values := make([]int32, 0, n)
+	byteWidth := (bitWidth + 7) / 8
+
+	for len(values) < n {
+		header, size := binary.Uvarint(data)
+		if size <= 0 {
+			return nil, errTruncatedPage
+		}
+
+		data = data[size:]
+
+		if header&1 == 0 {
+			// A run of a single value, held by the following bytes.
+			if len(data) < byteWidth {
+				return nil, errTruncatedPage
+			}
+
+			var v uint32
+			for i := 0; i < byteWidth; i++ {
+				v |= uint32(data[i]) << (8 * i)
+			}
+
+			data = data[byteWidth:]
+
+			for count := header >> 1; count > 0 && len(values) < n; count-- {
+				values = append(values, int32(v))
+			}
+
+			continue
+		}
+
+		// Groups of 8 bit-packed values, packed from the least significant bit.
+		length := int(header>>1) * bitWidth
+		if len(data) < length {
+			return nil, errTruncatedPage
+		}
+
+		for bit := 0; bit+bitWidth <= length*8 && len(values) < n; bit += bitWidth {
+			var v uint32
+			for i := 0; i < bitWidth; i++ {
+				if data[(bit+i)/8]&(1<<((bit+i)%8)) != 0 {
+					v |= 1 << i
+				}
+			}
+
+			values = append(values, int32(v))
+		}
+
+		data = data[length:]
+	}
+
+	return values, nil
+}
+
+// decodePlain decodes n values of the provided column, encoded through the
+// plain encoding.
+//
+// Values are decoded to their Go counterpart: booleans to bool, integers to
+// int64, floating point numbers to float64, int96 timestamps to [time.Time],
+// and byte arrays to string, when annotated as holding text, or []byte.
+func decodePlain(col column, data []byte, n int) ([]any, error) {
+	values := make([]any, 0, n)
+
+	// fixed takes the next size bytes, of values of a fixed size.
+	fixed := func(size int) ([]byte, error) {
+		if len(data) < size {
+			return nil, errTruncatedPage
+		}
+
+		b := data[:size]
+		data = data[size:]
+
+		return b, nil
+	}
+
+	for i := 0; i < n; i++ {
+		var (
+			b   []byte
+			err error
+		)
+
+		switch col.element.typ {
+		case typeBoolean:
+			if len(data) <= i/8 {
+				return nil, errTruncatedPage
+			}
+
+			values = append(values, data[i/8]&(1<<(i%8)) != 0)
+			continue
+		case typeInt32:
+			if b, err = fixed(4); err == nil {
+				values = append(values, int64(int32(binary.LittleEndian.Uint32(b))))
+			}
+		case typeInt64:
+			if b, err = fixed(8); err == nil {
+				values = append(values, int64(binary.LittleEndian.Uint64(b)))
+			}
+		case typeInt96:
+			if b, err = fixed(12); err == nil {
+				values = append(values, int96Time(b))
+			}
+		case typeFloat:
+			if b, err = fixed(4); err == nil {
+				values = append(values, float64(math.Float32frombits(binary.LittleEndian.Uint32(b))))
+			}
+		case typeDouble:
+			if b, err = fixed(8); err == nil {
+				values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(b)))
+			}
+		case typeByteArray:
+			if b, err = fixed(4); err == nil {
+				b, err = fixed(int(binary.LittleEndian.Uint32(b)))
+			}
+
+			if err == nil {
+				values = append(values, col.byteArray(b))
+			}
+		case typeFixedLenByteArray:
+			if b, err = fixed(int(col.element.typeLength)); err == nil {
+				values = append(values, col.byteArray(b))
+			}
+		default:
+			return nil, fmt.Errorf("unsupported physical type %d", col.element.typ)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// julianUnixEpoch holds the Julian day of the Unix epoch.
+const julianUnixEpoch = 2440588
+
+// int96Time converts the provided int96 timestamp, made of the nanoseconds
+// within the day followed by the Julian day, to a [time.Time].
+func int96Time(b []byte) time.Time {
+	nanos := int64(binary.LittleEndian.Uint64(b[:8]))
+	day := int64(binary.LittleEndian.Uint32(b[8:]))
+
+	return time.Unix((day-julianUnixEpoch)*24*60*60, nanos).UTC()
+}
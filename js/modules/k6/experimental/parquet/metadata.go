@@ -0,0 +1,354 @@
+package parquet
+
+import (
+	"fmt"
+)
+
+// The physical types of Parquet columns.
+const (
+	typeBoolean           = 0
+	typeInt32             = 1
+	typeInt64             = 2
+	typeInt96             = 3
+	typeFloat             = 4
+	typeDouble            = 5
+	typeByteArray         = 6
+	typeFixedLenByteArray = 7
+)
+
+// The repetition types of Parquet columns.
+const (
+	repetitionRequired = 0
+	repetitionOptional = 1
+	repetitionRepeated = 2
+)
+
+// The converted types, annotating Parquet columns, consumed by the module.
+const (
+	convertedUTF8 = 0
+	convertedEnum = 4
+	convertedJSON = 19
+)
+
+// The fields of the logicalType union consumed by the module.
+const (
+	logicalString = 1
+	logicalEnum   = 4
+	logicalJSON   = 12
+)
+
+// fileMetadata holds the parts of a Parquet file's metadata used by the module.
+type fileMetadata struct {
+	schema    []schemaElement
+	numRows   int64
+	rowGroups []rowGroup
+}
+
+// schemaElement describes a node of a Parquet file's schema. The first element
+// describes the schema's root, and the following ones its descendants, in
+// depth-first order.
+type schemaElement struct {
+	typ            int32
+	hasType        bool
+	typeLength     int32
+	repetitionType int32
+	name           string
+	numChildren    int32
+	convertedType  int32
+	hasConverted   bool
+
+	// logicalType holds the id of the field set in the logicalType union,
+	// or 0 if none is.
+	logicalType int16
+}
+
+// isString indicates whether the element's byte arrays are annotated as
+// holding text.
+func (e schemaElement) isString() bool {
+	switch e.logicalType {
+	case logicalString, logicalEnum, logicalJSON:
+		return true
+	}
+
+	if !e.hasConverted {
+		return false
+	}
+
+	switch e.convertedType {
+	case convertedUTF8, convertedEnum, convertedJSON:
+		return true
+	}
+
+	return false
+}
+
+// rowGroup describes a group of rows of a Parquet file, and the chunks its
+// columns are stored in.
+type rowGroup struct {
+	columns []columnChunk
+	numRows int64
+}
+
+// columnChunk describes the chunk holding a column's values for a row group.
+type columnChunk struct {
+	typ                     int32
+	path                    []string
+	codec                   int32
+	numValues               int64
+	totalCompressedSize     int64
+	dataPageOffset          int64
+	dictionaryPageOffset    int64
+	hasDictionaryPageOffset bool
+}
+
+// start returns the offset of the chunk's first page.
+func (c columnChunk) start() int64 {
+	if c.hasDictionaryPageOffset && c.dictionaryPageOffset > 0 && c.dictionaryPageOffset < c.dataPageOffset {
+		return c.dictionaryPageOffset
+	}
+
+	return c.dataPageOffset
+}
+
+// decodeFileMetadata decodes a Parquet file's metadata, as held by its footer.
+func decodeFileMetadata(data []byte) (fileMetadata, error) {
+	var md fileMetadata
+	d := newThriftDecoder(data)
+
+	err := d.readStruct(func(id int16, typ byte) error {
+		var err error
+
+		switch id {
+		case 2:
+			err = d.readList(func(byte) error {
+				e, err := decodeSchemaElement(d)
+				md.schema = append(md.schema, e)
+				return err
+			})
+		case 3:
+			md.numRows, err = d.readI64()
+		case 4:
+			err = d.readList(func(byte) error {
+				rg, err := decodeRowGroup(d)
+				md.rowGroups = append(md.rowGroups, rg)
+				return err
+			})
+		default:
+			err = d.skip(typ)
+		}
+
+		return err
+	})
+	if err != nil {
+		return fileMetadata{}, fmt.Errorf("invalid file metadata; reason: %w", err)
+	}
+
+	return md, nil
+}
+
+// decodeSchemaElement decodes a SchemaElement struct.
+func decodeSchemaElement(d *thriftDecoder) (schemaElement, error) {
+	var e schemaElement
+
+	err := d.readStruct(func(id int16, typ byte) error {
+		var err error
+
+		switch id {
+		case 1:
+			e.typ, err = d.readI32()
+			e.hasType = true
+		case 2:
+			e.typeLength, err = d.readI32()
+		case 3:
+			e.repetitionType, err = d.readI32()
+		case 4:
+			e.name, err = d.readString()
+		case 5:
+			e.numChildren, err = d.readI32()
+		case 6:
+			e.convertedType, err = d.readI32()
+			e.hasConverted = true
+		case 10:
+			// The logicalType union holds a single, empty for the
+			// types consumed here, struct field.
+			err = d.readStruct(func(id int16, typ byte) error {
+				e.logicalType = id
+				return d.skip(typ)
+			})
+		default:
+			err = d.skip(typ)
+		}
+
+		return err
+	})
+
+	return e, err
+}
+
+// decodeRowGroup decodes a RowGroup struct.
+func decodeRowGroup(d *thriftDecoder) (rowGroup, error) {
+	var rg rowGroup
+
+	err := d.readStruct(func(id int16, typ byte) error {
+		var err error
+
+		switch id {
+		case 1:
+			err = d.readList(func(byte) error {
+				c, err := decodeColumnChunk(d)
+				rg.columns = append(rg.columns, c)
+				return err
+			})
+		case 3:
+			rg.numRows, err = d.readI64()
+		default:
+			err = d.skip(typ)
+		}
+
+		return err
+	})
+
+	return rg, err
+}
+
+// decodeColumnChunk decodes a ColumnChunk struct, out of which only the
+// ColumnMetaData struct it holds is used.
+func decodeColumnChunk(d *thriftDecoder) (columnChunk, error) {
+	var c columnChunk
+
+	err := d.readStruct(func(id int16, typ byte) error {
+		if id != 3 {
+			return d.skip(typ)
+		}
+
+		return d.readStruct(func(id int16, typ byte) error {
+			var err error
+
+			switch id {
+			case 1:
+				c.typ, err = d.readI32()
+			case 3:
+				err = d.readList(func(byte) error {
+					name, err := d.readString()
+					c.path = append(c.path, name)
+					return err
+				})
+			case 4:
+				c.codec, err = d.readI32()
+			case 5:
+				c.numValues, err = d.readI64()
+			case 7:
+				c.totalCompressedSize, err = d.readI64()
+			case 9:
+				c.dataPageOffset, err = d.readI64()
+			case 11:
+				c.dictionaryPageOffset, err = d.readI64()
+				c.hasDictionaryPageOffset = true
+			default:
+				err = d.skip(typ)
+			}
+
+			return err
+		})
+	})
+
+	return c, err
+}
+
+// The types of Parquet pages.
+const (
+	pageData       = 0
+	pageIndex      = 1
+	pageDictionary = 2
+	pageDataV2     = 3
+)
+
+// pageHeader holds the parts of a Parquet page's header used by the module.
+type pageHeader struct {
+	typ              int32
+	uncompressedSize int32
+	compressedSize   int32
+
+	// numValues holds the number of values, nulls included, of a data
+	// page, or the number of entries of a dictionary page.
+	numValues int32
+
+	// encoding holds the encoding of the page's values.
+	encoding int32
+
+	// definitionLevelsLength and repetitionLevelsLength hold the length of
+	// the levels preceding the values of a version 2 data page.
+	definitionLevelsLength int32
+	repetitionLevelsLength int32
+
+	// compressed indicates whether the values of a version 2 data page are
+	// compressed. The levels are never compressed.
+	compressed bool
+}
+
+// decodePageHeader decodes a PageHeader struct, and returns it along with its
+// encoded length.
+func decodePageHeader(data []byte) (pageHeader, int, error) {
+	h := pageHeader{compressed: true}
+	d := newThriftDecoder(data)
+
+	err := d.readStruct(func(id int16, typ byte) error {
+		var err error
+
+		switch id {
+		case 1:
+			h.typ, err = d.readI32()
+		case 2:
+			h.uncompressedSize, err = d.readI32()
+		case 3:
+			h.compressedSize, err = d.readI32()
+		case 5, 7:
+			// DataPageHeader and DictionaryPageHeader both start with
+			// the number of values and their encoding.
+			err = d.readStruct(func(id int16, typ byte) error {
+				var err error
+
+				switch id {
+				case 1:
+					h.numValues, err = d.readI32()
+				case 2:
+					h.encoding, err = d.readI32()
+				default:
+					err = d.skip(typ)
+				}
+
+				return err
+			})
+		case 8:
+			err = d.readStruct(func(id int16, typ byte) error {
+				var err error
+
+				switch id {
+				case 1:
+					h.numValues, err = d.readI32()
+				case 4:
+					h.encoding, err = d.readI32()
+				case 5:
+					h.definitionLevelsLength, err = d.readI32()
+				case 6:
+					h.repetitionLevelsLength, err = d.readI32()
+				case 7:
+					h.compressed, err = d.readBool()
+				default:
+					err = d.skip(typ)
+				}
+
+				return err
+			})
+		default:
+			err = d.skip(typ)
+		}
+
+		return err
+	})
+	if err != nil {
+		return pageHeader{}, 0, err
+	}
+
+	return h, d.pos, nil
+}
@@ -0,0 +1,411 @@
+// Package parquet provides a k6 module that allows users to read the rows of
+// Parquet files, opened through the [fs] module, in a streaming fashion.
+//
+// Only a subset of the format is supported: flat schemas, made of required or
+// optional columns, the plain and dictionary encodings, and the uncompressed,
+// snappy, gzip, and zstd compression codecs.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/modules/k6/experimental/fs"
+)
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the parquet module for a single VU.
+	ModuleInstance struct {
+		vu modules.VU
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"Parser": mi.NewParser,
+		},
+	}
+}
+
+// magic holds the bytes Parquet files start and end with.
+var magic = []byte("PAR1")
+
+// Parser is a Parquet parser.
+type Parser struct {
+	// reader reads the provided input file.
+	reader io.ReadSeeker
+
+	// metadata holds the file's metadata, as read from its footer.
+	metadata fileMetadata
+
+	// columns holds the columns the parser returns, in the order they are
+	// returned in.
+	columns []column
+
+	// rowGroups holds the indices of the row groups the parser reads, in the
+	// order they are read in.
+	rowGroups []int
+
+	// group holds the index, within rowGroups, of the next row group to read.
+	group int
+
+	// values holds the values of the columns, in the same order as columns,
+	// of the row group being read.
+	values [][]any
+
+	// row holds the index, within the row group being read, of the next row
+	// to return.
+	row int
+
+	// mu serializes the reads, which happen in background goroutines.
+	mu sync.Mutex
+
+	// vu is the VU instance that owns this module instance.
+	vu modules.VU
+}
+
+// parseResult holds the result of a Parquet parser's parsing operation such
+// as when calling the [Parser.Next] method.
+type parseResult struct {
+	// Done indicates whether the parser has finished reading the file.
+	Done bool `js:"done"`
+
+	// Value holds the row's values.
+	Value sobek.Value `js:"value"`
+}
+
+// NewParser creates a new Parquet parser instance.
+//
+// The file's metadata is read as the parser is created, while its rows are
+// only read as they are requested. The parser is async iterable, each
+// iteration resolving to the result [Parser.Next] would.
+func (mi *ModuleInstance) NewParser(call sobek.ConstructorCall) *sobek.Object {
+	rt := mi.vu.Runtime()
+
+	if mi.vu.State() != nil {
+		common.Throw(rt, errors.New("parquet Parser constructor must be called in the init context"))
+	}
+
+	if len(call.Arguments) < 1 || common.IsNullish(call.Argument(0)) {
+		common.Throw(rt, errors.New("parquet Parser constructor takes at least one non-nil source argument"))
+	}
+
+	// Obtain the file argument from the constructor call
+	var file *fs.File
+	if err := rt.ExportTo(call.Argument(0), &file); err != nil || file == nil || file.Impl == nil {
+		common.Throw(rt, fmt.Errorf("first argument expected to be a fs.File instance, got %T instead", call.Argument(0)))
+	}
+
+	options := newDefaultParserOptions()
+	if len(call.Arguments) > 1 && !common.IsNullish(call.Argument(1)) {
+		var err error
+		options, err = newParserOptionsFrom(rt, call.Argument(1).ToObject(rt))
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("encountered an error while interpreting Parser options; reason: %w", err))
+		}
+	}
+
+	metadata, err := readFileMetadata(file.Impl, file.Impl.Stat().Size)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("failed to read the Parquet file's metadata; reason: %w", err))
+	}
+
+	parser, err := newParser(file.Impl, metadata, options)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	parser.vu = mi.vu
+
+	obj := rt.ToValue(parser).ToObject(rt)
+	common.MakeAsyncIterable(rt, obj, parser.Next)
+
+	return obj
+}
+
+// newParser creates a new Parquet parser reading the provided file, described
+// by the given metadata.
+func newParser(r io.ReadSeeker, metadata fileMetadata, options parserOptions) (*Parser, error) {
+	columns, err := columnsFrom(metadata.schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if columns, err = project(columns, options.Columns); err != nil {
+		return nil, fmt.Errorf("invalid columns option; reason: %w", err)
+	}
+
+	rowGroups := options.RowGroups
+	if rowGroups == nil {
+		rowGroups = make([]int, len(metadata.rowGroups))
+		for i := range rowGroups {
+			rowGroups[i] = i
+		}
+	}
+
+	for _, index := range rowGroups {
+		if index < 0 || index >= len(metadata.rowGroups) {
+			return nil, fmt.Errorf("invalid rowGroups option; reason: the file has %d row groups, got index %d", len(metadata.rowGroups), index)
+		}
+	}
+
+	return &Parser{
+		reader:    r,
+		metadata:  metadata,
+		columns:   columns,
+		rowGroups: rowGroups,
+	}, nil
+}
+
+// readFileMetadata reads the metadata held by the footer of the provided
+// Parquet file, of the given size.
+//
+// The footer is made of the metadata, followed by its 4-byte little-endian
+// length, and the file's magic bytes.
+func readFileMetadata(r io.ReadSeeker, size int64) (fileMetadata, error) {
+	footerSize := int64(len(magic) + 4)
+	if size < int64(len(magic))+footerSize {
+		return fileMetadata{}, errors.New("the file is too small to be a Parquet file")
+	}
+
+	footer := make([]byte, footerSize)
+	if _, err := r.Seek(size-footerSize, io.SeekStart); err != nil {
+		return fileMetadata{}, err
+	}
+
+	if _, err := io.ReadFull(r, footer); err != nil {
+		return fileMetadata{}, err
+	}
+
+	if !bytes.Equal(footer[4:], magic) {
+		return fileMetadata{}, errors.New("the file does not end with Parquet's magic bytes")
+	}
+
+	length := int64(binary.LittleEndian.Uint32(footer))
+	if length > size-int64(len(magic))-footerSize {
+		return fileMetadata{}, fmt.Errorf("the file's metadata length of %d bytes exceeds the file's size", length)
+	}
+
+	data := make([]byte, length)
+	if _, err := r.Seek(size-footerSize-length, io.SeekStart); err != nil {
+		return fileMetadata{}, err
+	}
+
+	if _, err := io.ReadFull(r, data); err != nil {
+		return fileMetadata{}, err
+	}
+
+	return decodeFileMetadata(data)
+}
+
+// Next returns a promise resolving to the next row of the Parquet file, as an
+// object holding the row's values of the columns selected through the
+// `columns` option, or of all the columns otherwise, in the same order.
+//
+// Rows are read one row group at a time, in a background goroutine: only the
+// selected columns of the row group being read are held in memory, and the
+// row groups left out by the `rowGroups` option are not read at all.
+//
+// Null values are returned as null, int96 timestamps as Date objects, and
+// byte arrays as strings, when annotated as holding text, or ArrayBuffer
+// objects otherwise.
+//
+// Once the last row has been read, the promise resolves to a result which
+// `done` property is set to true.
+func (p *Parser) Next() *sobek.Promise {
+	rt := p.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+	callback := p.vu.RegisterCallback()
+
+	go func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		row, err := p.next()
+
+		callback(func() error {
+			if errors.Is(err, io.EOF) {
+				resolve(parseResult{Done: true, Value: sobek.Undefined()})
+				return nil
+			}
+
+			if err != nil {
+				reject(err)
+				return nil
+			}
+
+			resolve(parseResult{Done: false, Value: p.toObject(rt, row)})
+			return nil
+		})
+	}()
+
+	return promise
+}
+
+// next returns the values of the next row, reading the next row group once
+// the one being read has been exhausted.
+func (p *Parser) next() ([]any, error) {
+	for p.values == nil || p.row >= len(p.values[0]) {
+		if p.group >= len(p.rowGroups) {
+			return nil, io.EOF
+		}
+
+		if err := p.readRowGroup(p.rowGroups[p.group]); err != nil {
+			return nil, err
+		}
+
+		p.group++
+	}
+
+	row := make([]any, len(p.columns))
+	for i := range p.columns {
+		row[i] = p.values[i][p.row]
+	}
+
+	p.row++
+
+	return row, nil
+}
+
+// readRowGroup reads the values of the selected columns of the row group the
+// provided index designates.
+func (p *Parser) readRowGroup(index int) error {
+	group := p.metadata.rowGroups[index]
+
+	values := make([][]any, len(p.columns))
+	for i, col := range p.columns {
+		if col.index >= len(group.columns) {
+			return fmt.Errorf("row group %d holds no chunk for column %q", index, col.element.name)
+		}
+
+		var err error
+		values[i], err = readColumnChunk(p.reader, col, group.columns[col.index], group.numRows)
+		if err != nil {
+			return fmt.Errorf("failed to read row group %d; reason: %w", index, err)
+		}
+	}
+
+	// Row groups without any selected column still hold rows.
+	if len(p.columns) == 0 {
+		values = [][]any{make([]any, group.numRows)}
+	}
+
+	p.values = values
+	p.row = 0
+
+	return nil
+}
+
+// toObject converts the provided row's values to an object, holding them by
+// column name.
+//
+// It must be called from the event loop.
+func (p *Parser) toObject(rt *sobek.Runtime, row []any) *sobek.Object {
+	obj := rt.NewObject()
+
+	for i, col := range p.columns {
+		var value sobek.Value
+
+		switch v := row[i].(type) {
+		case nil:
+			value = sobek.Null()
+		case []byte:
+			value = rt.ToValue(rt.NewArrayBuffer(v))
+		case time.Time:
+			date, err := rt.New(rt.Get("Date"), rt.ToValue(v.UnixMilli()))
+			if err != nil {
+				common.Throw(rt, err)
+			}
+
+			value = date
+		default:
+			value = rt.ToValue(v)
+		}
+
+		if err := obj.Set(col.element.name, value); err != nil {
+			common.Throw(rt, err)
+		}
+	}
+
+	return obj
+}
+
+// parserOptions holds options used to configure Parquet parsing when
+// utilizing the module.
+//
+// The options can be set by the user when instantiating a new [Parser].
+type parserOptions struct {
+	// Columns holds the names of the columns to read, in the order the rows
+	// hold them in. All the columns are read if it is not set.
+	Columns []string `js:"columns"`
+
+	// RowGroups holds the indices of the row groups to read, in the order to
+	// read them in. All the row groups are read if it is not set, while the
+	// ones it leaves out are skipped without being read.
+	RowGroups []int `js:"rowGroups"`
+}
+
+// newDefaultParserOptions creates a new parserOptions instance with default values.
+func newDefaultParserOptions() parserOptions {
+	return parserOptions{}
+}
+
+// newParserOptionsFrom creates a new parserOptions instance from the given
+// Sobek object.
+func newParserOptionsFrom(rt *sobek.Runtime, obj *sobek.Object) (parserOptions, error) {
+	options := newDefaultParserOptions()
+
+	if obj == nil {
+		return options, nil
+	}
+
+	if v := obj.Get("columns"); v != nil && !common.IsNullish(v) {
+		if err := rt.ExportTo(v, &options.Columns); err != nil {
+			return options, fmt.Errorf("columns must be an array of column names; reason: %w", err)
+		}
+	}
+
+	if v := obj.Get("rowGroups"); v != nil && !common.IsNullish(v) {
+		if err := rt.ExportTo(v, &options.RowGroups); err != nil {
+			return options, fmt.Errorf("rowGroups must be an array of row group indices; reason: %w", err)
+		}
+
+		if options.RowGroups == nil {
+			options.RowGroups = []int{}
+		}
+	}
+
+	return options, nil
+}
@@ -0,0 +1,829 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/compiler"
+	"go.k6.io/k6/js/modules/k6/experimental/fs"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib/fsext"
+)
+
+// testFilePath holds the path to the Parquet file used in the tests.
+const testFilePath = fsext.FilePathSeparator + "data.parquet"
+
+// testColumns holds the columns of the Parquet file used in most tests.
+var testColumns = []testColumn{
+	{name: "id", typ: typeInt64, values: []any{int64(1), int64(2), int64(3), int64(4), int64(5)}},
+	{name: "name", typ: typeByteArray, utf8: true, values: []any{"foo", "bar", "baz", "qux", "quux"}},
+	{name: "score", typ: typeDouble, values: []any{1.5, 2.5, 3.5, 4.5, 5.5}},
+}
+
+func TestParserConstructor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("constructing a parser without options should succeed", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, encodeTestFile(t, testColumns, testFileOptions{})))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new parquet.Parser(file);
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("constructing a parser without a file should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`new parquet.Parser()`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parquet Parser constructor takes at least one non-nil source argument")
+	})
+
+	t.Run("constructing a parser from something other than a file should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`new parquet.Parser(42)`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "first argument expected to be a fs.File instance")
+	})
+
+	t.Run("constructing a parser from a file other than a Parquet one should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, []byte("id,name\n1,foo\n2,bar\n")))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new parquet.Parser(file);
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the file does not end with Parquet's magic bytes")
+	})
+
+	t.Run("constructing a parser selecting an unknown column should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, encodeTestFile(t, testColumns, testFileOptions{})))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new parquet.Parser(file, { columns: ["id", "age"] });
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `the file holds no column named "age"`)
+	})
+
+	t.Run("constructing a parser selecting an unknown row group should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, encodeTestFile(t, testColumns, testFileOptions{rowGroupSize: 2})))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new parquet.Parser(file, { rowGroups: [3] });
+		`, testFilePath)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the file has 3 row groups, got index 3")
+	})
+}
+
+func TestParserNext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		columns []testColumn
+		file    testFileOptions
+		options string
+		want    string
+	}{
+		{
+			name:    "next should return each row",
+			columns: testColumns,
+			options: `{}`,
+			want: `{"id":1,"name":"foo","score":1.5}|{"id":2,"name":"bar","score":2.5}|{"id":3,"name":"baz","score":3.5}|` +
+				`{"id":4,"name":"qux","score":4.5}|{"id":5,"name":"quux","score":5.5}`,
+		},
+		{
+			name:    "rows spread over several row groups should be returned in order",
+			columns: testColumns,
+			file:    testFileOptions{rowGroupSize: 2},
+			options: `{}`,
+			want: `{"id":1,"name":"foo","score":1.5}|{"id":2,"name":"bar","score":2.5}|{"id":3,"name":"baz","score":3.5}|` +
+				`{"id":4,"name":"qux","score":4.5}|{"id":5,"name":"quux","score":5.5}`,
+		},
+		{
+			name:    "the columns option should select the columns returned, in its order",
+			columns: testColumns,
+			file:    testFileOptions{rowGroupSize: 2},
+			options: `{ columns: ["score", "id"] }`,
+			want:    `{"score":1.5,"id":1}|{"score":2.5,"id":2}|{"score":3.5,"id":3}|{"score":4.5,"id":4}|{"score":5.5,"id":5}`,
+		},
+		{
+			name:    "the rowGroups option should select the row groups read, in its order",
+			columns: testColumns,
+			file:    testFileOptions{rowGroupSize: 2},
+			options: `{ rowGroups: [2, 0], columns: ["id"] }`,
+			want:    `{"id":5}|{"id":1}|{"id":2}`,
+		},
+		{
+			name:    "an empty rowGroups option should read no row",
+			columns: testColumns,
+			file:    testFileOptions{rowGroupSize: 2},
+			options: `{ rowGroups: [] }`,
+			want:    ``,
+		},
+		{
+			name: "null values of optional columns should be returned as null",
+			columns: []testColumn{
+				{name: "id", typ: typeInt32, values: []any{int32(1), int32(2), int32(3), int32(4)}},
+				{name: "tag", typ: typeByteArray, utf8: true, optional: true, values: []any{"a", nil, nil, "d"}},
+			},
+			options: `{}`,
+			want:    `{"id":1,"tag":"a"}|{"id":2,"tag":null}|{"id":3,"tag":null}|{"id":4,"tag":"d"}`,
+		},
+		{
+			name: "booleans and floats should be supported",
+			columns: []testColumn{
+				{name: "ok", typ: typeBoolean, values: []any{true, false, true}},
+				{name: "ratio", typ: typeFloat, values: []any{float32(0.5), float32(0.25), float32(-1)}},
+			},
+			options: `{}`,
+			want:    `{"ok":true,"ratio":0.5}|{"ok":false,"ratio":0.25}|{"ok":true,"ratio":-1}`,
+		},
+		{
+			name: "int96 timestamps should be returned as dates",
+			columns: []testColumn{
+				{name: "at", typ: typeInt96, values: []any{time.Date(2024, 5, 17, 12, 30, 0, 0, time.UTC)}},
+			},
+			options: `{}`,
+			want:    `{"at":"2024-05-17T12:30:00.000Z"}`,
+		},
+		{
+			name:    "dictionary encoded columns should be supported",
+			columns: testColumns,
+			file:    testFileOptions{rowGroupSize: 3, dictionary: true},
+			options: `{ columns: ["name"] }`,
+			want:    `{"name":"foo"}|{"name":"bar"}|{"name":"baz"}|{"name":"qux"}|{"name":"quux"}`,
+		},
+		{
+			name:    "version 2 data pages should be supported",
+			columns: testColumns,
+			file:    testFileOptions{pageV2: true, codec: codecSnappy},
+			options: `{ columns: ["id", "name"] }`,
+			want:    `{"id":1,"name":"foo"}|{"id":2,"name":"bar"}|{"id":3,"name":"baz"}|{"id":4,"name":"qux"}|{"id":5,"name":"quux"}`,
+		},
+		{
+			name:    "snappy compressed pages should be supported",
+			columns: testColumns,
+			file:    testFileOptions{codec: codecSnappy, dictionary: true},
+			options: `{ columns: ["name"] }`,
+			want:    `{"name":"foo"}|{"name":"bar"}|{"name":"baz"}|{"name":"qux"}|{"name":"quux"}`,
+		},
+		{
+			name:    "gzip compressed pages should be supported",
+			columns: testColumns,
+			file:    testFileOptions{codec: codecGzip},
+			options: `{ columns: ["name"] }`,
+			want:    `{"name":"foo"}|{"name":"bar"}|{"name":"baz"}|{"name":"qux"}|{"name":"quux"}`,
+		},
+		{
+			name:    "zstd compressed pages should be supported",
+			columns: testColumns,
+			file:    testFileOptions{codec: codecZstd},
+			options: `{ columns: ["name"] }`,
+			want:    `{"name":"foo"}|{"name":"bar"}|{"name":"baz"}|{"name":"qux"}|{"name":"quux"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, encodeTestFile(t, tt.columns, tt.file)))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new parquet.Parser(file, %s);
+
+				let got = [];
+				let { done, value } = await parser.next();
+				while (!done) {
+					got.push(JSON.stringify(value));
+					({ done, value } = await parser.next());
+				}
+
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected rows " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, tt.want)))
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("byte arrays not annotated as text should be returned as array buffers", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		columns := []testColumn{
+			{name: "blob", typ: typeByteArray, values: []any{[]byte{1, 2, 3}}},
+			{name: "hash", typ: typeFixedLenByteArray, typeLength: 2, values: []any{[]byte{4, 5}}},
+		}
+		require.NoError(t, writeTestFile(r, testFilePath, encodeTestFile(t, columns, testFileOptions{})))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new parquet.Parser(file);
+
+			const { value } = await parser.next();
+			if (!(value.blob instanceof ArrayBuffer) || new Uint8Array(value.blob).join(",") !== "1,2,3") {
+				throw new Error("Unexpected blob " + value.blob);
+			}
+
+			if (!(value.hash instanceof ArrayBuffer) || new Uint8Array(value.hash).join(",") !== "4,5") {
+				throw new Error("Unexpected hash " + value.hash);
+			}
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParserRowGroupSkipping(t *testing.T) {
+	t.Parallel()
+
+	data := encodeTestFile(t, testColumns, testFileOptions{rowGroupSize: 2})
+
+	metadata, err := readFileMetadata(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, metadata.rowGroups, 3)
+
+	// Corrupt the chunks of the second row group, so that reading it fails.
+	for _, chunk := range metadata.rowGroups[1].columns {
+		for i := chunk.start(); i < chunk.start()+chunk.totalCompressedSize; i++ {
+			data[i] = 0xff
+		}
+	}
+
+	parser, err := newParser(bytes.NewReader(data), metadata, parserOptions{RowGroups: []int{0, 2}})
+	require.NoError(t, err)
+
+	var ids []any
+	for {
+		row, err := parser.next()
+		if err != nil {
+			require.ErrorIs(t, err, io.EOF)
+			break
+		}
+
+		ids = append(ids, row[0])
+	}
+
+	assert.Equal(t, []any{int64(1), int64(2), int64(5)}, ids)
+
+	parser, err = newParser(bytes.NewReader(data), metadata, parserOptions{RowGroups: []int{1}})
+	require.NoError(t, err)
+
+	_, err = parser.next()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read row group 1")
+}
+
+func TestParserAsyncIterator(t *testing.T) {
+	t.Parallel()
+
+	// The runtime does not support the `Symbol.asyncIterator` well-known symbol,
+	// nor `for await...of` loops, so we define the former and emulate the latter.
+	const polyfill = `Symbol.asyncIterator = Symbol("Symbol.asyncIterator");`
+
+	r, err := newConfiguredRuntime(t)
+	require.NoError(t, err)
+
+	require.NoError(t, writeTestFile(r, testFilePath, encodeTestFile(t, testColumns, testFileOptions{rowGroupSize: 2})))
+
+	_, err = r.RunOnEventLoop(polyfill + wrapInAsyncLambda(fmt.Sprintf(`
+		const file = await fs.open(%q);
+		const parser = new parquet.Parser(file, { columns: ["name"] });
+
+		const iterator = parser[Symbol.asyncIterator]();
+
+		let got = [];
+		for (let result = await iterator.next(); !result.done; result = await iterator.next()) {
+			got.push(result.value.name);
+		}
+
+		if (got.join("|") !== "foo|bar|baz|qux|quux") {
+			throw new Error("Unexpected rows " + JSON.stringify(got));
+		}
+	`, testFilePath)))
+
+	assert.NoError(t, err)
+}
+
+const initGlobals = `
+	globalThis.fs = require("k6/experimental/fs");
+	globalThis.parquet = require("k6/experimental/parquet");
+`
+
+func newConfiguredRuntime(t testing.TB) (*modulestest.Runtime, error) {
+	runtime := modulestest.NewRuntime(t)
+
+	modules := map[string]interface{}{
+		"k6/experimental/fs":      fs.New(),
+		"k6/experimental/parquet": New(),
+	}
+
+	err := runtime.SetupModuleSystem(modules, nil, compiler.New(runtime.VU.InitEnv().Logger))
+	if err != nil {
+		return nil, err
+	}
+
+	// Set up the VU environment with an in-memory filesystem and a CWD of "/".
+	runtime.VU.InitEnvField.FileSystems = map[string]fsext.Fs{
+		"file": fsext.NewMemMapFs(),
+	}
+	runtime.VU.InitEnvField.CWD = &url.URL{Scheme: "file"}
+
+	// Ensure the `fs` and `parquet` modules are available in the VU's runtime.
+	_, err = runtime.VU.Runtime().RunString(initGlobals)
+
+	return runtime, err
+}
+
+// writeTestFile writes the provided content to the given path in the runtime's
+// in-memory filesystem.
+func writeTestFile(r *modulestest.Runtime, path string, content []byte) error {
+	return fsext.WriteFile(r.VU.InitEnvField.FileSystems["file"], path, content, 0o644)
+}
+
+// wrapInAsyncLambda is a helper function that wraps the provided input in an async lambda. This
+// makes the use of `await` statements in the input possible.
+func wrapInAsyncLambda(input string) string {
+	// This makes it possible to use `await` freely on the "top" level
+	return "(async () => {\n " + input + "\n })()"
+}
+
+// testColumn describes a column of a Parquet file written by [encodeTestFile].
+type testColumn struct {
+	name       string
+	typ        int32
+	typeLength int32
+	optional   bool
+
+	// utf8 indicates whether the column is annotated as holding text.
+	utf8 bool
+
+	// values holds the column's values, with nil standing for null.
+	values []any
+}
+
+// testFileOptions holds the options of the Parquet files written by
+// [encodeTestFile].
+type testFileOptions struct {
+	// rowGroupSize holds the number of rows of each row group, all the rows
+	// are written in a single row group if it is not set.
+	rowGroupSize int
+
+	codec      int32
+	dictionary bool
+	pageV2     bool
+}
+
+// encodeTestFile encodes the provided columns as a Parquet file, which each
+// column chunk holds a single data page.
+func encodeTestFile(t testing.TB, columns []testColumn, options testFileOptions) []byte {
+	t.Helper()
+
+	numRows := len(columns[0].values)
+	groupSize := options.rowGroupSize
+	if groupSize == 0 {
+		groupSize = numRows
+	}
+
+	file := bytes.NewBuffer(append([]byte(nil), magic...))
+	md := &thriftEncoder{}
+
+	md.beginStruct()
+	md.fieldI32(1, 1) // version
+
+	md.fieldList(2, thriftStruct, len(columns)+1)
+	md.beginStruct()
+	md.fieldBinary(4, []byte("schema"))
+	md.fieldI32(5, int32(len(columns)))
+	md.endStruct()
+
+	for _, c := range columns {
+		md.beginStruct()
+		md.fieldI32(1, c.typ)
+		if c.typeLength > 0 {
+			md.fieldI32(2, c.typeLength)
+		}
+
+		repetition := int32(repetitionRequired)
+		if c.optional {
+			repetition = repetitionOptional
+		}
+
+		md.fieldI32(3, repetition)
+		md.fieldBinary(4, []byte(c.name))
+		if c.utf8 {
+			md.fieldI32(6, convertedUTF8)
+		}
+
+		md.endStruct()
+	}
+
+	md.fieldI64(3, int64(numRows))
+
+	groups := (numRows + groupSize - 1) / groupSize
+	md.fieldList(4, thriftStruct, groups)
+
+	for start := 0; start < numRows; start += groupSize {
+		end := start + groupSize
+		if end > numRows {
+			end = numRows
+		}
+
+		md.beginStruct()
+		md.fieldList(1, thriftStruct, len(columns))
+
+		for _, c := range columns {
+			offset := int64(file.Len())
+			dictionaryOffset, dataOffset := encodeTestChunk(t, file, c, c.values[start:end], options)
+
+			md.beginStruct()
+			md.fieldI64(2, offset) // file_offset
+			md.fieldStruct(3)
+			md.fieldI32(1, c.typ)
+			md.fieldList(2, thriftI32, 1)
+			md.i32(encodingPlain)
+			md.fieldList(3, thriftBinary, 1)
+			md.binary([]byte(c.name))
+			md.fieldI32(4, options.codec)
+			md.fieldI64(5, int64(end-start))
+			md.fieldI64(6, int64(file.Len())-offset)
+			md.fieldI64(7, int64(file.Len())-offset)
+			md.fieldI64(9, dataOffset)
+			if dictionaryOffset >= 0 {
+				md.fieldI64(11, dictionaryOffset)
+			}
+
+			md.endStruct()
+			md.endStruct()
+		}
+
+		md.fieldI64(2, 0) // total_byte_size
+		md.fieldI64(3, int64(end-start))
+		md.endStruct()
+	}
+
+	md.endStruct()
+
+	file.Write(md.buf.Bytes())
+	require.NoError(t, binary.Write(file, binary.LittleEndian, uint32(md.buf.Len())))
+	file.Write(magic)
+
+	return file.Bytes()
+}
+
+// encodeTestChunk writes the chunk holding the provided values of the given
+// column, and returns the offsets of its dictionary page, or -1 if it holds
+// none, and of its data page.
+func encodeTestChunk(
+	t testing.TB, file *bytes.Buffer, c testColumn, values []any, options testFileOptions,
+) (int64, int64) {
+	t.Helper()
+
+	var present []any
+	var levels []int
+	for _, v := range values {
+		if v == nil {
+			levels = append(levels, 0)
+			continue
+		}
+
+		levels = append(levels, 1)
+		present = append(present, v)
+	}
+
+	dictionaryOffset := int64(-1)
+	encoding := int32(encodingPlain)
+
+	var data []byte
+	if options.dictionary {
+		var dictionary []any
+		var indices []int
+
+		for _, v := range present {
+			index := -1
+			for i, d := range dictionary {
+				if fmt.Sprint(d) == fmt.Sprint(v) {
+					index = i
+				}
+			}
+
+			if index < 0 {
+				index = len(dictionary)
+				dictionary = append(dictionary, v)
+			}
+
+			indices = append(indices, index)
+		}
+
+		dictionaryOffset = int64(file.Len())
+		encodeTestPage(t, file, pageDictionary, len(dictionary), encodingPlain, nil, encodeTestPlain(c, dictionary), options)
+
+		// Indices are written as runs of a single value.
+		bitWidth := 1
+		for 1<<bitWidth < len(dictionary) {
+			bitWidth++
+		}
+
+		data = []byte{byte(bitWidth)}
+		for _, index := range indices {
+			data = binary.AppendUvarint(data, 1<<1)
+			for i := 0; i < (bitWidth+7)/8; i++ {
+				data = append(data, byte(index>>(8*i)))
+			}
+		}
+
+		encoding = encodingRLEDictionary
+	} else {
+		data = encodeTestPlain(c, present)
+	}
+
+	// Definition levels are written as bit-packed groups of 8 levels.
+	var definitions []byte
+	if c.optional {
+		groups := (len(levels) + 7) / 8
+		definitions = binary.AppendUvarint(nil, uint64(groups<<1|1))
+		packed := make([]byte, groups)
+		for i, level := range levels {
+			packed[i/8] |= byte(level << (i % 8))
+		}
+
+		definitions = append(definitions, packed...)
+	}
+
+	dataOffset := int64(file.Len())
+	encodeTestPage(t, file, pageData, len(values), encoding, definitions, data, options)
+
+	return dictionaryOffset, dataOffset
+}
+
+// encodeTestPage writes a page, holding the provided definition levels and
+// values.
+func encodeTestPage(
+	t testing.TB, file *bytes.Buffer, typ int32, numValues int, encoding int32,
+	definitions, values []byte, options testFileOptions,
+) {
+	t.Helper()
+
+	v2 := typ == pageData && options.pageV2
+	if v2 {
+		typ = pageDataV2
+	}
+
+	var uncompressed, compressed []byte
+	switch {
+	case v2:
+		uncompressed = append(append([]byte(nil), definitions...), values...)
+		compressed = append(append([]byte(nil), definitions...), compressTestData(t, options.codec, values)...)
+	case typ == pageData && definitions != nil:
+		uncompressed = binary.LittleEndian.AppendUint32(nil, uint32(len(definitions)))
+		uncompressed = append(append(uncompressed, definitions...), values...)
+		compressed = compressTestData(t, options.codec, uncompressed)
+	default:
+		uncompressed = values
+		compressed = compressTestData(t, options.codec, uncompressed)
+	}
+
+	header := &thriftEncoder{}
+	header.beginStruct()
+	header.fieldI32(1, typ)
+	header.fieldI32(2, int32(len(uncompressed)))
+	header.fieldI32(3, int32(len(compressed)))
+
+	switch typ {
+	case pageData:
+		header.fieldStruct(5)
+		header.fieldI32(1, int32(numValues))
+		header.fieldI32(2, encoding)
+		header.fieldI32(3, encodingRLE)
+		header.fieldI32(4, encodingRLE)
+		header.endStruct()
+	case pageDictionary:
+		header.fieldStruct(7)
+		header.fieldI32(1, int32(numValues))
+		header.fieldI32(2, encoding)
+		header.endStruct()
+	case pageDataV2:
+		header.fieldStruct(8)
+		header.fieldI32(1, int32(numValues))
+		header.fieldI32(2, 0) // num_nulls
+		header.fieldI32(3, int32(numValues))
+		header.fieldI32(4, encoding)
+		header.fieldI32(5, int32(len(definitions)))
+		header.fieldI32(6, 0)
+		header.fieldBool(7, true)
+		header.endStruct()
+	}
+
+	header.endStruct()
+
+	file.Write(header.buf.Bytes())
+	file.Write(compressed)
+}
+
+// encodeTestPlain encodes the provided values of the given column through the
+// plain encoding.
+func encodeTestPlain(c testColumn, values []any) []byte {
+	var data []byte
+
+	if c.typ == typeBoolean {
+		data = make([]byte, (len(values)+7)/8)
+		for i, v := range values {
+			if v.(bool) { //nolint:forcetypeassert
+				data[i/8] |= 1 << (i % 8)
+			}
+		}
+
+		return data
+	}
+
+	for _, v := range values {
+		switch v := v.(type) {
+		case int32:
+			data = binary.LittleEndian.AppendUint32(data, uint32(v))
+		case int64:
+			data = binary.LittleEndian.AppendUint64(data, uint64(v))
+		case float32:
+			data = binary.LittleEndian.AppendUint32(data, math.Float32bits(v))
+		case float64:
+			data = binary.LittleEndian.AppendUint64(data, math.Float64bits(v))
+		case time.Time:
+			day := v.Unix()/(24*60*60) + julianUnixEpoch
+			nanos := v.Sub(time.Unix((day-julianUnixEpoch)*24*60*60, 0))
+			data = binary.LittleEndian.AppendUint64(data, uint64(nanos))
+			data = binary.LittleEndian.AppendUint32(data, uint32(day))
+		case string:
+			data = binary.LittleEndian.AppendUint32(data, uint32(len(v)))
+			data = append(data, v...)
+		case []byte:
+			if c.typ == typeByteArray {
+				data = binary.LittleEndian.AppendUint32(data, uint32(len(v)))
+			}
+
+			data = append(data, v...)
+		}
+	}
+
+	return data
+}
+
+// compressTestData compresses the provided data with the given codec.
+func compressTestData(t testing.TB, codec int32, data []byte) []byte {
+	t.Helper()
+
+	switch codec {
+	case codecSnappy:
+		return snappy.Encode(nil, data)
+	case codecGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		return buf.Bytes()
+	case codecZstd:
+		w, err := zstd.NewWriter(nil)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, w.Close()) }()
+
+		return w.EncodeAll(data, nil)
+	default:
+		return data
+	}
+}
+
+// thriftEncoder encodes values through Thrift's compact protocol.
+type thriftEncoder struct {
+	buf bytes.Buffer
+
+	// last holds the id of the last field written to each of the structs
+	// being written.
+	last []int16
+}
+
+func (e *thriftEncoder) beginStruct() {
+	e.last = append(e.last, 0)
+}
+
+func (e *thriftEncoder) endStruct() {
+	e.buf.WriteByte(0)
+	e.last = e.last[:len(e.last)-1]
+}
+
+func (e *thriftEncoder) fieldHeader(id int16, typ byte) {
+	last := &e.last[len(e.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		e.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		e.buf.WriteByte(typ)
+		e.buf.Write(binary.AppendVarint(nil, int64(id)))
+	}
+
+	*last = id
+}
+
+func (e *thriftEncoder) fieldBool(id int16, v bool) {
+	if v {
+		e.fieldHeader(id, thriftBooleanTrue)
+	} else {
+		e.fieldHeader(id, thriftBooleanFalse)
+	}
+}
+
+func (e *thriftEncoder) fieldI32(id int16, v int32) {
+	e.fieldHeader(id, thriftI32)
+	e.i32(v)
+}
+
+func (e *thriftEncoder) fieldI64(id int16, v int64) {
+	e.fieldHeader(id, thriftI64)
+	e.buf.Write(binary.AppendVarint(nil, v))
+}
+
+func (e *thriftEncoder) fieldBinary(id int16, v []byte) {
+	e.fieldHeader(id, thriftBinary)
+	e.binary(v)
+}
+
+func (e *thriftEncoder) fieldList(id int16, elem byte, size int) {
+	e.fieldHeader(id, thriftList)
+	if size < 15 {
+		e.buf.WriteByte(byte(size)<<4 | elem)
+		return
+	}
+
+	e.buf.WriteByte(0xf0 | elem)
+	e.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+}
+
+func (e *thriftEncoder) fieldStruct(id int16) {
+	e.fieldHeader(id, thriftStruct)
+	e.beginStruct()
+}
+
+func (e *thriftEncoder) i32(v int32) {
+	e.buf.Write(binary.AppendVarint(nil, int64(v)))
+}
+
+func (e *thriftEncoder) binary(v []byte) {
+	e.buf.Write(binary.AppendUvarint(nil, uint64(len(v))))
+	e.buf.Write(v)
+}
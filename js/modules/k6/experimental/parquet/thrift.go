@@ -0,0 +1,284 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The types of the values encoded through Thrift's compact protocol, which
+// Parquet encodes its metadata with.
+const (
+	thriftBooleanTrue  = 1
+	thriftBooleanFalse = 2
+	thriftByte         = 3
+	thriftI16          = 4
+	thriftI32          = 5
+	thriftI64          = 6
+	thriftDouble       = 7
+	thriftBinary       = 8
+	thriftList         = 9
+	thriftSet          = 10
+	thriftMap          = 11
+	thriftStruct       = 12
+)
+
+// errThriftTruncated is returned when the encoded data ends unexpectedly.
+var errThriftTruncated = errors.New("truncated thrift data")
+
+// thriftDecoder decodes values encoded through Thrift's compact protocol.
+//
+// Structs are decoded field by field, through [thriftDecoder.readStruct],
+// which hands each field over to a callback decoding the fields it knows
+// of, and skipping the other ones.
+type thriftDecoder struct {
+	data []byte
+	pos  int
+
+	// fieldBool holds the value of the boolean field being read, if any, as
+	// booleans fields are held by their header.
+	fieldBool *bool
+}
+
+// newThriftDecoder creates a new thriftDecoder reading from the provided data.
+func newThriftDecoder(data []byte) *thriftDecoder {
+	return &thriftDecoder{data: data}
+}
+
+// readStruct reads a struct, calling field for each of its fields, with the
+// field's id and type. The callback is expected to consume the field's value,
+// or to skip it through [thriftDecoder.skip].
+func (d *thriftDecoder) readStruct(field func(id int16, typ byte) error) error {
+	var last int16
+
+	for {
+		header, err := d.readByte()
+		if err != nil {
+			return err
+		}
+
+		if header == 0 {
+			return nil
+		}
+
+		typ := header & 0x0f
+
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			v, err := d.readVarint()
+			if err != nil {
+				return err
+			}
+
+			id = int16(v)
+		}
+
+		last = id
+
+		d.fieldBool = nil
+		if typ == thriftBooleanTrue || typ == thriftBooleanFalse {
+			v := typ == thriftBooleanTrue
+			d.fieldBool = &v
+		}
+
+		err = field(id, typ)
+		d.fieldBool = nil
+
+		if err != nil {
+			return fmt.Errorf("field %d: %w", id, err)
+		}
+	}
+}
+
+// readList reads a list's header, and calls elem for each of its elements,
+// with the elements' type.
+func (d *thriftDecoder) readList(elem func(typ byte) error) error {
+	header, err := d.readByte()
+	if err != nil {
+		return err
+	}
+
+	typ := header & 0x0f
+
+	size := int(header >> 4)
+	if size == 15 {
+		v, err := d.readUvarint()
+		if err != nil {
+			return err
+		}
+
+		if v > uint64(len(d.data)) {
+			return errThriftTruncated
+		}
+
+		size = int(v)
+	}
+
+	for i := 0; i < size; i++ {
+		if err := elem(typ); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readBool reads a boolean, either held by the header of the field being
+// read, or by a list element.
+func (d *thriftDecoder) readBool() (bool, error) {
+	if d.fieldBool != nil {
+		v := *d.fieldBool
+		d.fieldBool = nil
+		return v, nil
+	}
+
+	b, err := d.readByte()
+	if err != nil {
+		return false, err
+	}
+
+	return b == thriftBooleanTrue, nil
+}
+
+// readI32 reads a 32-bit integer.
+func (d *thriftDecoder) readI32() (int32, error) {
+	v, err := d.readVarint()
+	if err != nil {
+		return 0, err
+	}
+
+	if v < math.MinInt32 || v > math.MaxInt32 {
+		return 0, fmt.Errorf("value %d overflows a 32-bit integer", v)
+	}
+
+	return int32(v), nil
+}
+
+// readI64 reads a 64-bit integer.
+func (d *thriftDecoder) readI64() (int64, error) {
+	return d.readVarint()
+}
+
+// readBinary reads a binary value, or a string.
+func (d *thriftDecoder) readBinary() ([]byte, error) {
+	n, err := d.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errThriftTruncated
+	}
+
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+
+	return b, nil
+}
+
+// readString reads a string.
+func (d *thriftDecoder) readString() (string, error) {
+	b, err := d.readBinary()
+	return string(b), err
+}
+
+// skip skips a value of the provided type.
+func (d *thriftDecoder) skip(typ byte) error {
+	switch typ {
+	case thriftBooleanTrue, thriftBooleanFalse:
+		_, err := d.readBool()
+		return err
+	case thriftByte:
+		_, err := d.readByte()
+		return err
+	case thriftI16, thriftI32, thriftI64:
+		_, err := d.readVarint()
+		return err
+	case thriftDouble:
+		if len(d.data)-d.pos < 8 {
+			return errThriftTruncated
+		}
+
+		d.pos += 8
+		return nil
+	case thriftBinary:
+		_, err := d.readBinary()
+		return err
+	case thriftList, thriftSet:
+		return d.readList(d.skip)
+	case thriftMap:
+		return d.skipMap()
+	case thriftStruct:
+		return d.readStruct(func(_ int16, typ byte) error {
+			return d.skip(typ)
+		})
+	default:
+		return fmt.Errorf("unknown thrift type %d", typ)
+	}
+}
+
+// skipMap skips a map.
+func (d *thriftDecoder) skipMap() error {
+	size, err := d.readUvarint()
+	if err != nil {
+		return err
+	}
+
+	if size == 0 {
+		return nil
+	}
+
+	types, err := d.readByte()
+	if err != nil {
+		return err
+	}
+
+	for i := uint64(0); i < size; i++ {
+		if err := d.skip(types >> 4); err != nil {
+			return err
+		}
+
+		if err := d.skip(types & 0x0f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readByte reads a single byte.
+func (d *thriftDecoder) readByte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, errThriftTruncated
+	}
+
+	b := d.data[d.pos]
+	d.pos++
+
+	return b, nil
+}
+
+// readUvarint reads an unsigned variable-length integer.
+func (d *thriftDecoder) readUvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, errThriftTruncated
+	}
+
+	d.pos += n
+
+	return v, nil
+}
+
+// readVarint reads a zigzag encoded variable-length integer.
+func (d *thriftDecoder) readVarint() (int64, error) {
+	v, n := binary.Varint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, errThriftTruncated
+	}
+
+	d.pos += n
+
+	return v, nil
+}
@@ -0,0 +1,305 @@
+// Package ratelimit provides a k6 module for token-bucket rate limiters which
+// are shared by all VUs of a k6 instance, so the scripts can respect the
+// quotas of third-party APIs, whatever the executors of the scenarios are.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/grafana/sobek"
+	"golang.org/x/time/rate"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+)
+
+// The scopes of the token buckets of a limiter.
+const (
+	scopeTest     = "test"
+	scopeScenario = "scenario"
+)
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU. It holds the limiters and the token buckets of all VUs.
+	RootModule struct {
+		mu       sync.Mutex
+		limiters map[string]limiterConfig
+		buckets  map[bucketID]*rate.Limiter
+	}
+
+	// ModuleInstance represents an instance of the ratelimit module for a single VU.
+	ModuleInstance struct {
+		vu   modules.VU
+		root *RootModule
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{
+		limiters: make(map[string]limiterConfig),
+		buckets:  make(map[bucketID]*rate.Limiter),
+	}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu, root: rm}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"Limiter": mi.newLimiter,
+		},
+	}
+}
+
+// limiterConfig is the configuration of a limiter, which all the limiters of
+// the same name, in all VUs, need to share.
+type limiterConfig struct {
+	rate   float64
+	period time.Duration
+	burst  int
+	scope  string
+}
+
+// bucketID identifies a token bucket, of a limiter for the test run, or for a
+// scenario, and for an arbitrary key.
+type bucketID struct {
+	limiter, scenario, key string
+}
+
+// register registers the configuration of the named limiter, or checks that
+// it's the one the limiter was registered with by another VU.
+func (rm *RootModule) register(name string, config limiterConfig) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	registered, ok := rm.limiters[name]
+	if !ok {
+		rm.limiters[name] = config
+		return nil
+	}
+	if registered != config {
+		return fmt.Errorf("the %q limiter is already defined with a different configuration", name)
+	}
+	return nil
+}
+
+// bucket returns the token bucket of the given ID, which starts full.
+func (rm *RootModule) bucket(id bucketID, config limiterConfig) *rate.Limiter {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	b, ok := rm.buckets[id]
+	if !ok {
+		b = rate.NewLimiter(rate.Limit(config.rate/config.period.Seconds()), config.burst)
+		rm.buckets[id] = b
+	}
+	return b
+}
+
+// limiter is a token-bucket rate limiter, whose buckets are shared by all the
+// limiters of the same name.
+type limiter struct {
+	vu     modules.VU
+	root   *RootModule
+	name   string
+	config limiterConfig
+}
+
+// newLimiter is the JS constructor of the limiters. The options need a name
+// and a rate of tokens per period, one second by default, which the bucket
+// holds at most burst of, the rate rounded up by default. The scope is either
+// test, for a bucket per test run, or scenario, for a bucket per scenario.
+func (mi *ModuleInstance) newLimiter(call sobek.ConstructorCall) *sobek.Object {
+	rt := mi.vu.Runtime()
+
+	name, config, err := parseLimiterOptions(rt, call.Argument(0))
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid limiter options: %w", err))
+	}
+	if err = mi.root.register(name, config); err != nil {
+		common.Throw(rt, err)
+	}
+
+	l := &limiter{vu: mi.vu, root: mi.root, name: name, config: config}
+	obj := rt.NewObject()
+	for k, v := range map[string]any{
+		"name":       name,
+		"acquire":    l.acquire,
+		"tryAcquire": l.tryAcquire,
+	} {
+		if err := obj.Set(k, v); err != nil {
+			common.Throw(rt, err)
+		}
+	}
+	return obj
+}
+
+func parseLimiterOptions(rt *sobek.Runtime, options sobek.Value) (string, limiterConfig, error) {
+	config := limiterConfig{period: time.Second, scope: scopeTest}
+	if common.IsNullish(options) {
+		return "", config, errors.New("the name and the rate of the limiter are required")
+	}
+
+	var name string
+	obj := options.ToObject(rt)
+	for _, k := range obj.Keys() {
+		v := obj.Get(k)
+		switch k {
+		case "name":
+			name = v.String()
+		case "rate":
+			config.rate = v.ToFloat()
+		case "period":
+			d, err := types.GetDurationValue(v.Export())
+			if err != nil {
+				return "", config, fmt.Errorf("invalid period: %w", err)
+			}
+			config.period = d
+		case "burst":
+			config.burst = int(v.ToInteger())
+			if config.burst < 1 {
+				return "", config, fmt.Errorf("the burst should be at least 1, but it is %d", config.burst)
+			}
+		case "scope":
+			config.scope = v.String()
+		default:
+			return "", config, fmt.Errorf("unknown option %q", k)
+		}
+	}
+
+	switch {
+	case name == "":
+		return "", config, errors.New("the name is required")
+	case !(config.rate > 0) || math.IsInf(config.rate, 1):
+		return "", config, fmt.Errorf("the rate should be a positive number, but it is %v", config.rate)
+	case config.period <= 0:
+		return "", config, fmt.Errorf("the period should be positive, but it is %s", config.period)
+	case config.scope != scopeTest && config.scope != scopeScenario:
+		return "", config, fmt.Errorf("unsupported scope %q, it needs to be test or scenario", config.scope)
+	}
+	if config.burst == 0 {
+		config.burst = int(math.Ceil(config.rate))
+	}
+	return name, config, nil
+}
+
+// parseAcquireOptions returns the key of the bucket, and the number of tokens,
+// to acquire.
+func parseAcquireOptions(rt *sobek.Runtime, options sobek.Value) (string, int, error) {
+	key, tokens := "", 1
+	if common.IsNullish(options) {
+		return key, tokens, nil
+	}
+
+	obj := options.ToObject(rt)
+	for _, k := range obj.Keys() {
+		v := obj.Get(k)
+		switch k {
+		case "key":
+			key = v.String()
+		case "tokens":
+			tokens = int(v.ToInteger())
+			if tokens < 1 {
+				return "", 0, fmt.Errorf("the tokens should be at least 1, but they are %d", tokens)
+			}
+		default:
+			return "", 0, fmt.Errorf("unknown option %q", k)
+		}
+	}
+	return key, tokens, nil
+}
+
+// bucket returns the token bucket of the key, for the scenario the VU runs if
+// the scope of the limiter is scenario.
+func (l *limiter) bucket(key string) *rate.Limiter {
+	id := bucketID{limiter: l.name, key: key}
+	if l.config.scope == scopeScenario {
+		if ss := lib.GetScenarioState(l.vu.Context()); ss != nil {
+			id.scenario = ss.Name
+		}
+	}
+	return l.root.bucket(id, l.config)
+}
+
+// acquire returns a promise which resolves once the tokens are acquired from
+// the bucket, which the VUs wait for in the order they called it. The options
+// can hold the `key` of the bucket and the number of `tokens` to acquire.
+func (l *limiter) acquire(options sobek.Value) *sobek.Promise {
+	rt := l.vu.Runtime()
+	if l.vu.State() == nil {
+		common.Throw(rt, common.NewInitContextError("acquiring tokens in the init context is not supported"))
+	}
+	promise, resolve, reject := rt.NewPromise()
+
+	key, tokens, err := parseAcquireOptions(rt, options)
+	if err != nil {
+		reject(fmt.Errorf("acquire() failed; reason: %w", err))
+		return promise
+	}
+
+	reservation := l.bucket(key).ReserveN(time.Now(), tokens)
+	if !reservation.OK() {
+		reject(fmt.Errorf("acquire() failed; reason: %d tokens exceed the burst of the %q limiter, which is %d",
+			tokens, l.name, l.config.burst))
+		return promise
+	}
+	delay := reservation.Delay()
+	if delay == 0 {
+		resolve(sobek.Undefined())
+		return promise
+	}
+
+	callback := l.vu.RegisterCallback()
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			callback(func() error {
+				resolve(sobek.Undefined())
+				return nil
+			})
+		case <-l.vu.Context().Done():
+			// the tokens are given back to the other VUs
+			reservation.Cancel()
+			callback(func() error { return nil })
+		}
+	}()
+
+	return promise
+}
+
+// tryAcquire acquires the tokens if they are available right away, and
+// returns whether it did, with the same options as acquire.
+func (l *limiter) tryAcquire(options sobek.Value) bool {
+	rt := l.vu.Runtime()
+	if l.vu.State() == nil {
+		common.Throw(rt, common.NewInitContextError("acquiring tokens in the init context is not supported"))
+	}
+
+	key, tokens, err := parseAcquireOptions(rt, options)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("tryAcquire() failed; reason: %w", err))
+	}
+	return l.bucket(key).AllowN(time.Now(), tokens)
+}
@@ -0,0 +1,210 @@
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+)
+
+func newRateLimitRuntime(t *testing.T, root *RootModule, scenario string) *modulestest.Runtime {
+	t.Helper()
+
+	runtime := modulestest.NewRuntime(t)
+	err := runtime.SetupModuleSystem(map[string]any{"k6/experimental/ratelimit": root}, nil, nil)
+	require.NoError(t, err)
+	_, err = runtime.VU.Runtime().RunString(`var { Limiter } = require("k6/experimental/ratelimit");`)
+	require.NoError(t, err)
+	runtime.MoveToVUContext(&lib.State{})
+	runtime.VU.CtxField = lib.WithScenarioState(runtime.VU.CtxField, &lib.ScenarioState{Name: scenario})
+
+	return runtime
+}
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"burst": `
+			const limiter = new Limiter({ name: "api", rate: 2, period: "1h" });
+			if (limiter.name !== "api") throw new Error("bad name " + limiter.name);
+			await limiter.acquire();
+			if (!limiter.tryAcquire()) throw new Error("the burst wasn't available");
+			if (limiter.tryAcquire()) throw new Error("the burst was exceeded");
+		`,
+		"keys": `
+			const limiter = new Limiter({ name: "api", rate: 1, period: "1h" });
+			if (!limiter.tryAcquire({ key: "user-1" })) throw new Error("the first key was limited");
+			if (!limiter.tryAcquire({ key: "user-2" })) throw new Error("the second key was limited");
+			if (limiter.tryAcquire({ key: "user-1" })) throw new Error("the first key wasn't limited");
+		`,
+		"tokens": `
+			const limiter = new Limiter({ name: "api", rate: 1, period: "1h", burst: 3 });
+			if (!limiter.tryAcquire({ tokens: 3 })) throw new Error("the burst wasn't available");
+			if (limiter.tryAcquire()) throw new Error("the burst was exceeded");
+			const exceeds = (e) => e.toString().includes('4 tokens exceed the burst of the "api" limiter, which is 3');
+			if (!await limiter.acquire({ tokens: 4 }).then(() => false, exceeds)) throw new Error("acquire() should have failed");
+		`,
+		"wait": `
+			const limiter = new Limiter({ name: "api", rate: 20, burst: 1 });
+			const start = Date.now();
+			for (let i = 0; i < 3; i++) {
+				await limiter.acquire();
+			}
+			const elapsed = Date.now() - start;
+			if (elapsed < 90) throw new Error("the limiter didn't wait, it took " + elapsed + "ms");
+		`,
+		"same definition": `
+			new Limiter({ name: "api", rate: 1 });
+			new Limiter({ name: "api", rate: 1, burst: 1, period: 1000 });
+		`,
+	}
+
+	for name, code := range cases {
+		code := code
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := newRateLimitRuntime(t, New(), "default")
+			_, err := runtime.RunOnEventLoop(`(async () => {` + code + `})()`)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLimiterExceptions(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		code, err string
+	}{
+		"no options": {
+			code: `new Limiter()`,
+			err:  "invalid limiter options: the name and the rate of the limiter are required",
+		},
+		"no name": {
+			code: `new Limiter({ rate: 1 })`,
+			err:  "invalid limiter options: the name is required",
+		},
+		"invalid rate": {
+			code: `new Limiter({ name: "api", rate: 0 })`,
+			err:  "invalid limiter options: the rate should be a positive number, but it is 0",
+		},
+		"invalid period": {
+			code: `new Limiter({ name: "api", rate: 1, period: "-1s" })`,
+			err:  "invalid limiter options: the period should be positive, but it is -1s",
+		},
+		"invalid burst": {
+			code: `new Limiter({ name: "api", rate: 1, burst: 0 })`,
+			err:  "invalid limiter options: the burst should be at least 1, but it is 0",
+		},
+		"invalid scope": {
+			code: `new Limiter({ name: "api", rate: 1, scope: "vu" })`,
+			err:  `invalid limiter options: unsupported scope "vu", it needs to be test or scenario`,
+		},
+		"unknown option": {
+			code: `new Limiter({ name: "api", rate: 1, foo: 1 })`,
+			err:  `invalid limiter options: unknown option "foo"`,
+		},
+		"different definition": {
+			code: `new Limiter({ name: "api", rate: 1 }); new Limiter({ name: "api", rate: 2 })`,
+			err:  `the "api" limiter is already defined with a different configuration`,
+		},
+		"invalid tokens": {
+			code: `new Limiter({ name: "api", rate: 1 }).tryAcquire({ tokens: 0 })`,
+			err:  "tryAcquire() failed; reason: the tokens should be at least 1, but they are 0",
+		},
+	}
+
+	for name, testCase := range cases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := newRateLimitRuntime(t, New(), "default")
+			_, err := runtime.VU.Runtime().RunString(testCase.code)
+			require.ErrorContains(t, err, testCase.err)
+		})
+	}
+}
+
+func TestLimiterInInitContext(t *testing.T) {
+	t.Parallel()
+
+	runtime := modulestest.NewRuntime(t)
+	err := runtime.SetupModuleSystem(map[string]any{"k6/experimental/ratelimit": New()}, nil, nil)
+	require.NoError(t, err)
+
+	_, err = runtime.VU.Runtime().RunString(`
+		var { Limiter } = require("k6/experimental/ratelimit");
+		var limiter = new Limiter({ name: "api", rate: 1 });
+	`)
+	require.NoError(t, err, "defining limiters in the init context should be allowed")
+
+	_, err = runtime.VU.Runtime().RunString(`limiter.acquire()`)
+	require.ErrorContains(t, err, "acquiring tokens in the init context is not supported")
+
+	_, err = runtime.VU.Runtime().RunString(`limiter.tryAcquire()`)
+	require.ErrorContains(t, err, "acquiring tokens in the init context is not supported")
+}
+
+func TestLimiterScopes(t *testing.T) {
+	t.Parallel()
+
+	root := New()
+	tryAcquire := func(runtime *modulestest.Runtime, scope string) bool {
+		v, err := runtime.VU.Runtime().RunString(
+			`new Limiter({ name: "` + scope + `", rate: 1, period: "1h", scope: "` + scope + `" }).tryAcquire()`)
+		require.NoError(t, err)
+		return v.ToBoolean()
+	}
+
+	first := newRateLimitRuntime(t, root, "first")
+	second := newRateLimitRuntime(t, root, "second")
+	other := newRateLimitRuntime(t, root, "second")
+
+	assert.True(t, tryAcquire(first, "test"))
+	assert.False(t, tryAcquire(second, "test"), "the bucket of the test run should be shared by the scenarios")
+
+	assert.True(t, tryAcquire(first, "scenario"))
+	assert.True(t, tryAcquire(second, "scenario"), "the scenarios should have their own buckets")
+	assert.False(t, tryAcquire(other, "scenario"), "the bucket of a scenario should be shared by its VUs")
+}
+
+func TestLimiterBetweenVUs(t *testing.T) {
+	t.Parallel()
+
+	const vus = 5
+	root := New()
+	runtimes := make([]*modulestest.Runtime, vus)
+	for i := range runtimes {
+		runtimes[i] = newRateLimitRuntime(t, root, "default")
+		_, err := runtimes[i].VU.Runtime().RunString(`var limiter = new Limiter({ name: "api", rate: 50, burst: 1 });`)
+		require.NoError(t, err)
+	}
+
+	start := time.Now()
+	wg := &sync.WaitGroup{}
+	for _, runtime := range runtimes {
+		runtime := runtime
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := runtime.RunOnEventLoop(`(async () => {
+				for (let i = 0; i < 2; i++) {
+					await limiter.acquire();
+				}
+			})()`)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// the 10 tokens, but the first one, are acquired every 20ms
+	assert.GreaterOrEqual(t, time.Since(start), 170*time.Millisecond)
+}
@@ -0,0 +1,43 @@
+package sockets
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics for the sockets module.
+type instanceMetrics struct {
+	// Connecting measures the time spent establishing the connections,
+	// including their TLS handshakes.
+	Connecting *metrics.Metric
+
+	// ConnectionDuration measures the time connections stayed open.
+	ConnectionDuration *metrics.Metric
+
+	// MessagesSent counts the calls to send().
+	MessagesSent *metrics.Metric
+
+	// MessagesReceived counts the calls to receive() that received data.
+	MessagesReceived *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.Connecting, err = registry.NewMetric("sockets_connecting", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.ConnectionDuration, err = registry.NewMetric("sockets_connection_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.MessagesSent, err = registry.NewMetric("sockets_msgs_sent", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.MessagesReceived, err = registry.NewMetric("sockets_msgs_received", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,321 @@
+package sockets
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+type testState struct {
+	*modulestest.Runtime
+	tb      *httpmultibin.HTTPMultiBin
+	samples chan metrics.SampleContainer
+}
+
+func newTestState(t testing.TB) testState {
+	t.Helper()
+
+	tb := httpmultibin.NewHTTPMultiBin(t)
+
+	testRuntime := modulestest.NewRuntime(t)
+	samples := make(chan metrics.SampleContainer, 1000)
+
+	logger := logrus.New()
+	logger.Out = io.Discard
+
+	registry := metrics.NewRegistry()
+	state := &lib.State{
+		Dialer: tb.Dialer,
+		Options: lib.Options{
+			SystemTags: metrics.NewSystemTagSet(metrics.TagURL),
+			UserAgent:  null.StringFrom("TestUserAgent"),
+		},
+		Logger:         logger,
+		Samples:        samples,
+		TLSConfig:      tb.TLSClientConfig,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	}
+
+	m, ok := New().NewModuleInstance(testRuntime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, testRuntime.VU.RuntimeField.Set("sockets", m.Exports().Named))
+	testRuntime.MoveToVUContext(state)
+
+	return testState{
+		Runtime: testRuntime,
+		tb:      tb,
+		samples: samples,
+	}
+}
+
+// startTCPServer starts a TCP server handling its connections with the
+// provided function, and returns its address.
+func startTCPServer(t testing.TB, handle func(net.Conn)) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer func() { _ = conn.Close() }()
+				handle(conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// echo writes back what the provided connection reads.
+func echo(conn net.Conn) {
+	_, _ = io.Copy(conn, conn)
+}
+
+// metricValues returns the sum of the values of each metric of the provided samples.
+func metricValues(samples []metrics.SampleContainer) map[string]float64 {
+	values := make(map[string]float64)
+	for _, container := range samples {
+		for _, sample := range container.GetSamples() {
+			values[sample.Metric.Name] += sample.Value
+		}
+	}
+
+	return values
+}
+
+// toString is a JS function turning an ArrayBuffer into a string.
+const toString = `
+	function toString(buffer) {
+		return String.fromCharCode.apply(null, new Uint8Array(buffer));
+	}
+`
+
+func TestSocketTCP(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	addr := startTCPServer(t, echo)
+	require.NoError(t, ts.VU.RuntimeField.Set("ADDR", addr))
+
+	_, err := ts.RunOnEventLoop(toString + `
+		(async function () {
+			const socket = await sockets.connect("tcp", ADDR, { timeout: "1s", tags: { tag: "value" } });
+			if (socket.network !== "tcp" || socket.remoteAddress !== ADDR) {
+				throw new Error("unexpected socket: " + socket.network + " " + socket.remoteAddress);
+			}
+
+			const sent = await socket.send("hello, ");
+			await socket.send(new Uint8Array([119, 111, 114, 108, 100]));
+			if (sent !== 7) { throw new Error("unexpected bytes sent: " + sent); }
+
+			const received = toString(await socket.receive({ size: 12 }));
+			if (received !== "hello, world") { throw new Error("unexpected data: " + received); }
+
+			await socket.close();
+			await socket.close();
+
+			try {
+				await socket.send("again");
+				throw new Error("expected send() to fail");
+			} catch (e) {
+				if (!String(e).includes("the socket is closed")) { throw e; }
+			}
+		})()
+	`)
+	require.NoError(t, err)
+
+	samples := metrics.GetBufferedSamples(ts.samples)
+	values := metricValues(samples)
+	assert.Equal(t, 2.0, values["sockets_msgs_sent"])
+	assert.Equal(t, 1.0, values["sockets_msgs_received"])
+	assert.Contains(t, values, "sockets_connecting")
+	assert.Contains(t, values, "sockets_connection_duration")
+
+	for _, container := range samples {
+		for _, sample := range container.GetSamples() {
+			url, _ := sample.Tags.Get("url")
+			assert.Equal(t, "tcp://"+addr, url)
+
+			tag, _ := sample.Tags.Get("tag")
+			assert.Equal(t, "value", tag)
+		}
+	}
+}
+
+func TestSocketReceive(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	addr := startTCPServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("bye"))
+
+		// The connection is closed once the client closes its own.
+		_, _ = io.Copy(io.Discard, conn)
+	})
+	silentAddr := startTCPServer(t, func(conn net.Conn) {
+		_, _ = io.Copy(io.Discard, conn)
+	})
+	require.NoError(t, ts.VU.RuntimeField.Set("ADDR", addr))
+	require.NoError(t, ts.VU.RuntimeField.Set("SILENT_ADDR", silentAddr))
+
+	_, err := ts.RunOnEventLoop(toString + `
+		(async function () {
+			const socket = await sockets.connect("tcp", ADDR);
+			const received = toString(await socket.receive());
+			if (received !== "bye") { throw new Error("unexpected data: " + received); }
+			await socket.close();
+
+			const silent = await sockets.connect("tcp", SILENT_ADDR);
+			try {
+				await silent.receive({ timeout: "50ms" });
+				throw new Error("expected receive() to time out");
+			} catch (e) {
+				if (!String(e).includes("no data was received within 50ms")) { throw e; }
+			}
+			await silent.close();
+		})()
+	`)
+	require.NoError(t, err)
+}
+
+func TestSocketUDP(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			_, _ = conn.WriteTo(buf[:n], addr)
+		}
+	}()
+
+	require.NoError(t, ts.VU.RuntimeField.Set("ADDR", conn.LocalAddr().String()))
+
+	_, err = ts.RunOnEventLoop(toString + `
+		(async function () {
+			const socket = await sockets.connect("udp", ADDR);
+			await socket.send("first");
+			await socket.send("second");
+
+			const first = toString(await socket.receive({ timeout: "1s" }));
+			const second = toString(await socket.receive({ timeout: "1s" }));
+			if (first !== "first" || second !== "second") {
+				throw new Error("unexpected datagrams: " + first + ", " + second);
+			}
+
+			await socket.close();
+		})()
+	`)
+	require.NoError(t, err)
+}
+
+func TestSocketTLS(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	sr := ts.tb.Replacer.Replace
+
+	_, err := ts.RunOnEventLoop(toString + sr(`
+		const request = "GET /get HTTP/1.1\r\nHost: HTTPSBIN_DOMAIN\r\nConnection: close\r\n\r\n";
+
+		(async function () {
+			const secured = await sockets.connect("tcp", "HTTPSBIN_DOMAIN:HTTPSBIN_PORT", { tls: true });
+			await secured.send(request);
+			let response = toString(await secured.receive());
+			if (!response.startsWith("HTTP/1.1 200")) { throw new Error("unexpected response: " + response); }
+			await secured.close();
+
+			const upgraded = await sockets.connect("tcp", "HTTPSBIN_DOMAIN:HTTPSBIN_PORT");
+			await upgraded.upgradeTLS({ serverName: "HTTPSBIN_DOMAIN" });
+			await upgraded.send(request);
+			response = toString(await upgraded.receive());
+			if (!response.startsWith("HTTP/1.1 200")) { throw new Error("unexpected response: " + response); }
+			await upgraded.close();
+		})()
+	`))
+	require.NoError(t, err)
+}
+
+func TestConnectErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{
+			name:    "unsupported network",
+			script:  `sockets.connect("sctp", "127.0.0.1:1")`,
+			wantErr: `unsupported network "sctp"; expected "tcp" or "udp"`,
+		},
+		{
+			name:    "invalid address",
+			script:  `sockets.connect("tcp", "127.0.0.1")`,
+			wantErr: `invalid address "127.0.0.1"`,
+		},
+		{
+			name:    "tls over udp",
+			script:  `sockets.connect("udp", "127.0.0.1:1", { tls: true })`,
+			wantErr: "the tls option is only supported by tcp sockets",
+		},
+		{
+			name:    "unknown option",
+			script:  `sockets.connect("tcp", "127.0.0.1:1", { unknown: true })`,
+			wantErr: "unknown option unknown",
+		},
+		{
+			name:    "refused connection",
+			script:  `sockets.connect("tcp", "127.0.0.1:1")`,
+			wantErr: "connect() failed",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestState(t)
+
+			_, err := ts.RunOnEventLoop(`
+				(async function () {
+					await ` + tt.script + `;
+					throw new Error("expected connect() to fail");
+				})()
+			`)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
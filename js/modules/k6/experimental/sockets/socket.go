@@ -0,0 +1,613 @@
+package sockets
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/promises"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	networkTCP = "tcp"
+	networkUDP = "udp"
+)
+
+// maxReceiveSize is the most bytes a call to receive() reads, unless its size
+// option is set; it is large enough to hold any UDP datagram.
+const maxReceiveSize = 64 * 1024
+
+// tlsOptions configures the TLS connection sockets are secured with.
+type tlsOptions struct {
+	// serverName holds the name of the server, checked against its certificate,
+	// which defaults to the host the socket is connected to.
+	serverName string
+
+	// insecureSkipVerify indicates whether the server's certificate is not to
+	// be verified.
+	insecureSkipVerify bool
+}
+
+// connectOptions represent the parameters bag of connect().
+type connectOptions struct {
+	timeout     time.Duration
+	tls         *tlsOptions
+	tagsAndMeta *metrics.TagsAndMeta
+}
+
+// receiveOptions represent the parameters bag of receive().
+type receiveOptions struct {
+	timeout time.Duration
+	size    int64
+}
+
+// Socket is a TCP connection, or a UDP socket, to a server.
+type Socket struct {
+	// Network holds the network of the socket, either "tcp" or "udp".
+	Network string `js:"network"`
+
+	// RemoteAddress holds the address of the server the socket is connected to.
+	RemoteAddress string `js:"remoteAddress"`
+
+	// LocalAddress holds the local address of the socket.
+	LocalAddress string `js:"localAddress"`
+
+	vu          modules.VU
+	metrics     *instanceMetrics
+	tagsAndMeta *metrics.TagsAndMeta
+
+	// host holds the host the socket was connected to, which the TLS server name
+	// defaults to.
+	host string
+
+	// ops serializes the TLS upgrades with the other operations, which can
+	// happen concurrently.
+	ops sync.RWMutex
+
+	// mu guards conn, which a TLS upgrade replaces, and closed.
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+
+	// done is closed once the socket is closed.
+	done   chan struct{}
+	opened time.Time
+}
+
+// Connect returns a promise that will resolve to a [Socket] connected to the
+// server at the provided address, over the given network, either "tcp" or "udp".
+//
+// The options can hold a `timeout` for the connection to be established within,
+// a `tls` property, either true or an object holding a `serverName` and an
+// `insecureSkipVerify` flag, to secure TCP connections, and `tags` to tag the
+// metrics of the socket with.
+func (mi *ModuleInstance) Connect(network, address, options sobek.Value) *sobek.Promise {
+	promise, resolve, reject := promises.New(mi.vu)
+
+	state := mi.vu.State()
+	if state == nil {
+		reject(errors.New("connect() failed; reason: using sockets in the init context is not supported"))
+		return promise
+	}
+
+	networkStr, addressStr, opts, err := mi.parseConnectArgs(state, network, address, options)
+	if err != nil {
+		reject(fmt.Errorf("connect() failed; reason: %w", err))
+		return promise
+	}
+
+	go func() {
+		socket, err := mi.connect(state, networkStr, addressStr, opts)
+		if err != nil {
+			reject(fmt.Errorf("connect() failed; reason: %w", err))
+			return
+		}
+
+		resolve(socket)
+	}()
+
+	return promise
+}
+
+// parseConnectArgs parses and validates the arguments of connect().
+func (mi *ModuleInstance) parseConnectArgs(
+	state *lib.State, network, address, options sobek.Value,
+) (string, string, *connectOptions, error) {
+	rt := mi.vu.Runtime()
+
+	if common.IsNullish(network) {
+		return "", "", nil, errors.New("network cannot be null or undefined")
+	}
+
+	networkStr := network.String()
+	if networkStr != networkTCP && networkStr != networkUDP {
+		return "", "", nil, fmt.Errorf("unsupported network %q; expected %q or %q", networkStr, networkTCP, networkUDP)
+	}
+
+	if common.IsNullish(address) || address.String() == "" {
+		return "", "", nil, errors.New("address cannot be empty")
+	}
+
+	addressStr := address.String()
+	if _, _, err := net.SplitHostPort(addressStr); err != nil {
+		return "", "", nil, fmt.Errorf("invalid address %q: %w", addressStr, err)
+	}
+
+	opts, err := parseConnectOptions(state, rt, options)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if opts.tls != nil && networkStr != networkTCP {
+		return "", "", nil, errors.New("the tls option is only supported by tcp sockets")
+	}
+
+	// the `name` and `url` tags have the exact same values, as for the other protocols
+	systemTags := state.Options.SystemTags
+	if nameTagValue, ok := opts.tagsAndMeta.Tags.Get(metrics.TagName.String()); ok {
+		opts.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, nameTagValue)
+	} else {
+		url := networkStr + "://" + addressStr
+		opts.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, url)
+		opts.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagName, url)
+	}
+
+	return networkStr, addressStr, opts, nil
+}
+
+// parseConnectOptions parses the options of connect().
+func parseConnectOptions(state *lib.State, rt *sobek.Runtime, raw sobek.Value) (*connectOptions, error) {
+	tagsAndMeta := state.Tags.GetCurrentValues()
+	parsed := &connectOptions{tagsAndMeta: &tagsAndMeta}
+
+	if common.IsNullish(raw) {
+		return parsed, nil
+	}
+
+	params := raw.ToObject(rt)
+	for _, k := range params.Keys() {
+		v := params.Get(k)
+		if common.IsNullish(v) {
+			continue
+		}
+
+		switch k {
+		case "timeout":
+			timeout, err := types.GetDurationValue(v.Export())
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout option: %w", err)
+			}
+			parsed.timeout = timeout
+		case "tls":
+			tlsOpts, err := parseTLSOptions(rt, v)
+			if err != nil {
+				return nil, err
+			}
+			parsed.tls = tlsOpts
+		case "tags":
+			if err := common.ApplyCustomUserTags(rt, parsed.tagsAndMeta, v); err != nil {
+				return nil, fmt.Errorf("invalid tags option: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unknown option %s", k)
+		}
+	}
+
+	return parsed, nil
+}
+
+// parseTLSOptions parses the tls option, of either connect() or upgradeTLS(),
+// which is nil if TLS is not to be used.
+func parseTLSOptions(rt *sobek.Runtime, v sobek.Value) (*tlsOptions, error) {
+	if common.IsNullish(v) {
+		return nil, nil //nolint:nilnil
+	}
+
+	if enabled, ok := v.Export().(bool); ok {
+		if !enabled {
+			return nil, nil //nolint:nilnil
+		}
+
+		return &tlsOptions{}, nil
+	}
+
+	opts := &tlsOptions{}
+	obj := v.ToObject(rt)
+	for _, k := range obj.Keys() {
+		switch k {
+		case "serverName":
+			opts.serverName = obj.Get(k).String()
+		case "insecureSkipVerify":
+			opts.insecureSkipVerify = obj.Get(k).ToBoolean()
+		default:
+			return nil, fmt.Errorf("unknown tls option %s", k)
+		}
+	}
+
+	return opts, nil
+}
+
+// connect connects to the server at the provided address, and returns the
+// socket connected to it.
+func (mi *ModuleInstance) connect(state *lib.State, network, address string, opts *connectOptions) (*Socket, error) {
+	ctx := mi.vu.Context()
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+
+	host, _, _ := net.SplitHostPort(address)
+
+	start := time.Now()
+	conn, err := state.Dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.tls != nil {
+		if conn, err = handshake(ctx, state, conn, host, opts.tls); err != nil {
+			return nil, err
+		}
+	}
+
+	opened := time.Now()
+
+	s := &Socket{
+		Network:       network,
+		RemoteAddress: conn.RemoteAddr().String(),
+		LocalAddress:  conn.LocalAddr().String(),
+		vu:            mi.vu,
+		metrics:       mi.metrics,
+		tagsAndMeta:   opts.tagsAndMeta,
+		host:          host,
+		conn:          conn,
+		done:          make(chan struct{}),
+		opened:        opened,
+	}
+
+	s.pushSample(s.metrics.Connecting, start, metrics.D(opened.Sub(start)))
+
+	// the socket is closed once the VU is done, so that pending operations fail
+	go func() {
+		select {
+		case <-mi.vu.Context().Done():
+			_ = s.close()
+		case <-s.done:
+		}
+	}()
+
+	return s, nil
+}
+
+// handshake secures the provided connection with TLS, and returns the secured
+// connection, which the provided connection is closed in favor of on failure.
+func handshake(ctx context.Context, state *lib.State, conn net.Conn, host string, opts *tlsOptions) (net.Conn, error) {
+	config := &tls.Config{} //nolint:gosec
+	if state.TLSConfig != nil {
+		config = state.TLSConfig.Clone()
+	}
+
+	config.ServerName = host
+	if opts.serverName != "" {
+		config.ServerName = opts.serverName
+	}
+
+	if opts.insecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("the TLS handshake failed: %w", err)
+	}
+
+	return tlsConn, nil
+}
+
+// Send returns a promise that will resolve to the number of bytes sent, once
+// the provided data, either a string, an ArrayBuffer, or a typed array, is sent.
+//
+// For UDP sockets, the data is sent as a single datagram.
+func (s *Socket) Send(data sobek.Value) *sobek.Promise {
+	promise, resolve, reject := promises.New(s.vu)
+
+	if common.IsNullish(data) {
+		reject(errors.New("send() failed; reason: data cannot be null or undefined"))
+		return promise
+	}
+
+	b, err := common.ToBytes(data.Export())
+	if err != nil {
+		reject(fmt.Errorf("send() failed; reason: %w", err))
+		return promise
+	}
+
+	// The data is copied, so that it is not modified while being sent.
+	b = append([]byte(nil), b...)
+
+	go func() {
+		s.ops.RLock()
+		defer s.ops.RUnlock()
+
+		conn, ok := s.getConn()
+		if !ok {
+			reject(errors.New("send() failed; reason: the socket is closed"))
+			return
+		}
+
+		n, err := conn.Write(b)
+		if err != nil {
+			reject(fmt.Errorf("send() failed; reason: %w", err))
+			return
+		}
+
+		s.pushSample(s.metrics.MessagesSent, time.Now(), 1)
+		resolve(n)
+	}()
+
+	return promise
+}
+
+// Receive returns a promise that will resolve to an ArrayBuffer holding the
+// data received.
+//
+// The options can hold a `timeout` for the data to be received within, and
+// for TCP sockets, a `size`, for exactly that many bytes to be received, rather
+// than the ones available. For UDP sockets, a single datagram is received.
+//
+// The promise resolves to an empty ArrayBuffer once the server closed the
+// connection.
+func (s *Socket) Receive(options sobek.Value) *sobek.Promise {
+	rt := s.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+
+	opts, err := s.parseReceiveOptions(options)
+	if err != nil {
+		reject(fmt.Errorf("receive() failed; reason: %w", err))
+		return promise
+	}
+
+	callback := s.vu.RegisterCallback()
+	go func() {
+		data, err := s.receive(opts)
+
+		callback(func() error {
+			if err != nil {
+				reject(err)
+				return nil
+			}
+
+			resolve(rt.NewArrayBuffer(data))
+			return nil
+		})
+	}()
+
+	return promise
+}
+
+// parseReceiveOptions parses the options of receive().
+func (s *Socket) parseReceiveOptions(raw sobek.Value) (*receiveOptions, error) {
+	parsed := &receiveOptions{}
+
+	if common.IsNullish(raw) {
+		return parsed, nil
+	}
+
+	params := raw.ToObject(s.vu.Runtime())
+	for _, k := range params.Keys() {
+		v := params.Get(k)
+		if common.IsNullish(v) {
+			continue
+		}
+
+		switch k {
+		case "timeout":
+			timeout, err := types.GetDurationValue(v.Export())
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout option: %w", err)
+			}
+			parsed.timeout = timeout
+		case "size":
+			if s.Network != networkTCP {
+				return nil, errors.New("the size option is only supported by tcp sockets")
+			}
+
+			size := v.ToInteger()
+			if size <= 0 {
+				return nil, fmt.Errorf("invalid size option %d: it must be positive", size)
+			}
+			parsed.size = size
+		default:
+			return nil, fmt.Errorf("unknown option %s", k)
+		}
+	}
+
+	return parsed, nil
+}
+
+// receive receives data from the socket, as described by the provided options.
+func (s *Socket) receive(opts *receiveOptions) ([]byte, error) {
+	s.ops.RLock()
+	defer s.ops.RUnlock()
+
+	conn, ok := s.getConn()
+	if !ok {
+		return nil, errors.New("receive() failed; reason: the socket is closed")
+	}
+
+	var deadline time.Time
+	if opts.timeout > 0 {
+		deadline = time.Now().Add(opts.timeout)
+	}
+
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("receive() failed; reason: %w", err)
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+
+	if opts.size > 0 {
+		data = make([]byte, opts.size)
+		_, err = io.ReadFull(conn, data)
+	} else {
+		var n int
+		data = make([]byte, maxReceiveSize)
+		n, err = conn.Read(data)
+		data = data[:n]
+	}
+
+	switch {
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return nil, fmt.Errorf("receive() failed; reason: no data was received within %s", opts.timeout)
+	case errors.Is(err, io.EOF) && len(data) == 0:
+		return []byte{}, nil
+	case err != nil:
+		return nil, fmt.Errorf("receive() failed; reason: %w", err)
+	}
+
+	s.pushSample(s.metrics.MessagesReceived, time.Now(), 1)
+
+	return data, nil
+}
+
+// UpgradeTLS returns a promise that will resolve once the TCP connection is
+// secured with TLS, after which the data is sent and received through TLS.
+//
+// The options can hold a `serverName` and an `insecureSkipVerify` flag, as the
+// tls option of connect() can.
+func (s *Socket) UpgradeTLS(options sobek.Value) *sobek.Promise {
+	promise, resolve, reject := promises.New(s.vu)
+
+	if s.Network != networkTCP {
+		reject(errors.New("upgradeTLS() failed; reason: only tcp sockets can be secured with TLS"))
+		return promise
+	}
+
+	opts := &tlsOptions{}
+	if !common.IsNullish(options) {
+		var err error
+		if opts, err = parseTLSOptions(s.vu.Runtime(), options); err != nil {
+			reject(fmt.Errorf("upgradeTLS() failed; reason: %w", err))
+			return promise
+		}
+	}
+
+	state := s.vu.State()
+
+	go func() {
+		s.ops.Lock()
+		defer s.ops.Unlock()
+
+		conn, ok := s.getConn()
+		if !ok {
+			reject(errors.New("upgradeTLS() failed; reason: the socket is closed"))
+			return
+		}
+
+		tlsConn, err := handshake(s.vu.Context(), state, conn, s.host, opts)
+		if err != nil {
+			_ = s.close()
+			reject(fmt.Errorf("upgradeTLS() failed; reason: %w", err))
+			return
+		}
+
+		if !s.setConn(tlsConn) {
+			_ = tlsConn.Close()
+			reject(errors.New("upgradeTLS() failed; reason: the socket is closed"))
+			return
+		}
+
+		resolve(sobek.Undefined())
+	}()
+
+	return promise
+}
+
+// getConn returns the connection of the socket, and whether it is still open.
+func (s *Socket) getConn() (net.Conn, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.conn, !s.closed
+}
+
+// setConn replaces the connection of the socket, unless it is closed, and
+// returns whether it was replaced.
+func (s *Socket) setConn(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	s.conn = conn
+
+	return true
+}
+
+// Close returns a promise that will resolve once the socket is closed, after
+// which data cannot be sent nor received anymore. Closing a closed socket has no effect.
+func (s *Socket) Close() *sobek.Promise {
+	promise, resolve, reject := promises.New(s.vu)
+
+	go func() {
+		if err := s.close(); err != nil {
+			reject(fmt.Errorf("close() failed; reason: %w", err))
+			return
+		}
+
+		resolve(sobek.Undefined())
+	}()
+
+	return promise
+}
+
+// close closes the socket, unless it is closed already, which makes the
+// pending operations fail.
+func (s *Socket) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	s.closed = true
+	close(s.done)
+	s.pushSample(s.metrics.ConnectionDuration, time.Now(), metrics.D(time.Since(s.opened)))
+
+	return s.conn.Close()
+}
+
+// pushSample pushes a sample of the provided metric, tagged with the socket's
+// tags and metadata.
+func (s *Socket) pushSample(metric *metrics.Metric, t time.Time, value float64) {
+	metrics.PushIfNotDone(s.vu.Context(), s.vu.State().Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: metric,
+			Tags:   s.tagsAndMeta.Tags,
+		},
+		Time:     t,
+		Metadata: s.tagsAndMeta.Metadata,
+		Value:    value,
+	})
+}
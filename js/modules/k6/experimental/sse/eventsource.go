@@ -0,0 +1,491 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/sobek"
+	"github.com/mstoykov/k6-taskqueue-lib/taskqueue"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	httpModule "go.k6.io/k6/js/modules/k6/http"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	eventOpen    = "open"
+	eventMessage = "message"
+	eventError   = "error"
+)
+
+// ReadyState describes the state of an [eventSource]'s connection.
+type ReadyState uint8
+
+const (
+	// Connecting is the state while connecting to the server, or reconnecting
+	// to it after the connection was lost.
+	Connecting ReadyState = iota
+
+	// Open is the state while the connection is open, and events are received.
+	Open
+
+	// Closed is the state once the connection is closed, and won't be
+	// established again.
+	Closed
+)
+
+// defaultReconnectionDelay is the time to wait for before reconnecting to the
+// server, unless the server or the user set it.
+const defaultReconnectionDelay = 3 * time.Second
+
+// ErrorEvent is the event the error listeners are called with.
+type ErrorEvent struct {
+	// Type holds the type of the event, always "error".
+	Type string `js:"type"`
+
+	// Error holds the message of the error that happened.
+	Error string `js:"error"`
+}
+
+// eventSourceParams represent the parameters bag of an [eventSource].
+type eventSourceParams struct {
+	method            string
+	body              []byte
+	headers           http.Header
+	cookieJar         *cookiejar.Jar
+	tagsAndMeta       *metrics.TagsAndMeta
+	reconnectionDelay time.Duration
+}
+
+// eventSource is a connection to a server pushing events, which is established
+// again, after a delay, whenever it is lost.
+type eventSource struct {
+	vu      modules.VU
+	metrics *instanceMetrics
+
+	url    *url.URL
+	params *eventSourceParams
+	client *http.Client
+	tq     *taskqueue.TaskQueue
+	obj    *sobek.Object // the object that is given to js to interact with the EventSource
+
+	// ctx is canceled once the event source is closed.
+	ctx    context.Context //nolint:containedctx
+	cancel context.CancelFunc
+
+	listeners map[string][]func(sobek.Value) (sobek.Value, error)
+
+	// fields that are only accessed by the goroutine connecting to the server
+	lastEventID       string
+	reconnectionDelay time.Duration
+
+	// fields that should be seen by js only be updated on the event loop
+	readyState      ReadyState
+	lastEventIDSeen string
+}
+
+// NewEventSource is the JS constructor of the EventSource, connecting to the
+// server at the provided URL, with the given parameters.
+//
+// The connection is established in the background, and the registered
+// listeners are called, on the event loop, as it opens, as events are
+// received, and as errors happen. The iteration lasts until the event source
+// is closed, either by calling its close() method, or because the server
+// responded with an unexpected status or content type.
+func (mi *ModuleInstance) NewEventSource(c sobek.ConstructorCall) *sobek.Object {
+	rt := mi.vu.Runtime()
+
+	state := mi.vu.State()
+	if state == nil {
+		common.Throw(rt, errors.New("using EventSource in the init context is not supported"))
+	}
+
+	u, err := parseURL(c.Argument(0))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	params, err := buildParams(state, rt, c.Argument(1))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	params.setSystemTags(state, u)
+
+	es := &eventSource{
+		vu:                mi.vu,
+		metrics:           mi.metrics,
+		url:               u,
+		params:            params,
+		client:            &http.Client{Transport: state.Transport},
+		tq:                taskqueue.New(mi.vu.RegisterCallback),
+		obj:               rt.NewObject(),
+		listeners:         make(map[string][]func(sobek.Value) (sobek.Value, error)),
+		reconnectionDelay: params.reconnectionDelay,
+		readyState:        Connecting,
+	}
+	es.ctx, es.cancel = context.WithCancel(mi.vu.Context())
+
+	// this is needed because of how interfaces work and that client.Jar is http.CookieJar
+	if params.cookieJar != nil {
+		es.client.Jar = params.cookieJar
+	}
+
+	defineEventSource(rt, es)
+
+	go es.run()
+
+	return es.obj
+}
+
+// parseURL parses the url from the first constructor calls argument or returns an error
+func parseURL(urlValue sobek.Value) (*url.URL, error) {
+	if common.IsNullish(urlValue) {
+		return nil, errors.New("EventSource requires a url")
+	}
+
+	urlString := urlValue.String()
+	u, err := url.Parse(urlString)
+	if err != nil {
+		return nil, fmt.Errorf("EventSource requires valid url, but got %q which resulted in %w", urlString, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("EventSource requires url with scheme http or https, but got %q", u.Scheme)
+	}
+
+	return u, nil
+}
+
+// buildParams builds the EventSource params out of the provided value.
+func buildParams(state *lib.State, rt *sobek.Runtime, raw sobek.Value) (*eventSourceParams, error) {
+	tagsAndMeta := state.Tags.GetCurrentValues()
+
+	parsed := &eventSourceParams{
+		method:            http.MethodGet,
+		headers:           make(http.Header),
+		cookieJar:         state.CookieJar,
+		tagsAndMeta:       &tagsAndMeta,
+		reconnectionDelay: defaultReconnectionDelay,
+	}
+
+	parsed.headers.Set("User-Agent", state.Options.UserAgent.String)
+	parsed.headers.Set("Accept", "text/event-stream")
+	parsed.headers.Set("Cache-Control", "no-cache")
+
+	if common.IsNullish(raw) {
+		return parsed, nil
+	}
+
+	params := raw.ToObject(rt)
+	for _, k := range params.Keys() {
+		v := params.Get(k)
+		if common.IsNullish(v) {
+			continue
+		}
+
+		switch k {
+		case "method":
+			parsed.method = v.String()
+		case "body":
+			body, err := common.ToBytes(v.Export())
+			if err != nil {
+				return nil, fmt.Errorf("invalid EventSource body option: %w", err)
+			}
+			parsed.body = body
+		case "headers":
+			headersObj := v.ToObject(rt)
+			for _, key := range headersObj.Keys() {
+				parsed.headers.Set(key, headersObj.Get(key).String())
+			}
+		case "tags":
+			if err := common.ApplyCustomUserTags(rt, parsed.tagsAndMeta, v); err != nil {
+				return nil, fmt.Errorf("invalid EventSource tags option: %w", err)
+			}
+		case "jar":
+			if jar, ok := v.Export().(*httpModule.CookieJar); ok {
+				parsed.cookieJar = jar.Jar
+			}
+		case "reconnectionDelay":
+			delay, err := types.GetDurationValue(v.Export())
+			if err != nil {
+				return nil, fmt.Errorf("invalid EventSource reconnectionDelay option: %w", err)
+			}
+			parsed.reconnectionDelay = delay
+		default:
+			return nil, fmt.Errorf("unknown EventSource's option %s", k)
+		}
+	}
+
+	return parsed, nil
+}
+
+// setSystemTags sets the system tags describing the connection to the
+// provided URL.
+func (p *eventSourceParams) setSystemTags(state *lib.State, u *url.URL) {
+	systemTags := state.Options.SystemTags
+
+	p.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagMethod, p.method)
+
+	// After k6 v0.41.0, the `name` and `url` tags have the exact same values:
+	if nameTagValue, ok := p.tagsAndMeta.Tags.Get(metrics.TagName.String()); ok {
+		p.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, nameTagValue)
+	} else {
+		p.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagURL, u.String())
+		p.tagsAndMeta.SetSystemTagOrMetaIfEnabled(systemTags, metrics.TagName, u.String())
+	}
+}
+
+// defineEventSource defines all properties and methods for the EventSource
+func defineEventSource(rt *sobek.Runtime, es *eventSource) {
+	must(rt, es.obj.DefineDataProperty(
+		"on", rt.ToValue(es.on), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, es.obj.DefineDataProperty(
+		"close", rt.ToValue(es.close), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, es.obj.DefineDataProperty(
+		"url", rt.ToValue(es.url.String()), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, es.obj.DefineAccessorProperty(
+		"readyState", rt.ToValue(func() uint8 {
+			// the state is converted, so that it is exposed as a number
+			return uint8(es.readyState)
+		}), nil, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+	must(rt, es.obj.DefineAccessorProperty(
+		"lastEventId", rt.ToValue(func() string {
+			return es.lastEventIDSeen
+		}), nil, sobek.FLAG_FALSE, sobek.FLAG_TRUE))
+}
+
+// on registers a handler for a certain event type: "open", "error", "message"
+// for the events of no particular type, or any of the types events are sent with.
+func (es *eventSource) on(event string, handler func(sobek.Value) (sobek.Value, error)) {
+	if handler == nil {
+		common.Throw(es.vu.Runtime(), fmt.Errorf("handler for %q event isn't a callable function", event))
+	}
+
+	es.listeners[event] = append(es.listeners[event], handler)
+}
+
+// close closes the connection, which won't be established again.
+func (es *eventSource) close() {
+	if es.readyState == Closed {
+		return
+	}
+
+	es.readyState = Closed
+	es.cancel()
+}
+
+// run connects to the server, and connects again, after the reconnection
+// delay, whenever the connection is lost, until the event source is closed.
+func (es *eventSource) run() {
+	defer func() {
+		es.cancel()
+		es.tq.Close()
+	}()
+
+	for attempt := 0; es.connect(attempt > 0); attempt++ {
+		select {
+		case <-time.After(es.reconnectionDelay):
+		case <-es.ctx.Done():
+			return
+		}
+	}
+}
+
+// connect connects to the server, and forwards the events it sends to the
+// listeners, until the connection is lost. It returns whether the connection
+// is to be established again.
+func (es *eventSource) connect(reconnection bool) bool {
+	tagsAndMeta := es.params.tagsAndMeta.Clone()
+
+	req, err := http.NewRequestWithContext(es.ctx, es.params.method, es.url.String(), bytes.NewReader(es.params.body))
+	if err != nil {
+		es.queueFailure(err)
+		return false
+	}
+
+	req.Header = es.params.headers.Clone()
+	if es.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", es.lastEventID)
+	}
+
+	start := time.Now()
+	resp, err := es.client.Do(req) //nolint:bodyclose
+	if err != nil {
+		if es.ctx.Err() != nil {
+			return false
+		}
+
+		// Network errors are considered transient, the connection is
+		// established again.
+		es.queueReconnection(err)
+		return true
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	opened := time.Now()
+	tagsAndMeta.SetSystemTagOrMetaIfEnabled(
+		es.vu.State().Options.SystemTags, metrics.TagStatus, strconv.Itoa(resp.StatusCode))
+	es.pushSample(es.metrics.Connecting, &tagsAndMeta, start, metrics.D(opened.Sub(start)))
+
+	if err := checkResponse(resp); err != nil {
+		es.queueFailure(err)
+		return false
+	}
+
+	es.pushSample(es.metrics.Connections, &tagsAndMeta, opened, 1)
+	if reconnection {
+		es.pushSample(es.metrics.Reconnections, &tagsAndMeta, opened, 1)
+	}
+
+	es.tq.Queue(func() error {
+		if es.readyState == Closed {
+			return nil
+		}
+
+		es.readyState = Open
+
+		return es.callListeners(eventOpen, Event{Type: eventOpen, LastEventID: es.lastEventIDSeen})
+	})
+
+	parser := newEventStreamParser(resp.Body, es.lastEventID)
+	last := opened
+
+	for {
+		event, err := parser.next()
+		if parser.retry > 0 {
+			es.reconnectionDelay = parser.retry
+		}
+
+		if err != nil {
+			es.pushSample(es.metrics.ConnectionDuration, &tagsAndMeta, opened, metrics.D(time.Since(opened)))
+
+			if es.ctx.Err() != nil {
+				return false
+			}
+
+			if errors.Is(err, io.EOF) {
+				err = errors.New("the server closed the connection")
+			}
+
+			es.queueReconnection(err)
+			return true
+		}
+
+		now := time.Now()
+		es.pushSample(es.metrics.EventsReceived, &tagsAndMeta, now, 1)
+		es.pushSample(es.metrics.EventLatency, &tagsAndMeta, now, metrics.D(now.Sub(last)))
+		last = now
+
+		es.lastEventID = event.LastEventID
+		es.queueEvent(event)
+	}
+}
+
+// checkResponse returns an error if the provided response isn't an event
+// stream the connection can be established with.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("the server responded with the unexpected status %d", resp.StatusCode)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != "text/event-stream" {
+		return fmt.Errorf("the server responded with the unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	return nil
+}
+
+// queueEvent queues calling the listeners of the provided event.
+func (es *eventSource) queueEvent(event Event) {
+	es.tq.Queue(func() error {
+		if es.readyState == Closed {
+			return nil
+		}
+
+		es.lastEventIDSeen = event.LastEventID
+
+		return es.callListeners(event.Type, event)
+	})
+}
+
+// queueReconnection queues calling the error listeners with the provided error,
+// as the connection is about to be established again.
+func (es *eventSource) queueReconnection(err error) {
+	es.tq.Queue(func() error {
+		if es.readyState == Closed {
+			return nil
+		}
+
+		es.readyState = Connecting
+
+		return es.callErrorListeners(err)
+	})
+}
+
+// queueFailure queues closing the event source and calling the error listeners
+// with the provided error, as the connection won't be established again.
+func (es *eventSource) queueFailure(err error) {
+	es.tq.Queue(func() error {
+		if es.readyState == Closed {
+			return nil
+		}
+
+		es.readyState = Closed
+
+		return es.callErrorListeners(err)
+	})
+}
+
+// callListeners calls the listeners of the provided event type with the given
+// event. The event source is closed if any of them throws.
+func (es *eventSource) callListeners(eventType string, event any) error {
+	rt := es.vu.Runtime()
+
+	for _, listener := range es.listeners[eventType] {
+		if _, err := listener(rt.ToValue(event)); err != nil {
+			es.close()
+			return err
+		}
+	}
+
+	return nil
+}
+
+// callErrorListeners calls the error listeners with the provided error.
+func (es *eventSource) callErrorListeners(err error) error {
+	if len(es.listeners[eventError]) == 0 {
+		es.vu.State().Logger.Warnf("no handlers for error registered, but an error happened: %s", err)
+	}
+
+	return es.callListeners(eventError, ErrorEvent{Type: eventError, Error: err.Error()})
+}
+
+// pushSample pushes a sample of the provided metric, tagged with the given
+// tags and metadata.
+func (es *eventSource) pushSample(metric *metrics.Metric, tagsAndMeta *metrics.TagsAndMeta, t time.Time, value float64) {
+	metrics.PushIfNotDone(es.vu.Context(), es.vu.State().Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: metric,
+			Tags:   tagsAndMeta.Tags,
+		},
+		Time:     t,
+		Metadata: tagsAndMeta.Metadata,
+		Value:    value,
+	})
+}
@@ -0,0 +1,59 @@
+package sse
+
+import "go.k6.io/k6/metrics"
+
+// instanceMetrics contains the metrics for the sse module.
+type instanceMetrics struct {
+	// Connections counts the connections established to the servers.
+	Connections *metrics.Metric
+
+	// Reconnections counts the connections established again after the
+	// previous one was lost.
+	Reconnections *metrics.Metric
+
+	// Connecting measures the time spent waiting for the servers to respond
+	// to the connection requests.
+	Connecting *metrics.Metric
+
+	// ConnectionDuration measures the time connections stayed open.
+	ConnectionDuration *metrics.Metric
+
+	// EventsReceived counts the events received.
+	EventsReceived *metrics.Metric
+
+	// EventLatency measures the time elapsed between two events of a
+	// connection, or between the connection being opened and its first event.
+	EventLatency *metrics.Metric
+}
+
+// registerMetrics registers and returns the metrics in the provided registry
+func registerMetrics(registry *metrics.Registry) (*instanceMetrics, error) {
+	var err error
+	m := &instanceMetrics{}
+
+	if m.Connections, err = registry.NewMetric("sse_connections", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.Reconnections, err = registry.NewMetric("sse_reconnections", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.Connecting, err = registry.NewMetric("sse_connecting", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.ConnectionDuration, err = registry.NewMetric("sse_connection_duration", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	if m.EventsReceived, err = registry.NewMetric("sse_events_received", metrics.Counter); err != nil {
+		return nil, err
+	}
+
+	if m.EventLatency, err = registry.NewMetric("sse_event_latency", metrics.Trend, metrics.Time); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,279 @@
+package sse
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils/httpmultibin"
+	"go.k6.io/k6/metrics"
+)
+
+type testState struct {
+	*modulestest.Runtime
+	tb      *httpmultibin.HTTPMultiBin
+	samples chan metrics.SampleContainer
+}
+
+func newTestState(t testing.TB) testState {
+	t.Helper()
+
+	tb := httpmultibin.NewHTTPMultiBin(t)
+
+	testRuntime := modulestest.NewRuntime(t)
+	samples := make(chan metrics.SampleContainer, 1000)
+
+	logger := logrus.New()
+	logger.Out = io.Discard
+
+	registry := metrics.NewRegistry()
+	state := &lib.State{
+		Dialer:    tb.Dialer,
+		Transport: tb.HTTPTransport,
+		Options: lib.Options{
+			SystemTags: metrics.NewSystemTagSet(
+				metrics.TagURL,
+				metrics.TagStatus,
+			),
+			UserAgent: null.StringFrom("TestUserAgent"),
+		},
+		Logger:         logger,
+		Samples:        samples,
+		TLSConfig:      tb.TLSClientConfig,
+		BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+	}
+
+	m, ok := New().NewModuleInstance(testRuntime.VU).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, testRuntime.VU.RuntimeField.Set("sse", m.Exports().Named))
+	testRuntime.MoveToVUContext(state)
+
+	return testState{
+		Runtime: testRuntime,
+		tb:      tb,
+		samples: samples,
+	}
+}
+
+// writeEvents writes the provided events to an event stream response.
+func writeEvents(t testing.TB, w http.ResponseWriter, events ...string) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+
+	for _, event := range events {
+		_, err := fmt.Fprint(w, event)
+		assert.NoError(t, err)
+
+		w.(http.Flusher).Flush()
+	}
+}
+
+func TestEventSource(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	ts.tb.Mux.HandleFunc("/sse", func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "text/event-stream", req.Header.Get("Accept"))
+
+		writeEvents(t, w, "id: 1\ndata: one\n\n", ": comment\n\n", "event: update\ndata: two\n\n")
+		<-req.Context().Done()
+	})
+
+	_, err := ts.RunOnEventLoop(ts.tb.Replacer.Replace(`
+		const received = [];
+		const es = new sse.EventSource("HTTPBIN_URL/sse");
+		if (es.readyState !== 0) { throw new Error("wrong readyState: " + es.readyState); }
+
+		es.on("open", () => {
+			if (es.readyState !== 1) { throw new Error("wrong readyState: " + es.readyState); }
+			received.push("open");
+		});
+		es.on("message", (e) => received.push(e.type + ":" + e.data + ":" + e.lastEventId));
+		es.on("update", (e) => {
+			received.push(e.type + ":" + e.data + ":" + e.lastEventId);
+			es.close();
+
+			if (es.readyState !== 2) { throw new Error("wrong readyState: " + es.readyState); }
+			if (received.join(",") !== "open,message:one:1,update:two:1") {
+				throw new Error("unexpected events: " + received.join(","));
+			}
+		});
+	`))
+	require.NoError(t, err)
+
+	samples := metrics.GetBufferedSamples(ts.samples)
+	seen := make(map[string]float64)
+	for _, container := range samples {
+		for _, sample := range container.GetSamples() {
+			if sample.Metric.Name == "sse_events_received" || sample.Metric.Name == "sse_connections" {
+				seen[sample.Metric.Name] += sample.Value
+			}
+
+			if sample.Metric.Name == "sse_connecting" {
+				url, _ := sample.Tags.Get("url")
+				assert.Equal(t, ts.tb.Replacer.Replace("HTTPBIN_URL/sse"), url)
+
+				status, _ := sample.Tags.Get("status")
+				assert.Equal(t, "200", status)
+			}
+
+			seen[sample.Metric.Name] += 0
+		}
+	}
+
+	assert.Equal(t, 1.0, seen["sse_connections"])
+	assert.Equal(t, 2.0, seen["sse_events_received"])
+	for _, name := range []string{"sse_connecting", "sse_connection_duration", "sse_event_latency"} {
+		assert.Contains(t, seen, name)
+	}
+}
+
+func TestEventSourceReconnection(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	ts.tb.Mux.HandleFunc("/sse-reconnect", func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Last-Event-ID") == "" {
+			// The connection is closed once the first event is sent.
+			writeEvents(t, w, "retry: 10\nid: 1\ndata: one\n\n")
+			return
+		}
+
+		writeEvents(t, w, fmt.Sprintf("data: %s\n\n", req.Header.Get("Last-Event-ID")))
+		<-req.Context().Done()
+	})
+
+	_, err := ts.RunOnEventLoop(ts.tb.Replacer.Replace(`
+		const received = [];
+		const es = new sse.EventSource("HTTPBIN_URL/sse-reconnect", { reconnectionDelay: "1m" });
+
+		es.on("open", () => received.push("open"));
+		es.on("error", (e) => {
+			if (es.readyState !== 0) { throw new Error("wrong readyState: " + es.readyState); }
+			received.push("error");
+		});
+		es.on("message", (e) => {
+			received.push(e.data);
+			if (received.length < 5) {
+				return;
+			}
+
+			es.close();
+			if (received.join(",") !== "open,one,error,open,1") {
+				throw new Error("unexpected events: " + received.join(","));
+			}
+		});
+	`))
+	require.NoError(t, err)
+
+	var reconnections float64
+	for _, container := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range container.GetSamples() {
+			if sample.Metric.Name == "sse_reconnections" {
+				reconnections += sample.Value
+			}
+		}
+	}
+	assert.Equal(t, 1.0, reconnections)
+}
+
+func TestEventSourceFailure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr string
+	}{
+		{
+			name: "status",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			wantErr: "the server responded with the unexpected status 204",
+		},
+		{
+			name: "content type",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte("{}"))
+			},
+			wantErr: `the server responded with the unexpected content type "application/json"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestState(t)
+			ts.tb.Mux.HandleFunc("/sse-failure", tt.handler)
+
+			_, err := ts.RunOnEventLoop(ts.tb.Replacer.Replace(`
+				const es = new sse.EventSource("HTTPBIN_URL/sse-failure");
+				es.on("open", () => { throw new Error("unexpected open event"); });
+				es.on("error", (e) => {
+					if (es.readyState !== 2) { throw new Error("wrong readyState: " + es.readyState); }
+					throw new Error(e.error);
+				});
+			`))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestEventSourceParams(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	ts.tb.Mux.HandleFunc("/sse-params", func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+
+		writeEvents(t, w, fmt.Sprintf("data: %s %s %s\n\n", req.Method, req.Header.Get("Authorization"), body))
+		<-req.Context().Done()
+	})
+
+	_, err := ts.RunOnEventLoop(ts.tb.Replacer.Replace(`
+		const es = new sse.EventSource("HTTPBIN_URL/sse-params", {
+			method: "POST",
+			body: '{"prompt":"hello"}',
+			headers: { Authorization: "Bearer token" },
+			tags: { tag: "value" },
+		});
+
+		es.on("message", (e) => {
+			es.close();
+			if (e.data !== 'POST Bearer token {"prompt":"hello"}') {
+				throw new Error("unexpected data: " + e.data);
+			}
+		});
+	`))
+	require.NoError(t, err)
+
+	for _, container := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range container.GetSamples() {
+			tag, _ := sample.Tags.Get("tag")
+			assert.Equal(t, "value", tag)
+		}
+	}
+
+	_, err = ts.RunOnEventLoop(`new sse.EventSource("ws://example.com")`)
+	require.ErrorContains(t, err, `EventSource requires url with scheme http or https, but got "ws"`)
+
+	_, err = ts.RunOnEventLoop(`new sse.EventSource("http://example.com", { unknown: true })`)
+	require.ErrorContains(t, err, "unknown EventSource's option unknown")
+}
@@ -0,0 +1,184 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLineSize is the size of the longest line of an event stream that can be
+// parsed.
+const maxLineSize = 16 << 20
+
+// Event is an event received from an event stream.
+type Event struct {
+	// Type holds the type of the event, "message" unless the server set it
+	// through the `event` field.
+	Type string `js:"type"`
+
+	// Data holds the data of the event, the `data` fields of which are joined
+	// by line feeds.
+	Data string `js:"data"`
+
+	// LastEventID holds the last event ID the server set through the `id`
+	// field, either for this event or a previous one.
+	LastEventID string `js:"lastEventId"`
+}
+
+// eventStreamParser parses a stream of events, as described by the
+// `text/event-stream` format of https://html.spec.whatwg.org/multipage/server-sent-events.html.
+type eventStreamParser struct {
+	scanner *bufio.Scanner
+
+	// skipLF indicates whether a line feed immediately following the carriage
+	// return ending the previous line belongs to that line's ending.
+	skipLF bool
+
+	// started indicates whether the first line has been read, so that a
+	// leading byte order mark is skipped only there.
+	started bool
+
+	eventType   string
+	data        strings.Builder
+	lastEventID string
+
+	// retry holds the reconnection time the server set through the `retry`
+	// field, or zero if it didn't.
+	retry time.Duration
+}
+
+// newEventStreamParser creates a new [eventStreamParser] parsing the provided
+// stream, and holding the given last event ID until the stream sets one.
+func newEventStreamParser(r io.Reader, lastEventID string) *eventStreamParser {
+	p := &eventStreamParser{lastEventID: lastEventID}
+
+	p.scanner = bufio.NewScanner(r)
+	p.scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	p.scanner.Split(p.splitLines)
+
+	return p
+}
+
+// next returns the next event of the stream, or io.EOF once the stream ends.
+//
+// An event still incomplete when the stream ends is discarded.
+func (p *eventStreamParser) next() (Event, error) {
+	for p.scanner.Scan() {
+		line := p.scanner.Text()
+
+		if !p.started {
+			p.started = true
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+
+		if line == "" {
+			if event, ok := p.dispatch(); ok {
+				return event, nil
+			}
+
+			continue
+		}
+
+		p.processLine(line)
+	}
+
+	if err := p.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+
+	return Event{}, io.EOF
+}
+
+// processLine processes a non-empty line of the stream.
+func (p *eventStreamParser) processLine(line string) {
+	// Lines starting with a colon are comments.
+	field, value, found := strings.Cut(line, ":")
+	if found && field == "" {
+		return
+	}
+
+	value = strings.TrimPrefix(value, " ")
+
+	switch field {
+	case "event":
+		p.eventType = value
+	case "data":
+		p.data.WriteString(value)
+		p.data.WriteByte('\n')
+	case "id":
+		if !strings.ContainsRune(value, 0) {
+			p.lastEventID = value
+		}
+	case "retry":
+		if ms, err := strconv.ParseUint(value, 10, 32); isDigits(value) && err == nil {
+			p.retry = time.Duration(ms) * time.Millisecond
+		}
+	}
+}
+
+// dispatch returns the event the lines processed since the last one describe,
+// and resets the event's buffers. It returns false if the event has no data,
+// in which case it is not to be dispatched.
+func (p *eventStreamParser) dispatch() (Event, bool) {
+	defer func() {
+		p.eventType = ""
+		p.data.Reset()
+	}()
+
+	if p.data.Len() == 0 {
+		return Event{}, false
+	}
+
+	event := Event{
+		Type:        p.eventType,
+		Data:        strings.TrimSuffix(p.data.String(), "\n"),
+		LastEventID: p.lastEventID,
+	}
+
+	if event.Type == "" {
+		event.Type = eventMessage
+	}
+
+	return event, true
+}
+
+// splitLines is a [bufio.SplitFunc] splitting the stream in lines ending with
+// either a carriage return, a line feed, or both.
+//
+// A line ending with a carriage return is returned as soon as it is read, so
+// that an event is not held until the following byte is received.
+func (p *eventStreamParser) splitLines(data []byte, atEOF bool) (int, []byte, error) {
+	if p.skipLF && len(data) > 0 {
+		p.skipLF = false
+
+		if data[0] == '\n' {
+			return 1, nil, nil
+		}
+	}
+
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		p.skipLF = data[i] == '\r'
+
+		return i + 1, data[:i], nil
+	}
+
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+
+	return 0, nil, nil
+}
+
+// isDigits returns whether the provided string only holds ASCII digits.
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return s != ""
+}
@@ -0,0 +1,115 @@
+package sse
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStreamParser(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		stream      string
+		lastEventID string
+		wantEvents  []Event
+		wantRetry   time.Duration
+	}{
+		{
+			name:       "message",
+			stream:     "data: hello\n\n",
+			wantEvents: []Event{{Type: "message", Data: "hello"}},
+		},
+		{
+			name:   "multiple data lines are joined",
+			stream: "data: first\ndata:second\ndata\n\n",
+			wantEvents: []Event{
+				{Type: "message", Data: "first\nsecond\n"},
+			},
+		},
+		{
+			name:   "event type and id",
+			stream: "event: update\nid: 1\ndata: one\n\ndata: two\n\nid\ndata: three\n\n",
+			wantEvents: []Event{
+				{Type: "update", Data: "one", LastEventID: "1"},
+				{Type: "message", Data: "two", LastEventID: "1"},
+				{Type: "message", Data: "three", LastEventID: ""},
+			},
+		},
+		{
+			name:        "last event id is kept until set",
+			stream:      "data: one\n\nid: 2\ndata: two\n\n",
+			lastEventID: "1",
+			wantEvents: []Event{
+				{Type: "message", Data: "one", LastEventID: "1"},
+				{Type: "message", Data: "two", LastEventID: "2"},
+			},
+		},
+		{
+			name:       "ids holding null characters are ignored",
+			stream:     "id: 1\x002\ndata: one\n\n",
+			wantEvents: []Event{{Type: "message", Data: "one"}},
+		},
+		{
+			name:       "comments and unknown fields are ignored",
+			stream:     ": keep-alive\nfoo: bar\ndata: one\n\n",
+			wantEvents: []Event{{Type: "message", Data: "one"}},
+		},
+		{
+			name:       "events without data are not dispatched",
+			stream:     "event: ping\n\ndata: one\n\n",
+			wantEvents: []Event{{Type: "message", Data: "one"}},
+		},
+		{
+			name:       "carriage return line endings",
+			stream:     "data: one\r\rdata: two\r\n\r\n",
+			wantEvents: []Event{{Type: "message", Data: "one"}, {Type: "message", Data: "two"}},
+		},
+		{
+			name:       "leading byte order mark",
+			stream:     "\uFEFFdata: one\n\n",
+			wantEvents: []Event{{Type: "message", Data: "one"}},
+		},
+		{
+			name:       "incomplete events are discarded",
+			stream:     "data: one\n\ndata: two\n",
+			wantEvents: []Event{{Type: "message", Data: "one"}},
+		},
+		{
+			name:       "retry",
+			stream:     "retry: 1500\ndata: one\n\nretry: 1s\n\n",
+			wantEvents: []Event{{Type: "message", Data: "one"}},
+			wantRetry:  1500 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := newEventStreamParser(strings.NewReader(tt.stream), tt.lastEventID)
+
+			var gotEvents []Event
+			for {
+				event, err := p.next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+
+				gotEvents = append(gotEvents, event)
+			}
+
+			assert.Equal(t, tt.wantEvents, gotEvents)
+			assert.Equal(t, tt.wantRetry, p.retry)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// Package store provides a k6 module with a key-value store that is shared by
+// all VUs of a k6 instance, so they can coordinate between each other, e.g.
+// to allocate unique IDs or to share authentication tokens.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+)
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the store module for a single VU.
+	ModuleInstance struct {
+		vu modules.VU
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+//nolint:gochecknoglobals
+var initContextErr = common.NewInitContextError("using the store in the init context is not supported")
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"get":            mi.get,
+			"set":            mi.set,
+			"incr":           mi.incr,
+			"compareAndSwap": mi.compareAndSwap,
+			"delete":         mi.delete,
+		},
+	}
+}
+
+// get returns the value of the key, or undefined if it doesn't exist.
+func (mi *ModuleInstance) get(key string) sobek.Value {
+	rt, store := mi.checkVUContext()
+
+	data, ok := store.Get(key)
+	if !ok {
+		return sobek.Undefined()
+	}
+	return mi.decode(rt, data)
+}
+
+// set sets the value of the key, optionally with a TTL.
+func (mi *ModuleInstance) set(key string, value sobek.Value, options sobek.Value) {
+	rt, store := mi.checkVUContext()
+	data := mi.encode(rt, key, value)
+	ttl := mi.parseTTL(rt, options)
+
+	store.Set(key, data, ttl)
+}
+
+// incr atomically adds delta (1 by default) to the integer value of the key
+// and returns the result. Keys that don't exist are treated as 0. The TTL of
+// the key, if it had any, is kept.
+func (mi *ModuleInstance) incr(key string, delta sobek.Value) int64 {
+	rt, store := mi.checkVUContext()
+	by := int64(1)
+	if !common.IsNullish(delta) {
+		by = delta.ToInteger()
+	}
+
+	var current int64
+	_, err := store.Update(key, func(data []byte, exists bool) ([]byte, error) {
+		if exists {
+			var number float64
+			if err := json.Unmarshal(data, &number); err != nil || number != math.Trunc(number) {
+				return nil, fmt.Errorf("the value of the %q key isn't an integer", key)
+			}
+			current = int64(number)
+		}
+		current += by
+		return []byte(fmt.Sprint(current)), nil
+	})
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	return current
+}
+
+// compareAndSwap atomically sets the value of the key to newValue, only if its
+// current value is equal to expected. An undefined or null expected value
+// means that the key shouldn't exist. It returns whether the value was set.
+func (mi *ModuleInstance) compareAndSwap(key string, expected, newValue sobek.Value, options sobek.Value) bool {
+	rt, store := mi.checkVUContext()
+	var expectedData []byte
+	if !common.IsNullish(expected) {
+		expectedData = mi.encode(rt, key, expected)
+	}
+	data := mi.encode(rt, key, newValue)
+	ttl := mi.parseTTL(rt, options)
+
+	return store.CompareAndSwap(key, expectedData, data, ttl)
+}
+
+// delete removes the key and returns whether it existed.
+func (mi *ModuleInstance) delete(key string) bool {
+	_, store := mi.checkVUContext()
+	return store.Delete(key)
+}
+
+// checkVUContext returns the runtime and the store, which is the one of the
+// execution state, shared with the test store of the k6/execution module.
+func (mi *ModuleInstance) checkVUContext() (*sobek.Runtime, *lib.SharedStore) {
+	rt := mi.vu.Runtime()
+	if mi.vu.State() == nil {
+		common.Throw(rt, initContextErr)
+	}
+	es := lib.GetExecutionState(mi.vu.Context())
+	if es == nil {
+		common.Throw(rt, initContextErr)
+	}
+	return rt, es.SharedStore()
+}
+
+func (mi *ModuleInstance) encode(rt *sobek.Runtime, key string, value sobek.Value) []byte {
+	if common.IsNullish(value) {
+		common.Throw(rt, fmt.Errorf("the value of the %q key can't be undefined or null", key))
+	}
+	data, err := json.Marshal(value.Export())
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("the value of the %q key can't be encoded to JSON: %w", key, err))
+	}
+	return data
+}
+
+func (mi *ModuleInstance) decode(rt *sobek.Runtime, data []byte) sobek.Value {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		common.Throw(rt, err)
+	}
+	return rt.ToValue(value)
+}
+
+func (mi *ModuleInstance) parseTTL(rt *sobek.Runtime, options sobek.Value) time.Duration {
+	if common.IsNullish(options) {
+		return 0
+	}
+	ttl := options.ToObject(rt).Get("ttl")
+	if common.IsNullish(ttl) {
+		return 0
+	}
+	d, err := types.GetDurationValue(ttl.Export())
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid ttl: %w", err))
+	}
+	if d <= 0 {
+		common.Throw(rt, errors.New("the ttl should be positive"))
+	}
+	return d
+}
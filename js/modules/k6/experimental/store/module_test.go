@@ -0,0 +1,208 @@
+package store
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+)
+
+func newExecutionState(t *testing.T) *lib.ExecutionState {
+	t.Helper()
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	return lib.NewExecutionState(nil, et, 0, 0)
+}
+
+func newStoreRuntime(t *testing.T, es *lib.ExecutionState) *modulestest.Runtime {
+	t.Helper()
+
+	runtime := modulestest.NewRuntime(t)
+	runtime.VU.CtxField = lib.WithExecutionState(runtime.VU.CtxField, es)
+	err := runtime.SetupModuleSystem(map[string]any{"k6/experimental/store": New()}, nil, nil)
+	require.NoError(t, err)
+	_, err = runtime.VU.Runtime().RunString(`var store = require("k6/experimental/store");`)
+	require.NoError(t, err)
+	runtime.MoveToVUContext(&lib.State{})
+
+	return runtime
+}
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"get and set": `
+			if (store.get("missing") !== undefined) throw new Error("missing key should be undefined");
+			store.set("user", { name: "admin", roles: ["a", "b"] });
+			const user = store.get("user");
+			if (user.name !== "admin" || user.roles[1] !== "b") throw new Error("bad user " + JSON.stringify(user));
+			user.name = "changed";
+			if (store.get("user").name !== "admin") throw new Error("stored value was modified");
+		`,
+		"incr": `
+			if (store.incr("counter") !== 1) throw new Error("bad first incr");
+			if (store.incr("counter", 10) !== 11) throw new Error("bad second incr");
+			if (store.incr("counter", -20) !== -9) throw new Error("bad third incr");
+			if (store.get("counter") !== -9) throw new Error("bad counter " + store.get("counter"));
+		`,
+		"compareAndSwap": `
+			if (!store.compareAndSwap("token", null, "first")) throw new Error("swapping a missing key failed");
+			if (store.compareAndSwap("token", null, "second")) throw new Error("swapping an existing key succeeded");
+			if (store.compareAndSwap("token", "wrong", "second")) throw new Error("swapping with a wrong value succeeded");
+			if (!store.compareAndSwap("token", "first", "second")) throw new Error("swapping with the current value failed");
+			if (store.get("token") !== "second") throw new Error("bad token " + store.get("token"));
+			store.set("obj", { a: [1, 2] });
+			if (!store.compareAndSwap("obj", { a: [1, 2] }, 3)) throw new Error("swapping an object failed");
+		`,
+		"delete": `
+			store.set("key", 1);
+			if (!store.delete("key")) throw new Error("deleting an existing key failed");
+			if (store.delete("key")) throw new Error("deleting a missing key succeeded");
+			if (store.get("key") !== undefined) throw new Error("deleted key should be undefined");
+		`,
+	}
+
+	for name, code := range cases {
+		code := code
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := newStoreRuntime(t, newExecutionState(t))
+			_, err := runtime.VU.Runtime().RunString(code)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestStoreExceptions(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		code, err string
+	}{
+		"undefined value": {
+			code: `store.set("key")`,
+			err:  `the value of the "key" key can't be undefined or null`,
+		},
+		"incr of a string": {
+			code: `store.set("key", "1"); store.incr("key")`,
+			err:  `the value of the "key" key isn't an integer`,
+		},
+		"incr of a float": {
+			code: `store.set("key", 1.5); store.incr("key")`,
+			err:  `the value of the "key" key isn't an integer`,
+		},
+		"invalid ttl": {
+			code: `store.set("key", 1, { ttl: "forever" })`,
+			err:  "invalid ttl",
+		},
+		"negative ttl": {
+			code: `store.set("key", 1, { ttl: -1 })`,
+			err:  "the ttl should be positive",
+		},
+	}
+
+	for name, testCase := range cases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := newStoreRuntime(t, newExecutionState(t))
+			_, err := runtime.VU.Runtime().RunString(testCase.code)
+			require.Error(t, err)
+			var exc *sobek.Exception
+			require.ErrorAs(t, err, &exc)
+			assert.Contains(t, exc.Error(), testCase.err)
+		})
+	}
+}
+
+func TestStoreNotInInitContext(t *testing.T) {
+	t.Parallel()
+
+	runtime := modulestest.NewRuntime(t)
+	err := runtime.SetupModuleSystem(map[string]any{"k6/experimental/store": New()}, nil, nil)
+	require.NoError(t, err)
+
+	_, err = runtime.VU.Runtime().RunString(`require("k6/experimental/store").get("key")`)
+	require.ErrorContains(t, err, "using the store in the init context is not supported")
+}
+
+func TestStoreTTL(t *testing.T) {
+	t.Parallel()
+
+	runtime := newStoreRuntime(t, newExecutionState(t))
+	_, err := runtime.VU.Runtime().RunString(`
+		store.set("short", 1, { ttl: 1 });
+		store.set("long", 1, { ttl: "1h" });
+	`)
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+	_, err = runtime.VU.Runtime().RunString(`
+		if (store.get("short") !== undefined) throw new Error("short should have expired");
+		if (store.get("long") !== 1) throw new Error("long shouldn't have expired");
+	`)
+	require.NoError(t, err)
+}
+
+func TestStoreSharedWithTestStore(t *testing.T) {
+	t.Parallel()
+
+	es := newExecutionState(t)
+	es.SetSharedData("token", []byte(`{"value":"secret"}`))
+	runtime := newStoreRuntime(t, es)
+
+	_, err := runtime.VU.Runtime().RunString(`
+		if (store.get("token").value !== "secret") throw new Error("bad token " + JSON.stringify(store.get("token")));
+		store.set("id", 42);
+	`)
+	require.NoError(t, err)
+
+	data, ok := es.GetSharedData("id")
+	require.True(t, ok)
+	assert.Equal(t, "42", string(data))
+}
+
+func TestStoreIsShared(t *testing.T) {
+	t.Parallel()
+
+	const vus, iterations = 5, 100
+	es := newExecutionState(t)
+	runtimes := make([]*modulestest.Runtime, vus)
+	for i := range runtimes {
+		runtimes[i] = newStoreRuntime(t, es)
+	}
+
+	wg := &sync.WaitGroup{}
+	for _, runtime := range runtimes {
+		runtime := runtime
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := runtime.VU.Runtime().RunString(`
+				for (let i = 0; i < 100; i++) {
+					store.incr("counter");
+					let current;
+					do {
+						current = store.get("list") || [];
+					} while (!store.compareAndSwap("list", current.length ? current : null, current.concat([i])));
+				}
+			`)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	v, err := runtimes[0].VU.Runtime().RunString(`[store.get("counter"), store.get("list").length]`)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(vus * iterations), int64(vus * iterations)}, v.Export())
+}
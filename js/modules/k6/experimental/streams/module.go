@@ -336,3 +336,96 @@ func underlyingSourceFromReader(vu modules.VU, reader io.Reader) *sobek.Object {
 
 	return underlyingSource
 }
+
+// readCloserChunkSize is the maximum size of the chunks a [ReadableStream] created
+// by [NewReadableStreamFromReadCloser] enqueues.
+const readCloserChunkSize = 64 * 1024
+
+// NewReadableStreamFromReadCloser initializes a new [ReadableStream] from a given
+// [io.ReadCloser] in Go code, such as the body of an HTTP response.
+//
+// As opposed to [NewReadableStreamFromReader], the reader is read in a background
+// goroutine, so that the event loop is not blocked while waiting for data, and its
+// chunks are enqueued as Uint8Array instances, which makes it suitable to binary
+// data. The reader is closed once it has been entirely read, fails to be, or the
+// stream is canceled; reading errors are surfaced by erroring the stream.
+func NewReadableStreamFromReadCloser(vu modules.VU, rc io.ReadCloser) *sobek.Object {
+	rt := vu.Runtime()
+	return newReadableStream(vu, sobek.ConstructorCall{
+		Arguments: []sobek.Value{rt.ToValue(underlyingSourceFromReadCloser(vu, rc))},
+		This:      rt.NewObject(),
+	})
+}
+
+func underlyingSourceFromReadCloser(vu modules.VU, rc io.ReadCloser) *sobek.Object {
+	rt := vu.Runtime()
+
+	// canceled indicates whether the stream has been canceled, in which case
+	// the controller cannot be used anymore. It is only accessed from the event loop.
+	canceled := false
+
+	underlyingSource := rt.NewObject()
+	if err := underlyingSource.Set("pull", rt.ToValue(func(controller *sobek.Object) *sobek.Promise {
+		promise, resolve, reject := rt.NewPromise()
+		callback := vu.RegisterCallback()
+
+		go func() {
+			buf := make([]byte, readCloserChunkSize)
+			n, err := rc.Read(buf)
+			callback(func() error {
+				if canceled {
+					resolve(sobek.Undefined())
+					return nil
+				}
+
+				call := func(method string, args ...sobek.Value) error {
+					fn, _ := sobek.AssertFunction(controller.Get(method))
+					_, err := fn(controller, args...)
+					return err
+				}
+
+				var callErr error
+				if n > 0 {
+					chunk, err := rt.New(rt.Get("Uint8Array"), rt.ToValue(rt.NewArrayBuffer(buf[:n])))
+					if err != nil {
+						callErr = err
+					} else {
+						callErr = call("enqueue", chunk)
+					}
+				}
+
+				switch {
+				case callErr != nil:
+				case errors.Is(err, io.EOF):
+					_ = rc.Close()
+					callErr = call("close")
+				case err != nil:
+					_ = rc.Close()
+					callErr = call("error", rt.NewGoError(err))
+				}
+
+				if callErr != nil {
+					reject(callErr)
+					return nil
+				}
+
+				resolve(sobek.Undefined())
+				return nil
+			})
+		}()
+
+		return promise
+	})); err != nil {
+		throw(rt, err)
+	}
+
+	if err := underlyingSource.Set("cancel", rt.ToValue(func(sobek.Value) *sobek.Promise {
+		canceled = true
+		_ = rc.Close()
+		return newResolvedPromise(vu, sobek.Undefined())
+	})); err != nil {
+		throw(rt, err)
+	}
+
+	return underlyingSource
+}
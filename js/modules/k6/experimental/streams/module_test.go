@@ -2,7 +2,10 @@ package streams
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"testing"
+	"testing/iotest"
 
 	"github.com/grafana/sobek"
 	"github.com/stretchr/testify/assert"
@@ -39,3 +42,92 @@ func TestNewReadableStreamFromReader(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, exp, p.Result().String())
 }
+
+func TestNewReadableStreamFromReadCloser(t *testing.T) {
+	t.Parallel()
+
+	t.Run("chunks", func(t *testing.T) {
+		t.Parallel()
+
+		// The value to be streamed, spanning several chunks.
+		exp := bytes.Repeat([]byte("0123456789"), readCloserChunkSize/4)
+
+		r := modulestest.NewRuntime(t)
+		rc := &closeRecorder{Reader: bytes.NewReader(exp)}
+		rs := NewReadableStreamFromReadCloser(r.VU, rc)
+		require.NoError(t, r.VU.Runtime().Set("rs", rs))
+
+		var ret sobek.Value
+		err := r.EventLoop.Start(func() (err error) {
+			ret, err = r.VU.Runtime().RunString(`(async () => {
+  const reader = rs.getReader();
+  let chunks = 0;
+  let received = [];
+  while (true) {
+    const {done, value} = await reader.read();
+    if (done) {
+      break;
+    }
+    if (!(value instanceof Uint8Array)) {
+      throw new Error("unexpected chunk " + value);
+    }
+    chunks++;
+    received.push(...value);
+  }
+  return {chunks, received: new Uint8Array(received).buffer};
+})()`)
+			return err
+		})
+		require.NoError(t, err)
+
+		p, ok := ret.Export().(*sobek.Promise)
+		require.True(t, ok)
+		require.Equal(t, sobek.PromiseStateFulfilled, p.State(), p.Result())
+
+		result := p.Result().ToObject(r.VU.Runtime())
+		assert.Equal(t, int64(3), result.Get("chunks").ToInteger())
+		assert.Equal(t, exp, result.Get("received").Export().(sobek.ArrayBuffer).Bytes())
+		assert.True(t, rc.closed)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		r := modulestest.NewRuntime(t)
+		rc := &closeRecorder{Reader: iotest.ErrReader(errors.New("connection reset"))}
+		rs := NewReadableStreamFromReadCloser(r.VU, rc)
+		require.NoError(t, r.VU.Runtime().Set("rs", rs))
+
+		var ret sobek.Value
+		err := r.EventLoop.Start(func() (err error) {
+			ret, err = r.VU.Runtime().RunString(`(async () => {
+  const reader = rs.getReader();
+  try {
+    await reader.read();
+  } catch (e) {
+    return String(e);
+  }
+  throw new Error("expected the read to fail");
+})()`)
+			return err
+		})
+		require.NoError(t, err)
+
+		p, ok := ret.Export().(*sobek.Promise)
+		require.True(t, ok)
+		require.Equal(t, sobek.PromiseStateFulfilled, p.State(), p.Result())
+		assert.Contains(t, p.Result().String(), "connection reset")
+		assert.True(t, rc.closed)
+	})
+}
+
+// closeRecorder is an io.ReadCloser recording whether it has been closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (cr *closeRecorder) Close() error {
+	cr.closed = true
+	return nil
+}
@@ -93,6 +93,10 @@ func (mi *ModuleInstance) newClient(cc sobek.ConstructorCall) *sobek.Object {
 //
 // When used in the context of a k6 script, it will automatically replace
 // the imported http module's methods with instrumented ones.
+//
+// The tracePropagator option propagates a trace context with all the HTTP and
+// gRPC requests, without instrumenting the http module. The requests this
+// instrumentation adds a trace context to are left as they are by the option.
 func (mi *ModuleInstance) instrumentHTTP(options sobek.Value) {
 	rt := mi.vu.Runtime()
 
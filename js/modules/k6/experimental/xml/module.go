@@ -0,0 +1,352 @@
+// Package xml provides a k6 module that allows users to parse XML, and HTML,
+// documents in a streaming fashion, as a sequence of events, rather than
+// holding their whole tree in memory.
+package xml
+
+import (
+	"bytes"
+	goxml "encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/modules/k6/experimental/fs"
+)
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU.
+	RootModule struct{}
+
+	// ModuleInstance represents an instance of the xml module for a single VU.
+	ModuleInstance struct {
+		vu modules.VU
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]any{
+			"Parser": mi.NewParser,
+		},
+	}
+}
+
+const (
+	// eventStartElement is the type of the events emitted for start tags.
+	eventStartElement = "startElement"
+
+	// eventEndElement is the type of the events emitted for end tags,
+	// including the ones implied by self-closing tags.
+	eventEndElement = "endElement"
+
+	// eventText is the type of the events emitted for character data,
+	// CDATA sections included.
+	eventText = "text"
+
+	// eventComment is the type of the events emitted for comments.
+	eventComment = "comment"
+)
+
+// Parser is a streaming, event-based, XML parser.
+type Parser struct {
+	// decoder reads the tokens of the provided document.
+	decoder *goxml.Decoder
+
+	// mu serializes the reads, which happen in background goroutines.
+	mu sync.Mutex
+
+	// err holds the error the parser stopped at, io.EOF once the whole
+	// document has been read.
+	err error
+
+	// options holds the parser's options as provided by the user.
+	options parserOptions
+
+	// vu is the VU instance that owns this module instance.
+	vu modules.VU
+}
+
+// event holds one of the events a [Parser] emits, as read from the document.
+type event struct {
+	typ       string
+	name      goxml.Name
+	attrs     []goxml.Attr
+	text      string
+	line, col int
+}
+
+// parseResult holds the result of an XML parser's parsing operation such as
+// when calling the [Parser.Next] method.
+type parseResult struct {
+	// Done indicates whether the parser has finished reading the document.
+	Done bool `js:"done"`
+
+	// Value holds the event.
+	Value sobek.Value `js:"value"`
+}
+
+// NewParser creates a new XML parser instance, reading either a file opened
+// through the [fs] module, or a document held by a string or an ArrayBuffer,
+// such as the body of an HTTP response.
+//
+// As opposed to the parsers of the other experimental modules, it can be
+// constructed outside of the init context, so that response bodies can be
+// parsed. Like them, it is async iterable, each iteration resolving to the
+// result [Parser.Next] would.
+func (mi *ModuleInstance) NewParser(call sobek.ConstructorCall) *sobek.Object {
+	rt := mi.vu.Runtime()
+
+	if len(call.Arguments) < 1 || common.IsNullish(call.Argument(0)) {
+		common.Throw(rt, errors.New("xml Parser constructor takes at least one non-nil source argument"))
+	}
+
+	r, err := newReaderFrom(rt, call.Argument(0))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	options := newDefaultParserOptions()
+	if len(call.Arguments) > 1 && !common.IsNullish(call.Argument(1)) {
+		options, err = newParserOptionsFrom(call.Argument(1).ToObject(rt))
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("encountered an error while interpreting Parser options; reason: %w", err))
+		}
+	}
+
+	decoder := goxml.NewDecoder(r)
+	if options.HTML {
+		decoder.Strict = false
+		decoder.AutoClose = goxml.HTMLAutoClose
+		decoder.Entity = goxml.HTMLEntity
+	}
+
+	parser := &Parser{
+		decoder: decoder,
+		options: options,
+		vu:      mi.vu,
+	}
+
+	obj := rt.ToValue(parser).ToObject(rt)
+	common.MakeAsyncIterable(rt, obj, parser.Next)
+
+	return obj
+}
+
+// newReaderFrom returns a reader of the document the provided source value,
+// either a [fs.File], a string, or an ArrayBuffer, holds.
+func newReaderFrom(rt *sobek.Runtime, v sobek.Value) (io.Reader, error) {
+	switch exported := v.Export().(type) {
+	case string:
+		return strings.NewReader(exported), nil
+	case sobek.ArrayBuffer:
+		// The content is copied, so that it is not modified while being parsed.
+		return bytes.NewReader(append([]byte(nil), exported.Bytes()...)), nil
+	}
+
+	var file *fs.File
+	if err := rt.ExportTo(v, &file); err != nil || file == nil || file.Impl == nil {
+		return nil, fmt.Errorf(
+			"first argument expected to be a fs.File instance, a string, or an ArrayBuffer, got %T instead", v,
+		)
+	}
+
+	return file.Impl, nil
+}
+
+// Next returns a promise resolving to the next event of the document, an
+// object which `type` property is one of:
+//   - "startElement", with `name`, `namespace`, and `attributes` properties,
+//     the latter mapping the local names of the element's attributes to their
+//     values, namespace declarations excluded;
+//   - "endElement", with `name` and `namespace` properties;
+//   - "text", with a `text` property, holding the character data;
+//   - "comment", with a `text` property, holding the comment's content.
+//
+// The element names are local names, and their namespace, the URI their prefix
+// is bound to, if any. The events also hold the `line` and `column` of the
+// document they were read at.
+//
+// Tokens are read in a background goroutine, only a single one being held in
+// memory at a time, which makes the parser suitable to documents too large to
+// be parsed at once, such as large sitemaps or SOAP responses.
+//
+// Once the end of the document has been reached, the promise resolves to a
+// result which `done` property is set to true. The promise is rejected if the
+// document is malformed.
+func (p *Parser) Next() *sobek.Promise {
+	rt := p.vu.Runtime()
+	promise, resolve, reject := rt.NewPromise()
+	callback := p.vu.RegisterCallback()
+
+	go func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		ev, err := p.next()
+
+		callback(func() error {
+			if errors.Is(err, io.EOF) {
+				resolve(parseResult{Done: true, Value: sobek.Undefined()})
+				return nil
+			}
+
+			if err != nil {
+				reject(err)
+				return nil
+			}
+
+			resolve(parseResult{Done: false, Value: ev.toObject(rt)})
+			return nil
+		})
+	}()
+
+	return promise
+}
+
+// next reads the next event of the document, skipping the tokens that are not
+// emitted as events, such as processing instructions and directives.
+func (p *Parser) next() (event, error) {
+	for p.err == nil {
+		line, col := p.decoder.InputPos()
+
+		token, err := p.decoder.Token()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				err = fmt.Errorf("failed to parse the document; reason: %w", err)
+			}
+
+			p.err = err
+			break
+		}
+
+		ev := event{line: line, col: col}
+
+		switch t := token.(type) {
+		case goxml.StartElement:
+			ev.typ, ev.name, ev.attrs = eventStartElement, t.Name, t.Attr
+		case goxml.EndElement:
+			ev.typ, ev.name = eventEndElement, t.Name
+		case goxml.CharData:
+			if p.options.SkipWhitespace && len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+
+			ev.typ, ev.text = eventText, string(t)
+		case goxml.Comment:
+			ev.typ, ev.text = eventComment, string(t)
+		default:
+			continue
+		}
+
+		return ev, nil
+	}
+
+	return event{}, p.err
+}
+
+// toObject returns the JS object representing the event.
+//
+// It must be called from the event loop.
+func (ev event) toObject(rt *sobek.Runtime) *sobek.Object {
+	obj := rt.NewObject()
+
+	set := func(name string, value any) {
+		if err := obj.Set(name, value); err != nil {
+			common.Throw(rt, err)
+		}
+	}
+
+	set("type", ev.typ)
+	set("line", ev.line)
+	set("column", ev.col)
+
+	switch ev.typ {
+	case eventStartElement, eventEndElement:
+		set("name", ev.name.Local)
+		set("namespace", ev.name.Space)
+	default:
+		set("text", ev.text)
+	}
+
+	if ev.typ == eventStartElement {
+		attributes := rt.NewObject()
+		for _, attr := range ev.attrs {
+			if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+				continue
+			}
+
+			if err := attributes.Set(attr.Name.Local, attr.Value); err != nil {
+				common.Throw(rt, err)
+			}
+		}
+
+		set("attributes", attributes)
+	}
+
+	return obj
+}
+
+// parserOptions holds options used to configure XML parsing when utilizing
+// the module.
+//
+// The options can be set by the user when instantiating a new [Parser].
+type parserOptions struct {
+	// HTML indicates whether the document should be parsed leniently, as HTML:
+	// the end tags of void elements, such as `br`, are implied, unknown or
+	// malformed entities are kept as is, and the HTML entities are resolved.
+	HTML bool `js:"html"`
+
+	// SkipWhitespace indicates whether the text made of whitespace only, such
+	// as the indentation between elements, should be skipped.
+	SkipWhitespace bool `js:"skipWhitespace"`
+}
+
+// newDefaultParserOptions creates a new parserOptions instance with default values.
+func newDefaultParserOptions() parserOptions {
+	return parserOptions{}
+}
+
+// newParserOptionsFrom creates a new parserOptions instance from the given
+// Sobek object.
+func newParserOptionsFrom(obj *sobek.Object) (parserOptions, error) {
+	options := newDefaultParserOptions()
+
+	if obj == nil {
+		return options, nil
+	}
+
+	if v := obj.Get("html"); v != nil {
+		options.HTML = v.ToBoolean()
+	}
+
+	if v := obj.Get("skipWhitespace"); v != nil {
+		options.SkipWhitespace = v.ToBoolean()
+	}
+
+	return options, nil
+}
@@ -0,0 +1,287 @@
+package xml
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/compiler"
+	"go.k6.io/k6/js/modules/k6/experimental/fs"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib/fsext"
+)
+
+// testFilePath holds the path to the XML file used in the tests.
+const testFilePath = fsext.FilePathSeparator + "sitemap.xml"
+
+const testXML = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <!-- pages -->
+  <url><loc>https://example.com/</loc><priority>1.0</priority></url>
+  <url><loc>https://example.com/a?x=1&amp;y=2</loc></url>
+</urlset>
+`
+
+// collectEvents is the script collecting the events of the parser in the `got`
+// array, one string per event.
+const collectEvents = `
+	let got = [];
+	let { done, value } = await parser.next();
+	while (!done) {
+		switch (value.type) {
+		case "startElement":
+			got.push("s:" + (value.namespace ? "{" + value.namespace + "}" : "") + value.name + JSON.stringify(value.attributes));
+			break;
+		case "endElement":
+			got.push("e:" + value.name);
+			break;
+		case "text":
+			got.push("t:" + value.text);
+			break;
+		case "comment":
+			got.push("c:" + value.text);
+			break;
+		}
+		({ done, value } = await parser.next());
+	}
+`
+
+func TestParserConstructor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("constructing a parser from a file should succeed", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		require.NoError(t, writeTestFile(r, testFilePath, testXML))
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+			const file = await fs.open(%q);
+			const parser = new xml.Parser(file);
+		`, testFilePath)))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("constructing a parser from a string or an ArrayBuffer should succeed", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`
+			new xml.Parser("<a/>");
+			new xml.Parser(new Uint8Array([60, 97, 47, 62]).buffer);
+		`)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("constructing a parser without a source should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`new xml.Parser()`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "xml Parser constructor takes at least one non-nil source argument")
+	})
+
+	t.Run("constructing a parser from an unsupported source should fail", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(`new xml.Parser(42)`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "first argument expected to be a fs.File instance, a string, or an ArrayBuffer")
+	})
+}
+
+func TestParserNext(t *testing.T) {
+	t.Parallel()
+
+	const sitemapNS = "{http://www.sitemaps.org/schemas/sitemap/0.9}"
+
+	tests := []struct {
+		name    string
+		content string
+		options string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "next should return the events of the document",
+			content: testXML,
+			options: `{ skipWhitespace: true }`,
+			want: "s:" + sitemapNS + "urlset{}|c: pages |" +
+				"s:" + sitemapNS + "url{}|s:" + sitemapNS + "loc{}|t:https://example.com/|e:loc|" +
+				"s:" + sitemapNS + "priority{}|t:1.0|e:priority|e:url|" +
+				"s:" + sitemapNS + "url{}|s:" + sitemapNS + "loc{}|t:https://example.com/a?x=1&y=2|e:loc|e:url|" +
+				"e:urlset",
+		},
+		{
+			name:    "whitespace should be kept by default",
+			content: "<a>\n  <b/>\n</a>",
+			options: `{}`,
+			want:    "s:a{}|t:\n  |s:b{}|e:b|t:\n|e:a",
+		},
+		{
+			name:    "attributes and prefixed namespaces should be resolved",
+			content: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body id="1" soap:mustUnderstand="true"/></soap:Envelope>`,
+			options: `{}`,
+			want: "s:{http://schemas.xmlsoap.org/soap/envelope/}Envelope{}|" +
+				`s:{http://schemas.xmlsoap.org/soap/envelope/}Body{"id":"1","mustUnderstand":"true"}|e:Body|e:Envelope`,
+		},
+		{
+			name:    "CDATA sections should be emitted as text",
+			content: "<a><![CDATA[<b>]]></a>",
+			options: `{}`,
+			want:    "s:a{}|t:<b>|e:a",
+		},
+		{
+			name:    "html documents should be parsed leniently",
+			content: `<!DOCTYPE html><html><body><p>caf&eacute;<br>ok</p></body></html>`,
+			options: `{ html: true }`,
+			want:    "s:html{}|s:body{}|s:p{}|t:café|s:br{}|e:br|t:ok|e:p|e:body|e:html",
+		},
+		{
+			name:    "a malformed document should fail",
+			content: "<a><b></a>",
+			options: `{}`,
+			wantErr: "failed to parse the document; reason: XML syntax error on line 1",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newConfiguredRuntime(t)
+			require.NoError(t, err)
+
+			require.NoError(t, writeTestFile(r, testFilePath, tt.content))
+
+			_, err = r.RunOnEventLoop(wrapInAsyncLambda(fmt.Sprintf(`
+				const file = await fs.open(%q);
+				const parser = new xml.Parser(file, %s);
+				%s
+				if (got.join("|") !== %q) {
+					throw new Error("Unexpected events " + JSON.stringify(got));
+				}
+			`, testFilePath, tt.options, collectEvents, tt.want)))
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("events should hold the position they were read at", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newConfiguredRuntime(t)
+		require.NoError(t, err)
+
+		_, err = r.RunOnEventLoop(wrapInAsyncLambda(`
+			const parser = new xml.Parser("<a>\n  <b/>\n</a>", { skipWhitespace: true });
+
+			await parser.next();
+			const { value } = await parser.next();
+			if (value.name !== "b" || value.line !== 2 || value.column !== 3) {
+				throw new Error("Unexpected event " + JSON.stringify(value));
+			}
+		`))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParserAsyncIterator(t *testing.T) {
+	t.Parallel()
+
+	// The runtime does not support the `Symbol.asyncIterator` well-known symbol,
+	// nor `for await...of` loops, so we define the former and emulate the latter.
+	const polyfill = `Symbol.asyncIterator = Symbol("Symbol.asyncIterator");`
+
+	r, err := newConfiguredRuntime(t)
+	require.NoError(t, err)
+
+	require.NoError(t, writeTestFile(r, testFilePath, testXML))
+
+	_, err = r.RunOnEventLoop(polyfill + wrapInAsyncLambda(fmt.Sprintf(`
+		const file = await fs.open(%q);
+		const parser = new xml.Parser(file, { skipWhitespace: true });
+
+		const iterator = parser[Symbol.asyncIterator]();
+
+		let got = [];
+		for (let result = await iterator.next(); !result.done; result = await iterator.next()) {
+			if (result.value.type === "startElement") {
+				got.push(result.value.name);
+			}
+		}
+
+		if (got.join("|") !== "urlset|url|loc|priority|url|loc") {
+			throw new Error("Unexpected events " + JSON.stringify(got));
+		}
+	`, testFilePath)))
+
+	assert.NoError(t, err)
+}
+
+const initGlobals = `
+	globalThis.fs = require("k6/experimental/fs");
+	globalThis.xml = require("k6/experimental/xml");
+`
+
+func newConfiguredRuntime(t testing.TB) (*modulestest.Runtime, error) {
+	runtime := modulestest.NewRuntime(t)
+
+	modules := map[string]interface{}{
+		"k6/experimental/fs":  fs.New(),
+		"k6/experimental/xml": New(),
+	}
+
+	err := runtime.SetupModuleSystem(modules, nil, compiler.New(runtime.VU.InitEnv().Logger))
+	if err != nil {
+		return nil, err
+	}
+
+	// Set up the VU environment with an in-memory filesystem and a CWD of "/".
+	runtime.VU.InitEnvField.FileSystems = map[string]fsext.Fs{
+		"file": fsext.NewMemMapFs(),
+	}
+	runtime.VU.InitEnvField.CWD = &url.URL{Scheme: "file"}
+
+	// Ensure the `fs` and `xml` modules are available in the VU's runtime.
+	_, err = runtime.VU.Runtime().RunString(initGlobals)
+
+	return runtime, err
+}
+
+// writeTestFile writes the provided content to the given path in the runtime's
+// in-memory filesystem.
+func writeTestFile(r *modulestest.Runtime, path, content string) error {
+	return fsext.WriteFile(r.VU.InitEnvField.FileSystems["file"], path, []byte(content), 0o644)
+}
+
+// wrapInAsyncLambda is a helper function that wraps the provided input in an async lambda. This
+// makes the use of `await` statements in the input possible.
+func wrapInAsyncLambda(input string) string {
+	// This makes it possible to use `await` freely on the "top" level
+	return "(async () => {\n " + input + "\n })()"
+}
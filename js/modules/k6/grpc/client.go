@@ -4,10 +4,12 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -21,6 +23,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protodesc"
@@ -36,9 +39,45 @@ type Client struct {
 	conn *grpcext.Conn
 	vu   modules.VU
 	addr string
+
+	// reflection caches the file descriptors obtained through the server
+	// reflection protocol across VUs.
+	reflection *reflectionCache
+
+	// reused is the connection kept open across the iterations, when it was
+	// connected with the reuse param, until its max lifetime or the end of
+	// the scenario.
+	reused *reusedConn
+}
+
+// reusedConn is a connection reused across the iterations of a VU.
+type reusedConn struct {
+	ctx         context.Context //nolint:containedctx
+	connected   time.Time
+	maxLifetime time.Duration
+}
+
+// reusable returns whether the connection can still be reused.
+func (c *Client) reusable() bool {
+	if c.conn == nil || c.reused == nil || c.reused.ctx.Err() != nil {
+		return false
+	}
+	return c.reused.maxLifetime == 0 || time.Since(c.reused.connected) < c.reused.maxLifetime
+}
+
+// descriptorSetExtensions holds the extensions of the files [Client.Load] reads
+// as descriptor sets, as [Client.LoadProtoset] does, rather than as proto files.
+var descriptorSetExtensions = map[string]struct{}{ //nolint:gochecknoglobals
+	".protoset": {},
+	".desc":     {},
+	".pb":       {},
 }
 
 // Load will parse the given proto files and make the file descriptors available to request.
+//
+// Precompiled descriptor sets, such as the ones `protoc --descriptor_set_out`
+// produces, can be loaded alongside the proto files, as long as their extension
+// is one of .protoset, .desc, or .pb.
 func (c *Client) Load(importPaths []string, filenames ...string) ([]MethodInfo, error) {
 	if c.vu.State() != nil {
 		return nil, errors.New("load must be called in the init context")
@@ -49,6 +88,20 @@ func (c *Client) Load(importPaths []string, filenames ...string) ([]MethodInfo,
 		return nil, errors.New("missing init environment")
 	}
 
+	fdset, err := loadFileDescriptors(initEnv, importPaths, filenames)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.convertToMethodInfo(fdset)
+}
+
+// loadFileDescriptors parses the given proto files and reads the given
+// descriptor sets, and returns their file descriptors along with the ones of
+// their dependencies.
+func loadFileDescriptors(
+	initEnv *common.InitEnvironment, importPaths []string, filenames []string,
+) (*descriptorpb.FileDescriptorSet, error) {
 	// If no import paths are specified, use the current working directory
 	if len(importPaths) == 0 {
 		importPaths = append(importPaths, initEnv.CWD.Path)
@@ -63,7 +116,16 @@ func (c *Client) Load(importPaths []string, filenames ...string) ([]MethodInfo,
 		}),
 	}
 
-	fds, err := parser.ParseFiles(filenames...)
+	var protoFiles, descriptorSets []string
+	for _, filename := range filenames {
+		if _, ok := descriptorSetExtensions[strings.ToLower(filepath.Ext(filename))]; ok {
+			descriptorSets = append(descriptorSets, filename)
+		} else {
+			protoFiles = append(protoFiles, filename)
+		}
+	}
+
+	fds, err := parser.ParseFiles(protoFiles...)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +136,23 @@ func (c *Client) Load(importPaths []string, filenames ...string) ([]MethodInfo,
 	for _, fd := range fds {
 		fdset.File = append(fdset.File, walkFileDescriptors(seen, fd)...)
 	}
-	return c.convertToMethodInfo(fdset)
+
+	for _, filename := range descriptorSets {
+		set, err := readProtoset(initEnv, filename)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fd := range set.File {
+			if _, ok := seen[fd.GetName()]; ok {
+				continue
+			}
+			seen[fd.GetName()] = struct{}{}
+			fdset.File = append(fdset.File, fd)
+		}
+	}
+
+	return fdset, nil
 }
 
 // LoadProtoset will parse the given protoset file (serialized FileDescriptorSet) and make the file
@@ -89,6 +167,16 @@ func (c *Client) LoadProtoset(protosetPath string) ([]MethodInfo, error) {
 		return nil, errors.New("missing init environment")
 	}
 
+	fdset, err := readProtoset(initEnv, protosetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.convertToMethodInfo(fdset)
+}
+
+// readProtoset reads the protoset file (serialized FileDescriptorSet) at the given path.
+func readProtoset(initEnv *common.InitEnvironment, protosetPath string) (*descriptorpb.FileDescriptorSet, error) {
 	absFilePath := initEnv.GetAbsFilePath(protosetPath)
 	fdsetFile, err := initEnv.FileSystems["file"].Open(absFilePath)
 	if err != nil {
@@ -106,7 +194,7 @@ func (c *Client) LoadProtoset(protosetPath string) ([]MethodInfo, error) {
 		return nil, fmt.Errorf("couldn't unmarshal protoset file %s: %w", protosetPath, err)
 	}
 
-	return c.convertToMethodInfo(fdset)
+	return fdset, nil
 }
 
 // Note: this function was lifted from `lib/options.go`
@@ -205,6 +293,14 @@ func buildTLSConfigFromMap(parentConfig *tls.Config, tlsConfigMap map[string]int
 }
 
 // Connect is a block dial to the gRPC server at the given address (host:port)
+//
+// When the server reflection protocol is used, the file descriptors it returns
+// are cached across VUs, per address and reflection metadata, so that they are
+// only fetched by the first VU connecting to the server.
+//
+// When the connection is reused, it's kept open by Close, and the next
+// connections to the same address of the VU reuse it, instead of dialing the
+// server again, until its max lifetime or the end of the scenario.
 func (c *Client) Connect(addr string, params sobek.Value) (bool, error) {
 	state := c.vu.State()
 	if state == nil {
@@ -216,6 +312,14 @@ func (c *Client) Connect(addr string, params sobek.Value) (bool, error) {
 		return false, fmt.Errorf("invalid grpc.connect() parameters: %w", err)
 	}
 
+	if c.reused != nil {
+		if p.Reuse && c.addr == addr && c.reusable() {
+			return true, nil
+		}
+		_ = c.conn.Close()
+		c.conn, c.reused = nil, nil
+	}
+
 	opts := grpcext.DefaultOptions(c.vu.State)
 
 	var tcred credentials.TransportCredentials
@@ -249,19 +353,33 @@ func (c *Client) Connect(addr string, params sobek.Value) (bool, error) {
 		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(int(p.MaxSendSize))))
 	}
 
+	if p.RetryPolicy != nil {
+		opts = append(opts, grpc.WithDefaultServiceConfig(p.RetryPolicy.serviceConfig()))
+	}
+
 	c.addr = addr
 	c.conn, err = grpcext.Dial(ctx, addr, opts...)
 	if err != nil {
 		return false, err
 	}
 
+	if p.Reuse {
+		vuCtx, conn := c.vu.Context(), c.conn
+		c.reused = &reusedConn{ctx: vuCtx, connected: time.Now(), maxLifetime: p.MaxLifetime}
+		go func() {
+			// the connection isn't reused after the scenario of the VU
+			<-vuCtx.Done()
+			_ = conn.Close()
+		}()
+	}
+
 	if !p.UseReflectionProtocol {
 		return true, nil
 	}
 
 	ctx = metadata.NewOutgoingContext(ctx, p.ReflectionMetadata)
 
-	fdset, err := c.conn.Reflect(ctx)
+	fdset, err := c.reflect(ctx, addr, p.ReflectionMetadata)
 	if err != nil {
 		return false, err
 	}
@@ -273,6 +391,16 @@ func (c *Client) Connect(addr string, params sobek.Value) (bool, error) {
 	return true, err
 }
 
+// reflect returns the file descriptors of the server at the provided address,
+// obtained through the server reflection protocol, and cached across VUs.
+func (c *Client) reflect(ctx context.Context, addr string, md metadata.MD) (*descriptorpb.FileDescriptorSet, error) {
+	if c.reflection == nil {
+		return c.conn.Reflect(ctx)
+	}
+
+	return c.reflection.get(ctx, addr, md, c.conn.Reflect)
+}
+
 // Invoke creates and calls a unary RPC by fully qualified method name
 func (c *Client) Invoke(
 	method string,
@@ -344,9 +472,65 @@ func (c *Client) buildInvokeRequest(
 		return grpcReq, fmt.Errorf("method %q not found in file descriptors", method)
 	}
 
+	if req == nil {
+		return grpcReq, errors.New("request cannot be nil")
+	}
+	b, err := req.ToObject(c.vu.Runtime()).MarshalJSON()
+	if err != nil {
+		return grpcReq, fmt.Errorf("unable to serialise request object: %w", err)
+	}
+
+	return c.newInvokeRequest("invoke", method, methodDesc, b, params)
+}
+
+// HealthCheck checks the health of the server, or of the provided service,
+// through the gRPC health checking protocol, and returns the response, whose
+// message holds the serving status, like "SERVING".
+//
+// See https://github.com/grpc/grpc/blob/master/doc/health-checking.md
+func (c *Client) HealthCheck(service sobek.Value, params sobek.Value) (*grpcext.InvokeResponse, error) {
+	if c.vu.State() == nil {
+		return nil, common.NewInitContextError("checking the health of a gRPC server in the init context is not supported")
+	}
+	if c.conn == nil {
+		return nil, errors.New("no gRPC connection, you must call connect first")
+	}
+
+	// An empty service name designates the server as a whole.
+	var serviceName string
+	if !common.IsNullish(service) {
+		serviceName = service.String()
+	}
+
+	b, err := json.Marshal(map[string]string{"service": serviceName})
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialise request object: %w", err)
+	}
+
+	methodDesc := grpc_health_v1.File_grpc_health_v1_health_proto.
+		Services().ByName("Health").Methods().ByName("Check")
+
+	grpcReq, err := c.newInvokeRequest("healthCheck", grpc_health_v1.Health_Check_FullMethodName, methodDesc, b, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.conn.Invoke(c.vu.Context(), grpcReq)
+}
+
+// newInvokeRequest creates a new InvokeRequest calling the given method with
+// the provided serialised message and parameters, on behalf of the client's
+// operation the given name designates.
+func (c *Client) newInvokeRequest(
+	op string,
+	method string,
+	methodDesc protoreflect.MethodDescriptor,
+	message []byte,
+	params sobek.Value,
+) (grpcext.InvokeRequest, error) {
 	p, err := newCallParams(c.vu, params)
 	if err != nil {
-		return grpcReq, fmt.Errorf("invalid GRPC's client.invoke() parameters: %w", err)
+		return grpcext.InvokeRequest{}, fmt.Errorf("invalid GRPC's client.%s() parameters: %w", op, err)
 	}
 
 	// k6 GRPC Invoke's default timeout is 2 minutes
@@ -354,33 +538,30 @@ func (c *Client) buildInvokeRequest(
 		p.Timeout = 2 * time.Minute
 	}
 
-	if req == nil {
-		return grpcReq, errors.New("request cannot be nil")
-	}
-	b, err := req.ToObject(c.vu.Runtime()).MarshalJSON()
-	if err != nil {
-		return grpcReq, fmt.Errorf("unable to serialise request object: %w", err)
+	p.SetSystemTags(c.vu.State(), c.addr, method)
+	p.PropagateTraceContext(c.vu.State())
+	if err = p.RunRequestHooks(c.vu.State(), c.addr, method); err != nil {
+		return grpcext.InvokeRequest{}, err
 	}
 
-	p.SetSystemTags(state, c.addr, method)
-
 	return grpcext.InvokeRequest{
 		Method:           method,
 		MethodDescriptor: methodDesc,
 		Timeout:          p.Timeout,
-		Message:          b,
+		Message:          message,
 		TagsAndMeta:      &p.TagsAndMeta,
 		Metadata:         p.Metadata,
 	}, nil
 }
 
-// Close will close the client gRPC connection
+// Close will close the client gRPC connection, unless it can still be reused
+// by the next iterations.
 func (c *Client) Close() error {
-	if c.conn == nil {
+	if c.conn == nil || c.reusable() {
 		return nil
 	}
 	err := c.conn.Close()
-	c.conn = nil
+	c.conn, c.reused = nil, nil
 
 	return err
 }
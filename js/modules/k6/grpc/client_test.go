@@ -6,8 +6,11 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"net"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	k6grpc "go.k6.io/k6/js/modules/k6/grpc"
 	"go.k6.io/k6/lib/netext/grpcext"
@@ -19,6 +22,7 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -30,6 +34,7 @@ import (
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/metadata"
 	v1alphagrpc "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 	grpcstats "google.golang.org/grpc/stats"
@@ -118,6 +123,20 @@ func TestClient(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "LoadProtosetThroughLoad",
+			initString: codeBlock{
+				code: `
+			var client = new grpc.Client();
+			client.load([], "../../../../lib/testutils/httpmultibin/grpc_protoset_testing/test.protoset");`,
+				val: []k6grpc.MethodInfo{
+					{
+						MethodInfo: grpc.MethodInfo{Name: "Test", IsClientStream: false, IsServerStream: false},
+						Package:    "grpc.protoset.testing", Service: "TestService", FullMethod: "/grpc.protoset.testing.TestService/Test",
+					},
+				},
+			},
+		},
 		{
 			name: "ConnectInit",
 			initString: codeBlock{
@@ -360,6 +379,50 @@ func TestClient(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "InvalidRetryPolicy",
+			vuString: codeBlock{
+				code: `
+				var client = new grpc.Client();
+				client.connect("GRPCBIN_ADDR", {retryPolicy: {maxAttempts: 1}});`,
+				err: "invalid retryPolicy param: invalid maxAttempts value: 1, it needs to be between 2 and 5",
+			},
+		},
+		{
+			name:       "HealthCheckNoConnection",
+			initString: codeBlock{code: `var client = new grpc.Client();`},
+			vuString: codeBlock{
+				code: `client.healthCheck()`,
+				err:  "no gRPC connection",
+			},
+		},
+		{
+			name: "HealthCheck",
+			setup: func(tb *httpmultibin.HTTPMultiBin) {
+				grpc_health_v1.RegisterHealthServer(tb.ServerGRPC, healthStub{})
+			},
+			initString: codeBlock{code: `var client = new grpc.Client();`},
+			vuString: codeBlock{
+				code: `
+				client.connect("GRPCBIN_ADDR");
+				var resp = client.healthCheck()
+				if (resp.status !== grpc.StatusOK || resp.message.status !== "SERVING") {
+					throw new Error("unexpected response: " + JSON.stringify(resp))
+				}
+				resp = client.healthCheck("grpc.testing.TestService", {tags: {check: "service"}})
+				if (resp.message.status !== "NOT_SERVING") {
+					throw new Error("unexpected response: " + JSON.stringify(resp))
+				}
+				resp = client.healthCheck("unknown")
+				if (resp.status !== grpc.StatusNotFound) {
+					throw new Error("unexpected status: " + resp.status)
+				}`,
+				asserts: func(t *testing.T, rb *httpmultibin.HTTPMultiBin, samples chan metrics.SampleContainer, _ error) {
+					samplesBuf := metrics.GetBufferedSamples(samples)
+					assertMetricEmitted(t, metrics.GRPCReqDurationName, samplesBuf, rb.Replacer.Replace("GRPCBIN_ADDR/grpc.health.v1.Health/Check"))
+				},
+			},
+		},
 		{
 			name: "InvokeAnyProto",
 			initString: codeBlock{code: `
@@ -564,6 +627,46 @@ func TestClient(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "ResponseExtractAndExpect",
+			initString: codeBlock{
+				code: `
+				var client = new grpc.Client();
+				client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`,
+			},
+			setup: func(tb *httpmultibin.HTTPMultiBin) {
+				tb.GRPCStub.UnaryCallFunc = func(context.Context, *grpc_testing.SimpleRequest) (*grpc_testing.SimpleResponse, error) {
+					return &grpc_testing.SimpleResponse{
+						Payload:  &grpc_testing.Payload{Type: grpc_testing.PayloadType_UNCOMPRESSABLE, Body: []byte("k6")},
+						Username: "k6-user",
+					}, nil
+				}
+			},
+			vuString: codeBlock{
+				code: `
+				client.connect("GRPCBIN_ADDR");
+				var resp = client.invoke("grpc.testing.TestService/UnaryCall", {})
+				if (resp.extract("$.payload.type") !== "UNCOMPRESSABLE" || resp.extract("username") !== "k6-user") {
+					throw new Error("unexpected extracted fields: " + JSON.stringify(resp.message))
+				}
+				if (resp.extract("$.payload.body") !== "azY=" || resp.extract("$.missing") !== null) {
+					throw new Error("unexpected extracted body: " + resp.extract("$.payload.body"))
+				}
+				var expected = grpc.expect(resp)
+				if (!expected.toHaveStatus(grpc.StatusOK) || expected.toHaveStatus(grpc.StatusNotFound)) {
+					throw new Error("unexpected status assertions")
+				}
+				if (!expected.toHaveField("$.payload.type", "UNCOMPRESSABLE") || !expected.toHaveField("oauth_scope")) {
+					throw new Error("unexpected field assertions")
+				}
+				if (expected.toHaveField("username", "k6") || expected.toHaveField("$.payload.size")) {
+					throw new Error("unexpected field assertions")
+				}
+				if (!grpc.expect(resp.message).toHaveField("$.payload.body", "azY=")) {
+					throw new Error("unexpected message field assertions")
+				}`,
+			},
+		},
 		{
 			name: "ResponseError",
 			initString: codeBlock{
@@ -1369,3 +1472,192 @@ func TestClientConnectionReflectMetadata(t *testing.T) {
 
 	assert.True(t, foundReflectionCall, "expected to find a reflection call in the logs, but didn't")
 }
+
+func TestClientTraceContextPropagation(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+
+	var traceparents []string
+	ts.httpBin.GRPCStub.EmptyCallFunc = func(ctx context.Context, _ *grpc_testing.Empty) (*grpc_testing.Empty, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		traceparents = append(traceparents, strings.Join(md.Get("traceparent"), ","))
+		return &grpc_testing.Empty{}, nil
+	}
+
+	_, err := ts.Run(`
+		var client = new grpc.Client();
+		client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");
+	`)
+	require.NoError(t, err)
+
+	ts.ToVUContext()
+	ts.VU.State().Options.TracePropagator = null.StringFrom("w3c")
+
+	_, err = ts.Run(`
+		client.connect("GRPCBIN_ADDR");
+		client.invoke("grpc.testing.TestService/EmptyCall", {});
+		client.invoke("grpc.testing.TestService/EmptyCall", {}, { metadata: { traceparent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01" } });
+	`)
+	require.NoError(t, err)
+
+	require.Len(t, traceparents, 2)
+	assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`, traceparents[0])
+	assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", traceparents[1])
+
+	var traceIDs []string
+	for _, container := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range container.GetSamples() {
+			if sample.Metric.Name == metrics.GRPCReqDurationName {
+				traceIDs = append(traceIDs, sample.Metadata["trace_id"])
+			}
+		}
+	}
+	require.Len(t, traceIDs, 2)
+	assert.Equal(t, traceparents[0][3:35], traceIDs[0])
+	assert.Empty(t, traceIDs[1])
+}
+
+func TestClientRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		failures         int32
+		expectedStatus   codes.Code
+		expectedAttempts int32
+	}{
+		{name: "Retried", failures: 2, expectedStatus: codes.OK, expectedAttempts: 3},
+		{name: "Exhausted", failures: 5, expectedStatus: codes.Unavailable, expectedAttempts: 3},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestState(t)
+
+			// gRPC only retries the RPCs failing with trailers-only responses,
+			// which the HTTP/2 server of the httpmultibin doesn't send.
+			var attempts atomic.Int32
+			srv := grpc.NewServer()
+			grpc_testing.RegisterTestServiceServer(srv, &httpmultibin.GRPCStub{
+				EmptyCallFunc: func(context.Context, *grpc_testing.Empty) (*grpc_testing.Empty, error) {
+					if attempts.Add(1) <= tt.failures {
+						return nil, status.Error(codes.Unavailable, "unavailable")
+					}
+					return &grpc_testing.Empty{}, nil
+				},
+			})
+
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			go func() { _ = srv.Serve(lis) }()
+			t.Cleanup(srv.Stop)
+
+			_, err = ts.Run(`
+				var client = new grpc.Client();
+				client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`)
+			require.NoError(t, err)
+
+			ts.ToVUContext()
+			val, err := ts.Run(fmt.Sprintf(`
+				client.connect(%q, {
+					plaintext: true,
+					retryPolicy: {
+						maxAttempts: 3,
+						initialBackoff: "1ms",
+						maxBackoff: "10ms",
+						retryableStatusCodes: [grpc.StatusUnavailable, "ABORTED"],
+					},
+				});
+				client.invoke("grpc.testing.TestService/EmptyCall", {}).status`, lis.Addr().String()))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, val.Export())
+			assert.Equal(t, tt.expectedAttempts, attempts.Load())
+
+			url := lis.Addr().String() + "/grpc.testing.TestService/EmptyCall"
+			samplesBuf := metrics.GetBufferedSamples(ts.samples)
+			assertMetricEmitted(t, metrics.GRPCReqDurationName, samplesBuf, url)
+			assertMetricEmitted(t, metrics.GRPCReqRetryDurationName, samplesBuf, url)
+		})
+	}
+}
+
+// countingListener counts the accepted connections.
+type countingListener struct {
+	net.Listener
+	accepted atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+	return conn, err
+}
+
+func TestClientConnectionReuse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                string
+		params              string
+		wait                time.Duration
+		expectedConnections int32
+	}{
+		{name: "NotReused", params: `{plaintext: true}`, expectedConnections: 3},
+		{name: "Reused", params: `{plaintext: true, reuse: true}`, expectedConnections: 1},
+		{
+			name:                "MaxLifetime",
+			params:              `{plaintext: true, reuse: true, maxLifetime: "10ms"}`,
+			wait:                20 * time.Millisecond,
+			expectedConnections: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := newTestState(t)
+
+			srv := grpc.NewServer()
+			grpc_testing.RegisterTestServiceServer(srv, &httpmultibin.GRPCStub{
+				EmptyCallFunc: func(context.Context, *grpc_testing.Empty) (*grpc_testing.Empty, error) {
+					return &grpc_testing.Empty{}, nil
+				},
+			})
+
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			counting := &countingListener{Listener: lis}
+			go func() { _ = srv.Serve(counting) }()
+			t.Cleanup(srv.Stop)
+
+			_, err = ts.Run(`
+				var client = new grpc.Client();
+				client.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");`)
+			require.NoError(t, err)
+
+			ts.ToVUContext()
+			// every run is an iteration of the VU
+			for i := 0; i < 3; i++ {
+				time.Sleep(tt.wait)
+				val, err := ts.Run(fmt.Sprintf(`
+					client.connect(%q, %s);
+					var status = client.invoke("grpc.testing.TestService/EmptyCall", {}).status;
+					client.close();
+					status`, lis.Addr().String(), tt.params))
+				require.NoError(t, err)
+				assert.Equal(t, codes.OK, val.Export())
+			}
+
+			assert.Equal(t, tt.expectedConnections, counting.accepted.Load())
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/sobek"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+)
+
+// Codec encodes and decodes protobuf messages to and from their binary
+// representation, with the message types loaded from proto files or
+// descriptor sets, like the ones of a [Client] are. It allows the payloads of
+// binary protocols, e.g. of WebSocket messages, to be handled as objects.
+type Codec struct {
+	vu       modules.VU
+	messages map[string]protoreflect.MessageDescriptor
+}
+
+// NewCodec is the JS constructor for the protobuf Codec.
+func (mi *ModuleInstance) NewCodec(_ sobek.ConstructorCall) *sobek.Object {
+	rt := mi.vu.Runtime()
+	return rt.ToValue(&Codec{vu: mi.vu, messages: make(map[string]protoreflect.MessageDescriptor)}).ToObject(rt)
+}
+
+// Load parses the given proto files and descriptor sets, as [Client.Load]
+// does, and returns the full names of the message types they define.
+func (c *Codec) Load(importPaths []string, filenames ...string) ([]string, error) {
+	if c.vu.State() != nil {
+		return nil, errors.New("load must be called in the init context")
+	}
+
+	initEnv := c.vu.InitEnv()
+	if initEnv == nil {
+		return nil, errors.New("missing init environment")
+	}
+
+	fdset, err := loadFileDescriptors(initEnv, importPaths, filenames)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.addMessages(fdset)
+}
+
+// LoadProtoset reads the given protoset file (serialized FileDescriptorSet)
+// and returns the full names of the message types it defines.
+func (c *Codec) LoadProtoset(protosetPath string) ([]string, error) {
+	if c.vu.State() != nil {
+		return nil, errors.New("load must be called in the init context")
+	}
+
+	initEnv := c.vu.InitEnv()
+	if initEnv == nil {
+		return nil, errors.New("missing init environment")
+	}
+
+	fdset, err := readProtoset(initEnv, protosetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.addMessages(fdset)
+}
+
+// addMessages makes the message types of the file descriptors, including the
+// nested ones, available to encode and decode, and returns their full names.
+func (c *Codec) addMessages(fdset *descriptorpb.FileDescriptorSet) ([]string, error) {
+	files, err := protodesc.NewFiles(fdset)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		stack := make([]protoreflect.MessageDescriptor, 0, fd.Messages().Len())
+		for i := 0; i < fd.Messages().Len(); i++ {
+			stack = append(stack, fd.Messages().Get(i))
+		}
+
+		for len(stack) > 0 {
+			message := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			name := string(message.FullName())
+			if _, ok := c.messages[name]; !ok {
+				names = append(names, name)
+			}
+			c.messages[name] = message
+
+			nested := message.Messages()
+			for i := 0; i < nested.Len(); i++ {
+				stack = append(stack, nested.Get(i))
+			}
+		}
+		return true
+	})
+
+	return names, nil
+}
+
+// getMessageDescriptor returns the descriptor of the loaded message type with
+// the given full name.
+func (c *Codec) getMessageDescriptor(messageType string) (protoreflect.MessageDescriptor, error) {
+	if messageType == "" {
+		return nil, errors.New("message type cannot be empty")
+	}
+	md, ok := c.messages[messageType]
+	if !ok {
+		return nil, fmt.Errorf("message type %q not found in file descriptors", messageType)
+	}
+	return md, nil
+}
+
+// Encode returns the binary representation of the given object as a message
+// of the given type.
+func (c *Codec) Encode(messageType string, message sobek.Value) (sobek.ArrayBuffer, error) {
+	rt := c.vu.Runtime()
+
+	md, err := c.getMessageDescriptor(messageType)
+	if err != nil {
+		return sobek.ArrayBuffer{}, err
+	}
+	if common.IsNullish(message) {
+		return sobek.ArrayBuffer{}, errors.New("message cannot be null or undefined")
+	}
+
+	b, err := message.ToObject(rt).MarshalJSON()
+	if err != nil {
+		return sobek.ArrayBuffer{}, fmt.Errorf("unable to serialise message object: %w", err)
+	}
+	dm := dynamicpb.NewMessage(md)
+	if err = protojson.Unmarshal(b, dm); err != nil {
+		return sobek.ArrayBuffer{}, fmt.Errorf("unable to encode the message as %s: %w", messageType, err)
+	}
+	encoded, err := proto.Marshal(dm)
+	if err != nil {
+		return sobek.ArrayBuffer{}, fmt.Errorf("unable to encode the message as %s: %w", messageType, err)
+	}
+
+	return rt.NewArrayBuffer(encoded), nil
+}
+
+// Decode returns the object of the message of the given type whose binary
+// representation is given, as an ArrayBuffer, a typed array or a string.
+func (c *Codec) Decode(messageType string, data sobek.Value) (interface{}, error) {
+	md, err := c.getMessageDescriptor(messageType)
+	if err != nil {
+		return nil, err
+	}
+	if common.IsNullish(data) {
+		return nil, errors.New("data cannot be null or undefined")
+	}
+
+	b, err := common.ToBytes(data.Export())
+	if err != nil {
+		return nil, err
+	}
+	dm := dynamicpb.NewMessage(md)
+	if err = proto.Unmarshal(b, dm); err != nil {
+		return nil, fmt.Errorf("unable to decode the data as %s: %w", messageType, err)
+	}
+
+	// The dynamic message is converted to a map through JSON, like the ones
+	// of the responses are, so its fields can be accessed from JS.
+	raw, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(dm)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert the decoded message to JSON: %w", err)
+	}
+	var msg map[string]interface{}
+	if err = json.Unmarshal(raw, &msg); err != nil {
+		return nil, fmt.Errorf("unable to convert the decoded message to JSON: %w", err)
+	}
+
+	return msg, nil
+}
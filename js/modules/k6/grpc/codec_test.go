@@ -0,0 +1,62 @@
+package grpc_test
+
+import (
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"go.k6.io/k6/lib/testutils/httpmultibin/grpc_testing"
+)
+
+func TestCodec(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestState(t)
+	_, err := ts.Run(`
+		var codec = new grpc.Codec();
+		var types = codec.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto");
+		if (types.indexOf("grpc.testing.SimpleRequest") < 0) throw new Error("SimpleRequest wasn't loaded");`)
+	require.NoError(t, err)
+
+	_, err = ts.Run(`codec.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto")`)
+	require.NoError(t, err)
+
+	ts.ToVUContext()
+
+	_, err = ts.Run(`codec.load([], "../../../../lib/testutils/httpmultibin/grpc_testing/test.proto")`)
+	require.ErrorContains(t, err, "load must be called in the init context")
+
+	val, err := ts.Run(`codec.encode("grpc.testing.SimpleRequest", { responseSize: 42, payload: { body: "aGVsbG8=" } })`)
+	require.NoError(t, err)
+	encoded, ok := val.Export().(sobek.ArrayBuffer)
+	require.True(t, ok)
+
+	req := &grpc_testing.SimpleRequest{}
+	require.NoError(t, proto.Unmarshal(encoded.Bytes(), req))
+	assert.Equal(t, int32(42), req.GetResponseSize())
+	assert.Equal(t, []byte("hello"), req.GetPayload().GetBody())
+
+	_, err = ts.Run(`
+		var buffer = codec.encode("grpc.testing.SimpleRequest", { responseSize: 7, fillUsername: true });
+		for (const data of [buffer, new Uint8Array(buffer)]) {
+			var decoded = codec.decode("grpc.testing.SimpleRequest", data);
+			if (decoded.responseSize !== 7 || decoded.fillUsername !== true) {
+				throw new Error("unexpected decoded message " + JSON.stringify(decoded));
+			}
+		}`)
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		`codec.encode("grpc.testing.Unknown", {})`:                   `message type "grpc.testing.Unknown" not found in file descriptors`,
+		`codec.encode("grpc.testing.SimpleRequest", { foo: 1 })`:     `unable to encode the message as grpc.testing.SimpleRequest`,
+		`codec.decode("grpc.testing.SimpleRequest", null)`:           `data cannot be null or undefined`,
+		`codec.decode("grpc.testing.SimpleRequest", "\xff\xff\xff")`: `unable to decode the data as grpc.testing.SimpleRequest`,
+	}
+	for code, expErr := range tests {
+		_, err = ts.Run(code)
+		assert.ErrorContains(t, err, expErr, code)
+	}
+}
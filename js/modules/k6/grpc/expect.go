@@ -0,0 +1,93 @@
+package grpc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+
+	"github.com/grafana/sobek"
+	"google.golang.org/grpc/codes"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib/netext/grpcext"
+)
+
+// expectation is what grpc.expect returns, with declarative assertions on a
+// response or a message received from a stream. The assertions return whether
+// they hold, so they can be used in checks.
+type expectation struct {
+	response *grpcext.InvokeResponse
+	message  interface{}
+}
+
+// expect returns the expectation on the given response or stream message.
+func (mi *ModuleInstance) expect(v sobek.Value) (*expectation, error) {
+	if common.IsNullish(v) {
+		return nil, errors.New("expect needs a gRPC response or a stream message")
+	}
+	if response, ok := v.Export().(*grpcext.InvokeResponse); ok {
+		return &expectation{response: response}, nil
+	}
+	return &expectation{message: v.Export()}, nil
+}
+
+// ToHaveField returns whether the message has a field at the path, e.g.
+// $.items[0].id, with the expected value, if one is given.
+func (e *expectation) ToHaveField(path string, expected sobek.Value) (bool, error) {
+	var (
+		value interface{}
+		found bool
+		err   error
+	)
+	if e.response != nil && e.response.Raw != nil {
+		value, found, err = grpcext.ExtractField(e.response.Raw, path)
+	} else {
+		message := e.message
+		if e.response != nil {
+			message = e.response.Message
+		}
+		value, found, err = grpcext.ExtractValue(message, path)
+	}
+	if err != nil || !found {
+		return false, err
+	}
+	if expected == nil || sobek.IsUndefined(expected) {
+		return true, nil
+	}
+	return valuesEqual(value, expected.Export()), nil
+}
+
+// ToHaveStatus returns whether the response has the status code.
+func (e *expectation) ToHaveStatus(code codes.Code) (bool, error) {
+	if e.response == nil {
+		return false, errors.New("toHaveStatus needs a gRPC response")
+	}
+	return e.response.Status == code, nil
+}
+
+// valuesEqual returns whether the extracted value is the expected one, with
+// the numbers compared by value, including the 64-bit integers, which are
+// extracted as strings.
+func valuesEqual(value, expected interface{}) bool {
+	normalize := func(v interface{}) interface{} {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+		var normalized interface{}
+		if err = json.Unmarshal(b, &normalized); err != nil {
+			return v
+		}
+		return normalized
+	}
+
+	value, expected = normalize(value), normalize(expected)
+	if s, ok := value.(string); ok {
+		if f, ok := expected.(float64); ok {
+			parsed, err := strconv.ParseFloat(s, 64)
+			return err == nil && parsed == f
+		}
+	}
+	return reflect.DeepEqual(value, expected)
+}
@@ -15,13 +15,18 @@ import (
 type (
 	// RootModule is the global module instance that will create module
 	// instances for each VU.
-	RootModule struct{}
+	RootModule struct {
+		// reflection holds the file descriptors obtained through the server
+		// reflection protocol, shared by every VU.
+		reflection *reflectionCache
+	}
 
 	// ModuleInstance represents an instance of the GRPC module for every VU.
 	ModuleInstance struct {
-		vu      modules.VU
-		exports map[string]interface{}
-		metrics *instanceMetrics
+		vu         modules.VU
+		exports    map[string]interface{}
+		metrics    *instanceMetrics
+		reflection *reflectionCache
 	}
 )
 
@@ -32,7 +37,7 @@ var (
 
 // New returns a pointer to a new RootModule instance.
 func New() *RootModule {
-	return &RootModule{}
+	return &RootModule{reflection: newReflectionCache()}
 }
 
 // NewModuleInstance implements the modules.Module interface to return
@@ -44,14 +49,17 @@ func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 	}
 
 	mi := &ModuleInstance{
-		vu:      vu,
-		exports: make(map[string]interface{}),
-		metrics: metrics,
+		vu:         vu,
+		exports:    make(map[string]interface{}),
+		metrics:    metrics,
+		reflection: r.reflection,
 	}
 
 	mi.exports["Client"] = mi.NewClient
+	mi.exports["Codec"] = mi.NewCodec
 	mi.defineConstants()
 	mi.exports["Stream"] = mi.stream
+	mi.exports["expect"] = mi.expect
 
 	return mi
 }
@@ -59,7 +67,7 @@ func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 // NewClient is the JS constructor for the grpc Client.
 func (mi *ModuleInstance) NewClient(_ sobek.ConstructorCall) *sobek.Object {
 	rt := mi.vu.Runtime()
-	return rt.ToValue(&Client{vu: mi.vu}).ToObject(rt)
+	return rt.ToValue(&Client{vu: mi.vu, reflection: mi.reflection}).ToObject(rt)
 }
 
 // defineConstants defines the constant variables of the module.
@@ -116,6 +124,10 @@ func (mi *ModuleInstance) stream(c sobek.ConstructorCall) *sobek.Object {
 	}
 
 	p.SetSystemTags(mi.vu.State(), client.addr, methodName)
+	p.PropagateTraceContext(mi.vu.State())
+	if err = p.RunRequestHooks(mi.vu.State(), client.addr, methodName); err != nil {
+		common.Throw(rt, err)
+	}
 
 	logger := mi.vu.State().Logger.WithField("streamMethod", methodName)
 
@@ -1,6 +1,7 @@
 package grpc_test
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -9,6 +10,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.k6.io/k6/metrics"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
 func assertResponse(t *testing.T, cb codeBlock, err error, val sobek.Value, ts testState) {
@@ -32,7 +36,7 @@ func assertResponse(t *testing.T, cb codeBlock, err error, val sobek.Value, ts t
 
 func assertMetricEmitted(
 	t *testing.T,
-	metricName string, //nolint:unparam
+	metricName string,
 	sampleContainers []metrics.SampleContainer,
 	url string,
 ) {
@@ -51,3 +55,24 @@ func assertMetricEmitted(
 	}
 	assert.True(t, seenMetric, "url %s didn't emit %s", url, metricName)
 }
+
+// healthStub is a gRPC health server reporting the server as serving, and the
+// grpc.testing.TestService service as not serving.
+type healthStub struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+// Check implements the grpc_health_v1.HealthServer interface.
+func (healthStub) Check(
+	_ context.Context,
+	req *grpc_health_v1.HealthCheckRequest,
+) (*grpc_health_v1.HealthCheckResponse, error) {
+	switch req.GetService() {
+	case "":
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+	case "grpc.testing.TestService":
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING}, nil
+	default:
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+}
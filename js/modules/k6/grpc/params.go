@@ -1,8 +1,10 @@
 package grpc
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,8 +12,10 @@ import (
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext/tracecontext"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -119,6 +123,48 @@ func (p *callParams) SetSystemTags(state *lib.State, addr string, methodName str
 	}
 }
 
+// PropagateTraceContext adds the metadata of a new trace context to the call,
+// when the tracePropagator option is set, and adds its trace ID to the
+// metadata of the call's samples. The calls which already have a trace context
+// in their metadata are left as they are.
+func (p *callParams) PropagateTraceContext(state *lib.State) {
+	tc, propagators, ok := tracecontext.FromOptions(state.Options.TracePropagator, state.Options.TraceSampling)
+	if !ok {
+		return
+	}
+	if len(p.Metadata.Get(tracecontext.W3CHeaderName)) > 0 || len(p.Metadata.Get(tracecontext.B3HeaderName)) > 0 {
+		return
+	}
+
+	for name, value := range tc.Headers(propagators) {
+		p.Metadata.Set(name, value)
+	}
+	p.TagsAndMeta.SetMetadata(tracecontext.MetadataKey, tc.TraceID)
+}
+
+// RunRequestHooks invokes the request hooks of the script with the call, and
+// applies the metadata and the tags they changed.
+func (p *callParams) RunRequestHooks(state *lib.State, addr string, methodName string) error {
+	if !state.Hooks.HasRequestHooks() {
+		return nil
+	}
+
+	hooked := lib.NewHookedRequest("grpc", methodName, addr+methodName, p.Metadata, p.TagsAndMeta.Tags)
+	if err := state.Hooks.Request(hooked); err != nil {
+		return err
+	}
+
+	set, removed := hooked.HeaderChanges()
+	for k, v := range set {
+		p.Metadata.Set(k, v)
+	}
+	for _, k := range removed {
+		p.Metadata.Delete(k)
+	}
+	hooked.ApplyTags(&p.TagsAndMeta)
+	return nil
+}
+
 // connectParams is the parameters that can be passed to a gRPC connect call.
 type connectParams struct {
 	IsPlaintext           bool
@@ -128,6 +174,9 @@ type connectParams struct {
 	MaxReceiveSize        int64
 	MaxSendSize           int64
 	TLS                   map[string]interface{}
+	RetryPolicy           *retryPolicy
+	Reuse                 bool
+	MaxLifetime           time.Duration
 }
 
 func newConnectParams(vu modules.VU, input sobek.Value) (*connectParams, error) { //nolint:gocognit
@@ -198,11 +247,37 @@ func newConnectParams(vu modules.VU, input sobek.Value) (*connectParams, error)
 			if err := parseConnectTLSParam(result, v); err != nil {
 				return result, err
 			}
+		case "retryPolicy":
+			rp, err := newRetryPolicy(rt, params.Get(k))
+			if err != nil {
+				return result, fmt.Errorf("invalid retryPolicy param: %w", err)
+			}
+
+			result.RetryPolicy = rp
+		case "reuse":
+			var ok bool
+			result.Reuse, ok = v.(bool)
+			if !ok {
+				return result, fmt.Errorf("invalid reuse value: '%#v', it needs to be boolean", v)
+			}
+		case "maxLifetime":
+			var err error
+			result.MaxLifetime, err = types.GetDurationValue(v)
+			if err != nil {
+				return result, fmt.Errorf("invalid maxLifetime value: %w", err)
+			}
+			if result.MaxLifetime < 0 {
+				return result, fmt.Errorf("invalid maxLifetime value: '%#v', it needs to be positive", v)
+			}
 		default:
 			return result, fmt.Errorf("unknown connect param: %q", k)
 		}
 	}
 
+	if result.MaxLifetime > 0 && !result.Reuse {
+		return result, errors.New("invalid maxLifetime param: it can only be set when the connection is reused")
+	}
+
 	return result, nil
 }
 
@@ -245,3 +320,145 @@ func parseConnectTLSParam(params *connectParams, v interface{}) error {
 	}
 	return nil
 }
+
+// retryPolicy is the policy the RPCs of a connection are retried under,
+// when they fail with one of the retryable status codes.
+//
+// See https://github.com/grpc/proposal/blob/master/A6-client-retries.md
+type retryPolicy struct {
+	MaxAttempts          int64
+	InitialBackoff       time.Duration
+	MaxBackoff           time.Duration
+	BackoffMultiplier    float64
+	RetryableStatusCodes []codes.Code
+}
+
+// newRetryPolicy constructs a retryPolicy from the input value. The backoff
+// defaults to starting at 100ms, and to doubling up to 1s, and only the
+// UNAVAILABLE status code is retryable unless specified otherwise.
+func newRetryPolicy(rt *sobek.Runtime, input sobek.Value) (*retryPolicy, error) {
+	if common.IsNullish(input) {
+		return nil, errors.New("must be an object")
+	}
+
+	result := &retryPolicy{
+		InitialBackoff:       100 * time.Millisecond,
+		MaxBackoff:           time.Second,
+		BackoffMultiplier:    2,
+		RetryableStatusCodes: []codes.Code{codes.Unavailable},
+	}
+
+	params := input.ToObject(rt)
+	for _, k := range params.Keys() {
+		v := params.Get(k).Export()
+
+		var err error
+		switch k {
+		case "maxAttempts":
+			var ok bool
+			result.MaxAttempts, ok = v.(int64)
+			if !ok {
+				return nil, fmt.Errorf("invalid maxAttempts value: '%#v', it needs to be an integer", v)
+			}
+		case "initialBackoff":
+			result.InitialBackoff, err = types.GetDurationValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid initialBackoff value: %w", err)
+			}
+		case "maxBackoff":
+			result.MaxBackoff, err = types.GetDurationValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid maxBackoff value: %w", err)
+			}
+		case "backoffMultiplier":
+			result.BackoffMultiplier = params.Get(k).ToFloat()
+		case "retryableStatusCodes":
+			result.RetryableStatusCodes, err = newStatusCodes(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid retryableStatusCodes value: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unknown param: %q", k)
+		}
+	}
+
+	// gRPC ignores the policies not following these rules, rather than failing.
+	switch {
+	case result.MaxAttempts < 2 || result.MaxAttempts > 5:
+		return nil, fmt.Errorf("invalid maxAttempts value: %d, it needs to be between 2 and 5", result.MaxAttempts)
+	case result.InitialBackoff <= 0:
+		return nil, errors.New("invalid initialBackoff value: it needs to be positive")
+	case result.MaxBackoff <= 0:
+		return nil, errors.New("invalid maxBackoff value: it needs to be positive")
+	case !(result.BackoffMultiplier > 0):
+		return nil, errors.New("invalid backoffMultiplier value: it needs to be positive")
+	case len(result.RetryableStatusCodes) == 0:
+		return nil, errors.New("invalid retryableStatusCodes value: it needs at least one status code")
+	}
+
+	return result, nil
+}
+
+// newStatusCodes returns the status codes the provided array holds, either as
+// the module's constants, like grpc.StatusUnavailable, or as their names in
+// the gRPC specification, like "UNAVAILABLE".
+func newStatusCodes(v interface{}) ([]codes.Code, error) {
+	values, ok := v.([]interface{})
+	if !ok {
+		return nil, errors.New("it needs to be an array of status codes")
+	}
+
+	result := make([]codes.Code, 0, len(values))
+	for _, value := range values {
+		var code codes.Code
+		switch value := value.(type) {
+		case codes.Code:
+			code = value
+		case int64:
+			if err := code.UnmarshalJSON([]byte(strconv.FormatInt(value, 10))); err != nil {
+				return nil, err
+			}
+		case string:
+			if err := code.UnmarshalJSON([]byte(strconv.Quote(value))); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid status code: '%#v'", value)
+		}
+
+		result = append(result, code)
+	}
+
+	return result, nil
+}
+
+// serviceConfig returns the service config, as JSON, applying the policy to
+// all the methods of a connection.
+func (rp *retryPolicy) serviceConfig() string {
+	// The durations are formatted as JSON representations of the protobuf's Duration.
+	duration := func(d time.Duration) string {
+		return fmt.Sprintf("%d.%09ds", d/time.Second, d%time.Second)
+	}
+
+	statusCodes := make([]uint32, 0, len(rp.RetryableStatusCodes))
+	for _, code := range rp.RetryableStatusCodes {
+		statusCodes = append(statusCodes, uint32(code))
+	}
+
+	b, _ := json.Marshal(map[string]interface{}{ //nolint:errchkjson // the values can always be marshaled
+		"methodConfig": []interface{}{
+			map[string]interface{}{
+				"name": []interface{}{map[string]interface{}{}},
+				"retryPolicy": map[string]interface{}{
+					"maxAttempts":          rp.MaxAttempts,
+					"initialBackoff":       duration(rp.InitialBackoff),
+					"maxBackoff":           duration(rp.MaxBackoff),
+					"backoffMultiplier":    rp.BackoffMultiplier,
+					"retryableStatusCodes": statusCodes,
+				},
+			},
+		},
+	})
+
+	return string(b)
+}
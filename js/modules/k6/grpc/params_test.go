@@ -176,3 +176,141 @@ func newParamsTestRuntime(t *testing.T, paramsJSON string) (*modulestest.Runtime
 
 	return testRuntime, params
 }
+
+func TestConnectParamsRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name                  string
+		JSON                  string
+		ErrContains           string
+		ExpectedServiceConfig string
+	}{
+		{
+			Name: "Defaults",
+			JSON: `{ retryPolicy: { maxAttempts: 3 } }`,
+			ExpectedServiceConfig: `{"methodConfig":[{"name":[{}],"retryPolicy":{"backoffMultiplier":2,` +
+				`"initialBackoff":"0.100000000s","maxAttempts":3,"maxBackoff":"1.000000000s","retryableStatusCodes":[14]}}]}`,
+		},
+		{
+			Name: "Custom",
+			JSON: `{ retryPolicy: { maxAttempts: 5, initialBackoff: "5ms", maxBackoff: 2000, ` +
+				`backoffMultiplier: 1.5, retryableStatusCodes: ["ABORTED", 8] } }`,
+			ExpectedServiceConfig: `{"methodConfig":[{"name":[{}],"retryPolicy":{"backoffMultiplier":1.5,` +
+				`"initialBackoff":"0.005000000s","maxAttempts":5,"maxBackoff":"2.000000000s","retryableStatusCodes":[10,8]}}]}`,
+		},
+		{
+			Name:        "MissingMaxAttempts",
+			JSON:        `{ retryPolicy: {} }`,
+			ErrContains: `invalid maxAttempts value: 0, it needs to be between 2 and 5`,
+		},
+		{
+			Name:        "TooManyAttempts",
+			JSON:        `{ retryPolicy: { maxAttempts: 6 } }`,
+			ErrContains: `invalid maxAttempts value: 6, it needs to be between 2 and 5`,
+		},
+		{
+			Name:        "InvalidBackoffMultiplier",
+			JSON:        `{ retryPolicy: { maxAttempts: 2, backoffMultiplier: 0 } }`,
+			ErrContains: `invalid backoffMultiplier value: it needs to be positive`,
+		},
+		{
+			Name:        "InvalidStatusCode",
+			JSON:        `{ retryPolicy: { maxAttempts: 2, retryableStatusCodes: ["SOMETIMES"] } }`,
+			ErrContains: `invalid retryableStatusCodes value: invalid code: "\"SOMETIMES\""`,
+		},
+		{
+			Name:        "NoStatusCodes",
+			JSON:        `{ retryPolicy: { maxAttempts: 2, retryableStatusCodes: [] } }`,
+			ErrContains: `invalid retryableStatusCodes value: it needs at least one status code`,
+		},
+		{
+			Name:        "UnknownParam",
+			JSON:        `{ retryPolicy: { maxAttempts: 2, hedging: true } }`,
+			ErrContains: `invalid retryPolicy param: unknown param: "hedging"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			testRuntime, params := newParamsTestRuntime(t, tc.JSON)
+
+			p, err := newConnectParams(testRuntime.VU, params)
+			if tc.ErrContains != "" {
+				assert.ErrorContains(t, err, tc.ErrContains)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, p.RetryPolicy)
+			assert.JSONEq(t, tc.ExpectedServiceConfig, p.RetryPolicy.serviceConfig())
+		})
+	}
+}
+
+func TestConnectParamsReuse(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name                string
+		JSON                string
+		ErrContains         string
+		ExpectedReuse       bool
+		ExpectedMaxLifetime time.Duration
+	}{
+		{
+			Name: "Default",
+			JSON: `{}`,
+		},
+		{
+			Name:          "Reuse",
+			JSON:          `{ reuse: true }`,
+			ExpectedReuse: true,
+		},
+		{
+			Name:                "MaxLifetime",
+			JSON:                `{ reuse: true, maxLifetime: "30s" }`,
+			ExpectedReuse:       true,
+			ExpectedMaxLifetime: 30 * time.Second,
+		},
+		{
+			Name:        "InvalidReuse",
+			JSON:        `{ reuse: "yes" }`,
+			ErrContains: `invalid reuse value: '"yes"', it needs to be boolean`,
+		},
+		{
+			Name:        "NegativeMaxLifetime",
+			JSON:        `{ reuse: true, maxLifetime: "-1s" }`,
+			ErrContains: `invalid maxLifetime value`,
+		},
+		{
+			Name:        "MaxLifetimeWithoutReuse",
+			JSON:        `{ maxLifetime: "30s" }`,
+			ErrContains: `invalid maxLifetime param: it can only be set when the connection is reused`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			testRuntime, params := newParamsTestRuntime(t, tc.JSON)
+
+			p, err := newConnectParams(testRuntime.VU, params)
+			if tc.ErrContains != "" {
+				assert.ErrorContains(t, err, tc.ErrContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedReuse, p.Reuse)
+			assert.Equal(t, tc.ExpectedMaxLifetime, p.MaxLifetime)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// reflectionCache holds the file descriptors obtained through the server
+// reflection protocol, shared by every VU, so that the reflection data of a
+// server is only fetched once per test run, rather than once per VU.
+type reflectionCache struct {
+	mu      sync.Mutex
+	entries map[string]*reflectionCacheEntry
+}
+
+// reflectionCacheEntry holds the file descriptors obtained from a server.
+type reflectionCacheEntry struct {
+	// mu ensures the file descriptors are only fetched by a single VU, while
+	// the others wait.
+	mu    sync.Mutex
+	fdset *descriptorpb.FileDescriptorSet
+}
+
+// newReflectionCache creates a new, empty, reflectionCache.
+func newReflectionCache() *reflectionCache {
+	return &reflectionCache{entries: make(map[string]*reflectionCacheEntry)}
+}
+
+// get returns the file descriptors of the server at the provided address, as
+// reflected with the given metadata, calling reflect to fetch them unless they
+// were already.
+//
+// Fetching failures are not cached, so that the next call retries.
+func (rc *reflectionCache) get(
+	ctx context.Context,
+	addr string,
+	md metadata.MD,
+	reflect func(context.Context) (*descriptorpb.FileDescriptorSet, error),
+) (*descriptorpb.FileDescriptorSet, error) {
+	key := reflectionCacheKey(addr, md)
+
+	rc.mu.Lock()
+	entry, ok := rc.entries[key]
+	if !ok {
+		entry = &reflectionCacheEntry{}
+		rc.entries[key] = entry
+	}
+	rc.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.fdset != nil {
+		return entry.fdset, nil
+	}
+
+	fdset, err := reflect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.fdset = fdset
+
+	return fdset, nil
+}
+
+// reflectionCacheKey returns the key the file descriptors of the server at the
+// provided address, reflected with the given metadata, are cached under.
+func reflectionCacheKey(addr string, md metadata.MD) string {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(addr)
+	for _, k := range keys {
+		b.WriteString("\n" + k + ":" + strings.Join(md[k], ","))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestReflectionCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ConcurrentGets", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int64
+		want := &descriptorpb.FileDescriptorSet{}
+		reflect := func(context.Context) (*descriptorpb.FileDescriptorSet, error) {
+			calls.Add(1)
+			return want, nil
+		}
+
+		rc := newReflectionCache()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				got, err := rc.get(context.Background(), "localhost:1234", metadata.New(nil), reflect)
+				assert.NoError(t, err)
+				assert.Same(t, want, got)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int64(1), calls.Load())
+	})
+
+	t.Run("KeyedByAddressAndMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int64
+		reflect := func(context.Context) (*descriptorpb.FileDescriptorSet, error) {
+			calls.Add(1)
+			return &descriptorpb.FileDescriptorSet{}, nil
+		}
+
+		rc := newReflectionCache()
+		ctx := context.Background()
+
+		_, err := rc.get(ctx, "localhost:1234", metadata.New(map[string]string{"a": "1", "b": "2"}), reflect)
+		require.NoError(t, err)
+		_, err = rc.get(ctx, "localhost:1234", metadata.New(map[string]string{"b": "2", "a": "1"}), reflect)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), calls.Load())
+
+		_, err = rc.get(ctx, "localhost:1234", metadata.New(map[string]string{"a": "2"}), reflect)
+		require.NoError(t, err)
+		_, err = rc.get(ctx, "localhost:5678", metadata.New(map[string]string{"a": "1", "b": "2"}), reflect)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), calls.Load())
+	})
+
+	t.Run("FailuresNotCached", func(t *testing.T) {
+		t.Parallel()
+
+		rc := newReflectionCache()
+		ctx := context.Background()
+
+		_, err := rc.get(ctx, "localhost:1234", nil, func(context.Context) (*descriptorpb.FileDescriptorSet, error) {
+			return nil, errors.New("unavailable")
+		})
+		require.ErrorContains(t, err, "unavailable")
+
+		want := &descriptorpb.FileDescriptorSet{}
+		got, err := rc.get(ctx, "localhost:1234", nil, func(context.Context) (*descriptorpb.FileDescriptorSet, error) {
+			return want, nil
+		})
+		require.NoError(t, err)
+		assert.Same(t, want, got)
+	})
+}
@@ -1,12 +1,16 @@
 package http
 
 import (
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/metrics"
 )
 
 func wrapInAsyncLambda(input string) string {
@@ -260,3 +264,85 @@ func TestAsyncRequestErrors(t *testing.T) {
 		assert.Contains(t, promiseRejected.ToString(), expErr)
 	})
 }
+
+func TestAsyncRequestResponseTypeStream(t *testing.T) {
+	t.Parallel()
+
+	// readAll reads the stream held by the response's body, and returns its
+	// content as a string.
+	const readAll = `
+		async function readAll(body) {
+			if (typeof body.getReader !== "function") {
+				throw new Error("expected the body to be a ReadableStream, got " + body);
+			}
+
+			const reader = body.getReader();
+			let content = "";
+			while (true) {
+				const { done, value } = await reader.read();
+				if (done) {
+					return content;
+				}
+				for (let i = 0; i < value.length; i++) {
+					content += String.fromCharCode(value[i]);
+				}
+			}
+		}
+	`
+
+	t.Run("ReadAll", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+
+		data := strings.Repeat("0123456789", 20000)
+		ts.tb.Mux.HandleFunc("/stream-data", func(w http.ResponseWriter, _ *http.Request) {
+			_, err := w.Write([]byte(data))
+			assert.NoError(t, err)
+		})
+
+		sr := ts.tb.Replacer.Replace
+		_, err := ts.runtime.RunOnEventLoop(readAll + wrapInAsyncLambda(sr(`
+			const res = await http.asyncRequest("GET", "HTTPBIN_URL/stream-data", null, { responseType: "stream" });
+			if (res.status != 200) { throw new Error("wrong status: " + res.status); }
+
+			const content = await readAll(res.body);
+			if (content.length !== 200000 || content.slice(0, 12) !== "012345678901") {
+				throw new Error("unexpected content of length " + content.length);
+			}
+		`)))
+		require.NoError(t, err)
+
+		assertRequestMetricsEmitted(t, metrics.GetBufferedSamples(ts.samples), "GET", sr("HTTPBIN_URL/stream-data"), 200, "")
+	})
+
+	t.Run("Decompressed", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+
+		sr := ts.tb.Replacer.Replace
+		_, err := ts.runtime.RunOnEventLoop(readAll + wrapInAsyncLambda(sr(`
+			const res = await http.asyncRequest("GET", "HTTPBIN_URL/gzip", null, { responseType: "stream" });
+			const content = JSON.parse(await readAll(res.body));
+			if (content.gzipped !== true) {
+				throw new Error("unexpected content " + JSON.stringify(content));
+			}
+		`)))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+
+		sr := ts.tb.Replacer.Replace
+		_, err := ts.runtime.RunOnEventLoop(wrapInAsyncLambda(sr(`
+			const res = await http.asyncRequest("GET", "HTTPBIN_URL/stream-bytes/100000", null, { responseType: "stream" });
+			const reader = res.body.getReader();
+			await reader.read();
+			await reader.cancel();
+		`)))
+		require.NoError(t, err)
+
+		assertRequestMetricsEmitted(t, metrics.GetBufferedSamples(ts.samples), "GET", sr("HTTPBIN_URL/stream-bytes/100000"), 200, "")
+	})
+}
@@ -1,16 +1,24 @@
 package http
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/cookiejar"
 	neturl "net/url"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/grafana/sobek"
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/event"
 	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib/fsext"
 )
 
 // ErrJarForbiddenInInitContext is used when a cookie jar was made in the init context
@@ -23,6 +31,19 @@ type CookieJar struct {
 	// js is to make it not be accessible from inside Sobek/js, the json is
 	// for when it is returned from setup().
 	Jar *cookiejar.Jar `js:"-" json:"-"`
+
+	// record has the attributes of the cookies of the jar, which it doesn't
+	// expose, so they can be exported.
+	record *cookieRecord
+}
+
+// httpJar returns the jar the requests set their cookies in, which records
+// them so they can be exported.
+func (j CookieJar) httpJar() http.CookieJar {
+	if j.record == nil {
+		return j.Jar
+	}
+	return recordingJar{jar: j.Jar, record: j.record}
 }
 
 // CookiesForURL return the cookies for a given url as a map of key and values
@@ -78,7 +99,7 @@ func (j CookieJar) Set(url, name, value string, opts sobek.Value) (bool, error)
 			}
 		}
 	}
-	j.Jar.SetCookies(u, []*http.Cookie{&c})
+	j.httpJar().SetCookies(u, []*http.Cookie{&c})
 	return true, nil
 }
 
@@ -93,7 +114,7 @@ func (j CookieJar) Clear(url string) error {
 	for _, c := range cookies {
 		c.MaxAge = -1
 	}
-	j.Jar.SetCookies(u, cookies)
+	j.httpJar().SetCookies(u, cookies)
 
 	return nil
 }
@@ -110,7 +131,386 @@ func (j CookieJar) Delete(url, name string) error {
 	}
 
 	c := http.Cookie{Name: name, MaxAge: -1}
-	j.Jar.SetCookies(u, []*http.Cookie{&c})
+	j.httpJar().SetCookies(u, []*http.Cookie{&c})
+
+	return nil
+}
+
+// exportedCookie is a cookie of a jar, as it's exported and imported.
+type exportedCookie struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Domain string `json:"domain"`
+	Path   string `json:"path"`
+	// Expires is empty for the session cookies.
+	Expires  string `json:"expires"`
+	Secure   bool   `json:"secure"`
+	HTTPOnly bool   `json:"http_only"`
+	// HostOnly is true for the cookies which are only sent to their domain,
+	// not to its subdomains.
+	HostOnly bool `json:"host_only"`
+}
+
+// url returns the URL the cookie is sent to, and is set for when imported.
+func (c exportedCookie) url() *neturl.URL {
+	scheme := "http"
+	if c.Secure {
+		scheme = "https"
+	}
+	host := c.Domain
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return &neturl.URL{Scheme: scheme, Host: host, Path: c.Path}
+}
+
+// Export returns the cookies of the jar, which can be imported in another jar
+// with importFrom(), e.g. in a later test run after being written to a file.
+// Only the cookies set by the k6/http requests and methods are exported.
+func (j CookieJar) Export() []exportedCookie {
+	if j.record == nil {
+		return []exportedCookie{}
+	}
+	return j.record.export(j.Jar)
+}
+
+// ImportFrom sets the given cookies, exported by export() as an array or as
+// its JSON, in the jar, and returns the number of cookies that were set.
+func (j CookieJar) ImportFrom(cookies sobek.Value) (int, error) {
+	if common.IsNullish(cookies) {
+		return 0, errors.New("the cookies to import can't be undefined or null")
+	}
+
+	var data []byte
+	if s, ok := cookies.Export().(string); ok {
+		data = []byte(s)
+	} else {
+		var err error
+		if data, err = json.Marshal(cookies.Export()); err != nil {
+			return 0, fmt.Errorf("unable to import the cookies: %w", err)
+		}
+	}
+	var exported []exportedCookie
+	if err := json.Unmarshal(data, &exported); err != nil {
+		return 0, fmt.Errorf("unable to import the cookies, they need to be an array of cookies: %w", err)
+	}
+
+	return importCookies(j.httpJar(), exported)
+}
+
+func importCookies(jar http.CookieJar, exported []exportedCookie) (int, error) {
+	for i, ec := range exported {
+		if ec.Name == "" || ec.Domain == "" {
+			return i, fmt.Errorf("unable to import the cookie %d, it needs a name and a domain", i)
+		}
+		c := &http.Cookie{
+			Name:     ec.Name,
+			Value:    ec.Value,
+			Path:     ec.Path,
+			Secure:   ec.Secure,
+			HttpOnly: ec.HTTPOnly,
+		}
+		if !ec.HostOnly {
+			c.Domain = ec.Domain
+		}
+		if ec.Expires != "" {
+			expires, err := time.Parse(http.TimeFormat, ec.Expires)
+			if err != nil {
+				return i, fmt.Errorf(`unable to parse the "expires" date string %q of the cookie %d: %w`, ec.Expires, i, err)
+			}
+			c.Expires = expires
+		}
+		jar.SetCookies(ec.url(), []*http.Cookie{c})
+	}
+	return len(exported), nil
+}
+
+// recordingJar is a cookie jar which records the cookies set in it.
+type recordingJar struct {
+	jar    *cookiejar.Jar
+	record *cookieRecord
+}
+
+var _ http.CookieJar = recordingJar{}
+
+func (r recordingJar) SetCookies(u *neturl.URL, cookies []*http.Cookie) {
+	r.jar.SetCookies(u, cookies)
+	r.record.add(u, cookies)
+}
+
+func (r recordingJar) Cookies(u *neturl.URL) []*http.Cookie {
+	return r.jar.Cookies(u)
+}
+
+// cookieKey identifies a cookie, like the jar does.
+type cookieKey struct {
+	domain, path, name string
+}
+
+// recordedCookie is a cookie, as it was set in the jar.
+type recordedCookie struct {
+	exportedCookie
+	expires time.Time
+}
+
+// cookieRecord has the attributes of the cookies set in a jar. A cookie of the
+// record can be gone from the jar, e.g. expired or replaced by a cookie of
+// another domain, so the jar is checked when they are exported.
+type cookieRecord struct {
+	mu      sync.Mutex
+	cookies map[cookieKey]recordedCookie
+}
+
+func newCookieRecord() *cookieRecord {
+	return &cookieRecord{cookies: make(map[cookieKey]recordedCookie)}
+}
+
+// add records the cookies set in the jar for the URL, with the defaults of
+// their domain, path and expiration applied like the jar does.
+func (r *cookieRecord) add(u *neturl.URL, cookies []*http.Cookie) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range cookies {
+		if c.Name == "" {
+			continue
+		}
+		rc := recordedCookie{exportedCookie: exportedCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   strings.ToLower(strings.TrimPrefix(c.Domain, ".")),
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+		}}
+		if rc.Domain == "" {
+			rc.Domain = strings.ToLower(u.Hostname())
+			rc.HostOnly = true
+		}
+		if rc.Path == "" || rc.Path[0] != '/' {
+			rc.Path = defaultCookiePath(u.Path)
+		}
+
+		key := cookieKey{domain: rc.Domain, path: rc.Path, name: rc.Name}
+		switch {
+		case c.MaxAge < 0:
+			delete(r.cookies, key)
+			continue
+		case c.MaxAge > 0:
+			rc.expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case !c.Expires.IsZero():
+			if !c.Expires.After(now) {
+				delete(r.cookies, key)
+				continue
+			}
+			rc.expires = c.Expires
+		}
+		r.cookies[key] = rc
+	}
+}
+
+// export returns the recorded cookies which are still in the jar, sorted by
+// their domain, path and name.
+func (r *cookieRecord) export(jar *cookiejar.Jar) []exportedCookie {
+	r.mu.Lock()
+	recorded := make([]recordedCookie, 0, len(r.cookies))
+	for _, rc := range r.cookies {
+		recorded = append(recorded, rc)
+	}
+	r.mu.Unlock()
+
+	exported := make([]exportedCookie, 0, len(recorded))
+	for _, rc := range recorded {
+		if !jarHasCookie(jar, rc.exportedCookie) {
+			continue
+		}
+		ec := rc.exportedCookie
+		if !rc.expires.IsZero() {
+			ec.Expires = rc.expires.UTC().Format(http.TimeFormat)
+		}
+		exported = append(exported, ec)
+	}
+	sortCookies(exported)
+	return exported
+}
+
+// sortCookies sorts the cookies by their domain, path and name.
+func sortCookies(cookies []exportedCookie) {
+	sort.Slice(cookies, func(i, k int) bool {
+		a, b := cookies[i], cookies[k]
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Name < b.Name
+	})
+}
+
+// jarHasCookie returns whether the jar sends the cookie to its URL.
+func jarHasCookie(jar *cookiejar.Jar, ec exportedCookie) bool {
+	for _, c := range jar.Cookies(ec.url()) {
+		if c.Name == ec.Name && c.Value == ec.Value {
+			return true
+		}
+	}
+	return false
+}
 
+// defaultCookiePath returns the path of the cookies which don't have one, the
+// directory of the URL's path, as described in RFC 6265 section 5.1.4.
+func defaultCookiePath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+// persistTo makes the jar file-backed: it imports the cookies of the file at
+// the path, if it exists, and its cookies are written back to it once the test
+// ends, so they persist across test runs. It needs the file writes to be
+// allowed.
+//
+// Every VU runs the init context, so each of them makes its own jar backed by
+// the file. They all import the cookies it held when the test started, and the
+// cookies of all of them are merged when the file is written: a cookie is kept
+// if any of the jars has it, and if they have different values for it, one of
+// them is kept.
+func (j CookieJar) persistTo(initEnv *common.InitEnvironment, path string) error {
+	if !initEnv.RuntimeOptions.AllowFileWrites.Bool {
+		return errors.New("writing files is not allowed, use the --allow-file-writes flag to allow it")
+	}
+	fs, ok := initEnv.FileSystems["file"]
+	if !ok {
+		return errors.New("unable to access the file system")
+	}
+	path = initEnv.GetAbsFilePath(path)
+
+	file, err := j.moduleInstance.rootModule.cookieFiles.get(j.moduleInstance.vu, initEnv, fs, path)
+	if err != nil {
+		return err
+	}
+	if _, err = importCookies(j.httpJar(), file.cookies); err != nil {
+		return fmt.Errorf("unable to import the cookies of %q: %w", path, err)
+	}
+	file.addJar(j)
 	return nil
 }
+
+// cookieFiles has the files backing the cookie jars of the test run, by path,
+// which the jars of all of the VUs share.
+type cookieFiles struct {
+	mu    sync.Mutex
+	files map[string]*cookieFile
+}
+
+// cookieFile is a file backing cookie jars. It's read when the first jar backed
+// by it is made, and written once, with the cookies of all of the jars merged,
+// when the test ends, rather than every time they change, so the requests
+// aren't slowed down, and the VUs don't overwrite each other's cookies.
+type cookieFile struct {
+	fs     fsext.Fs
+	path   string
+	logger logrus.FieldLogger
+
+	// cookies are the ones the file held when it was read.
+	cookies []exportedCookie
+
+	mu   sync.Mutex
+	jars []CookieJar
+}
+
+// get returns the file at the path, which is read if it's the first time it's
+// asked for. The file is then written once the test ends.
+func (cf *cookieFiles) get(
+	vu modules.VU, initEnv *common.InitEnvironment, fs fsext.Fs, path string,
+) (*cookieFile, error) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	if file, ok := cf.files[path]; ok {
+		return file, nil
+	}
+
+	file := &cookieFile{fs: fs, path: path, logger: initEnv.Logger.WithField("file", path)}
+	exists, err := fsext.Exists(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		data, err := fsext.ReadFile(fs, path)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(data, &file.cookies); err != nil {
+			return nil, fmt.Errorf("unable to import the cookies of %q: %w", path, err)
+		}
+	}
+
+	if events := vu.Events().Global; events != nil {
+		subID, eventsCh := events.Subscribe(event.TestEnd, event.Exit)
+		go func() {
+			for evt := range eventsCh {
+				if evt.Type == event.TestEnd {
+					file.write()
+				}
+				evt.Done()
+				if evt.Type == event.Exit {
+					events.Unsubscribe(subID)
+					return
+				}
+			}
+		}()
+	}
+
+	if cf.files == nil {
+		cf.files = make(map[string]*cookieFile)
+	}
+	cf.files[path] = file
+	return file, nil
+}
+
+// addJar adds a jar backed by the file.
+func (f *cookieFile) addJar(j CookieJar) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.jars = append(f.jars, j)
+}
+
+// write writes the merged cookies of the jars to the file. They are written to
+// a temporary file first, which then replaces it, so it's never left
+// partially written.
+func (f *cookieFile) write() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	merged := make(map[cookieKey]exportedCookie)
+	for _, j := range f.jars {
+		for _, ec := range j.Export() {
+			merged[cookieKey{domain: ec.Domain, path: ec.Path, name: ec.Name}] = ec
+		}
+	}
+	cookies := make([]exportedCookie, 0, len(merged))
+	for _, ec := range merged {
+		cookies = append(cookies, ec)
+	}
+	sortCookies(cookies)
+
+	data, err := json.Marshal(cookies)
+	if err == nil {
+		tmpPath := f.path + ".tmp"
+		if err = fsext.WriteFile(f.fs, tmpPath, data, 0o644); err == nil {
+			err = f.fs.Rename(tmpPath, f.path)
+		}
+	}
+	if err != nil {
+		f.logger.WithError(err).Warn("Unable to write the cookies of the jars to their file")
+	}
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/cookiejar"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/fsext"
+)
+
+func TestCookieJarExportImport(t *testing.T) {
+	t.Parallel()
+	ts := newTestCase(t)
+	tb := ts.tb
+	rt := ts.runtime.VU.Runtime()
+	state := ts.runtime.VU.State()
+	sr := tb.Replacer.Replace
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	state.CookieJar = jar
+
+	t.Run("VUJar", func(t *testing.T) {
+		v, err := rt.RunString(sr(`
+			http.get("HTTPBIN_URL/cookies/set?key=value&other=1");
+			var vuJar = http.cookieJar();
+			vuJar.delete("HTTPBIN_URL/cookies/", "other");
+			JSON.stringify(vuJar.export());
+		`))
+		require.NoError(t, err)
+
+		var exported []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(v.String()), &exported))
+		assert.Equal(t, []map[string]any{{
+			"name":      "key",
+			"value":     "value",
+			"domain":    sr("HTTPBIN_DOMAIN"),
+			"path":      "/cookies",
+			"expires":   "",
+			"secure":    false,
+			"http_only": true,
+			"host_only": true,
+		}}, exported)
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		_, err := rt.RunString(sr(`
+			var jar = new http.CookieJar();
+			jar.set("HTTPBIN_URL/cookies", "session", "abc", {
+				domain: "HTTPBIN_DOMAIN", path: "/cookies", expires: "Mon, 02 Jan 2040 15:04:05 GMT", http_only: true,
+			});
+			jar.set("HTTPBIN_URL/cookies", "expired", "1", { max_age: -1 });
+			var exported = jar.export();
+			if (exported.length !== 1) { throw new Error("wrong exported cookies: " + JSON.stringify(exported)); }
+			var cookie = exported[0];
+			if (cookie.host_only !== false || cookie.path !== "/cookies" || !cookie.http_only ||
+				cookie.expires !== "Mon, 02 Jan 2040 15:04:05 GMT") {
+				throw new Error("wrong exported cookie: " + JSON.stringify(cookie));
+			}
+
+			for (var cookies of [exported, JSON.stringify(exported)]) {
+				var imported = new http.CookieJar();
+				if (imported.importFrom(cookies) !== 1) { throw new Error("wrong number of imported cookies"); }
+				var res = http.get("HTTPBIN_URL/cookies", { jar: imported });
+				if (res.json().session !== "abc") { throw new Error("wrong cookies: " + res.body); }
+				if (JSON.stringify(imported.export()) !== JSON.stringify(exported)) {
+					throw new Error("wrong re-exported cookies: " + JSON.stringify(imported.export()));
+				}
+			}
+		`))
+		require.NoError(t, err)
+	})
+
+	t.Run("InvalidImport", func(t *testing.T) {
+		tests := map[string]string{
+			`null`:            "the cookies to import can't be undefined or null",
+			`"{}"`:            "unable to import the cookies, they need to be an array of cookies",
+			`[{ name: "a" }]`: "unable to import the cookie 0, it needs a name and a domain",
+			`[{ name: "a", domain: "example.com", expires: "tomorrow" }]`: `unable to parse the "expires" date string "tomorrow" of the cookie 0`,
+		}
+		for cookies, expErr := range tests {
+			_, err := rt.RunString(`new http.CookieJar().importFrom(` + cookies + `)`)
+			require.Error(t, err, cookies)
+			assert.Contains(t, err.Error(), expErr, cookies)
+		}
+	})
+}
+
+func TestCookieJarFile(t *testing.T) {
+	t.Parallel()
+	ts := newTestCase(t)
+	tb := ts.tb
+	rt := ts.runtime.VU.Runtime()
+	sr := tb.Replacer.Replace
+
+	fs := fsext.NewMemMapFs()
+	saved := sr(`[{"name":"saved","value":"1","domain":"HTTPBIN_DOMAIN","path":"/","host_only":true}]`)
+	require.NoError(t, fsext.WriteFile(fs, "/jars/vu.json", []byte(saved), 0o644))
+
+	initEnv := func(allowFileWrites bool) *common.InitEnvironment {
+		return &common.InitEnvironment{
+			TestPreInitState: &lib.TestPreInitState{
+				Logger:         ts.logger,
+				RuntimeOptions: lib.RuntimeOptions{AllowFileWrites: null.BoolFrom(allowFileWrites)},
+			},
+			FileSystems: map[string]fsext.Fs{"file": fs},
+			CWD:         &url.URL{Scheme: "file", Path: "/jars/"},
+		}
+	}
+
+	events := event.NewEventSystem(10, ts.logger)
+	ts.runtime.VU.EventsField = common.Events{Global: events}
+
+	// the file-backed jars are made in the init context
+	state := ts.runtime.VU.StateField
+	ts.runtime.VU.StateField = nil
+	ts.runtime.VU.InitEnvField = initEnv(false)
+	_, err := rt.RunString(`new http.CookieJar({ file: "vu.json" })`)
+	require.ErrorContains(t, err, "unable to make a file-backed cookie jar: writing files is not allowed")
+
+	ts.runtime.VU.InitEnvField = initEnv(true)
+	_, err = rt.RunString(`
+		var jar = new http.CookieJar({ file: "vu.json" });
+		var other = new http.CookieJar({ file: "vu.json" });
+	`)
+	require.NoError(t, err)
+	ts.runtime.MoveToVUContext(state)
+
+	_, err = rt.RunString(`new http.CookieJar({ file: "vu.json" })`)
+	require.ErrorContains(t, err, "Making file-backed cookie jars outside of the init context is not supported")
+
+	_, err = rt.RunString(sr(`
+		var res = http.get("HTTPBIN_URL/cookies/set?key=value", { jar: jar });
+		var cookies = res.json();
+		if (cookies.saved !== "1" || cookies.key !== "value") { throw new Error("wrong cookies: " + res.body); }
+
+		res = http.get("HTTPBIN_URL/cookies/set?other=1", { jar: other });
+		cookies = res.json();
+		if (cookies.saved !== "1" || cookies.other !== "1" || cookies.key) { throw new Error("wrong cookies: " + res.body); }
+	`))
+	require.NoError(t, err)
+
+	// the file is only written once the test ends
+	data, err := fsext.ReadFile(fs, "/jars/vu.json")
+	require.NoError(t, err)
+	assert.Equal(t, saved, string(data))
+
+	require.NoError(t, events.Emit(&event.Event{Type: event.TestEnd})(context.Background()))
+
+	// the cookies of the jars are merged
+	data, err = fsext.ReadFile(fs, "/jars/vu.json")
+	require.NoError(t, err)
+	var exported []exportedCookie
+	require.NoError(t, json.Unmarshal(data, &exported))
+	names := make([]string, 0, len(exported))
+	for _, ec := range exported {
+		names = append(names, ec.Name)
+	}
+	assert.Equal(t, []string{"saved", "key", "other"}, names)
+
+	require.NoError(t, events.Emit(&event.Event{Type: event.Exit})(context.Background()))
+}
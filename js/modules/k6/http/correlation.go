@@ -0,0 +1,242 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+)
+
+// The ways the correlated values are sent along with the next requests.
+const (
+	correlateAsField  = "field"
+	correlateAsHeader = "header"
+	correlateAsQuery  = "query"
+)
+
+// correlationRule extracts values from the body of a response, to send them
+// along with the next requests of the navigation flow, e.g. CSRF tokens.
+type correlationRule struct {
+	// name is the name of the field, header or query parameter the values are
+	// sent as. For the selector rules, it's the name attribute of each matched
+	// element by default.
+	name string
+
+	// selector matches the elements whose attribute, or text content if
+	// attribute is empty, are the values.
+	selector  string
+	attribute string
+
+	// regex is matched against the body otherwise, its first capture group, or
+	// the whole match if it has none, being the value.
+	regex *regexp.Regexp
+
+	// target is the way the values are sent: as form fields, headers, or query
+	// parameters.
+	target string
+}
+
+// defaultCorrelationRules extract the hidden fields of the page, which carry
+// the CSRF tokens and the ASP.NET ViewState-style values, and the CSRF tokens
+// of the meta tags, which are sent as headers.
+//
+//nolint:gochecknoglobals
+var defaultCorrelationRules = []correlationRule{
+	{selector: "input[type=hidden][name]", attribute: "value", target: correlateAsField},
+	{name: "X-CSRF-Token", selector: "meta[name=csrf-token]", attribute: "content", target: correlateAsHeader},
+	{name: "X-CSRF-Token", selector: "meta[name=_csrf]", attribute: "content", target: correlateAsHeader},
+}
+
+// correlatedValue is a value extracted by a correlation rule.
+type correlatedValue struct {
+	target, name, value string
+}
+
+// correlatedValues are the values extracted across a navigation flow, by the
+// target and the name they're sent as.
+type correlatedValues map[string]correlatedValue
+
+// add adds the value, replacing the one of the same target and name, if any.
+func (cv correlatedValues) add(target, name, value string) {
+	cv[target+":"+name] = correlatedValue{target: target, name: name, value: value}
+}
+
+// get returns the values of the given target, by their name.
+func (cv correlatedValues) get(target string) map[string]string {
+	values := make(map[string]string)
+	for _, v := range cv {
+		if v.target == target {
+			values[v.name] = v.value
+		}
+	}
+	return values
+}
+
+// parseCorrelate parses the correlate param of submitForm() and clickLink(),
+// either true for the default rules, or an object with the extraction rules,
+// which are added to the default ones unless defaults is false.
+func parseCorrelate(rt *sobek.Runtime, v sobek.Value) ([]correlationRule, error) {
+	if common.IsNullish(v) {
+		return nil, nil
+	}
+	obj, ok := v.(*sobek.Object)
+	if !ok {
+		if !v.ToBoolean() {
+			return nil, nil
+		}
+		return defaultCorrelationRules, nil
+	}
+
+	defaults := true
+	rules := []correlationRule{}
+	for _, k := range obj.Keys() {
+		switch k {
+		case "defaults":
+			defaults = obj.Get(k).ToBoolean()
+		case "rules":
+			var raw []map[string]string
+			if err := rt.ExportTo(obj.Get(k), &raw); err != nil {
+				return nil, errors.New("rules needs to be an array of rule objects")
+			}
+			for i, r := range raw {
+				rule, err := parseCorrelationRule(r)
+				if err != nil {
+					return nil, fmt.Errorf("invalid rule %d: %w", i, err)
+				}
+				rules = append(rules, rule)
+			}
+		default:
+			return nil, fmt.Errorf("unknown key %q", k)
+		}
+	}
+
+	if defaults {
+		rules = append(append([]correlationRule{}, defaultCorrelationRules...), rules...)
+	}
+	return rules, nil
+}
+
+func parseCorrelationRule(raw map[string]string) (correlationRule, error) {
+	rule := correlationRule{target: correlateAsField}
+	for k, v := range raw {
+		switch k {
+		case "name":
+			rule.name = v
+		case "selector":
+			rule.selector = v
+		case "attribute":
+			rule.attribute = v
+		case "regex":
+			re, err := regexp.Compile(v)
+			if err != nil {
+				return rule, fmt.Errorf("invalid regex: %w", err)
+			}
+			rule.regex = re
+		case "target":
+			switch v {
+			case correlateAsField, correlateAsHeader, correlateAsQuery:
+				rule.target = v
+			default:
+				return rule, fmt.Errorf("unsupported target %q, it needs to be field, header, or query", v)
+			}
+		default:
+			return rule, fmt.Errorf("unknown key %q", k)
+		}
+	}
+
+	switch {
+	case (rule.selector == "") == (rule.regex == nil):
+		return rule, errors.New("a rule needs either a selector or a regex")
+	case rule.regex != nil && rule.name == "":
+		return rule, errors.New("a regex rule needs a name")
+	}
+	return rule, nil
+}
+
+// correlate returns the values extracted from the response by the rules,
+// added to the ones extracted earlier in the navigation flow.
+func (res *Response) correlate(rules []correlationRule) (correlatedValues, error) {
+	values := make(correlatedValues, len(res.correlated))
+	for k, v := range res.correlated {
+		values[k] = v
+	}
+	if res.Body == nil {
+		return values, nil
+	}
+
+	body, err := common.ToString(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		if rule.regex != nil {
+			match := rule.regex.FindStringSubmatch(body)
+			switch {
+			case len(match) > 1:
+				values.add(rule.target, rule.name, match[1])
+			case len(match) == 1:
+				values.add(rule.target, rule.name, match[0])
+			}
+			continue
+		}
+
+		doc.Find(rule.selector).Each(func(_ int, sel *goquery.Selection) {
+			name := rule.name
+			if name == "" {
+				name = sel.AttrOr("name", "")
+			}
+			if name == "" {
+				return
+			}
+			if rule.attribute == "" {
+				values.add(rule.target, name, sel.Text())
+			} else if value, ok := sel.Attr(rule.attribute); ok {
+				values.add(rule.target, name, value)
+			}
+		})
+	}
+
+	return values, nil
+}
+
+// withCorrelatedHeaders returns the request params with the correlated
+// headers, which the headers of the params take precedence over.
+func withCorrelatedHeaders(rt *sobek.Runtime, params sobek.Value, headers map[string]string) sobek.Value {
+	if len(headers) == 0 {
+		return params
+	}
+
+	merged := rt.NewObject()
+	mergedHeaders := rt.NewObject()
+	for name, value := range headers {
+		_ = mergedHeaders.Set(name, value)
+	}
+	if !common.IsNullish(params) {
+		obj := params.ToObject(rt)
+		for _, k := range obj.Keys() {
+			if k != "headers" {
+				_ = merged.Set(k, obj.Get(k))
+				continue
+			}
+			if userHeaders := obj.Get(k); !common.IsNullish(userHeaders) {
+				h := userHeaders.ToObject(rt)
+				for _, name := range h.Keys() {
+					_ = mergedHeaders.Set(name, h.Get(name))
+				}
+			}
+		}
+	}
+	_ = merged.Set("headers", mergedHeaders)
+
+	return merged
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/cookiejar"
 
@@ -16,7 +17,10 @@ import (
 //
 // TODO: add sync.Once for all of the deprecation warnings we might want to do
 // for the old k6/http APIs here, so they are shown only once in a test run.
-type RootModule struct{}
+type RootModule struct {
+	// cookieFiles has the files backing the cookie jars, which the VUs share.
+	cookieFiles cookieFiles
+}
 
 // ModuleInstance represents an instance of the HTTP module for every VU.
 type ModuleInstance struct {
@@ -24,6 +28,17 @@ type ModuleInstance struct {
 	rootModule    *RootModule
 	defaultClient *Client
 	exports       *sobek.Object
+
+	// connPool keeps track of the connections of the VU's requests.
+	connPool *httpext.ConnPool
+	// clientCerts has the transports of the VU's requests with their own
+	// client certificate.
+	clientCerts *httpext.ClientCertTransports
+
+	// vuJar is the VU's cookie jar the vuJarRecord has the cookies of, which
+	// are recorded from scratch when the jar is reset for a new iteration.
+	vuJar       *cookiejar.Jar
+	vuJarRecord *cookieRecord
 }
 
 var (
@@ -40,9 +55,11 @@ func New() *RootModule {
 func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 	rt := vu.Runtime()
 	mi := &ModuleInstance{
-		vu:         vu,
-		rootModule: r,
-		exports:    rt.NewObject(),
+		vu:          vu,
+		rootModule:  r,
+		exports:     rt.NewObject(),
+		connPool:    httpext.NewConnPool(),
+		clientCerts: httpext.NewClientCertTransports(),
 	}
 	mi.defineConstants()
 
@@ -89,6 +106,8 @@ func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 	mustExport("asyncRequest", mi.defaultClient.asyncRequest)
 	mustExport("batch", mi.defaultClient.Batch)
 	mustExport("setResponseCallback", mi.defaultClient.SetResponseCallback)
+	mustExport("connectionStats", mi.connectionStats)
+	mustExport("closeIdleConnections", mi.closeIdleConnections)
 
 	mustExport("expectedStatuses", mi.expectedStatuses) // TODO: refactor?
 
@@ -141,25 +160,81 @@ func (mi *ModuleInstance) defineConstants() {
 	mustAddProp("OCSP_REASON_AA_COMPROMISE", netext.OCSP_REASON_AA_COMPROMISE)
 }
 
-func (mi *ModuleInstance) newCookieJar(_ sobek.ConstructorCall) *sobek.Object {
+// newCookieJar is the JS constructor of the cookie jars. The options can hold
+// the `file` which backs the jar, which needs to be made in the init context.
+func (mi *ModuleInstance) newCookieJar(call sobek.ConstructorCall) *sobek.Object {
 	rt := mi.vu.Runtime()
 	jar, err := cookiejar.New(nil)
 	if err != nil {
 		common.Throw(rt, err)
 	}
-	return rt.ToValue(&CookieJar{mi, jar}).ToObject(rt)
+	cj := &CookieJar{moduleInstance: mi, Jar: jar, record: newCookieRecord()}
+
+	if opts := call.Argument(0); !common.IsNullish(opts) {
+		params := opts.ToObject(rt)
+		for _, k := range params.Keys() {
+			switch k {
+			case "file":
+				initEnv := mi.vu.InitEnv()
+				if initEnv == nil {
+					common.Throw(rt, common.NewInitContextError(
+						"Making file-backed cookie jars outside of the init context is not supported"))
+				}
+				if err = cj.persistTo(initEnv, params.Get(k).String()); err != nil {
+					common.Throw(rt, fmt.Errorf("unable to make a file-backed cookie jar: %w", err))
+				}
+			default:
+				common.Throw(rt, fmt.Errorf("unknown cookie jar option %q", k))
+			}
+		}
+	}
+
+	return rt.ToValue(cj).ToObject(rt)
 }
 
 // getVUCookieJar returns the active cookie jar for the current VU.
 func (mi *ModuleInstance) getVUCookieJar(_ sobek.FunctionCall) sobek.Value {
 	rt := mi.vu.Runtime()
 	if state := mi.vu.State(); state != nil {
-		return rt.ToValue(&CookieJar{mi, state.CookieJar})
+		return rt.ToValue(&CookieJar{moduleInstance: mi, Jar: state.CookieJar, record: mi.vuCookieRecord(state.CookieJar)})
 	}
 	common.Throw(rt, ErrJarForbiddenInInitContext)
 	return nil
 }
 
+// vuCookieRecord returns the record of the cookies of the VU's cookie jar.
+func (mi *ModuleInstance) vuCookieRecord(jar *cookiejar.Jar) *cookieRecord {
+	if mi.vuJar != jar {
+		mi.vuJar, mi.vuJarRecord = jar, newCookieRecord()
+	}
+	return mi.vuJarRecord
+}
+
+// errConnectionsForbiddenInInitContext is used when the connections were closed in the init context
+var errConnectionsForbiddenInInitContext = common.NewInitContextError(
+	"Closing connections in the init context is not supported")
+
+// connectionStats returns the stats of the connections the VU's requests were
+// made over, which are still open.
+func (mi *ModuleInstance) connectionStats() httpext.ConnStats {
+	return mi.connPool.Stats()
+}
+
+// closeIdleConnections closes the VU's connections not serving any request.
+func (mi *ModuleInstance) closeIdleConnections() error {
+	state := mi.vu.State()
+	if state == nil {
+		return errConnectionsForbiddenInInitContext
+	}
+
+	if t, ok := state.Transport.(interface{ CloseIdleConnections() }); ok {
+		t.CloseIdleConnections()
+	}
+	mi.clientCerts.CloseIdleConnections()
+
+	return nil
+}
+
 // URL creates a new URL wrapper from the provided parts.
 func (mi *ModuleInstance) URL(parts []string, pieces ...string) (httpext.URL, error) {
 	var name, urlstr string
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/netext/httpext"
+	"go.k6.io/k6/metrics"
+)
+
+// newSOCKS5Server starts a minimal SOCKS5 proxy, with a username and password
+// authentication, which only supports the CONNECT command. It returns the
+// address of the proxy and a counter of the connections it proxied.
+func newSOCKS5Server(t *testing.T, username, password string) (string, *int64) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	var proxied int64
+	handle := func(conn net.Conn) error {
+		defer func() { _ = conn.Close() }()
+
+		// greeting, with the username and password method
+		buf := make([]byte, 2)
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, make([]byte, buf[1])); err != nil {
+			return err
+		}
+		if _, err := conn.Write([]byte{5, 2}); err != nil {
+			return err
+		}
+
+		// authentication
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return err
+		}
+		user := make([]byte, buf[1])
+		if _, err := io.ReadFull(conn, user); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return err
+		}
+		pass := make([]byte, buf[0])
+		if _, err := io.ReadFull(conn, pass); err != nil {
+			return err
+		}
+		if string(user) != username || string(pass) != password {
+			_, err := conn.Write([]byte{1, 1})
+			return err
+		}
+		if _, err := conn.Write([]byte{1, 0}); err != nil {
+			return err
+		}
+
+		// the connect request, with an IPv4 address or a domain name
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return err
+		}
+		var host string
+		switch header[3] {
+		case 1:
+			ip := make([]byte, 4)
+			if _, err := io.ReadFull(conn, ip); err != nil {
+				return err
+			}
+			host = net.IP(ip).String()
+		case 3:
+			if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+				return err
+			}
+			name := make([]byte, buf[0])
+			if _, err := io.ReadFull(conn, name); err != nil {
+				return err
+			}
+			host = string(name)
+		}
+		port := make([]byte, 2)
+		if _, err := io.ReadFull(conn, port); err != nil {
+			return err
+		}
+
+		target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = target.Close() }()
+		if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+			return err
+		}
+		atomic.AddInt64(&proxied, 1)
+
+		go func() { _, _ = io.Copy(target, conn) }()
+		_, err = io.Copy(conn, target)
+		return err
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() { _ = handle(conn) }()
+		}
+	}()
+
+	return listener.Addr().String(), &proxied
+}
+
+func TestRequestProxy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("HTTP", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+		ts.tb.HTTPTransport.Proxy = httpext.ProxyFromContext(nil)
+		tags := metrics.DefaultSystemTagSet | metrics.SystemTagSet(metrics.TagProxy)
+		ts.runtime.VU.State().Options.SystemTags = &tags
+
+		// The proxy responds to the requests itself, instead of forwarding them.
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, r.URL.String()+" "+r.Header.Get("Proxy-Authorization"))
+		}))
+		t.Cleanup(proxy.Close)
+
+		_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+			var res = http.get("HTTPBIN_URL/get", { proxy: "` + "http://user:pass@" + proxy.Listener.Addr().String() + `" });
+			if (res.body !== "HTTPBIN_URL/get Basic dXNlcjpwYXNz") {
+				throw new Error("the request wasn't proxied: " + res.body);
+			}
+			res = http.get("HTTPBIN_URL/get");
+			if (res.json().url !== "HTTPBIN_URL/get") {
+				throw new Error("the request without a proxy was proxied: " + res.body);
+			}
+		`))
+		require.NoError(t, err)
+
+		var proxyTags []string
+		for _, container := range metrics.GetBufferedSamples(ts.samples) {
+			for _, sample := range container.GetSamples() {
+				if sample.Metric.Name != metrics.HTTPReqsName {
+					continue
+				}
+				tag, _ := sample.Tags.Get("proxy")
+				proxyTags = append(proxyTags, tag)
+			}
+		}
+		assert.Equal(t, []string{"http://" + proxy.Listener.Addr().String(), ""}, proxyTags)
+	})
+
+	t.Run("SOCKS5", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+		ts.tb.HTTPTransport.Proxy = httpext.ProxyFromContext(nil)
+		addr, proxied := newSOCKS5Server(t, "user", "pass")
+
+		// The SOCKS5 proxy resolves the hostnames itself, so the IPs are used.
+		_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+			var params = { proxy: "socks5://user:pass@` + addr + `" };
+			var res = http.get("HTTPBIN_IP_URL/get", params);
+			if (res.status !== 200 || res.json().url !== "HTTPBIN_IP_URL/get") {
+				throw new Error("unexpected response: " + res.status + " " + res.body);
+			}
+			res = http.get("HTTPSBIN_IP_URL/get", params);
+			if (res.status !== 200) {
+				throw new Error("unexpected HTTPS response: " + res.status + " " + res.body);
+			}
+			var responses = http.batch([
+				["GET", "HTTPBIN_IP_URL/get", null, params],
+				["GET", "HTTPBIN_IP_URL/get", null, { proxy: "socks5://user:wrong@` + addr + `", throw: false }],
+			]);
+			if (responses[0].status !== 200 || !responses[1].error.includes("authentication failed")) {
+				throw new Error("unexpected batch responses: " + responses[0].status + " " + responses[1].status);
+			}
+		`))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), atomic.LoadInt64(proxied))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+
+		_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+			http.get("HTTPBIN_URL/get", { proxy: "ftp://127.0.0.1:21" });
+		`))
+		require.ErrorContains(t, err, `invalid proxy value: unsupported proxy scheme "ftp"`)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
@@ -16,6 +17,8 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules/k6/experimental/streams"
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/netext/httpext"
 	"go.k6.io/k6/lib/types"
 )
@@ -122,9 +125,9 @@ func (c *Client) asyncRequest(method string, url sobek.Value, args ...sobek.Valu
 	return p, nil
 }
 
-// processResponse stores the body as an ArrayBuffer if indicated by
-// respType. This is done here instead of in httpext.readResponseBody to avoid
-// a reverse dependency on js/common or sobek.
+// processResponse stores the body as an ArrayBuffer, or a ReadableStream, if
+// indicated by respType. This is done here instead of in httpext.readResponseBody
+// to avoid a reverse dependency on js/common or sobek.
 func (c *Client) processResponse(resp *httpext.Response, respType httpext.ResponseType) {
 	if respType == httpext.ResponseTypeBinary && resp.Body != nil {
 		b, ok := resp.Body.([]byte)
@@ -133,6 +136,14 @@ func (c *Client) processResponse(resp *httpext.Response, respType httpext.Respon
 		}
 		resp.Body = c.moduleInstance.vu.Runtime().NewArrayBuffer(b)
 	}
+
+	if respType == httpext.ResponseTypeStream && resp.Body != nil {
+		rc, ok := resp.Body.(io.ReadCloser)
+		if !ok {
+			panic("got an unexpected type for the response body, only io.ReadCloser is accepted")
+		}
+		resp.Body = streams.NewReadableStreamFromReadCloser(c.moduleInstance.vu, rc)
+	}
 }
 
 func (c *Client) responseFromHTTPext(resp *httpext.Response) *Response {
@@ -171,6 +182,7 @@ func (c *Client) parseRequest(
 		Redirects:        state.Options.MaxRedirects,
 		Cookies:          make(map[string]*httpext.HTTPRequestCookie),
 		ResponseCallback: c.responseCallback,
+		ConnPool:         c.moduleInstance.connPool,
 		TagsAndMeta:      c.moduleInstance.vu.State().Tags.GetCurrentValues(),
 	}
 
@@ -280,7 +292,7 @@ func (c *Client) parseRequest(
 	result.Req.Header.Set("User-Agent", state.Options.UserAgent.String)
 
 	if state.CookieJar != nil {
-		result.ActiveJar = state.CookieJar
+		result.ActiveJar = recordingJar{jar: state.CookieJar, record: c.moduleInstance.vuCookieRecord(state.CookieJar)}
 	}
 
 	// TODO: ditch sobek.Value, reflections and Object and use a simple go map and type assertions?
@@ -341,7 +353,7 @@ func (c *Client) parseRequest(
 					continue
 				}
 				if v, ok := jarV.Export().(*CookieJar); ok {
-					result.ActiveJar = v.Jar
+					result.ActiveJar = v.httpJar()
 				}
 			case "compression":
 				algosString := strings.TrimSpace(params.Get(k).ToString().String())
@@ -361,12 +373,34 @@ func (c *Client) parseRequest(
 				}
 			case "redirects":
 				result.Redirects = null.IntFrom(params.Get(k).ToInteger())
+			case "retries":
+				retries, err := parseRetries(rt, params.Get(k))
+				if err != nil {
+					return nil, fmt.Errorf("invalid retries value: %w", err)
+				}
+				result.Retries = retries
 			case "tags":
 				if err := common.ApplyCustomUserTags(rt, &result.TagsAndMeta, params.Get(k)); err != nil {
 					return nil, fmt.Errorf("invalid HTTP request metric tags: %w", err)
 				}
 			case "auth":
-				result.Auth = params.Get(k).String()
+				if err := parseAuth(params.Get(k), result); err != nil {
+					return nil, fmt.Errorf("invalid auth value: %w", err)
+				}
+			case "tlsAuth":
+				transport, err := c.parseTLSAuth(rt, params.Get(k))
+				if err != nil {
+					return nil, fmt.Errorf("invalid tlsAuth value: %w", err)
+				}
+				result.Transport = transport
+			case "proxy":
+				if proxy := params.Get(k); !common.IsNullish(proxy) {
+					proxyURL, err := httpext.ParseProxyURL(proxy.String())
+					if err != nil {
+						return nil, fmt.Errorf("invalid proxy value: %w", err)
+					}
+					result.Proxy = proxyURL
+				}
 			case "timeout":
 				t, err := types.GetDurationValue(params.Get(k).Export())
 				if err != nil {
@@ -394,6 +428,12 @@ func (c *Client) parseRequest(
 		}
 	}
 
+	if state.Hooks.HasRequestHooks() {
+		if err := runRequestHooks(state.Hooks, result); err != nil {
+			return nil, err
+		}
+	}
+
 	if result.ActiveJar != nil {
 		httpext.SetRequestCookies(result.Req, result.ActiveJar, result.Cookies)
 	}
@@ -401,6 +441,25 @@ func (c *Client) parseRequest(
 	return result, nil
 }
 
+// runRequestHooks invokes the request hooks with the request, and applies the
+// headers and the tags they changed.
+func runRequestHooks(hooks *lib.Hooks, req *httpext.ParsedHTTPRequest) error {
+	hooked := lib.NewHookedRequest("http", req.Req.Method, req.URL.URL, req.Req.Header, req.TagsAndMeta.Tags)
+	if err := hooks.Request(hooked); err != nil {
+		return err
+	}
+
+	set, removed := hooked.HeaderChanges()
+	for k, v := range set {
+		req.Req.Header.Set(k, v)
+	}
+	for _, k := range removed {
+		req.Req.Header.Del(k)
+	}
+	hooked.ApplyTags(&req.TagsAndMeta)
+	return nil
+}
+
 func (c *Client) prepareBatchArray(requests []interface{}) (
 	[]httpext.BatchParsedHTTPRequest, []*Response, error,
 ) {
@@ -581,3 +640,167 @@ func requestContainsFile(data map[string]interface{}) bool {
 	}
 	return false
 }
+
+// parseTLSAuth parses the tlsAuth param, an object with the PEM-encoded cert
+// and key, and an optional password of the key, and returns the transport
+// presenting that client certificate. Passing a different certificate in
+// later requests rotates it.
+func (c *Client) parseTLSAuth(rt *sobek.Runtime, v sobek.Value) (http.RoundTripper, error) {
+	if common.IsNullish(v) {
+		return nil, nil //nolint:nilnil
+	}
+	params := v.ToObject(rt)
+	auth := &lib.TLSAuth{}
+	if cert := params.Get("cert"); !common.IsNullish(cert) {
+		auth.Cert = cert.String()
+	}
+	if key := params.Get("key"); !common.IsNullish(key) {
+		auth.Key = key.String()
+	}
+	if password := params.Get("password"); !common.IsNullish(password) {
+		auth.Password = null.StringFrom(password.String())
+	}
+	if auth.Cert == "" || auth.Key == "" {
+		return nil, errors.New("both a cert and a key are required")
+	}
+
+	state := c.moduleInstance.vu.State()
+	return c.moduleInstance.clientCerts.Get(state.Transport, state.TLSConfig, auth)
+}
+
+// parseRetries parses the retries param, either the number of retries, or an
+// object with the count, backoff, delay, maxDelay, statusCodes, networkErrors
+// and methods keys.
+//
+// By default, the delay starts at 100ms and doubles with each retry, up to
+// 10s, and requests are retried on network errors and on 502, 503, and 504
+// responses, only if their method is idempotent: GET, HEAD, OPTIONS, PUT or
+// DELETE.
+func parseRetries(rt *sobek.Runtime, v sobek.Value) (httpext.RetryPolicy, error) {
+	if common.IsNullish(v) {
+		return httpext.RetryPolicy{}, nil
+	}
+
+	result := httpext.RetryPolicy{
+		Backoff:       httpext.BackoffExponential,
+		Delay:         100 * time.Millisecond,
+		MaxDelay:      10 * time.Second,
+		StatusCodes:   []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		NetworkErrors: true,
+	}
+
+	obj, ok := v.(*sobek.Object)
+	if !ok {
+		result.Count = v.ToInteger()
+		if result.Count < 0 {
+			return result, errors.New("the count of retries can't be negative")
+		}
+
+		return result, nil
+	}
+
+	for _, k := range obj.Keys() {
+		switch k {
+		case "count":
+			result.Count = obj.Get(k).ToInteger()
+			if result.Count < 0 {
+				return result, errors.New("the count of retries can't be negative")
+			}
+		case "backoff":
+			switch b := httpext.Backoff(obj.Get(k).String()); b {
+			case httpext.BackoffConstant, httpext.BackoffLinear, httpext.BackoffExponential:
+				result.Backoff = b
+			default:
+				return result, fmt.Errorf("unsupported backoff %q, it needs to be constant, linear, or exponential", b)
+			}
+		case "delay", "maxDelay":
+			d, err := types.GetDurationValue(obj.Get(k).Export())
+			if err != nil {
+				return result, fmt.Errorf("invalid %s value: %w", k, err)
+			}
+			if d < 0 {
+				return result, fmt.Errorf("invalid %s value: it can't be negative", k)
+			}
+
+			if k == "delay" {
+				result.Delay = d
+			} else {
+				result.MaxDelay = d
+			}
+		case "statusCodes":
+			var codes []int
+			if err := rt.ExportTo(obj.Get(k), &codes); err != nil {
+				return result, errors.New("statusCodes needs to be an array of status codes")
+			}
+			result.StatusCodes = codes
+		case "networkErrors":
+			result.NetworkErrors = obj.Get(k).ToBoolean()
+		case "methods":
+			var methods []string
+			if err := rt.ExportTo(obj.Get(k), &methods); err != nil {
+				return result, errors.New("methods needs to be an array of HTTP methods")
+			}
+			result.Methods = methods
+		default:
+			return result, fmt.Errorf("unknown key %q", k)
+		}
+	}
+
+	return result, nil
+}
+
+// parseAuth parses the auth param, either the name of the authentication
+// scheme, whose credentials are the ones of the URL, or an object with the
+// scheme as its type, along with its credentials.
+func parseAuth(v sobek.Value, result *httpext.ParsedHTTPRequest) error {
+	obj, ok := v.(*sobek.Object)
+	if !ok {
+		result.Auth = v.String()
+		return nil
+	}
+
+	var creds httpext.AuthCredentials
+	var sigV4 httpext.AWSSigV4Config
+	for _, k := range obj.Keys() {
+		value := obj.Get(k).String()
+		switch k {
+		case "type":
+			result.Auth = value
+		case "username":
+			creds.Username = value
+		case "password":
+			creds.Password = value
+		case "domain":
+			creds.Domain = value
+		case "accessKeyId":
+			sigV4.AccessKeyID = value
+		case "secretAccessKey":
+			sigV4.SecretAccessKey = value
+		case "sessionToken":
+			sigV4.SessionToken = value
+		case "region":
+			sigV4.Region = value
+		case "service":
+			sigV4.Service = value
+		default:
+			return fmt.Errorf("unknown key %q", k)
+		}
+	}
+
+	switch result.Auth {
+	case httpext.AuthBasic, httpext.AuthDigest, httpext.AuthNTLM:
+		if creds.Username == "" {
+			return fmt.Errorf("the %s authentication requires a username", result.Auth)
+		}
+		result.AuthCredentials = &creds
+	case httpext.AuthAWSSigV4:
+		if sigV4.AccessKeyID == "" || sigV4.SecretAccessKey == "" || sigV4.Region == "" || sigV4.Service == "" {
+			return errors.New("the awsSigV4 authentication requires an accessKeyId, a secretAccessKey, a region and a service")
+		}
+		result.AWSSigV4 = &sigV4
+	default:
+		return fmt.Errorf("unsupported type %q, it needs to be basic, digest, ntlm, or awsSigV4", result.Auth)
+	}
+
+	return nil
+}
@@ -23,6 +23,8 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -977,6 +979,71 @@ func TestRequest(t *testing.T) {
 					`, url))
 					assert.NoError(t, err)
 				})
+				t.Run("credentials", func(t *testing.T) {
+					_, err := rt.RunString(sr(`
+					var res = http.request("GET", "HTTPBIN_IP_URL/digest-auth/auth/bob/pass", null, {
+						auth: { type: "digest", username: "bob", password: "pass" },
+					});
+					if (res.status != 200) { throw new Error("wrong status: " + res.status); }
+					`))
+					assert.NoError(t, err)
+					metrics.GetBufferedSamples(samples)
+				})
+			})
+			t.Run("basic credentials", func(t *testing.T) {
+				_, err := rt.RunString(sr(`
+				var res = http.request("GET", "HTTPBIN_URL/basic-auth/bob/pass", null, {
+					auth: { type: "basic", username: "bob", password: "pass" },
+				});
+				if (res.status != 200) { throw new Error("wrong status: " + res.status); }
+				`))
+				assert.NoError(t, err)
+				assertRequestMetricsEmitted(t, metrics.GetBufferedSamples(samples), "GET", sr("HTTPBIN_URL/basic-auth/bob/pass"), 200, "")
+			})
+			t.Run("awsSigV4", func(t *testing.T) {
+				var authorizations, tokens []string
+				tb.Mux.HandleFunc("/sigv4/", func(w http.ResponseWriter, r *http.Request) {
+					authorizations = append(authorizations, r.Header.Get("Authorization"))
+					tokens = append(tokens, r.Header.Get("X-Amz-Security-Token"))
+					if r.URL.Path == "/sigv4/redirect" {
+						http.Redirect(w, r, "/sigv4/target", http.StatusFound)
+					}
+				})
+
+				_, err := rt.RunString(sr(`
+				var res = http.request("POST", "HTTPBIN_URL/sigv4/redirect", "body", {
+					auth: {
+						type: "awsSigV4",
+						accessKeyId: "AKIDEXAMPLE",
+						secretAccessKey: "secret",
+						sessionToken: "token",
+						region: "us-east-1",
+						service: "execute-api",
+					},
+				});
+				if (res.status != 200) { throw new Error("wrong status: " + res.status); }
+				`))
+				assert.NoError(t, err)
+				metrics.GetBufferedSamples(samples)
+
+				require.Len(t, authorizations, 2)
+				for _, authorization := range authorizations {
+					assert.Regexp(t, `^AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/\d{8}/us-east-1/execute-api/aws4_request, `+
+						`SignedHeaders=[a-z0-9;-]*host;[a-z0-9;-]*x-amz-security-token, Signature=[0-9a-f]{64}$`, authorization)
+				}
+				assert.NotEqual(t, authorizations[0], authorizations[1], "the redirect wasn't signed on its own")
+				assert.Equal(t, []string{"token", "token"}, tokens)
+			})
+			t.Run("invalid", func(t *testing.T) {
+				for params, expErr := range map[string]string{
+					`{ type: "bearer" }`:                         `unsupported type "bearer"`,
+					`{ type: "digest" }`:                         `the digest authentication requires a username`,
+					`{ type: "awsSigV4", accessKeyId: "AKID" }`:  `the awsSigV4 authentication requires`,
+					`{ type: "basic", username: "u", foo: "b" }`: `unknown key "foo"`,
+				} {
+					_, err := rt.RunString(sr(`http.request("GET", "HTTPBIN_URL/get", null, { auth: ` + params + ` });`))
+					require.ErrorContains(t, err, "invalid auth value: "+expErr)
+				}
 			})
 		})
 
@@ -2405,3 +2472,398 @@ func TestGzipped204Response(t *testing.T) {
 			`))
 	assert.NoError(t, err)
 }
+
+func TestRequestRetries(t *testing.T) {
+	t.Parallel()
+
+	// countSamples returns the number of samples of the provided metric,
+	// along with the values of their status tags.
+	countSamples := func(containers []metrics.SampleContainer, name string) (int, []string) {
+		count, statuses := 0, []string{}
+		for _, container := range containers {
+			for _, sample := range container.GetSamples() {
+				if sample.Metric.Name != name {
+					continue
+				}
+				count++
+				status, _ := sample.Tags.Get("status")
+				statuses = append(statuses, status)
+			}
+		}
+		return count, statuses
+	}
+
+	// failingHandler responds with the provided status to the first failures
+	// requests, and with the body it received to the next ones.
+	failingHandler := func(attempts *atomic.Int32, failures int32, status int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) <= failures {
+				w.WriteHeader(status)
+				return
+			}
+			_, _ = io.Copy(w, r.Body)
+		}
+	}
+
+	t.Run("StatusCode", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+
+		var attempts atomic.Int32
+		ts.tb.Mux.HandleFunc("/retry", failingHandler(&attempts, 2, http.StatusServiceUnavailable))
+
+		_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+			var res = http.post("HTTPBIN_URL/retry", "payload", {retries: {count: 3, delay: 1, methods: ["POST"]}});
+			if (res.status !== 200) { throw new Error("wrong status: " + res.status) }
+			if (res.body !== "payload") { throw new Error("wrong body: " + res.body) }
+		`))
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, attempts.Load())
+
+		samples := metrics.GetBufferedSamples(ts.samples)
+		retries, statuses := countSamples(samples, metrics.HTTPReqRetriesName)
+		assert.Equal(t, 2, retries)
+		assert.Equal(t, []string{"503", "503"}, statuses)
+
+		// Only the last attempt is counted as a request, while all of them are measured.
+		reqs, statuses := countSamples(samples, metrics.HTTPReqsName)
+		assert.Equal(t, 1, reqs)
+		assert.Equal(t, []string{"200"}, statuses)
+		failed, statuses := countSamples(samples, metrics.HTTPReqFailedName)
+		assert.Equal(t, 1, failed)
+		assert.Equal(t, []string{"200"}, statuses)
+		durations, statuses := countSamples(samples, metrics.HTTPReqDurationName)
+		assert.Equal(t, 3, durations)
+		assert.Equal(t, []string{"503", "503", "200"}, statuses)
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+
+		var attempts atomic.Int32
+		ts.tb.Mux.HandleFunc("/retry", failingHandler(&attempts, 10, http.StatusBadGateway))
+
+		_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+			var res = http.get("HTTPBIN_URL/retry", {retries: {count: 2, backoff: "constant", delay: 1}});
+			if (res.status !== 502) { throw new Error("wrong status: " + res.status) }
+		`))
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, attempts.Load())
+
+		samples := metrics.GetBufferedSamples(ts.samples)
+		retries, _ := countSamples(samples, metrics.HTTPReqRetriesName)
+		assert.Equal(t, 2, retries)
+
+		// The last attempt is counted as a failed request.
+		var failed []float64
+		for _, container := range samples {
+			for _, sample := range container.GetSamples() {
+				if sample.Metric.Name == metrics.HTTPReqFailedName {
+					failed = append(failed, sample.Value)
+				}
+			}
+		}
+		assert.Equal(t, []float64{1}, failed)
+	})
+
+	t.Run("NotRetryable", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+
+		var attempts atomic.Int32
+		ts.tb.Mux.HandleFunc("/retry", failingHandler(&attempts, 10, http.StatusServiceUnavailable))
+
+		_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+			var res = http.get("HTTPBIN_URL/retry", {retries: {count: 2, statusCodes: [429]}});
+			if (res.status !== 503) { throw new Error("wrong status: " + res.status) }
+		`))
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, attempts.Load())
+
+		retries, _ := countSamples(metrics.GetBufferedSamples(ts.samples), metrics.HTTPReqRetriesName)
+		assert.Equal(t, 0, retries)
+	})
+
+	t.Run("NotIdempotent", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+
+		var attempts atomic.Int32
+		ts.tb.Mux.HandleFunc("/retry", failingHandler(&attempts, 10, http.StatusServiceUnavailable))
+
+		_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+			var res = http.post("HTTPBIN_URL/retry", "payload", {retries: 2});
+			if (res.status !== 503) { throw new Error("wrong status: " + res.status) }
+		`))
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, attempts.Load())
+
+		retries, _ := countSamples(metrics.GetBufferedSamples(ts.samples), metrics.HTTPReqRetriesName)
+		assert.Equal(t, 0, retries)
+	})
+
+	t.Run("NetworkError", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+
+		// The connection of the first attempt is closed without a response.
+		var attempts atomic.Int32
+		ts.tb.Mux.HandleFunc("/retry", func(w http.ResponseWriter, _ *http.Request) {
+			if attempts.Add(1) == 1 {
+				conn, _, err := w.(http.Hijacker).Hijack()
+				assert.NoError(t, err)
+				_ = conn.Close()
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+
+		_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+			var res = http.get("HTTPBIN_URL/retry", {retries: 1});
+			if (res.status !== 200) { throw new Error("wrong status: " + res.status) }
+		`))
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, attempts.Load())
+
+		retries, statuses := countSamples(metrics.GetBufferedSamples(ts.samples), metrics.HTTPReqRetriesName)
+		assert.Equal(t, 1, retries)
+		assert.Equal(t, []string{"0"}, statuses)
+	})
+
+	t.Run("NetworkErrorsDisabled", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+
+		var attempts atomic.Int32
+		ts.tb.Mux.HandleFunc("/retry", func(w http.ResponseWriter, _ *http.Request) {
+			attempts.Add(1)
+			conn, _, err := w.(http.Hijacker).Hijack()
+			assert.NoError(t, err)
+			_ = conn.Close()
+		})
+
+		_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+			http.get("HTTPBIN_URL/retry", {retries: {count: 3, networkErrors: false}});
+		`))
+		require.Error(t, err)
+		assert.EqualValues(t, 1, attempts.Load())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		testCases := map[string]string{
+			`-1`:                         "the count of retries can't be negative",
+			`{count: 1, backoff: "fib"}`: `unsupported backoff "fib"`,
+			`{count: 1, delay: "soon"}`:  "invalid delay value",
+			`{count: 1, maxDelay: -5}`:   "invalid maxDelay value: it can't be negative",
+			`{count: 1, statusCodes: 5}`: "statusCodes needs to be an array of status codes",
+			`{count: 1, methods: "GET"}`: "methods needs to be an array of HTTP methods",
+			`{count: 1, jitter: true}`:   `unknown key "jitter"`,
+		}
+
+		for retries, expErr := range testCases {
+			retries, expErr := retries, expErr
+			t.Run(retries, func(t *testing.T) {
+				t.Parallel()
+				ts := newTestCase(t)
+
+				_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(
+					`http.get("HTTPBIN_URL/get", {retries: ` + retries + `});`))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid retries value: "+expErr)
+			})
+		}
+	})
+}
+
+func TestConnectionStats(t *testing.T) {
+	t.Parallel()
+
+	// remoteAddrs records the client addresses the requests were made from,
+	// i.e. the connections they were made over.
+	remoteAddrs := func(tb *httpmultibin.HTTPMultiBin) func() map[string]bool {
+		var mu sync.Mutex
+		addrs := make(map[string]bool)
+		tb.Mux.HandleFunc("/conn", func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			addrs[r.RemoteAddr] = true
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		})
+
+		return func() map[string]bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return addrs
+		}
+	}
+
+	t.Run("Reused", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+		addrs := remoteAddrs(ts.tb)
+
+		_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+			http.get("HTTPBIN_URL/conn");
+			http.get("HTTPBIN_URL/conn");
+			var stats = http.connectionStats();
+			if (stats.open !== 1 || stats.idle !== 1 || stats.reused !== 1) {
+				throw new Error("unexpected stats: " + JSON.stringify(stats));
+			}
+
+			http.closeIdleConnections();
+			stats = http.connectionStats();
+			if (stats.open !== 0 || stats.idle !== 0) {
+				throw new Error("unexpected stats once closed: " + JSON.stringify(stats));
+			}
+		`))
+		require.NoError(t, err)
+		assert.Len(t, addrs(), 1)
+	})
+
+	t.Run("MaxRequestsPerConnection", func(t *testing.T) {
+		t.Parallel()
+		ts := newTestCase(t)
+		addrs := remoteAddrs(ts.tb)
+		ts.runtime.VU.State().Options.MaxRequestsPerConnection = null.IntFrom(2)
+
+		_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+			for (var i = 0; i < 5; i++) {
+				http.get("HTTPBIN_URL/conn");
+			}
+			var stats = http.connectionStats();
+			if (stats.open !== 1 || stats.idle !== 1 || stats.reused !== 2) {
+				throw new Error("unexpected stats: " + JSON.stringify(stats));
+			}
+		`))
+		require.NoError(t, err)
+		assert.Len(t, addrs(), 3)
+	})
+
+	t.Run("CloseIdleConnectionsInit", func(t *testing.T) {
+		t.Parallel()
+		rt, _ := getTestModuleInstance(t)
+
+		_, err := rt.VU.Runtime().RunString(`http.closeIdleConnections()`)
+		require.ErrorContains(t, err, "Closing connections in the init context is not supported")
+	})
+}
+
+func TestRequestTraceContextPropagation(t *testing.T) {
+	t.Parallel()
+	ts := newTestCase(t)
+	ts.runtime.VU.State().Options.TracePropagator = null.StringFrom("w3c,b3")
+	ts.runtime.VU.State().Options.TraceSampling = null.FloatFrom(0)
+
+	v, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+		var headers = http.get("HTTPBIN_URL/headers").json().headers;
+		var custom = http.get("HTTPBIN_URL/headers", {
+			headers: { traceparent: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01" },
+		}).json().headers;
+		[headers["Traceparent"][0], headers["B3"][0], custom["Traceparent"][0], custom["B3"]];
+	`))
+	require.NoError(t, err)
+
+	var values []interface{}
+	require.NoError(t, ts.runtime.VU.Runtime().ExportTo(v, &values))
+	require.Len(t, values, 4)
+	assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-00$`, values[0])
+	traceID, spanID := values[0].(string)[3:35], values[0].(string)[36:52]
+	assert.Equal(t, traceID+"-"+spanID+"-0", values[1])
+	assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", values[2])
+	assert.Nil(t, values[3])
+
+	var traceIDs []string
+	for _, container := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range container.GetSamples() {
+			if sample.Metric.Name == metrics.HTTPReqsName {
+				traceIDs = append(traceIDs, sample.Metadata["trace_id"])
+			}
+		}
+	}
+	assert.Equal(t, []string{traceID, ""}, traceIDs)
+}
+
+func TestRequestURLGrouping(t *testing.T) {
+	t.Parallel()
+	ts := newTestCase(t)
+	grouping, err := types.NewNullURLGrouping([]types.URLGroupingRule{
+		{Path: "/anything/{id}"},
+		{Match: `/status/[0-9]+$`, Name: "status"},
+	})
+	require.NoError(t, err)
+	ts.runtime.VU.State().Options.URLGrouping = grouping
+
+	_, err = ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+		http.get("HTTPBIN_URL/anything/1");
+		http.get("HTTPBIN_URL/anything/2?q=1");
+		http.get("HTTPBIN_URL/status/204");
+		http.get("HTTPBIN_URL/status/201", { tags: { name: "created" } });
+		http.get("HTTPBIN_URL/headers");
+	`))
+	require.NoError(t, err)
+
+	var names []string
+	for _, container := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range container.GetSamples() {
+			if sample.Metric.Name == metrics.HTTPReqsName {
+				name, _ := sample.Tags.Get("name")
+				url, _ := sample.Tags.Get("url")
+				assert.Equal(t, name, url)
+				names = append(names, name)
+			}
+		}
+	}
+	assert.Equal(t, []string{
+		ts.tb.Replacer.Replace("HTTPBIN_URL/anything/{id}"),
+		ts.tb.Replacer.Replace("HTTPBIN_URL/anything/{id}"),
+		"status",
+		"created",
+		ts.tb.Replacer.Replace("HTTPBIN_URL/headers"),
+	}, names)
+}
+
+func TestRequestHTTP2Metrics(t *testing.T) {
+	t.Parallel()
+	ts := newTestCase(t)
+	ts.tb.Mux.HandleFunc("/h2-reset", func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler) // makes the HTTP/2 server reset the stream
+	})
+
+	_, err := ts.runtime.VU.Runtime().RunString(ts.tb.Replacer.Replace(`
+		var responses = http.batch(["HTTP2BIN_URL/get", "HTTP2BIN_URL/get", "HTTP2BIN_URL/get"]);
+		for (var i = 0; i < responses.length; i++) {
+			if (responses[i].proto != "HTTP/2.0") { throw new Error("wrong proto: " + responses[i].proto) }
+		}
+		http.get("HTTPSBIN_URL/get");
+		var res = http.get("HTTP2BIN_URL/h2-reset", { throw: false });
+		if (res.error_code != 1633) { throw new Error("wrong error code: " + res.error_code) }
+	`))
+	require.NoError(t, err)
+
+	var streams []float64
+	var resets float64
+	for _, container := range metrics.GetBufferedSamples(ts.samples) {
+		for _, sample := range container.GetSamples() {
+			switch sample.Metric.Name {
+			case metrics.HTTPReqH2StreamsName:
+				streams = append(streams, sample.Value)
+			case metrics.HTTPH2StreamResetsName:
+				resets += sample.Value
+			case metrics.HTTPH2GoAwaysName:
+				t.Errorf("unexpected GOAWAY sample %v", sample)
+			}
+		}
+	}
+
+	// The HTTPS request isn't made over HTTP/2, so it has no streams sample,
+	// while the request of the reset stream has one.
+	require.Len(t, streams, 4)
+	for _, value := range streams {
+		assert.GreaterOrEqual(t, value, 1.0)
+		assert.LessOrEqual(t, value, 3.0)
+	}
+	assert.Equal(t, 1.0, resets)
+}
@@ -23,6 +23,10 @@ type Response struct {
 
 	cachedJSON    interface{}
 	validatedJSON bool
+
+	// correlated are the values extracted across the navigation flow which
+	// led to the response, with submitForm() or clickLink() in correlate mode.
+	correlated correlatedValues
 }
 
 type jsonError struct {
@@ -142,6 +146,7 @@ func (res *Response) SubmitForm(args ...sobek.Value) (*Response, error) {
 	formSelector := "form"
 	submitSelector := "[type=\"submit\"]"
 	var fields map[string]sobek.Value
+	var correlationRules []correlationRule
 	requestParams := sobek.Null()
 	if len(args) > 0 {
 		params := args[0].ToObject(rt)
@@ -157,6 +162,11 @@ func (res *Response) SubmitForm(args ...sobek.Value) (*Response, error) {
 				}
 			case "params":
 				requestParams = params.Get(k)
+			case "correlate":
+				var err error
+				if correlationRules, err = parseCorrelate(rt, params.Get(k)); err != nil {
+					common.Throw(rt, fmt.Errorf("invalid correlate value: %w", err))
+				}
 			}
 		}
 	}
@@ -204,23 +214,57 @@ func (res *Response) SubmitForm(args ...sobek.Value) (*Response, error) {
 		values[submitName.String()] = submitValue
 	}
 
+	// In correlate mode, add the correlated fields the form lacks
+	var correlated correlatedValues
+	if correlationRules != nil {
+		if correlated, err = res.correlate(correlationRules); err != nil {
+			common.Throw(rt, err)
+		}
+		for k, v := range correlated.get(correlateAsField) {
+			if _, ok := values[k]; !ok {
+				values[k] = rt.ToValue(v)
+			}
+		}
+		requestParams = withCorrelatedHeaders(rt, requestParams, correlated.get(correlateAsHeader))
+	}
+
 	// Set the values supplied in the arguments, overriding automatically set values
 	for k, v := range fields {
 		values[k] = v
 	}
 
+	var body sobek.Value = sobek.Null()
 	if requestMethod == http.MethodGet {
 		q := url.Values{}
 		for k, v := range values {
 			q.Add(k, v.String())
 		}
 		requestURL.RawQuery = q.Encode()
-		return res.client.Request(requestMethod, rt.ToValue(requestURL.String()), sobek.Null(), requestParams)
+	} else {
+		body = rt.ToValue(values)
+	}
+	addCorrelatedQuery(requestURL, correlated)
+
+	resp, err := res.client.Request(requestMethod, rt.ToValue(requestURL.String()), body, requestParams)
+	if resp != nil && correlated != nil {
+		resp.correlated = correlated
 	}
-	return res.client.Request(
-		requestMethod, rt.ToValue(requestURL.String()),
-		rt.ToValue(values), requestParams,
-	)
+	return resp, err
+}
+
+// addCorrelatedQuery adds the correlated query parameters the URL lacks.
+func addCorrelatedQuery(u *url.URL, correlated correlatedValues) {
+	params := correlated.get(correlateAsQuery)
+	if len(params) == 0 {
+		return
+	}
+	q := u.Query()
+	for k, v := range params {
+		if !q.Has(k) {
+			q.Set(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
 }
 
 // ClickLink parses the body as an html, looks for a link and than makes a request as if the link was
@@ -229,6 +273,7 @@ func (res *Response) ClickLink(args ...sobek.Value) (*Response, error) {
 	rt := res.client.moduleInstance.vu.Runtime()
 
 	selector := "a[href]"
+	var correlationRules []correlationRule
 	requestParams := sobek.Null()
 	if len(args) > 0 {
 		params := args[0].ToObject(rt)
@@ -238,6 +283,11 @@ func (res *Response) ClickLink(args ...sobek.Value) (*Response, error) {
 				selector = params.Get(k).String()
 			case "params":
 				requestParams = params.Get(k)
+			case "correlate":
+				var err error
+				if correlationRules, err = parseCorrelate(rt, params.Get(k)); err != nil {
+					common.Throw(rt, fmt.Errorf("invalid correlate value: %w", err))
+				}
 			}
 		}
 	}
@@ -262,5 +312,20 @@ func (res *Response) ClickLink(args ...sobek.Value) (*Response, error) {
 	}
 	requestURL := responseURL.ResolveReference(hrefURL)
 
-	return res.client.Request(http.MethodGet, rt.ToValue(requestURL.String()), sobek.Undefined(), requestParams)
+	// In correlate mode, the correlated fields are carried over to the next
+	// form, while the headers and query parameters are sent with the link's request
+	var correlated correlatedValues
+	if correlationRules != nil {
+		if correlated, err = res.correlate(correlationRules); err != nil {
+			common.Throw(rt, err)
+		}
+		requestParams = withCorrelatedHeaders(rt, requestParams, correlated.get(correlateAsHeader))
+		addCorrelatedQuery(requestURL, correlated)
+	}
+
+	resp, err := res.client.Request(http.MethodGet, rt.ToValue(requestURL.String()), sobek.Undefined(), requestParams)
+	if resp != nil && correlated != nil {
+		resp.correlated = correlated
+	}
+	return resp, err
 }
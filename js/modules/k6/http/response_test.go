@@ -391,3 +391,138 @@ func TestResponse(t *testing.T) {
 		})
 	})
 }
+
+const testCorrelationHTML = `
+<html>
+<head>
+	<meta name="csrf-token" content="csrf-value">
+	<script>var sessionId = "session-value";</script>
+</head>
+<body>
+	<input type="hidden" name="__VIEWSTATE" value="viewstate-value"/>
+	<form method="post" action="/correlate/echo">
+		<input type="hidden" name="form_token" value="form-value"/>
+		<input type="text" name="username" value=""/>
+	</form>
+	<a href="/correlate/next">next</a>
+</body>
+`
+
+const testCorrelationNextHTML = `
+<html>
+<body>
+	<form method="post" action="/correlate/echo">
+		<input type="text" name="username" value=""/>
+	</form>
+</body>
+`
+
+func correlationEchoHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body, err := json.Marshal(struct {
+		Form    url.Values  `json:"form"`
+		Query   url.Values  `json:"query"`
+		Headers http.Header `json:"headers"`
+	}{
+		Form:    r.PostForm,
+		Query:   r.URL.Query(),
+		Headers: r.Header,
+	})
+	if err != nil {
+		body = []byte(`{"error": "failed serializing json"}`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+func TestResponseCorrelation(t *testing.T) {
+	t.Parallel()
+	ts := newTestCase(t)
+	tb := ts.tb
+	rt := ts.runtime.VU.Runtime()
+	sr := tb.Replacer.Replace
+
+	htmlHandler := func(html string) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(html))
+		}
+	}
+	tb.Mux.HandleFunc("/correlate/page", htmlHandler(testCorrelationHTML))
+	tb.Mux.HandleFunc("/correlate/next", htmlHandler(testCorrelationNextHTML))
+	tb.Mux.HandleFunc("/correlate/echo", correlationEchoHandler)
+
+	t.Run("SubmitForm", func(t *testing.T) {
+		_, err := rt.RunString(sr(`
+			var res = http.get("HTTPBIN_URL/correlate/page");
+			var data = res.submitForm({ fields: { username: "user" }, correlate: true }).json();
+			if (data.form.username[0] !== "user") { throw new Error("wrong username: " + JSON.stringify(data)); }
+			if (data.form.form_token[0] !== "form-value") { throw new Error("wrong form token: " + JSON.stringify(data)); }
+			if (data.form.__VIEWSTATE[0] !== "viewstate-value") { throw new Error("wrong view state: " + JSON.stringify(data)); }
+			if (data.headers["X-Csrf-Token"][0] !== "csrf-value") { throw new Error("wrong csrf header: " + JSON.stringify(data)); }
+		`))
+		require.NoError(t, err)
+	})
+
+	t.Run("WithoutCorrelate", func(t *testing.T) {
+		_, err := rt.RunString(sr(`
+			var res = http.get("HTTPBIN_URL/correlate/page");
+			var data = res.submitForm().json();
+			if (data.form.__VIEWSTATE !== undefined) { throw new Error("unexpected view state: " + JSON.stringify(data)); }
+			if (data.headers["X-Csrf-Token"] !== undefined) { throw new Error("unexpected csrf header: " + JSON.stringify(data)); }
+		`))
+		require.NoError(t, err)
+	})
+
+	t.Run("ClickLink", func(t *testing.T) {
+		_, err := rt.RunString(sr(`
+			var res = http.get("HTTPBIN_URL/correlate/page");
+			res = res.clickLink({ correlate: true });
+			var data = res.submitForm({ correlate: true }).json();
+			if (data.form.__VIEWSTATE[0] !== "viewstate-value") { throw new Error("wrong view state: " + JSON.stringify(data)); }
+			if (data.headers["X-Csrf-Token"][0] !== "csrf-value") { throw new Error("wrong csrf header: " + JSON.stringify(data)); }
+		`))
+		require.NoError(t, err)
+	})
+
+	t.Run("CustomRules", func(t *testing.T) {
+		_, err := rt.RunString(sr(`
+			var res = http.get("HTTPBIN_URL/correlate/page");
+			var data = res.submitForm({
+				params: { headers: { "X-Csrf-Token": "user-value" } },
+				correlate: {
+					defaults: false,
+					rules: [
+						{ name: "session", regex: 'sessionId = "([^"]+)"', target: "query" },
+						{ name: "X-View-State", selector: "input[name=__VIEWSTATE]", attribute: "value", target: "header" },
+						{ selector: "meta[name=csrf-token]", attribute: "content" },
+					],
+				},
+			}).json();
+			if (data.query.session[0] !== "session-value") { throw new Error("wrong session: " + JSON.stringify(data)); }
+			if (data.headers["X-View-State"][0] !== "viewstate-value") { throw new Error("wrong view state header: " + JSON.stringify(data)); }
+			if (data.headers["X-Csrf-Token"][0] !== "user-value") { throw new Error("wrong csrf header: " + JSON.stringify(data)); }
+			if (data.form.__VIEWSTATE !== undefined) { throw new Error("unexpected view state: " + JSON.stringify(data)); }
+			if (data.form.form_token[0] !== "form-value") { throw new Error("wrong form token: " + JSON.stringify(data)); }
+		`))
+		require.NoError(t, err)
+	})
+
+	t.Run("InvalidRules", func(t *testing.T) {
+		tests := map[string]string{
+			`{ rules: [{ name: "a" }] }`:                           "invalid rule 0: a rule needs either a selector or a regex",
+			`{ rules: [{ regex: "a" }] }`:                          "invalid rule 0: a regex rule needs a name",
+			`{ rules: [{ regex: "(", name: "a" }] }`:               "invalid rule 0: invalid regex",
+			`{ rules: [{ selector: "input", target: "cookie" }] }`: `invalid rule 0: unsupported target "cookie"`,
+			`{ foo: true }`:                                        `unknown key "foo"`,
+		}
+		for correlate, expErr := range tests {
+			_, err := rt.RunString(sr(`http.get("HTTPBIN_URL/correlate/page").submitForm({ correlate: ` + correlate + ` })`))
+			require.Error(t, err, correlate)
+			assert.Contains(t, err.Error(), "invalid correlate value: "+expErr, correlate)
+		}
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/capture"
 	"go.k6.io/k6/metrics"
 )
 
@@ -169,6 +170,7 @@ func (mi *K6) Check(arg0, checks sobek.Value, extras ...sobek.Value) (bool, erro
 
 	succ := true
 	var exc error
+	var failed []string
 	obj := checks.ToObject(rt)
 	for _, name := range obj.Keys() {
 		if strings.Contains(name, lib.GroupSeparator) {
@@ -199,6 +201,7 @@ func (mi *K6) Check(arg0, checks sobek.Value, extras ...sobek.Value) (bool, erro
 		if !booleanVal {
 			// A single failure makes the return value false.
 			succ = false
+			failed = append(failed, name)
 		}
 
 		sample := metrics.Sample{
@@ -220,5 +223,35 @@ func (mi *K6) Check(arg0, checks sobek.Value, extras ...sobek.Value) (bool, erro
 		}
 	}
 
+	if len(failed) > 0 && state.FailureCapture != nil {
+		mi.captureFailedChecks(arg0, failed)
+	}
+
 	return succ, nil
 }
+
+// captureFailedChecks records the checked response, if the value of the
+// checks is one, as they failed.
+func (mi *K6) captureFailedChecks(arg0 sobek.Value, failed []string) {
+	if common.IsNullish(arg0) {
+		return
+	}
+	res, ok := arg0.Export().(capture.Capturable)
+	if !ok {
+		return
+	}
+
+	state := mi.vu.State()
+	artifact := capture.Artifact{
+		Time:      time.Now(),
+		Reason:    "the checks failed",
+		Checks:    failed,
+		VU:        state.VUID,
+		Iteration: state.Iteration,
+		Exchange:  res.CaptureExchange(),
+	}
+	if ss := lib.GetScenarioState(mi.vu.Context()); ss != nil {
+		artifact.Scenario = ss.Name
+	}
+	state.FailureCapture.Record(artifact)
+}
@@ -0,0 +1,234 @@
+// Package log provides a k6 module for structured logging. Its log lines hold
+// the fields they are given, along with the VU, the iteration and the scenario
+// they were logged from, and go to the log output of k6, e.g. as JSON lines
+// with --log-format=json. The lines of each level can be rate limited, for all
+// VUs together, so that logging doesn't flood the output at high VU counts.
+package log
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/grafana/sobek"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/lib"
+)
+
+// levels are the levels of the log functions, by their names.
+//
+//nolint:gochecknoglobals
+var levels = map[string]logrus.Level{
+	"debug": logrus.DebugLevel,
+	"info":  logrus.InfoLevel,
+	"warn":  logrus.WarnLevel,
+	"error": logrus.ErrorLevel,
+}
+
+type (
+	// RootModule is the global module instance that will create instances of our
+	// module for each VU. It holds the configuration and the rate limiters of the
+	// log levels, which are shared by all VUs.
+	RootModule struct {
+		mu         sync.RWMutex
+		config     config
+		configured bool
+		limiters   map[logrus.Level]*levelLimiter
+	}
+
+	// ModuleInstance represents an instance of the log module for a single VU.
+	ModuleInstance struct {
+		vu   modules.VU
+		root *RootModule
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &ModuleInstance{}
+)
+
+// config is the configuration of the logging, which all VUs need to share.
+type config struct {
+	// level is the most verbose level which is logged.
+	level logrus.Level
+	// rateLimits are the numbers of lines per second logged at most for each
+	// level, unlimited when it's zero.
+	rateLimits [logrus.TraceLevel + 1]float64
+}
+
+// levelLimiter is the rate limiter of a level, which counts the lines it
+// drops, until the next line of the level is logged.
+type levelLimiter struct {
+	limiter *rate.Limiter
+	dropped atomic.Int64
+}
+
+// New returns a pointer to a new [RootModule] instance.
+func New() *RootModule {
+	return &RootModule{config: config{level: logrus.DebugLevel}}
+}
+
+// NewModuleInstance implements the modules.Module interface and returns a new
+// instance of our module for the given VU.
+func (rm *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	return &ModuleInstance{vu: vu, root: rm}
+}
+
+// Exports implements the modules.Module interface and returns the exports of
+// our module.
+func (mi *ModuleInstance) Exports() modules.Exports {
+	named := map[string]any{"configure": mi.configure}
+	for name, level := range levels {
+		named[name] = mi.logFunc(level)
+	}
+	return modules.Exports{Named: named}
+}
+
+// configure sets the level of the logging and the rate limits of the levels,
+// from the init context. As every VU runs the init context, the configuration
+// can't differ from the one of the other VUs.
+func (mi *ModuleInstance) configure(options sobek.Value) {
+	rt := mi.vu.Runtime()
+	if mi.vu.State() != nil {
+		common.Throw(rt, common.NewInitContextError("the logging can only be configured in the init context"))
+	}
+
+	c, err := parseConfig(rt, options)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid logging options: %w", err))
+	}
+	if err := mi.root.setConfig(c); err != nil {
+		common.Throw(rt, err)
+	}
+}
+
+func parseConfig(rt *sobek.Runtime, options sobek.Value) (config, error) {
+	c := config{level: logrus.DebugLevel}
+	if common.IsNullish(options) {
+		return c, nil
+	}
+
+	obj := options.ToObject(rt)
+	for _, k := range obj.Keys() {
+		v := obj.Get(k)
+		switch k {
+		case "level":
+			level, ok := levels[v.String()]
+			if !ok {
+				return c, fmt.Errorf("unsupported level %q, it needs to be debug, info, warn, or error", v.String())
+			}
+			c.level = level
+		case "rateLimits":
+			if common.IsNullish(v) {
+				continue
+			}
+			limits := v.ToObject(rt)
+			for _, name := range limits.Keys() {
+				level, ok := levels[name]
+				if !ok {
+					return c, fmt.Errorf("unsupported level %q of the rate limits", name)
+				}
+				limit := limits.Get(name).ToFloat()
+				if !(limit > 0) || math.IsInf(limit, 1) {
+					return c, fmt.Errorf("the rate limit of the %s level should be a positive number, but it is %v",
+						name, limit)
+				}
+				c.rateLimits[level] = limit
+			}
+		default:
+			return c, fmt.Errorf("unknown option %q", k)
+		}
+	}
+	return c, nil
+}
+
+// setConfig sets the configuration of the logging, or checks that it's the one
+// another VU set.
+func (rm *RootModule) setConfig(c config) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if rm.configured {
+		if rm.config != c {
+			return errors.New("the logging is already configured differently by another VU")
+		}
+		return nil
+	}
+
+	rm.config, rm.configured = c, true
+	rm.limiters = make(map[logrus.Level]*levelLimiter)
+	for level, limit := range c.rateLimits {
+		if limit > 0 {
+			rm.limiters[logrus.Level(level)] = &levelLimiter{
+				limiter: rate.NewLimiter(rate.Limit(limit), int(math.Ceil(limit))),
+			}
+		}
+	}
+	return nil
+}
+
+// allow returns whether a line of the level is logged, and the number of the
+// lines of the level which were dropped since the last one was.
+func (rm *RootModule) allow(level logrus.Level) (bool, int64) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	if level > rm.config.level {
+		return false, 0
+	}
+	l, ok := rm.limiters[level]
+	if !ok {
+		return true, 0
+	}
+	if !l.limiter.Allow() {
+		l.dropped.Add(1)
+		return false, 0
+	}
+	return true, l.dropped.Swap(0)
+}
+
+// logFunc returns the log function of the level, which logs a message with the
+// fields of the optional object, and the context of the VU.
+func (mi *ModuleInstance) logFunc(level logrus.Level) func(message string, fields sobek.Value) {
+	return func(message string, fields sobek.Value) {
+		allowed, dropped := mi.root.allow(level)
+		if !allowed {
+			return
+		}
+
+		data := logrus.Fields{}
+		if !common.IsNullish(fields) {
+			exported, ok := fields.Export().(map[string]any)
+			if !ok {
+				common.Throw(mi.vu.Runtime(), errors.New("the fields of the log line need to be an object"))
+			}
+			for k, v := range exported {
+				data[k] = v
+			}
+		}
+		if dropped > 0 {
+			data["dropped"] = dropped
+		}
+		data["source"] = "log"
+
+		var logger logrus.FieldLogger
+		if state := mi.vu.State(); state != nil {
+			logger = state.Logger
+			data["vu"] = state.VUID
+			data["iteration"] = state.Iteration
+			if ss := lib.GetScenarioState(mi.vu.Context()); ss != nil {
+				data["scenario"] = ss.Name
+			}
+		} else {
+			logger = mi.vu.InitEnv().Logger
+		}
+		logger.WithFields(data).Log(level, message)
+	}
+}
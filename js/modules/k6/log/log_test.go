@@ -0,0 +1,138 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/testutils"
+)
+
+func newLogRuntime(t *testing.T, root *RootModule, init string) (*modulestest.Runtime, *testutils.SimpleLogrusHook) {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.DebugLevel)
+	logger.SetOutput(testutils.NewTestOutput(t))
+	hook := &testutils.SimpleLogrusHook{HookedLevels: logrus.AllLevels}
+	logger.AddHook(hook)
+
+	runtime := modulestest.NewRuntime(t)
+	runtime.VU.InitEnvField.Logger = logger
+	err := runtime.SetupModuleSystem(map[string]any{"k6/log": root}, nil, nil)
+	require.NoError(t, err)
+	_, err = runtime.VU.Runtime().RunString(`var log = require("k6/log");` + init)
+	require.NoError(t, err)
+	runtime.MoveToVUContext(&lib.State{Logger: logger, VUID: 3, Iteration: 7})
+	runtime.VU.CtxField = lib.WithScenarioState(runtime.VU.CtxField, &lib.ScenarioState{Name: "default"})
+
+	return runtime, hook
+}
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	runtime, hook := newLogRuntime(t, New(), `log.info("from the init context");`)
+	_, err := runtime.VU.Runtime().RunString(`
+		log.debug("debug line");
+		log.warn("the request failed", { status: 500, url: "https://example.com", vu: 1 });
+	`)
+	require.NoError(t, err)
+
+	entries := hook.Drain()
+	require.Len(t, entries, 3)
+	assert.Equal(t, logrus.InfoLevel, entries[0].Level)
+	assert.Equal(t, "from the init context", entries[0].Message)
+	assert.Equal(t, logrus.Fields{"source": "log"}, entries[0].Data)
+
+	assert.Equal(t, logrus.DebugLevel, entries[1].Level)
+	assert.Equal(t, "debug line", entries[1].Message)
+
+	assert.Equal(t, logrus.WarnLevel, entries[2].Level)
+	assert.Equal(t, "the request failed", entries[2].Message)
+	assert.Equal(t, logrus.Fields{
+		"source":    "log",
+		"status":    int64(500),
+		"url":       "https://example.com",
+		"vu":        uint64(3),
+		"iteration": int64(7),
+		"scenario":  "default",
+	}, entries[2].Data)
+}
+
+func TestLogConfigure(t *testing.T) {
+	t.Parallel()
+
+	root := New()
+	init := `log.configure({ level: "info", rateLimits: { info: 2 } });`
+	runtime, hook := newLogRuntime(t, root, init)
+	_, err := runtime.VU.Runtime().RunString(`
+		log.debug("dropped by the level");
+		for (let i = 0; i < 5; i++) {
+			log.info("line " + i);
+		}
+		log.error("not limited");
+	`)
+	require.NoError(t, err)
+
+	lines := hook.Lines()
+	assert.Equal(t, []string{"line 0", "line 1", "not limited"}, lines)
+
+	// the rate limits are shared by the VUs, and the next line which isn't
+	// limited holds the number of the dropped ones
+	other, otherHook := newLogRuntime(t, root, init)
+	root.limiters[logrus.InfoLevel].limiter.SetLimit(rate.Inf)
+	_, err = other.VU.Runtime().RunString(`log.info("line 5");`)
+	require.NoError(t, err)
+
+	entries := otherHook.Drain()
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(3), entries[0].Data["dropped"])
+}
+
+func TestLogConfigureExceptions(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		code, err string
+	}{
+		"unknown level": {
+			code: `log.configure({ level: "trace" });`,
+			err:  `unsupported level "trace", it needs to be debug, info, warn, or error`,
+		},
+		"invalid rate limit": {
+			code: `log.configure({ rateLimits: { info: 0 } });`,
+			err:  "the rate limit of the info level should be a positive number, but it is 0",
+		},
+		"unknown option": {
+			code: `log.configure({ sampling: 1 });`,
+			err:  `unknown option "sampling"`,
+		},
+		"different configurations": {
+			code: `log.configure({ level: "info" }); log.configure({ level: "warn" });`,
+			err:  "the logging is already configured differently by another VU",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime := modulestest.NewRuntime(t)
+			err := runtime.SetupModuleSystem(map[string]any{"k6/log": New()}, nil, nil)
+			require.NoError(t, err)
+			_, err = runtime.VU.Runtime().RunString(`var log = require("k6/log");` + tc.code)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	runtime, _ := newLogRuntime(t, New(), "")
+	_, err := runtime.VU.Runtime().RunString(`log.configure({ level: "info" });`)
+	require.ErrorContains(t, err, "the logging can only be configured in the init context")
+}
@@ -7,6 +7,7 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/grafana/sobek"
@@ -20,27 +21,44 @@ import (
 type Metric struct {
 	metric *metrics.Metric
 	vu     modules.VU
+	// snapshotter is set for the summaries, and the gauges with a snapshot
+	// interval.
+	snapshotter *snapshotter
 }
 
 // ErrMetricsAddInInitContext is error returned when adding to metric is done in the init context
 var ErrMetricsAddInInitContext = common.NewInitContextError("Adding to metrics in the init context is not supported")
 
-func (mi *ModuleInstance) newMetric(call sobek.ConstructorCall, t metrics.MetricType) (*sobek.Object, error) {
+// newMetric creates a metric of the type; the summaries are gauges of the
+// quantiles of their values.
+func (mi *ModuleInstance) newMetric(
+	call sobek.ConstructorCall, t metrics.MetricType, summary bool,
+) (*sobek.Object, error) {
 	initEnv := mi.vu.InitEnv()
 	if initEnv == nil {
 		return nil, errors.New("metrics must be declared in the init context")
 	}
 	rt := mi.vu.Runtime()
-	c, _ := sobek.AssertFunction(rt.ToValue(func(name string, isTime ...bool) (*sobek.Object, error) {
+	c, _ := sobek.AssertFunction(rt.ToValue(func(name string, isTime bool, options sobek.Value) (*sobek.Object, error) {
 		valueType := metrics.Default
-		if len(isTime) > 0 && isTime[0] {
+		if isTime {
 			valueType = metrics.Time
 		}
+		var opts snapshotOptions
+		if t == metrics.Gauge {
+			var err error
+			if opts, err = parseSnapshotOptions(rt, options, summary); err != nil {
+				return nil, fmt.Errorf("invalid options for the '%s' metric: %w", name, err)
+			}
+		}
 		m, err := initEnv.Registry.NewMetric(name, t, valueType)
 		if err != nil {
 			return nil, err
 		}
 		metric := &Metric{metric: m, vu: mi.vu}
+		if opts.interval > 0 {
+			metric.snapshotter = mi.root.snapshotter(m, opts)
+		}
 		o := rt.NewObject()
 		err = o.DefineDataProperty("name", rt.ToValue(name), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_TRUE)
 		if err != nil {
@@ -115,25 +133,39 @@ func (m Metric) add(v sobek.Value, addTags sobek.Value) (bool, error) {
 		return false, fmt.Errorf("cannot add tags for the '%s' custom metric: %w", m.metric.Name, err)
 	}
 
-	sample := metrics.Sample{
-		TimeSeries: metrics.TimeSeries{
-			Metric: m.metric,
-			Tags:   ctm.Tags,
-		},
-		Time:     time.Now(),
-		Metadata: ctm.Metadata,
-		Value:    vfloat,
+	now := time.Now()
+	var snapshot metrics.Samples
+	if m.snapshotter != nil {
+		snapshot = m.snapshotter.add(ctm.Tags, vfloat, now)
+	}
+	if m.snapshotter == nil || !m.snapshotter.isSummary() {
+		sample := metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: m.metric,
+				Tags:   ctm.Tags,
+			},
+			Time:     now,
+			Metadata: ctm.Metadata,
+			Value:    vfloat,
+		}
+		metrics.PushIfNotDone(m.vu.Context(), state.Samples, sample)
+	}
+	if len(snapshot) > 0 {
+		metrics.PushIfNotDone(m.vu.Context(), state.Samples, snapshot)
 	}
-	metrics.PushIfNotDone(m.vu.Context(), state.Samples, sample)
 	return true, nil
 }
 
 type (
 	// RootModule is the root metrics module
-	RootModule struct{}
+	RootModule struct {
+		mu           sync.Mutex
+		snapshotters map[string]*snapshotter
+	}
 	// ModuleInstance represents an instance of the metrics module
 	ModuleInstance struct {
-		vu modules.VU
+		vu   modules.VU
+		root *RootModule
 	}
 )
 
@@ -143,13 +175,27 @@ var (
 )
 
 // NewModuleInstance implements modules.Module interface
-func (*RootModule) NewModuleInstance(m modules.VU) modules.Instance {
-	return &ModuleInstance{vu: m}
+func (r *RootModule) NewModuleInstance(m modules.VU) modules.Instance {
+	return &ModuleInstance{vu: m, root: r}
 }
 
 // New returns a new RootModule.
 func New() *RootModule {
-	return &RootModule{}
+	return &RootModule{snapshotters: make(map[string]*snapshotter)}
+}
+
+// snapshotter returns the snapshotter of the metric, which is shared by all
+// the VUs, creating it with the options on the first call.
+func (r *RootModule) snapshotter(m *metrics.Metric, opts snapshotOptions) *snapshotter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.snapshotters[m.Name]
+	if !ok {
+		s = newSnapshotter(m, opts.interval, opts.quantiles)
+		r.snapshotters[m.Name] = s
+	}
+	return s
 }
 
 // Exports returns the exports of the metrics module
@@ -160,13 +206,14 @@ func (mi *ModuleInstance) Exports() modules.Exports {
 			"Gauge":   mi.XGauge,
 			"Trend":   mi.XTrend,
 			"Rate":    mi.XRate,
+			"Summary": mi.XSummary,
 		},
 	}
 }
 
 // XCounter is a counter constructor
 func (mi *ModuleInstance) XCounter(call sobek.ConstructorCall, rt *sobek.Runtime) *sobek.Object {
-	v, err := mi.newMetric(call, metrics.Counter)
+	v, err := mi.newMetric(call, metrics.Counter, false)
 	if err != nil {
 		common.Throw(rt, err)
 	}
@@ -175,7 +222,7 @@ func (mi *ModuleInstance) XCounter(call sobek.ConstructorCall, rt *sobek.Runtime
 
 // XGauge is a gauge constructor
 func (mi *ModuleInstance) XGauge(call sobek.ConstructorCall, rt *sobek.Runtime) *sobek.Object {
-	v, err := mi.newMetric(call, metrics.Gauge)
+	v, err := mi.newMetric(call, metrics.Gauge, false)
 	if err != nil {
 		common.Throw(rt, err)
 	}
@@ -184,7 +231,17 @@ func (mi *ModuleInstance) XGauge(call sobek.ConstructorCall, rt *sobek.Runtime)
 
 // XTrend is a trend constructor
 func (mi *ModuleInstance) XTrend(call sobek.ConstructorCall, rt *sobek.Runtime) *sobek.Object {
-	v, err := mi.newMetric(call, metrics.Trend)
+	v, err := mi.newMetric(call, metrics.Trend, false)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	return v
+}
+
+// XSummary is a summary constructor; its values are aggregated in quantile
+// sketches, whose quantiles are emitted as a gauge instead of the values.
+func (mi *ModuleInstance) XSummary(call sobek.ConstructorCall, rt *sobek.Runtime) *sobek.Object {
+	v, err := mi.newMetric(call, metrics.Gauge, true)
 	if err != nil {
 		common.Throw(rt, err)
 	}
@@ -193,7 +250,7 @@ func (mi *ModuleInstance) XTrend(call sobek.ConstructorCall, rt *sobek.Runtime)
 
 // XRate is a rate constructor
 func (mi *ModuleInstance) XRate(call sobek.ConstructorCall, rt *sobek.Runtime) *sobek.Object {
-	v, err := mi.newMetric(call, metrics.Rate)
+	v, err := mi.newMetric(call, metrics.Rate, false)
 	if err != nil {
 		common.Throw(rt, err)
 	}
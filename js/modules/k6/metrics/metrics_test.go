@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/grafana/sobek"
 	"github.com/sirupsen/logrus"
@@ -235,3 +236,138 @@ func TestMetricDuplicates(t *testing.T) {
 
 	require.True(t, v.ToBoolean())
 }
+
+// newSnapshotTestVU returns a VU in the init context, and a function which moves
+// it to the VU context and returns its samples.
+func newSnapshotTestVU(t *testing.T) (*sobek.Runtime, *metrics.Registry, func() chan metrics.SampleContainer) {
+	t.Helper()
+
+	rt := sobek.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+	registry := metrics.NewRegistry()
+	mii := &modulestest.VU{
+		RuntimeField: rt,
+		InitEnvField: &common.InitEnvironment{TestPreInitState: &lib.TestPreInitState{Registry: registry}},
+		CtxField:     context.Background(),
+	}
+	m, ok := New().NewModuleInstance(mii).(*ModuleInstance)
+	require.True(t, ok)
+	require.NoError(t, rt.Set("metrics", m.Exports().Named))
+
+	return rt, registry, func() chan metrics.SampleContainer {
+		samples := make(chan metrics.SampleContainer, 1000)
+		mii.InitEnvField = nil
+		mii.StateField = &lib.State{
+			Options: lib.Options{},
+			Samples: samples,
+			Tags:    lib.NewVUStateTags(registry.RootTagSet()),
+			Logger:  logrus.New(),
+		}
+		return samples
+	}
+}
+
+func TestGaugeSnapshots(t *testing.T) {
+	t.Parallel()
+	rt, registry, vuContext := newSnapshotTestVU(t)
+
+	_, err := rt.RunString(`var g = new metrics.Gauge("queue_depth", false, { snapshotInterval: "1ms" })`)
+	require.NoError(t, err)
+	samples := vuContext()
+	_, err = rt.RunString(`
+		g.add(3, { partition: "a" });
+		g.add(5, { partition: "b" });
+	`)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = rt.RunString(`g.add(4, { partition: "a" })`)
+	require.NoError(t, err)
+
+	// the values are emitted as usual, along with the snapshots of the last
+	// values of all the partitions
+	snapshot := make(map[string]float64)
+	bufSamples := metrics.GetBufferedSamples(samples)
+	for _, c := range bufSamples[len(bufSamples)-1].GetSamples() {
+		assert.Equal(t, registry.Get("queue_depth"), c.Metric)
+		partition, _ := c.Tags.Get("partition")
+		snapshot[partition] = c.Value
+	}
+	assert.Equal(t, map[string]float64{"a": 4, "b": 5}, snapshot)
+}
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+	rt, registry, vuContext := newSnapshotTestVU(t)
+
+	_, err := rt.RunString(`
+		var s = new metrics.Summary("latency", true, { quantiles: [0.5, 0.99], snapshotInterval: "1ms" })
+	`)
+	require.NoError(t, err)
+	metric := registry.Get("latency")
+	require.NotNil(t, metric)
+	assert.Equal(t, metrics.Gauge, metric.Type)
+	assert.Equal(t, metrics.Time, metric.Contains)
+
+	samples := vuContext()
+	_, err = rt.RunString(`
+		for (var i = 1; i <= 100; i++) {
+			s.add(i);
+		}
+	`)
+	require.NoError(t, err)
+
+	time.Sleep(5 * time.Millisecond)
+	_, err = rt.RunString(`s.add(100)`)
+	require.NoError(t, err)
+
+	// only the quantiles are emitted, not the values
+	bufSamples := metrics.GetBufferedSamples(samples)
+	require.NotEmpty(t, bufSamples)
+	quantiles := make(map[string]float64)
+	for _, c := range bufSamples[len(bufSamples)-1].GetSamples() {
+		q, ok := c.Tags.Get("quantile")
+		require.True(t, ok)
+		quantiles[q] = c.Value
+	}
+	require.Len(t, quantiles, 2)
+	assert.InDelta(t, 50, quantiles["0.5"], 1)
+	assert.InDelta(t, 100, quantiles["0.99"], 1)
+}
+
+func TestSnapshotter(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	metric := registry.MustNewMetric("queue_depth", metrics.Gauge)
+	a := registry.RootTagSet().With("partition", "a")
+	b := registry.RootTagSet().With("partition", "b")
+
+	s := newSnapshotter(metric, time.Minute, nil)
+	start := s.lastSnapshot
+	assert.Empty(t, s.add(a, 1, start))
+	assert.Empty(t, s.add(b, 2, start.Add(time.Second)))
+	snapshot := s.add(a, 3, start.Add(time.Minute))
+	require.Len(t, snapshot, 2)
+	assert.Equal(t, a, snapshot[0].Tags)
+	assert.Equal(t, 3.0, snapshot[0].Value)
+	assert.Equal(t, b, snapshot[1].Tags)
+	assert.Equal(t, 2.0, snapshot[1].Value)
+	assert.Empty(t, s.add(b, 4, start.Add(time.Minute+time.Second)))
+}
+
+func TestSnapshotOptionsErrors(t *testing.T) {
+	t.Parallel()
+
+	for js, experr := range map[string]string{
+		`new metrics.Gauge("m", false, { snapshotInterval: "-1s" })`:   "the snapshotInterval must be positive",
+		`new metrics.Gauge("m", false, { snapshotInterval: "later" })`: "invalid snapshotInterval",
+		`new metrics.Gauge("m", false, { quantiles: [0.5] })`:          "only the summaries have quantiles",
+		`new metrics.Summary("m", false, { quantiles: [] })`:           "the summaries need at least one quantile",
+		`new metrics.Summary("m", false, { quantiles: [0.5, 1.5] })`:   "the quantiles must be between 0 and 1",
+		`new metrics.Summary("m", false, { quantiles: "p99" })`:        "invalid quantiles",
+	} {
+		rt, _, _ := newSnapshotTestVU(t) //nolint:dogsled
+		_, err := rt.RunString(js)
+		assert.ErrorContains(t, err, experr, js)
+	}
+}
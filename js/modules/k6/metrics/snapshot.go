@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// quantileTag is the tag of the quantiles emitted by the summaries.
+	quantileTag = "quantile"
+
+	defaultSummaryInterval = time.Second
+)
+
+//nolint:gochecknoglobals
+var defaultSummaryQuantiles = []float64{0.5, 0.9, 0.95, 0.99}
+
+// snapshotter keeps the state of a metric by tag set, shared by all the VUs,
+// and emits a snapshot of all the tag sets at once, at most every interval,
+// when the metric is added to.
+//
+// For the gauges, the state is the last value of each tag set, so the values
+// of the tag sets which aren't updated anymore are still reported. For the
+// summaries, it is a quantile sketch of each tag set, whose quantiles are
+// reported instead of the added values.
+type snapshotter struct {
+	metric    *metrics.Metric
+	interval  time.Duration
+	quantiles []float64 // only for the summaries
+
+	mu           sync.Mutex
+	lastSnapshot time.Time
+	tagSets      []*metrics.TagSet
+	lastValues   map[*metrics.TagSet]float64
+	sketches     map[*metrics.TagSet]*metrics.TrendSink
+}
+
+func newSnapshotter(metric *metrics.Metric, interval time.Duration, quantiles []float64) *snapshotter {
+	return &snapshotter{
+		metric:       metric,
+		interval:     interval,
+		quantiles:    quantiles,
+		lastSnapshot: time.Now(),
+		lastValues:   make(map[*metrics.TagSet]float64),
+		sketches:     make(map[*metrics.TagSet]*metrics.TrendSink),
+	}
+}
+
+// isSummary returns whether the snapshots are the quantiles of a summary.
+func (s *snapshotter) isSummary() bool {
+	return len(s.quantiles) > 0
+}
+
+// add records the value of the tag set and returns the snapshot of all the tag
+// sets, if the interval has passed since the last one.
+func (s *snapshotter) add(tags *metrics.TagSet, value float64, now time.Time) metrics.Samples {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isSummary() {
+		sketch, ok := s.sketches[tags]
+		if !ok {
+			sketch = metrics.NewHistogramTrendSink(metrics.DefaultTrendPrecision)
+			s.sketches[tags] = sketch
+			s.tagSets = append(s.tagSets, tags)
+		}
+		sketch.Add(metrics.Sample{Value: value})
+	} else {
+		if _, ok := s.lastValues[tags]; !ok {
+			s.tagSets = append(s.tagSets, tags)
+		}
+		s.lastValues[tags] = value
+	}
+
+	if now.Sub(s.lastSnapshot) < s.interval {
+		return nil
+	}
+	s.lastSnapshot = now
+	return s.snapshot(now)
+}
+
+func (s *snapshotter) snapshot(now time.Time) metrics.Samples {
+	if !s.isSummary() {
+		samples := make(metrics.Samples, 0, len(s.tagSets))
+		for _, tags := range s.tagSets {
+			samples = append(samples, s.sample(tags, s.lastValues[tags], now))
+		}
+		return samples
+	}
+
+	samples := make(metrics.Samples, 0, len(s.tagSets)*len(s.quantiles))
+	for _, tags := range s.tagSets {
+		sketch := s.sketches[tags]
+		for _, q := range s.quantiles {
+			quantile := tags.With(quantileTag, strconv.FormatFloat(q, 'f', -1, 64))
+			samples = append(samples, s.sample(quantile, sketch.P(q), now))
+		}
+	}
+	return samples
+}
+
+func (s *snapshotter) sample(tags *metrics.TagSet, value float64, now time.Time) metrics.Sample {
+	return metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: s.metric,
+			Tags:   tags,
+		},
+		Time:  now,
+		Value: value,
+	}
+}
+
+// snapshotOptions are the options of the gauges and the summaries.
+type snapshotOptions struct {
+	interval  time.Duration
+	quantiles []float64
+}
+
+// parseSnapshotOptions parses the options argument of the metric constructors,
+// i.e. the snapshotInterval of the gauges and the summaries, and the quantiles
+// of the summaries.
+func parseSnapshotOptions(rt *sobek.Runtime, v sobek.Value, summary bool) (snapshotOptions, error) {
+	var opts snapshotOptions
+	if summary {
+		opts.interval = defaultSummaryInterval
+		opts.quantiles = defaultSummaryQuantiles
+	}
+	if common.IsNullish(v) {
+		return opts, nil
+	}
+
+	obj := v.ToObject(rt)
+	if interval := obj.Get("snapshotInterval"); !common.IsNullish(interval) {
+		d, err := types.GetDurationValue(interval.Export())
+		if err != nil {
+			return opts, fmt.Errorf("invalid snapshotInterval: %w", err)
+		}
+		if d <= 0 {
+			return opts, errors.New("the snapshotInterval must be positive")
+		}
+		opts.interval = d
+	}
+
+	quantiles := obj.Get("quantiles")
+	if common.IsNullish(quantiles) {
+		return opts, nil
+	}
+	if !summary {
+		return opts, errors.New("only the summaries have quantiles")
+	}
+	opts.quantiles = nil
+	if err := rt.ExportTo(quantiles, &opts.quantiles); err != nil {
+		return opts, fmt.Errorf("invalid quantiles: %w", err)
+	}
+	if len(opts.quantiles) == 0 {
+		return opts, errors.New("the summaries need at least one quantile")
+	}
+	for _, q := range opts.quantiles {
+		if q <= 0 || q > 1 {
+			return opts, fmt.Errorf("the quantiles must be between 0 and 1, not %v", q)
+		}
+	}
+	return opts, nil
+}
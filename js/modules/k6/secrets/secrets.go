@@ -0,0 +1,98 @@
+// Package secrets provides a k6 module to get the secrets of the test, at
+// runtime, from the sources configured with the --secret-source option. The
+// secrets are redacted from the logs.
+package secrets
+
+import (
+	"errors"
+
+	"github.com/grafana/sobek"
+
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/js/promises"
+	"go.k6.io/k6/secretsource"
+)
+
+type (
+	// RootModule is the global module instance that will create module
+	// instances for each VU.
+	RootModule struct{}
+
+	// Secrets represents an instance of the secrets module.
+	Secrets struct {
+		vu      modules.VU
+		manager *secretsource.Manager
+	}
+)
+
+var (
+	_ modules.Module   = &RootModule{}
+	_ modules.Instance = &Secrets{}
+)
+
+// New returns a pointer to a new RootModule instance.
+func New() *RootModule {
+	return &RootModule{}
+}
+
+// NewModuleInstance implements the modules.Module interface to return
+// a new instance for each VU.
+func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	s := &Secrets{vu: vu}
+	if initEnv := vu.InitEnv(); initEnv != nil && initEnv.TestPreInitState != nil {
+		s.manager = initEnv.SecretsManager
+	}
+	return s
+}
+
+// Exports returns the exports of the secrets module.
+func (s *Secrets) Exports() modules.Exports {
+	return modules.Exports{
+		Named: map[string]interface{}{
+			"get":    s.getFrom(""),
+			"source": s.source,
+		},
+	}
+}
+
+// source returns the object to get the secrets from the named source, rather
+// than from the default one.
+func (s *Secrets) source(name string) *sobek.Object {
+	rt := s.vu.Runtime()
+	if name == "" {
+		common.Throw(rt, errors.New("the name of the secret source is required"))
+	}
+	obj := rt.NewObject()
+	if err := obj.Set("get", s.getFrom(name)); err != nil {
+		common.Throw(rt, err)
+	}
+	return obj
+}
+
+// getFrom returns the get() function of the named source, which returns a
+// promise of the secret of the given key. The secrets managers are queried
+// asynchronously, so the event loop isn't blocked.
+func (s *Secrets) getFrom(sourceName string) func(key string) *sobek.Promise {
+	return func(key string) *sobek.Promise {
+		promise, resolve, reject := promises.New(s.vu)
+		if s.manager == nil {
+			reject(errors.New("no secret sources are configured, use the --secret-source option"))
+			return promise
+		}
+		if key == "" {
+			reject(errors.New("the key of the secret is required"))
+			return promise
+		}
+
+		go func() {
+			secret, err := s.manager.Get(sourceName, key)
+			if err != nil {
+				reject(err)
+				return
+			}
+			resolve(secret)
+		}()
+		return promise
+	}
+}
@@ -0,0 +1,53 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/secretsource"
+)
+
+func newSecretsRuntime(t *testing.T, manager *secretsource.Manager) *modulestest.Runtime {
+	t.Helper()
+
+	runtime := modulestest.NewRuntime(t)
+	runtime.VU.InitEnvField.SecretsManager = manager
+	err := runtime.SetupModuleSystem(map[string]any{"k6/secrets": New()}, nil, nil)
+	require.NoError(t, err)
+	_, err = runtime.VU.Runtime().RunString(`var secrets = require("k6/secrets");`)
+	require.NoError(t, err)
+	runtime.MoveToVUContext(&lib.State{})
+
+	return runtime
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	manager, err := secretsource.NewManager(map[string]secretsource.Source{
+		"env": secretsource.NewEnvSource(map[string]string{"password": "hunter2"}, ""),
+		"api": secretsource.NewEnvSource(map[string]string{"API_TOKEN": "abc"}, "API_"),
+	}, "env")
+	require.NoError(t, err)
+
+	runtime := newSecretsRuntime(t, manager)
+	_, err = runtime.RunOnEventLoop(`(async () => {
+		const password = await secrets.get("password");
+		if (password !== "hunter2") throw new Error("wrong password " + password);
+		const token = await secrets.source("api").get("TOKEN");
+		if (token !== "abc") throw new Error("wrong token " + token);
+		await secrets.get("user");
+	})()`)
+	require.ErrorContains(t, err, `couldn't get the secret "user" from the environment`)
+}
+
+func TestGetWithoutSources(t *testing.T) {
+	t.Parallel()
+
+	runtime := newSecretsRuntime(t, nil)
+	_, err := runtime.RunOnEventLoop(`(async () => { await secrets.get("password"); })()`)
+	require.ErrorContains(t, err, "no secret sources are configured, use the --secret-source option")
+}
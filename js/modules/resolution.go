@@ -119,6 +119,9 @@ func (mr *ModuleResolver) resolveLoaded(basePWD *url.URL, arg string, data []byt
 	}
 	mr.reverse[mod] = specifier
 	mr.cache[specifier.String()] = moduleCacheElement{mod: mod, err: err}
+	if err == nil {
+		mr.preResolveDynamicImports(mod, prg)
+	}
 	return mod, err
 }
 
@@ -215,11 +218,13 @@ func NewModuleSystem(resolver *ModuleResolver, vu VU) *ModuleSystem {
 	// TODO:figure out if we can remove this
 	_ = rt.GlobalObject().DefineDataProperty("vubox",
 		rt.ToValue(vubox{vu: vu}), sobek.FLAG_FALSE, sobek.FLAG_FALSE, sobek.FLAG_FALSE)
-	return &ModuleSystem{
+	ms := &ModuleSystem{
 		resolver:      resolver,
 		instanceCache: make(map[sobek.ModuleRecord]sobek.ModuleInstance),
 		vu:            vu,
 	}
+	rt.SetImportModuleDynamically(ms.importModuleDynamically)
+	return ms
 }
 
 // RunSourceData runs the provided sourceData and adds it to the cache.
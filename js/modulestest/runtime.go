@@ -41,7 +41,8 @@ func NewRuntime(t testing.TB) *Runtime {
 			Logger:   testutils.NewLogger(t),
 			Registry: metrics.NewRegistry(),
 		},
-		CWD: new(url.URL),
+		CWD:   new(url.URL),
+		Hooks: &lib.Hooks{},
 	}
 
 	eventloop := eventloop.New(vu)
@@ -59,6 +60,9 @@ func NewRuntime(t testing.TB) *Runtime {
 
 // MoveToVUContext will set the state and nil the InitEnv just as a real VU
 func (r *Runtime) MoveToVUContext(state *lib.State) {
+	if state != nil && state.Hooks == nil && r.VU.InitEnvField != nil {
+		state.Hooks = r.VU.InitEnvField.Hooks
+	}
 	r.VU.InitEnvField = nil
 	r.VU.StateField = state
 }
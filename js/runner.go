@@ -30,6 +30,7 @@ import (
 	"go.k6.io/k6/lib/consts"
 	"go.k6.io/k6/lib/fsext"
 	"go.k6.io/k6/lib/netext"
+	"go.k6.io/k6/lib/netext/httpext"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/loader"
 	"go.k6.io/k6/metrics"
@@ -192,7 +193,7 @@ func (r *Runner) newVU(
 		tlsConfig.NameToCertificate = nameToCert
 	}
 	transport := &http.Transport{
-		Proxy:               http.ProxyFromEnvironment,
+		Proxy:               httpext.ProxyFromContext(http.ProxyFromEnvironment),
 		TLSClientConfig:     tlsConfig,
 		DialContext:         dialer.DialContext,
 		DisableCompression:  true,
@@ -201,6 +202,10 @@ func (r *Runner) newVU(
 		MaxIdleConnsPerHost: int(r.Bundle.Options.BatchPerHost.Int64),
 	}
 
+	if maxIdle := r.Bundle.Options.MaxIdleConnsPerHost; maxIdle.Valid {
+		transport.MaxIdleConnsPerHost = int(maxIdle.Int64)
+	}
+
 	if r.forceHTTP1() {
 		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper) // send over h1 protocol
 	} else {
@@ -243,8 +248,13 @@ func (r *Runner) newVU(
 		Tags:           lib.NewVUStateTags(vu.Runner.RunTags),
 		BuiltinMetrics: r.preInitState.BuiltinMetrics,
 		TracerProvider: r.preInitState.TracerProvider,
+		FailureCapture: r.preInitState.FailureCapture,
+		Hooks:          vu.hooks,
 	}
 	vu.moduleVUImpl.state = vu.state
+	if vu.Runner.Bundle.Options.LoadGeneratorMetrics.Bool {
+		vu.moduleVUImpl.eventLoop.TrackLag(true)
+	}
 	_ = vu.Runtime.Set("console", vu.Console)
 
 	return vu, nil
@@ -280,25 +290,39 @@ func (r *Runner) Setup(ctx context.Context, out chan<- metrics.SampleContainer)
 	}
 	r.preInitState.Logger.Debugf("Running %s()...", consts.SetupFn)
 
+	// r.setupData = nil is special it means undefined from this moment forward
+	var err error
+	r.setupData, err = r.runSetup(ctx, out, consts.SetupFn)
+	return err
+}
+
+// ScenarioSetup runs the given function as the setup of a scenario and returns
+// the JSON representation of its result, nil meaning undefined.
+func (r *Runner) ScenarioSetup(ctx context.Context, out chan<- metrics.SampleContainer, fn string) ([]byte, error) {
+	r.preInitState.Logger.Debugf("Running %s()...", fn)
+	return r.runSetup(ctx, out, fn)
+}
+
+// runSetup runs the given setup function, with the setup timeout, and returns
+// the JSON representation of its result, or nil if it's undefined.
+func (r *Runner) runSetup(ctx context.Context, out chan<- metrics.SampleContainer, fn string) ([]byte, error) {
 	setupCtx, setupCancel := context.WithTimeout(ctx, r.getTimeoutFor(consts.SetupFn))
 	defer setupCancel()
 
-	v, err := r.runPart(setupCtx, out, consts.SetupFn, nil)
+	v, err := r.runPart(setupCtx, out, consts.SetupFn, fn, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// r.setupData = nil is special it means undefined from this moment forward
 	if sobek.IsUndefined(v) {
-		r.setupData = nil
-		return nil
+		return nil, nil
 	}
 
-	r.setupData, err = json.Marshal(v.Export())
+	data, err := json.Marshal(v.Export())
 	if err != nil {
-		return fmt.Errorf("error marshaling setup() data to JSON: %w", err)
+		return nil, fmt.Errorf("error marshaling %s() data to JSON: %w", fn, err)
 	}
 	var tmp interface{}
-	return json.Unmarshal(r.setupData, &tmp)
+	return data, json.Unmarshal(data, &tmp)
 }
 
 // GetSetupData returns the setup data as json if Setup() was specified and executed, nil otherwise
@@ -321,18 +345,35 @@ func (r *Runner) Teardown(ctx context.Context, out chan<- metrics.SampleContaine
 	}
 	r.preInitState.Logger.Debugf("Running %s()...", consts.TeardownFn)
 
+	return r.runTeardown(ctx, out, consts.TeardownFn, r.setupData)
+}
+
+// ScenarioTeardown runs the given function as the teardown of a scenario, with
+// the data returned by the setup of the scenario.
+func (r *Runner) ScenarioTeardown(
+	ctx context.Context, out chan<- metrics.SampleContainer, fn string, setupData []byte,
+) error {
+	r.preInitState.Logger.Debugf("Running %s()...", fn)
+	return r.runTeardown(ctx, out, fn, setupData)
+}
+
+// runTeardown runs the given teardown function, with the teardown timeout and
+// the provided setup data.
+func (r *Runner) runTeardown(
+	ctx context.Context, out chan<- metrics.SampleContainer, fn string, setupData []byte,
+) error {
 	teardownCtx, teardownCancel := context.WithTimeout(ctx, r.getTimeoutFor(consts.TeardownFn))
 	defer teardownCancel()
 
 	var data interface{}
-	if r.setupData != nil {
-		if err := json.Unmarshal(r.setupData, &data); err != nil {
-			return fmt.Errorf("error unmarshaling setup data for teardown() from JSON: %w", err)
+	if setupData != nil {
+		if err := json.Unmarshal(setupData, &data); err != nil {
+			return fmt.Errorf("error unmarshaling setup data for %s() from JSON: %w", fn, err)
 		}
 	} else {
 		data = sobek.Undefined()
 	}
-	_, err := r.runPart(teardownCtx, out, consts.TeardownFn, data)
+	_, err := r.runPart(teardownCtx, out, consts.TeardownFn, fn, data)
 	return err
 }
 
@@ -521,10 +562,12 @@ func parseTTL(ttlS string) (time.Duration, error) {
 }
 
 // Runs an exported function in its own temporary VU, optionally with an argument. Execution is
-// interrupted if the context expires. No error is returned if the part does not exist.
+// interrupted if the context expires, the error then being a timeout of the given stage. No
+// error is returned if the part does not exist.
 func (r *Runner) runPart(
 	parentCtx context.Context,
 	out chan<- metrics.SampleContainer,
+	stage string,
 	name string,
 	arg interface{},
 ) (sobek.Value, error) {
@@ -558,7 +601,7 @@ func (r *Runner) runPart(
 	}
 	v, _, _, err := vu.runFn(ctx, false, fn, nil, vu.Runtime.ToValue(arg))
 
-	if deadlineError := r.checkDeadline(ctx, name, v, err); deadlineError != nil {
+	if deadlineError := r.checkDeadline(ctx, stage, v, err); deadlineError != nil {
 		return nil, deadlineError
 	}
 
@@ -635,8 +678,12 @@ type ActiveVU struct {
 	busy chan struct{}
 
 	scenarioName              string
+	scenarioSetupData         sobek.Value
 	getNextIterationCounters  func() (uint64, uint64)
 	scIterLocal, scIterGlobal uint64
+
+	// inWarmup is whether the samples are tagged with the warm-up phase.
+	inWarmup bool
 }
 
 // GetID returns the unique VU ID.
@@ -684,6 +731,7 @@ func (u *VU) Activate(params *lib.VUActivationParams) lib.ActiveVU {
 
 	ctx := params.RunContext
 	u.moduleVUImpl.ctx = ctx
+	u.Dialer.SetFaults(params.Faults)
 
 	u.state.GetScenarioVUIter = func() uint64 {
 		return u.scenarioIter[params.Scenario]
@@ -735,9 +783,24 @@ func (u *ActiveVU) RunOnce() error {
 		<-u.busy // unlock deactivation again
 	}()
 
-	// Unmarshall the setupData only the first time for each VU so that VUs are isolated but we
-	// still don't use too much CPU in the middle test
-	if u.setupData == nil {
+	// The scenarios with their own setup function get its data instead, unmarshalled only the
+	// first time for each activation
+	setupData := u.setupData
+	if data, ok := lib.GetScenarioSetupData(u.RunContext); ok {
+		if u.scenarioSetupData == nil {
+			u.scenarioSetupData = sobek.Undefined()
+			if data != nil {
+				var v interface{}
+				if err := json.Unmarshal(data, &v); err != nil {
+					return fmt.Errorf("error unmarshaling scenario setup data for the iteration from JSON: %w", err)
+				}
+				u.scenarioSetupData = u.Runtime.ToValue(v)
+			}
+		}
+		setupData = u.scenarioSetupData
+	} else if u.setupData == nil {
+		// Unmarshall the setupData only the first time for each VU so that VUs are isolated but we
+		// still don't use too much CPU in the middle test
 		if u.Runner.setupData != nil {
 			var data interface{}
 			if err := json.Unmarshal(u.Runner.setupData, &data); err != nil {
@@ -747,6 +810,7 @@ func (u *ActiveVU) RunOnce() error {
 		} else {
 			u.setupData = sobek.Undefined()
 		}
+		setupData = u.setupData
 	}
 
 	fn := u.getCallableExport(u.Exec)
@@ -759,6 +823,9 @@ func (u *ActiveVU) RunOnce() error {
 	if err := u.Runtime.Set("__ITER", u.iteration); err != nil {
 		panic(fmt.Errorf("error setting __ITER in Sobek runtime: %w", err))
 	}
+	if u.Warmup != nil {
+		u.updateWarmupPhase()
+	}
 
 	ctx, cancel := context.WithCancel(u.RunContext)
 	defer cancel()
@@ -773,7 +840,10 @@ func (u *ActiveVU) RunOnce() error {
 	u.emitAndWaitEvent(&event.Event{Type: event.IterStart, Data: eventIterData})
 
 	// Call the exported function.
-	_, isFullIteration, totalTime, err := u.runFn(ctx, true, fn, cancel, u.setupData)
+	_, isFullIteration, totalTime, err := u.runFn(ctx, true, fn, cancel, setupData)
+	if isFullIteration && u.state.Hooks.HasIterationEndHooks() {
+		err = u.runIterationEndHooks(err)
+	}
 	if err != nil {
 		var x *sobek.InterruptedError
 		if errors.As(err, &x) {
@@ -802,6 +872,25 @@ func (u *ActiveVU) RunOnce() error {
 	return err
 }
 
+// runIterationEndHooks invokes the iteration end hooks once the iteration and
+// its async operations are done, in the context of the VU's run, as the one of
+// the iteration is canceled by then. It returns the error of the iteration, or
+// else the one of the hooks.
+func (u *ActiveVU) runIterationEndHooks(iterErr error) error {
+	u.moduleVUImpl.ctx = u.RunContext
+	err := u.moduleVUImpl.eventLoop.Start(func() error {
+		return u.state.Hooks.IterationEnd(iterErr)
+	})
+	if iterErr != nil {
+		return iterErr
+	}
+	var exception *sobek.Exception
+	if errors.As(err, &exception) {
+		err = &scriptExceptionError{inner: exception}
+	}
+	return err
+}
+
 func (u *ActiveVU) emitAndWaitEvent(evt *event.Event) {
 	waitDone := u.moduleVUImpl.events.local.Emit(evt)
 	waitCtx, waitCancel := context.WithTimeout(u.RunContext, 30*time.Minute)
@@ -836,11 +925,18 @@ func (u *VU) runFn(
 	}
 
 	startTime := time.Now()
+	u.state.IterationStart = startTime
 
 	if u.moduleVUImpl.eventLoop == nil {
 		u.moduleVUImpl.eventLoop = eventloop.New(u.moduleVUImpl)
+		u.moduleVUImpl.eventLoop.TrackLag(opts.LoadGeneratorMetrics.Bool)
 	}
 	err = u.moduleVUImpl.eventLoop.Start(func() (err error) {
+		if isDefault {
+			if err = u.state.Hooks.IterationStart(); err != nil {
+				return err
+			}
+		}
 		v, err = fn(sobek.Undefined(), args...) // Actually run the JS script
 		return err
 	})
@@ -870,6 +966,19 @@ func (u *VU) runFn(
 		startTime, endTime, isFullIteration,
 		isDefault, u.state.Tags.GetCurrentValues(), u.Runner.preInitState.BuiltinMetrics)
 
+	if lag := u.moduleVUImpl.eventLoop.MaxLag(); opts.LoadGeneratorMetrics.Bool && lag > 0 {
+		ctm := u.state.Tags.GetCurrentValues()
+		u.state.Samples <- metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: u.Runner.preInitState.BuiltinMetrics.LoadGeneratorEventLoopLag,
+				Tags:   ctm.Tags,
+			},
+			Time:     endTime,
+			Metadata: ctm.Metadata,
+			Value:    metrics.D(lag),
+		}
+	}
+
 	v = unPromisify(v)
 
 	return v, isFullIteration, endTime.Sub(startTime), err
@@ -890,6 +999,32 @@ func (u *ActiveVU) incrIteration() {
 	}
 }
 
+// updateWarmupPhase tags the samples with the warm-up phase of the scenario,
+// as long as it's in progress.
+func (u *ActiveVU) updateWarmupPhase() {
+	var startTime time.Time
+	if ss := lib.GetScenarioState(u.RunContext); ss != nil {
+		startTime = ss.StartTime
+	}
+	inWarmup := u.Warmup.InProgress(startTime, u.scIterGlobal)
+	if inWarmup == u.inWarmup {
+		return
+	}
+	u.inWarmup = inWarmup
+
+	u.state.Tags.Modify(func(tagsAndMeta *metrics.TagsAndMeta) {
+		if !inWarmup {
+			tagsAndMeta.DeleteTag(lib.WarmupPhaseTag)
+			tagsAndMeta.DeleteMetadata(lib.WarmupDropMetadata)
+			return
+		}
+		tagsAndMeta.SetTag(lib.WarmupPhaseTag, lib.WarmupPhase)
+		if u.Warmup.DropsSamples() {
+			tagsAndMeta.SetMetadata(lib.WarmupDropMetadata, "true")
+		}
+	})
+}
+
 type scriptExceptionError struct {
 	inner *sobek.Exception
 }
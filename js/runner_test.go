@@ -8,6 +8,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"go/build"
@@ -534,6 +535,60 @@ func TestSetupDataPromise(t *testing.T) {
 	};`)
 }
 
+func TestScenarioSetupData(t *testing.T) {
+	t.Parallel()
+	r, err := getSimpleRunner(t, "/script.js", `
+	exports.options = { setupTimeout: "1s", teardownTimeout: "1s" };
+	exports.setup = function() {
+		return 42;
+	}
+	exports.checkoutSetup = function() {
+		return { cart: "abc" };
+	}
+	exports.default = function(data) {
+		var expected = __ENV.SCENARIO_DATA ? JSON.parse(__ENV.SCENARIO_DATA) : 42;
+		if (JSON.stringify(data) !== JSON.stringify(expected)) {
+			throw new Error("default: wrong data: " + JSON.stringify(data))
+		}
+	};
+	exports.checkoutTeardown = function(data) {
+		if (data.cart !== "abc") {
+			throw new Error("checkoutTeardown: wrong data: " + JSON.stringify(data))
+		}
+	};`)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	samples := make(chan metrics.SampleContainer, 100)
+
+	require.NoError(t, r.Setup(ctx, samples))
+	data, err := r.ScenarioSetup(ctx, samples, "checkoutSetup")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"cart":"abc"}`, string(data))
+
+	initVU, err := r.NewVU(ctx, 1, 1, samples)
+	require.NoError(t, err)
+
+	// The same VU gets the scenario data in the scenario and the test data in
+	// the others
+	scenarioCtx, scenarioCancel := context.WithCancel(lib.WithScenarioSetupData(ctx, data))
+	deactivated := make(chan struct{})
+	vu := initVU.Activate(&lib.VUActivationParams{
+		RunContext:         scenarioCtx,
+		DeactivateCallback: func(lib.InitializedVU) { close(deactivated) },
+		Env:                map[string]string{"SCENARIO_DATA": string(data)},
+	})
+	require.NoError(t, vu.RunOnce())
+	scenarioCancel()
+	<-deactivated
+
+	vu = initVU.Activate(&lib.VUActivationParams{RunContext: ctx})
+	require.NoError(t, vu.RunOnce())
+
+	require.NoError(t, r.ScenarioTeardown(ctx, samples, "checkoutTeardown", data))
+}
+
 func TestRunnerIntegrationImports(t *testing.T) {
 	t.Parallel()
 	t.Run("Modules", func(t *testing.T) {
@@ -1406,6 +1461,62 @@ func TestVUIntegrationOpenFunctionErrorWhenSneaky(t *testing.T) {
 	assert.Contains(t, err.Error(), "only available in the init stage")
 }
 
+func TestVUIntegrationDynamicImport(t *testing.T) {
+	t.Parallel()
+
+	fs := fsext.NewMemMapFs()
+	require.NoError(t, fsext.WriteFile(fs, "/scenarios/browse.js", []byte(`
+		import { value } from "../lib/value.js";
+		export function run() { return "browse " + value; }
+	`), 0o644))
+	require.NoError(t, fsext.WriteFile(fs, "/lib/value.js", []byte(`export const value = 42;`), 0o644))
+	require.NoError(t, fsext.WriteFile(fs, "/lib/legacy.js", []byte(`
+		exports.load = function() { return import("./value.js"); };
+	`), 0o644))
+
+	r, err := getSimpleRunner(t, "/script.js", `
+		const legacy = require("./lib/legacy.js");
+		const missing = "./lib/missing.js";
+
+		export default async function() {
+			const browse = await import("./scenarios/browse.js");
+			if (browse.run() !== "browse 42") {
+				throw new Error("unexpected result " + browse.run());
+			}
+
+			const { value } = await legacy.load();
+			if (value !== 42) {
+				throw new Error("unexpected value " + value);
+			}
+			if ((await import("./lib/legacy.js")).load !== legacy.load) {
+				throw new Error("the CommonJS module wasn't imported");
+			}
+
+			const k6 = await import("k6");
+			if (typeof k6.check !== "function") {
+				throw new Error("k6 wasn't imported");
+			}
+
+			try {
+				await import(missing);
+				throw new Error("importing a module that wasn't resolved in the init context didn't fail");
+			} catch (e) {
+				if (!e.toString().includes("was not previously resolved")) {
+					throw e;
+				}
+			}
+		}
+	`, fs)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	initVU, err := r.NewVU(ctx, 1, 1, make(chan metrics.SampleContainer, 100))
+	require.NoError(t, err)
+	vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx})
+	require.NoError(t, vu.RunOnce())
+}
+
 func TestVUDoesOpenUnderV0Condition(t *testing.T) {
 	t.Parallel()
 
@@ -1855,6 +1966,102 @@ func TestVUIntegrationClientCerts(t *testing.T) {
 	}
 }
 
+func TestVUIntegrationPerRequestClientCerts(t *testing.T) {
+	t.Parallel()
+
+	caCertPem, caKeyPem := generateTLSCertificate(t, "127.0.0.1", time.Now(), time.Hour)
+	caCertBlock, _ := pem.Decode(caCertPem)
+	caCert, err := x509.ParseCertificate(caCertBlock.Bytes)
+	require.NoError(t, err)
+	caKeyBlock, _ := pem.Decode(caKeyPem)
+	caKeyAny, err := x509.ParsePKCS8PrivateKey(caKeyBlock.Bytes)
+	require.NoError(t, err)
+	caKey, ok := caKeyAny.(*rsa.PrivateKey)
+	require.True(t, ok)
+
+	srvCertPem, srvKeyPem := generateTLSCertificateWithCA(t, "127.0.0.1", time.Now(), time.Hour, caCert, caKey)
+	serverCert, err := tls.X509KeyPair(append(srvCertPem, caCertPem...), srvKeyPem)
+	require.NoError(t, err)
+	clientCAPool := x509.NewCertPool()
+	require.True(t, clientCAPool.AppendCertsFromPEM(caCertPem))
+
+	// The server responds with the serial number of the client certificate.
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{ //nolint:gosec
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    clientCAPool,
+	})
+	require.NoError(t, err)
+	srv := &http.Server{ //nolint:gosec
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(r.TLS.PeerCertificates) == 0 {
+				_, _ = fmt.Fprint(w, "none")
+				return
+			}
+			_, _ = fmt.Fprint(w, r.TLS.PeerCertificates[0].SerialNumber.String())
+		}),
+		ErrorLog: stdlog.New(io.Discard, "", 0),
+	}
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(func() { _ = listener.Close() })
+
+	serials := make([]string, 3)
+	certs := make([]lib.TLSAuthFields, 3)
+	for i := range certs {
+		certPem, keyPem := generateTLSCertificateWithCA(t, "127.0.0.1", time.Now(), time.Hour, caCert, caKey)
+		certs[i] = lib.TLSAuthFields{Cert: string(certPem), Key: string(keyPem)}
+		block, _ := pem.Decode(certPem)
+		cert, err := x509.ParseCertificate(block.Bytes)
+		require.NoError(t, err)
+		serials[i] = cert.SerialNumber.String()
+	}
+
+	r, err := getSimpleRunner(t, "/script.js", fmt.Sprintf(`
+		var http = require("k6/http");
+		var certs = %s;
+		var serials = %s;
+		var url = "https://%s";
+		exports.default = function() {
+			var expect = function(params, expected) {
+				var body = http.get(url, params).body;
+				if (body !== expected) {
+					throw new Error("expected the " + expected + " certificate, but got " + body);
+				}
+			};
+			expect({}, serials[0]);
+			expect({ tlsAuth: certs[1] }, serials[1]);
+			expect({ tlsAuth: certs[2] }, serials[2]);
+			expect({ tlsAuth: certs[1] }, serials[1]);
+			expect(null, serials[0]);
+
+			var res = http.get(url, { tlsAuth: { cert: certs[1].cert } });
+			if (!res.error.includes("invalid tlsAuth value: both a cert and a key are required")) {
+				throw new Error("unexpected error: " + res.error);
+			}
+		}`, mustMarshal(t, certs), mustMarshal(t, serials), listener.Addr().String()))
+	require.NoError(t, err)
+
+	require.NoError(t, r.SetOptions(lib.Options{
+		Throw:                 null.BoolFrom(false),
+		InsecureSkipTLSVerify: null.BoolFrom(true),
+		TLSAuth:               []*lib.TLSAuth{{TLSAuthFields: certs[0]}},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	initVU, err := r.NewVU(ctx, 1, 1, make(chan metrics.SampleContainer, 100))
+	require.NoError(t, err)
+	vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx})
+	require.NoError(t, vu.RunOnce())
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
+
 func TestHTTPRequestInInitContext(t *testing.T) {
 	t.Parallel()
 	tb := httpmultibin.NewHTTPMultiBin(t)
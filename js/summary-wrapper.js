@@ -26,6 +26,8 @@
             var newFormatChecks = group.checks;
             group.checks = {};
             for (var i = 0; i < newFormatChecks.length; i++) {
+                // The diagnostics of the failures are only a part of the new format.
+                delete newFormatChecks[i].failures;
                 group.checks[newFormatChecks[i].name] = newFormatChecks[i];
             }
         }
@@ -37,6 +39,7 @@
         var results = JSON.parse(JSON.stringify(data));
         delete results.options;
         delete results.state;
+        delete results.baseline;
 
         forEach(results.metrics, function (metricName, metric) {
             var oldFormatMetric = metric.values;
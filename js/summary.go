@@ -60,28 +60,73 @@ func metricValueGetter(summaryTrendStats []string) func(metrics.Sink, time.Durat
 // summarizeMetricsToObject transforms the summary objects in a way that's
 // suitable to pass to the JS runtime or export to JSON.
 func summarizeMetricsToObject(data *lib.Summary, options lib.Options, setupData []byte) map[string]interface{} {
+	getMetricValues := metricValueGetter(options.SummaryTrendStats)
+
 	m := make(map[string]interface{})
-	m["root_group"] = exportGroup(data.RootGroup)
+	m["root_group"] = exportGroup(data.RootGroup, data.GroupMetrics, getMetricValues, data.TestRunDuration)
 	m["options"] = map[string]interface{}{
 		// TODO: improve when we can easily export all option values, including defaults?
 		"summaryTrendStats": options.SummaryTrendStats,
 		"summaryTimeUnit":   options.SummaryTimeUnit.String,
 		"noColor":           data.NoColor, // TODO: move to the (runtime) options
 	}
-	m["state"] = map[string]interface{}{
+	state := map[string]interface{}{
 		"isStdOutTTY":       data.UIState.IsStdOutTTY,
 		"isStdErrTTY":       data.UIState.IsStdErrTTY,
 		"testRunDurationMs": float64(data.TestRunDuration) / float64(time.Millisecond),
 	}
+	if data.AbortReason != "" {
+		state["abortReason"] = data.AbortReason
+	}
+	if len(data.FailureReasons) > 0 {
+		state["failureReasons"] = data.FailureReasons
+	}
+	m["state"] = state
 
-	getMetricValues := metricValueGetter(options.SummaryTrendStats)
+	m["metrics"] = exportMetrics(data.Metrics, getMetricValues, data.TestRunDuration)
+
+	if data.ScenarioMetrics != nil {
+		scenarios := make(map[string]interface{}, len(data.ScenarioMetrics))
+		for name, scenarioMetrics := range data.ScenarioMetrics {
+			scenarios[name] = map[string]interface{}{
+				"metrics": exportMetrics(scenarioMetrics, getMetricValues, data.TestRunDuration),
+			}
+		}
+		m["scenarios"] = scenarios
+	}
+
+	if data.Baseline != "" {
+		m["baseline"] = exportBaseline(data)
+	}
+
+	var setupDataI interface{}
+	if setupData != nil {
+		if err := json.Unmarshal(setupData, &setupDataI); err != nil {
+			// TODO: log the error
+			return m
+		}
+	} else {
+		setupDataI = sobek.Undefined()
+	}
 
-	metricsData := make(map[string]interface{})
-	for name, m := range data.Metrics {
+	m["setup_data"] = setupDataI
+
+	return m
+}
+
+// exportMetrics transforms the provided metrics, and their thresholds, in a
+// way that's suitable to pass to the JS runtime or export to JSON.
+func exportMetrics(
+	observed map[string]*metrics.Metric,
+	getMetricValues func(metrics.Sink, time.Duration) map[string]float64,
+	testRunDuration time.Duration,
+) map[string]interface{} {
+	metricsData := make(map[string]interface{}, len(observed))
+	for name, m := range observed {
 		metricData := map[string]interface{}{
 			"type":     m.Type.String(),
 			"contains": m.Contains.String(),
-			"values":   getMetricValues(m.Sink, data.TestRunDuration),
+			"values":   getMetricValues(m.Sink, testRunDuration),
 		}
 
 		if len(m.Thresholds.Thresholds) > 0 {
@@ -95,27 +140,59 @@ func summarizeMetricsToObject(data *lib.Summary, options lib.Options, setupData
 		}
 		metricsData[name] = metricData
 	}
-	m["metrics"] = metricsData
 
-	var setupDataI interface{}
-	if setupData != nil {
-		if err := json.Unmarshal(setupData, &setupDataI); err != nil {
-			// TODO: log the error
-			return m
+	return metricsData
+}
+
+// exportBaseline transforms the comparisons of the metrics with the baseline
+// in a way that's suitable to pass to the JS runtime or export to JSON.
+func exportBaseline(data *lib.Summary) map[string]interface{} {
+	comparisons := make([]map[string]interface{}, len(data.BaselineComparisons))
+	for i, c := range data.BaselineComparisons {
+		comparisons[i] = map[string]interface{}{
+			"metric":     c.Metric,
+			"stat":       c.Stat,
+			"baseline":   c.Baseline,
+			"current":    c.Current,
+			"change":     c.Change,
+			"tolerance":  c.Tolerance,
+			"regression": c.Regression,
 		}
-	} else {
-		setupDataI = sobek.Undefined()
 	}
 
-	m["setup_data"] = setupDataI
+	return map[string]interface{}{
+		"path":        data.Baseline,
+		"comparisons": comparisons,
+	}
+}
 
-	return m
+// exportCheckFailures transforms the diagnostics of the failures of a check,
+// omitting their empty fields.
+func exportCheckFailures(failures []lib.CheckFailure) []map[string]interface{} {
+	exported := make([]map[string]interface{}, len(failures))
+	for i, f := range failures {
+		exported[i] = map[string]interface{}{"message": f.Message}
+		for key, value := range map[string]string{"expected": f.Expected, "actual": f.Actual, "payload": f.Payload} {
+			if value != "" {
+				exported[i][key] = value
+			}
+		}
+	}
+	return exported
 }
 
-func exportGroup(group *lib.Group) map[string]interface{} {
+// exportGroup transforms the provided group, and its subgroups, along with
+// the metrics observed in them, if any, in a way that's suitable to pass to
+// the JS runtime or export to JSON.
+func exportGroup(
+	group *lib.Group,
+	groupMetrics map[string]map[string]*metrics.Metric,
+	getMetricValues func(metrics.Sink, time.Duration) map[string]float64,
+	testRunDuration time.Duration,
+) map[string]interface{} {
 	subGroups := make([]map[string]interface{}, len(group.OrderedGroups))
 	for i, subGroup := range group.OrderedGroups {
-		subGroups[i] = exportGroup(subGroup)
+		subGroups[i] = exportGroup(subGroup, groupMetrics, getMetricValues, testRunDuration)
 	}
 
 	checks := make([]map[string]interface{}, len(group.OrderedChecks))
@@ -127,15 +204,23 @@ func exportGroup(group *lib.Group) map[string]interface{} {
 			"passes": check.Passes,
 			"fails":  check.Fails,
 		}
+		if len(check.Failures) > 0 {
+			checks[i]["failures"] = exportCheckFailures(check.Failures)
+		}
 	}
 
-	return map[string]interface{}{
+	exported := map[string]interface{}{
 		"name":   group.Name,
 		"path":   group.Path,
 		"id":     group.ID,
 		"groups": subGroups,
 		"checks": checks,
 	}
+	if observed, ok := groupMetrics[group.Path]; ok {
+		exported["metrics"] = exportMetrics(observed, getMetricValues, testRunDuration)
+	}
+
+	return exported
 }
 
 func getSummaryResult(rawResult sobek.Value) (map[string]io.Reader, error) {
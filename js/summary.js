@@ -73,6 +73,16 @@ function summarizeCheck(indent, check, decorate) {
   }
 
   var succPercent = Math.floor((100 * check.passes) / (check.passes + check.fails))
+  var failures = ''
+  for (var f of check.failures || []) {
+    failures += '\n' + indent + '   ' + failMark + ' ' + f.message
+    if (f.actual !== undefined) {
+      failures += ', got ' + f.actual
+    }
+    if (f.payload !== undefined) {
+      failures += '\n' + indent + '     payload: ' + f.payload
+    }
+  }
   return decorate(
     indent +
     failMark +
@@ -91,7 +101,8 @@ function summarizeCheck(indent, check, decorate) {
     ' / ' +
     failMark +
     ' ' +
-    check.fails,
+    check.fails +
+    failures,
     palette.red
   )
 }
@@ -407,8 +418,86 @@ function generateTextSummary(data, options) {
 
   Array.prototype.push.apply(lines, summarizeMetrics(mergedOpts, data, decorate))
 
+  Array.prototype.push.apply(lines, summarizeScenarios(mergedOpts, data, decorate))
+
+  Array.prototype.push.apply(lines, summarizeBaseline(mergedOpts, data, decorate))
+
   return lines.join('\n')
 }
 
+function summarizeScenarios(options, data, decorate) {
+  var result = []
+
+  // The breakdown would only repeat the metrics of the whole test run.
+  var names = Object.keys(data.scenarios || {}).sort()
+  if (names.length < 2) {
+    return result
+  }
+
+  var scenarioOpts = Object.assign({}, options, { indent: options.indent + '  ' })
+  for (var name of names) {
+    result.push('')
+    result.push(options.indent + '    ' + groupPrefix + ' scenario ' + name + '\n')
+    Array.prototype.push.apply(result, summarizeMetrics(scenarioOpts, data.scenarios[name], decorate))
+  }
+
+  return result
+}
+
+function humanizeChange(change) {
+  if (!isFinite(change)) {
+    return 'new'
+  }
+  return (change >= 0 ? '+' : '') + (change * 100).toFixed(1) + '%'
+}
+
+function summarizeBaseline(options, data, decorate) {
+  var result = []
+  if (!data.baseline) {
+    return result
+  }
+
+  result.push('')
+  result.push(options.indent + '    ' + groupPrefix + ' baseline ' + data.baseline.path + '\n')
+
+  var comparisons = data.baseline.comparisons
+  if (comparisons.length == 0) {
+    result.push(options.indent + '      no metrics to compare')
+    return result
+  }
+
+  var names = []
+  var nameLenMax = 0
+  for (var c of comparisons) {
+    var name = displayNameForMetric(c.metric) + ' ' + c.stat
+    names.push(name)
+    if (strWidth(name) > nameLenMax) {
+      nameLenMax = strWidth(name)
+    }
+  }
+
+  for (var i = 0; i < comparisons.length; i++) {
+    var c = comparisons[i]
+    var metric = data.metrics[c.metric] || { type: c.stat == 'rate' ? 'rate' : 'trend', contains: 'default' }
+    var color = c.regression ? palette.red : palette.green
+    var fmtName =
+      names[i] + decorate('.'.repeat(nameLenMax - strWidth(names[i]) + 3) + ':', palette.faint)
+    var values =
+      humanizeValue(c.baseline, metric, options.summaryTimeUnit) +
+      ' → ' +
+      decorate(humanizeValue(c.current, metric, options.summaryTimeUnit), palette.cyan)
+    var change = decorate(
+      humanizeChange(c.change) + ' (tolerance ' + toFixedNoTrailingZeros(c.tolerance * 100, 2) + '%)',
+      color
+    )
+
+    result.push(
+      options.indent + '  ' + decorate(c.regression ? failMark : succMark, color) + ' ' + fmtName + ' ' + values + ' ' + change
+    )
+  }
+
+  return result
+}
+
 exports.humanizeValue = humanizeValue
 exports.textSummary = generateTextSummary
\ No newline at end of file
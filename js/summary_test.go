@@ -14,6 +14,7 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/baseline"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/metrics"
 )
@@ -701,3 +702,175 @@ func TestExceptionInHandleSummaryFallsBackToTextSummary(t *testing.T) {
 	assert.Contains(t, errMsg, "\"Error: intentional error\\n\\tat file:///script.js:4:11(3)\\n")
 	assert.Equal(t, logErrors[0].Data, logrus.Fields{"hint": "script exception"})
 }
+
+func TestSummaryBreakdowns(t *testing.T) {
+	t.Parallel()
+
+	newCounter := func(name string, value float64) *metrics.Metric {
+		m := &metrics.Metric{Name: name, Type: metrics.Counter, Sink: &metrics.CounterSink{}}
+		m.Sink.Add(metrics.Sample{Value: value})
+		return m
+	}
+
+	rootG, err := lib.NewGroup("", nil)
+	require.NoError(t, err)
+	_, err = rootG.Group("child")
+	require.NoError(t, err)
+
+	summary := &lib.Summary{
+		Metrics:   map[string]*metrics.Metric{"my_counter": newCounter("my_counter", 3)},
+		RootGroup: rootG,
+		ScenarioMetrics: map[string]map[string]*metrics.Metric{
+			"first":  {"my_counter": newCounter("my_counter", 1)},
+			"second": {"my_counter": newCounter("my_counter", 2)},
+		},
+		GroupMetrics: map[string]map[string]*metrics.Metric{
+			"::child": {"my_counter": newCounter("my_counter", 2)},
+		},
+		TestRunDuration: time.Second,
+	}
+
+	runner, err := getSimpleRunner(
+		t, "/script.js",
+		`
+		exports.default = function() { /* we don't run this, metrics are mocked */ };
+		exports.handleSummary = function(data) {
+			return {'breakdowns.json': JSON.stringify({
+				scenarios: data.scenarios,
+				group: data.root_group.groups[0].metrics,
+				root: data.root_group.metrics,
+			})};
+		};
+		`,
+		lib.RuntimeOptions{CompatibilityMode: null.NewString("base", true)},
+	)
+	require.NoError(t, err)
+
+	result, err := runner.HandleSummary(context.Background(), summary)
+	require.NoError(t, err)
+
+	breakdowns, err := io.ReadAll(result["breakdowns.json"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"scenarios": {
+			"first": {"metrics": {"my_counter": {"type": "counter", "contains": "default", "values": {"count": 1, "rate": 1}}}},
+			"second": {"metrics": {"my_counter": {"type": "counter", "contains": "default", "values": {"count": 2, "rate": 2}}}}
+		},
+		"group": {"my_counter": {"type": "counter", "contains": "default", "values": {"count": 2, "rate": 2}}}
+	}`, string(breakdowns))
+
+	runner, err = getSimpleRunner(
+		t, "/script.js",
+		"exports.default = function() {/* we don't run this, metrics are mocked */};",
+		lib.RuntimeOptions{CompatibilityMode: null.NewString("base", true)},
+	)
+	require.NoError(t, err)
+
+	result, err = runner.HandleSummary(context.Background(), summary)
+	require.NoError(t, err)
+
+	summaryOut, err := io.ReadAll(result["stdout"])
+	require.NoError(t, err)
+	assert.Equal(t, "\n"+
+		"     █ child\n\n"+
+		"     my_counter...: 3 3/s\n"+
+		"\n"+
+		"     █ scenario first\n\n"+
+		"       my_counter...: 1 1/s\n"+
+		"\n"+
+		"     █ scenario second\n\n"+
+		"       my_counter...: 2 2/s\n\n", string(summaryOut))
+}
+
+func TestSummaryBaseline(t *testing.T) {
+	t.Parallel()
+
+	duration := &metrics.Metric{
+		Name: "my_trend", Type: metrics.Trend, Contains: metrics.Time, Sink: metrics.NewTrendSink(),
+	}
+	duration.Sink.Add(metrics.Sample{Value: 150})
+
+	rootG, err := lib.NewGroup("", nil)
+	require.NoError(t, err)
+
+	summary := &lib.Summary{
+		Metrics:         map[string]*metrics.Metric{"my_trend": duration},
+		RootGroup:       rootG,
+		TestRunDuration: time.Second,
+		Baseline:        "baseline.json",
+		BaselineComparisons: []baseline.Comparison{
+			{Metric: "my_trend", Stat: "avg", Baseline: 100, Current: 150, Change: 0.5, Tolerance: 0.1, Regression: true},
+		},
+	}
+
+	runner, err := getSimpleRunner(
+		t, "/script.js",
+		"exports.default = function() {/* we don't run this, metrics are mocked */};",
+		lib.RuntimeOptions{
+			CompatibilityMode: null.NewString("base", true),
+			SummaryExport:     null.StringFrom("summary.json"),
+		},
+	)
+	require.NoError(t, err)
+
+	result, err := runner.HandleSummary(context.Background(), summary)
+	require.NoError(t, err)
+
+	summaryOut, err := io.ReadAll(result["stdout"])
+	require.NoError(t, err)
+	assert.Contains(t, string(summaryOut), "\n"+
+		"     █ baseline baseline.json\n\n"+
+		"   ✗ my_trend avg...: 100ms → 150ms +50.0% (tolerance 10%)\n\n")
+
+	// The comparisons aren't a part of the exported summary, which could be
+	// the baseline of the following runs.
+	exported, err := io.ReadAll(result["summary.json"])
+	require.NoError(t, err)
+	assert.NotContains(t, string(exported), "baseline")
+}
+
+func TestSummaryCheckFailures(t *testing.T) {
+	t.Parallel()
+
+	rootG, err := lib.NewGroup("", nil)
+	require.NoError(t, err)
+	check, err := rootG.Check("status is 200")
+	require.NoError(t, err)
+	check.Passes, check.Fails = 1, 1
+	check.AddFailure(lib.CheckFailure{
+		Message: "expected value to be 200", Expected: "200", Actual: "500", Payload: `{"error":"oops"}`,
+	})
+
+	summary := &lib.Summary{
+		Metrics:         map[string]*metrics.Metric{},
+		RootGroup:       rootG,
+		TestRunDuration: time.Second,
+	}
+
+	runner, err := getSimpleRunner(
+		t, "/script.js",
+		"exports.default = function() {/* we don't run this, metrics are mocked */};",
+		lib.RuntimeOptions{
+			CompatibilityMode: null.NewString("base", true),
+			SummaryExport:     null.StringFrom("summary.json"),
+		},
+	)
+	require.NoError(t, err)
+
+	result, err := runner.HandleSummary(context.Background(), summary)
+	require.NoError(t, err)
+
+	summaryOut, err := io.ReadAll(result["stdout"])
+	require.NoError(t, err)
+	assert.Contains(t, string(summaryOut), "\n"+
+		"     ✗ status is 200\n"+
+		"      ↳  50% — ✓ 1 / ✗ 1\n"+
+		"        ✗ expected value to be 200, got 500\n"+
+		"          payload: {\"error\":\"oops\"}\n")
+
+	// The diagnostics aren't a part of the legacy format of the exported summary.
+	exported, err := io.ReadAll(result["summary.json"])
+	require.NoError(t, err)
+	assert.Contains(t, string(exported), `"status is 200": {`)
+	assert.NotContains(t, string(exported), "failures")
+}
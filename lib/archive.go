@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/fs"
 	"net/url"
+	"os"
 	"path"
 	"path/filepath"
 	"regexp"
@@ -129,19 +130,20 @@ func ReadArchive(in io.Reader) (*Archive, error) {
 			continue
 		}
 
-		data, err := io.ReadAll(r)
-		if err != nil {
-			return nil, err
-		}
-
 		switch hdr.Name {
 		case "metadata.json":
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
 			if err = arc.loadMetadataJSON(data); err != nil {
 				return nil, err
 			}
 			continue
 		case "data":
-			arc.Data = data
+			if arc.Data, err = io.ReadAll(r); err != nil {
+				return nil, err
+			}
 			continue
 		}
 
@@ -166,7 +168,9 @@ func ReadArchive(in io.Reader) (*Archive, error) {
 		case "https", "file":
 			fileSystem := arc.getFs(pfx)
 			name = filepath.FromSlash(name)
-			if err = fsext.WriteFile(fileSystem, name, data, fs.FileMode(hdr.Mode)); err != nil {
+			// the content is copied straight from the archive, the assets can
+			// be big enough for an intermediate copy to matter
+			if err = writeFileFrom(fileSystem, name, r, fs.FileMode(hdr.Mode)); err != nil {
 				return nil, err
 			}
 			if err = fileSystem.Chtimes(name, hdr.AccessTime, hdr.ModTime); err != nil {
@@ -281,7 +285,7 @@ func (arc *Archive) Write(out io.Writer) error {
 		foundDirs := make(map[string]bool)
 		paths := make([]string, 0, 10)
 		infos := make(map[string]fs.FileInfo) // ... fix this ?
-		files := make(map[string][]byte)
+		files := make(map[string]string)
 
 		walkFunc := filepath.WalkFunc(func(filePath string, info fs.FileInfo, err error) error {
 			if err != nil {
@@ -295,9 +299,11 @@ func (arc *Archive) Write(out io.Writer) error {
 				return nil
 			}
 
+			// the content of the files is only read as they are written, for
+			// the archives with big assets not to be loaded in memory at once
 			paths = append(paths, normalizedPath)
-			files[normalizedPath], err = fsext.ReadFile(filesystem, filePath)
-			return err
+			files[normalizedPath] = filePath
+			return nil
 		})
 
 		if err = fsext.Walk(filesystem, fsext.FilePathSeparator, walkFunc); err != nil {
@@ -339,14 +345,14 @@ func (arc *Archive) Write(out io.Writer) error {
 				err = w.WriteHeader(&tar.Header{
 					Name:       fullFilePath,
 					Mode:       0o644, // MemMapFs is buggy
-					Size:       int64(len(files[filePath])),
+					Size:       infos[filePath].Size(),
 					AccessTime: infos[filePath].ModTime(),
 					ChangeTime: infos[filePath].ModTime(),
 					ModTime:    infos[filePath].ModTime(),
 					Typeflag:   tar.TypeReg,
 				})
 				if err == nil {
-					_, err = w.Write(files[filePath])
+					err = copyFile(w, filesystem, files[filePath])
 				}
 			}
 			if err != nil {
@@ -362,6 +368,33 @@ func (arc *Archive) Write(out io.Writer) error {
 	return w.Close()
 }
 
+// writeFileFrom writes the content read from the reader to the file, which is
+// created if it doesn't exist.
+func writeFileFrom(filesystem fsext.Fs, name string, r io.Reader, mode fs.FileMode) error {
+	f, err := filesystem.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// copyFile writes the content of the file to the writer.
+func copyFile(w io.Writer, filesystem fsext.Fs, name string) error {
+	f, err := filesystem.Open(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 func (arc *Archive) json() ([]byte, error) {
 	buffer := &bytes.Buffer{}
 	encoder := json.NewEncoder(buffer)
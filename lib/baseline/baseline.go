@@ -0,0 +1,247 @@
+package baseline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.k6.io/k6/metrics"
+)
+
+// DefaultTolerance is the tolerance of the comparisons which don't have one of
+// their own, i.e. 10% of the baseline value.
+const DefaultTolerance = 0.1
+
+// Baseline holds the values of the metrics of a previous test run.
+type Baseline struct {
+	// Metrics holds the values of the metrics, and sub-metrics, indexed by
+	// their names and then by their stats, e.g. avg or p(95).
+	Metrics map[string]map[string]float64
+}
+
+// Load loads a baseline from an end-of-test summary, either exported with
+// --summary-export or the data passed to handleSummary() encoded in JSON.
+func Load(data []byte) (*Baseline, error) {
+	var summary struct {
+		Metrics map[string]json.RawMessage `json:"metrics"`
+	}
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("the baseline isn't a valid JSON summary: %w", err)
+	}
+	if len(summary.Metrics) == 0 {
+		return nil, errors.New("the baseline summary doesn't have any metrics")
+	}
+
+	b := &Baseline{Metrics: make(map[string]map[string]float64, len(summary.Metrics))}
+	for name, raw := range summary.Metrics {
+		// The values of the metrics are wrapped in the handleSummary() data,
+		// while they're all at the top level of the exported summaries.
+		var metric struct {
+			Values map[string]interface{} `json:"values"`
+		}
+		if err := json.Unmarshal(raw, &metric); err != nil || metric.Values == nil {
+			if err = json.Unmarshal(raw, &metric.Values); err != nil {
+				return nil, fmt.Errorf("invalid values of the metric %s in the baseline: %w", name, err)
+			}
+		}
+
+		values := make(map[string]float64, len(metric.Values))
+		for stat, value := range metric.Values {
+			if v, ok := value.(float64); ok {
+				values[stat] = v
+			}
+		}
+		b.Metrics[name] = values
+	}
+
+	return b, nil
+}
+
+// Tolerances are the maximum relative changes of the metrics' stats, compared
+// to their baseline values, which aren't considered regressions.
+type Tolerances struct {
+	// Default is the tolerance of the stats without one of their own.
+	Default float64
+	// Overrides hold the tolerances of all the stats of some metrics, indexed
+	// by their names, or of some of their stats, indexed by metric:stat.
+	Overrides map[string]float64
+}
+
+// ParseTolerances parses tolerances of the form 10%, which is the default one,
+// http_req_duration=20%, for all the stats of a metric, or
+// http_req_duration:p(95)=5%, for one of them.
+func ParseTolerances(specs []string) (Tolerances, error) {
+	tolerances := Tolerances{Default: DefaultTolerance, Overrides: make(map[string]float64)}
+	for _, spec := range specs {
+		key, value := "", spec
+		if i := strings.LastIndexByte(spec, '='); i >= 0 {
+			key, value = strings.TrimSpace(spec[:i]), spec[i+1:]
+		}
+
+		tolerance, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+		if err != nil || tolerance < 0 {
+			return Tolerances{}, fmt.Errorf("invalid baseline tolerance %q, it should be a positive percentage, e.g. 10%%", spec)
+		}
+		tolerance /= 100
+
+		if key == "" {
+			tolerances.Default = tolerance
+		} else {
+			tolerances.Overrides[key] = tolerance
+		}
+	}
+
+	return tolerances, nil
+}
+
+// get returns the tolerance of the metric's stat.
+func (t Tolerances) get(metric, stat string) float64 {
+	if tolerance, ok := t.Overrides[metric+":"+stat]; ok {
+		return tolerance
+	}
+	if tolerance, ok := t.Overrides[metric]; ok {
+		return tolerance
+	}
+	return t.Default
+}
+
+// Comparison is the comparison of a metric's stat with its baseline value.
+type Comparison struct {
+	Metric string
+	Stat   string
+
+	Baseline float64
+	Current  float64
+	// Change is the relative change of the value, compared to the baseline
+	// one, e.g. 0.2 for an increase of 20%, or +Inf for an increase from 0.
+	Change    float64
+	Tolerance float64
+	// Regression is whether the value got worse by more than the tolerance.
+	Regression bool
+}
+
+// Compare compares the stats of the observed metrics, which have a baseline
+// value, with it, in the order of the metrics' names and stats.
+//
+// The compared stats are the ones for which a higher value is worse: the
+// averages, medians and percentiles of the Trend metrics and the rates of
+// the Rate metrics, with the exception of the checks' rate, for which a lower
+// value is worse. Counters and Gauges can't be compared without knowing what
+// they count or measure.
+func (b *Baseline) Compare(observed map[string]*metrics.Metric, tolerances Tolerances) []Comparison {
+	names := make([]string, 0, len(observed))
+	for name := range observed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var comparisons []Comparison
+	for _, name := range names {
+		values, ok := b.Metrics[name]
+		if !ok {
+			continue
+		}
+		m := observed[name]
+		for _, stat := range comparedStats(m.Type, values) {
+			current, ok := currentValue(m.Sink, stat)
+			if !ok {
+				continue
+			}
+
+			// The exported summaries have the rates of the Rate metrics as values.
+			baselineValue, ok := values[stat]
+			if !ok {
+				baselineValue = values["value"]
+			}
+
+			c := Comparison{
+				Metric:    name,
+				Stat:      stat,
+				Baseline:  baselineValue,
+				Current:   current,
+				Tolerance: tolerances.get(name, stat),
+			}
+			worsening := current - baselineValue
+			if isHigherBetter(name) {
+				worsening = -worsening
+			}
+			switch {
+			case baselineValue != 0:
+				c.Change = (current - baselineValue) / math.Abs(baselineValue)
+				c.Regression = worsening/math.Abs(baselineValue) > c.Tolerance
+			case current != 0:
+				c.Change = math.Inf(1)
+				c.Regression = worsening > 0
+			}
+			comparisons = append(comparisons, c)
+		}
+	}
+
+	return comparisons
+}
+
+// Regressions returns the comparisons which are regressions.
+func Regressions(comparisons []Comparison) []Comparison {
+	var regressions []Comparison
+	for _, c := range comparisons {
+		if c.Regression {
+			regressions = append(regressions, c)
+		}
+	}
+	return regressions
+}
+
+// comparedStats returns the stats of a metric of the given type which are
+// compared, among the ones with a baseline value, in a stable order.
+func comparedStats(mt metrics.MetricType, values map[string]float64) []string {
+	switch mt {
+	case metrics.Trend:
+		var stats []string
+		for stat := range values {
+			if stat == "avg" || stat == "med" || strings.HasPrefix(stat, "p(") {
+				stats = append(stats, stat)
+			}
+		}
+		sort.Strings(stats)
+		return stats
+	case metrics.Rate:
+		_, hasRate := values["rate"]
+		_, hasValue := values["value"]
+		if hasRate || hasValue {
+			return []string{"rate"}
+		}
+	default:
+	}
+	return nil
+}
+
+// isHigherBetter returns whether a higher value of the metric is better,
+// which is only the case of the checks among the compared metrics.
+func isHigherBetter(name string) bool {
+	parsedName, _, err := metrics.ParseMetricName(name)
+	return err == nil && parsedName == metrics.ChecksName
+}
+
+// currentValue returns the value of the sink's stat, if it has one.
+func currentValue(sink metrics.Sink, stat string) (float64, bool) {
+	if sink.IsEmpty() {
+		return 0, false
+	}
+
+	switch sinkImpl := sink.(type) {
+	case *metrics.TrendSink:
+		resolvers, err := metrics.GetResolversForTrendColumns([]string{stat})
+		if err != nil {
+			return 0, false
+		}
+		return resolvers[stat](sinkImpl), true
+	case *metrics.RateSink:
+		return float64(sinkImpl.Trues) / float64(sinkImpl.Total), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,107 @@
+package baseline
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/metrics"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	t.Run("SummaryExport", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := Load([]byte(`{"metrics": {
+			"http_req_duration": {"avg": 100, "p(95)": 200, "thresholds": {"p(95)<300": false}},
+			"http_req_failed": {"passes": 1, "fails": 9, "value": 0.1}
+		}}`))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]map[string]float64{
+			"http_req_duration": {"avg": 100, "p(95)": 200},
+			"http_req_failed":   {"passes": 1, "fails": 9, "value": 0.1},
+		}, b.Metrics)
+	})
+
+	t.Run("HandleSummaryData", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := Load([]byte(`{"metrics": {"checks": {"type": "rate", "values": {"rate": 0.9}}}}`))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]map[string]float64{"checks": {"rate": 0.9}}, b.Metrics)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Load([]byte(`not json`))
+		require.ErrorContains(t, err, "isn't a valid JSON summary")
+
+		_, err = Load([]byte(`{"root_group": {}}`))
+		require.ErrorContains(t, err, "doesn't have any metrics")
+	})
+}
+
+func TestParseTolerances(t *testing.T) {
+	t.Parallel()
+
+	tolerances, err := ParseTolerances([]string{"5%", "http_req_duration=20", "http_req_duration{status:200}:p(95)=2.5%"})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.05, tolerances.Default, 1e-9)
+	assert.InDelta(t, 0.2, tolerances.get("http_req_duration", "avg"), 1e-9)
+	assert.InDelta(t, 0.025, tolerances.get("http_req_duration{status:200}", "p(95)"), 1e-9)
+	assert.InDelta(t, 0.05, tolerances.get("http_req_duration{status:200}", "avg"), 1e-9)
+
+	tolerances, err = ParseTolerances(nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTolerance, tolerances.Default)
+
+	_, err = ParseTolerances([]string{"http_req_duration=-5%"})
+	require.ErrorContains(t, err, "invalid baseline tolerance")
+	_, err = ParseTolerances([]string{"ten percent"})
+	require.ErrorContains(t, err, "invalid baseline tolerance")
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	newMetric := func(name string, mt metrics.MetricType, values ...float64) *metrics.Metric {
+		m, err := registry.NewMetric(name, mt)
+		require.NoError(t, err)
+		for _, v := range values {
+			m.Sink.Add(metrics.Sample{Value: v})
+		}
+		return m
+	}
+	observed := map[string]*metrics.Metric{
+		"duration": newMetric("duration", metrics.Trend, 100, 150, 200),
+		"failed":   newMetric("failed", metrics.Rate, 1, 0, 0, 0),
+		"checks":   newMetric("checks", metrics.Rate, 1, 1, 1, 0),
+		"reqs":     newMetric("reqs", metrics.Counter, 1, 1),
+		"new":      newMetric("new", metrics.Trend, 1),
+	}
+
+	b := &Baseline{Metrics: map[string]map[string]float64{
+		"duration": {"avg": 140, "med": 150, "min": 1, "max": 1000, "p(95)": 195},
+		"failed":   {"value": 0},
+		"checks":   {"rate": 1},
+		"reqs":     {"count": 100},
+		"removed":  {"avg": 1},
+	}}
+	comparisons := b.Compare(observed, Tolerances{Default: 0.1, Overrides: map[string]float64{"checks": 0.5}})
+
+	inf := math.Inf(1)
+	assert.Equal(t, []Comparison{
+		{Metric: "checks", Stat: "rate", Baseline: 1, Current: 0.75, Change: -0.25, Tolerance: 0.5},
+		{Metric: "duration", Stat: "avg", Baseline: 140, Current: 150, Change: 10.0 / 140, Tolerance: 0.1},
+		{Metric: "duration", Stat: "med", Baseline: 150, Current: 150, Tolerance: 0.1},
+		{Metric: "duration", Stat: "p(95)", Baseline: 195, Current: 195, Tolerance: 0.1},
+		{Metric: "failed", Stat: "rate", Baseline: 0, Current: 0.25, Change: inf, Tolerance: 0.1, Regression: true},
+	}, comparisons)
+	assert.Equal(t, []Comparison{comparisons[4]}, Regressions(comparisons))
+}
@@ -0,0 +1,4 @@
+// Package baseline compares the metrics of a test run against the ones of a
+// previous test run, i.e. its exported end-of-test summary, and detects the
+// regressions beyond configurable tolerances.
+package baseline
@@ -0,0 +1,181 @@
+package capture
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/lib/fsext"
+)
+
+// The defaults of the configuration.
+const (
+	DefaultPath         = "k6-failures"
+	DefaultMaxArtifacts = 100
+	DefaultMaxBodySize  = 10 * 1024
+)
+
+// Config is the configuration of the capture of the failures.
+type Config struct {
+	// Path is the path of the directory the artifacts are written to.
+	Path string
+	// MaxArtifacts is the number of artifacts written at most, the next
+	// failures aren't captured.
+	MaxArtifacts int
+	// MaxBodySize is the size at which the bodies are truncated, in bytes.
+	MaxBodySize int
+}
+
+// ParseConfig parses the configuration of the capture, of the form
+// [path=failures][,max=100][,body=10240].
+func ParseConfig(s string) (Config, error) {
+	c := Config{Path: DefaultPath, MaxArtifacts: DefaultMaxArtifacts, MaxBodySize: DefaultMaxBodySize}
+	for _, kv := range strings.Split(s, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(kv), "=")
+		var err error
+		switch k {
+		case "":
+		case "path":
+			c.Path = v
+		case "max":
+			c.MaxArtifacts, err = strconv.Atoi(v)
+		case "body":
+			c.MaxBodySize, err = strconv.Atoi(v)
+		default:
+			return Config{}, fmt.Errorf("unknown capture option %q, the options are path, max and body", k)
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid capture option %s: %w", k, err)
+		}
+	}
+
+	switch {
+	case c.Path == "":
+		return Config{}, errors.New("the path of the captured failures is required")
+	case c.MaxArtifacts < 1:
+		return Config{}, fmt.Errorf("the max number of captured failures should be at least 1, but it is %d",
+			c.MaxArtifacts)
+	case c.MaxBodySize < 0:
+		return Config{}, fmt.Errorf("the max size of the captured bodies can't be negative, but it is %d",
+			c.MaxBodySize)
+	}
+	return c, nil
+}
+
+// Request is a captured request.
+type Request struct {
+	Method        string              `json:"method"`
+	URL           string              `json:"url"`
+	Headers       map[string][]string `json:"headers"`
+	Body          string              `json:"body"`
+	BodyTruncated bool                `json:"bodyTruncated,omitempty"`
+}
+
+// Response is a captured response.
+type Response struct {
+	Status        int               `json:"status"`
+	Headers       map[string]string `json:"headers"`
+	Body          string            `json:"body"`
+	BodyTruncated bool              `json:"bodyTruncated,omitempty"`
+	// Error is the error of the request, if it failed without a response.
+	Error string `json:"error,omitempty"`
+}
+
+// Exchange is a captured request and its response.
+type Exchange struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Capturable is implemented by the responses whose exchanges can be captured,
+// e.g. when they are the values of checks.
+type Capturable interface {
+	CaptureExchange() Exchange
+}
+
+// Artifact is a captured failure, which is written as a JSON file.
+type Artifact struct {
+	Time time.Time `json:"time"`
+	// Reason is why the exchange was captured, e.g. its unexpected status.
+	Reason string `json:"reason"`
+	// Checks are the names of the checks which failed, if any did.
+	Checks    []string `json:"checks,omitempty"`
+	VU        uint64   `json:"vu"`
+	Iteration int64    `json:"iteration"`
+	Scenario  string   `json:"scenario,omitempty"`
+
+	Exchange
+}
+
+// Recorder writes the artifacts of the failures to the directory of its
+// configuration, until the max number of them is reached. It's shared by all
+// VUs.
+type Recorder struct {
+	fs     fsext.Fs
+	config Config
+	logger logrus.FieldLogger
+
+	mu      sync.Mutex
+	written int
+	dirErr  error
+}
+
+// NewRecorder returns a recorder writing the artifacts to the directory of the
+// given configuration, which is created with the first artifact.
+func NewRecorder(fs fsext.Fs, config Config, logger logrus.FieldLogger) *Recorder {
+	return &Recorder{fs: fs, config: config, logger: logger.WithField("source", "capture")}
+}
+
+// Record writes the artifact, with its bodies truncated, unless the max
+// number of artifacts was already written.
+func (r *Recorder) Record(artifact Artifact) {
+	r.mu.Lock()
+	n := r.written
+	if n >= r.config.MaxArtifacts || r.dirErr != nil {
+		r.mu.Unlock()
+		return
+	}
+	if n == 0 {
+		if err := r.fs.MkdirAll(r.config.Path, 0o750); err != nil {
+			r.dirErr = err
+			r.mu.Unlock()
+			r.logger.WithError(err).Warn("Couldn't create the directory of the captured failures")
+			return
+		}
+	}
+	r.written++
+	r.mu.Unlock()
+
+	artifact.Request.Body, artifact.Request.BodyTruncated = r.truncate(artifact.Request.Body)
+	artifact.Response.Body, artifact.Response.BodyTruncated = r.truncate(artifact.Response.Body)
+	data, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		r.logger.WithError(err).Warn("Couldn't encode a captured failure")
+		return
+	}
+
+	name := fmt.Sprintf("%06d-vu%d-iter%d.json", n+1, artifact.VU, artifact.Iteration)
+	path := filepath.Join(r.config.Path, name)
+	if err := fsext.WriteFile(r.fs, path, data, 0o644); err != nil {
+		r.logger.WithError(err).Warn("Couldn't write a captured failure")
+		return
+	}
+	if n+1 == r.config.MaxArtifacts {
+		r.logger.Warnf("The max number of %d captured failures was reached in %s, the next ones won't be captured",
+			r.config.MaxArtifacts, r.config.Path)
+	}
+}
+
+func (r *Recorder) truncate(body string) (string, bool) {
+	if len(body) <= r.config.MaxBodySize {
+		return body, false
+	}
+	return body[:r.config.MaxBodySize], true
+}
@@ -0,0 +1,90 @@
+package capture
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/testutils"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		config Config
+		err    string
+	}{
+		"":                            {config: Config{Path: DefaultPath, MaxArtifacts: DefaultMaxArtifacts, MaxBodySize: DefaultMaxBodySize}},
+		"path=failures,max=10,body=0": {config: Config{Path: "failures", MaxArtifacts: 10, MaxBodySize: 0}},
+		"max=1":                       {config: Config{Path: DefaultPath, MaxArtifacts: 1, MaxBodySize: DefaultMaxBodySize}},
+		"dir=failures":                {err: `unknown capture option "dir", the options are path, max and body`},
+		"max=ten":                     {err: "invalid capture option max"},
+		"max=0":                       {err: "the max number of captured failures should be at least 1, but it is 0"},
+		"body=-1":                     {err: "the max size of the captured bodies can't be negative, but it is -1"},
+		"path=":                       {err: "the path of the captured failures is required"},
+	}
+
+	for s, tc := range cases {
+		s, tc := s, tc
+		t.Run(s, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := ParseConfig(s)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.config, config)
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.New()
+	logger.SetOutput(testutils.NewTestOutput(t))
+	hook := &testutils.SimpleLogrusHook{HookedLevels: logrus.AllLevels}
+	logger.AddHook(hook)
+
+	fs := fsext.NewMemMapFs()
+	recorder := NewRecorder(fs, Config{Path: "/failures", MaxArtifacts: 2, MaxBodySize: 4}, logger)
+	for i := 0; i < 3; i++ {
+		recorder.Record(Artifact{
+			Reason:    "the status 500 isn't an expected one",
+			VU:        1,
+			Iteration: int64(i),
+			Exchange: Exchange{
+				Request:  Request{Method: "POST", URL: "https://example.com", Body: "abc"},
+				Response: Response{Status: 500, Headers: map[string]string{"A": "b"}, Body: "Internal Server Error"},
+			},
+		})
+	}
+
+	entries, err := fsext.ReadDir(fs, "/failures")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "000001-vu1-iter0.json", entries[0].Name())
+	assert.Equal(t, "000002-vu1-iter1.json", entries[1].Name())
+
+	data, err := fsext.ReadFile(fs, "/failures/000002-vu1-iter1.json")
+	require.NoError(t, err)
+	var artifact Artifact
+	require.NoError(t, json.Unmarshal(data, &artifact))
+	assert.Equal(t, int64(1), artifact.Iteration)
+	assert.Equal(t, Request{Method: "POST", URL: "https://example.com", Body: "abc"}, artifact.Request)
+	assert.Equal(t, Response{
+		Status: 500, Headers: map[string]string{"A": "b"}, Body: "Inte", BodyTruncated: true,
+	}, artifact.Response)
+
+	lines := hook.Lines()
+	require.Len(t, lines, 1)
+	assert.True(t, strings.HasPrefix(lines[0], "The max number of 2 captured failures was reached"), lines[0])
+}
@@ -0,0 +1,4 @@
+// Package capture records the requests and the responses which failed, because
+// of their status or of the checks of the responses, as JSON artifacts in a
+// local directory, whose number and body sizes are bounded.
+package capture
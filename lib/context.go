@@ -9,6 +9,7 @@ type ctxKey int
 const (
 	ctxKeyExecState ctxKey = iota
 	ctxKeyScenario
+	ctxKeyScenarioSetupData
 )
 
 // WithExecutionState embeds an ExecutionState in ctx.
@@ -39,3 +40,20 @@ func GetScenarioState(ctx context.Context) *ScenarioState {
 	}
 	return v.(*ScenarioState) //nolint:forcetypeassert
 }
+
+// WithScenarioSetupData embeds in ctx the JSON representation of the data
+// returned by the setup function of the scenario run with it.
+func WithScenarioSetupData(ctx context.Context, data []byte) context.Context {
+	return context.WithValue(ctx, ctxKeyScenarioSetupData, data)
+}
+
+// GetScenarioSetupData returns the JSON representation of the scenario setup
+// data from ctx, and whether the scenario has its own setup function. The data
+// is nil if the function returned undefined.
+func GetScenarioSetupData(ctx context.Context) ([]byte, bool) {
+	v := ctx.Value(ctxKeyScenarioSetupData)
+	if v == nil {
+		return nil, false
+	}
+	return v.([]byte), true //nolint:forcetypeassert
+}
@@ -0,0 +1,137 @@
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the format of a converted document.
+type Format string
+
+const (
+	// Postman is the format of the Postman collections v2.1.
+	Postman Format = "postman"
+	// OpenAPI is the format of the OpenAPI 3.x documents.
+	OpenAPI Format = "openapi"
+)
+
+// Options configures the conversions.
+type Options struct {
+	// BaseURL replaces the URL of the server of an OpenAPI document.
+	BaseURL string
+}
+
+// Convert generates a k6 script from the document, whose format is detected.
+func Convert(data []byte, opts Options) ([]byte, error) {
+	format, err := DetectFormat(data)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case Postman:
+		return ConvertPostman(data)
+	default:
+		return ConvertOpenAPI(data, opts)
+	}
+}
+
+// DetectFormat detects the format of the document, from the schema of a
+// Postman collection or the version of an OpenAPI document.
+func DetectFormat(data []byte) (Format, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("the document is neither JSON nor YAML: %w", err)
+	}
+
+	if version, ok := doc["openapi"].(string); ok {
+		if !strings.HasPrefix(version, "3.") {
+			return "", fmt.Errorf("unsupported OpenAPI version %q, only 3.x documents are supported", version)
+		}
+		return OpenAPI, nil
+	}
+	if _, ok := doc["swagger"]; ok {
+		return "", errors.New("swagger 2.0 documents aren't supported, convert them to OpenAPI 3.x first")
+	}
+
+	if info, ok := doc["info"].(map[string]interface{}); ok {
+		if schema, ok := info["schema"].(string); ok && strings.Contains(schema, "getpostman.com") {
+			if !strings.Contains(schema, "v2.1") {
+				return "", fmt.Errorf("unsupported Postman collection schema %q, only v2.1 collections are supported", schema)
+			}
+			return Postman, nil
+		}
+	}
+
+	return "", errors.New("the document is neither a Postman collection nor an OpenAPI document")
+}
+
+// quote returns the string as a JavaScript string literal.
+func quote(s string) string {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// escapeTemplate escapes the string to be a part of a JavaScript template literal.
+func escapeTemplate(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${").Replace(s)
+}
+
+// indentJSON returns the value as indented JSON, whose lines but the first
+// one are prefixed with the indent.
+func indentJSON(value interface{}, indent string) string {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(indent, "  ")
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// header is a header of a generated request, whose value is a JavaScript
+// expression.
+type header struct {
+	name, value string
+}
+
+// request is a request of a generated script, whose fields are JavaScript
+// expressions.
+type request struct {
+	method  string
+	url     string
+	body    string
+	headers []header
+	// check is the check of the response.
+	check string
+}
+
+// write writes the statements making the request and checking its response,
+// prefixed with the indent.
+func (r request) write(b *strings.Builder, indent, assign string) {
+	body := r.body
+	if body == "" {
+		body = "null"
+	}
+
+	fmt.Fprintf(b, "%s%s http.request(%s, %s, %s", indent, assign, quote(r.method), r.url, body)
+	if len(r.headers) > 0 {
+		fmt.Fprintf(b, ", {\n%s  headers: {\n", indent)
+		for _, h := range r.headers {
+			fmt.Fprintf(b, "%s    %s: %s,\n", indent, quote(h.name), h.value)
+		}
+		fmt.Fprintf(b, "%s  },\n%s}", indent, indent)
+	}
+	b.WriteString(");\n")
+
+	check := r.check
+	if check == "" {
+		check = `"status is 2xx": (r) => r.status >= 200 && r.status < 300`
+	}
+	fmt.Fprintf(b, "%scheck(res, { %s });\n", indent, check)
+}
@@ -0,0 +1,50 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name, doc string
+		format    Format
+		err       string
+	}{
+		{
+			name:   "Postman",
+			doc:    `{"info": {"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"}}`,
+			format: Postman,
+		},
+		{
+			name: "PostmanV2.0",
+			doc:  `{"info": {"schema": "https://schema.getpostman.com/json/collection/v2.0.0/collection.json"}}`,
+			err:  "only v2.1 collections are supported",
+		},
+		{name: "OpenAPIJSON", doc: `{"openapi": "3.0.3"}`, format: OpenAPI},
+		{name: "OpenAPIYAML", doc: "openapi: 3.1.0\ninfo:\n  title: API\n", format: OpenAPI},
+		{name: "OpenAPIV4", doc: `openapi: "4.0.0"`, err: `unsupported OpenAPI version "4.0.0"`},
+		{name: "Swagger", doc: `{"swagger": "2.0"}`, err: "swagger 2.0 documents aren't supported"},
+		{name: "Unknown", doc: `{"name": "test"}`, err: "neither a Postman collection nor an OpenAPI document"},
+		{name: "Invalid", doc: `{[`, err: "neither JSON nor YAML"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			format, err := DetectFormat([]byte(tc.doc))
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.format, format)
+		})
+	}
+}
@@ -0,0 +1,4 @@
+// Package converter generates k6 scripts from the definitions of APIs, i.e.
+// Postman collections and OpenAPI documents, as a starting point for testing
+// them.
+package converter
@@ -0,0 +1,594 @@
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxSchemaDepth is the maximum number of nested references of the schemas
+// whose examples are generated.
+const maxSchemaDepth = 8
+
+//nolint:gochecknoglobals
+var (
+	openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+	// reservedNames are the names the functions of the operations can't have,
+	// because they're either reserved by JavaScript or exported by the scripts.
+	reservedNames = map[string]bool{
+		"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+		"debugger": true, "default": true, "delete": true, "do": true, "else": true, "enum": true,
+		"export": true, "extends": true, "false": true, "finally": true, "for": true, "function": true,
+		"if": true, "import": true, "in": true, "instanceof": true, "new": true, "null": true,
+		"return": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+		"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+		"yield": true, "let": true, "static": true, "await": true,
+		"options": true, "setup": true, "teardown": true, "handleSummary": true,
+		"http": true, "check": true, "encoding": true, "baseUrl": true,
+	}
+
+	openAPIPathParamRegexp = regexp.MustCompile(`\{([^{}]+)\}`)
+)
+
+type openAPIConverter struct {
+	doc         map[string]interface{}
+	baseURL     string
+	names       map[string]bool
+	envVars     map[string]bool
+	usesEncoder bool
+}
+
+type openAPIOperation struct {
+	name, method, path, summary string
+	request                     request
+	comments                    []string
+}
+
+// ConvertOpenAPI generates a k6 script with an exported function, and a
+// scenario executing it once, for every operation of an OpenAPI 3.x document.
+// The requests are made with the examples of the parameters and bodies, or
+// with values generated from their schemas.
+func ConvertOpenAPI(data []byte, opts Options) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+
+	c := &openAPIConverter{
+		doc:     doc,
+		baseURL: opts.BaseURL,
+		names:   make(map[string]bool),
+		envVars: make(map[string]bool),
+	}
+	if c.baseURL == "" {
+		c.baseURL = c.serverURL()
+	}
+	c.baseURL = strings.TrimSuffix(c.baseURL, "/")
+
+	operations := c.operations()
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("the OpenAPI document doesn't have any operations")
+	}
+
+	var b strings.Builder
+	title, _ := mapValue(doc["info"])["title"].(string)
+	fmt.Fprintf(&b, "// This script was converted from the %s OpenAPI document by k6 convert.\n", quote(title))
+	if len(c.envVars) > 0 {
+		envVars := make([]string, 0, len(c.envVars))
+		for name := range c.envVars {
+			envVars = append(envVars, name)
+		}
+		sort.Strings(envVars)
+		fmt.Fprintf(&b, "// The credentials are read from the %s environment variables.\n", strings.Join(envVars, ", "))
+	}
+	b.WriteString("import http from \"k6/http\";\n")
+	if c.usesEncoder {
+		b.WriteString("import encoding from \"k6/encoding\";\n")
+	}
+	b.WriteString("import { check } from \"k6\";\n\n")
+	fmt.Fprintf(&b, "const baseUrl = __ENV.BASE_URL || %s;\n\n", quote(c.baseURL))
+
+	b.WriteString("export const options = {\n  scenarios: {\n")
+	for _, op := range operations {
+		fmt.Fprintf(&b, "    %s: { executor: \"shared-iterations\", vus: 1, iterations: 1, exec: %s },\n",
+			op.name, quote(op.name))
+	}
+	b.WriteString("  },\n};\n")
+
+	for _, op := range operations {
+		fmt.Fprintf(&b, "\n// %s %s", strings.ToUpper(op.method), op.path)
+		if op.summary != "" {
+			fmt.Fprintf(&b, ": %s", op.summary)
+		}
+		fmt.Fprintf(&b, "\nexport function %s() {\n", op.name)
+		for _, comment := range op.comments {
+			fmt.Fprintf(&b, "  // %s\n", comment)
+		}
+		op.request.write(&b, "  ", "const res =")
+		b.WriteString("}\n")
+	}
+
+	return []byte(b.String()), nil
+}
+
+// serverURL returns the URL of the first server, with its variables replaced
+// by their default values.
+func (c *openAPIConverter) serverURL() string {
+	servers, _ := c.doc["servers"].([]interface{})
+	if len(servers) == 0 {
+		return ""
+	}
+	server := mapValue(c.resolve(servers[0]))
+	serverURL, _ := server["url"].(string)
+	variables := mapValue(server["variables"])
+	return openAPIPathParamRegexp.ReplaceAllStringFunc(serverURL, func(s string) string {
+		if value, ok := mapValue(variables[s[1:len(s)-1]])["default"]; ok {
+			return fmt.Sprint(value)
+		}
+		return s
+	})
+}
+
+func (c *openAPIConverter) operations() []openAPIOperation {
+	paths := mapValue(c.doc["paths"])
+	sortedPaths := make([]string, 0, len(paths))
+	for path := range paths {
+		sortedPaths = append(sortedPaths, path)
+	}
+	sort.Strings(sortedPaths)
+
+	var operations []openAPIOperation
+	for _, path := range sortedPaths {
+		item := mapValue(c.resolve(paths[path]))
+		for _, method := range openAPIMethods {
+			op, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			operations = append(operations, c.operation(path, method, item, op))
+		}
+	}
+	return operations
+}
+
+func (c *openAPIConverter) operation(
+	path, method string, item, op map[string]interface{},
+) openAPIOperation {
+	operationID, _ := op["operationId"].(string)
+	if operationID == "" {
+		operationID = method + " " + openAPIPathParamRegexp.ReplaceAllString(path, "$1")
+	}
+	result := openAPIOperation{name: c.functionName(operationID), method: method, path: path}
+	result.summary, _ = op["summary"].(string)
+
+	// The parameters of the operation override the ones of its path.
+	var params []map[string]interface{}
+	indexes := make(map[string]int)
+	for _, list := range []interface{}{item["parameters"], op["parameters"]} {
+		values, _ := list.([]interface{})
+		for _, value := range values {
+			param := mapValue(c.resolve(value))
+			key := fmt.Sprint(param["in"], ":", param["name"])
+			if i, ok := indexes[key]; ok {
+				params[i] = param
+				continue
+			}
+			indexes[key] = len(params)
+			params = append(params, param)
+		}
+	}
+
+	pathValues := make(map[string]string)
+	query := url.Values{}
+	var headers []header
+	for _, param := range params {
+		name, _ := param["name"].(string)
+		value := fmt.Sprint(c.paramExample(param))
+		switch param["in"] {
+		case "path":
+			pathValues[name] = url.PathEscape(value)
+		case "query":
+			query.Add(name, value)
+		case "header":
+			headers = append(headers, header{name: name, value: quote(value)})
+		case "cookie":
+			result.comments = append(result.comments, fmt.Sprintf("The %s cookie parameter isn't converted.", quote(name)))
+		}
+	}
+
+	path = openAPIPathParamRegexp.ReplaceAllStringFunc(path, func(s string) string {
+		if value, ok := pathValues[s[1:len(s)-1]]; ok {
+			return value
+		}
+		return s
+	})
+	urlSuffix := escapeTemplate(path)
+	if len(query) > 0 {
+		urlSuffix += "?" + escapeTemplate(query.Encode())
+	}
+
+	r := request{method: strings.ToUpper(method), headers: headers}
+	authHeaders, authQuery := c.security(op)
+	r.headers = append(r.headers, authHeaders...)
+	for _, q := range authQuery {
+		separator := "&"
+		if !strings.Contains(urlSuffix, "?") {
+			separator = "?"
+		}
+		urlSuffix += separator + q
+	}
+	r.url = "`${baseUrl}" + urlSuffix + "`"
+
+	c.requestBody(&r, mapValue(c.resolve(op["requestBody"])))
+	r.check = c.responseCheck(mapValue(op["responses"]))
+	result.request = r
+	return result
+}
+
+// functionName returns a unique JavaScript identifier for the operation.
+func (c *openAPIConverter) functionName(operationID string) string {
+	words := strings.FieldsFunc(operationID, func(r rune) bool {
+		return r > unicode.MaxASCII || (!unicode.IsLetter(r) && !unicode.IsDigit(r))
+	})
+	var b strings.Builder
+	for i, word := range words {
+		if i == 0 && !unicode.IsDigit(rune(word[0])) {
+			b.WriteString(strings.ToLower(word[:1]) + word[1:])
+		} else {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "operation" + name
+	}
+	if reservedNames[name] {
+		name += "Operation"
+	}
+
+	unique := name
+	for i := 2; c.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	c.names[unique] = true
+	return unique
+}
+
+func (c *openAPIConverter) paramExample(param map[string]interface{}) interface{} {
+	if example, ok := param["example"]; ok {
+		return example
+	}
+	if example, ok := firstExample(mapValue(param["examples"])); ok {
+		return example
+	}
+	value := c.schemaExample(param["schema"], nil)
+	if values, ok := value.([]interface{}); ok {
+		// The arrays are serialized with the default form style.
+		parts := make([]string, len(values))
+		for i, v := range values {
+			parts[i] = fmt.Sprint(v)
+		}
+		return strings.Join(parts, ",")
+	}
+	if value == nil {
+		return ""
+	}
+	return value
+}
+
+// requestBody sets the body of the request, from the first JSON or form
+// content of the request body.
+func (c *openAPIConverter) requestBody(r *request, body map[string]interface{}) {
+	content := mapValue(body["content"])
+	if len(content) == 0 {
+		return
+	}
+
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Slice(mediaTypes, func(i, j int) bool {
+		ri, rj := mediaTypeRank(mediaTypes[i]), mediaTypeRank(mediaTypes[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return mediaTypes[i] < mediaTypes[j]
+	})
+	mediaType := mediaTypes[0]
+	media := mapValue(content[mediaType])
+
+	example, ok := media["example"]
+	if !ok {
+		example, ok = firstExample(mapValue(media["examples"]))
+	}
+	if !ok {
+		example = c.schemaExample(media["schema"], nil)
+	}
+
+	switch mediaTypeRank(mediaType) {
+	case 0:
+		r.body = "JSON.stringify(" + indentJSON(example, "  ") + ")"
+		r.headers = append(r.headers, header{name: "Content-Type", value: quote(mediaType)})
+	case 1, 2:
+		// The objects are sent as forms by k6, URL-encoded unless they contain files.
+		fields := mapValue(example)
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, name := range names {
+			fmt.Fprintf(&b, "    %s: %s,\n", quote(name), quote(fmt.Sprint(fields[name])))
+		}
+		b.WriteString("  }")
+		r.body = b.String()
+	default:
+		if s, ok := example.(string); ok {
+			r.body = quote(s)
+		} else {
+			r.body = quote(strings.TrimSpace(indentJSON(example, "")))
+		}
+		r.headers = append(r.headers, header{name: "Content-Type", value: quote(mediaType)})
+	}
+}
+
+// mediaTypeRank ranks the media types of the request bodies, from the
+// preferred JSON ones to the forms and the others.
+func mediaTypeRank(mediaType string) int {
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return 0
+	case mediaType == "application/x-www-form-urlencoded":
+		return 1
+	case mediaType == "multipart/form-data":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// responseCheck returns the check of the first successful response status of
+// the operation.
+func (c *openAPIConverter) responseCheck(responses map[string]interface{}) string {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		if len(code) == 3 && code[0] == '2' && code[1] != 'X' && code[1] != 'x' {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return ""
+	}
+	sort.Strings(codes)
+	return fmt.Sprintf(`"status is %s": (r) => r.status === %s`, codes[0], codes[0])
+}
+
+// security returns the headers and the query parameters which authenticate
+// the operation with the first of its security requirements, or the ones of
+// the document.
+func (c *openAPIConverter) security(op map[string]interface{}) ([]header, []string) {
+	requirements, ok := op["security"].([]interface{})
+	if !ok {
+		requirements, _ = c.doc["security"].([]interface{})
+	}
+	if len(requirements) == 0 {
+		return nil, nil
+	}
+	requirement := mapValue(requirements[0])
+	names := make([]string, 0, len(requirement))
+	for name := range requirement {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	schemes := mapValue(mapValue(c.doc["components"])["securitySchemes"])
+	var headers []header
+	var query []string
+	for _, name := range names {
+		scheme := mapValue(c.resolve(schemes[name]))
+		prefix := envVarName(name)
+		switch scheme["type"] {
+		case "http":
+			if strings.EqualFold(fmt.Sprint(scheme["scheme"]), "basic") {
+				c.usesEncoder = true
+				username, password := c.envVar(prefix+"_USERNAME"), c.envVar(prefix+"_PASSWORD")
+				headers = append(headers, header{
+					name:  "Authorization",
+					value: fmt.Sprintf(`"Basic " + encoding.b64encode(%s + ":" + %s)`, username, password),
+				})
+				continue
+			}
+			headers = append(headers, header{name: "Authorization", value: `"Bearer " + ` + c.envVar(prefix+"_TOKEN")})
+		case "oauth2", "openIdConnect":
+			headers = append(headers, header{name: "Authorization", value: `"Bearer " + ` + c.envVar(prefix+"_TOKEN")})
+		case "apiKey":
+			keyName, _ := scheme["name"].(string)
+			key := c.envVar(prefix + "_API_KEY")
+			switch scheme["in"] {
+			case "query":
+				query = append(query, escapeTemplate(url.QueryEscape(keyName))+"=${encodeURIComponent("+key+")}")
+			case "cookie":
+				headers = append(headers, header{name: "Cookie", value: quote(keyName+"=") + " + " + key})
+			default:
+				headers = append(headers, header{name: keyName, value: key})
+			}
+		}
+	}
+	return headers, query
+}
+
+func (c *openAPIConverter) envVar(name string) string {
+	c.envVars[name] = true
+	return "__ENV." + name
+}
+
+// envVarName returns the name of a security scheme as the prefix of the
+// environment variables of its credentials.
+func envVarName(name string) string {
+	var b strings.Builder
+	prev := rune(0)
+	for _, r := range name {
+		switch {
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			b.WriteRune('_')
+			b.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToUpper(r))
+		default:
+			r = '_'
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	s := b.String()
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "AUTH_" + s
+	}
+	return s
+}
+
+// schemaExample returns an example value of the schema, either its own one or
+// one generated from its type. The properties of the objects whose schemas
+// reference the schemas they're in are left out.
+func (c *openAPIConverter) schemaExample(value interface{}, refs []string) interface{} {
+	if ref, ok := mapValue(value)["$ref"].(string); ok {
+		for _, r := range refs {
+			if r == ref {
+				return nil
+			}
+		}
+		refs = append(refs[:len(refs):len(refs)], ref)
+	}
+	schema := mapValue(c.resolve(value))
+	if schema == nil || len(refs) > maxSchemaDepth {
+		return nil
+	}
+
+	for _, key := range []string{"example", "default", "const"} {
+		if example, ok := schema[key]; ok {
+			return example
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		merged := map[string]interface{}{}
+		for _, s := range allOf {
+			for k, v := range mapValue(c.schemaExample(s, refs)) {
+				merged[k] = v
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if schemas, ok := schema[key].([]interface{}); ok && len(schemas) > 0 {
+			return c.schemaExample(schemas[0], refs)
+		}
+	}
+
+	schemaType := schema["type"]
+	if types, ok := schemaType.([]interface{}); ok && len(types) > 0 {
+		schemaType = types[0]
+	}
+	if schemaType == nil {
+		if _, ok := schema["properties"]; ok {
+			schemaType = "object"
+		} else if _, ok := schema["items"]; ok {
+			schemaType = "array"
+		}
+	}
+
+	switch schemaType {
+	case "object":
+		object := map[string]interface{}{}
+		for name, property := range mapValue(schema["properties"]) {
+			if example := c.schemaExample(property, refs); example != nil {
+				object[name] = example
+			}
+		}
+		return object
+	case "array":
+		if item := c.schemaExample(schema["items"], refs); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "integer", "number":
+		if minimum, ok := schema["minimum"]; ok {
+			return minimum
+		}
+		return 0
+	case "boolean":
+		return true
+	case "string":
+		return stringExample(fmt.Sprint(schema["format"]))
+	default:
+		return nil
+	}
+}
+
+func stringExample(format string) string {
+	switch format {
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-4000-8000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	case "ipv4":
+		return "192.0.2.1"
+	default:
+		return "string"
+	}
+}
+
+// resolve returns the value the local reference points to, or the value
+// itself when it's not a reference.
+func (c *openAPIConverter) resolve(value interface{}) interface{} {
+	for i := 0; i < maxSchemaDepth; i++ {
+		ref, ok := mapValue(value)["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return value
+		}
+		var target interface{} = c.doc
+		for _, part := range strings.Split(ref[2:], "/") {
+			part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+			target = mapValue(target)[part]
+		}
+		value = target
+	}
+	return value
+}
+
+// firstExample returns the value of the first of the named examples.
+func firstExample(examples map[string]interface{}) (interface{}, bool) {
+	names := make([]string, 0, len(examples))
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if value, ok := mapValue(examples[name])["value"]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func mapValue(value interface{}) map[string]interface{} {
+	m, _ := value.(map[string]interface{})
+	return m
+}
@@ -0,0 +1,167 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOpenAPIDocument = `
+openapi: 3.0.3
+info:
+  title: Petstore
+servers:
+  - url: https://{region}.petstore.k6.local/v1/
+    variables:
+      region:
+        default: eu
+security:
+  - petstoreAuth: []
+paths:
+  /pets:
+    get:
+      operationId: list-pets
+      summary: List the pets
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            default: 10
+        - name: X-Request-ID
+          in: header
+          example: abc
+      responses:
+        "200":
+          description: The pets
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Pet"
+      responses:
+        "201":
+          description: Created
+  /pets/{petId}:
+    parameters:
+      - $ref: "#/components/parameters/PetID"
+    get:
+      security:
+        - apiKey: []
+      responses:
+        default:
+          description: The pet
+    delete:
+      operationId: delete
+      security: []
+      responses:
+        "204":
+          description: Deleted
+components:
+  parameters:
+    PetID:
+      name: petId
+      in: path
+      required: true
+      schema:
+        type: string
+        format: uuid
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Rex
+        tags:
+          type: array
+          items:
+            type: string
+        owner:
+          $ref: "#/components/schemas/Pet"
+  securitySchemes:
+    petstoreAuth:
+      type: http
+      scheme: bearer
+    apiKey:
+      type: apiKey
+      in: query
+      name: api_key
+`
+
+func TestConvertOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	script, err := Convert([]byte(testOpenAPIDocument), Options{})
+	require.NoError(t, err)
+	_, err = sobek.ParseModule("script.js", string(script), nil)
+	require.NoError(t, err, string(script))
+
+	assert.Contains(t, string(script),
+		"// The credentials are read from the API_KEY_API_KEY, PETSTORE_AUTH_TOKEN environment variables.\n")
+	assert.Contains(t, string(script), `const baseUrl = __ENV.BASE_URL || "https://eu.petstore.k6.local/v1";`)
+	assert.Contains(t, string(script), `
+  scenarios: {
+    listPets: { executor: "shared-iterations", vus: 1, iterations: 1, exec: "listPets" },
+    createPet: { executor: "shared-iterations", vus: 1, iterations: 1, exec: "createPet" },
+    getPetsPetId: { executor: "shared-iterations", vus: 1, iterations: 1, exec: "getPetsPetId" },
+    deleteOperation: { executor: "shared-iterations", vus: 1, iterations: 1, exec: "deleteOperation" },
+  },
+`)
+	assert.Contains(t, string(script), `
+// GET /pets: List the pets
+export function listPets() {
+  const res = http.request("GET", `+"`${baseUrl}/pets?limit=10`"+`, null, {
+    headers: {
+      "X-Request-ID": "abc",
+      "Authorization": "Bearer " + __ENV.PETSTORE_AUTH_TOKEN,
+    },
+  });
+  check(res, { "status is 200": (r) => r.status === 200 });
+}
+`)
+	assert.Contains(t, string(script), `
+export function createPet() {
+  const res = http.request("POST", `+"`${baseUrl}/pets`"+`, JSON.stringify({
+    "name": "Rex",
+    "tags": [
+      "string"
+    ]
+  }), {`)
+	assert.Contains(t, string(script), `
+      "Content-Type": "application/json",
+    },
+  });
+  check(res, { "status is 201": (r) => r.status === 201 });
+`)
+	assert.Contains(t, string(script), `
+export function getPetsPetId() {
+  const res = http.request("GET", `+
+		"`${baseUrl}/pets/00000000-0000-4000-8000-000000000000?api_key=${encodeURIComponent(__ENV.API_KEY_API_KEY)}`"+
+		`, null);
+  check(res, { "status is 2xx": (r) => r.status >= 200 && r.status < 300 });
+`)
+	assert.Contains(t, string(script), `
+export function deleteOperation() {
+  const res = http.request("DELETE", `+"`${baseUrl}/pets/00000000-0000-4000-8000-000000000000`"+`, null);
+`)
+}
+
+func TestConvertOpenAPIBaseURL(t *testing.T) {
+	t.Parallel()
+
+	script, err := ConvertOpenAPI([]byte(testOpenAPIDocument), Options{BaseURL: "http://localhost:8080/"})
+	require.NoError(t, err)
+	assert.Contains(t, string(script), `const baseUrl = __ENV.BASE_URL || "http://localhost:8080";`)
+}
+
+func TestConvertOpenAPIWithoutOperations(t *testing.T) {
+	t.Parallel()
+
+	_, err := ConvertOpenAPI([]byte("openapi: 3.0.0\npaths: {}\n"), Options{})
+	require.ErrorContains(t, err, "doesn't have any operations")
+}
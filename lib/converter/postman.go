@@ -0,0 +1,333 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The types of the Postman collections v2.1, as documented in
+// https://schema.postman.com/collection/json/v2.1.0/draft-07/docs/index.html
+type (
+	postmanCollection struct {
+		Info struct {
+			Name string `json:"name"`
+		} `json:"info"`
+		Item     []postmanItem     `json:"item"`
+		Variable []postmanKeyValue `json:"variable"`
+		Auth     *postmanAuth      `json:"auth"`
+	}
+
+	// postmanItem is either a folder of items or a request.
+	postmanItem struct {
+		Name    string          `json:"name"`
+		Item    []postmanItem   `json:"item"`
+		Request *postmanRequest `json:"request"`
+		Auth    *postmanAuth    `json:"auth"`
+	}
+
+	postmanRequest struct {
+		Method string            `json:"method"`
+		Header []postmanKeyValue `json:"header"`
+		URL    postmanURL        `json:"url"`
+		Body   *postmanBody      `json:"body"`
+		Auth   *postmanAuth      `json:"auth"`
+	}
+
+	postmanURL struct {
+		Raw string `json:"raw"`
+	}
+
+	postmanBody struct {
+		Mode       string            `json:"mode"`
+		Raw        string            `json:"raw"`
+		URLEncoded []postmanKeyValue `json:"urlencoded"`
+		FormData   []postmanKeyValue `json:"formdata"`
+		GraphQL    *struct {
+			Query     string `json:"query"`
+			Variables string `json:"variables"`
+		} `json:"graphql"`
+		Options struct {
+			Raw struct {
+				Language string `json:"language"`
+			} `json:"raw"`
+		} `json:"options"`
+	}
+
+	postmanAuth struct {
+		Type   string            `json:"type"`
+		Bearer []postmanKeyValue `json:"bearer"`
+		Basic  []postmanKeyValue `json:"basic"`
+		APIKey []postmanKeyValue `json:"apikey"`
+	}
+
+	postmanKeyValue struct {
+		Key      string      `json:"key"`
+		Value    interface{} `json:"value"`
+		Type     string      `json:"type"`
+		Disabled bool        `json:"disabled"`
+	}
+)
+
+// UnmarshalJSON unmarshals a request, which may be only its URL.
+func (r *postmanRequest) UnmarshalJSON(data []byte) error {
+	var url string
+	if json.Unmarshal(data, &url) == nil {
+		*r = postmanRequest{Method: http.MethodGet, URL: postmanURL{Raw: url}}
+		return nil
+	}
+	type plain postmanRequest
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// UnmarshalJSON unmarshals a URL, which may be only its raw string.
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	if json.Unmarshal(data, &u.Raw) == nil {
+		return nil
+	}
+	type plain postmanURL
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+func (kv postmanKeyValue) value() string {
+	switch v := kv.Value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func authValue(values []postmanKeyValue, key string) string {
+	for _, kv := range values {
+		if kv.Key == key {
+			return kv.value()
+		}
+	}
+	return ""
+}
+
+//nolint:gochecknoglobals
+var postmanVariableRegexp = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+// postmanConverter converts a collection, keeping track of the variables its
+// requests reference.
+type postmanConverter struct {
+	vars        map[string]bool
+	usesEncoder bool
+}
+
+// template returns a JavaScript expression of the string, with its
+// {{variables}} replaced by the ones of the script.
+func (c *postmanConverter) template(s string) string {
+	if !postmanVariableRegexp.MatchString(s) {
+		return quote(s)
+	}
+
+	var b strings.Builder
+	b.WriteString("`")
+	last := 0
+	for _, match := range postmanVariableRegexp.FindAllStringSubmatchIndex(s, -1) {
+		name := s[match[2]:match[3]]
+		c.vars[name] = true
+		b.WriteString(escapeTemplate(s[last:match[0]]))
+		fmt.Fprintf(&b, "${vars[%s]}", quote(name))
+		last = match[1]
+	}
+	b.WriteString(escapeTemplate(s[last:]))
+	b.WriteString("`")
+	return b.String()
+}
+
+// ConvertPostman generates a k6 script making the requests of a Postman
+// collection v2.1, in the groups of its folders. The variables of the
+// collection can be overridden by the environment variables of the same names.
+func ConvertPostman(data []byte) ([]byte, error) {
+	var collection postmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("invalid Postman collection: %w", err)
+	}
+
+	c := &postmanConverter{vars: make(map[string]bool)}
+	var body strings.Builder
+	c.writeItems(&body, collection.Item, collection.Auth, "  ")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// This script was converted from the %s Postman collection by k6 convert.\n", quote(collection.Info.Name))
+	b.WriteString("import http from \"k6/http\";\n")
+	if c.usesEncoder {
+		b.WriteString("import encoding from \"k6/encoding\";\n")
+	}
+	b.WriteString("import { check, group } from \"k6\";\n\n")
+
+	b.WriteString("// The variables of the collection, which the environment variables of the same names override.\n")
+	b.WriteString("const vars = {\n")
+	defined := make(map[string]bool)
+	for _, v := range collection.Variable {
+		defined[v.Key] = true
+		fmt.Fprintf(&b, "  %s: __ENV[%s] || %s,\n", quote(v.Key), quote(v.Key), quote(v.value()))
+	}
+	undefined := make([]string, 0, len(c.vars))
+	for name := range c.vars {
+		if !defined[name] {
+			undefined = append(undefined, name)
+		}
+	}
+	sort.Strings(undefined)
+	for _, name := range undefined {
+		fmt.Fprintf(&b, "  %s: __ENV[%s] || \"\",\n", quote(name), quote(name))
+	}
+	b.WriteString("};\n\n")
+
+	b.WriteString("export const options = {\n  vus: 1,\n  iterations: 1,\n};\n\n")
+	b.WriteString("export default function () {\n  let res;\n")
+	b.WriteString(body.String())
+	b.WriteString("}\n")
+
+	return []byte(b.String()), nil
+}
+
+func (c *postmanConverter) writeItems(b *strings.Builder, items []postmanItem, auth *postmanAuth, indent string) {
+	for _, item := range items {
+		itemAuth := auth
+		if item.Auth != nil {
+			itemAuth = item.Auth
+		}
+
+		b.WriteString("\n")
+		if item.Request == nil {
+			fmt.Fprintf(b, "%sgroup(%s, function () {\n", indent, quote(item.Name))
+			b.WriteString(indent + "  let res;\n")
+			c.writeItems(b, item.Item, itemAuth, indent+"  ")
+			b.WriteString(indent + "});\n")
+			continue
+		}
+
+		if item.Request.Auth != nil {
+			itemAuth = item.Request.Auth
+		}
+		fmt.Fprintf(b, "%s// %s\n", indent, item.Name)
+		c.request(b, item.Request, itemAuth, indent).write(b, indent, "res =")
+	}
+}
+
+func (c *postmanConverter) request(
+	b *strings.Builder, req *postmanRequest, auth *postmanAuth, indent string,
+) request {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	rawURL := req.URL.Raw
+
+	var headers []header
+	hasContentType := false
+	for _, h := range req.Header {
+		if h.Disabled {
+			continue
+		}
+		if strings.EqualFold(h.Key, "Content-Type") {
+			hasContentType = true
+		}
+		headers = append(headers, header{name: h.Key, value: c.template(h.value())})
+	}
+
+	if auth != nil {
+		switch auth.Type {
+		case "bearer":
+			headers = append(headers, header{
+				name: "Authorization", value: c.template("Bearer " + authValue(auth.Bearer, "token")),
+			})
+		case "basic":
+			c.usesEncoder = true
+			credentials := authValue(auth.Basic, "username") + ":" + authValue(auth.Basic, "password")
+			headers = append(headers, header{
+				name: "Authorization", value: "\"Basic \" + encoding.b64encode(" + c.template(credentials) + ")",
+			})
+		case "apikey":
+			key, value := authValue(auth.APIKey, "key"), authValue(auth.APIKey, "value")
+			if authValue(auth.APIKey, "in") == "query" {
+				separator := "?"
+				if strings.Contains(rawURL, "?") {
+					separator = "&"
+				}
+				rawURL += separator + key + "=" + value
+			} else {
+				headers = append(headers, header{name: key, value: c.template(value)})
+			}
+		case "noauth", "":
+		default:
+			fmt.Fprintf(b, "%s// The %s authentication of the request isn't converted.\n", indent, auth.Type)
+		}
+	}
+
+	r := request{method: method, url: c.template(rawURL), headers: headers}
+	if req.Body != nil {
+		r.body = c.body(b, req.Body, indent)
+		if req.Body.Mode == "raw" && req.Body.Options.Raw.Language == "json" && !hasContentType {
+			r.headers = append(r.headers, header{name: "Content-Type", value: quote("application/json")})
+		}
+	}
+	return r
+}
+
+func (c *postmanConverter) body(b *strings.Builder, body *postmanBody, indent string) string {
+	switch body.Mode {
+	case "raw":
+		if body.Raw == "" {
+			return ""
+		}
+		return c.template(body.Raw)
+	case "urlencoded":
+		return c.fields(body.URLEncoded, indent)
+	case "formdata":
+		fields := make([]postmanKeyValue, 0, len(body.FormData))
+		for _, field := range body.FormData {
+			if field.Type == "file" {
+				fmt.Fprintf(b, "%s// The %s file field isn't converted, add it with http.file().\n", indent, quote(field.Key))
+				continue
+			}
+			fields = append(fields, field)
+		}
+		return c.fields(fields, indent)
+	case "graphql":
+		if body.GraphQL == nil {
+			return ""
+		}
+		variables := body.GraphQL.Variables
+		if strings.TrimSpace(variables) == "" {
+			variables = "{}"
+		}
+		return fmt.Sprintf("JSON.stringify({ query: %s, variables: JSON.parse(%s) })",
+			c.template(body.GraphQL.Query), c.template(variables))
+	default:
+		if body.Mode != "" {
+			fmt.Fprintf(b, "%s// The %s body of the request isn't converted.\n", indent, body.Mode)
+		}
+		return ""
+	}
+}
+
+// fields returns an object literal of the enabled fields, which k6 sends as a form.
+func (c *postmanConverter) fields(fields []postmanKeyValue, indent string) string {
+	var b strings.Builder
+	b.WriteString("{\n")
+	n := 0
+	for _, field := range fields {
+		if field.Disabled {
+			continue
+		}
+		fmt.Fprintf(&b, "%s  %s: %s,\n", indent, quote(field.Key), c.template(field.value()))
+		n++
+	}
+	if n == 0 {
+		return ""
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
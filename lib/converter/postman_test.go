@@ -0,0 +1,122 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCollection = `{
+  "info": {
+    "name": "Shop",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "variable": [{"key": "baseUrl", "value": "https://shop.k6.local"}],
+  "item": [
+    {
+      "name": "List products",
+      "request": {
+        "method": "GET",
+        "header": [
+          {"key": "Accept", "value": "application/json"},
+          {"key": "X-Debug", "value": "1", "disabled": true}
+        ],
+        "url": {"raw": "{{baseUrl}}/products?page=1", "host": ["{{baseUrl}}"]}
+      }
+    },
+    {
+      "name": "Admin",
+      "auth": {
+        "type": "basic",
+        "basic": [{"key": "username", "value": "admin"}, {"key": "password", "value": "{{password}}"}]
+      },
+      "item": [
+        {
+          "name": "Create product",
+          "request": {
+            "method": "POST",
+            "url": "{{baseUrl}}/products",
+            "body": {"mode": "raw", "raw": "{\"name\": \"` + "`k6`" + `\"}", "options": {"raw": {"language": "json"}}}
+          }
+        },
+        {
+          "name": "Upload image",
+          "request": {
+            "method": "POST",
+            "url": "{{baseUrl}}/images",
+            "auth": {"type": "noauth"},
+            "body": {
+              "mode": "formdata",
+              "formdata": [
+                {"key": "title", "value": "Front", "type": "text"},
+                {"key": "image", "src": "front.png", "type": "file"}
+              ]
+            }
+          }
+        }
+      ]
+    },
+    {"name": "Health", "request": "https://shop.k6.local/health"}
+  ]
+}`
+
+func TestConvertPostman(t *testing.T) {
+	t.Parallel()
+
+	script, err := Convert([]byte(testCollection), Options{})
+	require.NoError(t, err)
+	_, err = sobek.ParseModule("script.js", string(script), nil)
+	require.NoError(t, err, string(script))
+
+	assert.Contains(t, string(script), `import encoding from "k6/encoding";`)
+	assert.Contains(t, string(script), `
+const vars = {
+  "baseUrl": __ENV["baseUrl"] || "https://shop.k6.local",
+  "password": __ENV["password"] || "",
+  "token": __ENV["token"] || "",
+};
+`)
+	assert.Contains(t, string(script), `
+  // List products
+  res = http.request("GET", `+"`${vars[\"baseUrl\"]}/products?page=1`"+`, null, {
+    headers: {
+      "Accept": "application/json",
+      "Authorization": `+"`Bearer ${vars[\"token\"]}`"+`,
+    },
+  });
+  check(res, { "status is 2xx": (r) => r.status >= 200 && r.status < 300 });
+`)
+	assert.Contains(t, string(script), `
+  group("Admin", function () {
+    let res;
+
+    // Create product
+    res = http.request("POST", `+"`${vars[\"baseUrl\"]}/products`"+`, "{\"name\": \"`+"`k6`"+`\"}", {
+      headers: {
+        "Authorization": "Basic " + encoding.b64encode(`+"`admin:${vars[\"password\"]}`"+`),
+        "Content-Type": "application/json",
+      },
+    });
+`)
+	assert.Contains(t, string(script), `
+    // Upload image
+    // The "image" file field isn't converted, add it with http.file().
+    res = http.request("POST", `+"`${vars[\"baseUrl\"]}/images`"+`, {
+      "title": "Front",
+    });
+`)
+	assert.Contains(t, string(script), `
+  // Health
+  res = http.request("GET", "https://shop.k6.local/health", null, {`)
+	assert.NotContains(t, string(script), "X-Debug")
+}
+
+func TestConvertPostmanInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := ConvertPostman([]byte(`{"item": {}}`))
+	require.ErrorContains(t, err, "invalid Postman collection")
+}
@@ -196,6 +196,20 @@ type ExecutionState struct {
 	pauseStateLock      sync.RWMutex
 	totalPausedDuration time.Duration // only modified behind the lock
 	resumeNotify        chan struct{}
+
+	// The encoded values the VUs and the scenarios shared with each other,
+	// by key, e.g. so that the scenarios depending on others can get what
+	// those produced.
+	sharedStore *SharedStore
+
+	// The observers of the samples of the test run, registered by the
+	// executors adjusting to them.
+	sampleObservers *SampleObservers
+
+	// The reasons the script marked the test run as failed for, without
+	// stopping it.
+	failureReasons     []string
+	failureReasonsLock sync.Mutex
 }
 
 // NewExecutionState initializes all of the pointers in the ExecutionState
@@ -230,6 +244,8 @@ func NewExecutionState(
 		pauseStateLock:             sync.RWMutex{},
 		totalPausedDuration:        0, // Accessed only behind the pauseStateLock
 		resumeNotify:               resumeNotify,
+		sharedStore:                NewSharedStore(),
+		sampleObservers:            NewSampleObservers(),
 	}
 }
 
@@ -547,3 +563,43 @@ func (es *ExecutionState) ReturnVU(vu InitializedVU, wasActive bool) {
 		es.ModCurrentlyActiveVUsCount(-1)
 	}
 }
+
+// SharedStore returns the key-value store shared by all the VUs of the local
+// instance.
+func (es *ExecutionState) SharedStore() *SharedStore {
+	return es.sharedStore
+}
+
+// SampleObservers returns the observers of the samples of the test run, which
+// have to be added to the outputs for them to observe anything.
+func (es *ExecutionState) SampleObservers() *SampleObservers {
+	return es.sampleObservers
+}
+
+// SetSharedData stores the provided encoded value under the given key, for
+// any VU of the local instance to get it, replacing any previous value.
+func (es *ExecutionState) SetSharedData(key string, value []byte) {
+	es.sharedStore.Set(key, value, 0)
+}
+
+// GetSharedData returns the encoded value stored under the provided key, and
+// whether there is one.
+func (es *ExecutionState) GetSharedData(key string) ([]byte, bool) {
+	return es.sharedStore.Get(key)
+}
+
+// MarkFailed marks the test run as failed for the provided reason, without
+// stopping it.
+func (es *ExecutionState) MarkFailed(reason string) {
+	es.failureReasonsLock.Lock()
+	defer es.failureReasonsLock.Unlock()
+	es.failureReasons = append(es.failureReasons, reason)
+}
+
+// GetFailureReasons returns the reasons the test run was marked as failed
+// for, if it was.
+func (es *ExecutionState) GetFailureReasons() []string {
+	es.failureReasonsLock.Lock()
+	defer es.failureReasonsLock.Unlock()
+	return append([]string(nil), es.failureReasons...)
+}
@@ -0,0 +1,517 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/errext"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/ui/pb"
+)
+
+const adaptiveArrivalRateType = "adaptive-arrival-rate"
+
+func init() {
+	lib.RegisterExecutorConfigType(
+		adaptiveArrivalRateType,
+		func(name string, rawJSON []byte) (lib.ExecutorConfig, error) {
+			config := NewAdaptiveArrivalRateConfig(name)
+			err := lib.StrictJSONUnmarshal(rawJSON, &config)
+			return config, err
+		},
+	)
+}
+
+// AdaptiveArrivalRateConfig stores config for the adaptive arrival-rate
+// executor, which adjusts its rate to the highest one meeting an objective on
+// the duration and the failures of the iterations.
+type AdaptiveArrivalRateConfig struct {
+	BaseConfig
+	StartRate null.Int           `json:"startRate"`
+	TimeUnit  types.NullDuration `json:"timeUnit"`
+	Duration  types.NullDuration `json:"duration"`
+
+	// Every `AdjustInterval`, the rate is increased by `RateStep` if the
+	// iterations of the interval met the objective, or decreased by it
+	// otherwise, within `MinRate` and `MaxRate`, if specified
+	RateStep       null.Int           `json:"rateStep"`
+	MinRate        null.Int           `json:"minRate"`
+	MaxRate        null.Int           `json:"maxRate"`
+	AdjustInterval types.NullDuration `json:"adjustInterval"`
+
+	// The objective is met when the `LatencyPercentile` of the samples of the
+	// `LatencyMetric` time trend, `http_req_duration` by default, emitted by
+	// the scenario is at most `MaxLatency`, and the rate of the failed and
+	// dropped iterations is at most `MaxErrorRate`, for those specified
+	LatencyMetric     null.String        `json:"latencyMetric"`
+	LatencyPercentile null.Float         `json:"latencyPercentile"`
+	MaxLatency        types.NullDuration `json:"maxLatency"`
+	MaxErrorRate      null.Float         `json:"maxErrorRate"`
+
+	// Initialize `PreAllocatedVUs` number of VUs, and if more than that are needed,
+	// they will be dynamically allocated, until `MaxVUs` is reached, which is an
+	// absolutely hard limit on the number of VUs the executor will use
+	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
+	MaxVUs          null.Int `json:"maxVUs"`
+}
+
+// NewAdaptiveArrivalRateConfig returns an AdaptiveArrivalRateConfig with default values
+func NewAdaptiveArrivalRateConfig(name string) *AdaptiveArrivalRateConfig {
+	return &AdaptiveArrivalRateConfig{
+		BaseConfig:        NewBaseConfig(name, adaptiveArrivalRateType),
+		TimeUnit:          types.NewNullDuration(1*time.Second, false),
+		MinRate:           null.NewInt(1, false),
+		AdjustInterval:    types.NewNullDuration(10*time.Second, false),
+		LatencyMetric:     null.NewString("http_req_duration", false),
+		LatencyPercentile: null.NewFloat(95, false),
+	}
+}
+
+// Make sure we implement the lib.ExecutorConfig interface
+var _ lib.ExecutorConfig = &AdaptiveArrivalRateConfig{}
+
+// GetPreAllocatedVUs is just a helper method that returns the scaled pre-allocated VUs.
+func (aarc AdaptiveArrivalRateConfig) GetPreAllocatedVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(aarc.PreAllocatedVUs.Int64)
+}
+
+// GetMaxVUs is just a helper method that returns the scaled max VUs.
+func (aarc AdaptiveArrivalRateConfig) GetMaxVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(aarc.MaxVUs.Int64)
+}
+
+// GetDescription returns a human-readable description of the executor options
+func (aarc AdaptiveArrivalRateConfig) GetDescription(et *lib.ExecutionTuple) string {
+	preAllocatedVUs, maxVUs := aarc.GetPreAllocatedVUs(et), aarc.GetMaxVUs(et)
+	maxVUsRange := fmt.Sprintf("maxVUs: %d", preAllocatedVUs)
+	if maxVUs > preAllocatedVUs {
+		maxVUsRange += fmt.Sprintf("-%d", maxVUs)
+	}
+
+	startRatePerSec, _ := getArrivalRatePerSec(
+		getScaledArrivalRate(et.Segment, aarc.StartRate.Int64, aarc.TimeUnit.TimeDuration()),
+	).Float64()
+
+	return fmt.Sprintf("From %.2f iterations/s, adjusted every %s, for %s%s",
+		startRatePerSec, aarc.AdjustInterval.Duration, aarc.Duration.Duration,
+		aarc.getBaseInfo(maxVUsRange))
+}
+
+// Validate makes sure all options are configured and valid
+//
+//nolint:funlen,cyclop
+func (aarc *AdaptiveArrivalRateConfig) Validate() []error {
+	errors := aarc.BaseConfig.Validate()
+	if !aarc.StartRate.Valid {
+		errors = append(errors, fmt.Errorf("the startRate isn't specified"))
+	} else if aarc.StartRate.Int64 <= 0 {
+		errors = append(errors, fmt.Errorf("the startRate must be more than 0"))
+	}
+
+	if !aarc.RateStep.Valid {
+		errors = append(errors, fmt.Errorf("the rateStep isn't specified"))
+	} else if aarc.RateStep.Int64 <= 0 {
+		errors = append(errors, fmt.Errorf("the rateStep must be more than 0"))
+	}
+
+	if aarc.MinRate.Int64 <= 0 {
+		errors = append(errors, fmt.Errorf("the minRate must be more than 0"))
+	} else if aarc.MinRate.Int64 > aarc.StartRate.Int64 {
+		errors = append(errors, fmt.Errorf("the minRate can't be more than the startRate"))
+	}
+	if aarc.MaxRate.Valid && aarc.MaxRate.Int64 < aarc.StartRate.Int64 {
+		errors = append(errors, fmt.Errorf("the maxRate can't be less than the startRate"))
+	}
+
+	if aarc.TimeUnit.TimeDuration() <= 0 {
+		errors = append(errors, fmt.Errorf("the timeUnit must be more than 0"))
+	}
+
+	if aarc.AdjustInterval.TimeDuration() <= 0 {
+		errors = append(errors, fmt.Errorf("the adjustInterval must be more than 0"))
+	}
+
+	if !aarc.Duration.Valid {
+		errors = append(errors, fmt.Errorf("the duration is unspecified"))
+	} else if aarc.Duration.TimeDuration() < minDuration {
+		errors = append(errors, fmt.Errorf(
+			"the duration must be at least %s, but is %s", minDuration, aarc.Duration,
+		))
+	}
+
+	if !aarc.MaxLatency.Valid && !aarc.MaxErrorRate.Valid {
+		errors = append(errors, fmt.Errorf("either the maxLatency or the maxErrorRate has to be specified"))
+	}
+	if aarc.MaxLatency.Valid && aarc.MaxLatency.TimeDuration() <= 0 {
+		errors = append(errors, fmt.Errorf("the maxLatency must be more than 0"))
+	}
+	if aarc.LatencyMetric.String == "" {
+		errors = append(errors, fmt.Errorf("the latencyMetric can't be empty"))
+	}
+	if p := aarc.LatencyPercentile.Float64; p <= 0 || p > 100 {
+		errors = append(errors, fmt.Errorf("the latencyPercentile must be more than 0 and at most 100"))
+	}
+	if r := aarc.MaxErrorRate.Float64; aarc.MaxErrorRate.Valid && (r < 0 || r > 1) {
+		errors = append(errors, fmt.Errorf("the maxErrorRate must be between 0 and 1"))
+	}
+
+	errors = append(errors, validatePreAllocatedVUs(aarc.PreAllocatedVUs, &aarc.MaxVUs)...)
+
+	return errors
+}
+
+// GetExecutionRequirements returns the number of required VUs to run the
+// executor for its whole duration (disregarding any startTime), including the
+// maximum waiting time for any iterations to gracefully stop. This is used by
+// the execution scheduler in its VU reservation calculations, so it knows how
+// many VUs to pre-initialize.
+func (aarc AdaptiveArrivalRateConfig) GetExecutionRequirements(et *lib.ExecutionTuple) []lib.ExecutionStep {
+	return []lib.ExecutionStep{
+		{
+			TimeOffset:      0,
+			PlannedVUs:      uint64(et.ScaleInt64(aarc.PreAllocatedVUs.Int64)),
+			MaxUnplannedVUs: uint64(et.ScaleInt64(aarc.MaxVUs.Int64) - et.ScaleInt64(aarc.PreAllocatedVUs.Int64)),
+		}, {
+			TimeOffset:      aarc.Duration.TimeDuration() + aarc.GracefulStop.TimeDuration(),
+			PlannedVUs:      0,
+			MaxUnplannedVUs: 0,
+		},
+	}
+}
+
+// NewExecutor creates a new AdaptiveArrivalRate executor
+func (aarc AdaptiveArrivalRateConfig) NewExecutor(
+	es *lib.ExecutionState, logger *logrus.Entry,
+) (lib.Executor, error) {
+	return &AdaptiveArrivalRate{
+		BaseExecutor: NewBaseExecutor(&aarc, es, logger),
+		config:       aarc,
+	}, nil
+}
+
+// HasWork reports whether there is any work to be done for the given execution segment.
+func (aarc AdaptiveArrivalRateConfig) HasWork(et *lib.ExecutionTuple) bool {
+	return aarc.GetMaxVUs(et) > 0
+}
+
+// iterationsFeedback sums up the iterations of an adjustment interval, and
+// the samples of the latency metric they emitted.
+type iterationsFeedback struct {
+	latencies                  *metrics.TrendSink
+	completed, failed, dropped uint64
+}
+
+func newIterationsFeedback() iterationsFeedback {
+	return iterationsFeedback{latencies: metrics.NewHistogramTrendSink(metrics.DefaultTrendPrecision)}
+}
+
+// meetsObjective returns whether the iterations met the configured objective,
+// and the latency and the error rate they had. The latency objective isn't met
+// if there was no sample of the latency metric.
+func (aarc AdaptiveArrivalRateConfig) meetsObjective(fb iterationsFeedback) (bool, time.Duration, float64) {
+	total := fb.completed + fb.dropped
+	errorRate := float64(fb.failed+fb.dropped) / float64(total)
+	// The time trends are in milliseconds
+	latency := time.Duration(fb.latencies.P(aarc.LatencyPercentile.Float64/100) * float64(time.Millisecond))
+
+	met := true
+	if aarc.MaxLatency.Valid && (fb.latencies.Count() == 0 || latency > aarc.MaxLatency.TimeDuration()) {
+		met = false
+	}
+	if aarc.MaxErrorRate.Valid && errorRate > aarc.MaxErrorRate.Float64 {
+		met = false
+	}
+
+	return met, latency, errorRate
+}
+
+// nextRate returns the unscaled rate following the provided one, depending on
+// whether the objective was met with it.
+func (aarc AdaptiveArrivalRateConfig) nextRate(rate int64, met bool) int64 {
+	if !met {
+		if rate -= aarc.RateStep.Int64; rate < aarc.MinRate.Int64 {
+			rate = aarc.MinRate.Int64
+		}
+		return rate
+	}
+
+	if rate += aarc.RateStep.Int64; aarc.MaxRate.Valid && rate > aarc.MaxRate.Int64 {
+		rate = aarc.MaxRate.Int64
+	}
+	return rate
+}
+
+// AdaptiveArrivalRate starts iterations at a rate it adjusts to the highest
+// one meeting the configured objective, to find the capacity of the system
+// under test.
+type AdaptiveArrivalRate struct {
+	*BaseExecutor
+	config        AdaptiveArrivalRateConfig
+	et            *lib.ExecutionTuple
+	latencyMetric *metrics.Metric
+
+	mu       sync.Mutex
+	feedback iterationsFeedback
+}
+
+// Make sure we implement the lib.Executor interface.
+var _ lib.Executor = &AdaptiveArrivalRate{}
+
+// Init values needed for the execution
+func (aar *AdaptiveArrivalRate) Init(_ context.Context) error {
+	// err should always be nil, because Init() won't be called for executors
+	// with no work, as determined by their config's HasWork() method.
+	et, err := aar.BaseExecutor.executionState.ExecutionTuple.GetNewExecutionTupleFromValue(aar.config.MaxVUs.Int64)
+	aar.et = et
+	aar.iterSegIndex = lib.NewSegmentedIndex(et)
+	aar.feedback = newIterationsFeedback()
+	if err != nil {
+		return err
+	}
+
+	if !aar.config.MaxLatency.Valid {
+		return nil
+	}
+	name := aar.config.LatencyMetric.String
+	aar.latencyMetric = aar.executionState.Test.Registry.Get(name)
+	if aar.latencyMetric == nil {
+		return fmt.Errorf("the latencyMetric %q doesn't exist", name)
+	}
+	if aar.latencyMetric.Type != metrics.Trend || aar.latencyMetric.Contains != metrics.Time {
+		return fmt.Errorf("the latencyMetric %q isn't a time trend", name)
+	}
+	return nil
+}
+
+// observeSample records the samples of the latency metric emitted by the
+// scenario in the current adjustment interval. The samples without the
+// scenario tag, if it's disabled, are all recorded.
+func (aar *AdaptiveArrivalRate) observeSample(sample metrics.Sample) {
+	if sample.Metric != aar.latencyMetric {
+		return
+	}
+	if scenario, ok := sample.Tags.Get(metrics.TagScenario.String()); ok && scenario != aar.config.Name {
+		return
+	}
+
+	aar.mu.Lock()
+	defer aar.mu.Unlock()
+	aar.feedback.latencies.Add(sample)
+}
+
+// addIteration records an iteration of the current adjustment interval.
+func (aar *AdaptiveArrivalRate) addIteration(failed bool) {
+	aar.mu.Lock()
+	defer aar.mu.Unlock()
+
+	aar.feedback.completed++
+	if failed {
+		aar.feedback.failed++
+	}
+}
+
+// addDropped records a dropped iteration of the current adjustment interval.
+func (aar *AdaptiveArrivalRate) addDropped() {
+	aar.mu.Lock()
+	defer aar.mu.Unlock()
+
+	aar.feedback.dropped++
+}
+
+// takeFeedback returns the iterations of the ending adjustment interval, and
+// starts the next one.
+func (aar *AdaptiveArrivalRate) takeFeedback() iterationsFeedback {
+	aar.mu.Lock()
+	defer aar.mu.Unlock()
+
+	fb := aar.feedback
+	aar.feedback = newIterationsFeedback()
+
+	return fb
+}
+
+// feedbackVU records the outcome of the iterations of the VU it wraps, unless
+// they're interrupted.
+type feedbackVU struct {
+	lib.ActiveVU
+	ctx      context.Context //nolint:containedctx
+	executor *AdaptiveArrivalRate
+}
+
+// RunOnce runs an iteration of the wrapped VU, and records it.
+func (vu feedbackVU) RunOnce() error {
+	err := vu.ActiveVU.RunOnce()
+	if vu.ctx.Err() != nil || (err != nil && errext.IsInterruptError(err)) {
+		return err
+	}
+
+	vu.executor.addIteration(err != nil)
+
+	return err
+}
+
+// Run executes iterations at a rate adjusted every adjustInterval.
+//
+//nolint:funlen
+func (aar *AdaptiveArrivalRate) Run(parentCtx context.Context, out chan<- metrics.SampleContainer) (err error) {
+	gracefulStop := aar.config.GetGracefulStop()
+	duration := aar.config.Duration.TimeDuration()
+	adjustInterval := aar.config.AdjustInterval.TimeDuration()
+	timeUnit := aar.config.TimeUnit.TimeDuration()
+	preAllocatedVUs := aar.config.GetPreAllocatedVUs(aar.executionState.ExecutionTuple)
+	maxVUs := aar.config.GetMaxVUs(aar.executionState.ExecutionTuple)
+
+	// The rate is kept unscaled, to be adjusted by the configured steps, and
+	// scaled only to get the period between the iterations of this segment.
+	rate := aar.config.StartRate.Int64
+	var tickerPeriod, currentRatePerSec int64 // accessed atomically
+	setRate := func(rate int64) {
+		scaledRate := getScaledArrivalRate(aar.et.Segment, rate, timeUnit)
+		atomic.StoreInt64(&tickerPeriod, int64(getTickerPeriod(scaledRate).TimeDuration()))
+		ratePerSec, _ := getArrivalRatePerSec(scaledRate).Float64()
+		atomic.StoreInt64(&currentRatePerSec, int64(math.Round(ratePerSec*100)))
+	}
+	setRate(rate)
+
+	// Make sure the log and the progress bar have accurate information
+	aar.logger.WithFields(logrus.Fields{
+		"maxVUs": maxVUs, "preAllocatedVUs": preAllocatedVUs, "duration": duration,
+		"adjustInterval": adjustInterval, "type": aar.config.GetType(),
+	}).Debug("Starting executor run...")
+
+	if aar.latencyMetric != nil {
+		defer aar.executionState.SampleObservers().Observe(aar.observeSample)()
+	}
+
+	waitOnProgressChannel := make(chan struct{})
+	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(parentCtx, duration, gracefulStop)
+	defer func() {
+		cancel()
+		<-waitOnProgressChannel
+	}()
+
+	vus := newArrivalRateVUs(aar.BaseExecutor, aar.config.BaseConfig, preAllocatedVUs, maxVUs,
+		func(ctx context.Context, vu lib.ActiveVU) lib.ActiveVU {
+			return feedbackVU{ActiveVU: vu, ctx: ctx, executor: aar}
+		})
+	defer vus.stop(cancel)
+
+	progressFn := func() (float64, []string) {
+		spent := time.Since(startTime)
+		progIters := fmt.Sprintf("%.2f iters/s", float64(atomic.LoadInt64(&currentRatePerSec))/100)
+
+		right := []string{vus.progress(), duration.String(), progIters}
+
+		if spent > duration {
+			return 1, right
+		}
+
+		spentDuration := pb.GetFixedLengthDuration(spent, duration)
+		progDur := fmt.Sprintf("%s/%s", spentDuration, duration)
+		right[1] = progDur
+
+		return math.Min(1, float64(spent)/float64(duration)), right
+	}
+	aar.progress.Modify(pb.WithProgress(progressFn))
+	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
+		Name:       aar.config.Name,
+		Executor:   aar.config.Type,
+		StartTime:  startTime,
+		ProgressFn: progressFn,
+	})
+
+	go func() {
+		trackProgress(parentCtx, maxDurationCtx, regDurationCtx, aar, progressFn)
+		close(waitOnProgressChannel)
+	}()
+
+	if err = vus.start(maxDurationCtx, preAllocatedVUs); err != nil {
+		return err
+	}
+
+	// nextIterationTimer returns the timer until the iteration following the one
+	// started at the provided time, if the scaled rate of this segment isn't 0.
+	nextIterationTimer := func(last time.Time) (time.Time, <-chan time.Time) {
+		period := time.Duration(atomic.LoadInt64(&tickerPeriod))
+		if period <= 0 {
+			return last, nil
+		}
+		next := last.Add(period)
+		return next, time.After(time.Until(next))
+	}
+	nextIteration, iterationCh := nextIterationTimer(startTime)
+
+	adjustTicker := time.NewTicker(adjustInterval)
+	defer adjustTicker.Stop()
+
+	var evaluated bool
+	var highestMetRate int64
+	metricTags := aar.getMetricTags(nil)
+	for {
+		select {
+		case <-iterationCh:
+			nextIteration, iterationCh = nextIterationTimer(nextIteration)
+			if !vus.runIteration(parentCtx, out, metricTags) {
+				// The dropped iterations count against the objective, as
+				// the rate couldn't be sustained
+				aar.addDropped()
+			}
+
+		case <-adjustTicker.C:
+			fb := aar.takeFeedback()
+			if fb.completed+fb.dropped == 0 {
+				// Nothing to adjust the rate to, the iterations last longer
+				// than the interval or the segment has no iterations
+				continue
+			}
+
+			evaluated = true
+			met, latency, errorRate := aar.config.meetsObjective(fb)
+			if met && rate > highestMetRate {
+				highestMetRate = rate
+			}
+			newRate := aar.config.nextRate(rate, met)
+			aar.logger.WithFields(logrus.Fields{
+				"rate": rate, "newRate": newRate, "latency": latency, "errorRate": errorRate, "met": met,
+			}).Debug("Adjusting the arrival rate...")
+
+			if newRate != rate {
+				rate = newRate
+				setRate(rate)
+				if iterationCh == nil { // the segment had no iterations so far
+					nextIteration, iterationCh = nextIterationTimer(time.Now())
+				}
+			}
+
+		case <-regDurationCtx.Done():
+			if evaluated {
+				aar.logHighestMetRate(highestMetRate)
+			}
+			return nil
+		}
+	}
+}
+
+// logHighestMetRate logs the highest rate at which the iterations met the
+// objective, which is the outcome of the capacity discovery.
+func (aar *AdaptiveArrivalRate) logHighestMetRate(rate int64) {
+	if rate == 0 {
+		aar.logger.Warn("The objective wasn't met at any of the arrival rates")
+		return
+	}
+
+	ratePerSec, _ := getArrivalRatePerSec(big.NewRat(rate, int64(aar.config.TimeUnit.TimeDuration()))).Float64()
+	aar.logger.WithFields(logrus.Fields{
+		"rate": rate, "timeUnit": aar.config.TimeUnit.Duration,
+	}).Infof("The highest arrival rate meeting the objective was %.2f iterations/s", ratePerSec)
+}
@@ -0,0 +1,219 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func getTestAdaptiveArrivalRateConfig() *AdaptiveArrivalRateConfig {
+	return &AdaptiveArrivalRateConfig{
+		BaseConfig:        BaseConfig{GracefulStop: types.NullDurationFrom(1 * time.Second)},
+		TimeUnit:          types.NullDurationFrom(time.Second),
+		StartRate:         null.IntFrom(50),
+		RateStep:          null.IntFrom(20),
+		MinRate:           null.IntFrom(10),
+		AdjustInterval:    types.NullDurationFrom(500 * time.Millisecond),
+		Duration:          types.NullDurationFrom(2 * time.Second),
+		LatencyMetric:     null.StringFrom("http_req_duration"),
+		LatencyPercentile: null.FloatFrom(95),
+		PreAllocatedVUs:   null.IntFrom(10),
+		MaxVUs:            null.IntFrom(20),
+	}
+}
+
+func TestAdaptiveArrivalRateMeetsObjective(t *testing.T) {
+	t.Parallel()
+
+	config := getTestAdaptiveArrivalRateConfig()
+	config.MaxLatency = types.NullDurationFrom(100 * time.Millisecond)
+	config.MaxErrorRate = null.FloatFrom(0.1)
+
+	feedback := func(failed, dropped uint64, latencies ...time.Duration) iterationsFeedback {
+		fb := newIterationsFeedback()
+		fb.completed, fb.failed, fb.dropped = uint64(len(latencies)), failed, dropped
+		for _, l := range latencies {
+			fb.latencies.Add(metrics.Sample{Value: metrics.D(l)})
+		}
+		return fb
+	}
+
+	testCases := []struct {
+		name     string
+		feedback iterationsFeedback
+		met      bool
+	}{
+		{name: "fast", feedback: feedback(0, 0, 10*time.Millisecond, 50*time.Millisecond), met: true},
+		{name: "slow", feedback: feedback(0, 0, 10*time.Millisecond, 200*time.Millisecond)},
+		{name: "failed", feedback: feedback(1, 0, 10*time.Millisecond, 50*time.Millisecond)},
+		{name: "dropped", feedback: feedback(0, 1, 10*time.Millisecond, 50*time.Millisecond)},
+		{name: "all dropped", feedback: feedback(0, 3)},
+		{name: "no latency samples", feedback: iterationsFeedback{latencies: feedback(0, 0).latencies, completed: 2}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			met, _, _ := config.meetsObjective(tc.feedback)
+			assert.Equal(t, tc.met, met)
+		})
+	}
+}
+
+func TestAdaptiveArrivalRateNextRate(t *testing.T) {
+	t.Parallel()
+
+	config := getTestAdaptiveArrivalRateConfig()
+	config.MaxRate = null.IntFrom(80)
+
+	assert.Equal(t, int64(70), config.nextRate(50, true))
+	assert.Equal(t, int64(80), config.nextRate(70, true))
+	assert.Equal(t, int64(80), config.nextRate(80, true))
+	assert.Equal(t, int64(30), config.nextRate(50, false))
+	assert.Equal(t, int64(10), config.nextRate(20, false))
+	assert.Equal(t, int64(10), config.nextRate(10, false))
+
+	config.MaxRate = null.Int{}
+	assert.Equal(t, int64(1020), config.nextRate(1000, true))
+}
+
+func TestAdaptiveArrivalRateRunIncreasesRate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		count int64
+		es    *lib.ExecutionState
+	)
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		// what the output manager would do with the samples of the VUs
+		es.SampleObservers().AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: es.Test.BuiltinMetrics.HTTPReqDuration, Tags: es.Test.RunTags},
+			Time:       time.Now(),
+			Value:      10,
+		}})
+		return nil
+	})
+
+	config := getTestAdaptiveArrivalRateConfig()
+	config.StartRate = null.IntFrom(10)
+	config.MaxLatency = types.NullDurationFrom(time.Second)
+
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+	es = test.state
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	require.NoError(t, test.executor.Run(test.ctx, engineOut))
+	require.Empty(t, test.logHook.Drain())
+
+	// 10, 30, 50 and 70 iterations/s for half a second each, instead of a
+	// constant 10 iterations/s.
+	assert.InDelta(t, 80, atomic.LoadInt64(&count), 15)
+}
+
+func TestAdaptiveArrivalRateRunDecreasesRate(t *testing.T) {
+	t.Parallel()
+
+	var count int64
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		return errors.New("failed")
+	})
+
+	config := getTestAdaptiveArrivalRateConfig()
+	config.MaxErrorRate = null.FloatFrom(0.1)
+
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	require.NoError(t, test.executor.Run(test.ctx, engineOut))
+
+	// 50, 30, 10 and 10 iterations/s for half a second each, instead of a
+	// constant 50 iterations/s.
+	assert.InDelta(t, 50, atomic.LoadInt64(&count), 10)
+
+	var notMet bool
+	for _, entry := range test.logHook.Drain() {
+		if entry.Message == "The objective wasn't met at any of the arrival rates" {
+			notMet = true
+		}
+	}
+	assert.True(t, notMet)
+}
+
+func TestAdaptiveArrivalRateRunUsesLatencyMetric(t *testing.T) {
+	t.Parallel()
+
+	var (
+		count int64
+		es    *lib.ExecutionState
+	)
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		// the iterations are fast, but their requests are slow
+		es.SampleObservers().AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: es.Test.BuiltinMetrics.HTTPReqDuration, Tags: es.Test.RunTags},
+			Time:       time.Now(),
+			Value:      2000,
+		}})
+		return nil
+	})
+
+	config := getTestAdaptiveArrivalRateConfig()
+	config.MaxLatency = types.NullDurationFrom(time.Second)
+
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+	es = test.state
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	require.NoError(t, test.executor.Run(test.ctx, engineOut))
+
+	// 50, 30, 10 and 10 iterations/s for half a second each, instead of a
+	// constant 50 iterations/s.
+	assert.InDelta(t, 50, atomic.LoadInt64(&count), 10)
+}
+
+func TestAdaptiveArrivalRateInitLatencyMetric(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"missing":   `the latencyMetric "missing" doesn't exist`,
+		"vus":       `the latencyMetric "vus" isn't a time trend`,
+		"data_sent": `the latencyMetric "data_sent" isn't a time trend`,
+	}
+	for name, expErr := range testCases {
+		name, expErr := name, expErr
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := getTestAdaptiveArrivalRateConfig()
+			config.MaxLatency = types.NullDurationFrom(time.Second)
+			config.LatencyMetric = null.StringFrom(name)
+
+			runner := simpleRunner(func(_ context.Context, _ *lib.State) error { return nil })
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			testRunState := getTestRunState(t, lib.Options{}, runner)
+			execReqs := config.GetExecutionRequirements(et)
+			es := lib.NewExecutionState(testRunState, et, lib.GetMaxPlannedVUs(execReqs), lib.GetMaxPossibleVUs(execReqs))
+
+			executor, err := config.NewExecutor(es, testRunState.Logger.WithField("executor", "test"))
+			require.NoError(t, err)
+			require.EqualError(t, executor.Init(context.Background()), expErr)
+		})
+	}
+}
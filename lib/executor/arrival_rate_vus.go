@@ -0,0 +1,162 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/ui/pb"
+)
+
+// arrivalRateVUs manages the VUs of the executors which start their iterations
+// at given times, independently of the VUs: the pre-allocated VUs, and once
+// they are all busy, the unplanned ones, which are initialized in the
+// background as long as there are less than maxVUs.
+type arrivalRateVUs struct {
+	executor *BaseExecutor
+	config   BaseConfig
+	maxVUs   int64
+	// wrapVU, if set, wraps the activated VUs, e.g. to record their
+	// iterations.
+	wrapVU func(ctx context.Context, vu lib.ActiveVU) lib.ActiveVU
+
+	pool                  *activeVUPool
+	activeVUsWg           sync.WaitGroup
+	activeVUsCount        uint64 // accessed atomically
+	remainingUnplannedVUs int64
+	makeUnplannedVUCh     chan struct{}
+	returnedVUs           chan struct{}
+	shownWarning          bool
+	vusFmt                string
+}
+
+func newArrivalRateVUs(
+	executor *BaseExecutor, config BaseConfig, preAllocatedVUs, maxVUs int64,
+	wrapVU func(context.Context, lib.ActiveVU) lib.ActiveVU,
+) *arrivalRateVUs {
+	return &arrivalRateVUs{
+		executor:              executor,
+		config:                config,
+		maxVUs:                maxVUs,
+		wrapVU:                wrapVU,
+		pool:                  newActiveVUPool(executor.executionState),
+		remainingUnplannedVUs: maxVUs - preAllocatedVUs,
+		makeUnplannedVUCh:     make(chan struct{}),
+		returnedVUs:           make(chan struct{}),
+		vusFmt:                pb.GetFixedLengthIntFormat(maxVUs),
+	}
+}
+
+// progress returns the running and the active VUs, for the progress bar.
+func (v *arrivalRateVUs) progress() string {
+	return fmt.Sprintf(v.vusFmt+"/"+v.vusFmt+" VUs", v.pool.Running(), atomic.LoadUint64(&v.activeVUsCount))
+}
+
+// start activates the pre-allocated VUs with the provided context, and starts
+// initializing the unplanned VUs whenever they are needed.
+func (v *arrivalRateVUs) start(ctx context.Context, preAllocatedVUs int64) error {
+	es, logger := v.executor.executionState, v.executor.logger
+
+	returnVU := func(u lib.InitializedVU) {
+		// Return the VU without decreasing the global active VU counter, which
+		// is done in the goroutine started by activeVUPool.AddVU, whenever the
+		// VU finishes running an iteration. This results in a more accurate
+		// report of VUs that are _actually_ active.
+		es.ReturnVU(u, false)
+		v.activeVUsWg.Done()
+	}
+
+	runIterationBasic := getIterationRunner(es, logger)
+	activateVU := func(initVU lib.InitializedVU) {
+		v.activeVUsWg.Add(1)
+		activeVU := initVU.Activate(getVUActivationParams(
+			ctx, v.config, returnVU, v.executor.nextIterationCounters,
+		))
+		atomic.AddUint64(&v.activeVUsCount, 1)
+		if v.wrapVU != nil {
+			activeVU = v.wrapVU(ctx, activeVU)
+		}
+		v.pool.AddVU(ctx, activeVU, runIterationBasic)
+	}
+
+	go func() {
+		defer close(v.returnedVUs)
+		for range v.makeUnplannedVUCh {
+			logger.Debug("Starting initialization of an unplanned VU...")
+			initVU, err := es.GetUnplannedVU(ctx, logger)
+			if err != nil {
+				// TODO figure out how to return it to the Run goroutine
+				logger.WithError(err).Error("Error while allocating unplanned VU")
+			} else {
+				logger.Debug("The unplanned VU finished initializing successfully!")
+				activateVU(initVU)
+			}
+		}
+	}()
+
+	// Get the pre-allocated VUs in the local buffer
+	for i := int64(0); i < preAllocatedVUs; i++ {
+		initVU, err := es.GetPlannedVU(logger, false)
+		if err != nil {
+			return err
+		}
+		activateVU(initVU)
+	}
+	return nil
+}
+
+// runIteration starts an iteration on a free VU, and returns whether there was
+// one. Otherwise, the iteration is dropped, and an unplanned VU is initialized
+// in the background, if there can be more.
+func (v *arrivalRateVUs) runIteration(
+	ctx context.Context, out chan<- metrics.SampleContainer, droppedTags *metrics.TagSet,
+) bool {
+	if v.pool.TryRunIteration() {
+		return true
+	}
+
+	// Since there aren't any free VUs available, consider this iteration
+	// dropped - we aren't going to try to recover it, but
+	metrics.PushIfNotDone(ctx, out, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: v.executor.executionState.Test.BuiltinMetrics.DroppedIterations,
+			Tags:   droppedTags,
+		},
+		Time:  time.Now(),
+		Value: 1,
+	})
+
+	// We'll try to start allocating another VU in the background,
+	// non-blockingly, if we have remainingUnplannedVUs...
+	if v.remainingUnplannedVUs == 0 {
+		if !v.shownWarning {
+			v.executor.logger.Warningf("Insufficient VUs, reached %d active VUs and cannot initialize more", v.maxVUs)
+			v.shownWarning = true
+		}
+		return false
+	}
+
+	select {
+	case v.makeUnplannedVUCh <- struct{}{}: // great!
+		v.remainingUnplannedVUs--
+	default: // we're already allocating a new VU
+	}
+	return false
+}
+
+// stop waits for the VUs to finish their iterations, and for them to be
+// deactivated by the cancel function, which cancels their context.
+func (v *arrivalRateVUs) stop(cancel func()) {
+	close(v.makeUnplannedVUCh)
+	// Make sure all VUs aren't executing iterations anymore, for the cancel()
+	// below to deactivate them.
+	<-v.returnedVUs
+	// first close the vusPool so we wait for the gracefulShutdown
+	v.pool.Close()
+	cancel()
+	v.activeVUsWg.Wait()
+}
@@ -32,6 +32,11 @@ type BaseConfig struct {
 	Exec         null.String          `json:"exec"` // function name, externally validated
 	Tags         map[string]string    `json:"tags"`
 	Options      *lib.ScenarioOptions `json:"options,omitempty"`
+	DependsOn    []string             `json:"dependsOn,omitempty"`
+	Setup        string               `json:"setup,omitempty"`    // function name, externally validated
+	Teardown     string               `json:"teardown,omitempty"` // function name, externally validated
+	Faults       *lib.Faults          `json:"faults,omitempty"`
+	Warmup       *lib.Warmup          `json:"warmup,omitempty"`
 
 	// TODO: future extensions like distribution, others?
 }
@@ -68,6 +73,17 @@ func (bc BaseConfig) Validate() (errors []error) {
 	if bc.GracefulStop.Duration < 0 {
 		errors = append(errors, fmt.Errorf("the gracefulStop timeout can't be negative"))
 	}
+	for _, dep := range bc.DependsOn {
+		if dep == bc.Name {
+			errors = append(errors, fmt.Errorf("a scenario can't depend on itself"))
+		}
+	}
+	if bc.Faults != nil {
+		errors = append(errors, bc.Faults.Validate()...)
+	}
+	if bc.Warmup != nil {
+		errors = append(errors, bc.Warmup.Validate()...)
+	}
 	return errors
 }
 
@@ -82,11 +98,18 @@ func (bc BaseConfig) GetType() string {
 }
 
 // GetStartTime returns the starting time, relative to the beginning of the
-// actual test, that this executor is supposed to execute.
+// actual test, or to the end of the scenarios it depends on, if any, that this
+// executor is supposed to execute.
 func (bc BaseConfig) GetStartTime() time.Duration {
 	return bc.StartTime.TimeDuration()
 }
 
+// GetDependsOn returns the names of the scenarios that have to be done before
+// this executor starts.
+func (bc BaseConfig) GetDependsOn() []string {
+	return bc.DependsOn
+}
+
 // GetGracefulStop returns how long k6 is supposed to wait for any still
 // running iterations to finish executing at the end of the normal executor
 // duration, before it actually kills them.
@@ -112,6 +135,18 @@ func (bc BaseConfig) GetExec() string {
 	return exec
 }
 
+// GetSetup returns the name of the function to run once before the executor
+// starts, if any.
+func (bc BaseConfig) GetSetup() string {
+	return bc.Setup
+}
+
+// GetTeardown returns the name of the function to run once after the executor
+// is done, if any.
+func (bc BaseConfig) GetTeardown() string {
+	return bc.Teardown
+}
+
 // GetScenarioOptions returns the options specific to a scenario.
 func (bc BaseConfig) GetScenarioOptions() *lib.ScenarioOptions {
 	return bc.Options
@@ -133,6 +168,15 @@ func (bc BaseConfig) getBaseInfo(facts ...string) string {
 	if bc.Exec.Valid {
 		facts = append(facts, fmt.Sprintf("exec: %s", bc.Exec.String))
 	}
+	if bc.Setup != "" {
+		facts = append(facts, fmt.Sprintf("setup: %s", bc.Setup))
+	}
+	if bc.Teardown != "" {
+		facts = append(facts, fmt.Sprintf("teardown: %s", bc.Teardown))
+	}
+	if len(bc.DependsOn) > 0 {
+		facts = append(facts, fmt.Sprintf("dependsOn: %s", strings.Join(bc.DependsOn, ", ")))
+	}
 	if bc.StartTime.Duration > 0 {
 		facts = append(facts, fmt.Sprintf("startTime: %s", bc.StartTime.Duration))
 	}
@@ -2,9 +2,11 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -108,18 +110,7 @@ func (carc *ConstantArrivalRateConfig) Validate() []error {
 		))
 	}
 
-	if !carc.PreAllocatedVUs.Valid {
-		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
-	} else if carc.PreAllocatedVUs.Int64 < 0 {
-		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs can't be negative"))
-	}
-
-	if !carc.MaxVUs.Valid {
-		// TODO: don't change the config while validating
-		carc.MaxVUs.Int64 = carc.PreAllocatedVUs.Int64
-	} else if carc.MaxVUs.Int64 < carc.PreAllocatedVUs.Int64 {
-		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
-	}
+	errors = append(errors, validatePreAllocatedVUs(carc.PreAllocatedVUs, &carc.MaxVUs)...)
 
 	return errors
 }
@@ -147,9 +138,14 @@ func (carc ConstantArrivalRateConfig) GetExecutionRequirements(et *lib.Execution
 func (carc ConstantArrivalRateConfig) NewExecutor(
 	es *lib.ExecutionState, logger *logrus.Entry,
 ) (lib.Executor, error) {
+	currentRate := carc.Rate.Int64
 	return &ConstantArrivalRate{
 		BaseExecutor: NewBaseExecutor(&carc, es, logger),
 		config:       carc,
+		currentRate:  &currentRate,
+		rateUpdates:  make(chan rateUpdateEvent),
+		running:      make(chan struct{}),
+		stopped:      make(chan struct{}),
 	}, nil
 }
 
@@ -164,10 +160,67 @@ type ConstantArrivalRate struct {
 	*BaseExecutor
 	config ConstantArrivalRateConfig
 	et     *lib.ExecutionTuple
+
+	// The rate can be changed while the executor is running, the running and
+	// stopped channels being closed when it starts and stops starting
+	// iterations.
+	currentRate      *int64
+	rateUpdates      chan rateUpdateEvent
+	running, stopped chan struct{}
 }
 
-// Make sure we implement the lib.Executor interface.
-var _ lib.Executor = &ConstantArrivalRate{}
+type rateUpdateEvent struct {
+	rate int64
+	err  chan error
+}
+
+// Make sure we implement the lib.Executor and lib.LiveUpdatableExecutor
+// interfaces.
+var (
+	_ lib.Executor              = &ConstantArrivalRate{}
+	_ lib.LiveUpdatableExecutor = &ConstantArrivalRate{}
+)
+
+// GetCurrentConfig returns the executor's configuration, with its current rate.
+func (car *ConstantArrivalRate) GetCurrentConfig() ConstantArrivalRateConfig {
+	config := car.config
+	config.Rate = null.IntFrom(atomic.LoadInt64(car.currentRate))
+	return config
+}
+
+// UpdateConfig changes the rate of the executor while it's running. Only the
+// rate of the supplied ConstantArrivalRateConfig can differ from the current
+// one.
+func (car *ConstantArrivalRate) UpdateConfig(ctx context.Context, newConf interface{}) error {
+	newConfig, ok := newConf.(ConstantArrivalRateConfig)
+	if !ok {
+		return errors.New("invalid config type")
+	}
+	if errs := newConfig.Validate(); len(errs) != 0 {
+		return fmt.Errorf("invalid configuration supplied: %s", lib.ConcatErrors(errs, ", "))
+	}
+	currentConfig := car.GetCurrentConfig()
+	newConfig.MaxVUs = currentConfig.MaxVUs // possibly set by Validate()
+	currentConfig.Rate = newConfig.Rate
+	if !reflect.DeepEqual(newConfig, currentConfig) {
+		return errors.New("only the rate of the constant arrival rate executor can be changed")
+	}
+
+	select {
+	case <-car.running:
+	default:
+		return errors.New("the rate can only be changed while the executor is running")
+	}
+	event := rateUpdateEvent{rate: newConfig.Rate.Int64, err: make(chan error)}
+	select {
+	case car.rateUpdates <- event:
+		return <-event.err
+	case <-car.stopped:
+		return errors.New("the rate can only be changed while the executor is running")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
 // Init values needed for the execution
 func (car *ConstantArrivalRate) Init(_ context.Context) error {
@@ -197,8 +250,9 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	duration := car.config.Duration.TimeDuration()
 	preAllocatedVUs := car.config.GetPreAllocatedVUs(car.executionState.ExecutionTuple)
 	maxVUs := car.config.GetMaxVUs(car.executionState.ExecutionTuple)
+	timeUnit := car.config.TimeUnit.TimeDuration()
 	// TODO: refactor and simplify
-	arrivalRate := getScaledArrivalRate(car.et.Segment, car.config.Rate.Int64, car.config.TimeUnit.TimeDuration())
+	arrivalRate := getScaledArrivalRate(car.et.Segment, car.config.Rate.Int64, timeUnit)
 	tickerPeriod := getTickerPeriod(arrivalRate).TimeDuration()
 	arrivalRatePerSec, _ := getArrivalRatePerSec(arrivalRate).Float64()
 
@@ -231,13 +285,16 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	activeVUsCount := uint64(0)
 
 	vusFmt := pb.GetFixedLengthIntFormat(maxVUs)
-	progIters := fmt.Sprintf(
-		pb.GetFixedLengthFloatFormat(arrivalRatePerSec, 2)+" iters/s", arrivalRatePerSec)
+	itersFmt := pb.GetFixedLengthFloatFormat(arrivalRatePerSec, 2) + " iters/s"
 	progressFn := func() (float64, []string) {
 		spent := time.Since(startTime)
 		currActiveVUs := atomic.LoadUint64(&activeVUsCount)
 		progVUs := fmt.Sprintf(vusFmt+"/"+vusFmt+" VUs",
 			vusPool.Running(), currActiveVUs)
+		currRatePerSec, _ := getArrivalRatePerSec(
+			getScaledArrivalRate(car.et.Segment, atomic.LoadInt64(car.currentRate), timeUnit),
+		).Float64()
+		progIters := fmt.Sprintf(itersFmt, currRatePerSec)
 
 		right := []string{progVUs, duration.String(), progIters}
 
@@ -315,54 +372,78 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- metrics
 	start, offsets, _ := car.et.GetStripedOffsets()
 	timer := time.NewTimer(time.Hour * 24)
 	// here the we need the not scaled one
-	notScaledTickerPeriod := getTickerPeriod(
-		big.NewRat(
-			car.config.Rate.Int64,
-			int64(car.config.TimeUnit.TimeDuration()),
-		)).TimeDuration()
+	getNotScaledTickerPeriod := func(rate int64) time.Duration {
+		return getTickerPeriod(big.NewRat(rate, int64(timeUnit))).TimeDuration()
+	}
+	notScaledTickerPeriod := getNotScaledTickerPeriod(car.config.Rate.Int64)
+	// The iterations are started relative to the last rate change, if any
+	anchorTime, anchorIndex := startTime, int64(0)
 
 	droppedIterationMetric := car.executionState.Test.BuiltinMetrics.DroppedIterations
 	shownWarning := false
 	metricTags := car.getMetricTags(nil)
-	for li, gi := 0, start; ; li, gi = li+1, gi+offsets[li%len(offsets)] {
-		t := notScaledTickerPeriod*time.Duration(gi) - time.Since(startTime)
-		timer.Reset(t)
-		select {
-		case <-timer.C:
-			if vusPool.TryRunIteration() {
-				continue
-			}
-
-			// Since there aren't any free VUs available, consider this iteration
-			// dropped - we aren't going to try to recover it, but
-
-			metrics.PushIfNotDone(parentCtx, out, metrics.Sample{
-				TimeSeries: metrics.TimeSeries{
-					Metric: droppedIterationMetric,
-					Tags:   metricTags,
-				},
-				Time:  time.Now(),
-				Value: 1,
-			})
-
-			// We'll try to start allocating another VU in the background,
-			// non-blockingly, if we have remainingUnplannedVUs...
-			if remainingUnplannedVUs == 0 {
-				if !shownWarning {
-					car.logger.Warningf("Insufficient VUs, reached %d active VUs and cannot initialize more", maxVUs)
-					shownWarning = true
+	// waitForIteration waits for the start time of the iteration with the
+	// global index gi, applying the rate updates meanwhile, and returns false
+	// if the executor duration is over instead.
+	waitForIteration := func(gi int64) bool {
+		for {
+			timer.Reset(notScaledTickerPeriod*time.Duration(gi-anchorIndex) - time.Since(anchorTime))
+			select {
+			case <-timer.C:
+				return true
+			case update := <-car.rateUpdates:
+				if !timer.Stop() {
+					<-timer.C
 				}
-				continue
+				// The next iteration starts right away, the following ones
+				// at the new rate
+				anchorTime, anchorIndex = time.Now(), gi
+				notScaledTickerPeriod = getNotScaledTickerPeriod(update.rate)
+				atomic.StoreInt64(car.currentRate, update.rate)
+				car.logger.WithField("rate", update.rate).Debug("Changed the iteration rate")
+				update.err <- nil
+			case <-regDurationCtx.Done():
+				return false
 			}
+		}
+	}
 
-			select {
-			case makeUnplannedVUCh <- struct{}{}: // great!
-				remainingUnplannedVUs--
-			default: // we're already allocating a new VU
+	close(car.running)
+	defer close(car.stopped)
+	for li, gi := 0, start; ; li, gi = li+1, gi+offsets[li%len(offsets)] {
+		if !waitForIteration(gi) {
+			return nil
+		}
+		if vusPool.TryRunIteration() {
+			continue
+		}
+
+		// Since there aren't any free VUs available, consider this iteration
+		// dropped - we aren't going to try to recover it, but
+
+		metrics.PushIfNotDone(parentCtx, out, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: droppedIterationMetric,
+				Tags:   metricTags,
+			},
+			Time:  time.Now(),
+			Value: 1,
+		})
+
+		// We'll try to start allocating another VU in the background,
+		// non-blockingly, if we have remainingUnplannedVUs...
+		if remainingUnplannedVUs == 0 {
+			if !shownWarning {
+				car.logger.Warningf("Insufficient VUs, reached %d active VUs and cannot initialize more", maxVUs)
+				shownWarning = true
 			}
+			continue
+		}
 
-		case <-regDurationCtx.Done():
-			return nil
+		select {
+		case makeUnplannedVUCh <- struct{}{}: // great!
+			remainingUnplannedVUs--
+		default: // we're already allocating a new VU
 		}
 	}
 }
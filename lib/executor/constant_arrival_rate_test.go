@@ -393,3 +393,52 @@ func TestConstantArrivalRateActiveVUs(t *testing.T) {
 	assert.GreaterOrEqual(t, running, int64(5))
 	assert.LessOrEqual(t, running, int64(10))
 }
+
+func TestConstantArrivalRateUpdateConfig(t *testing.T) {
+	t.Parallel()
+
+	var count int64
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		atomic.AddInt64(&count, 1)
+		return nil
+	})
+
+	config := getTestConstantArrivalRateConfig()
+	config.Name, config.Type = "test", constantArrivalRateType
+	config.Rate = null.IntFrom(10)
+	config.Duration = types.NullDurationFrom(2 * time.Second)
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	car, ok := test.executor.(*ConstantArrivalRate)
+	require.True(t, ok)
+	newConfig := car.GetCurrentConfig()
+	newConfig.Rate = null.IntFrom(50)
+	require.EqualError(t, car.UpdateConfig(test.ctx, newConfig),
+		"the rate can only be changed while the executor is running")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		time.Sleep(time.Second)
+
+		invalidConfig := car.GetCurrentConfig()
+		invalidConfig.Duration = types.NullDurationFrom(time.Minute)
+		assert.EqualError(t, car.UpdateConfig(test.ctx, invalidConfig),
+			"only the rate of the constant arrival rate executor can be changed")
+
+		assert.NoError(t, car.UpdateConfig(test.ctx, newConfig))
+		assert.Equal(t, null.IntFrom(50), car.GetCurrentConfig().Rate)
+	}()
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	require.NoError(t, test.executor.Run(test.ctx, engineOut))
+	wg.Wait()
+	require.Empty(t, test.logHook.Drain())
+
+	// 10 iterations in the first second and 50 in the second one
+	assert.InDelta(t, 60, atomic.LoadInt64(&count), 5)
+	require.EqualError(t, car.UpdateConfig(test.ctx, newConfig),
+		"the rate can only be changed while the executor is running")
+}
@@ -406,8 +406,125 @@ var configMapTestCases = []configMapTestCase{
 	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "maxVUs": 50, "stages": [{"duration": "5m", "target": 10}], "timeUnit": "-1s"}}`, exp{validationError: true}},
 	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 20, "maxVUs": 50, "stages": [{"duration": "5m", "target": 10}], "timeUnit": "0s"}}`, exp{validationError: true}},
 	{`{"varrival": {"executor": "ramping-arrival-rate", "preAllocatedVUs": 30, "maxVUs": 20, "stages": [{"duration": "5m", "target": 10}]}}`, exp{validationError: true}},
+	// adaptive-arrival-rate
+	{
+		`{"aarrival": {"executor": "adaptive-arrival-rate", "startRate": 10, "rateStep": 5, "maxRate": 100,
+		"adjustInterval": "30s", "duration": "10m", "maxLatency": "300ms", "maxErrorRate": 0.01,
+		"preAllocatedVUs": 20, "maxVUs": 50}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			sched := NewAdaptiveArrivalRateConfig("aarrival")
+			sched.StartRate = null.IntFrom(10)
+			sched.RateStep = null.IntFrom(5)
+			sched.MaxRate = null.IntFrom(100)
+			sched.AdjustInterval = types.NullDurationFrom(30 * time.Second)
+			sched.Duration = types.NullDurationFrom(10 * time.Minute)
+			sched.MaxLatency = types.NullDurationFrom(300 * time.Millisecond)
+			sched.MaxErrorRate = null.FloatFrom(0.01)
+			sched.PreAllocatedVUs = null.IntFrom(20)
+			sched.MaxVUs = null.IntFrom(50)
+			require.Equal(t, cm, lib.ScenarioConfigs{"aarrival": sched})
+
+			assert.Empty(t, cm["aarrival"].Validate())
+			assert.Empty(t, cm.Validate())
+
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, "From 10.00 iterations/s, adjusted every 30s, for 10m0s (maxVUs: 20-50, gracefulStop: 30s)",
+				cm["aarrival"].GetDescription(et))
+
+			schedReqs := cm["aarrival"].GetExecutionRequirements(et)
+			endOffset, isFinal := lib.GetEndOffset(schedReqs)
+			assert.Equal(t, 630*time.Second, endOffset)
+			assert.Equal(t, true, isFinal)
+			assert.Equal(t, uint64(20), lib.GetMaxPlannedVUs(schedReqs))
+			assert.Equal(t, uint64(50), lib.GetMaxPossibleVUs(schedReqs))
+		}},
+	},
+	{`{"aarrival": {"executor": "adaptive-arrival-rate", "startRate": 10, "rateStep": 5, "duration": "10m", "maxErrorRate": 0.01, "preAllocatedVUs": 20}}`, exp{}},
+	{`{"aarrival": {"executor": "adaptive-arrival-rate", "rateStep": 5, "duration": "10m", "maxErrorRate": 0.01, "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"aarrival": {"executor": "adaptive-arrival-rate", "startRate": 10, "duration": "10m", "maxErrorRate": 0.01, "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"aarrival": {"executor": "adaptive-arrival-rate", "startRate": 10, "rateStep": 5, "duration": "10m", "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"aarrival": {"executor": "adaptive-arrival-rate", "startRate": 10, "rateStep": 5, "duration": "10m", "maxErrorRate": 2, "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"aarrival": {"executor": "adaptive-arrival-rate", "startRate": 10, "rateStep": 5, "duration": "10m", "maxLatency": "1s", "latencyPercentile": 101, "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"aarrival": {"executor": "adaptive-arrival-rate", "startRate": 10, "rateStep": 5, "duration": "10m", "maxLatency": "1s", "latencyMetric": "", "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"aarrival": {"executor": "adaptive-arrival-rate", "startRate": 10, "rateStep": 5, "minRate": 20, "duration": "10m", "maxErrorRate": 0.01, "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"aarrival": {"executor": "adaptive-arrival-rate", "startRate": 10, "rateStep": 5, "maxRate": 5, "duration": "10m", "maxErrorRate": 0.01, "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"aarrival": {"executor": "adaptive-arrival-rate", "startRate": 10, "rateStep": 5, "maxErrorRate": 0.01, "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"aarrival": {"executor": "adaptive-arrival-rate", "startRate": 10, "rateStep": 5, "duration": "10m", "maxErrorRate": 0.01, "preAllocatedVUs": 20, "maxVUs": 10}}`, exp{validationError: true}},
+	// replay
+	{
+		`{"replay": {"executor": "replay", "timeScale": 2, "preAllocatedVUs": 20, "maxVUs": 50, "timestamps": [
+		"2023-01-01T10:00:00Z", "2023-01-01T10:00:01.5Z", "2023-01-01T10:00:01.5Z", "2023-01-01T10:01:00Z"]}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+			sched := NewReplayConfig("replay")
+			sched.Timestamps = []time.Time{
+				start, start.Add(1500 * time.Millisecond), start.Add(1500 * time.Millisecond), start.Add(time.Minute),
+			}
+			sched.TimeScale = null.FloatFrom(2)
+			sched.PreAllocatedVUs = null.IntFrom(20)
+			sched.MaxVUs = null.IntFrom(50)
+			require.Equal(t, cm, lib.ScenarioConfigs{"replay": sched})
+
+			assert.Empty(t, cm["replay"].Validate())
+			assert.Empty(t, cm.Validate())
+
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, "4 replayed iterations over 30s at 2x speed (maxVUs: 20-50, gracefulStop: 30s)",
+				cm["replay"].GetDescription(et))
+
+			schedReqs := cm["replay"].GetExecutionRequirements(et)
+			endOffset, isFinal := lib.GetEndOffset(schedReqs)
+			assert.Equal(t, 60*time.Second, endOffset)
+			assert.Equal(t, true, isFinal)
+			assert.Equal(t, uint64(20), lib.GetMaxPlannedVUs(schedReqs))
+			assert.Equal(t, uint64(50), lib.GetMaxPossibleVUs(schedReqs))
+		}},
+	},
+	{`{"replay": {"executor": "replay", "preAllocatedVUs": 20, "timestamps": ["2023-01-01T10:00:00Z"]}}`, exp{}},
+	{`{"replay": {"executor": "replay", "preAllocatedVUs": 20}}`, exp{validationError: true}},
+	{`{"replay": {"executor": "replay", "timestamps": ["2023-01-01T10:00:00Z"]}}`, exp{validationError: true}},
+	{`{"replay": {"executor": "replay", "preAllocatedVUs": 20, "timestamps": ["2023-01-01T10:00:01Z", "2023-01-01T10:00:00Z"]}}`, exp{validationError: true}},
+	{`{"replay": {"executor": "replay", "preAllocatedVUs": 20, "timeScale": 0, "timestamps": ["2023-01-01T10:00:00Z"]}}`, exp{validationError: true}},
+	{`{"replay": {"executor": "replay", "preAllocatedVUs": 20, "timestamps": ["10:00:00"]}}`, exp{parseError: true}},
 	// TODO: more tests of mixed executors and execution plans
 
+	// scenario dependencies
+	{
+		`{"login": {"executor": "constant-vus", "vus": 10, "duration": "30s", "gracefulStop": "0s"},
+		"load": {"executor": "per-vu-iterations", "vus": 5, "iterations": 1, "maxDuration": "20s", "gracefulStop": "0s",
+		"dependsOn": ["login"]}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			assert.Equal(t, []string{"login"}, cm["load"].GetDependsOn())
+
+			et, err := lib.NewExecutionTuple(nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, "1 iterations for each of 5 VUs (maxDuration: 20s, dependsOn: login)",
+				cm["load"].GetDescription(et))
+
+			// The dependent scenario can start as soon as the test does, if
+			// the login one ends early, or only once it had the time to run.
+			assert.Equal(t, []lib.ExecutionStep{
+				{TimeOffset: 0, PlannedVUs: 5},
+				{TimeOffset: 0, PlannedVUs: 15},
+				{TimeOffset: 30 * time.Second, PlannedVUs: 5},
+				{TimeOffset: 50 * time.Second},
+			}, cm.GetFullExecutionRequirements(et))
+		}},
+	},
+	{`{"load": {"executor": "constant-vus", "vus": 1, "duration": "10s", "dependsOn": ["login"]}}`, exp{validationError: true}},
+	{`{"load": {"executor": "constant-vus", "vus": 1, "duration": "10s", "dependsOn": ["load"]}}`, exp{validationError: true}},
+	{
+		`{"a": {"executor": "constant-vus", "vus": 1, "duration": "10s", "dependsOn": ["b"]},
+		"b": {"executor": "constant-vus", "vus": 1, "duration": "10s", "dependsOn": ["a"]}}`,
+		exp{custom: func(t *testing.T, cm lib.ScenarioConfigs) {
+			errs := cm.Validate()
+			require.Len(t, errs, 1)
+			assert.EqualError(t, errs[0], "the scenarios have a circular dependency: a -> b -> a")
+		}, validationError: true},
+	},
+
 	// scenario options
 	{
 		`{"ui": {"executor": "shared-iterations", "iterations": 22, "vus": 12, "maxDuration": "100s", "options": {"browser": {"someBrowserOption": true}}}}`,
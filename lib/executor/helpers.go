@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/errext"
 	"go.k6.io/k6/execution"
@@ -105,7 +106,14 @@ func getIterationRunner(
 	executionState *lib.ExecutionState, logger *logrus.Entry,
 ) func(context.Context, lib.ActiveVU) bool {
 	return func(ctx context.Context, vu lib.ActiveVU) bool {
+		iterationDone, ok := execution.StartTestRunIteration(ctx)
+		if !ok {
+			// the test run is being stopped, and will be cancelled soon
+			<-ctx.Done()
+			return false
+		}
 		err := vu.RunOnce()
+		iterationDone()
 
 		// TODO: track (non-ramp-down) errors from script iterations as a metric,
 		// and have a default threshold that will abort the script when the error
@@ -216,6 +224,25 @@ func trackProgress(
 	}
 }
 
+// validatePreAllocatedVUs validates the preAllocatedVUs and the maxVUs of the
+// arrival-rate executors, and defaults the maxVUs to the preAllocatedVUs.
+func validatePreAllocatedVUs(preAllocatedVUs null.Int, maxVUs *null.Int) []error {
+	var errors []error
+	if !preAllocatedVUs.Valid {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
+	} else if preAllocatedVUs.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs can't be negative"))
+	}
+
+	if !maxVUs.Valid {
+		// TODO: don't change the config while validating
+		maxVUs.Int64 = preAllocatedVUs.Int64
+	} else if maxVUs.Int64 < preAllocatedVUs.Int64 {
+		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
+	}
+	return errors
+}
+
 // getScaledArrivalRate returns a rational number containing the scaled value of
 // the given rate over the given period. This should generally be the first
 // function that's called, before we do any calculations with the users-supplied
@@ -258,6 +285,8 @@ func getVUActivationParams(
 		Exec:                     conf.GetExec(),
 		Env:                      conf.GetEnv(),
 		Tags:                     conf.GetTags(),
+		Faults:                   conf.Faults,
+		Warmup:                   conf.Warmup,
 		DeactivateCallback:       deactivateCallback,
 		GetNextIterationCounters: nextIterationCounters,
 	}
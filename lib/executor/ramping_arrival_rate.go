@@ -97,18 +97,7 @@ func (varc *RampingArrivalRateConfig) Validate() []error {
 
 	errors = append(errors, validateStages(varc.Stages)...)
 
-	if !varc.PreAllocatedVUs.Valid {
-		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs isn't specified"))
-	} else if varc.PreAllocatedVUs.Int64 < 0 {
-		errors = append(errors, fmt.Errorf("the number of preAllocatedVUs can't be negative"))
-	}
-
-	if !varc.MaxVUs.Valid {
-		// TODO: don't change the config while validating
-		varc.MaxVUs.Int64 = varc.PreAllocatedVUs.Int64
-	} else if varc.MaxVUs.Int64 < varc.PreAllocatedVUs.Int64 {
-		errors = append(errors, fmt.Errorf("maxVUs can't be less than preAllocatedVUs"))
-	}
+	errors = append(errors, validatePreAllocatedVUs(varc.PreAllocatedVUs, &varc.MaxVUs)...)
 
 	return errors
 }
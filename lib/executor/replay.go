@@ -0,0 +1,270 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/ui/pb"
+)
+
+const replayType = "replay"
+
+func init() {
+	lib.RegisterExecutorConfigType(
+		replayType,
+		func(name string, rawJSON []byte) (lib.ExecutorConfig, error) {
+			config := NewReplayConfig(name)
+			err := lib.StrictJSONUnmarshal(rawJSON, &config)
+			return config, err
+		},
+	)
+}
+
+// ReplayConfig stores config for the replay executor
+type ReplayConfig struct {
+	BaseConfig
+
+	// The times at which the recorded requests were made, e.g. the
+	// startedDateTime of the entries of a HAR file, in chronological order.
+	// Every timestamp starts an iteration, at the same offset from the start
+	// of the scenario as from the first timestamp, divided by `TimeScale`
+	Timestamps []time.Time `json:"timestamps"`
+	TimeScale  null.Float  `json:"timeScale"`
+
+	// Initialize `PreAllocatedVUs` number of VUs, and if more than that are needed,
+	// they will be dynamically allocated, until `MaxVUs` is reached, which is an
+	// absolutely hard limit on the number of VUs the executor will use
+	PreAllocatedVUs null.Int `json:"preAllocatedVUs"`
+	MaxVUs          null.Int `json:"maxVUs"`
+}
+
+// NewReplayConfig returns a ReplayConfig with default values
+func NewReplayConfig(name string) *ReplayConfig {
+	return &ReplayConfig{
+		BaseConfig: NewBaseConfig(name, replayType),
+		TimeScale:  null.NewFloat(1, false),
+	}
+}
+
+// Make sure we implement the lib.ExecutorConfig interface
+var _ lib.ExecutorConfig = &ReplayConfig{}
+
+// GetPreAllocatedVUs is just a helper method that returns the scaled pre-allocated VUs.
+func (rc ReplayConfig) GetPreAllocatedVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(rc.PreAllocatedVUs.Int64)
+}
+
+// GetMaxVUs is just a helper method that returns the scaled max VUs.
+func (rc ReplayConfig) GetMaxVUs(et *lib.ExecutionTuple) int64 {
+	return et.ScaleInt64(rc.MaxVUs.Int64)
+}
+
+// getDuration returns the duration of the replay, from the first timestamp to
+// the last one, divided by the time scale.
+func (rc ReplayConfig) getDuration() time.Duration {
+	if len(rc.Timestamps) == 0 {
+		return 0
+	}
+	return rc.getOffset(len(rc.Timestamps) - 1)
+}
+
+// getOffset returns the offset from the start of the scenario of the
+// iteration of the provided timestamp index.
+func (rc ReplayConfig) getOffset(i int) time.Duration {
+	return time.Duration(float64(rc.Timestamps[i].Sub(rc.Timestamps[0])) / rc.TimeScale.Float64)
+}
+
+// GetDescription returns a human-readable description of the executor options
+func (rc ReplayConfig) GetDescription(et *lib.ExecutionTuple) string {
+	preAllocatedVUs, maxVUs := rc.GetPreAllocatedVUs(et), rc.GetMaxVUs(et)
+	maxVUsRange := fmt.Sprintf("maxVUs: %d", preAllocatedVUs)
+	if maxVUs > preAllocatedVUs {
+		maxVUsRange += fmt.Sprintf("-%d", maxVUs)
+	}
+
+	speed := ""
+	if rc.TimeScale.Float64 != 1 {
+		speed = fmt.Sprintf(" at %gx speed", rc.TimeScale.Float64)
+	}
+
+	return fmt.Sprintf("%d replayed iterations over %s%s%s",
+		et.ScaleInt64(int64(len(rc.Timestamps))), rc.getDuration(), speed, rc.getBaseInfo(maxVUsRange))
+}
+
+// Validate makes sure all options are configured and valid
+func (rc *ReplayConfig) Validate() []error {
+	errors := rc.BaseConfig.Validate()
+	if len(rc.Timestamps) == 0 {
+		errors = append(errors, fmt.Errorf("the timestamps to replay aren't specified"))
+	}
+	for i := 1; i < len(rc.Timestamps); i++ {
+		if rc.Timestamps[i].Before(rc.Timestamps[i-1]) {
+			errors = append(errors, fmt.Errorf(
+				"the timestamps must be in chronological order, but %s is before %s",
+				rc.Timestamps[i].Format(time.RFC3339Nano), rc.Timestamps[i-1].Format(time.RFC3339Nano),
+			))
+			break
+		}
+	}
+
+	if rc.TimeScale.Float64 <= 0 {
+		errors = append(errors, fmt.Errorf("the timeScale must be more than 0"))
+	}
+
+	errors = append(errors, validatePreAllocatedVUs(rc.PreAllocatedVUs, &rc.MaxVUs)...)
+
+	return errors
+}
+
+// GetExecutionRequirements returns the number of required VUs to run the
+// executor for its whole duration (disregarding any startTime), including the
+// maximum waiting time for any iterations to gracefully stop. This is used by
+// the execution scheduler in its VU reservation calculations, so it knows how
+// many VUs to pre-initialize.
+func (rc ReplayConfig) GetExecutionRequirements(et *lib.ExecutionTuple) []lib.ExecutionStep {
+	return []lib.ExecutionStep{
+		{
+			TimeOffset:      0,
+			PlannedVUs:      uint64(et.ScaleInt64(rc.PreAllocatedVUs.Int64)),
+			MaxUnplannedVUs: uint64(et.ScaleInt64(rc.MaxVUs.Int64) - et.ScaleInt64(rc.PreAllocatedVUs.Int64)),
+		}, {
+			TimeOffset:      rc.getDuration() + rc.GracefulStop.TimeDuration(),
+			PlannedVUs:      0,
+			MaxUnplannedVUs: 0,
+		},
+	}
+}
+
+// NewExecutor creates a new Replay executor
+func (rc ReplayConfig) NewExecutor(
+	es *lib.ExecutionState, logger *logrus.Entry,
+) (lib.Executor, error) {
+	return &Replay{
+		BaseExecutor: NewBaseExecutor(&rc, es, logger),
+		config:       rc,
+	}, nil
+}
+
+// HasWork reports whether there is any work to be done for the given execution segment.
+func (rc ReplayConfig) HasWork(et *lib.ExecutionTuple) bool {
+	return rc.GetMaxVUs(et) > 0 && et.ScaleInt64(int64(len(rc.Timestamps))) > 0
+}
+
+// Replay starts an iteration for every recorded timestamp, reproducing the
+// original inter-arrival times of the recorded requests.
+type Replay struct {
+	*BaseExecutor
+	config ReplayConfig
+	et     *lib.ExecutionTuple
+}
+
+// Make sure we implement the lib.Executor interface.
+var _ lib.Executor = &Replay{}
+
+// Init values needed for the execution
+func (r *Replay) Init(_ context.Context) error {
+	// err should always be nil, because Init() won't be called for executors
+	// with no work, as determined by their config's HasWork() method.
+	et, err := r.BaseExecutor.executionState.ExecutionTuple.GetNewExecutionTupleFromValue(r.config.MaxVUs.Int64)
+	r.et = et
+	r.iterSegIndex = lib.NewSegmentedIndex(et)
+
+	return err
+}
+
+// Run executes an iteration at the offset of every timestamp of this
+// execution segment.
+//
+// The iterations are started in the order of the timestamps, so that the
+// exec.scenario.iterationInTest of an iteration is the index of its timestamp,
+// unless several iterations are started at nearly the same time.
+//
+//nolint:funlen
+func (r Replay) Run(parentCtx context.Context, out chan<- metrics.SampleContainer) (err error) {
+	gracefulStop := r.config.GetGracefulStop()
+	duration := r.config.getDuration()
+	preAllocatedVUs := r.config.GetPreAllocatedVUs(r.executionState.ExecutionTuple)
+	maxVUs := r.config.GetMaxVUs(r.executionState.ExecutionTuple)
+	totalIters := r.et.ScaleInt64(int64(len(r.config.Timestamps)))
+
+	// Make sure the log and the progress bar have accurate information
+	r.logger.WithFields(logrus.Fields{
+		"maxVUs": maxVUs, "preAllocatedVUs": preAllocatedVUs, "duration": duration,
+		"iterations": totalIters, "type": r.config.GetType(),
+	}).Debug("Starting executor run...")
+
+	waitOnProgressChannel := make(chan struct{})
+	startTime, maxDurationCtx, regDurationCtx, cancel := getDurationContexts(parentCtx, duration, gracefulStop)
+	defer func() {
+		cancel()
+		<-waitOnProgressChannel
+	}()
+
+	vus := newArrivalRateVUs(r.BaseExecutor, r.config.BaseConfig, preAllocatedVUs, maxVUs, nil)
+	defer vus.stop(cancel)
+	var startedIters uint64
+
+	itersFmt := pb.GetFixedLengthIntFormat(totalIters)
+	progressFn := func() (float64, []string) {
+		spent := time.Since(startTime)
+		currentIters := atomic.LoadUint64(&startedIters)
+		progIters := fmt.Sprintf(itersFmt+"/"+itersFmt+" iters", currentIters, totalIters)
+
+		right := []string{vus.progress(), duration.String(), progIters}
+
+		if spent > duration {
+			return 1, right
+		}
+
+		spentDuration := pb.GetFixedLengthDuration(spent, duration)
+		progDur := fmt.Sprintf("%s/%s", spentDuration, duration)
+		right[1] = progDur
+
+		return math.Min(1, float64(currentIters)/float64(totalIters)), right
+	}
+	r.progress.Modify(pb.WithProgress(progressFn))
+	maxDurationCtx = lib.WithScenarioState(maxDurationCtx, &lib.ScenarioState{
+		Name:       r.config.Name,
+		Executor:   r.config.Type,
+		StartTime:  startTime,
+		ProgressFn: progressFn,
+	})
+
+	go func() {
+		trackProgress(parentCtx, maxDurationCtx, regDurationCtx, &r, progressFn)
+		close(waitOnProgressChannel)
+	}()
+
+	if err = vus.start(maxDurationCtx, preAllocatedVUs); err != nil {
+		return err
+	}
+
+	start, offsets, _ := r.et.GetStripedOffsets()
+	timer := time.NewTimer(time.Hour * 24)
+
+	metricTags := r.getMetricTags(nil)
+	for li, gi := 0, start; gi < int64(len(r.config.Timestamps)); li, gi = li+1, gi+offsets[li%len(offsets)] {
+		t := r.config.getOffset(int(gi)) - time.Since(startTime)
+		timer.Reset(t)
+		select {
+		case <-timer.C:
+			atomic.AddUint64(&startedIters, 1)
+			vus.runIteration(parentCtx, out, metricTags)
+
+		case <-parentCtx.Done():
+			// The scheduling ends with the last timestamp, which is at the end
+			// of the regular duration, so only an interruption stops it early.
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+	"go.k6.io/k6/metrics"
+)
+
+func getTestReplayConfig(offsets ...time.Duration) *ReplayConfig {
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	timestamps := make([]time.Time, 0, len(offsets))
+	for _, offset := range offsets {
+		timestamps = append(timestamps, start.Add(offset))
+	}
+
+	return &ReplayConfig{
+		BaseConfig:      BaseConfig{GracefulStop: types.NullDurationFrom(1 * time.Second)},
+		Timestamps:      timestamps,
+		TimeScale:       null.FloatFrom(1),
+		PreAllocatedVUs: null.IntFrom(5),
+		MaxVUs:          null.IntFrom(5),
+	}
+}
+
+func TestReplayRunCorrectTiming(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		timeScale float64
+		expected  []time.Duration
+	}{
+		{name: "original speed", timeScale: 1, expected: []time.Duration{0, 400, 400, 1000, 1200}},
+		{name: "double speed", timeScale: 2, expected: []time.Duration{0, 200, 200, 500, 600}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu      sync.Mutex
+				started []time.Time
+			)
+			runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+				mu.Lock()
+				started = append(started, time.Now())
+				mu.Unlock()
+				return nil
+			})
+
+			config := getTestReplayConfig(0, 400*time.Millisecond, 400*time.Millisecond, time.Second, 1200*time.Millisecond)
+			config.TimeScale = null.FloatFrom(tc.timeScale)
+
+			test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+			defer test.cancel()
+
+			startTime := time.Now()
+			engineOut := make(chan metrics.SampleContainer, 1000)
+			require.NoError(t, test.executor.Run(test.ctx, engineOut))
+			require.Empty(t, test.logHook.Drain())
+
+			mu.Lock()
+			defer mu.Unlock()
+			require.Len(t, started, len(tc.expected))
+			for i, expected := range tc.expected {
+				assert.InDelta(t, expected*time.Millisecond, started[i].Sub(startTime), float64(50*time.Millisecond))
+			}
+		})
+	}
+}
+
+func TestReplayDroppedIterations(t *testing.T) {
+	t.Parallel()
+
+	runner := simpleRunner(func(_ context.Context, _ *lib.State) error {
+		time.Sleep(500 * time.Millisecond)
+		return nil
+	})
+
+	config := getTestReplayConfig(0, 0, 0, 100*time.Millisecond)
+	config.PreAllocatedVUs = null.IntFrom(2)
+	config.MaxVUs = null.IntFrom(2)
+
+	test := setupExecutorTest(t, "", "", lib.Options{}, runner, config)
+	defer test.cancel()
+
+	engineOut := make(chan metrics.SampleContainer, 1000)
+	require.NoError(t, test.executor.Run(test.ctx, engineOut))
+	logs := test.logHook.Drain()
+	require.Len(t, logs, 1)
+	assert.Contains(t, logs[0].Message, "cannot initialize more")
+	assert.Equal(t, uint64(2), test.state.GetFullIterationCount())
+	assert.Equal(t, float64(2), sumMetricValues(engineOut, metrics.DroppedIterationsName))
+}
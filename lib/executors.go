@@ -65,6 +65,10 @@ type ExecutorConfig interface {
 	GetStartTime() time.Duration
 	GetGracefulStop() time.Duration
 
+	// Returns the names of the scenarios that have to be done before the
+	// executor starts, its start time then being relative to their end.
+	GetDependsOn() []string
+
 	// This is used to validate whether a particular script can run in the cloud
 	// or, in the future, in the native k6 distributed execution. Currently only
 	// the externally-controlled executor should return false.
@@ -76,6 +80,10 @@ type ExecutorConfig interface {
 	//
 	// TODO: use interface{} so plain http requests can be specified?
 	GetExec() string
+	// Return the names of the functions to run once before the executor
+	// starts and once after it's done, if they have been specified.
+	GetSetup() string
+	GetTeardown() string
 	GetTags() map[string]string
 
 	// Calculates the VU requirements in different stages of the executor's
@@ -202,10 +210,131 @@ func (scs ScenarioConfigs) Validate() (errors []error) {
 			errors = append(errors,
 				fmt.Errorf("scenario %s has configuration errors: %s", name, ConcatErrors(execErr, ", ")))
 		}
+		for _, dep := range exec.GetDependsOn() {
+			if _, ok := scs[dep]; !ok {
+				errors = append(errors, fmt.Errorf("scenario %s depends on the unknown scenario %s", name, dep))
+			}
+		}
+	}
+	if cycle := scs.findDependencyCycle(); cycle != nil {
+		errors = append(errors, fmt.Errorf("the scenarios have a circular dependency: %s", strings.Join(cycle, " -> ")))
 	}
 	return errors
 }
 
+// findDependencyCycle returns the names of the scenarios of a circular
+// dependency between them, if there is one, starting and ending with the same.
+func (scs ScenarioConfigs) findDependencyCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make(map[string]int, len(scs))
+
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch states[name] {
+		case visited:
+			return nil
+		case visiting:
+			for i, n := range path {
+				if n == name {
+					return append(append([]string{}, path[i:]...), name)
+				}
+			}
+		}
+
+		states[name] = visiting
+		path = append(path, name)
+		if config, ok := scs[name]; ok {
+			for _, dep := range config.GetDependsOn() {
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		states[name] = visited
+
+		return nil
+	}
+
+	names := make([]string, 0, len(scs))
+	for name := range scs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+// startWindow is the range of time offsets, from the beginning of the test, at
+// which an executor can start.
+type startWindow struct {
+	earliest, latest time.Duration
+}
+
+// getStartWindows returns the range of time offsets at which each executor
+// can start. The range of the executors without dependencies is their start
+// time, while the others can start as soon as all of their dependencies can
+// have ended, as they may end early, and at the latest once all of them had
+// the time to run fully, their start time added in both cases.
+func (scs ScenarioConfigs) getStartWindows(et *ExecutionTuple) map[string]startWindow {
+	windows := make(map[string]startWindow, len(scs))
+
+	var getWindow func(name string, seen int) startWindow
+	getWindow = func(name string, seen int) startWindow {
+		if w, ok := windows[name]; ok {
+			return w
+		}
+
+		config := scs[name]
+		var w startWindow
+		// The seen counter guards against circular dependencies, which are
+		// reported by Validate().
+		if seen <= len(scs) {
+			for _, dep := range config.GetDependsOn() {
+				if _, ok := scs[dep]; !ok {
+					continue
+				}
+				depWindow := getWindow(dep, seen+1)
+				depEnd, _ := GetEndOffset(scs[dep].GetExecutionRequirements(et))
+				if depWindow.earliest > w.earliest {
+					w.earliest = depWindow.earliest
+				}
+				if depWindow.latest+depEnd > w.latest {
+					w.latest = depWindow.latest + depEnd
+				}
+			}
+		}
+		w.earliest += config.GetStartTime()
+		w.latest += config.GetStartTime()
+
+		windows[name] = w
+		return w
+	}
+
+	for name := range scs {
+		getWindow(name, 0)
+	}
+
+	return windows
+}
+
+// GetStartOffsets returns the earliest and the latest time offsets at which
+// the scenario can start, which only differ if it depends on other scenarios.
+func (scs ScenarioConfigs) GetStartOffsets(et *ExecutionTuple, name string) (earliest, latest time.Duration) {
+	w := scs.getStartWindows(et)[name]
+	return w.earliest, w.latest
+}
+
 // GetSortedConfigs returns a slice with the executor configurations,
 // sorted in a consistent and predictable manner. It is useful when we want or
 // have to avoid using maps with string keys (and tons of string lookups in
@@ -239,9 +368,9 @@ func (scs ScenarioConfigs) GetSortedConfigs() []ExecutorConfig {
 }
 
 // GetFullExecutionRequirements combines the execution requirements from all of
-// the configured executors. It takes into account their start times and their
-// individual VU requirements and calculates the total VU requirements for each
-// moment in the test execution.
+// the configured executors. It takes into account their start times, their
+// dependencies and their individual VU requirements and calculates the total
+// VU requirements for each moment in the test execution.
 func (scs ScenarioConfigs) GetFullExecutionRequirements(et *ExecutionTuple) []ExecutionStep {
 	sortedConfigs := scs.GetSortedConfigs()
 
@@ -253,11 +382,22 @@ func (scs ScenarioConfigs) GetFullExecutionRequirements(et *ExecutionTuple) []Ex
 		configID int
 	}
 	trackedSteps := []trackedStep{}
+	startWindows := scs.getStartWindows(et)
 	for configID, config := range sortedConfigs { // orderly iteration over a slice
-		configStartTime := config.GetStartTime()
+		window := startWindows[config.GetName()]
 		configSteps := config.GetExecutionRequirements(et)
+		if window.earliest != window.latest {
+			// The executor can run at any time between the earliest start and
+			// the latest end, so it needs its maximum VUs for all of it.
+			end, _ := GetEndOffset(configSteps)
+			planned, possible := GetMaxPlannedVUs(configSteps), GetMaxPossibleVUs(configSteps)
+			configSteps = []ExecutionStep{
+				{TimeOffset: 0, PlannedVUs: planned, MaxUnplannedVUs: possible - planned},
+				{TimeOffset: window.latest - window.earliest + end},
+			}
+		}
 		for _, cs := range configSteps {
-			cs.TimeOffset += configStartTime // add the executor start time to the step time offset
+			cs.TimeOffset += window.earliest // add the executor start time to the step time offset
 			trackedSteps = append(trackedSteps, trackedStep{cs, configID})
 		}
 	}
@@ -0,0 +1,173 @@
+package lib
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/types"
+)
+
+// Faults are the faults injected in the outgoing traffic of the VUs of a
+// scenario, by their dialers, so the resilience of the clients and of the edge
+// can be tested along the load.
+type Faults struct {
+	// Latency is added to the responses, before they are read.
+	Latency *LatencyFault `json:"latency,omitempty"`
+	// Bandwidth throttles the connections.
+	Bandwidth *BandwidthFault `json:"bandwidth,omitempty"`
+	// Resets reset the connections, before the responses are read.
+	Resets *Fault `json:"resets,omitempty"`
+	// DNSFailures fail the lookups of the hosts of the new connections.
+	DNSFailures *Fault `json:"dnsFailures,omitempty"`
+	// NetworkProfile is the name of the client network to simulate, e.g.
+	// "Slow 3G", whose latency and bandwidth are injected, unless the latency
+	// and the bandwidth faults are set.
+	NetworkProfile string `json:"networkProfile,omitempty"`
+}
+
+// Fault is a fault which is injected at its rate, the probability it's
+// injected with, which is 1 by default.
+type Fault struct {
+	Rate null.Float `json:"rate"`
+}
+
+// LatencyFault is a fault adding latency to the responses.
+type LatencyFault struct {
+	Fault
+	Duration types.Duration `json:"duration"`
+}
+
+// BandwidthFault is a fault throttling the connections to a bandwidth, in
+// both directions, unless the bandwidth of the uploads is set.
+type BandwidthFault struct {
+	Fault
+	BytesPerSecond       int64 `json:"bytesPerSecond"`
+	UploadBytesPerSecond int64 `json:"uploadBytesPerSecond,omitempty"`
+}
+
+// NetworkProfile is the latency and the bandwidth of a client network.
+type NetworkProfile struct {
+	Latency                time.Duration
+	DownloadBytesPerSecond int64
+	UploadBytesPerSecond   int64
+}
+
+// GetNetworkProfiles returns the client networks the faults can simulate, by
+// name: the 3G ones of the browser module, and the 4G one of WebPageTest.
+func GetNetworkProfiles() map[string]NetworkProfile {
+	return map[string]NetworkProfile{
+		"Slow 3G": {
+			// 500 Kb/s with a 20% bandwidth loss, in both directions
+			Latency:                2000 * time.Millisecond,
+			DownloadBytesPerSecond: 50000,
+			UploadBytesPerSecond:   50000,
+		},
+		"Fast 3G": {
+			// 1.6 Mb/s down and 750 Kb/s up, with a 10% bandwidth loss
+			Latency:                562500 * time.Microsecond,
+			DownloadBytesPerSecond: 180000,
+			UploadBytesPerSecond:   84375,
+		},
+		"4G": {
+			// 9 Mb/s in both directions
+			Latency:                170 * time.Millisecond,
+			DownloadBytesPerSecond: 1125000,
+			UploadBytesPerSecond:   1125000,
+		},
+	}
+}
+
+// Injected returns whether the fault is injected this time, according to its
+// rate.
+func (f Fault) Injected() bool {
+	if !f.Rate.Valid {
+		return true
+	}
+	return rand.Float64() < f.Rate.Float64 //nolint:gosec
+}
+
+func (f Fault) validate(name string) error {
+	if f.Rate.Valid && (f.Rate.Float64 < 0 || f.Rate.Float64 > 1) {
+		return fmt.Errorf("the rate of the %s faults should be between 0 and 1, but it is %v", name, f.Rate.Float64)
+	}
+	return nil
+}
+
+// InjectedLatency returns the latency injected before a response this time,
+// if any, the one of the latency fault or of the network profile.
+func (f *Faults) InjectedLatency() (time.Duration, bool) {
+	if f.Latency != nil {
+		return time.Duration(f.Latency.Duration), f.Latency.Injected()
+	}
+	profile, ok := GetNetworkProfiles()[f.NetworkProfile]
+	return profile.Latency, ok
+}
+
+// InjectedBandwidth returns the bandwidths, in bytes per second, a new
+// connection is throttled to in each direction, if it is this time, the ones
+// of the bandwidth fault or of the network profile.
+func (f *Faults) InjectedBandwidth() (download, upload int64, ok bool) {
+	if f.Bandwidth != nil {
+		if !f.Bandwidth.Injected() {
+			return 0, 0, false
+		}
+		upload = f.Bandwidth.UploadBytesPerSecond
+		if upload == 0 {
+			upload = f.Bandwidth.BytesPerSecond
+		}
+		return f.Bandwidth.BytesPerSecond, upload, true
+	}
+	profile, ok := GetNetworkProfiles()[f.NetworkProfile]
+	return profile.DownloadBytesPerSecond, profile.UploadBytesPerSecond, ok
+}
+
+// Validate checks the rates and the parameters of the faults.
+func (f *Faults) Validate() (errors []error) {
+	if f.Latency != nil {
+		if err := f.Latency.validate("latency"); err != nil {
+			errors = append(errors, err)
+		}
+		if f.Latency.Duration <= 0 {
+			errors = append(errors, fmt.Errorf("the duration of the latency faults should be positive"))
+		}
+	}
+	if f.Bandwidth != nil {
+		if err := f.Bandwidth.validate("bandwidth"); err != nil {
+			errors = append(errors, err)
+		}
+		if f.Bandwidth.BytesPerSecond <= 0 {
+			errors = append(errors, fmt.Errorf("the bytesPerSecond of the bandwidth faults should be positive"))
+		}
+		if f.Bandwidth.UploadBytesPerSecond < 0 {
+			errors = append(errors, fmt.Errorf("the uploadBytesPerSecond of the bandwidth faults can't be negative"))
+		}
+	}
+	if f.Resets != nil {
+		if err := f.Resets.validate("resets"); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	if f.DNSFailures != nil {
+		if err := f.DNSFailures.validate("dnsFailures"); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	if f.NetworkProfile != "" {
+		profiles := GetNetworkProfiles()
+		if _, ok := profiles[f.NetworkProfile]; !ok {
+			names := make([]string, 0, len(profiles))
+			for name := range profiles {
+				names = append(names, fmt.Sprintf("%q", name))
+			}
+			sort.Strings(names)
+			errors = append(errors, fmt.Errorf("unknown network profile %q, it should be one of %s",
+				f.NetworkProfile, strings.Join(names, ", ")))
+		}
+	}
+	return errors
+}
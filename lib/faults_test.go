@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/types"
+)
+
+func TestFaultsValidate(t *testing.T) {
+	t.Parallel()
+
+	faults := &Faults{
+		Latency:        &LatencyFault{Fault: Fault{Rate: null.FloatFrom(1.5)}},
+		Bandwidth:      &BandwidthFault{UploadBytesPerSecond: -1},
+		DNSFailures:    &Fault{Rate: null.FloatFrom(0.1)},
+		NetworkProfile: "5G",
+	}
+	errs := faults.Validate()
+	require.Len(t, errs, 5)
+	assert.EqualError(t, errs[0], "the rate of the latency faults should be between 0 and 1, but it is 1.5")
+	assert.EqualError(t, errs[1], "the duration of the latency faults should be positive")
+	assert.EqualError(t, errs[2], "the bytesPerSecond of the bandwidth faults should be positive")
+	assert.EqualError(t, errs[3], "the uploadBytesPerSecond of the bandwidth faults can't be negative")
+	assert.EqualError(t, errs[4], `unknown network profile "5G", it should be one of "4G", "Fast 3G", "Slow 3G"`)
+}
+
+func TestFaultsNetworkProfile(t *testing.T) {
+	t.Parallel()
+
+	faults := &Faults{NetworkProfile: "Fast 3G"}
+	require.Empty(t, faults.Validate())
+	latency, ok := faults.InjectedLatency()
+	assert.True(t, ok)
+	assert.Equal(t, 562500*time.Microsecond, latency)
+	download, upload, ok := faults.InjectedBandwidth()
+	assert.True(t, ok)
+	assert.Equal(t, int64(180000), download)
+	assert.Equal(t, int64(84375), upload)
+
+	// the faults take precedence over the profile
+	faults.Latency = &LatencyFault{Duration: types.Duration(time.Second)}
+	faults.Bandwidth = &BandwidthFault{BytesPerSecond: 1000}
+	latency, ok = faults.InjectedLatency()
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, latency)
+	download, upload, ok = faults.InjectedBandwidth()
+	assert.True(t, ok)
+	assert.Equal(t, int64(1000), download)
+	assert.Equal(t, int64(1000), upload)
+
+	_, ok = (&Faults{}).InjectedLatency()
+	assert.False(t, ok)
+	_, _, ok = (&Faults{}).InjectedBandwidth()
+	assert.False(t, ok)
+}
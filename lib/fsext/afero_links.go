@@ -24,11 +24,22 @@ func NewReadOnlyFs(fs Fs) Fs {
 	return afero.NewReadOnlyFs(fs)
 }
 
+// NewBasePathFs returns a Fs wrapping the provided one and restricting all operations to the base path.
+func NewBasePathFs(fs Fs, path string) Fs {
+	return afero.NewBasePathFs(fs, path)
+}
+
 // WriteFile writes the provided data to the provided fs in the provided filename
 func WriteFile(fs Fs, filename string, data []byte, perm fs.FileMode) error {
 	return afero.WriteFile(fs, filename, data, perm)
 }
 
+// Glob returns the names of all files matching pattern, as defined by
+// [path/filepath.Match], or nil if there is no matching file
+func Glob(fs Fs, pattern string) ([]string, error) {
+	return afero.Glob(fs, pattern)
+}
+
 // ReadFile reads the whole file from the filesystem
 func ReadFile(fs Fs, filename string) ([]byte, error) {
 	return afero.ReadFile(fs, filename)
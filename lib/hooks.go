@@ -0,0 +1,153 @@
+package lib
+
+import (
+	"go.k6.io/k6/metrics"
+)
+
+// Hooks are the lifecycle hooks a VU's script registers, with the
+// k6/experimental/hooks module, which are invoked around each of its
+// iterations and each of its HTTP and gRPC requests.
+//
+// They are registered in the init context and invoked in the VU context, both
+// on the VU's goroutine, so they aren't safe for concurrent use.
+type Hooks struct {
+	iterationStart []func() error
+	iterationEnd   []func(iterErr error) error
+	request        []func(*HookedRequest) error
+}
+
+// OnIterationStart registers a hook invoked at the start of each iteration.
+func (h *Hooks) OnIterationStart(fn func() error) {
+	h.iterationStart = append(h.iterationStart, fn)
+}
+
+// OnIterationEnd registers a hook invoked at the end of each iteration, with
+// the error of the iteration, if any.
+func (h *Hooks) OnIterationEnd(fn func(iterErr error) error) {
+	h.iterationEnd = append(h.iterationEnd, fn)
+}
+
+// OnRequest registers a hook invoked before each request is sent.
+func (h *Hooks) OnRequest(fn func(*HookedRequest) error) {
+	h.request = append(h.request, fn)
+}
+
+// IterationStart invokes the iteration start hooks, in their registration
+// order, until one of them fails.
+func (h *Hooks) IterationStart() error {
+	if h == nil {
+		return nil
+	}
+	for _, fn := range h.iterationStart {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IterationEnd invokes the iteration end hooks, in their registration order,
+// until one of them fails.
+func (h *Hooks) IterationEnd(iterErr error) error {
+	if h == nil {
+		return nil
+	}
+	for _, fn := range h.iterationEnd {
+		if err := fn(iterErr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasIterationEndHooks returns whether there are iteration end hooks, so the
+// event loop isn't started again for nothing.
+func (h *Hooks) HasIterationEndHooks() bool {
+	return h != nil && len(h.iterationEnd) > 0
+}
+
+// HasRequestHooks returns whether there are request hooks, so the requests
+// aren't converted for nothing.
+func (h *Hooks) HasRequestHooks() bool {
+	return h != nil && len(h.request) > 0
+}
+
+// Request invokes the request hooks with the request, in their registration
+// order, until one of them fails.
+func (h *Hooks) Request(req *HookedRequest) error {
+	if h == nil {
+		return nil
+	}
+	for _, fn := range h.request {
+		if err := fn(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HookedRequest is the request the request hooks are invoked with, before it
+// is sent. The hooks can change its headers, or its gRPC metadata, and its
+// tags.
+type HookedRequest struct {
+	// Protocol is either "http" or "grpc".
+	Protocol string            `js:"protocol"`
+	Method   string            `js:"method"`
+	URL      string            `js:"url"`
+	Headers  map[string]string `js:"headers"`
+	Tags     map[string]string `js:"tags"`
+
+	headers map[string]string
+	tags    map[string]string
+}
+
+// NewHookedRequest returns the request with the headers, of which only the
+// first values are kept, and the tags.
+func NewHookedRequest(protocol, method, url string, headers map[string][]string, tags *metrics.TagSet) *HookedRequest {
+	r := &HookedRequest{
+		Protocol: protocol,
+		Method:   method,
+		URL:      url,
+		Headers:  make(map[string]string, len(headers)),
+		Tags:     tags.Map(),
+		headers:  make(map[string]string, len(headers)),
+		tags:     tags.Map(),
+	}
+	for k, v := range headers {
+		if len(v) > 0 {
+			r.Headers[k], r.headers[k] = v[0], v[0]
+		}
+	}
+	return r
+}
+
+// HeaderChanges returns the headers the hooks set, and the ones they removed.
+func (r *HookedRequest) HeaderChanges() (set map[string]string, removed []string) {
+	set = make(map[string]string)
+	for k, v := range r.Headers {
+		if original, ok := r.headers[k]; !ok || original != v {
+			set[k] = v
+		}
+	}
+	for k := range r.headers {
+		if _, ok := r.Headers[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	return set, removed
+}
+
+// ApplyTags applies the tags the hooks set and removed to the tags and
+// metadata of the request.
+func (r *HookedRequest) ApplyTags(tm *metrics.TagsAndMeta) {
+	for k, v := range r.Tags {
+		if original, ok := r.tags[k]; !ok || original != v {
+			tm.SetTag(k, v)
+		}
+	}
+	for k := range r.tags {
+		if _, ok := r.Tags[k]; !ok {
+			tm.DeleteTag(k)
+		}
+	}
+}
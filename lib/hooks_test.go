@@ -0,0 +1,78 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/metrics"
+)
+
+func TestHooks(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	hooks := &Hooks{}
+	hooks.OnIterationStart(func() error {
+		calls = append(calls, "start")
+		return nil
+	})
+	hooks.OnIterationEnd(func(iterErr error) error {
+		calls = append(calls, "end: "+iterErr.Error())
+		return errors.New("end failed")
+	})
+	hooks.OnIterationEnd(func(error) error {
+		calls = append(calls, "not called")
+		return nil
+	})
+
+	require.NoError(t, hooks.IterationStart())
+	assert.EqualError(t, hooks.IterationEnd(errors.New("iteration failed")), "end failed")
+	assert.Equal(t, []string{"start", "end: iteration failed"}, calls)
+	assert.False(t, hooks.HasRequestHooks())
+
+	var nilHooks *Hooks
+	assert.NoError(t, nilHooks.IterationStart())
+	assert.NoError(t, nilHooks.Request(&HookedRequest{}))
+	assert.False(t, nilHooks.HasRequestHooks())
+}
+
+func TestHookedRequest(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	tm := metrics.TagsAndMeta{Tags: registry.RootTagSet().WithTagsFromMap(map[string]string{
+		"debug": "true",
+		"group": "",
+		"name":  "req",
+	})}
+
+	hooks := &Hooks{}
+	hooks.OnRequest(func(req *HookedRequest) error {
+		assert.Equal(t, "http", req.Protocol)
+		req.Headers["Authorization"] = "Bearer token"
+		req.Headers["Accept"] = "text/plain"
+		delete(req.Headers, "X-Debug")
+		req.Tags["name"] = "hooked"
+		req.Tags["hooked"] = "yes"
+		delete(req.Tags, "debug")
+		return nil
+	})
+	require.True(t, hooks.HasRequestHooks())
+
+	req := NewHookedRequest("http", "GET", "http://example.com", map[string][]string{
+		"Accept":     {"*/*"},
+		"User-Agent": {"k6"},
+		"X-Debug":    {"1", "2"},
+	}, tm.Tags)
+	require.NoError(t, hooks.Request(req))
+
+	set, removed := req.HeaderChanges()
+	assert.Equal(t, map[string]string{"Authorization": "Bearer token", "Accept": "text/plain"}, set)
+	assert.Equal(t, []string{"X-Debug"}, removed)
+
+	req.ApplyTags(&tm)
+	assert.Equal(t, map[string]string{"group": "", "name": "hooked", "hooked": "yes"}, tm.Tags.Map())
+}
@@ -205,6 +205,55 @@ type Check struct {
 	// Counters for how many times this check has passed and failed respectively.
 	Passes int64 `json:"passes"`
 	Fails  int64 `json:"fails"`
+
+	// The diagnostics of the first failures of the check, if it's an assertion
+	// which reports them, up to MaxCheckFailures.
+	Failures []CheckFailure `json:"failures,omitempty"`
+}
+
+// MaxCheckFailures is the maximum number of failures kept for each check.
+const MaxCheckFailures = 5
+
+// The metadata keys of the diagnostics of a failed assertion, on its sample of
+// the checks metric.
+const (
+	CheckMetadataMessage  = "check_message"
+	CheckMetadataExpected = "check_expected"
+	CheckMetadataActual   = "check_actual"
+	CheckMetadataPayload  = "check_payload"
+)
+
+// CheckFailure holds the diagnostics of a failed assertion.
+type CheckFailure struct {
+	Message  string `json:"message"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	// Payload is an excerpt of the payload the assertion was made on, e.g.
+	// the body of a response.
+	Payload string `json:"payload,omitempty"`
+}
+
+// NewCheckFailure returns the diagnostics of a failed assertion from the
+// metadata of its sample, and false if it doesn't have any.
+func NewCheckFailure(metadata map[string]string) (CheckFailure, bool) {
+	message, ok := metadata[CheckMetadataMessage]
+	if !ok {
+		return CheckFailure{}, false
+	}
+	return CheckFailure{
+		Message:  message,
+		Expected: metadata[CheckMetadataExpected],
+		Actual:   metadata[CheckMetadataActual],
+		Payload:  metadata[CheckMetadataPayload],
+	}, true
+}
+
+// AddFailure records the diagnostics of a failure of the check, unless it
+// already holds MaxCheckFailures of them.
+func (c *Check) AddFailure(f CheckFailure) {
+	if len(c.Failures) < MaxCheckFailures {
+		c.Failures = append(c.Failures, f)
+	}
 }
 
 // NewCheck creates a new check with the given name and parent group. The group may not be nil.
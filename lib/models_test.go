@@ -73,3 +73,27 @@ func TestDataRaces(t *testing.T) {
 		assert.Equal(t, group1, group2, "Groups are the same")
 	})
 }
+
+func TestCheckFailures(t *testing.T) {
+	t.Parallel()
+
+	_, ok := NewCheckFailure(map[string]string{"trace_id": "abc"})
+	assert.False(t, ok)
+
+	failure, ok := NewCheckFailure(map[string]string{
+		CheckMetadataMessage:  "expected value to be 200",
+		CheckMetadataExpected: "200",
+		CheckMetadataActual:   "500",
+	})
+	assert.True(t, ok)
+	assert.Equal(t, CheckFailure{Message: "expected value to be 200", Expected: "200", Actual: "500"}, failure)
+
+	group, err := NewGroup("", nil)
+	assert.NoError(t, err)
+	check, err := group.Check("status is 200")
+	assert.NoError(t, err)
+	for i := 0; i < MaxCheckFailures+2; i++ {
+		check.AddFailure(failure)
+	}
+	assert.Len(t, check.Failures, MaxCheckFailures)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -23,6 +25,14 @@ type Dialer struct {
 	BlockedHostnames *types.HostnameTrie
 	Hosts            *types.Hosts
 
+	// overrides are the hosts set while the test is running, they take
+	// precedence over the Hosts.
+	overridesMu sync.RWMutex
+	overrides   map[string]types.Host
+
+	// faults are the faults injected in the connections, see SetFaults.
+	faults atomic.Pointer[lib.Faults]
+
 	BytesRead    int64
 	BytesWritten int64
 }
@@ -57,6 +67,9 @@ func (b BlockedHostError) Error() string {
 
 // DialContext wraps the net.Dialer.DialContext and handles the k6 specifics
 func (d *Dialer) DialContext(ctx context.Context, proto, addr string) (net.Conn, error) {
+	if err := d.dnsFailure(addr); err != nil {
+		return nil, &net.OpError{Op: "dial", Net: proto, Err: err}
+	}
 	dialAddr, err := d.getDialAddr(addr)
 	if err != nil {
 		return nil, err
@@ -65,7 +78,10 @@ func (d *Dialer) DialContext(ctx context.Context, proto, addr string) (net.Conn,
 	if err != nil {
 		return nil, err
 	}
-	conn = &Conn{conn, &d.BytesRead, &d.BytesWritten}
+	if d.faults.Load() != nil {
+		conn = newFaultConn(conn, &d.faults)
+	}
+	conn = &Conn{Conn: conn, BytesRead: &d.BytesRead, BytesWritten: &d.BytesWritten}
 	return conn, err
 }
 
@@ -161,6 +177,10 @@ func (d *Dialer) findRemote(addr string) (*types.Host, error) {
 		}
 	}
 
+	if remote, ok := d.getHostOverride(host, port); ok {
+		return remote, nil
+	}
+
 	if d.Hosts != nil {
 		remote, e := d.getConfiguredHost(addr, host, port)
 		if e != nil || remote != nil {
@@ -184,6 +204,47 @@ func (d *Dialer) findRemote(addr string) (*types.Host, error) {
 	return types.NewHost(ip, port)
 }
 
+// SetHostOverride makes the connections to the hostname go to the remote host,
+// instead of the address it resolves to or the configured one in the Hosts.
+// The port of the connections is kept if the remote host doesn't have one.
+func (d *Dialer) SetHostOverride(hostname string, remote types.Host) {
+	d.overridesMu.Lock()
+	defer d.overridesMu.Unlock()
+
+	if d.overrides == nil {
+		d.overrides = make(map[string]types.Host)
+	}
+	d.overrides[strings.ToLower(hostname)] = remote
+}
+
+// RemoveHostOverride removes the override of the hostname, if any, and
+// returns whether it existed.
+func (d *Dialer) RemoveHostOverride(hostname string) bool {
+	d.overridesMu.Lock()
+	defer d.overridesMu.Unlock()
+
+	hostname = strings.ToLower(hostname)
+	_, ok := d.overrides[hostname]
+	delete(d.overrides, hostname)
+	return ok
+}
+
+func (d *Dialer) getHostOverride(host, port string) (*types.Host, bool) {
+	d.overridesMu.RLock()
+	remote, ok := d.overrides[strings.ToLower(host)]
+	d.overridesMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	if remote.Port == 0 && port != "" {
+		if p, err := strconv.Atoi(port); err == nil {
+			remote.Port = p
+		}
+	}
+	return &remote, true
+}
+
 func (d *Dialer) getConfiguredHost(addr, host, port string) (*types.Host, error) {
 	if remote := d.Hosts.Match(addr); remote != nil {
 		return remote, nil
@@ -243,6 +304,41 @@ type Conn struct {
 	net.Conn
 
 	BytesRead, BytesWritten *int64
+
+	closeMu    sync.Mutex
+	closed     bool
+	closeHooks []func()
+}
+
+// OnClose registers a function called once the connection is closed, right
+// away if it's already closed.
+func (c *Conn) OnClose(hook func()) {
+	c.closeMu.Lock()
+	if !c.closed {
+		c.closeHooks = append(c.closeHooks, hook)
+		c.closeMu.Unlock()
+		return
+	}
+	c.closeMu.Unlock()
+
+	hook()
+}
+
+// Close closes the connection, and calls the functions registered with
+// [Conn.OnClose] the first time.
+func (c *Conn) Close() error {
+	err := c.Conn.Close()
+
+	c.closeMu.Lock()
+	hooks := c.closeHooks
+	c.closed, c.closeHooks = true, nil
+	c.closeMu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+
+	return err
 }
 
 func (c *Conn) Read(b []byte) (int, error) {
@@ -4,6 +4,7 @@ import (
 	"net"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"go.k6.io/k6/lib"
@@ -113,6 +114,47 @@ func TestDialerAddrBlockHostnamesStar(t *testing.T) {
 	}
 }
 
+func TestDialerHostOverrides(t *testing.T) {
+	t.Parallel()
+	dialer := NewDialer(net.Dialer{}, newResolver())
+	hosts, err := types.NewHosts(map[string]types.Host{
+		"example.com": {IP: net.ParseIP("3.4.5.6")},
+	})
+	require.NoError(t, err)
+	dialer.Hosts = hosts
+	ipNet, err := lib.ParseCIDR("8.9.10.0/24")
+	require.NoError(t, err)
+	dialer.Blacklist = []*lib.IPNet{ipNet}
+
+	dialer.SetHostOverride("Example.com", types.Host{IP: net.ParseIP("5.6.7.8")})
+	dialer.SetHostOverride("example-resolver.com", types.Host{IP: net.ParseIP("5.6.7.8"), Port: 8080})
+	dialer.SetHostOverride("blacklisted.com", types.Host{IP: net.ParseIP("8.9.10.11")})
+
+	testCases := []struct {
+		address, expAddress, expErr string
+	}{
+		{"example.com:80", "5.6.7.8:80", ""},
+		{"EXAMPLE.COM:443", "5.6.7.8:443", ""},
+		{"example-resolver.com:80", "5.6.7.8:8080", ""},
+		{"blacklisted.com:80", "", "IP (8.9.10.11) is in a blacklisted range (8.9.10.0/24)"},
+	}
+	for _, tc := range testCases {
+		addr, err := dialer.getDialAddr(tc.address)
+		if tc.expErr != "" {
+			require.EqualError(t, err, tc.expErr)
+		} else {
+			require.NoError(t, err)
+			require.Equal(t, tc.expAddress, addr)
+		}
+	}
+
+	assert.True(t, dialer.RemoveHostOverride("example.com"))
+	assert.False(t, dialer.RemoveHostOverride("example.com"))
+	addr, err := dialer.getDialAddr("example.com:80")
+	require.NoError(t, err)
+	assert.Equal(t, "3.4.5.6:80", addr)
+}
+
 // Benchmarks /etc/hosts like hostname mapping
 func BenchmarkDialerHosts(b *testing.B) {
 	hosts, err := types.NewHosts(map[string]types.Host{
@@ -148,3 +190,27 @@ func newResolver() *mockresolver.MockResolver {
 		},
 	)
 }
+
+func TestConnOnClose(t *testing.T) {
+	t.Parallel()
+
+	client, server := net.Pipe()
+	defer func() { _ = server.Close() }()
+
+	var bytesRead, bytesWritten int64
+	conn := &Conn{Conn: client, BytesRead: &bytesRead, BytesWritten: &bytesWritten}
+
+	calls := 0
+	conn.OnClose(func() { calls++ })
+
+	require.NoError(t, conn.Close())
+	assert.Equal(t, 1, calls)
+
+	// Closing again doesn't call the hooks again, while the hooks registered
+	// once closed are called right away.
+	_ = conn.Close()
+	assert.Equal(t, 1, calls)
+
+	conn.OnClose(func() { calls++ })
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,122 @@
+package netext
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"go.k6.io/k6/lib"
+)
+
+// SetFaults sets the faults injected in the connections of the dialer, from
+// then on, e.g. the ones of the scenario the VU is activated for.
+func (d *Dialer) SetFaults(faults *lib.Faults) {
+	d.faults.Store(faults)
+}
+
+// dnsFailure returns the failure of the lookup of the host, if one is
+// injected.
+func (d *Dialer) dnsFailure(addr string) error {
+	faults := d.faults.Load()
+	if faults == nil || faults.DNSFailures == nil {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil || !faults.DNSFailures.Injected() {
+		return nil
+	}
+	return &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+// faultConn is a connection whose traffic the faults of its dialer are
+// injected in. The latency and the resets are injected before the responses,
+// i.e. the first data read after writes, and the connection is throttled if
+// the bandwidth fault was injected, or a network profile simulated, when it
+// was dialed.
+type faultConn struct {
+	net.Conn
+
+	faults       *atomic.Pointer[lib.Faults]
+	readLimiter  *rate.Limiter
+	writeLimiter *rate.Limiter
+	written      atomic.Bool
+}
+
+func newFaultConn(conn net.Conn, faults *atomic.Pointer[lib.Faults]) net.Conn {
+	c := &faultConn{Conn: conn, faults: faults}
+	if f := faults.Load(); f != nil {
+		if download, upload, ok := f.InjectedBandwidth(); ok {
+			c.readLimiter = rate.NewLimiter(rate.Limit(download), int(download))
+			c.writeLimiter = rate.NewLimiter(rate.Limit(upload), int(upload))
+		}
+	}
+	return c
+}
+
+func (c *faultConn) Read(b []byte) (int, error) {
+	if c.readLimiter != nil && len(b) > c.readLimiter.Burst() {
+		b = b[:c.readLimiter.Burst()]
+	}
+	n, err := c.Conn.Read(b)
+	if n > 0 && c.written.Swap(false) {
+		// the reads can be blocked before the writes, so the faults are
+		// injected once the response is received
+		if ferr := c.beforeResponse(); ferr != nil {
+			return 0, ferr
+		}
+	}
+	if n > 0 && c.readLimiter != nil {
+		_ = c.readLimiter.WaitN(context.Background(), n)
+	}
+	return n, err
+}
+
+func (c *faultConn) Write(b []byte) (int, error) {
+	c.written.Store(true)
+	if c.writeLimiter == nil {
+		return c.Conn.Write(b)
+	}
+
+	var written int
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > c.writeLimiter.Burst() {
+			chunk = chunk[:c.writeLimiter.Burst()]
+		}
+		_ = c.writeLimiter.WaitN(context.Background(), len(chunk))
+		n, err := c.Conn.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[n:]
+	}
+	return written, nil
+}
+
+// beforeResponse injects the reset or the latency before a response.
+func (c *faultConn) beforeResponse() error {
+	faults := c.faults.Load()
+	if faults == nil {
+		return nil
+	}
+	if faults.Resets != nil && faults.Resets.Injected() {
+		_ = c.Conn.Close()
+		return &net.OpError{
+			Op:     "read",
+			Net:    c.RemoteAddr().Network(),
+			Source: c.LocalAddr(),
+			Addr:   c.RemoteAddr(),
+			Err:    os.NewSyscallError("read", syscall.ECONNRESET),
+		}
+	}
+	if latency, ok := faults.InjectedLatency(); ok {
+		time.Sleep(latency)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package netext
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
+)
+
+// newEchoServer returns the address of a TCP server echoing what it reads.
+func newEchoServer(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestDialerFaults(t *testing.T) {
+	t.Parallel()
+
+	addr := newEchoServer(t)
+	always := lib.Fault{Rate: null.FloatFrom(1)}
+	never := lib.Fault{Rate: null.FloatFrom(0)}
+
+	t.Run("dns failures", func(t *testing.T) {
+		t.Parallel()
+
+		dialer := NewDialer(net.Dialer{}, newResolver())
+		dialer.SetFaults(&lib.Faults{DNSFailures: &always})
+		_, err := dialer.DialContext(context.Background(), "tcp", "example-resolver.com:80")
+		var dnsErr *net.DNSError
+		require.ErrorAs(t, err, &dnsErr)
+		assert.Equal(t, "example-resolver.com", dnsErr.Name)
+		assert.True(t, dnsErr.IsNotFound)
+
+		// the IPs aren't looked up
+		conn, err := dialer.DialContext(context.Background(), "tcp", addr)
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+	})
+
+	t.Run("resets", func(t *testing.T) {
+		t.Parallel()
+
+		dialer := NewDialer(net.Dialer{}, newResolver())
+		dialer.SetFaults(&lib.Faults{Resets: &never})
+		conn, err := dialer.DialContext(context.Background(), "tcp", addr)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		buf := make([]byte, 4)
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+		_, err = io.ReadFull(conn, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "ping", string(buf))
+
+		// the faults of the dialer apply to its open connections
+		dialer.SetFaults(&lib.Faults{Resets: &always})
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+		_, err = conn.Read(buf)
+		assert.True(t, errors.Is(err, syscall.ECONNRESET), err)
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		t.Parallel()
+
+		dialer := NewDialer(net.Dialer{}, newResolver())
+		latency := 100 * time.Millisecond
+		dialer.SetFaults(&lib.Faults{Latency: &lib.LatencyFault{Duration: types.Duration(latency)}})
+		conn, err := dialer.DialContext(context.Background(), "tcp", addr)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		start := time.Now()
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+		_, err = io.ReadFull(conn, make([]byte, 4))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), latency)
+	})
+
+	t.Run("bandwidth", func(t *testing.T) {
+		t.Parallel()
+
+		dialer := NewDialer(net.Dialer{}, newResolver())
+		dialer.SetFaults(&lib.Faults{Bandwidth: &lib.BandwidthFault{BytesPerSecond: 1000}})
+		conn, err := dialer.DialContext(context.Background(), "tcp", addr)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		// the first 1000 bytes are the burst, the writes and the reads of the
+		// next 500 take half a second each
+		start := time.Now()
+		data := make([]byte, 1500)
+		go func() { _, _ = conn.Write(data) }()
+		_, err = io.ReadFull(conn, make([]byte, len(data)))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("network profile", func(t *testing.T) {
+		t.Parallel()
+
+		dialer := NewDialer(net.Dialer{}, newResolver())
+		dialer.SetFaults(&lib.Faults{NetworkProfile: "4G"})
+		conn, err := dialer.DialContext(context.Background(), "tcp", addr)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		start := time.Now()
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+		_, err = io.ReadFull(conn, make([]byte, 4))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), lib.GetNetworkProfiles()["4G"].Latency)
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -45,6 +46,10 @@ type InvokeResponse struct {
 	Headers  map[string][]string
 	Trailers map[string][]string
 	Status   codes.Code
+
+	// Raw is the response message, for its fields to be extracted without
+	// converting all of it.
+	Raw protoreflect.Message `js:"-"`
 }
 
 // StreamRequest represents a gRPC stream request.
@@ -172,6 +177,7 @@ func (c *Conn) Invoke(
 		}
 
 		response.Message = msg
+		response.Raw = resp
 	}
 	return &response, nil
 }
@@ -241,6 +247,9 @@ func (h statsHandler) HandleRPC(ctx context.Context, stat grpcstats.RPCStats) {
 	}
 
 	switch s := stat.(type) {
+	case *grpcstats.Begin:
+		// Each attempt of an RPC begins, and ends, in turn, when it is retried.
+		stateRPC.attempts.Add(1)
 	case *grpcstats.OutHeader:
 		// TODO: figure out something better, e.g. via TagConn() or TagRPC()?
 		if state.Options.SystemTags.Has(metrics.TagIP) && s.RemoteAddr != nil {
@@ -253,9 +262,16 @@ func (h statsHandler) HandleRPC(ctx context.Context, stat grpcstats.RPCStats) {
 			stateRPC.tagsAndMeta.SetSystemTagOrMeta(metrics.TagStatus, strconv.Itoa(int(status.Code(s.Error))))
 		}
 
+		// The latency of the retried attempts is measured apart from the
+		// first ones', so that retries don't skew it.
+		metric := state.BuiltinMetrics.GRPCReqDuration
+		if stateRPC.attempts.Load() > 1 {
+			metric = state.BuiltinMetrics.GRPCReqRetryDuration
+		}
+
 		metrics.PushIfNotDone(ctx, state.Samples, metrics.Sample{
 			TimeSeries: metrics.TimeSeries{
-				Metric: state.BuiltinMetrics.GRPCReqDuration,
+				Metric: metric,
 				Tags:   stateRPC.tagsAndMeta.Tags,
 			},
 			Time:     s.EndTime,
@@ -343,6 +359,9 @@ var ctxKeyRPCState = contextKey("rpcState") //nolint:gochecknoglobals
 
 type rpcState struct {
 	tagsAndMeta *metrics.TagsAndMeta
+
+	// attempts counts the attempts of the RPC begun so far.
+	attempts atomic.Int32
 }
 
 func withRPCState(ctx context.Context, rpcState *rpcState) context.Context {
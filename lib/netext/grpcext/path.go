@@ -0,0 +1,283 @@
+package grpcext
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// pathSegment is a segment of a path to a value of a message, either a name,
+// of a field or of a map key, or the index of a list element.
+type pathSegment struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+// parsePath parses a JSONPath-like path, e.g. $.items[0].id, where the $. root
+// is optional and names can also be quoted, e.g. $['items'][0]["id"].
+func parsePath(path string) ([]pathSegment, error) {
+	rest := strings.TrimPrefix(path, "$")
+	if len(rest) == len(path) && rest != "" && rest[0] != '[' {
+		rest = "." + rest
+	}
+
+	var segments []pathSegment
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("invalid path %q: empty field name", path)
+			}
+			segments = append(segments, pathSegment{name: rest[1:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unclosed bracket", path)
+			}
+			segment, err := parseBracket(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %w", path, err)
+			}
+			segments = append(segments, segment)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", path, rest[0])
+		}
+	}
+	return segments, nil
+}
+
+// parseBracket parses the content of a bracket of a path, either a list index
+// or a quoted name.
+func parseBracket(content string) (pathSegment, error) {
+	if len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0] {
+		return pathSegment{name: content[1 : len(content)-1]}, nil
+	}
+	index, err := strconv.Atoi(content)
+	if err != nil || index < 0 {
+		return pathSegment{}, fmt.Errorf("%q is neither a list index nor a quoted name", content)
+	}
+	return pathSegment{name: content, index: index, isIndex: true}, nil
+}
+
+// ExtractField returns the value at the path in the message, e.g.
+// $.items[0].id, converted like the whole message is converted for JS, and
+// whether the message has it. The fields are matched by either their JSON or
+// their proto names, and only the values on the path are converted.
+func ExtractField(msg protoreflect.Message, path string) (interface{}, bool, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	return lookupMessage(msg, segments)
+}
+
+// ExtractValue returns the value at the path in an already converted message,
+// e.g. one received from a stream, and whether the message has it.
+func ExtractValue(value interface{}, path string) (interface{}, bool, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, segment := range segments {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if value, ok = v[segment.name]; !ok {
+				return nil, false, nil
+			}
+		case []interface{}:
+			if !segment.isIndex || segment.index >= len(v) {
+				return nil, false, nil
+			}
+			value = v[segment.index]
+		default:
+			return nil, false, nil
+		}
+	}
+	return value, true, nil
+}
+
+// Extract returns the value at the path in the message of the response, or
+// nil if the message doesn't have it.
+func (r *InvokeResponse) Extract(path string) (interface{}, error) {
+	if r.Raw == nil {
+		value, _, err := ExtractValue(r.Message, path)
+		return value, err
+	}
+	value, _, err := ExtractField(r.Raw, path)
+	return value, err
+}
+
+func lookupMessage(msg protoreflect.Message, segments []pathSegment) (interface{}, bool, error) {
+	if len(segments) == 0 {
+		value, err := convertMessage(msg)
+		return value, err == nil, err
+	}
+
+	fields := msg.Descriptor().Fields()
+	fd := fields.ByJSONName(segments[0].name)
+	if fd == nil {
+		fd = fields.ByName(protoreflect.Name(segments[0].name))
+	}
+	if fd == nil {
+		return nil, false, nil
+	}
+
+	// Like the conversion of the whole message, the unset fields of a oneof
+	// are missing, and the other unset fields with presence are null.
+	if !msg.Has(fd) && !fd.IsList() && !fd.IsMap() {
+		if fd.ContainingOneof() != nil {
+			return nil, false, nil
+		}
+		if fd.HasPresence() {
+			return nil, len(segments) == 1, nil
+		}
+	}
+
+	value := msg.Get(fd)
+	rest := segments[1:]
+	switch {
+	case fd.IsList():
+		list := value.List()
+		if len(rest) == 0 {
+			return convertList(fd, list)
+		}
+		if !rest[0].isIndex || rest[0].index >= list.Len() {
+			return nil, false, nil
+		}
+		return lookupValue(fd, list.Get(rest[0].index), rest[1:])
+	case fd.IsMap():
+		m := value.Map()
+		if len(rest) == 0 {
+			return convertMap(fd, m)
+		}
+		key, ok := parseMapKey(fd.MapKey(), rest[0].name)
+		if !ok || !m.Has(key) {
+			return nil, false, nil
+		}
+		return lookupValue(fd.MapValue(), m.Get(key), rest[1:])
+	default:
+		return lookupValue(fd, value, rest)
+	}
+}
+
+// lookupValue returns the value at the path in a singular value of the field.
+func lookupValue(
+	fd protoreflect.FieldDescriptor, value protoreflect.Value, segments []pathSegment,
+) (interface{}, bool, error) {
+	if fd.Message() != nil {
+		return lookupMessage(value.Message(), segments)
+	}
+	if len(segments) > 0 {
+		return nil, false, nil
+	}
+	return convertScalar(fd, value), true, nil
+}
+
+func parseMapKey(fd protoreflect.FieldDescriptor, name string) (protoreflect.MapKey, bool) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(name).MapKey(), true
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(name)
+		return protoreflect.ValueOfBool(b).MapKey(), err == nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		i, err := strconv.ParseInt(name, 10, 32)
+		return protoreflect.ValueOfInt32(int32(i)).MapKey(), err == nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		i, err := strconv.ParseInt(name, 10, 64)
+		return protoreflect.ValueOfInt64(i).MapKey(), err == nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		u, err := strconv.ParseUint(name, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(u)).MapKey(), err == nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		u, err := strconv.ParseUint(name, 10, 64)
+		return protoreflect.ValueOfUint64(u).MapKey(), err == nil
+	default:
+		return protoreflect.MapKey{}, false
+	}
+}
+
+func convertMessage(msg protoreflect.Message) (interface{}, error) {
+	if !msg.IsValid() {
+		return nil, nil
+	}
+	return convert(protojson.MarshalOptions{EmitUnpopulated: true}, msg.Interface())
+}
+
+func convertList(fd protoreflect.FieldDescriptor, list protoreflect.List) (interface{}, bool, error) {
+	values := make([]interface{}, list.Len())
+	for i := range values {
+		value, _, err := lookupValue(fd, list.Get(i), nil)
+		if err != nil {
+			return nil, false, err
+		}
+		values[i] = value
+	}
+	return values, true, nil
+}
+
+func convertMap(fd protoreflect.FieldDescriptor, m protoreflect.Map) (interface{}, bool, error) {
+	values := make(map[string]interface{}, m.Len())
+	var err error
+	m.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+		values[key.String()], _, err = lookupValue(fd.MapValue(), value, nil)
+		return err == nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return values, true, nil
+}
+
+// convertScalar converts a scalar value the way protojson does, e.g. the 64-bit
+// integers to strings and the enums to the names of their values.
+func convertScalar(fd protoreflect.FieldDescriptor, value protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return value.Bool()
+	case protoreflect.StringKind:
+		return value.String()
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(value.Bytes())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(value.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int64(value.Enum())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return value.Int()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return int64(value.Uint())
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(value.Int(), 10)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(value.Uint(), 10)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		f := value.Float()
+		switch {
+		case math.IsNaN(f):
+			return "NaN"
+		case math.IsInf(f, 1):
+			return "Infinity"
+		case math.IsInf(f, -1):
+			return "-Infinity"
+		}
+		return f
+	default:
+		return value.Interface()
+	}
+}
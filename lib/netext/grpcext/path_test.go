@@ -0,0 +1,143 @@
+package grpcext
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestExtractField(t *testing.T) {
+	t.Parallel()
+
+	msg := messageFromJSON(t, `{
+		"status": "OK",
+		"items": [{"id": "a", "count": "1"}, {"id": "b", "count": "2"}],
+		"byName": {"c": {"id": "c"}},
+		"data": "azY=",
+		"ratio": 0.5,
+		"tags": ["x", "y"],
+		"counts": {"7": 3}
+	}`)
+
+	tests := []struct {
+		path  string
+		value interface{}
+		found bool
+	}{
+		{path: "status", value: "OK", found: true},
+		{path: "$.status", value: "OK", found: true},
+		{path: "$.items[1].id", value: "b", found: true},
+		{path: "$['items'][0][\"count\"]", value: "1", found: true},
+		{path: "$.items[0]", value: map[string]interface{}{"id": "a", "count": "1"}, found: true},
+		{path: "$.items[2].id"},
+		{path: "$.by_name.c.id", value: "c", found: true},
+		{path: "$.byName['c'].count", value: "0", found: true},
+		{path: "$.byName.d"},
+		{path: "$.counts[7]", value: int64(3), found: true},
+		{path: "$.data", value: "azY=", found: true},
+		{path: "$.ratio", value: 0.5, found: true},
+		{path: "$.tags", value: []interface{}{"x", "y"}, found: true},
+		{path: "$.first", value: nil, found: true},
+		{path: "$.first.id"},
+		{path: "$.error"},
+		{path: "$.status.name"},
+		{path: "$.missing"},
+	}
+	for _, tt := range tests {
+		value, found, err := ExtractField(msg, tt.path)
+		require.NoError(t, err, tt.path)
+		assert.Equal(t, tt.found, found, tt.path)
+		assert.Equal(t, tt.value, value, tt.path)
+	}
+
+	value, found, err := ExtractField(msg, "$")
+	require.NoError(t, err)
+	require.True(t, found)
+	converted, err := convert(protojson.MarshalOptions{EmitUnpopulated: true}, msg)
+	require.NoError(t, err)
+	assert.Equal(t, converted, value)
+
+	// the values of converted messages are extracted the same way
+	for _, tt := range tests {
+		if tt.path == "$.by_name.c.id" || tt.path == "$.counts[7]" {
+			continue // the proto names and the integer keys only work on messages
+		}
+		value, found, err := ExtractValue(converted, tt.path)
+		require.NoError(t, err, tt.path)
+		assert.Equal(t, tt.found, found, tt.path)
+		assert.EqualValues(t, tt.value, value, tt.path)
+	}
+}
+
+func TestExtractFieldInvalidPath(t *testing.T) {
+	t.Parallel()
+
+	msg := messageFromJSON(t, `{}`)
+	for path, experr := range map[string]string{
+		"$..status":      "empty field name",
+		"$.items[0":      "unclosed bracket",
+		"$.items[-1]":    "neither a list index nor a quoted name",
+		"$.items[first]": "neither a list index nor a quoted name",
+		"$status":        "unexpected 's'",
+	} {
+		_, _, err := ExtractField(msg, path)
+		assert.ErrorContains(t, err, experr, path)
+	}
+}
+
+func messageFromJSON(t *testing.T, data string) *dynamicpb.Message {
+	t.Helper()
+
+	path := "paths.proto"
+	parser := protoparse.Parser{
+		Accessor: protoparse.FileAccessor(func(filename string) (io.ReadCloser, error) {
+			if filename != path {
+				return nil, nil
+			}
+			return io.NopCloser(bytes.NewBufferString(`
+syntax = "proto3";
+
+package paths;
+
+enum Status {
+  UNKNOWN = 0;
+  OK = 1;
+}
+
+message Item {
+  string id = 1;
+  int64 count = 2;
+}
+
+message Response {
+  Status status = 1;
+  repeated Item items = 2;
+  map<string, Item> by_name = 3;
+  bytes data = 4;
+  Item first = 5;
+  oneof result {
+    string error = 6;
+    int32 code = 7;
+  }
+  double ratio = 8;
+  repeated string tags = 9;
+  map<int32, int32> counts = 10;
+}`)), nil
+		}),
+	}
+	fds, err := parser.ParseFiles(path)
+	require.NoError(t, err)
+	fd, err := protodesc.NewFile(fds[0].AsFileDescriptorProto(), nil)
+	require.NoError(t, err)
+
+	msg := dynamicpb.NewMessage(fd.Messages().ByName("Response"))
+	require.NoError(t, protojson.Unmarshal([]byte(data), msg))
+	return msg
+}
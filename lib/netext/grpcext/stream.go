@@ -10,6 +10,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/dynamicpb"
 )
@@ -78,7 +79,7 @@ func (s *Stream) receive() (*dynamicpb.Message, error) {
 // {"x":6,"y":4}
 // rather than the desired:
 // {"x":6,"y":4,"z":0}
-func convert(marshaler protojson.MarshalOptions, msg *dynamicpb.Message) (interface{}, error) {
+func convert(marshaler protojson.MarshalOptions, msg proto.Message) (interface{}, error) {
 	// TODO(olegbespalov): add the test that checks that message is not nil
 
 	raw, err := marshaler.Marshal(msg)
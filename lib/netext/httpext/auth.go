@@ -0,0 +1,49 @@
+package httpext
+
+import "net/http"
+
+// The schemes of the authentication of the requests.
+const (
+	AuthBasic    = "basic"
+	AuthDigest   = "digest"
+	AuthNTLM     = "ntlm"
+	AuthAWSSigV4 = "awsSigV4"
+)
+
+// AuthCredentials are the credentials of the basic, digest or NTLM
+// authentication of a request, given apart from its URL.
+type AuthCredentials struct {
+	Username string
+	Password string
+
+	// Domain is the domain of the user, for the NTLM authentication.
+	Domain string
+}
+
+// ntlmUsername returns the username in the DOMAIN\user form the NTLM
+// authentication expects, when there is a domain.
+func (c AuthCredentials) ntlmUsername() string {
+	if c.Domain == "" {
+		return c.Username
+	}
+	return c.Domain + `\` + c.Username
+}
+
+// basicAuthTransport sets the basic authentication credentials of the
+// requests made to the host of the original request, which the NTLM
+// authentication is also negotiated from. As with the credentials of the
+// URL, they aren't sent to the other hosts the request is redirected to.
+type basicAuthTransport struct {
+	originalTransport  http.RoundTripper
+	host               string
+	username, password string
+}
+
+// RoundTrip sets the credentials on a copy of the request, and makes it.
+func (t basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == t.host {
+		req = req.Clone(req.Context())
+		req.SetBasicAuth(t.username, t.password)
+	}
+	return t.originalTransport.RoundTrip(req)
+}
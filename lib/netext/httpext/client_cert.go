@@ -0,0 +1,104 @@
+package httpext
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/http2"
+
+	"go.k6.io/k6/lib"
+)
+
+// maxClientCertTransports is the maximum number of transports with a client
+// certificate that a [ClientCertTransports] keeps, the oldest ones are closed
+// when the certificates are rotated.
+const maxClientCertTransports = 16
+
+// ClientCertTransports keeps a transport for each of the client certificates
+// that requests are made with. The connections have to be bound to the identity
+// they were established with, so every certificate gets its own transport, and
+// its own connections.
+type ClientCertTransports struct {
+	mu         sync.Mutex
+	transports map[[sha256.Size]byte]*http.Transport
+	// order contains the keys of the transports, from the oldest one.
+	order [][sha256.Size]byte
+}
+
+// NewClientCertTransports creates a new [ClientCertTransports].
+func NewClientCertTransports() *ClientCertTransports {
+	return &ClientCertTransports{transports: make(map[[sha256.Size]byte]*http.Transport)}
+}
+
+// Get returns the transport presenting the certificate of the auth, which is
+// based on the given VU transport and its TLS config.
+func (c *ClientCertTransports) Get(
+	base http.RoundTripper, tlsConfig *tls.Config, auth *lib.TLSAuth,
+) (http.RoundTripper, error) {
+	h := sha256.New()
+	for _, s := range []string{auth.Cert, auth.Key, auth.Password.String} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t, ok := c.transports[key]; ok {
+		return t, nil
+	}
+
+	baseTransport, ok := base.(*http.Transport)
+	if !ok {
+		return nil, errors.New("client certificates aren't supported by the VU's transport")
+	}
+	cert, err := auth.Certificate()
+	if err != nil {
+		return nil, err
+	}
+
+	t := baseTransport.Clone()
+	if tlsConfig != nil {
+		t.TLSClientConfig = tlsConfig.Clone()
+	} else {
+		t.TLSClientConfig = &tls.Config{} //nolint:gosec
+	}
+	t.TLSClientConfig.Certificates = nil
+	t.TLSClientConfig.NameToCertificate = nil //nolint:staticcheck
+	t.TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return cert, nil
+	}
+	// The HTTP/2 connections are pooled by the HTTP/2 transport the base one
+	// was configured with, so the clone needs its own.
+	if len(baseTransport.TLSNextProto) > 0 {
+		t.TLSNextProto = nil
+		if err = http2.ConfigureTransport(t); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.order) >= maxClientCertTransports {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		c.transports[oldest].CloseIdleConnections()
+		delete(c.transports, oldest)
+	}
+	c.transports[key] = t
+	c.order = append(c.order, key)
+	return t, nil
+}
+
+// CloseIdleConnections closes the idle connections of all transports.
+func (c *ClientCertTransports) CloseIdleConnections() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, t := range c.transports {
+		t.CloseIdleConnections()
+	}
+}
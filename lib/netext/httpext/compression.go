@@ -132,38 +132,24 @@ func readResponseBody(
 		return nil, err
 	}
 
-	rc := &readCloser{resp.Body}
 	// Ensure that the entire response body is read and closed, e.g. in case of decoding errors
 	defer func(respBody io.ReadCloser) {
 		_, _ = io.Copy(io.Discard, respBody)
 		_ = respBody.Close()
 	}(resp.Body)
 
-	if (resp.StatusCode >= 100 && resp.StatusCode <= 199) || // 1xx
-		resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
-		// for all three of this status code there is always no content
-		// https://www.rfc-editor.org/rfc/rfc9110.html#section-6.4.1-8
-		// this also prevents trying to read
+	if !hasContent(resp) {
 		return nil, nil //nolint:nilnil
 	}
-	contentEncodings := strings.Split(resp.Header.Get("Content-Encoding"), ",")
-	// Transparently decompress the body if it's has a content-encoding we
-	// support. If not, simply return it as it is.
-	for i := len(contentEncodings) - 1; i >= 0; i-- {
-		contentEncoding := strings.TrimSpace(contentEncodings[i])
-		if compression, err := CompressionTypeString(contentEncoding); err == nil {
-			decoder, err := pickDecoder(compression, rc)
-			if err != nil {
-				return nil, newDecompressionError(err)
-			}
 
-			rc = &readCloser{decoder}
-		}
+	rc, err := decodeResponseBody(resp)
+	if err != nil {
+		return nil, err
 	}
 
 	buf := state.BufferPool.Get()
 	defer state.BufferPool.Put(buf)
-	_, err := io.Copy(buf, rc.Reader)
+	_, err = io.Copy(buf, rc.Reader)
 	if err != nil {
 		respErr = wrapDecompressionError(err)
 	}
@@ -193,6 +179,38 @@ func readResponseBody(
 	return result, respErr
 }
 
+// hasContent returns false if the response's status code implies it has no content.
+func hasContent(resp *http.Response) bool {
+	// for all three of this status code there is always no content
+	// https://www.rfc-editor.org/rfc/rfc9110.html#section-6.4.1-8
+	// this also prevents trying to read
+	return !((resp.StatusCode >= 100 && resp.StatusCode <= 199) || // 1xx
+		resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified)
+}
+
+// decodeResponseBody returns a reader of the response's body, transparently
+// decompressing it if it has a content-encoding we support.
+func decodeResponseBody(resp *http.Response) (*readCloser, error) {
+	rc := &readCloser{resp.Body}
+
+	contentEncodings := strings.Split(resp.Header.Get("Content-Encoding"), ",")
+	// Transparently decompress the body if it's has a content-encoding we
+	// support. If not, simply return it as it is.
+	for i := len(contentEncodings) - 1; i >= 0; i-- {
+		contentEncoding := strings.TrimSpace(contentEncodings[i])
+		if compression, err := CompressionTypeString(contentEncoding); err == nil {
+			decoder, err := pickDecoder(compression, rc)
+			if err != nil {
+				return nil, newDecompressionError(err)
+			}
+
+			rc = &readCloser{decoder}
+		}
+	}
+
+	return rc, nil
+}
+
 func pickDecoder(compression CompressionType, rc *readCloser) (io.Reader, error) {
 	var decoder io.Reader
 	var err error
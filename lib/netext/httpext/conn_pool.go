@@ -0,0 +1,155 @@
+package httpext
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http/httptrace"
+	"sync"
+
+	"golang.org/x/net/http2"
+
+	"go.k6.io/k6/lib/netext"
+)
+
+// ConnStats describes the connections of a [ConnPool].
+type ConnStats struct {
+	// Open is the number of connections currently open.
+	Open int64 `json:"open"`
+
+	// Idle is the number of open connections not serving any request.
+	Idle int64 `json:"idle"`
+
+	// Reused is the number of requests made over a reused connection.
+	Reused int64 `json:"reused"`
+}
+
+// ConnPool keeps track of the connections HTTP requests are made over, and
+// closes them once they served the maximum number of requests, if any.
+//
+// It doesn't manage the connections itself, which the transport of the
+// requests does; it only observes the connections the transport gets.
+type ConnPool struct {
+	mu     sync.Mutex
+	conns  map[*netext.Conn]*pooledConn
+	reused int64
+}
+
+// pooledConn holds the state of a connection of a [ConnPool].
+type pooledConn struct {
+	conn *netext.Conn
+
+	// requests counts the requests made over the connection so far, and
+	// active the ones it's currently serving.
+	requests, active int64
+}
+
+// NewConnPool creates a new [ConnPool].
+func NewConnPool() *ConnPool {
+	return &ConnPool{conns: make(map[*netext.Conn]*pooledConn)}
+}
+
+// Stats returns the current stats of the pool's connections.
+func (p *ConnPool) Stats() ConnStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := ConnStats{Open: int64(len(p.conns)), Reused: p.reused}
+	for _, pc := range p.conns {
+		if pc.active == 0 {
+			stats.Idle++
+		}
+	}
+
+	return stats
+}
+
+// trace returns a [httptrace.ClientTrace] recording the connection a request
+// gets into the pool. The provided function is called with the function to
+// call once the request is done, and which closes the connection if it has
+// served the given maximum number of requests, if positive, along with the
+// number of streams open over the connection, including the request's one,
+// if it's an HTTP/2 connection, or 0 otherwise.
+func (p *ConnPool) trace(maxRequests int64, gotConn func(done func(), h2Streams int64)) *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			conn := unwrapConn(info.Conn)
+			if conn == nil {
+				return
+			}
+
+			pc, active, added := p.acquire(conn, info.Reused)
+			if added {
+				conn.OnClose(func() { p.remove(conn) })
+			}
+
+			var h2Streams int64
+			if isHTTP2Conn(info.Conn) {
+				h2Streams = active
+			}
+
+			gotConn(func() { p.release(pc, maxRequests) }, h2Streams)
+		},
+	}
+}
+
+// acquire records a request being made over the provided connection, and
+// returns the number of requests it's currently serving, including this one,
+// and whether the connection was added to the pool.
+func (p *ConnPool) acquire(conn *netext.Conn, reused bool) (*pooledConn, int64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if reused {
+		p.reused++
+	}
+
+	pc, ok := p.conns[conn]
+	if !ok {
+		pc = &pooledConn{conn: conn}
+		p.conns[conn] = pc
+	}
+
+	pc.requests++
+	pc.active++
+
+	return pc, pc.active, !ok
+}
+
+// remove removes the provided connection, which was closed, from the pool.
+func (p *ConnPool) remove(conn *netext.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.conns, conn)
+}
+
+// release records a request made over the provided connection being done.
+func (p *ConnPool) release(pc *pooledConn, maxRequests int64) {
+	p.mu.Lock()
+	pc.active--
+	retire := maxRequests > 0 && pc.requests >= maxRequests && pc.active == 0
+	p.mu.Unlock()
+
+	if retire {
+		_ = pc.conn.Close()
+	}
+}
+
+// unwrapConn returns the [netext.Conn] the provided connection is, or wraps
+// when it's a TLS connection, or nil if it's neither.
+func unwrapConn(conn net.Conn) *netext.Conn {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+
+	nc, _ := conn.(*netext.Conn)
+
+	return nc
+}
+
+// isHTTP2Conn returns whether the provided connection negotiated HTTP/2, whose
+// requests are multiplexed as streams over the connection.
+func isHTTP2Conn(conn net.Conn) bool {
+	tlsConn, ok := conn.(*tls.Conn)
+	return ok && tlsConn.ConnectionState().NegotiatedProtocol == http2.NextProtoTLS
+}
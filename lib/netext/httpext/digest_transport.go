@@ -9,6 +9,11 @@ import (
 
 type digestTransport struct {
 	originalTransport http.RoundTripper
+
+	// credentials, if set, are used instead of the ones of the URL for the
+	// requests made to host, the one of the original request.
+	credentials *AuthCredentials
+	host        string
 }
 
 // RoundTrip handles digest auth by behaving like an http.RoundTripper
@@ -24,6 +29,9 @@ func (t digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	// authorization header
 	username := req.URL.User.Username()
 	password, _ := req.URL.User.Password()
+	if t.credentials != nil && req.URL.Host == t.host {
+		username, password = t.credentials.Username, t.credentials.Password
+	}
 
 	// Remove the user data from the URL to avoid sending the authorization
 	// header for basic auth
@@ -0,0 +1,65 @@
+package httpext
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type proxyKey struct{}
+
+// WithProxy returns a copy of the context, which makes the requests made with
+// it go through the given proxy, when the transport's proxy function was
+// created by [ProxyFromContext].
+func WithProxy(ctx context.Context, proxy *url.URL) context.Context {
+	return context.WithValue(ctx, proxyKey{}, proxy)
+}
+
+func proxyFromContext(ctx context.Context) *url.URL {
+	proxy, _ := ctx.Value(proxyKey{}).(*url.URL)
+	return proxy
+}
+
+// ProxyFromContext returns a function for the Proxy field of [http.Transport],
+// which uses the proxy set in the context of the request by [WithProxy], or
+// the fallback function for the requests without one.
+//
+// The connections are pooled by the transport per proxy, so the requests with
+// different proxies don't share connections.
+func ProxyFromContext(fallback func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if proxy := proxyFromContext(req.Context()); proxy != nil {
+			return proxy, nil
+		}
+		if fallback == nil {
+			return nil, nil //nolint:nilnil
+		}
+		return fallback(req)
+	}
+}
+
+// ParseProxyURL parses the URL of an HTTP, HTTPS or SOCKS5 proxy, which may
+// contain the username and the password for its authentication.
+func ParseProxyURL(rawURL string) (*url.URL, error) {
+	proxy, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch proxy.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, it should be http, https, socks5 or socks5h", proxy.Scheme)
+	}
+	if proxy.Host == "" {
+		return nil, errors.New("the proxy URL doesn't have a host")
+	}
+	return proxy, nil
+}
+
+// proxyTagValue returns the value of the proxy system tag, which doesn't
+// contain the credentials of the proxy.
+func proxyTagValue(proxy *url.URL) string {
+	return (&url.URL{Scheme: proxy.Scheme, Host: proxy.Host}).String()
+}
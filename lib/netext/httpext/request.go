@@ -8,7 +8,7 @@ import (
 	"io"
 	"net"
 	"net/http"
-	"net/http/cookiejar"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +18,7 @@ import (
 	"gopkg.in/guregu/null.v3"
 
 	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/capture"
 	"go.k6.io/k6/metrics"
 )
 
@@ -48,9 +49,22 @@ type ParsedHTTPRequest struct {
 	ResponseCallback func(int) bool
 	Compressions     []CompressionType
 	Redirects        null.Int
-	ActiveJar        *cookiejar.Jar
-	Cookies          map[string]*HTTPRequestCookie
-	TagsAndMeta      metrics.TagsAndMeta
+	Retries          RetryPolicy
+	ConnPool         *ConnPool
+	// Transport, if set, is used instead of the VU's transport, e.g. for the
+	// requests with their own client certificate.
+	Transport http.RoundTripper
+	// Proxy, if set, is the proxy the request and its redirects go through.
+	Proxy *url.URL
+	// AuthCredentials, if set, are the credentials of the basic, digest or
+	// NTLM authentication, which are otherwise taken from the URL.
+	AuthCredentials *AuthCredentials
+	// AWSSigV4, if set, is the config the request is signed with, when its
+	// authentication is awsSigV4.
+	AWSSigV4    *AWSSigV4Config
+	ActiveJar   http.CookieJar
+	Cookies     map[string]*HTTPRequestCookie
+	TagsAndMeta metrics.TagsAndMeta
 }
 
 // Matches non-compliant io.Closer implementations (e.g. zstd.Decoder)
@@ -174,6 +188,16 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		preq.TagsAndMeta.SetSystemTagOrMeta(metrics.TagName, preq.URL.Name)
 	}
 
+	// Otherwise, group the URL under the name given by the URL grouping rules, if any matches it.
+	if _, ok := preq.TagsAndMeta.Tags.Get(metrics.TagName.String()); !ok &&
+		state.Options.SystemTags.Has(metrics.TagName) && state.Options.URLGrouping.Valid {
+		if name, matched := state.Options.URLGrouping.Name(preq.URL.GetURL(), preq.URL.Clean()); matched {
+			preq.TagsAndMeta.SetSystemTagOrMeta(metrics.TagName, name)
+		}
+	}
+
+	propagateTraceContext(state, preq)
+
 	// Check rate limit *after* we've prepared a request; no need to wait with that part.
 	if rpsLimit := state.RPSLimit; rpsLimit != nil {
 		if err := rpsLimit.Wait(ctx); err != nil {
@@ -181,7 +205,7 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		}
 	}
 
-	tracerTransport := newTransport(ctx, state, &preq.TagsAndMeta, preq.ResponseCallback)
+	tracerTransport := newTransport(ctx, state, &preq.TagsAndMeta, preq.ResponseCallback, preq.ConnPool, preq.Transport)
 	var transport http.RoundTripper = tracerTransport
 
 	if state.Options.HTTPDebug.String != "" {
@@ -204,7 +228,24 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		}
 	}
 
-	if preq.Auth == "digest" {
+	// The requests are signed below the retries, so every attempt, like every
+	// redirect, is signed on its own.
+	if preq.Auth == AuthAWSSigV4 && preq.AWSSigV4 != nil {
+		transport = sigV4Transport{originalTransport: transport, config: preq.AWSSigV4}
+	}
+
+	if preq.Retries.Count > 0 {
+		transport = retryTransport{
+			originalTransport: transport,
+			policy:            preq.Retries,
+			ctx:               ctx,
+			state:             state,
+			tagsAndMeta:       &preq.TagsAndMeta,
+		}
+	}
+
+	switch preq.Auth {
+	case AuthDigest:
 		// Until digest authentication is refactored, the first response will always
 		// be a 401 error, so we expect that.
 		if tracerTransport.responseCallback != nil {
@@ -214,8 +255,12 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 				return status == 401
 			}
 		}
-		transport = digestTransport{originalTransport: transport}
-	} else if preq.Auth == "ntlm" {
+		transport = digestTransport{
+			originalTransport: transport,
+			credentials:       preq.AuthCredentials,
+			host:              preq.Req.URL.Host,
+		}
+	case AuthNTLM:
 		// The first response of NTLM auth may be a 401 error.
 		if tracerTransport.responseCallback != nil {
 			originalResponseCallback := tracerTransport.responseCallback
@@ -226,6 +271,23 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 			}
 		}
 		transport = ntlmssp.Negotiator{RoundTripper: transport}
+		if preq.AuthCredentials != nil {
+			transport = basicAuthTransport{
+				originalTransport: transport,
+				host:              preq.Req.URL.Host,
+				username:          preq.AuthCredentials.ntlmUsername(),
+				password:          preq.AuthCredentials.Password,
+			}
+		}
+	case AuthBasic:
+		if preq.AuthCredentials != nil {
+			transport = basicAuthTransport{
+				originalTransport: transport,
+				host:              preq.Req.URL.Host,
+				username:          preq.AuthCredentials.Username,
+				password:          preq.AuthCredentials.Password,
+			}
+		}
 	}
 
 	resp := &Response{URL: preq.URL.URL, Request: respReq}
@@ -260,7 +322,17 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 	}
 
 	reqCtx, cancelFunc := context.WithTimeout(ctx, preq.Timeout)
-	defer cancelFunc()
+	// Streamed bodies are read after we return, and release the context once
+	// they are finished.
+	streamed := false
+	defer func() {
+		if !streamed {
+			cancelFunc()
+		}
+	}()
+	if preq.Proxy != nil {
+		reqCtx = WithProxy(reqCtx, preq.Proxy)
+	}
 	mreq := preq.Req.WithContext(reqCtx)
 	res, resErr := client.Do(mreq)
 
@@ -273,16 +345,35 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		return nil, fmt.Errorf("unsupported response status: %s", res.Status)
 	}
 
-	if resErr == nil {
+	switch {
+	case resErr == nil && preq.ResponseType == ResponseTypeStream:
+		// The metrics are emitted once the body is finished, so that the
+		// receiving time accounts for reading it. As this happens after we
+		// return, the timings of the response are not populated.
+		var body *streamedBody
+		body, resErr = newStreamedBody(reqCtx, res, func(err error) {
+			defer cancelFunc()
+
+			if err != nil && errors.Is(err, context.DeadlineExceeded) {
+				err = NewK6Error(requestTimeoutErrorCode, requestTimeoutErrorCodeMsg, err)
+			}
+			tracerTransport.processLastSavedRequest(err)
+		})
+		if streamed = resErr == nil; streamed {
+			resp.Body = body
+		}
+	case resErr == nil:
 		resp.Body, resErr = readResponseBody(state, preq.ResponseType, res, resErr)
 		if resErr != nil && errors.Is(resErr, context.DeadlineExceeded) {
 			// TODO This can be more specific that the timeout happened in the middle of the reading of the body
 			resErr = NewK6Error(requestTimeoutErrorCode, requestTimeoutErrorCodeMsg, resErr)
 		}
 	}
-	finishedReq := tracerTransport.processLastSavedRequest(wrapDecompressionError(resErr))
-	if finishedReq != nil {
-		updateK6Response(resp, finishedReq)
+	if !streamed {
+		finishedReq := tracerTransport.processLastSavedRequest(wrapDecompressionError(resErr))
+		if finishedReq != nil {
+			updateK6Response(resp, finishedReq)
+		}
 	}
 
 	if resErr == nil {
@@ -322,6 +413,10 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 		}
 	}
 
+	if state.FailureCapture != nil && !streamed && preq.ResponseCallback != nil && !preq.ResponseCallback(resp.Status) {
+		captureFailure(ctx, state, resp)
+	}
+
 	if resErr != nil {
 		if preq.Throw { // if we are going to throw, we shouldn't log it
 			return nil, resErr
@@ -340,7 +435,7 @@ func MakeRequest(ctx context.Context, state *lib.State, preq *ParsedHTTPRequest)
 
 // SetRequestCookies sets the cookies of the requests getting those cookies both from the jar and
 // from the reqCookies map. The Replace field of the HTTPRequestCookie will be taken into account
-func SetRequestCookies(req *http.Request, jar *cookiejar.Jar, reqCookies map[string]*HTTPRequestCookie) {
+func SetRequestCookies(req *http.Request, jar http.CookieJar, reqCookies map[string]*HTTPRequestCookie) {
 	replacedCookies := make(map[string]struct{})
 	for key, reqCookie := range reqCookies {
 		req.AddCookie(&http.Cookie{Name: key, Value: reqCookie.Value})
@@ -354,3 +449,23 @@ func SetRequestCookies(req *http.Request, jar *cookiejar.Jar, reqCookies map[str
 		}
 	}
 }
+
+// captureFailure records the request and the response, whose status isn't an
+// expected one according to the response callback.
+func captureFailure(ctx context.Context, state *lib.State, resp *Response) {
+	reason := fmt.Sprintf("the status %d isn't an expected one", resp.Status)
+	if resp.Error != "" {
+		reason = "the request failed: " + resp.Error
+	}
+	artifact := capture.Artifact{
+		Time:      time.Now(),
+		Reason:    reason,
+		VU:        state.VUID,
+		Iteration: state.Iteration,
+		Exchange:  resp.CaptureExchange(),
+	}
+	if ss := lib.GetScenarioState(ctx); ss != nil {
+		artifact.Scenario = ss.Name
+	}
+	state.FailureCapture.Record(artifact)
+}
@@ -3,6 +3,7 @@ package httpext
 import (
 	"crypto/tls"
 
+	"go.k6.io/k6/lib/capture"
 	"go.k6.io/k6/lib/netext"
 )
 
@@ -29,6 +30,11 @@ const (
 	// want to  measure, but we don't care about their responses' contents. This is the
 	// default value for all requests if the global discardResponseBodies is enablled.
 	ResponseTypeNone
+	// ResponseTypeStream causes k6 to return the response body as an io.ReadCloser,
+	// read as it is consumed rather than buffered at once, which is suitable for
+	// large downloads and long-lived responses. The request's metrics are emitted
+	// once the body has been entirely read, has failed to be, or has been closed.
+	ResponseTypeStream
 )
 
 // ResponseTimings is a struct to put all timings for a given HTTP response/request
@@ -84,3 +90,30 @@ func (res *Response) setTLSInfo(tlsState *tls.ConnectionState) {
 	res.TLSCipherSuite = tlsInfo.CipherSuite
 	res.OCSP = oscp
 }
+
+// CaptureExchange returns the request and the response, as they are captured
+// on failures. The bodies of the streamed responses aren't captured, as they
+// are read by the scripts.
+func (res *Response) CaptureExchange() capture.Exchange {
+	var exchange capture.Exchange
+	if res.Request != nil {
+		exchange.Request = capture.Request{
+			Method:  res.Request.Method,
+			URL:     res.Request.URL,
+			Headers: res.Request.Headers,
+			Body:    res.Request.Body,
+		}
+	}
+	exchange.Response = capture.Response{
+		Status:  res.Status,
+		Headers: res.Headers,
+		Error:   res.Error,
+	}
+	switch body := res.Body.(type) {
+	case string:
+		exchange.Response.Body = body
+	case []byte:
+		exchange.Response.Body = string(body)
+	}
+	return exchange
+}
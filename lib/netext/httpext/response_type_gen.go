@@ -7,9 +7,9 @@ import (
 	"fmt"
 )
 
-const _ResponseTypeName = "textbinarynone"
+const _ResponseTypeName = "textbinarynonestream"
 
-var _ResponseTypeIndex = [...]uint8{0, 4, 10, 14}
+var _ResponseTypeIndex = [...]uint8{0, 4, 10, 14, 20}
 
 func (i ResponseType) String() string {
 	if i >= ResponseType(len(_ResponseTypeIndex)-1) {
@@ -18,12 +18,13 @@ func (i ResponseType) String() string {
 	return _ResponseTypeName[_ResponseTypeIndex[i]:_ResponseTypeIndex[i+1]]
 }
 
-var _ResponseTypeValues = []ResponseType{0, 1, 2}
+var _ResponseTypeValues = []ResponseType{0, 1, 2, 3}
 
 var _ResponseTypeNameToValueMap = map[string]ResponseType{
 	_ResponseTypeName[0:4]:   0,
 	_ResponseTypeName[4:10]:  1,
 	_ResponseTypeName[10:14]: 2,
+	_ResponseTypeName[14:20]: 3,
 }
 
 // ResponseTypeString retrieves an enum value from the enum constants string name.
@@ -0,0 +1,225 @@
+package httpext
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+// Backoff is the strategy the delay between the attempts of a retried request
+// grows with.
+type Backoff string
+
+const (
+	// BackoffConstant waits the same delay before each retry.
+	BackoffConstant Backoff = "constant"
+
+	// BackoffLinear waits the delay times the number of the retry.
+	BackoffLinear Backoff = "linear"
+
+	// BackoffExponential doubles the delay with each retry.
+	BackoffExponential Backoff = "exponential"
+)
+
+// RetryPolicy describes when, and how, a failed request is retried.
+type RetryPolicy struct {
+	// Count is the maximum number of retries, no retries being made if it is 0.
+	Count int64
+
+	// Backoff is the strategy the delay between the attempts grows with.
+	Backoff Backoff
+
+	// Delay is the delay before the first retry.
+	Delay time.Duration
+
+	// MaxDelay caps the delay between two attempts.
+	MaxDelay time.Duration
+
+	// StatusCodes are the response status codes the request is retried on.
+	StatusCodes []int
+
+	// NetworkErrors indicates whether the request is retried when it fails
+	// without a response, like when the connection is refused or reset.
+	NetworkErrors bool
+
+	// Methods are the methods of the requests which are retried, the
+	// idempotent ones if it's empty, since retrying the others could repeat
+	// their side effects, e.g. when the server processed the request, but the
+	// connection broke before it responded.
+	Methods []string
+}
+
+// idempotentMethods are the methods of the requests retried by default.
+var idempotentMethods = []string{ //nolint:gochecknoglobals
+	http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete,
+}
+
+// retriesMethod returns whether the requests with the provided method are
+// retried.
+func (p RetryPolicy) retriesMethod(method string) bool {
+	methods := p.Methods
+	if len(methods) == 0 {
+		methods = idempotentMethods
+	}
+
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// delay returns the delay to wait for before the provided retry, starting at 1.
+func (p RetryPolicy) delay(retry int64) time.Duration {
+	var d time.Duration
+	switch p.Backoff {
+	case BackoffLinear:
+		d = p.Delay * time.Duration(retry)
+	case BackoffExponential:
+		d = p.Delay
+		for i := int64(1); i < retry && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+			d *= 2
+		}
+	default:
+		d = p.Delay
+	}
+
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	return d
+}
+
+// retryable returns whether an attempt having resulted in the provided
+// response and error should be retried.
+func (p RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return p.NetworkErrors
+	}
+
+	for _, code := range p.StatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryAttemptKey is the context key of the retryAttempt of a request.
+type retryAttemptKey struct{}
+
+// retryAttempt is held by the context of each attempt of a retried request.
+type retryAttempt struct {
+	// retried is set once the attempt has been retried, right before the next
+	// attempt is made.
+	retried bool
+}
+
+// isRetriedAttempt returns whether the request of the provided context is an
+// attempt which has been retried.
+func isRetriedAttempt(ctx context.Context) bool {
+	attempt, _ := ctx.Value(retryAttemptKey{}).(*retryAttempt)
+	return attempt != nil && attempt.retried
+}
+
+// retryTransport is an http.RoundTripper retrying the requests under a policy.
+//
+// Each attempt is made with its own clone of the request, and goes through the
+// original transport, which measures it. The attempts which have been retried
+// aren't counted by the http_reqs and http_req_failed metrics though, only the
+// last one is, while the retries are counted by the http_req_retries metric.
+type retryTransport struct {
+	originalTransport http.RoundTripper
+	policy            RetryPolicy
+
+	ctx         context.Context
+	state       *lib.State
+	tagsAndMeta *metrics.TagsAndMeta
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attempt := &retryAttempt{}
+	attemptReq := req.Clone(context.WithValue(ctx, retryAttemptKey{}, attempt))
+
+	// The request can't be retried if its body can't be read again.
+	rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	retried := rewindable && t.policy.retriesMethod(req.Method)
+
+	for retry := int64(1); ; retry++ {
+		resp, err := t.originalTransport.RoundTrip(attemptReq)
+		if retry > t.policy.Count || !retried || !t.policy.retryable(resp, err) {
+			return resp, err
+		}
+
+		// The request shouldn't be retried if it's already done, or if it
+		// would time out before the next attempt, the last response or error
+		// being returned as they are instead.
+		delay := t.policy.delay(retry)
+		if deadline, ok := ctx.Deadline(); ctx.Err() != nil || (ok && time.Until(deadline) <= delay) {
+			return resp, err
+		}
+
+		if resp != nil {
+			// The body is read, so that the connection can be reused.
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		t.emitRetry(req, resp)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		next := &retryAttempt{}
+		attemptReq = req.Clone(context.WithValue(ctx, retryAttemptKey{}, next))
+		if req.GetBody != nil {
+			if attemptReq.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		// The previous attempt is only measured once the next one is made.
+		attempt.retried = true
+		attempt = next
+	}
+}
+
+// emitRetry emits the sample counting the retry of the provided request,
+// following the attempt having resulted in the given response, if any.
+func (t retryTransport) emitRetry(req *http.Request, resp *http.Response) {
+	enabledTags := t.state.Options.SystemTags
+	tagsAndMeta := requestTagsAndMeta(t.tagsAndMeta, enabledTags, req)
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	tagsAndMeta.SetSystemTagOrMetaIfEnabled(enabledTags, metrics.TagStatus, strconv.Itoa(status))
+
+	metrics.PushIfNotDone(t.ctx, t.state.Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: t.state.BuiltinMetrics.HTTPReqRetries,
+			Tags:   tagsAndMeta.Tags,
+		},
+		Time:     time.Now(),
+		Metadata: tagsAndMeta.Metadata,
+		Value:    1,
+	})
+}
@@ -0,0 +1,160 @@
+package httpext
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		policy   RetryPolicy
+		expected []time.Duration
+	}{
+		{
+			name:     "Constant",
+			policy:   RetryPolicy{Backoff: BackoffConstant, Delay: time.Second},
+			expected: []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			name:     "Linear",
+			policy:   RetryPolicy{Backoff: BackoffLinear, Delay: time.Second, MaxDelay: 5 * time.Second},
+			expected: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second},
+		},
+		{
+			name:     "Exponential",
+			policy:   RetryPolicy{Backoff: BackoffExponential, Delay: 100 * time.Millisecond, MaxDelay: time.Second},
+			expected: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second},
+		},
+		{
+			name:     "ExponentialUncapped",
+			policy:   RetryPolicy{Backoff: BackoffExponential, Delay: time.Millisecond},
+			expected: []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 8 * time.Millisecond},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for i, expected := range tc.expected {
+				assert.Equal(t, expected, tc.policy.delay(int64(i+1)), "retry %d", i+1)
+			}
+		})
+	}
+}
+
+// attemptsRoundTripper responds to the requests with the provided statuses in
+// turn, recording the requests and their bodies.
+type attemptsRoundTripper struct {
+	statuses []int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (rt *attemptsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+	}
+	rt.requests = append(rt.requests, req)
+	rt.bodies = append(rt.bodies, string(body))
+
+	status := rt.statuses[len(rt.requests)-1]
+	return &http.Response{StatusCode: status, Body: http.NoBody, Request: req}, nil
+}
+
+func TestRetryTransportRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	newRetryTransport := func(rt http.RoundTripper) retryTransport {
+		registry := metrics.NewRegistry()
+		state := &lib.State{
+			Options:        lib.Options{SystemTags: &metrics.DefaultSystemTagSet},
+			BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+			Samples:        make(chan metrics.SampleContainer, 10),
+		}
+
+		return retryTransport{
+			originalTransport: rt,
+			policy:            RetryPolicy{Count: 2, StatusCodes: []int{http.StatusServiceUnavailable}},
+			ctx:               context.Background(),
+			state:             state,
+			tagsAndMeta:       &metrics.TagsAndMeta{Tags: registry.RootTagSet()},
+		}
+	}
+
+	t.Run("EachAttemptIsAClone", func(t *testing.T) {
+		t.Parallel()
+
+		rt := &attemptsRoundTripper{statuses: []int{503, 503, 200}}
+		req, err := http.NewRequest(http.MethodPut, "http://example.com", strings.NewReader("payload"))
+		require.NoError(t, err)
+		body := req.Body
+
+		resp, err := newRetryTransport(rt).RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		assert.Equal(t, []string{"payload", "payload", "payload"}, rt.bodies)
+		assert.Equal(t, body, req.Body, "the request's body shouldn't be changed")
+		for i, attempt := range rt.requests {
+			assert.NotSame(t, req, attempt)
+			assert.Equal(t, i < 2, isRetriedAttempt(attempt.Context()), "attempt %d", i)
+		}
+	})
+
+	t.Run("Methods", func(t *testing.T) {
+		t.Parallel()
+
+		for method, retried := range map[string]bool{
+			http.MethodGet:    true,
+			http.MethodDelete: true,
+			http.MethodPost:   false,
+			http.MethodPatch:  false,
+		} {
+			rt := &attemptsRoundTripper{statuses: []int{503, 200}}
+			req, err := http.NewRequest(method, "http://example.com", nil)
+			require.NoError(t, err)
+
+			_, err = newRetryTransport(rt).RoundTrip(req)
+			require.NoError(t, err)
+			assert.Equal(t, retried, len(rt.requests) == 2, method)
+		}
+
+		transport := newRetryTransport(&attemptsRoundTripper{statuses: []int{503, 200}})
+		transport.policy.Methods = []string{"post"}
+		req, err := http.NewRequest(http.MethodPost, "http://example.com", nil)
+		require.NoError(t, err)
+
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "POST should be retried once opted-in")
+	})
+
+	t.Run("NotRewindable", func(t *testing.T) {
+		t.Parallel()
+
+		rt := &attemptsRoundTripper{statuses: []int{503, 503, 200}}
+		req, err := http.NewRequest(http.MethodPut, "http://example.com", io.NopCloser(strings.NewReader("payload")))
+		require.NoError(t, err)
+
+		resp, err := newRetryTransport(rt).RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Len(t, rt.requests, 1)
+	})
+}
@@ -0,0 +1,253 @@
+package httpext
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm      = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat     = "20060102T150405Z"
+	sigV4DateFormat     = "20060102"
+	sigV4UnsignedBody   = "UNSIGNED-PAYLOAD"
+	sigV4ContentSHA256  = "X-Amz-Content-Sha256"
+	sigV4DateHeader     = "X-Amz-Date"
+	sigV4SecurityHeader = "X-Amz-Security-Token"
+)
+
+// sigV4IgnoredHeaders are the headers that aren't signed, since they can be
+// changed by the proxies, or by the transport itself.
+//
+//nolint:gochecknoglobals
+var sigV4IgnoredHeaders = map[string]struct{}{
+	"authorization":   {},
+	"user-agent":      {},
+	"x-amzn-trace-id": {},
+	"expect":          {},
+	"connection":      {},
+}
+
+// AWSSigV4Config holds the credentials and the scope of the AWS Signature
+// Version 4 of the requests.
+type AWSSigV4Config struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is the token of the temporary credentials, if any.
+	SessionToken string
+	Region       string
+	Service      string
+}
+
+// sigV4Transport signs every request it makes, including the redirects and
+// the retries, with the AWS Signature Version 4.
+type sigV4Transport struct {
+	originalTransport http.RoundTripper
+	config            *AWSSigV4Config
+
+	// now returns the time the requests are signed at.
+	now func() time.Time
+}
+
+// NewAWSSigV4Transport returns a transport signing every request it makes with
+// the AWS Signature Version 4, e.g. for the clients of the AWS APIs.
+func NewAWSSigV4Transport(transport http.RoundTripper, config *AWSSigV4Config) http.RoundTripper {
+	return sigV4Transport{originalTransport: transport, config: config}
+}
+
+// RoundTrip signs a copy of the request and makes it.
+func (t sigV4Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	signed, err := t.sign(req)
+	if err != nil {
+		return nil, err
+	}
+	return t.originalTransport.RoundTrip(signed)
+}
+
+// sign returns a copy of the request, with its signature as its Authorization
+// header.
+func (t sigV4Transport) sign(req *http.Request) (*http.Request, error) {
+	now := time.Now
+	if t.now != nil {
+		now = t.now
+	}
+	signedAt := now().UTC()
+
+	req = req.Clone(req.Context())
+	req.Header.Del("Authorization")
+	req.Header.Set(sigV4DateHeader, signedAt.Format(sigV4TimeFormat))
+	if t.config.SessionToken != "" {
+		req.Header.Set(sigV4SecurityHeader, t.config.SessionToken)
+	}
+
+	payloadHash := req.Header.Get(sigV4ContentSHA256)
+	if payloadHash == "" {
+		var err error
+		if payloadHash, err = hashPayload(req); err != nil {
+			return nil, err
+		}
+		// S3 requires the hash of the payload as a header, while the other
+		// services need it only when the payload isn't signed.
+		if t.config.Service == "s3" || payloadHash == sigV4UnsignedBody {
+			req.Header.Set(sigV4ContentSHA256, payloadHash)
+		}
+	}
+
+	headers, signedHeaders := canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL, t.config.Service != "s3"),
+		canonicalQuery(req.URL),
+		headers,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{
+		signedAt.Format(sigV4DateFormat), t.config.Region, t.config.Service, "aws4_request",
+	}, "/")
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		signedAt.Format(sigV4TimeFormat),
+		scope,
+		hashSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := []byte("AWS4" + t.config.SecretAccessKey)
+	for _, part := range []string{signedAt.Format(sigV4DateFormat), t.config.Region, t.config.Service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", sigV4Algorithm+" Credential="+t.config.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+
+	return req, nil
+}
+
+// hashPayload returns the hex-encoded SHA-256 hash of the request's body, or
+// UNSIGNED-PAYLOAD if the body can't be read again, e.g. when it's streamed.
+func hashPayload(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return hashSHA256(nil), nil
+	}
+	if req.GetBody == nil {
+		return sigV4UnsignedBody, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = body.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// canonicalPath returns the URI-encoded path of the URL, which is encoded
+// twice for all of the services except S3.
+func canonicalPath(u *url.URL, encodeTwice bool) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	if encodeTwice {
+		path = sigV4Escape(path, false)
+	}
+	return path
+}
+
+// canonicalQuery returns the query of the URL, sorted by key and value, with
+// its keys and values URI-encoded.
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, sigV4Escape(key, true)+"="+sigV4Escape(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// canonicalHeaders returns the canonical headers of the request, including
+// its host, and the list of their names.
+func canonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	if h, port, err := net.SplitHostPort(host); err == nil &&
+		(req.URL.Scheme == "https" && port == "443" || req.URL.Scheme == "http" && port == "80") {
+		host = h
+	}
+
+	values := map[string]string{"host": host}
+	for name, vals := range req.Header {
+		name = strings.ToLower(name)
+		if _, ignored := sigV4IgnoredHeaders[name]; ignored {
+			continue
+		}
+		trimmed := make([]string, len(vals))
+		for i, v := range vals {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		values[name] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var headers strings.Builder
+	for _, name := range names {
+		headers.WriteString(name + ":" + values[name] + "\n")
+	}
+	return headers.String(), strings.Join(names, ";")
+}
+
+// sigV4Escape URI-encodes every byte of the string but the unreserved
+// characters, and the slashes unless encodeSlash is true.
+func sigV4Escape(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&0xF])
+		}
+	}
+	return b.String()
+}
+
+func hashSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
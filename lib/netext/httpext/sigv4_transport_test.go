@@ -0,0 +1,99 @@
+package httpext
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigV4Sign(t *testing.T) {
+	t.Parallel()
+
+	signedAt := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	newTransport := func(service, region, sessionToken string) sigV4Transport {
+		return sigV4Transport{
+			config: &AWSSigV4Config{
+				AccessKeyID:     "AKIDEXAMPLE",
+				SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+				SessionToken:    sessionToken,
+				Region:          region,
+				Service:         service,
+			},
+			now: func() time.Time { return signedAt },
+		}
+	}
+
+	t.Run("GetVanilla", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		require.NoError(t, err)
+		signed, err := newTransport("service", "us-east-1", "").sign(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=host;x-amz-date, "+
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+			signed.Header.Get("Authorization"))
+		assert.Equal(t, "20150830T123600Z", signed.Header.Get("X-Amz-Date"))
+		assert.Empty(t, req.Header.Get("Authorization"), "the original request was modified")
+	})
+
+	t.Run("GetQueryOrder", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/?Param2=value2&Param1=value1", nil)
+		require.NoError(t, err)
+		signed, err := newTransport("service", "us-east-1", "").sign(req)
+		require.NoError(t, err)
+
+		assert.True(t, strings.HasSuffix(signed.Header.Get("Authorization"),
+			"Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"))
+	})
+
+	t.Run("IAMListUsers", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+		signed, err := newTransport("iam", "us-east-1", "").sign(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, "+
+			"SignedHeaders=content-type;host;x-amz-date, "+
+			"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+			signed.Header.Get("Authorization"))
+	})
+
+	t.Run("S3Payload", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/my%20key", strings.NewReader("body"))
+		require.NoError(t, err)
+		signed, err := newTransport("s3", "eu-west-1", "token").sign(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, "230d8358dc8e8890b4c58deeb62912ee2f20357ae92a5cc861b98e68fe31acb5",
+			signed.Header.Get("X-Amz-Content-Sha256"))
+		assert.Equal(t, "token", signed.Header.Get("X-Amz-Security-Token"))
+		assert.Contains(t, signed.Header.Get("Authorization"),
+			"SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token, ")
+	})
+
+	t.Run("UnsignedPayload", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := http.NewRequest(http.MethodPost, "https://example.amazonaws.com/", strings.NewReader("body"))
+		require.NoError(t, err)
+		req.GetBody = nil // the body is streamed, so it can't be hashed
+		signed, err := newTransport("service", "us-east-1", "").sign(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, "UNSIGNED-PAYLOAD", signed.Header.Get("X-Amz-Content-Sha256"))
+	})
+}
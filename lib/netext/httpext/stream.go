@@ -0,0 +1,100 @@
+package httpext
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// streamedBody is the body of a response requested with [ResponseTypeStream],
+// read as it is consumed rather than at once.
+//
+// It is finished, and the provided finish function called with the error that
+// interrupted reading it, if any, once it has been entirely read, has failed to
+// be, or has been closed, whichever happens first.
+type streamedBody struct {
+	body   io.ReadCloser
+	finish func(error)
+
+	// mu serializes the reads of the decoded body with its closing, as the
+	// decoders do not support being closed while being read.
+	mu      sync.Mutex
+	decoded *readCloser
+
+	once sync.Once
+	done chan struct{}
+}
+
+var _ io.ReadCloser = (*streamedBody)(nil)
+
+// newStreamedBody returns a streamed body reading the provided response's body,
+// which is also finished if the given context is done before it is.
+func newStreamedBody(ctx context.Context, resp *http.Response, finish func(error)) (*streamedBody, error) {
+	decoded := &readCloser{http.NoBody}
+	if hasContent(resp) {
+		var err error
+		if decoded, err = decodeResponseBody(resp); err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+	}
+
+	sb := &streamedBody{
+		body:    resp.Body,
+		finish:  finish,
+		decoded: decoded,
+		done:    make(chan struct{}),
+	}
+
+	// The request's metrics are emitted even if the body is never consumed.
+	go func() {
+		select {
+		case <-ctx.Done():
+			sb.close(ctx.Err())
+		case <-sb.done:
+		}
+	}()
+
+	return sb, nil
+}
+
+// Read implements the [io.Reader] interface.
+func (sb *streamedBody) Read(p []byte) (int, error) {
+	sb.mu.Lock()
+	n, err := sb.decoded.Read(p)
+	sb.mu.Unlock()
+
+	switch {
+	case errors.Is(err, io.EOF):
+		sb.close(nil)
+	case err != nil:
+		err = wrapDecompressionError(err)
+		sb.close(err)
+	}
+
+	return n, err
+}
+
+// Close implements the [io.Closer] interface, finishing the body if it is not
+// already.
+func (sb *streamedBody) Close() error {
+	sb.close(nil)
+	return nil
+}
+
+// close finishes the body, with the error that interrupted reading it, if any.
+func (sb *streamedBody) close(err error) {
+	sb.once.Do(func() {
+		// Closing the body first interrupts the pending reads, if any.
+		_ = sb.body.Close()
+
+		sb.mu.Lock()
+		_ = sb.decoded.Close()
+		sb.mu.Unlock()
+
+		sb.finish(err)
+		close(sb.done)
+	})
+}
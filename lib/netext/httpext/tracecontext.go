@@ -0,0 +1,27 @@
+package httpext
+
+import (
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/netext/tracecontext"
+)
+
+// propagateTraceContext adds the headers of a new trace context to the
+// request, when the tracePropagator option is set, and adds its trace ID to
+// the metadata of the request's samples.
+//
+// The requests which already have a trace context header, e.g. set by the
+// script or by the tracing module's instrumentation, are left as they are.
+func propagateTraceContext(state *lib.State, preq *ParsedHTTPRequest) {
+	tc, propagators, ok := tracecontext.FromOptions(state.Options.TracePropagator, state.Options.TraceSampling)
+	if !ok {
+		return
+	}
+	if preq.Req.Header.Get(tracecontext.W3CHeaderName) != "" || preq.Req.Header.Get(tracecontext.B3HeaderName) != "" {
+		return
+	}
+
+	for name, value := range tc.Headers(propagators) {
+		preq.Req.Header.Set(name, value)
+	}
+	preq.TagsAndMeta.SetMetadata(tracecontext.MetadataKey, tc.TraceID)
+}
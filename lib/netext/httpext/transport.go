@@ -9,6 +9,7 @@ import (
 	"net/http/httptrace"
 	"strconv"
 	"sync"
+	"time"
 
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/netext"
@@ -22,6 +23,10 @@ type transport struct {
 	state            *lib.State
 	tagsAndMeta      *metrics.TagsAndMeta
 	responseCallback func(int) bool
+	connPool         *ConnPool
+	// roundTripper is the transport the requests are made with, it's the
+	// VU's transport if none was given.
+	roundTripper http.RoundTripper
 
 	lastRequest     *unfinishedRequest
 	lastRequestLock *sync.Mutex
@@ -35,6 +40,14 @@ type unfinishedRequest struct {
 	request  *http.Request
 	response *http.Response
 	err      error
+
+	// connDone, if any, is called once the request is done with the connection
+	// it was made over.
+	connDone func()
+
+	// h2Streams is the number of streams open over the HTTP/2 connection the
+	// request was made over, including its own one, or 0 for other protocols.
+	h2Streams int64
 }
 
 // finishedRequest is produced once the request has been finalized; it is
@@ -60,12 +73,19 @@ func newTransport(
 	state *lib.State,
 	tagsAndMeta *metrics.TagsAndMeta,
 	responseCallback func(int) bool,
+	connPool *ConnPool,
+	roundTripper http.RoundTripper,
 ) *transport {
+	if roundTripper == nil {
+		roundTripper = state.Transport
+	}
 	return &transport{
 		ctx:              ctx,
 		state:            state,
 		tagsAndMeta:      tagsAndMeta,
 		responseCallback: responseCallback,
+		connPool:         connPool,
+		roundTripper:     roundTripper,
 		lastRequestLock:  new(sync.Mutex),
 	}
 }
@@ -75,6 +95,10 @@ func newTransport(
 //
 //nolint:funlen
 func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRequest {
+	if unfReq.connDone != nil {
+		unfReq.connDone()
+	}
+
 	trail := unfReq.tracer.Done()
 
 	result := &finishedRequest{
@@ -82,27 +106,8 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 		trail:             trail,
 	}
 
-	tagsAndMeta := t.tagsAndMeta.Clone()
 	enabledTags := t.state.Options.SystemTags
-	cleanURL := URL{u: unfReq.request.URL, URL: unfReq.request.URL.String()}.Clean()
-
-	// After k6 v0.41.0, the `name` and `url` tags have the exact same values:
-	nameTagValue, nameTagManuallySet := tagsAndMeta.Tags.Get(metrics.TagName.String())
-	if !nameTagManuallySet {
-		// If the user *didn't* manually set a `name` tag value and didn't use
-		// the http.url template literal helper to have k6 automatically set
-		// it (see `lib/netext/httpext.MakeRequest()`), we will use the cleaned
-		// URL value as the value of both `name` and `url` tags.
-		tagsAndMeta.SetSystemTagOrMetaIfEnabled(enabledTags, metrics.TagName, cleanURL)
-		tagsAndMeta.SetSystemTagOrMetaIfEnabled(enabledTags, metrics.TagURL, cleanURL)
-	} else {
-		// However, if the user set the `name` tag value somehow, we will use
-		// whatever they set as the value of the `url` tags too, to prevent
-		// high-cardinality values in the indexed tags.
-		tagsAndMeta.SetSystemTagOrMetaIfEnabled(enabledTags, metrics.TagURL, nameTagValue)
-	}
-
-	tagsAndMeta.SetSystemTagOrMetaIfEnabled(enabledTags, metrics.TagMethod, unfReq.request.Method)
+	tagsAndMeta := requestTagsAndMeta(t.tagsAndMeta, enabledTags, unfReq.request)
 
 	if unfReq.err != nil {
 		result.errorCode, result.errorMsg = errorCodeForError(unfReq.err)
@@ -129,6 +134,11 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 			tagsAndMeta.SetSystemTagOrMeta(metrics.TagIP, ip)
 		}
 	}
+	if enabledTags.Has(metrics.TagProxy) {
+		if proxy := proxyFromContext(unfReq.request.Context()); proxy != nil {
+			tagsAndMeta.SetSystemTagOrMeta(metrics.TagProxy, proxyTagValue(proxy))
+		}
+	}
 	var failed float64
 	if t.responseCallback != nil {
 		var statusCode int
@@ -144,11 +154,27 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 	}
 
 	trail.SaveSamples(t.state.BuiltinMetrics, &tagsAndMeta)
+
+	// The attempts of a request which have been retried are counted by the
+	// http_req_retries metric, rather than as requests.
+	retried := isRetriedAttempt(unfReq.ctx)
+	if retried {
+		samples := trail.Samples[:0]
+		for _, sample := range trail.Samples {
+			if sample.Metric != t.state.BuiltinMetrics.HTTPReqs {
+				samples = append(samples, sample)
+			}
+		}
+		trail.Samples = samples
+	}
+
 	if t.responseCallback != nil {
 		trail.Failed.Valid = true
 		if failed == 1 {
 			trail.Failed.Bool = true
 		}
+	}
+	if t.responseCallback != nil && !retried {
 		trail.Samples = append(trail.Samples,
 			metrics.Sample{
 				TimeSeries: metrics.TimeSeries{
@@ -161,10 +187,69 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 			},
 		)
 	}
+	trail.Samples = append(trail.Samples, t.http2Samples(result, trail.EndTime, tagsAndMeta)...)
 	metrics.PushIfNotDone(t.ctx, t.state.Samples, trail)
 	return result
 }
 
+// http2Samples returns the samples of the HTTP/2-specific metrics of the
+// request: the number of streams open over its connection, and whether it
+// failed because of a GOAWAY or a RST_STREAM frame the server sent.
+func (t *transport) http2Samples(req *finishedRequest, endTime time.Time, ctm metrics.TagsAndMeta) []metrics.Sample {
+	var samples []metrics.Sample
+	newSample := func(metric *metrics.Metric, value float64) {
+		samples = append(samples, metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: metric, Tags: ctm.Tags},
+			Time:       endTime,
+			Metadata:   ctm.Metadata,
+			Value:      value,
+		})
+	}
+
+	if req.h2Streams > 0 {
+		newSample(t.state.BuiltinMetrics.HTTPReqH2Streams, float64(req.h2Streams))
+	}
+	switch {
+	case req.errorCode >= unknownHTTP2GoAwayErrorCode && req.errorCode < unknownHTTP2StreamErrorCode:
+		newSample(t.state.BuiltinMetrics.HTTPH2GoAways, 1)
+	case req.errorCode >= unknownHTTP2StreamErrorCode && req.errorCode < unknownHTTP2ConnectionErrorCode:
+		newSample(t.state.BuiltinMetrics.HTTPH2StreamResets, 1)
+	}
+
+	return samples
+}
+
+// requestTagsAndMeta returns a copy of the provided tags and metadata, with
+// the system tags describing the given request set.
+func requestTagsAndMeta(
+	tagsAndMeta *metrics.TagsAndMeta,
+	enabledTags *metrics.SystemTagSet,
+	req *http.Request,
+) metrics.TagsAndMeta {
+	result := tagsAndMeta.Clone()
+	cleanURL := URL{u: req.URL, URL: req.URL.String()}.Clean()
+
+	// After k6 v0.41.0, the `name` and `url` tags have the exact same values:
+	nameTagValue, nameTagManuallySet := result.Tags.Get(metrics.TagName.String())
+	if !nameTagManuallySet {
+		// If the user *didn't* manually set a `name` tag value and didn't use
+		// the http.url template literal helper to have k6 automatically set
+		// it (see `lib/netext/httpext.MakeRequest()`), we will use the cleaned
+		// URL value as the value of both `name` and `url` tags.
+		result.SetSystemTagOrMetaIfEnabled(enabledTags, metrics.TagName, cleanURL)
+		result.SetSystemTagOrMetaIfEnabled(enabledTags, metrics.TagURL, cleanURL)
+	} else {
+		// However, if the user set the `name` tag value somehow, we will use
+		// whatever they set as the value of the `url` tags too, to prevent
+		// high-cardinality values in the indexed tags.
+		result.SetSystemTagOrMetaIfEnabled(enabledTags, metrics.TagURL, nameTagValue)
+	}
+
+	result.SetSystemTagOrMetaIfEnabled(enabledTags, metrics.TagMethod, req.Method)
+
+	return result
+}
+
 func (t *transport) saveCurrentRequest(currentRequest *unfinishedRequest) {
 	t.lastRequestLock.Lock()
 	unprocessedRequest := t.lastRequest
@@ -203,8 +288,25 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	ctx := req.Context()
 	tracer := &Tracer{}
-	reqWithTracer := req.WithContext(httptrace.WithClientTrace(ctx, tracer.Trace()))
-	resp, err := t.state.Transport.RoundTrip(reqWithTracer)
+	traceCtx := httptrace.WithClientTrace(ctx, tracer.Trace())
+
+	var connDone func()
+	var h2Streams int64
+	if t.connPool != nil {
+		maxRequests := t.state.Options.MaxRequestsPerConnection.Int64
+		traceCtx = httptrace.WithClientTrace(traceCtx, t.connPool.trace(maxRequests, func(done func(), streams int64) {
+			// The transport may get another connection, when retrying the
+			// request over a broken one.
+			if connDone != nil {
+				connDone()
+			}
+			connDone = done
+			h2Streams = streams
+		}))
+	}
+
+	reqWithTracer := req.WithContext(traceCtx)
+	resp, err := t.roundTripper.RoundTrip(reqWithTracer)
 
 	var netError net.Error
 	if errors.As(err, &netError) && netError.Timeout() {
@@ -217,11 +319,13 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	}
 
 	t.saveCurrentRequest(&unfinishedRequest{
-		ctx:      ctx,
-		tracer:   tracer,
-		request:  req,
-		response: resp,
-		err:      err,
+		ctx:       ctx,
+		tracer:    tracer,
+		request:   req,
+		response:  resp,
+		err:       err,
+		connDone:  connDone,
+		h2Streams: h2Streams,
 	})
 
 	return resp, err
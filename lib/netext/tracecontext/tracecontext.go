@@ -0,0 +1,116 @@
+// Package tracecontext generates the trace context of the HTTP and gRPC
+// requests, and propagates it in the W3C Trace Context and B3 formats, so the
+// backends can correlate their spans with the metrics of the requests.
+package tracecontext
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	// W3C is the name of the propagator of the W3C Trace Context `traceparent` header.
+	W3C = "w3c"
+
+	// B3 is the name of the propagator of the B3 single `b3` header.
+	B3 = "b3"
+
+	// W3CHeaderName is the name of the W3C Trace Context header.
+	W3CHeaderName = "traceparent"
+
+	// B3HeaderName is the name of the B3 single header.
+	B3HeaderName = "b3"
+
+	// MetadataKey is the key of the trace ID in the metadata of the samples.
+	MetadataKey = "trace_id"
+)
+
+// TraceContext is the trace context of a request, which is the root span of
+// its trace.
+type TraceContext struct {
+	// TraceID is the hex-encoded 16 bytes ID of the trace.
+	TraceID string
+	// SpanID is the hex-encoded 8 bytes ID of the span.
+	SpanID string
+	// Sampled is the sampling decision of the trace.
+	Sampled bool
+}
+
+// New returns a new trace context with random IDs, which is sampled with the
+// given probability, between 0 and 1.
+func New(sampling float64) TraceContext {
+	var traceID [16]byte
+	var spanID [8]byte
+	_, _ = rand.Read(traceID[:]) //nolint:gosec
+	_, _ = rand.Read(spanID[:])  //nolint:gosec
+
+	return TraceContext{
+		TraceID: hex.EncodeToString(traceID[:]),
+		SpanID:  hex.EncodeToString(spanID[:]),
+		Sampled: sampling >= 1 || rand.Float64() < sampling, //nolint:gosec
+	}
+}
+
+// ParsePropagators parses a comma-separated list of propagators, e.g. "w3c,b3".
+func ParsePropagators(s string) ([]string, error) {
+	var propagators []string
+	for _, p := range strings.Split(s, ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch p {
+		case "":
+			continue
+		case W3C, B3:
+			propagators = append(propagators, p)
+		default:
+			return nil, fmt.Errorf("unsupported trace propagator %q, it should be %s or %s", p, W3C, B3)
+		}
+	}
+	return propagators, nil
+}
+
+// Headers returns the headers propagating the trace context in the formats of
+// the given propagators.
+func (tc TraceContext) Headers(propagators []string) map[string]string {
+	headers := make(map[string]string, len(propagators))
+	for _, p := range propagators {
+		switch p {
+		case W3C:
+			flags := "00"
+			if tc.Sampled {
+				flags = "01"
+			}
+			headers[W3CHeaderName] = "00-" + tc.TraceID + "-" + tc.SpanID + "-" + flags
+		case B3:
+			flags := "0"
+			if tc.Sampled {
+				flags = "1"
+			}
+			headers[B3HeaderName] = tc.TraceID + "-" + tc.SpanID + "-" + flags
+		}
+	}
+	return headers
+}
+
+// FromOptions returns a new trace context, and the propagators to propagate it
+// with, as the tracePropagator and traceSampling options configure it. It
+// returns false if the propagation is disabled.
+func FromOptions(propagator null.String, sampling null.Float) (TraceContext, []string, bool) {
+	if !propagator.Valid || propagator.String == "" {
+		return TraceContext{}, nil, false
+	}
+	// The options are validated before the test starts.
+	propagators, err := ParsePropagators(propagator.String)
+	if err != nil || len(propagators) == 0 {
+		return TraceContext{}, nil, false
+	}
+
+	rate := 1.0
+	if sampling.Valid {
+		rate = sampling.Float64
+	}
+	return New(rate), propagators, true
+}
@@ -0,0 +1,43 @@
+package tracecontext
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePropagators(t *testing.T) {
+	t.Parallel()
+
+	propagators, err := ParsePropagators("W3C, b3,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{W3C, B3}, propagators)
+
+	propagators, err = ParsePropagators("")
+	require.NoError(t, err)
+	assert.Empty(t, propagators)
+
+	_, err = ParsePropagators("w3c,jaeger")
+	require.EqualError(t, err, `unsupported trace propagator "jaeger", it should be w3c or b3`)
+}
+
+func TestHeaders(t *testing.T) {
+	t.Parallel()
+
+	tc := New(1)
+	assert.Len(t, tc.TraceID, 32)
+	assert.Len(t, tc.SpanID, 16)
+	assert.True(t, tc.Sampled)
+	assert.False(t, New(0).Sampled)
+
+	headers := tc.Headers([]string{W3C, B3})
+	assert.Equal(t, "00-"+tc.TraceID+"-"+tc.SpanID+"-01", headers[W3CHeaderName])
+	assert.Equal(t, tc.TraceID+"-"+tc.SpanID+"-1", headers[B3HeaderName])
+	assert.Regexp(t, regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-0[01]$`), headers[W3CHeaderName])
+
+	tc.Sampled = false
+	headers = tc.Headers([]string{B3})
+	assert.Equal(t, map[string]string{B3HeaderName: tc.TraceID + "-" + tc.SpanID + "-0"}, headers)
+}
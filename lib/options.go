@@ -9,6 +9,7 @@ import (
 	"net"
 	"reflect"
 
+	"go.k6.io/k6/lib/netext/tracecontext"
 	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 	"gopkg.in/guregu/null.v3"
@@ -270,6 +271,11 @@ type Options struct {
 	// Should all HTTP requests and responses be logged (excluding body)?
 	HTTPDebug null.String `json:"httpDebug" envconfig:"K6_HTTP_DEBUG"`
 
+	// Propagate a trace context with the HTTP and gRPC requests, in the formats of these
+	// comma-separated propagators ("w3c", "b3"), and sample that ratio of the traces.
+	TracePropagator null.String `json:"tracePropagator" envconfig:"K6_TRACE_PROPAGATOR"`
+	TraceSampling   null.Float  `json:"traceSampling" envconfig:"K6_TRACE_SAMPLING"`
+
 	// Accept invalid or untrusted TLS certificates.
 	InsecureSkipTLSVerify null.Bool `json:"insecureSkipTLSVerify" envconfig:"K6_INSECURE_SKIP_TLS_VERIFY"`
 
@@ -295,6 +301,9 @@ type Options struct {
 	// Hosts overrides dns entries for given hosts
 	Hosts types.NullHosts `json:"hosts" envconfig:"K6_HOSTS"`
 
+	// Rules grouping the URLs of the HTTP requests under their name tags
+	URLGrouping types.NullURLGrouping `json:"urlGrouping" envconfig:"K6_URL_GROUPING"`
+
 	// Disable keep-alive connections
 	NoConnectionReuse null.Bool `json:"noConnectionReuse" envconfig:"K6_NO_CONNECTION_REUSE"`
 
@@ -303,6 +312,14 @@ type Options struct {
 	// errors about running out of file handles or sockets, or being unable to bind addresses.
 	NoVUConnectionReuse null.Bool `json:"noVUConnectionReuse" envconfig:"K6_NO_VU_CONNECTION_REUSE"`
 
+	// Maximum number of idle connections kept per host by the connection pool of each VU,
+	// which defaults to batchPerHost.
+	MaxIdleConnsPerHost null.Int `json:"maxIdleConnsPerHost" envconfig:"K6_MAX_IDLE_CONNS_PER_HOST"`
+
+	// Close the HTTP connections once they served that many requests, forcing new ones to
+	// be opened, as load balancers recycling the connections do.
+	MaxRequestsPerConnection null.Int `json:"maxRequestsPerConnection" envconfig:"K6_MAX_REQUESTS_PER_CONNECTION"`
+
 	// MinIterationDuration can be used to force VUs to pause between iterations if a specific
 	// iteration is shorter than the specified value.
 	MinIterationDuration types.NullDuration `json:"minIterationDuration" envconfig:"K6_MIN_ITERATION_DURATION"`
@@ -321,6 +338,14 @@ type Options struct {
 	// Summary time unit for summary metrics (response times) in CLI output
 	SummaryTimeUnit null.String `json:"summaryTimeUnit" envconfig:"K6_SUMMARY_TIME_UNIT"`
 
+	// Keep all the values of trend metrics to calculate exact percentiles,
+	// instead of estimating them from histograms
+	ExactTrendPercentiles null.Bool `json:"exactTrendPercentiles" envconfig:"K6_EXACT_TREND_PERCENTILES"`
+
+	// Precision, in significant digits, of the percentiles estimated from the
+	// histograms of trend metrics
+	TrendPercentilesPrecision null.Int `json:"trendPercentilesPrecision" envconfig:"K6_TREND_PERCENTILES_PRECISION"`
+
 	// Which system tags to include with metrics ("method", "vu" etc.)
 	// Use pointer for identifying whether user provide any tag or not.
 	SystemTags *metrics.SystemTagSet `json:"systemTags" envconfig:"K6_SYSTEM_TAGS"`
@@ -331,6 +356,23 @@ type Options struct {
 	// Buffer size of the channel for metric samples; 0 means unbuffered
 	MetricSamplesBufferSize null.Int `json:"metricSamplesBufferSize" envconfig:"K6_METRIC_SAMPLES_BUFFER_SIZE"`
 
+	// Limit of the unique tag sets of every metric, past which the new values
+	// of its highest-cardinality tag are folded into "_other"; 0 means no limit
+	MetricCardinalityLimit null.Int `json:"metricCardinalityLimit" envconfig:"K6_METRIC_CARDINALITY_LIMIT"`
+
+	// Limit of the unique values of every tag of every metric, past which its
+	// new values are folded into "_other"; 0 means no limit
+	TagCardinalityLimit null.Int `json:"tagCardinalityLimit" envconfig:"K6_TAG_CARDINALITY_LIMIT"`
+
+	// Emit the loadgen_* metrics of the resources the load generator itself
+	// uses, to tell when it's the bottleneck
+	LoadGeneratorMetrics null.Bool `json:"loadGeneratorMetrics" envconfig:"K6_LOAD_GENERATOR_METRICS"`
+
+	// Rules redacting the sensitive data, e.g. emails or tokens, from the tags
+	// and the metadata of the samples, and from the logs, before they leave
+	// the process
+	Redact types.NullRedaction `json:"redact" envconfig:"K6_REDACT"`
+
 	// Do not reset cookies after a VU iteration
 	NoCookiesReset null.Bool `json:"noCookiesReset" envconfig:"K6_NO_COOKIES_RESET"`
 
@@ -433,6 +475,12 @@ func (o Options) Apply(opts Options) Options {
 	if opts.HTTPDebug.Valid {
 		o.HTTPDebug = opts.HTTPDebug
 	}
+	if opts.TracePropagator.Valid {
+		o.TracePropagator = opts.TracePropagator
+	}
+	if opts.TraceSampling.Valid {
+		o.TraceSampling = opts.TraceSampling
+	}
 	if opts.InsecureSkipTLSVerify.Valid {
 		o.InsecureSkipTLSVerify = opts.InsecureSkipTLSVerify
 	}
@@ -460,12 +508,21 @@ func (o Options) Apply(opts Options) Options {
 	if opts.Hosts.Valid {
 		o.Hosts = opts.Hosts
 	}
+	if opts.URLGrouping.Valid {
+		o.URLGrouping = opts.URLGrouping
+	}
 	if opts.NoConnectionReuse.Valid {
 		o.NoConnectionReuse = opts.NoConnectionReuse
 	}
 	if opts.NoVUConnectionReuse.Valid {
 		o.NoVUConnectionReuse = opts.NoVUConnectionReuse
 	}
+	if opts.MaxIdleConnsPerHost.Valid {
+		o.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	}
+	if opts.MaxRequestsPerConnection.Valid {
+		o.MaxRequestsPerConnection = opts.MaxRequestsPerConnection
+	}
 	if opts.MinIterationDuration.Valid {
 		o.MinIterationDuration = opts.MinIterationDuration
 	}
@@ -484,6 +541,12 @@ func (o Options) Apply(opts Options) Options {
 	if opts.SummaryTimeUnit.Valid {
 		o.SummaryTimeUnit = opts.SummaryTimeUnit
 	}
+	if opts.ExactTrendPercentiles.Valid {
+		o.ExactTrendPercentiles = opts.ExactTrendPercentiles
+	}
+	if opts.TrendPercentilesPrecision.Valid {
+		o.TrendPercentilesPrecision = opts.TrendPercentilesPrecision
+	}
 	if opts.SystemTags != nil {
 		o.SystemTags = opts.SystemTags
 	}
@@ -493,6 +556,18 @@ func (o Options) Apply(opts Options) Options {
 	if opts.MetricSamplesBufferSize.Valid {
 		o.MetricSamplesBufferSize = opts.MetricSamplesBufferSize
 	}
+	if opts.MetricCardinalityLimit.Valid {
+		o.MetricCardinalityLimit = opts.MetricCardinalityLimit
+	}
+	if opts.TagCardinalityLimit.Valid {
+		o.TagCardinalityLimit = opts.TagCardinalityLimit
+	}
+	if opts.LoadGeneratorMetrics.Valid {
+		o.LoadGeneratorMetrics = opts.LoadGeneratorMetrics
+	}
+	if opts.Redact.Valid {
+		o.Redact = opts.Redact
+	}
 	if opts.DiscardResponseBodies.Valid {
 		o.DiscardResponseBodies = opts.DiscardResponseBodies
 	}
@@ -534,6 +609,26 @@ func (o Options) Validate() []error {
 					o.ExecutionSegment, o.ExecutionSegmentSequence))
 		}
 	}
+	if p := o.TrendPercentilesPrecision; p.Valid &&
+		(p.Int64 < metrics.MinTrendPrecision || p.Int64 > metrics.MaxTrendPrecision) {
+		errors = append(errors,
+			fmt.Errorf("the trend percentiles precision must be between %d and %d significant digits, not %d",
+				metrics.MinTrendPrecision, metrics.MaxTrendPrecision, p.Int64))
+	}
+	if o.TracePropagator.Valid {
+		if _, err := tracecontext.ParsePropagators(o.TracePropagator.String); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	if l := o.MetricCardinalityLimit; l.Valid && l.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the metric cardinality limit can't be negative, got %d", l.Int64))
+	}
+	if l := o.TagCardinalityLimit; l.Valid && l.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the tag cardinality limit can't be negative, got %d", l.Int64))
+	}
+	if s := o.TraceSampling; s.Valid && (s.Float64 < 0 || s.Float64 > 1) {
+		errors = append(errors, fmt.Errorf("the trace sampling must be between 0 and 1, not %g", s.Float64))
+	}
 	return append(errors, o.Scenarios.Validate()...)
 }
 
@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"net"
+	"net/url"
 	"reflect"
 	"testing"
 	"time"
@@ -411,6 +412,68 @@ func TestOptions(t *testing.T) {
 		assert.True(t, opts.NoVUConnectionReuse.Valid)
 		assert.True(t, opts.NoVUConnectionReuse.Bool)
 	})
+	t.Run("MaxIdleConnsPerHost", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{MaxIdleConnsPerHost: null.IntFrom(4)})
+		assert.True(t, opts.MaxIdleConnsPerHost.Valid)
+		assert.Equal(t, int64(4), opts.MaxIdleConnsPerHost.Int64)
+	})
+	t.Run("MaxRequestsPerConnection", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{MaxRequestsPerConnection: null.IntFrom(100)})
+		assert.True(t, opts.MaxRequestsPerConnection.Valid)
+		assert.Equal(t, int64(100), opts.MaxRequestsPerConnection.Int64)
+	})
+	t.Run("ExactTrendPercentiles", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{ExactTrendPercentiles: null.BoolFrom(true)})
+		assert.True(t, opts.ExactTrendPercentiles.Valid)
+		assert.True(t, opts.ExactTrendPercentiles.Bool)
+	})
+	t.Run("LoadGeneratorMetrics", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{LoadGeneratorMetrics: null.BoolFrom(true)})
+		assert.True(t, opts.LoadGeneratorMetrics.Valid)
+		assert.True(t, opts.LoadGeneratorMetrics.Bool)
+	})
+	t.Run("TrendPercentilesPrecision", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{TrendPercentilesPrecision: null.IntFrom(4)})
+		assert.True(t, opts.TrendPercentilesPrecision.Valid)
+		assert.Equal(t, int64(4), opts.TrendPercentilesPrecision.Int64)
+	})
+	t.Run("TracePropagator", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{TracePropagator: null.StringFrom("w3c,b3")})
+		assert.True(t, opts.TracePropagator.Valid)
+		assert.Equal(t, "w3c,b3", opts.TracePropagator.String)
+	})
+	t.Run("TraceSampling", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{TraceSampling: null.FloatFrom(0.5)})
+		assert.True(t, opts.TraceSampling.Valid)
+		assert.Equal(t, 0.5, opts.TraceSampling.Float64)
+	})
+	t.Run("MetricCardinalityLimit", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{MetricCardinalityLimit: null.IntFrom(1000)})
+		assert.True(t, opts.MetricCardinalityLimit.Valid)
+		assert.Equal(t, int64(1000), opts.MetricCardinalityLimit.Int64)
+	})
+	t.Run("TagCardinalityLimit", func(t *testing.T) {
+		t.Parallel()
+		opts := Options{}.Apply(Options{TagCardinalityLimit: null.IntFrom(100)})
+		assert.True(t, opts.TagCardinalityLimit.Valid)
+		assert.Equal(t, int64(100), opts.TagCardinalityLimit.Int64)
+	})
+	t.Run("Redact", func(t *testing.T) {
+		t.Parallel()
+		redaction, err := types.NewNullRedaction([]types.RedactionRule{{Match: "token=[^&]+"}})
+		require.NoError(t, err)
+		opts := Options{}.Apply(Options{Redact: redaction})
+		assert.True(t, opts.Redact.Valid)
+		assert.Equal(t, "/?[REDACTED]&page=2", opts.Redact.RedactTag("url", "/?token=s3cr3t&page=2"))
+	})
 	t.Run("NoCookiesReset", func(t *testing.T) {
 		t.Parallel()
 		opts := Options{}.Apply(Options{NoCookiesReset: null.BoolFrom(true)})
@@ -470,6 +533,20 @@ func TestOptions(t *testing.T) {
 		assert.Equal(t, "192.0.2.1:80", opts.Hosts.Trie.Match("test.loadimpact.com").String())
 	})
 
+	t.Run("URLGrouping", func(t *testing.T) {
+		t.Parallel()
+		grouping, err := types.NewNullURLGrouping([]types.URLGroupingRule{{Path: "/users/{id}"}})
+		require.NoError(t, err)
+		opts := Options{}.Apply(Options{URLGrouping: grouping})
+		assert.True(t, opts.URLGrouping.Valid)
+
+		u, err := url.Parse("https://test.k6.io/users/1")
+		require.NoError(t, err)
+		name, ok := opts.URLGrouping.Name(u, u.String())
+		assert.True(t, ok)
+		assert.Equal(t, "https://test.k6.io/users/{id}", name)
+	})
+
 	t.Run("Throws", func(t *testing.T) {
 		t.Parallel()
 		opts := Options{}.Apply(Options{Throw: null.BoolFrom(true)})
@@ -639,6 +716,59 @@ func TestOptionsEnv(t *testing.T) {
 			"true":  null.BoolFrom(true),
 			"false": null.BoolFrom(false),
 		},
+		{"MaxIdleConnsPerHost", "K6_MAX_IDLE_CONNS_PER_HOST"}: {
+			"":  null.Int{},
+			"4": null.IntFrom(4),
+			"0": null.IntFrom(0),
+		},
+		{"MaxRequestsPerConnection", "K6_MAX_REQUESTS_PER_CONNECTION"}: {
+			"":    null.Int{},
+			"100": null.IntFrom(100),
+		},
+		{"ExactTrendPercentiles", "K6_EXACT_TREND_PERCENTILES"}: {
+			"":      null.Bool{},
+			"true":  null.BoolFrom(true),
+			"false": null.BoolFrom(false),
+		},
+		{"LoadGeneratorMetrics", "K6_LOAD_GENERATOR_METRICS"}: {
+			"":      null.Bool{},
+			"true":  null.BoolFrom(true),
+			"false": null.BoolFrom(false),
+		},
+		{"TrendPercentilesPrecision", "K6_TREND_PERCENTILES_PRECISION"}: {
+			"":  null.Int{},
+			"4": null.IntFrom(4),
+		},
+		{"TracePropagator", "K6_TRACE_PROPAGATOR"}: {
+			"":   null.String{},
+			"b3": null.StringFrom("b3"),
+		},
+		{"TraceSampling", "K6_TRACE_SAMPLING"}: {
+			"":    null.Float{},
+			"0.1": null.FloatFrom(0.1),
+		},
+		{"MetricCardinalityLimit", "K6_METRIC_CARDINALITY_LIMIT"}: {
+			"":     null.Int{},
+			"5000": null.IntFrom(5000),
+		},
+		{"TagCardinalityLimit", "K6_TAG_CARDINALITY_LIMIT"}: {
+			"":    null.Int{},
+			"100": null.IntFrom(100),
+		},
+		{"Redact", "K6_REDACT"}: {
+			"":            types.NullRedaction{},
+			"token=[^&]+": mustNullRedaction(types.RedactionRule{Match: "token=[^&]+"}),
+			`[{"tags":["user"],"replacement":"-"}]`: mustNullRedaction(
+				types.RedactionRule{Tags: []string{"user"}, Replacement: "-"},
+			),
+		},
+		{"URLGrouping", "K6_URL_GROUPING"}: {
+			"": types.NullURLGrouping{},
+			"/users/{id},/orders/{id}": mustNullURLGrouping(
+				types.URLGroupingRule{Path: "/users/{id}"}, types.URLGroupingRule{Path: "/orders/{id}"},
+			),
+			`[{"match":"/v[0-9]/","name":"v"}]`: mustNullURLGrouping(types.URLGroupingRule{Match: "/v[0-9]/", Name: "v"}),
+		},
 		{"UserAgent", "K6_USER_AGENT"}: {
 			"":    null.String{},
 			"Hi!": null.StringFrom("Hi!"),
@@ -789,3 +919,53 @@ func TestHost(t *testing.T) {
 		})
 	}
 }
+
+func TestOptionsValidateTraceContext(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, Options{
+		TracePropagator: null.StringFrom("w3c,b3"),
+		TraceSampling:   null.FloatFrom(0.25),
+	}.Validate())
+
+	errs := Options{
+		TracePropagator: null.StringFrom("w3c,jaeger"),
+		TraceSampling:   null.FloatFrom(1.5),
+	}.Validate()
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], `unsupported trace propagator "jaeger", it should be w3c or b3`)
+	assert.EqualError(t, errs[1], "the trace sampling must be between 0 and 1, not 1.5")
+}
+
+func TestOptionsValidateCardinalityLimits(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, Options{
+		MetricCardinalityLimit: null.IntFrom(1000),
+		TagCardinalityLimit:    null.IntFrom(0),
+	}.Validate())
+
+	errs := Options{
+		MetricCardinalityLimit: null.IntFrom(-1),
+		TagCardinalityLimit:    null.IntFrom(-2),
+	}.Validate()
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "the metric cardinality limit can't be negative, got -1")
+	assert.EqualError(t, errs[1], "the tag cardinality limit can't be negative, got -2")
+}
+
+func mustNullURLGrouping(rules ...types.URLGroupingRule) types.NullURLGrouping {
+	grouping, err := types.NewNullURLGrouping(rules)
+	if err != nil {
+		panic(err)
+	}
+	return grouping
+}
+
+func mustNullRedaction(rules ...types.RedactionRule) types.NullRedaction {
+	redaction, err := types.NewNullRedaction(rules)
+	if err != nil {
+		panic(err)
+	}
+	return redaction
+}
@@ -0,0 +1,4 @@
+// Package recorder implements the recording of the requests made through a
+// local HTTP proxy, which intercepts the TLS connections with the certificates
+// of its own CA, and the generation of a k6 script replaying them.
+package recorder
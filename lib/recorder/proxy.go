@@ -0,0 +1,254 @@
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/lib/types"
+)
+
+// hopByHopHeaders are the headers which apply to a single connection, so they
+// aren't forwarded by the proxy.
+//
+//nolint:gochecknoglobals
+var hopByHopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// Proxy is an HTTP proxy recording the requests made through it. The HTTPS
+// requests, tunneled with the CONNECT method, are intercepted with the
+// certificates of the CA.
+type Proxy struct {
+	ca        *CA
+	recording *Recording
+	logger    logrus.FieldLogger
+
+	// Transport makes the requests to the servers.
+	Transport http.RoundTripper
+	// Hosts are the hostnames the requests to are recorded, or all of them if
+	// it's nil. The connections to the other hosts are tunneled as they are.
+	Hosts *types.HostnameTrie
+}
+
+// NewProxy creates a new Proxy adding the requests to the recording.
+func NewProxy(ca *CA, recording *Recording, logger logrus.FieldLogger) *Proxy {
+	return &Proxy{
+		ca:        ca,
+		recording: recording,
+		logger:    logger,
+		Transport: http.DefaultTransport.(*http.Transport).Clone(), //nolint:forcetypeassert
+	}
+}
+
+// ServeHTTP implements [http.Handler].
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.serveConnect(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "this is a recording proxy, configure it as the HTTP proxy of the client", http.StatusBadRequest)
+		return
+	}
+
+	r.RequestURI = ""
+	resp := p.roundTrip(r)
+	defer func() { _ = resp.Body.Close() }()
+
+	for name, values := range resp.Header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+func (p *Proxy) record(host string) bool {
+	if p.Hosts == nil {
+		return true
+	}
+	_, ok := p.Hosts.Contains(host)
+	return ok
+}
+
+func (p *Proxy) serveConnect(w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "the connection can't be hijacked", http.StatusInternalServerError)
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		p.logger.WithError(err).Debug("Hijacking the connection failed")
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		return
+	}
+
+	host := r.URL.Hostname()
+	if !p.record(host) {
+		p.tunnel(conn, r.Host)
+		return
+	}
+
+	tlsConn := tls.Server(conn, &tls.Config{ //nolint:gosec
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName != "" {
+				return p.ca.certificate(hello.ServerName)
+			}
+			return p.ca.certificate(host)
+		},
+		NextProtos: []string{"http/1.1"},
+	})
+	if err = tlsConn.Handshake(); err != nil {
+		p.logger.WithError(err).Warnf("The TLS handshake with the client for %s failed, does it trust the CA?", r.Host)
+		return
+	}
+
+	reader := bufio.NewReader(tlsConn)
+	for {
+		req, err := http.ReadRequest(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				p.logger.WithError(err).Debug("Reading a request failed")
+			}
+			return
+		}
+		req.URL.Scheme = "https"
+		req.URL.Host = r.Host
+		req.RequestURI = ""
+
+		resp := p.roundTrip(req)
+		err = resp.Write(tlsConn)
+		_ = resp.Body.Close()
+		if err != nil || req.Close || resp.Close {
+			return
+		}
+	}
+}
+
+// tunnel relays the connection to the address, without intercepting it.
+func (p *Proxy) tunnel(conn net.Conn, addr string) {
+	target, err := net.Dial("tcp", addr)
+	if err != nil {
+		p.logger.WithError(err).Debugf("Connecting to %s failed", addr)
+		return
+	}
+	defer func() { _ = target.Close() }()
+
+	go func() { _, _ = io.Copy(target, conn) }()
+	_, _ = io.Copy(conn, target)
+}
+
+// roundTrip forwards the request to the server, and records it with its
+// response. It returns a 502 response if the request failed.
+func (p *Proxy) roundTrip(req *http.Request) *http.Response {
+	for _, name := range hopByHopHeaders {
+		req.Header.Del(name)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return errorResponse(req, err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	start := time.Now()
+	resp, err := p.Transport.RoundTrip(req)
+	if err != nil {
+		p.logger.WithError(err).Warnf("The %s request to %s failed", req.Method, req.URL)
+		return errorResponse(req, err)
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return errorResponse(req, err)
+	}
+	duration := time.Since(start)
+
+	for _, name := range hopByHopHeaders {
+		resp.Header.Del(name)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	resp.ContentLength = int64(len(respBody))
+	resp.TransferEncoding = nil
+
+	if p.record(req.URL.Hostname()) {
+		p.recording.Add(Entry{
+			StartedAt:      start,
+			Duration:       duration,
+			Method:         req.Method,
+			URL:            req.URL.String(),
+			Header:         req.Header.Clone(),
+			Body:           body,
+			Status:         resp.StatusCode,
+			ResponseHeader: resp.Header.Clone(),
+			ResponseBody:   decodeBody(resp.Header.Get("Content-Encoding"), respBody),
+		})
+		p.logger.Infof("Recorded %s %s %d", req.Method, req.URL, resp.StatusCode)
+	}
+
+	return resp
+}
+
+func errorResponse(req *http.Request, err error) *http.Response {
+	body := fmt.Sprintf("k6 record: the request failed: %s", err)
+	return &http.Response{
+		StatusCode:    http.StatusBadGateway,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// decodeBody decodes the body of a response with the given content encoding,
+// or returns it as it is, if it isn't supported.
+func decodeBody(encoding string, body []byte) []byte {
+	var reader io.ReadCloser
+	switch strings.ToLower(encoding) {
+	case "gzip":
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return body
+		}
+		reader = r
+	case "deflate":
+		r, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return body
+		}
+		reader = r
+	default:
+		return body
+	}
+	defer func() { _ = reader.Close() }()
+
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return body
+	}
+	return decoded
+}
@@ -0,0 +1,137 @@
+package recorder
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/lib/types"
+)
+
+func newTestProxy(t *testing.T, backend *httptest.Server) (*Proxy, *Recording, *http.Client) {
+	t.Helper()
+
+	ca, err := NewCA()
+	require.NoError(t, err)
+	recording := &Recording{}
+	proxy := NewProxy(ca, recording, testutils.NewLogger(t))
+	proxy.Transport = backend.Client().Transport
+
+	server := httptest.NewServer(proxy)
+	t.Cleanup(server.Close)
+	proxyURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(ca.CertificatePEM()))
+	transport := backend.Client().Transport.(*http.Transport).Clone() //nolint:forcetypeassert
+	transport.Proxy = http.ProxyURL(proxyURL)
+	transport.TLSClientConfig = &tls.Config{RootCAs: roots} //nolint:gosec
+
+	return proxy, recording, &http.Client{Transport: transport}
+}
+
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = io.WriteString(w, r.Method+" "+r.URL.Path+" "+string(body))
+}
+
+func TestProxyHTTP(t *testing.T) {
+	t.Parallel()
+
+	backend := httptest.NewServer(http.HandlerFunc(echoHandler))
+	t.Cleanup(backend.Close)
+	_, recording, client := newTestProxy(t, backend)
+
+	resp, err := client.Get(backend.URL + "/path?q=1")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "GET /path ", string(body))
+
+	entries := recording.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "GET", entries[0].Method)
+	assert.Equal(t, backend.URL+"/path?q=1", entries[0].URL)
+	assert.Equal(t, http.StatusOK, entries[0].Status)
+	assert.Equal(t, "GET /path ", string(entries[0].ResponseBody))
+	assert.Empty(t, entries[0].Header.Get("Proxy-Connection"))
+}
+
+func TestProxyHTTPS(t *testing.T) {
+	t.Parallel()
+
+	backend := httptest.NewTLSServer(http.HandlerFunc(echoHandler))
+	t.Cleanup(backend.Close)
+	_, recording, client := newTestProxy(t, backend)
+
+	for _, body := range []string{"first", "second"} {
+		resp, err := client.Post(backend.URL+"/login", "text/plain", strings.NewReader(body))
+		require.NoError(t, err)
+		respBody, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, "POST /login "+body, string(respBody))
+	}
+
+	entries := recording.Entries()
+	require.Len(t, entries, 2)
+	for i, body := range []string{"first", "second"} {
+		assert.Equal(t, "POST", entries[i].Method)
+		assert.Equal(t, backend.URL+"/login", entries[i].URL)
+		assert.Equal(t, body, string(entries[i].Body))
+		assert.Equal(t, "text/plain", entries[i].Header.Get("Content-Type"))
+	}
+}
+
+func TestProxyHosts(t *testing.T) {
+	t.Parallel()
+
+	backend := httptest.NewTLSServer(http.HandlerFunc(echoHandler))
+	t.Cleanup(backend.Close)
+	proxy, recording, client := newTestProxy(t, backend)
+	hosts, err := types.NewHostnameTrie([]string{"*.k6.local"})
+	require.NoError(t, err)
+	proxy.Hosts = hosts
+
+	// The connection isn't intercepted, so the backend's certificate is presented.
+	transport := client.Transport.(*http.Transport)                                                          //nolint:forcetypeassert
+	transport.TLSClientConfig.RootCAs = backend.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs //nolint:forcetypeassert
+
+	resp, err := client.Get(backend.URL + "/")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, recording.Entries())
+}
+
+func TestLoadCA(t *testing.T) {
+	t.Parallel()
+
+	ca, err := NewCA()
+	require.NoError(t, err)
+	loaded, err := LoadCA(ca.CertificatePEM(), ca.KeyPEM())
+	require.NoError(t, err)
+	assert.Equal(t, ca.cert.Raw, loaded.cert.Raw)
+
+	cert, err := loaded.certificate("test.k6.local")
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test.k6.local"}, leaf.DNSNames)
+	require.NoError(t, leaf.CheckSignatureFrom(loaded.cert))
+
+	_, err = LoadCA([]byte("invalid"), ca.KeyPEM())
+	require.ErrorContains(t, err, "invalid CA certificate or key")
+}
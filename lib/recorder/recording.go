@@ -0,0 +1,53 @@
+package recorder
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Entry is a recorded request and its response.
+type Entry struct {
+	// StartedAt is the time the request was sent at.
+	StartedAt time.Time
+	// Duration is the time it took to receive the whole response.
+	Duration time.Duration
+
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+
+	Status         int
+	ResponseHeader http.Header
+	// ResponseBody is the decoded body of the response.
+	ResponseBody []byte
+}
+
+// Recording holds the entries recorded by a proxy. It's safe for concurrent use.
+type Recording struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// Add adds an entry to the recording.
+func (r *Recording) Add(entry Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, entry)
+}
+
+// Entries returns the recorded entries, in the order the requests were sent.
+func (r *Recording) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make([]Entry, len(r.entries))
+	copy(entries, r.entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedAt.Before(entries[j].StartedAt)
+	})
+	return entries
+}
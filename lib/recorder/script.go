@@ -0,0 +1,255 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// minCorrelationLength is the minimum length of the values of the responses
+	// which are looked for in the following requests, as the shorter ones are
+	// likely to be found by coincidence.
+	minCorrelationLength = 8
+	maxCorrelationLength = 4096
+)
+
+// DefaultMinThinkTime is the default minimum pause between two requests which
+// is replayed with a sleep.
+const DefaultMinThinkTime = time.Second
+
+// skippedHeaders are the request headers which aren't replayed, as k6 sets
+// them itself, e.g. the cookies of its cookie jar, or to not replay the
+// conditional requests of the client's cache.
+//
+//nolint:gochecknoglobals
+var skippedHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Cookie":            true,
+	"If-None-Match":     true,
+	"If-Modified-Since": true,
+}
+
+// ScriptOptions configures the generated script.
+type ScriptOptions struct {
+	// MinThinkTime is the minimum pause between the end of a response and the
+	// next request, which is replayed with a sleep.
+	MinThinkTime time.Duration
+}
+
+// correlation is a value of a response, which may be sent by the following
+// requests.
+type correlation struct {
+	value string
+	// request is the number of the request whose response holds the value.
+	request int
+	// extract is the expression extracting the value from the response.
+	extract string
+}
+
+// GenerateScript generates a k6 script making the requests of the entries,
+// with pauses replaying the think time between them. The values of the
+// responses which are sent by the following requests are pointed out with
+// comments, as they may have to be extracted from the responses to make the
+// script work.
+func GenerateScript(entries []Entry, opts ScriptOptions) []byte {
+	var b bytes.Buffer
+	b.WriteString(`// This script was recorded by k6 record, edit it to make it fit your test.
+import http from "k6/http";
+import { check, sleep } from "k6";
+
+export const options = {
+  vus: 1,
+  iterations: 1,
+};
+
+export default function () {
+`)
+
+	if len(entries) == 0 {
+		b.WriteString("  // No requests were recorded.\n}\n")
+		return b.Bytes()
+	}
+	b.WriteString("  let res;\n")
+
+	var correlations []correlation
+	for i, entry := range entries {
+		if i > 0 {
+			prev := entries[i-1]
+			thinkTime := entry.StartedAt.Sub(prev.StartedAt.Add(prev.Duration))
+			if thinkTime >= opts.MinThinkTime && thinkTime > 0 {
+				fmt.Fprintf(&b, "\n  sleep(%s);\n", strconv.FormatFloat(thinkTime.Round(100*time.Millisecond).Seconds(), 'f', -1, 64))
+			}
+		}
+
+		fmt.Fprintf(&b, "\n  // Request %d: %s %s\n", i+1, entry.Method, entry.URL)
+		writeCorrelationHints(&b, entry, correlations)
+		writeRequest(&b, entry)
+		fmt.Fprintf(&b, "  check(res, { \"status is %d\": (r) => r.status === %d });\n", entry.Status, entry.Status)
+
+		correlations = append(correlations, findCorrelations(i+1, entry)...)
+	}
+
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+func writeRequest(b *bytes.Buffer, entry Entry) {
+	url := quote(entry.URL)
+
+	body := "null"
+	if len(entry.Body) > 0 {
+		if utf8.Valid(entry.Body) {
+			body = quote(string(entry.Body))
+		} else {
+			fmt.Fprintf(b, "  // The binary body of %d bytes isn't replayed.\n", len(entry.Body))
+		}
+	}
+
+	switch entry.Method {
+	case http.MethodGet:
+		fmt.Fprintf(b, "  res = http.get(%s, ", url)
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		fmt.Fprintf(b, "  res = http.%s(%s, %s, ", strings.ToLower(entry.Method), url, body)
+	default:
+		fmt.Fprintf(b, "  res = http.request(%s, %s, %s, ", quote(entry.Method), url, body)
+	}
+
+	names := make([]string, 0, len(entry.Header))
+	for name := range entry.Header {
+		if !skippedHeaders[http.CanonicalHeaderKey(name)] && !strings.HasPrefix(name, ":") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		b.WriteString("{});\n")
+		return
+	}
+
+	b.WriteString("{\n    headers: {\n")
+	for _, name := range names {
+		fmt.Fprintf(b, "      %s: %s,\n", quote(name), quote(strings.Join(entry.Header[name], ", ")))
+	}
+	b.WriteString("    },\n  });\n")
+}
+
+func writeCorrelationHints(b *bytes.Buffer, entry Entry, correlations []correlation) {
+	var sent strings.Builder
+	sent.WriteString(entry.URL)
+	for name, values := range entry.Header {
+		if http.CanonicalHeaderKey(name) != "Cookie" {
+			sent.WriteString("\n" + strings.Join(values, "\n"))
+		}
+	}
+	sent.WriteString("\n")
+	sent.Write(entry.Body)
+	haystack := sent.String()
+
+	hinted := make(map[string]bool)
+	for _, c := range correlations {
+		if hinted[c.value] || !strings.Contains(haystack, c.value) {
+			continue
+		}
+		hinted[c.value] = true
+		fmt.Fprintf(b, "  // Correlation: %s comes from the response to request %d, extract it with %s.\n",
+			quote(abbreviate(c.value)), c.request, c.extract)
+	}
+}
+
+//nolint:gochecknoglobals
+var (
+	inputRegexp = regexp.MustCompile(`(?i)<input\b[^>]*>`)
+	nameRegexp  = regexp.MustCompile(`(?i)\bname\s*=\s*"([^"]*)"`)
+	valueRegexp = regexp.MustCompile(`(?i)\bvalue\s*=\s*"([^"]*)"`)
+)
+
+// findCorrelations returns the values of the response of the entry which may
+// be sent by the following requests: the strings of a JSON body, and the
+// values of the input fields of an HTML body.
+func findCorrelations(request int, entry Entry) []correlation {
+	var correlations []correlation
+	add := func(value, extract string) {
+		if len(value) >= minCorrelationLength && len(value) <= maxCorrelationLength {
+			correlations = append(correlations, correlation{value: value, request: request, extract: extract})
+		}
+	}
+
+	contentType := entry.ResponseHeader.Get("Content-Type")
+	switch {
+	case strings.Contains(contentType, "json"):
+		var body interface{}
+		if json.Unmarshal(entry.ResponseBody, &body) != nil {
+			return nil
+		}
+		walkJSON(body, "", func(path, value string) {
+			add(value, fmt.Sprintf("res.json(%s)", quote(path)))
+		})
+	case strings.Contains(contentType, "html"):
+		for _, input := range inputRegexp.FindAllString(string(entry.ResponseBody), -1) {
+			name, value := nameRegexp.FindStringSubmatch(input), valueRegexp.FindStringSubmatch(input)
+			if name == nil || value == nil {
+				continue
+			}
+			add(value[1], fmt.Sprintf("res.html().find(%s).attr(\"value\")", quote(`input[name="`+name[1]+`"]`)))
+		}
+	}
+	return correlations
+}
+
+// walkJSON calls the function with the path, in the syntax of res.json(), of
+// every string of the JSON value.
+func walkJSON(value interface{}, path string, fn func(path, value string)) {
+	join := func(key string) string {
+		key = strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`).Replace(key)
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	switch v := value.(type) {
+	case string:
+		if path != "" {
+			fn(path, v)
+		}
+	case []interface{}:
+		for i, item := range v {
+			walkJSON(item, join(strconv.Itoa(i)), fn)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			walkJSON(v[key], join(key), fn)
+		}
+	}
+}
+
+// quote returns the string as a JavaScript string literal.
+func quote(s string) string {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func abbreviate(s string) string {
+	const maxLength = 24
+	if utf8.RuneCountInString(s) <= maxLength {
+		return s
+	}
+	return string([]rune(s)[:maxLength]) + "..."
+}
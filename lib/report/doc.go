@@ -0,0 +1,3 @@
+// Package report generates machine-readable end-of-test reports, in a
+// versioned JSON schema, as JUnit XML or as a standalone HTML page.
+package report
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"bytes"
+	_ "embed" // this is used to embed the template of the HTML reports
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed report.html
+var htmlTemplateCode string
+
+//nolint:gochecknoglobals
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"formatValue": formatValue,
+	"percent":     func(v float64) string { return strconv.FormatFloat(v*100, 'f', 2, 64) + "%" },
+	"millis": func(ms float64) string {
+		return time.Duration(ms * float64(time.Millisecond)).Round(time.Millisecond).String()
+	},
+	"sortedMetrics": sortedMetrics,
+	"sortedKeys": func(m map[string]map[string]Metric) []string {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys
+	},
+}).Parse(htmlTemplateCode))
+
+// namedMetric is a metric along with its name and formatted values, in the
+// order of the stats' names.
+type namedMetric struct {
+	Name   string
+	Type   string
+	Values []namedValue
+}
+
+type namedValue struct {
+	Stat  string
+	Value string
+}
+
+func sortedMetrics(observed map[string]Metric) []namedMetric {
+	result := make([]namedMetric, 0, len(observed))
+	for name, m := range observed {
+		stats := make([]string, 0, len(m.Values))
+		for stat := range m.Values {
+			stats = append(stats, stat)
+		}
+		sort.Strings(stats)
+
+		nm := namedMetric{Name: name, Type: m.Type}
+		for _, stat := range stats {
+			nm.Values = append(nm.Values, namedValue{Stat: stat, Value: formatValue(m, stat, m.Values[stat])})
+		}
+		result = append(result, nm)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// formatValue formats the value of a metric's stat as the text summary does.
+func formatValue(m Metric, stat string, value float64) string {
+	switch {
+	case m.Type == "rate" && stat == "rate":
+		return strconv.FormatFloat(value*100, 'f', 2, 64) + "%"
+	case stat == "count" || stat == "passes" || stat == "fails":
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case m.Contains == "time":
+		return time.Duration(value * float64(time.Millisecond)).String()
+	case m.Contains == "data":
+		return strconv.FormatFloat(value, 'f', 0, 64) + " B"
+	default:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+}
+
+// encodeHTML encodes the report as a standalone HTML page, which embeds the
+// JSON report too.
+func (r *Report) encodeHTML() ([]byte, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	err = htmlTemplate.Execute(&b, struct {
+		*Report
+		Failed bool
+		JSON   template.JS
+	}{Report: r, Failed: r.Failed(), JSON: template.JS(data)}) //nolint:gosec // it's JSON encoded
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate the HTML report: %w", err)
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Time     string           `xml:"time,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+
+	junitTestSuite struct {
+		Name      string          `xml:"name,attr"`
+		Tests     int             `xml:"tests,attr"`
+		Failures  int             `xml:"failures,attr"`
+		TestCases []junitTestCase `xml:"testcase"`
+	}
+
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+	}
+
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+func (s *junitTestSuite) add(tc junitTestCase) {
+	s.Tests++
+	if tc.Failure != nil {
+		s.Failures++
+	}
+	s.TestCases = append(s.TestCases, tc)
+}
+
+// encodeJUnit encodes the report as JUnit XML, with a test suite of the
+// thresholds, with a test case for each of them, one of the checks, and one
+// of the baseline comparisons, if any.
+func (r *Report) encodeJUnit() ([]byte, error) {
+	thresholds := junitTestSuite{Name: "thresholds"}
+	for _, t := range r.Thresholds {
+		tc := junitTestCase{Name: t.Source, ClassName: t.Metric}
+		if !t.OK {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("the threshold %s on the metric %s has been crossed", t.Source, t.Metric),
+				Type:    "threshold",
+			}
+		}
+		thresholds.add(tc)
+	}
+
+	checks := junitTestSuite{Name: "checks"}
+	for _, c := range r.Checks {
+		group := strings.TrimPrefix(c.Group, "::")
+		if group == "" {
+			group = "root"
+		}
+		tc := junitTestCase{Name: c.Name, ClassName: group}
+		if c.Fails > 0 {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("the check failed %d times out of %d", c.Fails, c.Passes+c.Fails),
+				Type:    "check",
+			}
+			diagnostics := make([]string, len(c.Failures))
+			for i, f := range c.Failures {
+				diagnostics[i] = fmt.Sprintf("%s, got %s", f.Message, f.Actual)
+			}
+			tc.Failure.Text = strings.Join(diagnostics, "; ")
+		}
+		checks.add(tc)
+	}
+
+	suites := junitTestSuites{
+		Name:   "k6",
+		Time:   fmt.Sprintf("%.3f", r.Metadata.DurationMs/1000),
+		Suites: []junitTestSuite{thresholds, checks},
+	}
+
+	if len(r.Baseline) > 0 {
+		comparisons := junitTestSuite{Name: "baseline"}
+		for _, c := range r.Baseline {
+			tc := junitTestCase{Name: c.Stat, ClassName: c.Metric}
+			if c.Regression {
+				tc.Failure = &junitFailure{
+					Message: fmt.Sprintf("the value regressed from %g to %g, beyond the tolerance of %g%%",
+						c.Baseline, c.Current, c.Tolerance*100),
+					Type: "regression",
+				}
+			}
+			comparisons.add(tc)
+		}
+		suites.Suites = append(suites.Suites, comparisons)
+	}
+
+	for _, s := range suites.Suites {
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,292 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/baseline"
+	"go.k6.io/k6/metrics"
+)
+
+// SchemaVersion is the version of the JSON schema of the reports, which is
+// increased on every backwards incompatible change of it.
+const SchemaVersion = 1
+
+// Format is the format of a report.
+type Format string
+
+const (
+	// JSON is the format of the reports in the versioned JSON schema.
+	JSON Format = "json"
+	// JUnit is the format of the reports as JUnit XML, with a test case for
+	// every threshold and check.
+	JUnit Format = "junit"
+	// HTML is the format of the reports as a standalone HTML page.
+	HTML Format = "html"
+)
+
+// Config is the configuration of a report to generate.
+type Config struct {
+	Format Format
+	// Path is the path of the file the report is written to.
+	Path string
+}
+
+// defaultPaths are the paths the reports are written to by default.
+//
+//nolint:gochecknoglobals
+var defaultPaths = map[Format]string{
+	JSON:  "k6-report.json",
+	JUnit: "k6-report.xml",
+	HTML:  "k6-report.html",
+}
+
+// ParseConfig parses the configuration of a report, of the form
+// format=html[,path=report.html].
+func ParseConfig(s string) (Config, error) {
+	var c Config
+	for _, kv := range strings.Split(s, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(kv), "=")
+		switch k {
+		case "format":
+			c.Format = Format(v)
+		case "path":
+			c.Path = v
+		default:
+			return Config{}, fmt.Errorf("unknown report option %q, the options are format and path", k)
+		}
+	}
+
+	defaultPath, ok := defaultPaths[c.Format]
+	if !ok {
+		return Config{}, fmt.Errorf("invalid report format %q, the formats are json, junit and html", c.Format)
+	}
+	if c.Path == "" {
+		c.Path = defaultPath
+	}
+
+	return c, nil
+}
+
+// Metadata describes the test run of a report.
+type Metadata struct {
+	K6Version string `json:"k6Version"`
+	Script    string `json:"script"`
+	// GitCommit is the SHA of the git commit the test was run from, if known.
+	GitCommit   string      `json:"gitCommit,omitempty"`
+	GeneratedAt time.Time   `json:"generatedAt"`
+	DurationMs  float64     `json:"durationMs"`
+	Options     lib.Options `json:"options"`
+}
+
+// Threshold is the outcome of a threshold.
+type Threshold struct {
+	Metric string `json:"metric"`
+	Source string `json:"source"`
+	OK     bool   `json:"ok"`
+}
+
+// Check is the outcome of a check.
+type Check struct {
+	Name string `json:"name"`
+	// Group is the path of the group of the check, e.g. ::outer::inner.
+	Group  string `json:"group"`
+	Passes int64  `json:"passes"`
+	Fails  int64  `json:"fails"`
+	// Failures hold the diagnostics of the first failures of the check, if
+	// it's an assertion which reports them.
+	Failures []lib.CheckFailure `json:"failures,omitempty"`
+}
+
+// Metric holds the aggregated values of a metric.
+type Metric struct {
+	Type     string             `json:"type"`
+	Contains string             `json:"contains"`
+	Values   map[string]float64 `json:"values"`
+}
+
+// BaselineComparison is the comparison of a metric's stat with its baseline.
+type BaselineComparison struct {
+	Metric   string  `json:"metric"`
+	Stat     string  `json:"stat"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+	// Change is the relative change of the value, or 0 if the baseline
+	// value is 0, as JSON can't encode an infinite change.
+	Change     float64 `json:"change"`
+	Tolerance  float64 `json:"tolerance"`
+	Regression bool    `json:"regression"`
+}
+
+// Report is an end-of-test report.
+type Report struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	Metadata      Metadata          `json:"metadata"`
+	Thresholds    []Threshold       `json:"thresholds"`
+	Checks        []Check           `json:"checks"`
+	Metrics       map[string]Metric `json:"metrics"`
+	// Scenarios hold the metrics observed in each scenario, if the summary
+	// broke them down.
+	Scenarios map[string]map[string]Metric `json:"scenarios,omitempty"`
+	// Baseline holds the comparisons with the baseline, if any.
+	Baseline []BaselineComparison `json:"baseline,omitempty"`
+}
+
+// New creates a report of the test run's summary.
+func New(summary *lib.Summary, metadata Metadata) (*Report, error) {
+	getValues, err := metricValueGetter(metadata.Options.SummaryTrendStats)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata.DurationMs = float64(summary.TestRunDuration) / float64(time.Millisecond)
+	r := &Report{
+		SchemaVersion: SchemaVersion,
+		Metadata:      metadata,
+		Thresholds:    []Threshold{},
+		Checks:        []Check{},
+		Metrics:       exportMetrics(summary.Metrics, getValues, summary.TestRunDuration),
+	}
+
+	names := make([]string, 0, len(summary.Metrics))
+	for name := range summary.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, t := range summary.Metrics[name].Thresholds.Thresholds {
+			r.Thresholds = append(r.Thresholds, Threshold{Metric: name, Source: t.Source, OK: !t.LastFailed})
+		}
+	}
+
+	if summary.RootGroup != nil {
+		r.Checks = appendChecks(r.Checks, summary.RootGroup)
+	}
+
+	if summary.ScenarioMetrics != nil {
+		r.Scenarios = make(map[string]map[string]Metric, len(summary.ScenarioMetrics))
+		for name, observed := range summary.ScenarioMetrics {
+			r.Scenarios[name] = exportMetrics(observed, getValues, summary.TestRunDuration)
+		}
+	}
+
+	for _, c := range summary.BaselineComparisons {
+		r.Baseline = append(r.Baseline, newBaselineComparison(c))
+	}
+
+	return r, nil
+}
+
+// Encode encodes the report in the given format.
+func (r *Report) Encode(format Format) ([]byte, error) {
+	switch format {
+	case JSON:
+		return json.MarshalIndent(r, "", "  ")
+	case JUnit:
+		return r.encodeJUnit()
+	case HTML:
+		return r.encodeHTML()
+	default:
+		return nil, fmt.Errorf("invalid report format %q", format)
+	}
+}
+
+// Failed returns whether any threshold or check of the report failed.
+func (r *Report) Failed() bool {
+	for _, t := range r.Thresholds {
+		if !t.OK {
+			return true
+		}
+	}
+	for _, c := range r.Checks {
+		if c.Fails > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func newBaselineComparison(c baseline.Comparison) BaselineComparison {
+	bc := BaselineComparison(c)
+	if math.IsInf(bc.Change, 0) {
+		bc.Change = 0
+	}
+	return bc
+}
+
+// appendChecks appends the checks of the group and of its subgroups, in the
+// order they were executed for the first time.
+func appendChecks(checks []Check, group *lib.Group) []Check {
+	for _, c := range group.OrderedChecks {
+		checks = append(checks, Check{
+			Name: c.Name, Group: group.Path, Passes: c.Passes, Fails: c.Fails, Failures: c.Failures,
+		})
+	}
+	for _, g := range group.OrderedGroups {
+		checks = appendChecks(checks, g)
+	}
+	return checks
+}
+
+func exportMetrics(
+	observed map[string]*metrics.Metric,
+	getValues func(metrics.Sink, time.Duration) map[string]float64,
+	duration time.Duration,
+) map[string]Metric {
+	exported := make(map[string]Metric, len(observed))
+	for name, m := range observed {
+		exported[name] = Metric{
+			Type:     m.Type.String(),
+			Contains: m.Contains.String(),
+			Values:   getValues(m.Sink, duration),
+		}
+	}
+	return exported
+}
+
+// metricValueGetter returns a function getting the values of the metrics'
+// sinks, as they're shown in the end-of-test summary.
+func metricValueGetter(summaryTrendStats []string) (func(metrics.Sink, time.Duration) map[string]float64, error) {
+	trendResolvers, err := metrics.GetResolversForTrendColumns(summaryTrendStats)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(sink metrics.Sink, t time.Duration) map[string]float64 {
+		switch sink := sink.(type) {
+		case *metrics.CounterSink:
+			rate := 0.0
+			if t > 0 {
+				rate = sink.Value / (float64(t) / float64(time.Second))
+			}
+			return map[string]float64{"count": sink.Value, "rate": rate}
+		case *metrics.GaugeSink:
+			return map[string]float64{"value": sink.Value, "min": sink.Min, "max": sink.Max}
+		case *metrics.RateSink:
+			values := map[string]float64{
+				"passes": float64(sink.Trues),
+				"fails":  float64(sink.Total - sink.Trues),
+			}
+			if sink.Total > 0 {
+				values["rate"] = float64(sink.Trues) / float64(sink.Total)
+			}
+			return values
+		case *metrics.TrendSink:
+			values := make(map[string]float64, len(summaryTrendStats))
+			if sink.IsEmpty() {
+				return values
+			}
+			for _, col := range summaryTrendStats {
+				values[col] = trendResolvers[col](sink)
+			}
+			return values
+		default:
+			return map[string]float64{}
+		}
+	}, nil
+}
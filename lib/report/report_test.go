@@ -0,0 +1,166 @@
+package report
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/baseline"
+	"go.k6.io/k6/metrics"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input    string
+		expected Config
+		err      string
+	}{
+		{input: "format=json", expected: Config{Format: JSON, Path: "k6-report.json"}},
+		{input: "format=junit", expected: Config{Format: JUnit, Path: "k6-report.xml"}},
+		{input: "format=html,path=out/report.html", expected: Config{Format: HTML, Path: "out/report.html"}},
+		{input: "path=report.txt, format=json", expected: Config{Format: JSON, Path: "report.txt"}},
+		{input: "format=pdf", err: `invalid report format "pdf"`},
+		{input: "path=report.json", err: `invalid report format ""`},
+		{input: "format=json,name=report", err: `unknown report option "name"`},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := ParseConfig(tc.input)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, config)
+		})
+	}
+}
+
+func createTestReport(t *testing.T) *Report {
+	t.Helper()
+
+	registry := metrics.NewRegistry()
+	reqs, err := registry.NewMetric("http_reqs", metrics.Counter)
+	require.NoError(t, err)
+	reqs.Sink.Add(metrics.Sample{Value: 10})
+	reqs.Thresholds = metrics.Thresholds{Thresholds: []*metrics.Threshold{{Source: "count>5"}}}
+
+	duration, err := registry.NewMetric("http_req_duration", metrics.Trend, metrics.Time)
+	require.NoError(t, err)
+	for _, v := range []float64{100, 200, 300} {
+		duration.Sink.Add(metrics.Sample{Value: v})
+	}
+	duration.Thresholds = metrics.Thresholds{Thresholds: []*metrics.Threshold{{Source: "avg<150", LastFailed: true}}}
+
+	rootG, err := lib.NewGroup("", nil)
+	require.NoError(t, err)
+	check, err := rootG.Check("status is 200")
+	require.NoError(t, err)
+	check.Passes, check.Fails = 8, 2
+	check.AddFailure(lib.CheckFailure{Message: "expected value to be 200", Expected: "200", Actual: "503"})
+	subG, err := rootG.Group("login")
+	require.NoError(t, err)
+	subCheck, err := subG.Check("logged in")
+	require.NoError(t, err)
+	subCheck.Passes = 10
+
+	r, err := New(&lib.Summary{
+		Metrics: map[string]*metrics.Metric{
+			"http_reqs":         reqs,
+			"http_req_duration": duration,
+		},
+		RootGroup:       rootG,
+		TestRunDuration: 2 * time.Second,
+		BaselineComparisons: []baseline.Comparison{
+			{Metric: "http_req_duration", Stat: "avg", Baseline: 150, Current: 200, Change: 1.0 / 3, Tolerance: 0.1, Regression: true},
+		},
+	}, Metadata{
+		K6Version:   "1.0.0",
+		Script:      "script.js",
+		GitCommit:   "0123456789abcdef",
+		GeneratedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Options:     lib.Options{SummaryTrendStats: []string{"avg", "max"}},
+	})
+	require.NoError(t, err)
+	return r
+}
+
+func TestReportJSON(t *testing.T) {
+	t.Parallel()
+
+	r := createTestReport(t)
+	assert.True(t, r.Failed())
+
+	data, err := r.Encode(JSON)
+	require.NoError(t, err)
+
+	var decoded Report
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, SchemaVersion, decoded.SchemaVersion)
+	assert.Equal(t, "0123456789abcdef", decoded.Metadata.GitCommit)
+	assert.Equal(t, 2000.0, decoded.Metadata.DurationMs)
+	assert.Equal(t, []Threshold{
+		{Metric: "http_req_duration", Source: "avg<150", OK: false},
+		{Metric: "http_reqs", Source: "count>5", OK: true},
+	}, decoded.Thresholds)
+	assert.Equal(t, []Check{
+		{
+			Name: "status is 200", Group: "", Passes: 8, Fails: 2,
+			Failures: []lib.CheckFailure{{Message: "expected value to be 200", Expected: "200", Actual: "503"}},
+		},
+		{Name: "logged in", Group: "::login", Passes: 10},
+	}, decoded.Checks)
+	assert.Equal(t, Metric{
+		Type: "counter", Contains: "default", Values: map[string]float64{"count": 10, "rate": 5},
+	}, decoded.Metrics["http_reqs"])
+	assert.Equal(t, Metric{
+		Type: "trend", Contains: "time", Values: map[string]float64{"avg": 200, "max": 300},
+	}, decoded.Metrics["http_req_duration"])
+	require.Len(t, decoded.Baseline, 1)
+	assert.True(t, decoded.Baseline[0].Regression)
+	assert.Nil(t, decoded.Scenarios)
+}
+
+func TestReportJUnit(t *testing.T) {
+	t.Parallel()
+
+	data, err := createTestReport(t).Encode(JUnit)
+	require.NoError(t, err)
+
+	out := string(data)
+	assert.Contains(t, out, `<testsuites name="k6" tests="5" failures="3" time="2.000">`)
+	assert.Contains(t, out, `<testsuite name="thresholds" tests="2" failures="1">`)
+	assert.Contains(t, out, `<testcase name="avg&lt;150" classname="http_req_duration">`+"\n"+
+		`      <failure message="the threshold avg&lt;150 on the metric http_req_duration has been crossed" type="threshold"></failure>`)
+	assert.Contains(t, out, `<testcase name="count&gt;5" classname="http_reqs"></testcase>`)
+	assert.Contains(t, out, `<testsuite name="checks" tests="2" failures="1">`)
+	assert.Contains(t, out, `<failure message="the check failed 2 times out of 10" type="check">`+
+		"expected value to be 200, got 503</failure>")
+	assert.Contains(t, out, `<testcase name="logged in" classname="login"></testcase>`)
+	assert.Contains(t, out, `<testsuite name="baseline" tests="1" failures="1">`)
+}
+
+func TestReportHTML(t *testing.T) {
+	t.Parallel()
+
+	data, err := createTestReport(t).Encode(HTML)
+	require.NoError(t, err)
+
+	out := string(data)
+	assert.Contains(t, out, "<!DOCTYPE html>")
+	assert.Contains(t, out, "script.js")
+	assert.Contains(t, out, "http_req_duration")
+	assert.Contains(t, out, "status is 200")
+	assert.Contains(t, out, "expected value to be 200, got <code>503</code>")
+	assert.Contains(t, out, `<script type="application/json" id="k6-report">`)
+}
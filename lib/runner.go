@@ -5,6 +5,7 @@ import (
 	"io"
 	"time"
 
+	"go.k6.io/k6/lib/baseline"
 	"go.k6.io/k6/metrics"
 )
 
@@ -36,6 +37,10 @@ type VUActivationParams struct {
 	Env, Tags                map[string]string
 	Exec, Scenario           string
 	GetNextIterationCounters func() (uint64, uint64)
+	// Faults are the faults injected in the traffic of the VU, if any.
+	Faults *Faults
+	// Warmup is the warm-up phase of the scenario, if any.
+	Warmup *Warmup
 }
 
 // A Runner is a factory for VUs. It should precompute as much as possible upon
@@ -71,6 +76,14 @@ type Runner interface {
 	// Runs post-test teardown, if applicable.
 	Teardown(ctx context.Context, out chan<- metrics.SampleContainer) error
 
+	// Runs the given function as the setup of a scenario, returning the json
+	// representation of its result, or nil if it's undefined.
+	ScenarioSetup(ctx context.Context, out chan<- metrics.SampleContainer, fn string) ([]byte, error)
+
+	// Runs the given function as the teardown of a scenario, with the json
+	// representation of the data returned by the scenario setup.
+	ScenarioTeardown(ctx context.Context, out chan<- metrics.SampleContainer, fn string, data []byte) error
+
 	// Get and set options. The initial value will be whatever the script specifies (for JS,
 	// `export let options = {}`); cmd/run.go will mix this in with CLI-, config- and env-provided
 	// values and write it back to the runner.
@@ -98,4 +111,20 @@ type Summary struct {
 	TestRunDuration time.Duration // TODO: use lib.ExecutionState-based interface instead?
 	NoColor         bool          // TODO: drop this when noColor is part of the (runtime) options
 	UIState         UIState
+
+	// ScenarioMetrics and GroupMetrics hold the metrics observed in each
+	// scenario and in each group, indexed by the scenario names and the
+	// group paths, if they were aggregated.
+	ScenarioMetrics map[string]map[string]*metrics.Metric
+	GroupMetrics    map[string]map[string]*metrics.Metric
+
+	// Baseline is the path of the summary the metrics were compared with,
+	// if any, and BaselineComparisons the results of the comparisons.
+	Baseline            string
+	BaselineComparisons []baseline.Comparison
+
+	// AbortReason is why the test run was aborted, if it was, and
+	// FailureReasons why the script marked it as failed, if it did.
+	AbortReason    string
+	FailureReasons []string
 }
@@ -39,11 +39,44 @@ type RuntimeOptions struct {
 	// Environment variables passed onto the runner
 	Env map[string]string `json:"env"`
 
+	// Whether to allow the experimental fs module to write files
+	AllowFileWrites null.Bool `json:"allowFileWrites"`
+
+	// Whether to type-check the script with the TypeScript compiler before
+	// running it, in the experimental_enhanced compatibility mode
+	TypeCheck null.Bool `json:"typeCheck"`
+
+	// ModulesLock is the path of the lock file pinning the integrity hashes
+	// of the remote modules, and ModulesCache the directory they're cached
+	// in, so the next test runs can load them offline.
+	ModulesLock  null.String `json:"modulesLock"`
+	ModulesCache null.String `json:"modulesCache"`
+
+	// SecretSources are the configurations of the sources of the secrets of
+	// the k6/secrets module, e.g. file=secrets.txt,name=db.
+	SecretSources []string `json:"secretSources"`
+
+	// CaptureFailures is the configuration of the capture of the failed
+	// requests and their responses, e.g. path=failures,max=100,body=10240.
+	CaptureFailures null.String `json:"captureFailures"`
+
 	NoThresholds  null.Bool   `json:"noThresholds"`
 	NoSummary     null.Bool   `json:"noSummary"`
 	SummaryExport null.String `json:"summaryExport"`
 	KeyWriter     null.String `json:"-"`
 	TracesOutput  null.String `json:"tracesOutput"`
+
+	// Baseline is the path of the end-of-test summary of a previous test
+	// run, which the summary is compared with, BaselineTolerance the
+	// tolerances of the comparisons, and BaselineFail whether the
+	// regressions beyond them fail the test run.
+	Baseline          null.String `json:"baseline"`
+	BaselineTolerance null.String `json:"baselineTolerance"`
+	BaselineFail      null.Bool   `json:"baselineFail"`
+
+	// Reports are the configurations of the end-of-test reports to generate,
+	// e.g. format=html,path=report.html.
+	Reports []string `json:"reports"`
 }
 
 // ValidateCompatibilityMode checks if the provided val is a valid compatibility mode
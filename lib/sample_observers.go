@@ -0,0 +1,75 @@
+package lib
+
+import (
+	"sync"
+
+	"go.k6.io/k6/metrics"
+)
+
+// SampleObserversDescription is the description of the SampleObservers used to
+// identify and ignore it for the purposes of the cli descriptions.
+const SampleObserversDescription = "Internal sample observers output"
+
+// SampleObservers is an internal output implementation that passes the
+// samples of the test run to the observers registered by the executors, e.g.
+// for them to adjust to the metrics of their iterations.
+type SampleObservers struct {
+	mu        sync.RWMutex
+	observers map[uint64]func(metrics.Sample)
+	lastID    uint64
+}
+
+// NewSampleObservers returns a new SampleObservers without any observer.
+func NewSampleObservers() *SampleObservers {
+	return &SampleObservers{observers: make(map[uint64]func(metrics.Sample))}
+}
+
+// Observe registers the function to be called with every sample of the test
+// run, until the returned function is called. It's called on the goroutine of
+// the outputs, so it must not block.
+func (so *SampleObservers) Observe(fn func(metrics.Sample)) (stop func()) {
+	so.mu.Lock()
+	defer so.mu.Unlock()
+
+	so.lastID++
+	id := so.lastID
+	so.observers[id] = fn
+
+	return func() {
+		so.mu.Lock()
+		defer so.mu.Unlock()
+		delete(so.observers, id)
+	}
+}
+
+// Description is part of the output.Output interface
+func (so *SampleObservers) Description() string {
+	return SampleObserversDescription
+}
+
+// Start is part of the output.Output interface
+func (so *SampleObservers) Start() error {
+	return nil
+}
+
+// Stop is part of the output.Output interface
+func (so *SampleObservers) Stop() error {
+	return nil
+}
+
+// AddMetricSamples is part of the output.Output interface
+func (so *SampleObservers) AddMetricSamples(containers []metrics.SampleContainer) {
+	so.mu.RLock()
+	defer so.mu.RUnlock()
+
+	if len(so.observers) == 0 {
+		return
+	}
+	for _, container := range containers {
+		for _, sample := range container.GetSamples() {
+			for _, fn := range so.observers {
+				fn(sample)
+			}
+		}
+	}
+}
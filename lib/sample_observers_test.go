@@ -0,0 +1,33 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.k6.io/k6/metrics"
+)
+
+func TestSampleObservers(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	metric := registry.MustNewMetric("test_metric", metrics.Trend)
+	sample := func(value float64) metrics.Sample {
+		return metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: metric, Tags: registry.RootTagSet()}, Value: value}
+	}
+
+	so := NewSampleObservers()
+	so.AddMetricSamples([]metrics.SampleContainer{sample(1)})
+
+	var first, second []float64
+	stopFirst := so.Observe(func(s metrics.Sample) { first = append(first, s.Value) })
+	so.Observe(func(s metrics.Sample) { second = append(second, s.Value) })
+
+	so.AddMetricSamples([]metrics.SampleContainer{sample(2), metrics.Samples{sample(3), sample(4)}})
+	stopFirst()
+	so.AddMetricSamples([]metrics.SampleContainer{sample(5)})
+
+	assert.Equal(t, []float64{2, 3, 4}, first)
+	assert.Equal(t, []float64{2, 3, 4, 5}, second)
+}
@@ -0,0 +1,112 @@
+package lib
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// SharedStore is the key-value store shared by all the VUs of the local
+// instance, e.g. for the scenarios to pass data to each other, or for the VUs
+// to share tokens or to allocate unique IDs. Its values are encoded, e.g. as
+// JSON, and they can expire.
+type SharedStore struct {
+	mu      sync.Mutex
+	entries map[string]sharedEntry
+
+	// now is used to check the expiration of the entries, it can be replaced
+	// in tests.
+	now func() time.Time
+}
+
+type sharedEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewSharedStore returns a new empty SharedStore.
+func NewSharedStore() *SharedStore {
+	return &SharedStore{
+		entries: make(map[string]sharedEntry),
+		now:     time.Now,
+	}
+}
+
+// getEntry returns the entry of the key if it exists and it hasn't expired,
+// it has to be called with the lock held.
+func (s *SharedStore) getEntry(key string) (sharedEntry, bool) {
+	e, ok := s.entries[key]
+	if !ok {
+		return e, false
+	}
+	if !e.expiresAt.IsZero() && !s.now().Before(e.expiresAt) {
+		delete(s.entries, key)
+		return e, false
+	}
+	return e, true
+}
+
+func (s *SharedStore) newEntry(value []byte, ttl time.Duration) sharedEntry {
+	e := sharedEntry{value: value}
+	if ttl > 0 {
+		e.expiresAt = s.now().Add(ttl)
+	}
+	return e
+}
+
+// Get returns the value of the key, and whether it exists.
+func (s *SharedStore) Get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.getEntry(key)
+	return e.value, ok
+}
+
+// Set sets the value of the key, which expires after the TTL, unless it's 0.
+func (s *SharedStore) Set(key string, value []byte, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = s.newEntry(value, ttl)
+}
+
+// Update atomically sets the value of the key to the one fn returns for its
+// current value, if it exists, and returns it. The TTL of the key, if it had
+// any, is kept. Nothing is changed if fn fails.
+func (s *SharedStore) Update(key string, fn func(value []byte, exists bool) ([]byte, error)) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.getEntry(key)
+	value, err := fn(e.value, ok)
+	if err != nil {
+		return nil, err
+	}
+	e.value = value
+	s.entries[key] = e
+	return value, nil
+}
+
+// CompareAndSwap atomically sets the value of the key, which expires after the
+// TTL, unless it's 0, only if its current value is the expected one. A nil
+// expected value means that the key shouldn't exist. It returns whether the
+// value was set.
+func (s *SharedStore) CompareAndSwap(key string, expected, value []byte, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.getEntry(key)
+	if ok != (expected != nil) || (ok && !bytes.Equal(e.value, expected)) {
+		return false
+	}
+	s.entries[key] = s.newEntry(value, ttl)
+	return true
+}
+
+// Delete removes the key and returns whether it existed.
+func (s *SharedStore) Delete(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.getEntry(key)
+	delete(s.entries, key)
+	return ok
+}
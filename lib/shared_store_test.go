@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedStore(t *testing.T) {
+	t.Parallel()
+
+	s := NewSharedStore()
+	_, ok := s.Get("key")
+	assert.False(t, ok)
+
+	s.Set("key", []byte("1"), 0)
+	value, ok := s.Get("key")
+	require.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	assert.False(t, s.CompareAndSwap("key", nil, []byte("2"), 0))
+	assert.False(t, s.CompareAndSwap("key", []byte("3"), []byte("2"), 0))
+	assert.True(t, s.CompareAndSwap("key", []byte("1"), []byte("2"), 0))
+	assert.True(t, s.CompareAndSwap("other", nil, []byte("1"), 0))
+
+	value, err := s.Update("key", func(value []byte, exists bool) ([]byte, error) {
+		assert.True(t, exists)
+		assert.Equal(t, []byte("2"), value)
+		return []byte("3"), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("3"), value)
+
+	_, err = s.Update("key", func([]byte, bool) ([]byte, error) {
+		return nil, errors.New("failed")
+	})
+	require.Error(t, err)
+	value, _ = s.Get("key")
+	assert.Equal(t, []byte("3"), value)
+
+	assert.True(t, s.Delete("key"))
+	assert.False(t, s.Delete("key"))
+	_, ok = s.Get("key")
+	assert.False(t, ok)
+}
+
+func TestSharedStoreTTL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	s := NewSharedStore()
+	s.now = func() time.Time { return now }
+
+	s.Set("short", []byte("1"), time.Second)
+	s.Set("long", []byte("1"), 2*time.Second)
+	s.Set("forever", []byte("1"), 0)
+	require.True(t, s.CompareAndSwap("swapped", nil, []byte("1"), time.Second))
+
+	now = now.Add(time.Second)
+	_, ok := s.Get("short")
+	assert.False(t, ok)
+	_, ok = s.Get("swapped")
+	assert.False(t, ok)
+	_, ok = s.Get("forever")
+	assert.True(t, ok)
+	_, err := s.Update("long", func(value []byte, exists bool) ([]byte, error) {
+		assert.True(t, exists)
+		return []byte("2"), nil
+	})
+	require.NoError(t, err)
+
+	// Update keeps the TTL of the key
+	now = now.Add(time.Second)
+	_, ok = s.Get("long")
+	assert.False(t, ok)
+}
+
+func TestExecutionStateSharedData(t *testing.T) {
+	t.Parallel()
+
+	et, err := NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := NewExecutionState(nil, et, 0, 0)
+
+	es.SetSharedData("key", []byte(`"value"`))
+	value, ok := es.SharedStore().Get("key")
+	require.True(t, ok)
+	assert.Equal(t, []byte(`"value"`), value)
+}
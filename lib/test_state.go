@@ -7,8 +7,11 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"go.k6.io/k6/event"
+	"go.k6.io/k6/lib/capture"
 	"go.k6.io/k6/lib/trace"
+	"go.k6.io/k6/loader"
 	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/secretsource"
 )
 
 // TestPreInitState contains all of the state that can be gathered and built
@@ -22,6 +25,17 @@ type TestPreInitState struct {
 	LookupEnv      func(key string) (val string, ok bool)
 	Logger         logrus.FieldLogger
 	TracerProvider *trace.TracerProvider
+
+	// ModulesLock pins the integrity hashes of the remote modules, if any.
+	ModulesLock *loader.ModulesLock
+
+	// SecretsManager gets the secrets of the k6/secrets module from their
+	// sources, if any, and redacts them from the logs.
+	SecretsManager *secretsource.Manager
+
+	// FailureCapture records the failed requests and their responses, with
+	// the --capture-failures option.
+	FailureCapture *capture.Recorder
 }
 
 // TestRunState contains the pre-init state as well as all of the state and
@@ -112,6 +126,9 @@ func (gs *GroupSummary) handleSample(sample metrics.Sample) error {
 		}
 		if sample.Value == 0 {
 			atomic.AddInt64(&check.Fails, 1)
+			if failure, ok := NewCheckFailure(sample.Metadata); ok {
+				check.AddFailure(failure)
+			}
 		} else {
 			atomic.AddInt64(&check.Passes, 1)
 		}
@@ -21,9 +21,13 @@ var (
 // using a real JS runtime, it allows us to directly specify the options and
 // functions with Go code.
 type MiniRunner struct {
-	Fn              func(ctx context.Context, state *lib.State, out chan<- metrics.SampleContainer) error
-	SetupFn         func(ctx context.Context, out chan<- metrics.SampleContainer) ([]byte, error)
-	TeardownFn      func(ctx context.Context, out chan<- metrics.SampleContainer) error
+	Fn                 func(ctx context.Context, state *lib.State, out chan<- metrics.SampleContainer) error
+	SetupFn            func(ctx context.Context, out chan<- metrics.SampleContainer) ([]byte, error)
+	TeardownFn         func(ctx context.Context, out chan<- metrics.SampleContainer) error
+	ScenarioSetupFn    func(ctx context.Context, out chan<- metrics.SampleContainer, fn string) ([]byte, error)
+	ScenarioTeardownFn func(
+		ctx context.Context, out chan<- metrics.SampleContainer, fn string, data []byte,
+	) error
 	HandleSummaryFn func(context.Context, *lib.Summary) (map[string]io.Reader, error)
 
 	SetupData []byte
@@ -85,6 +89,25 @@ func (r MiniRunner) Teardown(ctx context.Context, out chan<- metrics.SampleConta
 	return nil
 }
 
+// ScenarioSetup calls the supplied mock scenario setup function, if present.
+func (r MiniRunner) ScenarioSetup(ctx context.Context, out chan<- metrics.SampleContainer, fn string) ([]byte, error) {
+	if r.ScenarioSetupFn != nil {
+		return r.ScenarioSetupFn(ctx, out, fn)
+	}
+	return nil, nil
+}
+
+// ScenarioTeardown calls the supplied mock scenario teardown function, if
+// present.
+func (r MiniRunner) ScenarioTeardown(
+	ctx context.Context, out chan<- metrics.SampleContainer, fn string, data []byte,
+) error {
+	if r.ScenarioTeardownFn != nil {
+		return r.ScenarioTeardownFn(ctx, out, fn, data)
+	}
+	return nil
+}
+
 // IsExecutable satisfies lib.Runner, but is mocked for MiniRunner since
 // it doesn't deal with JS.
 func (r MiniRunner) IsExecutable(_ string) bool {
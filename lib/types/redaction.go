@@ -0,0 +1,155 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// DefaultRedactionReplacement is what the redacted values are replaced with,
+// unless the rule has its own replacement.
+const DefaultRedactionReplacement = "[REDACTED]"
+
+// RedactionRule scrubs sensitive data, e.g. emails, tokens or card numbers,
+// either the parts of the text matching a regular expression, or the whole
+// values of some tags, or the parts of the values of some tags matching a
+// regular expression.
+type RedactionRule struct {
+	// Match is the regular expression whose matches are redacted.
+	Match string `json:"match,omitempty"`
+	// Tags are the names of the tags whose values are redacted, wholly if
+	// the rule doesn't have a regular expression. A rule without tags applies
+	// to the values of all of the tags, and to the text of the logs.
+	Tags []string `json:"tags,omitempty"`
+	// Replacement is what the redacted data is replaced with, which can refer
+	// to the capture groups of Match, e.g. $1.
+	Replacement string `json:"replacement,omitempty"`
+
+	re   *regexp.Regexp
+	tags map[string]struct{}
+}
+
+// compile validates the rule and compiles its regular expression.
+func (r *RedactionRule) compile() error {
+	if r.Match == "" && len(r.Tags) == 0 {
+		return errors.New("a redaction rule needs either a match or tags")
+	}
+	if r.Match != "" {
+		var err error
+		if r.re, err = regexp.Compile(r.Match); err != nil {
+			return fmt.Errorf("invalid redaction rule: %w", err)
+		}
+	}
+	if len(r.Tags) > 0 {
+		r.tags = make(map[string]struct{}, len(r.Tags))
+		for _, tag := range r.Tags {
+			r.tags[tag] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// redact returns the text with the matches of the rule replaced, or the
+// whole text if the rule doesn't have a regular expression.
+func (r *RedactionRule) redact(text string) string {
+	replacement := r.Replacement
+	if replacement == "" {
+		replacement = DefaultRedactionReplacement
+	}
+	if r.re == nil {
+		return replacement
+	}
+	return r.re.ReplaceAllString(text, replacement)
+}
+
+// NullRedaction is a nullable list of redaction rules, applied in order to
+// the samples and to the logs before they leave the process.
+type NullRedaction struct {
+	Rules []RedactionRule
+	Valid bool
+}
+
+// NewNullRedaction validates the rules and returns them.
+func NewNullRedaction(rules []RedactionRule) (NullRedaction, error) {
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return NullRedaction{}, err
+		}
+	}
+	return NullRedaction{Rules: rules, Valid: true}, nil
+}
+
+// RedactTag returns the value of the tag with the rules applying to it
+// applied.
+func (r NullRedaction) RedactTag(name, value string) string {
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if rule.tags != nil {
+			if _, ok := rule.tags[name]; !ok {
+				continue
+			}
+		}
+		value = rule.redact(value)
+	}
+	return value
+}
+
+// RedactText returns the text, e.g. a log message, with the regular
+// expressions of the rules not restricted to tags applied.
+func (r NullRedaction) RedactText(text string) string {
+	for i := range r.Rules {
+		if rule := &r.Rules[i]; rule.tags == nil {
+			text = rule.redact(text)
+		}
+	}
+	return text
+}
+
+// UnmarshalText converts text data to a valid NullRedaction, either a JSON
+// array of rules, or a single regular expression whose matches are redacted.
+func (r *NullRedaction) UnmarshalText(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) == 0:
+		*r = NullRedaction{}
+		return nil
+	case data[0] == '[':
+		return r.UnmarshalJSON(data)
+	}
+
+	redaction, err := NewNullRedaction([]RedactionRule{{Match: string(data)}})
+	if err != nil {
+		return err
+	}
+	*r = redaction
+	return nil
+}
+
+// UnmarshalJSON converts JSON data to a valid NullRedaction
+func (r *NullRedaction) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte(`null`)) {
+		*r = NullRedaction{}
+		return nil
+	}
+
+	var rules []RedactionRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+	redaction, err := NewNullRedaction(rules)
+	if err != nil {
+		return err
+	}
+	*r = redaction
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface
+func (r NullRedaction) MarshalJSON() ([]byte, error) {
+	if !r.Valid {
+		return []byte(`null`), nil
+	}
+	return json.Marshal(r.Rules)
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNullRedaction(t *testing.T) {
+	t.Parallel()
+
+	var redaction NullRedaction
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"match": "[\\w.+-]+@[\\w-]+\\.[\\w.]+"},
+		{"match": "([?&]token=)[^&]+", "replacement": "${1}***", "tags": ["url", "name"]},
+		{"tags": ["user"]}
+	]`), &redaction))
+	assert.True(t, redaction.Valid)
+
+	assert.Equal(t, "https://k6.local/users/[REDACTED]?token=***&page=2",
+		redaction.RedactTag("url", "https://k6.local/users/jane@k6.io?token=s3cr3t&page=2"))
+	assert.Equal(t, "?token=s3cr3t", redaction.RedactTag("error", "?token=s3cr3t"))
+	assert.Equal(t, "[REDACTED]", redaction.RedactTag("user", "jane"))
+	assert.Equal(t, "request to [REDACTED] failed, ?token=s3cr3t",
+		redaction.RedactText("request to jane@k6.io failed, ?token=s3cr3t"))
+
+	data, err := json.Marshal(redaction)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"match": "[\\w.+-]+@[\\w-]+\\.[\\w.]+"},
+		{"match": "([?&]token=)[^&]+", "replacement": "${1}***", "tags": ["url", "name"]},
+		{"tags": ["user"]}
+	]`, string(data))
+}
+
+func TestNullRedactionUnmarshalText(t *testing.T) {
+	t.Parallel()
+
+	var redaction NullRedaction
+	require.NoError(t, redaction.UnmarshalText([]byte(`\b\d{4}-\d{4}-\d{4}-\d{4}\b`)))
+	assert.True(t, redaction.Valid)
+	assert.Equal(t, "card [REDACTED]", redaction.RedactText("card 4242-4242-4242-4242"))
+
+	require.NoError(t, redaction.UnmarshalText([]byte(`[{"tags": ["user"], "replacement": "-"}]`)))
+	assert.Equal(t, "-", redaction.RedactTag("user", "jane"))
+
+	require.NoError(t, redaction.UnmarshalText(nil))
+	assert.False(t, redaction.Valid)
+}
+
+func TestNullRedactionInvalid(t *testing.T) {
+	t.Parallel()
+
+	for data, err := range map[string]string{
+		`[{}]`:                "a redaction rule needs either a match or tags",
+		`[{"match": "(foo"}]`: "invalid redaction rule",
+	} {
+		var redaction NullRedaction
+		assert.ErrorContains(t, json.Unmarshal([]byte(data), &redaction), err, data)
+	}
+}
@@ -0,0 +1,167 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// URLGroupingRule groups the URLs it matches under the same name, either with
+// a regular expression matched against the whole URL, or with a path template
+// in the OpenAPI format, e.g. /users/{id}, matched against the URL's path.
+type URLGroupingRule struct {
+	// Match is the regular expression matched against the whole URL.
+	Match string `json:"match,omitempty"`
+	// Path is the path template matched against the URL's path, each
+	// {parameter} of which matches a single path segment.
+	Path string `json:"path,omitempty"`
+	// Name is the name of the matching URLs, which can refer to the capture
+	// groups of Match, e.g. $1. It's the URL with the path template as its path
+	// by default for path templates, and is required for regular expressions.
+	Name string `json:"name,omitempty"`
+
+	re *regexp.Regexp
+}
+
+//nolint:gochecknoglobals
+var pathTemplateParameter = regexp.MustCompile(`\{[^/{}]+\}`)
+
+// compile validates the rule and compiles its regular expression.
+func (r *URLGroupingRule) compile() error {
+	var err error
+	switch {
+	case r.Match != "" && r.Path != "":
+		return errors.New("a URL grouping rule can't have both a match and a path")
+	case r.Match != "":
+		if r.Name == "" {
+			return fmt.Errorf("the URL grouping rule matching %q doesn't have a name", r.Match)
+		}
+		r.re, err = regexp.Compile(r.Match)
+	case r.Path != "":
+		if !strings.HasPrefix(r.Path, "/") {
+			return fmt.Errorf("the path template %q of a URL grouping rule doesn't start with /", r.Path)
+		}
+		var pattern strings.Builder
+		pattern.WriteString("^")
+		last := 0
+		for _, loc := range pathTemplateParameter.FindAllStringIndex(r.Path, -1) {
+			pattern.WriteString(regexp.QuoteMeta(r.Path[last:loc[0]]))
+			pattern.WriteString("[^/]+")
+			last = loc[1]
+		}
+		pattern.WriteString(regexp.QuoteMeta(r.Path[last:]))
+		pattern.WriteString("/?$")
+		r.re, err = regexp.Compile(pattern.String())
+	default:
+		return errors.New("a URL grouping rule needs either a match or a path")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid URL grouping rule: %w", err)
+	}
+	return nil
+}
+
+// name returns the name of the URL, whose string representation is given,
+// and whether the rule matches it.
+func (r *URLGroupingRule) name(u *url.URL, s string) (string, bool) {
+	if r.Path == "" {
+		match := r.re.FindStringSubmatchIndex(s)
+		if match == nil {
+			return "", false
+		}
+		return string(r.re.ExpandString(nil, r.Name, s, match)), true
+	}
+
+	if u == nil || !r.re.MatchString(u.EscapedPath()) {
+		return "", false
+	}
+	if r.Name != "" {
+		return r.Name, true
+	}
+	grouped := url.URL{Scheme: u.Scheme, Host: u.Host}
+	return grouped.String() + r.Path, true
+}
+
+// NullURLGrouping is a nullable list of URL grouping rules, the first one
+// matching the URL of a request giving its name tag.
+type NullURLGrouping struct {
+	Rules []URLGroupingRule
+	Valid bool
+}
+
+// NewNullURLGrouping validates the rules and returns them.
+func NewNullURLGrouping(rules []URLGroupingRule) (NullURLGrouping, error) {
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return NullURLGrouping{}, err
+		}
+	}
+	return NullURLGrouping{Rules: rules, Valid: true}, nil
+}
+
+// Name returns the name of the URL, given by the first rule matching it, if
+// any. The string representation of the URL, against which the regular
+// expressions are matched, is given so the credentials in it can be masked.
+func (g NullURLGrouping) Name(u *url.URL, s string) (string, bool) {
+	for i := range g.Rules {
+		if name, ok := g.Rules[i].name(u, s); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// UnmarshalText converts text data to a valid NullURLGrouping, either a JSON
+// array of rules, or a comma-separated list of path templates.
+func (g *NullURLGrouping) UnmarshalText(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) == 0:
+		*g = NullURLGrouping{}
+		return nil
+	case data[0] == '[':
+		return g.UnmarshalJSON(data)
+	}
+
+	var rules []URLGroupingRule
+	for _, path := range strings.Split(string(data), ",") {
+		rules = append(rules, URLGroupingRule{Path: strings.TrimSpace(path)})
+	}
+	grouping, err := NewNullURLGrouping(rules)
+	if err != nil {
+		return err
+	}
+	*g = grouping
+	return nil
+}
+
+// UnmarshalJSON converts JSON data to a valid NullURLGrouping
+func (g *NullURLGrouping) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte(`null`)) {
+		*g = NullURLGrouping{}
+		return nil
+	}
+
+	var rules []URLGroupingRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+	grouping, err := NewNullURLGrouping(rules)
+	if err != nil {
+		return err
+	}
+	*g = grouping
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface
+func (g NullURLGrouping) MarshalJSON() ([]byte, error) {
+	if !g.Valid {
+		return []byte(`null`), nil
+	}
+	return json.Marshal(g.Rules)
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNullURLGrouping(t *testing.T) {
+	t.Parallel()
+
+	var grouping NullURLGrouping
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"path": "/users/{id}"},
+		{"path": "/users/{id}/orders/{orderId}", "name": "user orders"},
+		{"match": "^(https://[^/]+)/items/[0-9a-f-]{36}(\\?.*)?$", "name": "${1}/items/{uuid}"}
+	]`), &grouping))
+	assert.True(t, grouping.Valid)
+
+	testCases := []struct {
+		url, name string
+		matched   bool
+	}{
+		{url: "https://api.k6.local/users/123", name: "https://api.k6.local/users/{id}", matched: true},
+		{url: "https://api.k6.local/users/123/?fields=name", name: "https://api.k6.local/users/{id}", matched: true},
+		{url: "http://localhost:8080/users/123/orders/456", name: "user orders", matched: true},
+		{
+			url:     "https://api.k6.local/items/1b4e28ba-2fa1-11d2-883f-0016785cfe66?expand=true",
+			name:    "https://api.k6.local/items/{uuid}",
+			matched: true,
+		},
+		{url: "https://api.k6.local/users"},
+		{url: "https://api.k6.local/users/123/profile"},
+		{url: "https://api.k6.local/items/123"},
+	}
+	for _, tc := range testCases {
+		u, err := url.Parse(tc.url)
+		require.NoError(t, err)
+		name, matched := grouping.Name(u, tc.url)
+		assert.Equal(t, tc.matched, matched, tc.url)
+		assert.Equal(t, tc.name, name, tc.url)
+	}
+
+	data, err := json.Marshal(grouping)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"path": "/users/{id}"},
+		{"path": "/users/{id}/orders/{orderId}", "name": "user orders"},
+		{"match": "^(https://[^/]+)/items/[0-9a-f-]{36}(\\?.*)?$", "name": "${1}/items/{uuid}"}
+	]`, string(data))
+}
+
+func TestNullURLGroupingUnmarshalText(t *testing.T) {
+	t.Parallel()
+
+	var grouping NullURLGrouping
+	require.NoError(t, grouping.UnmarshalText([]byte("/users/{id}, /orders/{id}")))
+	assert.Equal(t, []URLGroupingRule{{Path: "/users/{id}"}, {Path: "/orders/{id}"}}, withoutRegexps(grouping.Rules))
+
+	require.NoError(t, grouping.UnmarshalText([]byte(`[{"match": "/v[0-9]+/", "name": "versioned"}]`)))
+	assert.Equal(t, []URLGroupingRule{{Match: "/v[0-9]+/", Name: "versioned"}}, withoutRegexps(grouping.Rules))
+
+	require.NoError(t, grouping.UnmarshalText(nil))
+	assert.False(t, grouping.Valid)
+}
+
+func TestNullURLGroupingInvalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		`[{}]`:                           "a URL grouping rule needs either a match or a path",
+		`[{"match": "a", "path": "/b"}]`: "a URL grouping rule can't have both a match and a path",
+		`[{"match": "/users/[0-9]+"}]`:   `the URL grouping rule matching "/users/[0-9]+" doesn't have a name`,
+		`[{"match": "(", "name": "n"}]`:  "invalid URL grouping rule: error parsing regexp",
+		`[{"path": "users/{id}"}]`:       `the path template "users/{id}" of a URL grouping rule doesn't start with /`,
+		`{"path": "/users/{id}"}`:        "cannot unmarshal object",
+	}
+	for data, expErr := range testCases {
+		var grouping NullURLGrouping
+		assert.ErrorContains(t, json.Unmarshal([]byte(data), &grouping), expErr, data)
+	}
+}
+
+func withoutRegexps(rules []URLGroupingRule) []URLGroupingRule {
+	result := make([]URLGroupingRule, len(rules))
+	for i, r := range rules {
+		r.re = nil
+		result[i] = r
+	}
+	return result
+}
@@ -7,11 +7,13 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
 
+	"go.k6.io/k6/lib/capture"
 	"go.k6.io/k6/metrics"
 )
 
@@ -63,6 +65,9 @@ type State struct {
 	VUID, VUIDGlobal uint64
 	Iteration        int64
 
+	// IterationStart is when the current iteration of the VU started.
+	IterationStart time.Time
+
 	// TODO: rename this field with one more representative
 	// because it includes now also the metadata.
 	Tags *VUStateTags
@@ -81,6 +86,13 @@ type State struct {
 
 	// Tracing instrumentation.
 	TracerProvider TracerProvider
+
+	// FailureCapture records the failed requests and their responses, if the
+	// capture of the failures is enabled.
+	FailureCapture *capture.Recorder
+
+	// Hooks are the lifecycle hooks the script registered in the init context.
+	Hooks *Hooks
 }
 
 // VUStateTags wraps the current VU's tags and ensures a thread-safe way to
@@ -0,0 +1,98 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/types"
+)
+
+const (
+	// WarmupPhaseTag is the tag of the samples emitted in the warm-up phase of
+	// a scenario, with the WarmupPhase value.
+	WarmupPhaseTag = "phase"
+	// WarmupPhase is the value of the WarmupPhaseTag in the warm-up phases.
+	WarmupPhase = "warmup"
+	// WarmupDropMetadata is the metadata of the samples emitted in the warm-up
+	// phases whose samples are dropped, before they are sent to the outputs.
+	WarmupDropMetadata = "warmup_drop"
+)
+
+// The policies for the samples of the warm-up phases.
+const (
+	WarmupSamplesTag  = "tag"
+	WarmupSamplesDrop = "drop"
+)
+
+// Warmup is the warm-up phase of a scenario, e.g. for the JIT compilers or the
+// caches to warm up, whose samples are excluded from the thresholds and the
+// end-of-test summary. It lasts for a duration, or a number of iterations of
+// the scenario, or as long as either if it has both.
+type Warmup struct {
+	Duration   types.NullDuration `json:"duration"`
+	Iterations null.Int           `json:"iterations"`
+	// Samples is what happens to the samples: they are tagged with the
+	// warm-up phase by default, and are otherwise dropped.
+	Samples string `json:"samples,omitempty"`
+}
+
+// UnmarshalJSON converts either a duration, an iteration count or an object
+// to a Warmup.
+func (w *Warmup) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) > 0 && data[0] == '"':
+		*w = Warmup{}
+		return w.Duration.UnmarshalJSON(data)
+	case len(data) > 0 && data[0] != '{':
+		*w = Warmup{}
+		return w.Iterations.UnmarshalJSON(data)
+	}
+
+	type warmup Warmup
+	var decoded warmup
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*w = Warmup(decoded)
+	return nil
+}
+
+// InProgress returns whether the warm-up phase of the scenario which started
+// at the time is still in progress at the given iteration of the scenario.
+func (w *Warmup) InProgress(startTime time.Time, iteration uint64) bool {
+	if w.Duration.Valid && time.Since(startTime) < w.Duration.TimeDuration() {
+		return true
+	}
+	return w.Iterations.Valid && iteration < uint64(w.Iterations.Int64)
+}
+
+// DropsSamples returns whether the samples of the warm-up phase are dropped.
+func (w *Warmup) DropsSamples() bool {
+	return w.Samples == WarmupSamplesDrop
+}
+
+// Validate returns the errors of the warm-up phase, if any.
+func (w *Warmup) Validate() (errors []error) {
+	if !w.Duration.Valid && !w.Iterations.Valid {
+		errors = append(errors, fmt.Errorf("the warmup needs either a duration or iterations"))
+	}
+	if w.Duration.Duration < 0 {
+		errors = append(errors, fmt.Errorf("the warmup duration can't be negative"))
+	}
+	if w.Iterations.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the warmup iterations can't be negative"))
+	}
+	switch w.Samples {
+	case "", WarmupSamplesTag, WarmupSamplesDrop:
+	default:
+		errors = append(errors, fmt.Errorf(
+			"the warmup samples can be either %q or %q, not %q", WarmupSamplesTag, WarmupSamplesDrop, w.Samples,
+		))
+	}
+	return errors
+}
@@ -0,0 +1,69 @@
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/types"
+)
+
+func TestWarmupUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]Warmup{
+		`"30s"`: {Duration: types.NullDurationFrom(30 * time.Second)},
+		`100`:   {Iterations: null.IntFrom(100)},
+		`{"duration": "1m", "iterations": 10, "samples": "drop"}`: {
+			Duration:   types.NullDurationFrom(time.Minute),
+			Iterations: null.IntFrom(10),
+			Samples:    WarmupSamplesDrop,
+		},
+	}
+	for data, expected := range testCases {
+		var warmup Warmup
+		require.NoError(t, json.Unmarshal([]byte(data), &warmup), data)
+		assert.Equal(t, expected, warmup, data)
+		assert.Empty(t, warmup.Validate(), data)
+	}
+}
+
+func TestWarmupInProgress(t *testing.T) {
+	t.Parallel()
+
+	startTime := time.Now()
+	byDuration := &Warmup{Duration: types.NullDurationFrom(time.Hour)}
+	assert.True(t, byDuration.InProgress(startTime, 1000))
+	assert.False(t, byDuration.InProgress(startTime.Add(-2*time.Hour), 0))
+
+	byIterations := &Warmup{Iterations: null.IntFrom(5)}
+	assert.True(t, byIterations.InProgress(startTime, 4))
+	assert.False(t, byIterations.InProgress(startTime, 5))
+
+	both := &Warmup{Duration: types.NullDurationFrom(time.Hour), Iterations: null.IntFrom(5)}
+	assert.True(t, both.InProgress(startTime, 10))
+	assert.True(t, both.InProgress(startTime.Add(-2*time.Hour), 0))
+	assert.False(t, both.InProgress(startTime.Add(-2*time.Hour), 5))
+}
+
+func TestWarmupValidate(t *testing.T) {
+	t.Parallel()
+
+	errs := (&Warmup{}).Validate()
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "the warmup needs either a duration or iterations")
+
+	errs = (&Warmup{
+		Duration:   types.NullDurationFrom(-time.Second),
+		Iterations: null.IntFrom(-1),
+		Samples:    "skip",
+	}).Validate()
+	require.Len(t, errs, 3)
+	assert.EqualError(t, errs[0], "the warmup duration can't be negative")
+	assert.EqualError(t, errs[1], "the warmup iterations can't be negative")
+	assert.EqualError(t, errs[2], `the warmup samples can be either "tag" or "drop", not "skip"`)
+}
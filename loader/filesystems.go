@@ -23,3 +23,10 @@ func CreateFilesystems(osfs fsext.Fs) map[string]fsext.Fs {
 		"https": fsext.NewMemMapFs(),
 	}
 }
+
+// CacheRemoteModules makes the remote modules be read from the given directory
+// of the OS filesystem when they are cached there, and the fetched ones be
+// cached in it, so the next test runs can load them offline.
+func CacheRemoteModules(filesystems map[string]fsext.Fs, osfs fsext.Fs, dir string) {
+	filesystems["https"] = fsext.NewCacheOnReadFs(fsext.NewBasePathFs(osfs, dir), filesystems["https"], 0)
+}
@@ -173,11 +173,29 @@ func Load(
 	result.URL = moduleSpecifier
 	// TODO maybe make an fsext.Fs which makes request directly and than use CacheOnReadFs
 	// on top of as with the `file` scheme fs
-	_ = fsext.WriteFile(filesystems[scheme], pathOnFs, result.Data, 0o644)
+	_ = cacheRemoteFile(filesystems[scheme], pathOnFs, result.Data)
 
 	return result, nil
 }
 
+// cacheRemoteFile writes the fetched file to the https filesystem. It's created
+// rather than opened for writing, as the cache of CacheRemoteModules, as any
+// afero.CacheOnReadFs, doesn't allow opening the new files for writing.
+func cacheRemoteFile(filesystem fsext.Fs, path string, data []byte) error {
+	if err := filesystem.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := filesystem.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func loadRemoteURL(logger logrus.FieldLogger, u *url.URL) (*SourceData, error) {
 	oldQuery := u.RawQuery
 	if u.RawQuery != "" {
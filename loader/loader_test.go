@@ -222,6 +222,36 @@ func TestLoad(t *testing.T) {
 		})
 	})
 
+	t.Run("Cached", func(t *testing.T) {
+		t.Parallel()
+		osfs := fsext.NewMemMapFs()
+		root, err := url.Parse("file:///")
+		require.NoError(t, err)
+
+		moduleSpecifier := sr("HTTPSBIN_URL/robots.txt")
+		moduleSpecifierURL, err := loader.Resolve(root, moduleSpecifier)
+		require.NoError(t, err)
+
+		filesystems := map[string]fsext.Fs{"https": fsext.NewMemMapFs()}
+		loader.CacheRemoteModules(filesystems, osfs, "/cache")
+		src, err := loader.Load(logger, filesystems, moduleSpecifierURL, moduleSpecifier)
+		require.NoError(t, err)
+		assert.Equal(t, "User-agent: *\nDisallow: /deny\n", string(src.Data))
+
+		cachedPath := filepath.Join("/cache", moduleSpecifierURL.Host, "robots.txt")
+		cached, err := fsext.ReadFile(osfs, cachedPath)
+		require.NoError(t, err)
+		assert.Equal(t, src.Data, cached)
+
+		// the next runs load the module from the cache, without fetching it
+		require.NoError(t, fsext.WriteFile(osfs, cachedPath, []byte("cached"), 0o644))
+		filesystems = map[string]fsext.Fs{"https": fsext.NewMemMapFs()}
+		loader.CacheRemoteModules(filesystems, osfs, "/cache")
+		src, err = loader.Load(logger, filesystems, moduleSpecifierURL, moduleSpecifier)
+		require.NoError(t, err)
+		assert.Equal(t, "cached", string(src.Data))
+	})
+
 	t.Run("No _k6=1 Fallback", func(t *testing.T) {
 		t.Parallel()
 		root, err := url.Parse("file:///")
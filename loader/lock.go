@@ -0,0 +1,101 @@
+package loader
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"go.k6.io/k6/lib/fsext"
+)
+
+// ModulesLock pins the contents of the remote modules, the ones imported from
+// https URLs, with their integrity hashes, as the lock files of the package
+// managers do. The modules that changed since they were pinned fail to load,
+// so the test runs are reproducible.
+type ModulesLock struct {
+	fs   fsext.Fs
+	path string
+
+	mu      sync.Mutex
+	modules map[string]string
+	changed bool
+}
+
+type modulesLockFile struct {
+	Modules map[string]string `json:"modules"`
+}
+
+// ReadModulesLock reads the modules lock file at the given path, if it exists.
+// The modules missing from it are pinned as they are loaded.
+func ReadModulesLock(filesystem fsext.Fs, path string) (*ModulesLock, error) {
+	lock := &ModulesLock{fs: filesystem, path: path, modules: make(map[string]string)}
+
+	data, err := fsext.ReadFile(filesystem, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the modules lock file: %w", err)
+	}
+
+	var file modulesLockFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("couldn't parse the modules lock file %s: %w", path, err)
+	}
+	for specifier, integrity := range file.Modules {
+		lock.modules[specifier] = integrity
+	}
+	return lock, nil
+}
+
+// Verify checks the data of the module with the given specifier against its
+// pinned integrity hash, or pins it if it wasn't.
+func (l *ModulesLock) Verify(specifier string, data []byte) error {
+	integrity := moduleIntegrity(data)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	pinned, ok := l.modules[specifier]
+	if !ok {
+		l.modules[specifier] = integrity
+		l.changed = true
+		return nil
+	}
+	if pinned != integrity {
+		return fmt.Errorf("the integrity of the module %q is %s, which doesn't match the %s pinned in %s; "+
+			"if the change is expected, remove its entry from the lock file to pin it again",
+			specifier, integrity, pinned, l.path)
+	}
+	return nil
+}
+
+// Save writes the lock file, if new modules were pinned.
+func (l *ModulesLock) Save() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.changed {
+		return nil
+	}
+	data, err := json.MarshalIndent(modulesLockFile{Modules: l.modules}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fsext.WriteFile(l.fs, l.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("couldn't write the modules lock file: %w", err)
+	}
+	l.changed = false
+	return nil
+}
+
+// moduleIntegrity returns the integrity hash of the data, in the format of
+// the subresource integrity.
+func moduleIntegrity(data []byte) string {
+	sum := sha512.Sum384(data)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,41 @@
+package loader_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/loader"
+)
+
+func TestModulesLock(t *testing.T) {
+	t.Parallel()
+
+	const specifier = "https://jslib.k6.io/k6-utils/1.4.0/index.js"
+	fs := fsext.NewMemMapFs()
+
+	lock, err := loader.ReadModulesLock(fs, "/k6.lock")
+	require.NoError(t, err)
+	require.NoError(t, lock.Verify(specifier, []byte("export const a = 1;")))
+	require.NoError(t, lock.Save())
+
+	data, err := fsext.ReadFile(fs, "/k6.lock")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"modules": {
+		"https://jslib.k6.io/k6-utils/1.4.0/index.js":
+			"sha384-cbUm2un28wzCuhRbr5P2NjvygLIPE7Upshq7IFu3zHWe7O6QknqF2PIHu0c5wGP6"
+	}}`, string(data))
+
+	lock, err = loader.ReadModulesLock(fs, "/k6.lock")
+	require.NoError(t, err)
+	require.NoError(t, lock.Verify(specifier, []byte("export const a = 1;")))
+	err = lock.Verify(specifier, []byte("export const a = 2;"))
+	require.ErrorContains(t, err, `the integrity of the module "`+specifier+`" is sha384-`)
+	require.ErrorContains(t, err, "which doesn't match the sha384-cbUm2un2")
+
+	require.NoError(t, fsext.WriteFile(fs, "/invalid.lock", []byte("{"), 0o644))
+	_, err = loader.ReadModulesLock(fs, "/invalid.lock")
+	require.ErrorContains(t, err, "couldn't parse the modules lock file /invalid.lock")
+}
@@ -0,0 +1,51 @@
+package log
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AddRedactionHook makes the logger redact its entries with the function. The
+// hook is fired before the ones the logger already has, e.g. the ones of the
+// log outputs, so they get the redacted entries.
+func AddRedactionHook(logger *logrus.Logger, redact func(string) string) {
+	hooks := make(logrus.LevelHooks)
+	hooks.Add(redactionHook{redact: redact})
+	for level, levelHooks := range logger.Hooks {
+		hooks[level] = append(hooks[level], levelHooks...)
+	}
+	logger.ReplaceHooks(hooks)
+}
+
+// redactionHook redacts the message and the fields of the log entries.
+type redactionHook struct {
+	redact func(string) string
+}
+
+func (h redactionHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h redactionHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.redact(entry.Message)
+
+	// the entries the hooks are fired with have their own copy of the fields
+	for key, value := range entry.Data {
+		var text string
+		switch v := value.(type) {
+		case string:
+			text = v
+		case error:
+			text = v.Error()
+		case fmt.Stringer:
+			text = v.String()
+		default:
+			continue
+		}
+		if redacted := h.redact(text); redacted != text {
+			entry.Data[key] = redacted
+		}
+	}
+	return nil
+}
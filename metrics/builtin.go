@@ -14,6 +14,7 @@ const (
 
 	HTTPReqsName              = "http_reqs"
 	HTTPReqFailedName         = "http_req_failed"
+	HTTPReqRetriesName        = "http_req_retries"
 	HTTPReqDurationName       = "http_req_duration"
 	HTTPReqBlockedName        = "http_req_blocked"
 	HTTPReqConnectingName     = "http_req_connecting"
@@ -21,6 +22,9 @@ const (
 	HTTPReqSendingName        = "http_req_sending"
 	HTTPReqWaitingName        = "http_req_waiting"
 	HTTPReqReceivingName      = "http_req_receiving"
+	HTTPReqH2StreamsName      = "http_req_h2_streams"
+	HTTPH2GoAwaysName         = "http_h2_goaways"
+	HTTPH2StreamResetsName    = "http_h2_stream_resets"
 
 	WSSessionsName         = "ws_sessions"
 	WSMessagesSentName     = "ws_msgs_sent"
@@ -29,10 +33,17 @@ const (
 	WSSessionDurationName  = "ws_session_duration"
 	WSConnectingName       = "ws_connecting"
 
-	GRPCReqDurationName = "grpc_req_duration"
+	GRPCReqDurationName      = "grpc_req_duration"
+	GRPCReqRetryDurationName = "grpc_req_retry_duration"
 
 	DataSentName     = "data_sent"
 	DataReceivedName = "data_received"
+
+	LoadGeneratorCPUName          = "loadgen_cpu"
+	LoadGeneratorHeapName         = "loadgen_heap_bytes"
+	LoadGeneratorGCPauseName      = "loadgen_gc_pause"
+	LoadGeneratorEventLoopLagName = "loadgen_event_loop_lag"
+	LoadGeneratorSaturatedName    = "loadgen_saturated"
 )
 
 // BuiltinMetrics represent all the builtin metrics of k6
@@ -50,6 +61,7 @@ type BuiltinMetrics struct {
 	// HTTP-related.
 	HTTPReqs              *Metric
 	HTTPReqFailed         *Metric
+	HTTPReqRetries        *Metric
 	HTTPReqDuration       *Metric
 	HTTPReqBlocked        *Metric
 	HTTPReqConnecting     *Metric
@@ -57,6 +69,9 @@ type BuiltinMetrics struct {
 	HTTPReqSending        *Metric
 	HTTPReqWaiting        *Metric
 	HTTPReqReceiving      *Metric
+	HTTPReqH2Streams      *Metric
+	HTTPH2GoAways         *Metric
+	HTTPH2StreamResets    *Metric
 
 	// Websocket-related
 	WSSessions         *Metric
@@ -67,11 +82,19 @@ type BuiltinMetrics struct {
 	WSConnecting       *Metric
 
 	// gRPC-related
-	GRPCReqDuration *Metric
+	GRPCReqDuration      *Metric
+	GRPCReqRetryDuration *Metric
 
 	// Network-related; used for future protocols as well.
 	DataSent     *Metric
 	DataReceived *Metric
+
+	// Load generator-related, i.e. the resources used by k6 itself.
+	LoadGeneratorCPU          *Metric
+	LoadGeneratorHeap         *Metric
+	LoadGeneratorGCPause      *Metric
+	LoadGeneratorEventLoopLag *Metric
+	LoadGeneratorSaturated    *Metric
 }
 
 // RegisterBuiltinMetrics register and returns the builtin metrics in the provided registry
@@ -88,6 +111,7 @@ func RegisterBuiltinMetrics(registry *Registry) *BuiltinMetrics {
 
 		HTTPReqs:              registry.MustNewMetric(HTTPReqsName, Counter),
 		HTTPReqFailed:         registry.MustNewMetric(HTTPReqFailedName, Rate),
+		HTTPReqRetries:        registry.MustNewMetric(HTTPReqRetriesName, Counter),
 		HTTPReqDuration:       registry.MustNewMetric(HTTPReqDurationName, Trend, Time),
 		HTTPReqBlocked:        registry.MustNewMetric(HTTPReqBlockedName, Trend, Time),
 		HTTPReqConnecting:     registry.MustNewMetric(HTTPReqConnectingName, Trend, Time),
@@ -95,6 +119,9 @@ func RegisterBuiltinMetrics(registry *Registry) *BuiltinMetrics {
 		HTTPReqSending:        registry.MustNewMetric(HTTPReqSendingName, Trend, Time),
 		HTTPReqWaiting:        registry.MustNewMetric(HTTPReqWaitingName, Trend, Time),
 		HTTPReqReceiving:      registry.MustNewMetric(HTTPReqReceivingName, Trend, Time),
+		HTTPReqH2Streams:      registry.MustNewMetric(HTTPReqH2StreamsName, Trend),
+		HTTPH2GoAways:         registry.MustNewMetric(HTTPH2GoAwaysName, Counter),
+		HTTPH2StreamResets:    registry.MustNewMetric(HTTPH2StreamResetsName, Counter),
 
 		WSSessions:         registry.MustNewMetric(WSSessionsName, Counter),
 		WSMessagesSent:     registry.MustNewMetric(WSMessagesSentName, Counter),
@@ -103,9 +130,16 @@ func RegisterBuiltinMetrics(registry *Registry) *BuiltinMetrics {
 		WSSessionDuration:  registry.MustNewMetric(WSSessionDurationName, Trend, Time),
 		WSConnecting:       registry.MustNewMetric(WSConnectingName, Trend, Time),
 
-		GRPCReqDuration: registry.MustNewMetric(GRPCReqDurationName, Trend, Time),
+		GRPCReqDuration:      registry.MustNewMetric(GRPCReqDurationName, Trend, Time),
+		GRPCReqRetryDuration: registry.MustNewMetric(GRPCReqRetryDurationName, Trend, Time),
 
 		DataSent:     registry.MustNewMetric(DataSentName, Counter, Data),
 		DataReceived: registry.MustNewMetric(DataReceivedName, Counter, Data),
+
+		LoadGeneratorCPU:          registry.MustNewMetric(LoadGeneratorCPUName, Gauge),
+		LoadGeneratorHeap:         registry.MustNewMetric(LoadGeneratorHeapName, Gauge, Data),
+		LoadGeneratorGCPause:      registry.MustNewMetric(LoadGeneratorGCPauseName, Trend, Time),
+		LoadGeneratorEventLoopLag: registry.MustNewMetric(LoadGeneratorEventLoopLagName, Trend, Time),
+		LoadGeneratorSaturated:    registry.MustNewMetric(LoadGeneratorSaturatedName, Rate),
 	}
 }
@@ -0,0 +1,141 @@
+package metrics
+
+import "sort"
+
+// OtherTagValue is the value the overflowing values of a tag are folded into,
+// once the cardinality limits of its metric have been hit.
+const OtherTagValue = "_other"
+
+// CardinalityOverflow describes a tag of a metric whose new values started
+// being folded into OtherTagValue.
+type CardinalityOverflow struct {
+	Metric string
+	Tag    string
+
+	// PerMetric is whether it was the limit of the unique tag sets of the
+	// metric that was hit, instead of the one of the unique values of the tag.
+	PerMetric bool
+	Limit     int
+}
+
+// CardinalityLimiter limits the number of unique values of each tag of every
+// metric, and the number of unique tag sets (i.e. time series) of every
+// metric. Once a limit is hit, the new values of the overflowing tag are
+// folded into OtherTagValue, while the values seen before are kept.
+//
+// It isn't safe for concurrent use.
+type CardinalityLimiter struct {
+	metricLimit int
+	tagLimit    int
+	metrics     map[*Metric]*metricCardinality
+}
+
+// metricCardinality holds the tag sets and tag values seen for a metric.
+type metricCardinality struct {
+	series map[*TagSet]struct{}
+	values map[string]map[string]struct{}
+	// folded are the tags whose new values are folded into OtherTagValue.
+	folded map[string]struct{}
+}
+
+// NewCardinalityLimiter returns a limiter of the unique tag sets of every
+// metric to metricLimit, and of the unique values of every tag of every
+// metric to tagLimit. A limit of 0 disables it.
+func NewCardinalityLimiter(metricLimit, tagLimit int) *CardinalityLimiter {
+	return &CardinalityLimiter{
+		metricLimit: metricLimit,
+		tagLimit:    tagLimit,
+		metrics:     make(map[*Metric]*metricCardinality),
+	}
+}
+
+// Limit returns the sample with the overflowing values of its tags folded into
+// OtherTagValue, along with the tags that started overflowing because of it.
+func (l *CardinalityLimiter) Limit(s Sample) (Sample, []CardinalityOverflow) {
+	mc, ok := l.metrics[s.Metric]
+	if !ok {
+		mc = &metricCardinality{
+			series: make(map[*TagSet]struct{}),
+			values: make(map[string]map[string]struct{}),
+			folded: make(map[string]struct{}),
+		}
+		l.metrics[s.Metric] = mc
+	}
+	if _, seen := mc.series[s.Tags]; seen {
+		return s, nil
+	}
+
+	var overflows []CardinalityOverflow
+	tags := s.Tags
+	added := make(map[string]string)
+	for name, value := range tags.Map() {
+		if value == OtherTagValue {
+			continue
+		}
+		values, hasValues := mc.values[name]
+		if !hasValues {
+			values = make(map[string]struct{})
+			mc.values[name] = values
+		}
+		if _, seen := values[value]; seen {
+			continue
+		}
+
+		_, folded := mc.folded[name]
+		if !folded && l.tagLimit > 0 && len(values) >= l.tagLimit {
+			mc.folded[name] = struct{}{}
+			overflows = append(overflows, CardinalityOverflow{Metric: s.Metric.Name, Tag: name, Limit: l.tagLimit})
+			folded = true
+		}
+		if folded {
+			tags = tags.With(name, OtherTagValue)
+			continue
+		}
+		values[value] = struct{}{}
+		added[name] = value
+	}
+
+	// A new tag set past the limit gets its highest-cardinality tag folded,
+	// unless some of its tags already were, and the resulting tag set is kept
+	// even if it's new, so the limit is exceeded by the tag sets with the
+	// overflowing values only, until all of the tags are folded.
+	if l.metricLimit > 0 && tags == s.Tags && len(mc.series) >= l.metricLimit {
+		if name := mc.highestCardinalityTag(tags); name != "" {
+			if _, folded := mc.folded[name]; !folded {
+				mc.folded[name] = struct{}{}
+				overflows = append(overflows, CardinalityOverflow{
+					Metric: s.Metric.Name, Tag: name, PerMetric: true, Limit: l.metricLimit,
+				})
+			}
+			if value, ok := added[name]; ok {
+				delete(mc.values[name], value)
+			}
+			tags = tags.With(name, OtherTagValue)
+		}
+	}
+
+	mc.series[tags] = struct{}{}
+	s.Tags = tags
+	return s, overflows
+}
+
+// highestCardinalityTag returns the tag of the tag set that isn't folded into
+// OtherTagValue yet and that has the most unique values, or an empty string
+// if all of them are folded.
+func (mc *metricCardinality) highestCardinalityTag(tags *TagSet) string {
+	var names []string
+	for name, value := range tags.Map() {
+		if value != OtherTagValue {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	highest := ""
+	for _, name := range names {
+		if highest == "" || len(mc.values[name]) > len(mc.values[highest]) {
+			highest = name
+		}
+	}
+	return highest
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardinalityLimiterTagLimit(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	m, err := r.NewMetric("http_reqs", Counter)
+	require.NoError(t, err)
+	l := NewCardinalityLimiter(0, 2)
+
+	limit := func(tags map[string]string) (map[string]string, []CardinalityOverflow) {
+		s, overflows := l.Limit(Sample{TimeSeries: TimeSeries{Metric: m, Tags: r.RootTagSet().WithTagsFromMap(tags)}})
+		return s.Tags.Map(), overflows
+	}
+
+	tags, overflows := limit(map[string]string{"url": "/1", "method": "GET"})
+	assert.Equal(t, map[string]string{"url": "/1", "method": "GET"}, tags)
+	assert.Empty(t, overflows)
+	tags, overflows = limit(map[string]string{"url": "/2", "method": "GET"})
+	assert.Equal(t, map[string]string{"url": "/2", "method": "GET"}, tags)
+	assert.Empty(t, overflows)
+
+	tags, overflows = limit(map[string]string{"url": "/3", "method": "POST"})
+	assert.Equal(t, map[string]string{"url": OtherTagValue, "method": "POST"}, tags)
+	assert.Equal(t, []CardinalityOverflow{{Metric: "http_reqs", Tag: "url", Limit: 2}}, overflows)
+
+	// The overflow is only reported once, and the values seen before are kept.
+	tags, overflows = limit(map[string]string{"url": "/4", "method": "GET"})
+	assert.Equal(t, map[string]string{"url": OtherTagValue, "method": "GET"}, tags)
+	assert.Empty(t, overflows)
+	tags, _ = limit(map[string]string{"url": "/1", "method": "POST"})
+	assert.Equal(t, map[string]string{"url": "/1", "method": "POST"}, tags)
+
+	// The limits are per metric.
+	other, err := r.NewMetric("http_req_duration", Trend)
+	require.NoError(t, err)
+	s, overflows := l.Limit(Sample{TimeSeries: TimeSeries{
+		Metric: other, Tags: r.RootTagSet().WithTagsFromMap(map[string]string{"url": "/5"}),
+	}})
+	assert.Equal(t, map[string]string{"url": "/5"}, s.Tags.Map())
+	assert.Empty(t, overflows)
+}
+
+func TestCardinalityLimiterMetricLimit(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	m, err := r.NewMetric("http_reqs", Counter)
+	require.NoError(t, err)
+	l := NewCardinalityLimiter(5, 0)
+
+	var allOverflows []CardinalityOverflow
+	seriesTags := make(map[string]map[string]string)
+	for i := 0; i < 20; i++ {
+		tags := map[string]string{"id": fmt.Sprint(i), "status": fmt.Sprint(200 + i%2)}
+		s, overflows := l.Limit(Sample{TimeSeries: TimeSeries{Metric: m, Tags: r.RootTagSet().WithTagsFromMap(tags)}})
+		allOverflows = append(allOverflows, overflows...)
+		seriesTags[fmt.Sprint(s.Tags.Map())] = s.Tags.Map()
+	}
+
+	// The highest-cardinality tag is folded, once the limit of tag sets is hit.
+	assert.Equal(t, []CardinalityOverflow{{Metric: "http_reqs", Tag: "id", PerMetric: true, Limit: 5}}, allOverflows)
+	assert.Len(t, seriesTags, 7)
+	assert.Contains(t, seriesTags, fmt.Sprint(map[string]string{"id": OtherTagValue, "status": "200"}))
+	assert.Contains(t, seriesTags, fmt.Sprint(map[string]string{"id": OtherTagValue, "status": "201"}))
+}
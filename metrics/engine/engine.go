@@ -31,6 +31,15 @@ type MetricsEngine struct {
 	metricsWithThresholds   []*metrics.Metric
 	breachedThresholdsCount uint32
 
+	// metricExpressions are the metric expressions with thresholds, whose
+	// values are added to the Gauge sinks of their pseudo-metrics before
+	// the thresholds are evaluated.
+	metricExpressions []*metricExpression
+
+	// trendPrecision is the precision of the histograms the Trend metrics
+	// keep their values in, or 0 if they keep all of them.
+	trendPrecision int
+
 	// TODO: completely refactor:
 	//   - make these private, add a method to export the raw data
 	//   - do not use an unnecessary map for the observed metrics
@@ -38,6 +47,12 @@ type MetricsEngine struct {
 	//     the metrics are decoupled from their types
 	MetricsLock     sync.Mutex
 	ObservedMetrics map[string]*metrics.Metric
+
+	// ScenarioMetrics and GroupMetrics hold, once the breakdowns are
+	// enabled, the metrics observed in each scenario and in each group but
+	// the root one, indexed by the scenario names and the group paths.
+	ScenarioMetrics map[string]map[string]*metrics.Metric
+	GroupMetrics    map[string]map[string]*metrics.Metric
 }
 
 // NewMetricsEngine creates a new metrics Engine with the given parameters.
@@ -107,6 +122,55 @@ func (me *MetricsEngine) getThresholdMetricOrSubmetric(name string) (*metrics.Me
 	return sm.Metric, nil
 }
 
+// EnableBreakdowns makes the engine aggregate the metric samples of each
+// scenario and group, besides the whole test run ones, for the end-of-test
+// summary. It has to be called before the ingester is started.
+func (me *MetricsEngine) EnableBreakdowns() {
+	me.ScenarioMetrics = make(map[string]map[string]*metrics.Metric)
+	me.GroupMetrics = make(map[string]map[string]*metrics.Metric)
+}
+
+// newSink creates a sink for the provided metric type, which for Trend
+// metrics keeps their values in a histogram, unless exact percentiles were
+// requested.
+func (me *MetricsEngine) newSink(mt metrics.MetricType) metrics.Sink {
+	if mt == metrics.Trend && me.trendPrecision > 0 {
+		return metrics.NewHistogramTrendSink(me.trendPrecision)
+	}
+	return metrics.NewSink(mt)
+}
+
+// addToBreakdown adds the provided sample to the copy of its metric held by
+// the given breakdown for the value of the provided tag, if the sample has
+// a non-empty one.
+func (me *MetricsEngine) addToBreakdown(
+	breakdown map[string]map[string]*metrics.Metric, tag string, sample metrics.Sample,
+) {
+	value, ok := sample.Tags.Get(tag)
+	if !ok || value == "" {
+		return
+	}
+
+	observed, ok := breakdown[value]
+	if !ok {
+		observed = make(map[string]*metrics.Metric)
+		breakdown[value] = observed
+	}
+
+	m, ok := observed[sample.Metric.Name]
+	if !ok {
+		m = &metrics.Metric{
+			Name:     sample.Metric.Name,
+			Type:     sample.Metric.Type,
+			Contains: sample.Metric.Contains,
+			Sink:     me.newSink(sample.Metric.Type),
+			Observed: true,
+		}
+		observed[sample.Metric.Name] = m
+	}
+	m.Sink.Add(sample)
+}
+
 func (me *MetricsEngine) markObserved(metric *metrics.Metric) {
 	if !metric.Observed {
 		metric.Observed = true
@@ -119,6 +183,27 @@ func (me *MetricsEngine) markObserved(metric *metrics.Metric) {
 // were referenced in them.
 func (me *MetricsEngine) InitSubMetricsAndThresholds(options lib.Options, onlyLogErrors bool) error {
 	for metricName, thresholds := range options.Thresholds {
+		if metrics.IsMetricExpression(metricName) {
+			expression, err := me.newMetricExpression(metricName)
+
+			if onlyLogErrors {
+				if err != nil {
+					me.logger.WithError(err).Warnf("Invalid metric expression '%s' in threshold definitions", metricName)
+				}
+				continue
+			}
+
+			if err != nil {
+				return fmt.Errorf("invalid metric expression '%s' in threshold definitions: %w", metricName, err)
+			}
+
+			expression.metric.Thresholds = thresholds
+			me.metricExpressions = append(me.metricExpressions, expression)
+			me.metricsWithThresholds = append(me.metricsWithThresholds, expression.metric)
+			me.markObserved(expression.metric)
+			continue
+		}
+
 		metric, err := me.getThresholdMetricOrSubmetric(metricName)
 
 		if onlyLogErrors {
@@ -153,9 +238,39 @@ func (me *MetricsEngine) InitSubMetricsAndThresholds(options lib.Options, onlyLo
 		}
 	}
 
+	me.initTrendSinks(options)
+
 	return nil
 }
 
+// initTrendSinks replaces the sinks of the Trend metrics and sub-metrics, all
+// of which have to be already registered, with ones keeping their values in
+// histograms, unless exact percentiles were requested.
+func (me *MetricsEngine) initTrendSinks(options lib.Options) {
+	if options.ExactTrendPercentiles.Bool {
+		return
+	}
+
+	me.trendPrecision = metrics.DefaultTrendPrecision
+	if options.TrendPercentilesPrecision.Valid {
+		me.trendPrecision = int(options.TrendPercentilesPrecision.Int64)
+	}
+
+	for _, m := range me.registry.All() {
+		if m.Type != metrics.Trend {
+			continue
+		}
+		if m.Sink.IsEmpty() {
+			m.Sink = me.newSink(m.Type)
+		}
+		for _, sm := range m.Submetrics {
+			if sm.Metric.Sink.IsEmpty() {
+				sm.Metric.Sink = me.newSink(m.Type)
+			}
+		}
+	}
+}
+
 // StartThresholdCalculations spins up a new goroutine to crunch thresholds and
 // returns a callback that will stop the goroutine and finalizes calculations.
 func (me *MetricsEngine) StartThresholdCalculations(
@@ -223,6 +338,7 @@ func (me *MetricsEngine) evaluateThresholds(
 	defer me.MetricsLock.Unlock()
 
 	t := getCurrentTestRunDuration()
+	me.evaluateMetricExpressions(t)
 
 	me.logger.Debugf("Running thresholds on %d metrics...", len(me.metricsWithThresholds))
 	for _, m := range me.metricsWithThresholds {
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"go.k6.io/k6/lib"
 	"go.k6.io/k6/lib/testutils"
 	"go.k6.io/k6/metrics"
+	"gopkg.in/guregu/null.v3"
 )
 
 func TestNewMetricsEngineWithThresholds(t *testing.T) {
@@ -153,6 +155,119 @@ func TestMetricsEngineEvaluateIgnoreEmptySink(t *testing.T) {
 	assert.Empty(t, breached)
 }
 
+func TestMetricsEngineEvaluateThresholdOverWindowAbort(t *testing.T) {
+	t.Parallel()
+
+	me := newTestMetricsEngine(t)
+	m1, err := me.registry.NewMetric("m1", metrics.Trend)
+	require.NoError(t, err)
+
+	ths := metrics.NewThresholds([]string{"avg<100", "max<100 over 10s"})
+	require.NoError(t, ths.Parse())
+	m1.Thresholds = ths
+	m1.Thresholds.Thresholds[1].AbortOnFail = true
+	me.metricsWithThresholds = []*metrics.Metric{m1}
+
+	ingester := me.CreateIngester()
+	require.NoError(t, ingester.Start())
+
+	// A spike fails the threshold over the window, while the average over
+	// the test run stays healthy.
+	now := time.Now()
+	samples := make([]metrics.SampleContainer, 0, 11)
+	for i := 0; i < 10; i++ {
+		samples = append(samples, metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: m1}, Time: now, Value: 10})
+	}
+	samples = append(samples, metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: m1}, Time: now, Value: 500})
+	ingester.AddMetricSamples(samples)
+	require.NoError(t, ingester.Stop())
+
+	breached, abort := me.evaluateThresholds(true, zeroTestRunDuration)
+	assert.True(t, abort)
+	assert.Equal(t, []string{"m1"}, breached)
+	assert.False(t, m1.Thresholds.Thresholds[0].LastFailed)
+	assert.True(t, m1.Thresholds.Thresholds[1].LastFailed)
+}
+
+func TestMetricsEngineEvaluateMetricExpressionThresholds(t *testing.T) {
+	t.Parallel()
+
+	me := newTestMetricsEngine(t)
+	failed, err := me.registry.NewMetric("failed", metrics.Rate)
+	require.NoError(t, err)
+	reqs, err := me.registry.NewMetric("reqs", metrics.Counter)
+	require.NoError(t, err)
+
+	ths := metrics.NewThresholds([]string{"value<0.1"})
+	require.NoError(t, ths.Parse())
+	options := lib.Options{Thresholds: map[string]metrics.Thresholds{"failed / reqs": ths}}
+	require.NoError(t, me.InitSubMetricsAndThresholds(options, false))
+	require.Len(t, me.metricsWithThresholds, 1)
+	assert.Contains(t, me.ObservedMetrics, "failed / reqs")
+
+	// Without any request, the expression has no value yet.
+	breached, _ := me.evaluateThresholds(true, zeroTestRunDuration)
+	assert.Empty(t, breached)
+
+	for i := 0; i < 10; i++ {
+		reqs.Sink.Add(metrics.Sample{Value: 1})
+		failed.Sink.Add(metrics.Sample{Value: 0})
+	}
+	breached, _ = me.evaluateThresholds(true, zeroTestRunDuration)
+	assert.Empty(t, breached)
+
+	failed.Sink.Add(metrics.Sample{Value: 1})
+	failed.Sink.Add(metrics.Sample{Value: 1})
+	breached, _ = me.evaluateThresholds(true, zeroTestRunDuration)
+	assert.Equal(t, []string{"failed / reqs"}, breached)
+}
+
+func TestMetricsEngineInitTrendSinks(t *testing.T) {
+	t.Parallel()
+
+	for _, exact := range []bool{false, true} {
+		exact := exact
+		t.Run(fmt.Sprintf("exact=%t", exact), func(t *testing.T) {
+			t.Parallel()
+
+			me := newTestMetricsEngine(t)
+			trend, err := me.registry.NewMetric("my_trend", metrics.Trend)
+			require.NoError(t, err)
+			counter, err := me.registry.NewMetric("my_counter", metrics.Counter)
+			require.NoError(t, err)
+			trendSink, counterSink := trend.Sink, counter.Sink
+
+			opts := lib.Options{
+				Thresholds: map[string]metrics.Thresholds{
+					"my_trend{a:1}": {Thresholds: []*metrics.Threshold{}},
+				},
+				ExactTrendPercentiles: null.BoolFrom(exact),
+			}
+			require.NoError(t, me.InitSubMetricsAndThresholds(opts, false))
+			require.Len(t, trend.Submetrics, 1)
+
+			assert.Same(t, counterSink, counter.Sink)
+			if exact {
+				assert.Same(t, trendSink, trend.Sink)
+				assert.Zero(t, me.trendPrecision)
+			} else {
+				assert.NotSame(t, trendSink, trend.Sink)
+				assert.NotSame(t, trendSink, trend.Submetrics[0].Metric.Sink)
+				assert.Equal(t, metrics.DefaultTrendPrecision, me.trendPrecision)
+			}
+
+			for _, m := range []*metrics.Metric{trend, trend.Submetrics[0].Metric} {
+				for _, v := range []float64{1, 2, 3} {
+					m.Sink.Add(metrics.Sample{TimeSeries: metrics.TimeSeries{Metric: m}, Value: v})
+				}
+				tm := m.Sink.(*metrics.TrendSink) //nolint:forcetypeassert
+				assert.Equal(t, 3.0, tm.Max())
+				assert.InEpsilon(t, 2.0, tm.P(0.5), 0.001)
+			}
+		})
+	}
+}
+
 func newTestMetricsEngine(t *testing.T) *MetricsEngine {
 	m, err := NewMetricsEngine(metrics.NewRegistry(), testutils.NewLogger(t))
 	require.NoError(t, err)
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"time"
+
+	"go.k6.io/k6/metrics"
+)
+
+// metricExpression is a metric expression with thresholds, whose values are
+// kept by a Gauge pseudo-metric named after it, so that its thresholds are
+// evaluated, and it's shown in the end-of-test summary, like the metrics'.
+type metricExpression struct {
+	expression *metrics.MetricExpression
+	metric     *metrics.Metric
+
+	// operands are the metrics and sub-metrics the expression refers to,
+	// indexed by their names.
+	operands map[string]*metrics.Metric
+}
+
+// newMetricExpression parses the metric expression, and looks up the metrics
+// it refers to, initializing the sub-metrics among them.
+func (me *MetricsEngine) newMetricExpression(source string) (*metricExpression, error) {
+	expression, err := metrics.ParseMetricExpression(source)
+	if err != nil {
+		return nil, err
+	}
+
+	operands := make(map[string]*metrics.Metric)
+	for _, operand := range expression.Operands() {
+		metric, err := me.getThresholdMetricOrSubmetric(operand.Name)
+		if err != nil {
+			return nil, err
+		}
+		operands[operand.Name] = metric
+	}
+
+	return &metricExpression{
+		expression: expression,
+		metric: &metrics.Metric{
+			Name:     source,
+			Type:     metrics.Gauge,
+			Contains: metrics.Default,
+			Sink:     metrics.NewSink(metrics.Gauge),
+		},
+		operands: operands,
+	}, nil
+}
+
+// evaluateMetricExpressions adds the current values of the metric
+// expressions, if they have one, to the sinks of their pseudo-metrics.
+func (me *MetricsEngine) evaluateMetricExpressions(duration time.Duration) {
+	now := time.Now()
+	for _, e := range me.metricExpressions {
+		value, ok := e.expression.Eval(func(name string) metrics.Sink {
+			return e.operands[name].Sink
+		}, duration)
+		if !ok {
+			continue
+		}
+		e.metric.Sink.Add(metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: e.metric},
+			Time:       now,
+			Value:      value,
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.k6.io/k6/lib"
 	"go.k6.io/k6/metrics"
 	"go.k6.io/k6/output"
 )
@@ -85,9 +86,23 @@ func (oi *OutputIngester) flushMetrics() {
 		}
 
 		for _, sample := range samples {
+			// the samples of the warm-up phases of the scenarios are only
+			// sent to the outputs
+			if sample.Tags != nil {
+				if phase, ok := sample.Tags.Get(lib.WarmupPhaseTag); ok && phase == lib.WarmupPhase {
+					continue
+				}
+			}
+
 			m := sample.Metric               // this should have come from the Registry, no need to look it up
 			oi.metricsEngine.markObserved(m) // mark it as observed so it shows in the end-of-test summary
 			m.Sink.Add(sample)               // finally, add its value to its own sink
+			m.Thresholds.AddSample(sample)   // and to the time windows of its thresholds, if any
+
+			if oi.metricsEngine.ScenarioMetrics != nil {
+				oi.metricsEngine.addToBreakdown(oi.metricsEngine.ScenarioMetrics, metrics.TagScenario.String(), sample)
+				oi.metricsEngine.addToBreakdown(oi.metricsEngine.GroupMetrics, metrics.TagGroup.String(), sample)
+			}
 
 			// and also to the same for any submetrics that match the metric sample
 			for _, sm := range m.Submetrics {
@@ -96,6 +111,7 @@ func (oi *OutputIngester) flushMetrics() {
 				}
 				oi.metricsEngine.markObserved(sm.Metric)
 				sm.Metric.Sink.Add(sample)
+				sm.Metric.Thresholds.AddSample(sample)
 			}
 
 			oi.cardinality.Add(sample.TimeSeries)
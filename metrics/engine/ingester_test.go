@@ -47,6 +47,41 @@ func TestIngesterOutputFlushMetrics(t *testing.T) {
 	assert.Equal(t, 42.0, sink.Total())
 }
 
+func TestIngesterOutputFlushMetricsWarmup(t *testing.T) {
+	t.Parallel()
+
+	piState := newTestPreInitState(t)
+	testMetric, err := piState.Registry.NewMetric("test_metric", metrics.Trend)
+	require.NoError(t, err)
+
+	ingester := OutputIngester{
+		logger: piState.Logger,
+		metricsEngine: &MetricsEngine{
+			ObservedMetrics: make(map[string]*metrics.Metric),
+		},
+		cardinality: newCardinalityControl(),
+	}
+	require.NoError(t, ingester.Start())
+	ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: testMetric,
+			Tags:   piState.Registry.RootTagSet().With(lib.WarmupPhaseTag, lib.WarmupPhase),
+		},
+		Value: 1000,
+	}})
+	ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+		TimeSeries: metrics.TimeSeries{Metric: testMetric, Tags: piState.Registry.RootTagSet()},
+		Value:      21,
+	}})
+	require.NoError(t, ingester.Stop())
+
+	metric := ingester.metricsEngine.ObservedMetrics["test_metric"]
+	require.NotNil(t, metric)
+	sink := metric.Sink.(*metrics.TrendSink) //nolint:forcetypeassert
+	assert.Equal(t, uint64(1), sink.Count())
+	assert.Equal(t, 21.0, sink.Max())
+}
+
 func TestIngesterOutputFlushSubmetrics(t *testing.T) {
 	t.Parallel()
 
@@ -205,3 +240,48 @@ func newTestPreInitState(tb testing.TB) *lib.TestPreInitState {
 		BuiltinMetrics: metrics.RegisterBuiltinMetrics(reg),
 	}
 }
+
+func TestIngesterOutputFlushBreakdowns(t *testing.T) {
+	t.Parallel()
+
+	piState := newTestPreInitState(t)
+	testMetric, err := piState.Registry.NewMetric("test_metric", metrics.Counter)
+	require.NoError(t, err)
+
+	me := &MetricsEngine{
+		logger:          piState.Logger,
+		registry:        piState.Registry,
+		ObservedMetrics: make(map[string]*metrics.Metric),
+	}
+	me.EnableBreakdowns()
+
+	ingester := OutputIngester{
+		logger:        piState.Logger,
+		metricsEngine: me,
+		cardinality:   newCardinalityControl(),
+	}
+	require.NoError(t, ingester.Start())
+	for _, tags := range []map[string]string{
+		{"scenario": "s1", "group": ""},
+		{"scenario": "s1", "group": "::g1"},
+		{"scenario": "s2", "group": "::g1"},
+	} {
+		ingester.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+			TimeSeries: metrics.TimeSeries{
+				Metric: testMetric,
+				Tags:   piState.Registry.RootTagSet().WithTagsFromMap(tags),
+			},
+			Value: 1,
+		}})
+	}
+	require.NoError(t, ingester.Stop())
+
+	require.Len(t, me.ScenarioMetrics, 2)
+	assert.Equal(t, 2.0, me.ScenarioMetrics["s1"]["test_metric"].Sink.(*metrics.CounterSink).Value)
+	assert.Equal(t, 1.0, me.ScenarioMetrics["s2"]["test_metric"].Sink.(*metrics.CounterSink).Value)
+
+	// The samples of the root group are only part of the whole test run's.
+	require.Len(t, me.GroupMetrics, 1)
+	assert.Equal(t, 2.0, me.GroupMetrics["::g1"]["test_metric"].Sink.(*metrics.CounterSink).Value)
+	assert.Equal(t, 3.0, testMetric.Sink.(*metrics.CounterSink).Value)
+}
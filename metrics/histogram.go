@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// MinTrendPrecision and MaxTrendPrecision are the bounds of the precision,
+	// in significant digits, of the percentiles computed from a histogram.
+	MinTrendPrecision = 1
+	MaxTrendPrecision = 5
+
+	// DefaultTrendPrecision is the default precision, in significant digits,
+	// of the percentiles computed from a histogram.
+	DefaultTrendPrecision = 3
+)
+
+// histogram is a sparse histogram with logarithmic buckets, which keeps the
+// distribution of the values it's added, rather than the values themselves.
+//
+// A value lands in the bucket of index ceil(log_gamma(|v|)), on the side of
+// its sign, and is estimated as the middle of its bucket, so that the relative
+// error of the estimate is at most alpha, where gamma = (1 + alpha) / (1 - alpha).
+// The number of buckets grows logarithmically with the range of the values,
+// rather than linearly with their count.
+//
+// The indexes of the non-empty buckets are kept sorted as buckets are created,
+// so that estimating percentiles doesn't sort them on every call.
+type histogram struct {
+	gamma    float64
+	logGamma float64
+
+	positive        map[int]uint64
+	positiveIndexes []int
+	negative        map[int]uint64
+	negativeIndexes []int
+	zeros           uint64
+}
+
+// newHistogram creates a histogram estimating the values it's added with the
+// provided number of significant digits.
+func newHistogram(precision int) *histogram {
+	alpha := math.Pow(10, -float64(precision))
+	gamma := (1 + alpha) / (1 - alpha)
+
+	return &histogram{
+		gamma:    gamma,
+		logGamma: math.Log(gamma),
+		positive: make(map[int]uint64),
+		negative: make(map[int]uint64),
+	}
+}
+
+// minIndexable is the smallest absolute value which isn't considered to be 0.
+const minIndexable = 1e-9
+
+// add adds the provided value to the histogram.
+func (h *histogram) add(v float64) {
+	switch {
+	case math.Abs(v) < minIndexable:
+		h.zeros++
+	case v > 0:
+		h.positiveIndexes = increment(h.positive, h.positiveIndexes, h.index(v))
+	default:
+		h.negativeIndexes = increment(h.negative, h.negativeIndexes, h.index(-v))
+	}
+}
+
+// increment increments the count of the bucket of the provided index, and
+// returns the sorted indexes of the non-empty buckets, which the index is
+// inserted into if its bucket was empty.
+func increment(counts map[int]uint64, indexes []int, index int) []int {
+	if counts[index] == 0 {
+		i := sort.SearchInts(indexes, index)
+		indexes = append(indexes, 0)
+		copy(indexes[i+1:], indexes[i:])
+		indexes[i] = index
+	}
+	counts[index]++
+
+	return indexes
+}
+
+// index returns the index of the bucket of the provided positive value.
+func (h *histogram) index(v float64) int {
+	return int(math.Ceil(math.Log(v) / h.logGamma))
+}
+
+// estimate returns the estimate of the positive values of the bucket of the
+// provided index.
+func (h *histogram) estimate(index int) float64 {
+	return 2 * math.Pow(h.gamma, float64(index)) / (h.gamma + 1)
+}
+
+// bucket is a bucket of a histogram, in ascending order of its values.
+type bucket struct {
+	value float64
+	count uint64
+}
+
+// buckets returns the non-empty buckets of the histogram, sorted in ascending
+// order of their values.
+func (h *histogram) buckets() []bucket {
+	buckets := make([]bucket, 0, len(h.negativeIndexes)+len(h.positiveIndexes)+1)
+
+	// The higher the index of a negative bucket, the lower its values.
+	for j := len(h.negativeIndexes) - 1; j >= 0; j-- {
+		i := h.negativeIndexes[j]
+		buckets = append(buckets, bucket{value: -h.estimate(i), count: h.negative[i]})
+	}
+
+	if h.zeros > 0 {
+		buckets = append(buckets, bucket{value: 0, count: h.zeros})
+	}
+
+	for _, i := range h.positiveIndexes {
+		buckets = append(buckets, bucket{value: h.estimate(i), count: h.positive[i]})
+	}
+
+	return buckets
+}
+
+// quantile returns the estimates of the values at the provided ranks, the
+// lowest value being at rank 0, which are expected to be in ascending order
+// and lower than the number of the values added.
+func (h *histogram) quantile(ranks ...uint64) []float64 {
+	values := make([]float64, len(ranks))
+
+	var seen uint64
+	r := 0
+	for _, b := range h.buckets() {
+		seen += b.count
+		for r < len(ranks) && ranks[r] < seen {
+			values[r] = b.value
+			r++
+		}
+		if r == len(ranks) {
+			break
+		}
+	}
+
+	return values
+}
@@ -100,11 +100,25 @@ func NewTrendSink() *TrendSink {
 	return &TrendSink{}
 }
 
+// NewHistogramTrendSink makes a Trend sink which keeps the distribution of
+// the values in a histogram, the percentiles being estimated with the
+// provided number of significant digits, rather than keeping all the values.
+//
+// Its memory usage thus grows with the range of the values, rather than with
+// their count, while the minimum, maximum, average and count stay exact.
+func NewHistogramTrendSink(precision int) *TrendSink {
+	return &TrendSink{hist: newHistogram(precision)}
+}
+
 // TrendSink is a sink for a Trend
 type TrendSink struct {
 	values []float64
 	sorted bool
 
+	// hist holds the distribution of the values instead of values, if the
+	// sink was created with NewHistogramTrendSink.
+	hist *histogram
+
 	count    uint64
 	min, max float64
 	sum      float64
@@ -126,19 +140,25 @@ func (t *TrendSink) Add(s Sample) {
 		}
 	}
 
-	t.values = append(t.values, s.Value)
-	t.sorted = false
+	if t.hist != nil {
+		t.hist.add(s.Value)
+	} else {
+		t.values = append(t.values, s.Value)
+		t.sorted = false
+	}
 	t.count++
 	t.sum += s.Value
 }
 
 // P calculates the given percentile from sink values.
 func (t *TrendSink) P(pct float64) float64 {
-	switch t.count {
-	case 0:
+	switch {
+	case t.count == 0:
 		return 0
-	case 1:
-		return t.values[0]
+	case t.count == 1:
+		return t.min
+	case t.hist != nil:
+		return t.histogramP(pct)
 	default:
 		if !t.sorted {
 			sort.Float64s(t.values)
@@ -156,6 +176,32 @@ func (t *TrendSink) P(pct float64) float64 {
 	}
 }
 
+// histogramP estimates the given percentile from the sink's histogram, as
+// P calculates it from the sink's values.
+func (t *TrendSink) histogramP(pct float64) float64 {
+	i := pct * (float64(t.count) - 1.0)
+	lo, hi := uint64(math.Floor(i)), uint64(math.Ceil(i))
+	estimates := t.hist.quantile(lo, hi)
+	j, k := estimates[0], estimates[1]
+	f := i - math.Floor(i)
+
+	// The lowest and highest values are known exactly.
+	exact := func(rank uint64, estimate float64) float64 {
+		switch rank {
+		case 0:
+			return t.min
+		case t.count - 1:
+			return t.max
+		default:
+			return estimate
+		}
+	}
+	j, k = exact(lo, j), exact(hi, k)
+
+	// The estimates can't be out of the exact bounds of the values.
+	return math.Min(math.Max(j+(k-j)*f, t.min), t.max)
+}
+
 // Min returns the minimum value.
 func (t *TrendSink) Min() float64 {
 	return t.min
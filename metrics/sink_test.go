@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"math"
+	"sort"
 	"testing"
 	"time"
 
@@ -236,6 +237,88 @@ func TestTrendSink(t *testing.T) {
 	})
 }
 
+func TestHistogramTrendSink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("exact stats", func(t *testing.T) {
+		t.Parallel()
+
+		sink := NewHistogramTrendSink(DefaultTrendPrecision)
+		for _, s := range []float64{0.0, 100.0, 30.0, 80.0, 70.0, 60.0, 50.0, 40.0, 90.0, 20.0} {
+			sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: s})
+		}
+		assert.Nil(t, sink.values)
+		assert.Equal(t, uint64(10), sink.Count())
+		assert.Equal(t, 0.0, sink.Min())
+		assert.Equal(t, 100.0, sink.Max())
+		assert.Equal(t, 54.0, sink.Avg())
+		assert.Equal(t, 0.0, sink.P(0.0))
+		assert.Equal(t, 100.0, sink.P(1.0))
+	})
+
+	t.Run("estimated percentiles", func(t *testing.T) {
+		t.Parallel()
+
+		exact := NewTrendSink()
+		for precision := MinTrendPrecision; precision <= MaxTrendPrecision; precision++ {
+			sink := NewHistogramTrendSink(precision)
+			for i := 1; i <= 10000; i++ {
+				// A mix of negative, null and positive values, spanning magnitudes.
+				v := math.Pow(float64(i%97), 2)/7 - 42
+				if i%13 == 0 {
+					v = 0
+				}
+				s := Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: v}
+				sink.Add(s)
+				if precision == MinTrendPrecision {
+					exact.Add(s)
+				}
+			}
+
+			maxErr := math.Pow(10, -float64(precision))
+			for _, pct := range []float64{0.01, 0.1, 0.5, 0.9, 0.95, 0.99, 0.999} {
+				want := exact.P(pct)
+				assert.InEpsilon(t, want, sink.P(pct), maxErr,
+					"p(%g) with a precision of %d", pct*100, precision)
+			}
+		}
+	})
+
+	t.Run("bounded memory", func(t *testing.T) {
+		t.Parallel()
+
+		sink := NewHistogramTrendSink(DefaultTrendPrecision)
+		for i := 0; i < 100000; i++ {
+			sink.Add(Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: float64(i%1000) + 0.5})
+		}
+		// The buckets spanning 0.5 to 1000 with a relative error of 0.1%.
+		assert.Less(t, len(sink.hist.positive), 4000)
+	})
+
+	t.Run("percentiles between adds", func(t *testing.T) {
+		t.Parallel()
+
+		exact := NewTrendSink()
+		sink := NewHistogramTrendSink(DefaultTrendPrecision)
+		for _, v := range []float64{50, -3, 1000, 0.5, -700, 20, 1000, -3} {
+			s := Sample{TimeSeries: TimeSeries{Metric: &Metric{}}, Value: v}
+			exact.Add(s)
+			sink.Add(s)
+
+			// The percentiles account for the buckets created since the last call;
+			// interpolating across signs loosens the error bound of the estimates.
+			for _, pct := range []float64{0, 0.25, 0.5, 0.75, 1} {
+				assert.InEpsilon(t, exact.P(pct), sink.P(pct), 1e-2, "p(%g) after adding %g", pct*100, v)
+			}
+		}
+
+		assert.True(t, sort.IntsAreSorted(sink.hist.positiveIndexes))
+		assert.True(t, sort.IntsAreSorted(sink.hist.negativeIndexes))
+		assert.Len(t, sink.hist.positiveIndexes, len(sink.hist.positive))
+		assert.Len(t, sink.hist.negativeIndexes, len(sink.hist.negative))
+	})
+}
+
 func TestRateSink(t *testing.T) {
 	t.Parallel()
 	samples6 := []float64{1.0, 0.0, 1.0, 0.0, 0.0, 1.0}
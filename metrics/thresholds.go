@@ -26,6 +26,9 @@ type Threshold struct {
 	AbortGracePeriod types.NullDuration
 	// parsed is the threshold expression parsed from the Source
 	parsed *thresholdExpression
+	// breached is a marker if the threshold, evaluated over a time window,
+	// failed over any of the windows it was evaluated over so far
+	breached bool
 }
 
 func newThreshold(src string, abortOnFail bool, gracePeriod types.NullDuration) *Threshold {
@@ -81,6 +84,14 @@ func (t *Threshold) runNoTaint(sinks map[string]float64) (bool, error) {
 
 func (t *Threshold) run(sinks map[string]float64) (bool, error) {
 	passes, err := t.runNoTaint(sinks)
+
+	// A threshold evaluated over a time window fails as soon as it fails
+	// over any window, even if it passes over the following ones.
+	if t.parsed.Window > 0 {
+		t.breached = t.breached || !passes
+		passes = passes && !t.breached
+	}
+
 	t.LastFailed = !passes
 	return passes, err
 }
@@ -118,6 +129,12 @@ type Thresholds struct {
 	Thresholds []*Threshold
 	Abort      bool
 	sinked     map[string]float64
+
+	// windows holds the samples of the time windows the thresholds are
+	// evaluated over, if any, and windowSinked their sinks' values, indexed
+	// by the windows' durations.
+	windows      map[time.Duration]*windowedSink
+	windowSinked map[time.Duration]map[string]float64
 }
 
 // NewThresholds returns Thresholds objects representing the provided source strings
@@ -139,13 +156,18 @@ func newThresholdsWithConfig(configs []thresholdConfig) Thresholds {
 		thresholds[i] = t
 	}
 
-	return Thresholds{thresholds, false, sinked}
+	return Thresholds{Thresholds: thresholds, Abort: false, sinked: sinked}
 }
 
 func (ts *Thresholds) runAll(timeSpentInTest time.Duration) (bool, error) {
 	succeeded := true
 	for i, threshold := range ts.Thresholds {
-		b, err := threshold.run(ts.sinked)
+		sinked := ts.sinked
+		if threshold.parsed.Window > 0 {
+			sinked = ts.windowSinked[threshold.parsed.Window]
+		}
+
+		b, err := threshold.run(sinked)
 		if err != nil {
 			return false, fmt.Errorf("threshold %d run error: %w", i, err)
 		}
@@ -167,9 +189,39 @@ func (ts *Thresholds) runAll(timeSpentInTest time.Duration) (bool, error) {
 
 // Run processes all the thresholds with the provided Sink at the provided time and returns if any
 // of them fails
+//
+// The thresholds evaluated over a time window are run against the samples
+// added with AddSample over the window ending now.
 func (ts *Thresholds) Run(sink Sink, duration time.Duration) (bool, error) {
 	// Initialize the sinks store
-	ts.sinked = make(map[string]float64)
+	sinked, err := ts.sinkValues(sink, duration)
+	if err != nil {
+		return false, err
+	}
+	ts.sinked = sinked
+
+	ts.windowSinked = make(map[time.Duration]map[string]float64, len(ts.windows))
+	now := time.Now()
+	for window, ws := range ts.windows {
+		windowSink := ws.sink(now)
+		if windowSink.IsEmpty() {
+			// As with the whole test run's sink, the thresholds are
+			// considered to pass while there are no samples.
+			continue
+		}
+
+		if ts.windowSinked[window], err = ts.sinkValues(windowSink, window); err != nil {
+			return false, err
+		}
+	}
+
+	return ts.runAll(duration)
+}
+
+// sinkValues returns the values of the provided Sink, holding the samples
+// emitted over the given duration, which the thresholds are run against.
+func (ts *Thresholds) sinkValues(sink Sink, duration time.Duration) (map[string]float64, error) {
+	sinked := make(map[string]float64)
 
 	// FIXME: Remove this comment as soon as the metrics.Sink does not expose Format anymore.
 	//
@@ -180,15 +232,15 @@ func (ts *Thresholds) Run(sink Sink, duration time.Duration) (bool, error) {
 	// For more details, see https://github.com/grafana/k6/issues/2320
 	switch sinkImpl := sink.(type) {
 	case *CounterSink:
-		ts.sinked["count"] = sinkImpl.Value
-		ts.sinked["rate"] = sinkImpl.Value / (float64(duration) / float64(time.Second))
+		sinked["count"] = sinkImpl.Value
+		sinked["rate"] = sinkImpl.Value / (float64(duration) / float64(time.Second))
 	case *GaugeSink:
-		ts.sinked["value"] = sinkImpl.Value
+		sinked["value"] = sinkImpl.Value
 	case *TrendSink:
-		ts.sinked["min"] = sinkImpl.Min()
-		ts.sinked["max"] = sinkImpl.Max()
-		ts.sinked["avg"] = sinkImpl.Avg()
-		ts.sinked["med"] = sinkImpl.P(0.5)
+		sinked["min"] = sinkImpl.Min()
+		sinked["max"] = sinkImpl.Max()
+		sinked["avg"] = sinkImpl.Avg()
+		sinked["med"] = sinkImpl.P(0.5)
 
 		// Parse the percentile thresholds and insert them in
 		// the sinks mapping.
@@ -198,19 +250,19 @@ func (ts *Thresholds) Run(sink Sink, duration time.Duration) (bool, error) {
 			}
 
 			key := fmt.Sprintf("p(%g)", threshold.parsed.AggregationValue.Float64)
-			ts.sinked[key] = sinkImpl.P(threshold.parsed.AggregationValue.Float64 / 100)
+			sinked[key] = sinkImpl.P(threshold.parsed.AggregationValue.Float64 / 100)
 		}
 	case *RateSink:
 		// We want to avoid division by zero, which
 		// would lead to [#2520](https://github.com/grafana/k6/issues/2520)
 		if sinkImpl.Total > 0 {
-			ts.sinked["rate"] = float64(sinkImpl.Trues) / float64(sinkImpl.Total)
+			sinked["rate"] = float64(sinkImpl.Trues) / float64(sinkImpl.Total)
 		}
 	default:
-		return false, fmt.Errorf("unable to run Thresholds; reason: unknown sink type")
+		return nil, fmt.Errorf("unable to run Thresholds; reason: unknown sink type")
 	}
 
-	return ts.runAll(duration)
+	return sinked, nil
 }
 
 // Parse parses the Thresholds and fills each Threshold.parsed field with the result.
@@ -238,6 +290,10 @@ var ErrInvalidThreshold = errors.New("invalid threshold")
 // Note that this function expects the passed in thresholds to have been parsed already, and
 // have their Parsed (ThresholdExpression) field already filled.
 func (ts *Thresholds) Validate(metricName string, r *Registry) error {
+	if IsMetricExpression(metricName) {
+		return ts.validateExpression(metricName, r)
+	}
+
 	parsedMetricName, _, err := ParseMetricName(metricName)
 	if err != nil {
 		parseErr := fmt.Errorf("unable to validate threshold expressions; reason: %w", err)
@@ -285,6 +341,50 @@ func (ts *Thresholds) Validate(metricName string, r *Registry) error {
 	return nil
 }
 
+// validateExpression ensures the thresholds defined on a metric expression
+// are consistent with it. As the expressions are evaluated as Gauges, the
+// only supported aggregation method is value, and they can't be evaluated
+// over time windows.
+func (ts *Thresholds) validateExpression(source string, r *Registry) error {
+	expression, err := ParseMetricExpression(source)
+	if err != nil {
+		return errext.WithExitCodeIfNone(fmt.Errorf("%w; %w", ErrInvalidThreshold, err), exitcodes.InvalidConfig)
+	}
+	if err = expression.Validate(r); err != nil {
+		err = fmt.Errorf("%w defined on %s; reason: %w", ErrInvalidThreshold, source, err)
+		return errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+	}
+
+	for _, threshold := range ts.Thresholds {
+		if threshold.parsed == nil {
+			thresholdExpression, err := parseThresholdExpression(threshold.Source)
+			if err != nil {
+				return fmt.Errorf("unable to validate threshold %q on metric expression %s; reason: "+
+					"parsing threshold failed %w", threshold.Source, source, err)
+			}
+
+			threshold.parsed = thresholdExpression
+		}
+
+		var reason string
+		switch {
+		case threshold.parsed.AggregationMethod != tokenValue:
+			reason = fmt.Sprintf("unsupported aggregation method %s on a metric expression, "+
+				"the only supported one is value", threshold.parsed.AggregationMethod)
+		case threshold.parsed.Window > 0:
+			reason = "metric expressions can't be evaluated over time windows"
+		default:
+			continue
+		}
+
+		err := fmt.Errorf("%w %q applied on metric expression %s; reason: %s",
+			ErrInvalidThreshold, threshold.Source, source, reason)
+		return errext.WithExitCodeIfNone(err, exitcodes.InvalidConfig)
+	}
+
+	return nil
+}
+
 // UnmarshalJSON is implementation of json.Unmarshaler
 func (ts *Thresholds) UnmarshalJSON(data []byte) error {
 	var configs []thresholdConfig
@@ -0,0 +1,359 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricExpressionOperators are the arithmetic operators of the metric
+// expressions, which metric names can't contain.
+const metricExpressionOperators = "+-*/()"
+
+// MetricOperand is a metric, or sub-metric, whose aggregated value is an
+// operand of a MetricExpression.
+type MetricOperand struct {
+	// Name is the name of the metric, or of the sub-metric, e.g.
+	// http_reqs{status:500}.
+	Name string
+
+	// Method is the aggregation method of the metric's value, or empty for
+	// the default one of its type: the count of a Counter, the value of a
+	// Gauge, the number of non-zero samples of a Rate, and the average of a
+	// Trend.
+	Method string
+}
+
+// MetricExpression is an arithmetic expression over the values of metrics,
+// e.g. http_req_failed / http_reqs, the thresholds of which are evaluated
+// against its value, as if it were a Gauge.
+//
+// It is of the form:
+// ```
+// expression -> term (("+" | "-") term)*
+// term       -> factor (("*" | "/") factor)*
+// factor     -> float | operand | "(" expression ")" | "-" factor
+// operand    -> metric | method "(" metric ")"
+// method     -> "count" | "rate" | "value" | "avg" | "min" | "med" | "max"
+// metric     -> name ("{" tags "}")?
+// ```
+type MetricExpression struct {
+	// Source is the text based source of the expression
+	Source string
+
+	root     *metricExpressionNode
+	operands []MetricOperand
+}
+
+// metricExpressionNode is either an operation on its two children, a
+// number or an operand.
+type metricExpressionNode struct {
+	operator    byte
+	left, right *metricExpressionNode
+
+	number  float64
+	operand *MetricOperand
+}
+
+// IsMetricExpression returns whether the name the thresholds are defined on
+// is a metric expression rather than a metric or a sub-metric.
+func IsMetricExpression(name string) bool {
+	var outside strings.Builder
+	depth := 0
+	for _, r := range name {
+		switch {
+		case r == '{':
+			depth++
+		case r == '}' && depth > 0:
+			depth--
+		case depth == 0:
+			outside.WriteRune(r)
+		}
+	}
+
+	return strings.ContainsAny(outside.String(), metricExpressionOperators)
+}
+
+// ParseMetricExpression parses a metric expression, e.g. http_req_failed / http_reqs.
+func ParseMetricExpression(input string) (*MetricExpression, error) {
+	p := &metricExpressionParser{input: input}
+	root, err := p.expression()
+	if err == nil && p.skipSpaces() < len(p.input) {
+		err = fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing metric expression %q; reason: %w", input, err)
+	}
+	if len(p.operands) == 0 {
+		return nil, fmt.Errorf("failed parsing metric expression %q; reason: it doesn't refer to any metric", input)
+	}
+
+	return &MetricExpression{Source: input, root: root, operands: p.operands}, nil
+}
+
+// Operands returns the metrics the expression refers to.
+func (e *MetricExpression) Operands() []MetricOperand {
+	return e.operands
+}
+
+// Validate ensures the metrics the expression refers to exist in the
+// registry, and support the aggregation methods applied to them.
+func (e *MetricExpression) Validate(r *Registry) error {
+	for _, operand := range e.operands {
+		parsedMetricName, _, err := ParseMetricName(operand.Name)
+		if err != nil {
+			return fmt.Errorf("invalid metric %q in expression %q; reason: %w", operand.Name, e.Source, err)
+		}
+
+		metric := r.Get(parsedMetricName)
+		if metric == nil {
+			return fmt.Errorf("no metric name %q found in expression %q", parsedMetricName, e.Source)
+		}
+
+		if operand.Method != "" && !metric.Type.supportsAggregationMethod(operand.Method) {
+			return fmt.Errorf("unsupported aggregation method %s on metric %s of type %s in expression %q. "+
+				"supported aggregation methods for this metric are: %s",
+				operand.Method, operand.Name, metric.Type, e.Source,
+				strings.Join(metric.Type.supportedAggregationMethods(), ", "),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Eval evaluates the expression against the sinks of the metrics it refers
+// to, which hold the samples emitted over the given duration. It returns
+// false if the expression has no value yet, because a sink has none or
+// because of a division by zero.
+func (e *MetricExpression) Eval(sinks func(name string) Sink, duration time.Duration) (float64, bool) {
+	return e.root.eval(sinks, duration)
+}
+
+func (n *metricExpressionNode) eval(sinks func(name string) Sink, duration time.Duration) (float64, bool) {
+	if n.operand != nil {
+		sink := sinks(n.operand.Name)
+		if sink == nil {
+			return 0, false
+		}
+		return operandValue(sink, n.operand.Method, duration)
+	}
+	if n.operator == 0 {
+		return n.number, true
+	}
+
+	left, ok := n.left.eval(sinks, duration)
+	if !ok {
+		return 0, false
+	}
+	right, ok := n.right.eval(sinks, duration)
+	if !ok {
+		return 0, false
+	}
+
+	switch n.operator {
+	case '+':
+		return left + right, true
+	case '-':
+		return left - right, true
+	case '*':
+		return left * right, true
+	default:
+		if right == 0 {
+			return 0, false
+		}
+		return left / right, true
+	}
+}
+
+// operandValue returns the value of the sink for the aggregation method, or
+// for the default one of its type if it's empty.
+func operandValue(sink Sink, method string, duration time.Duration) (float64, bool) {
+	if method == "" {
+		switch sinkImpl := sink.(type) {
+		case *CounterSink:
+			method = tokenCount
+		case *GaugeSink:
+			method = tokenValue
+		case *RateSink:
+			return float64(sinkImpl.Trues), true
+		default:
+			method = tokenAvg
+		}
+	}
+
+	// An empty set of thresholds only gets the values of the aggregation
+	// methods but the percentiles, which the expressions don't support.
+	sinked, err := (&Thresholds{}).sinkValues(sink, duration)
+	if err != nil {
+		return 0, false
+	}
+	value, ok := sinked[method]
+	return value, ok
+}
+
+// metricExpressionParser is a recursive descent parser of metric expressions.
+type metricExpressionParser struct {
+	input    string
+	pos      int
+	operands []MetricOperand
+}
+
+// skipSpaces moves past the spaces at the current position, and returns it.
+func (p *metricExpressionParser) skipSpaces() int {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+	return p.pos
+}
+
+// peek returns the character at the current position, past the spaces, or 0
+// at the end of the input.
+func (p *metricExpressionParser) peek() byte {
+	if p.skipSpaces() >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *metricExpressionParser) expression() (*metricExpressionNode, error) {
+	node, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	for c := p.peek(); c == '+' || c == '-'; c = p.peek() {
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		node = &metricExpressionNode{operator: c, left: node, right: right}
+	}
+
+	return node, nil
+}
+
+func (p *metricExpressionParser) term() (*metricExpressionNode, error) {
+	node, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+
+	for c := p.peek(); c == '*' || c == '/'; c = p.peek() {
+		p.pos++
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		node = &metricExpressionNode{operator: c, left: node, right: right}
+	}
+
+	return node, nil
+}
+
+func (p *metricExpressionParser) factor() (*metricExpressionNode, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, errors.New("unexpected end of expression")
+	case c == '-':
+		p.pos++
+		operand, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return &metricExpressionNode{operator: '-', left: &metricExpressionNode{}, right: operand}, nil
+	case c == '(':
+		p.pos++
+		node, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis at position %d", p.pos)
+		}
+		p.pos++
+		return node, nil
+	case c >= '0' && c <= '9' || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		number, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed number at position %d; reason: %w", start, err)
+		}
+		return &metricExpressionNode{number: number}, nil
+	default:
+		return p.operand()
+	}
+}
+
+func (p *metricExpressionParser) operand() (*metricExpressionNode, error) {
+	name, err := p.metric()
+	if err != nil {
+		return nil, err
+	}
+
+	operand := MetricOperand{Name: name}
+	if p.peek() == '(' {
+		if !isAggregationMethodToken(name) {
+			return nil, fmt.Errorf("unknown aggregation method %q", name)
+		}
+		p.pos++
+		p.skipSpaces()
+		if operand.Name, err = p.metric(); err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis at position %d", p.pos)
+		}
+		p.pos++
+		operand.Method = name
+	}
+
+	p.operands = append(p.operands, operand)
+	return &metricExpressionNode{operand: &operand}, nil
+}
+
+// metric scans the name of a metric, or a sub-metric, at the current position.
+func (p *metricExpressionParser) metric() (string, error) {
+	start := p.pos
+	for p.pos < len(p.input) && isMetricNameChar(p.input[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		if p.pos < len(p.input) {
+			return "", fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+		}
+		return "", errors.New("unexpected end of expression")
+	}
+
+	if p.pos < len(p.input) && p.input[p.pos] == '{' {
+		end := strings.IndexByte(p.input[p.pos:], '}')
+		if end < 0 {
+			return "", errors.New("missing ending bracket, sub-metric format needs to be 'metric{key:value}'")
+		}
+		p.pos += end + 1
+	}
+
+	return p.input[start:p.pos], nil
+}
+
+func isMetricNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// isAggregationMethodToken returns whether the input is one of the
+// aggregation methods but the percentiles.
+func isAggregationMethodToken(input string) bool {
+	for _, m := range aggregationMethodTokens {
+		if m == input && m != tokenPercentile {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsMetricExpression(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsMetricExpression("http_req_failed / http_reqs"))
+	assert.True(t, IsMetricExpression("rate(http_req_failed)"))
+	assert.False(t, IsMetricExpression("http_reqs"))
+	assert.False(t, IsMetricExpression("http_reqs{url:https://k6.io/a-b/(c)}"))
+}
+
+func TestParseMetricExpression(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input    string
+		operands []MetricOperand
+		err      string
+	}{
+		{
+			input:    "http_req_failed/http_reqs",
+			operands: []MetricOperand{{Name: "http_req_failed"}, {Name: "http_reqs"}},
+		},
+		{
+			input: "max(http_req_duration{status:200}) - 2 * (min(http_req_duration) + 1)",
+			operands: []MetricOperand{
+				{Name: "http_req_duration{status:200}", Method: "max"},
+				{Name: "http_req_duration", Method: "min"},
+			},
+		},
+		{input: "-custom_revenue / iterations", operands: []MetricOperand{{Name: "custom_revenue"}, {Name: "iterations"}}},
+		{input: "1 + 2", err: "it doesn't refer to any metric"},
+		{input: "http_reqs /", err: "unexpected end of expression"},
+		{input: "(http_reqs + 1", err: "missing closing parenthesis"},
+		{input: "http_reqs 1", err: `unexpected '1' at position 10`},
+		{input: "p(http_reqs) / 2", err: `unknown aggregation method "p"`},
+		{input: "http_reqs{status:200 / 2", err: "missing ending bracket"},
+		{input: "1.2.3 * http_reqs", err: "malformed number"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			t.Parallel()
+
+			expression, err := ParseMetricExpression(tc.input)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.operands, expression.Operands())
+		})
+	}
+}
+
+func TestMetricExpressionEval(t *testing.T) {
+	t.Parallel()
+
+	counter := NewSink(Counter)
+	for i := 0; i < 4; i++ {
+		counter.Add(Sample{Value: 1})
+	}
+	rate := NewSink(Rate)
+	rate.Add(Sample{Value: 1})
+	rate.Add(Sample{Value: 0})
+	trend := NewSink(Trend)
+	trend.Add(Sample{Value: 10})
+	trend.Add(Sample{Value: 30})
+	sinks := map[string]Sink{"counter": counter, "rate": rate, "trend": trend, "empty": NewSink(Rate)}
+	lookup := func(name string) Sink { return sinks[name] }
+
+	testCases := []struct {
+		input string
+		value float64
+		ok    bool
+	}{
+		{input: "rate / counter", value: 0.25, ok: true},
+		{input: "rate(rate) * 100", value: 50, ok: true},
+		{input: "trend - max(trend) / 2", value: 5, ok: true},
+		{input: "rate(counter) + -1", value: 1, ok: true},
+		{input: "(counter - 1) * 2 / 3", value: 2, ok: true},
+		{input: "counter / (rate - 1)", ok: false},
+		{input: "rate(empty) + 1", ok: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			t.Parallel()
+
+			expression, err := ParseMetricExpression(tc.input)
+			require.NoError(t, err)
+
+			value, ok := expression.Eval(lookup, 2*time.Second)
+			require.Equal(t, tc.ok, ok)
+			assert.InDelta(t, tc.value, value, 0.0001)
+		})
+	}
+}
+
+func TestThresholdsValidateMetricExpression(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	_, err := registry.NewMetric("test_counter", Counter)
+	require.NoError(t, err)
+	_, err = registry.NewMetric("test_rate", Rate)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name, threshold, err string
+	}{
+		{name: "test_rate / test_counter", threshold: "value<0.01"},
+		{name: "test_rate{status:500} / test_counter", threshold: "value<0.01"},
+		{name: "test_rate / unknown", threshold: "value<0.01", err: `no metric name "unknown" found`},
+		{name: "avg(test_rate) / test_counter", threshold: "value<0.01", err: "unsupported aggregation method avg"},
+		{name: "test_rate / test_counter", threshold: "count<1", err: "the only supported one is value"},
+		{name: "test_rate / test_counter", threshold: "value<1 over 1m", err: "can't be evaluated over time windows"},
+		{name: "test_rate / (test_counter", threshold: "value<1", err: "missing closing parenthesis"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name+" "+tc.threshold, func(t *testing.T) {
+			t.Parallel()
+
+			ts := NewThresholds([]string{tc.threshold})
+			err := ts.Validate(tc.name, registry)
+			if tc.err != "" {
+				require.ErrorIs(t, err, ErrInvalidThreshold)
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/types"
 )
 
 // thresholdExpression holds the parsed result of a threshold expression,
@@ -27,6 +30,11 @@ type thresholdExpression struct {
 
 	// Value holds the value parsed from the threshold expression.
 	Value float64
+
+	// Window holds the duration of the sliding time window the expression
+	// is evaluated over, for instance 1m for p(95) < 500 over 1m, or 0 if it
+	// is evaluated over the whole test run.
+	Window time.Duration
 }
 
 // SinkKey computes the key used to index a thresholdExpression in the engine's sinks.
@@ -53,9 +61,10 @@ func (te *thresholdExpression) SinkKey() string {
 // as defined in a JS script (for instance p(95)<1000), into a thresholdExpression
 // instance.
 //
-// It is expected to be of the form: `aggregation_method operator value`.
-// As defined by the following BNF:
+// It is expected to be of the form: `aggregation_method operator value`,
+// optionally followed by `over duration`. As defined by the following BNF:
 // ```
+// expression          -> assertion (whitespace+ "over" whitespace+ duration)?
 // assertion           -> aggregation_method whitespace* operator whitespace* float
 // aggregation_method  -> trend | rate | gauge | counter
 // counter             -> "count" | "rate"
@@ -66,12 +75,18 @@ func (te *thresholdExpression) SinkKey() string {
 // operator            -> ">" | ">=" | "<=" | "<" | "==" | "===" | "!="
 // float               -> digit+ ("." digit+)?
 // digit               -> "0" | "1" | "2" | "3" | "4" | "5" | "6" | "7" | "8" | "9"
+// duration            -> a duration, as accepted by types.ParseExtendedDuration
 // whitespace          -> " "
 // ```
 func parseThresholdExpression(input string) (*thresholdExpression, error) {
+	assertion, window, err := parseThresholdWindow(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing threshold expression's %q time window; reason: %w", input, err)
+	}
+
 	// Scanning makes no assumption on the underlying values, and only
 	// checks that the expression has the right format.
-	method, operator, value, err := scanThresholdExpression(input)
+	method, operator, value, err := scanThresholdExpression(assertion)
 	if err != nil {
 		return nil, fmt.Errorf("failed parsing threshold expression %q; reason: %w", input, err)
 	}
@@ -97,11 +112,37 @@ func parseThresholdExpression(input string) (*thresholdExpression, error) {
 		AggregationValue:  parsedMethodValue,
 		Operator:          operator,
 		Value:             parsedValue,
+		Window:            window,
 	}
 
 	return condition, nil
 }
 
+// tokenOver separates a threshold expression's assertion from the duration
+// of the time window it applies to.
+const tokenOver = "over"
+
+// parseThresholdWindow splits a threshold expression into its assertion and
+// the duration of the time window following it, if any, or 0 otherwise.
+func parseThresholdWindow(input string) (string, time.Duration, error) {
+	fields := strings.Fields(input)
+	if len(fields) < 2 || fields[len(fields)-2] != tokenOver {
+		return input, 0, nil
+	}
+
+	window, err := types.ParseExtendedDuration(fields[len(fields)-1])
+	if err != nil {
+		return "", 0, err
+	}
+	if window <= 0 {
+		return "", 0, fmt.Errorf("the time window must be positive, not %s", window)
+	}
+
+	assertion := strings.TrimSpace(input[:strings.LastIndex(input, tokenOver)])
+
+	return assertion, window, nil
+}
+
 // Define accepted threshold expression operators tokens
 const (
 	tokenLessEqual     = "<="
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/guregu/null.v3"
@@ -40,6 +41,36 @@ func TestParseThresholdExpression(t *testing.T) {
 			wantExpression: &thresholdExpression{AggregationMethod: "count", Operator: ">", Value: 20},
 			wantErr:        false,
 		},
+		{
+			name:  "valid threshold expression syntax over a time window",
+			input: "p(95) < 500 over 1m",
+			wantExpression: &thresholdExpression{
+				AggregationMethod: "p",
+				AggregationValue:  null.FloatFrom(95),
+				Operator:          "<",
+				Value:             500,
+				Window:            time.Minute,
+			},
+			wantErr: false,
+		},
+		{
+			name:           "missing time window duration fails",
+			input:          "count>20 over",
+			wantExpression: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "invalid time window duration fails",
+			input:          "count>20 over abc",
+			wantExpression: nil,
+			wantErr:        true,
+		},
+		{
+			name:           "negative time window duration fails",
+			input:          "count>20 over -1s",
+			wantExpression: nil,
+			wantErr:        true,
+		},
 	}
 	for _, testCase := range tests {
 		testCase := testCase
@@ -0,0 +1,65 @@
+package metrics
+
+import "time"
+
+// windowedSample is the time and value of a sample kept by a windowedSink.
+type windowedSample struct {
+	time  time.Time
+	value float64
+}
+
+// windowedSink keeps the samples of a metric emitted over a sliding time
+// window, so that the thresholds evaluated over the window can be run
+// against them.
+type windowedSink struct {
+	window     time.Duration
+	metricType MetricType
+	samples    []windowedSample
+}
+
+// add adds the provided sample to the window.
+func (ws *windowedSink) add(s Sample) {
+	ws.samples = append(ws.samples, windowedSample{time: s.Time, value: s.Value})
+}
+
+// sink drops the samples emitted before the window ending at the provided
+// time, and returns a sink of the metric's type holding the remaining ones.
+func (ws *windowedSink) sink(now time.Time) Sink {
+	start := now.Add(-ws.window)
+
+	// The samples are appended in roughly chronological order, but not
+	// strictly, as they are emitted by concurrent VUs; hence the filtering.
+	kept := ws.samples[:0]
+	sink := NewSink(ws.metricType)
+	for _, s := range ws.samples {
+		if s.time.Before(start) {
+			continue
+		}
+		kept = append(kept, s)
+		sink.Add(Sample{Time: s.time, Value: s.value})
+	}
+	ws.samples = kept
+
+	return sink
+}
+
+// AddSample adds the provided sample of the metric the thresholds apply to,
+// to the time windows of those evaluated over one, if any.
+func (ts *Thresholds) AddSample(s Sample) {
+	if ts.windows == nil {
+		// Thresholds evaluated over the same duration share their window.
+		ts.windows = make(map[time.Duration]*windowedSink)
+		for _, t := range ts.Thresholds {
+			if t.parsed == nil || t.parsed.Window <= 0 {
+				continue
+			}
+			if _, ok := ts.windows[t.parsed.Window]; !ok {
+				ts.windows[t.parsed.Window] = &windowedSink{window: t.parsed.Window, metricType: s.Metric.Type}
+			}
+		}
+	}
+
+	for _, ws := range ts.windows {
+		ws.add(s)
+	}
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowedSink(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	ws := &windowedSink{window: time.Minute, metricType: Trend}
+	ws.add(Sample{Time: now.Add(-2 * time.Minute), Value: 500})
+	ws.add(Sample{Time: now.Add(-10 * time.Second), Value: 20})
+	ws.add(Sample{Time: now.Add(-30 * time.Second), Value: 10})
+
+	sink, ok := ws.sink(now).(*TrendSink)
+	require.True(t, ok)
+	assert.Equal(t, uint64(2), sink.Count())
+	assert.Equal(t, float64(20), sink.Max())
+	assert.Len(t, ws.samples, 2, "the samples out of the window should have been dropped")
+
+	assert.True(t, ws.sink(now.Add(time.Minute)).IsEmpty())
+	assert.Empty(t, ws.samples)
+}
+
+func TestThresholdsRunOverWindow(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	metric, err := registry.NewMetric("test_trend", Trend)
+	require.NoError(t, err)
+
+	ts := NewThresholds([]string{"max<100", "max<100 over 1m"})
+	require.NoError(t, ts.Parse())
+
+	sink := NewTrendSink()
+	add := func(at time.Time, value float64) {
+		s := Sample{TimeSeries: TimeSeries{Metric: metric}, Time: at, Value: value}
+		sink.Add(s)
+		ts.AddSample(s)
+	}
+
+	// The spike happened before the window, so only the threshold evaluated
+	// over the whole test run fails.
+	now := time.Now()
+	add(now.Add(-2*time.Minute), 500)
+	add(now, 50)
+
+	passes, err := ts.Run(sink, 3*time.Minute)
+	require.NoError(t, err)
+	assert.False(t, passes)
+	assert.True(t, ts.Thresholds[0].LastFailed)
+	assert.False(t, ts.Thresholds[1].LastFailed)
+
+	// A spike within the window fails the threshold evaluated over it, ...
+	add(now, 200)
+	_, err = ts.Run(sink, 3*time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ts.Thresholds[1].LastFailed)
+
+	// ... which keeps failing once the spike is out of the window.
+	ts.windows[time.Minute].samples = nil
+	_, err = ts.Run(sink, 3*time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ts.Thresholds[1].LastFailed)
+}
+
+func TestThresholdsRunOverWindowCounterRate(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	metric, err := registry.NewMetric("test_counter", Counter)
+	require.NoError(t, err)
+
+	ts := NewThresholds([]string{"rate<1 over 10s"})
+	require.NoError(t, ts.Parse())
+
+	sink := &CounterSink{}
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		s := Sample{TimeSeries: TimeSeries{Metric: metric}, Time: now, Value: 1}
+		sink.Add(s)
+		ts.AddSample(s)
+	}
+
+	// The rate is computed over the window, rather than over the test run.
+	passes, err := ts.Run(sink, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, passes)
+	assert.Equal(t, 0.5, ts.windowSinked[10*time.Second]["rate"])
+}
@@ -0,0 +1,99 @@
+package clickhouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"go.k6.io/k6/lib/types"
+)
+
+func TestParseURL(t *testing.T) {
+	t.Parallel()
+	testdata := map[string]struct {
+		Config Config
+		Err    string
+	}{
+		"":                          {Config{}, ""},
+		"http://localhost:8123":     {Config{Addr: null.StringFrom("http://localhost:8123")}, ""},
+		"http://localhost:8123/db1": {Config{Addr: null.StringFrom("http://localhost:8123"), Database: null.StringFrom("db1")}, ""},
+		"https://user:pass@ch:8443": {
+			Config{
+				Addr:     null.StringFrom("https://ch:8443"),
+				Username: null.StringFrom("user"),
+				Password: null.StringFrom("pass"),
+			}, "",
+		},
+		"?table=results&asyncInsert=false&retries=5&retryInterval=2s": {
+			Config{
+				Table:         null.StringFrom("results"),
+				AsyncInsert:   null.BoolFrom(false),
+				Retries:       null.IntFrom(5),
+				RetryInterval: types.NullDurationFrom(2 * time.Second),
+			}, "",
+		},
+		"?tagsAsColumns=name&tagsAsColumns=group": {Config{TagsAsColumns: []string{"name", "group"}}, ""},
+		"?createTable=nope":                       {Config{}, "createTable must be true or false, not nope"},
+		"?retries=a":                              {Config{}, "strconv.Atoi: parsing \"a\": invalid syntax"},
+		"?foo=bar":                                {Config{}, "unknown query parameter: foo"},
+	}
+	for str, data := range testdata {
+		str, data := str, data
+		t.Run(str, func(t *testing.T) {
+			t.Parallel()
+			config, err := ParseURL(str)
+			if data.Err != "" {
+				assert.EqualError(t, err, data.Err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, data.Config, config)
+		})
+	}
+}
+
+func TestGetConsolidatedConfig(t *testing.T) {
+	t.Parallel()
+	config, err := GetConsolidatedConfig(
+		[]byte(`{"table":"from_json","retries":1}`),
+		map[string]string{"K6_CLICKHOUSE_TABLE": "from_env", "K6_CLICKHOUSE_ASYNC_INSERT": "false"},
+		"http://localhost:8123/?retries=2",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "from_env", config.Table.String)
+	assert.Equal(t, int64(2), config.Retries.Int64)
+	assert.False(t, config.AsyncInsert.Bool)
+	assert.Equal(t, "k6", config.Database.String)
+	assert.NoError(t, config.Validate())
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+	testdata := map[string]struct {
+		Config Config
+		Err    string
+	}{
+		"negative retries": {
+			Config{Retries: null.IntFrom(-1)},
+			"clickhouse's retries must not be negative, not -1",
+		},
+		"invalid table": {
+			Config{Table: null.StringFrom("samples; DROP TABLE users")},
+			`invalid clickhouse identifier "samples; DROP TABLE users", only letters, digits and underscores are allowed`,
+		},
+		"reserved tag": {
+			Config{TagsAsColumns: []string{"value"}},
+			`the "value" tag can't be stored as a column, it's the name of a reserved column`,
+		},
+	}
+	for name, data := range testdata {
+		data := data
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.EqualError(t, NewConfig().Apply(data.Config).Validate(), data.Err)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Package clickhouse provides an output plugin for inserting the metric
+// samples into a ClickHouse table, over ClickHouse's HTTP interface.
+package clickhouse
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output"
+)
+
+// timestampFormat is the format of the timestamps inserted into the UTC
+// DateTime64(6) timestamp column.
+const timestampFormat = "2006-01-02 15:04:05.000000"
+
+// requestTimeout is the time after which a request to ClickHouse is given up.
+const requestTimeout = 30 * time.Second
+
+// reservedColumns are the columns of the table which can't hold a tag.
+var reservedColumns = []string{"timestamp", "metric", "metric_type", "value", "tags", "metadata"}
+
+// isReservedColumn returns whether the provided name is a reserved column.
+func isReservedColumn(name string) bool {
+	for _, c := range reservedColumns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Output is the ClickHouse Output struct
+type Output struct {
+	output.SampleBuffer
+
+	Config Config
+
+	client          *http.Client
+	logger          logrus.FieldLogger
+	periodicFlusher *output.PeriodicFlusher
+}
+
+// New returns new ClickHouse output
+func New(params output.Params) (output.Output, error) {
+	return newOutput(params)
+}
+
+func newOutput(params output.Params) (*Output, error) {
+	conf, err := GetConsolidatedConfig(params.JSONConfig, params.Environment, params.ConfigArgument)
+	if err != nil {
+		return nil, err
+	}
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	if conf.Insecure.Bool {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+	}
+
+	return &Output{
+		Config: conf,
+		client: &http.Client{Transport: transport, Timeout: requestTimeout},
+		logger: params.Logger.WithFields(logrus.Fields{
+			"output": "ClickHouse",
+		}),
+	}, nil
+}
+
+// Description returns a human-readable description of the output.
+func (o *Output) Description() string {
+	return fmt.Sprintf("ClickHouse (%s, %s.%s)", o.Config.Addr.String, o.Config.Database.String, o.Config.Table.String)
+}
+
+// Start creates the table, unless disabled, and starts the goroutine for
+// metric flushing.
+func (o *Output) Start() error {
+	o.logger.Debug("Starting...")
+	if o.Config.CreateTable.Bool {
+		if err := o.createTable(); err != nil {
+			return fmt.Errorf("couldn't create the clickhouse table: %w", err)
+		}
+	}
+
+	pf, err := output.NewPeriodicFlusher(o.Config.PushInterval.TimeDuration(), o.flushMetrics)
+	if err != nil {
+		return err
+	}
+	o.logger.Debug("Started!")
+	o.periodicFlusher = pf
+
+	return nil
+}
+
+// Stop flushes any remaining metrics and stops the goroutine.
+func (o *Output) Stop() error {
+	o.logger.Debug("Stopping...")
+	defer o.logger.Debug("Stopped!")
+	o.periodicFlusher.Stop()
+	return nil
+}
+
+// createTable creates the database and the table the samples are inserted
+// into, if they don't exist.
+func (o *Output) createTable() error {
+	if err := o.exec("CREATE DATABASE IF NOT EXISTS "+o.Config.Database.String, nil, nil); err != nil {
+		return err
+	}
+
+	var columns strings.Builder
+	columns.WriteString("timestamp DateTime64(6, 'UTC'), " +
+		"metric LowCardinality(String), " +
+		"metric_type LowCardinality(String), " +
+		"value Float64, ")
+	for _, tag := range o.Config.TagsAsColumns {
+		columns.WriteString(tag + " LowCardinality(String), ")
+	}
+	columns.WriteString("tags Map(String, String), metadata Map(String, String)")
+
+	query := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s.%s (%s) ENGINE = MergeTree ORDER BY (metric, timestamp)",
+		o.Config.Database.String, o.Config.Table.String, columns.String(),
+	)
+	return o.exec(query, nil, nil)
+}
+
+func (o *Output) flushMetrics() {
+	containers := o.GetBufferedSamples()
+	if len(containers) < 1 {
+		return
+	}
+
+	body, count, err := o.encodeSamples(containers)
+	if err != nil {
+		o.logger.WithError(err).Error("Couldn't encode the samples")
+		return
+	}
+
+	o.logger.WithField("samples", count).Debug("Inserting...")
+	settings := url.Values{}
+	if o.Config.AsyncInsert.Bool {
+		settings.Set("async_insert", "1")
+		settings.Set("wait_for_async_insert", "1")
+	}
+	query := fmt.Sprintf("INSERT INTO %s.%s FORMAT JSONEachRow", o.Config.Database.String, o.Config.Table.String)
+
+	startTime := time.Now()
+	if err := o.exec(query, settings, body); err != nil {
+		o.logger.WithError(err).Error("Couldn't insert the samples")
+		return
+	}
+	t := time.Since(startTime)
+	o.logger.WithField("t", t).Debug("Samples inserted!")
+
+	if t > o.Config.PushInterval.TimeDuration() {
+		o.logger.WithField("t", t).
+			Warn("The flush operation took higher than the expected set push interval. If you see this message multiple times then the setup or configuration need to be adjusted to achieve a sustainable rate.") //nolint:lll
+	}
+}
+
+// encodeSamples returns the provided samples encoded as JSONEachRow rows, and
+// their count.
+func (o *Output) encodeSamples(containers []metrics.SampleContainer) ([]byte, int, error) {
+	var (
+		buf   bytes.Buffer
+		count int
+	)
+	enc := json.NewEncoder(&buf)
+	for _, container := range containers {
+		for _, sample := range container.GetSamples() {
+			tags := sample.Tags.Map()
+			metadata := sample.Metadata
+			if metadata == nil {
+				metadata = map[string]string{}
+			}
+
+			row := map[string]interface{}{
+				"timestamp":   sample.Time.UTC().Format(timestampFormat),
+				"metric":      sample.Metric.Name,
+				"metric_type": sample.Metric.Type.String(),
+				"value":       sample.Value,
+				"tags":        tags,
+				"metadata":    metadata,
+			}
+			for _, tag := range o.Config.TagsAsColumns {
+				row[tag] = tags[tag]
+				delete(tags, tag)
+			}
+
+			if err := enc.Encode(row); err != nil {
+				return nil, 0, err
+			}
+			count++
+		}
+	}
+
+	return buf.Bytes(), count, nil
+}
+
+// exec executes the provided query, with the given settings and data, retrying
+// on transient failures as many times as configured.
+func (o *Output) exec(query string, settings url.Values, data []byte) error {
+	var err error
+	for attempt := int64(0); ; attempt++ {
+		var retryable bool
+		if retryable, err = o.do(query, settings, data); err == nil || !retryable {
+			return err
+		}
+		if attempt >= o.Config.Retries.Int64 {
+			return err
+		}
+
+		delay := o.Config.RetryInterval.TimeDuration() * time.Duration(attempt+1)
+		o.logger.WithError(err).WithField("delay", delay).Warn("Retrying the clickhouse request")
+		time.Sleep(delay)
+	}
+}
+
+// do makes the request executing the provided query, and returns whether the
+// error it failed with, if any, is transient.
+func (o *Output) do(query string, settings url.Values, data []byte) (bool, error) {
+	params := url.Values{}
+	for k, vs := range settings {
+		params[k] = vs
+	}
+	// The queries name the database themselves, as it may not exist yet.
+	params.Set("query", query)
+
+	req, err := http.NewRequest(http.MethodPost, o.Config.Addr.String+"/?"+params.Encode(), bytes.NewReader(data)) //nolint:noctx
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if o.Config.Username.Valid {
+		req.SetBasicAuth(o.Config.Username.String, o.Config.Password.String)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	err = fmt.Errorf("clickhouse responded with %s: %s", resp.Status, bytes.TrimSpace(msg))
+	if len(msg) == 0 {
+		err = errors.New("clickhouse responded with " + resp.Status)
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests, err
+}
@@ -0,0 +1,142 @@
+package clickhouse
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/metrics"
+	"go.k6.io/k6/output"
+)
+
+func TestOutput(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		queries  []string
+		rows     []map[string]interface{}
+		failures = 1
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "pass", pass)
+
+		query := r.URL.Query().Get("query")
+		queries = append(queries, query)
+		if !strings.HasPrefix(query, "INSERT") {
+			return
+		}
+		assert.Equal(t, "1", r.URL.Query().Get("async_insert"))
+
+		// The first insert fails, and is expected to be retried.
+		if failures > 0 {
+			failures--
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		s := bufio.NewScanner(r.Body)
+		for s.Scan() {
+			var row map[string]interface{}
+			assert.NoError(t, json.Unmarshal(s.Bytes(), &row))
+			rows = append(rows, row)
+		}
+	}))
+	defer srv.Close()
+
+	o, err := newOutput(output.Params{
+		Logger:         testutils.NewLogger(t),
+		ConfigArgument: strings.Replace(srv.URL, "http://", "http://user:pass@", 1) + "/loadtests?retryInterval=1ms",
+	})
+	require.NoError(t, err)
+	require.NoError(t, o.Start())
+
+	registry := metrics.NewRegistry()
+	metric, err := registry.NewMetric("test_trend", metrics.Trend)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+	o.AddMetricSamples([]metrics.SampleContainer{metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: metric,
+			Tags:   registry.RootTagSet().With("name", "home").With("group", "::g"),
+		},
+		Time:     now,
+		Value:    12.5,
+		Metadata: map[string]string{"vu": "1"},
+	}})
+	require.NoError(t, o.Stop())
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, queries, 4)
+	assert.Equal(t, "CREATE DATABASE IF NOT EXISTS loadtests", queries[0])
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS loadtests.samples (timestamp DateTime64(6, 'UTC'), "+
+		"metric LowCardinality(String), metric_type LowCardinality(String), value Float64, "+
+		"scenario LowCardinality(String), name LowCardinality(String), method LowCardinality(String), "+
+		"status LowCardinality(String), tags Map(String, String), metadata Map(String, String)) "+
+		"ENGINE = MergeTree ORDER BY (metric, timestamp)", queries[1])
+	assert.Equal(t, "INSERT INTO loadtests.samples FORMAT JSONEachRow", queries[2])
+	assert.Equal(t, queries[2], queries[3])
+
+	require.Len(t, rows, 1)
+	assert.Equal(t, map[string]interface{}{
+		"timestamp":   "2024-01-02 03:04:05.000006",
+		"metric":      "test_trend",
+		"metric_type": "trend",
+		"value":       12.5,
+		"scenario":    "",
+		"name":        "home",
+		"method":      "",
+		"status":      "",
+		"tags":        map[string]interface{}{"group": "::g"},
+		"metadata":    map[string]interface{}{"vu": "1"},
+	}, rows[0])
+}
+
+func TestOutputNonRetryableError(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests++
+		rw.WriteHeader(http.StatusBadRequest)
+		_, _ = rw.Write([]byte("Code: 62. DB::Exception: Syntax error\n"))
+	}))
+	defer srv.Close()
+
+	o, err := newOutput(output.Params{
+		Logger:         testutils.NewLogger(t),
+		ConfigArgument: srv.URL + "?retryInterval=1ms",
+	})
+	require.NoError(t, err)
+
+	err = o.Start()
+	require.EqualError(t, err, "couldn't create the clickhouse table: "+
+		"clickhouse responded with 400 Bad Request: Code: 62. DB::Exception: Syntax error")
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, requests)
+}
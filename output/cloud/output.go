@@ -308,6 +308,9 @@ func (out *Output) getRunStatus(testErr error) cloudapi.RunStatus {
 			return cloudapi.RunStatusAbortedLimit
 		case errext.AbortedByOutput:
 			return cloudapi.RunStatusAbortedSystem
+		case errext.FailedByScript:
+			// The test run finished normally, like below.
+			return cloudapi.RunStatusFinished
 		case errext.AbortedByThresholdsAfterTestEnd:
 			// The test run finished normally, it wasn't prematurely aborted by
 			// anything while running, but the thresholds failed at the end and
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/lib/types"
 	"go.k6.io/k6/metrics"
 )
 
@@ -17,6 +19,8 @@ type Manager struct {
 	logger  logrus.FieldLogger
 
 	testStopCallback func(error)
+	cardinality      *metrics.CardinalityLimiter
+	redaction        types.NullRedaction
 }
 
 // NewManager returns a new manager for the given outputs.
@@ -28,6 +32,20 @@ func NewManager(outputs []Output, logger logrus.FieldLogger, testStopCallback fu
 	}
 }
 
+// SetCardinalityLimiter makes the manager fold the overflowing tag values of
+// the samples with the limiter, before they are sent to the outputs. It has
+// to be called before Start().
+func (om *Manager) SetCardinalityLimiter(limiter *metrics.CardinalityLimiter) {
+	om.cardinality = limiter
+}
+
+// SetRedaction makes the manager redact the values of the tags and the
+// metadata of the samples with the rules, before they are sent to the outputs.
+// It has to be called before Start().
+func (om *Manager) SetRedaction(redaction types.NullRedaction) {
+	om.redaction = redaction
+}
+
 // Start spins up all configured outputs and then starts a new goroutine that
 // pipes metrics from the given samples channel to them.
 //
@@ -66,6 +84,15 @@ func (om *Manager) Start(samplesChan chan metrics.SampleContainer) (wait func(),
 					sendToOutputs(buffer)
 					return
 				}
+				if sampleContainer = dropWarmupSamples(sampleContainer); sampleContainer == nil {
+					continue
+				}
+				if om.redaction.Valid {
+					sampleContainer = om.redact(sampleContainer)
+				}
+				if om.cardinality != nil {
+					sampleContainer = om.limitCardinality(sampleContainer)
+				}
 				buffer = append(buffer, sampleContainer)
 			case <-ticker.C:
 				sendToOutputs(buffer)
@@ -82,6 +109,109 @@ func (om *Manager) Start(samplesChan chan metrics.SampleContainer) (wait func(),
 	return wait, finish, nil
 }
 
+// limitCardinality folds the overflowing tag values of the container's
+// samples, warning about the tags that start overflowing.
+func (om *Manager) limitCardinality(container metrics.SampleContainer) metrics.SampleContainer {
+	limit := func(s metrics.Sample) metrics.Sample {
+		s, overflows := om.cardinality.Limit(s)
+		for _, o := range overflows {
+			if o.PerMetric {
+				om.logger.Warnf("The metric '%s' has more than %d unique tag sets, the new values of its tag '%s' "+
+					"are folded into '%s'. Consider not using high-cardinality values, like unique IDs, as tags.",
+					o.Metric, o.Limit, o.Tag, metrics.OtherTagValue)
+			} else {
+				om.logger.Warnf("The tag '%s' of the metric '%s' has more than %d unique values, its new values "+
+					"are folded into '%s'. Consider not using high-cardinality values, like unique IDs, as tags.",
+					o.Tag, o.Metric, o.Limit, metrics.OtherTagValue)
+			}
+		}
+		return s
+	}
+
+	// A single sample is its own container, a copy of which is returned by
+	// GetSamples(), so it has to be replaced.
+	if s, ok := container.(metrics.Sample); ok {
+		return limit(s)
+	}
+	samples := container.GetSamples()
+	for i := range samples {
+		samples[i] = limit(samples[i])
+	}
+	return container
+}
+
+// dropWarmupSamples returns the container without the samples of the warm-up
+// phases whose samples are dropped, or nil if none of its samples are left.
+func dropWarmupSamples(container metrics.SampleContainer) metrics.SampleContainer {
+	if s, ok := container.(metrics.Sample); ok {
+		if _, drop := s.Metadata[lib.WarmupDropMetadata]; drop {
+			return nil
+		}
+		return s
+	}
+
+	samples := container.GetSamples()
+	kept := 0
+	for _, s := range samples {
+		if _, drop := s.Metadata[lib.WarmupDropMetadata]; !drop {
+			kept++
+		}
+	}
+	switch kept {
+	case len(samples):
+		return container
+	case 0:
+		return nil
+	}
+	filtered := make(metrics.Samples, 0, kept)
+	for _, s := range samples {
+		if _, drop := s.Metadata[lib.WarmupDropMetadata]; !drop {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// redact redacts the values of the tags and the metadata of the container's
+// samples.
+func (om *Manager) redact(container metrics.SampleContainer) metrics.SampleContainer {
+	redact := func(s metrics.Sample) metrics.Sample {
+		for name, value := range s.Tags.Map() {
+			if redacted := om.redaction.RedactTag(name, value); redacted != value {
+				s.Tags = s.Tags.With(name, redacted)
+			}
+		}
+		var metadata map[string]string
+		for key, value := range s.Metadata {
+			if redacted := om.redaction.RedactTag(key, value); redacted != value {
+				if metadata == nil {
+					// the metadata can be shared with other samples
+					metadata = make(map[string]string, len(s.Metadata))
+					for k, v := range s.Metadata {
+						metadata[k] = v
+					}
+				}
+				metadata[key] = redacted
+			}
+		}
+		if metadata != nil {
+			s.Metadata = metadata
+		}
+		return s
+	}
+
+	// A single sample is its own container, a copy of which is returned by
+	// GetSamples(), so it has to be replaced.
+	if s, ok := container.(metrics.Sample); ok {
+		return redact(s)
+	}
+	samples := container.GetSamples()
+	for i := range samples {
+		samples[i] = redact(samples[i])
+	}
+	return container
+}
+
 // startOutputs spins up all configured outputs. If some output fails to start,
 // it stops the already started ones. This may take some time, since some
 // outputs make initial network requests to set up whatever remote services are
@@ -0,0 +1,157 @@
+// Package aws provides a source of the secrets in AWS Secrets Manager.
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.k6.io/k6/lib/netext/httpext"
+	"go.k6.io/k6/secretsource"
+)
+
+const requestTimeout = 30 * time.Second
+
+// Config is the configuration of a source of the secrets in AWS Secrets
+// Manager.
+type Config struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Endpoint is the endpoint of the Secrets Manager API, the one of the
+	// region by default.
+	Endpoint string
+
+	// SecretID is the ID of the secret, whose value is a JSON object, whose
+	// fields are the secrets. When it's empty, the keys of the secrets are the
+	// IDs of the secrets, or the ID#field pairs for the fields of the JSON
+	// ones.
+	SecretID string
+}
+
+type source struct {
+	config Config
+	client *http.Client
+}
+
+// NewSource returns a source of the secrets in AWS Secrets Manager.
+func NewSource(config Config, transport http.RoundTripper) (secretsource.Source, error) {
+	if config.Region == "" {
+		return nil, errors.New("the AWS region is required")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("the AWS credentials are required")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://secretsmanager." + config.Region + ".amazonaws.com"
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	client := &http.Client{
+		Timeout: requestTimeout,
+		Transport: httpext.NewAWSSigV4Transport(transport, &httpext.AWSSigV4Config{
+			AccessKeyID:     config.AccessKeyID,
+			SecretAccessKey: config.SecretAccessKey,
+			SessionToken:    config.SessionToken,
+			Region:          config.Region,
+			Service:         "secretsmanager",
+		}),
+	}
+	return source{config: config, client: client}, nil
+}
+
+func (s source) Description() string {
+	return fmt.Sprintf("AWS Secrets Manager in %s", s.config.Region)
+}
+
+func (s source) Get(key string) (string, error) {
+	id, field, hasField := s.config.SecretID, key, true
+	if id == "" {
+		id, field, hasField = strings.Cut(key, "#")
+	}
+
+	value, err := s.getSecretValue(id)
+	if err != nil {
+		return "", err
+	}
+	if !hasField {
+		return value, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("the secret %s isn't a JSON object", id)
+	}
+	secret, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("the secret %s has no field %q", id, field)
+	}
+	if str, ok := secret.(string); ok {
+		return str, nil
+	}
+	encoded, err := json.Marshal(secret)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// getSecretValue returns the string value of the secret of the given ID, with
+// the GetSecretValue action of the Secrets Manager API.
+func (s source) getSecretValue(id string) (string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Type != "" {
+			return "", fmt.Errorf("%s: %s", apiErr.Type, apiErr.Message)
+		}
+		return "", fmt.Errorf("unexpected status code %d for the secret %s", res.StatusCode, id)
+	}
+
+	var secret struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("couldn't parse the secret %s: %w", id, err)
+	}
+	if secret.SecretString == nil {
+		return "", fmt.Errorf("the secret %s has no string value", id)
+	}
+	return *secret.SecretString, nil
+}
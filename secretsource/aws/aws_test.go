@@ -0,0 +1,80 @@
+package aws_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/secretsource/aws"
+)
+
+func TestSource(t *testing.T) {
+	t.Parallel()
+
+	secrets := map[string]string{
+		"prod/db":    `{"password": "hunter2", "port": 5432}`,
+		"prod/token": "abc",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=AKID/"), r.Header.Get("Authorization"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/secretsmanager/aws4_request")
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+
+		var input struct{ SecretId string } //nolint:revive,stylecheck
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		secret, ok := secrets[input.SecretId]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"__type": "ResourceNotFoundException", "message": "Secrets Manager can't find the secret."}`)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"Name": input.SecretId, "SecretString": secret})
+	}))
+	defer srv.Close()
+
+	config := aws.Config{
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "SECRET",
+		SessionToken:    "session",
+		Endpoint:        srv.URL,
+	}
+	source, err := aws.NewSource(config, srv.Client().Transport)
+	require.NoError(t, err)
+	assert.Equal(t, "AWS Secrets Manager in eu-west-1", source.Description())
+
+	secret, err := source.Get("prod/token")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", secret)
+	secret, err = source.Get("prod/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+	secret, err = source.Get("prod/db#port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", secret)
+
+	_, err = source.Get("prod/db#user")
+	require.ErrorContains(t, err, `the secret prod/db has no field "user"`)
+	_, err = source.Get("prod/token#value")
+	require.ErrorContains(t, err, "the secret prod/token isn't a JSON object")
+	_, err = source.Get("prod/api")
+	require.ErrorContains(t, err, "ResourceNotFoundException: Secrets Manager can't find the secret.")
+
+	config.SecretID = "prod/db"
+	source, err = aws.NewSource(config, srv.Client().Transport)
+	require.NoError(t, err)
+	secret, err = source.Get("password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+
+	_, err = aws.NewSource(aws.Config{Region: "eu-west-1"}, nil)
+	require.ErrorContains(t, err, "the AWS credentials are required")
+}
@@ -0,0 +1,31 @@
+package secretsource
+
+import "fmt"
+
+// envSource gets the secrets from the environment variables of the given
+// prefix, e.g. the secret "password" from K6_SECRET_password.
+type envSource struct {
+	env    map[string]string
+	prefix string
+}
+
+// NewEnvSource returns a source of the secrets in the environment variables
+// whose names are the keys with the given prefix.
+func NewEnvSource(env map[string]string, prefix string) Source {
+	return envSource{env: env, prefix: prefix}
+}
+
+func (s envSource) Description() string {
+	if s.prefix == "" {
+		return "the environment variables"
+	}
+	return fmt.Sprintf("the environment variables prefixed with %s", s.prefix)
+}
+
+func (s envSource) Get(key string) (string, error) {
+	secret, ok := s.env[s.prefix+key]
+	if !ok {
+		return "", fmt.Errorf("the environment variable %s isn't set", s.prefix+key)
+	}
+	return secret, nil
+}
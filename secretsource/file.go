@@ -0,0 +1,60 @@
+package secretsource
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.k6.io/k6/lib/fsext"
+)
+
+// fileSource gets the secrets from a file of key=value lines, read when the
+// source is created.
+type fileSource struct {
+	path    string
+	secrets map[string]string
+}
+
+// NewFileSource returns a source of the secrets in the file of the given path,
+// which has a key=value line for each secret, the spaces around the keys and
+// the values being trimmed. The empty lines, and the ones starting with #, are
+// ignored.
+func NewFileSource(fs fsext.Fs, path string) (Source, error) {
+	data, err := fsext.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read the secrets file: %w", err)
+	}
+
+	secrets := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, secret, ok := strings.Cut(text, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("line %d of the secrets file %s isn't a key=value pair", line, path)
+		}
+		secrets[strings.TrimSpace(key)] = strings.TrimSpace(secret)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read the secrets file: %w", err)
+	}
+
+	return fileSource{path: path, secrets: secrets}, nil
+}
+
+func (s fileSource) Description() string {
+	return fmt.Sprintf("the file %s", s.path)
+}
+
+func (s fileSource) Get(key string) (string, error) {
+	secret, ok := s.secrets[key]
+	if !ok {
+		return "", errors.New("the secret isn't in the file")
+	}
+	return secret, nil
+}
@@ -0,0 +1,131 @@
+// Package secretsource holds the sources of the secrets of the tests, e.g. the
+// environment, files, or secrets managers, which the scripts get the secrets
+// from at runtime, with the k6/secrets module, rather than from __ENV.
+//
+// The secrets are redacted from the logs, which include the console output,
+// the HTTP request and response dumps, and the error messages.
+package secretsource
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"go.k6.io/k6/log"
+)
+
+// Redacted is what the secrets are replaced with in the logs.
+const Redacted = "***SECRET_REDACTED***"
+
+// Source is a source of secrets.
+type Source interface {
+	// Description returns a short human-readable description of the source.
+	Description() string
+
+	// Get returns the secret of the given key.
+	Get(key string) (string, error)
+}
+
+// Manager gets the secrets from the sources of the test, and redacts them from
+// the logs. It's safe for concurrent use by the VUs.
+type Manager struct {
+	sources       map[string]Source
+	defaultSource string
+
+	mu      sync.RWMutex
+	secrets map[secretID]string
+	// replacer replaces all the secrets that were got, longest first, so
+	// the ones containing the others are redacted as a whole.
+	replacer *strings.Replacer
+}
+
+type secretID struct {
+	source, key string
+}
+
+// NewManager returns a new manager of the given sources, by their names. The
+// default one is the one to get the secrets from when no source is named.
+func NewManager(sources map[string]Source, defaultSource string) (*Manager, error) {
+	if _, ok := sources[defaultSource]; !ok && len(sources) > 0 {
+		return nil, fmt.Errorf("the default secret source %q isn't defined", defaultSource)
+	}
+	return &Manager{
+		sources:       sources,
+		defaultSource: defaultSource,
+		secrets:       make(map[secretID]string),
+	}, nil
+}
+
+// Get returns the secret of the given key from the named source, or the
+// default one if the name is empty. The secrets are fetched once, and redacted
+// from the logs from then on.
+func (m *Manager) Get(sourceName, key string) (string, error) {
+	if len(m.sources) == 0 {
+		return "", errors.New("no secret sources are configured, use the --secret-source option")
+	}
+	if sourceName == "" {
+		sourceName = m.defaultSource
+	}
+	source, ok := m.sources[sourceName]
+	if !ok {
+		return "", fmt.Errorf("unknown secret source %q", sourceName)
+	}
+
+	id := secretID{source: sourceName, key: key}
+	m.mu.RLock()
+	secret, ok := m.secrets[id]
+	m.mu.RUnlock()
+	if ok {
+		return secret, nil
+	}
+
+	secret, err := source.Get(key)
+	if err != nil {
+		return "", fmt.Errorf("couldn't get the secret %q from %s: %w", key, source.Description(), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.secrets[id] = secret
+	m.updateReplacer()
+	return secret, nil
+}
+
+// updateReplacer needs to be called with the lock held.
+func (m *Manager) updateReplacer() {
+	values := make([]string, 0, len(m.secrets))
+	for _, secret := range m.secrets {
+		if secret != "" {
+			values = append(values, secret)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+
+	oldnew := make([]string, 0, 2*len(values))
+	for _, secret := range values {
+		oldnew = append(oldnew, secret, Redacted)
+	}
+	m.replacer = strings.NewReplacer(oldnew...)
+}
+
+// Redact returns the text with the secrets that were got replaced.
+func (m *Manager) Redact(text string) string {
+	m.mu.RLock()
+	replacer := m.replacer
+	m.mu.RUnlock()
+	if replacer == nil {
+		return text
+	}
+	return replacer.Replace(text)
+}
+
+// AddRedactionHook makes the logger redact the secrets from its entries. The
+// hook is fired before the ones the logger already has, e.g. the ones of the
+// log outputs, so they get the redacted entries.
+func (m *Manager) AddRedactionHook(logger *logrus.Logger) {
+	log.AddRedactionHook(logger, m.Redact)
+}
@@ -0,0 +1,163 @@
+package secretsource_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.k6.io/k6/lib/fsext"
+	"go.k6.io/k6/lib/testutils"
+	"go.k6.io/k6/secretsource"
+)
+
+type countingSource struct {
+	secrets map[string]string
+	gets    int
+}
+
+func (s *countingSource) Description() string { return "the test secrets" }
+
+func (s *countingSource) Get(key string) (string, error) {
+	s.gets++
+	secret, ok := s.secrets[key]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return secret, nil
+}
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	db := &countingSource{secrets: map[string]string{"password": "hunter2", "user": "admin"}}
+	api := &countingSource{secrets: map[string]string{"token": "hunter2-token"}}
+	manager, err := secretsource.NewManager(map[string]secretsource.Source{"db": db, "api": api}, "db")
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.SetOutput(testutils.NewTestOutput(t))
+	hook := &testutils.SimpleLogrusHook{HookedLevels: logrus.AllLevels}
+	logger.AddHook(hook)
+	manager.AddRedactionHook(logger)
+
+	logger.Info("before hunter2 was got")
+	assert.Equal(t, []string{"before hunter2 was got"}, hook.Lines())
+
+	secret, err := manager.Get("", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+	secret, err = manager.Get("db", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+	assert.Equal(t, 1, db.gets, "the secrets are fetched once")
+
+	secret, err = manager.Get("api", "token")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2-token", secret)
+
+	logger.WithField("error", fmt.Errorf("token hunter2-token was rejected")).
+		WithField("user", "admin").
+		Warn("the password is hunter2")
+	entries := hook.Drain()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "the password is ***SECRET_REDACTED***", entries[0].Message)
+	assert.Equal(t, "token ***SECRET_REDACTED*** was rejected", entries[0].Data["error"])
+	assert.Equal(t, "admin", entries[0].Data["user"], "only the secrets that were got are redacted")
+
+	_, err = manager.Get("db", "unknown")
+	require.ErrorContains(t, err, `couldn't get the secret "unknown" from the test secrets: not found`)
+	_, err = manager.Get("unknown", "password")
+	require.ErrorContains(t, err, `unknown secret source "unknown"`)
+
+	_, err = secretsource.NewManager(map[string]secretsource.Source{"db": db}, "api")
+	require.ErrorContains(t, err, `the default secret source "api" isn't defined`)
+}
+
+func TestEnvSource(t *testing.T) {
+	t.Parallel()
+
+	source := secretsource.NewEnvSource(map[string]string{"K6_SECRET_password": "hunter2", "password": "env"}, "K6_SECRET_")
+	secret, err := source.Get("password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+
+	_, err = source.Get("token")
+	require.ErrorContains(t, err, "the environment variable K6_SECRET_token isn't set")
+}
+
+func TestFileSource(t *testing.T) {
+	t.Parallel()
+
+	fs := fsext.NewMemMapFs()
+	require.NoError(t, fsext.WriteFile(fs, "/secrets.txt", []byte("# the database\npassword=hunter2=\n\n token = abc\n"), 0o644))
+	source, err := secretsource.NewFileSource(fs, "/secrets.txt")
+	require.NoError(t, err)
+
+	secret, err := source.Get("password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2=", secret)
+	secret, err = source.Get("token")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", secret)
+	_, err = source.Get("user")
+	require.ErrorContains(t, err, "the secret isn't in the file")
+
+	require.NoError(t, fsext.WriteFile(fs, "/invalid.txt", []byte("password=hunter2\ntoken\n"), 0o644))
+	_, err = secretsource.NewFileSource(fs, "/invalid.txt")
+	require.ErrorContains(t, err, "line 2 of the secrets file /invalid.txt isn't a key=value pair")
+
+	_, err = secretsource.NewFileSource(fs, "/missing.txt")
+	require.ErrorContains(t, err, "couldn't read the secrets file")
+}
+
+func TestVaultSource(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/k6/db":
+			_, _ = fmt.Fprint(w, `{"data": {"data": {"password": "hunter2", "port": 5432}, "metadata": {"version": 1}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	config := secretsource.VaultConfig{Address: srv.URL, Token: "root", Namespace: "team", Mount: "kv"}
+	source, err := secretsource.NewVaultSource(config, srv.Client())
+	require.NoError(t, err)
+
+	secret, err := source.Get("k6/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+	secret, err = source.Get("k6/db#port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", secret)
+
+	_, err = source.Get("k6/db#user")
+	require.ErrorContains(t, err, `the secret k6/db has no field "user"`)
+	_, err = source.Get("k6/api#token")
+	require.ErrorContains(t, err, "unexpected status code 404 for the secret k6/api")
+	_, err = source.Get("password")
+	require.ErrorContains(t, err, "the key needs to be a path#field pair")
+
+	config.Path = "k6/db"
+	source, err = secretsource.NewVaultSource(config, srv.Client())
+	require.NoError(t, err)
+	secret, err = source.Get("password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret)
+
+	_, err = secretsource.NewVaultSource(secretsource.VaultConfig{Address: srv.URL}, nil)
+	require.ErrorContains(t, err, "the Vault token is required")
+}
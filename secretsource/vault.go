@@ -0,0 +1,113 @@
+package secretsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const vaultRequestTimeout = 30 * time.Second
+
+// VaultConfig is the configuration of a source of the secrets in the KV
+// version 2 secrets engine of HashiCorp Vault.
+type VaultConfig struct {
+	// Address is the address of the Vault server, e.g. https://vault:8200.
+	Address string
+	// Token is the token the requests are authenticated with.
+	Token string
+	// Namespace is the namespace of the secrets, if any.
+	Namespace string
+	// Mount is the path the secrets engine is mounted at, "secret" by default.
+	Mount string
+	// Path is the path of the secret whose fields are the secrets. When it's
+	// empty, the keys of the secrets are the path#field pairs instead.
+	Path string
+}
+
+type vaultSource struct {
+	config VaultConfig
+	client *http.Client
+}
+
+// NewVaultSource returns a source of the secrets in the KV version 2 secrets
+// engine of HashiCorp Vault.
+func NewVaultSource(config VaultConfig, client *http.Client) (Source, error) {
+	if config.Address == "" {
+		return nil, errors.New("the address of the Vault server is required")
+	}
+	if config.Token == "" {
+		return nil, errors.New("the Vault token is required")
+	}
+	if config.Mount == "" {
+		config.Mount = "secret"
+	}
+	if client == nil {
+		client = &http.Client{Timeout: vaultRequestTimeout}
+	}
+	return vaultSource{config: config, client: client}, nil
+}
+
+func (s vaultSource) Description() string {
+	return fmt.Sprintf("the Vault server %s", s.config.Address)
+}
+
+func (s vaultSource) Get(key string) (string, error) {
+	path, field := s.config.Path, key
+	if path == "" {
+		var ok bool
+		if path, field, ok = strings.Cut(key, "#"); !ok {
+			return "", errors.New("the key needs to be a path#field pair, as no path is configured")
+		}
+	}
+
+	u, err := url.JoinPath(s.config.Address, "v1", s.config.Mount, "data", path)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), vaultRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", s.config.Token)
+	if s.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.config.Namespace)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d for the secret %s", res.StatusCode, path)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("couldn't parse the secret %s: %w", path, err)
+	}
+	value, ok := body.Data.Data[field]
+	if !ok {
+		return "", fmt.Errorf("the secret %s has no field %q", path, field)
+	}
+	if secret, ok := value.(string); ok {
+		return secret, nil
+	}
+	// the other JSON values are returned as they are encoded
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
@@ -114,6 +114,19 @@ func (pb *ProgressBar) Left() string {
 	return pb.renderLeft(0)
 }
 
+// Progress returns the current progress, clamped between 0 and 1, and the
+// status of the progressbar in a thread-safe way.
+func (pb *ProgressBar) Progress() (float64, Status) {
+	pb.mutex.RLock()
+	defer pb.mutex.RUnlock()
+
+	var progress float64
+	if pb.progress != nil {
+		progress, _ = pb.progress()
+	}
+	return Clampf(progress, 0, 1), pb.status
+}
+
 // renderLeft renders the left part of the progressbar, replacing text
 // exceeding maxLen with an ellipsis.
 func (pb *ProgressBar) renderLeft(maxLen int) string {
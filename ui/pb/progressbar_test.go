@@ -125,3 +125,17 @@ func TestProgressBarLeft(t *testing.T) {
 		})
 	}
 }
+
+func TestProgressBarProgress(t *testing.T) {
+	t.Parallel()
+
+	pbar := New()
+	progress, status := pbar.Progress()
+	assert.Equal(t, 0.0, progress)
+	assert.Equal(t, Status(0), status)
+
+	pbar.Modify(WithConstProgress(1.5), WithStatus(Done))
+	progress, status = pbar.Progress()
+	assert.Equal(t, 1.0, progress)
+	assert.Equal(t, Done, status)
+}